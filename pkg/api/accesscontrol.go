@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 )
@@ -375,12 +376,11 @@ func (hs *HTTPServer) declareFixedRoles() error {
 				Description: "Update organization annotations.",
 				Group:       "Annotations",
 				Permissions: []ac.Permission{
-					// Need to leave the permissions as they are, so that the seeder doesn't replace permissions when they have been removed from the basic role by the user
-					// Otherwise we could split this into ac.ScopeAnnotationsTypeOrganization and ac.ScopeAnnotationsTypeDashboard scopes and eventually remove the dashboard scope.
-					// https://github.com/grafana/identity-access-team/issues/524
-					{Action: ac.ActionAnnotationsCreate, Scope: ac.ScopeAnnotationsAll},
-					{Action: ac.ActionAnnotationsDelete, Scope: ac.ScopeAnnotationsAll},
-					{Action: ac.ActionAnnotationsWrite, Scope: ac.ScopeAnnotationsAll},
+					// The annotations of the dashboards are created, updated and deleted with the permissions of the
+					// users on the dashboards and their folders, see the managed dashboard and folder permissions.
+					{Action: ac.ActionAnnotationsCreate, Scope: ac.ScopeAnnotationsTypeOrganization},
+					{Action: ac.ActionAnnotationsDelete, Scope: ac.ScopeAnnotationsTypeOrganization},
+					{Action: ac.ActionAnnotationsWrite, Scope: ac.ScopeAnnotationsTypeOrganization},
 				},
 			},
 			Grants: []string{string(org.RoleEditor)},
@@ -580,6 +580,34 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{"Admin"},
 	}
 
+	playlistsReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:playlists:reader",
+			DisplayName: "Reader",
+			Description: "Read all playlists.",
+			Group:       "Playlists",
+			Permissions: []ac.Permission{
+				{Action: playlist.ActionRead, Scope: playlist.ScopeAll},
+			},
+		},
+		Grants: []string{string(org.RoleViewer)},
+	}
+
+	playlistsWriterRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:playlists:writer",
+			DisplayName: "Writer",
+			Description: "Create, read, write or delete all playlists.",
+			Group:       "Playlists",
+			Permissions: ac.ConcatPermissions(playlistsReaderRole.Role.Permissions, []ac.Permission{
+				{Action: playlist.ActionCreate},
+				{Action: playlist.ActionWrite, Scope: playlist.ScopeAll},
+				{Action: playlist.ActionDelete, Scope: playlist.ScopeAll},
+			}),
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
 	featuremgmtReaderRole := ac.RoleRegistration{
 		Role: ac.RoleDTO{
 			Name:        "fixed:featuremgmt:reader",
@@ -613,7 +641,8 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		dashboardsCreatorRole, dashboardsReaderRole, dashboardsWriterRole,
 		foldersCreatorRole, foldersReaderRole, generalFolderReaderRole, foldersWriterRole, apikeyReaderRole, apikeyWriterRole,
 		publicDashboardsWriterRole, featuremgmtReaderRole, featuremgmtWriterRole, libraryPanelsCreatorRole,
-		libraryPanelsReaderRole, libraryPanelsWriterRole, libraryPanelsGeneralReaderRole, libraryPanelsGeneralWriterRole}
+		libraryPanelsReaderRole, libraryPanelsWriterRole, libraryPanelsGeneralReaderRole, libraryPanelsGeneralWriterRole,
+		playlistsReaderRole, playlistsWriterRole}
 
 	if hs.Features.IsEnabled(context.Background(), featuremgmt.FlagAnnotationPermissionUpdate) {
 		allAnnotationsReaderRole := ac.RoleRegistration{
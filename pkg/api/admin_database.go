@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	defaultSlowQueriesLimit = 20
+	maxSlowQueriesLimit     = 100
+)
+
+func (hs *HTTPServer) AdminGetSlowQueries(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.DatabaseInstrumentQueries || hs.Cfg.DatabaseSlowQueryThreshold <= 0 {
+		return response.Error(http.StatusNotFound, "Slow queries are not recorded, set instrument_queries and slow_query_threshold in the [database] section", nil)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultSlowQueriesLimit
+	}
+	if limit > maxSlowQueriesLimit {
+		limit = maxSlowQueriesLimit
+	}
+
+	return response.JSON(http.StatusOK, sqlstore.SlowQueries(limit))
+}
@@ -25,6 +25,15 @@ func (hs *HTTPServer) AdminReEncryptEncryptionKeys(c *contextmodel.ReqContext) r
 	return response.Respond(http.StatusOK, "Data encryption keys re-encrypted successfully")
 }
 
+func (hs *HTTPServer) AdminGetEncryptionStatus(c *contextmodel.ReqContext) response.Response {
+	status, err := hs.SecretsService.GetEncryptionStatus(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get encryption status", err)
+	}
+
+	return response.JSON(http.StatusOK, status)
+}
+
 func (hs *HTTPServer) AdminReEncryptSecrets(c *contextmodel.ReqContext) response.Response {
 	success, err := hs.secretsMigrator.ReEncryptSecrets(c.Req.Context())
 	if err != nil {
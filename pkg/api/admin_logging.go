@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// SetLogLevelCommand is the body of the request setting the level of a logger.
+type SetLogLevelCommand struct {
+	Level string `json:"level"`
+	// Duration is how long the level is kept, e.g. "15m", it's kept until reset when empty.
+	Duration string `json:"duration"`
+}
+
+func (hs *HTTPServer) AdminGetLogLevels(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, log.Levels())
+}
+
+func (hs *HTTPServer) AdminSetLogLevel(c *contextmodel.ReqContext) response.Response {
+	name := web.Params(c.Req)[":logger"]
+	cmd := SetLogLevelCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	var ttl time.Duration
+	if cmd.Duration != "" {
+		var err error
+		if ttl, err = time.ParseDuration(cmd.Duration); err != nil || ttl <= 0 {
+			return response.Error(http.StatusBadRequest, "duration must be a positive duration", err)
+		}
+	}
+
+	if err := log.SetLevel(name, cmd.Level, ttl); err != nil {
+		if errors.Is(err, log.ErrUnknownLevel) {
+			return response.Error(http.StatusBadRequest, "Unknown log level", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to set log level", err)
+	}
+	hs.log.Info("Log level changed", "logger", name, "level", cmd.Level, "duration", ttl, "userId", c.UserID)
+
+	return response.Success("Log level set")
+}
+
+func (hs *HTTPServer) AdminResetLogLevel(c *contextmodel.ReqContext) response.Response {
+	name := web.Params(c.Req)[":logger"]
+	if !log.ResetLevel(name) {
+		return response.Error(http.StatusNotFound, "Log level not set", nil)
+	}
+	hs.log.Info("Log level reset", "logger", name, "userId", c.UserID)
+
+	return response.Success("Log level reset")
+}
@@ -3,12 +3,20 @@ package api
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	prov_git "github.com/grafana/grafana/pkg/services/provisioning/git"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
 )
 
+// maxGitWebhookBodySize is the size limit of the payload of a Git provisioning webhook.
+const maxGitWebhookBodySize = 25 << 20
+
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
 //
 // Reload dashboard provisioning configurations.
@@ -85,3 +93,112 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route GET /admin/provisioning/status admin_provisioning adminProvisioningStatus
+//
+// Get the status of the provisioners.
+//
+// Returns the last run of each provisioner, with the result of each provisioning file: whether it changed and was applied, or was skipped because it did not change, and the error it failed with. Data source and alerting files that did not change since they were last applied are skipped, and the resources of removed files are deleted if pruning is enabled.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminProvisioningStatus(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.ProvisioningService.GetProvisioningStatus())
+}
+
+// swagger:response adminProvisioningStatusResponse
+type AdminProvisioningStatusResponse struct {
+	// in:body
+	Body []status.ProvisionerStatus `json:"body"`
+}
+
+// swagger:route GET /admin/provisioning/git admin_provisioning adminProvisioningGitStatus
+//
+// Get the status of the Git repositories.
+//
+// Returns the last sync of each Git repository of the provisioning files, with the commit its provisioning files are read from and the error it failed with.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningGitStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminProvisioningGitStatus(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.ProvisioningService.GetGitRepositoriesStatus())
+}
+
+// swagger:route POST /admin/provisioning/git/{name}/sync admin_provisioning adminProvisioningSyncGitRepository
+//
+// Sync a Git repository.
+//
+// Fetches the last commit of the branch of the Git repository in the background. The data sources and alerting resources of the repository are provisioned again if the commit changed, and its dashboards are picked up by the polling of their provider.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (hs *HTTPServer) AdminProvisioningSyncGitRepository(c *contextmodel.ReqContext) response.Response {
+	if err := hs.ProvisioningService.SyncGitRepository(web.Params(c.Req)[":name"]); err != nil {
+		if errors.Is(err, prov_git.ErrRepositoryNotFound) {
+			return response.Error(http.StatusNotFound, "Git repository not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to sync Git repository", err)
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "Git repository sync started"})
+}
+
+// ProvisioningGitWebhook handles the push webhooks of the Git repositories of the provisioning files. The webhooks
+// are authenticated with the webhook secret of the repository, not with the session of a user.
+func (hs *HTTPServer) ProvisioningGitWebhook(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Resp, c.Req.Body, maxGitWebhookBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return response.Error(http.StatusRequestEntityTooLarge, "Webhook payload is too large", nil)
+		}
+		return response.Error(http.StatusBadRequest, "Failed to read webhook payload", err)
+	}
+
+	triggered, err := hs.ProvisioningService.HandleGitWebhook(c.Req.Context(), web.Params(c.Req)[":name"], c.Req.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, prov_git.ErrRepositoryNotFound), errors.Is(err, prov_git.ErrWebhookNotConfigured):
+			// do not tell which repositories exist
+			return response.Error(http.StatusNotFound, "Webhook not found", nil)
+		case errors.Is(err, prov_git.ErrInvalidWebhookSignature):
+			return response.Error(http.StatusUnauthorized, "Invalid webhook signature", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to handle webhook", err)
+	}
+	if !triggered {
+		return response.JSON(http.StatusOK, util.DynMap{"message": "Webhook ignored"})
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "Git repository sync started"})
+}
+
+// swagger:parameters adminProvisioningSyncGitRepository
+type AdminProvisioningSyncGitRepositoryParams struct {
+	// in:path
+	// required:true
+	Name string `json:"name"`
+}
+
+// swagger:response adminProvisioningGitStatusResponse
+type AdminProvisioningGitStatusResponse struct {
+	// in:body
+	Body []prov_git.RepositoryStatus `json:"body"`
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ReleaseServerLockCommand is the body of the request releasing a server lock.
+type ReleaseServerLockCommand struct {
+	ActionName string `json:"actionName"`
+}
+
+func (hs *HTTPServer) AdminGetServerLocks(c *contextmodel.ReqContext) response.Response {
+	locks, err := hs.serverLock.Locks(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list server locks", err)
+	}
+
+	return response.JSON(http.StatusOK, locks)
+}
+
+func (hs *HTTPServer) AdminReleaseServerLock(c *contextmodel.ReqContext) response.Response {
+	cmd := ReleaseServerLockCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.ActionName == "" {
+		return response.Error(http.StatusBadRequest, "actionName is required", nil)
+	}
+
+	if err := hs.serverLock.ForceRelease(c.Req.Context(), cmd.ActionName); err != nil {
+		if errors.Is(err, serverlock.ErrLockNotFound) {
+			return response.Error(http.StatusNotFound, "Server lock not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to release server lock", err)
+	}
+
+	return response.Success("Server lock released")
+}
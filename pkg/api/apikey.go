@@ -53,6 +53,8 @@ func (hs *HTTPServer) GetAPIKeys(c *contextmodel.ReqContext) response.Response {
 			Role:       t.Role,
 			Expiration: expiration,
 			LastUsedAt: t.LastUsedAt,
+			LastUsedIP: t.LastUsedIP,
+			UseCount:   t.UseCount,
 		}
 	}
 
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardlint "github.com/grafana/grafana/pkg/services/dashboards/lint"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
@@ -31,7 +32,9 @@ import (
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/resourcehash"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -225,6 +228,8 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 		Meta:      meta,
 	}
 
+	hs.dashboardInsights.RecordView(dash.OrgID, dash.UID)
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(http.StatusOK, dto)
 }
@@ -280,6 +285,42 @@ func (hs *HTTPServer) getDashboardHelper(ctx context.Context, orgID int64, id in
 	return queryResult, nil
 }
 
+// swagger:route GET /dashboards/trash dashboards getDashboardTrash
+//
+// Get the deleted dashboards and folders.
+//
+// Lists the dashboards and folders in the trash of the organization, the most recently deleted first. They can be
+// restored until their permanent deletion date.
+//
+// Responses:
+// 200: getDashboardTrashResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardTrash(c *contextmodel.ReqContext) response.Response {
+	deleted, err := hs.DashboardService.GetSoftDeletedDashboards(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the deleted dashboards", err)
+	}
+
+	retention := hs.Cfg.DashboardTrash.Retention
+	if retention <= 0 {
+		retention = setting.DefaultDashboardTrashRetention
+	}
+	items := make([]dtos.DashboardTrashItem, 0, len(deleted))
+	for _, dash := range deleted {
+		items = append(items, dtos.DashboardTrashItem{
+			UID:                   dash.UID,
+			Title:                 dash.Title,
+			IsFolder:              dash.IsFolder,
+			FolderUID:             dash.FolderUID,
+			Deleted:               dash.Deleted,
+			PermanentlyDeleteDate: dash.Deleted.Add(retention),
+		})
+	}
+	return response.JSON(http.StatusOK, items)
+}
+
 // swagger:route PATCH /dashboards/uid/{uid}/trash dashboards restoreDeletedDashboardByUID
 //
 // Restore a dashboard to a given dashboard version using UID.
@@ -319,7 +360,7 @@ func (hs *HTTPServer) RestoreDeletedDashboard(c *contextmodel.ReqContext) respon
 		if ok := errors.As(err, &dashboardErr); ok {
 			return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), err)
 		}
-		return response.Error(http.StatusInternalServerError, "Dashboard cannot be restored", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Dashboard cannot be restored", err)
 	}
 
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -434,7 +475,8 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 		return dashboardGuardianResponse(err)
 	}
 
-	if dash.IsFolder {
+	// the folders in the trash are purged like the dashboards, with the dashboards deleted with them left in the trash
+	if dash.IsFolder && dash.Deleted.IsZero() {
 		return response.Error(http.StatusBadRequest, "Use folders endpoint for deleting folders.", nil)
 	}
 
@@ -551,6 +593,20 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		allowUiUpdate = hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
 	}
 
+	// the state of the dashboard before the save, to return the fields it changed
+	var before resourcehash.State
+	if dash.UID != "" {
+		existing, err := hs.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dash.UID, OrgID: dash.OrgID})
+		if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusInternalServerError, "Failed to get the dashboard", err)
+		}
+		if existing != nil {
+			if before, err = dashboardState(existing); err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to hash the dashboard", err)
+			}
+		}
+	}
+
 	dashItem := &dashboards.SaveDashboardDTO{
 		Dashboard: dash,
 		Message:   cmd.Message,
@@ -601,8 +657,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
-	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":    "success",
 		"slug":      dashboard.Slug,
 		"version":   dashboard.Version,
@@ -610,7 +665,24 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
-	})
+	}
+	after, err := dashboardState(dashboard)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the dashboard", err)
+	}
+	hash, err := after.Compare(before)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the dashboard", err)
+	}
+	result["fieldsChanged"] = hash.FieldsChanged
+	result["hash"] = hash.Hash
+	// the lint warnings don't prevent saving, they are returned for the editor to show them
+	if warnings := hs.dashboardLint.LintSaved(ctx, dashboard); len(warnings) > 0 {
+		result["lintWarnings"] = warnings
+	}
+
+	c.TimeRequest(metrics.MApiDashboardSave)
+	return response.JSON(http.StatusOK, result).SetHeader(resourcehash.Header, hash.Hash)
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -1303,6 +1375,10 @@ type PostDashboardResponse struct {
 		// FolderUID The unique identifier (uid) of the folder the dashboard belongs to.
 		// required: false
 		FolderUID string `json:"folderUid"`
+
+		// LintWarnings The problems found in the saved dashboard, if any.
+		// required: false
+		LintWarnings []dashboardlint.Warning `json:"lintWarnings,omitempty"`
 	} `json:"body"`
 }
 
@@ -1324,6 +1400,12 @@ type DashboardsTagsResponse struct {
 	Body []*dashboards.DashboardTagCloudItem `json:"body"`
 }
 
+// swagger:response getDashboardTrashResponse
+type GetDashboardTrashResponse struct {
+	// in: body
+	Body []dtos.DashboardTrashItem `json:"body"`
+}
+
 // Get home dashboard response.
 // swagger:model GetHomeDashboardResponse
 type GetHomeDashboardResponseBody struct {
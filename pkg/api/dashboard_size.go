@@ -0,0 +1,175 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /dashboards/size dashboards getDashboardSizes
+//
+// Get the largest dashboards.
+//
+// Lists the dashboards of the organization from the largest, with the size of their JSON and the size stored in the
+// database, which is smaller when the dashboard is compressed.
+//
+// Responses:
+// 200: getDashboardSizesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardSizes(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	limit := c.QueryInt("limit")
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	sizes, err := hs.DashboardService.GetDashboardSizes(ctx, &dashboards.GetDashboardSizesQuery{OrgID: orgID, Limit: limit})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the dashboard sizes", err)
+	}
+	maxSize, err := hs.DashboardService.GetDashboardSizeLimit(ctx, orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the dashboard size limit", err)
+	}
+	return response.JSON(http.StatusOK, dtos.DashboardSizes{MaxSize: maxSize, Dashboards: sizes})
+}
+
+// swagger:route GET /dashboards/uid/{uid}/size dashboards getDashboardSizeByUID
+//
+// Get the size of a dashboard.
+//
+// Responses:
+// 200: getDashboardSizeResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardSizeByUID(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	sizes, err := hs.DashboardService.GetDashboardSizes(c.Req.Context(), &dashboards.GetDashboardSizesQuery{
+		OrgID: c.SignedInUser.GetOrgID(),
+		UIDs:  []string{uid},
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the dashboard size", err)
+	}
+	if len(sizes) == 0 {
+		return response.Error(http.StatusNotFound, "Dashboard not found", nil)
+	}
+	return response.JSON(http.StatusOK, sizes[0])
+}
+
+// swagger:route GET /orgs/{org_id}/dashboards/size-limit orgs getOrgDashboardSizeLimit
+//
+// Get the dashboard size limit of an organization.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `orgs.quotas:read` and scope `org:id:1` (orgIDScope).
+//
+// Responses:
+// 200: getDashboardSizeLimitResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgDashboardSizeLimit(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	maxSize, err := hs.DashboardService.GetDashboardSizeLimit(c.Req.Context(), orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the dashboard size limit", err)
+	}
+	return response.JSON(http.StatusOK, dtos.DashboardSizeLimit{MaxSize: &maxSize})
+}
+
+// swagger:route PUT /orgs/{org_id}/dashboards/size-limit orgs updateOrgDashboardSizeLimit
+//
+// Update the dashboard size limit of an organization.
+//
+// Saving a dashboard larger than the limit fails, the dashboards already larger than the limit are kept.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `orgs.quotas:write` and scope `org:id:1` (orgIDScope).
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateOrgDashboardSizeLimit(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	cmd := dtos.DashboardSizeLimit{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.MaxSize != nil && *cmd.MaxSize < 0 {
+		return response.Error(http.StatusBadRequest, "maxSize cannot be negative", nil)
+	}
+
+	if err := hs.DashboardService.SetDashboardSizeLimit(c.Req.Context(), orgID, cmd.MaxSize); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update the dashboard size limit", err)
+	}
+	return response.Success("Dashboard size limit updated")
+}
+
+// swagger:parameters getDashboardSizes
+type GetDashboardSizesParams struct {
+	// Maximum number of dashboards to return, 100 by default and at most 1000.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters getDashboardSizeByUID
+type GetDashboardSizeByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters getOrgDashboardSizeLimit
+type GetOrgDashboardSizeLimitParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:parameters updateOrgDashboardSizeLimit
+type UpdateOrgDashboardSizeLimitParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+	// in:body
+	// required:true
+	Body dtos.DashboardSizeLimit `json:"body"`
+}
+
+// swagger:response getDashboardSizesResponse
+type GetDashboardSizesResponse struct {
+	// in: body
+	Body dtos.DashboardSizes `json:"body"`
+}
+
+// swagger:response getDashboardSizeResponse
+type GetDashboardSizeResponse struct {
+	// in: body
+	Body dashboards.DashboardSize `json:"body"`
+}
+
+// swagger:response getDashboardSizeLimitResponse
+type GetDashboardSizeLimitResponse struct {
+	// in: body
+	Body dtos.DashboardSizeLimit `json:"body"`
+}
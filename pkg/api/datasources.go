@@ -28,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/resourcehash"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -612,7 +613,12 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
 	}
 
-	_, err := hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
+	before, err := dataSourceState(hs.convertModelToDtos(c.Req.Context(), ds))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the data source", err)
+	}
+
+	_, err = hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
 	if err != nil {
 		if errors.Is(err, datasources.ErrDataSourceNameExists) {
 			return response.Error(http.StatusConflict, "Failed to update datasource: "+err.Error(), err)
@@ -646,12 +652,28 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
 
+	after, err := dataSourceState(datasourceDTO)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the data source", err)
+	}
+	// the secrets are encrypted, so they are assumed to change when they are set
+	var changed []string
+	if len(cmd.SecureJsonData) > 0 {
+		changed = append(changed, "secureJsonFields")
+	}
+	result, err := after.Compare(before, changed...)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the data source", err)
+	}
+
 	return response.JSON(http.StatusOK, util.DynMap{
-		"message":    "Datasource updated",
-		"id":         cmd.ID,
-		"name":       cmd.Name,
-		"datasource": datasourceDTO,
-	})
+		"message":       "Datasource updated",
+		"id":            cmd.ID,
+		"name":          cmd.Name,
+		"datasource":    datasourceDTO,
+		"fieldsChanged": result.FieldsChanged,
+		"hash":          result.Hash,
+	}).SetHeader(resourcehash.Header, result.Hash)
 }
 
 func (hs *HTTPServer) getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*datasources.DataSource, error) {
@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -17,6 +19,10 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/query/progress"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/grafana/grafana/pkg/tsdb/queryerror"
 	"github.com/grafana/grafana/pkg/util/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -52,9 +58,29 @@ func (hs *HTTPServer) getDSQueryEndpoint() web.Handler {
 			hs.clientConfigProvider.DirectlyServeHTTP(w, r)
 		}
 	}
+	if hs.queryAuditService.Enabled() {
+		return hs.auditedQueryMetrics
+	}
 	return routing.Wrap(hs.QueryMetricsV2)
 }
 
+// auditedQueryMetrics executes the queries like QueryMetricsV2 and records the execution in the query audit log.
+func (hs *HTTPServer) auditedQueryMetrics(c *contextmodel.ReqContext) {
+	reqDTO := dtos.MetricRequest{}
+	if err := web.Bind(c.Req, &reqDTO); err != nil {
+		response.Error(http.StatusBadRequest, "bad request data", err).WriteTo(c)
+		return
+	}
+
+	start := time.Now()
+	hs.queryMetrics(c, reqDTO).WriteTo(c)
+	hs.queryAuditService.Record(c.Req.Context(), c.SignedInUser, reqDTO, queryaudit.Result{
+		Duration: time.Since(start),
+		Bytes:    c.Resp.Size(),
+		Status:   c.Resp.Status(),
+	})
+}
+
 // QueryMetricsV2 returns query metrics.
 // swagger:route POST /ds/query ds queryMetricsWithExpressions
 //
@@ -75,24 +101,121 @@ func (hs *HTTPServer) QueryMetricsV2(c *contextmodel.ReqContext) response.Respon
 	if err := web.Bind(c.Req, &reqDTO); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
+	return hs.queryMetrics(c, reqDTO)
+}
 
+func (hs *HTTPServer) queryMetrics(c *contextmodel.ReqContext, reqDTO dtos.MetricRequest) response.Response {
+	if err := hs.queryCostService.Enforce(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO); err != nil {
+		return response.Err(err)
+	}
+	if queryID := c.Req.Header.Get(progress.HeaderQueryID); queryID != "" {
+		return hs.trackedQueryMetrics(c, queryID, reqDTO)
+	}
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
+	hs.recordDashboardQueries(c, reqDTO, resp, err)
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
 	return hs.toJsonStreamingResponse(c.Req.Context(), resp)
 }
 
+// recordDashboardQueries adds the queries of a dashboard panel and the failed ones to the usage insights of the
+// dashboard, which is read from the headers of the request.
+func (hs *HTTPServer) recordDashboardQueries(c *contextmodel.ReqContext, reqDTO dtos.MetricRequest, resp *backend.QueryDataResponse, err error) {
+	dashboardUID := c.Req.Header.Get(query.HeaderDashboardUID)
+	if dashboardUID == "" || !hs.dashboardInsights.Enabled() {
+		return
+	}
+	failed := len(reqDTO.Queries)
+	if err == nil {
+		failed = 0
+		for _, res := range resp.Responses {
+			if res.Error != nil {
+				failed++
+			}
+		}
+	}
+	hs.dashboardInsights.RecordQueries(c.SignedInUser.GetOrgID(), dashboardUID, len(reqDTO.Queries), failed)
+}
+
+// QueryMetricsStream executes the queries like QueryMetricsV2, but writes the responses of every
+// datasource as a separate newline delimited JSON object as soon as they are available.
+func (hs *HTTPServer) QueryMetricsStream(c *contextmodel.ReqContext) {
+	reqDTO := dtos.MetricRequest{}
+	if err := web.Bind(c.Req, &reqDTO); err != nil {
+		response.Error(http.StatusBadRequest, "bad request data", err).WriteTo(c)
+		return
+	}
+	if err := hs.queryCostService.Enforce(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO); err != nil {
+		response.Err(err).WriteTo(c)
+		return
+	}
+
+	ctx := c.Req.Context()
+	var tracked *progress.Query
+	if queryID := c.Req.Header.Get(progress.HeaderQueryID); queryID != "" {
+		var err error
+		if ctx, tracked, err = hs.queryProgress.Start(ctx, c.SignedInUser, queryID, len(reqDTO.Queries)); err != nil {
+			queryProgressError(err).WriteTo(c)
+			return
+		}
+	}
+
+	started := false
+	// the responses are kept without their frames, for the usage insights of the dashboard
+	received := &backend.QueryDataResponse{Responses: backend.Responses{}}
+	err := hs.queryDataService.QueryDataStream(ctx, c.SignedInUser, c.SkipDSCache, reqDTO, func(responses backend.Responses) error {
+		if tracked != nil {
+			tracked.Update(ctx, responses)
+		}
+		for refID, res := range responses {
+			received.Responses[refID] = backend.DataResponse{Error: res.Error}
+		}
+		body, err := json.Marshal(&backend.QueryDataResponse{Responses: responses})
+		if err != nil {
+			return err
+		}
+		if !started {
+			started = true
+			c.Resp.Header().Set("Content-Type", "application/x-ndjson")
+			c.Resp.WriteHeader(http.StatusOK)
+		}
+		if _, err := c.Resp.Write(append(body, '\n')); err != nil {
+			return err
+		}
+		c.Resp.Flush()
+		return nil
+	})
+	if tracked != nil {
+		tracked.Finish(ctx, err)
+	}
+	hs.recordDashboardQueries(c, reqDTO, received, err)
+	if err == nil {
+		return
+	}
+	if !started {
+		hs.handleQueryMetricsError(err).WriteTo(c)
+		return
+	}
+	hs.log.Warn("Failed to stream query responses", "error", err)
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(ctx context.Context, qdr *backend.QueryDataResponse) response.Response {
+	// the status follows the type of the errors of the failed queries, and is a bad request when they differ
 	statusCode := http.StatusOK
 	for _, res := range qdr.Responses {
-		if res.Error != nil {
+		if res.Error == nil {
+			continue
+		}
+		errorStatus := queryerror.Classify(res).HTTPStatus()
+		if statusCode != http.StatusOK && statusCode != errorStatus {
 			statusCode = http.StatusBadRequest
 			break
 		}
+		statusCode = errorStatus
 	}
 
-	if statusCode == http.StatusBadRequest {
+	if statusCode != http.StatusOK {
 		// an error in the response we treat as downstream.
 		requestmeta.WithDownstreamStatusSource(ctx)
 	}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/query/progress"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// trackedQueryMetrics executes the queries like queryMetrics, and reports the progress of the request under the
+// query ID while the datasources respond.
+func (hs *HTTPServer) trackedQueryMetrics(c *contextmodel.ReqContext, queryID string, reqDTO dtos.MetricRequest) response.Response {
+	ctx, tracked, err := hs.queryProgress.Start(c.Req.Context(), c.SignedInUser, queryID, len(reqDTO.Queries))
+	if err != nil {
+		return queryProgressError(err)
+	}
+
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{}}
+	err = hs.queryDataService.QueryDataStream(ctx, c.SignedInUser, c.SkipDSCache, reqDTO, func(responses backend.Responses) error {
+		tracked.Update(ctx, responses)
+		for refID, res := range responses {
+			resp.Responses[refID] = res
+		}
+		return nil
+	})
+	tracked.Finish(ctx, err)
+	hs.recordDashboardQueries(c, reqDTO, resp, err)
+	if err != nil {
+		return hs.handleQueryMetricsError(err)
+	}
+	return hs.toJsonStreamingResponse(c.Req.Context(), resp)
+}
+
+// QueryProgressEvents streams the progress of a query request sent with an X-Query-Id header as server-sent
+// events, until the query is finished.
+func (hs *HTTPServer) QueryProgressEvents(c *contextmodel.ReqContext) {
+	updates, unsubscribe, err := hs.queryProgress.Subscribe(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":queryId"])
+	if err != nil {
+		queryProgressError(err).WriteTo(c)
+		return
+	}
+	defer unsubscribe()
+
+	c.Resp.Header().Set("Content-Type", "text/event-stream")
+	c.Resp.Header().Set("Cache-Control", "no-cache")
+	c.Resp.WriteHeader(http.StatusOK)
+	for {
+		select {
+		case <-c.Req.Context().Done():
+			return
+		case p, ok := <-updates:
+			if !ok {
+				return
+			}
+			body, err := json.Marshal(p)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(c.Resp, "event: progress\ndata: %s\n\n", body); err != nil {
+				return
+			}
+			c.Resp.Flush()
+		}
+	}
+}
+
+// CancelQuery cancels a running query request sent with an X-Query-Id header.
+func (hs *HTTPServer) CancelQuery(c *contextmodel.ReqContext) response.Response {
+	if err := hs.queryProgress.Cancel(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":queryId"]); err != nil {
+		return queryProgressError(err)
+	}
+	return response.Success("Query cancelled")
+}
+
+func queryProgressError(err error) *response.NormalResponse {
+	switch {
+	case errors.Is(err, progress.ErrQueryNotFound):
+		return response.Error(http.StatusNotFound, "Query not found", err)
+	case errors.Is(err, progress.ErrQueryIDInUse):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, progress.ErrInvalidQueryID):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	default:
+		return response.Error(http.StatusInternalServerError, "Query progress error", err)
+	}
+}
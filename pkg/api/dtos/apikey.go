@@ -23,5 +23,7 @@ type ApiKeyDTO struct {
 	Role          org.RoleType           `json:"role"`
 	Expiration    *time.Time             `json:"expiration,omitempty"`
 	LastUsedAt    *time.Time             `json:"lastUsedAt,omitempty"`
+	LastUsedIP    *string                `json:"lastUsedIp,omitempty"`
+	UseCount      int64                  `json:"useCount"`
 	AccessControl accesscontrol.Metadata `json:"accessControl,omitempty"`
 }
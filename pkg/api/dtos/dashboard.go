@@ -5,6 +5,7 @@ import (
 
 	dashboardsV0 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 )
 
 type DashboardMeta struct {
@@ -61,3 +62,29 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+// DashboardSizes are the largest dashboards of an organization.
+type DashboardSizes struct {
+	// MaxSize is the maximum size in bytes of the dashboards of the organization, zero means no limit.
+	MaxSize    int64                       `json:"maxSize"`
+	Dashboards []*dashboards.DashboardSize `json:"dashboards"`
+}
+
+// DashboardSizeLimit is the maximum size in bytes of the dashboards of an organization.
+type DashboardSizeLimit struct {
+	// MaxSize is the maximum size, zero means no limit. When updating the limit, null resets it to the limit of the
+	// configuration.
+	MaxSize *int64 `json:"maxSize"`
+}
+
+// DashboardTrashItem is a dashboard or a folder in the trash of an organization.
+type DashboardTrashItem struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	IsFolder  bool   `json:"isFolder"`
+	FolderUID string `json:"folderUid,omitempty"`
+	// Deleted is when the dashboard or folder was moved to the trash.
+	Deleted time.Time `json:"deleted"`
+	// PermanentlyDeleteDate is when the dashboard or folder is purged from the trash.
+	PermanentlyDeleteDate time.Time `json:"permanentlyDeleteDate"`
+}
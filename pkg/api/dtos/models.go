@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/templatevars"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -79,6 +80,11 @@ type MetricRequest struct {
 	Queries []*simplejson.Json `json:"queries"`
 	// required: false
 	Debug bool `json:"debug"`
+	// Variables are the values of the dashboard template variables used in the queries, by name. The variables
+	// are resolved on the server with the same syntax and formats as in dashboards.
+	// required: false
+	// example: { "job": { "value": ["api", "web"] }, "instance": { "value": "host:9090" } }
+	Variables templatevars.Variables `json:"variables,omitempty"`
 }
 
 func (mr *MetricRequest) GetUniqueDatasourceTypes() []string {
@@ -155,3 +161,8 @@ func IsHiddenUser(userLogin string, signedInUser identity.Requester, cfg *settin
 
 	return false
 }
+
+// ResourceHash is the hash of a resource, which only changes when the resource changes.
+type ResourceHash struct {
+	Hash string `json:"hash"`
+}
@@ -68,3 +68,20 @@ func (slice PluginList) Swap(i, j int) {
 type InstallPluginCommand struct {
 	Version string `json:"version"`
 }
+
+type RollbackPluginCommand struct {
+	// Version to roll back to, the most recent previous version if empty.
+	Version string `json:"version"`
+}
+
+type InstallPluginResult struct {
+	PluginID string `json:"pluginId"`
+	Version  string `json:"version"`
+	// RequiresRestart is true for the plugins Grafana only starts on startup, like the renderer plugins.
+	RequiresRestart bool `json:"requiresRestart"`
+}
+
+type PluginVersionsDTO struct {
+	// Versions the plugin can be rolled back to, the most recent first.
+	Versions []string `json:"versions"`
+}
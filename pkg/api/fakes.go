@@ -35,6 +35,18 @@ func (pm *fakePluginInstaller) Remove(_ context.Context, pluginID, _ string) err
 	return nil
 }
 
+func (pm *fakePluginInstaller) Rollback(_ context.Context, pluginID, version string) error {
+	pm.plugins[pluginID] = fakePlugin{
+		pluginID: pluginID,
+		version:  version,
+	}
+	return nil
+}
+
+func (pm *fakePluginInstaller) Versions(_ context.Context, _ string) ([]string, error) {
+	return []string{}, nil
+}
+
 type fakeRendererPluginManager struct {
 	rendering.PluginManager
 }
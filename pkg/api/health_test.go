@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/health"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -170,6 +172,48 @@ func TestHealthAPI_DatabaseHealthCached(t *testing.T) {
 	require.True(t, healthy.(bool))
 }
 
+func TestHealthAPI_Live(t *testing.T) {
+	m, _ := setupHealthAPITestEnvironment(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health/live", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	require.JSONEq(t, `{"status": "ok"}`, rec.Body.String())
+}
+
+func TestHealthAPI_Ready(t *testing.T) {
+	ok := health.CheckerFunc(func(context.Context) error { return nil })
+	failing := health.CheckerFunc(func(context.Context) error { return errors.New("unreachable") })
+
+	t.Run("degraded when a non-critical dependency fails", func(t *testing.T) {
+		m, hs := setupHealthAPITestEnvironment(t)
+		hs.health.Register(health.Dependency{Name: "database", Critical: true, Checker: ok})
+		hs.health.Register(health.Dependency{Name: "plugins", Checker: failing})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/health/ready", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 200, rec.Code)
+		require.Equal(t, "degraded", rec.Header().Get("X-Grafana-Health"))
+		require.NotContains(t, rec.Body.String(), "unreachable", "the errors are not exposed")
+	})
+
+	t.Run("not ready when a critical dependency fails", func(t *testing.T) {
+		m, hs := setupHealthAPITestEnvironment(t)
+		hs.health.Register(health.Dependency{Name: "database", Critical: true, Checker: failing})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/health/ready", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 503, rec.Code)
+		require.Equal(t, "failing", rec.Header().Get("X-Grafana-Health"))
+	})
+}
+
 func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*web.Mux, *HTTPServer) {
 	t.Helper()
 
@@ -182,8 +226,11 @@ func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*we
 		CacheService: localcache.New(5*time.Minute, 10*time.Minute),
 		Cfg:          cfg,
 		SQLStore:     dbtest.NewFakeDB(),
+		health:       health.NewService(nil),
 	}
 
 	m.Get("/api/health", hs.apiHealthHandler)
+	m.Get("/api/health/live", hs.apiHealthLiveHandler)
+	m.Get("/api/health/ready", hs.apiHealthReadyHandler)
 	return m, hs
 }
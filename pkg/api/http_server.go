@@ -34,26 +34,32 @@ import (
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/csrf"
+	"github.com/grafana/grafana/pkg/middleware/loadshedding"
 	"github.com/grafana/grafana/pkg/middleware/loggermw"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/pluginscdn"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/admissionwebhook"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardinsights "github.com/grafana/grafana/pkg/services/dashboards/insights"
+	dashboardlint "github.com/grafana/grafana/pkg/services/dashboards/lint"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
@@ -62,6 +68,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/health"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
@@ -80,11 +87,16 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/managedplugins"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstatus"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginwebhooks"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	publicdashboardsApi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/query/progress"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/grafana/grafana/pkg/services/querycost"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -99,6 +111,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/star"
 	starApi "github.com/grafana/grafana/pkg/services/star/api"
 	"github.com/grafana/grafana/pkg/services/stats"
+	"github.com/grafana/grafana/pkg/services/stepup"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/services/team"
@@ -193,6 +206,7 @@ type HTTPServer struct {
 	PublicDashboardsApi          *publicdashboardsApi.Api
 	starService                  star.Service
 	playlistService              playlist.Service
+	playlistPermissionsService   accesscontrol.PlaylistPermissionsService
 	apiKeyService                apikey.Service
 	kvStore                      kvstore.KVStore
 	pluginsCDNService            *pluginscdn.Service
@@ -216,6 +230,18 @@ type HTTPServer struct {
 	namespacer           request.NamespaceMapper
 	anonService          anonymous.Service
 	userVerifier         user.Verifier
+	queryAuditService    *queryaudit.Service
+	queryCostService     *querycost.Service
+	queryProgress        *progress.Tracker
+	dashboardLint        *dashboardlint.Service
+	dashboardInsights    *dashboardinsights.Service
+	auditLog             *auditlog.Service
+	pluginStatus         *pluginstatus.Service
+	pluginWebhookLimiter *pluginwebhooks.Limiter
+	admissionWebhooks    *admissionwebhook.Service
+	stepUp               *stepup.Service
+	serverLock           *serverlock.ServerLockService
+	health               *health.Service
 	tlsCerts             TLSCerts
 }
 
@@ -257,7 +283,8 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	folderPermissionsService accesscontrol.FolderPermissionsService,
 	dashboardPermissionsService accesscontrol.DashboardPermissionsService, dashboardVersionService dashver.Service,
 	starService star.Service, csrfService csrf.Service, managedPlugins managedplugins.Manager,
-	playlistService playlist.Service, apiKeyService apikey.Service, kvStore kvstore.KVStore,
+	playlistService playlist.Service, playlistPermissionsService accesscontrol.PlaylistPermissionsService,
+	apiKeyService apikey.Service, kvStore kvstore.KVStore,
 	secretsMigrator secrets.Migrator, secretsPluginManager plugins.SecretsPluginManager, secretsService secrets.Service,
 	secretsPluginMigrator spm.SecretMigrationProvider, secretsStore secretsKV.SecretsKVStore,
 	publicDashboardsApi *publicdashboardsApi.Api, userService user.Service, tempUserService tempUser.Service,
@@ -266,7 +293,10 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	annotationRepo annotations.Repository, tagService tag.Service, searchv2HTTPService searchV2.SearchHTTPService, oauthTokenService oauthtoken.OAuthTokenService,
 	statsService stats.Service, authnService authn.Service, pluginsCDNService *pluginscdn.Service, promGatherer prometheus.Gatherer,
 	starApi *starApi.API, promRegister prometheus.Registerer, clientConfigProvider grafanaapiserver.DirectRestConfigProvider, anonService anonymous.Service,
-	userVerifier user.Verifier,
+	userVerifier user.Verifier, queryAuditService *queryaudit.Service, queryCostService *querycost.Service,
+	queryProgress *progress.Tracker, dashboardLint *dashboardlint.Service, dashboardInsights *dashboardinsights.Service,
+	auditLog *auditlog.Service, pluginStatus *pluginstatus.Service, admissionWebhooks *admissionwebhook.Service,
+	stepUp *stepup.Service, serverLock *serverlock.ServerLockService, healthService *health.Service,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -345,6 +375,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		dashboardVersionService:      dashboardVersionService,
 		starService:                  starService,
 		playlistService:              playlistService,
+		playlistPermissionsService:   playlistPermissionsService,
 		apiKeyService:                apiKeyService,
 		kvStore:                      kvStore,
 		PublicDashboardsApi:          publicDashboardsApi,
@@ -369,6 +400,18 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		namespacer:                   request.GetNamespaceMapper(cfg),
 		anonService:                  anonService,
 		userVerifier:                 userVerifier,
+		queryAuditService:            queryAuditService,
+		queryCostService:             queryCostService,
+		queryProgress:                queryProgress,
+		dashboardLint:                dashboardLint,
+		dashboardInsights:            dashboardInsights,
+		auditLog:                     auditLog,
+		pluginStatus:                 pluginStatus,
+		pluginWebhookLimiter:         pluginwebhooks.NewLimiter(cfg.PluginWebhooks),
+		admissionWebhooks:            admissionWebhooks,
+		stepUp:                       stepUp,
+		serverLock:                   serverLock,
+		health:                       healthService,
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
@@ -599,6 +642,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	}
 
 	m.UseMiddleware(middleware.Recovery(hs.Cfg, hs.License))
+
 	m.UseMiddleware(hs.Csrf.Middleware())
 
 	hs.mapStatic(m, hs.Cfg.StaticRootPath, "build", "public/build")
@@ -626,13 +670,29 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	// and should not be redirected or rejected.
 	m.Use(hs.healthzHandler)
 	m.Use(hs.apiHealthHandler)
+	m.Use(hs.apiHealthLiveHandler)
+	m.Use(hs.apiHealthReadyHandler)
 	m.Use(hs.metricsEndpoint)
 	m.Use(hs.pluginMetricsEndpoint)
 	m.Use(hs.frontendLogEndpoints())
 
 	m.UseMiddleware(hs.ContextHandler.Middleware)
+
+	// needs to be after context handler, to classify the requests by identity
+	if hs.Cfg.LoadShedding.Enabled {
+		m.UseMiddleware(loadshedding.Middleware(hs.Cfg, hs.promRegister))
+	}
+
 	m.Use(middleware.OrgRedirect(hs.Cfg, hs.userService))
 
+	if hs.auditLog.Enabled() {
+		m.UseMiddleware(hs.auditLog.Middleware())
+	}
+
+	if hs.stepUp.Enabled() {
+		m.UseMiddleware(hs.stepUp.Middleware())
+	}
+
 	// needs to be after context handler
 	if hs.Cfg.EnforceDomain {
 		m.Use(middleware.ValidateHostHeader(hs.Cfg))
@@ -736,6 +796,65 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	}
 }
 
+// swagger:route GET /health/live health getHealthLive
+//
+// apiHealthLiveHandler returns ok if Grafana's web server is running, whatever the state of its dependencies.
+//
+// Responses:
+// 200: healthLiveResponse
+func (hs *HTTPServer) apiHealthLiveHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/api/health/live" {
+		return
+	}
+
+	hs.writeHealth(ctx, http.StatusOK, healthLiveResponse{Status: health.StatusOK})
+}
+
+// swagger:model healthLiveResponse
+type healthLiveResponse struct {
+	Status health.Status `json:"status"`
+}
+
+// swagger:route GET /health/ready health getHealthReady
+//
+// apiHealthReadyHandler checks the dependencies of Grafana. It returns http status code 503 if a critical
+// dependency, such as the database, is failing. If only non-critical dependencies, such as the image renderer, are
+// failing, it returns 200 with the degraded status and the X-Grafana-Health: degraded header.
+//
+// Responses:
+// 200: healthReport
+// 503: healthReport
+func (hs *HTTPServer) apiHealthReadyHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/api/health/ready" {
+		return
+	}
+
+	report := hs.health.Check(ctx.Req.Context())
+	ctx.Resp.Header().Set("X-Grafana-Health", string(report.Status))
+	status := http.StatusOK
+	if report.Status == health.StatusFailing {
+		status = http.StatusServiceUnavailable
+	}
+	hs.writeHealth(ctx, status, report)
+}
+
+func (hs *HTTPServer) writeHealth(ctx *web.Context, status int, data any) {
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.WriteHeader(status)
+
+	dataBytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		hs.log.Error("Failed to encode data", "err", err)
+		return
+	}
+
+	if _, err := ctx.Resp.Write(dataBytes); err != nil {
+		hs.log.Error("Failed to write to response", "err", err)
+	}
+}
+
 func (hs *HTTPServer) mapStatic(m *web.Mux, rootDir string, dir string, prefix string, exclude ...string) {
 	headers := func(c *web.Context) {
 		c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
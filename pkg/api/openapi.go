@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+// openAPIComponentSections are the sections of the components of an OpenAPI v3 document.
+var openAPIComponentSections = []string{
+	"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links", "callbacks",
+}
+
+// GetMergedOpenAPIv3 serves one OpenAPI v3 document describing both the legacy /api routes and the
+// routes of the grafana.app API groups. The group query parameter limits the document to the given
+// API groups, in which case the legacy routes are only included when legacy=true.
+//
+// GET /api/openapi/v3
+func (hs *HTTPServer) GetMergedOpenAPIv3(c *contextmodel.ReqContext) response.Response {
+	groups := c.QueryStrings("group")
+	includeLegacy := c.QueryBoolWithDefault("legacy", len(groups) == 0)
+
+	doc := map[string]any{
+		"openapi": "3.0.0",
+		"info": map[string]any{
+			"title":   "Grafana API",
+			"version": hs.Cfg.BuildVersion,
+		},
+		"servers": []any{
+			map[string]any{"url": hs.Cfg.AppSubURL + "/"},
+		},
+		"paths":      map[string]any{},
+		"components": map[string]any{},
+	}
+
+	if includeLegacy {
+		legacy, err := hs.readLegacyOpenAPIv3()
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to read the OpenAPI document of the legacy API", err)
+		}
+		mergeOpenAPIDoc(doc, legacy, "/api")
+		if security, ok := legacy["security"]; ok {
+			doc["security"] = security
+		}
+	}
+
+	if hs.clientConfigProvider != nil {
+		groupDocs, err := hs.getAPIGroupsOpenAPIv3(c, groups)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to get the OpenAPI documents of the API groups", err)
+		}
+		for _, groupDoc := range groupDocs {
+			mergeOpenAPIDoc(doc, groupDoc, "")
+		}
+	}
+
+	return response.JSON(http.StatusOK, doc)
+}
+
+func (hs *HTTPServer) readLegacyOpenAPIv3() (map[string]any, error) {
+	data, err := os.ReadFile(filepath.Join(hs.Cfg.StaticRootPath, "openapi3.json"))
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]any{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// getAPIGroupsOpenAPIv3 returns the OpenAPI documents of the grafana.app API groups, sorted by group and
+// version. When groups is not empty, only the documents of these groups are returned.
+func (hs *HTTPServer) getAPIGroupsOpenAPIv3(c *contextmodel.ReqContext, groups []string) ([]map[string]any, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(hs.clientConfigProvider.GetDirectRestConfig(c))
+	if err != nil {
+		return nil, err
+	}
+	paths, err := client.OpenAPIV3().Paths()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	docs := []map[string]any{}
+	for _, key := range keys {
+		// the keys are apis/<group>/<version>
+		parts := strings.Split(key, "/")
+		if len(parts) != 3 || parts[0] != "apis" || !strings.HasSuffix(parts[1], ".grafana.app") {
+			continue
+		}
+		if len(groups) > 0 && !slices.Contains(groups, parts[1]) {
+			continue
+		}
+
+		data, err := paths[key].Schema(runtime.ContentTypeJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the OpenAPI document of %s: %w", key, err)
+		}
+		doc := map[string]any{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse the OpenAPI document of %s: %w", key, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// mergeOpenAPIDoc adds the paths, components and tags of src to dst. The paths of src are prefixed with
+// pathPrefix. Components with a name already defined in dst are skipped, since the API groups share the
+// definitions of the Kubernetes types.
+func mergeOpenAPIDoc(dst, src map[string]any, pathPrefix string) {
+	dstPaths := openAPIObject(dst, "paths")
+	if srcPaths, ok := src["paths"].(map[string]any); ok {
+		for path, item := range srcPaths {
+			dstPaths[pathPrefix+path] = item
+		}
+	}
+
+	dstComponents := openAPIObject(dst, "components")
+	if srcComponents, ok := src["components"].(map[string]any); ok {
+		for _, section := range openAPIComponentSections {
+			srcSection, ok := srcComponents[section].(map[string]any)
+			if !ok {
+				continue
+			}
+			dstSection := openAPIObject(dstComponents, section)
+			for name, component := range srcSection {
+				if _, exists := dstSection[name]; !exists {
+					dstSection[name] = component
+				}
+			}
+		}
+	}
+
+	if srcTags, ok := src["tags"].([]any); ok {
+		dstTags, _ := dst["tags"].([]any)
+		names := map[any]bool{}
+		for _, tag := range dstTags {
+			if t, ok := tag.(map[string]any); ok {
+				names[t["name"]] = true
+			}
+		}
+		for _, tag := range srcTags {
+			if t, ok := tag.(map[string]any); ok && !names[t["name"]] {
+				names[t["name"]] = true
+				dstTags = append(dstTags, tag)
+			}
+		}
+		dst["tags"] = dstTags
+	}
+}
+
+func openAPIObject(parent map[string]any, key string) map[string]any {
+	obj, ok := parent[key].(map[string]any)
+	if !ok {
+		obj = map[string]any{}
+		parent[key] = obj
+	}
+	return obj
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMergeOpenAPIDoc(t *testing.T) {
+	dst := map[string]any{
+		"paths": map[string]any{},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": map[string]any{"description": "first"},
+			},
+		},
+		"tags": []any{map[string]any{"name": "teams"}},
+	}
+
+	mergeOpenAPIDoc(dst, map[string]any{
+		"paths": map[string]any{
+			"/teams/search": map[string]any{"get": map[string]any{}},
+		},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"TeamDTO": map[string]any{"type": "object"},
+			},
+			"securitySchemes": map[string]any{
+				"basic": map[string]any{"type": "http"},
+			},
+		},
+		"tags": []any{map[string]any{"name": "teams"}, map[string]any{"name": "users"}},
+	}, "/api")
+
+	mergeOpenAPIDoc(dst, map[string]any{
+		"paths": map[string]any{
+			"/apis/identity.grafana.app/v0alpha1/namespaces/{namespace}/users": map[string]any{"get": map[string]any{}},
+		},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": map[string]any{"description": "second"},
+			},
+		},
+	}, "")
+
+	paths := dst["paths"].(map[string]any)
+	assert.Contains(t, paths, "/api/teams/search")
+	assert.Contains(t, paths, "/apis/identity.grafana.app/v0alpha1/namespaces/{namespace}/users")
+
+	components := dst["components"].(map[string]any)
+	schemas := components["schemas"].(map[string]any)
+	assert.Contains(t, schemas, "TeamDTO")
+	assert.Equal(t, map[string]any{"description": "first"}, schemas["io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"])
+	assert.Contains(t, components["securitySchemes"], "basic")
+
+	assert.Equal(t, []any{map[string]any{"name": "teams"}, map[string]any{"name": "users"}}, dst["tags"])
+}
+
+func TestGetMergedOpenAPIv3(t *testing.T) {
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.StaticRootPath = "../../public/"
+	})
+
+	get := func(t *testing.T, url string) map[string]any {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest(url), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		doc := map[string]any{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&doc))
+		require.NoError(t, res.Body.Close())
+		return doc
+	}
+
+	t.Run("includes the legacy routes under /api", func(t *testing.T) {
+		doc := get(t, "/api/openapi/v3")
+		assert.Equal(t, "3.0.0", doc["openapi"])
+		assert.Contains(t, doc["paths"], "/api/teams/search")
+		assert.NotEmpty(t, doc["security"])
+	})
+
+	t.Run("leaves out the legacy routes when filtering on groups", func(t *testing.T) {
+		doc := get(t, "/api/openapi/v3?group=identity.grafana.app")
+		assert.Empty(t, doc["paths"])
+	})
+
+	t.Run("includes the legacy routes when asked to", func(t *testing.T) {
+		doc := get(t, "/api/openapi/v3?group=identity.grafana.app&legacy=true")
+		assert.Contains(t, doc["paths"], "/api/teams/search")
+	})
+}
@@ -9,12 +9,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1"
-	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/infra/log"
 	internalplaylist "github.com/grafana/grafana/pkg/registry/apis/playlist"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -25,25 +27,28 @@ import (
 )
 
 func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
+	authorize := ac.Middleware(hs.AccessControl)
+	uidScope := playlist.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
 	// Register the actual handlers
 	apiRoute.Group("/playlists", func(playlistRoute routing.RouteRegister) {
 		if hs.Features.IsEnabledGlobally(featuremgmt.FlagKubernetesPlaylists) {
 			// Use k8s client to implement legacy API
 			handler := newPlaylistK8sHandler(hs)
-			playlistRoute.Get("/", handler.searchPlaylists)
-			playlistRoute.Get("/:uid", handler.getPlaylist)
-			playlistRoute.Get("/:uid/items", handler.getPlaylistItems)
-			playlistRoute.Delete("/:uid", handler.deletePlaylist)
-			playlistRoute.Put("/:uid", handler.updatePlaylist)
-			playlistRoute.Post("/", handler.createPlaylist)
+			playlistRoute.Get("/", authorize(ac.EvalPermission(playlist.ActionRead)), handler.searchPlaylists)
+			playlistRoute.Get("/:uid", authorize(ac.EvalPermission(playlist.ActionRead, uidScope)), handler.getPlaylist)
+			playlistRoute.Get("/:uid/items", authorize(ac.EvalPermission(playlist.ActionRead, uidScope)), handler.getPlaylistItems)
+			playlistRoute.Delete("/:uid", authorize(ac.EvalPermission(playlist.ActionDelete, uidScope)), handler.deletePlaylist)
+			playlistRoute.Put("/:uid", authorize(ac.EvalPermission(playlist.ActionWrite, uidScope)), handler.updatePlaylist)
+			playlistRoute.Post("/", authorize(ac.EvalPermission(playlist.ActionCreate)), handler.createPlaylist)
 		} else {
 			// Legacy handlers
-			playlistRoute.Get("/", routing.Wrap(hs.SearchPlaylists))
-			playlistRoute.Get("/:uid", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylist))
-			playlistRoute.Get("/:uid/items", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistItems))
-			playlistRoute.Delete("/:uid", middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.DeletePlaylist))
-			playlistRoute.Put("/:uid", middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.UpdatePlaylist))
-			playlistRoute.Post("/", middleware.ReqEditorRole, routing.Wrap(hs.CreatePlaylist))
+			playlistRoute.Get("/", authorize(ac.EvalPermission(playlist.ActionRead)), routing.Wrap(hs.SearchPlaylists))
+			playlistRoute.Get("/:uid", authorize(ac.EvalPermission(playlist.ActionRead, uidScope)), hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylist))
+			playlistRoute.Get("/:uid/items", authorize(ac.EvalPermission(playlist.ActionRead, uidScope)), hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistItems))
+			playlistRoute.Delete("/:uid", authorize(ac.EvalPermission(playlist.ActionDelete, uidScope)), hs.validateOrgPlaylist, routing.Wrap(hs.DeletePlaylist))
+			playlistRoute.Put("/:uid", authorize(ac.EvalPermission(playlist.ActionWrite, uidScope)), hs.validateOrgPlaylist, routing.Wrap(hs.UpdatePlaylist))
+			playlistRoute.Post("/", authorize(ac.EvalPermission(playlist.ActionCreate)), routing.Wrap(hs.CreatePlaylist))
 		}
 	})
 }
@@ -95,7 +100,23 @@ func (hs *HTTPServer) SearchPlaylists(c *contextmodel.ReqContext) response.Respo
 		return response.Error(http.StatusInternalServerError, "Search failed", err)
 	}
 
-	return response.JSON(http.StatusOK, playlists)
+	canRead := canReadPlaylist(c)
+	filtered := make(playlist.Playlists, 0, len(playlists))
+	for _, p := range playlists {
+		if canRead(p.UID) {
+			filtered = append(filtered, p)
+		}
+	}
+
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// canReadPlaylist returns whether the user can read a playlist by its UID.
+func canReadPlaylist(c *contextmodel.ReqContext) func(uid string) bool {
+	canRead := ac.Checker(c.SignedInUser, playlist.ActionRead)
+	return func(uid string) bool {
+		return canRead(playlist.ScopeProvider.GetResourceScopeUID(uid))
+	}
 }
 
 // swagger:route GET /playlists/{uid} playlists getPlaylist
@@ -160,6 +181,10 @@ func (hs *HTTPServer) DeletePlaylist(c *contextmodel.ReqContext) response.Respon
 		return response.Error(http.StatusInternalServerError, "Failed to delete playlist", err)
 	}
 
+	if err := hs.playlistPermissionsService.DeleteResourcePermissions(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		hs.log.Warn("Failed to delete playlist permissions", "uid", uid, "error", err)
+	}
+
 	return response.JSON(http.StatusOK, "")
 }
 
@@ -185,9 +210,30 @@ func (hs *HTTPServer) CreatePlaylist(c *contextmodel.ReqContext) response.Respon
 		return response.Error(http.StatusInternalServerError, "Failed to create playlist", err)
 	}
 
+	if err := setDefaultPlaylistPermissions(c, hs.playlistPermissionsService, p.UID); err != nil {
+		hs.log.Warn("Failed to set playlist permissions", "uid", p.UID, "error", err)
+	}
+
 	return response.JSON(http.StatusOK, p)
 }
 
+// setDefaultPlaylistPermissions makes the user who created the playlist its admin. The other users of the org
+// access it with the permissions of their roles on all playlists.
+func setDefaultPlaylistPermissions(c *contextmodel.ReqContext, permissionsService ac.PlaylistPermissionsService, uid string) error {
+	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return nil
+	}
+
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return err
+	}
+
+	_, err = permissionsService.SetUserPermission(c.Req.Context(), c.SignedInUser.GetOrgID(),
+		ac.User{ID: userID}, uid, "Admin")
+	return err
+}
+
 // swagger:route PUT /playlists/{uid} playlists updatePlaylist
 //
 // Update playlist.
@@ -322,6 +368,8 @@ type playlistK8sHandler struct {
 	namespacer           request.NamespaceMapper
 	gvr                  schema.GroupVersionResource
 	clientConfigProvider grafanaapiserver.DirectRestConfigProvider
+	permissionsService   ac.PlaylistPermissionsService
+	log                  log.Logger
 }
 
 //-----------------------------------------------------------------------------------------
@@ -333,6 +381,8 @@ func newPlaylistK8sHandler(hs *HTTPServer) *playlistK8sHandler {
 		gvr:                  v0alpha1.PlaylistResourceInfo.GroupVersionResource(),
 		namespacer:           request.GetNamespaceMapper(hs.Cfg),
 		clientConfigProvider: hs.clientConfigProvider,
+		permissionsService:   hs.playlistPermissionsService,
+		log:                  hs.log,
 	}
 }
 
@@ -348,10 +398,11 @@ func (pk8s *playlistK8sHandler) searchPlaylists(c *contextmodel.ReqContext) {
 	}
 
 	query := strings.ToUpper(c.Query("query"))
+	canRead := canReadPlaylist(c)
 	playlists := []playlist.Playlist{}
 	for _, item := range out.Items {
 		p := internalplaylist.UnstructuredToLegacyPlaylist(item)
-		if p == nil {
+		if p == nil || !canRead(p.UID) {
 			continue
 		}
 		if query != "" && !strings.Contains(strings.ToUpper(p.Name), query) {
@@ -401,6 +452,9 @@ func (pk8s *playlistK8sHandler) deletePlaylist(c *contextmodel.ReqContext) {
 		pk8s.writeError(c, err)
 		return
 	}
+	if err := pk8s.permissionsService.DeleteResourcePermissions(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		pk8s.log.Warn("Failed to delete playlist permissions", "uid", uid, "error", err)
+	}
 	c.JSON(http.StatusOK, "")
 }
 
@@ -441,6 +495,9 @@ func (pk8s *playlistK8sHandler) createPlaylist(c *contextmodel.ReqContext) {
 		pk8s.writeError(c, err)
 		return
 	}
+	if err := setDefaultPlaylistPermissions(c, pk8s.permissionsService, out.GetName()); err != nil {
+		pk8s.log.Warn("Failed to set playlist permissions", "uid", out.GetName(), "error", err)
+	}
 	c.JSON(http.StatusOK, internalplaylist.UnstructuredToLegacyPlaylistDTO(*out))
 }
 
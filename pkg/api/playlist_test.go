@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/playlist/playlisttest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func setupPlaylistServer(t *testing.T) *webtest.Server {
+	t.Helper()
+	return SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.playlistService = &playlisttest.FakePlaylistService{
+			ExpectedPlaylist:    &playlist.Playlist{UID: "a", Name: "A", OrgId: 1},
+			ExpectedPlaylistDTO: &playlist.PlaylistDTO{Uid: "a", Name: "A"},
+			ExpectedPlaylists: playlist.Playlists{
+				{UID: "a", Name: "A", OrgId: 1},
+				{UID: "b", Name: "B", OrgId: 1},
+			},
+		}
+		hs.playlistPermissionsService = &actest.FakePermissionsService{}
+	})
+}
+
+func TestHTTPServer_SearchPlaylists(t *testing.T) {
+	t.Run("should only return the playlists the user can read", func(t *testing.T) {
+		server := setupPlaylistServer(t)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/playlists"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: playlist.ActionRead, Scope: playlist.ScopeProvider.GetResourceScopeUID("b")},
+		})))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result playlist.Playlists
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.Len(t, result, 1)
+		assert.Equal(t, "b", result[0].UID)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not search without the read permission", func(t *testing.T) {
+		server := setupPlaylistServer(t)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/playlists"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+func TestHTTPServer_PlaylistPermissions(t *testing.T) {
+	type testCase struct {
+		desc         string
+		method       string
+		path         string
+		body         string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should get the playlist with the read permission on it",
+			method:       http.MethodGet,
+			path:         "/api/playlists/a",
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionRead, Scope: "playlists:uid:a"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not get the playlist with the read permission on another one",
+			method:       http.MethodGet,
+			path:         "/api/playlists/a",
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionRead, Scope: "playlists:uid:b"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should update the playlist with the write permission on it",
+			method:       http.MethodPut,
+			path:         "/api/playlists/a",
+			body:         `{"name": "A", "interval": "5m"}`,
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionWrite, Scope: "playlists:uid:a"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not update the playlist with the read permission only",
+			method:       http.MethodPut,
+			path:         "/api/playlists/a",
+			body:         `{"name": "A", "interval": "5m"}`,
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionRead, Scope: playlist.ScopeAll}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should delete the playlist with the delete permission on all playlists",
+			method:       http.MethodDelete,
+			path:         "/api/playlists/a",
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionDelete, Scope: playlist.ScopeAll}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should create a playlist with the create permission",
+			method:       http.MethodPost,
+			path:         "/api/playlists",
+			body:         `{"name": "C", "interval": "5m"}`,
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionCreate}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not create a playlist without the create permission",
+			method:       http.MethodPost,
+			path:         "/api/playlists",
+			body:         `{"name": "C", "interval": "5m"}`,
+			permissions:  []accesscontrol.Permission{{Action: playlist.ActionWrite, Scope: playlist.ScopeAll}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupPlaylistServer(t)
+
+			req := server.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			res, err := server.SendJSON(webtest.RequestWithSignedInUser(req, userWithPermissions(1, tt.permissions)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/common/expfmt"
 
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/web"
@@ -16,7 +17,8 @@ func (hs *HTTPServer) pluginMetricsEndpoint(ctx *web.Context) {
 		return
 	}
 
-	if ctx.Req.Method != http.MethodGet || !strings.HasPrefix(ctx.Req.URL.Path, "/metrics/plugins/") {
+	aggregated := ctx.Req.URL.Path == "/metrics/plugins"
+	if ctx.Req.Method != http.MethodGet || !(aggregated || strings.HasPrefix(ctx.Req.URL.Path, "/metrics/plugins/")) {
 		return
 	}
 
@@ -25,6 +27,11 @@ func (hs *HTTPServer) pluginMetricsEndpoint(ctx *web.Context) {
 		return
 	}
 
+	if aggregated {
+		hs.aggregatedPluginMetrics(ctx)
+		return
+	}
+
 	pathParts := strings.SplitAfter(ctx.Req.URL.Path, "/")
 	pluginID := pathParts[len(pathParts)-1]
 
@@ -44,3 +51,22 @@ func (hs *HTTPServer) pluginMetricsEndpoint(ctx *web.Context) {
 		hs.log.Error("Failed to write to response", "err", err)
 	}
 }
+
+// aggregatedPluginMetrics writes the metrics of all backend plugins, labeled by plugin ID.
+func (hs *HTTPServer) aggregatedPluginMetrics(ctx *web.Context) {
+	families, err := hs.pluginStatus.Gather(ctx.Req.Context())
+	if err != nil {
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	format := expfmt.NewFormat(expfmt.TypeTextPlain)
+	ctx.Resp.Header().Set("Content-Type", string(format))
+	enc := expfmt.NewEncoder(ctx.Resp, format)
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			hs.log.Error("Failed to write to response", "err", err)
+			return
+		}
+	}
+}
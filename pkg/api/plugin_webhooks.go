@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/httpresponsesender"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginwebhooks"
+	"github.com/grafana/grafana/pkg/util/proxyutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// PluginWebhook receives a webhook on behalf of an app plugin. Once the signature of the payload is
+// verified, the request is forwarded to the webhooks/:name resource of the plugin. The request doesn't
+// need to be authenticated, so the organization of the app is given by the orgId query parameter.
+//
+// POST /api/plugins/:pluginId/webhooks/:name
+func (hs *HTTPServer) PluginWebhook(c *contextmodel.ReqContext) {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	name := web.Params(c.Req)[":name"]
+
+	plugin, exists := hs.pluginStore.Plugin(c.Req.Context(), pluginID)
+	if !exists || !plugin.IsApp() || !plugin.Backend {
+		c.JsonApiErr(http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+	webhook, exists := plugin.Webhook(name)
+	if !exists {
+		c.JsonApiErr(http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+
+	orgID := c.QueryInt64WithDefault("orgId", 1)
+	if orgID <= 0 {
+		c.JsonApiErr(http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+
+	pCtx, err := hs.pluginContextProvider.Get(c.Req.Context(), pluginID, nil, orgID)
+	if err != nil {
+		if errors.Is(err, plugins.ErrPluginNotRegistered) {
+			c.JsonApiErr(http.StatusNotFound, "Webhook not found", nil)
+			return
+		}
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to get plugin settings", err)
+		return
+	}
+
+	// an app that isn't enabled or configured in the organization can't receive webhooks
+	if pCtx.AppInstanceSettings == nil || pCtx.AppInstanceSettings.DecryptedSecureJSONData[webhook.SecretKey] == "" {
+		c.JsonApiErr(http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+
+	// the limiter only tracks the webhooks of the apps configured in the organization
+	if !hs.pluginWebhookLimiter.Allow(orgID, pluginID, name) {
+		c.JsonApiErr(http.StatusTooManyRequests, "Too many webhook requests", nil)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Resp, c.Req.Body, hs.Cfg.PluginWebhooks.MaxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JsonApiErr(http.StatusRequestEntityTooLarge, "Webhook payload is too large", nil)
+			return
+		}
+		c.JsonApiErr(http.StatusBadRequest, "Failed to read webhook payload", err)
+		return
+	}
+
+	secret := pCtx.AppInstanceSettings.DecryptedSecureJSONData[webhook.SecretKey]
+	if err := pluginwebhooks.VerifySignature(webhook, secret, c.Req.Header, body); err != nil {
+		c.JsonApiErr(http.StatusUnauthorized, "Invalid webhook signature", err)
+		return
+	}
+
+	req := c.Req.Clone(c.Req.Context())
+	proxyutil.PrepareProxyRequest(req)
+	query := req.URL.Query()
+	query.Del("orgId")
+	resourceURL := url.URL{Path: "webhooks/" + name, RawQuery: query.Encode()}
+
+	crReq := &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          resourceURL.Path,
+		Method:        http.MethodPost,
+		URL:           resourceURL.String(),
+		Headers:       req.Header,
+		Body:          body,
+	}
+	if err := hs.pluginClient.CallResource(c.Req.Context(), crReq, httpresponsesender.New(c.Resp)); err != nil {
+		handleCallResourceError(err, c)
+		return
+	}
+
+	requestmeta.WithStatusSource(c.Req.Context(), c.Resp.Status())
+}
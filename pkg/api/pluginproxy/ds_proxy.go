@@ -272,6 +272,32 @@ func (proxy *DataSourceProxy) director(req *http.Request) {
 	}
 
 	proxyutil.ApplyForwardIDHeader(req, proxy.ctx.SignedInUser)
+
+	proxy.applyForwardHeaders(req)
+}
+
+// applyForwardHeaders sets the headers configured in the header forwarding policy of the data source.
+func (proxy *DataSourceProxy) applyForwardHeaders(req *http.Request) {
+	if proxy.ds.JsonData == nil {
+		return
+	}
+
+	ctxLogger := logger.FromContext(req.Context())
+	jsonData, err := proxy.ds.JsonData.MarshalJSON()
+	if err != nil {
+		ctxLogger.Error("Failed to marshal json data", "error", err)
+		return
+	}
+	policy, err := proxyutil.ParseForwardHeadersPolicy(jsonData)
+	if err != nil {
+		ctxLogger.Warn("Invalid header forwarding policy", "error", err)
+		return
+	}
+
+	data := proxyutil.NewForwardHeadersData(req.Context(), proxy.ctx.Req.Header, proxy.ctx.SignedInUser, proxy.ds.UID)
+	if err := policy.Apply(data, req.Header.Set); err != nil {
+		ctxLogger.Warn("Failed to forward headers", "error", err)
+	}
 }
 
 func (proxy *DataSourceProxy) validateRequest() error {
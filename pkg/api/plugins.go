@@ -1,12 +1,15 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path"
 	"path/filepath"
 	"runtime"
@@ -30,6 +33,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstatus"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -445,6 +449,49 @@ func (hs *HTTPServer) CheckHealth(c *contextmodel.ReqContext) response.Response
 	return response.JSON(http.StatusOK, payload)
 }
 
+// GetPluginStatus returns the health, process state and metrics of a plugin.
+//
+// /api/plugins/:pluginId/status
+func (hs *HTTPServer) GetPluginStatus(c *contextmodel.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	var pCtx backend.PluginContext
+	var err error
+	// the health of a data source plugin is checked for the data source of the datasourceUid parameter
+	if dsUID := c.Query("datasourceUid"); dsUID != "" {
+		ds, err := hs.DataSourceCache.GetDatasourceByUID(c.Req.Context(), dsUID, c.SignedInUser, c.SkipDSCache)
+		if err != nil {
+			if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
+				return response.Error(http.StatusForbidden, "Access denied to datasource", err)
+			}
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return response.Error(http.StatusNotFound, "Data source not found", err)
+			}
+			return response.Error(http.StatusInternalServerError, "Unable to load datasource metadata", err)
+		}
+		if ds.Type != pluginID {
+			return response.Error(http.StatusBadRequest, "The data source is not of the plugin", nil)
+		}
+		pCtx, err = hs.pluginContextProvider.GetWithDataSource(c.Req.Context(), pluginID, c.SignedInUser, ds)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get plugin settings", err)
+		}
+	} else {
+		pCtx, err = hs.pluginContextProvider.Get(c.Req.Context(), pluginID, c.SignedInUser, c.SignedInUser.GetOrgID())
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get plugin settings", err)
+		}
+	}
+
+	status, err := hs.pluginStatus.Status(c.Req.Context(), pCtx)
+	if err != nil {
+		if errors.Is(err, pluginstatus.ErrPluginNotFound) {
+			return response.Error(http.StatusNotFound, "Plugin not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get plugin status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
 func (hs *HTTPServer) GetPluginErrorsList(c *contextmodel.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, hs.pluginErrorResolver.PluginErrors(c.Req.Context()))
 }
@@ -458,30 +505,123 @@ func (hs *HTTPServer) InstallPlugin(c *contextmodel.ReqContext) response.Respons
 
 	hs.log.Info("Plugin install/update requested", "pluginId", pluginID, "user", c.Login)
 
-	for _, preinstalled := range hs.Cfg.PreinstallPlugins {
-		if preinstalled.ID == pluginID && preinstalled.Version != "" {
-			return response.Error(http.StatusConflict, "Cannot update a pinned pre-installed plugin", nil)
-		}
+	if hs.isPinnedPreinstalledPlugin(pluginID) {
+		return response.Error(http.StatusConflict, "Cannot update a pinned pre-installed plugin", nil)
 	}
 
 	compatOpts := plugins.NewCompatOpts(hs.Cfg.BuildVersion, runtime.GOOS, runtime.GOARCH)
 	err := hs.pluginInstaller.Add(c.Req.Context(), pluginID, dto.Version, compatOpts)
 	if err != nil {
-		var dupeErr plugins.DuplicateError
-		if errors.As(err, &dupeErr) {
-			return response.Error(http.StatusConflict, "Plugin already installed", err)
+		return installPluginErrorToAPIError(err)
+	}
+
+	return hs.pluginInstalled(c, pluginID)
+}
+
+// maxPluginArchiveSize limits the size of the uploaded plugin archives, which include the backend binaries
+// of all the platforms.
+const maxPluginArchiveSize = 512 << 20
+
+// UploadPlugin installs or updates a plugin from an uploaded archive, in the "file" field of a multipart form.
+func (hs *HTTPServer) UploadPlugin(c *contextmodel.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	hs.log.Info("Plugin upload requested", "pluginId", pluginID, "user", c.Login)
+
+	if hs.isPinnedPreinstalledPlugin(pluginID) {
+		return response.Error(http.StatusConflict, "Cannot update a pinned pre-installed plugin", nil)
+	}
+
+	c.Req.Body = http.MaxBytesReader(c.Resp, c.Req.Body, maxPluginArchiveSize)
+	file, _, err := c.Req.FormFile("file")
+	if err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Plugin archive is missing or larger than %s", util.ByteCountSI(maxPluginArchiveSize)), err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			hs.log.Warn("Failed to close uploaded plugin archive", "error", err)
 		}
-		var clientError repo.ErrResponse4xx
-		if errors.As(err, &clientError) {
-			return response.Error(clientError.StatusCode(), clientError.Message(), err)
+	}()
+
+	// the archive is read from a file, which is removed once the plugin is extracted
+	tmp, err := os.CreateTemp("", "grafana-plugin-*.zip")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save plugin archive", err)
+	}
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil {
+			hs.log.Warn("Failed to remove uploaded plugin archive", "path", tmp.Name(), "error", err)
 		}
-		if errors.Is(err, plugins.ErrInstallCorePlugin) {
-			return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
+	}()
+	_, err = io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save plugin archive", err)
+	}
+
+	archive, err := zip.OpenReader(tmp.Name())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Plugin archive is not a valid zip file", err)
+	}
+	// the installer closes the archive once it is extracted, closing it again is harmless
+	defer func() { _ = archive.Close() }()
+
+	compatOpts := plugins.NewCompatOpts(hs.Cfg.BuildVersion, runtime.GOOS, runtime.GOARCH)
+	if err = hs.pluginInstaller.AddFromArchive(c.Req.Context(), pluginID, archive, compatOpts); err != nil {
+		return installPluginErrorToAPIError(err)
+	}
+
+	return hs.pluginInstalled(c, pluginID)
+}
+
+// GetPluginVersions returns the previous versions of a plugin that it can be rolled back to.
+func (hs *HTTPServer) GetPluginVersions(c *contextmodel.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	versions, err := hs.pluginInstaller.Versions(c.Req.Context(), pluginID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list plugin versions", err)
+	}
+	return response.JSON(http.StatusOK, dtos.PluginVersionsDTO{Versions: versions})
+}
+
+// RollbackPlugin replaces a plugin with one of its previous versions.
+func (hs *HTTPServer) RollbackPlugin(c *contextmodel.ReqContext) response.Response {
+	dto := dtos.RollbackPluginCommand{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	hs.log.Info("Plugin rollback requested", "pluginId", pluginID, "version", dto.Version, "user", c.Login)
+
+	if hs.isPinnedPreinstalledPlugin(pluginID) {
+		return response.Error(http.StatusConflict, "Cannot update a pinned pre-installed plugin", nil)
+	}
+
+	if err := hs.pluginInstaller.Rollback(c.Req.Context(), pluginID, dto.Version); err != nil {
+		if errors.Is(err, plugins.ErrPluginVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Plugin version not found", err)
 		}
+		return installPluginErrorToAPIError(err)
+	}
 
-		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to install plugin", err)
+	return hs.pluginInstalled(c, pluginID)
+}
+
+func (hs *HTTPServer) isPinnedPreinstalledPlugin(pluginID string) bool {
+	for _, preinstalled := range hs.Cfg.PreinstallPlugins {
+		if preinstalled.ID == pluginID && preinstalled.Version != "" {
+			return true
+		}
 	}
+	return false
+}
 
+// pluginInstalled returns the version of an installed plugin, and whether Grafana must be restarted to use it.
+func (hs *HTTPServer) pluginInstalled(c *contextmodel.ReqContext, pluginID string) response.Response {
 	if hs.Features.IsEnabled(c.Req.Context(), featuremgmt.FlagExternalServiceAccounts) {
 		// This is a non-blocking function that verifies that the installer has
 		// the permissions that the plugin requests to have on Grafana.
@@ -489,7 +629,39 @@ func (hs *HTTPServer) InstallPlugin(c *contextmodel.ReqContext) response.Respons
 		hs.hasPluginRequestedPermissions(c, pluginID)
 	}
 
-	return response.JSON(http.StatusOK, []byte{})
+	result := dtos.InstallPluginResult{PluginID: pluginID}
+	if plugin, exists := hs.pluginStore.Plugin(c.Req.Context(), pluginID); exists {
+		result.Version = plugin.Info.Version
+		result.RequiresRestart = plugin.RequiresRestart()
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func installPluginErrorToAPIError(err error) response.Response {
+	var dupeErr plugins.DuplicateError
+	if errors.As(err, &dupeErr) {
+		return response.Error(http.StatusConflict, "Plugin already installed", err)
+	}
+	var clientError repo.ErrResponse4xx
+	if errors.As(err, &clientError) {
+		return response.Error(clientError.StatusCode(), clientError.Message(), err)
+	}
+	if errors.Is(err, plugins.ErrInstallCorePlugin) {
+		return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
+	}
+	if errors.Is(err, plugins.ErrPluginInstallInProgress) {
+		return response.Error(http.StatusConflict, "Plugin is being installed", err)
+	}
+	if errors.Is(err, plugins.ErrPluginArchiveMismatch) {
+		return response.Error(http.StatusBadRequest, "Plugin archive does not contain the plugin", err)
+	}
+	// the plugin could not be loaded, for example because its signature is invalid, and was rolled back
+	var loadErr *plugins.Error
+	if errors.As(err, &loadErr) {
+		return response.Error(http.StatusBadRequest, loadErr.PublicMessage(), err)
+	}
+
+	return response.ErrOrFallback(http.StatusInternalServerError, "Failed to install plugin", err)
 }
 
 func (hs *HTTPServer) UninstallPlugin(c *contextmodel.ReqContext) response.Response {
@@ -132,6 +132,16 @@ func Test_PluginsInstallAndUninstall(t *testing.T) {
 			require.NoError(t, res.Body.Close())
 		})
 
+		t.Run(testName("Rollback", tc), func(t *testing.T) {
+			input := strings.NewReader(`{"version": "1.0.1"}`)
+			endpoint := fmt.Sprintf("/api/plugins/%s/rollback", pluginID)
+			req := webtest.RequestWithSignedInUser(server.NewPostRequest(endpoint, input), userWithPermissions(tc.permissionOrg, tc.permissions))
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+
 		t.Run(testName("Uninstall", tc), func(t *testing.T) {
 			input := strings.NewReader("{ }")
 			endpoint := fmt.Sprintf("/api/plugins/%s/uninstall", pluginID)
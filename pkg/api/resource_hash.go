@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/util/resourcehash"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// dataSourceVolatileFields are the fields of a data source set by Grafana on each write or request, which are left
+// out of its hash.
+var dataSourceVolatileFields = []string{"id", "version", "accessControl"}
+
+// dashboardVolatileFields are the fields of the JSON model of a dashboard set by Grafana on each save, which are left
+// out of its hash.
+var dashboardVolatileFields = []string{"id", "version"}
+
+func dataSourceState(ds dtos.DataSource) (resourcehash.State, error) {
+	return resourcehash.New(ds, dataSourceVolatileFields...)
+}
+
+// dashboardState returns the state of a dashboard, which is its JSON model and its folder. A dashboard moved to
+// another folder changes.
+func dashboardState(dash *dashboards.Dashboard) (resourcehash.State, error) {
+	state := resourcehash.State{}
+	if dash.Data != nil {
+		var err error
+		if state, err = resourcehash.New(dash.Data, dashboardVolatileFields...); err != nil {
+			return nil, err
+		}
+	}
+	folderUID, err := json.Marshal(dash.FolderUID)
+	if err != nil {
+		return nil, err
+	}
+	state["folderUid"] = folderUID
+	return state, nil
+}
+
+func resourceHashResponse(state resourcehash.State) response.Response {
+	hash, err := state.Hash()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the resource", err)
+	}
+	return response.JSON(http.StatusOK, dtos.ResourceHash{Hash: hash}).SetHeader(resourcehash.Header, hash)
+}
+
+// swagger:route GET /datasources/uid/{uid}/hash datasources getDataSourceHashByUID
+//
+// Get the hash of a data source.
+//
+// The hash only changes when the data source changes, so clients can detect drift by comparing it with the hash
+// returned when they last updated the data source.
+//
+// Responses:
+// 200: getResourceHashResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDataSourceHashByUID(c *contextmodel.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.SignedInUser.GetOrgID())
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	state, err := dataSourceState(hs.convertModelToDtos(c.Req.Context(), ds))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the data source", err)
+	}
+	return resourceHashResponse(state)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/hash dashboards getDashboardHashByUID
+//
+// Get the hash of a dashboard.
+//
+// The hash only changes when the JSON model or the folder of the dashboard changes, so clients can detect drift by
+// comparing it with the hash returned when they last saved the dashboard.
+//
+// Responses:
+// 200: getResourceHashResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardHashByUID(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	dash, rsp := hs.getDashboardHelper(ctx, c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	g, err := guardian.NewByDashboard(ctx, dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	state, err := dashboardState(dash)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to hash the dashboard", err)
+	}
+	return resourceHashResponse(state)
+}
+
+// swagger:response getResourceHashResponse
+type GetResourceHashResponse struct {
+	// in: body
+	Body dtos.ResourceHash `json:"body"`
+}
+
+// swagger:parameters getDataSourceHashByUID getDashboardHashByUID
+type GetResourceHashParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
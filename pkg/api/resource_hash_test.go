@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func TestDashboardState(t *testing.T) {
+	dash := &dashboards.Dashboard{
+		FolderUID: "folder",
+		Data:      simplejson.NewFromAny(map[string]any{"id": 1, "uid": "dash", "title": "Dash", "version": 1}),
+	}
+	before, err := dashboardState(dash)
+	require.NoError(t, err)
+
+	t.Run("a save which only bumps the version changes nothing", func(t *testing.T) {
+		saved := &dashboards.Dashboard{
+			FolderUID: "folder",
+			Data:      simplejson.NewFromAny(map[string]any{"id": 1, "uid": "dash", "title": "Dash", "version": 2}),
+		}
+		after, err := dashboardState(saved)
+		require.NoError(t, err)
+		require.Empty(t, after.FieldsChanged(before))
+	})
+
+	t.Run("a dashboard moved to another folder changes", func(t *testing.T) {
+		moved := &dashboards.Dashboard{FolderUID: "other", Data: dash.Data}
+		after, err := dashboardState(moved)
+		require.NoError(t, err)
+		require.Equal(t, []string{"folderUid"}, after.FieldsChanged(before))
+	})
+
+	t.Run("a dashboard without data only has its folder", func(t *testing.T) {
+		state, err := dashboardState(&dashboards.Dashboard{FolderUID: "folder"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"folderUid"}, state.FieldsChanged(nil))
+	})
+}
+
+func TestDataSourceState(t *testing.T) {
+	ds := dtos.DataSource{Id: 1, UID: "ds", Name: "Prometheus", Version: 1}
+	before, err := dataSourceState(ds)
+	require.NoError(t, err)
+
+	ds.Version = 2
+	ds.Url = "http://prometheus:9090"
+	after, err := dataSourceState(ds)
+	require.NoError(t, err)
+	require.Equal(t, []string{"url"}, after.FieldsChanged(before))
+
+	result, err := after.Compare(before, "secureJsonFields")
+	require.NoError(t, err)
+	require.Equal(t, []string{"secureJsonFields", "url"}, result.FieldsChanged)
+}
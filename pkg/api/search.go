@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -33,6 +34,7 @@ func (hs *HTTPServer) Search(c *contextmodel.ReqContext) response.Response {
 	page := c.QueryInt64("page")
 	dashboardType := c.Query("type")
 	sort := c.Query("sort")
+	continueToken := c.Query("continueToken")
 	deleted := c.Query("deleted")
 	permission := dashboardaccess.PERMISSION_VIEW
 
@@ -96,16 +98,26 @@ func (hs *HTTPServer) Search(c *contextmodel.ReqContext) response.Response {
 		FolderUIDs:    folderUIDs,
 		Permission:    permission,
 		Sort:          sort,
+		ContinueToken: continueToken,
 	}
 
 	hits, err := hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
 	if err != nil {
+		if errors.Is(err, search.ErrInvalidContinueToken) {
+			return response.Error(http.StatusBadRequest, "Invalid continue token", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Search failed", err)
 	}
 
+	hs.dashboardLint.AnnotateHits(c.Req.Context(), c.SignedInUser.GetOrgID(), hits)
+
 	defer c.TimeRequest(metrics.MApiDashboardSearch)
 
-	return response.JSON(http.StatusOK, hits)
+	resp := response.JSON(http.StatusOK, hits)
+	if token := search.NextContinueToken(&searchQuery, hits); token != "" {
+		resp.SetHeader("X-Grafana-Search-Continue-Token", token)
+	}
+	return resp
 }
 
 // swagger:route GET /search/sorting search listSortOptions
@@ -199,8 +211,13 @@ type SearchParams struct {
 	// in:query
 	// required: false
 	// default: alpha-asc
-	// Enum: alpha-asc,alpha-desc
+	// Enum: alpha-asc,alpha-desc,relevance
 	Sort string `json:"sort"`
+	// Token of the next page of results sorted by relevance, returned in the X-Grafana-Search-Continue-Token header
+	// of the previous page. The page parameter is ignored when it is set.
+	// in:query
+	// required: false
+	ContinueToken string `json:"continueToken"`
 	// Flag indicating if only soft deleted Dashboards should be returned
 	// in:query
 	// required: false
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ua-parser/uap-go/uaparser"
@@ -15,11 +16,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// logoutBatchSize is the number of users whose auth tokens are revoked by one query.
+const logoutBatchSize = 500
+
 // swagger:route GET /user/auth-tokens signed_in_user getUserAuthTokens
 //
 // Auth tokens of the actual User.
@@ -74,6 +79,101 @@ func (hs *HTTPServer) RevokeUserAuthToken(c *contextmodel.ReqContext) response.R
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route DELETE /user/auth-tokens/{auth_token_id} signed_in_user revokeUserAuthTokenByID
+//
+// Revoke an auth token of the actual User by ID.
+//
+// Revokes the given auth token (device) for the actual user. User of issued auth token (device) will no longer be logged in and will be required to authenticate again upon next activity.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeUserAuthTokenByID(c *contextmodel.ReqContext) response.Response {
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return response.Error(http.StatusForbidden, "entity not allowed to revoke tokens", nil)
+	}
+
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	return hs.revokeUserAuthTokenInternal(c, userID, auth.RevokeAuthTokenCmd{AuthTokenId: tokenID})
+}
+
+// swagger:route POST /orgs/{org_id}/logout orgs adminLogoutOrgUsers
+//
+// Logout the users of an organization.
+//
+// Revokes all auth tokens (devices) of the users of the organization, except the ones of the signed in user. Users of the issued auth tokens (devices) will no longer be logged in and will be required to authenticate again upon next activity.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.logout` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminLogoutOrgUsers(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	if _, err := hs.orgService.GetByID(c.Req.Context(), &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		if errors.Is(err, org.ErrOrgNotFound) {
+			return response.Error(http.StatusNotFound, "Organization not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get organization", err)
+	}
+
+	result, err := hs.orgService.SearchOrgUsers(c.Req.Context(), &org.SearchOrgUsersQuery{
+		OrgID:                    orgID,
+		User:                     c.SignedInUser,
+		DontEnforceAccessControl: true,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get organization users", err)
+	}
+
+	// the signed in user stays logged in, like when logging out a single user
+	var currentUserID int64
+	if c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		currentUserID, _ = c.SignedInUser.GetInternalID()
+	}
+	userIDs := make([]int64, 0, len(result.OrgUsers))
+	for _, orgUser := range result.OrgUsers {
+		if orgUser.UserID != currentUserID {
+			userIDs = append(userIDs, orgUser.UserID)
+		}
+	}
+
+	for start := 0; start < len(userIDs); start += logoutBatchSize {
+		end := min(start+logoutBatchSize, len(userIDs))
+		if err := hs.AuthTokenService.BatchRevokeAllUserTokens(c.Req.Context(), userIDs[start:end]); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to logout organization users", err)
+		}
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Organization users logged out",
+		"count":   len(userIDs),
+	})
+}
+
 func (hs *HTTPServer) RotateUserAuthTokenRedirect(c *contextmodel.ReqContext) response.Response {
 	if err := hs.rotateToken(c); err != nil {
 		hs.log.FromContext(c.Req.Context()).Debug("Failed to rotate token", "error", err)
@@ -276,6 +376,20 @@ type RevokeUserAuthTokenParams struct {
 	Body auth.RevokeAuthTokenCmd `json:"body"`
 }
 
+// swagger:parameters revokeUserAuthTokenByID
+type RevokeUserAuthTokenByIDParams struct {
+	// in:path
+	// required:true
+	AuthTokenID int64 `json:"auth_token_id"`
+}
+
+// swagger:parameters adminLogoutOrgUsers
+type AdminLogoutOrgUsersParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
 // swagger:response getUserAuthTokensResponse
 type GetUserAuthTokensResponse struct {
 	// in:body
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -92,6 +93,57 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 		}, mockUser)
 	})
 
+	t.Run("When revoke an auth token by ID", func(t *testing.T) {
+		mockUser := &usertest.FakeUserService{
+			ExpectedUser: &user.User{ID: 200},
+		}
+		revokeUserAuthTokenByIDScenario(t, "Should be successful", &auth.UserToken{Id: 1}, func(sc *scenarioContext) {
+			var revoked int64
+			sc.userAuthTokenService.GetUserTokenProvider = func(ctx context.Context, userId, userTokenId int64) (*auth.UserToken, error) {
+				return &auth.UserToken{Id: userTokenId}, nil
+			}
+			sc.userAuthTokenService.RevokeTokenProvider = func(ctx context.Context, token *auth.UserToken, soft bool) error {
+				revoked = token.Id
+				return nil
+			}
+			sc.fakeReqWithParams("DELETE", "/api/user/auth-tokens/2", map[string]string{}).exec()
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, int64(2), revoked)
+
+			sc.fakeReqWithParams("DELETE", "/api/user/auth-tokens/1", map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code, "the active auth token can't be revoked")
+
+			sc.fakeReqWithParams("DELETE", "/api/user/auth-tokens/abc", map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		}, mockUser)
+	})
+
+	t.Run("When logging out the users of an organization", func(t *testing.T) {
+		orgService := orgtest.NewOrgServiceFake()
+		orgService.ExpectedOrg = &org.Org{ID: 2}
+		orgService.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{
+			{UserID: testUserID}, {UserID: 3}, {UserID: 4},
+		}}
+		adminLogoutOrgUsersScenario(t, "Should revoke the tokens of the other users", orgService, func(sc *scenarioContext) {
+			var revoked []int64
+			sc.userAuthTokenService.BatchRevokedTokenProvider = func(ctx context.Context, userIDs []int64) error {
+				revoked = append(revoked, userIDs...)
+				return nil
+			}
+			sc.fakeReqWithParams("POST", "/api/orgs/2/logout", map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, []int64{3, 4}, revoked)
+			assert.Equal(t, 2, sc.ToJSON().Get("count").MustInt())
+		})
+
+		orgService = orgtest.NewOrgServiceFake()
+		orgService.ExpectedError = org.ErrOrgNotFound
+		adminLogoutOrgUsersScenario(t, "Should return not found for unknown organizations", orgService, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", "/api/orgs/2/logout", map[string]string{}).exec()
+			assert.Equal(t, 404, sc.resp.Code)
+		})
+	})
+
 	t.Run("When gets auth tokens for a user", func(t *testing.T) {
 		currentToken := &auth.UserToken{Id: 1}
 		mockUser := usertest.NewUserServiceFake()
@@ -343,6 +395,55 @@ func revokeUserAuthTokenInternalScenario(t *testing.T, desc string, cmd auth.Rev
 	})
 }
 
+func revokeUserAuthTokenByIDScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc, userService user.Service) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+			userService:      userService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			sc.context.OrgID = testOrgID
+			sc.context.OrgRole = org.RoleViewer
+			sc.context.UserToken = token
+
+			return hs.RevokeUserAuthTokenByID(c)
+		})
+		sc.m.Delete("/api/user/auth-tokens/:id", sc.defaultHandler)
+		fn(sc)
+	})
+}
+
+func adminLogoutOrgUsersScenario(t *testing.T, desc string, orgService org.Service, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+			orgService:       orgService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			sc.context.OrgID = testOrgID
+			sc.context.IsGrafanaAdmin = true
+
+			return hs.AdminLogoutOrgUsers(c)
+		})
+		sc.m.Post("/api/orgs/:orgId/logout", sc.defaultHandler)
+		fn(sc)
+	})
+}
+
 func getUserAuthTokensInternalScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc, userService user.Service) {
 	t.Run(desc, func(t *testing.T) {
 		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
@@ -64,3 +64,48 @@ type Item struct {
 	//  - dashboard_by_uid: The value is the dashboard UID
 	Value string `json:"value"`
 }
+
+// RotationState is the state of the server-side rotation of a playlist, which kiosk displays follow.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RotationState struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Running is false until the rotation is started, and after it is stopped.
+	Running bool `json:"running"`
+
+	// Index of the current item in the playlist items.
+	Index int `json:"index"`
+
+	// The current item, empty when the playlist has no items.
+	// +optional
+	Item *Item `json:"item,omitempty"`
+
+	// SwitchedAt is when the rotation switched to the current item, in milliseconds since the epoch.
+	SwitchedAt int64 `json:"switchedAt"`
+
+	// NextSwitchAt is when the rotation switches to the next item, in milliseconds since the epoch.
+	// It is 0 when the rotation is not running.
+	NextSwitchAt int64 `json:"nextSwitchAt"`
+}
+
+// Action of a rotation command.
+// +enum
+type RotationAction string
+
+// Defines values for RotationAction.
+const (
+	RotationActionStart    RotationAction = "start"
+	RotationActionStop     RotationAction = "stop"
+	RotationActionNext     RotationAction = "next"
+	RotationActionPrevious RotationAction = "previous"
+)
+
+// RotationCommand drives the server-side rotation of a playlist.
+type RotationCommand struct {
+	// Action to apply to the rotation.
+	Action RotationAction `json:"action"`
+
+	// Index of the item to start the rotation from, the current item by default.
+	// +optional
+	Index *int `json:"index,omitempty"`
+}
@@ -87,6 +87,57 @@ func (in *PlaylistList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationCommand) DeepCopyInto(out *RotationCommand) {
+	*out = *in
+	if in.Index != nil {
+		in, out := &in.Index, &out.Index
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationCommand.
+func (in *RotationCommand) DeepCopy() *RotationCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RotationState) DeepCopyInto(out *RotationState) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Item != nil {
+		in, out := &in.Item, &out.Item
+		*out = new(Item)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RotationState.
+func (in *RotationState) DeepCopy() *RotationState {
+	if in == nil {
+		return nil
+	}
+	out := new(RotationState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RotationState) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Spec) DeepCopyInto(out *Spec) {
 	*out = *in
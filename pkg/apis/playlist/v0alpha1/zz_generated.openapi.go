@@ -14,10 +14,12 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item":         schema_pkg_apis_playlist_v0alpha1_Item(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Playlist":     schema_pkg_apis_playlist_v0alpha1_Playlist(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.PlaylistList": schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Spec":         schema_pkg_apis_playlist_v0alpha1_Spec(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item":            schema_pkg_apis_playlist_v0alpha1_Item(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Playlist":        schema_pkg_apis_playlist_v0alpha1_Playlist(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.PlaylistList":    schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.RotationCommand": schema_pkg_apis_playlist_v0alpha1_RotationCommand(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.RotationState":   schema_pkg_apis_playlist_v0alpha1_RotationState(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Spec":            schema_pkg_apis_playlist_v0alpha1_Spec(ref),
 	}
 }
 
@@ -140,6 +142,104 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref common.ReferenceCallback
 	}
 }
 
+func schema_pkg_apis_playlist_v0alpha1_RotationCommand(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RotationCommand drives the server-side rotation of a playlist.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"action": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Action to apply to the rotation.\n\nPossible enum values:\n - `\"next\"`\n - `\"previous\"`\n - `\"start\"`\n - `\"stop\"`",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+							Enum:        []interface{}{"next", "previous", "start", "stop"},
+						},
+					},
+					"index": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Index of the item to start the rotation from, the current item by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"action"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_playlist_v0alpha1_RotationState(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RotationState is the state of the server-side rotation of a playlist, which kiosk displays follow.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"running": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Running is false until the rotation is started, and after it is stopped.",
+							Default:     false,
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"index": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Index of the current item in the playlist items.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"item": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The current item, empty when the playlist has no items.",
+							Ref:         ref("github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item"),
+						},
+					},
+					"switchedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SwitchedAt is when the rotation switched to the current item, in milliseconds since the epoch.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"nextSwitchAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NextSwitchAt is when the rotation switches to the next item, in milliseconds since the epoch. It is 0 when the rotation is not running.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"running", "index", "switchedAt", "nextSwitchAt"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item"},
+	}
+}
+
 func schema_pkg_apis_playlist_v0alpha1_Spec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -0,0 +1,253 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+const (
+	// The headers set by the API Priority and Fairness of Kubernetes. Since the levels are not API objects,
+	// the API group and the flow schema name are used instead of their UIDs.
+	flowSchemaHeader    = "X-Kubernetes-PF-FlowSchema-UID"
+	priorityLevelHeader = "X-Kubernetes-PF-PriorityLevel-UID"
+
+	// flowSchemaName is the flow schema of all the requests: their flows are distinguished by user.
+	flowSchemaName = "grafana-by-user"
+
+	retryAfterSeconds = 1
+)
+
+// FlowControlConfig configures the flow control of the requests to the API groups. It follows the API
+// Priority and Fairness of Kubernetes: each API group is a priority level with a share of the seats,
+// and the requests of each user wait for a seat in their own queue, which are served in turn.
+type FlowControlConfig struct {
+	// Seats is the number of requests served concurrently, shared by the API groups.
+	Seats int
+	// GroupShares are the concurrency shares of the API groups. The others have DefaultGroupShares.
+	GroupShares        map[string]int
+	DefaultGroupShares int
+	// UserShare is the fraction of the seats of an API group a single user can use.
+	UserShare float64
+	// QueueLength is the number of requests to an API group waiting for a seat.
+	QueueLength int
+	// QueueTimeout is how long a request waits for a seat before it is rejected.
+	QueueTimeout time.Duration
+}
+
+type FlowController struct {
+	levels       map[string]*priorityLevel
+	queueTimeout time.Duration
+}
+
+// NewFlowController splits the seats between the given API groups according to their shares.
+func NewFlowController(cfg FlowControlConfig, groups []string) *FlowController {
+	shares := map[string]int{}
+	total := 0
+	for _, group := range groups {
+		if _, ok := shares[group]; ok {
+			continue
+		}
+		share, ok := cfg.GroupShares[group]
+		if !ok {
+			share = cfg.DefaultGroupShares
+		}
+		shares[group] = max(share, 1)
+		total += shares[group]
+	}
+
+	fc := &FlowController{
+		levels:       make(map[string]*priorityLevel, len(shares)),
+		queueTimeout: cfg.QueueTimeout,
+	}
+	for group, share := range shares {
+		seats := max(int(math.Ceil(float64(cfg.Seats)*float64(share)/float64(total))), 1)
+		fc.levels[group] = &priorityLevel{
+			name:        group,
+			seats:       seats,
+			userSeats:   max(int(float64(seats)*cfg.UserShare), 1),
+			queueLength: cfg.QueueLength,
+			userInUse:   map[string]int{},
+			queues:      map[string][]chan struct{}{},
+		}
+	}
+	return fc
+}
+
+// WithFlowControl limits the concurrent requests to the resources of the API groups. Requests that
+// can't get a seat before the queue timeout are rejected with a 429 status. Watches are long running,
+// so they are not limited, like the requests of the privileged users of the apiserver.
+func WithFlowControl(handler http.Handler, fc *FlowController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		info, ok := request.RequestInfoFrom(ctx)
+		if !ok || !info.IsResourceRequest || info.Verb == "watch" {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		level, ok := fc.levels[info.APIGroup]
+		if !ok {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		userID := ""
+		if u, ok := request.UserFrom(ctx); ok {
+			if slices.Contains(u.GetGroups(), user.SystemPrivilegedGroup) {
+				handler.ServeHTTP(w, req)
+				return
+			}
+			// the names are not unique, e.g. a user and a service account can have the same login
+			userID = u.GetUID()
+		}
+
+		w.Header().Set(flowSchemaHeader, flowSchemaName)
+		w.Header().Set(priorityLevelHeader, level.name)
+
+		if !level.acquire(ctx, userID, fc.queueTimeout) {
+			tooManyRequests(w)
+			return
+		}
+		defer level.release(userID)
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func tooManyRequests(w http.ResponseWriter) {
+	status := apierrors.NewTooManyRequests("too many requests, please try again later", retryAfterSeconds).Status()
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// priorityLevel hands out the seats of an API group. The waiting requests are queued by user, and the
+// users take turns when a seat is released, so a single user can't starve the others.
+type priorityLevel struct {
+	name        string
+	seats       int
+	userSeats   int
+	queueLength int
+
+	mu        sync.Mutex
+	inUse     int
+	userInUse map[string]int
+	queues    map[string][]chan struct{}
+	// users are the users with queued requests, in the order they are served
+	users  []string
+	queued int
+}
+
+// acquire waits for a seat, and returns false when none is available before the timeout.
+func (l *priorityLevel) acquire(ctx context.Context, userID string, timeout time.Duration) bool {
+	l.mu.Lock()
+	if len(l.queues[userID]) == 0 && l.available(userID) {
+		l.take(userID)
+		l.mu.Unlock()
+		return true
+	}
+	if l.queued >= l.queueLength {
+		l.mu.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	if len(l.queues[userID]) == 0 {
+		l.users = append(l.users, userID)
+	}
+	l.queues[userID] = append(l.queues[userID], ready)
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.dequeue(userID, ready) {
+		// the seat was given to the request while it timed out
+		return true
+	}
+	return false
+}
+
+func (l *priorityLevel) release(userID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inUse--
+	l.userInUse[userID]--
+	if l.userInUse[userID] <= 0 {
+		delete(l.userInUse, userID)
+	}
+	l.dispatch()
+}
+
+func (l *priorityLevel) available(userID string) bool {
+	return l.inUse < l.seats && l.userInUse[userID] < l.userSeats
+}
+
+func (l *priorityLevel) take(userID string) {
+	l.inUse++
+	l.userInUse[userID]++
+}
+
+// dispatch gives the free seats to the queued requests, taking the users in turn.
+func (l *priorityLevel) dispatch() {
+	for i := 0; i < len(l.users) && l.inUse < l.seats; {
+		userID := l.users[i]
+		if !l.available(userID) {
+			i++
+			continue
+		}
+
+		queue := l.queues[userID]
+		ready := queue[0]
+		l.queued--
+		l.take(userID)
+		close(ready)
+
+		// the user goes to the end of the line
+		l.users = append(l.users[:i], l.users[i+1:]...)
+		if len(queue) > 1 {
+			l.queues[userID] = queue[1:]
+			l.users = append(l.users, userID)
+		} else {
+			delete(l.queues, userID)
+		}
+	}
+}
+
+// dequeue removes a request from its queue, and returns false when it is not queued anymore.
+func (l *priorityLevel) dequeue(userID string, ready chan struct{}) bool {
+	queue := l.queues[userID]
+	i := slices.Index(queue, ready)
+	if i < 0 {
+		return false
+	}
+	l.queued--
+	if len(queue) == 1 {
+		delete(l.queues, userID)
+		l.users = slices.DeleteFunc(l.users, func(u string) bool { return u == userID })
+		return true
+	}
+	l.queues[userID] = slices.Delete(queue, i, i+1)
+	return true
+}
@@ -0,0 +1,132 @@
+package filters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestFlowController(t *testing.T) {
+	t.Run("should split the seats between the groups by shares", func(t *testing.T) {
+		fc := NewFlowController(FlowControlConfig{
+			Seats:              10,
+			GroupShares:        map[string]int{"dashboard.grafana.app": 3},
+			DefaultGroupShares: 1,
+			UserShare:          0.5,
+		}, []string{"dashboard.grafana.app", "folder.grafana.app", "folder.grafana.app"})
+
+		require.Len(t, fc.levels, 2)
+		require.Equal(t, 8, fc.levels["dashboard.grafana.app"].seats)
+		require.Equal(t, 4, fc.levels["dashboard.grafana.app"].userSeats)
+		require.Equal(t, 3, fc.levels["folder.grafana.app"].seats)
+		require.Equal(t, 1, fc.levels["folder.grafana.app"].userSeats)
+	})
+
+	t.Run("should limit the seats of a user", func(t *testing.T) {
+		level := newTestLevel(2, 1, 10)
+		require.True(t, level.acquire(context.Background(), "a", time.Second))
+		require.False(t, level.acquire(context.Background(), "a", 10*time.Millisecond))
+		require.True(t, level.acquire(context.Background(), "b", time.Second))
+
+		level.release("a")
+		require.True(t, level.acquire(context.Background(), "a", time.Second))
+	})
+
+	t.Run("should serve the queued users in turn", func(t *testing.T) {
+		level := newTestLevel(1, 1, 10)
+		require.True(t, level.acquire(context.Background(), "a", time.Second))
+
+		served := make(chan string, 3)
+		queue := func(userID string) {
+			go func() {
+				if level.acquire(context.Background(), userID, time.Second) {
+					served <- userID
+				}
+			}()
+			require.Eventually(t, func() bool {
+				level.mu.Lock()
+				defer level.mu.Unlock()
+				return len(level.queues[userID]) > 0
+			}, time.Second, time.Millisecond)
+		}
+		queue("a")
+		queue("b")
+
+		level.release("a")
+		require.Equal(t, "a", <-served)
+		level.release("a")
+		require.Equal(t, "b", <-served)
+	})
+
+	t.Run("should reject requests when the queue is full", func(t *testing.T) {
+		level := newTestLevel(1, 1, 0)
+		require.True(t, level.acquire(context.Background(), "a", time.Second))
+		require.False(t, level.acquire(context.Background(), "b", time.Second))
+	})
+}
+
+func TestWithFlowControl(t *testing.T) {
+	fc := NewFlowController(FlowControlConfig{Seats: 1, DefaultGroupShares: 1, UserShare: 1, QueueLength: 0}, []string{"dashboard.grafana.app"})
+	block := make(chan struct{})
+	handler := WithFlowControl(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		w.WriteHeader(http.StatusOK)
+	}), fc)
+
+	newRequest := func(info *request.RequestInfo, u user.Info) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards", nil)
+		ctx := request.WithRequestInfo(req.Context(), info)
+		ctx = request.WithUser(ctx, u)
+		return req.WithContext(ctx)
+	}
+	list := &request.RequestInfo{IsResourceRequest: true, APIGroup: "dashboard.grafana.app", Verb: "list"}
+	watch := &request.RequestInfo{IsResourceRequest: true, APIGroup: "dashboard.grafana.app", Verb: "watch"}
+	admin := &user.DefaultInfo{Name: "admin", UID: "user:1"}
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest(list, admin))
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		level := fc.levels["dashboard.grafana.app"]
+		level.mu.Lock()
+		defer level.mu.Unlock()
+		return level.userInUse["user:1"] == 1
+	}, time.Second, time.Millisecond, "the flows are keyed by the UID of the users")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, newRequest(list, &user.DefaultInfo{Name: "admin", UID: "service-account:2"}))
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "1", rr.Header().Get("Retry-After"))
+	require.Equal(t, "dashboard.grafana.app", rr.Header().Get(priorityLevelHeader))
+	require.Contains(t, rr.Body.String(), `"reason":"TooManyRequests"`)
+
+	close(block)
+	<-done
+
+	// watches and privileged users are not limited
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, newRequest(watch, admin))
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, newRequest(list, &user.DefaultInfo{Name: "loopback", Groups: []string{user.SystemPrivilegedGroup}}))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func newTestLevel(seats, userSeats, queueLength int) *priorityLevel {
+	return &priorityLevel{
+		name:        "test",
+		seats:       seats,
+		userSeats:   userSeats,
+		queueLength: queueLength,
+		userInUse:   map[string]int{},
+		queues:      map[string][]chan struct{}{},
+	}
+}
@@ -25,6 +25,13 @@ func WithRequester(handler http.Handler) http.Handler {
 		// Find the kubernetes user info
 		info, ok := request.UserFrom(ctx)
 		if ok {
+			// Set by the authenticators that map tokens to Grafana identities, e.g. service account tokens.
+			// Their name is chosen by Grafana users, so it must not be matched against the k8s system users below.
+			if r, ok := info.(identity.Requester); ok {
+				handler.ServeHTTP(w, req.WithContext(identity.WithRequester(ctx, r)))
+				return
+			}
+
 			if info.GetName() == user.Anonymous {
 				requester = &identity.StaticRequester{
 					Type:        claims.TypeAnonymous,
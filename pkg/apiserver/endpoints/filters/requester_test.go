@@ -0,0 +1,53 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+func TestWithRequester(t *testing.T) {
+	serve := func(t *testing.T, info user.Info) identity.Requester {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(request.WithUser(req.Context(), info))
+
+		handler := &fakeHandler{}
+		WithRequester(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		requester, err := identity.GetRequester(handler.ctx)
+		require.NoError(t, err)
+		return requester
+	}
+
+	t.Run("should map the apiserver user to a super admin", func(t *testing.T) {
+		requester := serve(t, &user.DefaultInfo{Name: user.APIServerUser, Groups: []string{user.SystemPrivilegedGroup}})
+		require.True(t, requester.GetIsGrafanaAdmin())
+	})
+
+	t.Run("should map the anonymous user", func(t *testing.T) {
+		requester := serve(t, &user.DefaultInfo{Name: user.Anonymous})
+		require.True(t, requester.IsIdentityType(claims.TypeAnonymous))
+	})
+
+	t.Run("should keep a Grafana identity named like the apiserver user", func(t *testing.T) {
+		sa := &identity.StaticRequester{
+			Type:    claims.TypeServiceAccount,
+			UserID:  2,
+			OrgID:   1,
+			Name:    user.APIServerUser,
+			Login:   user.APIServerUser,
+			OrgRole: identity.RoleViewer,
+		}
+		requester := serve(t, sa)
+		require.Same(t, sa, requester)
+		require.False(t, requester.GetIsGrafanaAdmin())
+	})
+}
@@ -233,6 +233,16 @@ func SetDualWritingMode(
 		}
 	}
 
+	if (desiredMode == Mode3) && (currentMode == Mode1) {
+		// Mode 3 is reached through mode 2, whose data syncer is the gate to mode 3
+		currentMode = Mode2
+
+		err := kvs.Set(ctx, entity, fmt.Sprint(currentMode))
+		if err != nil {
+			return Mode0, errDualWriterSetCurrentMode
+		}
+	}
+
 	if (desiredMode == Mode3) && (currentMode == Mode2) {
 		// This is where we go through the different gates to allow the instance to migrate from mode 2 to mode 3.
 
@@ -36,6 +36,18 @@ func TestSetDualWritingMode(t *testing.T) {
 				desiredMode:  Mode1,
 				expectedMode: Mode1,
 			},
+			{
+				name:         "should return a mode 3 dual writer through mode 2 when mode 3 is set as the desired mode and the data is in sync",
+				stackID:      "stack-1",
+				desiredMode:  Mode3,
+				expectedMode: Mode3,
+			},
+			{
+				name:         "should keep a mode 1 dual writer when mode 4 is set as the desired mode",
+				stackID:      "stack-1",
+				desiredMode:  Mode4,
+				expectedMode: Mode1,
+			},
 		}
 
 	for _, tt := range tests {
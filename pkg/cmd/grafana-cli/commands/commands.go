@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/orgmigration"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -165,6 +166,70 @@ var adminCommands = []*cli.Command{
 				Usage:  "Rotates persisted data encryption keys. Returns ok unless there is an error. Safe to execute multiple times.",
 				Action: runRunnerCommand(secretsmigrations.ReEncryptDEKS),
 			},
+			{
+				Name:   "encryption-status",
+				Usage:  "Prints the configured encryption providers and the number of data encryption keys encrypted by each of them.",
+				Action: runRunnerCommand(secretsmigrations.EncryptionStatus),
+			},
+		},
+	},
+	{
+		Name:  "org-migration",
+		Usage: "Exports an organization into a portable archive, and imports an archive into an organization",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export",
+				Usage:  "Exports the users, teams, folders, dashboards, data sources and alert rules of an organization into an archive.",
+				Action: runRunnerCommand(exportOrgCommand),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization to export",
+						Value: 1,
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "The path of the archive to create",
+					},
+					&cli.StringFlag{
+						Name:  "secrets-key-file",
+						Usage: "The path of a file with the key to encrypt the secrets of the data sources with in the archive. The key is read from GF_ORG_MIGRATION_SECRETS_KEY without it, and the secrets are not exported without a key",
+					},
+				},
+			},
+			{
+				Name:   "import",
+				Usage:  "Imports an archive into an organization.",
+				Action: runRunnerCommand(importOrgCommand),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization to import into",
+						Value: 1,
+					},
+					&cli.StringFlag{
+						Name:  "input",
+						Usage: "The path of the archive to import",
+					},
+					&cli.StringFlag{
+						Name:  "secrets-key-file",
+						Usage: "The path of a file with the key the secrets of the data sources were exported with. The key is read from GF_ORG_MIGRATION_SECRETS_KEY without it",
+					},
+					&cli.StringFlag{
+						Name:  "on-collision",
+						Usage: "What to do with the folders, dashboards, data sources and alert rules which already exist: fail, skip, overwrite or new-uid",
+						Value: string(orgmigration.CollisionFail),
+					},
+					&cli.BoolFlag{
+						Name:  "merge-users",
+						Usage: "Add the existing users with the email of a user of the archive to the organization. The import fails if any exists without it",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print what the import would do without importing anything",
+					},
+				},
+			},
 		},
 	},
 	{
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/orgmigration"
+)
+
+// secretsKeyEnvVar is the environment variable the secrets key is read from if no key file is given. The key is
+// never passed as a flag, so it doesn't show up in the process list or the shell history.
+const secretsKeyEnvVar = "GF_ORG_MIGRATION_SECRETS_KEY"
+
+// secretsKey returns the secrets key read from the --secrets-key-file file, or from the environment variable.
+func secretsKey(c utils.CommandLine) (string, error) {
+	path := c.String("secrets-key-file")
+	if path == "" {
+		return os.Getenv(secretsKeyEnvVar), nil
+	}
+	// nolint:gosec
+	// The path of the key file is given by the admin running the command.
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the secrets key: %w", err)
+	}
+	return strings.TrimSpace(string(key)), nil
+}
+
+func exportOrgCommand(c utils.CommandLine, runner server.Runner) error {
+	output := c.String("output")
+	if output == "" {
+		return errors.New("the --output flag is required")
+	}
+	key, err := secretsKey(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create the archive: %w", err)
+	}
+	manifest, err := runner.OrgMigration.Export(context.Background(), f, orgmigration.ExportOptions{
+		OrgID:      int64(c.Int("org-id")),
+		SecretsKey: key,
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("failed to export the organization: %w", err)
+	}
+
+	if manifest.SecretsKeyCheck == "" {
+		logger.Infof("The secrets of the data sources were not exported, pass --secrets-key-file or set %s to export them.\n", secretsKeyEnvVar)
+	}
+	logger.Infof("Organization %q exported to %s %s\n", manifest.OrgName, output, color.GreenString("✔"))
+	return printJSON(manifest.Counts)
+}
+
+func importOrgCommand(c utils.CommandLine, runner server.Runner) error {
+	input := c.String("input")
+	if input == "" {
+		return errors.New("the --input flag is required")
+	}
+	policy, err := orgmigration.ParseCollisionPolicy(c.String("on-collision"))
+	if err != nil {
+		return err
+	}
+	key, err := secretsKey(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("failed to open the archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	result, err := runner.OrgMigration.Import(context.Background(), f, orgmigration.ImportOptions{
+		OrgID:      int64(c.Int("org-id")),
+		SecretsKey: key,
+		Collision:  policy,
+		MergeUsers: c.Bool("merge-users"),
+		DryRun:     c.Bool("dry-run"),
+	})
+	if err != nil {
+		var collisionErr *orgmigration.CollisionError
+		if errors.As(err, &collisionErr) {
+			logger.Infof("%s, pass --on-collision to import them:\n", collisionErr.Error())
+			_ = printJSON(collisionErr.Resources)
+		}
+		if errors.Is(err, orgmigration.ErrUsersExist) {
+			logger.Infof("Check that the users are the same people, and pass --merge-users to add them to the organization.\n")
+		}
+		return fmt.Errorf("failed to import the organization: %w", err)
+	}
+
+	if result.DryRun {
+		logger.Infof("Dry run, nothing was imported.\n")
+	} else {
+		logger.Infof("Organization imported from %s %s\n", input, color.GreenString("✔"))
+	}
+	return printJSON(result.Resources)
+}
+
+func printJSON(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	logger.Info(string(out))
+	return nil
+}
@@ -2,7 +2,9 @@ package secretsmigrations
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/server"
 )
@@ -20,3 +22,18 @@ func RollBackSecrets(_ utils.CommandLine, runner server.Runner) error {
 	_, err := runner.SecretsMigrator.RollBackSecrets(context.Background())
 	return err
 }
+
+func EncryptionStatus(_ utils.CommandLine, runner server.Runner) error {
+	status, err := runner.SecretsService.GetEncryptionStatus(context.Background())
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	logger.Info(string(out), "\n")
+	return nil
+}
@@ -9,10 +9,14 @@ import (
 
 	"github.com/grafana/pyroscope-go/godeltaprof/http/pprof"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/apiserver/pkg/server/mux"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
 	"k8s.io/client-go/tools/clientcmd"
 	netutils "k8s.io/utils/net"
 
@@ -20,10 +24,13 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	grafanaAPIServer "github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/services/apiserver/options"
 	"github.com/grafana/grafana/pkg/services/apiserver/standalone"
 	standaloneoptions "github.com/grafana/grafana/pkg/services/apiserver/standalone/options"
 	"github.com/grafana/grafana/pkg/services/apiserver/utils"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/apistore"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
 )
 
 const (
@@ -91,6 +98,7 @@ func (o *APIServerOptions) Config(tracer tracing.Tracer) (*genericapiserver.Reco
 	o.Options.RecommendedOptions.Authorization = nil
 
 	o.Options.RecommendedOptions.Admission = nil
+	storageConfig := o.Options.RecommendedOptions.Etcd.StorageConfig
 	o.Options.RecommendedOptions.Etcd = nil
 
 	if o.Options.RecommendedOptions.CoreAPI.CoreAPIKubeconfigPath == "" {
@@ -110,6 +118,10 @@ func (o *APIServerOptions) Config(tracer tracing.Tracer) (*genericapiserver.Reco
 		}
 	}
 
+	if err := o.applyStorageOptions(serverConfig, storageConfig); err != nil {
+		return nil, err
+	}
+
 	serverConfig.DisabledPostStartHooks = serverConfig.DisabledPostStartHooks.Insert("generic-apiserver-start-informers")
 	serverConfig.DisabledPostStartHooks = serverConfig.DisabledPostStartHooks.Insert("priority-and-fairness-config-consumer")
 
@@ -118,6 +130,7 @@ func (o *APIServerOptions) Config(tracer tracing.Tracer) (*genericapiserver.Reco
 		grafanaAPIServer.Scheme,
 		serverConfig,
 		o.builders,
+		o.Options.FlowControlOptions.Config(),
 		setting.BuildStamp,
 		setting.BuildVersion,
 		setting.BuildCommit,
@@ -127,6 +140,38 @@ func (o *APIServerOptions) Config(tracer tracing.Tracer) (*genericapiserver.Reco
 	return serverConfig, err
 }
 
+// applyStorageOptions sets up the storage of the resources. The legacy storage type keeps the default,
+// where each group only serves the resources of its legacy store.
+func (o *APIServerOptions) applyStorageOptions(serverConfig *genericapiserver.RecommendedConfig, storageConfig storagebackend.Config) error {
+	storageOpts := o.Options.StorageOptions
+	switch storageOpts.StorageType {
+	case options.StorageTypeLegacy:
+		return nil
+
+	case options.StorageTypeFile:
+		restOptionsGetter, err := apistore.NewRESTOptionsGetterForFile(storageOpts.DataPath, storageConfig)
+		if err != nil {
+			return err
+		}
+		serverConfig.RESTOptionsGetter = restOptionsGetter
+
+	case options.StorageTypeUnifiedGrpc:
+		conn, err := grpc.NewClient(storageOpts.Address,
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		if err != nil {
+			return err
+		}
+		client := resource.NewResourceStoreClientGRPC(conn)
+		serverConfig.RESTOptionsGetter = apistore.NewRESTOptionsGetterForClient(client, storageConfig)
+
+	default:
+		return fmt.Errorf("storage type %s is not supported in standalone mode", storageOpts.StorageType)
+	}
+	return nil
+}
+
 func (o *APIServerOptions) AddFlags(fs *pflag.FlagSet) {
 	o.Options.AddFlags(fs)
 
@@ -165,7 +210,6 @@ func (o *APIServerOptions) RunAPIServer(ctx context.Context, config *genericapis
 	}
 
 	// Install the API Group+version
-	// #TODO figure out how to configure storage type in o.Options.StorageOptions
 	err = builder.InstallAPIs(grafanaAPIServer.Scheme, grafanaAPIServer.Codecs, server, config.RESTOptionsGetter, o.builders, o.Options.StorageOptions,
 		o.Options.MetricsOptions.MetricsRegisterer, nil, nil, nil, // no need for server lock in standalone
 	)
@@ -0,0 +1,50 @@
+package simplejson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressedPrefix starts the compressed JSON values, which are the base64 encoding of the zstd compressed JSON so
+// they can be stored in text columns. A JSON value never starts with it.
+const compressedPrefix = "zstd:"
+
+var (
+	// the encoder and decoder are safe for concurrent use with EncodeAll and DecodeAll
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// Compress returns the JSON compressed to be stored in the database. It is decompressed by FromDB and Scan.
+func Compress(data []byte) []byte {
+	compressed := zstdEncoder.EncodeAll(data, nil)
+	out := make([]byte, len(compressedPrefix)+base64.StdEncoding.EncodedLen(len(compressed)))
+	copy(out, compressedPrefix)
+	base64.StdEncoding.Encode(out[len(compressedPrefix):], compressed)
+	return out
+}
+
+// IsCompressed returns whether a value stored in the database is compressed JSON.
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(compressedPrefix))
+}
+
+// Decompress returns the JSON of a value stored in the database, which is returned as is if it is not compressed.
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(data)-len(compressedPrefix)))
+	n, err := base64.StdEncoding.Decode(compressed, data[len(compressedPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed JSON: %w", err)
+	}
+	decompressed, err := zstdDecoder.DecodeAll(compressed[:n], nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed JSON: %w", err)
+	}
+	return decompressed, nil
+}
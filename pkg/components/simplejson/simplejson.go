@@ -26,6 +26,10 @@ type Json struct {
 func (j *Json) FromDB(data []byte) error {
 	j.data = make(map[string]any)
 
+	data, err := Decompress(data)
+	if err != nil {
+		return err
+	}
 	dec := json.NewDecoder(bytes.NewBuffer(data))
 	dec.UseNumber()
 	return dec.Decode(&j.data)
@@ -45,12 +49,20 @@ func (j *Json) Scan(val any) error {
 		if len(v) == 0 {
 			return nil
 		}
-		return json.Unmarshal(v, &j)
+		data, err := Decompress(v)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &j)
 	case string:
 		if len(v) == 0 {
 			return nil
 		}
-		return json.Unmarshal([]byte(v), &j)
+		data, err := Decompress([]byte(v))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &j)
 	default:
 		return fmt.Errorf("unsupported type: %T", v)
 	}
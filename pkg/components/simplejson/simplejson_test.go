@@ -272,3 +272,25 @@ func TestMustJson(t *testing.T) {
 		MustJson([]byte(`{`))
 	})
 }
+
+func TestCompressedFromDB(t *testing.T) {
+	data := []byte(`{"title": "compressed", "panels": [{"id": 1}]}`)
+	compressed := Compress(data)
+	assert.True(t, IsCompressed(compressed))
+	assert.False(t, IsCompressed(data))
+
+	decompressed, err := Decompress(compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+
+	js := &Json{}
+	assert.NoError(t, js.FromDB(compressed))
+	assert.Equal(t, "compressed", js.Get("title").MustString())
+
+	js = &Json{}
+	assert.NoError(t, js.Scan(string(compressed)))
+	assert.Equal(t, "compressed", js.Get("title").MustString())
+
+	_, err = Decompress([]byte("zstd:invalid"))
+	assert.Error(t, err)
+}
@@ -71,6 +71,17 @@ type DataSourceCreated struct {
 	OrgID     int64     `json:"org_id"`
 }
 
+// DataSourceHealthChanged is emitted when the periodic health check of a data source
+// finds it healthy after it was unhealthy, or the other way around.
+type DataSourceHealthChanged struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	Healthy   bool      `json:"healthy"`
+	Message   string    `json:"message"`
+}
+
 // FolderFullPathUpdated is emitted when the full path of the folder(s) is updated.
 // For example, when the folder is renamed or moved to another folder.
 // It does not contain the full path of the folders because calculating
@@ -343,6 +343,8 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeJoin is the CMDType for joining series on time and labels
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +361,8 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -379,6 +383,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
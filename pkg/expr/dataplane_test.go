@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -57,7 +58,7 @@ func framesPassThroughService(t *testing.T, frames data.Frames) (data.Frames, er
 		cfg:         cfg,
 		dataService: me,
 		features:    features,
-		pCtxProvider: plugincontext.ProvideService(cfg, nil, &pluginstore.FakePluginStore{
+		pCtxProvider: plugincontext.ProvideService(cfg, namedcache.FakeProvider{}, &pluginstore.FakePluginStore{
 			PluginList: []pluginstore.Plugin{
 				{JSONData: plugins.JSONData{ID: "test"}},
 			}},
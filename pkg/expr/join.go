@@ -0,0 +1,282 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// How series are joined on time
+// +enum
+type JoinMode string
+
+const (
+	// Keep only the timestamps present in every series
+	JoinModeInner JoinMode = "inner"
+
+	// Keep the timestamps present in any series
+	JoinModeOuter JoinMode = "outer"
+)
+
+// How missing values are filled in an outer join
+// +enum
+type JoinFillMode string
+
+const (
+	// Leave missing values empty
+	JoinFillNull JoinFillMode = "null"
+
+	// Use a fixed value
+	JoinFillValue JoinFillMode = "value"
+
+	// Use the previous value of the series
+	JoinFillPrevious JoinFillMode = "previous"
+)
+
+type JoinFill struct {
+	Mode JoinFillMode `json:"mode"`
+
+	// Only valid when mode is value
+	Value *float64 `json:"value,omitempty"`
+}
+
+// JoinCommand aligns series coming from different queries, usually of different data sources,
+// on time and labels and then evaluates a math expression on the aligned series.
+type JoinCommand struct {
+	RawExpression string
+	Expression    *mathexp.Expr
+	Mode          JoinMode
+	On            []string
+	Resolution    time.Duration
+	Fill          JoinFill
+	refID         string
+}
+
+// NewJoinCommand creates a new JoinCommand. It will return an error if the
+// expression cannot be parsed or the join settings are invalid.
+func NewJoinCommand(refID, expr string, mode JoinMode, on []string, resolution time.Duration, fill *JoinFill) (*JoinCommand, error) {
+	parsedExpr, err := mathexp.New(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case "":
+		mode = JoinModeInner
+	case JoinModeInner, JoinModeOuter:
+	default:
+		return nil, fmt.Errorf("unsupported join mode '%s'. Supported only: [%s,%s]", mode, JoinModeInner, JoinModeOuter)
+	}
+
+	f := JoinFill{Mode: JoinFillNull}
+	if fill != nil && fill.Mode != "" {
+		f = *fill
+	}
+	switch f.Mode {
+	case JoinFillNull, JoinFillPrevious:
+	case JoinFillValue:
+		if f.Value == nil {
+			return nil, fmt.Errorf("fill value must be specified when fill mode is '%s'", JoinFillValue)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported fill mode '%s'. Supported only: [%s,%s,%s]", f.Mode, JoinFillNull, JoinFillValue, JoinFillPrevious)
+	}
+
+	if resolution < 0 {
+		return nil, fmt.Errorf("join resolution must not be negative")
+	}
+
+	return &JoinCommand{
+		RawExpression: expr,
+		Expression:    parsedExpr,
+		Mode:          mode,
+		On:            on,
+		Resolution:    resolution,
+		Fill:          f,
+		refID:         refID,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	q := JoinQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the join command: %w", err)
+	}
+	return newJoinCommandFromQuery(rn.RefID, q)
+}
+
+func newJoinCommandFromQuery(refID string, q JoinQuery) (*JoinCommand, error) {
+	if q.Expression == "" {
+		return nil, fmt.Errorf("join command is missing an expression")
+	}
+	var resolution time.Duration
+	if q.Resolution != "" {
+		var err error
+		resolution, err = gtime.ParseDuration(q.Resolution)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse join "resolution" duration field %q: %w`, q.Resolution, err)
+		}
+	}
+	return NewJoinCommand(refID, q.Expression, q.Join, q.On, resolution, q.Fill)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	return jc.Expression.VarNames
+}
+
+// Execute aligns the series of the variables, groups them by their labels and evaluates
+// the expression on every group.
+func (jc *JoinCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteJoin")
+	span.SetAttributes(attribute.String("expression", jc.RawExpression), attribute.String("join", string(jc.Mode)))
+	defer span.End()
+
+	groups := map[string]*joinGroup{}
+	for _, varName := range jc.NeedsVars() {
+		for _, val := range vars[varName].Values {
+			switch v := val.(type) {
+			case mathexp.Series:
+				labels := jc.groupLabels(v.GetLabels())
+				key := labels.String()
+				g, ok := groups[key]
+				if !ok {
+					g = &joinGroup{labels: labels, series: map[string]mathexp.Series{}}
+					groups[key] = g
+				}
+				if _, ok := g.series[varName]; ok {
+					return mathexp.Results{}, fmt.Errorf("join '%s': more than one series of '%s' matches labels %s", jc.refID, varName, key)
+				}
+				g.series[varName] = v
+			case mathexp.NoData:
+			default:
+				return mathexp.Results{}, fmt.Errorf("join '%s': can only join type series, got type %v for '%s'", jc.refID, val.Type(), varName)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	newRes := mathexp.Results{}
+	for _, key := range keys {
+		g := groups[key]
+		if jc.Mode == JoinModeInner && len(g.series) != len(jc.NeedsVars()) {
+			continue
+		}
+
+		aligned := jc.align(g)
+		res, err := jc.Expression.Execute(jc.refID, aligned, tracer)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		newRes.Values = append(newRes.Values, res.Values...)
+	}
+
+	if len(newRes.Values) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+	return newRes, nil
+}
+
+func (jc *JoinCommand) Type() string {
+	return TypeJoin.String()
+}
+
+type joinGroup struct {
+	labels data.Labels
+	series map[string]mathexp.Series
+}
+
+// groupLabels returns the labels series are matched on.
+func (jc *JoinCommand) groupLabels(labels data.Labels) data.Labels {
+	if len(jc.On) == 0 {
+		return labels.Copy()
+	}
+	matched := data.Labels{}
+	for _, name := range jc.On {
+		if v, ok := labels[name]; ok {
+			matched[name] = v
+		}
+	}
+	return matched
+}
+
+// align returns one series per variable of the expression with the same timestamps and the labels of the group.
+func (jc *JoinCommand) align(g *joinGroup) mathexp.Vars {
+	// Points are keyed by their unix time in nanoseconds, as time.Time values of the same
+	// instant are not equal if they have different locations.
+	points := make(map[string]map[int64]*float64, len(g.series))
+	counts := map[int64]int{}
+	for varName, s := range g.series {
+		values := make(map[int64]*float64, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			if jc.Resolution > 0 {
+				t = t.Truncate(jc.Resolution)
+			}
+			ts := t.UnixNano()
+			if _, ok := values[ts]; !ok {
+				counts[ts]++
+			}
+			values[ts] = v
+		}
+		points[varName] = values
+	}
+
+	timestamps := make([]int64, 0, len(counts))
+	for ts, n := range counts {
+		if jc.Mode == JoinModeInner && n != len(g.series) {
+			continue
+		}
+		timestamps = append(timestamps, ts)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+
+	aligned := make(mathexp.Vars, len(jc.NeedsVars()))
+	for _, varName := range jc.NeedsVars() {
+		values := points[varName]
+		s := mathexp.NewSeries(varName, g.labels.Copy(), len(timestamps))
+		var prev *float64
+		for i, ts := range timestamps {
+			v, ok := values[ts]
+			if !ok {
+				v = jc.fillValue(prev)
+			}
+			s.SetPoint(i, time.Unix(0, ts).UTC(), v)
+			if v != nil {
+				prev = v
+			}
+		}
+		aligned[varName] = mathexp.Results{Values: mathexp.Values{s}}
+	}
+	return aligned
+}
+
+func (jc *JoinCommand) fillValue(prev *float64) *float64 {
+	switch jc.Fill.Mode {
+	case JoinFillValue:
+		v := *jc.Fill.Value
+		return &v
+	case JoinFillPrevious:
+		return prev
+	default:
+		return nil
+	}
+}
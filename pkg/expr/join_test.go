@@ -0,0 +1,137 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func joinTestSeries(refID string, labels data.Labels, points map[int64]float64) mathexp.Series {
+	s := mathexp.NewSeries(refID, labels, 0)
+	for _, ts := range []int64{0, 10, 20, 30, 40} {
+		if v, ok := points[ts]; ok {
+			s.AppendPoint(time.Unix(ts, 0), util.Pointer(v))
+		}
+	}
+	return s
+}
+
+func joinSeriesValues(t *testing.T, v mathexp.Value) map[int64]*float64 {
+	t.Helper()
+	s, ok := v.(mathexp.Series)
+	require.True(t, ok, "expected series, got %T", v)
+	values := map[int64]*float64{}
+	for i := 0; i < s.Len(); i++ {
+		ts, f := s.GetPoint(i)
+		values[ts.Unix()] = f
+	}
+	return values
+}
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	cmd, err := UnmarshalJoinCommand(&rawNode{
+		RefID:    "C",
+		QueryRaw: []byte(`{"expression": "$A / $B", "join": "outer", "on": ["job"], "resolution": "1m", "fill": {"mode": "value", "value": 0}}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, JoinModeOuter, cmd.Mode)
+	require.Equal(t, []string{"job"}, cmd.On)
+	require.Equal(t, time.Minute, cmd.Resolution)
+	require.Equal(t, JoinFillValue, cmd.Fill.Mode)
+	require.ElementsMatch(t, []string{"A", "B"}, cmd.NeedsVars())
+
+	for _, raw := range []string{
+		`{"expression": ""}`,
+		`{"expression": "$A / $B", "join": "left"}`,
+		`{"expression": "$A / $B", "fill": {"mode": "value"}}`,
+		`{"expression": "$A / $B", "fill": {"mode": "linear"}}`,
+		`{"expression": "$A / $B", "resolution": "soon"}`,
+	} {
+		_, err := UnmarshalJoinCommand(&rawNode{RefID: "C", QueryRaw: []byte(raw)})
+		require.Error(t, err, raw)
+	}
+}
+
+func TestJoinCommand_Execute(t *testing.T) {
+	// A comes from one data source with an extra label, B from another with points at other timestamps.
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			joinTestSeries("A", data.Labels{"job": "api", "instance": "a"}, map[int64]float64{0: 10, 10: 20, 20: 30}),
+			joinTestSeries("A", data.Labels{"job": "db", "instance": "b"}, map[int64]float64{0: 1}),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			joinTestSeries("B", data.Labels{"job": "api"}, map[int64]float64{10: 2, 20: 3, 30: 4}),
+		}},
+	}
+
+	t.Run("inner join keeps matching labels and timestamps", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "$A / $B", JoinModeInner, []string{"job"}, 0, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, data.Labels{"job": "api"}, res.Values[0].GetLabels())
+		require.Equal(t, map[int64]*float64{10: util.Pointer(10.0), 20: util.Pointer(10.0)}, joinSeriesValues(t, res.Values[0]))
+	})
+
+	t.Run("outer join fills missing values", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "$A + $B", JoinModeOuter, []string{"job"}, 0, &JoinFill{Mode: JoinFillValue, Value: util.Pointer(0.0)})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, map[int64]*float64{0: util.Pointer(10.0), 10: util.Pointer(22.0), 20: util.Pointer(33.0), 30: util.Pointer(4.0)}, joinSeriesValues(t, res.Values[0]))
+		require.Equal(t, map[int64]*float64{0: util.Pointer(1.0)}, joinSeriesValues(t, res.Values[1]))
+	})
+
+	t.Run("outer join fills with the previous value", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "$A + $B", JoinModeOuter, []string{"job"}, 0, &JoinFill{Mode: JoinFillPrevious})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		values := joinSeriesValues(t, res.Values[0])
+		require.Nil(t, values[0], "no previous value of B")
+		require.Equal(t, util.Pointer(34.0), values[30])
+	})
+
+	t.Run("resolution aligns close timestamps", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "$A - $B", JoinModeInner, nil, 20*time.Second, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{joinTestSeries("A", nil, map[int64]float64{0: 5, 20: 7})}},
+			"B": mathexp.Results{Values: mathexp.Values{joinTestSeries("B", nil, map[int64]float64{10: 1, 30: 2})}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, map[int64]*float64{0: util.Pointer(4.0), 20: util.Pointer(5.0)}, joinSeriesValues(t, res.Values[0]))
+	})
+
+	t.Run("no matching series returns no data", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "$A / $B", JoinModeInner, nil, 0, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, res.IsNoData())
+	})
+
+	t.Run("numbers cannot be joined", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "$A / $B", JoinModeInner, nil, 0, nil)
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
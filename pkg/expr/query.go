@@ -29,6 +29,9 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Join query results on time and labels
+	QueryTypeJoin QueryType = "join"
 )
 
 type MathQuery struct {
@@ -79,6 +82,24 @@ type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
 }
 
+// QueryType = join
+type JoinQuery struct {
+	// Math expression evaluated on the aligned series
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A / $B"`
+
+	// How series are joined on time, defaults to inner
+	Join JoinMode `json:"join,omitempty"`
+
+	// Labels the series are matched on, all labels when empty
+	On []string `json:"on,omitempty"`
+
+	// Timestamps are truncated to this duration before they are aligned
+	Resolution string `json:"resolution,omitempty" jsonschema:"example=1m,example=30s"`
+
+	// How missing values are filled in an outer join
+	Fill *JoinFill `json:"fill,omitempty"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -27,6 +27,8 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(JoinModeInner),
+				reflect.TypeOf(JoinFillNull),
 			},
 		})
 	require.NoError(t, err)
@@ -92,6 +94,22 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeJoin),
+			GoType:         reflect.TypeOf(&JoinQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "ratio of A and B aligned on the minute",
+					SaveModel: data.AsUnstructured(JoinQuery{
+						Expression: "$A / $B",
+						Join:       JoinModeOuter,
+						On:         []string{"service"},
+						Resolution: "1m",
+						Fill:       &JoinFill{Mode: JoinFillPrevious},
+					}),
+				},
+			},
+		},
 		schemabuilder.QueryTypeInfo{
 			Discriminators: data.NewDiscriminators("type", QueryTypeClassic),
 			GoType:         reflect.TypeOf(&ClassicQuery{}),
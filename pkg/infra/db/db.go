@@ -24,6 +24,11 @@ type DB interface {
 	// through [context.Context] or if that's not present, as non-transactional database
 	// operations.
 	WithDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
+	// WithReadOnlyDbSession runs read-only database operations on a read replica when read
+	// replicas are configured and healthy, and like [DB.WithDbSession] otherwise. Reads from a
+	// replica may lag behind the latest writes, so it must not be used to read back data that
+	// was just written.
+	WithReadOnlyDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
 	// GetDialect returns an object that contains information about the peculiarities of
 	// the particular database type available to the runtime.
 	GetDialect() migrator.Dialect
@@ -28,6 +28,10 @@ func (f *FakeDB) WithDbSession(ctx context.Context, callback sqlstore.DBTransact
 	return f.ExpectedError
 }
 
+func (f *FakeDB) WithReadOnlyDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error {
+	return f.ExpectedError
+}
+
 func (f *FakeDB) WithNewDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error {
 	return f.ExpectedError
 }
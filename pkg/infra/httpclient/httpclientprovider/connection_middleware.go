@@ -0,0 +1,115 @@
+package httpclientprovider
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/metrics/metricutil"
+)
+
+var (
+	datasourceConnectionsCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "datasource_connections_total",
+			Help:      "A counter for connections used by outgoing data source requests, by whether an existing connection was reused",
+		},
+		[]string{"datasource", "datasource_type", "reused"},
+	)
+
+	datasourceConnectionRefreshCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "datasource_idle_connections_refresh_total",
+			Help:      "A counter for the times the idle connections of a data source were closed to resolve its host again",
+		},
+		[]string{"datasource", "datasource_type"},
+	)
+)
+
+const ConnectionMiddlewareName = "connection"
+
+type idleConnectionsCloser interface {
+	CloseIdleConnections()
+}
+
+// ConnectionMiddleware counts whether requests reuse connections and closes idle connections at the
+// DNS refresh interval of the data source. It must be the last middleware, so that it wraps the transport.
+func ConnectionMiddleware() sdkhttpclient.Middleware {
+	return sdkhttpclient.NamedMiddlewareFunc(ConnectionMiddlewareName, func(opts sdkhttpclient.Options, next http.RoundTripper) http.RoundTripper {
+		datasourceName, exists := opts.Labels["datasource_name"]
+		if !exists {
+			return next
+		}
+		datasourceLabelName, err := metricutil.SanitizeLabelName(datasourceName)
+		if err != nil {
+			return next
+		}
+		datasourceLabelType, err := metricutil.SanitizeLabelName(opts.Labels["datasource_type"])
+		if err != nil {
+			return next
+		}
+
+		connections := datasourceConnectionsCounter.MustCurryWith(prometheus.Labels{
+			"datasource":      datasourceLabelName,
+			"datasource_type": datasourceLabelType,
+		})
+		refreshes := datasourceConnectionRefreshCounter.WithLabelValues(datasourceLabelName, datasourceLabelType)
+
+		refresher := &idleConnectionsRefresher{
+			interval: TransportSettingsFromOptions(opts).DNSRefreshInterval,
+			now:      time.Now,
+		}
+		refresher.closer, _ = next.(idleConnectionsCloser)
+		refresher.lastRefresh = refresher.now()
+
+		return sdkhttpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if refresher.refresh() {
+				refreshes.Inc()
+			}
+
+			trace := &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) {
+					connections.WithLabelValues(strconv.FormatBool(info.Reused)).Inc()
+				},
+			}
+			return next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+		})
+	})
+}
+
+// idleConnectionsRefresher closes the idle connections of a transport once per interval.
+type idleConnectionsRefresher struct {
+	interval time.Duration
+	closer   idleConnectionsCloser
+	now      func() time.Time
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+}
+
+// refresh closes the idle connections if the interval passed since the last refresh and returns true if it did.
+func (r *idleConnectionsRefresher) refresh() bool {
+	if r.interval <= 0 || r.closer == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	now := r.now()
+	if now.Sub(r.lastRefresh) < r.interval {
+		r.mu.Unlock()
+		return false
+	}
+	r.lastRefresh = now
+	r.mu.Unlock()
+
+	r.closer.CloseIdleConnections()
+	return true
+}
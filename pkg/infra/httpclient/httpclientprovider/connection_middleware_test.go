@@ -0,0 +1,86 @@
+package httpclientprovider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIdleConnectionsCloser struct {
+	calls int
+}
+
+func (f *fakeIdleConnectionsCloser) CloseIdleConnections() {
+	f.calls++
+}
+
+func TestConnectionMiddleware(t *testing.T) {
+	t.Run("Without data source name label should return next http.RoundTripper", func(t *testing.T) {
+		ctx := &testContext{}
+		finalRoundTripper := ctx.createRoundTripper("finalrt")
+		mw := ConnectionMiddleware()
+		rt := mw.CreateMiddleware(sdkhttpclient.Options{}, finalRoundTripper)
+		require.NotNil(t, rt)
+		require.Equal(t, ConnectionMiddlewareName, mw.(sdkhttpclient.MiddlewareName).MiddlewareName())
+
+		req, err := http.NewRequest(http.MethodGet, "http://", nil)
+		require.NoError(t, err)
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, []string{"finalrt"}, ctx.callChain)
+	})
+
+	t.Run("Should count new and reused connections", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		transport := &http.Transport{}
+		t.Cleanup(transport.CloseIdleConnections)
+		rt := ConnectionMiddleware().CreateMiddleware(sdkhttpclient.Options{
+			Labels: map[string]string{"datasource_name": "reuse", "datasource_type": "loki"},
+		}, transport)
+
+		for i := 0; i < 3; i++ {
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			res, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+		}
+
+		require.Equal(t, 1.0, testutil.ToFloat64(datasourceConnectionsCounter.WithLabelValues("reuse", "loki", "false")))
+		require.Equal(t, 2.0, testutil.ToFloat64(datasourceConnectionsCounter.WithLabelValues("reuse", "loki", "true")))
+	})
+}
+
+func TestIdleConnectionsRefresher(t *testing.T) {
+	now := time.Now()
+	closer := &fakeIdleConnectionsCloser{}
+	r := &idleConnectionsRefresher{
+		interval:    time.Minute,
+		closer:      closer,
+		now:         func() time.Time { return now },
+		lastRefresh: now,
+	}
+
+	require.False(t, r.refresh())
+	now = now.Add(30 * time.Second)
+	require.False(t, r.refresh())
+	now = now.Add(30 * time.Second)
+	require.True(t, r.refresh())
+	require.False(t, r.refresh())
+	require.Equal(t, 1, closer.calls)
+
+	t.Run("Without interval should not close connections", func(t *testing.T) {
+		r := &idleConnectionsRefresher{closer: closer, now: time.Now}
+		require.False(t, r.refresh())
+	})
+}
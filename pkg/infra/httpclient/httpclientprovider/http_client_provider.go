@@ -1,6 +1,9 @@
 package httpclientprovider
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/mwitkow/go-conntrack"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient/tunnel"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics/metricutil"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -31,6 +35,7 @@ func New(cfg *setting.Cfg, validator validations.PluginRequestValidator, tracer
 		sdkhttpclient.BasicAuthenticationMiddleware(),
 		sdkhttpclient.CustomHeadersMiddleware(),
 		sdkhttpclient.ResponseLimitMiddleware(cfg.ResponseLimit),
+		MaxResponseBytesMiddleware(),
 		RedirectLimitMiddleware(validator),
 	}
 
@@ -61,11 +66,17 @@ func New(cfg *setting.Cfg, validator validations.PluginRequestValidator, tracer
 		middlewares = append(middlewares, awssdk.SigV4MiddlewareWithAuthSettings(cfg.SigV4VerboseLogging, authSettings))
 	}
 
+	// The connection middleware wraps the transport directly, so it has to be the last one
+	middlewares = append(middlewares, ConnectionMiddleware())
+
 	setDefaultTimeoutOptions(cfg)
 
 	return newProviderFunc(sdkhttpclient.ProviderOptions{
 		Middlewares: middlewares,
 		ConfigureTransport: func(opts sdkhttpclient.Options, transport *http.Transport) {
+			TransportSettingsFromOptions(opts).Apply(transport)
+			configureTunnel(opts, transport, logger)
+
 			datasourceName, exists := opts.Labels["datasource_name"]
 			if !exists {
 				return
@@ -80,6 +91,30 @@ func New(cfg *setting.Cfg, validator validations.PluginRequestValidator, tracer
 	})
 }
 
+// configureTunnel routes the connections of the transport through the tunnel of the data source, if it has one.
+func configureTunnel(opts sdkhttpclient.Options, transport *http.Transport, logger log.Logger) {
+	tunnelOpts, ok := tunnel.FromClientOptions(opts)
+	if !ok {
+		return
+	}
+
+	forward := &net.Dialer{}
+	if opts.Timeouts != nil {
+		forward.Timeout = opts.Timeouts.DialTimeout
+		forward.KeepAlive = opts.Timeouts.KeepAlive
+	}
+	dialer, err := tunnel.NewDialer(tunnelOpts, forward)
+	if err != nil {
+		logger.Error("Failed to create data source tunnel", "datasource", tunnelOpts.ID, "error", err)
+		transport.DialContext = func(context.Context, string, string) (net.Conn, error) {
+			return nil, fmt.Errorf("failed to create data source tunnel: %w", err)
+		}
+		return
+	}
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+}
+
 // newConntrackRoundTripper takes a http.DefaultTransport and adds the Conntrack Dialer
 // so we can instrument outbound connections
 func newConntrackRoundTripper(name string, transport *http.Transport) *http.Transport {
@@ -0,0 +1,47 @@
+package httpclientprovider
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// MaxResponseBytesMiddlewareName is the middleware name used by MaxResponseBytesMiddleware.
+const MaxResponseBytesMiddlewareName = "max-response-bytes"
+
+// MaxResponseBytesMiddleware limits the size of the responses to the size limit of the query request the
+// request is made for, see backendplugin.WithMaxResponseBytes. Reading a body over the limit fails with
+// plugins.ErrResponseTooLarge, so that the core plugins, like Loki and Prometheus, stop decoding it.
+func MaxResponseBytesMiddleware() httpclient.Middleware {
+	return httpclient.NamedMiddlewareFunc(MaxResponseBytesMiddlewareName, func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			maxBytes := backendplugin.MaxResponseBytes(req.Context())
+			if maxBytes > 0 && res != nil && res.Body != nil && res.StatusCode != http.StatusSwitchingProtocols {
+				res.Body = &maxResponseBytesReader{ReadCloser: httpclient.MaxBytesReader(res.Body, maxBytes)}
+			}
+			return res, nil
+		})
+	})
+}
+
+type maxResponseBytesReader struct {
+	io.ReadCloser
+}
+
+func (r *maxResponseBytesReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if errors.Is(err, httpclient.ErrResponseBodyTooLarge) {
+		err = plugins.ErrResponseTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,56 @@
+package httpclientprovider
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+func TestMaxResponseBytesMiddleware(t *testing.T) {
+	finalRoundTripper := httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Request: req, Body: io.NopCloser(bytes.NewBufferString("0123456789"))}, nil
+	})
+	mw := MaxResponseBytesMiddleware()
+	rt := mw.CreateMiddleware(httpclient.Options{}, finalRoundTripper)
+	middlewareName, ok := mw.(httpclient.MiddlewareName)
+	require.True(t, ok)
+	require.Equal(t, MaxResponseBytesMiddlewareName, middlewareName.MiddlewareName())
+
+	t.Run("Without a size limit should not limit the response", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://", nil)
+		require.NoError(t, err)
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "0123456789", string(body))
+	})
+
+	t.Run("With a size limit should fail to read the responses over it", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://", nil)
+		require.NoError(t, err)
+		req = req.WithContext(backendplugin.WithMaxResponseBytes(req.Context(), 5))
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		_, err = io.ReadAll(res.Body)
+		require.ErrorIs(t, err, plugins.ErrResponseTooLarge)
+	})
+
+	t.Run("With a size limit should read the responses under it", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://", nil)
+		require.NoError(t, err)
+		req = req.WithContext(backendplugin.WithMaxResponseBytes(req.Context(), 10))
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "0123456789", string(body))
+	})
+}
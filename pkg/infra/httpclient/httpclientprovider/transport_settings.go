@@ -0,0 +1,95 @@
+package httpclientprovider
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+)
+
+// TransportSettings are the connection pooling settings a data source can set in its JsonData
+// to override the [dataproxy] defaults.
+type TransportSettings struct {
+	// MaxIdleConns is read from maxIdleConns
+	MaxIdleConns *int
+	// MaxIdleConnsPerHost is read from maxIdleConnsPerHost
+	MaxIdleConnsPerHost *int
+	// IdleConnTimeout is read from idleConnTimeout, in seconds
+	IdleConnTimeout *time.Duration
+	// EnableHTTP2 is read from enableHTTP2
+	EnableHTTP2 *bool
+	// DNSRefreshInterval is read from dnsRefreshInterval, in seconds. Idle connections are closed
+	// at this interval so that new connections resolve the host again.
+	DNSRefreshInterval time.Duration
+}
+
+// TransportSettingsFromOptions reads the transport settings from the JsonData of a data source, which the
+// data source service and the plugin SDK pass in the grafanaData custom option.
+func TransportSettingsFromOptions(opts sdkhttpclient.Options) TransportSettings {
+	settings := TransportSettings{}
+	jsonData, ok := opts.CustomOptions["grafanaData"].(map[string]any)
+	if !ok {
+		return settings
+	}
+
+	if v, ok := intOption(jsonData, "maxIdleConns"); ok && v >= 0 {
+		settings.MaxIdleConns = &v
+	}
+	if v, ok := intOption(jsonData, "maxIdleConnsPerHost"); ok && v >= 0 {
+		settings.MaxIdleConnsPerHost = &v
+	}
+	if v, ok := intOption(jsonData, "idleConnTimeout"); ok && v >= 0 {
+		timeout := time.Duration(v) * time.Second
+		settings.IdleConnTimeout = &timeout
+	}
+	if v, ok := jsonData["enableHTTP2"].(bool); ok {
+		settings.EnableHTTP2 = &v
+	}
+	if v, ok := intOption(jsonData, "dnsRefreshInterval"); ok && v > 0 {
+		settings.DNSRefreshInterval = time.Duration(v) * time.Second
+	}
+	return settings
+}
+
+// Apply overrides the connection pooling settings of the transport.
+func (s TransportSettings) Apply(transport *http.Transport) {
+	if s.MaxIdleConns != nil {
+		transport.MaxIdleConns = *s.MaxIdleConns
+	}
+	if s.MaxIdleConnsPerHost != nil {
+		transport.MaxIdleConnsPerHost = *s.MaxIdleConnsPerHost
+	}
+	if s.IdleConnTimeout != nil {
+		transport.IdleConnTimeout = *s.IdleConnTimeout
+	}
+	if s.EnableHTTP2 != nil {
+		transport.ForceAttemptHTTP2 = *s.EnableHTTP2
+		if !*s.EnableHTTP2 {
+			// A non-nil empty map disables HTTP/2 on TLS connections
+			transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+}
+
+// intOption returns an integer of the JsonData, which can be stored as a number or a string.
+func intOption(jsonData map[string]any, key string) (int, bool) {
+	switch v := jsonData[key].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case json.Number:
+		i, err := v.Int64()
+		return int(i), err == nil
+	case string:
+		i, err := strconv.Atoi(v)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
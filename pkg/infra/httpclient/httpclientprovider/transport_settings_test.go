@@ -0,0 +1,62 @@
+package httpclientprovider
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportSettings(t *testing.T) {
+	t.Run("Without JsonData should not change the transport", func(t *testing.T) {
+		transport := &http.Transport{MaxIdleConns: 100, IdleConnTimeout: time.Minute, ForceAttemptHTTP2: true}
+		settings := TransportSettingsFromOptions(sdkhttpclient.Options{})
+		require.Equal(t, TransportSettings{}, settings)
+
+		settings.Apply(transport)
+		require.Equal(t, 100, transport.MaxIdleConns)
+		require.Equal(t, time.Minute, transport.IdleConnTimeout)
+		require.True(t, transport.ForceAttemptHTTP2)
+		require.Nil(t, transport.TLSNextProto)
+	})
+
+	t.Run("Should apply the settings of the JsonData", func(t *testing.T) {
+		transport := &http.Transport{MaxIdleConns: 100, IdleConnTimeout: time.Minute, ForceAttemptHTTP2: true}
+		settings := TransportSettingsFromOptions(sdkhttpclient.Options{
+			CustomOptions: map[string]any{
+				"grafanaData": map[string]any{
+					"maxIdleConns":        float64(500),
+					"maxIdleConnsPerHost": "50",
+					"idleConnTimeout":     float64(30),
+					"enableHTTP2":         false,
+					"dnsRefreshInterval":  float64(60),
+				},
+			},
+		})
+		require.Equal(t, time.Minute, settings.DNSRefreshInterval)
+
+		settings.Apply(transport)
+		require.Equal(t, 500, transport.MaxIdleConns)
+		require.Equal(t, 50, transport.MaxIdleConnsPerHost)
+		require.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+		require.False(t, transport.ForceAttemptHTTP2)
+		require.NotNil(t, transport.TLSNextProto)
+		require.Empty(t, transport.TLSNextProto)
+	})
+
+	t.Run("Should ignore invalid values", func(t *testing.T) {
+		settings := TransportSettingsFromOptions(sdkhttpclient.Options{
+			CustomOptions: map[string]any{
+				"grafanaData": map[string]any{
+					"maxIdleConns":       float64(-1),
+					"idleConnTimeout":    "soon",
+					"enableHTTP2":        "yes",
+					"dnsRefreshInterval": float64(0),
+				},
+			},
+		})
+		require.Equal(t, TransportSettings{}, settings)
+	})
+}
@@ -0,0 +1,201 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+)
+
+var errTunnelClosed = errors.New("ssh tunnel is closed")
+
+// sshClients shares the SSH connections to the bastions between the transports of the data sources,
+// as transports are created again whenever a data source is updated.
+var sshClients = &sshClientPool{clients: map[[sha256.Size]byte]*sshDialer{}, keys: map[string][sha256.Size]byte{}}
+
+type sshClientPool struct {
+	mu      sync.Mutex
+	clients map[[sha256.Size]byte]*sshDialer
+	// keys are the keys of the clients used by each data source, so that a client is closed once no data source
+	// uses it anymore.
+	keys map[string][sha256.Size]byte
+}
+
+func (p *sshClientPool) get(opts *Options, config *ssh.ClientConfig, forward proxy.ContextDialer) *sshDialer {
+	key := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%t",
+		opts.Address, opts.Username, opts.Password, opts.PrivateKey, opts.Passphrase, opts.HostKey, opts.SkipHostKeyVerify)))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if opts.ID != "" {
+		// the settings of the data source changed, its previous client may not be used anymore
+		if previous, ok := p.keys[opts.ID]; ok && previous != key {
+			p.release(opts.ID)
+		}
+		p.keys[opts.ID] = key
+	}
+	if d, ok := p.clients[key]; ok {
+		return d
+	}
+	d := &sshDialer{address: opts.Address, config: config, forward: forward}
+	p.clients[key] = d
+	return d
+}
+
+// remove closes the client of a data source unless another data source uses it.
+func (p *sshClientPool) remove(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.release(id)
+}
+
+func (p *sshClientPool) release(id string) {
+	key, ok := p.keys[id]
+	if !ok {
+		return
+	}
+	delete(p.keys, id)
+	for _, k := range p.keys {
+		if k == key {
+			return
+		}
+	}
+	if d, ok := p.clients[key]; ok {
+		d.close()
+		delete(p.clients, key)
+	}
+}
+
+func sshClientConfig(opts *Options) (*ssh.ClientConfig, error) {
+	config := &ssh.ClientConfig{
+		User:    opts.Username,
+		Timeout: opts.DialTimeout,
+	}
+
+	if opts.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if opts.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(opts.PrivateKey), []byte(opts.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(opts.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ssh private key: %w", err)
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if opts.Password != "" {
+		config.Auth = append(config.Auth, ssh.Password(opts.Password))
+	}
+
+	if opts.SkipHostKeyVerify {
+		// #nosec G106 -- the data source explicitly disabled host key verification
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	} else {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(opts.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ssh host key: %w", err)
+		}
+		config.HostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+	return config, nil
+}
+
+// sshDialer opens connections through an SSH bastion. The connection to the bastion is opened on the
+// first dial and opened again if it breaks.
+type sshDialer struct {
+	address string
+	config  *ssh.ClientConfig
+	forward proxy.ContextDialer
+
+	mu     sync.Mutex
+	client *ssh.Client
+	closed bool
+}
+
+func (d *sshDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := d.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.DialContext(ctx, network, addr)
+	if err == nil || !isBroken(ctx, client) {
+		return conn, err
+	}
+
+	// The connection to the bastion is broken, try once more with a new one
+	d.reset(client)
+	client, clientErr := d.getClient(ctx)
+	if clientErr != nil {
+		return nil, clientErr
+	}
+	return client.DialContext(ctx, network, addr)
+}
+
+func (d *sshDialer) getClient(ctx context.Context) (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, errTunnelClosed
+	}
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	conn, err := d.forward.DialContext(ctx, "tcp", d.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh bastion: %w", err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, d.address, d.config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to open ssh connection: %w", err)
+	}
+	d.client = ssh.NewClient(sshConn, chans, reqs)
+	return d.client, nil
+}
+
+// reset closes the client if it is still the current one.
+func (d *sshDialer) reset(client *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client == client {
+		_ = d.client.Close()
+		d.client = nil
+	}
+}
+
+// close closes the client, the dialer can't open connections anymore.
+func (d *sshDialer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.client != nil {
+		_ = d.client.Close()
+		d.client = nil
+	}
+}
+
+// isBroken tells whether the connection to the bastion is broken. A failed dial of the target doesn't mean
+// it is, the target may be down or refuse the connection, so the bastion is sent a keepalive request: the
+// request fails only if the connection is broken, the bastion replies to unknown requests.
+func isBroken(ctx context.Context, client *ssh.Client) bool {
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		return err != nil
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,81 @@
+package tunnel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	tunnelUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Name:      "datasource_tunnel_up",
+			Help:      "1 if the last connection of a data source through its tunnel succeeded, 0 otherwise",
+		},
+		[]string{"datasource_uid", "tunnel_type"},
+	)
+
+	tunnelDialFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "datasource_tunnel_dial_failures_total",
+			Help:      "A counter for the connections of data sources that failed to open through their tunnel",
+		},
+		[]string{"datasource_uid", "tunnel_type"},
+	)
+)
+
+// Status is the result of the last connection opened through the tunnel of a data source.
+type Status struct {
+	Type            Type       `json:"type"`
+	Connected       bool       `json:"connected"`
+	Error           string     `json:"error,omitempty"`
+	LastConnectedAt *time.Time `json:"lastConnectedAt,omitempty"`
+	LastDialAt      time.Time  `json:"lastDialAt"`
+}
+
+var statuses = struct {
+	sync.RWMutex
+	byID map[string]Status
+}{byID: map[string]Status{}}
+
+// GetStatus returns the status of the tunnel of a data source. It returns false if no
+// connection was opened through the tunnel of the data source yet.
+func GetStatus(id string) (Status, bool) {
+	statuses.RLock()
+	defer statuses.RUnlock()
+	status, ok := statuses.byID[id]
+	return status, ok
+}
+
+func record(id string, tunnelType Type, err error) {
+	if id == "" {
+		return
+	}
+	now := time.Now()
+
+	statuses.Lock()
+	status := statuses.byID[id]
+	status.Type = tunnelType
+	status.LastDialAt = now
+	status.Connected = err == nil
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.LastConnectedAt = &now
+	}
+	statuses.byID[id] = status
+	statuses.Unlock()
+
+	up := tunnelUp.WithLabelValues(id, string(tunnelType))
+	if err != nil {
+		up.Set(0)
+		tunnelDialFailures.WithLabelValues(id, string(tunnelType)).Inc()
+		return
+	}
+	up.Set(1)
+}
@@ -0,0 +1,199 @@
+// Package tunnel routes the outgoing connections of data sources through a SOCKS5 proxy or an SSH bastion,
+// so that data sources in private networks can be queried without exposing them.
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"golang.org/x/net/proxy"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// OptionsKey is the key of the tunnel options in the custom options of the HTTP client options.
+const OptionsKey = "tunnel"
+
+type Type string
+
+const (
+	TypeSOCKS5 Type = "socks5"
+	TypeSSH    Type = "ssh"
+)
+
+var (
+	ErrInvalidType    = errors.New("invalid tunnel type")
+	ErrMissingAddress = errors.New("tunnel address is required")
+	ErrMissingAuth    = errors.New("ssh tunnel requires a private key or a password")
+	ErrMissingHostKey = errors.New("ssh tunnel requires a host key unless host key verification is skipped")
+)
+
+// Options are the tunnel settings of a data source. They are read from the JsonData and the
+// decrypted SecureJsonData of the data source.
+type Options struct {
+	// ID identifies the tunnel in the status reports, usually the data source UID
+	ID   string
+	Type Type
+	// Address is the host:port of the SOCKS5 proxy or the SSH bastion
+	Address  string
+	Username string
+	// Password is read from the tunnelPassword secure field
+	Password string
+	// PrivateKey is the PEM encoded SSH key, read from the tunnelSSHPrivateKey secure field
+	PrivateKey string
+	// Passphrase of the private key, read from the tunnelSSHPassphrase secure field
+	Passphrase string
+	// HostKey is the public key of the SSH bastion in authorized_keys format
+	HostKey           string
+	SkipHostKeyVerify bool
+	DialTimeout       time.Duration
+}
+
+// OptionsFromJSONData returns the tunnel options of a data source, or nil if the tunnel is not enabled.
+func OptionsFromJSONData(id string, jsonData *simplejson.Json, decryptedValues map[string]string) (*Options, error) {
+	if jsonData == nil || !jsonData.Get("enableTunnel").MustBool(false) {
+		return nil, nil
+	}
+
+	opts := &Options{
+		ID:                id,
+		Type:              Type(jsonData.Get("tunnelType").MustString(string(TypeSOCKS5))),
+		Address:           jsonData.Get("tunnelAddress").MustString(),
+		Username:          jsonData.Get("tunnelUsername").MustString(),
+		Password:          decryptedValues["tunnelPassword"],
+		PrivateKey:        decryptedValues["tunnelSSHPrivateKey"],
+		Passphrase:        decryptedValues["tunnelSSHPassphrase"],
+		HostKey:           jsonData.Get("tunnelSSHHostKey").MustString(),
+		SkipHostKeyVerify: jsonData.Get("tunnelSSHSkipHostKeyVerify").MustBool(false),
+		DialTimeout:       sdkhttpclient.DefaultTimeoutOptions.DialTimeout,
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// OptionsFromSettings returns the tunnel options of the data source of a backend plugin, or nil if the tunnel is not enabled.
+func OptionsFromSettings(settings backend.DataSourceInstanceSettings) (*Options, error) {
+	if len(settings.JSONData) == 0 {
+		return nil, nil
+	}
+	jsonData, err := simplejson.NewJson(settings.JSONData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data source json data: %w", err)
+	}
+	return OptionsFromJSONData(settings.UID, jsonData, settings.DecryptedSecureJSONData)
+}
+
+// Configure adds the tunnel options of a backend plugin data source to its HTTP client options,
+// so that the HTTP client provider routes its connections through the tunnel.
+func Configure(settings backend.DataSourceInstanceSettings, clientOpts *sdkhttpclient.Options) error {
+	opts, err := OptionsFromSettings(settings)
+	if err != nil || opts == nil {
+		return err
+	}
+	if clientOpts.CustomOptions == nil {
+		clientOpts.CustomOptions = map[string]any{}
+	}
+	clientOpts.CustomOptions[OptionsKey] = opts
+	return nil
+}
+
+// FromClientOptions returns the tunnel options set in the HTTP client options.
+func FromClientOptions(clientOpts sdkhttpclient.Options) (*Options, bool) {
+	opts, ok := clientOpts.CustomOptions[OptionsKey].(*Options)
+	return opts, ok && opts != nil
+}
+
+func (o *Options) validate() error {
+	if o.Address == "" {
+		return ErrMissingAddress
+	}
+	switch o.Type {
+	case TypeSOCKS5:
+	case TypeSSH:
+		if o.PrivateKey == "" && o.Password == "" {
+			return ErrMissingAuth
+		}
+		if o.HostKey == "" && !o.SkipHostKeyVerify {
+			return ErrMissingHostKey
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidType, o.Type)
+	}
+	return nil
+}
+
+// NewDialer returns a dialer that opens connections through the tunnel. The forward dialer is
+// used to connect to the SOCKS5 proxy or the SSH bastion.
+func NewDialer(opts *Options, forward proxy.ContextDialer) (proxy.ContextDialer, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if forward == nil {
+		forward = &net.Dialer{Timeout: opts.DialTimeout}
+	}
+
+	var dialer proxy.ContextDialer
+	switch opts.Type {
+	case TypeSOCKS5:
+		var auth *proxy.Auth
+		if opts.Username != "" {
+			auth = &proxy.Auth{User: opts.Username, Password: opts.Password}
+		}
+		d, err := proxy.SOCKS5("tcp", opts.Address, auth, forwardDialer{forward})
+		if err != nil {
+			return nil, err
+		}
+		contextDialer, ok := d.(proxy.ContextDialer)
+		if !ok {
+			return nil, fmt.Errorf("socks5 dialer does not support contexts")
+		}
+		dialer = contextDialer
+	case TypeSSH:
+		config, err := sshClientConfig(opts)
+		if err != nil {
+			return nil, err
+		}
+		dialer = sshClients.get(opts, config, forward)
+	}
+
+	return &reportingDialer{id: opts.ID, tunnelType: opts.Type, dialer: dialer}, nil
+}
+
+// Remove closes the connection to the SSH bastion of a data source, unless other data sources use it, and forgets
+// the status of its tunnel. It's called when the data source is updated or deleted.
+func Remove(id string) {
+	sshClients.remove(id)
+
+	statuses.Lock()
+	delete(statuses.byID, id)
+	statuses.Unlock()
+}
+
+// forwardDialer adapts a proxy.ContextDialer to the proxy.Dialer the SOCKS5 dialer expects.
+type forwardDialer struct {
+	proxy.ContextDialer
+}
+
+func (d forwardDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// reportingDialer records the result of every dial in the status of the tunnel.
+type reportingDialer struct {
+	id         string
+	tunnelType Type
+	dialer     proxy.ContextDialer
+}
+
+func (d *reportingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, addr)
+	record(d.id, d.tunnelType, err)
+	return conn, err
+}
@@ -0,0 +1,253 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestOptionsFromJSONData(t *testing.T) {
+	t.Run("returns nil if the tunnel is not enabled", func(t *testing.T) {
+		opts, err := OptionsFromJSONData("ds", simplejson.NewFromAny(map[string]any{"tunnelAddress": "bastion:22"}), nil)
+		require.NoError(t, err)
+		require.Nil(t, opts)
+	})
+
+	t.Run("reads the secrets from the decrypted values", func(t *testing.T) {
+		opts, err := OptionsFromJSONData("ds", simplejson.NewFromAny(map[string]any{
+			"enableTunnel":     true,
+			"tunnelType":       "ssh",
+			"tunnelAddress":    "bastion:22",
+			"tunnelUsername":   "grafana",
+			"tunnelSSHHostKey": "ssh-ed25519 AAAA",
+		}), map[string]string{"tunnelSSHPrivateKey": "key", "tunnelSSHPassphrase": "secret"})
+		require.NoError(t, err)
+		require.Equal(t, TypeSSH, opts.Type)
+		require.Equal(t, "ds", opts.ID)
+		require.Equal(t, "grafana", opts.Username)
+		require.Equal(t, "key", opts.PrivateKey)
+		require.Equal(t, "secret", opts.Passphrase)
+	})
+
+	t.Run("returns nil for the data sources without json data", func(t *testing.T) {
+		opts, err := OptionsFromSettings(backend.DataSourceInstanceSettings{UID: "ds", JSONData: []byte("")})
+		require.NoError(t, err)
+		require.Nil(t, opts)
+	})
+
+	t.Run("validates the settings", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			jsonData map[string]any
+			secrets  map[string]string
+			err      error
+		}{
+			"missing address": {
+				jsonData: map[string]any{"enableTunnel": true},
+				err:      ErrMissingAddress,
+			},
+			"unknown type": {
+				jsonData: map[string]any{"enableTunnel": true, "tunnelType": "vpn", "tunnelAddress": "proxy:1080"},
+				err:      ErrInvalidType,
+			},
+			"ssh without credentials": {
+				jsonData: map[string]any{"enableTunnel": true, "tunnelType": "ssh", "tunnelAddress": "bastion:22", "tunnelSSHSkipHostKeyVerify": true},
+				err:      ErrMissingAuth,
+			},
+			"ssh without host key": {
+				jsonData: map[string]any{"enableTunnel": true, "tunnelType": "ssh", "tunnelAddress": "bastion:22"},
+				secrets:  map[string]string{"tunnelPassword": "secret"},
+				err:      ErrMissingHostKey,
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := OptionsFromJSONData("ds", simplejson.NewFromAny(tc.jsonData), tc.secrets)
+				require.ErrorIs(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestNewDialer(t *testing.T) {
+	t.Run("socks5 tunnel", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("through the tunnel"))
+		}))
+		t.Cleanup(server.Close)
+		proxyAddr := startSOCKS5Server(t)
+
+		dialer, err := NewDialer(&Options{ID: "socks5-ds", Type: TypeSOCKS5, Address: proxyAddr}, nil)
+		require.NoError(t, err)
+
+		client := &http.Client{Transport: &http.Transport{DialContext: dialer.DialContext}}
+		res, err := client.Get(server.URL)
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, "through the tunnel", string(body))
+
+		status, ok := GetStatus("socks5-ds")
+		require.True(t, ok)
+		require.True(t, status.Connected)
+		require.NotNil(t, status.LastConnectedAt)
+	})
+
+	t.Run("reports failed connections", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		dialer, err := NewDialer(&Options{ID: "unreachable-ds", Type: TypeSOCKS5, Address: addr}, nil)
+		require.NoError(t, err)
+		_, err = dialer.DialContext(context.Background(), "tcp", "example.com:80")
+		require.Error(t, err)
+
+		status, ok := GetStatus("unreachable-ds")
+		require.True(t, ok)
+		require.False(t, status.Connected)
+		require.NotEmpty(t, status.Error)
+	})
+
+	t.Run("ssh tunnel with an invalid host key", func(t *testing.T) {
+		_, err := NewDialer(&Options{Type: TypeSSH, Address: "bastion:22", Password: "secret", HostKey: "not a key"}, nil)
+		require.ErrorContains(t, err, "host key")
+	})
+}
+
+func TestSSHDialer(t *testing.T) {
+	t.Run("keeps the connection to the bastion when the target can't be reached", func(t *testing.T) {
+		addr := startSSHServer(t)
+		d := &sshDialer{address: addr, config: &ssh.ClientConfig{User: "grafana", HostKeyCallback: ssh.InsecureIgnoreHostKey()}, forward: &net.Dialer{}}
+		t.Cleanup(d.close)
+
+		_, err := d.DialContext(context.Background(), "tcp", "10.0.0.1:80")
+		require.Error(t, err)
+		client := d.client
+		require.NotNil(t, client)
+
+		_, err = d.DialContext(context.Background(), "tcp", "10.0.0.1:80")
+		require.Error(t, err)
+		require.Same(t, client, d.client)
+	})
+
+	t.Run("closes the clients no data source uses anymore", func(t *testing.T) {
+		pool := &sshClientPool{clients: map[[sha256.Size]byte]*sshDialer{}, keys: map[string][sha256.Size]byte{}}
+		shared := pool.get(&Options{ID: "a", Address: "bastion:22", Password: "secret"}, nil, nil)
+		require.Same(t, shared, pool.get(&Options{ID: "b", Address: "bastion:22", Password: "secret"}, nil, nil))
+
+		changed := pool.get(&Options{ID: "a", Address: "bastion:22", Password: "changed"}, nil, nil)
+		require.NotSame(t, shared, changed)
+		require.False(t, shared.closed, "the client is still used by b")
+
+		pool.remove("b")
+		require.True(t, shared.closed)
+		_, err := shared.DialContext(context.Background(), "tcp", "10.0.0.1:80")
+		require.ErrorIs(t, err, errTunnelClosed)
+
+		pool.remove("a")
+		require.True(t, changed.closed)
+		require.Empty(t, pool.clients)
+	})
+}
+
+// startSSHServer starts an SSH server without authentication that rejects the connections to the targets.
+func startSSHServer(t *testing.T) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					_ = ch.Reject(ssh.ConnectionFailed, "connection refused")
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startSOCKS5Server starts a SOCKS5 server without authentication that only supports CONNECT to IPv4 addresses.
+func startSOCKS5Server(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveSOCKS5(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+
+	request := make([]byte, 10)
+	if _, err := io.ReadFull(conn, request); err != nil || request[3] != 1 {
+		return
+	}
+	addr := net.JoinHostPort(net.IP(request[4:8]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(request[8:10]))))
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer func() { _ = target.Close() }()
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
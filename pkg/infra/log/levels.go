@@ -0,0 +1,119 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+)
+
+// ErrUnknownLevel is returned when setting a level which doesn't exist.
+var ErrUnknownLevel = errors.New("unknown log level")
+
+// levelOverride is the level of a named logger set at runtime.
+type levelOverride struct {
+	name    string
+	option  level.Option
+	expires time.Time
+	timer   *time.Timer
+}
+
+// LoggerLevel is the level of a named logger.
+type LoggerLevel struct {
+	Name string `json:"name"`
+	// Level is the level set at runtime, the loggers without one use the levels of the configuration.
+	Level string `json:"level,omitempty"`
+	// Expires is when the level set at runtime is reset, it's kept until reset when empty.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// SetLevel sets the level of a named logger at runtime, for all the targets, until it's reset. A positive ttl
+// resets it automatically after the duration. The logger doesn't have to exist yet.
+func SetLevel(name, levelName string, ttl time.Duration) error {
+	return root.setLevel(name, levelName, ttl)
+}
+
+// ResetLevel resets the level of a named logger to the levels of the configuration. It returns false if the level
+// wasn't set at runtime.
+func ResetLevel(name string) bool {
+	return root.resetLevel(name, nil)
+}
+
+// Levels returns the named loggers, and the loggers whose level was set at runtime, sorted by name.
+func Levels() []LoggerLevel {
+	return root.levels()
+}
+
+func (lm *logManager) setLevel(name, levelName string, ttl time.Duration) error {
+	levelName = strings.ToLower(levelName)
+	option, ok := logLevels[levelName]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownLevel, levelName)
+	}
+
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	override := &levelOverride{name: levelName, option: option}
+	if ttl > 0 {
+		override.expires = now().Add(ttl)
+		override.timer = time.AfterFunc(ttl, func() {
+			lm.resetLevel(name, override)
+		})
+	}
+	if previous, exists := lm.overrides[name]; exists && previous.timer != nil {
+		previous.timer.Stop()
+	}
+	lm.overrides[name] = override
+	lm.swapNamed(name)
+	return nil
+}
+
+// resetLevel removes the level set at runtime for a named logger. When expired is set, the level is only removed if
+// it wasn't set again since.
+func (lm *logManager) resetLevel(name string, expired *levelOverride) bool {
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	override, exists := lm.overrides[name]
+	if !exists || (expired != nil && override != expired) {
+		return false
+	}
+	if override.timer != nil {
+		override.timer.Stop()
+	}
+	delete(lm.overrides, name)
+	lm.swapNamed(name)
+	return true
+}
+
+func (lm *logManager) levels() []LoggerLevel {
+	lm.mutex.RLock()
+	defer lm.mutex.RUnlock()
+
+	names := make(map[string]struct{}, len(lm.loggersByName))
+	for name := range lm.loggersByName {
+		names[name] = struct{}{}
+	}
+	for name := range lm.overrides {
+		names[name] = struct{}{}
+	}
+
+	levels := make([]LoggerLevel, 0, len(names))
+	for name := range names {
+		loggerLevel := LoggerLevel{Name: name}
+		if override, exists := lm.overrides[name]; exists {
+			loggerLevel.Level = override.name
+			if !override.expires.IsZero() {
+				expires := override.expires
+				loggerLevel.Expires = &expires
+			}
+		}
+		levels = append(levels, loggerLevel)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Name < levels[j].Name })
+	return levels
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -67,13 +68,16 @@ type logManager struct {
 	*ConcreteLogger
 	loggersByName map[string]*ConcreteLogger
 	logFilters    []logWithFilters
-	mutex         sync.RWMutex
+	// overrides are the levels of the named loggers set at runtime, they take precedence over the configuration.
+	overrides map[string]*levelOverride
+	mutex     sync.RWMutex
 }
 
 func newManager(logger gokitlog.Logger) *logManager {
 	return &logManager{
 		ConcreteLogger: newConcreteLogger(logger),
 		loggersByName:  map[string]*ConcreteLogger{},
+		overrides:      map[string]*levelOverride{},
 	}
 }
 
@@ -96,19 +100,38 @@ func (lm *logManager) initialize(loggers []logWithFilters) {
 	sort.Strings(loggersByName)
 
 	for _, name := range loggersByName {
-		ctxLoggers := make([]gokitlog.Logger, len(loggers))
-
-		for index, logger := range loggers {
-			ctxLogger := gokitlog.With(logger.val, lm.loggersByName[name].ctx...)
-			if filterLevel, exists := logger.filters[name]; !exists {
-				ctxLoggers[index] = level.NewFilter(ctxLogger, logger.maxLevel)
-			} else {
-				ctxLoggers[index] = level.NewFilter(ctxLogger, filterLevel)
-			}
+		lm.swapNamed(name)
+	}
+}
+
+// namedLogger returns the logger writing the entries of a named logger to the targets, filtered by the level of
+// the logger for each target and sampled.
+func (lm *logManager) namedLogger(name string) *compositeLogger {
+	compositeLogger := newCompositeLogger()
+	for _, logWithFilter := range lm.logFilters {
+		logger := logWithFilter.val
+		if rate := logWithFilter.sampling[name]; rate > 1 {
+			logger = newSamplingLogger(logger, rate)
 		}
 
-		lm.loggersByName[name].Swap(&compositeLogger{loggers: ctxLoggers})
+		filterLevel, ok := logWithFilter.filters[name]
+		if override, exists := lm.overrides[name]; exists {
+			filterLevel = override.option
+		} else if !ok {
+			filterLevel = logWithFilter.maxLevel
+		}
+		compositeLogger.loggers = append(compositeLogger.loggers, level.NewFilter(logger, filterLevel))
 	}
+	return compositeLogger
+}
+
+// swapNamed rebuilds a named logger after its level changed.
+func (lm *logManager) swapNamed(name string) {
+	logger, exists := lm.loggersByName[name]
+	if !exists || len(lm.logFilters) == 0 {
+		return
+	}
+	logger.Swap(gokitlog.With(lm.namedLogger(name), logger.ctx...))
 }
 
 func (lm *logManager) New(ctx ...any) *ConcreteLogger {
@@ -145,19 +168,7 @@ func (lm *logManager) New(ctx ...any) *ConcreteLogger {
 		return ctxLogger
 	}
 
-	compositeLogger := newCompositeLogger()
-	for _, logWithFilter := range lm.logFilters {
-		filterLevel, ok := logWithFilter.filters[loggerName]
-		if ok {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, filterLevel)
-		} else {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, logWithFilter.maxLevel)
-		}
-
-		compositeLogger.loggers = append(compositeLogger.loggers, logWithFilter.val)
-	}
-
-	ctxLogger := newConcreteLogger(compositeLogger, ctx...)
+	ctxLogger := newConcreteLogger(lm.namedLogger(loggerName), ctx...)
 	lm.loggersByName[loggerName] = ctxLogger
 	return ctxLogger
 }
@@ -354,6 +365,26 @@ func getFilters(filterStrArray []string) map[string]level.Option {
 	return filterMap
 }
 
+// the sampling is composed with logger name and sample rate
+func getSampling(samplingStrArray []string) map[string]uint64 {
+	samplingMap := make(map[string]uint64)
+
+	for _, samplingStr := range samplingStrArray {
+		parts := strings.Split(strings.TrimSpace(samplingStr), ":")
+		if len(parts) != 2 {
+			continue
+		}
+		rate, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil || rate == 0 {
+			_ = level.Error(root).Log("Invalid log sample rate", "logger", parts[0], "rate", parts[1])
+			continue
+		}
+		samplingMap[parts[0]] = rate
+	}
+
+	return samplingMap
+}
+
 func Stack(skip int) string {
 	call := stack.Caller(skip)
 	s := stack.Trace().TrimBelow(call).TrimRuntime()
@@ -410,6 +441,7 @@ func Close() error {
 		}
 	}
 	loggersToClose = make([]DisposableHandler, 0)
+	loggersToReload = make([]ReloadableHandler, 0)
 
 	return err
 }
@@ -426,9 +458,13 @@ func Reload() error {
 }
 
 type logWithFilters struct {
+	// mode is the log mode of the target, console, file or syslog.
+	mode     string
 	val      gokitlog.Logger
 	filters  map[string]level.Option
 	maxLevel level.Option
+	// sampling is the sample rate of the high-volume loggers, by logger name.
+	sampling map[string]uint64
 }
 
 func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
@@ -441,9 +477,6 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 		return nil
 	}
 
-	defaultLevelName, _ := getLogLevelFromConfig("log", "info", cfg)
-	defaultFilters := getFilters(util.SplitString(cfg.Section("log").Key("filters").String()))
-
 	configLoggers := make([]logWithFilters, 0, len(modes))
 	for _, mode := range modes {
 		mode = strings.TrimSpace(mode)
@@ -453,13 +486,9 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 			return fmt.Errorf("failed to get config section log. %s: %w", mode, err)
 		}
 
-		// Log level.
-		_, leveloption := getLogLevelFromConfig("log."+mode, defaultLevelName, cfg)
-		modeFilters := getFilters(util.SplitString(sec.Key("filters").String()))
-
 		format := getLogFormat(sec.Key("format").MustString(""))
 
-		var handler logWithFilters
+		handler := logWithFilters{mode: mode}
 
 		switch mode {
 		case "console":
@@ -496,16 +525,7 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 			panic(fmt.Sprintf("Handler is uninitialized for mode %q", mode))
 		}
 
-		// join default filters and mode filters together
-		for key, value := range defaultFilters {
-			if _, exist := modeFilters[key]; !exist {
-				modeFilters[key] = value
-			}
-		}
-
-		handler.filters = modeFilters
-		handler.maxLevel = leveloption
-
+		handler.maxLevel, handler.filters, handler.sampling = readLevels(cfg, mode)
 		configLoggers = append(configLoggers, handler)
 	}
 	if len(configLoggers) > 0 {
@@ -515,6 +535,50 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 	return nil
 }
 
+// ReloadLevels applies the levels, the filters and the sampling of the logging configuration to the log targets,
+// without opening them again. The other changes of the logging configuration require a restart.
+func ReloadLevels(cfg *ini.File) {
+	root.mutex.RLock()
+	loggers := make([]logWithFilters, 0, len(root.logFilters))
+	for _, logger := range root.logFilters {
+		logger.maxLevel, logger.filters, logger.sampling = readLevels(cfg, logger.mode)
+		loggers = append(loggers, logger)
+	}
+	root.mutex.RUnlock()
+
+	if len(loggers) > 0 {
+		root.initialize(loggers)
+	}
+}
+
+// readLevels reads the level, the filters and the sampling of a log mode, joined with the defaults of the [log]
+// section.
+func readLevels(cfg *ini.File, mode string) (level.Option, map[string]level.Option, map[string]uint64) {
+	defaultLevelName, _ := getLogLevelFromConfig("log", "info", cfg)
+	defaultFilters := getFilters(util.SplitString(cfg.Section("log").Key("filters").String()))
+	defaultSampling := getSampling(util.SplitString(cfg.Section("log").Key("sampling").String()))
+
+	sec := cfg.Section("log." + mode)
+	_, levelOption := getLogLevelFromConfig("log."+mode, defaultLevelName, cfg)
+	modeFilters := getFilters(util.SplitString(sec.Key("filters").String()))
+	modeSampling := getSampling(util.SplitString(sec.Key("sampling").String()))
+
+	// join default filters and mode filters together
+	for key, value := range defaultFilters {
+		if _, exist := modeFilters[key]; !exist {
+			modeFilters[key] = value
+		}
+	}
+
+	for key, value := range defaultSampling {
+		if _, exist := modeSampling[key]; !exist {
+			modeSampling[key] = value
+		}
+	}
+
+	return levelOption, modeFilters, modeSampling
+}
+
 // SetupConsoleLogger setup Grafana console logger with provided level.
 func SetupConsoleLogger(level string) error {
 	iniFile := ini.Empty()
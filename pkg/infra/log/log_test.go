@@ -10,6 +10,7 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
 
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -197,6 +198,145 @@ func TestWithSuffix_appendsContext(t *testing.T) {
 	})
 }
 
+func TestSetLevel(t *testing.T) {
+	scenario := newLoggerScenario(t)
+	logger := gokitlog.LoggerFunc(func(i ...any) error {
+		scenario.loggedArgs = append(scenario.loggedArgs, i)
+		return nil
+	})
+	root.initialize([]logWithFilters{{
+		val:      logger,
+		filters:  map[string]level.Option{"filtered": level.AllowDebug()},
+		maxLevel: level.AllowInfo(),
+	}})
+
+	ls := New("test")
+	child := ls.New("k1", "v1")
+	filtered := New("filtered")
+
+	t.Run("level set at runtime applies to the logger and its children", func(t *testing.T) {
+		scenario.loggedArgs = [][]any{}
+		require.NoError(t, SetLevel("test", "debug", 0))
+		ls.Debug("hello")
+		child.Debug("hello child")
+
+		require.Len(t, scenario.loggedArgs, 2)
+		scenario.ValidateLineEquality(t, 1, []any{
+			"logger", "test",
+			"k1", "v1",
+			"t", scenario.mockedTime,
+			level.Key(), level.DebugValue(),
+			"msg", "hello child",
+		})
+	})
+
+	t.Run("level set at runtime takes precedence over the filters", func(t *testing.T) {
+		scenario.loggedArgs = [][]any{}
+		require.NoError(t, SetLevel("filtered", "error", 0))
+		filtered.Info("hello")
+		filtered.Error("hello error")
+		require.Len(t, scenario.loggedArgs, 1)
+	})
+
+	t.Run("level set at runtime applies to the loggers created later", func(t *testing.T) {
+		scenario.loggedArgs = [][]any{}
+		require.NoError(t, SetLevel("later", "error", 0))
+		New("later").Warn("hello")
+		require.Empty(t, scenario.loggedArgs)
+	})
+
+	t.Run("unknown level is rejected", func(t *testing.T) {
+		require.ErrorIs(t, SetLevel("test", "verbose", 0), ErrUnknownLevel)
+	})
+
+	t.Run("levels lists the named loggers", func(t *testing.T) {
+		require.Equal(t, []LoggerLevel{
+			{Name: "filtered", Level: "error"},
+			{Name: "later", Level: "error"},
+			{Name: "test", Level: "debug"},
+		}, Levels())
+	})
+
+	t.Run("reset level uses the configuration again", func(t *testing.T) {
+		scenario.loggedArgs = [][]any{}
+		require.True(t, ResetLevel("test"))
+		require.False(t, ResetLevel("test"))
+		ls.Debug("hello")
+		child.Debug("hello child")
+		require.Empty(t, scenario.loggedArgs)
+	})
+
+	t.Run("level set with a duration is reset when it expires", func(t *testing.T) {
+		require.NoError(t, SetLevel("test", "debug", time.Millisecond))
+		require.Eventually(t, func() bool {
+			for _, l := range Levels() {
+				if l.Name == "test" {
+					return l.Level == ""
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestSampling(t *testing.T) {
+	scenario := newLoggerScenario(t)
+	logger := gokitlog.LoggerFunc(func(i ...any) error {
+		scenario.loggedArgs = append(scenario.loggedArgs, i)
+		return nil
+	})
+	root.initialize([]logWithFilters{{
+		val:      logger,
+		maxLevel: level.AllowInfo(),
+		sampling: map[string]uint64{"sampled": 3},
+	}})
+
+	ls := New("sampled")
+	for i := 0; i < 6; i++ {
+		ls.Info("hello")
+		ls.Debug("filtered before sampling")
+	}
+	require.Len(t, scenario.loggedArgs, 2, "1 in 3 info entries is logged")
+
+	ls.Warn("warning")
+	ls.Error("error")
+	require.Len(t, scenario.loggedArgs, 4, "the warnings and errors are not sampled")
+
+	New("other").Info("hello")
+	require.Len(t, scenario.loggedArgs, 5)
+}
+
+func TestReloadLevels(t *testing.T) {
+	scenario := newLoggerScenario(t)
+	logger := gokitlog.LoggerFunc(func(i ...any) error {
+		scenario.loggedArgs = append(scenario.loggedArgs, i)
+		return nil
+	})
+	root.initialize([]logWithFilters{{
+		mode:     "console",
+		val:      logger,
+		maxLevel: level.AllowInfo(),
+	}})
+	ls := New("reloaded")
+	other := New("other")
+
+	cfg := ini.Empty()
+	cfg.Section("log").Key("level").SetValue("debug")
+	cfg.Section("log.console").Key("filters").SetValue("other:error")
+	ReloadLevels(cfg)
+
+	ls.Debug("hello")
+	other.Warn("filtered")
+	require.Len(t, scenario.loggedArgs, 1, "the levels and the filters are reloaded")
+	require.Len(t, root.logFilters, 1)
+	require.Equal(t, "console", root.logFilters[0].mode)
+}
+
+func TestGetSampling(t *testing.T) {
+	sampling := getSampling(util.SplitString(`ngalert.scheduler:10 live:100 invalid:0 invalid:x missing`))
+	require.Equal(t, map[string]uint64{"ngalert.scheduler": 10, "live": 100}, sampling)
+}
+
 func TestGetFilters(t *testing.T) {
 	t.Run("Parsing filters on single line with only space should return expected result", func(t *testing.T) {
 		filter := `   `
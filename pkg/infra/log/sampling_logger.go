@@ -0,0 +1,37 @@
+package log
+
+import (
+	"sync/atomic"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// samplingLogger logs 1 in rate of the entries of a high-volume logger below the warning level. The warnings and
+// errors are always logged.
+type samplingLogger struct {
+	logger gokitlog.Logger
+	rate   uint64
+	count  atomic.Uint64
+}
+
+func newSamplingLogger(logger gokitlog.Logger, rate uint64) *samplingLogger {
+	return &samplingLogger{logger: logger, rate: rate}
+}
+
+func (l *samplingLogger) Log(keyvals ...any) error {
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if keyvals[i] != level.Key() {
+			continue
+		}
+		if v := keyvals[i+1]; v == level.WarnValue() || v == level.ErrorValue() {
+			return l.logger.Log(keyvals...)
+		}
+		break
+	}
+
+	if (l.count.Add(1)-1)%l.rate != 0 {
+		return nil
+	}
+	return l.logger.Log(keyvals...)
+}
@@ -93,6 +93,18 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingQueueWaiting is a metric gauge for render jobs waiting for a free renderer slot
+	MRenderingQueueWaiting *prometheus.GaugeVec
+
+	// MRenderingQueueWaitDuration is a metric histogram for the time render jobs spend waiting in the queue
+	MRenderingQueueWaitDuration *prometheus.HistogramVec
+
+	// MRenderingRetriesTotal is a metric counter for retried render requests
+	MRenderingRetriesTotal *prometheus.CounterVec
+
+	// MRemoteCacheUsage is a metric counter for the hits and misses of the named caches
+	MRemoteCacheUsage *prometheus.CounterVec
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
 
@@ -436,6 +448,31 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingQueueWaiting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rendering_queue_waiting",
+		Help:      "number of render jobs waiting for a free renderer slot",
+		Namespace: ExporterName,
+	}, []string{"priority"})
+
+	MRenderingQueueWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "rendering_queue_wait_duration_seconds",
+		Help:      "histogram of the time render jobs spend waiting in the rendering queue",
+		Namespace: ExporterName,
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"priority", "status"})
+
+	MRenderingRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "rendering_retries_total",
+		Help:      "total number of retried render requests",
+		Namespace: ExporterName,
+	}, []string{"type"})
+
+	MRemoteCacheUsage = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "remote_cache_usage_total",
+		Help:      "named cache hit/miss/error",
+		Namespace: ExporterName,
+	}, []string{"cache", "status"})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -742,6 +779,10 @@ func initMetricVars(reg prometheus.Registerer) {
 		MRenderingSummary,
 		MRenderingUserLookupSummary,
 		MRenderingQueue,
+		MRenderingQueueWaiting,
+		MRenderingQueueWaitDuration,
+		MRenderingRetriesTotal,
+		MRemoteCacheUsage,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAccessSearchPermissionsSummary,
@@ -0,0 +1,281 @@
+// Package otlpbridge provides a bridge to push Prometheus metrics to an OpenTelemetry collector, or any backend
+// accepting OTLP over HTTP.
+package otlpbridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	defaultInterval = 15 * time.Second
+	defaultTimeout  = 10 * time.Second
+	scopeName       = "github.com/grafana/grafana"
+	// maxErrorBodySize is the size of the response body logged when a push fails.
+	maxErrorBodySize = 1024
+)
+
+// Config defines the OTLP bridge config.
+type Config struct {
+	// Endpoint is the URL the metrics are pushed to, e.g. http://localhost:4318/v1/metrics. Required.
+	Endpoint string
+
+	// Headers are added to the requests, e.g. for authentication.
+	Headers map[string]string
+
+	// ResourceAttributes describe the Grafana server in the pushed metrics, e.g. service.name.
+	ResourceAttributes map[string]string
+
+	// ScopeVersion is the version of the instrumentation scope of the metrics.
+	ScopeVersion string
+
+	// Interval is the interval between pushes. Defaults to 15 seconds.
+	Interval time.Duration
+
+	// Timeout is the timeout of each push. Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// Gatherer is the Gatherer the metrics are read from. Defaults to prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+
+	// Logger is the logger the errors are written to. Defaults to the "metrics.otlp" logger.
+	Logger log.Logger
+}
+
+// Bridge pushes the metrics of a Prometheus Gatherer to an OTLP endpoint.
+type Bridge struct {
+	endpoint           string
+	headers            map[string]string
+	resourceAttributes map[string]string
+	scopeVersion       string
+	interval           time.Duration
+	client             *http.Client
+	g                  prometheus.Gatherer
+	logger             log.Logger
+
+	// start is the start time of the cumulative metrics, the metrics are read since Grafana started.
+	start time.Time
+	now   func() time.Time
+}
+
+// NewBridge returns a bridge pushing to the configured endpoint.
+func NewBridge(c *Config) (*Bridge, error) {
+	if c.Endpoint == "" {
+		return nil, errors.New("missing endpoint")
+	}
+
+	b := &Bridge{
+		endpoint:           c.Endpoint,
+		headers:            c.Headers,
+		resourceAttributes: c.ResourceAttributes,
+		scopeVersion:       c.ScopeVersion,
+		interval:           c.Interval,
+		client:             &http.Client{Timeout: c.Timeout},
+		g:                  c.Gatherer,
+		logger:             c.Logger,
+		now:                time.Now,
+	}
+	if b.interval <= 0 {
+		b.interval = defaultInterval
+	}
+	if b.client.Timeout <= 0 {
+		b.client.Timeout = defaultTimeout
+	}
+	if b.g == nil {
+		b.g = prometheus.DefaultGatherer
+	}
+	if b.logger == nil {
+		b.logger = log.New("metrics.otlp")
+	}
+	b.start = b.now()
+
+	return b, nil
+}
+
+// Run pushes the metrics at the configured interval until the context is done.
+func (b *Bridge) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Push(ctx); err != nil {
+				b.logger.Error("Failed to push metrics to OTLP endpoint", "endpoint", b.endpoint, "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Push gathers the metrics and pushes them to the endpoint. The metrics gathered are pushed even if the Gatherer
+// returned an error, like the Prometheus endpoint serves them.
+func (b *Bridge) Push(ctx context.Context) error {
+	mfs, err := b.g.Gather()
+	if err != nil {
+		if len(mfs) == 0 {
+			return fmt.Errorf("failed to gather metrics: %w", err)
+		}
+		b.logger.Warn("Error gathering metrics, pushing the gathered metrics", "error", err)
+	}
+
+	body, err := pmetricotlp.NewExportRequestFromMetrics(b.convert(mfs)).MarshalProto()
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range b.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			b.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, msg)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// convert converts the Prometheus metric families to OTLP metrics. The counters are converted to monotonic
+// cumulative sums, the gauges and untyped metrics to gauges, the classic histograms to explicit bucket histograms
+// and the summaries to summaries.
+func (b *Bridge) convert(mfs []*dto.MetricFamily) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	for name, value := range b.resourceAttributes {
+		rm.Resource().Attributes().PutStr(name, value)
+	}
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(scopeName)
+	sm.Scope().SetVersion(b.scopeVersion)
+
+	now := b.now()
+	start := pcommon.NewTimestampFromTime(b.start)
+	for _, mf := range mfs {
+		if len(mf.GetMetric()) == 0 {
+			continue
+		}
+
+		m := pmetric.NewMetric()
+		m.SetName(mf.GetName())
+		m.SetDescription(mf.GetHelp())
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			sum := m.SetEmptySum()
+			sum.SetIsMonotonic(true)
+			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			for _, metric := range mf.GetMetric() {
+				dp := sum.DataPoints().AppendEmpty()
+				setAttributes(dp.Attributes(), metric)
+				dp.SetStartTimestamp(start)
+				dp.SetTimestamp(timestamp(metric, now))
+				dp.SetDoubleValue(metric.GetCounter().GetValue())
+			}
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			gauge := m.SetEmptyGauge()
+			for _, metric := range mf.GetMetric() {
+				dp := gauge.DataPoints().AppendEmpty()
+				setAttributes(dp.Attributes(), metric)
+				dp.SetTimestamp(timestamp(metric, now))
+				if mf.GetType() == dto.MetricType_GAUGE {
+					dp.SetDoubleValue(metric.GetGauge().GetValue())
+				} else {
+					dp.SetDoubleValue(metric.GetUntyped().GetValue())
+				}
+			}
+		case dto.MetricType_HISTOGRAM:
+			histogram := m.SetEmptyHistogram()
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			for _, metric := range mf.GetMetric() {
+				dp := histogram.DataPoints().AppendEmpty()
+				setAttributes(dp.Attributes(), metric)
+				dp.SetStartTimestamp(start)
+				dp.SetTimestamp(timestamp(metric, now))
+				dp.SetCount(metric.GetHistogram().GetSampleCount())
+				dp.SetSum(metric.GetHistogram().GetSampleSum())
+				bounds, counts := buckets(metric.GetHistogram())
+				dp.ExplicitBounds().FromRaw(bounds)
+				dp.BucketCounts().FromRaw(counts)
+			}
+		case dto.MetricType_SUMMARY:
+			summary := m.SetEmptySummary()
+			for _, metric := range mf.GetMetric() {
+				dp := summary.DataPoints().AppendEmpty()
+				setAttributes(dp.Attributes(), metric)
+				dp.SetStartTimestamp(start)
+				dp.SetTimestamp(timestamp(metric, now))
+				dp.SetCount(metric.GetSummary().GetSampleCount())
+				dp.SetSum(metric.GetSummary().GetSampleSum())
+				for _, q := range metric.GetSummary().GetQuantile() {
+					quantile := dp.QuantileValues().AppendEmpty()
+					quantile.SetQuantile(q.GetQuantile())
+					quantile.SetValue(q.GetValue())
+				}
+			}
+		default:
+			continue
+		}
+		m.MoveTo(sm.Metrics().AppendEmpty())
+	}
+	return md
+}
+
+// buckets returns the explicit bounds and the counts of the buckets of a histogram. The Prometheus buckets are
+// cumulative and may end with +Inf, the OTLP counts are per bucket and have an implicit last bucket up to +Inf.
+func buckets(h *dto.Histogram) ([]float64, []uint64) {
+	bounds := make([]float64, 0, len(h.GetBucket()))
+	counts := make([]uint64, 0, len(h.GetBucket())+1)
+	var previous uint64
+	for _, bucket := range h.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			break
+		}
+		bounds = append(bounds, bucket.GetUpperBound())
+		counts = append(counts, bucket.GetCumulativeCount()-previous)
+		previous = bucket.GetCumulativeCount()
+	}
+	return bounds, append(counts, h.GetSampleCount()-previous)
+}
+
+func setAttributes(attributes pcommon.Map, metric *dto.Metric) {
+	for _, label := range metric.GetLabel() {
+		attributes.PutStr(label.GetName(), label.GetValue())
+	}
+}
+
+func timestamp(metric *dto.Metric, now time.Time) pcommon.Timestamp {
+	if metric.TimestampMs != nil {
+		return pcommon.NewTimestampFromTime(time.UnixMilli(metric.GetTimestampMs()))
+	}
+	return pcommon.NewTimestampFromTime(now)
+}
@@ -0,0 +1,113 @@
+package otlpbridge
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+func TestBridge_Push(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "grafana_requests_total", Help: "requests"}, []string{"method"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "grafana_active_users"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "grafana_duration_seconds", Buckets: []float64{1, 5}})
+	reg.MustRegister(counter, gauge, histogram)
+
+	counter.WithLabelValues("GET").Add(3)
+	gauge.Set(7)
+	histogram.Observe(0.5)
+	histogram.Observe(2)
+	histogram.Observe(10)
+
+	var received pmetric.Metrics
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := pmetricotlp.NewExportRequest()
+		assert.NoError(t, req.UnmarshalProto(body))
+		received = req.Metrics()
+	}))
+	t.Cleanup(server.Close)
+
+	b, err := NewBridge(&Config{
+		Endpoint:           server.URL,
+		Headers:            map[string]string{"Authorization": "Bearer token"},
+		ResourceAttributes: map[string]string{"service.name": "grafana"},
+		Gatherer:           reg,
+	})
+	require.NoError(t, err)
+	b.now = func() time.Time { return time.Unix(100, 0) }
+
+	require.NoError(t, b.Push(context.Background()))
+	require.Equal(t, "Bearer token", headers.Get("Authorization"))
+	require.Equal(t, "application/x-protobuf", headers.Get("Content-Type"))
+
+	require.Equal(t, 1, received.ResourceMetrics().Len())
+	rm := received.ResourceMetrics().At(0)
+	serviceName, ok := rm.Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	require.Equal(t, "grafana", serviceName.Str())
+
+	metrics := map[string]pmetric.Metric{}
+	sm := rm.ScopeMetrics().At(0)
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		metrics[sm.Metrics().At(i).Name()] = sm.Metrics().At(i)
+	}
+	require.Len(t, metrics, 3)
+
+	t.Run("counters are converted to cumulative sums", func(t *testing.T) {
+		m := metrics["grafana_requests_total"]
+		require.Equal(t, pmetric.MetricTypeSum, m.Type())
+		require.True(t, m.Sum().IsMonotonic())
+		require.Equal(t, pmetric.AggregationTemporalityCumulative, m.Sum().AggregationTemporality())
+		dp := m.Sum().DataPoints().At(0)
+		require.Equal(t, 3.0, dp.DoubleValue())
+		method, ok := dp.Attributes().Get("method")
+		require.True(t, ok)
+		require.Equal(t, "GET", method.Str())
+		require.Equal(t, time.Unix(100, 0).UTC(), dp.Timestamp().AsTime())
+	})
+
+	t.Run("gauges are converted to gauges", func(t *testing.T) {
+		m := metrics["grafana_active_users"]
+		require.Equal(t, pmetric.MetricTypeGauge, m.Type())
+		require.Equal(t, 7.0, m.Gauge().DataPoints().At(0).DoubleValue())
+	})
+
+	t.Run("histograms are converted to explicit bucket histograms", func(t *testing.T) {
+		m := metrics["grafana_duration_seconds"]
+		require.Equal(t, pmetric.MetricTypeHistogram, m.Type())
+		dp := m.Histogram().DataPoints().At(0)
+		require.Equal(t, uint64(3), dp.Count())
+		require.Equal(t, 12.5, dp.Sum())
+		require.Equal(t, []float64{1, 5}, dp.ExplicitBounds().AsRaw())
+		require.Equal(t, []uint64{1, 1, 1}, dp.BucketCounts().AsRaw())
+	})
+}
+
+func TestBridge_PushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	b, err := NewBridge(&Config{Endpoint: server.URL, Gatherer: prometheus.NewRegistry()})
+	require.NoError(t, err)
+	require.ErrorContains(t, b.Push(context.Background()), "unexpected status code 401")
+}
+
+func TestNewBridge(t *testing.T) {
+	_, err := NewBridge(&Config{})
+	require.Error(t, err)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics/graphitebridge"
+	"github.com/grafana/grafana/pkg/infra/metrics/otlpbridge"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -26,12 +27,13 @@ func (lw *logWrapper) Println(v ...any) {
 	lw.logger.Info("graphite metric bridge", v...)
 }
 
-func ProvideService(cfg *setting.Cfg, reg prometheus.Registerer) (*InternalMetricsService, error) {
+func ProvideService(cfg *setting.Cfg, reg prometheus.Registerer, gatherer prometheus.Gatherer) (*InternalMetricsService, error) {
 	initMetricVars(reg)
 	initFrontendMetrics(reg)
 
 	s := &InternalMetricsService{
-		Cfg: cfg,
+		Cfg:      cfg,
+		gatherer: gatherer,
 	}
 	return s, s.readSettings()
 }
@@ -41,6 +43,9 @@ type InternalMetricsService struct {
 
 	intervalSeconds int64
 	graphiteCfg     *graphitebridge.Config
+	otlpCfg         *otlpbridge.Config
+	// gatherer gathers the metrics served by the Prometheus endpoint, which are also pushed by the OTLP bridge.
+	gatherer prometheus.Gatherer
 }
 
 func (im *InternalMetricsService) Run(ctx context.Context) error {
@@ -54,6 +59,16 @@ func (im *InternalMetricsService) Run(ctx context.Context) error {
 		}
 	}
 
+	// Start OTLP Bridge
+	if im.otlpCfg != nil {
+		bridge, err := otlpbridge.NewBridge(im.otlpCfg)
+		if err != nil {
+			metricsLogger.Error("failed to create OTLP bridge", "error", err)
+		} else {
+			go bridge.Run(ctx)
+		}
+	}
+
 	MInstanceStart.Inc()
 
 	<-ctx.Done()
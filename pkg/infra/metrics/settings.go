@@ -8,6 +8,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/infra/metrics/graphitebridge"
+	"github.com/grafana/grafana/pkg/infra/metrics/otlpbridge"
 )
 
 func (im *InternalMetricsService) readSettings() error {
@@ -22,6 +23,10 @@ func (im *InternalMetricsService) readSettings() error {
 		return fmt.Errorf("unable to parse metrics graphite section: %w", err)
 	}
 
+	if err := im.parseOTLPSettings(); err != nil {
+		return fmt.Errorf("unable to parse metrics otlp section: %w", err)
+	}
+
 	return nil
 }
 
@@ -59,3 +64,64 @@ func (im *InternalMetricsService) parseGraphiteSettings() error {
 	im.graphiteCfg = bridgeCfg
 	return nil
 }
+
+func (im *InternalMetricsService) parseOTLPSettings() error {
+	otlpSection, err := im.Cfg.Raw.GetSection("metrics.otlp")
+	if err != nil {
+		return nil
+	}
+
+	endpoint := otlpSection.Key("endpoint").String()
+	if endpoint == "" {
+		return nil
+	}
+
+	headers, err := splitKeyValues(otlpSection.Key("headers").String())
+	if err != nil {
+		return fmt.Errorf("invalid headers: %w", err)
+	}
+
+	resourceAttributes, err := splitKeyValues(otlpSection.Key("resource_attributes").String())
+	if err != nil {
+		return fmt.Errorf("invalid resource_attributes: %w", err)
+	}
+	defaultAttributes := map[string]string{
+		"service.name":        "grafana",
+		"service.version":     im.Cfg.BuildVersion,
+		"service.instance.id": im.Cfg.InstanceName,
+	}
+	for key, value := range defaultAttributes {
+		if _, exists := resourceAttributes[key]; !exists {
+			resourceAttributes[key] = value
+		}
+	}
+
+	im.otlpCfg = &otlpbridge.Config{
+		Endpoint:           endpoint,
+		Headers:            headers,
+		ResourceAttributes: resourceAttributes,
+		ScopeVersion:       im.Cfg.BuildVersion,
+		Interval:           otlpSection.Key("interval").MustDuration(time.Duration(im.intervalSeconds) * time.Second),
+		Timeout:            otlpSection.Key("timeout").MustDuration(10 * time.Second),
+		Gatherer:           im.gatherer,
+		Logger:             metricsLogger,
+	}
+	return nil
+}
+
+// splitKeyValues parses a comma separated list of key:value pairs.
+func splitKeyValues(s string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("malformed value, must be in 'key:value' form: %q", v)
+		}
+		values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return values, nil
+}
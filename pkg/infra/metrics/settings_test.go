@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestParseOTLPSettings(t *testing.T) {
+	newService := func(t *testing.T, config string) *InternalMetricsService {
+		t.Helper()
+		cfg := setting.NewCfg()
+		cfg.BuildVersion = "11.0.0"
+		cfg.InstanceName = "grafana-1"
+		require.NoError(t, cfg.Raw.Append([]byte(config)))
+		return &InternalMetricsService{Cfg: cfg, intervalSeconds: 10, gatherer: prometheus.NewRegistry()}
+	}
+
+	t.Run("bridge is disabled without an endpoint", func(t *testing.T) {
+		im := newService(t, "[metrics.otlp]\nendpoint =\n")
+		require.NoError(t, im.parseOTLPSettings())
+		require.Nil(t, im.otlpCfg)
+	})
+
+	t.Run("settings are parsed", func(t *testing.T) {
+		im := newService(t, `
+[metrics.otlp]
+endpoint = http://localhost:4318/v1/metrics
+timeout = 5s
+headers = Authorization:Basic dXNlcjpwYXNz, X-Scope-OrgID:1
+resource_attributes = deployment.environment:prod, service.name:grafana-prod
+`)
+		require.NoError(t, im.parseOTLPSettings())
+		require.NotNil(t, im.otlpCfg)
+		require.Equal(t, "http://localhost:4318/v1/metrics", im.otlpCfg.Endpoint)
+		require.Equal(t, 10*time.Second, im.otlpCfg.Interval, "interval defaults to interval_seconds")
+		require.Equal(t, 5*time.Second, im.otlpCfg.Timeout)
+		require.Equal(t, map[string]string{"Authorization": "Basic dXNlcjpwYXNz", "X-Scope-OrgID": "1"}, im.otlpCfg.Headers)
+		require.Equal(t, map[string]string{
+			"deployment.environment": "prod",
+			"service.name":           "grafana-prod",
+			"service.version":        "11.0.0",
+			"service.instance.id":    "grafana-1",
+		}, im.otlpCfg.ResourceAttributes)
+	})
+
+	t.Run("malformed headers are rejected", func(t *testing.T) {
+		im := newService(t, "[metrics.otlp]\nendpoint = http://localhost:4318/v1/metrics\nheaders = Authorization\n")
+		require.Error(t, im.parseOTLPSettings())
+	})
+}
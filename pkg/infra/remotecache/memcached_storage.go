@@ -3,6 +3,7 @@ package remotecache
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
@@ -18,9 +19,15 @@ type memcachedStorage struct {
 	c *memcache.Client
 }
 
+// newMemcachedStorage connects to the memcached servers listed in the connection string, separated by
+// commas. Keys are distributed between the servers.
 func newMemcachedStorage(opts *setting.RemoteCacheOptions) *memcachedStorage {
+	servers := strings.Split(opts.ConnStr, ",")
+	for i := range servers {
+		servers[i] = strings.TrimSpace(servers[i])
+	}
 	return &memcachedStorage{
-		c: memcache.New(opts.ConnStr),
+		c: memcache.New(servers...),
 	}
 }
 
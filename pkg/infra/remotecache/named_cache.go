@@ -0,0 +1,23 @@
+package remotecache
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
+)
+
+var _ namedcache.Provider = (*RemoteCache)(nil)
+
+// NamedCache returns the cache called name, configured in the [remote_cache.<name>] section.
+// It is kept in memory unless its backend is "remote".
+func (ds *RemoteCache) NamedCache(name string, defaultTTL time.Duration) *namedcache.Cache {
+	opts := ds.Cfg.RemoteCacheOptions.Caches[name]
+	switch opts.Backend {
+	case namedcache.RemoteBackend:
+		return namedcache.New(name, defaultTTL, opts, ds.client)
+	case namedcache.LocalBackend, "":
+	default:
+		ds.log.Warn("Unknown cache backend, caching in memory", "cache", name, "backend", opts.Backend)
+	}
+	return namedcache.NewLocal(name, defaultTTL, opts)
+}
@@ -0,0 +1,135 @@
+// Package namedcache provides the caches used by Grafana services, such as the data source cache.
+// Each cache is configured in a [remote_cache.<name>] section, and is kept in memory on each instance
+// unless it is configured to share the remote cache.
+package namedcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// LocalBackend caches the items in memory on each instance.
+	LocalBackend = "local"
+	// RemoteBackend caches the items in the remote cache shared by all instances.
+	RemoteBackend = "remote"
+
+	cacheHit   = "hit"
+	cacheMiss  = "miss"
+	cacheError = "error"
+)
+
+// ErrCacheItemNotFound is returned if the key isn't cached.
+var ErrCacheItemNotFound = errors.New("cache item not found")
+
+// Storage stores the items of the caches.
+type Storage interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, expire time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Provider provides the named caches.
+type Provider interface {
+	// NamedCache returns the cache called name. Items expire after defaultTTL, unless a TTL
+	// is configured for the cache.
+	NamedCache(name string, defaultTTL time.Duration) *Cache
+}
+
+// Cache is a cache used by a single Grafana service. Its keys are prefixed by its namespace, its
+// items expire after its TTL at the latest, and its hits and misses are counted under its name.
+type Cache struct {
+	name      string
+	namespace string
+	ttl       time.Duration
+	storage   Storage
+}
+
+// New returns the cache called name, storing its items in storage.
+func New(name string, defaultTTL time.Duration, opts setting.NamedCacheOptions, storage Storage) *Cache {
+	c := &Cache{
+		name:      name,
+		namespace: opts.Namespace,
+		ttl:       defaultTTL,
+		storage:   storage,
+	}
+	if c.namespace == "" {
+		c.namespace = name
+	}
+	if opts.TTL > 0 {
+		c.ttl = opts.TTL
+	}
+	return c
+}
+
+// NewLocal returns the cache called name, storing its items in memory.
+func NewLocal(name string, defaultTTL time.Duration, opts setting.NamedCacheOptions) *Cache {
+	ttl := defaultTTL
+	if opts.TTL > 0 {
+		ttl = opts.TTL
+	}
+	return New(name, defaultTTL, opts, newLocalStorage(ttl))
+}
+
+// Get returns the cached value, or ErrCacheItemNotFound if the key isn't cached.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.storage.Get(ctx, c.key(key))
+	switch {
+	case err == nil:
+		metrics.MRemoteCacheUsage.WithLabelValues(c.name, cacheHit).Inc()
+	case errors.Is(err, ErrCacheItemNotFound):
+		metrics.MRemoteCacheUsage.WithLabelValues(c.name, cacheMiss).Inc()
+	default:
+		metrics.MRemoteCacheUsage.WithLabelValues(c.name, cacheError).Inc()
+	}
+	return value, err
+}
+
+// Set caches the value until it expires, or until the TTL of the cache when expire is zero or longer.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	if expire <= 0 || expire > c.ttl {
+		expire = c.ttl
+	}
+	return c.storage.Set(ctx, c.key(key), value, expire)
+}
+
+// Delete removes the key from the cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return c.storage.Delete(ctx, c.key(key))
+}
+
+func (c *Cache) key(key string) string {
+	return c.namespace + ":" + key
+}
+
+// localStorage caches items in memory on the local instance.
+type localStorage struct {
+	cache *localcache.CacheService
+}
+
+func newLocalStorage(ttl time.Duration) *localStorage {
+	return &localStorage{cache: localcache.New(ttl, 2*ttl)}
+}
+
+func (s *localStorage) Get(_ context.Context, key string) ([]byte, error) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, ErrCacheItemNotFound
+	}
+	return value.([]byte), nil
+}
+
+func (s *localStorage) Set(_ context.Context, key string, value []byte, expire time.Duration) error {
+	s.cache.Set(key, value, expire)
+	return nil
+}
+
+func (s *localStorage) Delete(_ context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
@@ -0,0 +1,90 @@
+package namedcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeStorage struct {
+	items   map[string][]byte
+	expires map[string]time.Duration
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{items: map[string][]byte{}, expires: map[string]time.Duration{}}
+}
+
+func (s *fakeStorage) Get(_ context.Context, key string) ([]byte, error) {
+	value, ok := s.items[key]
+	if !ok {
+		return nil, ErrCacheItemNotFound
+	}
+	return value, nil
+}
+
+func (s *fakeStorage) Set(_ context.Context, key string, value []byte, expire time.Duration) error {
+	s.items[key] = value
+	s.expires[key] = expire
+	return nil
+}
+
+func (s *fakeStorage) Delete(_ context.Context, key string) error {
+	delete(s.items, key)
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("prefixes the keys with the namespace", func(t *testing.T) {
+		storage := newFakeStorage()
+		c := New("datasources", time.Minute, setting.NamedCacheOptions{}, storage)
+		require.NoError(t, c.Set(ctx, "ds-1", []byte("value"), 0))
+		require.Contains(t, storage.items, "datasources:ds-1")
+
+		c = New("datasources", time.Minute, setting.NamedCacheOptions{Namespace: "shared"}, storage)
+		require.NoError(t, c.Set(ctx, "ds-1", []byte("value"), 0))
+		require.Contains(t, storage.items, "shared:ds-1")
+	})
+
+	t.Run("caps the expiration to the TTL", func(t *testing.T) {
+		storage := newFakeStorage()
+		c := New("datasources", time.Minute, setting.NamedCacheOptions{TTL: 10 * time.Second}, storage)
+
+		require.NoError(t, c.Set(ctx, "default", []byte("value"), 0))
+		require.NoError(t, c.Set(ctx, "shorter", []byte("value"), time.Second))
+		require.NoError(t, c.Set(ctx, "longer", []byte("value"), time.Hour))
+
+		require.Equal(t, 10*time.Second, storage.expires["datasources:default"])
+		require.Equal(t, time.Second, storage.expires["datasources:shorter"])
+		require.Equal(t, 10*time.Second, storage.expires["datasources:longer"])
+	})
+
+	t.Run("counts hits and misses", func(t *testing.T) {
+		c := NewLocal("test_hits", time.Minute, setting.NamedCacheOptions{})
+		hits := metrics.MRemoteCacheUsage.WithLabelValues("test_hits", cacheHit)
+		misses := metrics.MRemoteCacheUsage.WithLabelValues("test_hits", cacheMiss)
+
+		_, err := c.Get(ctx, "key")
+		require.ErrorIs(t, err, ErrCacheItemNotFound)
+
+		require.NoError(t, c.Set(ctx, "key", []byte("value"), 0))
+		value, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), value)
+
+		require.NoError(t, c.Delete(ctx, "key"))
+		_, err = c.Get(ctx, "key")
+		require.ErrorIs(t, err, ErrCacheItemNotFound)
+
+		require.Equal(t, float64(1), testutil.ToFloat64(hits))
+		require.Equal(t, float64(2), testutil.ToFloat64(misses))
+	})
+}
@@ -0,0 +1,14 @@
+package namedcache
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// FakeProvider provides in-memory caches with their default TTL.
+type FakeProvider struct{}
+
+func (FakeProvider) NamedCache(name string, defaultTTL time.Duration) *Cache {
+	return NewLocal(name, defaultTTL, setting.NamedCacheOptions{})
+}
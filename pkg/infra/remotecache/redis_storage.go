@@ -17,20 +17,29 @@ import (
 const redisCacheType = "redis"
 
 type redisStorage struct {
-	c *redis.Client
+	c redis.UniversalClient
 }
 
-// parseRedisConnStr parses k=v pairs in csv and builds a redis Options object
-func parseRedisConnStr(connStr string) (*redis.Options, error) {
+// redisOptions are the options of a standalone, cluster or sentinel Redis client.
+type redisOptions struct {
+	redis.UniversalOptions
+	// cluster connects to a Redis Cluster, it must be set explicitly.
+	cluster bool
+}
+
+// parseRedisConnStr parses k=v pairs in csv and builds a redis Options object.
+// addr accepts several addresses separated by semicolons, which are the nodes of a Redis Cluster when cluster is
+// set, or the Sentinels of master_name.
+func parseRedisConnStr(connStr string) (*redisOptions, error) {
 	keyValueCSV := strings.Split(connStr, ",")
-	options := &redis.Options{Network: "tcp"}
+	options := &redisOptions{}
 	setTLSIsTrue := false
 	for _, rawKeyValue := range keyValueCSV {
 		keyValueTuple := strings.SplitN(rawKeyValue, "=", 2)
 		if len(keyValueTuple) != 2 {
-			if strings.HasPrefix(rawKeyValue, "password") {
+			if strings.HasPrefix(rawKeyValue, "password") || strings.HasPrefix(rawKeyValue, "sentinel_password") {
 				// don't log the password
-				rawKeyValue = "password" + setting.RedactedPassword
+				rawKeyValue = strings.SplitN(rawKeyValue, "=", 2)[0] + setting.RedactedPassword
 			}
 			return nil, fmt.Errorf("incorrect redis connection string format detected for '%v', format is key=value,key=value", rawKeyValue)
 		}
@@ -38,7 +47,7 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 		connVal := keyValueTuple[1]
 		switch connKey {
 		case "addr":
-			options.Addr = connVal
+			options.Addrs = strings.Split(connVal, ";")
 		case "password":
 			options.Password = connVal
 		case "db":
@@ -63,16 +72,35 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 			if connVal == "insecure" {
 				options.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 			}
+		case "cluster":
+			b, err := strconv.ParseBool(connVal)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", "value for cluster in redis connection string must be true or false", err)
+			}
+			options.cluster = b
+		case "master_name":
+			options.MasterName = connVal
+		case "sentinel_password":
+			options.SentinelPassword = connVal
 		default:
 			return nil, fmt.Errorf("unrecognized option '%v' in redis connection string", connKey)
 		}
 	}
+	if options.cluster && options.MasterName != "" {
+		return nil, fmt.Errorf("cluster and master_name can't both be set in redis connection string")
+	}
+	if options.cluster && options.DB != 0 {
+		return nil, fmt.Errorf("db can't be set in redis connection string with Redis Cluster")
+	}
+	if len(options.Addrs) > 1 && !options.cluster && options.MasterName == "" {
+		return nil, fmt.Errorf("several addresses in redis connection string require cluster=true or master_name")
+	}
 	if setTLSIsTrue {
-		// Get hostname from the Addr property and set it on the configuration for TLS
-		sp := strings.Split(options.Addr, ":")
-		if len(sp) < 1 {
-			return nil, fmt.Errorf("unable to get hostname from the addr field, expected host:port, got '%v'", options.Addr)
+		// Get hostname from the first address and set it on the configuration for TLS
+		if len(options.Addrs) == 0 {
+			return nil, fmt.Errorf("unable to get hostname from the addr field, expected host:port")
 		}
+		sp := strings.Split(options.Addrs[0], ":")
 		options.TLSConfig = &tls.Config{ServerName: sp[0]}
 	}
 	return options, nil
@@ -83,7 +111,12 @@ func newRedisStorage(opts *setting.RemoteCacheOptions) (*redisStorage, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &redisStorage{c: redis.NewClient(opt)}, nil
+	if opt.cluster {
+		return &redisStorage{c: redis.NewClusterClient(opt.Cluster())}, nil
+	}
+	// NewUniversalClient returns a sentinel client when master_name is set, and a standalone client otherwise,
+	// as several addresses require one of cluster and master_name.
+	return &redisStorage{c: redis.NewUniversalClient(&opt.UniversalOptions)}, nil
 }
 
 // Set sets value to a given key
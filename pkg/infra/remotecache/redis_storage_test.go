@@ -12,48 +12,87 @@ import (
 func Test_parseRedisConnStr(t *testing.T) {
 	cases := map[string]struct {
 		InputConnStr  string
-		OutputOptions *redis.Options
+		OutputOptions *redisOptions
 		ShouldErr     bool
 	}{
 		"all redis options should parse": {
 			"addr=127.0.0.1:6379,pool_size=100,db=1,password=grafanaRocks,ssl=false",
-			&redis.Options{
-				Addr:      "127.0.0.1:6379",
+			&redisOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:     []string{"127.0.0.1:6379"},
 				PoolSize:  100,
 				DB:        1,
 				Password:  "grafanaRocks",
-				Network:   "tcp",
 				TLSConfig: nil,
-			},
+			}},
 			false,
 		},
 		"subset of redis options should parse": {
 			"addr=127.0.0.1:6379,pool_size=100",
-			&redis.Options{
-				Addr:     "127.0.0.1:6379",
+			&redisOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:    []string{"127.0.0.1:6379"},
 				PoolSize: 100,
-				Network:  "tcp",
-			},
+			}},
 			false,
 		},
 		"ssl set to true should result in default TLS configuration with tls set to addr's host": {
 			"addr=grafana.com:6379,ssl=true",
-			&redis.Options{
-				Addr:      "grafana.com:6379",
-				Network:   "tcp",
+			&redisOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:     []string{"grafana.com:6379"},
 				TLSConfig: &tls.Config{ServerName: "grafana.com"},
-			},
+			}},
 			false,
 		},
 		"ssl to insecure should result in TLS configuration with InsecureSkipVerify": {
 			"addr=127.0.0.1:6379,ssl=insecure",
-			&redis.Options{
-				Addr:      "127.0.0.1:6379",
-				Network:   "tcp",
+			&redisOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:     []string{"127.0.0.1:6379"},
 				TLSConfig: &tls.Config{InsecureSkipVerify: true},
+			}},
+			false,
+		},
+		"several cluster addresses should parse": {
+			"addr=10.0.0.1:6379;10.0.0.2:6379,password=grafanaRocks,cluster=true",
+			&redisOptions{
+				UniversalOptions: redis.UniversalOptions{
+					Addrs:    []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+					Password: "grafanaRocks",
+				},
+				cluster: true,
 			},
 			false,
 		},
+		"several addresses without cluster or master_name should err": {
+			"addr=10.0.0.1:6379;10.0.0.2:6379",
+			nil,
+			true,
+		},
+		"cluster should parse": {
+			"addr=10.0.0.1:6379,cluster=true",
+			&redisOptions{
+				UniversalOptions: redis.UniversalOptions{Addrs: []string{"10.0.0.1:6379"}},
+				cluster:          true,
+			},
+			false,
+		},
+		"sentinel options should parse": {
+			"addr=10.0.0.1:26379;10.0.0.2:26379,master_name=grafana,sentinel_password=sentinelRocks",
+			&redisOptions{UniversalOptions: redis.UniversalOptions{
+				Addrs:            []string{"10.0.0.1:26379", "10.0.0.2:26379"},
+				MasterName:       "grafana",
+				SentinelPassword: "sentinelRocks",
+			}},
+			false,
+		},
+		"cluster with master_name should err": {
+			"addr=10.0.0.1:6379,cluster=true,master_name=grafana",
+			nil,
+			true,
+		},
+		"cluster with db should err": {
+			"addr=10.0.0.1:6379,cluster=true,db=1",
+			nil,
+			true,
+		},
 		"invalid SSL option should err": {
 			"addr=127.0.0.1:6379,ssl=dragons",
 			nil,
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -14,7 +16,7 @@ import (
 
 var (
 	// ErrCacheItemNotFound is returned if cache does not exist
-	ErrCacheItemNotFound = errors.New("cache item not found")
+	ErrCacheItemNotFound = namedcache.ErrCacheItemNotFound
 
 	// ErrInvalidCacheType is returned if the type is invalid
 	ErrInvalidCacheType = errors.New("invalid remote cache name")
@@ -24,6 +26,8 @@ var (
 
 const (
 	ServiceName = "RemoteCache"
+	// healthCheckKey is read to check that the cache can be reached, it's never set.
+	healthCheckKey = "health-check"
 )
 
 func ProvideService(cfg *setting.Cfg, sqlStore db.DB, usageStats usagestats.Service,
@@ -36,6 +40,7 @@ func ProvideService(cfg *setting.Cfg, sqlStore db.DB, usageStats usagestats.Serv
 		SQLStore: sqlStore,
 		Cfg:      cfg,
 		client:   client,
+		log:      log.New("remotecache"),
 	}
 
 	usageStats.RegisterMetricsFunc(s.getUsageStats)
@@ -76,6 +81,7 @@ type RemoteCache struct {
 	client   CacheStorage
 	SQLStore db.DB
 	Cfg      *setting.Cfg
+	log      log.Logger
 }
 
 // Get returns the cached value as an byte array
@@ -97,6 +103,15 @@ func (ds *RemoteCache) Delete(ctx context.Context, key string) error {
 	return ds.client.Delete(ctx, key)
 }
 
+// CheckHealth returns an error if the cache can't be reached.
+func (ds *RemoteCache) CheckHealth(ctx context.Context) error {
+	_, err := ds.client.Get(ctx, healthCheckKey)
+	if err != nil && !errors.Is(err, ErrCacheItemNotFound) {
+		return err
+	}
+	return nil
+}
+
 // Run starts the backend processes for cache clients.
 func (ds *RemoteCache) Run(ctx context.Context) error {
 	// create new interface if more clients need GC jobs
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
@@ -162,3 +163,27 @@ func (f fakeSecretsService) reverse(input []byte) []byte {
 	}
 	return []byte(string(r))
 }
+
+func TestNamedCache(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeCacheStorage()
+	cfg := setting.NewCfg()
+	cfg.RemoteCacheOptions = &setting.RemoteCacheOptions{
+		Name: redisCacheType,
+		Caches: map[string]setting.NamedCacheOptions{
+			"shared": {Backend: "remote"},
+		},
+	}
+	remoteCache := &RemoteCache{Cfg: cfg, client: client, log: log.NewNopLogger()}
+
+	shared := remoteCache.NamedCache("shared", time.Minute)
+	require.NoError(t, shared.Set(ctx, "key", []byte("value"), 0))
+	require.Contains(t, client.Storage, "shared:key")
+
+	local := remoteCache.NamedCache("local", time.Minute)
+	require.NoError(t, local.Set(ctx, "key", []byte("value"), 0))
+	require.NotContains(t, client.Storage, "local:key")
+	value, err := local.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+}
@@ -1,5 +1,16 @@
 package serverlock
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLeaseLost is returned when a lease was released by force, or acquired by another holder after it expired.
+	ErrLeaseLost    = errors.New("the lease was lost")
+	ErrLockNotFound = errors.New("lock not found")
+)
+
 type ServerLockExistsError struct {
 	actionName string
 }
@@ -7,3 +18,17 @@ type ServerLockExistsError struct {
 func (e *ServerLockExistsError) Error() string {
 	return "there is already a lock for this actionName: " + e.actionName
 }
+
+// LeaseHeldError is returned when a lease is held by another holder.
+type LeaseHeldError struct {
+	actionName string
+	Holder     string
+	Expires    time.Time
+}
+
+func (e *LeaseHeldError) Error() string {
+	if e.Holder == "" {
+		return "the lease for this actionName is held by another holder: " + e.actionName
+	}
+	return "the lease for this actionName is held by " + e.Holder + ": " + e.actionName
+}
@@ -0,0 +1,319 @@
+package serverlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Lease is a lock held by an instance until it releases it or stops renewing it, so that a lease held by an
+// instance which stopped is acquired by another instance once it expires. The fencing token of the lease increases
+// each time the lease is acquired: a holder which lost its lease, for example after a long pause, can tell that
+// another holder acquired it by comparing the tokens.
+type Lease struct {
+	ActionName string
+	Holder     string
+	Token      int64
+	Expires    time.Time
+
+	ttl time.Duration
+	sl  *ServerLockService
+}
+
+// holderID identifies the instance in the leases it holds.
+func holderID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), util.GenerateShortUID())
+}
+
+// AcquireLease acquires the lease of actionName for ttl, and returns a *LeaseHeldError if another holder holds it.
+func (sl *ServerLockService) AcquireLease(ctx context.Context, actionName string, ttl time.Duration) (*Lease, error) {
+	ctx, span := sl.tracer.Start(ctx, "ServerLockService.AcquireLease")
+	span.SetAttributes(attribute.String("serverlock.actionName", actionName))
+	defer span.End()
+
+	now := time.Now()
+	lease := &Lease{
+		ActionName: actionName,
+		Holder:     sl.holder,
+		Expires:    now.Add(ttl),
+		ttl:        ttl,
+		sl:         sl,
+	}
+	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		current := &serverLease{}
+		has, err := dbSession.Where("operation_uid = ?", actionName).Get(current)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			row := &serverLease{
+				OperationUID: actionName,
+				Holder:       sl.holder,
+				Token:        1,
+				Acquired:     now.Unix(),
+				Renewed:      now.Unix(),
+				Expires:      lease.Expires.Unix(),
+			}
+			if _, err := dbSession.Insert(row); err != nil {
+				if sl.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+					// another instance acquired the lease meanwhile
+					return &LeaseHeldError{actionName: actionName}
+				}
+				return err
+			}
+			lease.Token = row.Token
+			return nil
+		}
+
+		if current.Holder != "" && current.Expires >= now.Unix() {
+			return &LeaseHeldError{actionName: actionName, Holder: current.Holder, Expires: time.Unix(current.Expires, 0)}
+		}
+		res, err := dbSession.Exec("UPDATE server_lock_lease SET holder = ?, token = ?, acquired = ?, renewed = ?, expires = ? WHERE id = ? AND token = ?",
+			sl.holder, current.Token+1, now.Unix(), now.Unix(), lease.Expires.Unix(), current.ID, current.Token)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return &LeaseHeldError{actionName: actionName}
+		}
+		lease.Token = current.Token + 1
+		return nil
+	})
+	if err != nil {
+		var heldErr *LeaseHeldError
+		if !errors.As(err, &heldErr) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to acquire lease: %v", err))
+		}
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("serverlock.token", lease.Token))
+	return lease, nil
+}
+
+// Renew extends the lease for its ttl, and returns ErrLeaseLost if the lease was released by force or acquired
+// by another holder.
+func (l *Lease) Renew(ctx context.Context) error {
+	now := time.Now()
+	expires := now.Add(l.ttl)
+	var affected int64
+	err := l.sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		res, err := dbSession.Exec("UPDATE server_lock_lease SET renewed = ?, expires = ? WHERE operation_uid = ? AND holder = ? AND token = ?",
+			now.Unix(), expires.Unix(), l.ActionName, l.Holder, l.Token)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// MySQL doesn't count the rows whose values didn't change, when the lease is renewed within the same second.
+	if affected != 1 {
+		if err := l.Validate(ctx); err != nil {
+			return err
+		}
+	}
+	l.Expires = expires
+	return nil
+}
+
+// Validate returns ErrLeaseLost if the lease expired, was released by force, or was acquired by another holder.
+// The lease can still be lost right after, the writes that must not be done by a former holder use Guard.
+func (l *Lease) Validate(ctx context.Context) error {
+	current := &serverLease{}
+	has := false
+	err := l.sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		var err error
+		has, err = dbSession.Where("operation_uid = ?", l.ActionName).Get(current)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !has || current.Holder != l.Holder || current.Token != l.Token || current.Expires < time.Now().Unix() {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Guard executes fn in a transaction which is only committed if the lease is still held with its fencing token.
+// The row of the lease is updated first in the transaction, so another holder can't acquire the lease until the
+// writes of fn are committed: a former holder can't write once another holder acquired the lease, unlike with
+// Validate followed by the writes. fn must make its writes in the transaction of its context. It returns
+// ErrLeaseLost without executing fn if the lease was lost.
+func (l *Lease) Guard(ctx context.Context, fn func(ctx context.Context) error) error {
+	return l.sl.SQLStore.InTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		var affected int64
+		err := l.sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+			res, err := dbSession.Exec("UPDATE server_lock_lease SET renewed = ? WHERE operation_uid = ? AND holder = ? AND token = ? AND expires >= ?",
+				now.Unix(), l.ActionName, l.Holder, l.Token, now.Unix())
+			if err != nil {
+				return err
+			}
+			affected, err = res.RowsAffected()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return ErrLeaseLost
+		}
+		return fn(ctx)
+	})
+}
+
+// Release releases the lease, unless it was already lost.
+func (l *Lease) Release(ctx context.Context) error {
+	return l.sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		_, err := dbSession.Exec("UPDATE server_lock_lease SET holder = '', expires = 0 WHERE operation_uid = ? AND holder = ? AND token = ?",
+			l.ActionName, l.Holder, l.Token)
+		return err
+	})
+}
+
+// LeaseAndExecute acquires the lease of actionName and executes fn while renewing the lease every third of ttl,
+// then releases it. The context of fn is cancelled if the lease is lost. It returns a *LeaseHeldError without
+// executing fn if another holder holds the lease.
+func (sl *ServerLockService) LeaseAndExecute(ctx context.Context, actionName string, ttl time.Duration, fn func(ctx context.Context, lease *Lease)) error {
+	start := time.Now()
+	ctxLogger := sl.log.FromContext(ctx)
+	ctxLogger.Debug("Start LeaseAndExecute", "actionName", actionName)
+
+	lease, err := sl.AcquireLease(ctx, actionName, ttl)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-fnCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := lease.Renew(fnCtx)
+			switch {
+			case errors.Is(err, ErrLeaseLost):
+				ctxLogger.Warn("Lost the lease, cancelling the execution", "actionName", actionName, "token", lease.Token)
+				cancel()
+				return
+			case err != nil && fnCtx.Err() == nil:
+				ctxLogger.Warn("Failed to renew the lease", "actionName", actionName, "error", err)
+				if time.Now().After(lease.Expires) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	sl.executeFunc(fnCtx, actionName, func(ctx context.Context) { fn(ctx, lease) })
+	cancel()
+	<-renewed
+
+	if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+		ctxLogger.Error("Failed to release the lease", "actionName", actionName, "error", err)
+	}
+
+	ctxLogger.Debug("LeaseAndExecute finished", "actionName", actionName, "token", lease.Token, "duration", time.Since(start))
+	return nil
+}
+
+// Locks returns the locks and the leases, sorted by action name.
+func (sl *ServerLockService) Locks(ctx context.Context) ([]LockInfo, error) {
+	locks := make([]*serverLock, 0)
+	leases := make([]*serverLease, 0)
+	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		if err := dbSession.SQL("SELECT * FROM server_lock").Find(&locks); err != nil {
+			return err
+		}
+		return dbSession.Find(&leases)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	infos := make([]LockInfo, 0, len(locks)+len(leases))
+	for _, lock := range locks {
+		infos = append(infos, LockInfo{
+			ActionName:    lock.OperationUID,
+			Type:          LockTypeLock,
+			LastExecution: lock.LastExecution,
+		})
+	}
+	for _, lease := range leases {
+		infos = append(infos, LockInfo{
+			ActionName: lease.OperationUID,
+			Type:       LockTypeLease,
+			Holder:     lease.Holder,
+			Token:      lease.Token,
+			Acquired:   lease.Acquired,
+			Renewed:    lease.Renewed,
+			Expires:    lease.Expires,
+			Held:       lease.Holder != "" && lease.Expires >= now,
+		})
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].ActionName < infos[j].ActionName })
+	return infos, nil
+}
+
+// ForceRelease releases the lock and the lease of actionName whoever holds them, for example when an instance
+// stopped while holding a lock with a long interval. The holder of a released lease loses it on its next renewal.
+func (sl *ServerLockService) ForceRelease(ctx context.Context, actionName string) error {
+	var affected int64
+	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		res, err := dbSession.Exec("UPDATE server_lock_lease SET holder = '', expires = 0 WHERE operation_uid = ? AND holder <> ''", actionName)
+		if err != nil {
+			return err
+		}
+		leases, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		res, err = dbSession.Exec("DELETE FROM server_lock WHERE operation_uid = ?", actionName)
+		if err != nil {
+			return err
+		}
+		locks, err := res.RowsAffected()
+		affected = leases + locks
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrLockNotFound
+	}
+	sl.log.FromContext(ctx).Info("Released lock by force", "actionName", actionName)
+	return nil
+}
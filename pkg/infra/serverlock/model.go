@@ -7,3 +7,40 @@ type serverLock struct {
 	LastExecution int64
 	Version       int64
 }
+
+// serverLease is a lease on an operation, held by Holder until Expires, in epoch seconds. Token is the fencing
+// token of the lease, incremented each time the lease is acquired. A released lease keeps its token.
+type serverLease struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OperationUID string `xorm:"operation_uid"`
+	Holder       string `xorm:"holder"`
+	Token        int64  `xorm:"token"`
+	Acquired     int64  `xorm:"acquired"`
+	Renewed      int64  `xorm:"renewed"`
+	Expires      int64  `xorm:"expires"`
+}
+
+func (serverLease) TableName() string {
+	return "server_lock_lease"
+}
+
+const (
+	LockTypeLock  = "lock"
+	LockTypeLease = "lease"
+)
+
+// LockInfo describes a lock or a lease for the admin API.
+type LockInfo struct {
+	ActionName string `json:"actionName"`
+	// Type is "lock" for the locks of LockAndExecute and LockExecuteAndRelease, and "lease" for the leases.
+	Type string `json:"type"`
+	// LastExecution is when a lock was last acquired, in epoch seconds.
+	LastExecution int64  `json:"lastExecution,omitempty"`
+	Holder        string `json:"holder,omitempty"`
+	Token         int64  `json:"token,omitempty"`
+	Acquired      int64  `json:"acquired,omitempty"`
+	Renewed       int64  `json:"renewed,omitempty"`
+	Expires       int64  `json:"expires,omitempty"`
+	// Held is true while a lease is held and not expired.
+	Held bool `json:"held"`
+}
@@ -23,16 +23,20 @@ func ProvideService(sqlStore db.DB, tracer tracing.Tracer) *ServerLockService {
 		SQLStore: sqlStore,
 		tracer:   tracer,
 		log:      log.New("infra.lockservice"),
+		holder:   holderID(),
 	}
 }
 
 // ServerLockService allows servers in HA mode to claim a lock and execute a function if the server was granted the lock
 // It exposes 2 services LockAndExecute and LockExecuteAndRelease, which are intended to be used independently, don't mix
-// them up (ie, use the same actionName for both of them).
+// them up (ie, use the same actionName for both of them). It also exposes renewable leases with fencing tokens, with
+// AcquireLease and LeaseAndExecute, for the functions which run for an unknown time.
 type ServerLockService struct {
 	SQLStore db.DB
 	tracer   tracing.Tracer
 	log      log.Logger
+	// holder identifies this instance in the leases it holds.
+	holder string
 }
 
 // LockAndExecute try to create a lock for this server and only executes the
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
 )
 
 func TestIntegrationServerLock_LockAndExecute(t *testing.T) {
@@ -125,3 +127,115 @@ func TestIntegrationServerLock_LockExecuteAndReleaseWithRetries(t *testing.T) {
 	require.Equal(t, expectedRetries, retries)
 	require.Equal(t, 1, funcRuns)
 }
+
+func TestIntegrationServerLock_Lease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sl := createTestableServerLock(t)
+	other := &ServerLockService{SQLStore: sl.SQLStore, tracer: sl.tracer, log: sl.log, holder: holderID()}
+	ctx := context.Background()
+	actionName := "test-operation"
+
+	lease, err := sl.AcquireLease(ctx, actionName, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), lease.Token)
+	require.NoError(t, lease.Renew(ctx))
+	require.NoError(t, lease.Validate(ctx))
+
+	// the lease is held until it's released
+	_, err = other.AcquireLease(ctx, actionName, time.Minute)
+	var heldErr *LeaseHeldError
+	require.ErrorAs(t, err, &heldErr)
+	require.Equal(t, sl.holder, heldErr.Holder)
+
+	require.NoError(t, lease.Release(ctx))
+	otherLease, err := other.AcquireLease(ctx, actionName, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), otherLease.Token, "the token increases each time the lease is acquired")
+
+	// the former holder can't renew the lease anymore
+	require.ErrorIs(t, lease.Renew(ctx), ErrLeaseLost)
+	require.ErrorIs(t, lease.Validate(ctx), ErrLeaseLost)
+
+	// nor write with the fencing token of the lease
+	require.ErrorIs(t, lease.Guard(ctx, func(context.Context) error {
+		t.Fatal("the former holder should not write")
+		return nil
+	}), ErrLeaseLost)
+	guarded := false
+	require.NoError(t, otherLease.Guard(ctx, func(context.Context) error {
+		guarded = true
+		return nil
+	}))
+	require.True(t, guarded)
+
+	// an expired lease is acquired by another holder
+	require.NoError(t, sl.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE server_lock_lease SET expires = ? WHERE operation_uid = ?", time.Now().Add(-time.Second).Unix(), actionName)
+		return err
+	}))
+	lease, err = sl.AcquireLease(ctx, actionName, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), lease.Token)
+	require.ErrorIs(t, otherLease.Validate(ctx), ErrLeaseLost)
+
+	locks, err := sl.Locks(ctx)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	require.Equal(t, LockInfo{
+		ActionName: actionName,
+		Type:       LockTypeLease,
+		Holder:     sl.holder,
+		Token:      3,
+		Acquired:   locks[0].Acquired,
+		Renewed:    locks[0].Renewed,
+		Expires:    locks[0].Expires,
+		Held:       true,
+	}, locks[0])
+
+	// a lease released by force is lost by its holder
+	require.NoError(t, other.ForceRelease(ctx, actionName))
+	require.ErrorIs(t, lease.Renew(ctx), ErrLeaseLost)
+	require.ErrorIs(t, other.ForceRelease(ctx, actionName), ErrLockNotFound)
+}
+
+func TestIntegrationServerLock_LeaseAndExecute(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sl := createTestableServerLock(t)
+	ctx := context.Background()
+	actionName := "test-operation"
+
+	var token int64
+	err := sl.LeaseAndExecute(ctx, actionName, time.Minute, func(ctx context.Context, lease *Lease) {
+		token = lease.Token
+		// the lease is held during the execution
+		err := sl.LeaseAndExecute(ctx, actionName, time.Minute, func(context.Context, *Lease) {
+			t.Fatal("the lease should be held")
+		})
+		var heldErr *LeaseHeldError
+		require.ErrorAs(t, err, &heldErr)
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), token)
+
+	// the lease was released after the execution
+	err = sl.LeaseAndExecute(ctx, actionName, time.Minute, func(ctx context.Context, lease *Lease) {
+		token = lease.Token
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), token)
+
+	// the execution is cancelled when the lease is lost
+	err = sl.LeaseAndExecute(ctx, actionName, 300*time.Millisecond, func(ctx context.Context, lease *Lease) {
+		require.NoError(t, sl.ForceRelease(ctx, actionName))
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("the execution should be cancelled")
+		}
+	})
+	require.NoError(t, err)
+}
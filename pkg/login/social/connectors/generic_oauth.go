@@ -38,6 +38,7 @@ var ExtraGenericOAuthSettingKeys = map[string]ExtraKeyInfo{
 
 var _ social.SocialConnector = (*SocialGenericOAuth)(nil)
 var _ ssosettings.Reloadable = (*SocialGenericOAuth)(nil)
+var _ ssosettings.ClaimsMapper = (*SocialGenericOAuth)(nil)
 
 type SocialGenericOAuth struct {
 	*SocialBase
@@ -54,7 +55,17 @@ type SocialGenericOAuth struct {
 }
 
 func NewGenericOAuthProvider(info *social.OAuthInfo, cfg *setting.Cfg, orgRoleMapper *OrgRoleMapper, ssoSettings ssosettings.Service, features featuremgmt.FeatureToggles) *SocialGenericOAuth {
-	provider := &SocialGenericOAuth{
+	provider := newGenericOAuth(info, cfg, orgRoleMapper, features)
+
+	if features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsApi) {
+		ssoSettings.RegisterReloadable(social.GenericOAuthProviderName, provider)
+	}
+
+	return provider
+}
+
+func newGenericOAuth(info *social.OAuthInfo, cfg *setting.Cfg, orgRoleMapper *OrgRoleMapper, features featuremgmt.FeatureToggles) *SocialGenericOAuth {
+	return &SocialGenericOAuth{
 		SocialBase:           newSocialBase(social.GenericOAuthProviderName, orgRoleMapper, info, features, cfg),
 		teamsUrl:             info.TeamsUrl,
 		emailAttributeName:   info.EmailAttributeName,
@@ -67,12 +78,6 @@ func NewGenericOAuthProvider(info *social.OAuthInfo, cfg *setting.Cfg, orgRoleMa
 		teamIds:              util.SplitString(info.Extra[teamIdsKey]),
 		allowedOrganizations: util.SplitString(info.Extra[allowedOrganizationsKey]),
 	}
-
-	if features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsApi) {
-		ssoSettings.RegisterReloadable(social.GenericOAuthProviderName, provider)
-	}
-
-	return provider
 }
 
 func (s *SocialGenericOAuth) Validate(ctx context.Context, newSettings ssoModels.SSOSettings, oldSettings ssoModels.SSOSettings, requester identity.Requester) error {
@@ -143,6 +148,38 @@ func (s *SocialGenericOAuth) Reload(ctx context.Context, settings ssoModels.SSOS
 	return nil
 }
 
+// MapClaims maps the claims of a user with the settings, as UserInfo does with the claims of the ID token and of the
+// API response. The team and organization memberships, which are fetched from the API of the provider, are not checked.
+func (s *SocialGenericOAuth) MapClaims(ctx context.Context, settings ssoModels.SSOSettings, claims []byte) (*social.BasicUserInfo, error) {
+	info, err := CreateOAuthInfoFromKeyValues(settings.Settings)
+	if err != nil {
+		return nil, ssosettings.ErrInvalidSettings.Errorf("SSO settings map cannot be converted to OAuthInfo: %v", err)
+	}
+	provider := newGenericOAuth(info, s.cfg, s.orgRoleMapper, s.features)
+
+	var data UserInfoJson
+	if err := json.Unmarshal(claims, &data); err != nil {
+		return nil, fmt.Errorf("error decoding claims: %w", err)
+	}
+	data.rawJSON = claims
+	data.source = "sample"
+
+	userInfo, err := provider.mapUserInfo([]*UserInfoJson{&data})
+	if err != nil {
+		return nil, err
+	}
+
+	if userInfo.Login == "" {
+		userInfo.Login = userInfo.Email
+	}
+
+	if !provider.isGroupMember(userInfo.Groups) {
+		return userInfo, errMissingGroupMembership
+	}
+
+	return userInfo, nil
+}
+
 // TODOD: remove this in the next PR and use the isGroupMember from social.go
 func (s *SocialGenericOAuth) isGroupMember(groups []string) bool {
 	if len(s.info.AllowedGroups) == 0 {
@@ -235,6 +272,46 @@ func (s *SocialGenericOAuth) UserInfo(ctx context.Context, client *http.Client,
 		toCheck = append(toCheck, apiData)
 	}
 
+	userInfo, err := s.mapUserInfo(toCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.info.AllowAssignGrafanaAdmin && s.info.SkipOrgRoleSync {
+		s.log.Debug("AllowAssignGrafanaAdmin and skipOrgRoleSync are both set, Grafana Admin role will not be synced, consider setting one or the other")
+	}
+
+	if s.canFetchPrivateEmail(userInfo) {
+		userInfo.Email, err = s.fetchPrivateEmail(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		s.log.Debug("Setting email from fetched private email", "email", userInfo.Email)
+	}
+
+	if userInfo.Login == "" {
+		s.log.Debug("Defaulting to using email for user info login", "email", userInfo.Email)
+		userInfo.Login = userInfo.Email
+	}
+
+	if !s.isTeamMember(ctx, client) {
+		return nil, errors.New("user not a member of one of the required teams")
+	}
+
+	if !s.isOrganizationMember(ctx, client) {
+		return nil, errors.New("user not a member of one of the required organizations")
+	}
+
+	if !s.isGroupMember(userInfo.Groups) {
+		return nil, errMissingGroupMembership
+	}
+
+	s.log.Debug("User info result", "result", userInfo)
+	return userInfo, nil
+}
+
+// mapUserInfo maps the user info from the claims of the sources, the first source having a value taking precedence.
+func (s *SocialGenericOAuth) mapUserInfo(toCheck []*UserInfoJson) (*social.BasicUserInfo, error) {
 	userInfo := &social.BasicUserInfo{}
 	var externalOrgs []string
 	for _, data := range toCheck {
@@ -301,37 +378,6 @@ func (s *SocialGenericOAuth) UserInfo(ctx context.Context, client *http.Client,
 		}
 	}
 
-	if s.info.AllowAssignGrafanaAdmin && s.info.SkipOrgRoleSync {
-		s.log.Debug("AllowAssignGrafanaAdmin and skipOrgRoleSync are both set, Grafana Admin role will not be synced, consider setting one or the other")
-	}
-
-	if s.canFetchPrivateEmail(userInfo) {
-		var err error
-		userInfo.Email, err = s.fetchPrivateEmail(ctx, client)
-		if err != nil {
-			return nil, err
-		}
-		s.log.Debug("Setting email from fetched private email", "email", userInfo.Email)
-	}
-
-	if userInfo.Login == "" {
-		s.log.Debug("Defaulting to using email for user info login", "email", userInfo.Email)
-		userInfo.Login = userInfo.Email
-	}
-
-	if !s.isTeamMember(ctx, client) {
-		return nil, errors.New("user not a member of one of the required teams")
-	}
-
-	if !s.isOrganizationMember(ctx, client) {
-		return nil, errors.New("user not a member of one of the required organizations")
-	}
-
-	if !s.isGroupMember(userInfo.Groups) {
-		return nil, errMissingGroupMembership
-	}
-
-	s.log.Debug("User info result", "result", userInfo)
 	return userInfo, nil
 }
 
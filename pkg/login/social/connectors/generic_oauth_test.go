@@ -1215,6 +1215,48 @@ func TestSocialGenericOAuth_Reload(t *testing.T) {
 	}
 }
 
+func TestSocialGenericOAuth_MapClaims(t *testing.T) {
+	s := NewGenericOAuthProvider(&social.OAuthInfo{ClientId: "client-id"}, setting.NewCfg(),
+		ProvideOrgRoleMapper(setting.NewCfg(), orgtest.NewOrgServiceFake()), &ssosettingstests.MockService{}, featuremgmt.WithFeatures())
+
+	settings := func(allowedGroups string) ssoModels.SSOSettings {
+		return ssoModels.SSOSettings{
+			Settings: map[string]any{
+				"client_id":             "new-client-id",
+				"login_attribute_path":  "preferred_username",
+				"role_attribute_path":   "contains(groups[*], 'admins') && 'Admin' || 'Viewer'",
+				"groups_attribute_path": "groups",
+				"allowed_groups":        allowedGroups,
+			},
+		}
+	}
+	claims := []byte(`{"sub": "1", "email": "jane@example.org", "preferred_username": "jane", "groups": ["admins", "devs"]}`)
+
+	t.Run("should map the claims with the settings", func(t *testing.T) {
+		userInfo, err := s.MapClaims(context.Background(), settings(""), claims)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1", userInfo.Id)
+		assert.Equal(t, "jane@example.org", userInfo.Email)
+		assert.Equal(t, "jane", userInfo.Login)
+		assert.Equal(t, org.RoleAdmin, userInfo.Role)
+		assert.Equal(t, []string{"admins", "devs"}, userInfo.Groups)
+		assert.Equal(t, "client-id", s.info.ClientId, "the settings of the provider are not changed")
+	})
+
+	t.Run("should return the mapped user info when the user isn't allowed", func(t *testing.T) {
+		userInfo, err := s.MapClaims(context.Background(), settings("ops"), claims)
+		require.ErrorIs(t, err, errMissingGroupMembership)
+		require.NotNil(t, userInfo)
+		assert.Equal(t, []string{"admins", "devs"}, userInfo.Groups)
+	})
+
+	t.Run("should fail with invalid claims", func(t *testing.T) {
+		_, err := s.MapClaims(context.Background(), settings(""), []byte("not-json"))
+		require.Error(t, err)
+	})
+}
+
 func TestGenericOAuth_Reload_ExtraFields(t *testing.T) {
 	testCases := []struct {
 		name                         string
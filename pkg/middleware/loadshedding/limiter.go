@@ -0,0 +1,92 @@
+package loadshedding
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// shortWindow and longWindow are the number of samples averaged by the short and long term latencies.
+	shortWindow = 10
+	longWindow  = 600
+	// smoothing is the weight of a new limit compared to the previous one.
+	smoothing = 0.2
+	// minGradient bounds how fast the limit decreases on a single sample.
+	minGradient = 0.5
+)
+
+// limiter adapts the number of concurrent requests to the latency of the server, like the gradient
+// algorithm of Netflix's concurrency-limits: while the latency of the recent requests stays close to
+// the long term latency, the limit grows to let more requests in; when the recent requests get slower,
+// which means they are queuing for a saturated resource, the limit shrinks proportionally.
+type limiter struct {
+	mtx      sync.Mutex
+	limit    float64
+	minLimit float64
+	maxLimit float64
+	inFlight int
+	shortRTT float64
+	longRTT  float64
+}
+
+func newLimiter(initial, minLimit, maxLimit int) *limiter {
+	return &limiter{
+		limit:    float64(initial),
+		minLimit: float64(minLimit),
+		maxLimit: float64(maxLimit),
+	}
+}
+
+// acquire lets a request in if fewer than share of the limit are in flight, and returns a function to
+// call when the request completes.
+func (l *limiter) acquire(share float64) (func(), bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if float64(l.inFlight) >= math.Max(1, math.Floor(l.limit*share)) {
+		return nil, false
+	}
+	l.inFlight++
+	start := time.Now()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(time.Since(start)) })
+	}, true
+}
+
+func (l *limiter) release(rtt time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	inFlight := l.inFlight
+	l.inFlight--
+	l.update(rtt.Seconds(), inFlight)
+}
+
+// update adjusts the limit with the latency of a request completed while inFlight requests were running.
+func (l *limiter) update(rtt float64, inFlight int) {
+	if l.longRTT == 0 {
+		l.shortRTT, l.longRTT = rtt, rtt
+		return
+	}
+	l.shortRTT += (rtt - l.shortRTT) / shortWindow
+	l.longRTT += (rtt - l.longRTT) / longWindow
+
+	// the server doesn't use the limit, so it can't tell whether it would cope with a higher one
+	if float64(inFlight) < l.limit/2 {
+		return
+	}
+
+	gradient := math.Max(minGradient, math.Min(1, l.longRTT/l.shortRTT))
+	// the square root of the limit lets the limit grow when the latency is stable
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, l.limit*(1-smoothing)+newLimit*smoothing))
+}
+
+func (l *limiter) state() (limit float64, inFlight int) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.limit, l.inFlight
+}
@@ -0,0 +1,171 @@
+// Package loadshedding limits the concurrency of the HTTP server to what it can serve without queuing,
+// and rejects the requests over the limit by priority, so that an overloaded Grafana keeps serving its
+// users and the alerts posted to it while API automation is asked to retry later.
+package loadshedding
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// Class is the priority class of a request.
+type Class string
+
+const (
+	// ClassHealth are the health checks and metrics scrapes, which are never shed.
+	ClassHealth Class = "health"
+	// ClassAlertWebhook are the alerts posted to Grafana.
+	ClassAlertWebhook Class = "alert_webhook"
+	// ClassInteractive are the requests of the users of the UI, signed in with a session or anonymous.
+	ClassInteractive Class = "interactive"
+	// ClassAutomation are the other API requests, authenticated with service account tokens, API keys or
+	// basic auth.
+	ClassAutomation Class = "automation"
+)
+
+// shares are the fractions of the concurrency limit each class can use. The lower the priority of a class,
+// the sooner its requests are shed.
+var shares = map[Class]float64{
+	ClassAlertWebhook: 1,
+	ClassInteractive:  0.9,
+	ClassAutomation:   0.7,
+}
+
+var healthPaths = []string{"/api/health", "/healthz", "/livez", "/readyz", "/metrics"}
+
+// Middleware sheds the requests over the concurrency limit of their class with a 503 response. It classifies
+// the requests by their identity, so it must run after the context handler.
+func Middleware(cfg *setting.Cfg, promRegister prometheus.Registerer) web.Middleware {
+	settings := cfg.LoadShedding
+	l := newLimiter(settings.InitialLimit, settings.MinLimit, settings.MaxLimit)
+	retryAfter := strconv.Itoa(int(math.Ceil(settings.RetryAfter.Seconds())))
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "http_load_shedding",
+		Name:      "requests_total",
+		Help:      "Number of requests admitted or shed by priority class.",
+	}, []string{"class", "result"})
+	limit := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "http_load_shedding",
+		Name:      "concurrency_limit",
+		Help:      "Adaptive limit of concurrent requests.",
+	}, func() float64 {
+		limit, _ := l.state()
+		return limit
+	})
+	inFlight := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "http_load_shedding",
+		Name:      "in_flight_requests",
+		Help:      "Number of requests counted against the concurrency limit.",
+	}, func() float64 {
+		_, inFlight := l.state()
+		return float64(inFlight)
+	})
+	promRegister.MustRegister(requests, limit, inFlight)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// long-lived connections don't tell anything about the load of the server
+			if isStream(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			class := classify(cfg, r)
+			if class == ClassHealth {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			done, ok := l.acquire(shares[class])
+			if !ok {
+				requests.WithLabelValues(string(class), "shed").Inc()
+				w.Header().Set("Retry-After", retryAfter)
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"message":"Grafana is overloaded, retry later"}`))
+				return
+			}
+			defer done()
+
+			requests.WithLabelValues(string(class), "admitted").Inc()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// classify returns the priority class of a request, from its path and the identity it is authenticated with.
+func classify(cfg *setting.Cfg, r *http.Request) Class {
+	path := r.URL.Path
+	if cfg.ServeFromSubPath && cfg.AppSubURL != "" {
+		path = strings.TrimPrefix(path, cfg.AppSubURL)
+	}
+
+	for _, p := range healthPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return ClassHealth
+		}
+	}
+
+	if r.Method == http.MethodPost && isAlertWebhook(cfg.LoadShedding.AlertWebhookPaths, path) {
+		return ClassAlertWebhook
+	}
+
+	if !strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, "/apis/") {
+		// pages and static assets of the UI
+		return ClassInteractive
+	}
+
+	c := contexthandler.FromContext(r.Context())
+	if c == nil || c.SignedInUser == nil {
+		return ClassAutomation
+	}
+	// the users of the UI are signed in with a session, or anonymous
+	if c.UserToken != nil || c.SignedInUser.IsAnonymous {
+		return ClassInteractive
+	}
+	return ClassAutomation
+}
+
+func isAlertWebhook(paths []string, path string) bool {
+	// alerts posted to the Alertmanager of Grafana or proxied to external Alertmanagers
+	if strings.HasPrefix(path, "/api/alertmanager/") && strings.HasSuffix(path, "/api/v2/alerts") {
+		return true
+	}
+	for _, p := range paths {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStream returns true for the long-lived requests: the websockets, like the ones of Grafana Live, the server-sent
+// events and the watches of the Kubernetes APIs.
+func isStream(r *http.Request) bool {
+	if r.Header.Get("Upgrade") != "" {
+		return true
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	if strings.Contains(r.URL.Path, "/apis/") {
+		if watch := r.URL.Query().Get("watch"); watch == "true" || watch == "1" {
+			return true
+		}
+		if strings.Contains(r.URL.Path, "/watch/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package loadshedding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models/usertoken"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestClassify(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LoadShedding.AlertWebhookPaths = []string{"/api/plugins/my-app/resources/alerts"}
+
+	session := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{UserID: 1}, UserToken: &usertoken.UserToken{}}
+	anonymous := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{IsAnonymous: true}}
+	token := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{UserID: 2, IsServiceAccount: true}}
+
+	require.Equal(t, ClassHealth, classify(cfg, newRequest(http.MethodGet, "/api/health", nil, nil)))
+	require.Equal(t, ClassHealth, classify(cfg, newRequest(http.MethodGet, "/metrics/plugins/loki", nil, nil)))
+	require.Equal(t, ClassAlertWebhook, classify(cfg, newRequest(http.MethodPost, "/api/alertmanager/grafana/api/v2/alerts", nil, nil)))
+	require.Equal(t, ClassAlertWebhook, classify(cfg, newRequest(http.MethodPost, "/api/plugins/my-app/resources/alerts", token, nil)))
+	require.Equal(t, ClassInteractive, classify(cfg, newRequest(http.MethodGet, "/d/abc/dashboard", nil, nil)))
+	require.Equal(t, ClassInteractive, classify(cfg, newRequest(http.MethodPost, "/api/ds/query", session, nil)))
+	require.Equal(t, ClassInteractive, classify(cfg, newRequest(http.MethodPost, "/api/ds/query", anonymous, nil)))
+	require.Equal(t, ClassInteractive, classify(cfg, newRequest(http.MethodGet, "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards", session, nil)))
+	require.Equal(t, ClassAutomation, classify(cfg, newRequest(http.MethodPost, "/api/ds/query", token, nil)))
+	require.Equal(t, ClassAutomation, classify(cfg, newRequest(http.MethodGet, "/apis/dashboard.grafana.app/v0alpha1/namespaces/default/dashboards", token, nil)))
+	require.Equal(t, ClassAutomation, classify(cfg, newRequest(http.MethodGet, "/api/alertmanager/grafana/api/v2/alerts", nil, nil)))
+	// a session cookie that did not authenticate the request doesn't make it interactive
+	require.Equal(t, ClassAutomation, classify(cfg, newRequest(http.MethodGet, "/api/search", token, map[string]string{"Cookie": "grafana_session=abc"})))
+}
+
+func TestIsStream(t *testing.T) {
+	require.True(t, isStream(newRequest(http.MethodGet, "/api/live/ws", nil, map[string]string{"Upgrade": "websocket"})))
+	require.True(t, isStream(newRequest(http.MethodGet, "/api/plugins/my-app/resources/events", nil, map[string]string{"Accept": "text/event-stream"})))
+	require.True(t, isStream(newRequest(http.MethodGet, "/apis/playlist.grafana.app/v0alpha1/namespaces/default/playlists?watch=true", nil, nil)))
+	require.True(t, isStream(newRequest(http.MethodGet, "/apis/playlist.grafana.app/v0alpha1/watch/namespaces/default/playlists", nil, nil)))
+	require.False(t, isStream(newRequest(http.MethodGet, "/apis/playlist.grafana.app/v0alpha1/namespaces/default/playlists", nil, nil)))
+	require.False(t, isStream(newRequest(http.MethodGet, "/api/search?watch=true", nil, nil)))
+}
+
+func newRequest(method, path string, c *contextmodel.ReqContext, headers map[string]string) *http.Request {
+	r := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	if c != nil {
+		r = r.WithContext(ctxkey.Set(r.Context(), c))
+	}
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LoadShedding = setting.LoadSheddingSettings{Enabled: true, InitialLimit: 10, MinLimit: 10, MaxLimit: 10, RetryAfter: 1500 * time.Millisecond}
+
+	reg := prometheus.NewRegistry()
+	release := make(chan struct{})
+	blocked := make(chan struct{})
+	handler := Middleware(cfg, reg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/slow" {
+			blocked <- struct{}{}
+			<-release
+		}
+	}))
+
+	session := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{UserID: 1}, UserToken: &usertoken.UserToken{}}
+	token := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{UserID: 2, IsServiceAccount: true}}
+	serve := func(path string, c *contextmodel.ReqContext) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(http.MethodGet, path, c, nil))
+		return rec
+	}
+
+	// automation can use 7 of the 10 slots
+	var wg sync.WaitGroup
+	for i := 0; i < 7; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve("/api/slow", token)
+		}()
+		<-blocked
+	}
+
+	rec := serve("/api/search", token)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	require.Equal(t, http.StatusOK, serve("/api/search", session).Code, "interactive requests have a higher priority")
+	require.Equal(t, http.StatusOK, serve("/api/health", nil).Code)
+
+	close(release)
+	wg.Wait()
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP grafana_http_load_shedding_in_flight_requests Number of requests counted against the concurrency limit.
+# TYPE grafana_http_load_shedding_in_flight_requests gauge
+grafana_http_load_shedding_in_flight_requests 0
+# HELP grafana_http_load_shedding_requests_total Number of requests admitted or shed by priority class.
+# TYPE grafana_http_load_shedding_requests_total counter
+grafana_http_load_shedding_requests_total{class="automation",result="admitted"} 7
+grafana_http_load_shedding_requests_total{class="automation",result="shed"} 1
+grafana_http_load_shedding_requests_total{class="interactive",result="admitted"} 1
+`), "grafana_http_load_shedding_in_flight_requests", "grafana_http_load_shedding_requests_total"))
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(100, 10, 200)
+
+	// a stable latency under load increases the limit, up to the maximum
+	for i := 0; i < 100; i++ {
+		l.update(0.1, 100)
+	}
+	limit, _ := l.state()
+	require.Equal(t, 200.0, limit)
+
+	// an increasing latency decreases it
+	for i := 0; i < 20; i++ {
+		l.update(1, 200)
+	}
+	decreased, _ := l.state()
+	require.Less(t, decreased, limit)
+
+	// down to the minimum
+	for i := 0; i < 50; i++ {
+		l.update(10, 200)
+	}
+	limit, _ = l.state()
+	require.Equal(t, 10.0, limit)
+
+	// the limit doesn't grow when it isn't used
+	l = newLimiter(100, 10, 200)
+	for i := 0; i < 100; i++ {
+		l.update(0.1, 10)
+	}
+	limit, _ = l.state()
+	require.Equal(t, 100.0, limit)
+}
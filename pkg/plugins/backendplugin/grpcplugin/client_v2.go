@@ -5,15 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/grpcplugin"
 	"github.com/grafana/grafana-plugin-sdk-go/genproto/pluginv2"
 	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/secretsmanagerplugin"
 	"github.com/grafana/grafana/pkg/plugins/log"
@@ -179,13 +182,22 @@ func (c *ClientV2) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	protoReq := backend.ToProto().QueryDataRequest(req)
-	protoResp, err := c.DataClient.QueryData(ctx, protoReq)
+	var opts []grpc.CallOption
+	if maxBytes := backendplugin.MaxResponseBytes(ctx); maxBytes > 0 && maxBytes <= math.MaxInt32 {
+		// the response is rejected while it is received instead of once it is buffered
+		opts = append(opts, grpc.MaxCallRecvMsgSize(int(maxBytes)))
+	}
+	protoResp, err := c.DataClient.QueryData(ctx, protoReq, opts...)
 
 	if err != nil {
 		if status.Code(err) == codes.Unimplemented {
 			return nil, plugins.ErrMethodNotImplemented
 		}
 
+		if len(opts) > 0 && status.Code(err) == codes.ResourceExhausted {
+			return nil, plugins.ErrResponseTooLarge
+		}
+
 		return nil, fmt.Errorf("%v: %w", "Failed to query data", err)
 	}
 
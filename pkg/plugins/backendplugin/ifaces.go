@@ -28,6 +28,12 @@ type Plugin interface {
 	backend.StreamHandler
 }
 
+// ProcessPlugin is implemented by backend plugins running in their own process.
+type ProcessPlugin interface {
+	// PID returns the process ID of the running plugin process, if any.
+	PID() (int, bool)
+}
+
 type Target string
 
 const (
@@ -0,0 +1,21 @@
+package backendplugin
+
+import "context"
+
+type maxResponseBytesKey struct{}
+
+// WithMaxResponseBytes returns a context which limits the size of the responses received from the backend
+// plugins, and of the HTTP responses the core plugins receive from their data sources. The responses over the
+// limit fail with plugins.ErrResponseTooLarge while they are received.
+func WithMaxResponseBytes(ctx context.Context, maxBytes int64) context.Context {
+	if maxBytes <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxResponseBytesKey{}, maxBytes)
+}
+
+// MaxResponseBytes returns the response size limit of the context, or 0 when the size is unlimited.
+func MaxResponseBytes(ctx context.Context) int64 {
+	maxBytes, _ := ctx.Value(maxResponseBytesKey{}).(int64)
+	return maxBytes
+}
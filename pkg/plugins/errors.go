@@ -18,6 +18,11 @@ var (
 	// ErrMethodNotImplemented error returned when a plugin method is not implemented.
 	ErrMethodNotImplemented = errMethodNotImplementedBase.Errorf("method not implemented")
 
+	errResponseTooLargeBase = errutil.BadRequest("plugin.responseTooLarge",
+		errutil.WithPublicMessage("Plugin response too large"))
+	// ErrResponseTooLarge error returned when a plugin response exceeds the size limit of the request.
+	ErrResponseTooLarge = errResponseTooLargeBase.Errorf("plugin response exceeds the size limit")
+
 	// ErrPluginHealthCheck error returned when a plugin fails its health check.
 	// Exposed as a base error to wrap it with plugin error.
 	ErrPluginHealthCheck = errutil.Internal("plugin.healthCheck",
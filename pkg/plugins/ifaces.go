@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"archive/zip"
 	"context"
 	"io/fs"
 	"time"
@@ -15,6 +16,12 @@ type Installer interface {
 	Add(ctx context.Context, pluginID, version string, opts CompatOpts) error
 	// Remove removes an existing plugin.
 	Remove(ctx context.Context, pluginID, version string) error
+	// AddFromArchive adds a new plugin, or upgrades it, from a plugin archive.
+	AddFromArchive(ctx context.Context, pluginID string, archive *zip.ReadCloser, opts CompatOpts) error
+	// Rollback replaces a plugin with one of its previous versions, or the most recent one if version is empty.
+	Rollback(ctx context.Context, pluginID, version string) error
+	// Versions returns the previous versions of a plugin it can be rolled back to.
+	Versions(ctx context.Context, pluginID string) ([]string, error)
 }
 
 type PluginSource interface {
@@ -56,6 +56,10 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			return nil, err
 		}
 
+		if errors.Is(err, plugins.ErrResponseTooLarge) {
+			return nil, err
+		}
+
 		if errors.Is(err, context.Canceled) {
 			return nil, plugins.ErrPluginRequestCanceledErrorBase.Errorf("client: query data request canceled: %w", err)
 		}
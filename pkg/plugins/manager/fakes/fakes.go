@@ -21,7 +21,10 @@ import (
 type FakePluginInstaller struct {
 	AddFunc func(ctx context.Context, pluginID, version string, opts plugins.CompatOpts) error
 	// Remove removes a plugin from the store.
-	RemoveFunc func(ctx context.Context, pluginID, version string) error
+	RemoveFunc         func(ctx context.Context, pluginID, version string) error
+	AddFromArchiveFunc func(ctx context.Context, pluginID string, archive *zip.ReadCloser, opts plugins.CompatOpts) error
+	RollbackFunc       func(ctx context.Context, pluginID, version string) error
+	VersionsFunc       func(ctx context.Context, pluginID string) ([]string, error)
 }
 
 func (i *FakePluginInstaller) Add(ctx context.Context, pluginID, version string, opts plugins.CompatOpts) error {
@@ -38,6 +41,27 @@ func (i *FakePluginInstaller) Remove(ctx context.Context, pluginID, version stri
 	return nil
 }
 
+func (i *FakePluginInstaller) AddFromArchive(ctx context.Context, pluginID string, archive *zip.ReadCloser, opts plugins.CompatOpts) error {
+	if i.AddFromArchiveFunc != nil {
+		return i.AddFromArchiveFunc(ctx, pluginID, archive, opts)
+	}
+	return nil
+}
+
+func (i *FakePluginInstaller) Rollback(ctx context.Context, pluginID, version string) error {
+	if i.RollbackFunc != nil {
+		return i.RollbackFunc(ctx, pluginID, version)
+	}
+	return nil
+}
+
+func (i *FakePluginInstaller) Versions(ctx context.Context, pluginID string) ([]string, error) {
+	if i.VersionsFunc != nil {
+		return i.VersionsFunc(ctx, pluginID)
+	}
+	return []string{}, nil
+}
+
 type FakeLoader struct {
 	LoadFunc   func(_ context.Context, _ plugins.PluginSource) ([]*plugins.Plugin, error)
 	UnloadFunc func(_ context.Context, _ *plugins.Plugin) (*plugins.Plugin, error)
@@ -268,7 +292,11 @@ func (r *FakePluginRepo) PluginVersion(pluginID, version string, compatOpts repo
 }
 
 type FakePluginStorage struct {
-	ExtractFunc func(_ context.Context, pluginID string, dirNameFunc storage.DirNameGeneratorFunc, z *zip.ReadCloser) (*storage.ExtractedPluginArchive, error)
+	ExtractFunc  func(_ context.Context, pluginID string, dirNameFunc storage.DirNameGeneratorFunc, z *zip.ReadCloser) (*storage.ExtractedPluginArchive, error)
+	ArchiveFunc  func(_ context.Context, pluginID, version, pluginDir string) error
+	RestoreFunc  func(_ context.Context, pluginID, version string, dirNameFunc storage.DirNameGeneratorFunc) (*storage.ExtractedPluginArchive, error)
+	VersionsFunc func(_ context.Context, pluginID string) ([]string, error)
+	DeleteFunc   func(_ context.Context, pluginID string) error
 }
 
 func NewFakePluginStorage() *FakePluginStorage {
@@ -282,6 +310,56 @@ func (s *FakePluginStorage) Extract(ctx context.Context, pluginID string, dirNam
 	return &storage.ExtractedPluginArchive{}, nil
 }
 
+func (s *FakePluginStorage) Archive(ctx context.Context, pluginID, version, pluginDir string) error {
+	if s.ArchiveFunc != nil {
+		return s.ArchiveFunc(ctx, pluginID, version, pluginDir)
+	}
+	return nil
+}
+
+func (s *FakePluginStorage) Restore(ctx context.Context, pluginID, version string, dirNameFunc storage.DirNameGeneratorFunc) (*storage.ExtractedPluginArchive, error) {
+	if s.RestoreFunc != nil {
+		return s.RestoreFunc(ctx, pluginID, version, dirNameFunc)
+	}
+	return &storage.ExtractedPluginArchive{}, nil
+}
+
+func (s *FakePluginStorage) Versions(ctx context.Context, pluginID string) ([]string, error) {
+	if s.VersionsFunc != nil {
+		return s.VersionsFunc(ctx, pluginID)
+	}
+	return []string{}, nil
+}
+
+func (s *FakePluginStorage) Delete(ctx context.Context, pluginID string) error {
+	if s.DeleteFunc != nil {
+		return s.DeleteFunc(ctx, pluginID)
+	}
+	return nil
+}
+
+type FakeErrorResolver struct {
+	Errors map[string]*plugins.Error
+}
+
+func NewFakeErrorResolver() *FakeErrorResolver {
+	return &FakeErrorResolver{
+		Errors: make(map[string]*plugins.Error),
+	}
+}
+
+func (r *FakeErrorResolver) PluginErrors(_ context.Context) []*plugins.Error {
+	errs := make([]*plugins.Error, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (r *FakeErrorResolver) PluginError(_ context.Context, pluginID string) *plugins.Error {
+	return r.Errors[pluginID]
+}
+
 type FakePluginEnvProvider struct {
 	PluginEnvVarsFunc func(ctx context.Context, plugin *plugins.Plugin) []string
 }
@@ -1,9 +1,11 @@
 package manager
 
 import (
+	"archive/zip"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/grafana/grafana/pkg/plugins"
@@ -22,29 +24,33 @@ var _ plugins.Installer = (*PluginInstaller)(nil)
 type PluginInstaller struct {
 	pluginRepo           repo.Service
 	pluginStorage        storage.ZipExtractor
+	pluginVersions       storage.VersionStore
 	pluginStorageDirFunc storage.DirNameGeneratorFunc
 	pluginRegistry       registry.Service
 	pluginLoader         loader.Service
+	pluginErrorResolver  plugins.ErrorResolver
 	installing           sync.Map
 	log                  log.Logger
 	serviceRegistry      auth.ExternalServiceRegistry
 }
 
 func ProvideInstaller(cfg *config.PluginManagementCfg, pluginRegistry registry.Service, pluginLoader loader.Service,
-	pluginRepo repo.Service, serviceRegistry auth.ExternalServiceRegistry) *PluginInstaller {
-	return New(pluginRegistry, pluginLoader, pluginRepo,
-		storage.FileSystem(log.NewPrettyLogger("installer.fs"), cfg.PluginsPath), storage.SimpleDirNameGeneratorFunc, serviceRegistry)
+	pluginRepo repo.Service, serviceRegistry auth.ExternalServiceRegistry, pluginErrorResolver plugins.ErrorResolver) *PluginInstaller {
+	fs := storage.FileSystem(log.NewPrettyLogger("installer.fs"), cfg.PluginsPath)
+	return New(pluginRegistry, pluginLoader, pluginRepo, fs, fs, storage.SimpleDirNameGeneratorFunc, serviceRegistry, pluginErrorResolver)
 }
 
 func New(pluginRegistry registry.Service, pluginLoader loader.Service, pluginRepo repo.Service,
-	pluginStorage storage.ZipExtractor, pluginStorageDirFunc storage.DirNameGeneratorFunc,
-	serviceRegistry auth.ExternalServiceRegistry) *PluginInstaller {
+	pluginStorage storage.ZipExtractor, pluginVersions storage.VersionStore, pluginStorageDirFunc storage.DirNameGeneratorFunc,
+	serviceRegistry auth.ExternalServiceRegistry, pluginErrorResolver plugins.ErrorResolver) *PluginInstaller {
 	return &PluginInstaller{
 		pluginLoader:         pluginLoader,
 		pluginRegistry:       pluginRegistry,
 		pluginRepo:           pluginRepo,
 		pluginStorage:        pluginStorage,
+		pluginVersions:       pluginVersions,
 		pluginStorageDirFunc: pluginStorageDirFunc,
+		pluginErrorResolver:  pluginErrorResolver,
 		installing:           sync.Map{},
 		log:                  log.New("plugin.installer"),
 		serviceRegistry:      serviceRegistry,
@@ -65,38 +71,196 @@ func (m *PluginInstaller) Add(ctx context.Context, pluginID, version string, opt
 		m.installing.Delete(pluginID)
 	}()
 
-	archive, err := m.install(ctx, pluginID, version, compatOpts)
+	archive, previous, err := m.install(ctx, pluginID, version, compatOpts)
 	if err != nil {
 		return err
 	}
 
+	if err = m.addDependencies(ctx, pluginID, archive, previous, opts); err != nil {
+		return err
+	}
+
+	return m.load(ctx, pluginID, archive, previous)
+}
+
+// AddFromArchive installs a plugin, or upgrades it, from an archive instead of the plugin repository. The
+// dependencies of the plugin are installed from the repository.
+func (m *PluginInstaller) AddFromArchive(ctx context.Context, pluginID string, pluginArchive *zip.ReadCloser, opts plugins.CompatOpts) error {
+	if ok, _ := m.installing.Load(pluginID); ok != nil {
+		return plugins.ErrPluginInstallInProgress
+	}
+	m.installing.Store(pluginID, true)
+	defer func() {
+		m.installing.Delete(pluginID)
+	}()
+
+	previous, err := m.archiveInstalled(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+
+	m.log.Info("Installing plugin from archive", "pluginId", pluginID)
+	archive, err := m.pluginStorage.Extract(ctx, pluginID, m.pluginStorageDirFunc, pluginArchive)
+	if err != nil {
+		m.restore(ctx, pluginID, "", previous)
+		return err
+	}
+	if archive.ID != pluginID {
+		m.restore(ctx, pluginID, archive.Path, previous)
+		return fmt.Errorf("%w: found %q instead of %q", plugins.ErrPluginArchiveMismatch, archive.ID, pluginID)
+	}
+
+	if err = m.addDependencies(ctx, pluginID, archive, previous, opts); err != nil {
+		return err
+	}
+
+	return m.load(ctx, pluginID, archive, previous)
+}
+
+// Rollback replaces the installed version of a plugin with one of its previous versions, or with the most
+// recent one if version is empty. The replaced version is kept, so that the rollback can be reverted.
+func (m *PluginInstaller) Rollback(ctx context.Context, pluginID, version string) error {
+	if ok, _ := m.installing.Load(pluginID); ok != nil {
+		return plugins.ErrPluginInstallInProgress
+	}
+	m.installing.Store(pluginID, true)
+	defer func() {
+		m.installing.Delete(pluginID)
+	}()
+
+	versions, err := m.pluginVersions.Versions(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+	if version == "" && len(versions) > 0 {
+		version = versions[0]
+	}
+	if !slices.Contains(versions, version) {
+		return plugins.ErrPluginVersionNotFound
+	}
+
+	if plugin, exists := m.plugin(ctx, pluginID, ""); exists && plugin.Info.Version == version {
+		return plugins.DuplicateError{PluginID: pluginID}
+	}
+
+	previous, err := m.archiveInstalled(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+
+	m.log.Info("Rolling back plugin", "pluginId", pluginID, "version", version)
+	archive, err := m.pluginVersions.Restore(ctx, pluginID, version, m.pluginStorageDirFunc)
+	if err != nil {
+		m.restore(ctx, pluginID, "", previous)
+		if errors.Is(err, storage.ErrVersionNotFound) {
+			return plugins.ErrPluginVersionNotFound
+		}
+		return err
+	}
+
+	// the dependencies of the restored version were installed with it
+	return m.load(ctx, pluginID, archive, previous)
+}
+
+// Versions returns the previous versions of a plugin that it can be rolled back to, the most recent first.
+func (m *PluginInstaller) Versions(ctx context.Context, pluginID string) ([]string, error) {
+	return m.pluginVersions.Versions(ctx, pluginID)
+}
+
+// addDependencies installs the dependencies of an extracted plugin. If one cannot be installed, the plugin is
+// removed and its previous version is restored.
+func (m *PluginInstaller) addDependencies(ctx context.Context, pluginID string, archive *storage.ExtractedPluginArchive, previous *plugins.Plugin, opts plugins.CompatOpts) error {
 	for _, dep := range archive.Dependencies {
 		m.log.Info(fmt.Sprintf("Fetching %s dependency %s...", pluginID, dep.ID))
 
-		err = m.Add(ctx, dep.ID, dep.Version, opts)
+		err := m.Add(ctx, dep.ID, dep.Version, opts)
 		if err != nil {
+			m.restore(ctx, pluginID, archive.Path, previous)
 			return fmt.Errorf("%v: %w", fmt.Sprintf("failed to download plugin %s from repository", dep.ID), err)
 		}
 	}
+	return nil
+}
 
-	_, err = m.pluginLoader.Load(ctx, sources.NewLocalSource(plugins.ClassExternal, []string{archive.Path}))
+// load loads an extracted plugin. If the plugin cannot be loaded, for example because its signature is
+// invalid, it is removed and its previous version is restored.
+func (m *PluginInstaller) load(ctx context.Context, pluginID string, archive *storage.ExtractedPluginArchive, previous *plugins.Plugin) error {
+	loaded, err := m.pluginLoader.Load(ctx, sources.NewLocalSource(plugins.ClassExternal, []string{archive.Path}))
 	if err != nil {
 		m.log.Error("Could not load plugins", "path", archive.Path, "error", err)
+		m.restore(ctx, pluginID, archive.Path, previous)
 		return err
 	}
 
+	// the loader skips the plugins it cannot load, like the plugins with an invalid signature, and records why
+	if !slices.ContainsFunc(loaded, func(p *plugins.Plugin) bool { return p.ID == pluginID }) {
+		if loadErr := m.pluginErrorResolver.PluginError(ctx, pluginID); loadErr != nil {
+			m.log.Error("Could not load plugin", "pluginId", pluginID, "path", archive.Path, "error", loadErr)
+			m.restore(ctx, pluginID, archive.Path, previous)
+			return loadErr
+		}
+	}
+
 	return nil
 }
 
-func (m *PluginInstaller) install(ctx context.Context, pluginID, version string, compatOpts repo.CompatOpts) (*storage.ExtractedPluginArchive, error) {
+// archiveInstalled unloads the installed version of a plugin, if any, and keeps it to be restored.
+func (m *PluginInstaller) archiveInstalled(ctx context.Context, pluginID string) (*plugins.Plugin, error) {
+	plugin, exists := m.plugin(ctx, pluginID, "")
+	if !exists {
+		return nil, nil
+	}
+	if plugin.IsCorePlugin() || plugin.IsBundledPlugin() {
+		return nil, plugins.ErrInstallCorePlugin
+	}
+
+	if _, err := m.pluginLoader.Unload(ctx, plugin); err != nil {
+		return nil, err
+	}
+	if err := m.pluginVersions.Archive(ctx, plugin.ID, plugin.Info.Version, plugin.FS.Base()); err != nil {
+		m.log.Error("Could not keep the previous version of the plugin", "pluginId", plugin.ID, "version", plugin.Info.Version, "error", err)
+		if _, loadErr := m.pluginLoader.Load(ctx, sources.NewLocalSource(plugins.ClassExternal, []string{plugin.FS.Base()})); loadErr != nil {
+			m.log.Error("Could not load plugins", "path", plugin.FS.Base(), "error", loadErr)
+		}
+		return nil, err
+	}
+	return plugin, nil
+}
+
+// restore removes a plugin that failed to install, and restores and loads its previous version, if any.
+func (m *PluginInstaller) restore(ctx context.Context, pluginID, failedPath string, previous *plugins.Plugin) {
+	if failedPath != "" {
+		if err := plugins.NewLocalFS(failedPath).Remove(); err != nil {
+			m.log.Warn("Could not remove the plugin that failed to install", "pluginId", pluginID, "path", failedPath, "error", err)
+		}
+	}
+	if previous == nil {
+		return
+	}
+
+	m.log.Info("Restoring previous version of plugin", "pluginId", pluginID, "version", previous.Info.Version)
+	archive, err := m.pluginVersions.Restore(ctx, pluginID, previous.Info.Version, m.pluginStorageDirFunc)
+	if err != nil {
+		m.log.Error("Could not restore the previous version of the plugin", "pluginId", pluginID, "version", previous.Info.Version, "error", err)
+		return
+	}
+	if _, err = m.pluginLoader.Load(ctx, sources.NewLocalSource(plugins.ClassExternal, []string{archive.Path})); err != nil {
+		m.log.Error("Could not load plugins", "path", archive.Path, "error", err)
+	}
+}
+
+// install downloads and extracts a plugin. When the plugin is upgraded, it also returns the previous version,
+// which is kept to be restored.
+func (m *PluginInstaller) install(ctx context.Context, pluginID, version string, compatOpts repo.CompatOpts) (*storage.ExtractedPluginArchive, *plugins.Plugin, error) {
 	var pluginArchive *repo.PluginArchive
+	var previous *plugins.Plugin
 	if plugin, exists := m.plugin(ctx, pluginID, version); exists {
 		if plugin.IsCorePlugin() || plugin.IsBundledPlugin() {
-			return nil, plugins.ErrInstallCorePlugin
+			return nil, nil, plugins.ErrInstallCorePlugin
 		}
 
 		if plugin.Info.Version == version {
-			return nil, plugins.DuplicateError{
+			return nil, nil, plugins.DuplicateError{
 				PluginID: plugin.ID,
 			}
 		}
@@ -104,54 +268,55 @@ func (m *PluginInstaller) install(ctx context.Context, pluginID, version string,
 		// get plugin update information to confirm if target update is possible
 		pluginArchiveInfo, err := m.pluginRepo.GetPluginArchiveInfo(ctx, pluginID, version, compatOpts)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		m.log.Info("Updating plugin", "pluginId", pluginID, "from", plugin.Info.Version, "to", pluginArchiveInfo.Version)
 
 		// if existing plugin version is the same as the target update version
 		if pluginArchiveInfo.Version == plugin.Info.Version {
-			return nil, plugins.DuplicateError{
+			return nil, nil, plugins.DuplicateError{
 				PluginID: plugin.ID,
 			}
 		}
 
 		if pluginArchiveInfo.URL == "" && pluginArchiveInfo.Version == "" {
-			return nil, fmt.Errorf("could not determine update options for %s", pluginID)
-		}
-
-		// remove existing installation of plugin
-		err = m.Remove(ctx, plugin.ID, plugin.Info.Version)
-		if err != nil {
-			return nil, err
+			return nil, nil, fmt.Errorf("could not determine update options for %s", pluginID)
 		}
 
 		if pluginArchiveInfo.URL != "" {
 			pluginArchive, err = m.pluginRepo.GetPluginArchiveByURL(ctx, pluginArchiveInfo.URL, compatOpts)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		} else {
 			pluginArchive, err = m.pluginRepo.GetPluginArchive(ctx, pluginID, pluginArchiveInfo.Version, compatOpts)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
+
+		// keep the existing installation of the plugin, to restore it if the update fails or is rolled back
+		previous, err = m.archiveInstalled(ctx, pluginID)
+		if err != nil {
+			return nil, nil, err
+		}
 	} else {
 		var err error
 		pluginArchive, err = m.pluginRepo.GetPluginArchive(ctx, pluginID, version, compatOpts)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		m.log.Info("Installing plugin", "pluginId", pluginID, "version", version)
 	}
 
 	extractedArchive, err := m.pluginStorage.Extract(ctx, pluginID, m.pluginStorageDirFunc, pluginArchive.File)
 	if err != nil {
-		return nil, err
+		m.restore(ctx, pluginID, "", previous)
+		return nil, nil, err
 	}
 
-	return extractedArchive, nil
+	return extractedArchive, previous, nil
 }
 
 func (m *PluginInstaller) Remove(ctx context.Context, pluginID, version string) error {
@@ -175,6 +340,10 @@ func (m *PluginInstaller) Remove(ctx context.Context, pluginID, version string)
 		}
 	}
 
+	if err = m.pluginVersions.Delete(ctx, pluginID); err != nil {
+		m.log.Warn("Could not remove the previous versions of the plugin", "pluginId", pluginID, "error", err)
+	}
+
 	has, err := m.serviceRegistry.HasExternalService(ctx, pluginID)
 	if err == nil && has {
 		return m.serviceRegistry.RemoveExternalService(ctx, pluginID)
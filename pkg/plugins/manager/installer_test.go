@@ -67,7 +67,7 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 			},
 		}
 
-		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{})
+		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{}, fakes.NewFakeErrorResolver())
 		err := inst.Add(context.Background(), pluginID, v1, testCompatOpts())
 		require.NoError(t, err)
 
@@ -169,7 +169,7 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 				},
 			}
 
-			pm := New(reg, &fakes.FakeLoader{}, &fakes.FakePluginRepo{}, &fakes.FakePluginStorage{}, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{})
+			pm := New(reg, &fakes.FakeLoader{}, &fakes.FakePluginRepo{}, &fakes.FakePluginStorage{}, &fakes.FakePluginStorage{}, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{}, fakes.NewFakeErrorResolver())
 			err := pm.Add(context.Background(), p.ID, "3.2.0", testCompatOpts())
 			require.ErrorIs(t, err, plugins.ErrInstallCorePlugin)
 
@@ -227,7 +227,7 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 			},
 		}
 
-		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{})
+		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{}, fakes.NewFakeErrorResolver())
 		err := inst.Add(context.Background(), p3, "", testCompatOpts())
 		require.NoError(t, err)
 		require.Equal(t, []string{p1Zip, p2Zip, p3Zip}, loadedPaths)
@@ -274,13 +274,117 @@ func TestPluginManager_Add_Remove(t *testing.T) {
 			},
 		}
 
-		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{})
+		inst := New(fakes.NewFakePluginRegistry(), loader, pluginRepo, fs, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{}, fakes.NewFakeErrorResolver())
 		err := inst.Add(context.Background(), p1, "", testCompatOpts())
 		require.NoError(t, err)
 		require.Equal(t, []string{p2Zip, p1Zip}, loadedPaths)
 	})
 }
 
+func TestPluginManager_Rollback(t *testing.T) {
+	const (
+		pluginID   = "test-panel"
+		v1, v2, v3 = "1.0.0", "2.0.0", "3.0.0"
+	)
+
+	newInstaller := func(t *testing.T) (*PluginInstaller, *fakes.FakePluginRegistry, *fakes.FakePluginStorage, *fakes.FakeErrorResolver, *[]string) {
+		reg := &fakes.FakePluginRegistry{
+			Store: map[string]*plugins.Plugin{
+				pluginID: createPlugin(t, pluginID, plugins.ClassExternal, true, true, func(plugin *plugins.Plugin) {
+					plugin.Info.Version = v2
+				}),
+			},
+		}
+		var archived []string
+		fs := &fakes.FakePluginStorage{
+			ArchiveFunc: func(_ context.Context, id, version, dir string) error {
+				require.Equal(t, pluginID, id)
+				require.Equal(t, pluginID, dir)
+				archived = append(archived, version)
+				return nil
+			},
+			RestoreFunc: func(_ context.Context, id, version string, _ storage.DirNameGeneratorFunc) (*storage.ExtractedPluginArchive, error) {
+				return &storage.ExtractedPluginArchive{ID: id, Version: version, Path: id + "-" + version}, nil
+			},
+			VersionsFunc: func(_ context.Context, _ string) ([]string, error) {
+				return []string{v1}, nil
+			},
+		}
+		loader := &fakes.FakeLoader{
+			LoadFunc: func(ctx context.Context, src plugins.PluginSource) ([]*plugins.Plugin, error) {
+				path := src.PluginURIs(ctx)[0]
+				version := path[len(pluginID)+1:]
+				p := createPlugin(t, pluginID, plugins.ClassExternal, true, true, func(plugin *plugins.Plugin) {
+					plugin.Info.Version = version
+				})
+				reg.Store[pluginID] = p
+				return []*plugins.Plugin{p}, nil
+			},
+			UnloadFunc: func(_ context.Context, p *plugins.Plugin) (*plugins.Plugin, error) {
+				delete(reg.Store, p.ID)
+				return p, nil
+			},
+		}
+		errs := fakes.NewFakeErrorResolver()
+		return New(reg, loader, &fakes.FakePluginRepo{}, fs, fs, storage.SimpleDirNameGeneratorFunc, &fakes.FakeAuthService{}, errs), reg, fs, errs, &archived
+	}
+
+	t.Run("Rolls back to the previous version and keeps the replaced one", func(t *testing.T) {
+		inst, reg, _, _, archived := newInstaller(t)
+
+		err := inst.Rollback(context.Background(), pluginID, "")
+		require.NoError(t, err)
+		require.Equal(t, v1, reg.Store[pluginID].Info.Version)
+		require.Equal(t, []string{v2}, *archived)
+	})
+
+	t.Run("Can't roll back to a version that is not kept", func(t *testing.T) {
+		inst, reg, _, _, archived := newInstaller(t)
+
+		err := inst.Rollback(context.Background(), pluginID, v3)
+		require.ErrorIs(t, err, plugins.ErrPluginVersionNotFound)
+		require.Equal(t, v2, reg.Store[pluginID].Info.Version)
+		require.Empty(t, *archived)
+	})
+
+	t.Run("Restores the previous version if the new one cannot be loaded", func(t *testing.T) {
+		inst, reg, fs, errs, archived := newInstaller(t)
+		fs.ExtractFunc = func(_ context.Context, id string, _ storage.DirNameGeneratorFunc, _ *zip.ReadCloser) (*storage.ExtractedPluginArchive, error) {
+			return &storage.ExtractedPluginArchive{ID: id, Version: v3, Path: id + "-" + v3}, nil
+		}
+		inst.pluginLoader.(*fakes.FakeLoader).LoadFunc = func(ctx context.Context, src plugins.PluginSource) ([]*plugins.Plugin, error) {
+			path := src.PluginURIs(ctx)[0]
+			if path == pluginID+"-"+v3 {
+				errs.Errors[pluginID] = &plugins.Error{PluginID: pluginID, SignatureStatus: plugins.SignatureStatusInvalid}
+				return []*plugins.Plugin{}, nil
+			}
+			p := createPlugin(t, pluginID, plugins.ClassExternal, true, true, func(plugin *plugins.Plugin) {
+				plugin.Info.Version = path[len(pluginID)+1:]
+			})
+			reg.Store[pluginID] = p
+			return []*plugins.Plugin{p}, nil
+		}
+
+		err := inst.AddFromArchive(context.Background(), pluginID, &zip.ReadCloser{}, testCompatOpts())
+		var loadErr *plugins.Error
+		require.ErrorAs(t, err, &loadErr)
+		require.Equal(t, plugins.SignatureStatusInvalid, loadErr.SignatureStatus)
+		require.Equal(t, v2, reg.Store[pluginID].Info.Version)
+		require.Equal(t, []string{v2}, *archived)
+	})
+
+	t.Run("Rejects an archive of another plugin", func(t *testing.T) {
+		inst, reg, fs, _, _ := newInstaller(t)
+		fs.ExtractFunc = func(_ context.Context, _ string, _ storage.DirNameGeneratorFunc, _ *zip.ReadCloser) (*storage.ExtractedPluginArchive, error) {
+			return &storage.ExtractedPluginArchive{ID: "other-panel", Version: v3, Path: pluginID + "-" + v3}, nil
+		}
+
+		err := inst.AddFromArchive(context.Background(), pluginID, &zip.ReadCloser{}, testCompatOpts())
+		require.ErrorIs(t, err, plugins.ErrPluginArchiveMismatch)
+		require.Equal(t, v2, reg.Store[pluginID].Info.Version)
+	})
+}
+
 func createPlugin(t *testing.T, pluginID string, class plugins.Class, managed, backend bool, cbs ...func(*plugins.Plugin)) *plugins.Plugin {
 	t.Helper()
 
@@ -292,6 +396,7 @@ func createPlugin(t *testing.T, pluginID string, class plugins.Class, managed, b
 			Backend: backend,
 		},
 	}
+	p.FS = fakes.NewFakePluginFS(pluginID)
 	p.SetLogger(log.NewTestLogger())
 	if p.Backend {
 		p.RegisterClient(&fakes.FakePluginClient{
@@ -2,28 +2,76 @@ package process
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/config"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginerrs"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const defaultKeepPluginAliveTickerDuration = time.Second
 
 type Service struct {
 	keepPluginAliveTickerDuration time.Duration
+	limits                        setting.PluginLimitsSettings
+	errorTracker                  pluginerrs.ErrorTracker
+	readUsage                     func(pid int) (Usage, error)
+	now                           func() time.Time
+
+	mu     sync.RWMutex
+	states map[string]*state
 }
 
-func ProvideService() *Service {
+func ProvideService(cfg *config.PluginManagementCfg, errorTracker pluginerrs.ErrorTracker) *Service {
 	return &Service{
 		keepPluginAliveTickerDuration: defaultKeepPluginAliveTickerDuration,
+		limits:                        cfg.PluginLimits,
+		errorTracker:                  errorTracker,
+		readUsage:                     readProcessUsage,
+		now:                           time.Now,
+		states:                        make(map[string]*state),
 	}
 }
 
+// Usage is the resource usage of a backend plugin process.
+type Usage struct {
+	MemoryBytes uint64
+	CPUSeconds  float64
+}
+
+// Status reports the state and resource usage of a backend plugin process.
+type Status struct {
+	PluginID string
+	PID      int
+	Running  bool
+	// Disabled is true when the plugin exceeded its restart budget and is no longer restarted.
+	Disabled bool
+	// Restarts is the number of restarts since the plugin was started.
+	Restarts    int
+	LastRestart time.Time
+	MemoryBytes uint64
+	CPUSeconds  float64
+	// CPUUsage is the number of CPUs used by the process since the previous sample.
+	CPUUsage  float64
+	SampledAt time.Time
+	Limits    setting.PluginLimits
+}
+
+type state struct {
+	Status
+	plugin   *plugins.Plugin
+	restarts []time.Time
+}
+
 func (s *Service) Start(ctx context.Context, p *plugins.Plugin) error {
 	if !p.IsManaged() || !p.Backend || p.Error != nil {
 		return nil
 	}
 
+	s.resetState(ctx, p)
+
 	if err := s.startPluginAndKeepItAlive(ctx, p); err != nil {
 		return err
 	}
@@ -32,7 +80,7 @@ func (s *Service) Start(ctx context.Context, p *plugins.Plugin) error {
 	return nil
 }
 
-func (*Service) Stop(ctx context.Context, p *plugins.Plugin) error {
+func (s *Service) Stop(ctx context.Context, p *plugins.Plugin) error {
 	p.Logger().Debug("Stopping plugin process")
 	if err := p.Decommission(); err != nil {
 		return err
@@ -42,9 +90,61 @@ func (*Service) Stop(ctx context.Context, p *plugins.Plugin) error {
 		return err
 	}
 
+	s.mu.Lock()
+	delete(s.states, p.ID)
+	s.mu.Unlock()
+
 	return nil
 }
 
+// Status returns the status of the backend plugin process of the provided plugin.
+func (s *Service) Status(pluginID string) (Status, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, exists := s.states[pluginID]
+	if !exists {
+		return Status{}, false
+	}
+	return s.snapshot(st), true
+}
+
+// Statuses returns the status of all backend plugin processes.
+func (s *Service) Statuses() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(s.states))
+	for _, st := range s.states {
+		statuses = append(statuses, s.snapshot(st))
+	}
+	return statuses
+}
+
+func (s *Service) snapshot(st *state) Status {
+	status := st.Status
+	status.Running = !status.Disabled && !st.plugin.Exited()
+	return status
+}
+
+// resetState clears the restart history of the plugin and any crash loop error recorded for it, since
+// the plugin is (re)installed or Grafana is starting it for the first time.
+func (s *Service) resetState(ctx context.Context, p *plugins.Plugin) {
+	s.mu.Lock()
+	s.states[p.ID] = &state{
+		Status: Status{
+			PluginID: p.ID,
+			Limits:   s.limits.Limits(p.ID),
+		},
+		plugin: p,
+	}
+	s.mu.Unlock()
+
+	if err := s.errorTracker.Error(ctx, p.ID); err != nil && err.ErrorCode == plugins.ErrorCodeCrashLoop {
+		s.errorTracker.Clear(ctx, p.ID)
+	}
+}
+
 func (s *Service) startPluginAndKeepItAlive(ctx context.Context, p *plugins.Plugin) error {
 	if err := p.Start(ctx); err != nil {
 		return err
@@ -63,12 +163,29 @@ func (s *Service) startPluginAndKeepItAlive(ctx context.Context, p *plugins.Plug
 	return nil
 }
 
-// keepPluginAlive will restart the plugin if the process is killed or exits
+// keepPluginAlive will restart the plugin if the process is killed or exits, as long as the plugin
+// has not used up its restart budget. It also samples the resource usage of the plugin process.
 func (s *Service) keepPluginAlive(p *plugins.Plugin) error {
 	ticker := time.NewTicker(s.keepPluginAliveTickerDuration)
+	defer ticker.Stop()
+
+	var usageTicker <-chan time.Time
+	if s.limits.ResourceUsageInterval > 0 {
+		t := time.NewTicker(s.limits.ResourceUsageInterval)
+		defer t.Stop()
+		usageTicker = t.C
+	}
 
 	for {
-		<-ticker.C
+		select {
+		case <-ticker.C:
+		case <-usageTicker:
+			if !p.IsDecommissioned() && !p.Exited() {
+				s.sampleUsage(p)
+			}
+			continue
+		}
+
 		if p.IsDecommissioned() {
 			p.Logger().Debug("Plugin decommissioned")
 			return nil
@@ -78,6 +195,11 @@ func (s *Service) keepPluginAlive(p *plugins.Plugin) error {
 			continue
 		}
 
+		if !s.allowRestart(p) {
+			s.disable(p)
+			return nil
+		}
+
 		p.Logger().Debug("Restarting plugin")
 		if err := p.Start(context.Background()); err != nil {
 			p.Logger().Error("Failed to restart plugin", "error", err)
@@ -86,3 +208,101 @@ func (s *Service) keepPluginAlive(p *plugins.Plugin) error {
 		p.Logger().Debug("Plugin restarted")
 	}
 }
+
+// allowRestart records a restart attempt of the plugin, and returns false if the plugin has already been
+// restarted as many times as its restart budget allows within the restart budget window.
+func (s *Service) allowRestart(p *plugins.Plugin) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, exists := s.states[p.ID]
+	if !exists {
+		return true
+	}
+
+	now := s.now()
+	if window := st.Limits.RestartBudgetWindow; window > 0 {
+		recent := st.restarts[:0]
+		for _, t := range st.restarts {
+			if now.Sub(t) < window {
+				recent = append(recent, t)
+			}
+		}
+		st.restarts = recent
+	}
+
+	if st.Limits.RestartBudget > 0 && len(st.restarts) >= st.Limits.RestartBudget {
+		return false
+	}
+
+	st.restarts = append(st.restarts, now)
+	st.Restarts++
+	st.LastRestart = now
+	return true
+}
+
+func (s *Service) disable(p *plugins.Plugin) {
+	s.mu.Lock()
+	var limits setting.PluginLimits
+	if st, exists := s.states[p.ID]; exists {
+		st.Disabled = true
+		limits = st.Limits
+	}
+	s.mu.Unlock()
+
+	p.Logger().Error("Plugin exceeded its restart budget and has been disabled",
+		"restartBudget", limits.RestartBudget, "restartBudgetWindow", limits.RestartBudgetWindow)
+	s.errorTracker.Record(context.Background(), &plugins.Error{
+		PluginID:  p.ID,
+		ErrorCode: plugins.ErrorCodeCrashLoop,
+	})
+}
+
+// sampleUsage reads the resource usage of the plugin process and stops the process when it uses more
+// memory than allowed, which makes keepPluginAlive restart it. The CPU limit is advisory, the process is only
+// reported when it uses more CPUs than allowed.
+func (s *Service) sampleUsage(p *plugins.Plugin) {
+	pid, ok := p.PID()
+	if !ok {
+		return
+	}
+
+	usage, err := s.readUsage(pid)
+	if err != nil {
+		p.Logger().Debug("Failed to read plugin process resource usage", "pid", pid, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	st, exists := s.states[p.ID]
+	if !exists {
+		s.mu.Unlock()
+		return
+	}
+	now := s.now()
+	if st.PID == pid && !st.SampledAt.IsZero() && now.After(st.SampledAt) {
+		st.CPUUsage = (usage.CPUSeconds - st.CPUSeconds) / now.Sub(st.SampledAt).Seconds()
+	} else {
+		st.CPUUsage = 0
+	}
+	st.PID = pid
+	st.MemoryBytes = usage.MemoryBytes
+	st.CPUSeconds = usage.CPUSeconds
+	st.SampledAt = now
+	limits := st.Limits
+	cpuUsage := st.CPUUsage
+	s.mu.Unlock()
+
+	if limits.MaxCPU > 0 && cpuUsage > limits.MaxCPU {
+		p.Logger().Warn("Plugin process uses more CPUs than its advisory limit",
+			"pid", pid, "cpuUsage", cpuUsage, "maxCPU", limits.MaxCPU)
+	}
+
+	if limits.MaxMemoryMB > 0 && usage.MemoryBytes > uint64(limits.MaxMemoryMB)*1024*1024 {
+		p.Logger().Warn("Plugin process exceeded its memory limit and will be restarted",
+			"pid", pid, "memoryBytes", usage.MemoryBytes, "maxMemoryMB", limits.MaxMemoryMB)
+		if err := p.Stop(context.Background()); err != nil {
+			p.Logger().Error("Failed to stop plugin process", "error", err)
+		}
+	}
+}
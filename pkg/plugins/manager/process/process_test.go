@@ -4,13 +4,17 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/config"
 	"github.com/grafana/grafana/pkg/plugins/log"
 	"github.com/grafana/grafana/pkg/plugins/manager/fakes"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginerrs"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestProcessManager_Start(t *testing.T) {
@@ -68,7 +72,7 @@ func TestProcessManager_Start(t *testing.T) {
 					plugin.Error = tc.Error
 				})
 
-				m := ProvideService()
+				m := ProvideService(&config.PluginManagementCfg{}, pluginerrs.ProvideErrorTracker())
 				err := m.Start(context.Background(), p)
 				require.NoError(t, err)
 				require.Equal(t, tc.expectedStartCount, bp.StartCount)
@@ -90,7 +94,7 @@ func TestProcessManager_Start(t *testing.T) {
 			plugin.Backend = true
 		})
 
-		m := ProvideService()
+		m := ProvideService(&config.PluginManagementCfg{}, pluginerrs.ProvideErrorTracker())
 		m.keepPluginAliveTickerDuration = 1
 		ctx := context.Background()
 		ctx, cancel := context.WithCancel(ctx)
@@ -119,7 +123,7 @@ func TestProcessManager_Stop(t *testing.T) {
 			plugin.Backend = true
 		})
 
-		m := ProvideService()
+		m := ProvideService(&config.PluginManagementCfg{}, pluginerrs.ProvideErrorTracker())
 		err := m.Stop(context.Background(), p)
 		require.NoError(t, err)
 
@@ -139,7 +143,7 @@ func TestProcessManager_ManagedBackendPluginLifecycle(t *testing.T) {
 			plugin.Backend = true
 		})
 
-		m := ProvideService()
+		m := ProvideService(&config.PluginManagementCfg{}, pluginerrs.ProvideErrorTracker())
 
 		err := m.Start(context.Background(), p)
 		require.NoError(t, err)
@@ -167,6 +171,114 @@ func TestProcessManager_ManagedBackendPluginLifecycle(t *testing.T) {
 	})
 }
 
+func TestProcessManager_RestartBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Plugin that exceeds its restart budget is disabled", func(t *testing.T) {
+		t.Parallel()
+
+		bp := fakes.NewFakeBackendPlugin(true)
+		p := createPlugin(t, bp, func(plugin *plugins.Plugin) {
+			plugin.Backend = true
+		})
+
+		errorTracker := pluginerrs.ProvideErrorTracker()
+		m := ProvideService(&config.PluginManagementCfg{
+			PluginLimits: setting.PluginLimitsSettings{
+				Default: setting.PluginLimits{RestartBudget: 2, RestartBudgetWindow: time.Hour},
+			},
+		}, errorTracker)
+		m.keepPluginAliveTickerDuration = time.Millisecond
+
+		err := m.Start(context.Background(), p)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, m.Stop(context.Background(), p))
+		})
+
+		for i := 0; i < 3; i++ {
+			bp.Kill()
+			require.Eventually(t, func() bool {
+				status, _ := m.Status(p.ID)
+				return status.Disabled || !bp.Exited()
+			}, time.Second, time.Millisecond)
+		}
+
+		status, exists := m.Status(p.ID)
+		require.True(t, exists)
+		require.True(t, status.Disabled)
+		require.False(t, status.Running)
+		require.Equal(t, 2, status.Restarts)
+		require.Equal(t, 3, bp.StartCount)
+
+		pluginErr := errorTracker.Error(context.Background(), p.ID)
+		require.NotNil(t, pluginErr)
+		require.Equal(t, plugins.ErrorCodeCrashLoop, pluginErr.ErrorCode)
+	})
+
+	t.Run("Restarts outside of the restart budget window are not counted", func(t *testing.T) {
+		t.Parallel()
+
+		bp := fakes.NewFakeBackendPlugin(true)
+		p := createPlugin(t, bp, func(plugin *plugins.Plugin) {
+			plugin.Backend = true
+		})
+
+		m := ProvideService(&config.PluginManagementCfg{
+			PluginLimits: setting.PluginLimitsSettings{
+				Default: setting.PluginLimits{RestartBudget: 1, RestartBudgetWindow: time.Minute},
+			},
+		}, pluginerrs.ProvideErrorTracker())
+		now := time.Now()
+		m.now = func() time.Time { return now }
+		m.resetState(context.Background(), p)
+
+		require.True(t, m.allowRestart(p))
+		require.False(t, m.allowRestart(p))
+
+		now = now.Add(time.Minute)
+		require.True(t, m.allowRestart(p))
+	})
+
+	t.Run("Plugin process that uses too much memory is stopped", func(t *testing.T) {
+		t.Parallel()
+
+		bp := &fakeProcessPlugin{FakeBackendPlugin: fakes.NewFakeBackendPlugin(true), pid: 42}
+		p := createPlugin(t, bp, func(plugin *plugins.Plugin) {
+			plugin.Backend = true
+		})
+
+		m := ProvideService(&config.PluginManagementCfg{
+			PluginLimits: setting.PluginLimitsSettings{
+				Default: setting.PluginLimits{MaxMemoryMB: 1},
+			},
+		}, pluginerrs.ProvideErrorTracker())
+		m.readUsage = func(pid int) (Usage, error) {
+			require.Equal(t, 42, pid)
+			return Usage{MemoryBytes: 2 * 1024 * 1024, CPUSeconds: 1}, nil
+		}
+		m.resetState(context.Background(), p)
+		require.NoError(t, p.Start(context.Background()))
+
+		m.sampleUsage(p)
+
+		status, exists := m.Status(p.ID)
+		require.True(t, exists)
+		require.Equal(t, uint64(2*1024*1024), status.MemoryBytes)
+		require.Equal(t, 42, status.PID)
+		require.Equal(t, 1, bp.StopCount)
+	})
+}
+
+type fakeProcessPlugin struct {
+	*fakes.FakeBackendPlugin
+	pid int
+}
+
+func (p *fakeProcessPlugin) PID() (int, bool) {
+	return p.pid, true
+}
+
 func createPlugin(t *testing.T, bp backendplugin.Plugin, cbs ...func(p *plugins.Plugin)) *plugins.Plugin {
 	t.Helper()
 
@@ -0,0 +1,24 @@
+//go:build linux
+
+package process
+
+import (
+	"github.com/prometheus/procfs"
+)
+
+func readProcessUsage(pid int) (Usage, error) {
+	proc, err := procfs.NewProc(pid)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	stat, err := proc.Stat()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return Usage{
+		MemoryBytes: uint64(stat.ResidentMemory()),
+		CPUSeconds:  stat.CPUTime(),
+	}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package process
+
+import "errors"
+
+var errUsageUnsupported = errors.New("reading process resource usage is not supported on this platform")
+
+func readProcessUsage(_ int) (Usage, error) {
+	return Usage{}, errUsageUnsupported
+}
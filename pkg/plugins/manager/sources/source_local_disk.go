@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/plugins"
 )
@@ -56,6 +57,10 @@ func DirAsLocalSources(pluginsPath string, class plugins.Class) ([]*LocalSource,
 
 	var pluginDirs []string
 	for _, dir := range d {
+		// hidden directories are not plugins, like the previous versions kept by the installer
+		if strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
 		if dir.IsDir() || dir.Type()&os.ModeSymlink == os.ModeSymlink {
 			pluginDirs = append(pluginDirs, filepath.Join(pluginsPath, dir.Name()))
 		}
@@ -14,9 +14,12 @@ const (
 )
 
 var (
-	ErrInstallCorePlugin   = errors.New("cannot install a Core plugin")
-	ErrUninstallCorePlugin = errors.New("cannot uninstall a Core plugin")
-	ErrPluginNotInstalled  = errors.New("plugin is not installed")
+	ErrInstallCorePlugin       = errors.New("cannot install a Core plugin")
+	ErrUninstallCorePlugin     = errors.New("cannot uninstall a Core plugin")
+	ErrPluginNotInstalled      = errors.New("plugin is not installed")
+	ErrPluginVersionNotFound   = errors.New("plugin version not found")
+	ErrPluginArchiveMismatch   = errors.New("plugin archive does not contain the plugin")
+	ErrPluginInstallInProgress = errors.New("plugin is being installed")
 )
 
 type NotFoundError struct {
@@ -243,6 +246,7 @@ const (
 	errorCodeSignatureInvalid   ErrorCode = "signatureInvalid"
 	ErrorCodeFailedBackendStart ErrorCode = "failedBackendStart"
 	ErrorAngular                ErrorCode = "angular"
+	ErrorCodeCrashLoop          ErrorCode = "crashLoop"
 )
 
 type ErrorCode string
@@ -311,6 +315,8 @@ func (e Error) PublicMessage() string {
 		return "Plugin failed to start"
 	case ErrorAngular:
 		return "Angular plugins are not supported"
+	case ErrorCodeCrashLoop:
+		return "Plugin was disabled after restarting too many times"
 	}
 
 	return "Plugin failed to load"
@@ -109,7 +109,8 @@ type JSONData struct {
 	SkipDataQuery bool `json:"skipDataQuery"`
 
 	// App settings
-	AutoEnabled bool `json:"autoEnabled"`
+	AutoEnabled bool      `json:"autoEnabled"`
+	Webhooks    []Webhook `json:"webhooks,omitempty"`
 
 	// Datasource settings
 	Annotations  bool            `json:"annotations"`
@@ -125,6 +126,11 @@ type JSONData struct {
 	Streaming    bool            `json:"streaming"`
 	SDK          bool            `json:"sdk,omitempty"`
 
+	// QueryCostEstimation is true if the plugin handles the query/estimate resource
+	QueryCostEstimation bool `json:"queryCostEstimation,omitempty"`
+	// QueryBatching is true if the plugin can handle the queries of several requests in one QueryData call
+	QueryBatching bool `json:"queryBatching,omitempty"`
+
 	// Backend (Datasource + Renderer + SecretsManager)
 	Executable string `json:"executable,omitempty"`
 
@@ -220,6 +226,27 @@ type Route struct {
 	Body         json.RawMessage `json:"body"`
 }
 
+// Webhook describes an inbound webhook that Grafana receives on behalf of
+// an app plugin and forwards to the plugin backend
+type Webhook struct {
+	Name string `json:"name"`
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of the body
+	SignatureHeader string `json:"signatureHeader"`
+	SignaturePrefix string `json:"signaturePrefix"`
+	// SecretKey is the key of the secure JSON data of the app holding the signing secret
+	SecretKey string `json:"secretKey"`
+}
+
+// Webhook returns the webhook with the given name, if the plugin declares it.
+func (d JSONData) Webhook(name string) (Webhook, bool) {
+	for _, w := range d.Webhooks {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Webhook{}, false
+}
+
 // Header describes an HTTP header that is forwarded with
 // the proxied request for a plugin route
 type Header struct {
@@ -307,6 +334,14 @@ func (p *Plugin) Exited() bool {
 	return false
 }
 
+// PID returns the process ID of the backend plugin process, if the plugin runs in its own process.
+func (p *Plugin) PID() (int, bool) {
+	if pp, ok := p.client.(backendplugin.ProcessPlugin); ok {
+		return pp.PID()
+	}
+	return 0, false
+}
+
 func (p *Plugin) Target() backendplugin.Target {
 	if !p.Backend {
 		return backendplugin.TargetNone
@@ -50,6 +50,10 @@ func (fs *FS) Extract(ctx context.Context, pluginID string, dirNameFunc DirNameG
 
 	fs.log.Successf("Downloaded and extracted %s v%s zip successfully to %s", pluginJSON.ID, pluginJSON.Info.Version, pluginDir)
 
+	return newExtractedPluginArchive(pluginJSON, pluginDir), nil
+}
+
+func newExtractedPluginArchive(pluginJSON plugins.JSONData, pluginDir string) *ExtractedPluginArchive {
 	deps := make([]*Dependency, 0, len(pluginJSON.Dependencies.Plugins))
 	for _, plugin := range pluginJSON.Dependencies.Plugins {
 		deps = append(deps, &Dependency{
@@ -63,7 +67,7 @@ func (fs *FS) Extract(ctx context.Context, pluginID string, dirNameFunc DirNameG
 		Version:      pluginJSON.Info.Version,
 		Dependencies: deps,
 		Path:         pluginDir,
-	}, nil
+	}
 }
 
 func (fs *FS) extractFiles(_ context.Context, pluginArchive *zip.ReadCloser, pluginID string, dirNameFunc DirNameGeneratorFunc) (string, error) {
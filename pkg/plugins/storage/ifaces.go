@@ -9,4 +9,17 @@ type ZipExtractor interface {
 	Extract(ctx context.Context, pluginID string, destDir DirNameGeneratorFunc, rc *zip.ReadCloser) (*ExtractedPluginArchive, error)
 }
 
+// VersionStore keeps the previous versions of the installed plugins side-by-side with the installed ones,
+// so that they can be restored without downloading them again.
+type VersionStore interface {
+	// Archive moves the directory of an installed plugin version to the store.
+	Archive(ctx context.Context, pluginID, version, pluginDir string) error
+	// Restore moves an archived plugin version back to the plugins directory, replacing the installed version.
+	Restore(ctx context.Context, pluginID, version string, destDir DirNameGeneratorFunc) (*ExtractedPluginArchive, error)
+	// Versions returns the archived versions of a plugin, the most recently archived first.
+	Versions(ctx context.Context, pluginID string) ([]string, error)
+	// Delete removes all the archived versions of a plugin.
+	Delete(ctx context.Context, pluginID string) error
+}
+
 type DirNameGeneratorFunc = func(pluginID string) string
@@ -1,6 +1,11 @@
 package storage
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrVersionNotFound = errors.New("plugin version not found")
 
 type ErrPermissionDenied struct {
 	Path string
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+var _ VersionStore = (*FS)(nil)
+
+const (
+	// VersionsDirName is the directory of the plugins directory where the previous versions of the plugins are
+	// kept. Hidden directories of the plugins directory are not loaded.
+	VersionsDirName = ".versions"
+
+	// maxArchivedVersions is the number of previous versions kept for each plugin.
+	maxArchivedVersions = 3
+)
+
+func (fs *FS) Archive(_ context.Context, pluginID, version, pluginDir string) error {
+	if version == "" {
+		return fmt.Errorf("cannot archive plugin %s without a version", pluginID)
+	}
+	versionDir, err := fs.versionDir(pluginID, version)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(versionDir); err != nil {
+		return err
+	}
+	// We can ignore gosec G301 here since it makes sense to give all users read access, like the plugins directory
+	// nolint:gosec
+	if err := os.MkdirAll(filepath.Dir(versionDir), 0755); err != nil {
+		if os.IsPermission(err) {
+			return ErrPermissionDenied{Path: versionDir}
+		}
+		return err
+	}
+	if err := os.Rename(pluginDir, versionDir); err != nil {
+		return fmt.Errorf("failed to archive plugin %s v%s: %w", pluginID, version, err)
+	}
+
+	// the modification time orders the versions by archive time
+	now := time.Now()
+	if err := os.Chtimes(versionDir, now, now); err != nil {
+		fs.log.Warn("Failed to update the archive time of the plugin version", "pluginId", pluginID, "version", version, "error", err)
+	}
+	fs.log.Debugf("Archived %s v%s to %s", pluginID, version, versionDir)
+
+	return fs.prune(pluginID)
+}
+
+func (fs *FS) Restore(_ context.Context, pluginID, version string, dirNameFunc DirNameGeneratorFunc) (*ExtractedPluginArchive, error) {
+	versionDir, err := fs.versionDir(pluginID, version)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(versionDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+
+	installDir := filepath.Join(fs.pluginsDir, dirNameFunc(pluginID))
+	if err := os.RemoveAll(installDir); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(versionDir, installDir); err != nil {
+		return nil, fmt.Errorf("failed to restore plugin %s v%s: %w", pluginID, version, err)
+	}
+
+	pluginJSON, err := readPluginJSON(installDir)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "failed to convert to plugin DTO", err)
+	}
+	fs.log.Successf("Restored %s v%s to %s", pluginID, version, installDir)
+
+	return newExtractedPluginArchive(pluginJSON, installDir), nil
+}
+
+func (fs *FS) Versions(_ context.Context, pluginID string) ([]string, error) {
+	entries, err := fs.archivedVersions(pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		versions = append(versions, e.Name())
+	}
+	return versions, nil
+}
+
+func (fs *FS) Delete(_ context.Context, pluginID string) error {
+	pluginDir, err := fs.versionDir(pluginID, "")
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(pluginDir)
+}
+
+// prune removes the oldest archived versions of a plugin.
+func (fs *FS) prune(pluginID string) error {
+	entries, err := fs.archivedVersions(pluginID)
+	if err != nil {
+		return err
+	}
+	if len(entries) <= maxArchivedVersions {
+		return nil
+	}
+
+	for _, e := range entries[maxArchivedVersions:] {
+		versionDir, err := fs.versionDir(pluginID, e.Name())
+		if err != nil {
+			return err
+		}
+		fs.log.Debugf("Removing archived version %s of plugin %s", e.Name(), pluginID)
+		if err := os.RemoveAll(versionDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivedVersions returns the directories of the archived versions of a plugin, the most recent first.
+func (fs *FS) archivedVersions(pluginID string) ([]os.FileInfo, error) {
+	pluginDir, err := fs.versionDir(pluginID, "")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []os.FileInfo{}, nil
+		}
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].ModTime().After(infos[j].ModTime())
+	})
+	return infos, nil
+}
+
+// versionDir returns the directory of an archived plugin version, or of all the archived versions of the
+// plugin when version is empty. The ID and version come from requests, so they must not escape the directory.
+func (fs *FS) versionDir(pluginID, version string) (string, error) {
+	if pluginID == "" {
+		return "", errors.New("plugin id is required")
+	}
+	for _, name := range []string{pluginID, version} {
+		if name != "" && (name != filepath.Base(name) || name == "." || name == "..") {
+			return "", fmt.Errorf("invalid plugin id or version %q", name)
+		}
+	}
+	return filepath.Join(fs.pluginsDir, VersionsDirName, pluginID, version), nil
+}
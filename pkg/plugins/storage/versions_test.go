@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/log"
+)
+
+func TestVersions(t *testing.T) {
+	pluginsDir := t.TempDir()
+	fs := FileSystem(log.NewTestPrettyLogger(), pluginsDir)
+	ctx := context.Background()
+
+	install := func(t *testing.T, version string) string {
+		dir := filepath.Join(pluginsDir, "test-app")
+		require.NoError(t, os.MkdirAll(dir, 0o750))
+		pluginJSON := `{"id": "test-app", "type": "app", "info": {"version": "` + version + `"}}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(pluginJSON), 0o600))
+		return dir
+	}
+
+	archiveTimes := time.Now().Add(-time.Hour)
+	for _, version := range []string{"1.0.0", "2.0.0", "3.0.0", "4.0.0"} {
+		require.NoError(t, fs.Archive(ctx, "test-app", version, install(t, version)))
+		// the file system may not tell apart versions archived in a row
+		archiveTimes = archiveTimes.Add(time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(pluginsDir, VersionsDirName, "test-app", version), archiveTimes, archiveTimes))
+	}
+
+	versions, err := fs.Versions(ctx, "test-app")
+	require.NoError(t, err)
+	require.Equal(t, []string{"4.0.0", "3.0.0", "2.0.0"}, versions)
+
+	archive, err := fs.Restore(ctx, "test-app", "3.0.0", SimpleDirNameGeneratorFunc)
+	require.NoError(t, err)
+	require.Equal(t, "test-app", archive.ID)
+	require.Equal(t, "3.0.0", archive.Version)
+	require.Equal(t, filepath.Join(pluginsDir, "test-app"), archive.Path)
+
+	versions, err = fs.Versions(ctx, "test-app")
+	require.NoError(t, err)
+	require.Equal(t, []string{"4.0.0", "2.0.0"}, versions)
+
+	_, err = fs.Restore(ctx, "test-app", "1.0.0", SimpleDirNameGeneratorFunc)
+	require.ErrorIs(t, err, ErrVersionNotFound)
+	_, err = fs.Restore(ctx, "test-app", "../../etc", SimpleDirNameGeneratorFunc)
+	require.Error(t, err)
+
+	require.NoError(t, fs.Delete(ctx, "test-app"))
+	versions, err = fs.Versions(ctx, "test-app")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
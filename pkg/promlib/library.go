@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -123,6 +124,14 @@ func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceReq
 		return sender.Send(vResp)
 	}
 
+	if strings.EqualFold(req.Path, "query/estimate") && req.Method == http.MethodPost {
+		resp, err := i.queryData.Estimate(ctx, req)
+		if err != nil {
+			return err
+		}
+		return sender.Send(resp)
+	}
+
 	resp, err := i.resource.Execute(ctx, req)
 	if err != nil {
 		return err
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// ApplyLabelAccessSelector adds the matchers of the selector to every vector selector of the expression,
+// so that the query only reads the series the selector matches.
+func ApplyLabelAccessSelector(rawExpr string, selector string) (string, error) {
+	matchers, err := parser.ParseMetricSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	expr, err := parser.ParseExpr(rawExpr)
+	if err != nil {
+		return "", err
+	}
+
+	parser.Inspect(expr, func(node parser.Node, nodes []parser.Node) error {
+		if v, ok := node.(*parser.VectorSelector); ok {
+			v.LabelMatchers = append(v.LabelMatchers, matchers...)
+		}
+		return nil
+	})
+	return expr.String(), nil
+}
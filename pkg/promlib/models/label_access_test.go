@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLabelAccessSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		selector  string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "Selector is added to every vector selector",
+			query:    `sum(rate(http_requests_total{job="api"}[5m])) / sum(rate(http_requests_total[5m]))`,
+			selector: `{namespace="team-a"}`,
+			expected: `sum(rate(http_requests_total{job="api",namespace="team-a"}[5m])) / sum(rate(http_requests_total{namespace="team-a"}[5m]))`,
+		},
+		{
+			name:     "Selector is added next to an existing matcher of the same label",
+			query:    `up{namespace="team-b"}`,
+			selector: `{namespace=~"team-a|team-c"}`,
+			expected: `up{namespace="team-b",namespace=~"team-a|team-c"}`,
+		},
+		{
+			name:      "Invalid selector",
+			query:     `up`,
+			selector:  `{namespace=}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ApplyLabelAccessSelector(tt.query, tt.selector)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, expr)
+		})
+	}
+}
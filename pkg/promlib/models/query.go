@@ -186,6 +186,9 @@ type internalQueryModel struct {
 	// Timezone offset to align start & end time on backend
 	UtcOffsetSec int64  `json:"utcOffsetSec,omitempty"`
 	Interval     string `json:"interval,omitempty"`
+
+	// Label selector Grafana adds to the query when the label access rules of the datasource restrict the user
+	LabelAccessSelector string `json:"labelAccessSelector,omitempty"`
 }
 
 func Parse(span trace.Span, query backend.DataQuery, dsScrapeInterval string, intervalCalculator intervalv2.Calculator, fromAlert bool, enableScope bool) (*Query, error) {
@@ -244,6 +247,14 @@ func Parse(span trace.Span, query backend.DataQuery, dsScrapeInterval string, in
 		}
 	}
 
+	// Applied last, so that no filter can replace the matchers of the selector
+	if model.LabelAccessSelector != "" {
+		expr, err = ApplyLabelAccessSelector(expr, model.LabelAccessSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if !model.Instant && !model.Range {
 		// In older dashboards, we were not setting range query param and !range && !instant was run as range query
 		model.Range = true
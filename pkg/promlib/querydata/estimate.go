@@ -0,0 +1,158 @@
+package querydata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+// EstimateRequest is the body of a query/estimate resource call.
+type EstimateRequest struct {
+	Queries []EstimateQuery `json:"queries"`
+}
+
+type EstimateQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType,omitempty"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	IntervalMS    int64           `json:"intervalMs"`
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Model         json.RawMessage `json:"model"`
+}
+
+// EstimateResponse is the body of the response of a query/estimate resource call.
+type EstimateResponse struct {
+	Estimates []Estimate `json:"estimates"`
+}
+
+type Estimate struct {
+	RefID string `json:"refId"`
+	// Samples is the number of samples the query is expected to return
+	Samples int64  `json:"samples"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Estimate returns the number of samples every query of the request is expected to return.
+// The number of series is counted with an instant query at the end of the time range and
+// multiplied by the number of steps of the query.
+func (s *QueryData) Estimate(ctx context.Context, req *backend.CallResourceRequest) (*backend.CallResourceResponse, error) {
+	estimateReq := EstimateRequest{}
+	if err := json.Unmarshal(req.Body, &estimateReq); err != nil {
+		return &backend.CallResourceResponse{
+			Status: http.StatusBadRequest,
+			Body:   []byte(fmt.Sprintf(`{"message": %q}`, err.Error())),
+		}, nil
+	}
+
+	resp := EstimateResponse{Estimates: make([]Estimate, 0, len(estimateReq.Queries))}
+	for _, eq := range estimateReq.Queries {
+		estimate := Estimate{RefID: eq.RefID}
+		samples, err := s.estimateSamples(ctx, eq)
+		if err != nil {
+			estimate.Error = err.Error()
+		}
+		estimate.Samples = samples
+		resp.Estimates = append(resp.Estimates, estimate)
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}, nil
+}
+
+func (s *QueryData) estimateSamples(ctx context.Context, eq EstimateQuery) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "datasource.prometheus.estimate")
+	defer span.End()
+
+	q, err := models.Parse(span, backend.DataQuery{
+		RefID:         eq.RefID,
+		QueryType:     eq.QueryType,
+		MaxDataPoints: eq.MaxDataPoints,
+		Interval:      time.Duration(eq.IntervalMS) * time.Millisecond,
+		TimeRange:     backend.TimeRange{From: eq.From, To: eq.To},
+		JSON:          eq.Model,
+	}, s.TimeInterval, s.intervalCalculator, false, false)
+	if err != nil {
+		return 0, err
+	}
+
+	countQuery := *q
+	countQuery.Expr = fmt.Sprintf("count(%s)", q.Expr)
+	res, err := s.client.QueryInstant(ctx, &countQuery)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	series, err := parseCountResponse(res)
+	if err != nil {
+		return 0, err
+	}
+
+	var steps int64
+	if q.InstantQuery {
+		steps++
+	}
+	if q.RangeQuery && q.Step > 0 {
+		tr := q.TimeRange()
+		steps += int64(tr.End.Sub(tr.Start)/tr.Step) + 1
+	}
+	return series * steps, nil
+}
+
+// parseCountResponse returns the value of the vector returned by a count() instant query.
+func parseCountResponse(res *http.Response) (int64, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var countRes struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Result []struct {
+				Value [2]any `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &countRes); err != nil {
+		return 0, fmt.Errorf("failed to parse count response: %w", err)
+	}
+	if countRes.Status == "error" {
+		return 0, fmt.Errorf("count query failed: %s", countRes.Error)
+	}
+	// count() of an empty vector returns no result
+	if len(countRes.Data.Result) == 0 {
+		return 0, nil
+	}
+
+	value, ok := countRes.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count value %v", countRes.Data.Result[0].Value[1])
+	}
+	count, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse count value: %w", err)
+	}
+	return int64(count), nil
+}
@@ -0,0 +1,59 @@
+package querydata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/querydata"
+)
+
+func TestQueryData_Estimate(t *testing.T) {
+	tctx, err := setup()
+	require.NoError(t, err)
+
+	countRes, err := toAPIResponse(map[string]any{
+		"resultType": "vector",
+		"result": []map[string]any{
+			{"metric": map[string]string{}, "value": []any{1700000000, "3"}},
+		},
+	})
+	require.NoError(t, err)
+	tctx.httpProvider.setResponse(countRes)
+
+	to := time.Unix(1700000000, 0)
+	body, err := json.Marshal(querydata.EstimateRequest{Queries: []querydata.EstimateQuery{
+		{
+			RefID:         "A",
+			MaxDataPoints: 100,
+			IntervalMS:    60000,
+			From:          to.Add(-time.Hour),
+			To:            to,
+			Model:         json.RawMessage(`{"expr": "up", "range": true, "interval": "1m"}`),
+		},
+	}})
+	require.NoError(t, err)
+
+	res, err := tctx.queryData.Estimate(context.Background(), &backend.CallResourceRequest{
+		Path:   "query/estimate",
+		Method: http.MethodPost,
+		Body:   body,
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.Status)
+	require.NoError(t, tctx.httpProvider.req.ParseForm())
+	require.Equal(t, "count(up)", tctx.httpProvider.req.PostForm.Get("query"))
+
+	estimates := querydata.EstimateResponse{}
+	require.NoError(t, json.Unmarshal(res.Body, &estimates))
+	require.Len(t, estimates.Estimates, 1)
+	require.Equal(t, "A", estimates.Estimates[0].RefID)
+	require.Empty(t, estimates.Estimates[0].Error)
+	// 3 series with 61 steps of a minute in the hour
+	require.Equal(t, int64(3*61), estimates.Estimates[0].Samples)
+}
@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboard "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards/apply"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// applyRoute is the apiserver equivalent of POST /api/dashboards/apply: it returns the plan of a bundle of
+// dashboards for a dry run, and applies it otherwise.
+func (b *DashboardsAPIBuilder) applyRoute() builder.APIRouteHandler {
+	return builder.APIRouteHandler{
+		Path: dashboard.DashboardResourceInfo.GroupResource().Resource + "/apply",
+		Spec: &spec3.PathProps{
+			Post: &spec3.Operation{
+				OperationProps: spec3.OperationProps{
+					Tags:        []string{dashboard.DashboardResourceInfo.GroupVersionKind().Kind},
+					Summary:     "Apply a bundle of dashboards to a folder",
+					Description: "Computes the plan to create, update and delete the dashboards of a folder matched by UID, and applies it in a single transaction when the plan ID of a dry run is confirmed",
+					Parameters: []*spec3.Parameter{
+						{
+							ParameterProps: spec3.ParameterProps{
+								Name:        "namespace",
+								In:          "path",
+								Required:    true,
+								Example:     "default",
+								Description: "workspace",
+								Schema:      spec.StringProperty(),
+							},
+						},
+					},
+				},
+			},
+		},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			user, err := identity.GetRequester(r.Context())
+			if err != nil {
+				errhttp.Write(r.Context(), err, w)
+				return
+			}
+			wrap := &contextmodel.ReqContext{
+				Logger: b.log,
+				Context: &web.Context{
+					Req:  r,
+					Resp: web.NewResponseWriter(r.Method, w),
+				},
+			}
+
+			info, err := claims.ParseNamespace(mux.Vars(r)["namespace"])
+			if err != nil {
+				wrap.JsonApiErr(http.StatusBadRequest, "expected namespace", nil)
+				return
+			}
+			if info.OrgID != user.GetOrgID() {
+				wrap.JsonApiErr(http.StatusBadRequest,
+					fmt.Sprintf("user orgId does not match namespace (%d != %d)", info.OrgID, user.GetOrgID()), nil)
+				return
+			}
+
+			cmd := apply.ApplyCommand{}
+			if err := web.Bind(r, &cmd); err != nil {
+				wrap.JsonApiErr(http.StatusBadRequest, "bad request data", err)
+				return
+			}
+			plan, err := b.applyService.Apply(r.Context(), user, cmd)
+			if err != nil {
+				apply.ErrorResponse(err).WriteTo(wrap)
+				return
+			}
+			wrap.JSON(http.StatusOK, plan)
+		},
+	}
+}
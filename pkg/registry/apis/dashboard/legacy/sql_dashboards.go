@@ -291,6 +291,10 @@ func (a *dashboardSqlAccess) scanRow(rows *sql.Rows) (*dashboardRow, error) {
 		}
 
 		if len(data) > 0 {
+			data, err = simplejson.Decompress(data)
+			if err != nil {
+				return row, err
+			}
 			err = dash.Spec.UnmarshalJSON(data)
 			if err != nil {
 				return row, err
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/apply"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/setting"
@@ -35,6 +36,7 @@ var _ builder.APIGroupBuilder = (*DashboardsAPIBuilder)(nil)
 // This is used just so wire has something unique to return
 type DashboardsAPIBuilder struct {
 	dashboardService dashboards.DashboardService
+	applyService     *apply.Service
 
 	accessControl accesscontrol.AccessControl
 	legacy        *dashboardStorage
@@ -51,6 +53,7 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 	reg prometheus.Registerer,
 	sql db.DB,
 	tracing *tracing.TracingService,
+	applyService *apply.Service,
 ) *DashboardsAPIBuilder {
 	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) {
 		return nil // skip registration unless opting into experimental apis
@@ -63,6 +66,7 @@ func RegisterAPIService(cfg *setting.Cfg, features featuremgmt.FeatureToggles,
 		log: log.New("grafana-apiserver.dashboards"),
 
 		dashboardService: dashboardService,
+		applyService:     applyService,
 		accessControl:    accessControl,
 
 		legacy: &dashboardStorage{
@@ -192,5 +196,7 @@ func (b *DashboardsAPIBuilder) PostProcessOpenAPI(oas *spec3.OpenAPI) (*spec3.Op
 }
 
 func (b *DashboardsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
-	return nil // no custom API routes
+	return &builder.APIRoutes{
+		Namespace: []builder.APIRouteHandler{b.applyRoute()},
+	}
 }
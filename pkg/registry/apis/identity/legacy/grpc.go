@@ -0,0 +1,186 @@
+package legacy
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/authlib/claims"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+// The legacy identity store is exposed over gRPC, so the identity API group can run in a standalone
+// apiserver without access to the Grafana database. The messages are the query and result types of
+// the store encoded as JSON, so there is no need for a protobuf definition of them.
+
+const (
+	grpcServiceName = "identity.legacy.LegacyIdentityStore"
+	grpcContentType = "json"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return grpcContentType
+}
+
+type grpcRequest[Q any] struct {
+	Namespace string
+	Query     Q
+}
+
+type getUserTeamsResult struct {
+	Teams []team.Team
+}
+
+var grpcServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcServiceName,
+	HandlerType: (*LegacyIdentityStore)(nil),
+	Methods: []grpc.MethodDesc{
+		grpcMethod("ListDisplay", LegacyIdentityStore.ListDisplay),
+		grpcMethod("ListUsers", func(s LegacyIdentityStore, ctx context.Context, ns claims.NamespaceInfo, q ListUserQuery) (*ListUserResult, error) {
+			res, err := s.ListUsers(ctx, ns, q)
+			if res != nil {
+				for i := range res.Users {
+					// never send the credentials of the users over the wire
+					res.Users[i].Password = ""
+					res.Users[i].Salt = ""
+					res.Users[i].Rands = ""
+				}
+			}
+			return res, err
+		}),
+		grpcMethod("ListTeams", LegacyIdentityStore.ListTeams),
+		grpcMethod("ListTeamBindings", LegacyIdentityStore.ListTeamBindings),
+		grpcMethod("ListTeamMembers", LegacyIdentityStore.ListTeamMembers),
+		grpcMethod("GetUserTeams", func(s LegacyIdentityStore, ctx context.Context, ns claims.NamespaceInfo, uid string) (*getUserTeamsResult, error) {
+			teams, err := s.GetUserTeams(ctx, ns, uid)
+			return &getUserTeamsResult{Teams: teams}, err
+		}),
+	},
+	Metadata: "legacy identity store",
+}
+
+// RegisterGRPCServer serves the store on the gRPC server. The caller must be an admin of the organization
+// of the requested namespace, or a Grafana admin.
+func RegisterGRPCServer(registrar grpc.ServiceRegistrar, store LegacyIdentityStore) {
+	registrar.RegisterService(&grpcServiceDesc, store)
+}
+
+func grpcMethod[Q any, R any](name string, call func(LegacyIdentityStore, context.Context, claims.NamespaceInfo, Q) (R, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := &grpcRequest[Q]{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, r any) (any, error) {
+				req := r.(*grpcRequest[Q])
+				ns, err := claims.ParseNamespace(req.Namespace)
+				if err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				if err := authorizeGRPCRequest(ctx, ns); err != nil {
+					return nil, err
+				}
+				return call(srv.(LegacyIdentityStore), ctx, ns, req.Query)
+			}
+
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + grpcServiceName + "/" + name,
+			}
+			return interceptor(ctx, req, info, handler)
+		},
+	}
+}
+
+func authorizeGRPCRequest(ctx context.Context, ns claims.NamespaceInfo) error {
+	grpcCtx := grpccontext.FromContext(ctx)
+	if grpcCtx == nil || grpcCtx.SignedInUser == nil {
+		return status.Error(codes.Unauthenticated, "no identity found")
+	}
+
+	user := grpcCtx.SignedInUser
+	if user.GetIsGrafanaAdmin() {
+		return nil
+	}
+	if user.GetOrgID() != ns.OrgID || !user.HasRole(identity.RoleAdmin) {
+		return status.Error(codes.PermissionDenied, "only organization admins can read the identities of the organization")
+	}
+	return nil
+}
+
+var (
+	_ LegacyIdentityStore = (*grpcClient)(nil)
+)
+
+// NewGRPCClient returns a store that reads the identities from the legacy store served by Grafana
+// on the provided connection.
+func NewGRPCClient(conn grpc.ClientConnInterface) LegacyIdentityStore {
+	return &grpcClient{conn: conn}
+}
+
+type grpcClient struct {
+	conn grpc.ClientConnInterface
+}
+
+func grpcInvoke[Q any, R any](ctx context.Context, conn grpc.ClientConnInterface, method string, ns claims.NamespaceInfo, query Q) (*R, error) {
+	res := new(R)
+	req := &grpcRequest[Q]{Namespace: ns.Value, Query: query}
+	if err := conn.Invoke(ctx, "/"+grpcServiceName+"/"+method, req, res, grpc.CallContentSubtype(grpcContentType)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *grpcClient) ListDisplay(ctx context.Context, ns claims.NamespaceInfo, query ListDisplayQuery) (*ListUserResult, error) {
+	return grpcInvoke[ListDisplayQuery, ListUserResult](ctx, c.conn, "ListDisplay", ns, query)
+}
+
+func (c *grpcClient) ListUsers(ctx context.Context, ns claims.NamespaceInfo, query ListUserQuery) (*ListUserResult, error) {
+	return grpcInvoke[ListUserQuery, ListUserResult](ctx, c.conn, "ListUsers", ns, query)
+}
+
+func (c *grpcClient) ListTeams(ctx context.Context, ns claims.NamespaceInfo, query ListTeamQuery) (*ListTeamResult, error) {
+	return grpcInvoke[ListTeamQuery, ListTeamResult](ctx, c.conn, "ListTeams", ns, query)
+}
+
+func (c *grpcClient) ListTeamBindings(ctx context.Context, ns claims.NamespaceInfo, query ListTeamBindingsQuery) (*ListTeamBindingsResult, error) {
+	return grpcInvoke[ListTeamBindingsQuery, ListTeamBindingsResult](ctx, c.conn, "ListTeamBindings", ns, query)
+}
+
+func (c *grpcClient) ListTeamMembers(ctx context.Context, ns claims.NamespaceInfo, query ListTeamMembersQuery) (*ListTeamMembersResult, error) {
+	return grpcInvoke[ListTeamMembersQuery, ListTeamMembersResult](ctx, c.conn, "ListTeamMembers", ns, query)
+}
+
+func (c *grpcClient) GetUserTeams(ctx context.Context, ns claims.NamespaceInfo, uid string) ([]team.Team, error) {
+	res, err := grpcInvoke[string, getUserTeamsResult](ctx, c.conn, "GetUserTeams", ns, uid)
+	if err != nil {
+		return nil, err
+	}
+	return res.Teams, nil
+}
@@ -0,0 +1,120 @@
+package legacy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestGRPCBridge(t *testing.T) {
+	store := &fakeIdentityStore{
+		users: []user.User{{ID: 1, UID: "u1", Login: "admin", Password: "secret", Salt: "salt", OrgID: 1}},
+		teams: []team.Team{{ID: 1, UID: "t1", Name: "team", OrgID: 1}},
+	}
+
+	t.Run("returns the identities of the organization to its admins", func(t *testing.T) {
+		client := setupGRPCBridge(t, store, &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleAdmin})
+		ns := claims.NamespaceInfo{Value: "default", OrgID: 1}
+
+		users, err := client.ListUsers(context.Background(), ns, ListUserQuery{OrgID: 1, UID: "u1"})
+		require.NoError(t, err)
+		require.Len(t, users.Users, 1)
+		require.Equal(t, "admin", users.Users[0].Login)
+		require.Empty(t, users.Users[0].Password)
+		require.Empty(t, users.Users[0].Salt)
+		require.Equal(t, ListUserQuery{OrgID: 1, UID: "u1"}, store.lastUserQuery)
+		require.Equal(t, int64(1), store.lastNamespace.OrgID)
+
+		teams, err := client.GetUserTeams(context.Background(), ns, "u1")
+		require.NoError(t, err)
+		require.Equal(t, store.teams[0].UID, teams[0].UID)
+	})
+
+	t.Run("rejects the admins of another organization", func(t *testing.T) {
+		client := setupGRPCBridge(t, store, &user.SignedInUser{OrgID: 2, OrgRole: identity.RoleAdmin})
+
+		_, err := client.ListUsers(context.Background(), claims.NamespaceInfo{Value: "default", OrgID: 1}, ListUserQuery{OrgID: 1})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("rejects viewers", func(t *testing.T) {
+		client := setupGRPCBridge(t, store, &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleViewer})
+
+		_, err := client.ListTeams(context.Background(), claims.NamespaceInfo{Value: "default", OrgID: 1}, ListTeamQuery{OrgID: 1})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func setupGRPCBridge(t *testing.T, store LegacyIdentityStore, signedInUser *user.SignedInUser) LegacyIdentityStore {
+	t.Helper()
+
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	server := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(contextHandler.SetUser(ctx, signedInUser), req)
+		},
+	))
+	RegisterGRPCServer(server, store)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewGRPCClient(conn)
+}
+
+type fakeIdentityStore struct {
+	users []user.User
+	teams []team.Team
+
+	lastNamespace claims.NamespaceInfo
+	lastUserQuery ListUserQuery
+}
+
+func (f *fakeIdentityStore) ListDisplay(ctx context.Context, ns claims.NamespaceInfo, query ListDisplayQuery) (*ListUserResult, error) {
+	return &ListUserResult{Users: f.users}, nil
+}
+
+func (f *fakeIdentityStore) ListUsers(ctx context.Context, ns claims.NamespaceInfo, query ListUserQuery) (*ListUserResult, error) {
+	f.lastNamespace = ns
+	f.lastUserQuery = query
+	users := make([]user.User, len(f.users))
+	copy(users, f.users)
+	return &ListUserResult{Users: users}, nil
+}
+
+func (f *fakeIdentityStore) ListTeams(ctx context.Context, ns claims.NamespaceInfo, query ListTeamQuery) (*ListTeamResult, error) {
+	return &ListTeamResult{Teams: f.teams}, nil
+}
+
+func (f *fakeIdentityStore) ListTeamBindings(ctx context.Context, ns claims.NamespaceInfo, query ListTeamBindingsQuery) (*ListTeamBindingsResult, error) {
+	return &ListTeamBindingsResult{}, nil
+}
+
+func (f *fakeIdentityStore) ListTeamMembers(ctx context.Context, ns claims.NamespaceInfo, query ListTeamMembersQuery) (*ListTeamMembersResult, error) {
+	return &ListTeamMembersResult{}, nil
+}
+
+func (f *fakeIdentityStore) GetUserTeams(ctx context.Context, ns claims.NamespaceInfo, uid string) ([]team.Team, error) {
+	return f.teams, nil
+}
@@ -13,6 +13,7 @@ import (
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	common "k8s.io/kube-openapi/pkg/common"
 
+	commonv0alpha1 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	identityv0 "github.com/grafana/grafana/pkg/apis/identity/v0alpha1"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
@@ -22,8 +23,10 @@ import (
 	"github.com/grafana/grafana/pkg/registry/apis/identity/sso"
 	"github.com/grafana/grafana/pkg/registry/apis/identity/team"
 	"github.com/grafana/grafana/pkg/registry/apis/identity/user"
+	grafanaauthorizer "github.com/grafana/grafana/pkg/services/apiserver/auth/authorizer"
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
 	"github.com/grafana/grafana/pkg/services/ssosettings"
 	"github.com/grafana/grafana/pkg/storage/legacysql"
 )
@@ -34,6 +37,11 @@ var _ builder.APIGroupBuilder = (*IdentityAPIBuilder)(nil)
 type IdentityAPIBuilder struct {
 	Store      legacy.LegacyIdentityStore
 	SSOService ssosettings.Service
+
+	// UnifiedStorage is set when the group runs in a standalone apiserver, where users, teams and
+	// service accounts are stored in the unified resource store. The other resources are still read
+	// from the legacy store.
+	UnifiedStorage bool
 }
 
 func RegisterAPIService(
@@ -41,6 +49,7 @@ func RegisterAPIService(
 	apiregistration builder.APIRegistrar,
 	ssoService ssosettings.Service,
 	sql db.DB,
+	grpcServer grpcserver.Provider,
 ) (*IdentityAPIBuilder, error) {
 	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) {
 		return nil, nil // skip registration unless opting into experimental apis
@@ -52,9 +61,23 @@ func RegisterAPIService(
 	}
 	apiregistration.RegisterAPI(builder)
 
+	// Serve the legacy store to the identity group running in a standalone apiserver
+	if features.IsEnabledGlobally(featuremgmt.FlagGrpcServer) {
+		legacy.RegisterGRPCServer(grpcServer.GetServer(), builder.Store)
+	}
+
 	return builder, nil
 }
 
+// NewStandaloneAPIBuilder creates the identity group for a standalone apiserver, reading the legacy
+// identities from the Grafana gRPC server.
+func NewStandaloneAPIBuilder(store legacy.LegacyIdentityStore) *IdentityAPIBuilder {
+	return &IdentityAPIBuilder{
+		Store:          store,
+		UnifiedStorage: true,
+	}
+}
+
 func (b *IdentityAPIBuilder) GetGroupVersion() schema.GroupVersion {
 	return identityv0.SchemeGroupVersion
 }
@@ -94,6 +117,16 @@ func (b *IdentityAPIBuilder) GetAPIGroupInfo(
 	serviceaccountResource := identityv0.ServiceAccountResourceInfo
 	storage[serviceaccountResource.StoragePath()] = serviceaccount.NewLegacyStore(b.Store)
 
+	if b.UnifiedStorage && optsGetter != nil {
+		for _, resourceInfo := range []commonv0alpha1.ResourceInfo{teamResource, userResource, serviceaccountResource} {
+			store, err := newStorage(scheme, resourceInfo, optsGetter)
+			if err != nil {
+				return nil, err
+			}
+			storage[resourceInfo.StoragePath()] = store
+		}
+	}
+
 	if b.SSOService != nil {
 		ssoResource := identityv0.SSOSettingResourceInfo
 		storage[ssoResource.StoragePath()] = sso.NewLegacyStore(b.SSOService)
@@ -116,7 +149,6 @@ func (b *IdentityAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 }
 
 func (b *IdentityAPIBuilder) GetAuthorizer() authorizer.Authorizer {
-	// TODO: handle authorization based in entity.
 	return authorizer.AuthorizerFunc(
 		func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
 			user, err := identity.GetRequester(ctx)
@@ -126,6 +158,11 @@ func (b *IdentityAPIBuilder) GetAuthorizer() authorizer.Authorizer {
 			if user.GetIsGrafanaAdmin() {
 				return authorizer.DecisionAllow, "", nil
 			}
+			// users, teams and service accounts are authorized with the RBAC permissions of the user
+			gr := schema.GroupResource{Group: a.GetAPIGroup(), Resource: a.GetResource()}
+			if _, ok := grafanaauthorizer.RBACResources[gr]; ok && a.IsResourceRequest() {
+				return authorizer.DecisionNoOpinion, "", nil
+			}
 			return authorizer.DecisionDeny, "only grafana admins have access for now", nil
 		})
 }
@@ -29,6 +29,7 @@ type PlaylistAPIBuilder struct {
 	service    playlistsvc.Service
 	namespacer request.NamespaceMapper
 	gv         schema.GroupVersion
+	kvStore    kvstore.KVStore
 }
 
 func RegisterAPIService(p playlistsvc.Service,
@@ -41,6 +42,7 @@ func RegisterAPIService(p playlistsvc.Service,
 		service:    p,
 		namespacer: request.GetNamespaceMapper(cfg),
 		gv:         playlist.PlaylistResourceInfo.GroupVersion(),
+		kvStore:    kvStore,
 	}
 	apiregistration.RegisterAPI(builder)
 	return builder
@@ -54,6 +56,7 @@ func addKnownTypes(scheme *runtime.Scheme, gv schema.GroupVersion) {
 	scheme.AddKnownTypes(gv,
 		&playlist.Playlist{},
 		&playlist.PlaylistList{},
+		&playlist.RotationState{},
 	)
 }
 
@@ -107,6 +110,9 @@ func (b *PlaylistAPIBuilder) GetAPIGroupInfo(
 		storage[resource.StoragePath()] = dualWriter
 	}
 
+	// the rotation reads the playlists from the same storage as the API
+	storage[resource.StoragePath("rotation")] = newSubRotationREST(storage[resource.StoragePath()].(rest.Getter), b.kvStore)
+
 	apiGroupInfo.VersionedResourcesStorageMap[playlist.VERSION] = storage
 	return &apiGroupInfo, nil
 }
@@ -120,5 +126,5 @@ func (b *PlaylistAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 }
 
 func (b *PlaylistAPIBuilder) GetAuthorizer() authorizer.Authorizer {
-	return nil // default authorizer is fine
+	return nil // the playlists are authorized with their RBAC actions, see authorizer.RBACResources
 }
@@ -0,0 +1,303 @@
+package playlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	playlist "github.com/grafana/grafana/pkg/apis/playlist/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+const (
+	// rotationNamespace is the kvstore namespace of the rotation states, keyed by playlist name.
+	rotationNamespace = "playlist-rotation"
+	// rotationPollInterval is how often watched rotations are reloaded, to follow the changes made by other instances.
+	rotationPollInterval = 10 * time.Second
+)
+
+// storedRotation is the state of a rotation saved in the kvstore, the current item is computed from it.
+type storedRotation struct {
+	Running    bool  `json:"running"`
+	Index      int   `json:"index"`
+	SwitchedAt int64 `json:"switchedAt"`
+}
+
+// subRotationREST is the rotation subresource of the playlists, which kiosk displays get or watch to show the
+// current item of a playlist, and which drives the rotation.
+type subRotationREST struct {
+	getter rest.Getter
+	kv     kvstore.KVStore
+	now    func() time.Time
+
+	mu sync.Mutex
+	// changed has a channel per watched rotation, closed when the rotation is changed on this instance
+	changed map[string]chan struct{}
+}
+
+var (
+	_ = rest.Connecter(&subRotationREST{})
+	_ = rest.StorageMetadata(&subRotationREST{})
+)
+
+func newSubRotationREST(getter rest.Getter, kv kvstore.KVStore) *subRotationREST {
+	return &subRotationREST{
+		getter:  getter,
+		kv:      kv,
+		now:     time.Now,
+		changed: make(map[string]chan struct{}),
+	}
+}
+
+func (r *subRotationREST) New() runtime.Object {
+	return &playlist.RotationState{}
+}
+
+func (r *subRotationREST) Destroy() {
+}
+
+func (r *subRotationREST) ConnectMethods() []string {
+	return []string{"GET", "POST"}
+}
+
+func (r *subRotationREST) ProducesMIMETypes(verb string) []string {
+	return nil
+}
+
+func (r *subRotationREST) ProducesObject(verb string) interface{} {
+	return &playlist.RotationState{}
+}
+
+func (r *subRotationREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return nil, false, "" // true means you can use the trailing path as a variable
+}
+
+func (r *subRotationREST) Connect(ctx context.Context, name string, opts runtime.Object, responder rest.Responder) (http.Handler, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			cmd := playlist.RotationCommand{}
+			if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+				responder.Error(apierrors.NewBadRequest("error reading rotation command"))
+				return
+			}
+			state, err := r.apply(ctx, info.OrgID, name, cmd)
+			if err != nil {
+				responder.Error(err)
+				return
+			}
+			responder.Object(http.StatusOK, state)
+			return
+		}
+
+		state, err := r.current(ctx, info.OrgID, name)
+		if err != nil {
+			responder.Error(err)
+			return
+		}
+		if req.URL.Query().Get("watch") != "true" {
+			responder.Object(http.StatusOK, state)
+			return
+		}
+		r.watch(ctx, w, info.OrgID, name, state)
+	}), nil
+}
+
+// current returns the current state of the rotation of a playlist.
+func (r *subRotationREST) current(ctx context.Context, orgID int64, name string) (*playlist.RotationState, error) {
+	p, err := r.getPlaylist(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := r.load(ctx, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	return rotationState(p, stored, r.now()), nil
+}
+
+// apply applies a command to the rotation of a playlist and returns its new state.
+func (r *subRotationREST) apply(ctx context.Context, orgID int64, name string, cmd playlist.RotationCommand) (*playlist.RotationState, error) {
+	p, err := r.getPlaylist(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	count := len(p.Spec.Items)
+	if count == 0 {
+		return nil, apierrors.NewBadRequest("the playlist has no items")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.load(ctx, orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	state := rotationState(p, stored, now)
+
+	switch cmd.Action {
+	case playlist.RotationActionStart:
+		index := state.Index
+		if cmd.Index != nil {
+			if *cmd.Index < 0 || *cmd.Index >= count {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("index must be between 0 and %d", count-1))
+			}
+			index = *cmd.Index
+		}
+		stored = storedRotation{Running: true, Index: index, SwitchedAt: now.UnixMilli()}
+	case playlist.RotationActionStop:
+		stored = storedRotation{Running: false, Index: state.Index, SwitchedAt: state.SwitchedAt}
+	case playlist.RotationActionNext:
+		stored = storedRotation{Running: state.Running, Index: (state.Index + 1) % count, SwitchedAt: now.UnixMilli()}
+	case playlist.RotationActionPrevious:
+		stored = storedRotation{Running: state.Running, Index: (state.Index - 1 + count) % count, SwitchedAt: now.UnixMilli()}
+	default:
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid action %q", cmd.Action))
+	}
+
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.kv.Set(ctx, orgID, rotationNamespace, name, string(value)); err != nil {
+		return nil, err
+	}
+
+	key := rotationKey(orgID, name)
+	if changed, ok := r.changed[key]; ok {
+		close(changed)
+		delete(r.changed, key)
+	}
+
+	return rotationState(p, stored, now), nil
+}
+
+// watch streams the states of the rotation of a playlist as JSON lines, writing a state when the rotation switches
+// to another item and when it is changed, until the request is canceled.
+func (r *subRotationREST) watch(ctx context.Context, w http.ResponseWriter, orgID int64, name string, state *playlist.RotationState) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	key := rotationKey(orgID, name)
+	var last *playlist.RotationState
+	for {
+		// subscribe before loading the state, to not miss the changes made in between
+		changed := r.subscribe(key)
+		if state == nil {
+			var err error
+			if state, err = r.current(ctx, orgID, name); err != nil {
+				return
+			}
+		}
+
+		if last == nil || !reflect.DeepEqual(last, state) {
+			if err := encoder.Encode(state); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			last = state
+		}
+
+		wait := rotationPollInterval
+		if state.NextSwitchAt > 0 {
+			if untilSwitch := time.UnixMilli(state.NextSwitchAt).Sub(r.now()); untilSwitch < wait {
+				wait = untilSwitch
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+		state = nil
+	}
+}
+
+func (r *subRotationREST) subscribe(key string) <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed, ok := r.changed[key]
+	if !ok {
+		changed = make(chan struct{})
+		r.changed[key] = changed
+	}
+	return changed
+}
+
+func (r *subRotationREST) getPlaylist(ctx context.Context, name string) (*playlist.Playlist, error) {
+	obj, err := r.getter.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	p, ok := obj.(*playlist.Playlist)
+	if !ok {
+		return nil, fmt.Errorf("expected playlist")
+	}
+	return p, nil
+}
+
+func (r *subRotationREST) load(ctx context.Context, orgID int64, name string) (storedRotation, error) {
+	stored := storedRotation{}
+	value, ok, err := r.kv.Get(ctx, orgID, rotationNamespace, name)
+	if err != nil || !ok {
+		return stored, err
+	}
+	err = json.Unmarshal([]byte(value), &stored)
+	return stored, err
+}
+
+func rotationKey(orgID int64, name string) string {
+	return fmt.Sprintf("%d/%s", orgID, name)
+}
+
+// rotationState computes the state of a rotation at a time, moving to the next items every interval of the
+// playlist while it is running.
+func rotationState(p *playlist.Playlist, stored storedRotation, now time.Time) *playlist.RotationState {
+	count := len(p.Spec.Items)
+	if count == 0 {
+		return &playlist.RotationState{}
+	}
+
+	state := &playlist.RotationState{
+		Running:    stored.Running,
+		Index:      ((stored.Index % count) + count) % count,
+		SwitchedAt: stored.SwitchedAt,
+	}
+	interval, err := gtime.ParseDuration(p.Spec.Interval)
+	if stored.Running && err == nil && interval.Milliseconds() > 0 {
+		intervalMs := interval.Milliseconds()
+		if steps := (now.UnixMilli() - stored.SwitchedAt) / intervalMs; steps > 0 {
+			state.Index = (state.Index + int(steps%int64(count))) % count
+			state.SwitchedAt += steps * intervalMs
+		}
+		state.NextSwitchAt = state.SwitchedAt + intervalMs
+	}
+	item := p.Spec.Items[state.Index]
+	state.Item = &item
+	return state
+}
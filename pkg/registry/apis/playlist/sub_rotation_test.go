@@ -0,0 +1,103 @@
+package playlist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	playlist "github.com/grafana/grafana/pkg/apis/playlist/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+type fakePlaylistGetter struct {
+	playlist *playlist.Playlist
+}
+
+func (f *fakePlaylistGetter) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return f.playlist, nil
+}
+
+func TestRotationState(t *testing.T) {
+	p := &playlist.Playlist{Spec: playlist.Spec{
+		Interval: "1m",
+		Items: []playlist.Item{
+			{Type: playlist.ItemTypeDashboardByUid, Value: "a"},
+			{Type: playlist.ItemTypeDashboardByUid, Value: "b"},
+			{Type: playlist.ItemTypeDashboardByTag, Value: "c"},
+		},
+	}}
+	start := time.UnixMilli(1_000_000)
+
+	t.Run("stays on the current item when not running", func(t *testing.T) {
+		state := rotationState(p, storedRotation{Index: 1, SwitchedAt: start.UnixMilli()}, start.Add(time.Hour))
+		require.Equal(t, 1, state.Index)
+		require.Equal(t, "b", state.Item.Value)
+		require.Zero(t, state.NextSwitchAt)
+	})
+
+	t.Run("switches items every interval when running", func(t *testing.T) {
+		state := rotationState(p, storedRotation{Running: true, Index: 1, SwitchedAt: start.UnixMilli()}, start.Add(2*time.Minute+10*time.Second))
+		require.Equal(t, 0, state.Index)
+		require.Equal(t, "a", state.Item.Value)
+		require.Equal(t, start.Add(2*time.Minute).UnixMilli(), state.SwitchedAt)
+		require.Equal(t, start.Add(3*time.Minute).UnixMilli(), state.NextSwitchAt)
+	})
+
+	t.Run("returns no item without items", func(t *testing.T) {
+		state := rotationState(&playlist.Playlist{}, storedRotation{Running: true}, start)
+		require.Equal(t, &playlist.RotationState{}, state)
+	})
+}
+
+func TestRotationCommands(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	r := newSubRotationREST(&fakePlaylistGetter{playlist: &playlist.Playlist{Spec: playlist.Spec{
+		Interval: "5m",
+		Items: []playlist.Item{
+			{Type: playlist.ItemTypeDashboardByUid, Value: "a"},
+			{Type: playlist.ItemTypeDashboardByUid, Value: "b"},
+		},
+	}}}, kvstore.NewFakeKVStore())
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	state, err := r.current(ctx, 1, "p")
+	require.NoError(t, err)
+	require.False(t, state.Running, "the rotation is not running until it is started")
+
+	index := 1
+	state, err = r.apply(ctx, 1, "p", playlist.RotationCommand{Action: playlist.RotationActionStart, Index: &index})
+	require.NoError(t, err)
+	require.True(t, state.Running)
+	require.Equal(t, "b", state.Item.Value)
+	require.Equal(t, now.Add(5*time.Minute).UnixMilli(), state.NextSwitchAt)
+
+	now = now.Add(time.Minute)
+	state, err = r.apply(ctx, 1, "p", playlist.RotationCommand{Action: playlist.RotationActionNext})
+	require.NoError(t, err)
+	require.Equal(t, 0, state.Index)
+	require.Equal(t, now.UnixMilli(), state.SwitchedAt, "the interval restarts from the switch")
+
+	state, err = r.apply(ctx, 1, "p", playlist.RotationCommand{Action: playlist.RotationActionStop})
+	require.NoError(t, err)
+	require.False(t, state.Running)
+
+	now = now.Add(time.Hour)
+	state, err = r.current(ctx, 1, "p")
+	require.NoError(t, err)
+	require.Equal(t, 0, state.Index, "a stopped rotation stays on its item")
+
+	state, err = r.current(ctx, 2, "p")
+	require.NoError(t, err)
+	require.False(t, state.Running, "the rotations are stored per organization")
+
+	index = 2
+	_, err = r.apply(ctx, 1, "p", playlist.RotationCommand{Action: playlist.RotationActionStart, Index: &index})
+	require.Error(t, err)
+	_, err = r.apply(ctx, 1, "p", playlist.RotationCommand{Action: "pause"})
+	require.Error(t, err)
+}
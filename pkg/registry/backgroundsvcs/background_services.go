@@ -9,22 +9,43 @@ import (
 	"github.com/grafana/grafana/pkg/infra/usagestats/statscollector"
 	"github.com/grafana/grafana/pkg/registry"
 	apiregistry "github.com/grafana/grafana/pkg/registry/apis"
+	"github.com/grafana/grafana/pkg/services/annotations/bulk"
+	"github.com/grafana/grafana/pkg/services/annotations/retention"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn/authnimpl"
 	"github.com/grafana/grafana/pkg/services/authz"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/cloudmigration"
+	"github.com/grafana/grafana/pkg/services/configreload"
+	"github.com/grafana/grafana/pkg/services/credentials"
+	dashboardinsights "github.com/grafana/grafana/pkg/services/dashboards/insights"
+	"github.com/grafana/grafana/pkg/services/dashboards/schemamigration"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashsnaprender "github.com/grafana/grafana/pkg/services/dashboardsnapshots/render"
+	"github.com/grafana/grafana/pkg/services/datasources/healthcheck"
+	dsusage "github.com/grafana/grafana/pkg/services/datasources/usage"
+	"github.com/grafana/grafana/pkg/services/featureoverrides"
+	"github.com/grafana/grafana/pkg/services/folder/foldertree"
+	"github.com/grafana/grafana/pkg/services/groupmapping"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/jobs"
 	ldapapi "github.com/grafana/grafana/pkg/services/ldap/api"
+	ldapteamsync "github.com/grafana/grafana/pkg/services/ldap/teamsync"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/livehistory"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/live/pushpipeline"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattemptimpl"
+	"github.com/grafana/grafana/pkg/services/metering/meteringimpl"
+	"github.com/grafana/grafana/pkg/services/networkpolicy"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/outbox"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angulardetectorsprovider"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/keyretriever/dynamic"
@@ -33,12 +54,19 @@ import (
 	pluginStore "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	publicdashboardsmetric "github.com/grafana/grafana/pkg/services/publicdashboards/metric"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/sharelinks"
+	"github.com/grafana/grafana/pkg/services/query/querygrpc"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/grafana/grafana/pkg/services/recordedqueries"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/scheduledreports"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	secretsMigrations "github.com/grafana/grafana/pkg/services/secrets/kvstore/migrations"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	samanager "github.com/grafana/grafana/pkg/services/serviceaccounts/manager"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tokenexpiry"
+	"github.com/grafana/grafana/pkg/services/signedurl"
 	"github.com/grafana/grafana/pkg/services/ssosettings"
 	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingsimpl"
 	"github.com/grafana/grafana/pkg/services/store"
@@ -65,13 +93,35 @@ func ProvideBackgroundServiceRegistry(
 	ssoSettings *ssosettingsimpl.Service,
 	pluginExternal *pluginexternal.Service,
 	pluginInstaller *plugininstaller.Service,
+	queryAudit *queryaudit.Service,
+	dataSourceHealthCheck *healthcheck.Service,
+	recordedQueries *recordedqueries.Service,
+	dataSourceUsage *dsusage.Service,
+	credentialsService *credentials.Service,
+	dashboardSchemaMigration *schemamigration.Service,
+	annotationRetention *retention.Service,
+	dashboardInsights *dashboardinsights.Service,
+	jobService *jobs.Service,
+	meteringService *meteringimpl.Service,
+	outboxService *outbox.Service,
+	auditLog *auditlog.Service,
+	featureOverrides *featureoverrides.Service,
+	oauthTokenService *oauthtoken.Service,
+	groupMapping *groupmapping.Service,
+	ldapTeamSync *ldapteamsync.Service,
+	tokenExpiry *tokenexpiry.Service,
+	liveHistory *livehistory.Service,
+	configReload *configreload.Service,
+	pushPipeline *pushpipeline.Service,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ dashboardsnapshots.Service,
 	_ serviceaccounts.Service, _ *guardian.Provider,
 	_ *plugindashboardsservice.DashboardUpdater, _ *sanitizer.Provider,
 	_ *grpcserver.HealthService, _ authz.Client, _ *grpcserver.ReflectionService,
 	_ *ldapapi.Service, _ *apiregistry.Service, _ auth.IDService, _ *teamapi.TeamAPI, _ ssosettings.Service,
-	_ cloudmigration.Service, _ authnimpl.Registration,
+	_ cloudmigration.Service, _ authnimpl.Registration, _ *foldertree.Service, _ *sharelinks.Service,
+	_ *bulk.Service, _ *dashsnaprender.Service, _ *signedurl.Service, _ *networkpolicy.Service,
+	_ *scheduledreports.Service, _ *querygrpc.Service,
 ) *BackgroundServiceRegistry {
 	return NewBackgroundServiceRegistry(
 		httpServer,
@@ -108,6 +158,26 @@ func ProvideBackgroundServiceRegistry(
 		ssoSettings,
 		pluginExternal,
 		pluginInstaller,
+		queryAudit,
+		dataSourceHealthCheck,
+		recordedQueries,
+		dataSourceUsage,
+		credentialsService,
+		dashboardSchemaMigration,
+		annotationRetention,
+		dashboardInsights,
+		jobService,
+		meteringService,
+		outboxService,
+		auditLog,
+		featureOverrides,
+		oauthTokenService,
+		groupMapping,
+		ldapTeamSync,
+		tokenExpiry,
+		liveHistory,
+		configReload,
+		pushPipeline,
 	)
 }
 
@@ -4,6 +4,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/orgmigration"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -19,12 +20,13 @@ type Runner struct {
 	SecretsService    *manager.SecretsService
 	SecretsMigrator   secrets.Migrator
 	UserService       user.Service
+	OrgMigration      *orgmigration.Service
 }
 
 func NewRunner(cfg *setting.Cfg, sqlStore db.DB, settingsProvider setting.Provider,
 	encryptionService encryption.Internal, features featuremgmt.FeatureToggles,
 	secretsService *manager.SecretsService, secretsMigrator secrets.Migrator,
-	userService user.Service,
+	userService user.Service, orgMigration *orgmigration.Service,
 ) Runner {
 	return Runner{
 		Cfg:               cfg,
@@ -35,5 +37,6 @@ func NewRunner(cfg *setting.Cfg, sqlStore db.DB, settingsProvider setting.Provid
 		SecretsMigrator:   secretsMigrator,
 		Features:          features,
 		UserService:       userService,
+		OrgMigration:      orgMigration,
 	}
 }
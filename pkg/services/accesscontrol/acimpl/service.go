@@ -53,10 +53,11 @@ func ProvideService(
 	cfg *setting.Cfg, db db.ReplDB, routeRegister routing.RouteRegister, cache *localcache.CacheService,
 	accessControl accesscontrol.AccessControl, actionResolver accesscontrol.ActionResolver,
 	features featuremgmt.FeatureToggles, tracer tracing.Tracer, zclient zanzana.Client, permRegistry permreg.PermissionRegistry,
+	userService user.Service,
 ) (*Service, error) {
 	service := ProvideOSSService(cfg, database.ProvideService(db), actionResolver, cache, features, tracer, zclient, db.DB(), permRegistry)
 
-	api.NewAccessControlAPI(routeRegister, accessControl, service, features).RegisterAPIEndpoints()
+	api.NewAccessControlAPI(routeRegister, accessControl, service, userService, features).RegisterAPIEndpoints()
 	if err := accesscontrol.DeclareFixedRoles(service, cfg); err != nil {
 		return nil, err
 	}
@@ -163,6 +164,61 @@ func (s *Service) getUserPermissions(ctx context.Context, user identity.Requeste
 	return append(permissions, dbPermissions...), nil
 }
 
+// GetUserPermissionGrants returns the permissions of the user along with the roles and the assignments granting them.
+// It doesn't use the permission cache, so that it reflects the latest assignments of the user.
+func (s *Service) GetUserPermissionGrants(ctx context.Context, user identity.Requester) ([]accesscontrol.PermissionGrant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.GetUserPermissionGrants")
+	defer span.End()
+
+	grants := make([]accesscontrol.PermissionGrant, 0)
+	roles := accesscontrol.GetOrgRoles(user)
+
+	// The fixed roles are granted in memory to the basic roles and the basic roles inheriting from them
+	for _, builtin := range roles {
+		s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+			if _, ok := accesscontrol.BuiltInRolesWithParents(registration.Grants)[builtin]; !ok {
+				return true
+			}
+			for _, p := range registration.Role.Permissions {
+				grants = append(grants, accesscontrol.PermissionGrant{
+					Action:     p.Action,
+					Scope:      p.Scope,
+					Role:       registration.Role.Name,
+					Assignment: accesscontrol.AssignmentBasicRole,
+					Assignee:   builtin,
+				})
+			}
+			return true
+		})
+	}
+
+	userID, _ := identity.UserIdentifier(user.GetID())
+	dbGrants, err := s.store.GetUserPermissionGrants(ctx, accesscontrol.GetUserPermissionsQuery{
+		OrgID:        user.GetOrgID(),
+		UserID:       userID,
+		Roles:        roles,
+		TeamIDs:      user.GetTeams(),
+		RolePrefixes: OSSRolesPrefixes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grant := range dbGrants {
+		if !s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
+			grants = append(grants, grant)
+			continue
+		}
+		for _, p := range s.actionResolver.ExpandActionSets([]accesscontrol.Permission{grant.Permission()}) {
+			expanded := grant
+			expanded.Action, expanded.Scope = p.Action, p.Scope
+			grants = append(grants, expanded)
+		}
+	}
+
+	return grants, nil
+}
+
 func (s *Service) getBasicRolePermissions(ctx context.Context, role string, orgID int64) ([]accesscontrol.Permission, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.getBasicRolePermissions")
 	defer span.End()
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
@@ -969,3 +970,35 @@ func TestService_DeleteExternalServiceRole(t *testing.T) {
 		})
 	}
 }
+
+func TestService_GetUserPermissionGrants(t *testing.T) {
+	ctx := context.Background()
+	ac := setupTestEnv(t)
+	ac.store = actest.FakeStore{ExpectedPermissionGrants: []accesscontrol.PermissionGrant{
+		{Action: "teams:read", Scope: "teams:id:1", Role: accesscontrol.ManagedTeamRoleName(1), Assignment: accesscontrol.AssignmentTeam, Assignee: "1"},
+	}}
+
+	require.NoError(t, ac.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{
+				Name:        "fixed:test:reader",
+				Permissions: []accesscontrol.Permission{{Action: "test:read", Scope: "test:*"}},
+			},
+			Grants: []string{string(org.RoleViewer)},
+		},
+		accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{
+				Name:        "fixed:test:admin",
+				Permissions: []accesscontrol.Permission{{Action: "test:delete", Scope: "test:*"}},
+			},
+			Grants: []string{string(org.RoleAdmin)},
+		},
+	))
+
+	grants, err := ac.GetUserPermissionGrants(ctx, &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleEditor, Teams: []int64{1}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []accesscontrol.PermissionGrant{
+		{Action: "test:read", Scope: "test:*", Role: "fixed:test:reader", Assignment: accesscontrol.AssignmentBasicRole, Assignee: "Editor"},
+		{Action: "teams:read", Scope: "teams:id:1", Role: "managed:teams:1:permissions", Assignment: accesscontrol.AssignmentTeam, Assignee: "1"},
+	}, grants)
+}
@@ -17,6 +17,7 @@ type FakeService struct {
 	ExpectedPermissions             []accesscontrol.Permission
 	ExpectedFilteredUserPermissions []accesscontrol.Permission
 	ExpectedUsersPermissions        map[int64][]accesscontrol.Permission
+	ExpectedPermissionGrants        []accesscontrol.PermissionGrant
 }
 
 func (f FakeService) GetUsageStats(ctx context.Context) map[string]any {
@@ -27,6 +28,10 @@ func (f FakeService) GetUserPermissions(ctx context.Context, user identity.Reque
 	return f.ExpectedPermissions, f.ExpectedErr
 }
 
+func (f FakeService) GetUserPermissionGrants(ctx context.Context, user identity.Requester) ([]accesscontrol.PermissionGrant, error) {
+	return f.ExpectedPermissionGrants, f.ExpectedErr
+}
+
 func (f FakeService) SearchUsersPermissions(ctx context.Context, user identity.Requester, options accesscontrol.SearchOptions) (map[int64][]accesscontrol.Permission, error) {
 	return f.ExpectedUsersPermissions, f.ExpectedErr
 }
@@ -81,6 +86,7 @@ type FakeStore struct {
 	ExpectedTeamsPermissions      map[int64][]accesscontrol.Permission
 	ExpectedUsersPermissions      map[int64][]accesscontrol.Permission
 	ExpectedUsersRoles            map[int64][]string
+	ExpectedPermissionGrants      []accesscontrol.PermissionGrant
 	ExpectedErr                   error
 }
 
@@ -96,6 +102,10 @@ func (f FakeStore) GetTeamsPermissions(ctx context.Context, query accesscontrol.
 	return f.ExpectedTeamsPermissions, f.ExpectedErr
 }
 
+func (f FakeStore) GetUserPermissionGrants(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.PermissionGrant, error) {
+	return f.ExpectedPermissionGrants, f.ExpectedErr
+}
+
 func (f FakeStore) SearchUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions) (map[int64][]accesscontrol.Permission, error) {
 	return f.ExpectedUsersPermissions, f.ExpectedErr
 }
@@ -129,6 +129,36 @@ func (_m *MockStore) GetTeamsPermissions(ctx context.Context, query accesscontro
 	return r0, r1
 }
 
+// GetUserPermissionGrants provides a mock function with given fields: ctx, query
+func (_m *MockStore) GetUserPermissionGrants(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.PermissionGrant, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserPermissionGrants")
+	}
+
+	var r0 []accesscontrol.PermissionGrant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.PermissionGrant, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, accesscontrol.GetUserPermissionsQuery) []accesscontrol.PermissionGrant); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]accesscontrol.PermissionGrant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, accesscontrol.GetUserPermissionsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserPermissions provides a mock function with given fields: ctx, query
 func (_m *MockStore) GetUserPermissions(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.Permission, error) {
 	ret := _m.Called(ctx, query)
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -10,17 +11,19 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
 	"go.opentelemetry.io/otel"
 )
 
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/accesscontrol/api")
 
 func NewAccessControlAPI(router routing.RouteRegister, accesscontrol ac.AccessControl, service ac.Service,
-	features featuremgmt.FeatureToggles) *AccessControlAPI {
+	userService user.Service, features featuremgmt.FeatureToggles) *AccessControlAPI {
 	return &AccessControlAPI{
 		RouteRegister: router,
 		Service:       service,
 		AccessControl: accesscontrol,
+		userService:   userService,
 		features:      features,
 	}
 }
@@ -29,6 +32,7 @@ type AccessControlAPI struct {
 	Service       ac.Service
 	AccessControl ac.AccessControl
 	RouteRegister routing.RouteRegister
+	userService   user.Service
 	features      featuremgmt.FeatureToggles
 }
 
@@ -41,6 +45,7 @@ func (api *AccessControlAPI) RegisterAPIEndpoints() {
 		if api.features.IsEnabledGlobally(featuremgmt.FlagAccessControlOnCall) {
 			rr.Get("/users/permissions/search", authorize(ac.EvalPermission(ac.ActionUsersPermissionsRead)), routing.Wrap(api.searchUsersPermissions))
 		}
+		rr.Get("/users/permissions/simulate", authorize(ac.EvalPermission(ac.ActionUsersPermissionsRead)), routing.Wrap(api.simulateUserPermission))
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
 }
 
@@ -108,3 +113,95 @@ func (api *AccessControlAPI) searchUsersPermissions(c *contextmodel.ReqContext)
 
 	return response.JSON(http.StatusOK, permsByAction)
 }
+
+type simulationResult struct {
+	UserID         int64   `json:"userId"`
+	OrgID          int64   `json:"orgId"`
+	OrgRole        string  `json:"orgRole"`
+	IsGrafanaAdmin bool    `json:"isGrafanaAdmin"`
+	Teams          []int64 `json:"teams"`
+	Action         string  `json:"action"`
+	Scope          string  `json:"scope,omitempty"`
+	Allowed        bool    `json:"allowed"`
+	// Grants are the permissions of the user allowing the action on the scope, with the roles granting them.
+	Grants []ac.PermissionGrant `json:"grants"`
+}
+
+// GET /api/access-control/users/permissions/simulate
+func (api *AccessControlAPI) simulateUserPermission(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.simulateUserPermission")
+	defer span.End()
+
+	action, scope := c.Query("action"), c.Query("scope")
+	if action == "" {
+		return response.JSON(http.StatusBadRequest, "'action' must be provided")
+	}
+	if scope != "" && !ac.ValidateScope(scope) {
+		return response.JSON(http.StatusBadRequest, "'scope' is invalid")
+	}
+
+	searchOptions := ac.SearchOptions{TypedID: c.Query("namespacedId")}
+	userID, err := searchOptions.ComputeUserID()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "'namespacedId' must be the ID of a user or a service account", err)
+	}
+
+	usr, err := api.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: userID, OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, "user not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "could not get user", err)
+	}
+
+	grants, err := api.Service.GetUserPermissionGrants(ctx, usr)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "could not get user permissions", err)
+	}
+
+	permissions := make([]ac.Permission, 0, len(grants))
+	for _, grant := range grants {
+		permissions = append(permissions, grant.Permission())
+	}
+	usr.Permissions = map[int64]map[string][]string{usr.OrgID: ac.GroupScopesByActionContext(ctx, permissions)}
+
+	evaluator := ac.EvalPermission(action)
+	if scope != "" {
+		evaluator = ac.EvalPermission(action, scope)
+	}
+	allowed, err := api.AccessControl.Evaluate(ctx, usr, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "could not evaluate user permissions", err)
+	}
+
+	// Evaluate the permissions one by one, with the scope resolvers, to find the ones allowing the action
+	matching := make([]ac.PermissionGrant, 0)
+	if allowed {
+		for _, grant := range grants {
+			if grant.Action != action {
+				continue
+			}
+			grantee := *usr
+			grantee.Permissions = map[int64]map[string][]string{usr.OrgID: {grant.Action: {grant.Scope}}}
+			ok, err := api.AccessControl.Evaluate(ctx, &grantee, evaluator)
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "could not evaluate user permissions", err)
+			}
+			if ok {
+				matching = append(matching, grant)
+			}
+		}
+	}
+
+	return response.JSON(http.StatusOK, simulationResult{
+		UserID:         usr.UserID,
+		OrgID:          usr.OrgID,
+		OrgRole:        string(usr.OrgRole),
+		IsGrafanaAdmin: usr.IsGrafanaAdmin,
+		Teams:          usr.Teams,
+		Action:         action,
+		Scope:          scope,
+		Allowed:        allowed,
+		Grants:         matching,
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -40,7 +41,7 @@ func TestAPI_getUserActions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedPermissions: tt.permissions}
-			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, featuremgmt.WithFeatures())
+			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, usertest.NewUserServiceFake(), featuremgmt.WithFeatures())
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -93,7 +94,7 @@ func TestAPI_getUserPermissions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedPermissions: tt.permissions}
-			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, featuremgmt.WithFeatures())
+			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, usertest.NewUserServiceFake(), featuremgmt.WithFeatures())
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -174,7 +175,7 @@ func TestAccessControlAPI_searchUsersPermissions(t *testing.T) {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedUsersPermissions: tt.permissions}
 			accessControl := actest.FakeAccessControl{ExpectedEvaluate: true} // Always allow access to the endpoint
-			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, featuremgmt.WithFeatures(featuremgmt.FlagAccessControlOnCall))
+			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, usertest.NewUserServiceFake(), featuremgmt.WithFeatures(featuremgmt.FlagAccessControlOnCall))
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -199,3 +200,76 @@ func TestAccessControlAPI_searchUsersPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_simulateUserPermission(t *testing.T) {
+	type testCase struct {
+		desc           string
+		query          string
+		userErr        error
+		expectedCode   int
+		expectedGrants []ac.PermissionGrant
+	}
+
+	grants := []ac.PermissionGrant{
+		{Action: "dashboards:read", Scope: "dashboards:*", Role: "fixed:dashboards:reader", Assignment: ac.AssignmentBasicRole, Assignee: "Viewer"},
+		{Action: "dashboards:read", Scope: "dashboards:uid:1", Role: "managed:teams:1:permissions", Assignment: ac.AssignmentTeam, Assignee: "1"},
+		{Action: "dashboards:write", Scope: "dashboards:uid:1", Role: "managed:users:2:permissions", Assignment: ac.AssignmentUser, Assignee: "2"},
+	}
+
+	tests := []testCase{
+		{
+			desc:         "Should reject if the action is missing",
+			query:        "?namespacedId=user:2&scope=dashboards:uid:1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "Should reject if the identity is not a user or a service account",
+			query:        "?namespacedId=api-key:2&action=dashboards:read",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "Should return not found if the user doesn't exist",
+			query:        "?namespacedId=user:2&action=dashboards:read",
+			userErr:      user.ErrUserNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:           "Should return the grants allowing the action",
+			query:          "?namespacedId=user:2&action=dashboards:read&scope=dashboards:uid:1",
+			expectedCode:   http.StatusOK,
+			expectedGrants: grants[:2],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			acSvc := actest.FakeService{ExpectedPermissionGrants: grants}
+			userSvc := &usertest.FakeUserService{
+				ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: "Viewer", Teams: []int64{1}},
+				ExpectedError:        tt.userErr,
+			}
+			accessControl := actest.FakeAccessControl{ExpectedEvaluate: true}
+			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, userSvc, featuremgmt.WithFeatures())
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewGetRequest("/api/access-control/users/permissions/simulate" + tt.query)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID:       1,
+				Permissions: map[int64]map[string][]string{},
+			})
+			res, err := server.Send(req)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var output simulationResult
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&output))
+				require.True(t, output.Allowed)
+				require.Equal(t, "Viewer", output.OrgRole)
+				require.Equal(t, []int64{1}, output.Teams)
+				require.Equal(t, tt.expectedGrants, output.Grants)
+			}
+		})
+	}
+}
@@ -163,6 +163,106 @@ func (s *AccessControlStore) GetTeamsPermissions(ctx context.Context, query acce
 	return teamPermissions, err
 }
 
+type permissionGrant struct {
+	Action     string `xorm:"action"`
+	Scope      string `xorm:"scope"`
+	RoleName   string `xorm:"role_name"`
+	AssigneeID int64  `xorm:"assignee_id"`
+	Assignee   string `xorm:"assignee"`
+}
+
+// GetUserPermissionGrants returns the permissions of the roles assigned to the user, to their teams and to their
+// basic roles, along with the name of the role and the assignment granting each of them.
+func (s *AccessControlStore) GetUserPermissionGrants(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.PermissionGrant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetUserPermissionGrants")
+	defer span.End()
+
+	result := make([]accesscontrol.PermissionGrant, 0)
+	err := s.sql.ReadReplica().WithDbSession(ctx, func(sess *db.Session) error {
+		find := func(assignment, assigneeColumn, assignmentsSQL string, params []any) error {
+			q := `
+			SELECT
+				permission.action,
+				permission.scope,
+				role.name AS role_name,
+				assignment.` + assigneeColumn + `
+			FROM permission
+			INNER JOIN role ON role.id = permission.role_id
+			INNER JOIN (` + assignmentsSQL + `) AS assignment ON role.id = assignment.role_id
+			`
+
+			if len(query.RolePrefixes) > 0 {
+				rolePrefixesFilter, filterParams := accesscontrol.RolePrefixesFilter(query.RolePrefixes)
+				q += rolePrefixesFilter
+				params = append(params, filterParams...)
+			}
+
+			grants := make([]permissionGrant, 0)
+			if err := sess.SQL(q, params...).Find(&grants); err != nil {
+				return err
+			}
+
+			for _, g := range grants {
+				assignee := g.Assignee
+				if assignee == "" {
+					assignee = strconv.FormatInt(g.AssigneeID, 10)
+				}
+				result = append(result, accesscontrol.PermissionGrant{
+					Action:     g.Action,
+					Scope:      g.Scope,
+					Role:       g.RoleName,
+					Assignment: assignment,
+					Assignee:   assignee,
+				})
+			}
+			return nil
+		}
+
+		// This is an additional security. We should never have permissions granted to userID 0.
+		if query.UserID > 0 {
+			err := find(accesscontrol.AssignmentUser, "assignee_id", `
+				SELECT ur.role_id, ur.user_id AS assignee_id FROM user_role AS ur
+				WHERE ur.user_id = ? AND (ur.org_id = ? OR ur.org_id = ?)
+			`, []any{query.UserID, query.OrgID, accesscontrol.GlobalOrgID})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(query.TeamIDs) > 0 {
+			params := make([]any, 0, len(query.TeamIDs)+1)
+			for _, id := range query.TeamIDs {
+				params = append(params, id)
+			}
+			err := find(accesscontrol.AssignmentTeam, "assignee_id", `
+				SELECT tr.role_id, tr.team_id AS assignee_id FROM team_role AS tr
+				WHERE tr.team_id IN(?`+strings.Repeat(", ?", len(query.TeamIDs)-1)+`) AND tr.org_id = ?
+			`, append(params, query.OrgID))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(query.Roles) > 0 {
+			params := make([]any, 0, len(query.Roles)+2)
+			for _, role := range query.Roles {
+				params = append(params, role)
+			}
+			err := find(accesscontrol.AssignmentBasicRole, "assignee", `
+				SELECT br.role_id, br.role AS assignee FROM builtin_role AS br
+				WHERE br.role IN (?`+strings.Repeat(", ?", len(query.Roles)-1)+`) AND (br.org_id = ? OR br.org_id = ?)
+			`, append(params, query.OrgID, accesscontrol.GlobalOrgID))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
 // SearchUsersPermissions returns the list of user permissions in specific organization indexed by UserID
 func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions) (map[int64][]accesscontrol.Permission, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.database.SearchUsersPermissions")
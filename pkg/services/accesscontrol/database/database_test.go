@@ -240,6 +240,52 @@ func TestAccessControlStore_GetTeamsPermissions(t *testing.T) {
 	}
 }
 
+func TestAccessControlStore_GetUserPermissionGrants(t *testing.T) {
+	store, permissionStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	ctx := context.Background()
+
+	user, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	_, err := permissionStore.SetUserResourcePermission(ctx, 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+		Actions: []string{"dashboards:write"}, Resource: "dashboards", ResourceAttribute: "uid", ResourceID: "1",
+	}, nil)
+	require.NoError(t, err)
+	_, err = permissionStore.SetTeamResourcePermission(ctx, 1, team.ID, rs.SetResourcePermissionCommand{
+		Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceAttribute: "uid", ResourceID: "2",
+	}, nil)
+	require.NoError(t, err)
+	_, err = permissionStore.SetBuiltInResourcePermission(ctx, 1, "Editor", rs.SetResourcePermissionCommand{
+		Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceAttribute: "uid", ResourceID: "3",
+	}, nil)
+	require.NoError(t, err)
+	_, err = permissionStore.SetBuiltInResourcePermission(ctx, 1, "Admin", rs.SetResourcePermissionCommand{
+		Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceAttribute: "uid", ResourceID: "4",
+	}, nil)
+	require.NoError(t, err)
+
+	grants, err := store.GetUserPermissionGrants(ctx, accesscontrol.GetUserPermissionsQuery{
+		OrgID:   1,
+		UserID:  user.ID,
+		Roles:   []string{"Editor", "Viewer"},
+		TeamIDs: []int64{team.ID},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []accesscontrol.PermissionGrant{
+		{
+			Action: "dashboards:write", Scope: "dashboards:uid:1",
+			Role: accesscontrol.ManagedUserRoleName(user.ID), Assignment: accesscontrol.AssignmentUser, Assignee: fmt.Sprint(user.ID),
+		},
+		{
+			Action: "dashboards:read", Scope: "dashboards:uid:2",
+			Role: accesscontrol.ManagedTeamRoleName(team.ID), Assignment: accesscontrol.AssignmentTeam, Assignee: fmt.Sprint(team.ID),
+		},
+		{
+			Action: "dashboards:read", Scope: "dashboards:uid:3",
+			Role: accesscontrol.ManagedBuiltInRoleName("Editor"), Assignment: accesscontrol.AssignmentBasicRole, Assignee: "Editor",
+		},
+	}, grants)
+}
+
 func TestAccessControlStore_DeleteUserPermissions(t *testing.T) {
 	t.Run("expect permissions in all orgs to be deleted", func(t *testing.T) {
 		store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
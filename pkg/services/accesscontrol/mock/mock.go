@@ -23,6 +23,7 @@ type Calls struct {
 	Evaluate                       []interface{}
 	GetRoleByName                  []interface{}
 	GetUserPermissions             []interface{}
+	GetUserPermissionGrants        []interface{}
 	ClearUserPermissionCache       []interface{}
 	DeclareFixedRoles              []interface{}
 	DeclarePluginRoles             []interface{}
@@ -50,6 +51,7 @@ type Mock struct {
 	EvaluateFunc                       func(context.Context, identity.Requester, accesscontrol.Evaluator) (bool, error)
 	GetRoleByNameFunc                  func(context.Context, int64, string) (*accesscontrol.RoleDTO, error)
 	GetUserPermissionsFunc             func(context.Context, identity.Requester, accesscontrol.Options) ([]accesscontrol.Permission, error)
+	GetUserPermissionGrantsFunc        func(context.Context, identity.Requester) ([]accesscontrol.PermissionGrant, error)
 	ClearUserPermissionCacheFunc       func(identity.Requester)
 	DeclareFixedRolesFunc              func(...accesscontrol.RoleRegistration) error
 	DeclarePluginRolesFunc             func(context.Context, string, string, []plugins.RoleRegistration) error
@@ -151,6 +153,15 @@ func (m *Mock) GetUserPermissions(ctx context.Context, user identity.Requester,
 	return m.permissions, nil
 }
 
+func (m *Mock) GetUserPermissionGrants(ctx context.Context, user identity.Requester) ([]accesscontrol.PermissionGrant, error) {
+	m.Calls.GetUserPermissionGrants = append(m.Calls.GetUserPermissionGrants, []interface{}{ctx, user})
+	// Use override if provided
+	if m.GetUserPermissionGrantsFunc != nil {
+		return m.GetUserPermissionGrantsFunc(ctx, user)
+	}
+	return nil, nil
+}
+
 func (m *Mock) ClearUserPermissionCache(user identity.Requester) {
 	m.Calls.ClearUserPermissionCache = append(m.Calls.ClearUserPermissionCache, []interface{}{user})
 	// Use override if provided
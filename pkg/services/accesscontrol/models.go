@@ -226,6 +226,29 @@ type GetUserPermissionsQuery struct {
 	RolePrefixes []string
 }
 
+const (
+	AssignmentUser      = "user"
+	AssignmentTeam      = "team"
+	AssignmentBasicRole = "basic_role"
+)
+
+// PermissionGrant is a permission of a user along with the role which contains it and the assignment
+// of the role to the user, either directly, through one of their teams or through their basic role.
+type PermissionGrant struct {
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	// Role is the name of the role containing the permission
+	Role string `json:"role"`
+	// Assignment is how the role is assigned to the user: user, team or basic_role
+	Assignment string `json:"assignment"`
+	// Assignee is the user ID, the team ID or the basic role the role is assigned to
+	Assignee string `json:"assignee"`
+}
+
+func (g PermissionGrant) Permission() Permission {
+	return Permission{Action: g.Action, Scope: g.Scope}
+}
+
 // ResourcePermission is structure that holds all actions that either a team / user / builtin-role
 // can perform against specific resource.
 type ResourcePermission struct {
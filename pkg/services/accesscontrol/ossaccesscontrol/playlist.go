@@ -0,0 +1,64 @@
+package ossaccesscontrol
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	PlaylistViewActions  = []string{playlist.ActionRead}
+	PlaylistEditActions  = append(PlaylistViewActions, []string{playlist.ActionWrite, playlist.ActionDelete}...)
+	PlaylistAdminActions = append(PlaylistEditActions, []string{playlist.ActionPermissionsRead, playlist.ActionPermissionsWrite}...)
+)
+
+type PlaylistPermissionsService struct {
+	*resourcepermissions.Service
+}
+
+func ProvidePlaylistPermissions(
+	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
+	license licensing.Licensing, playlistService playlist.Service, service accesscontrol.Service,
+	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+) (*PlaylistPermissionsService, error) {
+	options := resourcepermissions.Options{
+		Resource:          "playlists",
+		ResourceAttribute: "uid",
+		ResourceValidator: func(ctx context.Context, orgID int64, resourceID string) error {
+			ctx, span := tracer.Start(ctx, "accesscontrol.ossaccesscontrol.ProvidePlaylistPermissions.ResourceValidator")
+			defer span.End()
+
+			_, err := playlistService.GetWithoutItems(ctx, &playlist.GetPlaylistByUidQuery{UID: resourceID, OrgId: orgID})
+			return err
+		},
+		Assignments: resourcepermissions.Assignments{
+			Users:           true,
+			Teams:           true,
+			BuiltInRoles:    true,
+			ServiceAccounts: true,
+		},
+		PermissionsToActions: map[string][]string{
+			"View":  PlaylistViewActions,
+			"Edit":  PlaylistEditActions,
+			"Admin": PlaylistAdminActions,
+		},
+		ReaderRoleName: "Playlist permission reader",
+		WriterRoleName: "Playlist permission writer",
+		RoleGroup:      "Playlists",
+	}
+
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService)
+	if err != nil {
+		return nil, err
+	}
+	return &PlaylistPermissionsService{srv}, nil
+}
@@ -49,6 +49,7 @@ func (a *api) registerEndpoints() {
 		r.Get("/description", auth(accesscontrol.EvalPermission(actionRead)), routing.Wrap(a.getDescription))
 		r.Get("/:resourceID", auth(accesscontrol.EvalPermission(actionRead, scope)), routing.Wrap(a.getPermissions))
 		r.Post("/:resourceID", licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setPermissions))
+		r.Post("/:resourceID/preview", licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.previewPermissions))
 		if a.service.options.Assignments.Users {
 			r.Post("/:resourceID/users/:userID", licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setUserPermission))
 		}
@@ -416,6 +417,58 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 	return response.Success("Permissions updated")
 }
 
+// swagger:parameters previewResourcePermissions
+type PreviewResourcePermissionsParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:path
+	// required:true
+	ResourceID string `json:"resourceID"`
+
+	// in:body
+	// required:true
+	Body setPermissionsCommand
+}
+
+// swagger:response previewResourcePermissionsResponse
+type previewResourcePermissionsResponse []PermissionChange
+
+// swagger:route POST /access-control/{resource}/{resourceID}/preview access_control previewResourcePermissions
+//
+// Preview resource permissions.
+//
+// Returns the changes of access to a resource that setting its permissions with the same body as
+// `POST /access-control/{resource}/{resourceID}` would make, without setting them.
+// The changes of the users are their effective access, resolved from all their permissions, including the users
+// of the teams of the body. The changes of the teams and built-in roles are the permissions they are granted.
+//
+// Responses:
+// 200: previewResourcePermissionsResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (a *api) previewPermissions(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.previewPermissions")
+	defer span.End()
+
+	resourceID := web.Params(c.Req)[":resourceID"]
+
+	cmd := setPermissionsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data: "+err.Error(), err)
+	}
+
+	changes, err := a.service.PreviewPermissions(ctx, c.SignedInUser, resourceID, cmd.Permissions...)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, previewResourcePermissionsResponse(changes))
+}
+
 func permissionSetResponse(cmd setPermissionCommand) response.Response {
 	message := "Permission updated"
 	if cmd.Permission == "" {
@@ -31,3 +31,18 @@ type GetResourcePermissionsQuery struct {
 	EnforceAccessControl bool
 	User                 identity.Requester
 }
+
+// PermissionChange is the change of access to a resource of a user, a team or a built-in role.
+type PermissionChange struct {
+	UserID      int64  `json:"userId,omitempty"`
+	UserLogin   string `json:"userLogin,omitempty"`
+	TeamID      int64  `json:"teamId,omitempty"`
+	Team        string `json:"team,omitempty"`
+	BuiltInRole string `json:"builtInRole,omitempty"`
+	// Before and After are the highest permissions before and after the change, empty without access.
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// Gained and Lost are the permissions gained and lost with the change, from the lowest to the highest.
+	Gained []string `json:"gained"`
+	Lost   []string `json:"lost"`
+}
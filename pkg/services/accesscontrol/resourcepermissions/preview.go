@@ -0,0 +1,231 @@
+package resourcepermissions
+
+import (
+	"context"
+	"sort"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// assignee is the user, team or built-in role a managed permission is granted to.
+type assignee struct {
+	userID      int64
+	teamID      int64
+	builtInRole string
+}
+
+// PreviewPermissions returns the changes of access to a resource that setting permissions with the commands would
+// make, without setting them. The access of the users is their effective access, resolved from all their permissions
+// including the ones of their teams and basic roles. The access of the teams and built-in roles is the permission
+// they are granted on the resource; the users of the built-in roles are not listed.
+func (s *Service) PreviewPermissions(
+	ctx context.Context, requester identity.Requester, resourceID string,
+	commands ...accesscontrol.SetResourcePermissionCommand,
+) ([]PermissionChange, error) {
+	ctx, span := tracer.Start(ctx, "accesscontroll.resourcepermissions.PreviewPermissions")
+	defer span.End()
+
+	orgID := requester.GetOrgID()
+	if err := s.validateResource(ctx, orgID, resourceID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetPermissions(ctx, requester, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the permissions set on the resource itself, the inherited ones are not changed
+	scope := accesscontrol.Scope(s.options.Resource, s.options.ResourceAttribute, resourceID)
+	before := make(map[assignee][]string)
+	for _, p := range current {
+		if !p.IsManaged || p.IsInherited || p.Scope != scope {
+			continue
+		}
+		key := assignee{userID: p.UserId, teamID: p.TeamId, builtInRole: p.BuiltInRole}
+		before[key] = append(before[key], p.Actions...)
+	}
+
+	after := make(map[assignee][]string, len(before))
+	for key, actions := range before {
+		after[key] = actions
+	}
+
+	changes := make([]PermissionChange, 0)
+	userIDs := make([]int64, 0)
+	for _, cmd := range commands {
+		actions, err := s.validateCommand(ctx, orgID, cmd)
+		if err != nil {
+			return nil, err
+		}
+		key := assignee{userID: cmd.UserID, teamID: cmd.TeamID, builtInRole: cmd.BuiltinRole}
+		if len(actions) == 0 {
+			delete(after, key)
+		} else {
+			after[key] = actions
+		}
+
+		switch {
+		case cmd.UserID != 0:
+			userIDs = append(userIDs, cmd.UserID)
+		case cmd.TeamID != 0:
+			members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: orgID, TeamID: cmd.TeamID, SignedInUser: requester})
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				userIDs = append(userIDs, member.UserID)
+			}
+		}
+	}
+
+	// the teams and built-in roles of the commands, in the order of the commands
+	for _, cmd := range commands {
+		if cmd.UserID != 0 {
+			continue
+		}
+		key := assignee{teamID: cmd.TeamID, builtInRole: cmd.BuiltinRole}
+		change := s.newPermissionChange(s.grantedPermissions(before[key]), s.grantedPermissions(after[key]))
+		if change == nil {
+			continue
+		}
+		change.TeamID = cmd.TeamID
+		change.BuiltInRole = cmd.BuiltinRole
+		if cmd.TeamID != 0 {
+			t, err := s.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: orgID, ID: cmd.TeamID})
+			if err != nil {
+				return nil, err
+			}
+			change.Team = t.Name
+		}
+		changes = append(changes, *change)
+	}
+
+	// the users of the commands and the members of their teams, ordered by ID
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	for _, userID := range slices.Compact(userIDs) {
+		usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{OrgID: orgID, UserID: userID})
+		if err != nil {
+			return nil, err
+		}
+
+		permissions, err := s.service.GetUserPermissions(ctx, usr, accesscontrol.Options{})
+		if err != nil {
+			return nil, err
+		}
+		// the permissions the user has on the resource through its managed permissions are replaced
+		// with the ones before and after the change
+		unmanaged := make([]accesscontrol.Permission, 0, len(permissions))
+		for _, p := range permissions {
+			if p.Scope != scope || !s.isManagedAction(p.Action) {
+				unmanaged = append(unmanaged, p)
+			}
+		}
+
+		granted := func(assigned map[assignee][]string) ([]string, error) {
+			all := slices.Clone(unmanaged)
+			for key, actions := range assigned {
+				if key.userID == usr.UserID || (key.teamID != 0 && slices.Contains(usr.Teams, key.teamID)) ||
+					(key.builtInRole != "" && slices.Contains(accesscontrol.GetOrgRoles(usr), key.builtInRole)) {
+					all = append(all, accesscontrol.PermissionsForActions(actions, scope)...)
+				}
+			}
+			return s.evaluatePermissions(ctx, usr, all, scope)
+		}
+		userBefore, err := granted(before)
+		if err != nil {
+			return nil, err
+		}
+		userAfter, err := granted(after)
+		if err != nil {
+			return nil, err
+		}
+
+		if change := s.newPermissionChange(userBefore, userAfter); change != nil {
+			change.UserID = usr.UserID
+			change.UserLogin = usr.Login
+			changes = append(changes, *change)
+		}
+	}
+
+	return changes, nil
+}
+
+// evaluatePermissions returns the permissions of the resource a user has with a set of permissions, resolving the
+// scopes of the resource like access control evaluation does.
+func (s *Service) evaluatePermissions(ctx context.Context, usr *user.SignedInUser, permissions []accesscontrol.Permission, scope string) ([]string, error) {
+	simulated := *usr
+	simulated.Permissions = map[int64]map[string][]string{usr.OrgID: accesscontrol.GroupScopesByActionContext(ctx, permissions)}
+
+	granted := make([]string, 0, len(s.permissions))
+	for _, permission := range s.permissions {
+		evaluators := make([]accesscontrol.Evaluator, 0, len(s.options.PermissionsToActions[permission]))
+		for _, action := range s.options.PermissionsToActions[permission] {
+			evaluators = append(evaluators, accesscontrol.EvalPermission(action, scope))
+		}
+		ok, err := s.ac.Evaluate(ctx, &simulated, accesscontrol.EvalAll(evaluators...))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			granted = append(granted, permission)
+		}
+	}
+	return granted, nil
+}
+
+// grantedPermissions returns the permissions of the resource the actions grant.
+func (s *Service) grantedPermissions(actions []string) []string {
+	granted := make([]string, 0, len(s.permissions))
+	for _, permission := range s.permissions {
+		p := accesscontrol.ResourcePermission{Actions: actions}
+		if len(actions) > 0 && p.Contains(s.options.PermissionsToActions[permission]) {
+			granted = append(granted, permission)
+		}
+	}
+	return granted
+}
+
+// newPermissionChange returns the change between the permissions granted before and after, or nil if there is none.
+// The permissions are ordered from the highest to the lowest.
+func (s *Service) newPermissionChange(before, after []string) *PermissionChange {
+	change := &PermissionChange{Gained: []string{}, Lost: []string{}}
+	if len(before) > 0 {
+		change.Before = before[0]
+	}
+	if len(after) > 0 {
+		change.After = after[0]
+	}
+	// list the permissions from the lowest to the highest
+	for i := len(s.permissions) - 1; i >= 0; i-- {
+		permission := s.permissions[i]
+		had, has := slices.Contains(before, permission), slices.Contains(after, permission)
+		if !had && has {
+			change.Gained = append(change.Gained, permission)
+		} else if had && !has {
+			change.Lost = append(change.Lost, permission)
+		}
+	}
+	if len(change.Gained) == 0 && len(change.Lost) == 0 {
+		return nil
+	}
+	return change
+}
+
+// isManagedAction returns whether an action can be granted by the managed permissions of the resource.
+func (s *Service) isManagedAction(action string) bool {
+	if slices.Contains(s.actions, action) {
+		return true
+	}
+	for _, permission := range s.permissions {
+		if action == GetActionSetName(s.options.Resource, permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -299,21 +299,7 @@ func (s *Service) SetPermissions(
 
 	dbCommands := make([]SetResourcePermissionsCommand, 0, len(commands))
 	for _, cmd := range commands {
-		if cmd.UserID != 0 {
-			if err := s.validateUser(ctx, orgID, cmd.UserID); err != nil {
-				return nil, err
-			}
-		} else if cmd.TeamID != 0 {
-			if err := s.validateTeam(ctx, orgID, cmd.TeamID); err != nil {
-				return nil, err
-			}
-		} else {
-			if err := s.validateBuiltinRole(ctx, cmd.BuiltinRole); err != nil {
-				return nil, err
-			}
-		}
-
-		actions, err := s.mapPermission(cmd.Permission)
+		actions, err := s.validateCommand(ctx, orgID, cmd)
 		if err != nil {
 			return nil, err
 		}
@@ -369,6 +355,25 @@ func (s *Service) mapPermission(permission string) ([]string, error) {
 	return nil, ErrInvalidPermission.Build(ErrInvalidPermissionData(permission))
 }
 
+// validateCommand validates the assignment of a set permission command and returns the actions of its permission.
+func (s *Service) validateCommand(ctx context.Context, orgID int64, cmd accesscontrol.SetResourcePermissionCommand) ([]string, error) {
+	if cmd.UserID != 0 {
+		if err := s.validateUser(ctx, orgID, cmd.UserID); err != nil {
+			return nil, err
+		}
+	} else if cmd.TeamID != 0 {
+		if err := s.validateTeam(ctx, orgID, cmd.TeamID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.validateBuiltinRole(ctx, cmd.BuiltinRole); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.mapPermission(cmd.Permission)
+}
+
 func (s *Service) validateResource(ctx context.Context, orgID int64, resourceID string) error {
 	ctx, span := tracer.Start(ctx, "accesscontroll.resourcepermissions.validateResource")
 	defer span.End()
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
 	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/team/teamimpl"
@@ -226,6 +227,78 @@ func TestService_SetPermissions(t *testing.T) {
 	}
 }
 
+func TestService_PreviewPermissions(t *testing.T) {
+	service, usrSvc, teamSvc := setupTestEnvironment(t, Options{
+		Resource:          "dashboards",
+		ResourceAttribute: "uid",
+		Assignments: Assignments{
+			Users:        true,
+			Teams:        true,
+			BuiltInRoles: true,
+		},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+			"Edit": {"dashboards:read", "dashboards:write"},
+		},
+	})
+	requester := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		accesscontrol.ActionOrgUsersRead: {"users:*"},
+		accesscontrol.ActionTeamsRead:    {"teams:*"},
+		serviceaccounts.ActionRead:       {"serviceaccounts:*"},
+	}}}
+
+	alice, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "alice", OrgID: 1})
+	require.NoError(t, err)
+	bob, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "bob", OrgID: 1})
+	require.NoError(t, err)
+	tm, err := teamSvc.CreateTeam(context.Background(), "team", "", 1)
+	require.NoError(t, err)
+	_, err = service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: alice.ID}, "1", "View")
+	require.NoError(t, err)
+	// the permissions of the users include their managed permissions on the dashboard
+	service.service.(*actest.FakeService).ExpectedPermissions = []accesscontrol.Permission{
+		{Action: "dashboards:read", Scope: "dashboards:uid:1"},
+	}
+
+	t.Run("should return the changes of the users and teams", func(t *testing.T) {
+		changes, err := service.PreviewPermissions(context.Background(), requester, "1",
+			accesscontrol.SetResourcePermissionCommand{UserID: alice.ID, Permission: "Edit"},
+			accesscontrol.SetResourcePermissionCommand{UserID: bob.ID, Permission: ""},
+			accesscontrol.SetResourcePermissionCommand{TeamID: tm.ID, Permission: "View"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []PermissionChange{
+			{TeamID: tm.ID, Team: "team", Before: "", After: "View", Gained: []string{"View"}, Lost: []string{}},
+			{UserID: alice.ID, UserLogin: "alice", Before: "View", After: "Edit", Gained: []string{"Edit"}, Lost: []string{}},
+		}, changes)
+	})
+
+	t.Run("should return the permissions lost by removing a permission", func(t *testing.T) {
+		changes, err := service.PreviewPermissions(context.Background(), requester, "1",
+			accesscontrol.SetResourcePermissionCommand{UserID: alice.ID, Permission: ""},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []PermissionChange{
+			{UserID: alice.ID, UserLogin: "alice", Before: "View", After: "", Gained: []string{}, Lost: []string{"View"}},
+		}, changes)
+	})
+
+	t.Run("should not set the permissions", func(t *testing.T) {
+		permissions, err := service.GetPermissions(context.Background(), requester, "1")
+		require.NoError(t, err)
+		require.Len(t, permissions, 1)
+		assert.Equal(t, alice.ID, permissions[0].UserId)
+		assert.Equal(t, "View", service.MapActions(permissions[0]))
+	})
+
+	t.Run("should return error for invalid permission", func(t *testing.T) {
+		_, err := service.PreviewPermissions(context.Background(), requester, "1",
+			accesscontrol.SetResourcePermissionCommand{UserID: alice.ID, Permission: "Not real permission"},
+		)
+		assert.Error(t, err)
+	})
+}
+
 func TestService_RegisterActionSets(t *testing.T) {
 	type registerActionSetsTest struct {
 		desc               string
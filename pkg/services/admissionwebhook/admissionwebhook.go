@@ -0,0 +1,211 @@
+package admissionwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/authlib/claims"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// Service validates and mutates the dashboards and folders of a Kubernetes cluster before they are
+// committed to etcd, with the same checks as the resource server of unified storage. Besides the
+// dashboard.grafana.app and folder.grafana.app resources, it understands the GrafanaDashboard and
+// GrafanaFolder resources of the Grafana Operator.
+type Service struct {
+	settings  setting.AdmissionWebhooksSettings
+	namespace string
+	folders   folder.Service
+	validator *resource.EventValidator
+	user      identity.Requester
+	log       log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, routeRegister routing.RouteRegister, folderService folder.Service) *Service {
+	s := &Service{
+		settings:  cfg.AdmissionWebhooks,
+		namespace: request.GetNamespaceMapper(cfg)(cfg.AdmissionWebhooks.OrgID),
+		folders:   folderService,
+		user: accesscontrol.BackgroundUser("admission_webhook", cfg.AdmissionWebhooks.OrgID, org.RoleViewer, []accesscontrol.Permission{
+			{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+		}),
+		log: log.New("admission-webhook"),
+	}
+	s.validator = &resource.EventValidator{Access: resource.WriteAccessHooks{Folder: s.folderExists}}
+
+	if s.settings.Enabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+// Validate checks the object of an admission request. Objects that are not dashboards or folders are allowed.
+func (s *Service) Validate(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return allowed(req)
+	}
+
+	obj, key, err := s.toGrafanaObject(req)
+	if err != nil {
+		return denied(req, resource.NewBadRequestError(err.Error()))
+	}
+	if obj == nil {
+		return allowed(req)
+	}
+
+	title, _, _ := unstructured.NestedString(obj.Object, "spec", "title")
+	if title == "" {
+		return denied(req, resource.NewBadRequestError(fmt.Sprintf("%s title is required", obj.GetKind())))
+	}
+
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return denied(req, resource.AsErrorResult(err))
+	}
+	if result := s.validator.Validate(ctx, s.user, key, meta); result != nil {
+		return denied(req, result)
+	}
+	return allowed(req)
+}
+
+// Mutate sets the uid of the model of the dashboard.grafana.app dashboards to their name and removes
+// the internal id, so the model stored in the cluster matches the one Grafana would store.
+func (s *Service) Mutate(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return allowed(req)
+	}
+	if req.Kind.Group != dashboardv0alpha1.GROUP || req.Kind.Kind != "Dashboard" {
+		return allowed(req)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.Object.Raw); err != nil {
+		return denied(req, resource.NewBadRequestError(err.Error()))
+	}
+	spec, ok := obj.Object["spec"].(map[string]any)
+	if !ok {
+		return allowed(req)
+	}
+
+	patch := []jsonPatchOperation{}
+	if uid, _ := spec["uid"].(string); uid != obj.GetName() {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/spec/uid", Value: obj.GetName()})
+	}
+	if _, ok := spec["id"]; ok {
+		patch = append(patch, jsonPatchOperation{Op: "remove", Path: "/spec/id"})
+	}
+
+	res := allowed(req)
+	if len(patch) > 0 {
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return denied(req, resource.AsErrorResult(err))
+		}
+		patchType := admissionv1.PatchTypeJSONPatch
+		res.Patch = data
+		res.PatchType = &patchType
+	}
+	return res
+}
+
+// toGrafanaObject converts the object of the request to a dashboard or a folder of the namespace of
+// the configured organization. It returns a nil object when the object is neither a dashboard nor a folder.
+func (s *Service) toGrafanaObject(req *admissionv1.AdmissionRequest) (*unstructured.Unstructured, *resource.ResourceKey, error) {
+	var convert func(*unstructured.Unstructured) (*unstructured.Unstructured, error)
+	switch {
+	case req.Kind.Group == dashboardv0alpha1.GROUP && req.Kind.Kind == "Dashboard",
+		req.Kind.Group == folderv0alpha1.GROUP && req.Kind.Kind == "Folder":
+		convert = func(src *unstructured.Unstructured) (*unstructured.Unstructured, error) { return src, nil }
+	case req.Kind.Group == operatorGroup && req.Kind.Kind == "GrafanaDashboard":
+		convert = fromOperatorDashboard
+	case req.Kind.Group == operatorGroup && req.Kind.Kind == "GrafanaFolder":
+		convert = fromOperatorFolder
+	default:
+		return nil, nil, nil
+	}
+
+	src := &unstructured.Unstructured{}
+	if err := src.UnmarshalJSON(req.Object.Raw); err != nil {
+		return nil, nil, err
+	}
+	obj, err := convert(src)
+	if obj == nil || err != nil {
+		return nil, nil, err
+	}
+
+	obj.SetNamespace(s.namespace)
+	gvk := obj.GroupVersionKind()
+	key := &resource.ResourceKey{
+		Namespace: s.namespace,
+		Group:     gvk.Group,
+		Resource:  dashboardv0alpha1.DashboardResourceInfo.GroupResource().Resource,
+		Name:      obj.GetName(),
+	}
+	if gvk.Group == folderv0alpha1.GROUP {
+		key.Resource = folderv0alpha1.RESOURCE
+	}
+	return obj, key, nil
+}
+
+// folderExists is the folder hook of the validator: the objects of the cluster can be saved in any
+// folder of the organization.
+func (s *Service) folderExists(ctx context.Context, _ claims.AuthInfo, uid string) bool {
+	_, err := s.folders.Get(ctx, &folder.GetFolderQuery{
+		UID:          &uid,
+		OrgID:        s.settings.OrgID,
+		SignedInUser: s.user,
+	})
+	if err != nil {
+		if !errors.Is(err, dashboards.ErrFolderNotFound) && !errors.Is(err, folder.ErrFolderNotFound) {
+			s.log.Error("Failed to get folder", "uid", uid, "error", err)
+		}
+		return false
+	}
+	return true
+}
+
+type jsonPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+func allowed(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+}
+
+func denied(req *admissionv1.AdmissionRequest, result *resource.ErrorResult) *admissionv1.AdmissionResponse {
+	code := result.Code
+	if code == 0 {
+		code = http.StatusBadRequest
+	}
+	return &admissionv1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: result.Message,
+			Reason:  metav1.StatusReason(result.Reason),
+			Code:    code,
+		},
+	}
+}
@@ -0,0 +1,156 @@
+package admissionwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupService(t *testing.T, folderErr error) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.AdmissionWebhooks = setting.AdmissionWebhooksSettings{Token: "secret", OrgID: 1}
+	folders := foldertest.NewFakeService()
+	folders.ExpectedFolder = &folder.Folder{UID: "parent"}
+	folders.ExpectedError = folderErr
+	return ProvideService(cfg, routing.NewRouteRegister(), folders)
+}
+
+func newRequest(group, kind string, operation admissionv1.Operation, obj map[string]any) *admissionv1.AdmissionRequest {
+	raw, _ := json.Marshal(obj)
+	return &admissionv1.AdmissionRequest{
+		UID:       "req-1",
+		Kind:      metav1.GroupVersionKind{Group: group, Kind: kind},
+		Operation: operation,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := setupService(t, nil)
+
+	t.Run("allows a valid dashboard", func(t *testing.T) {
+		res := s.Validate(context.Background(), newRequest("dashboard.grafana.app", "Dashboard", admissionv1.Create, map[string]any{
+			"apiVersion": "dashboard.grafana.app/v0alpha1",
+			"kind":       "Dashboard",
+			"metadata": map[string]any{
+				"name":        "abc",
+				"namespace":   "monitoring",
+				"annotations": map[string]any{"grafana.app/folder": "parent"},
+			},
+			"spec": map[string]any{"title": "Dashboard"},
+		}))
+		require.True(t, res.Allowed)
+		require.Equal(t, "req-1", string(res.UID))
+	})
+
+	t.Run("rejects a dashboard without title", func(t *testing.T) {
+		res := s.Validate(context.Background(), newRequest("dashboard.grafana.app", "Dashboard", admissionv1.Create, map[string]any{
+			"apiVersion": "dashboard.grafana.app/v0alpha1",
+			"kind":       "Dashboard",
+			"metadata":   map[string]any{"name": "abc"},
+			"spec":       map[string]any{},
+		}))
+		require.False(t, res.Allowed)
+		require.Equal(t, int32(http.StatusBadRequest), res.Result.Code)
+	})
+
+	t.Run("rejects an operator dashboard with an invalid uid", func(t *testing.T) {
+		res := s.Validate(context.Background(), newRequest(operatorGroup, "GrafanaDashboard", admissionv1.Update, map[string]any{
+			"apiVersion": "grafana.integreatly.org/v1beta1",
+			"kind":       "GrafanaDashboard",
+			"metadata":   map[string]any{"name": "abc"},
+			"spec":       map[string]any{"json": `{"title": "Dashboard", "uid": "not valid"}`},
+		}))
+		require.False(t, res.Allowed)
+	})
+
+	t.Run("rejects an operator dashboard with invalid json", func(t *testing.T) {
+		res := s.Validate(context.Background(), newRequest(operatorGroup, "GrafanaDashboard", admissionv1.Create, map[string]any{
+			"apiVersion": "grafana.integreatly.org/v1beta1",
+			"kind":       "GrafanaDashboard",
+			"metadata":   map[string]any{"name": "abc"},
+			"spec":       map[string]any{"json": `{`},
+		}))
+		require.False(t, res.Allowed)
+	})
+
+	t.Run("allows an operator folder named after the resource", func(t *testing.T) {
+		res := s.Validate(context.Background(), newRequest(operatorGroup, "GrafanaFolder", admissionv1.Create, map[string]any{
+			"apiVersion": "grafana.integreatly.org/v1beta1",
+			"kind":       "GrafanaFolder",
+			"metadata":   map[string]any{"name": "team-a"},
+			"spec":       map[string]any{"parentFolderUID": "parent"},
+		}))
+		require.True(t, res.Allowed)
+	})
+
+	t.Run("rejects a folder whose parent does not exist", func(t *testing.T) {
+		s := setupService(t, folder.ErrFolderNotFound)
+		res := s.Validate(context.Background(), newRequest(operatorGroup, "GrafanaFolder", admissionv1.Create, map[string]any{
+			"apiVersion": "grafana.integreatly.org/v1beta1",
+			"kind":       "GrafanaFolder",
+			"metadata":   map[string]any{"name": "team-a"},
+			"spec":       map[string]any{"parentFolderUID": "missing"},
+		}))
+		require.False(t, res.Allowed)
+	})
+
+	t.Run("allows other resources and deletions", func(t *testing.T) {
+		require.True(t, s.Validate(context.Background(), newRequest("", "ConfigMap", admissionv1.Create, map[string]any{})).Allowed)
+		require.True(t, s.Validate(context.Background(), newRequest("dashboard.grafana.app", "Dashboard", admissionv1.Delete, nil)).Allowed)
+	})
+}
+
+func TestMutate(t *testing.T) {
+	s := setupService(t, nil)
+
+	res := s.Mutate(context.Background(), newRequest("dashboard.grafana.app", "Dashboard", admissionv1.Create, map[string]any{
+		"apiVersion": "dashboard.grafana.app/v0alpha1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "abc"},
+		"spec":       map[string]any{"title": "Dashboard", "id": 12, "uid": "other"},
+	}))
+	require.True(t, res.Allowed)
+	require.Equal(t, admissionv1.PatchTypeJSONPatch, *res.PatchType)
+	require.JSONEq(t, `[{"op":"add","path":"/spec/uid","value":"abc"},{"op":"remove","path":"/spec/id"}]`, string(res.Patch))
+
+	res = s.Mutate(context.Background(), newRequest("dashboard.grafana.app", "Dashboard", admissionv1.Create, map[string]any{
+		"apiVersion": "dashboard.grafana.app/v0alpha1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "abc"},
+		"spec":       map[string]any{"title": "Dashboard", "uid": "abc"},
+	}))
+	require.True(t, res.Allowed)
+	require.Nil(t, res.Patch)
+}
+
+func TestAuthorized(t *testing.T) {
+	s := setupService(t, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admission/validate", strings.NewReader("{}"))
+	require.False(t, s.authorized(req))
+
+	req.Header.Set("Authorization", "Bearer wrong")
+	require.False(t, s.authorized(req))
+
+	req.Header.Set("Authorization", "Bearer secret")
+	require.True(t, s.authorized(req))
+
+	s.settings.Token = ""
+	req.Header.Set("Authorization", "Bearer ")
+	require.False(t, s.authorized(req))
+}
@@ -0,0 +1,60 @@
+package admissionwebhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+// The endpoints are called by the Kubernetes API server, which authenticates with the configured token
+// instead of a Grafana user.
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/admission", func(admission routing.RouteRegister) {
+		admission.Post("/validate", routing.Wrap(s.validateHandler))
+		admission.Post("/mutate", routing.Wrap(s.mutateHandler))
+	})
+}
+
+func (s *Service) validateHandler(c *contextmodel.ReqContext) response.Response {
+	return s.review(c, s.Validate)
+}
+
+func (s *Service) mutateHandler(c *contextmodel.ReqContext) response.Response {
+	return s.review(c, s.Mutate)
+}
+
+func (s *Service) review(c *contextmodel.ReqContext, handle func(context.Context, *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) response.Response {
+	if !s.authorized(c.Req) {
+		return response.Error(http.StatusUnauthorized, "Invalid admission webhook token", nil)
+	}
+
+	review := admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(c.Req.Body).Decode(&review); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to decode admission review", err)
+	}
+	if review.Request == nil {
+		return response.Error(http.StatusBadRequest, "Admission review has no request", nil)
+	}
+
+	return response.JSON(http.StatusOK, &admissionv1.AdmissionReview{
+		TypeMeta: review.TypeMeta,
+		Response: handle(c.Req.Context(), review.Request),
+	})
+}
+
+// authorized checks the bearer token of the request. No request is authorized when no token is configured.
+func (s *Service) authorized(req *http.Request) bool {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || s.settings.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.settings.Token)) == 1
+}
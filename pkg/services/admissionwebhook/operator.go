@@ -0,0 +1,80 @@
+package admissionwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+)
+
+// operatorGroup is the API group of the resources of the Grafana Operator.
+const operatorGroup = "grafana.integreatly.org"
+
+// fromOperatorDashboard converts a GrafanaDashboard to the dashboard the operator creates in Grafana.
+// Dashboards whose model is not inline, e.g. loaded from a URL or a config map, are not converted since
+// their model is only known once the operator fetches it.
+func fromOperatorDashboard(src *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	raw, _, _ := unstructured.NestedString(src.Object, "spec", "json")
+	if raw == "" {
+		return nil, nil
+	}
+	model := map[string]any{}
+	if err := json.Unmarshal([]byte(raw), &model); err != nil {
+		return nil, fmt.Errorf("invalid dashboard json: %w", err)
+	}
+
+	// the operator uses the uid of the spec, then the uid of the model
+	uid, _, _ := unstructured.NestedString(src.Object, "spec", "uid")
+	if uid == "" {
+		uid, _ = model["uid"].(string)
+	}
+	if uid == "" {
+		uid = src.GetName()
+	}
+	model["uid"] = uid
+
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": model}}
+	obj.SetAPIVersion(dashboardv0alpha1.APIVERSION)
+	obj.SetKind("Dashboard")
+	obj.SetName(uid)
+	parent, _, _ := unstructured.NestedString(src.Object, "spec", "folderUID")
+	return obj, setFolder(obj, parent)
+}
+
+// fromOperatorFolder converts a GrafanaFolder to the folder the operator creates in Grafana.
+func fromOperatorFolder(src *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	uid, _, _ := unstructured.NestedString(src.Object, "spec", "uid")
+	if uid == "" {
+		uid = src.GetName()
+	}
+	// the operator names the folder after the resource when it has no title
+	title, _, _ := unstructured.NestedString(src.Object, "spec", "title")
+	if title == "" {
+		title = src.GetName()
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"title": title},
+	}}
+	obj.SetAPIVersion(folderv0alpha1.APIVERSION)
+	obj.SetKind("Folder")
+	obj.SetName(uid)
+	parent, _, _ := unstructured.NestedString(src.Object, "spec", "parentFolderUID")
+	return obj, setFolder(obj, parent)
+}
+
+func setFolder(obj *unstructured.Unstructured, uid string) error {
+	if uid == "" {
+		return nil
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return err
+	}
+	meta.SetFolder(uid)
+	return nil
+}
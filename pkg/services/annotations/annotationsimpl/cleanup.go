@@ -2,6 +2,8 @@ package annotationsimpl
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -25,6 +27,14 @@ const (
 	apiAnnotationType       = "alert_id = 0 AND dashboard_id = 0"
 )
 
+// TypeConditions are the SQL conditions on the annotation table of the annotations created by alert rules, made on
+// dashboards, and created with the API without dashboard.
+var TypeConditions = map[string]string{
+	"alert":     alertAnnotationType,
+	"dashboard": dashboardAnnotationType,
+	"api":       apiAnnotationType,
+}
+
 // Run deletes old annotations created by alert rules, API
 // requests and human made in the UI. It subsequently deletes orphaned rows
 // from the annotation_tag table. Cleanup actions are performed in batches
@@ -56,3 +66,16 @@ func (cs *CleanupServiceImpl) Run(ctx context.Context, cfg *setting.Cfg) (int64,
 	}
 	return totalCleanedAnnotations, affected, err
 }
+
+// CleanOrgAnnotations deletes the annotations of an organization that match a condition of TypeConditions and are
+// older than maxAge. It subsequently deletes orphaned rows from the annotation_tag table.
+//
+// Returns the number of annotation and annotation_tag rows deleted.
+func (cs *CleanupServiceImpl) CleanOrgAnnotations(ctx context.Context, orgID int64, condition string, maxAge time.Duration) (int64, int64, error) {
+	affected, err := cs.store.CleanAnnotations(ctx, setting.AnnotationCleanupSettings{MaxAge: maxAge}, fmt.Sprintf("org_id = %d AND %s", orgID, condition))
+	if err != nil || affected == 0 {
+		return affected, 0, err
+	}
+	affectedTags, err := cs.store.CleanOrphanedAnnotationTags(ctx)
+	return affected, affectedTags, err
+}
@@ -249,7 +249,7 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 	var sql bytes.Buffer
 	params := make([]interface{}, 0)
 	items := make([]*annotations.ItemDTO, 0)
-	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+	err := r.db.WithReadOnlyDbSession(ctx, func(sess *db.Session) error {
 		sql.WriteString(`
 			SELECT
 				annotation.id,
@@ -442,7 +442,7 @@ func (r *xormRepositoryImpl) Delete(ctx context.Context, params *annotations.Del
 
 func (r *xormRepositoryImpl) GetTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	var items []*annotations.Tag
-	err := r.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+	err := r.db.WithReadOnlyDbSession(ctx, func(dbSession *db.Session) error {
 		if query.Limit == 0 {
 			query.Limit = 100
 		}
@@ -0,0 +1,33 @@
+package bulk
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	routeRegister.Post("/api/annotations/bulk", middleware.ReqSignedIn, authorize(ac.EvalPermission(ac.ActionAnnotationsCreate)), routing.Wrap(s.ingestHandler))
+}
+
+// ingestHandler saves the annotations of an ndjson body, one annotation per line. It responds with 429 and the
+// line to resume from if the rate limit of the organization is reached.
+func (s *Service) ingestHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.Ingest(c.Req.Context(), c.SignedInUser, c.Req.Body)
+	if err != nil {
+		if errors.Is(err, ErrTooManyLines) || errors.Is(err, ErrInvalidLine) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save annotations", err)
+	}
+	if result.RateLimited {
+		return response.JSON(http.StatusTooManyRequests, result)
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,279 @@
+// Package bulk ingests annotations in bulk from ndjson streams, with a rate limit per organization.
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	maxLineSize = 1024 * 1024
+	maxErrors   = 100
+	batchSize   = 500
+	// maxRateLimitWait is how long a request waits for the rate limit of its organization before it stops.
+	maxRateLimitWait = 5 * time.Second
+)
+
+// Service ingests annotations in bulk.
+type Service struct {
+	repo             annotations.Repository
+	dashboardService dashboards.DashboardService
+	accessControl    ac.AccessControl
+	features         featuremgmt.FeatureToggles
+	settings         setting.AnnotationBulkSettings
+	maxTagsLength    int64
+	log              log.Logger
+
+	mtx      sync.Mutex
+	limiters map[int64]*rate.Limiter
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, repo annotations.Repository,
+	dashboardService dashboards.DashboardService, accessControl ac.AccessControl, routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		repo:             repo,
+		dashboardService: dashboardService,
+		accessControl:    accessControl,
+		features:         features,
+		settings:         cfg.AnnotationBulk,
+		maxTagsLength:    cfg.AnnotationMaximumTagsLength,
+		log:              log.New("annotations.bulk"),
+		limiters:         make(map[int64]*rate.Limiter),
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+type pendingItem struct {
+	line int
+	item annotations.Item
+}
+
+// Ingest saves the annotations of an ndjson stream. The whole stream is read and validated first: invalid annotations
+// are skipped and reported, and the request fails with ErrTooManyLines without saving anything if it has more
+// annotations than allowed. The valid annotations are then saved in batches, within the rate limit of the
+// organization of the user.
+func (s *Service) Ingest(ctx context.Context, user identity.Requester, body io.Reader) (*Result, error) {
+	result := &Result{Errors: make([]LineError, 0)}
+	items, err := s.read(ctx, user, body, result)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := s.limiter(user.GetOrgID())
+	for start := 0; start < len(items); start += s.batchSize() {
+		end := min(start+s.batchSize(), len(items))
+		batch := items[start:end]
+		if limiter != nil {
+			waitCtx, cancel := context.WithTimeout(ctx, maxRateLimitWait)
+			err := limiter.WaitN(waitCtx, len(batch))
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				result.RateLimited = true
+				result.NextLine = batch[0].line
+				break
+			}
+		}
+
+		saved := make([]annotations.Item, 0, len(batch))
+		for _, pending := range batch {
+			saved = append(saved, pending.item)
+		}
+		if err := s.repo.SaveMany(ctx, saved); err != nil {
+			return nil, fmt.Errorf("failed to save annotations of lines %d to %d: %w", batch[0].line, batch[len(batch)-1].line, err)
+		}
+		result.Accepted += len(batch)
+	}
+
+	s.log.Debug("Ingested annotations", "orgId", user.GetOrgID(), "accepted", result.Accepted, "failed", result.Failed, "rateLimited", result.RateLimited)
+	return result, nil
+}
+
+// read reads and validates the annotations of a stream. The errors of the invalid ones are added to the result.
+func (s *Service) read(ctx context.Context, user identity.Requester, body io.Reader, result *Result) ([]pendingItem, error) {
+	userID, _ := identity.UserIdentifier(user.GetID())
+	checker := &permissionChecker{service: s, user: user, dashboardIDs: map[string]int64{}, allowed: map[string]error{}}
+
+	items := make([]pendingItem, 0)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	count := 0
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		count++
+		if count > s.settings.MaxLines {
+			return nil, fmt.Errorf("%w: the maximum is %d", ErrTooManyLines, s.settings.MaxLines)
+		}
+
+		item, err := s.parse(ctx, checker, raw)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidLine) {
+				return nil, err
+			}
+			result.Failed++
+			if len(result.Errors) < maxErrors {
+				result.Errors = append(result.Errors, LineError{Line: lineNumber, Message: err.Error()})
+			}
+			continue
+		}
+		item.OrgID = user.GetOrgID()
+		item.UserID = userID
+		items = append(items, pendingItem{line: lineNumber, item: *item})
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: a line is longer than %d bytes", ErrInvalidLine, maxLineSize)
+		}
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *Service) parse(ctx context.Context, checker *permissionChecker, raw []byte) (*annotations.Item, error) {
+	line := Line{}
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLine, err)
+	}
+	if line.Text == "" {
+		return nil, fmt.Errorf("%w: text field should not be empty", ErrInvalidLine)
+	}
+	if line.TimeEnd != 0 && line.TimeEnd < line.Time {
+		return nil, fmt.Errorf("%w: timeEnd is before time", ErrInvalidLine)
+	}
+	if length := tagsLength(line.Tags); length > s.maxTagsLength {
+		return nil, fmt.Errorf("%w: tags length (%d) exceeds the maximum allowed (%d)", ErrInvalidLine, length, s.maxTagsLength)
+	}
+
+	dashboardID, err := checker.check(ctx, line.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	return &annotations.Item{
+		DashboardID: dashboardID,
+		PanelID:     line.PanelID,
+		Epoch:       line.Time,
+		EpochEnd:    line.TimeEnd,
+		Text:        line.Text,
+		Tags:        line.Tags,
+		Data:        line.Data,
+	}, nil
+}
+
+// limiter returns the rate limiter of an organization, nil if there is no rate limit.
+func (s *Service) limiter(orgID int64) *rate.Limiter {
+	if s.settings.RateLimit == 0 {
+		return nil
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	l, ok := s.limiters[orgID]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(s.settings.RateLimit), s.settings.RateLimitBurst)
+		s.limiters[orgID] = l
+	}
+	return l
+}
+
+// batchSize returns the number of annotations saved at once, which can't be more than the burst of the rate limit.
+func (s *Service) batchSize() int {
+	if s.settings.RateLimit > 0 && s.settings.RateLimitBurst < batchSize {
+		return s.settings.RateLimitBurst
+	}
+	return batchSize
+}
+
+// tagsLength estimates the length of the tags once stored as JSON, like the annotation store.
+func tagsLength(tags []string) int64 {
+	length := 2
+	for i, t := range tags {
+		if i > 0 {
+			length++
+		}
+		length += len(t) + 2
+	}
+	return int64(length)
+}
+
+// permissionChecker checks that a user can create the annotations of the dashboards of a request, once per dashboard.
+type permissionChecker struct {
+	service      *Service
+	user         identity.Requester
+	dashboardIDs map[string]int64
+	allowed      map[string]error
+}
+
+// check returns the ID of a dashboard if the user can create its annotations, 0 for organization annotations.
+func (c *permissionChecker) check(ctx context.Context, dashboardUID string) (int64, error) {
+	if err, ok := c.allowed[dashboardUID]; ok {
+		return c.dashboardIDs[dashboardUID], err
+	}
+	id, err := c.evaluate(ctx, dashboardUID)
+	c.dashboardIDs[dashboardUID] = id
+	c.allowed[dashboardUID] = err
+	return id, err
+}
+
+func (c *permissionChecker) evaluate(ctx context.Context, dashboardUID string) (int64, error) {
+	s := c.service
+	if dashboardUID == "" {
+		ok, err := s.accessControl.Evaluate(ctx, c.user, ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeOrganization))
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, fmt.Errorf("%w: access denied to create organization annotations", ErrInvalidLine)
+		}
+		return 0, nil
+	}
+
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: c.user.GetOrgID(), UID: dashboardUID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return 0, fmt.Errorf("%w: dashboard %q not found", ErrInvalidLine, dashboardUID)
+		}
+		return 0, err
+	}
+	dashboardScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)
+	var evaluator ac.Evaluator
+	if s.features.IsEnabled(ctx, featuremgmt.FlagAnnotationPermissionUpdate) {
+		evaluator = ac.EvalPermission(ac.ActionAnnotationsCreate, dashboardScope)
+	} else {
+		evaluator = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeDashboard),
+			ac.EvalPermission(dashboards.ActionDashboardsWrite, dashboardScope),
+		)
+	}
+	ok, err := s.accessControl.Evaluate(ctx, c.user, evaluator)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: access denied to create annotations of dashboard %q", ErrInvalidLine, dashboardUID)
+	}
+	return dash.ID, nil
+}
@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestService(t *testing.T, settings setting.AnnotationBulkSettings) (*Service, interface{ Len() int }) {
+	t.Helper()
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "dash"
+	})).Return(&dashboards.Dashboard{ID: 7, UID: "dash"}, nil).Maybe()
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+
+	repo := annotationstest.NewFakeAnnotationsRepo()
+	return &Service{
+		repo:             repo,
+		dashboardService: dashboardService,
+		accessControl:    actest.FakeAccessControl{ExpectedEvaluate: true},
+		features:         featuremgmt.WithFeatures(),
+		settings:         settings,
+		maxTagsLength:    500,
+		log:              log.NewNopLogger(),
+		limiters:         map[int64]*rate.Limiter{},
+	}, repo
+}
+
+func TestIngest(t *testing.T) {
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1}
+	body := strings.Join([]string{
+		`{"text": "deploy", "time": 1000, "tags": ["deploy"]}`,
+		``,
+		`{"dashboardUID": "dash", "panelId": 2, "text": "on a panel", "time": 1000, "timeEnd": 2000}`,
+		`{"text": ""}`,
+		`not json`,
+		`{"dashboardUID": "unknown", "text": "missing dashboard"}`,
+		`{"text": "backwards", "time": 2000, "timeEnd": 1000}`,
+	}, "\n")
+
+	t.Run("saves the valid annotations and reports the invalid ones", func(t *testing.T) {
+		s, repo := newTestService(t, setting.AnnotationBulkSettings{MaxLines: 10})
+		result, err := s.Ingest(context.Background(), signedInUser, strings.NewReader(body))
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Accepted)
+		require.Equal(t, 4, result.Failed)
+		lines := make([]int, 0, len(result.Errors))
+		for _, lineErr := range result.Errors {
+			lines = append(lines, lineErr.Line)
+		}
+		require.Equal(t, []int{4, 5, 6, 7}, lines, "blank lines are counted in the line numbers")
+		require.Equal(t, 2, repo.Len())
+	})
+
+	t.Run("rejects requests with too many annotations", func(t *testing.T) {
+		s, repo := newTestService(t, setting.AnnotationBulkSettings{MaxLines: 3})
+		_, err := s.Ingest(context.Background(), signedInUser, strings.NewReader(body))
+		require.ErrorIs(t, err, ErrTooManyLines)
+		require.Zero(t, repo.Len())
+	})
+
+	t.Run("stops when the rate limit of the organization is reached", func(t *testing.T) {
+		s, repo := newTestService(t, setting.AnnotationBulkSettings{MaxLines: 10, RateLimit: 0.1, RateLimitBurst: 2})
+		lines := strings.Repeat(`{"text": "a"}`+"\n", 5)
+		result, err := s.Ingest(context.Background(), signedInUser, strings.NewReader(lines))
+		require.NoError(t, err)
+		require.True(t, result.RateLimited)
+		require.Equal(t, 2, result.Accepted)
+		require.Equal(t, 3, result.NextLine)
+		require.Equal(t, 2, repo.Len())
+	})
+}
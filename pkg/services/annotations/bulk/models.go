@@ -0,0 +1,45 @@
+package bulk
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrTooManyLines = errors.New("too many annotations in the request")
+	ErrInvalidLine  = errors.New("invalid annotation")
+)
+
+// Line is an annotation of a bulk request, a line of its ndjson body.
+type Line struct {
+	// DashboardUID is the dashboard of the annotation, an organization annotation if it is empty.
+	DashboardUID string           `json:"dashboardUID"`
+	PanelID      int64            `json:"panelId"`
+	Time         int64            `json:"time"`
+	TimeEnd      int64            `json:"timeEnd"`
+	Text         string           `json:"text"`
+	Tags         []string         `json:"tags"`
+	Data         *simplejson.Json `json:"data"`
+}
+
+// Result is the result of a bulk request.
+type Result struct {
+	// Accepted is the number of saved annotations.
+	Accepted int `json:"accepted"`
+	// Failed is the number of invalid annotations, which are skipped.
+	Failed int `json:"failed"`
+	// Errors are the errors of the first invalid annotations.
+	Errors []LineError `json:"errors"`
+	// RateLimited is true if the rate limit of the organization was reached before all the annotations were saved.
+	// The request can be resumed from NextLine.
+	RateLimited bool `json:"rateLimited,omitempty"`
+	NextLine    int  `json:"nextLine,omitempty"`
+}
+
+// LineError is the error of an invalid annotation of a bulk request.
+type LineError struct {
+	// Line is the line number of the annotation, starting at 1.
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
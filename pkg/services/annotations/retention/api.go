@@ -0,0 +1,99 @@
+package retention
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/annotations/retention", func(retentionRoute routing.RouteRegister) {
+		retentionRoute.Get("/policies", routing.Wrap(s.listHandler))
+		retentionRoute.Post("/policies", routing.Wrap(s.createHandler))
+		retentionRoute.Get("/policies/:uid", routing.Wrap(s.getHandler))
+		retentionRoute.Put("/policies/:uid", routing.Wrap(s.updateHandler))
+		retentionRoute.Delete("/policies/:uid", routing.Wrap(s.deleteHandler))
+		retentionRoute.Get("/report", routing.Wrap(s.reportHandler))
+		retentionRoute.Post("/apply", routing.Wrap(s.applyHandler))
+	}, middleware.ReqOrgAdmin)
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	policies, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return errorResponse(err, "Failed to list annotation retention policies")
+	}
+	return response.JSON(http.StatusOK, policies)
+}
+
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to get annotation retention policy")
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := SavePolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	policy, err := s.Create(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to create annotation retention policy")
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := SavePolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	policy, err := s.Update(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to update annotation retention policy")
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err, "Failed to delete annotation retention policy")
+	}
+	return response.Success("Annotation retention policy deleted")
+}
+
+// reportHandler returns what the enabled policies of the organization delete, without deleting anything.
+func (s *Service) reportHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.Report(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return errorResponse(err, "Failed to report annotation retention")
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+func (s *Service) applyHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.Apply(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return errorResponse(err, "Failed to apply annotation retention policies")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrPolicyNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrInvalidPolicy):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	default:
+		return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+	}
+}
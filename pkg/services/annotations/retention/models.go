@@ -0,0 +1,66 @@
+package retention
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPolicyNotFound = errors.New("annotation retention policy not found")
+	ErrInvalidPolicy  = errors.New("invalid annotation retention policy")
+)
+
+// minMaxAge is the minimum age of the annotations deleted by a policy.
+const minMaxAge = time.Hour
+
+// Policy deletes the annotations of a type of an organization once they are older than its maximum age.
+type Policy struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID   string `xorm:"uid" json:"uid"`
+	OrgID int64  `xorm:"org_id" json:"-"`
+	Name  string `xorm:"name" json:"name"`
+	// AnnotationType is alert for the annotations of alert rules, dashboard for the annotations made on dashboards,
+	// and api for the annotations created with the API without dashboard.
+	AnnotationType string `xorm:"annotation_type" json:"type"`
+	// MaxAge is a duration such as 12h, 30d or 1y.
+	MaxAge  string `xorm:"max_age" json:"maxAge"`
+	Enabled bool   `xorm:"enabled" json:"enabled"`
+	Created int64  `xorm:"created" json:"created"`
+	Updated int64  `xorm:"updated" json:"updated"`
+}
+
+func (Policy) TableName() string {
+	return "annotation_retention_policy"
+}
+
+// SavePolicyCommand creates or updates a retention policy.
+type SavePolicyCommand struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	MaxAge  string `json:"maxAge"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Report is the result of a dry run of the enabled retention policies of an organization.
+type Report struct {
+	Policies []*PolicyReport `json:"policies"`
+}
+
+// PolicyReport is the result of a dry run of a retention policy.
+type PolicyReport struct {
+	Policy *Policy `json:"policy"`
+	// Cutoff is the creation time before which the annotations are deleted.
+	Cutoff time.Time `json:"cutoff"`
+	// Count is the number of annotations the policy deletes.
+	Count int64 `json:"count"`
+	// Oldest is the creation time of the oldest annotation the policy deletes.
+	Oldest *time.Time `json:"oldest,omitempty"`
+}
+
+// ApplyResult is the result of the application of the enabled retention policies of an organization.
+type ApplyResult struct {
+	// Deleted is the number of annotations deleted by policy UID.
+	Deleted map[string]int64 `json:"deleted"`
+	// DeletedTags is the number of orphaned annotation tags deleted.
+	DeletedTags int64 `json:"deletedTags"`
+}
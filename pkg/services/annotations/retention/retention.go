@@ -0,0 +1,221 @@
+// Package retention deletes the annotations of the organizations with retention policies by annotation type and
+// age. The policies are applied in the background, or on demand after a dry run report of what they delete. They
+// come in addition to the instance-wide cleanup of the [annotations.*] settings.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationsimpl"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Service manages and applies the annotation retention policies.
+type Service struct {
+	settings   setting.AnnotationRetentionSettings
+	store      db.DB
+	cleaner    *annotationsimpl.CleanupServiceImpl
+	serverLock *serverlock.ServerLockService
+	log        log.Logger
+	now        func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, cleaner *annotationsimpl.CleanupServiceImpl,
+	serverLock *serverlock.ServerLockService, routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		settings:   cfg.AnnotationRetention,
+		store:      sqlStore,
+		cleaner:    cleaner,
+		serverLock: serverLock,
+		log:        log.New("annotations.retention"),
+		now:        time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// IsDisabled returns true if the policies are not applied in the background. They can still be applied on demand.
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled
+}
+
+// Run applies the enabled policies of every organization every interval until ctx is done. Only one Grafana
+// instance applies them at a time.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.Interval)
+	defer ticker.Stop()
+	for {
+		err := s.serverLock.LockAndExecute(ctx, "apply annotation retention policies", s.settings.Interval/2, func(ctx context.Context) {
+			if _, err := s.Apply(ctx, 0); err != nil {
+				s.log.Error("Failed to apply annotation retention policies", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to apply annotation retention policies", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// List returns the retention policies of an organization.
+func (s *Service) List(ctx context.Context, orgID int64) ([]*Policy, error) {
+	return s.list(ctx, orgID, false)
+}
+
+// Get returns a retention policy of an organization.
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*Policy, error) {
+	policy, err := s.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, ErrPolicyNotFound
+	}
+	return policy, nil
+}
+
+// Create creates a retention policy for an organization.
+func (s *Service) Create(ctx context.Context, orgID int64, cmd SavePolicyCommand) (*Policy, error) {
+	if err := validate(cmd); err != nil {
+		return nil, err
+	}
+	now := s.now().Unix()
+	policy := &Policy{
+		UID:            util.GenerateShortUID(),
+		OrgID:          orgID,
+		Name:           cmd.Name,
+		AnnotationType: cmd.Type,
+		MaxAge:         cmd.MaxAge,
+		Enabled:        cmd.Enabled,
+		Created:        now,
+		Updated:        now,
+	}
+	if err := s.insert(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Update updates a retention policy of an organization.
+func (s *Service) Update(ctx context.Context, orgID int64, uid string, cmd SavePolicyCommand) (*Policy, error) {
+	if err := validate(cmd); err != nil {
+		return nil, err
+	}
+	policy, err := s.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	policy.Name = cmd.Name
+	policy.AnnotationType = cmd.Type
+	policy.MaxAge = cmd.MaxAge
+	policy.Enabled = cmd.Enabled
+	policy.Updated = s.now().Unix()
+	if err := s.update(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Delete deletes a retention policy of an organization.
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	deleted, err := s.delete(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+// Report returns the annotations the enabled policies of an organization would delete, without deleting them.
+func (s *Service) Report(ctx context.Context, orgID int64) (*Report, error) {
+	policies, err := s.list(ctx, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Policies: make([]*PolicyReport, 0, len(policies))}
+	for _, policy := range policies {
+		maxAge, err := gtime.ParseDuration(policy.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q has an invalid max age: %w", policy.UID, err)
+		}
+		cutoff := s.now().Add(-maxAge)
+		count, oldest, err := s.countAnnotations(ctx, orgID, annotationsimpl.TypeConditions[policy.AnnotationType], cutoff)
+		if err != nil {
+			return nil, err
+		}
+		policyReport := &PolicyReport{Policy: policy, Cutoff: cutoff.UTC(), Count: count}
+		if count > 0 {
+			oldestTime := time.UnixMilli(oldest).UTC()
+			policyReport.Oldest = &oldestTime
+		}
+		report.Policies = append(report.Policies, policyReport)
+	}
+	return report, nil
+}
+
+// Apply deletes the annotations of the enabled policies of an organization, of every organization if orgID is 0.
+func (s *Service) Apply(ctx context.Context, orgID int64) (*ApplyResult, error) {
+	policies, err := s.list(ctx, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &ApplyResult{Deleted: make(map[string]int64, len(policies))}
+	for _, policy := range policies {
+		maxAge, err := gtime.ParseDuration(policy.MaxAge)
+		if err != nil {
+			s.log.Warn("Skipping annotation retention policy with an invalid max age", "orgId", policy.OrgID, "uid", policy.UID, "maxAge", policy.MaxAge)
+			continue
+		}
+		deleted, deletedTags, err := s.cleaner.CleanOrgAnnotations(ctx, policy.OrgID, annotationsimpl.TypeConditions[policy.AnnotationType], maxAge)
+		result.Deleted[policy.UID] += deleted
+		result.DeletedTags += deletedTags
+		if err != nil {
+			return result, fmt.Errorf("failed to apply policy %q: %w", policy.UID, err)
+		}
+		if deleted > 0 {
+			s.log.Info("Applied annotation retention policy", "orgId", policy.OrgID, "uid", policy.UID, "type", policy.AnnotationType, "maxAge", policy.MaxAge, "deleted", deleted)
+		}
+	}
+	return result, nil
+}
+
+func validate(cmd SavePolicyCommand) error {
+	if strings.TrimSpace(cmd.Name) == "" {
+		return fmt.Errorf("%w: the name is required", ErrInvalidPolicy)
+	}
+	if _, ok := annotationsimpl.TypeConditions[cmd.Type]; !ok {
+		types := make([]string, 0, len(annotationsimpl.TypeConditions))
+		for t := range annotationsimpl.TypeConditions {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return fmt.Errorf("%w: the type must be one of %s", ErrInvalidPolicy, strings.Join(types, ", "))
+	}
+	maxAge, err := gtime.ParseDuration(cmd.MaxAge)
+	if err != nil {
+		return fmt.Errorf("%w: invalid max age %q", ErrInvalidPolicy, cmd.MaxAge)
+	}
+	if maxAge < minMaxAge {
+		return fmt.Errorf("%w: the max age can't be less than %s", ErrInvalidPolicy, minMaxAge)
+	}
+	return nil
+}
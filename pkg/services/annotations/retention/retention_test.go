@@ -0,0 +1,95 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationsimpl"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]SavePolicyCommand{
+		"no name":         {Type: "api", MaxAge: "30d"},
+		"unknown type":    {Name: "a", Type: "other", MaxAge: "30d"},
+		"invalid max age": {Name: "a", Type: "api", MaxAge: "forever"},
+		"max age too low": {Name: "a", Type: "api", MaxAge: "10m"},
+	}
+	for name, cmd := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, validate(cmd), ErrInvalidPolicy)
+		})
+	}
+	require.NoError(t, validate(SavePolicyCommand{Name: "a", Type: "dashboard", MaxAge: "2w"}))
+}
+
+func TestIntegrationRetention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	cfg.AnnotationCleanupJobBatchSize = 10
+	now := time.Now()
+	s := &Service{
+		store:   sqlStore,
+		cleaner: annotationsimpl.ProvideCleanupService(sqlStore, cfg),
+		log:     log.NewNopLogger(),
+		now:     func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	old := now.Add(-48 * time.Hour).UnixMilli()
+	items := []annotations.Item{
+		{OrgID: 1, Text: "old api", Created: old, Epoch: old},
+		{OrgID: 1, DashboardID: 1, Text: "old dashboard", Created: old, Epoch: old},
+		{OrgID: 1, Text: "recent api", Created: now.UnixMilli(), Epoch: now.UnixMilli()},
+		{OrgID: 2, Text: "old api of another org", Created: old, Epoch: old},
+	}
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for i := range items {
+			if _, err := sess.Table("annotation").Insert(&items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	policy, err := s.Create(ctx, 1, SavePolicyCommand{Name: "API annotations", Type: "api", MaxAge: "1d", Enabled: true})
+	require.NoError(t, err)
+	_, err = s.Create(ctx, 1, SavePolicyCommand{Name: "Disabled", Type: "dashboard", MaxAge: "1h"})
+	require.NoError(t, err)
+
+	report, err := s.Report(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, report.Policies, 1, "disabled policies are not reported")
+	require.Equal(t, int64(1), report.Policies[0].Count)
+	require.Equal(t, old, report.Policies[0].Oldest.UnixMilli())
+
+	result, err := s.Apply(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{policy.UID: 1}, result.Deleted)
+
+	var texts []string
+	err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("annotation").Cols("text").Asc("id").Find(&texts)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"old dashboard", "recent api", "old api of another org"}, texts)
+
+	require.ErrorIs(t, s.Delete(ctx, 1, "unknown"), ErrPolicyNotFound)
+	require.NoError(t, s.Delete(ctx, 1, policy.UID))
+	_, err = s.Get(ctx, 1, policy.UID)
+	require.ErrorIs(t, err, ErrPolicyNotFound)
+}
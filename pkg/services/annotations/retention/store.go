@@ -0,0 +1,78 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) insert(ctx context.Context, policy *Policy) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(policy)
+		return err
+	})
+}
+
+func (s *Service) update(ctx context.Context, policy *Policy) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(policy.ID).Cols("name", "annotation_type", "max_age", "enabled", "updated").Update(policy)
+		return err
+	})
+}
+
+func (s *Service) delete(ctx context.Context, orgID int64, uid string) (bool, error) {
+	var deleted bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Policy{})
+		deleted = affected > 0
+		return err
+	})
+	return deleted, err
+}
+
+// get returns a policy of an organization, nil if there is none.
+func (s *Service) get(ctx context.Context, orgID int64, uid string) (*Policy, error) {
+	policy := &Policy{}
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(policy)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// list returns the policies of an organization ordered by name, of every organization if orgID is 0.
+func (s *Service) list(ctx context.Context, orgID int64, enabledOnly bool) ([]*Policy, error) {
+	policies := make([]*Policy, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if orgID != 0 {
+			sess.Where("org_id = ?", orgID)
+		}
+		if enabledOnly {
+			sess.And("enabled = ?", s.store.GetDialect().BooleanStr(true))
+		}
+		return sess.Asc("org_id", "name").Find(&policies)
+	})
+	return policies, err
+}
+
+// countAnnotations returns the number of annotations of an organization matching a condition that were created
+// before a cutoff, and the creation time in milliseconds of the oldest.
+func (s *Service) countAnnotations(ctx context.Context, orgID int64, condition string, cutoff time.Time) (int64, int64, error) {
+	var result struct {
+		Total  int64 `xorm:"total"`
+		Oldest int64 `xorm:"oldest"`
+	}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := fmt.Sprintf("SELECT COUNT(*) AS total, COALESCE(MIN(created), 0) AS oldest FROM annotation WHERE org_id = ? AND %s AND created < ?", condition)
+		_, err := sess.SQL(sql, orgID, cutoff.UnixMilli()).Get(&result)
+		return err
+	})
+	return result.Total, result.Oldest, err
+}
@@ -22,10 +22,13 @@ type AnonDBStore struct {
 	sqlStore    db.DB
 	log         log.Logger
 	deviceLimit int64
+	// limitWindow is how long after their last request the devices count against the device limit.
+	limitWindow time.Duration
 }
 
 type Device struct {
 	ID        int64     `json:"-" xorm:"id" db:"id"`
+	OrgID     int64     `json:"orgId" xorm:"org_id" db:"org_id"`
 	DeviceID  string    `json:"deviceId" xorm:"device_id" db:"device_id"`
 	ClientIP  string    `json:"clientIp" xorm:"client_ip" db:"client_ip"`
 	UserAgent string    `json:"userAgent" xorm:"user_agent" db:"user_agent"`
@@ -34,6 +37,7 @@ type Device struct {
 }
 
 type DeviceSearchHitDTO struct {
+	OrgID      int64     `json:"orgId" xorm:"org_id" db:"org_id"`
 	DeviceID   string    `json:"deviceId" xorm:"device_id" db:"device_id"`
 	ClientIP   string    `json:"clientIp" xorm:"client_ip" db:"client_ip"`
 	UserAgent  string    `json:"userAgent" xorm:"user_agent" db:"user_agent"`
@@ -57,6 +61,15 @@ type SearchDeviceQuery struct {
 	SortOpts []model.SortOption
 }
 
+// OrgDeviceStats are the numbers of anonymous devices of an organization.
+type OrgDeviceStats struct {
+	OrgID int64 `json:"orgId" xorm:"org_id"`
+	// ActiveDevices are the devices which count against the device limit.
+	ActiveDevices int64 `json:"activeDevices" xorm:"-"`
+	// Devices are the devices seen in the last 30 days.
+	Devices int64 `json:"devices" xorm:"devices"`
+}
+
 func (a *Device) CacheKey() string {
 	return strings.Join([]string{cacheKeyPrefix, a.DeviceID}, ":")
 }
@@ -74,10 +87,20 @@ type AnonStore interface {
 	DeleteDevicesOlderThan(ctx context.Context, olderThan time.Time) error
 	// SearchDevices searches for devices within the 30 days active.
 	SearchDevices(ctx context.Context, query *SearchDeviceQuery) (*SearchDeviceQueryResult, error)
+	// GetDeviceStats returns the numbers of devices of the organizations which have devices.
+	GetDeviceStats(ctx context.Context) ([]*OrgDeviceStats, error)
 }
 
 func ProvideAnonDBStore(sqlStore db.DB, deviceLimit int64) *AnonDBStore {
-	return &AnonDBStore{sqlStore: sqlStore, log: log.New("anonstore"), deviceLimit: deviceLimit}
+	return NewAnonDBStore(sqlStore, deviceLimit, anonymousDeviceExpiration)
+}
+
+// NewAnonDBStore returns a store limiting the number of devices of each organization seen within the limit window.
+func NewAnonDBStore(sqlStore db.DB, deviceLimit int64, limitWindow time.Duration) *AnonDBStore {
+	if limitWindow <= 0 {
+		limitWindow = anonymousDeviceExpiration
+	}
+	return &AnonDBStore{sqlStore: sqlStore, log: log.New("anonstore"), deviceLimit: deviceLimit, limitWindow: limitWindow}
 }
 
 func (s *AnonDBStore) ListDevices(ctx context.Context, from *time.Time, to *time.Time) ([]*Device, error) {
@@ -96,16 +119,16 @@ func (s *AnonDBStore) ListDevices(ctx context.Context, from *time.Time, to *time
 	return devices, err
 }
 
-// updateDevice updates a device if it exists and has been updated between the given times.
+// updateDevice updates a device if it exists in the organization and is active, i.e. has been updated within the limit window.
 func (s *AnonDBStore) updateDevice(ctx context.Context, device *Device) error {
 	const query = `UPDATE anon_device SET
 client_ip = ?,
 user_agent = ?,
 updated_at = ?
-WHERE device_id = ? AND updated_at BETWEEN ? AND ?`
+WHERE device_id = ? AND org_id = ? AND updated_at BETWEEN ? AND ?`
 
-	args := []interface{}{device.ClientIP, device.UserAgent, device.UpdatedAt.UTC(), device.DeviceID,
-		device.UpdatedAt.UTC().Add(-anonymousDeviceExpiration), device.UpdatedAt.UTC().Add(time.Minute),
+	args := []interface{}{device.ClientIP, device.UserAgent, device.UpdatedAt.UTC(), device.DeviceID, device.OrgID,
+		device.UpdatedAt.UTC().Add(-s.limitWindow), device.UpdatedAt.UTC().Add(time.Minute),
 	}
 	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		args = append([]interface{}{query}, args...)
@@ -132,9 +155,9 @@ WHERE device_id = ? AND updated_at BETWEEN ? AND ?`
 func (s *AnonDBStore) CreateOrUpdateDevice(ctx context.Context, device *Device) error {
 	var query string
 
-	// if device limit is reached, only update devices
+	// if the device limit of the organization is reached, only update its active devices
 	if s.deviceLimit > 0 {
-		count, err := s.CountDevices(ctx, time.Now().UTC().Add(-anonymousDeviceExpiration), time.Now().UTC().Add(time.Minute))
+		count, err := s.countOrgDevices(ctx, device.OrgID, time.Now().UTC().Add(-s.limitWindow), time.Now().UTC().Add(time.Minute))
 		if err != nil {
 			return err
 		}
@@ -145,30 +168,33 @@ func (s *AnonDBStore) CreateOrUpdateDevice(ctx context.Context, device *Device)
 	}
 
 	args := []any{device.DeviceID, device.ClientIP, device.UserAgent,
-		device.CreatedAt.UTC(), device.UpdatedAt.UTC()}
+		device.CreatedAt.UTC(), device.UpdatedAt.UTC(), device.OrgID}
 	switch s.sqlStore.GetDBType() {
 	case migrator.Postgres:
-		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5)
+		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at, org_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 ON CONFLICT (device_id) DO UPDATE SET
 client_ip = $2,
 user_agent = $3,
-updated_at = $5
+updated_at = $5,
+org_id = $6
 RETURNING id`
 	case migrator.MySQL:
-		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?)
+		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at, org_id)
+VALUES (?, ?, ?, ?, ?, ?)
 ON DUPLICATE KEY UPDATE
 client_ip = VALUES(client_ip),
 user_agent = VALUES(user_agent),
-updated_at = VALUES(updated_at)`
+updated_at = VALUES(updated_at),
+org_id = VALUES(org_id)`
 	case migrator.SQLite:
-		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?)
+		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at, org_id)
+VALUES (?, ?, ?, ?, ?, ?)
 ON CONFLICT (device_id) DO UPDATE SET
 client_ip = excluded.client_ip,
 user_agent = excluded.user_agent,
-updated_at = excluded.updated_at`
+updated_at = excluded.updated_at,
+org_id = excluded.org_id`
 	default:
 		return fmt.Errorf("unsupported database driver: %s", s.sqlStore.GetDBType())
 	}
@@ -192,6 +218,43 @@ func (s *AnonDBStore) CountDevices(ctx context.Context, from time.Time, to time.
 	return count, err
 }
 
+func (s *AnonDBStore) countOrgDevices(ctx context.Context, orgID int64, from time.Time, to time.Time) (int64, error) {
+	var count int64
+	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.SQL("SELECT COUNT(*) FROM anon_device WHERE org_id = ? AND updated_at BETWEEN ? AND ?", orgID, from.UTC(), to.UTC()).Get(&count)
+		return err
+	})
+
+	return count, err
+}
+
+func (s *AnonDBStore) GetDeviceStats(ctx context.Context) ([]*OrgDeviceStats, error) {
+	now := time.Now().UTC()
+	stats := []*OrgDeviceStats{}
+	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if err := dbSession.SQL("SELECT org_id, COUNT(*) AS devices FROM anon_device WHERE updated_at BETWEEN ? AND ? GROUP BY org_id ORDER BY org_id",
+			now.Add(-anonymousDeviceExpiration), now.Add(time.Minute)).Find(&stats); err != nil {
+			return err
+		}
+
+		var active []*OrgDeviceStats
+		if err := dbSession.SQL("SELECT org_id, COUNT(*) AS devices FROM anon_device WHERE updated_at BETWEEN ? AND ? GROUP BY org_id",
+			now.Add(-s.limitWindow), now.Add(time.Minute)).Find(&active); err != nil {
+			return err
+		}
+		activeByOrg := make(map[int64]int64, len(active))
+		for _, a := range active {
+			activeByOrg[a.OrgID] = a.Devices
+		}
+		for _, st := range stats {
+			st.ActiveDevices = activeByOrg[st.OrgID]
+		}
+		return nil
+	})
+
+	return stats, err
+}
+
 func (s *AnonDBStore) DeleteDevice(ctx context.Context, deviceID string) error {
 	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		_, err := dbSession.Exec("DELETE FROM anon_device WHERE device_id = ?", deviceID)
@@ -235,7 +298,7 @@ func (s *AnonDBStore) SearchDevices(ctx context.Context, query *SearchDeviceQuer
 			offset := query.Limit * (query.Page - 1)
 			sess.Limit(query.Limit, offset)
 		}
-		sess.Cols("d.id", "d.device_id", "d.client_ip", "d.user_agent", "d.updated_at")
+		sess.Cols("d.id", "d.org_id", "d.device_id", "d.client_ip", "d.user_agent", "d.updated_at")
 
 		if len(query.SortOpts) > 0 {
 			for i := range query.SortOpts {
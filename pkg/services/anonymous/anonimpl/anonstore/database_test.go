@@ -103,3 +103,28 @@ func TestIntegrationAnonStore_DeleteDevice(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 0, len(devices))
 }
+
+func TestIntegrationDeviceLimitPerOrg(t *testing.T) {
+	store := db.InitTestDB(t)
+	anonDBStore := NewAnonDBStore(store, 1, time.Hour)
+	ctx := context.Background()
+
+	device := func(orgID int64, deviceID string, updatedAt time.Time) *Device {
+		return &Device{OrgID: orgID, DeviceID: deviceID, ClientIP: "10.30.30.2", UserAgent: "test", CreatedAt: updatedAt, UpdatedAt: updatedAt}
+	}
+
+	require.NoError(t, anonDBStore.CreateOrUpdateDevice(ctx, device(1, "a", time.Now().Add(-2*time.Hour))))
+	require.NoError(t, anonDBStore.CreateOrUpdateDevice(ctx, device(1, "b", time.Now())),
+		"the devices which are no longer active don't count against the limit")
+	require.ErrorIs(t, anonDBStore.CreateOrUpdateDevice(ctx, device(1, "c", time.Now())), ErrDeviceLimitReached)
+	require.ErrorIs(t, anonDBStore.CreateOrUpdateDevice(ctx, device(1, "a", time.Now())), ErrDeviceLimitReached)
+	require.NoError(t, anonDBStore.CreateOrUpdateDevice(ctx, device(1, "b", time.Now())), "the active devices are updated")
+	require.NoError(t, anonDBStore.CreateOrUpdateDevice(ctx, device(2, "d", time.Now())), "the other orgs have their own limit")
+
+	stats, err := anonDBStore.GetDeviceStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*OrgDeviceStats{
+		{OrgID: 1, ActiveDevices: 1, Devices: 2},
+		{OrgID: 2, ActiveDevices: 1, Devices: 1},
+	}, stats)
+}
@@ -23,3 +23,7 @@ func (s *FakeAnonStore) CountDevices(ctx context.Context, from time.Time, to tim
 func (s *FakeAnonStore) SearchDevices(ctx context.Context, query SearchDeviceQuery) (*SearchDeviceQueryResult, error) {
 	return nil, nil
 }
+
+func (s *FakeAnonStore) GetDeviceStats(ctx context.Context) ([]*OrgDeviceStats, error) {
+	return nil, nil
+}
@@ -52,6 +52,7 @@ func (api *AnonDeviceServiceAPI) RegisterAPIEndpoints() {
 	api.RouterRegister.Group("/api/anonymous", func(anonRoutes routing.RouteRegister) {
 		anonRoutes.Get("/devices", auth(accesscontrol.EvalPermission(accesscontrol.ActionUsersRead)), routing.Wrap(api.ListDevices))
 		anonRoutes.Get("/search", auth(accesscontrol.EvalPermission(accesscontrol.ActionUsersRead)), routing.Wrap(api.SearchDevices))
+		anonRoutes.Get("/stats", auth(accesscontrol.EvalPermission(accesscontrol.ActionUsersRead)), routing.Wrap(api.GetDeviceStats))
 	})
 }
 
@@ -137,6 +138,46 @@ func (api *AnonDeviceServiceAPI) SearchDevices(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, results)
 }
 
+// swagger:route GET /anonymous/stats devices getDeviceStats
+//
+// # Returns the numbers of anonymous devices of the organizations and the device limit
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: deviceStatsResponse
+//	401: unauthorisedError
+//	403: forbiddenError
+//	500: internalServerError
+func (api *AnonDeviceServiceAPI) GetDeviceStats(c *contextmodel.ReqContext) response.Response {
+	stats, err := api.store.GetDeviceStats(c.Req.Context())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get device stats", err)
+	}
+
+	return response.JSON(http.StatusOK, deviceStatsDTO{
+		DeviceLimit:       api.cfg.AnonymousDeviceLimit,
+		DeviceLimitWindow: api.cfg.AnonymousDeviceLimitWindow.String(),
+		Orgs:              stats,
+	})
+}
+
+type deviceStatsDTO struct {
+	// DeviceLimit is the number of active devices each organization can have, 0 when unlimited.
+	DeviceLimit int64 `json:"deviceLimit"`
+	// DeviceLimitWindow is how long after their last request the devices are active.
+	DeviceLimitWindow string                      `json:"deviceLimitWindow"`
+	Orgs              []*anonstore.OrgDeviceStats `json:"orgs"`
+}
+
+// swagger:response deviceStatsResponse
+type DeviceStatsResponse struct {
+	// in:body
+	Body deviceStatsDTO `json:"body"`
+}
+
 // swagger:response devicesResponse
 type DevicesResponse struct {
 	// in:body
@@ -2,7 +2,10 @@ package anonimpl
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -26,6 +29,9 @@ const thirtyDays = 30 * 24 * time.Hour
 const deviceIDHeader = "X-Grafana-Device-Id"
 const keepFor = time.Hour * 24 * 61
 
+// fingerprintPrefix marks the devices identified by a fingerprint of their requests, which don't send a device ID.
+const fingerprintPrefix = "fp-"
+
 type AnonDeviceService struct {
 	log        log.Logger
 	localCache *localcache.CacheService
@@ -38,10 +44,17 @@ func ProvideAnonymousDeviceService(usageStats usagestats.Service, authBroker aut
 	sqlStore db.DB, cfg *setting.Cfg, orgService org.Service,
 	serverLockService *serverlock.ServerLockService, accesscontrol accesscontrol.AccessControl, routeRegister routing.RouteRegister,
 ) *AnonDeviceService {
+	// The devices tagged recently are not updated again until they leave the cache, which must not outlive
+	// the limit window, otherwise the active devices would not be counted against the device limit.
+	cacheTTL := 29 * time.Minute
+	if window := cfg.AnonymousDeviceLimitWindow; window > 0 && window/2 < cacheTTL {
+		cacheTTL = window / 2
+	}
+
 	a := &AnonDeviceService{
 		log:        log.New("anonymous-session-service"),
-		localCache: localcache.New(29*time.Minute, 15*time.Minute),
-		anonStore:  anonstore.ProvideAnonDBStore(sqlStore, cfg.AnonymousDeviceLimit),
+		localCache: localcache.New(cacheTTL, 15*time.Minute),
+		anonStore:  anonstore.NewAnonDBStore(sqlStore, cfg.AnonymousDeviceLimit, cfg.AnonymousDeviceLimitWindow),
 		serverLock: serverLockService,
 		cfg:        cfg,
 	}
@@ -116,12 +129,7 @@ func (a *AnonDeviceService) untagDevice(ctx context.Context,
 	}
 }
 
-func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind) error {
-	deviceID := httpReq.Header.Get(deviceIDHeader)
-	if deviceID == "" {
-		return nil
-	}
-
+func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind, orgID int64) error {
 	addr := web.RemoteAddr(httpReq)
 	ip, err := network.GetIPFromAddress(addr)
 	if err != nil {
@@ -134,7 +142,16 @@ func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request
 		clientIPStr = ""
 	}
 
+	deviceID := httpReq.Header.Get(deviceIDHeader)
+	if deviceID == "" {
+		deviceID = fingerprint(httpReq, clientIPStr)
+	}
+	if deviceID == "" {
+		return nil
+	}
+
 	taggedDevice := &anonstore.Device{
+		OrgID:     orgID,
 		DeviceID:  deviceID,
 		ClientIP:  clientIPStr,
 		UserAgent: httpReq.UserAgent(),
@@ -151,6 +168,23 @@ func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request
 	return nil
 }
 
+// fingerprint identifies the devices which don't send a device ID, e.g. the API clients and the embedded
+// dashboards, by their address and the headers of their browser. It returns an empty string when the
+// request doesn't have enough of them to tell the devices apart.
+func fingerprint(httpReq *http.Request, clientIP string) string {
+	userAgent := httpReq.UserAgent()
+	if clientIP == "" || userAgent == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		clientIP,
+		userAgent,
+		httpReq.Header.Get("Accept-Language"),
+	}, "\n")))
+	return fingerprintPrefix + hex.EncodeToString(hash[:16])
+}
+
 // ListDevices returns all devices that have been updated between the given times.
 func (a *AnonDeviceService) ListDevices(ctx context.Context, from *time.Time, to *time.Time) ([]*anonstore.Device, error) {
 	if !a.cfg.AnonymousEnabled {
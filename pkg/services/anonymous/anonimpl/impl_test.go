@@ -3,6 +3,7 @@ package anonimpl
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,10 +67,31 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 			expectedAnonUICount: 1,
 			expectedKey:         "ui-anon-session:32mdo31deeqwes",
 			expectedDevice: &anonstore.Device{
+				OrgID:     1,
 				DeviceID:  "32mdo31deeqwes",
 				ClientIP:  "10.30.30.1",
 				UserAgent: "test"},
 		},
+		{
+			name: "should tag the fingerprint of the devices without device ID",
+			req: []tagReq{{httpReq: &http.Request{
+				Header: http.Header{
+					"User-Agent":      []string{"test"},
+					"X-Forwarded-For": []string{"10.30.30.1"},
+				},
+			},
+				kind: anonymous.AnonDeviceUI,
+			}, {httpReq: &http.Request{
+				Header: http.Header{
+					"User-Agent":      []string{"test"},
+					"X-Forwarded-For": []string{"10.30.30.2"},
+				},
+			},
+				kind: anonymous.AnonDeviceUI,
+			},
+			},
+			expectedAnonUICount: 2,
+		},
 		{
 			name: "repeat request should not tag",
 			req: []tagReq{{httpReq: &http.Request{
@@ -122,7 +144,7 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 				&authntest.FakeService{}, store, setting.NewCfg(), orgtest.NewOrgServiceFake(), nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{})
 
 			for _, req := range tc.req {
-				err := anonService.TagDevice(context.Background(), req.httpReq, req.kind)
+				err := anonService.TagDevice(context.Background(), req.httpReq, req.kind, 1)
 				require.NoError(t, err)
 			}
 
@@ -174,7 +196,7 @@ func TestIntegrationAnonDeviceService_localCacheSafety(t *testing.T) {
 	key := anonDevice.CacheKey()
 	anonService.localCache.SetDefault(key, true)
 
-	err := anonService.TagDevice(context.Background(), req, anonymous.AnonDeviceUI)
+	err := anonService.TagDevice(context.Background(), req, anonymous.AnonDeviceUI, 1)
 	require.NoError(t, err)
 
 	stats, err := anonService.usageStatFn(context.Background())
@@ -271,3 +293,18 @@ func TestIntegrationDeviceService_SearchDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestFingerprint(t *testing.T) {
+	req := func(userAgent, language string) *http.Request {
+		return &http.Request{Header: http.Header{"User-Agent": []string{userAgent}, "Accept-Language": []string{language}}}
+	}
+
+	fp := fingerprint(req("test", "en-US"), "10.30.30.1")
+	assert.True(t, strings.HasPrefix(fp, fingerprintPrefix))
+	assert.Equal(t, fp, fingerprint(req("test", "en-US"), "10.30.30.1"))
+	assert.NotEqual(t, fp, fingerprint(req("test", "fr-FR"), "10.30.30.1"))
+	assert.NotEqual(t, fp, fingerprint(req("test", "en-US"), "10.30.30.2"))
+
+	assert.Empty(t, fingerprint(req("", "en-US"), "10.30.30.1"))
+	assert.Empty(t, fingerprint(req("test", "en-US"), ""))
+}
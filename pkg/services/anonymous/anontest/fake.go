@@ -19,7 +19,7 @@ func NewFakeService() *FakeService {
 	return &FakeService{}
 }
 
-func (f *FakeService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind) error {
+func (f *FakeService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind, orgID int64) error {
 	return f.ExpectedError
 }
 
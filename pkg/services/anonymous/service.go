@@ -15,7 +15,8 @@ const (
 )
 
 type Service interface {
-	TagDevice(context.Context, *http.Request, DeviceKind) error
+	// TagDevice records the device of the request as an anonymous device of the organization.
+	TagDevice(ctx context.Context, httpReq *http.Request, kind DeviceKind, orgID int64) error
 	CountDevices(ctx context.Context, from time.Time, to time.Time) (int64, error)
 	ListDevices(ctx context.Context, from *time.Time, to *time.Time) ([]*anonstore.Device, error)
 }
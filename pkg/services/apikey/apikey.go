@@ -12,7 +12,8 @@ type Service interface {
 	GetApiKeyById(ctx context.Context, query *GetByIDQuery) (res *APIKey, err error)
 	GetApiKeyByName(ctx context.Context, query *GetByNameQuery) (res *APIKey, err error)
 	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
-	UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64) error
+	// UpdateAPIKeyLastUsedDate records a use of the key: its date, its source IP and the number of uses.
+	UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64, sourceIP string) error
 	// IsDisabled returns true if the API key is not available for use.
 	IsDisabled(ctx context.Context, orgID int64) (bool, error)
 }
@@ -16,7 +16,7 @@ type store interface {
 	GetApiKeyById(ctx context.Context, query *apikey.GetByIDQuery) (res *apikey.APIKey, err error)
 	GetApiKeyByName(ctx context.Context, query *apikey.GetByNameQuery) (res *apikey.APIKey, err error)
 	GetAPIKeyByHash(ctx context.Context, hash string) (*apikey.APIKey, error)
-	UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64) error
+	UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64, sourceIP string) error
 
 	Count(context.Context, *quota.ScopeParameters) (*quota.Map, error)
 }
@@ -133,13 +133,18 @@ func testIntegrationApiKeyDataAccess(t *testing.T, fn getStore) {
 
 			assert.Nil(t, key.LastUsedAt)
 
-			err = ss.UpdateAPIKeyLastUsedDate(context.Background(), key.ID)
+			err = ss.UpdateAPIKeyLastUsedDate(context.Background(), key.ID, "10.0.0.1")
+			require.NoError(t, err)
+			err = ss.UpdateAPIKeyLastUsedDate(context.Background(), key.ID, "10.0.0.2")
 			require.NoError(t, err)
 
 			query := apikey.GetByNameQuery{KeyName: "last-update-at", OrgID: 1}
 			key, err = ss.GetApiKeyByName(context.Background(), &query)
 			assert.Nil(t, err)
 			assert.NotNil(t, key.LastUsedAt)
+			require.NotNil(t, key.LastUsedIP)
+			assert.Equal(t, "10.0.0.2", *key.LastUsedIP)
+			assert.Equal(t, int64(2), key.UseCount)
 		})
 
 		t.Run("Add a key with negative lifespan", func(t *testing.T) {
@@ -184,10 +184,12 @@ func (ss *sqlStore) GetAPIKeyByHash(ctx context.Context, hash string) (*apikey.A
 	return &key, err
 }
 
-func (ss *sqlStore) UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64) error {
+func (ss *sqlStore) UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64, sourceIP string) error {
 	now := timeNow()
 	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		if _, err := sess.Table("api_key").ID(tokenID).Cols("last_used_at").Update(&apikey.APIKey{LastUsedAt: &now}); err != nil {
+		// the count is incremented in the database, the uses of a key are recorded concurrently
+		if _, err := sess.Table("api_key").ID(tokenID).Cols("last_used_at", "last_used_ip").Incr("use_count").
+			Update(&apikey.APIKey{LastUsedAt: &now, LastUsedIP: &sourceIP}); err != nil {
 			return err
 		}
 
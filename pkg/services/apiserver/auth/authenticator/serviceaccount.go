@@ -0,0 +1,55 @@
+package authenticator
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/authlib/claims"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/klog/v2"
+
+	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/authn"
+)
+
+// serviceAccountTokenPrefix is the prefix of the tokens of the Grafana service accounts.
+const serviceAccountTokenPrefix = satokengen.GrafanaPrefix + "sa_"
+
+var _ authenticator.Token = (*serviceAccountTokenAuthenticator)(nil)
+
+// serviceAccountTokenAuthenticator authenticates the bearer tokens of the Grafana service accounts, so
+// clients that talk to the apiserver directly, like kubectl --token=glsa_..., are mapped to the same
+// requester, with the same permissions, as requests made to the Grafana HTTP API with the token.
+type serviceAccountTokenAuthenticator struct {
+	authn authn.Authenticator
+}
+
+func NewServiceAccountTokenAuthenticator(authenticator authn.Authenticator) authenticator.Token {
+	return &serviceAccountTokenAuthenticator{authn: authenticator}
+}
+
+func (a *serviceAccountTokenAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	if !strings.HasPrefix(token, serviceAccountTokenPrefix) {
+		return nil, false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/apis", nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	id, err := a.authn.Authenticate(ctx, &authn.Request{HTTPRequest: req})
+	if err != nil {
+		klog.V(5).Info("failed to authenticate service account token", "err", err)
+		return nil, false, nil
+	}
+	if !id.IsIdentityType(claims.TypeServiceAccount) {
+		return nil, false, nil
+	}
+
+	return &authenticator.Response{
+		User: id.SignedInUser(),
+	}, true, nil
+}
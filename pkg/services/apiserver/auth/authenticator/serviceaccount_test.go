@@ -0,0 +1,60 @@
+package authenticator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+)
+
+func TestServiceAccountTokenAuthenticator(t *testing.T) {
+	t.Run("should map the token to the service account", func(t *testing.T) {
+		authnService := &authntest.FakeService{ExpectedIdentity: &authn.Identity{
+			ID:          "2",
+			Type:        claims.TypeServiceAccount,
+			OrgID:       1,
+			Login:       "sa-kubectl",
+			Permissions: map[int64]map[string][]string{1: {"dashboards:read": {"dashboards:*"}}},
+		}}
+
+		res, ok, err := NewServiceAccountTokenAuthenticator(authnService).AuthenticateToken(context.Background(), "glsa_abc_123")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		requester, isRequester := res.User.(identity.Requester)
+		require.True(t, isRequester)
+		require.Equal(t, "sa-kubectl", requester.GetLogin())
+		require.Equal(t, int64(1), requester.GetOrgID())
+		require.Equal(t, []string{"dashboards:*"}, requester.GetPermissions()["dashboards:read"])
+	})
+
+	t.Run("should skip other tokens", func(t *testing.T) {
+		authnService := &authntest.FakeService{ExpectedIdentity: &authn.Identity{ID: "1", Type: claims.TypeServiceAccount}}
+
+		_, ok, err := NewServiceAccountTokenAuthenticator(authnService).AuthenticateToken(context.Background(), "eyJrIjoi")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("should reject invalid tokens", func(t *testing.T) {
+		authnService := &authntest.FakeService{ExpectedErr: errors.New("invalid API key")}
+
+		_, ok, err := NewServiceAccountTokenAuthenticator(authnService).AuthenticateToken(context.Background(), "glsa_abc_123")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("should reject tokens of other identities", func(t *testing.T) {
+		authnService := &authntest.FakeService{ExpectedIdentity: &authn.Identity{ID: "1", Type: claims.TypeAPIKey}}
+
+		_, ok, err := NewServiceAccountTokenAuthenticator(authnService).AuthenticateToken(context.Background(), "glsa_abc_123")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
@@ -3,45 +3,61 @@ package authorizer
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	orgsvc "github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8suser "k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
-	"k8s.io/apiserver/pkg/authorization/union"
+	unionauthorizer "k8s.io/apiserver/pkg/authorization/union"
 )
 
 var _ authorizer.Authorizer = (*GrafanaAuthorizer)(nil)
 
 type GrafanaAuthorizer struct {
-	apis map[string]authorizer.Authorizer
-	auth authorizer.Authorizer
+	apis        map[string]authorizer.Authorizer
+	authorizers []namedAuthorizer
+	auth        authorizer.Authorizer
+}
+
+type namedAuthorizer struct {
+	name string
+	authorizer.Authorizer
 }
 
-func NewGrafanaAuthorizer(cfg *setting.Cfg, orgService orgsvc.Service) *GrafanaAuthorizer {
-	authorizers := []authorizer.Authorizer{
-		&impersonationAuthorizer{},
-		authorizerfactory.NewPrivilegedGroups(k8suser.SystemPrivilegedGroup),
+func NewGrafanaAuthorizer(cfg *setting.Cfg, orgService orgsvc.Service, ac accesscontrol.AccessControl) *GrafanaAuthorizer {
+	authorizers := []namedAuthorizer{
+		{"impersonation", &impersonationAuthorizer{}},
+		{"privileged", authorizerfactory.NewPrivilegedGroups(k8suser.SystemPrivilegedGroup)},
 	}
 
 	// In Hosted grafana, the StackID replaces the orgID as a valid namespace
 	if cfg.StackID != "" {
-		authorizers = append(authorizers, newStackIDAuthorizer(cfg))
+		authorizers = append(authorizers, namedAuthorizer{"stack", newStackIDAuthorizer(cfg)})
 	} else {
-		authorizers = append(authorizers, newOrgIDAuthorizer(orgService))
+		authorizers = append(authorizers, namedAuthorizer{"org", newOrgIDAuthorizer(orgService)})
 	}
 
 	// Individual services may have explicit implementations
 	apis := make(map[string]authorizer.Authorizer)
-	authorizers = append(authorizers, &authorizerForAPI{apis})
+	authorizers = append(authorizers, namedAuthorizer{"api", &authorizerForAPI{apis}})
+
+	// The resources with RBAC actions are authorized like their /api routes
+	authorizers = append(authorizers, namedAuthorizer{"rbac", newRBACAuthorizer(ac, RBACResources)})
 
 	// org role is last -- and will return allow for verbs that match expectations
 	// The apiVersion flavors will run first and can return early when FGAC has appropriate rules
-	authorizers = append(authorizers, newOrgRoleAuthorizer(orgService))
+	authorizers = append(authorizers, namedAuthorizer{"orgrole", newOrgRoleAuthorizer(orgService)})
+
+	union := make([]authorizer.Authorizer, 0, len(authorizers))
+	for _, a := range authorizers {
+		union = append(union, a)
+	}
 	return &GrafanaAuthorizer{
-		apis: apis,
-		auth: union.New(authorizers...),
+		apis:        apis,
+		authorizers: authorizers,
+		auth:        unionauthorizer.New(union...),
 	}
 }
 
@@ -54,6 +70,43 @@ func (a *GrafanaAuthorizer) Authorize(ctx context.Context, attr authorizer.Attri
 	return a.auth.Authorize(ctx, attr)
 }
 
+// AuthorizerDecision is the decision of one of the authorizers of a request.
+type AuthorizerDecision struct {
+	Authorizer string `json:"authorizer"`
+	Decision   string `json:"decision"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Explain returns the decisions of the authorizers consulted for the request, in order. Like Authorize,
+// it stops at the first authorizer allowing or denying the request.
+func (a *GrafanaAuthorizer) Explain(ctx context.Context, attr authorizer.Attributes) []AuthorizerDecision {
+	decisions := []AuthorizerDecision{}
+	for _, auth := range a.authorizers {
+		decision, reason, err := auth.Authorize(ctx, attr)
+		d := AuthorizerDecision{Authorizer: auth.name, Decision: decisionString(decision), Reason: reason}
+		if err != nil {
+			d.Error = err.Error()
+		}
+		decisions = append(decisions, d)
+		if decision != authorizer.DecisionNoOpinion {
+			break
+		}
+	}
+	return decisions
+}
+
+func decisionString(decision authorizer.Decision) string {
+	switch decision {
+	case authorizer.DecisionAllow:
+		return "allow"
+	case authorizer.DecisionDeny:
+		return "deny"
+	default:
+		return "no opinion"
+	}
+}
+
 type authorizerForAPI struct {
 	apis map[string]authorizer.Authorizer
 }
@@ -0,0 +1,136 @@
+package authorizer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	identityv0alpha1 "github.com/grafana/grafana/pkg/apis/identity/v0alpha1"
+	playlistv0alpha1 "github.com/grafana/grafana/pkg/apis/playlist/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+const rbacCacheTTL = 10 * time.Second
+
+// RBACResource maps the verbs on a resource onto the RBAC actions checked by the /api routes of the resource.
+type RBACResource struct {
+	Actions map[string]string
+	// Scope gives the scope of the named resources. When it is nil, and for requests without a name,
+	// the action is checked on any scope.
+	Scope accesscontrol.ScopeProvider
+}
+
+// RBACResources are the resources authorized with the RBAC permissions of the user.
+var RBACResources = map[schema.GroupResource]RBACResource{
+	dashboardv0alpha1.DashboardResourceInfo.GroupResource(): {
+		Actions: crudActions(dashboards.ActionDashboardsRead, dashboards.ActionDashboardsCreate, dashboards.ActionDashboardsWrite, dashboards.ActionDashboardsDelete),
+		Scope:   dashboards.ScopeDashboardsProvider,
+	},
+	folderv0alpha1.FolderResourceInfo.GroupResource(): {
+		Actions: crudActions(dashboards.ActionFoldersRead, dashboards.ActionFoldersCreate, dashboards.ActionFoldersWrite, dashboards.ActionFoldersDelete),
+		Scope:   dashboards.ScopeFoldersProvider,
+	},
+	playlistv0alpha1.PlaylistResourceInfo.GroupResource(): {
+		Actions: crudActions(playlist.ActionRead, playlist.ActionCreate, playlist.ActionWrite, playlist.ActionDelete),
+		Scope:   playlist.ScopeProvider,
+	},
+	identityv0alpha1.UserResourceInfo.GroupResource(): {
+		Actions: crudActions(accesscontrol.ActionUsersRead, accesscontrol.ActionUsersCreate, accesscontrol.ActionUsersWrite, accesscontrol.ActionUsersDelete),
+	},
+	identityv0alpha1.TeamResourceInfo.GroupResource(): {
+		Actions: crudActions(accesscontrol.ActionTeamsRead, accesscontrol.ActionTeamsCreate, accesscontrol.ActionTeamsWrite, accesscontrol.ActionTeamsDelete),
+	},
+	identityv0alpha1.ServiceAccountResourceInfo.GroupResource(): {
+		Actions: crudActions(serviceaccounts.ActionRead, serviceaccounts.ActionCreate, serviceaccounts.ActionWrite, serviceaccounts.ActionDelete),
+	},
+}
+
+func crudActions(read, create, write, delete string) map[string]string {
+	return map[string]string{
+		"get":              read,
+		"list":             read,
+		"watch":            read,
+		"create":           create,
+		"update":           write,
+		"patch":            write,
+		"delete":           delete,
+		"deletecollection": delete,
+	}
+}
+
+var _ authorizer.Authorizer = (*rbacAuthorizer)(nil)
+
+// rbacAuthorizer authorizes the requests to the RBAC resources, so their permissions are the same
+// through /api and /apis. The decisions are cached for a short time, since resolving the scopes of
+// a resource can be expensive.
+type rbacAuthorizer struct {
+	ac        accesscontrol.AccessControl
+	resources map[schema.GroupResource]RBACResource
+	cache     *localcache.CacheService
+}
+
+type rbacDecision struct {
+	decision authorizer.Decision
+	reason   string
+}
+
+func newRBACAuthorizer(ac accesscontrol.AccessControl, resources map[schema.GroupResource]RBACResource) *rbacAuthorizer {
+	return &rbacAuthorizer{
+		ac:        ac,
+		resources: resources,
+		cache:     localcache.New(rbacCacheTTL, 2*rbacCacheTTL),
+	}
+}
+
+func (a *rbacAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	if !attr.IsResourceRequest() {
+		return authorizer.DecisionNoOpinion, "", nil
+	}
+	resource, ok := a.resources[schema.GroupResource{Group: attr.GetAPIGroup(), Resource: attr.GetResource()}]
+	if !ok {
+		return authorizer.DecisionNoOpinion, "", nil
+	}
+	action, ok := resource.Actions[attr.GetVerb()]
+	if !ok {
+		return authorizer.DecisionDeny, fmt.Sprintf("verb %s is not supported on %s", attr.GetVerb(), attr.GetResource()), nil
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return authorizer.DecisionDeny, "valid user is required", err
+	}
+
+	scopes := []string{}
+	if resource.Scope != nil && attr.GetName() != "" {
+		scopes = append(scopes, resource.Scope.GetResourceScopeUID(attr.GetName()))
+	}
+	evaluator := accesscontrol.EvalPermission(action, scopes...)
+
+	key := fmt.Sprintf("%s/%d/%s", user.GetID(), user.GetOrgID(), evaluator.GoString())
+	if cached, ok := a.cache.Get(key); ok {
+		d := cached.(rbacDecision)
+		return d.decision, d.reason, nil
+	}
+
+	allowed, err := a.ac.Evaluate(ctx, user, evaluator)
+	if err != nil {
+		return authorizer.DecisionDeny, fmt.Sprintf("failed to evaluate %s", evaluator.GoString()), err
+	}
+
+	d := rbacDecision{decision: authorizer.DecisionAllow, reason: fmt.Sprintf("granted %s", evaluator.GoString())}
+	if !allowed {
+		d = rbacDecision{decision: authorizer.DecisionDeny, reason: fmt.Sprintf("missing %s", evaluator.GoString())}
+	}
+	a.cache.SetDefault(key, d)
+	return d.decision, d.reason, nil
+}
@@ -0,0 +1,102 @@
+package authorizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestRBACAuthorizer(t *testing.T) {
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{UserID: 1, OrgID: 1})
+
+	t.Run("should check the action of the verb on the scope of the resource", func(t *testing.T) {
+		ac := &recordingAccessControl{allow: true}
+		auth := newRBACAuthorizer(ac, RBACResources)
+
+		decision, reason, err := auth.Authorize(ctx, &authorizer.AttributesRecord{
+			Verb: "update", APIGroup: "folder.grafana.app", Resource: "folders", Name: "abc", ResourceRequest: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, authorizer.DecisionAllow, decision)
+		require.Equal(t, "granted action:folders:write scopes:folders:uid:abc", reason)
+		require.Equal(t, "action:folders:write scopes:folders:uid:abc", ac.evaluated[0])
+	})
+
+	t.Run("should check the action on any scope for requests without name", func(t *testing.T) {
+		ac := &recordingAccessControl{allow: false}
+		auth := newRBACAuthorizer(ac, RBACResources)
+
+		decision, reason, err := auth.Authorize(ctx, &authorizer.AttributesRecord{
+			Verb: "list", APIGroup: "identity.grafana.app", Resource: "users", ResourceRequest: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, authorizer.DecisionDeny, decision)
+		require.Equal(t, "missing action:users:read scopes:", reason)
+	})
+
+	t.Run("should cache the decisions", func(t *testing.T) {
+		ac := &recordingAccessControl{allow: true}
+		auth := newRBACAuthorizer(ac, RBACResources)
+		attr := &authorizer.AttributesRecord{
+			Verb: "get", APIGroup: "dashboard.grafana.app", Resource: "dashboards", Name: "abc", ResourceRequest: true,
+		}
+
+		for i := 0; i < 3; i++ {
+			decision, _, err := auth.Authorize(ctx, attr)
+			require.NoError(t, err)
+			require.Equal(t, authorizer.DecisionAllow, decision)
+		}
+		require.Len(t, ac.evaluated, 1)
+	})
+
+	t.Run("should have no opinion on other resources", func(t *testing.T) {
+		auth := newRBACAuthorizer(&recordingAccessControl{}, RBACResources)
+
+		decision, _, err := auth.Authorize(ctx, &authorizer.AttributesRecord{
+			Verb: "get", APIGroup: "featuretoggle.grafana.app", Resource: "features", ResourceRequest: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, authorizer.DecisionNoOpinion, decision)
+	})
+
+	t.Run("should check the playlist actions on the scope of the playlist", func(t *testing.T) {
+		ac := &recordingAccessControl{allow: true}
+		auth := newRBACAuthorizer(ac, RBACResources)
+
+		decision, _, err := auth.Authorize(ctx, &authorizer.AttributesRecord{
+			Verb: "delete", APIGroup: "playlist.grafana.app", Resource: "playlists", Name: "abc", ResourceRequest: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, authorizer.DecisionAllow, decision)
+		require.Equal(t, "action:playlists:delete scopes:playlists:uid:abc", ac.evaluated[0])
+	})
+
+	t.Run("should deny unknown verbs", func(t *testing.T) {
+		auth := newRBACAuthorizer(&recordingAccessControl{allow: true}, RBACResources)
+
+		decision, _, err := auth.Authorize(ctx, &authorizer.AttributesRecord{
+			Verb: "escalate", APIGroup: "folder.grafana.app", Resource: "folders", ResourceRequest: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, authorizer.DecisionDeny, decision)
+	})
+}
+
+type recordingAccessControl struct {
+	allow     bool
+	evaluated []string
+}
+
+func (r *recordingAccessControl) Evaluate(ctx context.Context, user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
+	r.evaluated = append(r.evaluated, evaluator.GoString())
+	return r.allow, nil
+}
+
+func (r *recordingAccessControl) RegisterScopeAttributeResolver(prefix string, resolver accesscontrol.ScopeAttributeResolver) {
+}
@@ -0,0 +1,47 @@
+package apiserver
+
+import (
+	"net/http"
+
+	k8sauthorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/apiserver/auth/authorizer"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+type authorizationExplanation struct {
+	// Allowed is true when one of the authorizers allows the request.
+	Allowed     bool                            `json:"allowed"`
+	Authorizers []authorizer.AuthorizerDecision `json:"authorizers"`
+}
+
+// explainAuthorization explains how the authorizers of the apiserver decide on a request of the signed
+// in user, given by the verb, group, version, resource, subresource, name and namespace query parameters.
+//
+// GET /api/apiserver/authorization
+func (s *service) explainAuthorization(c *contextmodel.ReqContext) response.Response {
+	attr := k8sauthorizer.AttributesRecord{
+		User:            c.SignedInUser,
+		Verb:            c.Query("verb"),
+		APIGroup:        c.Query("group"),
+		APIVersion:      c.Query("version"),
+		Resource:        c.Query("resource"),
+		Subresource:     c.Query("subresource"),
+		Name:            c.Query("name"),
+		Namespace:       c.Query("namespace"),
+		ResourceRequest: true,
+	}
+	if attr.Verb == "" || attr.Resource == "" {
+		return response.Error(http.StatusBadRequest, "verb and resource are required", nil)
+	}
+
+	ctx := identity.WithRequester(c.Req.Context(), c.SignedInUser)
+	decisions := s.authorizer.Explain(ctx, attr)
+	last := decisions[len(decisions)-1]
+	return response.JSON(http.StatusOK, authorizationExplanation{
+		Allowed:     last.Decision == "allow",
+		Authorizers: decisions,
+	})
+}
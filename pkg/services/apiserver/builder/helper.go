@@ -57,6 +57,7 @@ func SetupConfig(
 	scheme *runtime.Scheme,
 	serverConfig *genericapiserver.RecommendedConfig,
 	builders []APIGroupBuilder,
+	flowControl *filters.FlowControlConfig,
 	buildTimestamp int64,
 	buildVersion string,
 	buildCommit string,
@@ -107,6 +108,14 @@ func SetupConfig(
 		handler := filters.WithTracingHTTPLoggingAttributes(requestHandler)
 		// filters.WithRequester needs to be after the K8s chain because it depends on the K8s user in context
 		handler = filters.WithRequester(handler)
+		// filters.WithFlowControl depends on the request info and the K8s user in context
+		if flowControl != nil {
+			groups := make([]string, 0, len(builders))
+			for _, b := range builders {
+				groups = append(groups, b.GetGroupVersion().Group)
+			}
+			handler = filters.WithFlowControl(handler, filters.NewFlowController(*flowControl, groups))
+		}
 		handler = genericapiserver.DefaultBuildHandlerChain(handler, c)
 
 		// If optional middlewares include auth function, they need to happen before DefaultBuildHandlerChain
@@ -5,6 +5,7 @@ import (
 	"net"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	playlist "github.com/grafana/grafana/pkg/apis/playlist/v0alpha1"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
@@ -57,7 +58,8 @@ func applyGrafanaConfig(cfg *setting.Cfg, features featuremgmt.FeatureToggles, o
 	o.StorageOptions.Address = apiserverCfg.Key("address").MustString(o.StorageOptions.Address)
 	o.StorageOptions.DualWriterDesiredModes = map[string]grafanarest.DualWriterMode{
 		// TODO: use the new config from HGAPI after https://github.com/grafana/hosted-grafana/pull/5707
-		playlist.GROUPRESOURCE: 2,
+		// Mode 3 reads the playlists from unified storage, which supports watching them, once the data is in sync
+		playlist.GROUPRESOURCE: grafanarest.DualWriterMode(apiserverCfg.Key("playlist_dual_writer_mode").MustInt(2)),
 	}
 
 	o.StorageOptions.DualWriterDataSyncJobEnabled = map[string]bool{
@@ -69,6 +71,31 @@ func applyGrafanaConfig(cfg *setting.Cfg, features featuremgmt.FeatureToggles, o
 	// remove this after changing the unified_storage_mode key format in HGAPI
 	o.StorageOptions.DualWriterDesiredModes[playlist.RESOURCE+"."+playlist.GROUP] = o.StorageOptions.DualWriterDesiredModes[playlist.GROUPRESOURCE]
 
+	o.RecommendedOptions.Audit.PolicyFile = apiserverCfg.Key("audit_policy_file").MustString("")
+	o.RecommendedOptions.Audit.LogOptions.Path = apiserverCfg.Key("audit_log_path").MustString("")
+	o.RecommendedOptions.Audit.LogOptions.Format = apiserverCfg.Key("audit_log_format").MustString(o.RecommendedOptions.Audit.LogOptions.Format)
+	o.RecommendedOptions.Audit.LogOptions.MaxAge = apiserverCfg.Key("audit_log_max_age").MustInt(7)
+	o.RecommendedOptions.Audit.LogOptions.MaxBackups = apiserverCfg.Key("audit_log_max_backups").MustInt(5)
+	o.RecommendedOptions.Audit.LogOptions.MaxSize = apiserverCfg.Key("audit_log_max_size").MustInt(100)
+	o.RecommendedOptions.Audit.WebhookOptions.ConfigFile = apiserverCfg.Key("audit_webhook_config_file").MustString("")
+	o.AuditOptions.DefaultLevel = apiserverCfg.Key("audit_level").MustString(o.AuditOptions.DefaultLevel)
+	o.AuditOptions.PolicyPath = filepath.Join(o.StorageOptions.DataPath, "audit-policy.yaml")
+
+	o.FlowControlOptions.Enabled = apiserverCfg.Key("flow_control_enabled").MustBool(o.FlowControlOptions.Enabled)
+	o.FlowControlOptions.Seats = apiserverCfg.Key("flow_control_seats").MustInt(o.FlowControlOptions.Seats)
+	o.FlowControlOptions.DefaultGroupShares = apiserverCfg.Key("flow_control_default_group_shares").MustInt(o.FlowControlOptions.DefaultGroupShares)
+	o.FlowControlOptions.UserShare = apiserverCfg.Key("flow_control_user_share").MustFloat64(o.FlowControlOptions.UserShare)
+	o.FlowControlOptions.QueueLength = apiserverCfg.Key("flow_control_queue_length").MustInt(o.FlowControlOptions.QueueLength)
+	o.FlowControlOptions.QueueTimeout = apiserverCfg.Key("flow_control_queue_timeout").MustDuration(o.FlowControlOptions.QueueTimeout)
+	for _, share := range apiserverCfg.Key("flow_control_group_shares").Strings(",") {
+		group, value, ok := strings.Cut(share, "=")
+		shares, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil {
+			return fmt.Errorf("invalid flow_control_group_shares entry %q, expected <group>=<shares>", share)
+		}
+		o.FlowControlOptions.GroupShares[strings.TrimSpace(group)] = shares
+	}
+
 	o.ExtraOptions.DevMode = features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerEnsureKubectlAccess)
 	o.ExtraOptions.ExternalAddress = host
 	o.ExtraOptions.APIURL = apiURL
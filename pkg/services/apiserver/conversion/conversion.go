@@ -0,0 +1,199 @@
+package conversion
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+)
+
+// legacyOrigin is the origin of the objects stored in the legacy SQL tables, with their internal ID as path.
+const legacyOrigin = "SQL"
+
+// Converter converts the objects of a kind to and from the DTOs of the legacy /api routes, so both APIs can
+// be served from the same storage. The fields of the DTOs that depend on the requester, like the permissions
+// or the login of the creator, are not converted.
+type Converter interface {
+	// Kind is the kind of the converted objects.
+	Kind() schema.GroupVersionKind
+	// ToObject converts a DTO to an object in the namespace.
+	ToObject(dto any, namespace string) (runtime.Object, error)
+	// ToDTO converts an object to a DTO of the organization of its namespace.
+	ToDTO(obj runtime.Object) (any, error)
+}
+
+// NewConverter returns the converter of a kind from the functions converting its objects, of type O, and its
+// DTOs, of type D. The DTOs given to the converter can be either values or pointers.
+func NewConverter[D any, O runtime.Object](
+	kind schema.GroupVersionKind,
+	toObject func(dto *D, namespace string) (O, error),
+	toDTO func(obj O, orgID int64) (*D, error),
+) Converter {
+	return &converter[D, O]{kind: kind, toObject: toObject, toDTO: toDTO}
+}
+
+type converter[D any, O runtime.Object] struct {
+	kind     schema.GroupVersionKind
+	toObject func(dto *D, namespace string) (O, error)
+	toDTO    func(obj O, orgID int64) (*D, error)
+}
+
+func (c *converter[D, O]) Kind() schema.GroupVersionKind {
+	return c.kind
+}
+
+func (c *converter[D, O]) ToObject(dto any, namespace string) (runtime.Object, error) {
+	switch v := dto.(type) {
+	case *D:
+		if v == nil {
+			return nil, fmt.Errorf("missing %s DTO", c.kind.Kind)
+		}
+		return c.toObject(v, namespace)
+	case D:
+		return c.toObject(&v, namespace)
+	default:
+		return nil, fmt.Errorf("expected %T DTO for %s, got %T", new(D), c.kind.Kind, dto)
+	}
+}
+
+func (c *converter[D, O]) ToDTO(obj runtime.Object) (any, error) {
+	v, ok := obj.(O)
+	if !ok {
+		return nil, fmt.Errorf("expected %s object, got %T", c.kind.Kind, obj)
+	}
+	meta, err := utils.MetaAccessor(v)
+	if err != nil {
+		return nil, err
+	}
+	info, err := claims.ParseNamespace(meta.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	return c.toDTO(v, info.OrgID)
+}
+
+// AddToScheme registers the conversions of the converter in the scheme, so that scheme.Convert converts the
+// DTOs to objects, with the namespace as context, and the objects to DTOs.
+func (c *converter[D, O]) AddToScheme(scheme *runtime.Scheme) error {
+	var obj O
+	if err := scheme.AddConversionFunc((*D)(nil), obj, func(a, b any, scope conversion.Scope) error {
+		var namespace string
+		if meta := scope.Meta(); meta != nil {
+			namespace, _ = meta.Context.(string)
+		}
+		if namespace == "" {
+			return fmt.Errorf("missing namespace to convert the %s DTO", c.kind.Kind)
+		}
+		converted, err := c.toObject(a.(*D), namespace)
+		if err != nil {
+			return err
+		}
+		reflect.ValueOf(b).Elem().Set(reflect.ValueOf(converted).Elem())
+		return nil
+	}); err != nil {
+		return err
+	}
+	return scheme.AddConversionFunc(obj, (*D)(nil), func(a, b any, _ conversion.Scope) error {
+		dto, err := c.ToDTO(a.(O))
+		if err != nil {
+			return err
+		}
+		*b.(*D) = *dto.(*D)
+		return nil
+	})
+}
+
+// Registry holds the converters of the kinds served by both APIs.
+type Registry struct {
+	converters map[schema.GroupVersionKind]Converter
+}
+
+// NewRegistry returns a registry of the converters of the dashboards, the folders, the users and the teams.
+func NewRegistry() *Registry {
+	r := &Registry{converters: map[schema.GroupVersionKind]Converter{}}
+	for _, c := range []Converter{dashboardConverter, folderConverter, userConverter, teamConverter} {
+		r.converters[c.Kind()] = c
+	}
+	return r
+}
+
+// Register adds the converter of a kind. A kind has a single converter.
+func (r *Registry) Register(c Converter) error {
+	if _, ok := r.converters[c.Kind()]; ok {
+		return fmt.Errorf("a converter is already registered for %s", c.Kind())
+	}
+	r.converters[c.Kind()] = c
+	return nil
+}
+
+// AddToScheme registers the conversions of the converters in the scheme. The converters not created with
+// NewConverter can't be registered.
+func (r *Registry) AddToScheme(scheme *runtime.Scheme) error {
+	for kind, c := range r.converters {
+		sc, ok := c.(interface {
+			AddToScheme(scheme *runtime.Scheme) error
+		})
+		if !ok {
+			return fmt.Errorf("the converter of %s can't be added to the scheme", kind)
+		}
+		if err := sc.AddToScheme(scheme); err != nil {
+			return fmt.Errorf("failed to add the conversions of %s to the scheme: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// Converter returns the converter of a kind.
+func (r *Registry) Converter(kind schema.GroupVersionKind) (Converter, error) {
+	c, ok := r.converters[kind]
+	if !ok {
+		return nil, fmt.Errorf("no converter registered for %s", kind)
+	}
+	return c, nil
+}
+
+// ToObject converts a DTO to an object of the kind in the namespace.
+func (r *Registry) ToObject(kind schema.GroupVersionKind, dto any, namespace string) (runtime.Object, error) {
+	c, err := r.Converter(kind)
+	if err != nil {
+		return nil, err
+	}
+	return c.ToObject(dto, namespace)
+}
+
+// ToDTO converts an object to the DTO of its kind.
+func (r *Registry) ToDTO(obj runtime.Object) (any, error) {
+	c, err := r.Converter(obj.GetObjectKind().GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	return c.ToDTO(obj)
+}
+
+// setLegacyID keeps the internal ID of the DTO in the origin of the object, like the legacy storage does.
+func setLegacyID(meta utils.GrafanaMetaAccessor, id int64) {
+	if id > 0 {
+		meta.SetOriginInfo(&utils.ResourceOriginInfo{
+			Name: legacyOrigin,
+			Path: strconv.FormatInt(id, 10),
+		})
+	}
+}
+
+// getLegacyID returns the internal ID kept by setLegacyID, and 0 for the objects without one.
+func getLegacyID(meta utils.GrafanaMetaAccessor) int64 {
+	if meta.GetOriginName() != legacyOrigin {
+		return 0
+	}
+	id, err := strconv.ParseInt(meta.GetOriginPath(), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
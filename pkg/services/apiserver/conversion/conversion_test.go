@@ -0,0 +1,137 @@
+package conversion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	identityv0alpha1 "github.com/grafana/grafana/pkg/apis/identity/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	// the annotations only keep the timestamps to the second
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	// roundTrip converts the DTO to an object of the kind and back, and checks nothing was lost.
+	roundTrip := func(t *testing.T, kind schema.GroupVersionKind, dto any) runtime.Object {
+		t.Helper()
+		obj, err := r.ToObject(kind, dto, "org-2")
+		require.NoError(t, err)
+		back, err := r.ToDTO(obj)
+		require.NoError(t, err)
+		require.Equal(t, dto, back)
+		return obj
+	}
+
+	t.Run("dashboards", func(t *testing.T) {
+		dto := &dtos.DashboardFullWithMeta{
+			Dashboard: simplejson.NewFromAny(map[string]any{"id": 12, "uid": "abc", "title": "Hello world", "version": 3}),
+			Meta: dtos.DashboardMeta{
+				Type:                  "db",
+				Slug:                  "hello-world",
+				Url:                   "/d/abc/hello-world",
+				Created:               created,
+				Updated:               updated,
+				Version:               3,
+				FolderUid:             "folder",
+				Provisioned:           true,
+				ProvisionedExternalId: "dashboards/hello.json",
+			},
+		}
+		obj, err := r.ToObject(dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(), dto, "org-2")
+		require.NoError(t, err)
+		dash := obj.(*dashboardv0alpha1.Dashboard)
+		require.Equal(t, "abc", dash.Name)
+		require.Equal(t, "org-2", dash.Namespace)
+		require.Equal(t, "Hello world", dash.Spec.GetNestedString("title"))
+		meta, err := utils.MetaAccessor(dash)
+		require.NoError(t, err)
+		require.Equal(t, "folder", meta.GetFolder())
+
+		back, err := r.ToDTO(dash)
+		require.NoError(t, err)
+		backDTO := back.(*dtos.DashboardFullWithMeta)
+		require.Equal(t, dto.Meta, backDTO.Meta)
+		require.JSONEq(t, string(dto.Dashboard.MustEncode()), string(backDTO.Dashboard.MustEncode()))
+	})
+
+	t.Run("folders", func(t *testing.T) {
+		obj := roundTrip(t, folderv0alpha1.FolderResourceInfo.GroupVersionKind(), &dtos.Folder{
+			ID:        7, // nolint:staticcheck
+			UID:       "folder",
+			OrgID:     2,
+			Title:     "My folder",
+			URL:       "/dashboards/f/folder/my-folder",
+			Created:   created,
+			Updated:   updated,
+			ParentUID: "parent",
+		})
+		f := obj.(*folderv0alpha1.Folder)
+		require.Equal(t, "My folder", f.Spec.Title)
+		require.Equal(t, folderv0alpha1.FolderResourceInfo.TypeMeta(), f.TypeMeta)
+	})
+
+	t.Run("users", func(t *testing.T) {
+		obj := roundTrip(t, identityv0alpha1.UserResourceInfo.GroupVersionKind(), &user.UserProfileDTO{
+			ID:         3,
+			UID:        "user",
+			Email:      "user@example.com",
+			Name:       "User",
+			Login:      "user",
+			OrgID:      2,
+			IsDisabled: true,
+			CreatedAt:  created,
+			UpdatedAt:  updated,
+		})
+		require.Equal(t, "user", obj.(*identityv0alpha1.User).Spec.Login)
+	})
+
+	t.Run("teams", func(t *testing.T) {
+		// the DTOs can also be given by value
+		obj, err := r.ToObject(identityv0alpha1.TeamResourceInfo.GroupVersionKind(), team.TeamDTO{ID: 4, UID: "team", OrgID: 2, Name: "Team", Email: "team@example.com"}, "org-2")
+		require.NoError(t, err)
+		require.Equal(t, "Team", obj.(*identityv0alpha1.Team).Spec.Title)
+
+		roundTrip(t, identityv0alpha1.TeamResourceInfo.GroupVersionKind(), &team.TeamDTO{ID: 4, UID: "team", OrgID: 2, Name: "Team", Email: "team@example.com"})
+	})
+
+	t.Run("should reject unknown kinds and DTOs", func(t *testing.T) {
+		_, err := r.ToObject(identityv0alpha1.ServiceAccountResourceInfo.GroupVersionKind(), &user.UserProfileDTO{}, "default")
+		require.Error(t, err)
+
+		_, err = r.ToObject(identityv0alpha1.UserResourceInfo.GroupVersionKind(), &team.TeamDTO{}, "default")
+		require.ErrorContains(t, err, "expected *user.UserProfileDTO DTO for User")
+
+		require.Error(t, r.Register(userConverter))
+	})
+}
+
+func TestAddToScheme(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, NewRegistry().AddToScheme(scheme))
+
+	dto := &team.TeamDTO{ID: 4, UID: "team", OrgID: 2, Name: "Team", Email: "team@example.com"}
+	obj := &identityv0alpha1.Team{}
+	require.NoError(t, scheme.Convert(dto, obj, "org-2"))
+	require.Equal(t, "team", obj.Name)
+	require.Equal(t, "org-2", obj.Namespace)
+	require.Equal(t, "Team", obj.Spec.Title)
+
+	back := &team.TeamDTO{}
+	require.NoError(t, scheme.Convert(obj, back, nil))
+	require.Equal(t, dto, back)
+
+	require.Error(t, scheme.Convert(dto, &identityv0alpha1.Team{}, nil))
+}
@@ -0,0 +1,104 @@
+package conversion
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	dashboardv0alpha1 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	gapiutil "github.com/grafana/grafana/pkg/services/apiserver/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// provisionedOrigin is the origin of the provisioned dashboards, since their DTO does not say which provisioner
+// they come from.
+const provisionedOrigin = "provisioning"
+
+var dashboardConverter = NewConverter(dashboardv0alpha1.DashboardResourceInfo.GroupVersionKind(), dashboardToObject, dashboardToDTO)
+
+// dashboardToObject converts the dashboard of GET /api/dashboards/uid/:uid. The internal ID and the version
+// stay in the spec, like in the legacy storage.
+func dashboardToObject(dto *dtos.DashboardFullWithMeta, namespace string) (*dashboardv0alpha1.Dashboard, error) {
+	if dto.Dashboard == nil {
+		return nil, fmt.Errorf("missing dashboard")
+	}
+	spec, err := dto.Dashboard.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("invalid dashboard: %w", err)
+	}
+
+	dash := &dashboardv0alpha1.Dashboard{
+		TypeMeta: dashboardv0alpha1.DashboardResourceInfo.TypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              dto.Dashboard.Get("uid").MustString(),
+			Namespace:         namespace,
+			CreationTimestamp: metav1.NewTime(dto.Meta.Created),
+		},
+	}
+	if err := dash.Spec.UnmarshalJSON(spec); err != nil {
+		return nil, fmt.Errorf("invalid dashboard: %w", err)
+	}
+
+	meta, err := utils.MetaAccessor(dash)
+	if err != nil {
+		return nil, err
+	}
+	meta.SetUpdatedTimestamp(&dto.Meta.Updated)
+	if dto.Meta.FolderUid != "" {
+		meta.SetFolder(dto.Meta.FolderUid)
+	}
+	if dto.Meta.Provisioned {
+		meta.SetOriginInfo(&utils.ResourceOriginInfo{
+			Name: provisionedOrigin,
+			Path: dto.Meta.ProvisionedExternalId,
+		})
+	}
+	dash.UID = gapiutil.CalculateClusterWideUID(dash)
+	return dash, nil
+}
+
+func dashboardToDTO(dash *dashboardv0alpha1.Dashboard, _ int64) (*dtos.DashboardFullWithMeta, error) {
+	meta, err := utils.MetaAccessor(dash)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := dash.Spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	data, err := simplejson.NewJson(spec)
+	if err != nil {
+		return nil, err
+	}
+	data.Set("uid", dash.Name)
+
+	slug := slugify.Slugify(data.Get("title").MustString())
+	dto := &dtos.DashboardFullWithMeta{
+		Dashboard: data,
+		Meta: dtos.DashboardMeta{
+			Type:      dashboards.DashTypeDB,
+			Slug:      slug,
+			Url:       dashboards.GetDashboardFolderURL(false, dash.Name, slug),
+			Created:   dash.CreationTimestamp.Time,
+			Version:   data.Get("version").MustInt(),
+			FolderUid: meta.GetFolder(),
+		},
+	}
+	updated, err := meta.GetUpdatedTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	if updated != nil {
+		dto.Meta.Updated = *updated
+	}
+	if origin := meta.GetOriginName(); origin != "" && origin != legacyOrigin && origin != "plugin" {
+		dto.Meta.Provisioned = true
+		dto.Meta.ProvisionedExternalId = meta.GetOriginPath()
+	}
+	return dto, nil
+}
@@ -0,0 +1,69 @@
+package conversion
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	folderv0alpha1 "github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	gapiutil "github.com/grafana/grafana/pkg/services/apiserver/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+var folderConverter = NewConverter(folderv0alpha1.FolderResourceInfo.GroupVersionKind(), folderToObject, folderToDTO)
+
+// folderToObject converts the folder of GET /api/folders/:uid, like the legacy storage of the folders.
+func folderToObject(dto *dtos.Folder, namespace string) (*folderv0alpha1.Folder, error) {
+	f := &folderv0alpha1.Folder{
+		TypeMeta: folderv0alpha1.FolderResourceInfo.TypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              dto.UID,
+			Namespace:         namespace,
+			ResourceVersion:   fmt.Sprintf("%d", dto.Updated.UnixMilli()),
+			CreationTimestamp: metav1.NewTime(dto.Created),
+		},
+		Spec: folderv0alpha1.Spec{
+			Title: dto.Title,
+		},
+	}
+
+	meta, err := utils.MetaAccessor(f)
+	if err != nil {
+		return nil, err
+	}
+	meta.SetUpdatedTimestamp(&dto.Updated)
+	setLegacyID(meta, dto.ID) // nolint:staticcheck
+	if dto.ParentUID != "" {
+		meta.SetFolder(dto.ParentUID)
+	}
+	f.UID = gapiutil.CalculateClusterWideUID(f)
+	return f, nil
+}
+
+func folderToDTO(f *folderv0alpha1.Folder, orgID int64) (*dtos.Folder, error) {
+	meta, err := utils.MetaAccessor(f)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := &dtos.Folder{
+		ID:        getLegacyID(meta), // nolint:staticcheck
+		UID:       f.Name,
+		OrgID:     orgID,
+		Title:     f.Spec.Title,
+		URL:       dashboards.GetFolderURL(f.Name, slugify.Slugify(f.Spec.Title)),
+		Created:   f.CreationTimestamp.Time,
+		ParentUID: meta.GetFolder(),
+	}
+	updated, err := meta.GetUpdatedTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	if updated != nil {
+		dto.Updated = *updated
+	}
+	return dto, nil
+}
@@ -0,0 +1,103 @@
+package conversion
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	identityv0alpha1 "github.com/grafana/grafana/pkg/apis/identity/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var userConverter = NewConverter(identityv0alpha1.UserResourceInfo.GroupVersionKind(), userToObject, userToDTO)
+
+// userToObject converts the user of GET /api/users/:id, like the legacy storage of the users. The email
+// verification is not part of the DTO, so it is left unset.
+func userToObject(dto *user.UserProfileDTO, namespace string) (*identityv0alpha1.User, error) {
+	u := &identityv0alpha1.User{
+		TypeMeta: identityv0alpha1.UserResourceInfo.TypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              dto.UID,
+			Namespace:         namespace,
+			ResourceVersion:   strconv.FormatInt(dto.UpdatedAt.UnixMilli(), 10),
+			CreationTimestamp: metav1.NewTime(dto.CreatedAt),
+		},
+		Spec: identityv0alpha1.UserSpec{
+			Name:     dto.Name,
+			Login:    dto.Login,
+			Email:    dto.Email,
+			Disabled: dto.IsDisabled,
+		},
+	}
+	meta, err := utils.MetaAccessor(u)
+	if err != nil {
+		return nil, err
+	}
+	meta.SetUpdatedTimestamp(&dto.UpdatedAt)
+	setLegacyID(meta, dto.ID)
+	return u, nil
+}
+
+func userToDTO(u *identityv0alpha1.User, orgID int64) (*user.UserProfileDTO, error) {
+	meta, err := utils.MetaAccessor(u)
+	if err != nil {
+		return nil, err
+	}
+	dto := &user.UserProfileDTO{
+		ID:         getLegacyID(meta),
+		UID:        u.Name,
+		Email:      u.Spec.Email,
+		Name:       u.Spec.Name,
+		Login:      u.Spec.Login,
+		OrgID:      orgID,
+		IsDisabled: u.Spec.Disabled,
+		CreatedAt:  u.CreationTimestamp.Time,
+	}
+	updated, err := meta.GetUpdatedTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	if updated != nil {
+		dto.UpdatedAt = *updated
+	}
+	return dto, nil
+}
+
+var teamConverter = NewConverter(identityv0alpha1.TeamResourceInfo.GroupVersionKind(), teamToObject, teamToDTO)
+
+// teamToObject converts the team of GET /api/teams/:id. The DTO has no timestamps, so they are left unset.
+func teamToObject(dto *team.TeamDTO, namespace string) (*identityv0alpha1.Team, error) {
+	t := &identityv0alpha1.Team{
+		TypeMeta: identityv0alpha1.TeamResourceInfo.TypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dto.UID,
+			Namespace: namespace,
+		},
+		Spec: identityv0alpha1.TeamSpec{
+			Title: dto.Name,
+			Email: dto.Email,
+		},
+	}
+	meta, err := utils.MetaAccessor(t)
+	if err != nil {
+		return nil, err
+	}
+	setLegacyID(meta, dto.ID)
+	return t, nil
+}
+
+func teamToDTO(t *identityv0alpha1.Team, orgID int64) (*team.TeamDTO, error) {
+	meta, err := utils.MetaAccessor(t)
+	if err != nil {
+		return nil, err
+	}
+	return &team.TeamDTO{
+		ID:    getLegacyID(meta),
+		UID:   t.Name,
+		OrgID: orgID,
+		Name:  t.Spec.Title,
+		Email: t.Spec.Email,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const orgAliasCacheTTL = time.Minute
+
+var (
+	ErrInvalidNamespace  = errors.New("invalid namespace")
+	ErrNamespaceNotFound = errors.New("namespace not found")
+
+	// namespacePathPattern matches the paths of the namespaced resources: /apis/<group>/<version>/[watch/]namespaces/<namespace>/...
+	namespacePathPattern = regexp.MustCompile(`^(/apis/[^/]+/[^/]+/(?:watch/)?namespaces/)([^/]+)(/.*)?$`)
+)
+
+// OrgLookup returns the ID of the organization with the given name, or 0 when there is none.
+type OrgLookup func(ctx context.Context, name string) (int64, error)
+
+// NamespaceResolver resolves the namespaces of the requests. Besides the namespaces formatted by the
+// NamespaceMapper, it accepts stack-<id> for the namespace of a stack, and org-<name> for the namespace
+// of an organization, looked up by name. These aliases are resolved to the namespace formatted by the
+// NamespaceMapper, so the objects of an organization always share the same namespace.
+type NamespaceResolver struct {
+	mapper NamespaceMapper
+	lookup OrgLookup
+	cache  *localcache.CacheService
+}
+
+// NewNamespaceResolver returns a resolver looking up the org-<name> aliases with lookup. When lookup
+// is nil, the aliases of the organizations are not supported.
+func NewNamespaceResolver(cfg *setting.Cfg, lookup OrgLookup) *NamespaceResolver {
+	return &NamespaceResolver{
+		mapper: GetNamespaceMapper(cfg),
+		lookup: lookup,
+		cache:  localcache.New(orgAliasCacheTTL, 2*orgAliasCacheTTL),
+	}
+}
+
+// Resolve validates a namespace, and returns its info. The value of the info is the namespace in the
+// format of the NamespaceMapper.
+func (r *NamespaceResolver) Resolve(ctx context.Context, ns string) (claims.NamespaceInfo, error) {
+	if ns == "" {
+		return claims.ParseNamespace(ns)
+	}
+	if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+		return claims.NamespaceInfo{}, fmt.Errorf("%w %q: %s", ErrInvalidNamespace, ns, strings.Join(errs, ", "))
+	}
+
+	switch {
+	case strings.HasPrefix(ns, "stack-"):
+		stackID, err := strconv.ParseInt(strings.TrimPrefix(ns, "stack-"), 10, 64)
+		if err != nil || stackID < 1 {
+			return claims.NamespaceInfo{}, fmt.Errorf("%w %q: invalid stack id", ErrInvalidNamespace, ns)
+		}
+		ns = claims.CloudNamespaceFormatter(stackID)
+
+	case strings.HasPrefix(ns, "org-"):
+		name := strings.TrimPrefix(ns, "org-")
+		if _, err := strconv.ParseInt(name, 10, 64); err == nil {
+			break
+		}
+		orgID, err := r.lookupOrg(ctx, name)
+		if err != nil {
+			return claims.NamespaceInfo{}, err
+		}
+		if orgID < 1 {
+			return claims.NamespaceInfo{}, fmt.Errorf("%w: no organization named %q", ErrNamespaceNotFound, name)
+		}
+		ns = r.mapper(orgID)
+	}
+
+	info, err := claims.ParseNamespace(ns)
+	if err != nil {
+		return info, fmt.Errorf("%w %q: %s", ErrInvalidNamespace, ns, err.Error())
+	}
+	return info, nil
+}
+
+func (r *NamespaceResolver) lookupOrg(ctx context.Context, name string) (int64, error) {
+	if r.lookup == nil {
+		return 0, nil
+	}
+	if cached, ok := r.cache.Get(name); ok {
+		return cached.(int64), nil
+	}
+	orgID, err := r.lookup(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	// the missing organizations are cached too, so the unknown aliases don't hit the database
+	r.cache.SetDefault(name, orgID)
+	return orgID, nil
+}
+
+// Middleware rewrites the namespaces of the request paths in the format of the NamespaceMapper, so the
+// requests can use the aliases of the namespaces. The requests to invalid namespaces are rejected. It must
+// run after the requests are authenticated.
+func (r *NamespaceResolver) Middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		matches := namespacePathPattern.FindStringSubmatch(req.URL.Path)
+		if matches == nil {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		info, err := r.Resolve(req.Context(), matches[2])
+		if err != nil {
+			writeNamespaceError(w, matches[2], err)
+			return
+		}
+		if info.Value != matches[2] {
+			req.URL.Path = matches[1] + info.Value + matches[3]
+			req.URL.RawPath = ""
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func writeNamespaceError(w http.ResponseWriter, ns string, err error) {
+	var statusErr *apierrors.StatusError
+	switch {
+	case errors.Is(err, ErrInvalidNamespace):
+		statusErr = apierrors.NewBadRequest(err.Error())
+	case errors.Is(err, ErrNamespaceNotFound):
+		statusErr = apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, ns)
+	default:
+		statusErr = apierrors.NewInternalError(err)
+	}
+	status := statusErr.Status()
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(status.Code))
+	_ = json.NewEncoder(w).Encode(status)
+}
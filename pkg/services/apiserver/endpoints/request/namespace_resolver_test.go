@@ -0,0 +1,101 @@
+package request_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNamespaceResolver(t *testing.T) {
+	lookups := 0
+	resolver := request.NewNamespaceResolver(setting.NewCfg(), func(ctx context.Context, name string) (int64, error) {
+		lookups++
+		if name == "main" {
+			return 1, nil
+		}
+		if name == "sales" {
+			return 3, nil
+		}
+		return 0, nil
+	})
+	ctx := context.Background()
+
+	t.Run("should keep the namespaces of the mapper", func(t *testing.T) {
+		info, err := resolver.Resolve(ctx, "default")
+		require.NoError(t, err)
+		require.Equal(t, "default", info.Value)
+		require.Equal(t, int64(1), info.OrgID)
+
+		info, err = resolver.Resolve(ctx, "org-2")
+		require.NoError(t, err)
+		require.Equal(t, "org-2", info.Value)
+		require.Equal(t, int64(2), info.OrgID)
+	})
+
+	t.Run("should resolve the stack aliases", func(t *testing.T) {
+		info, err := resolver.Resolve(ctx, "stack-123")
+		require.NoError(t, err)
+		require.Equal(t, "stack-123", info.Value)
+		require.Equal(t, int64(123), info.StackID)
+
+		_, err = resolver.Resolve(ctx, "stack-abc")
+		require.ErrorIs(t, err, request.ErrInvalidNamespace)
+	})
+
+	t.Run("should resolve the organization aliases", func(t *testing.T) {
+		info, err := resolver.Resolve(ctx, "org-sales")
+		require.NoError(t, err)
+		require.Equal(t, "org-3", info.Value)
+		require.Equal(t, int64(3), info.OrgID)
+
+		info, err = resolver.Resolve(ctx, "org-main")
+		require.NoError(t, err)
+		require.Equal(t, "default", info.Value)
+
+		_, err = resolver.Resolve(ctx, "org-unknown")
+		require.ErrorIs(t, err, request.ErrNamespaceNotFound)
+
+		// the aliases are cached
+		lookups = 0
+		_, _ = resolver.Resolve(ctx, "org-sales")
+		_, _ = resolver.Resolve(ctx, "org-unknown")
+		require.Equal(t, 0, lookups)
+	})
+
+	t.Run("should reject the invalid namespaces", func(t *testing.T) {
+		_, err := resolver.Resolve(ctx, "Org_Sales")
+		require.ErrorIs(t, err, request.ErrInvalidNamespace)
+	})
+
+	t.Run("should rewrite the aliases of the request paths", func(t *testing.T) {
+		var path string
+		handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+		}))
+
+		serve := func(p string) *httptest.ResponseRecorder {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, p, nil))
+			return rr
+		}
+
+		serve("/apis/dashboard.grafana.app/v0alpha1/namespaces/org-sales/dashboards/abc")
+		require.Equal(t, "/apis/dashboard.grafana.app/v0alpha1/namespaces/org-3/dashboards/abc", path)
+
+		serve("/apis/dashboard.grafana.app/v0alpha1/namespaces/stack-5")
+		require.Equal(t, "/apis/dashboard.grafana.app/v0alpha1/namespaces/stack-5", path)
+
+		serve("/apis/dashboard.grafana.app/v0alpha1/dashboards")
+		require.Equal(t, "/apis/dashboard.grafana.app/v0alpha1/dashboards", path)
+
+		rr := serve("/apis/dashboard.grafana.app/v0alpha1/namespaces/org-unknown/dashboards")
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), `"reason":"NotFound"`)
+	})
+}
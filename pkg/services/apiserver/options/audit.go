@@ -0,0 +1,73 @@
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+)
+
+// AuditOptions complete the Kubernetes audit options of the recommended options. The audit events are
+// written to the log file and sent to the webhook configured there, following the rules of the policy
+// file. When the audit is enabled without a policy file, every request is recorded at the default level.
+type AuditOptions struct {
+	DefaultLevel string
+	// PolicyPath is where the policy of the default level is written.
+	PolicyPath string
+}
+
+func NewAuditOptions() *AuditOptions {
+	return &AuditOptions{
+		DefaultLevel: string(auditinternal.LevelMetadata),
+	}
+}
+
+func (o *AuditOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.DefaultLevel, "grafana-apiserver-audit-default-level", o.DefaultLevel, "Level of the audit events when no audit policy file is given: None, Metadata, Request or RequestResponse")
+	fs.StringVar(&o.PolicyPath, "grafana-apiserver-audit-default-policy-path", o.PolicyPath, "Path the audit policy of the default level is written to")
+}
+
+func (o *AuditOptions) Validate() []error {
+	switch auditinternal.Level(o.DefaultLevel) {
+	case auditinternal.LevelNone, auditinternal.LevelMetadata, auditinternal.LevelRequest, auditinternal.LevelRequestResponse:
+		return nil
+	}
+	return []error{fmt.Errorf("--grafana-apiserver-audit-default-level must be one of %s, %s, %s, %s",
+		auditinternal.LevelNone, auditinternal.LevelMetadata, auditinternal.LevelRequest, auditinternal.LevelRequestResponse)}
+}
+
+func (o *AuditOptions) ApplyTo(audit *genericoptions.AuditOptions, serverConfig *genericapiserver.RecommendedConfig) error {
+	if audit == nil {
+		return nil
+	}
+
+	enabled := audit.LogOptions.Path != "" || audit.WebhookOptions.ConfigFile != ""
+	if enabled && audit.PolicyFile == "" && o.PolicyPath != "" {
+		if err := writeDefaultAuditPolicy(o.PolicyPath, o.DefaultLevel); err != nil {
+			return err
+		}
+		audit.PolicyFile = o.PolicyPath
+	}
+	return audit.ApplyTo(&serverConfig.Config)
+}
+
+// writeDefaultAuditPolicy writes a policy recording all the requests at the given level, once they
+// are answered.
+func writeDefaultAuditPolicy(path string, level string) error {
+	policy := fmt.Sprintf(`apiVersion: audit.k8s.io/v1
+kind: Policy
+omitStages:
+  - RequestReceived
+rules:
+  - level: %s
+`, level)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(policy), 0o600)
+}
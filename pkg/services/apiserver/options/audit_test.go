@@ -0,0 +1,52 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestAuditOptions(t *testing.T) {
+	newConfig := func() *genericapiserver.RecommendedConfig {
+		return genericapiserver.NewRecommendedConfig(serializer.NewCodecFactory(scheme.Scheme))
+	}
+
+	t.Run("writes the default policy when the audit is enabled without policy", func(t *testing.T) {
+		dir := t.TempDir()
+		o := &AuditOptions{DefaultLevel: "Request", PolicyPath: filepath.Join(dir, "audit", "policy.yaml")}
+		audit := genericoptions.NewAuditOptions()
+		audit.LogOptions.Path = filepath.Join(dir, "audit.log")
+
+		config := newConfig()
+		require.NoError(t, o.ApplyTo(audit, config))
+		require.Equal(t, o.PolicyPath, audit.PolicyFile)
+		require.NotNil(t, config.AuditBackend)
+		require.NotNil(t, config.AuditPolicyRuleEvaluator)
+
+		policy, err := os.ReadFile(o.PolicyPath)
+		require.NoError(t, err)
+		require.Contains(t, string(policy), "level: Request")
+	})
+
+	t.Run("does not record anything when the audit is not enabled", func(t *testing.T) {
+		o := &AuditOptions{DefaultLevel: "Metadata", PolicyPath: filepath.Join(t.TempDir(), "policy.yaml")}
+		audit := genericoptions.NewAuditOptions()
+
+		config := newConfig()
+		require.NoError(t, o.ApplyTo(audit, config))
+		require.Empty(t, audit.PolicyFile)
+		require.Nil(t, config.AuditBackend)
+		require.NoFileExists(t, o.PolicyPath)
+	})
+
+	t.Run("validates the default level", func(t *testing.T) {
+		require.Empty(t, (&AuditOptions{DefaultLevel: "RequestResponse"}).Validate())
+		require.NotEmpty(t, (&AuditOptions{DefaultLevel: "Everything"}).Validate())
+	})
+}
@@ -0,0 +1,77 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/grafana/grafana/pkg/apiserver/endpoints/filters"
+)
+
+// FlowControlOptions limit the concurrent requests to the API groups, to protect the resource store
+// from bursts of requests, e.g. when many informers list the resources at once.
+type FlowControlOptions struct {
+	Enabled            bool
+	Seats              int
+	GroupShares        map[string]int
+	DefaultGroupShares int
+	UserShare          float64
+	QueueLength        int
+	QueueTimeout       time.Duration
+}
+
+func NewFlowControlOptions() *FlowControlOptions {
+	return &FlowControlOptions{
+		Enabled:            false,
+		Seats:              100,
+		GroupShares:        map[string]int{},
+		DefaultGroupShares: 10,
+		UserShare:          0.5,
+		QueueLength:        200,
+		QueueTimeout:       15 * time.Second,
+	}
+}
+
+func (o *FlowControlOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "grafana-apiserver-flow-control", o.Enabled, "Limit the concurrent requests to the API groups")
+	fs.IntVar(&o.Seats, "grafana-apiserver-flow-control-seats", o.Seats, "Number of requests served concurrently, shared by the API groups")
+	fs.StringToIntVar(&o.GroupShares, "grafana-apiserver-flow-control-group-shares", o.GroupShares, "Concurrency shares of the API groups, e.g. dashboard.grafana.app=20")
+	fs.IntVar(&o.DefaultGroupShares, "grafana-apiserver-flow-control-default-group-shares", o.DefaultGroupShares, "Concurrency shares of the API groups without configured shares")
+	fs.Float64Var(&o.UserShare, "grafana-apiserver-flow-control-user-share", o.UserShare, "Fraction of the seats of an API group a single user can use")
+	fs.IntVar(&o.QueueLength, "grafana-apiserver-flow-control-queue-length", o.QueueLength, "Number of requests to an API group waiting for a seat")
+	fs.DurationVar(&o.QueueTimeout, "grafana-apiserver-flow-control-queue-timeout", o.QueueTimeout, "How long a request waits for a seat before it is rejected")
+}
+
+func (o *FlowControlOptions) Validate() []error {
+	if !o.Enabled {
+		return nil
+	}
+
+	errs := []error{}
+	if o.Seats < 1 {
+		errs = append(errs, fmt.Errorf("--grafana-apiserver-flow-control-seats must be at least 1"))
+	}
+	if o.UserShare <= 0 || o.UserShare > 1 {
+		errs = append(errs, fmt.Errorf("--grafana-apiserver-flow-control-user-share must be greater than 0 and at most 1"))
+	}
+	if o.QueueLength < 0 {
+		errs = append(errs, fmt.Errorf("--grafana-apiserver-flow-control-queue-length must not be negative"))
+	}
+	return errs
+}
+
+// Config returns the configuration of the flow control filter, or nil when it is disabled.
+func (o *FlowControlOptions) Config() *filters.FlowControlConfig {
+	if o == nil || !o.Enabled {
+		return nil
+	}
+	return &filters.FlowControlConfig{
+		Seats:              o.Seats,
+		GroupShares:        o.GroupShares,
+		DefaultGroupShares: o.DefaultGroupShares,
+		UserShare:          o.UserShare,
+		QueueLength:        o.QueueLength,
+		QueueTimeout:       o.QueueTimeout,
+	}
+}
@@ -24,6 +24,8 @@ type Options struct {
 	KubeAggregatorOptions    *KubeAggregatorOptions
 	StorageOptions           *StorageOptions
 	ExtraOptions             *ExtraOptions
+	AuditOptions             *AuditOptions
+	FlowControlOptions       *FlowControlOptions
 	APIOptions               []OptionsProvider
 }
 
@@ -34,6 +36,8 @@ func NewOptions(codec runtime.Codec) *Options {
 		KubeAggregatorOptions:    NewAggregatorServerOptions(),
 		StorageOptions:           NewStorageOptions(),
 		ExtraOptions:             NewExtraOptions(),
+		AuditOptions:             NewAuditOptions(),
+		FlowControlOptions:       NewFlowControlOptions(),
 	}
 }
 
@@ -43,6 +47,8 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	o.KubeAggregatorOptions.AddFlags(fs)
 	o.StorageOptions.AddFlags(fs)
 	o.ExtraOptions.AddFlags(fs)
+	o.AuditOptions.AddFlags(fs)
+	o.FlowControlOptions.AddFlags(fs)
 
 	for _, api := range o.APIOptions {
 		api.AddFlags(fs)
@@ -70,6 +76,18 @@ func (o *Options) Validate() []error {
 		return errs
 	}
 
+	if errs := o.FlowControlOptions.Validate(); len(errs) != 0 {
+		return errs
+	}
+
+	if errs := o.AuditOptions.Validate(); len(errs) != 0 {
+		return errs
+	}
+
+	if errs := o.RecommendedOptions.Audit.Validate(); len(errs) != 0 {
+		return errs
+	}
+
 	if o.ExtraOptions.DevMode {
 		// NOTE: Only consider authn for dev mode - resolves the failure due to missing extension apiserver auth-config
 		// in parent k8s
@@ -120,7 +138,8 @@ func (o *Options) ApplyTo(serverConfig *genericapiserver.RecommendedConfig) erro
 		}
 		serverConfig.SecureServing = nil
 	}
-	return nil
+
+	return o.AuditOptions.ApplyTo(o.RecommendedOptions.Audit, serverConfig)
 }
 
 func NewRecommendedOptions(codec runtime.Codec) *genericoptions.RecommendedOptions {
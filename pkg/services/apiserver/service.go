@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
 	"k8s.io/apiserver/pkg/endpoints/responsewriter"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	clientrest "k8s.io/client-go/rest"
@@ -36,15 +38,19 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/registry/apis/datasource"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	kubeaggregator "github.com/grafana/grafana/pkg/services/apiserver/aggregator"
 	"github.com/grafana/grafana/pkg/services/apiserver/auth/authenticator"
 	"github.com/grafana/grafana/pkg/services/apiserver/auth/authorizer"
 	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/services/apiserver/conversion"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	grafanaapiserveroptions "github.com/grafana/grafana/pkg/services/apiserver/options"
 	"github.com/grafana/grafana/pkg/services/apiserver/utils"
+	"github.com/grafana/grafana/pkg/services/authn"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/health"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -121,6 +127,7 @@ type service struct {
 	metrics prometheus.Registerer
 
 	authorizer        *authorizer.GrafanaAuthorizer
+	namespaces        *request.NamespaceResolver
 	serverLockService builder.ServerLockService
 	kvStore           kvstore.KVStore
 
@@ -128,6 +135,8 @@ type service struct {
 	datasources     datasource.ScopedPluginDatasourceProvider
 	contextProvider datasource.PluginContextWrapper
 	pluginStore     pluginstore.Store
+	authnService    authn.Service
+	health          *health.Service
 }
 
 func ProvideService(
@@ -143,6 +152,9 @@ func ProvideService(
 	datasources datasource.ScopedPluginDatasourceProvider,
 	contextProvider datasource.PluginContextWrapper,
 	pluginStore pluginstore.Store,
+	authnService authn.Service,
+	accessControl accesscontrol.AccessControl,
+	healthService *health.Service,
 ) (*service, error) {
 	s := &service{
 		cfg:               cfg,
@@ -151,7 +163,8 @@ func ProvideService(
 		startedCh:         make(chan struct{}),
 		stopCh:            make(chan struct{}),
 		builders:          []builder.APIGroupBuilder{},
-		authorizer:        authorizer.NewGrafanaAuthorizer(cfg, orgService),
+		authorizer:        authorizer.NewGrafanaAuthorizer(cfg, orgService, accessControl),
+		namespaces:        request.NewNamespaceResolver(cfg, orgLookup(orgService)),
 		tracing:           tracing,
 		db:                db, // For Unified storage
 		metrics:           metrics.ProvideRegisterer(),
@@ -161,6 +174,8 @@ func ProvideService(
 		contextProvider:   contextProvider,
 		pluginStore:       pluginStore,
 		serverLockService: serverLockService,
+		authnService:      authnService,
+		health:            healthService,
 	}
 
 	// This will be used when running as a dskit service
@@ -189,7 +204,9 @@ func ProvideService(
 			}
 
 			resp := responsewriter.WrapForHTTP1Or2(c.Resp)
-			s.handler.ServeHTTP(resp, req)
+			// the aliases of the namespaces are resolved once the request is authenticated, so that anonymous
+			// requests can't find out which organizations exist
+			s.namespaces.Middleware(s.handler).ServeHTTP(resp, req)
 		}
 		k8sRoute.Any("/", middleware.ReqSignedIn, handler)
 		k8sRoute.Any("/*", middleware.ReqSignedIn, handler)
@@ -202,6 +219,8 @@ func ProvideService(
 	s.rr.Group("/openapi", proxyHandler)
 	s.rr.Group("/version", proxyHandler)
 
+	s.rr.Get("/api/apiserver/authorization", middleware.ReqSignedIn, routing.Wrap(s.explainAuthorization))
+
 	return s, nil
 }
 
@@ -252,6 +271,11 @@ func (s *service) start(ctx context.Context) error {
 		}
 	}
 
+	// Register the conversions to the DTOs of the legacy API, so both APIs can be served from the same storage
+	if err := conversion.NewRegistry().AddToScheme(Scheme); err != nil {
+		return err
+	}
+
 	o := grafanaapiserveroptions.NewOptions(Codecs.LegacyCodec(groupVersions...))
 	err := applyGrafanaConfig(s.cfg, s.features, o)
 	if err != nil {
@@ -268,7 +292,10 @@ func (s *service) start(ctx context.Context) error {
 		return err
 	}
 	serverConfig.Authorization.Authorizer = s.authorizer
-	serverConfig.Authentication.Authenticator = authenticator.NewAuthenticator(serverConfig.Authentication.Authenticator)
+	serverConfig.Authentication.Authenticator = authenticator.NewAuthenticator(
+		bearertoken.New(authenticator.NewServiceAccountTokenAuthenticator(s.authnService)),
+		serverConfig.Authentication.Authenticator,
+	)
 	serverConfig.TracerProvider = s.tracing.GetTracerProvider()
 
 	// setup loopback transport for the aggregator server
@@ -291,6 +318,7 @@ func (s *service) start(ctx context.Context) error {
 			return err
 		}
 		client := resource.NewLocalResourceStoreClient(server)
+		s.registerStorageHealth(server.IsHealthy)
 		serverConfig.Config.RESTOptionsGetter = apistore.NewRESTOptionsGetterForClient(client,
 			o.RecommendedOptions.Etcd.StorageConfig)
 
@@ -307,6 +335,9 @@ func (s *service) start(ctx context.Context) error {
 
 		// Create a client instance
 		client := resource.NewResourceStoreClientGRPC(conn)
+		s.registerStorageHealth(func(ctx context.Context, req *resource.HealthCheckRequest) (*resource.HealthCheckResponse, error) {
+			return resource.NewDiagnosticsClient(conn).IsHealthy(ctx, req)
+		})
 		serverConfig.Config.RESTOptionsGetter = apistore.NewRESTOptionsGetterForClient(client, o.RecommendedOptions.Etcd.StorageConfig)
 
 	case grafanaapiserveroptions.StorageTypeLegacy:
@@ -324,6 +355,7 @@ func (s *service) start(ctx context.Context) error {
 		Scheme,
 		serverConfig,
 		builders,
+		o.FlowControlOptions.Config(),
 		s.cfg.BuildStamp,
 		s.cfg.BuildVersion,
 		s.cfg.BuildCommit,
@@ -394,6 +426,24 @@ func (s *service) start(ctx context.Context) error {
 	return nil
 }
 
+// registerStorageHealth registers unified storage as a critical dependency for the readiness of Grafana.
+func (s *service) registerStorageHealth(isHealthy func(context.Context, *resource.HealthCheckRequest) (*resource.HealthCheckResponse, error)) {
+	s.health.Register(health.Dependency{
+		Name:     "unified-storage",
+		Critical: true,
+		Checker: health.CheckerFunc(func(ctx context.Context) error {
+			res, err := isHealthy(ctx, &resource.HealthCheckRequest{})
+			if err != nil {
+				return err
+			}
+			if res.Status != resource.HealthCheckResponse_SERVING {
+				return fmt.Errorf("unified storage is %s", res.Status)
+			}
+			return nil
+		}),
+	})
+}
+
 func (s *service) startCoreServer(
 	ctx context.Context,
 	transport *roundTripperFunc,
@@ -538,6 +588,20 @@ func ensureKubeConfig(restConfig *clientrest.Config, dir string) error {
 	)
 }
 
+// orgLookup looks up the organizations of the org-<name> namespaces.
+func orgLookup(orgService org.Service) request.OrgLookup {
+	return func(ctx context.Context, name string) (int64, error) {
+		o, err := orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: name})
+		if errors.Is(err, org.ErrOrgNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		return o.ID, nil
+	}
+}
+
 type roundTripperFunc struct {
 	ready chan struct{}
 	fn    func(req *http.Request) (*http.Response, error)
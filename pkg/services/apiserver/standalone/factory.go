@@ -7,11 +7,13 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 
 	"github.com/grafana/grafana/pkg/apis/datasource/v0alpha1"
+	identityv0 "github.com/grafana/grafana/pkg/apis/identity/v0alpha1"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry/apis/datasource"
@@ -43,13 +45,21 @@ type APIServerFactory interface {
 
 // Zero dependency provider for testing
 func GetDummyAPIFactory() APIServerFactory {
-	return &DummyAPIFactory{}
+	return &DummyAPIFactory{
+		identity: &IdentityOptions{},
+	}
 }
 
-type DummyAPIFactory struct{}
+type DummyAPIFactory struct {
+	identity *IdentityOptions
+	conns    []*grpc.ClientConn
+}
 
 func (p *DummyAPIFactory) GetOptions() options.OptionsProvider {
-	return nil
+	if p.identity == nil {
+		return nil
+	}
+	return p.identity
 }
 
 func (p *DummyAPIFactory) GetOptionalMiddlewares(_ tracing.Tracer) []web.Middleware {
@@ -106,12 +116,20 @@ func (p *DummyAPIFactory) MakeAPIServer(_ context.Context, tracer tracing.Tracer
 			&actest.FakeAccessControl{ExpectedEvaluate: true},
 			true, // show query types
 		)
+
+	// Reads the legacy identities from Grafana over gRPC
+	case identityv0.GROUP:
+		return p.makeIdentityAPIBuilder()
 	}
 
 	return nil, fmt.Errorf("unsupported group")
 }
 
-func (p *DummyAPIFactory) Shutdown() {}
+func (p *DummyAPIFactory) Shutdown() {
+	for _, conn := range p.conns {
+		_ = conn.Close()
+	}
+}
 
 // Simple stub for standalone datasource testing
 type pluginDatasourceImpl struct {
@@ -0,0 +1,122 @@
+package standalone
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+
+	"github.com/grafana/grafana/pkg/registry/apis/identity"
+	"github.com/grafana/grafana/pkg/registry/apis/identity/legacy"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+	"github.com/grafana/grafana/pkg/services/apiserver/options"
+)
+
+var _ options.OptionsProvider = (*IdentityOptions)(nil)
+
+// IdentityOptions configures the connection of the identity group to the Grafana gRPC server, which
+// serves the legacy users, teams and service accounts. The connection uses TLS unless LegacyInsecure is set,
+// since the requests carry the service account token.
+type IdentityOptions struct {
+	LegacyAddress       string
+	LegacyToken         string
+	LegacyTLSCAFile     string
+	LegacyTLSServerName string
+	LegacyInsecure      bool
+}
+
+func (o *IdentityOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.LegacyAddress, "grafana.identity.legacy-address", "", "Address of the Grafana gRPC server serving the legacy identities, e.g. localhost:10000.")
+	fs.StringVar(&o.LegacyToken, "grafana.identity.legacy-token", "", "Service account token used to authenticate to the Grafana gRPC server.")
+	fs.StringVar(&o.LegacyTLSCAFile, "grafana.identity.legacy-tls-ca-file", "", "CA certificates file used to verify the certificate of the Grafana gRPC server, the system CAs by default.")
+	fs.StringVar(&o.LegacyTLSServerName, "grafana.identity.legacy-tls-server-name", "", "Server name expected in the certificate of the Grafana gRPC server, the host of the address by default.")
+	fs.BoolVar(&o.LegacyInsecure, "grafana.identity.legacy-insecure", false, "Connect to the Grafana gRPC server without TLS, only for local development: the token is sent in plain text.")
+}
+
+func (o *IdentityOptions) ValidateOptions() []error {
+	if o.LegacyAddress == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(o.LegacyAddress); err != nil {
+		return []error{fmt.Errorf("--grafana.identity.legacy-address must be a valid network address: %v", err)}
+	}
+	if o.LegacyInsecure && (o.LegacyTLSCAFile != "" || o.LegacyTLSServerName != "") {
+		return []error{fmt.Errorf("--grafana.identity.legacy-insecure can't be used with the TLS options")}
+	}
+	return nil
+}
+
+// transportCredentials returns the credentials of the connection to the Grafana gRPC server.
+func (o *IdentityOptions) transportCredentials() (credentials.TransportCredentials, error) {
+	if o.LegacyInsecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.LegacyTLSServerName,
+	}
+	if o.LegacyTLSCAFile != "" {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path comes from the command line
+		pem, err := os.ReadFile(o.LegacyTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --grafana.identity.legacy-tls-ca-file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in --grafana.identity.legacy-tls-ca-file %q", o.LegacyTLSCAFile)
+		}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+func (o *IdentityOptions) ApplyTo(config *genericapiserver.RecommendedConfig) error {
+	return nil
+}
+
+func (p *DummyAPIFactory) makeIdentityAPIBuilder() (builder.APIGroupBuilder, error) {
+	if p.identity == nil || p.identity.LegacyAddress == "" {
+		return nil, fmt.Errorf("--grafana.identity.legacy-address is required to run the identity group")
+	}
+
+	creds, err := p.identity.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(p.identity.LegacyAddress,
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(tokenCredentials{token: p.identity.LegacyToken, secure: !p.identity.LegacyInsecure}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.conns = append(p.conns, conn)
+
+	return identity.NewStandaloneAPIBuilder(legacy.NewGRPCClient(conn)), nil
+}
+
+// tokenCredentials authenticates the requests to the Grafana gRPC server with a service account token. The
+// token is only sent over TLS, unless the connection was explicitly made insecure.
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (t tokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return t.secure
+}
@@ -41,6 +41,7 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	o.MetricsOptions.AddFlags(fs)
 	o.ProfilingOptions.AddFlags(fs)
 	o.ServerRunOptions.AddUniversalFlags(fs)
+	o.StorageOptions.AddFlags(fs)
 }
 
 func (o *Options) Validate() []error {
@@ -68,6 +69,10 @@ func (o *Options) Validate() []error {
 		return errs
 	}
 
+	if errs := o.StorageOptions.Validate(); len(errs) != 0 {
+		return errs
+	}
+
 	// NOTE: we don't call validate on the top level recommended options as it doesn't like skipping etcd-servers
 	// the function is left here for troubleshooting any other config issues
 	// errors = append(errors, o.RecommendedOptions.Validate()...)
@@ -0,0 +1,202 @@
+// Package auditlog records the mutating HTTP requests, with the identity that sent them, the resources
+// they change and their outcome, to files, Loki, syslog or webhooks.
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// maxBatchSize is the maximum number of events written to the sinks at once.
+	maxBatchSize = 500
+	// writeTimeout is the timeout of the writes of a batch of events to a sink.
+	writeTimeout = 30 * time.Second
+	// redactedValue replaces the values of the redacted fields.
+	redactedValue = "[REDACTED]"
+)
+
+// record is an event encoded as a JSON line.
+type record struct {
+	time time.Time
+	line []byte
+}
+
+// sink writes the audit events to a destination.
+type sink interface {
+	name() string
+	write(ctx context.Context, records []record) error
+	close() error
+}
+
+type Service struct {
+	settings setting.AuditLogSettings
+	sinks    []sink
+	records  chan record
+	redacted map[string]bool
+	metrics  *metrics
+	log      log.Logger
+	now      func() time.Time
+	sample   func() float64
+}
+
+func ProvideService(cfg *setting.Cfg, registerer prometheus.Registerer) (*Service, error) {
+	s := &Service{
+		settings: cfg.AuditLog,
+		redacted: make(map[string]bool, len(cfg.AuditLog.RedactedFields)),
+		metrics:  newMetrics(registerer),
+		log:      log.New("audit-log"),
+		now:      time.Now,
+		sample:   rand.Float64,
+	}
+	for _, field := range s.settings.RedactedFields {
+		s.redacted[strings.ToLower(field)] = true
+	}
+	if !s.settings.Enabled {
+		return s, nil
+	}
+
+	for _, name := range s.settings.Sinks {
+		sk, err := newSink(name, s.settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure audit log sink %s: %w", name, err)
+		}
+		s.sinks = append(s.sinks, sk)
+	}
+	s.records = make(chan record, max(s.settings.BufferSize, 1))
+	return s, nil
+}
+
+func newSink(name string, settings setting.AuditLogSettings) (sink, error) {
+	switch name {
+	case "file":
+		return newFileSink(settings.File)
+	case "loki":
+		return newLokiSink(settings.Loki)
+	case "syslog":
+		return newSyslogSink(settings.Syslog)
+	case "webhook":
+		return newWebhookSink(settings.Webhook)
+	default:
+		return nil, errors.New("unknown sink")
+	}
+}
+
+// Enabled returns true if the mutating requests are recorded.
+func (s *Service) Enabled() bool {
+	return s != nil && s.settings.Enabled
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Enabled()
+}
+
+// Run writes the recorded events to the sinks in batches, until Grafana stops.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]record, 0, maxBatchSize)
+	for {
+		select {
+		case r := <-s.records:
+			batch = append(batch, r)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			// write the events recorded before Grafana stopped
+			for len(s.records) > 0 {
+				batch = append(batch, <-s.records)
+			}
+			s.flush(context.Background(), batch)
+			for _, sk := range s.sinks {
+				if err := sk.close(); err != nil {
+					s.log.Warn("Failed to close audit log sink", "sink", sk.name(), "error", err)
+				}
+			}
+			return nil
+		}
+
+		s.flush(ctx, batch)
+		batch = batch[:0]
+	}
+}
+
+func (s *Service) flush(ctx context.Context, batch []record) {
+	if len(batch) == 0 {
+		return
+	}
+	for _, sk := range s.sinks {
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		if err := sk.write(writeCtx, batch); err != nil {
+			s.metrics.sinkErrors.WithLabelValues(sk.name()).Inc()
+			s.log.Error("Failed to write audit events", "sink", sk.name(), "events", len(batch), "error", err)
+		}
+		cancel()
+	}
+}
+
+// Record queues an event to be written to the sinks. Events are dropped when the sinks don't keep up,
+// rather than slowing down the requests.
+func (s *Service) Record(e *Event) {
+	if !s.Enabled() {
+		return
+	}
+
+	line, err := s.encode(e)
+	if err != nil {
+		s.log.Warn("Failed to encode audit event", "path", e.Path, "error", err)
+		return
+	}
+
+	select {
+	case s.records <- record{time: e.Time, line: line}:
+		s.metrics.events.WithLabelValues("recorded").Inc()
+	default:
+		s.metrics.events.WithLabelValues("dropped").Inc()
+	}
+}
+
+// encode returns the JSON of an event with the values of the redacted fields replaced.
+func (s *Service) encode(e *Event) ([]byte, error) {
+	line, err := json.Marshal(e)
+	if err != nil || len(s.redacted) == 0 {
+		return line, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, err
+	}
+	s.redact(fields)
+	return json.Marshal(fields)
+}
+
+func (s *Service) redact(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if s.redacted[strings.ToLower(k)] {
+				v[k] = redactedValue
+				continue
+			}
+			s.redact(field)
+		}
+	case []any:
+		for _, item := range v {
+			s.redact(item)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeSink struct {
+	records []record
+}
+
+func (s *fakeSink) name() string { return "fake" }
+
+func (s *fakeSink) write(_ context.Context, records []record) error {
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *fakeSink) close() error { return nil }
+
+func newTestService(settings setting.AuditLogSettings) *Service {
+	settings.Enabled = true
+	s := &Service{
+		settings: settings,
+		records:  make(chan record, 10),
+		redacted: map[string]bool{},
+		metrics:  newMetrics(nil),
+		log:      log.NewNopLogger(),
+		now:      time.Now,
+		sample:   func() float64 { return 0.5 },
+	}
+	for _, field := range settings.RedactedFields {
+		s.redacted[strings.ToLower(field)] = true
+	}
+	return s
+}
+
+func recorded(t *testing.T, s *Service) []map[string]any {
+	var events []map[string]any
+	for len(s.records) > 0 {
+		var e map[string]any
+		require.NoError(t, json.Unmarshal((<-s.records).line, &e))
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestMiddleware(t *testing.T) {
+	s := newTestService(setting.AuditLogSettings{
+		SampleRate:         1,
+		RedactedFields:     []string{"password", "userAgent"},
+		IncludeRequestBody: true,
+		MaxBodySize:        1024,
+		ExcludedPaths:      []string{"/api/ds/query"},
+	})
+
+	m := web.New()
+	m.UseMiddleware(s.Middleware())
+	m.Put("/api/datasources/uid/:uid", Resource("datasource", ":uid"), func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"loki","password":"secret"}`, string(body), "the handler reads the whole body")
+		w.WriteHeader(http.StatusOK)
+	})
+	m.Delete("/api/folders/:uid", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	m.Get("/api/folders/:uid", func(w http.ResponseWriter, r *http.Request) {})
+	m.Post("/api/ds/query", func(w http.ResponseWriter, r *http.Request) {})
+
+	serve := func(method, path, body string) {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("User-Agent", "terraform")
+		m.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve(http.MethodPut, "/api/datasources/uid/abc", `{"name":"loki","password":"secret"}`)
+	serve(http.MethodDelete, "/api/folders/xyz", "")
+	serve(http.MethodGet, "/api/folders/xyz", "")
+	serve(http.MethodPost, "/api/ds/query", "{}")
+
+	events := recorded(t, s)
+	require.Len(t, events, 2, "reads and excluded paths aren't recorded")
+
+	require.Equal(t, "PUT", events[0]["method"])
+	require.Equal(t, OutcomeSuccess, events[0]["outcome"])
+	require.Equal(t, []any{map[string]any{"kind": "datasource", "param": ":uid", "id": "abc"}}, events[0]["resources"])
+	require.Equal(t, map[string]any{"name": "loki", "password": redactedValue}, events[0]["body"])
+	require.Equal(t, redactedValue, events[0]["userAgent"])
+
+	require.Equal(t, OutcomeDenied, events[1]["outcome"])
+	require.Equal(t, float64(http.StatusForbidden), events[1]["status"])
+	require.Equal(t, []any{map[string]any{"param": ":uid", "id": "xyz"}}, events[1]["resources"])
+
+	t.Run("samples successful requests only", func(t *testing.T) {
+		s.settings.SampleRate = 0.1
+		serve(http.MethodPut, "/api/datasources/uid/abc", `{"name":"loki","password":"secret"}`)
+		serve(http.MethodDelete, "/api/folders/xyz", "")
+
+		events := recorded(t, s)
+		require.Len(t, events, 1)
+		require.Equal(t, OutcomeDenied, events[0]["outcome"])
+	})
+}
+
+func TestRun(t *testing.T) {
+	s := newTestService(setting.AuditLogSettings{FlushInterval: time.Hour})
+	sk := &fakeSink{}
+	s.sinks = []sink{sk}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	s.Record(&Event{Method: http.MethodPost, Path: "/api/dashboards/db"})
+	cancel()
+	require.NoError(t, <-done)
+	require.Len(t, sk.records, 1, "the recorded events are written when Grafana stops")
+}
+
+func TestSinks(t *testing.T) {
+	records := []record{
+		{time: time.Unix(1700000000, 0), line: []byte(`{"method":"POST"}`)},
+		{time: time.Unix(1700000001, 0), line: []byte(`{"method":"DELETE"}`)},
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "audit.log")
+		sk, err := newFileSink(setting.AuditLogFileSettings{Path: path})
+		require.NoError(t, err)
+		require.NoError(t, sk.write(context.Background(), records))
+		require.NoError(t, sk.close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "{\"method\":\"POST\"}\n{\"method\":\"DELETE\"}\n", string(data))
+	})
+
+	t.Run("loki", func(t *testing.T) {
+		var body map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/loki/api/v1/push", r.URL.Path)
+			require.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		sk, err := newLokiSink(setting.AuditLogLokiSettings{URL: server.URL, TenantID: "tenant", Labels: map[string]string{"job": "grafana_audit"}})
+		require.NoError(t, err)
+		require.NoError(t, sk.write(context.Background(), records))
+		require.Equal(t, map[string]any{"streams": []any{map[string]any{
+			"stream": map[string]any{"job": "grafana_audit"},
+			"values": []any{
+				[]any{"1700000000000000000", `{"method":"POST"}`},
+				[]any{"1700000001000000000", `{"method":"DELETE"}`},
+			},
+		}}}, body)
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		status := http.StatusOK
+		var body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+
+		sk, err := newWebhookSink(setting.AuditLogWebhookSettings{URL: server.URL, Authorization: "Bearer token"})
+		require.NoError(t, err)
+		require.NoError(t, sk.write(context.Background(), records))
+		require.JSONEq(t, `[{"method":"POST"},{"method":"DELETE"}]`, body)
+
+		status = http.StatusInternalServerError
+		require.Error(t, sk.write(context.Background(), records))
+	})
+}
@@ -0,0 +1,28 @@
+package auditlog
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	events     *prometheus.CounterVec
+	sinkErrors *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		events: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "audit_log",
+			Name:      "events_total",
+			Help:      "Number of audit events by result: recorded, or dropped because the buffer is full.",
+		}, []string{"result"}),
+		sinkErrors: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "audit_log",
+			Name:      "sink_errors_total",
+			Help:      "Number of failed writes of audit events by sink.",
+		}, []string{"sink"}),
+	}
+}
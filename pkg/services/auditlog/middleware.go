@@ -0,0 +1,172 @@
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type annotationKey struct{}
+
+// annotation holds the resources declared by the route of a request.
+type annotation struct {
+	resources []resourceRef
+}
+
+type resourceRef struct {
+	kind   string
+	params []string
+}
+
+// Resource annotates a route with the kind of resource it changes, and the route parameters that identify
+// it in the audit events, for example Resource("dashboard", ":uid"). The parameters of the routes that
+// aren't annotated are recorded without a kind.
+func Resource(kind string, params ...string) web.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a, ok := r.Context().Value(annotationKey{}).(*annotation); ok {
+			a.resources = append(a.resources, resourceRef{kind: kind, params: params})
+		}
+	}
+}
+
+// Middleware records the mutating requests once they are served.
+func (s *Service) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.shouldAudit(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := s.now()
+			var body any
+			if s.settings.IncludeRequestBody {
+				body = s.readBody(r)
+			}
+
+			a := &annotation{}
+			*r = *r.WithContext(context.WithValue(r.Context(), annotationKey{}, a))
+
+			rw := web.Rw(w, r)
+			next.ServeHTTP(rw, r)
+
+			e := &Event{
+				Time:       start.UTC(),
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Status:     rw.Status(),
+				Outcome:    outcome(rw.Status()),
+				DurationMs: s.now().Sub(start).Milliseconds(),
+				RemoteAddr: web.RemoteAddr(r),
+				UserAgent:  r.UserAgent(),
+				TraceID:    tracing.TraceIDFromContext(r.Context(), false),
+				Body:       body,
+			}
+			if e.Outcome == OutcomeSuccess && s.sample() >= s.settings.SampleRate {
+				return
+			}
+
+			// the route handlers replace the request to add the route name
+			req := r
+			if wc := web.FromContext(r.Context()); wc != nil {
+				req = wc.Req
+			}
+			if c := contexthandler.FromContext(r.Context()); c != nil {
+				e.RemoteAddr = c.RemoteAddr()
+				if c.SignedInUser != nil && !c.SignedInUser.IsNil() {
+					e.Actor = Actor{
+						ID:              c.SignedInUser.GetID(),
+						Login:           c.SignedInUser.GetLogin(),
+						Type:            string(c.SignedInUser.GetIdentityType()),
+						AuthenticatedBy: c.SignedInUser.GetAuthenticatedBy(),
+					}
+					e.OrgID = c.SignedInUser.GetOrgID()
+				}
+			}
+			if route, ok := middleware.RouteOperationName(req); ok {
+				e.Route = route
+			}
+			e.Resources = resources(a, web.Params(req))
+
+			s.Record(e)
+		})
+	}
+}
+
+func (s *Service) shouldAudit(r *http.Request) bool {
+	if !s.Enabled() {
+		return false
+	}
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return false
+	}
+	for _, p := range s.settings.ExcludedPaths {
+		if strings.HasPrefix(r.URL.Path, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// readBody returns the JSON body of a request, and restores it for the handler.
+func (s *Service) readBody(r *http.Request) any {
+	if r.Body == nil || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, s.settings.MaxBodySize+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+	if err != nil || int64(len(data)) > s.settings.MaxBodySize {
+		return nil
+	}
+
+	var body any
+	if json.Unmarshal(data, &body) != nil {
+		return nil
+	}
+	return body
+}
+
+func resources(a *annotation, params map[string]string) []Resource {
+	var result []Resource
+	if len(a.resources) > 0 {
+		for _, ref := range a.resources {
+			for _, p := range ref.params {
+				if id := params[p]; id != "" {
+					result = append(result, Resource{Kind: ref.kind, Param: p, ID: id})
+				}
+			}
+		}
+		return result
+	}
+
+	for p, id := range params {
+		if strings.HasPrefix(p, ":") && id != "" {
+			result = append(result, Resource{Param: p, ID: id})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Param < result[j].Param })
+	return result
+}
+
+func outcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= http.StatusBadRequest:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
@@ -0,0 +1,46 @@
+package auditlog
+
+import "time"
+
+// Event is the audit record of a mutating HTTP request.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	// Route is the pattern of the route that served the request, such as /api/dashboards/uid/:uid.
+	Route     string     `json:"route,omitempty"`
+	Actor     Actor      `json:"actor"`
+	OrgID     int64      `json:"orgId"`
+	Resources []Resource `json:"resources,omitempty"`
+	Status    int        `json:"status"`
+	// Outcome is success, denied for the requests rejected with 401 or 403, or failure.
+	Outcome    string `json:"outcome"`
+	DurationMs int64  `json:"durationMs"`
+	RemoteAddr string `json:"remoteAddr"`
+	UserAgent  string `json:"userAgent,omitempty"`
+	TraceID    string `json:"traceId,omitempty"`
+	// Body is the JSON body of the request, when request bodies are recorded.
+	Body any `json:"body,omitempty"`
+}
+
+// Actor is the identity that sent the request.
+type Actor struct {
+	ID              string `json:"id,omitempty"`
+	Login           string `json:"login,omitempty"`
+	Type            string `json:"type,omitempty"`
+	AuthenticatedBy string `json:"authenticatedBy,omitempty"`
+}
+
+// Resource identifies a resource changed by the request, with the value of a route parameter.
+type Resource struct {
+	// Kind is set by the route annotation, such as dashboard or datasource.
+	Kind  string `json:"kind,omitempty"`
+	Param string `json:"param"`
+	ID    string `json:"id"`
+}
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
@@ -0,0 +1,55 @@
+//go:build !windows && !nacl && !plan9
+
+package auditlog
+
+import (
+	"context"
+	"log/syslog"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var facilities = map[string]syslog.Priority{
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogSink sends each event as a syslog message.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(settings setting.AuditLogSyslogSettings) (*syslogSink, error) {
+	facility, ok := facilities[settings.Facility]
+	if !ok {
+		facility = syslog.LOG_LOCAL7
+	}
+	w, err := syslog.Dial(settings.Network, settings.Address, facility|syslog.LOG_INFO, settings.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: w}, nil
+}
+
+func (s *syslogSink) name() string { return "syslog" }
+
+func (s *syslogSink) write(_ context.Context, records []record) error {
+	for _, r := range records {
+		if err := s.writer.Info(string(r.line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,13 @@
+//go:build windows || nacl || plan9
+
+package auditlog
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newSyslogSink(setting.AuditLogSyslogSettings) (sink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,160 @@
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fileSink appends the events to a file as JSON lines. The file can be rotated by an external tool that
+// truncates it.
+type fileSink struct {
+	mtx  sync.Mutex
+	file *os.File
+}
+
+func newFileSink(settings setting.AuditLogFileSettings) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(settings.Path), 0o750); err != nil {
+		return nil, err
+	}
+	// the path comes from the configuration
+	// nolint:gosec
+	f, err := os.OpenFile(settings.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: f}, nil
+}
+
+func (s *fileSink) name() string { return "file" }
+
+func (s *fileSink) write(_ context.Context, records []record) error {
+	var buf bytes.Buffer
+	for _, r := range records {
+		buf.Write(r.line)
+		buf.WriteByte('\n')
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_, err := s.file.Write(buf.Bytes())
+	return err
+}
+
+func (s *fileSink) close() error {
+	return s.file.Close()
+}
+
+// lokiSink pushes the events to a Loki stream.
+type lokiSink struct {
+	client   *http.Client
+	url      string
+	settings setting.AuditLogLokiSettings
+}
+
+func newLokiSink(settings setting.AuditLogLokiSettings) (*lokiSink, error) {
+	if settings.URL == "" {
+		return nil, errors.New("missing url")
+	}
+	url := strings.TrimSuffix(settings.URL, "/")
+	if !strings.HasSuffix(url, "/loki/api/v1/push") {
+		url += "/loki/api/v1/push"
+	}
+	return &lokiSink{client: &http.Client{Timeout: writeTimeout}, url: url, settings: settings}, nil
+}
+
+func (s *lokiSink) name() string { return "loki" }
+
+func (s *lokiSink) write(ctx context.Context, records []record) error {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	values := make([][2]string, 0, len(records))
+	for _, r := range records {
+		values = append(values, [2]string{strconv.FormatInt(r.time.UnixNano(), 10), string(r.line)})
+	}
+	body, err := json.Marshal(map[string][]stream{"streams": {{Stream: s.settings.Labels, Values: values}}})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, s.client, s.url, body, func(req *http.Request) {
+		if s.settings.TenantID != "" {
+			req.Header.Set("X-Scope-OrgID", s.settings.TenantID)
+		}
+		if s.settings.Username != "" {
+			req.SetBasicAuth(s.settings.Username, s.settings.Password)
+		}
+	})
+}
+
+func (s *lokiSink) close() error { return nil }
+
+// webhookSink posts the events to a URL as a JSON array.
+type webhookSink struct {
+	client   *http.Client
+	settings setting.AuditLogWebhookSettings
+}
+
+func newWebhookSink(settings setting.AuditLogWebhookSettings) (*webhookSink, error) {
+	if settings.URL == "" {
+		return nil, errors.New("missing url")
+	}
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = writeTimeout
+	}
+	return &webhookSink{client: &http.Client{Timeout: timeout}, settings: settings}, nil
+}
+
+func (s *webhookSink) name() string { return "webhook" }
+
+func (s *webhookSink) write(ctx context.Context, records []record) error {
+	lines := make([][]byte, 0, len(records))
+	for _, r := range records {
+		lines = append(lines, r.line)
+	}
+	body := append(append([]byte{'['}, bytes.Join(lines, []byte{','})...), ']')
+
+	return post(ctx, s.client, s.settings.URL, body, func(req *http.Request) {
+		if s.settings.Authorization != "" {
+			req.Header.Set("Authorization", s.settings.Authorization)
+		}
+	})
+}
+
+func (s *webhookSink) close() error { return nil }
+
+func post(ctx context.Context, client *http.Client, url string, body []byte, setHeaders func(*http.Request)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setHeaders(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	return nil
+}
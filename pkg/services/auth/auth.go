@@ -73,6 +73,7 @@ type UserTokenService interface {
 	RotateToken(ctx context.Context, cmd RotateCommand) (*UserToken, error)
 	RevokeToken(ctx context.Context, token *UserToken, soft bool) error
 	RevokeAllUserTokens(ctx context.Context, userID int64) error
+	BatchRevokeAllUserTokens(ctx context.Context, userIDs []int64) error
 	GetUserToken(ctx context.Context, userID, userTokenID int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userID int64) ([]*UserToken, error)
 	ActiveTokenCount(ctx context.Context, userID *int64) (int64, error)
@@ -28,12 +28,15 @@ const (
 	ClientForm        = "auth.client.form"
 	ClientProxy       = "auth.client.proxy"
 	ClientSAML        = "auth.client.saml"
+	ClientSignedURL   = "auth.client.signed-url"
 )
 
 const (
 	MetaKeyUsername   = "username"
 	MetaKeyAuthModule = "authModule"
 	MetaKeyIsLogin    = "isLogin"
+	// MetaKeyAPIKeyID is the id of the API key or service account token which authenticated the request.
+	MetaKeyAPIKeyID = "apiKeyID"
 )
 
 // ClientParams are hints to the auth service about how to handle the identity management
@@ -11,8 +11,8 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/sync/singleflight"
 
-	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/services/auth"
@@ -20,9 +20,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 )
 
-const maxOAuthTokenCacheTTL = 5 * time.Minute
+const (
+	maxOAuthTokenCacheTTL = 5 * time.Minute
+	// oauthTokenCacheName is the name of the cache of OAuth token checks, configured in the
+	// [remote_cache.oauth_token_checks] section.
+	oauthTokenCacheName = "oauth_token_checks"
+)
 
-func ProvideOAuthTokenSync(service oauthtoken.OAuthTokenService, sessionService auth.UserTokenService, socialService social.Service, tracer tracing.Tracer) *OAuthTokenSync {
+func ProvideOAuthTokenSync(service oauthtoken.OAuthTokenService, sessionService auth.UserTokenService, socialService social.Service, tracer tracing.Tracer, caches namedcache.Provider) *OAuthTokenSync {
 	return &OAuthTokenSync{
 		log.New("oauth_token.sync"),
 		service,
@@ -30,7 +35,7 @@ func ProvideOAuthTokenSync(service oauthtoken.OAuthTokenService, sessionService
 		socialService,
 		new(singleflight.Group),
 		tracer,
-		localcache.New(maxOAuthTokenCacheTTL, 15*time.Minute),
+		caches.NamedCache(oauthTokenCacheName, maxOAuthTokenCacheTTL),
 	}
 }
 
@@ -41,7 +46,7 @@ type OAuthTokenSync struct {
 	socialService     social.Service
 	singleflightGroup *singleflight.Group
 	tracer            tracing.Tracer
-	cache             *localcache.CacheService
+	cache             *namedcache.Cache
 }
 
 func (s *OAuthTokenSync) SyncOauthTokenHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
@@ -72,7 +77,7 @@ func (s *OAuthTokenSync) SyncOauthTokenHook(ctx context.Context, id *authn.Ident
 	ctxLogger := s.log.FromContext(ctx).New("userID", userID)
 
 	cacheKey := fmt.Sprintf("token-check-%s", id.GetID())
-	if _, ok := s.cache.Get(cacheKey); ok {
+	if _, err := s.cache.Get(ctx, cacheKey); err == nil {
 		ctxLogger.Debug("Expiration check has been cached, no need to refresh")
 		return nil
 	}
@@ -96,11 +101,15 @@ func (s *OAuthTokenSync) SyncOauthTokenHook(ctx context.Context, id *authn.Ident
 				ctxLogger.Warn("Failed to revoke session token", "id", id.ID, "tokenId", id.SessionToken.Id, "error", err)
 			}
 
-			s.cache.Delete(cacheKey)
+			if err := s.cache.Delete(ctx, cacheKey); err != nil {
+				ctxLogger.Warn("Failed to delete the cached expiration check", "error", err)
+			}
 			return nil, refreshErr
 		}
 
-		s.cache.Set(cacheKey, true, getOAuthTokenCacheTTL(token))
+		if err := s.cache.Set(ctx, cacheKey, []byte{1}, getOAuthTokenCacheTTL(token)); err != nil {
+			ctxLogger.Warn("Failed to cache the expiration check", "error", err)
+		}
 		return nil, nil
 	})
 
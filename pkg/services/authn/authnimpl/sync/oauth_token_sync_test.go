@@ -12,8 +12,8 @@ import (
 	"golang.org/x/sync/singleflight"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
-	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/login/social/socialtest"
@@ -115,7 +115,7 @@ func TestOAuthTokenSync_SyncOAuthTokenHook(t *testing.T) {
 				socialService:     socialService,
 				singleflightGroup: new(singleflight.Group),
 				tracer:            tracing.InitializeTracerForTest(),
-				cache:             localcache.New(maxOAuthTokenCacheTTL, 15*time.Minute),
+				cache:             namedcache.FakeProvider{}.NamedCache(oauthTokenCacheName, maxOAuthTokenCacheTTL),
 			}
 
 			err := sync.SyncOauthTokenHook(context.Background(), tt.identity, nil)
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"errors"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var (
@@ -31,16 +33,20 @@ var _ authn.HookClient = new(APIKey)
 var _ authn.ContextAwareClient = new(APIKey)
 var _ authn.IdentityResolverClient = new(APIKey)
 
-func ProvideAPIKey(apiKeyService apikey.Service) *APIKey {
+// ProvideAPIKey returns the API key client. The forwarded headers of the requests are only trusted to hold the
+// address the keys are last used from when the requests come from the trusted proxies.
+func ProvideAPIKey(apiKeyService apikey.Service, trustedProxies []*net.IPNet) *APIKey {
 	return &APIKey{
-		log:           log.New(authn.ClientAPIKey),
-		apiKeyService: apiKeyService,
+		log:            log.New(authn.ClientAPIKey),
+		apiKeyService:  apiKeyService,
+		trustedProxies: trustedProxies,
 	}
 }
 
 type APIKey struct {
-	log           log.Logger
-	apiKeyService apikey.Service
+	log            log.Logger
+	apiKeyService  apikey.Service
+	trustedProxies []*net.IPNet
 }
 
 func (s *APIKey) Name() string {
@@ -63,6 +69,7 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 	if err := validateApiKey(r.OrgID, key); err != nil {
 		return nil, err
 	}
+	r.SetMeta(authn.MetaKeyAPIKeyID, strconv.FormatInt(key.ID, 10))
 
 	// if the api key don't belong to a service account construct the identity and return it
 	if key.ServiceAccountId == nil || *key.ServiceAccountId < 1 {
@@ -176,13 +183,20 @@ func (s *APIKey) Hook(ctx context.Context, identity *authn.Identity, r *authn.Re
 		return nil
 	}
 
+	sourceIP := ""
+	if r.HTTPRequest != nil {
+		if ip := web.TrustedRemoteAddr(r.HTTPRequest, s.trustedProxies); ip != nil {
+			sourceIP = ip.String()
+		}
+	}
+
 	go func(apikeyID int64) {
 		defer func() {
 			if err := recover(); err != nil {
 				s.log.Error("Panic during user last seen sync", "err", err)
 			}
 		}()
-		if err := s.apiKeyService.UpdateAPIKeyLastUsedDate(context.Background(), apikeyID); err != nil {
+		if err := s.apiKeyService.UpdateAPIKeyLastUsedDate(context.Background(), apikeyID, sourceIP); err != nil {
 			s.log.Warn("Failed to update last use date for api key", "id", apikeyID)
 		}
 	}(id)
@@ -0,0 +1,35 @@
+package caching
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OSSCachingService) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	routeRegister.Group("/api/query-caching", func(subrouter routing.RouteRegister) {
+		subrouter.Delete("/datasources/:uid", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.handlePurgeDatasource))
+	})
+}
+
+func (s *OSSCachingService) handlePurgeDatasource(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if uid == "" {
+		return response.Error(http.StatusBadRequest, "data source uid is missing", nil)
+	}
+
+	removed := s.PurgeDatasource(c.SignedInUser.GetOrgID(), uid)
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Query cache purged",
+		"removed": removed,
+	})
+}
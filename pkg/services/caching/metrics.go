@@ -0,0 +1,31 @@
+package caching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type queryCachingMetrics struct {
+	// requests counts the lookups per cache status. The hit ratio is the rate of HIT over the rate of all statuses.
+	requests *prometheus.CounterVec
+}
+
+func newQueryCachingMetrics(r prometheus.Registerer, cache *queryCache) *queryCachingMetrics {
+	promauto.With(r).NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "query_caching",
+		Name:      "entries",
+		Help:      "Number of query responses in the cache.",
+	}, func() float64 {
+		return float64(cache.len())
+	})
+
+	return &queryCachingMetrics{
+		requests: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "query_caching",
+			Name:      "requests_total",
+			Help:      "Number of query requests handled by the query cache, by cache status.",
+		}, []string{"datasource_type", "status"}),
+	}
+}
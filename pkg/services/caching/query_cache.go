@@ -0,0 +1,321 @@
+package caching
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/util/proxyutil"
+)
+
+// volatileQueryFields are set by the frontend for every request and do not change the result of a query.
+var volatileQueryFields = []string{"requestId", "queryCachingTTL"}
+
+type cacheEntry struct {
+	orgID         int64
+	datasourceUID string
+	response      *backend.QueryDataResponse
+	storedAt      time.Time
+	expiresAt     time.Time
+}
+
+// queryCache is an in-memory store of query responses with per entry expiration.
+type queryCache struct {
+	maxEntries int
+	now        func() time.Time
+
+	mtx     sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func newQueryCache(maxEntries int, now func() time.Time) *queryCache {
+	return &queryCache{
+		maxEntries: maxEntries,
+		now:        now,
+		entries:    make(map[string]*cacheEntry),
+	}
+}
+
+// get returns a copy of the response stored for key if it has not expired and is not older than maxAge.
+// A maxAge of zero or less means any age is accepted.
+func (c *queryCache) get(key string, maxAge time.Duration) (*backend.QueryDataResponse, bool) {
+	resp, ok := c.lookup(key, maxAge)
+	if !ok {
+		return nil, false
+	}
+	// the callers change the responses, e.g. to apply the query limits, so each gets its own copy
+	resp, err := copyResponse(resp)
+	if err != nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+func (c *queryCache) lookup(key string, maxAge time.Duration) (*backend.QueryDataResponse, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	now := c.now()
+	if !now.Before(e.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	if maxAge > 0 && now.Sub(e.storedAt) > maxAge {
+		return nil, false
+	}
+	return e.response, true
+}
+
+// set stores a copy of a response, so that the caller can keep changing it.
+func (c *queryCache) set(key string, orgID int64, datasourceUID string, resp *backend.QueryDataResponse, ttl time.Duration) {
+	resp, err := copyResponse(resp)
+	if err != nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evictLocked(now)
+	}
+	c.entries[key] = &cacheEntry{
+		orgID:         orgID,
+		datasourceUID: datasourceUID,
+		response:      resp,
+		storedAt:      now,
+		expiresAt:     now.Add(ttl),
+	}
+}
+
+// evictLocked removes all expired entries. If the cache is still full, the entry that expires first is removed.
+func (c *queryCache) evictLocked(now time.Time) {
+	var firstKey string
+	var first *cacheEntry
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if first == nil || e.expiresAt.Before(first.expiresAt) {
+			firstKey, first = key, e
+		}
+	}
+	if first != nil && len(c.entries) >= c.maxEntries {
+		delete(c.entries, firstKey)
+	}
+}
+
+// purge removes all entries of a data source and returns how many were removed.
+func (c *queryCache) purge(orgID int64, datasourceUID string) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	removed := 0
+	for key, e := range c.entries {
+		if e.orgID == orgID && e.datasourceUID == datasourceUID {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (c *queryCache) len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return len(c.entries)
+}
+
+// copyResponse returns a deep copy of a response. The frames are copied through their Arrow encoding, which keeps
+// their metadata and field configs.
+func copyResponse(resp *backend.QueryDataResponse) (*backend.QueryDataResponse, error) {
+	c := &backend.QueryDataResponse{Responses: make(backend.Responses, len(resp.Responses))}
+	for refID, res := range resp.Responses {
+		if len(res.Frames) > 0 {
+			encoded, err := res.Frames.MarshalArrow()
+			if err != nil {
+				return nil, err
+			}
+			if res.Frames, err = data.UnmarshalArrowFrames(encoded); err != nil {
+				return nil, err
+			}
+		}
+		c.Responses[refID] = res
+	}
+	return c, nil
+}
+
+type cacheKeyQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	Interval      time.Duration   `json:"interval"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	Model         json.RawMessage `json:"model"`
+}
+
+type cacheKey struct {
+	OrgID             int64           `json:"orgId"`
+	DatasourceUID     string          `json:"datasourceUid"`
+	DatasourceUpdated int64           `json:"datasourceUpdated"`
+	Scope             []string        `json:"scope"`
+	Queries           []cacheKeyQuery `json:"queries"`
+}
+
+// queryCacheKey builds the key of a query request. It is made of the data source, the normalized queries,
+// the time range truncated to rounding and the RBAC scope of the user, so that only users with the same
+// data source permissions share results.
+func queryCacheKey(req *backend.QueryDataRequest, user identity.Requester, rounding time.Duration) (string, error) {
+	settings := req.PluginContext.DataSourceInstanceSettings
+	if settings == nil {
+		return "", errors.New("request has no data source")
+	}
+
+	key := cacheKey{
+		OrgID:             req.PluginContext.OrgID,
+		DatasourceUID:     settings.UID,
+		DatasourceUpdated: settings.Updated.UnixNano(),
+		Scope:             userScope(user, settings),
+		Queries:           make([]cacheKeyQuery, 0, len(req.Queries)),
+	}
+
+	for _, q := range req.Queries {
+		model, err := normalizeQueryModel(q.JSON)
+		if err != nil {
+			return "", err
+		}
+		key.Queries = append(key.Queries, cacheKeyQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			From:          roundTime(q.TimeRange.From, rounding),
+			To:            roundTime(q.TimeRange.To, rounding),
+			Model:         model,
+		})
+	}
+	sort.Slice(key.Queries, func(i, j int) bool {
+		return key.Queries[i].RefID < key.Queries[j].RefID
+	})
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeQueryModel removes the fields that do not affect the result of a query.
+// Marshaling the decoded map also sorts the keys, so that equivalent queries have the same representation.
+func normalizeQueryModel(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var model map[string]any
+	if err := json.Unmarshal(raw, &model); err != nil {
+		return nil, err
+	}
+	for _, field := range volatileQueryFields {
+		delete(model, field)
+	}
+	return json.Marshal(model)
+}
+
+func roundTime(t time.Time, rounding time.Duration) int64 {
+	if rounding > 0 {
+		t = t.Truncate(rounding)
+	}
+	return t.UnixMilli()
+}
+
+// userScope returns the part of the identity of the user that can change the result of a query:
+// the scopes the user can query data sources with and, for data sources that forward the identity
+// of the user, the user itself.
+func userScope(user identity.Requester, settings *backend.DataSourceInstanceSettings) []string {
+	scope := append([]string{}, user.GetPermissions()[datasources.ActionQuery]...)
+	sort.Strings(scope)
+	if forwardsIdentity(settings) {
+		scope = append(scope, "user:"+user.GetUID())
+	}
+	return scope
+}
+
+func forwardsIdentity(settings *backend.DataSourceInstanceSettings) bool {
+	if len(settings.JSONData) == 0 {
+		return false
+	}
+	var jsonData map[string]any
+	if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+		// Be conservative, we can't tell whether results are user specific.
+		return true
+	}
+	for _, key := range []string{"oauthPassThru", "oauthTokenExchange", "forwardGrafanaIdToken"} {
+		if v, ok := jsonData[key].(bool); ok && v {
+			return true
+		}
+	}
+	// Forwarded headers can carry anything about the user, their values are not part of the key.
+	if rules, ok := jsonData[proxyutil.ForwardHeadersJSONDataKey].([]any); ok && len(rules) > 0 {
+		return true
+	}
+	return false
+}
+
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	maxAge  time.Duration
+}
+
+// parseCacheControl parses the directives of a Cache-Control request header that are relevant to query caching.
+func parseCacheControl(header string) cacheControl {
+	var cc cacheControl
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			cc.noStore = true
+		case directive == "no-cache":
+			cc.noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			if seconds == 0 {
+				cc.noCache = true
+				continue
+			}
+			cc.maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	return cc
+}
+
+func hasErrors(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return true
+		}
+	}
+	return false
+}
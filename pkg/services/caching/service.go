@@ -2,8 +2,17 @@ package caching
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -35,8 +44,19 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
+func ProvideCachingService(cfg *setting.Cfg, routeRegister routing.RouteRegister, accessControl ac.AccessControl, registerer prometheus.Registerer) *OSSCachingService {
+	s := &OSSCachingService{}
+	if !cfg.QueryCaching.Enabled {
+		return s
+	}
+
+	s.settings = cfg.QueryCaching
+	s.cache = newQueryCache(cfg.QueryCaching.MaxEntries, time.Now)
+	s.metrics = newQueryCachingMetrics(registerer, s.cache)
+	s.accessControl = accessControl
+	s.log = log.New("query-caching")
+	s.registerAPIEndpoints(routeRegister)
+	return s
 }
 
 type CachingService interface {
@@ -49,12 +69,89 @@ type CachingService interface {
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
 }
 
-// Implementation of interface - does nothing
+// Implementation of interface - caches query responses in memory if query caching is enabled, otherwise does nothing
 type OSSCachingService struct {
+	settings      setting.QueryCachingSettings
+	cache         *queryCache
+	metrics       *queryCachingMetrics
+	accessControl ac.AccessControl
+	log           log.Logger
 }
 
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	if s.cache == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	reqCtx := contexthandler.FromContext(ctx)
+	settings := req.PluginContext.DataSourceInstanceSettings
+	if reqCtx == nil || settings == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	ttl := s.ttl(settings.UID)
+	if ttl <= 0 {
+		return false, CachedQueryDataResponse{}
+	}
+
+	status := func(status string) {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+		s.metrics.requests.WithLabelValues(settings.Type, status).Inc()
+	}
+
+	cc := parseCacheControl(reqCtx.Req.Header.Get("Cache-Control"))
+	if cc.noStore || reqCtx.SkipQueryCache {
+		status(StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		status(StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := queryCacheKey(req, user, s.settings.TimeRangeRounding)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to build query cache key", "datasource", settings.UID, "error", err)
+		status(StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	if !cc.noCache {
+		if resp, ok := s.cache.get(key, cc.maxAge); ok {
+			status(StatusHit)
+			return true, CachedQueryDataResponse{Response: resp}
+		}
+	}
+
+	status(StatusMiss)
+	orgID := req.PluginContext.OrgID
+	return false, CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.QueryDataResponse) {
+			// Errors are often temporary, don't keep serving them.
+			if resp == nil || hasErrors(resp) {
+				return
+			}
+			s.cache.set(key, orgID, settings.UID, resp, ttl)
+		},
+	}
+}
+
+// ttl returns how long responses of a data source are cached.
+func (s *OSSCachingService) ttl(datasourceUID string) time.Duration {
+	if ttl, ok := s.settings.DatasourceTTLs[datasourceUID]; ok {
+		return ttl
+	}
+	return s.settings.DefaultTTL
+}
+
+// PurgeDatasource removes all cached responses of a data source.
+func (s *OSSCachingService) PurgeDatasource(orgID int64, datasourceUID string) int {
+	if s.cache == nil {
+		return 0
+	}
+	return s.cache.purge(orgID, datasourceUID)
 }
 
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
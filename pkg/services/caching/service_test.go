@@ -0,0 +1,193 @@
+package caching
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestQueryCaching(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryCaching = setting.QueryCachingSettings{
+		Enabled:           true,
+		DefaultTTL:        time.Minute,
+		DatasourceTTLs:    map[string]time.Duration{"uncached": 0},
+		TimeRangeRounding: 10 * time.Second,
+		MaxEntries:        10,
+	}
+	s := ProvideCachingService(cfg, routing.NewRouteRegister(), &actest.FakeAccessControl{}, prometheus.NewRegistry())
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newRequest := func(dsUID string, to time.Time) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: dsUID, Type: "prometheus"},
+			},
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+				JSON:      []byte(`{"expr": "up", "requestId": "` + to.String() + `"}`),
+			}},
+		}
+	}
+	newContext := func(usr *user.SignedInUser, headers map[string]string) (context.Context, *contextmodel.ReqContext) {
+		req := httptest.NewRequest(http.MethodPost, "/api/ds/query", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		reqCtx := &contextmodel.ReqContext{
+			Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(http.MethodPost, httptest.NewRecorder())},
+			SignedInUser: usr,
+		}
+		ctx := ctxkey.Set(context.Background(), reqCtx)
+		return identity.WithRequester(ctx, usr), reqCtx
+	}
+	viewer := &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {datasources.ActionQuery: {"datasources:*"}}}}
+	restricted := &user.SignedInUser{UserID: 2, OrgID: 1, Permissions: map[int64]map[string][]string{1: {datasources.ActionQuery: {"datasources:uid:ds"}}}}
+
+	cached := &backend.QueryDataResponse{Responses: backend.Responses{"A": {}}}
+
+	ctx, reqCtx := newContext(viewer, nil)
+	hit, resp := s.HandleQueryRequest(ctx, newRequest("ds", now))
+	require.False(t, hit)
+	require.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+	require.NotNil(t, resp.UpdateCacheFn)
+	resp.UpdateCacheFn(ctx, cached)
+
+	t.Run("returns the cached response for an equivalent query", func(t *testing.T) {
+		ctx, reqCtx := newContext(viewer, nil)
+		hit, resp := s.HandleQueryRequest(ctx, newRequest("ds", now.Add(5*time.Second)))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.Equal(t, cached, resp.Response)
+	})
+
+	t.Run("does not share responses between users with different scopes", func(t *testing.T) {
+		ctx, _ := newContext(restricted, nil)
+		hit, _ := s.HandleQueryRequest(ctx, newRequest("ds", now))
+		require.False(t, hit)
+	})
+
+	t.Run("honors cache control headers", func(t *testing.T) {
+		ctx, reqCtx := newContext(viewer, map[string]string{"Cache-Control": "no-store"})
+		hit, resp := s.HandleQueryRequest(ctx, newRequest("ds", now))
+		require.False(t, hit)
+		require.Nil(t, resp.UpdateCacheFn)
+		require.Equal(t, StatusBypass, reqCtx.Resp.Header().Get(XCacheHeader))
+
+		ctx, _ = newContext(viewer, map[string]string{"Cache-Control": "no-cache"})
+		hit, resp = s.HandleQueryRequest(ctx, newRequest("ds", now))
+		require.False(t, hit)
+		require.NotNil(t, resp.UpdateCacheFn)
+	})
+
+	t.Run("does not cache data sources with a TTL of 0", func(t *testing.T) {
+		ctx, reqCtx := newContext(viewer, nil)
+		hit, resp := s.HandleQueryRequest(ctx, newRequest("uncached", now))
+		require.False(t, hit)
+		require.Nil(t, resp.UpdateCacheFn)
+		require.Empty(t, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("does not cache error responses", func(t *testing.T) {
+		ctx, _ := newContext(viewer, nil)
+		req := newRequest("failing", now)
+		_, resp := s.HandleQueryRequest(ctx, req)
+		resp.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: errors.New("boom")}}})
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+	})
+
+	t.Run("does not share responses of data sources that forward headers between users", func(t *testing.T) {
+		other := &user.SignedInUser{UserID: 3, OrgID: 1, Permissions: viewer.Permissions}
+		forwarding := func() *backend.QueryDataRequest {
+			req := newRequest("forwarding", now)
+			req.PluginContext.DataSourceInstanceSettings.JSONData = []byte(`{"forwardHeaders": [{"header": "X-Tenant"}]}`)
+			return req
+		}
+
+		ctx, _ := newContext(viewer, nil)
+		_, resp := s.HandleQueryRequest(ctx, forwarding())
+		resp.UpdateCacheFn(ctx, cached)
+		hit, _ := s.HandleQueryRequest(ctx, forwarding())
+		require.True(t, hit)
+
+		ctx, _ = newContext(other, nil)
+		hit, _ = s.HandleQueryRequest(ctx, forwarding())
+		require.False(t, hit)
+	})
+
+	t.Run("purges the responses of a data source", func(t *testing.T) {
+		require.Equal(t, 1, s.PurgeDatasource(1, "ds"))
+		ctx, _ := newContext(viewer, nil)
+		hit, _ := s.HandleQueryRequest(ctx, newRequest("ds", now))
+		require.False(t, hit)
+	})
+}
+
+func TestParseCacheControl(t *testing.T) {
+	require.Equal(t, cacheControl{}, parseCacheControl(""))
+	require.Equal(t, cacheControl{noStore: true}, parseCacheControl("no-store"))
+	require.Equal(t, cacheControl{noCache: true, maxAge: 0}, parseCacheControl("max-age=0"))
+	require.Equal(t, cacheControl{maxAge: 30 * time.Second}, parseCacheControl("public, Max-Age=30"))
+}
+
+func TestQueryCacheCopiesResponses(t *testing.T) {
+	now := time.Now()
+	c := newQueryCache(10, func() time.Time { return now })
+	frame := data.NewFrame("frame", data.NewField("value", nil, []float64{1, 2}))
+	frame.SetMeta(&data.FrameMeta{ExecutedQueryString: "up"})
+	stored := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Frames: data.Frames{frame}}}}
+	c.set("a", 1, "ds", stored, time.Minute)
+
+	// the caller keeps changing its response
+	frame.Fields[0].Set(0, 10.0)
+	stored.Responses["B"] = backend.DataResponse{}
+
+	first, ok := c.get("a", 0)
+	require.True(t, ok)
+	require.Len(t, first.Responses, 1)
+	require.Equal(t, 1.0, first.Responses["A"].Frames[0].Fields[0].At(0))
+	require.Equal(t, "up", first.Responses["A"].Frames[0].Meta.ExecutedQueryString)
+
+	first.Responses["A"].Frames[0].AppendNotices(data.Notice{Text: "truncated"})
+	first.Responses["A"] = backend.DataResponse{}
+
+	second, ok := c.get("a", 0)
+	require.True(t, ok)
+	require.Len(t, second.Responses["A"].Frames, 1)
+	require.Empty(t, second.Responses["A"].Frames[0].Meta.Notices)
+}
+
+func TestQueryCacheEviction(t *testing.T) {
+	now := time.Now()
+	c := newQueryCache(2, func() time.Time { return now })
+	c.set("a", 1, "ds", &backend.QueryDataResponse{}, time.Minute)
+	c.set("b", 1, "ds", &backend.QueryDataResponse{}, 2*time.Minute)
+	c.set("c", 1, "ds", &backend.QueryDataResponse{}, 3*time.Minute)
+
+	require.Equal(t, 2, c.len())
+	_, ok := c.get("a", 0)
+	require.False(t, ok, "entry that expires first should be evicted")
+	_, ok = c.get("c", 0)
+	require.True(t, ok)
+}
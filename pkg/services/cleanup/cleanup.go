@@ -7,7 +7,6 @@ import (
 	"io/fs"
 	"os"
 	"path"
-	"strconv"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -20,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/jobs"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
@@ -46,7 +46,8 @@ type CleanUpService struct {
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService,
+	jobService *jobs.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -62,18 +63,49 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		annotationCleaner:         annotationCleaner,
 		dashboardService:          dashboardService,
 	}
+	for _, j := range s.cleanupJobs() {
+		if err := jobService.Register(jobs.Definition{
+			Name:        j.name,
+			Description: j.description,
+			Schedule:    "@every 10m",
+			Timeout:     5 * time.Minute,
+			MaxAttempts: 3,
+			Handler: func(ctx context.Context, _ []byte) error {
+				ctx, span := s.tracer.Start(ctx, j.name)
+				defer span.End()
+				return j.fn(ctx)
+			},
+		}); err != nil {
+			s.log.Error("Failed to register the cleanup job", "job", j.name, "error", err)
+		}
+	}
 	return s
 }
 
+// cleanUpJob cleans up rows of the database. It's run by the job service, so it runs on a single instance and is
+// retried when it fails.
 type cleanUpJob struct {
-	name string
-	fn   func(context.Context)
+	name        string
+	description string
+	fn          func(context.Context) error
 }
 
-func (j cleanUpJob) String() string {
-	return strconv.Quote(j.name)
+func (srv *CleanUpService) cleanupJobs() []cleanUpJob {
+	return []cleanUpJob{
+		{"cleanup.delete-expired-snapshots", "Deletes the expired dashboard snapshots.", srv.deleteExpiredSnapshots},
+		{"cleanup.delete-expired-dashboard-versions", "Deletes the dashboard versions over the limit.", srv.deleteExpiredDashboardVersions},
+		{"cleanup.delete-expired-images", "Deletes the expired alert images.", srv.deleteExpiredImages},
+		{"cleanup.delete-old-annotations", "Deletes the annotations older than their retention.", srv.cleanUpOldAnnotations},
+		{"cleanup.expire-user-invites", "Expires the old user invites.", srv.expireOldUserInvites},
+		{"cleanup.delete-stale-short-urls", "Deletes the short URLs which haven't been used.", srv.deleteStaleShortURLs},
+		{"cleanup.delete-stale-query-history", "Deletes the old query history and enforces its row limits.", srv.deleteStaleQueryHistory},
+		{"cleanup.expire-email-verifications", "Expires the old email verifications.", srv.expireOldVerifications},
+		{"cleanup.delete-trash-dashboards", "Deletes the dashboards deleted more than their retention ago.", srv.cleanUpTrashDashboards},
+	}
 }
 
+// Run cleans up the temporary files, which are local to each instance. The rows of the database are cleaned up by
+// the jobs registered in ProvideService.
 func (srv *CleanUpService) Run(ctx context.Context) error {
 	srv.cleanUpTmpFiles(ctx)
 
@@ -81,58 +113,21 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 	for {
 		select {
 		case <-ticker.C:
-			srv.clean(ctx)
+			srv.cleanUpTmpFiles(ctx)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
-func (srv *CleanUpService) clean(ctx context.Context) {
-	const timeout = 9 * time.Minute
-	start := time.Now()
-	ctx, span := srv.tracer.Start(ctx, "cleanup background job")
-	defer span.End()
-	ctx, cancelFn := context.WithTimeout(ctx, timeout)
-	defer cancelFn()
-
-	cleanupJobs := []cleanUpJob{
-		{"clean up temporary files", srv.cleanUpTmpFiles},
-		{"delete expired snapshots", srv.deleteExpiredSnapshots},
-		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
-		{"delete expired images", srv.deleteExpiredImages},
-		{"cleanup old annotations", srv.cleanUpOldAnnotations},
-		{"expire old user invites", srv.expireOldUserInvites},
-		{"delete stale short URLs", srv.deleteStaleShortURLs},
-		{"delete stale query history", srv.deleteStaleQueryHistory},
-		{"expire old email verifications", srv.expireOldVerifications},
-		{"cleanup trash dashboards", srv.cleanUpTrashDashboards},
-	}
-
-	logger := srv.log.FromContext(ctx)
-	logger.Debug("Starting cleanup jobs", "jobs", fmt.Sprintf("%v", cleanupJobs))
-
-	for _, j := range cleanupJobs {
-		if ctx.Err() != nil {
-			logger.Error("Cancelled cleanup job", "error", ctx.Err(), "duration", time.Since(start))
-			return
-		}
-		ctx, span := srv.tracer.Start(ctx, j.name)
-		j.fn(ctx)
-		span.End()
-	}
-
-	logger.Info("Completed cleanup jobs", "duration", time.Since(start))
-}
-
-func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) error {
 	affected, affectedTags, err := srv.annotationCleaner.Run(ctx, srv.Cfg)
+	// the cleaner deletes the annotations in batches until the job times out, the next run deletes the rest
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
-		logger.Error("failed to clean up old annotations", "error", err)
-	} else {
-		logger.Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
+		return fmt.Errorf("failed to clean up old annotations: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
+	return nil
 }
 
 func (srv *CleanUpService) cleanUpTmpFiles(ctx context.Context) {
@@ -196,40 +191,38 @@ func (srv *CleanUpService) shouldCleanupTempFile(filemtime time.Time, now time.T
 	return filemtime.Add(srv.Cfg.TempDataLifetime).Before(now)
 }
 
-func (srv *CleanUpService) deleteExpiredSnapshots(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+// deleteExpiredSnapshots is run by the job service, which retries it when it fails.
+func (srv *CleanUpService) deleteExpiredSnapshots(ctx context.Context) error {
 	cmd := dashboardsnapshots.DeleteExpiredSnapshotsCommand{}
 	if err := srv.dashboardSnapshotService.DeleteExpiredSnapshots(ctx, &cmd); err != nil {
-		logger.Error("Failed to delete expired snapshots", "error", err.Error())
-	} else {
-		logger.Debug("Deleted expired snapshots", "rows affected", cmd.DeletedRows)
+		return fmt.Errorf("failed to delete expired snapshots: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Deleted expired snapshots", "rows affected", cmd.DeletedRows)
+	return nil
 }
 
-func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) error {
 	cmd := dashver.DeleteExpiredVersionsCommand{}
 	if err := srv.dashboardVersionService.DeleteExpired(ctx, &cmd); err != nil {
-		logger.Error("Failed to delete expired dashboard versions", "error", err.Error())
-	} else {
-		logger.Debug("Deleted old/expired dashboard versions", "rows affected", cmd.DeletedRows)
+		return fmt.Errorf("failed to delete expired dashboard versions: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Deleted old/expired dashboard versions", "rows affected", cmd.DeletedRows)
+	return nil
 }
 
-func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) error {
 	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
-		return
+		return nil
 	}
-	if rowsAffected, err := srv.deleteExpiredImageService.DeleteExpired(ctx); err != nil {
-		logger.Error("Failed to delete expired images", "error", err.Error())
-	} else {
-		logger.Debug("Deleted expired images", "rows affected", rowsAffected)
+	rowsAffected, err := srv.deleteExpiredImageService.DeleteExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired images: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Deleted expired images", "rows affected", rowsAffected)
+	return nil
 }
 
-func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) error {
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
 
 	cmd := tempuser.ExpireTempUsersCommand{
@@ -237,14 +230,13 @@ func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	}
 
 	if err := srv.tempUserService.ExpireOldUserInvites(ctx, &cmd); err != nil {
-		logger.Error("Problem expiring user invites", "error", err.Error())
-	} else {
-		logger.Debug("Expired user invites", "rows affected", cmd.NumExpired)
+		return fmt.Errorf("failed to expire user invites: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Expired user invites", "rows affected", cmd.NumExpired)
+	return nil
 }
 
-func (srv *CleanUpService) expireOldVerifications(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) expireOldVerifications(ctx context.Context) error {
 	maxVerificationLifetime := srv.Cfg.VerificationEmailMaxLifetime
 
 	cmd := tempuser.ExpireTempUsersCommand{
@@ -252,61 +244,57 @@ func (srv *CleanUpService) expireOldVerifications(ctx context.Context) {
 	}
 
 	if err := srv.tempUserService.ExpireOldVerifications(ctx, &cmd); err != nil {
-		logger.Error("Problem expiring email verifications", "error", err.Error())
-	} else {
-		logger.Debug("Expired email verifications", "rows affected", cmd.NumExpired)
+		return fmt.Errorf("failed to expire email verifications: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Expired email verifications", "rows affected", cmd.NumExpired)
+	return nil
 }
 
-func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) error {
 	cmd := shorturls.DeleteShortUrlCommand{
 		OlderThan: time.Now().Add(-time.Duration(srv.Cfg.ShortLinkExpiration*24) * time.Hour),
 	}
 	if err := srv.ShortURLService.DeleteStaleShortURLs(ctx, &cmd); err != nil {
-		logger.Error("Problem deleting stale short urls", "error", err.Error())
-	} else {
-		logger.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
+		return fmt.Errorf("failed to delete stale short urls: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
+	return nil
 }
 
-func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
+func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) error {
 	logger := srv.log.FromContext(ctx)
 	// Delete query history from 14+ days ago with exception of starred queries
 	maxQueryHistoryLifetime := time.Hour * 24 * 14
 	olderThan := time.Now().Add(-maxQueryHistoryLifetime).Unix()
 	rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
 	if err != nil {
-		logger.Error("Problem deleting stale query history", "error", err.Error())
-	} else {
-		logger.Debug("Deleted stale query history", "rows affected", rowsCount)
+		return fmt.Errorf("failed to delete stale query history: %w", err)
 	}
+	logger.Debug("Deleted stale query history", "rows affected", rowsCount)
 
 	// Enforce 200k limit for query_history table
 	queryHistoryLimit := 200000
 	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryLimit, false)
 	if err != nil {
-		logger.Error("Problem with enforcing row limit for query_history", "error", err.Error())
-	} else {
-		logger.Debug("Enforced row limit for query_history", "rows affected", rowsCount)
+		return fmt.Errorf("failed to enforce the row limit of query_history: %w", err)
 	}
+	logger.Debug("Enforced row limit for query_history", "rows affected", rowsCount)
 
 	// Enforce 150k limit for query_history_star table
 	queryHistoryStarLimit := 150000
 	rowsCount, err = srv.QueryHistoryService.EnforceRowLimitInQueryHistory(ctx, queryHistoryStarLimit, true)
 	if err != nil {
-		logger.Error("Problem with enforcing row limit for query_history_star", "error", err.Error())
-	} else {
-		logger.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
+		return fmt.Errorf("failed to enforce the row limit of query_history_star: %w", err)
 	}
+	logger.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
+	return nil
 }
 
-func (srv *CleanUpService) cleanUpTrashDashboards(ctx context.Context) {
-	logger := srv.log.FromContext(ctx)
+func (srv *CleanUpService) cleanUpTrashDashboards(ctx context.Context) error {
 	affected, err := srv.dashboardService.CleanUpDeletedDashboards(ctx)
 	if err != nil {
-		logger.Error("Problem cleaning up deleted dashboards", "error", err)
-	} else {
-		logger.Debug("Cleaned up deleted dashboards", "dashboards affected", affected)
+		return fmt.Errorf("failed to clean up deleted dashboards: %w", err)
 	}
+	srv.log.FromContext(ctx).Debug("Cleaned up deleted dashboards", "dashboards affected", affected)
+	return nil
 }
@@ -0,0 +1,24 @@
+package configreload
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/admin/settings", func(entities routing.RouteRegister) {
+		entities.Post("/reload", routing.Wrap(s.reloadHandler))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+func (s *Service) reloadHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.Reload(c.Req.Context(), triggerAPI)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reload the configuration", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,109 @@
+// Package configreload reloads the configuration of Grafana at runtime, when Grafana receives a SIGHUP signal or
+// when an admin calls the reload API. The changed sections which are safe to reload are applied by the reload
+// handlers registered in the settings provider, the other changes are reported as requiring a restart.
+package configreload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	triggerSignal = "signal"
+	triggerAPI    = "api"
+)
+
+type Service struct {
+	settings setting.Provider
+	metrics  *metrics
+	log      log.Logger
+}
+
+// quotaReloader is implemented by the quota service when the quotas are enabled.
+type quotaReloader interface {
+	ReloadDefaultLimits(quotas setting.QuotaSettings) error
+}
+
+// ProvideService registers the reload handlers of the services which are created before the settings provider.
+func ProvideService(settingsProvider setting.Provider, features *featuremgmt.FeatureManager,
+	quotaService quota.Service, mailer notifications.Mailer, notificationService *notifications.NotificationService,
+	routeRegister routing.RouteRegister,
+	registerer prometheus.Registerer) *Service {
+	s := &Service{
+		settings: settingsProvider,
+		metrics:  newMetrics(registerer),
+		log:      log.New("configreload"),
+	}
+
+	settingsProvider.RegisterReloadHandler("feature_toggles", features.ReloadHandler())
+	// the quota service is only reloadable when the quotas are enabled
+	if reloader, ok := quotaService.(quotaReloader); ok {
+		settingsProvider.RegisterReloadHandler("quota", setting.ReloadHandlerFunc(func(setting.Section) error {
+			return reloader.ReloadDefaultLimits(settingsProvider.QuotaSettings())
+		}))
+	}
+	client, _ := mailer.(*notifications.SmtpClient)
+	for _, section := range setting.SmtpSections {
+		settingsProvider.RegisterReloadHandler(section, setting.ReloadHandlerFunc(func(setting.Section) error {
+			smtp := settingsProvider.SmtpSettings()
+			if client != nil {
+				client.SetSettings(smtp)
+			}
+			notificationService.SetSmtpSettings(smtp)
+			return nil
+		}))
+	}
+
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Run reloads the configuration each time Grafana receives a SIGHUP signal.
+func (s *Service) Run(ctx context.Context) error {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sighup:
+			if _, err := s.Reload(ctx, triggerSignal); err != nil {
+				s.log.Error("Failed to reload the configuration", "error", err)
+			}
+		}
+	}
+}
+
+// Reload reloads the configuration, and returns the report of the changed settings.
+func (s *Service) Reload(ctx context.Context, trigger string) (*setting.ReloadReport, error) {
+	s.log.Info("Reloading the configuration", "trigger", trigger)
+	report, err := s.settings.Reload(ctx)
+	if err != nil {
+		s.metrics.reloads.WithLabelValues(trigger, "error").Inc()
+		return nil, err
+	}
+
+	result := "success"
+	if report.Count(setting.ChangeFailed) > 0 {
+		result = "failure"
+	}
+	s.metrics.reloads.WithLabelValues(trigger, result).Inc()
+	for _, change := range report.Changes {
+		s.metrics.changes.WithLabelValues(string(change.Status)).Inc()
+		s.log.Info("Setting changed", "section", change.Section, "key", change.Key, "status", change.Status)
+	}
+	return report, nil
+}
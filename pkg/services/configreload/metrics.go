@@ -0,0 +1,28 @@
+package configreload
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	reloads *prometheus.CounterVec
+	changes *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		reloads: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "config_reload",
+			Name:      "reloads_total",
+			Help:      "Number of reloads of the configuration by trigger and result.",
+		}, []string{"trigger", "result"}),
+		changes: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "config_reload",
+			Name:      "changes_total",
+			Help:      "Number of changed settings found by the reloads of the configuration by status.",
+		}, []string{"status"}),
+	}
+}
@@ -140,6 +140,12 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 		// End the span to make next handlers not wrapped within middleware span
 		span.End()
 
+		// The loggers created from the request context log the organization, along with the trace ID, so that all
+		// the entries of a request can be correlated.
+		if reqContext.OrgID > 0 {
+			ctx = log.WithContextualAttributes(ctx, []any{"orgId", reqContext.OrgID})
+		}
+
 		next.ServeHTTP(w, r.WithContext(identity.WithRequester(ctx, id)))
 	})
 }
@@ -22,11 +22,14 @@ func (s *CorrelationsService) registerAPIEndpoints() {
 	s.RouteRegister.Group("/api/datasources/uid/:uid/correlations", func(entities routing.RouteRegister) {
 		entities.Get("/", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getCorrelationsBySourceUIDHandler))
 		entities.Post("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.createHandler))
+		entities.Post("/test", authorize(ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.testHandler))
+		entities.Post("/resolve", authorize(ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.resolveHandler))
 
 		entities.Group("/:correlationUID", func(entities routing.RouteRegister) {
 			entities.Get("/", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getCorrelationHandler))
 			entities.Delete("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.deleteHandler))
 			entities.Patch("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.updateHandler))
+			entities.Post("/test", authorize(ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.testHandler))
 		})
 	}, middleware.ReqSignedIn)
 }
@@ -352,3 +355,112 @@ type GetCorrelationsResponse struct {
 	// in: body
 	Body []Correlation `json:"body"`
 }
+
+// swagger:route POST /datasources/uid/{sourceUID}/correlations/{correlationUID}/test correlations testCorrelation
+//
+// Test a correlation against sample data.
+//
+// Applies the transformations of the correlation to a row of sample data and returns the extracted variables and the
+// target query with the variables replaced. The config in the body replaces the one of the correlation, and is
+// required to test a correlation which is not saved with `POST /datasources/uid/{sourceUID}/correlations/test`.
+//
+// Responses:
+// 200: testCorrelationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) testHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := TestCorrelationCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":correlationUID"]
+	cmd.SourceUID = web.Params(c.Req)[":uid"]
+	cmd.OrgId = c.SignedInUser.GetOrgID()
+
+	result, err := s.TestCorrelation(c.Req.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCorrelationNotFound):
+			return response.Error(http.StatusNotFound, "Correlation not found", err)
+		case errors.Is(err, ErrSourceDataSourceDoesNotExists):
+			return response.Error(http.StatusNotFound, "Source data source not found", err)
+		case errors.Is(err, ErrTestCorrelationConfigRequired), errors.Is(err, ErrInvalidTransformationExpression),
+			errors.Is(err, ErrInvalidTransformationType), errors.Is(err, ErrTransformationRegexReqExp), errors.Is(err, ErrTransformationTemplateReqExp):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to test correlation", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters testCorrelation
+type TestCorrelationParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"sourceUID"`
+	// in:path
+	// required:true
+	CorrelationUID string `json:"correlationUID"`
+	// in:body
+	// required:true
+	Body TestCorrelationCommand `json:"body"`
+}
+
+//swagger:response testCorrelationResponse
+type TestCorrelationResponse struct {
+	// in: body
+	Body TestCorrelationResponseBody `json:"body"`
+}
+
+// swagger:route POST /datasources/uid/{sourceUID}/correlations/resolve correlations resolveCorrelations
+//
+// Resolve the correlations of a data source for a row of its data.
+//
+// Applies the transformations of each correlation of the data source to the row and returns the target queries with
+// the variables replaced, for the correlations whose link is shown for the row.
+//
+// Responses:
+// 200: resolveCorrelationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) resolveHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ResolveCorrelationsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.SourceUID = web.Params(c.Req)[":uid"]
+	cmd.OrgId = c.SignedInUser.GetOrgID()
+
+	result, err := s.ResolveCorrelations(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, ErrSourceDataSourceDoesNotExists) {
+			return response.Error(http.StatusNotFound, "Source data source not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to resolve correlations", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters resolveCorrelations
+type ResolveCorrelationsParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"sourceUID"`
+	// in:body
+	// required:true
+	Body ResolveCorrelationsCommand `json:"body"`
+}
+
+//swagger:response resolveCorrelationsResponse
+type ResolveCorrelationsResponse struct {
+	// in: body
+	Body ResolveCorrelationsResponseBody `json:"body"`
+}
@@ -103,6 +103,48 @@ func (s CorrelationsService) GetCorrelations(ctx context.Context, cmd GetCorrela
 	return s.getCorrelations(ctx, cmd)
 }
 
+// TestCorrelation returns the variables and the target query of a correlation for a row of sample data, to check its
+// transformations before using it.
+func (s CorrelationsService) TestCorrelation(ctx context.Context, cmd TestCorrelationCommand) (TestCorrelationResponseBody, error) {
+	config := cmd.Config
+	if cmd.UID != "" {
+		correlation, err := s.getCorrelation(ctx, GetCorrelationQuery{UID: cmd.UID, SourceUID: cmd.SourceUID, OrgId: cmd.OrgId})
+		if err != nil {
+			return TestCorrelationResponseBody{}, err
+		}
+		if config == nil {
+			config = &correlation.Config
+		}
+	} else {
+		if config == nil {
+			return TestCorrelationResponseBody{}, ErrTestCorrelationConfigRequired
+		}
+		if _, err := s.DataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: cmd.OrgId, UID: cmd.SourceUID}); err != nil {
+			return TestCorrelationResponseBody{}, ErrSourceDataSourceDoesNotExists
+		}
+	}
+	if err := config.Transformations.Validate(); err != nil {
+		return TestCorrelationResponseBody{}, err
+	}
+
+	vars, err := config.Variables(cmd.Data)
+	if err != nil {
+		return TestCorrelationResponseBody{}, err
+	}
+	query, missing := config.TargetQuery(vars)
+	return TestCorrelationResponseBody{Variables: vars, Query: query, MissingVariables: missing}, nil
+}
+
+// ResolveCorrelations returns the correlations of a data source for a row of its data, with the target query built
+// from the row by the transformations of each correlation.
+func (s CorrelationsService) ResolveCorrelations(ctx context.Context, cmd ResolveCorrelationsCommand) (ResolveCorrelationsResponseBody, error) {
+	correlations, err := s.getCorrelationsBySourceUID(ctx, GetCorrelationsBySourceUIDQuery{SourceUID: cmd.SourceUID, OrgId: cmd.OrgId})
+	if err != nil {
+		return ResolveCorrelationsResponseBody{}, err
+	}
+	return ResolveCorrelationsResponseBody{Correlations: resolve(correlations, cmd.Data)}, nil
+}
+
 func (s CorrelationsService) DeleteCorrelationsBySourceUID(ctx context.Context, cmd DeleteCorrelationsBySourceUIDCommand) error {
 	return s.deleteCorrelationsBySourceUID(ctx, cmd)
 }
@@ -9,17 +9,20 @@ import (
 )
 
 var (
-	ErrCorrelationReadOnly           = errors.New("correlation can only be edited via provisioning")
-	ErrSourceDataSourceDoesNotExists = errors.New("source data source does not exist")
-	ErrTargetDataSourceDoesNotExists = errors.New("target data source does not exist")
-	ErrCorrelationNotFound           = errors.New("correlation not found")
-	ErrUpdateCorrelationEmptyParams  = errors.New("not enough parameters to edit correlation")
-	ErrInvalidConfigType             = errors.New("invalid correlation config type")
-	ErrInvalidTransformationType     = errors.New("invalid transformation type")
-	ErrTransformationNotNested       = errors.New("transformations must be nested under config")
-	ErrTransformationRegexReqExp     = errors.New("regex transformations require expression")
-	ErrCorrelationsQuotaFailed       = errors.New("error getting correlations quota")
-	ErrCorrelationsQuotaReached      = errors.New("correlations quota reached")
+	ErrCorrelationReadOnly             = errors.New("correlation can only be edited via provisioning")
+	ErrSourceDataSourceDoesNotExists   = errors.New("source data source does not exist")
+	ErrTargetDataSourceDoesNotExists   = errors.New("target data source does not exist")
+	ErrCorrelationNotFound             = errors.New("correlation not found")
+	ErrUpdateCorrelationEmptyParams    = errors.New("not enough parameters to edit correlation")
+	ErrInvalidConfigType               = errors.New("invalid correlation config type")
+	ErrInvalidTransformationType       = errors.New("invalid transformation type")
+	ErrTransformationNotNested         = errors.New("transformations must be nested under config")
+	ErrTransformationRegexReqExp       = errors.New("regex transformations require expression")
+	ErrTransformationTemplateReqExp    = errors.New("template transformations require expression")
+	ErrInvalidTransformationExpression = errors.New("invalid transformation expression")
+	ErrTestCorrelationConfigRequired   = errors.New("a config is required to test a correlation which is not saved")
+	ErrCorrelationsQuotaFailed         = errors.New("error getting correlations quota")
+	ErrCorrelationsQuotaReached        = errors.New("correlations quota reached")
 )
 
 const (
@@ -30,7 +33,9 @@ const (
 type CorrelationType string
 
 type Transformation struct {
-	//Enum: regex,logfmt
+	// Type of the transformation. A template sets the variable named by mapValue, or the field of the correlation,
+	// to its expression with the variables extracted so far replaced.
+	//Enum: regex,logfmt,template
 	Type       string `json:"type"`
 	Expression string `json:"expression,omitempty"`
 	Field      string `json:"field,omitempty"`
@@ -50,10 +55,12 @@ func (t CorrelationType) Validate() error {
 
 func (t Transformations) Validate() error {
 	for _, v := range t {
-		if v.Type != "regex" && v.Type != "logfmt" {
-			return fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, t)
+		if v.Type != "regex" && v.Type != "logfmt" && v.Type != "template" {
+			return fmt.Errorf("%w: \"%s\"", ErrInvalidTransformationType, t)
 		} else if v.Type == "regex" && len(v.Expression) == 0 {
-			return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+			return fmt.Errorf("%w: \"%s\"", ErrTransformationRegexReqExp, t)
+		} else if v.Type == "template" && len(v.Expression) == 0 {
+			return fmt.Errorf("%w: \"%s\"", ErrTransformationTemplateReqExp, t)
 		}
 	}
 	return nil
@@ -276,6 +283,78 @@ type GetCorrelationsQuery struct {
 	SourceUIDs []string `json:"sourceuid"`
 }
 
+// TestCorrelationCommand is the command for testing a correlation against a row of sample data
+// swagger:model
+type TestCorrelationCommand struct {
+	// UID of the correlation to test, empty to test a config which is not saved.
+	UID       string `json:"-"`
+	SourceUID string `json:"-"`
+	OrgId     int64  `json:"-"`
+
+	// Values of the fields of a row of the source data, by field name
+	// required:true
+	// example: {"message":"level=error traceID=4bf92f3577b34da6 msg=\"request failed\""}
+	Data map[string]any `json:"data" binding:"Required"`
+	// Configuration to test instead of the one of the correlation, required when testing a correlation which is not saved
+	// required:false
+	Config *CorrelationConfig `json:"config"`
+}
+
+// swagger:model
+type TestCorrelationResponseBody struct {
+	// Variables of the correlation for the sample data, including the ones extracted by the transformations
+	// example: {"message":"level=error traceID=4bf92f3577b34da6","traceID":"4bf92f3577b34da6"}
+	Variables map[string]string `json:"variables"`
+	// Target query with the variables replaced
+	// example: {"query":"4bf92f3577b34da6"}
+	Query map[string]any `json:"query"`
+	// Variables used by the target query which are not defined, the correlation is not shown for the sample data if
+	// there are any
+	MissingVariables []string `json:"missingVariables"`
+}
+
+// ResolveCorrelationsCommand is the command for resolving the correlations of a data source for a row of its data
+// swagger:model
+type ResolveCorrelationsCommand struct {
+	SourceUID string `json:"-"`
+	OrgId     int64  `json:"-"`
+
+	// Values of the fields of a row of the source data, by field name
+	// required:true
+	// example: {"message":"level=error traceID=4bf92f3577b34da6 msg=\"request failed\""}
+	Data map[string]any `json:"data" binding:"Required"`
+}
+
+// ResolvedCorrelation is a correlation of a row of the source data, with the target query to run
+// swagger:model
+type ResolvedCorrelation struct {
+	// Unique identifier of the correlation
+	// example: 50xhMlg9k
+	UID string `json:"uid"`
+	// UID of the data source the correlation points to
+	// example: PE1C5CBDA0504A6A3
+	TargetUID *string `json:"targetUID"`
+	// Label identifying the correlation
+	// example: My Label
+	Label string `json:"label"`
+	// Description of the correlation
+	// example: Logs to Traces
+	Description string `json:"description"`
+	// Field the correlation link is attached to
+	// example: message
+	Field string `json:"field"`
+	// Target query with the variables of the row replaced
+	// example: {"query":"4bf92f3577b34da6"}
+	Query map[string]any `json:"query"`
+}
+
+// swagger:model
+type ResolveCorrelationsResponseBody struct {
+	// Correlations of the row, the ones whose field is not in the row or whose target query uses variables the row
+	// does not define are left out
+	Correlations []ResolvedCorrelation `json:"correlations"`
+}
+
 type DeleteCorrelationsBySourceUIDCommand struct {
 	SourceUID       string
 	OrgId           int64
@@ -0,0 +1,186 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-logfmt/logfmt"
+)
+
+// variablePattern matches the variables of the target queries and of the template transformations: $name, ${name}
+// and ${name:format}, the format is ignored.
+var variablePattern = regexp.MustCompile(`\$\{([^}:]+)(?::[^}]*)?\}|\$(\w+)`)
+
+// Variables returns the variables of the correlation for a row of the source data. They are the values of the fields
+// of the row, __value.raw for the value of the field of the correlation, and the variables extracted by the
+// transformations, applied in order so a template can use the variables extracted before it.
+func (c CorrelationConfig) Variables(row map[string]any) (map[string]string, error) {
+	vars := make(map[string]string, len(row)+1)
+	for name, value := range row {
+		vars[name] = stringValue(value)
+	}
+	vars["__value.raw"] = stringValue(row[c.Field])
+
+	for _, t := range c.Transformations {
+		field := c.Field
+		if t.Field != "" {
+			field = t.Field
+		}
+		value := stringValue(row[field])
+		name := c.Field
+		if t.MapValue != "" {
+			name = t.MapValue
+		}
+
+		switch t.Type {
+		case "regex":
+			// the expression is case insensitive, like in the frontend
+			re, err := regexp.Compile("(?i)" + t.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidTransformationExpression, err)
+			}
+			names := re.SubexpNames()
+			named := false
+			for _, n := range names {
+				named = named || n != ""
+			}
+			for _, match := range re.FindAllStringSubmatch(value, -1) {
+				if named {
+					for i, n := range names {
+						if n != "" {
+							vars[n] = match[i]
+						}
+					}
+				} else if len(match) > 1 && match[1] != "" {
+					vars[name] = match[1]
+				} else {
+					vars[name] = match[0]
+				}
+			}
+		case "logfmt":
+			decoder := logfmt.NewDecoder(strings.NewReader(value))
+			for decoder.ScanRecord() {
+				for decoder.ScanKeyval() {
+					vars[string(decoder.Key())] = string(decoder.Value())
+				}
+			}
+			if err := decoder.Err(); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidTransformationExpression, err)
+			}
+		case "template":
+			vars[name], _ = interpolate(t.Expression, vars)
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTransformationType, t.Type)
+		}
+	}
+	return vars, nil
+}
+
+// TargetQuery returns the target query of the correlation with its variables replaced, and the names of the variables
+// it uses which are not defined, sorted. The target query is not changed.
+func (c CorrelationConfig) TargetQuery(vars map[string]string) (map[string]any, []string) {
+	missing := map[string]bool{}
+	query, _ := interpolateValue(c.Target, vars, missing).(map[string]any)
+	if query == nil {
+		query = map[string]any{}
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return query, names
+}
+
+// resolve returns the correlations of a row of the source data, with their transformations applied and the variables
+// of their target query replaced. A correlation is left out when its field is not in the row, when its
+// transformations fail, or when its target query uses variables which are not defined, like the links the frontend
+// doesn't show.
+func resolve(correlations []Correlation, row map[string]any) []ResolvedCorrelation {
+	resolved := make([]ResolvedCorrelation, 0, len(correlations))
+	for _, c := range correlations {
+		if _, ok := row[c.Config.Field]; !ok {
+			continue
+		}
+		vars, err := c.Config.Variables(row)
+		if err != nil {
+			logger.Debug("Failed to apply the transformations of the correlation", "uid", c.UID, "error", err)
+			continue
+		}
+		query, missing := c.Config.TargetQuery(vars)
+		if len(missing) > 0 {
+			continue
+		}
+		resolved = append(resolved, ResolvedCorrelation{
+			UID:         c.UID,
+			TargetUID:   c.TargetUID,
+			Label:       c.Label,
+			Description: c.Description,
+			Field:       c.Config.Field,
+			Query:       query,
+		})
+	}
+	return resolved
+}
+
+func interpolateValue(value any, vars map[string]string, missing map[string]bool) any {
+	switch v := value.(type) {
+	case string:
+		s, undefined := interpolate(v, vars)
+		for _, name := range undefined {
+			missing[name] = true
+		}
+		return s
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = interpolateValue(item, vars, missing)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = interpolateValue(item, vars, missing)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// interpolate replaces the variables of a string, the undefined variables are kept and returned.
+func interpolate(s string, vars map[string]string) (string, []string) {
+	var undefined []string
+	out := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		name := groups[1]
+		if name == "" {
+			name = groups[2]
+		}
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		undefined = append(undefined, name)
+		return match
+	})
+	return out, undefined
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,99 @@
+package correlations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationTransformations(t *testing.T) {
+	row := map[string]any{
+		"message": `level=error traceID=4bf92f3577b34da6 msg="request failed" path=/api/users/42`,
+		"status":  500,
+	}
+
+	t.Run("Extracts the variables with the transformations in order", func(t *testing.T) {
+		config := CorrelationConfig{
+			Field: "message",
+			Target: map[string]any{
+				"expr":   `{service="$service"} |= "${traceID}"`,
+				"labels": []any{"${userID}", "$status"},
+			},
+			Transformations: Transformations{
+				{Type: "logfmt"},
+				{Type: "regex", Expression: `users/(\d+)`, MapValue: "userID"},
+				{Type: "regex", Field: "path", Expression: `^/(?P<service>\w+)/`},
+				{Type: "template", Expression: "${service}-${userID}", MapValue: "key"},
+			},
+		}
+
+		vars, err := config.Variables(row)
+		require.NoError(t, err)
+		require.Equal(t, "4bf92f3577b34da6", vars["traceID"])
+		require.Equal(t, "42", vars["userID"])
+		require.Equal(t, "api", vars["service"])
+		require.Equal(t, "api-42", vars["key"])
+		require.Equal(t, "500", vars["status"])
+		require.Equal(t, row["message"], vars["__value.raw"])
+
+		query, missing := config.TargetQuery(vars)
+		require.Equal(t, map[string]any{
+			"expr":   `{service="api"} |= "4bf92f3577b34da6"`,
+			"labels": []any{"42", "500"},
+		}, query)
+		require.Empty(t, missing)
+		require.Equal(t, `{service="$service"} |= "${traceID}"`, config.Target["expr"], "the target is not changed")
+	})
+
+	t.Run("Uses the whole match or the field name without capture group or map value", func(t *testing.T) {
+		config := CorrelationConfig{
+			Field:           "message",
+			Transformations: Transformations{{Type: "regex", Expression: `TRACEID=\w+`}},
+		}
+		vars, err := config.Variables(row)
+		require.NoError(t, err)
+		require.Equal(t, "traceID=4bf92f3577b34da6", vars["message"], "the expression is case insensitive")
+	})
+
+	t.Run("Returns the variables missing from the sample data", func(t *testing.T) {
+		config := CorrelationConfig{Field: "message", Target: map[string]any{"expr": "${traceID:raw} $spanID $status"}}
+		vars, err := config.Variables(row)
+		require.NoError(t, err)
+		query, missing := config.TargetQuery(vars)
+		require.Equal(t, []string{"spanID", "traceID"}, missing)
+		require.Equal(t, "${traceID:raw} $spanID 500", query["expr"])
+	})
+
+	t.Run("Fails on invalid expressions", func(t *testing.T) {
+		config := CorrelationConfig{Field: "message", Transformations: Transformations{{Type: "regex", Expression: "(unclosed"}}}
+		_, err := config.Variables(row)
+		require.ErrorIs(t, err, ErrInvalidTransformationExpression)
+	})
+}
+
+func TestResolveCorrelations(t *testing.T) {
+	targetUID := "tempo"
+	correlation := func(uid string, config CorrelationConfig) Correlation {
+		return Correlation{UID: uid, TargetUID: &targetUID, Label: uid, Config: config}
+	}
+	correlations := []Correlation{
+		correlation("trace", CorrelationConfig{
+			Field:           "message",
+			Target:          map[string]any{"query": "${traceID}"},
+			Transformations: Transformations{{Type: "logfmt"}, {Type: "template", Expression: "trace-${traceID}", MapValue: "key"}},
+		}),
+		correlation("key", CorrelationConfig{
+			Field:           "message",
+			Target:          map[string]any{"query": "${key}"},
+			Transformations: Transformations{{Type: "logfmt"}, {Type: "template", Expression: "trace-${traceID}", MapValue: "key"}},
+		}),
+		correlation("missing-variable", CorrelationConfig{Field: "message", Target: map[string]any{"query": "${userID}"}}),
+		correlation("other-field", CorrelationConfig{Field: "path", Target: map[string]any{"query": "$path"}}),
+	}
+
+	resolved := resolve(correlations, map[string]any{"message": "level=error traceID=4bf92f3577b34da6"})
+	require.Equal(t, []ResolvedCorrelation{
+		{UID: "trace", TargetUID: &targetUID, Label: "trace", Field: "message", Query: map[string]any{"query": "4bf92f3577b34da6"}},
+		{UID: "key", TargetUID: &targetUID, Label: "key", Field: "message", Query: map[string]any{"query": "trace-4bf92f3577b34da6"}},
+	}, resolved)
+}
@@ -0,0 +1,128 @@
+package credentials
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/credentials", func(entities routing.RouteRegister) {
+		entities.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.listHandler))
+		entities.Post("/", middleware.ReqOrgAdmin, routing.Wrap(s.createHandler))
+		entities.Get("/status", middleware.ReqOrgAdmin, routing.Wrap(s.listStatusHandler))
+		entities.Get("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.getHandler))
+		entities.Put("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.updateHandler))
+		entities.Delete("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.deleteHandler))
+		entities.Get("/:uid/status", middleware.ReqOrgAdmin, routing.Wrap(s.statusHandler))
+		entities.Get("/:uid/secrets", middleware.ReqOrgAdmin, routing.Wrap(s.secretsHandler))
+		entities.Post("/:uid/rotate", middleware.ReqOrgAdmin, routing.Wrap(s.rotateHandler))
+	})
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	credentials, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list credentials", err)
+	}
+	return response.JSON(http.StatusOK, credentials)
+}
+
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CredentialCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	credential, err := s.Create(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to create credential")
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	credential, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to get credential")
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CredentialCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	credential, err := s.Update(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to update credential")
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err, "Failed to delete credential")
+	}
+	return response.Success("Credential deleted")
+}
+
+// listStatusHandler returns the rotation status of every credential of the organization.
+func (s *Service) listStatusHandler(c *contextmodel.ReqContext) response.Response {
+	credentials, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list credentials", err)
+	}
+	statuses := make([]RotationStatus, 0, len(credentials))
+	for _, credential := range credentials {
+		statuses = append(statuses, s.Status(credential))
+	}
+	return response.JSON(http.StatusOK, statuses)
+}
+
+func (s *Service) statusHandler(c *contextmodel.ReqContext) response.Response {
+	credential, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to get credential")
+	}
+	return response.JSON(http.StatusOK, s.Status(credential))
+}
+
+// secretsHandler returns the valid secrets of a credential, to configure the receivers during a rotation.
+func (s *Service) secretsHandler(c *contextmodel.ReqContext) response.Response {
+	secrets, err := s.Secrets(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to get credential secrets")
+	}
+	return response.JSON(http.StatusOK, secrets)
+}
+
+func (s *Service) rotateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := RotateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	credential, err := s.Rotate(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to rotate credential")
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrCredentialNotFound):
+		return response.Error(http.StatusNotFound, "Credential not found", err)
+	case errors.Is(err, ErrCredentialExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidCredential):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	default:
+		return response.Error(http.StatusInternalServerError, message, err)
+	}
+}
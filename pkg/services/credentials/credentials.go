@@ -0,0 +1,388 @@
+// Package credentials manages the secrets Grafana sends to outbound integrations, like the API keys of webhook
+// contact points. Secrets are kept in the secrets store and rotated on a schedule, with a dual-validity window so
+// that the receivers can accept a new secret before Grafana starts sending it.
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	tickInterval = time.Minute
+	secretLength = 40
+
+	// secretType and pendingSecretType are the types of the current and the pending secret of a credential in the
+	// secrets store, under the UID of the credential.
+	secretType        = "credential"
+	pendingSecretType = "credential-pending"
+)
+
+var (
+	nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	// referenceRegexp matches the references to credentials in the settings of integrations.
+	referenceRegexp = regexp.MustCompile(`\$__credential\{([a-zA-Z0-9_.-]+)\}`)
+)
+
+// Service manages the credentials of outbound integrations and rotates them on schedule.
+type Service struct {
+	store      db.DB
+	secrets    kvstore.SecretsKVStore
+	serverLock *serverlock.ServerLockService
+	log        log.Logger
+	now        func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, secretsStore kvstore.SecretsKVStore, serverLock *serverlock.ServerLockService,
+	routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		store:      sqlStore,
+		secrets:    secretsStore,
+		serverLock: serverLock,
+		log:        log.New("credentials"),
+		now:        time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Run activates the pending secrets and rotates the credentials that are due until ctx is done. Only one Grafana
+// instance rotates credentials at a time.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		err := s.serverLock.LockAndExecute(ctx, "rotate credentials", tickInterval/2, func(ctx context.Context) {
+			s.rotateDue(ctx)
+		})
+		if err != nil {
+			s.log.Error("Failed to rotate credentials", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Create stores a new credential and its secret, and returns the secret.
+func (s *Service) Create(ctx context.Context, user identity.Requester, cmd CredentialCommand) (*CredentialWithSecret, error) {
+	now := s.now()
+	c := &Credential{
+		UID:       util.GenerateShortUID(),
+		OrgID:     user.GetOrgID(),
+		CreatedBy: user.GetUID(),
+		Version:   1,
+		RotatedAt: now.Unix(),
+		Created:   now.Unix(),
+		Updated:   now.Unix(),
+	}
+	if err := apply(c, cmd); err != nil {
+		return nil, err
+	}
+	c.NextRotationAt = nextRotation(c, now)
+
+	secret, err := secretOrGenerate(cmd.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.insert(ctx, c); err != nil {
+		return nil, err
+	}
+	if err := s.secrets.Set(ctx, c.OrgID, c.UID, secretType, secret); err != nil {
+		// don't keep a credential without a secret
+		if delErr := s.delete(ctx, c.OrgID, c.UID); delErr != nil {
+			s.log.Error("Failed to delete credential without secret", "uid", c.UID, "error", delErr)
+		}
+		return nil, fmt.Errorf("failed to store secret: %w", err)
+	}
+	return &CredentialWithSecret{Credential: c, Secret: secret}, nil
+}
+
+// Update changes the name, the description and the rotation schedule of a credential.
+func (s *Service) Update(ctx context.Context, orgID int64, uid string, cmd CredentialCommand) (*Credential, error) {
+	c, err := s.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(c, cmd); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	c.NextRotationAt = nextRotation(c, time.Unix(c.RotatedAt, 0))
+	c.Updated = now.Unix()
+	if err := s.update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Delete deletes a credential and its secrets.
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	if err := s.delete(ctx, orgID, uid); err != nil {
+		return err
+	}
+	for _, typ := range []string{secretType, pendingSecretType} {
+		if err := s.secrets.Del(ctx, orgID, uid, typ); err != nil {
+			s.log.Warn("Failed to delete credential secret", "uid", uid, "error", err)
+		}
+	}
+	return nil
+}
+
+// Get returns a credential of an organization, without its secrets.
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*Credential, error) {
+	return s.get(ctx, orgID, uid)
+}
+
+// List returns the credentials of an organization, sorted by name.
+func (s *Service) List(ctx context.Context, orgID int64) ([]*Credential, error) {
+	return s.list(ctx, orgID)
+}
+
+// Status returns the rotation status of a credential.
+func (s *Service) Status(c *Credential) RotationStatus {
+	now := s.now()
+	status := RotationStatus{
+		UID:               c.UID,
+		Name:              c.Name,
+		Version:           c.Version,
+		State:             StateActive,
+		RotatedAt:         c.RotatedAt,
+		PendingActiveAt:   c.PendingActiveAt,
+		NextRotationAt:    c.NextRotationAt,
+		LastRotationError: c.LastRotationError,
+	}
+	switch {
+	case c.LastRotationError != "":
+		status.State = StateFailed
+	case c.PendingActiveAt > 0:
+		status.State = StateRotating
+	case c.NextRotationAt > 0 && now.Sub(time.Unix(c.NextRotationAt, 0)) > 2*tickInterval:
+		status.State = StateOverdue
+	}
+	return status
+}
+
+// Secrets returns the valid secrets of a credential, for the receivers to accept either during a rotation.
+func (s *Service) Secrets(ctx context.Context, orgID int64, uid string) (*Secrets, error) {
+	c, err := s.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	current, pending, err := s.secretsOf(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	result := &Secrets{Version: c.Version, Current: current}
+	if pending != "" {
+		result.Pending = pending
+		result.PendingActiveAt = c.PendingActiveAt
+	}
+	return result, nil
+}
+
+// Rotate starts the rotation of the secret of a credential: the new secret is valid right away, and replaces the
+// current one once the overlap period of the credential has passed.
+func (s *Service) Rotate(ctx context.Context, orgID int64, uid string, cmd RotateCommand) (*CredentialWithSecret, error) {
+	c, err := s.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := secretOrGenerate(cmd.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rotate(ctx, c, secret); err != nil {
+		return nil, err
+	}
+	return &CredentialWithSecret{Credential: c, Secret: secret}, nil
+}
+
+func (s *Service) rotate(ctx context.Context, c *Credential, secret string) error {
+	// a rotation requested during the overlap period of the previous one replaces its pending secret
+	if err := s.secrets.Set(ctx, c.OrgID, c.UID, pendingSecretType, secret); err != nil {
+		return fmt.Errorf("failed to store secret: %w", err)
+	}
+
+	now := s.now()
+	c.Version++
+	c.RotatedAt = now.Unix()
+	c.PendingActiveAt = now.Add(c.overlap()).Unix()
+	c.NextRotationAt = nextRotation(c, now)
+	c.LastRotationError = ""
+	c.Updated = now.Unix()
+	if err := s.update(ctx, c); err != nil {
+		return err
+	}
+	if c.OverlapSeconds == 0 {
+		return s.activate(ctx, c)
+	}
+	return nil
+}
+
+// activate replaces the current secret of a credential with the pending one.
+func (s *Service) activate(ctx context.Context, c *Credential) error {
+	pending, ok, err := s.secrets.Get(ctx, c.OrgID, c.UID, pendingSecretType)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := s.secrets.Set(ctx, c.OrgID, c.UID, secretType, pending); err != nil {
+			return err
+		}
+		if err := s.secrets.Del(ctx, c.OrgID, c.UID, pendingSecretType); err != nil {
+			return err
+		}
+	}
+	c.PendingActiveAt = 0
+	c.Updated = s.now().Unix()
+	return s.update(ctx, c)
+}
+
+// rotateDue activates the pending secrets whose overlap period has passed and rotates the credentials whose
+// scheduled rotation is due.
+func (s *Service) rotateDue(ctx context.Context) {
+	now := s.now()
+	due, err := s.listDue(ctx, now.Unix())
+	if err != nil {
+		s.log.Error("Failed to list credentials to rotate", "error", err)
+		return
+	}
+
+	for _, c := range due {
+		if c.pendingActive(now) {
+			if err := s.activate(ctx, c); err != nil {
+				s.log.Error("Failed to activate the new secret of a credential", "uid", c.UID, "orgId", c.OrgID, "error", err)
+			}
+			continue
+		}
+		if c.PendingActiveAt > 0 || c.NextRotationAt == 0 || c.NextRotationAt > now.Unix() {
+			continue
+		}
+
+		secret, err := secretOrGenerate("")
+		if err == nil {
+			err = s.rotate(ctx, c, secret)
+		}
+		if err != nil {
+			s.log.Error("Failed to rotate credential", "uid", c.UID, "orgId", c.OrgID, "error", err)
+			c.LastRotationError = err.Error()
+			c.NextRotationAt = now.Add(tickInterval).Unix()
+			if err := s.update(ctx, c); err != nil {
+				s.log.Error("Failed to store credential rotation error", "uid", c.UID, "error", err)
+			}
+			continue
+		}
+		s.log.Info("Rotated credential", "uid", c.UID, "orgId", c.OrgID, "version", c.Version)
+	}
+}
+
+// Resolve replaces the $__credential{name} references in value with the secrets Grafana currently sends for the
+// credentials of the organization.
+func (s *Service) Resolve(ctx context.Context, orgID int64, value string) (string, error) {
+	if !strings.Contains(value, "$__credential{") {
+		return value, nil
+	}
+
+	var resolveErr error
+	resolved := referenceRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		if resolveErr != nil {
+			return ref
+		}
+		name := referenceRegexp.FindStringSubmatch(ref)[1]
+		secret, err := s.activeSecret(ctx, orgID, name)
+		if err != nil {
+			resolveErr = fmt.Errorf("failed to resolve credential %q: %w", name, err)
+			return ref
+		}
+		return secret
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// activeSecret returns the secret Grafana sends for a credential: the pending secret of a rotation once its overlap
+// period has passed, even if it has not been activated yet, and the current secret otherwise.
+func (s *Service) activeSecret(ctx context.Context, orgID int64, name string) (string, error) {
+	c, err := s.getByName(ctx, orgID, name)
+	if err != nil {
+		return "", err
+	}
+	current, pending, err := s.secretsOf(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	if pending != "" && c.pendingActive(s.now()) {
+		return pending, nil
+	}
+	return current, nil
+}
+
+func (s *Service) secretsOf(ctx context.Context, c *Credential) (string, string, error) {
+	current, ok, err := s.secrets.Get(ctx, c.OrgID, c.UID, secretType)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return "", "", fmt.Errorf("the secret of credential %q is missing", c.Name)
+	}
+	pending := ""
+	if c.PendingActiveAt > 0 {
+		if pending, _, err = s.secrets.Get(ctx, c.OrgID, c.UID, pendingSecretType); err != nil {
+			return "", "", err
+		}
+	}
+	return current, pending, nil
+}
+
+// apply validates the command and sets its fields on the credential.
+func apply(c *Credential, cmd CredentialCommand) error {
+	if !nameRegexp.MatchString(cmd.Name) {
+		return fmt.Errorf("%w: the name can only contain letters, digits, '_', '.' and '-'", ErrInvalidCredential)
+	}
+	if cmd.RotationIntervalSeconds < 0 || cmd.OverlapSeconds < 0 {
+		return fmt.Errorf("%w: the rotation interval and the overlap can't be negative", ErrInvalidCredential)
+	}
+	if cmd.RotationIntervalSeconds > 0 && cmd.RotationIntervalSeconds < int64(time.Hour/time.Second) {
+		return fmt.Errorf("%w: the rotation interval must be at least 1h", ErrInvalidCredential)
+	}
+	if cmd.RotationIntervalSeconds > 0 && cmd.OverlapSeconds >= cmd.RotationIntervalSeconds {
+		return fmt.Errorf("%w: the overlap must be shorter than the rotation interval", ErrInvalidCredential)
+	}
+	c.Name = cmd.Name
+	c.Description = cmd.Description
+	c.RotationIntervalSeconds = cmd.RotationIntervalSeconds
+	c.OverlapSeconds = cmd.OverlapSeconds
+	return nil
+}
+
+// nextRotation returns when a credential rotated at rotatedAt is rotated next, 0 if it is only rotated on request.
+func nextRotation(c *Credential, rotatedAt time.Time) int64 {
+	if c.RotationIntervalSeconds == 0 {
+		return 0
+	}
+	return rotatedAt.Add(c.rotationInterval()).Unix()
+}
+
+func secretOrGenerate(secret string) (string, error) {
+	if secret != "" {
+		return secret, nil
+	}
+	return util.GetRandomString(secretLength)
+}
@@ -0,0 +1,120 @@
+package credentials
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestApply(t *testing.T) {
+	valid := func() CredentialCommand {
+		return CredentialCommand{Name: "webhook.api-key", RotationIntervalSeconds: 86400, OverlapSeconds: 3600}
+	}
+
+	c := &Credential{}
+	require.NoError(t, apply(c, valid()))
+	require.Equal(t, int64(1700086400), nextRotation(c, time.Unix(1700000000, 0)))
+
+	tests := map[string]func(cmd *CredentialCommand){
+		"invalid name":      func(cmd *CredentialCommand) { cmd.Name = "api key" },
+		"negative overlap":  func(cmd *CredentialCommand) { cmd.OverlapSeconds = -1 },
+		"short interval":    func(cmd *CredentialCommand) { cmd.RotationIntervalSeconds = 60 },
+		"overlap too large": func(cmd *CredentialCommand) { cmd.OverlapSeconds = 86400 },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := valid()
+			modify(&cmd)
+			require.ErrorIs(t, apply(&Credential{}, cmd), ErrInvalidCredential)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := &Service{now: func() time.Time { return now }}
+
+	require.Equal(t, StateActive, s.Status(&Credential{NextRotationAt: now.Unix()}).State)
+	require.Equal(t, StateRotating, s.Status(&Credential{PendingActiveAt: now.Add(time.Hour).Unix()}).State)
+	require.Equal(t, StateOverdue, s.Status(&Credential{NextRotationAt: now.Add(-time.Hour).Unix()}).State)
+	require.Equal(t, StateFailed, s.Status(&Credential{LastRotationError: "boom"}).State)
+}
+
+func TestIntegrationCredentialRotation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Unix(1700000000, 0)
+	s := &Service{
+		store:   db.InitTestDB(t),
+		secrets: kvstore.NewFakeSecretsKVStore(),
+		log:     log.NewNopLogger(),
+		now:     func() time.Time { return now },
+	}
+	ctx := context.Background()
+	signedInUser := &user.SignedInUser{OrgID: 1, UserUID: "u1"}
+
+	created, err := s.Create(ctx, signedInUser, CredentialCommand{Name: "webhook", RotationIntervalSeconds: 86400, OverlapSeconds: 3600, Secret: "first"})
+	require.NoError(t, err)
+	_, err = s.Create(ctx, signedInUser, CredentialCommand{Name: "webhook"})
+	require.ErrorIs(t, err, ErrCredentialExists)
+
+	resolved, err := s.Resolve(ctx, 1, "Bearer $__credential{webhook}")
+	require.NoError(t, err)
+	require.Equal(t, "Bearer first", resolved)
+	_, err = s.Resolve(ctx, 2, "Bearer $__credential{webhook}")
+	require.ErrorIs(t, err, ErrCredentialNotFound)
+
+	// the scheduled rotation starts the dual-validity window, Grafana keeps sending the current secret
+	now = now.Add(24 * time.Hour)
+	s.rotateDue(ctx)
+	secrets, err := s.Secrets(ctx, 1, created.UID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), secrets.Version)
+	require.Equal(t, "first", secrets.Current)
+	require.NotEmpty(t, secrets.Pending)
+	c, err := s.Get(ctx, 1, created.UID)
+	require.NoError(t, err)
+	require.Equal(t, StateRotating, s.Status(c).State)
+	resolved, err = s.Resolve(ctx, 1, "$__credential{webhook}")
+	require.NoError(t, err)
+	require.Equal(t, "first", resolved)
+
+	// once the overlap has passed, the pending secret replaces the current one
+	now = now.Add(time.Hour)
+	s.rotateDue(ctx)
+	current, err := s.Secrets(ctx, 1, created.UID)
+	require.NoError(t, err)
+	require.Equal(t, secrets.Pending, current.Current)
+	require.Empty(t, current.Pending)
+	c, err = s.Get(ctx, 1, created.UID)
+	require.NoError(t, err)
+	require.Equal(t, StateActive, s.Status(c).State)
+	require.Equal(t, now.Add(23*time.Hour).Unix(), c.NextRotationAt)
+
+	// without overlap, a rotation replaces the secret right away
+	_, err = s.Update(ctx, 1, created.UID, CredentialCommand{Name: "webhook"})
+	require.NoError(t, err)
+	_, err = s.Rotate(ctx, 1, created.UID, RotateCommand{Secret: "manual"})
+	require.NoError(t, err)
+	resolved, err = s.Resolve(ctx, 1, "$__credential{webhook}")
+	require.NoError(t, err)
+	require.Equal(t, "manual", resolved)
+
+	require.NoError(t, s.Delete(ctx, 1, created.UID))
+	_, err = s.Get(ctx, 1, created.UID)
+	require.ErrorIs(t, err, ErrCredentialNotFound)
+}
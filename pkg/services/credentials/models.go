@@ -0,0 +1,109 @@
+package credentials
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCredentialNotFound = errors.New("credential not found")
+	ErrCredentialExists   = errors.New("a credential with the same name already exists")
+	ErrInvalidCredential  = errors.New("invalid credential")
+)
+
+// Credential is a secret Grafana sends to an outbound integration, like the API key of a webhook contact point.
+// Only the metadata of the credential is stored here, the secrets are kept in the secrets store.
+type Credential struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID   string `xorm:"uid" json:"uid"`
+	OrgID int64  `xorm:"org_id" json:"orgId"`
+	// Name is unique in the organization, and references the credential as $__credential{name}.
+	Name        string `xorm:"name" json:"name"`
+	Description string `xorm:"description" json:"description"`
+	// RotationIntervalSeconds is how often the secret is rotated. 0 means the secret is only rotated on request.
+	RotationIntervalSeconds int64 `xorm:"rotation_interval_seconds" json:"rotationIntervalSeconds"`
+	// OverlapSeconds is the dual-validity window of a rotation: the current and the new secret are both valid for
+	// that long, and Grafana keeps sending the current one, so that the receivers can accept the new secret before
+	// it is used.
+	OverlapSeconds int64 `xorm:"overlap_seconds" json:"overlapSeconds"`
+	// Version is incremented on every rotation.
+	Version   int64 `xorm:"version" json:"version"`
+	RotatedAt int64 `xorm:"rotated_at" json:"rotatedAt"`
+	// PendingActiveAt is when the new secret of a rotation replaces the current one, 0 if no rotation is pending.
+	PendingActiveAt int64 `xorm:"pending_active_at" json:"pendingActiveAt"`
+	// NextRotationAt is when the secret is rotated next, 0 if it is only rotated on request.
+	NextRotationAt    int64  `xorm:"next_rotation_at" json:"nextRotationAt"`
+	LastRotationError string `xorm:"last_rotation_error" json:"lastRotationError,omitempty"`
+	CreatedBy         string `xorm:"created_by" json:"createdBy"`
+	Created           int64  `xorm:"created" json:"created"`
+	Updated           int64  `xorm:"updated" json:"updated"`
+}
+
+func (Credential) TableName() string {
+	return "credential"
+}
+
+func (c *Credential) overlap() time.Duration {
+	return time.Duration(c.OverlapSeconds) * time.Second
+}
+
+func (c *Credential) rotationInterval() time.Duration {
+	return time.Duration(c.RotationIntervalSeconds) * time.Second
+}
+
+// pendingActive returns true if the new secret of a pending rotation has replaced the current one.
+func (c *Credential) pendingActive(now time.Time) bool {
+	return c.PendingActiveAt > 0 && now.Unix() >= c.PendingActiveAt
+}
+
+// CredentialCommand creates or updates a credential.
+type CredentialCommand struct {
+	Name                    string `json:"name"`
+	Description             string `json:"description"`
+	RotationIntervalSeconds int64  `json:"rotationIntervalSeconds"`
+	OverlapSeconds          int64  `json:"overlapSeconds"`
+	// Secret is the initial secret of a new credential. A random secret is generated if it is empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+// RotateCommand rotates the secret of a credential.
+type RotateCommand struct {
+	// Secret is the new secret. A random secret is generated if it is empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+// CredentialWithSecret is returned when a credential is created or rotated, the only times the new secret is returned.
+type CredentialWithSecret struct {
+	*Credential
+	Secret string `json:"secret"`
+}
+
+// Secrets are the valid secrets of a credential. During the dual-validity window of a rotation, both the current
+// and the pending secret are valid, and receivers must accept either.
+type Secrets struct {
+	Version         int64  `json:"version"`
+	Current         string `json:"current"`
+	Pending         string `json:"pending,omitempty"`
+	PendingActiveAt int64  `json:"pendingActiveAt,omitempty"`
+}
+
+const (
+	StateActive   = "active"
+	StateRotating = "rotating"
+	StateOverdue  = "overdue"
+	StateFailed   = "failed"
+)
+
+// RotationStatus is the rotation status of a credential.
+type RotationStatus struct {
+	UID     string `json:"uid"`
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+	// State is active, rotating during the dual-validity window of a rotation, overdue if a scheduled rotation
+	// is late, or failed if the last scheduled rotation failed.
+	State             string `json:"state"`
+	RotatedAt         int64  `json:"rotatedAt"`
+	PendingActiveAt   int64  `json:"pendingActiveAt,omitempty"`
+	NextRotationAt    int64  `json:"nextRotationAt,omitempty"`
+	LastRotationError string `json:"lastRotationError,omitempty"`
+}
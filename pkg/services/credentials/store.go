@@ -0,0 +1,102 @@
+package credentials
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) insert(ctx context.Context, c *Credential) error {
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+			exists, err := sess.Where("org_id = ? AND name = ?", c.OrgID, c.Name).Exist(&Credential{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return ErrCredentialExists
+			}
+			_, err = sess.Insert(c)
+			return err
+		})
+	})
+}
+
+func (s *Service) update(ctx context.Context, c *Credential) error {
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+			exists, err := sess.Where("org_id = ? AND name = ? AND uid <> ?", c.OrgID, c.Name, c.UID).Exist(&Credential{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return ErrCredentialExists
+			}
+			affected, err := sess.Where("org_id = ? AND uid = ?", c.OrgID, c.UID).AllCols().Omit("id", "created", "created_by").Update(c)
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				return ErrCredentialNotFound
+			}
+			return nil
+		})
+	})
+}
+
+func (s *Service) get(ctx context.Context, orgID int64, uid string) (*Credential, error) {
+	return s.getWhere(ctx, "org_id = ? AND uid = ?", orgID, uid)
+}
+
+func (s *Service) getByName(ctx context.Context, orgID int64, name string) (*Credential, error) {
+	return s.getWhere(ctx, "org_id = ? AND name = ?", orgID, name)
+}
+
+func (s *Service) getWhere(ctx context.Context, query string, args ...any) (*Credential, error) {
+	c := &Credential{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where(query, args...).Get(c)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCredentialNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Service) list(ctx context.Context, orgID int64) ([]*Credential, error) {
+	credentials := make([]*Credential, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&credentials)
+	})
+	return credentials, err
+}
+
+// listDue returns the credentials with a pending secret to activate or a scheduled rotation due at now.
+func (s *Service) listDue(ctx context.Context, now int64) ([]*Credential, error) {
+	credentials := make([]*Credential, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("(pending_active_at > 0 AND pending_active_at <= ?) OR (next_rotation_at > 0 AND next_rotation_at <= ?)", now, now).
+			Find(&credentials)
+	})
+	return credentials, err
+}
+
+func (s *Service) delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Credential{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrCredentialNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package apply
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	routeRegister.Post("/api/dashboards/apply",
+		authorize(ac.EvalAny(ac.EvalPermission(dashboards.ActionDashboardsCreate), ac.EvalPermission(dashboards.ActionDashboardsWrite))),
+		routing.Wrap(s.applyHandler))
+}
+
+// applyHandler returns the plan of a bundle of dashboards for a dry run, and applies it otherwise.
+func (s *Service) applyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ApplyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	plan, err := s.Apply(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, plan)
+}
+
+// ErrorResponse returns the response of an error of Apply.
+func ErrorResponse(err error) response.Response {
+	var dashboardErr dashboards.DashboardErr
+	switch {
+	case errors.Is(err, ErrInvalidBundle), errors.Is(err, ErrPlanRequired):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrPlanChanged):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrQuotaReached):
+		return response.Error(http.StatusForbidden, "Quota reached", err)
+	case errors.Is(err, dashboards.ErrFolderNotFound), errors.Is(err, folder.ErrFolderNotFound):
+		return response.Error(http.StatusNotFound, "Folder not found", err)
+	case errors.As(err, &dashboardErr):
+		return response.Error(dashboardErr.StatusCode, err.Error(), err)
+	default:
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to apply dashboards", err)
+	}
+}
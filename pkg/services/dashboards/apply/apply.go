@@ -0,0 +1,338 @@
+// Package apply applies bundles of dashboards managed as code to a folder. Applying a bundle computes a plan of
+// the dashboards to create, update and delete, matched by UID, which can be previewed with a dry run and is then
+// applied in a single transaction.
+package apply
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+const (
+	maxBundleSize = 1000
+	searchLimit   = 1000
+)
+
+// Service applies bundles of dashboards.
+type Service struct {
+	store            db.DB
+	dashboardService dashboards.DashboardService
+	folderService    folder.Service
+	accessControl    ac.AccessControl
+	quotaService     quota.Service
+	log              log.Logger
+}
+
+func ProvideService(sqlStore db.DB, dashboardService dashboards.DashboardService, folderService folder.Service,
+	accessControl ac.AccessControl, quotaService quota.Service, routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		store:            sqlStore,
+		dashboardService: dashboardService,
+		folderService:    folderService,
+		accessControl:    accessControl,
+		quotaService:     quotaService,
+		log:              log.New("dashboards.apply"),
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Apply computes the plan of a bundle, and applies it unless the command is a dry run. The plan ID of the
+// command must be the ID of the current plan, so that the applied changes are the previewed ones.
+func (s *Service) Apply(ctx context.Context, user identity.Requester, cmd ApplyCommand) (*Plan, error) {
+	plan, err := s.Plan(ctx, user, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.DryRun {
+		return plan, nil
+	}
+	if cmd.PlanID == "" {
+		return nil, ErrPlanRequired
+	}
+	if cmd.PlanID != plan.ID {
+		return nil, ErrPlanChanged
+	}
+	if err := s.checkQuota(ctx, user, plan); err != nil {
+		return nil, err
+	}
+
+	err = s.store.InTransaction(ctx, func(ctx context.Context) error {
+		for _, change := range plan.Changes {
+			if err := s.applyChange(ctx, user, cmd, change); err != nil {
+				return fmt.Errorf("failed to %s dashboard %q: %w", change.Action, change.UID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	s.log.Info("Applied dashboard bundle", "orgId", user.GetOrgID(), "folderUid", cmd.FolderUID, "plan", plan.ID, "changes", len(plan.Changes))
+	return plan, nil
+}
+
+// checkQuota returns ErrQuotaReached if the plan creates dashboards and the dashboard quota is reached.
+func (s *Service) checkQuota(ctx context.Context, user identity.Requester, plan *Plan) error {
+	for _, change := range plan.Changes {
+		if change.Action != ActionCreate {
+			continue
+		}
+		params := &quota.ScopeParameters{OrgID: user.GetOrgID()}
+		if id, err := identity.UserIdentifier(user.GetID()); err == nil {
+			params.UserID = id
+		}
+		reached, err := s.quotaService.CheckQuotaReached(ctx, dashboards.QuotaTargetSrv, params)
+		if err != nil {
+			return err
+		}
+		if reached {
+			return ErrQuotaReached
+		}
+		return nil
+	}
+	return nil
+}
+
+func (s *Service) applyChange(ctx context.Context, user identity.Requester, cmd ApplyCommand, change *Change) error {
+	switch change.Action {
+	case ActionCreate, ActionUpdate:
+		dash := dashboards.NewDashboardFromJson(change.dashboard)
+		dash.OrgID = user.GetOrgID()
+		dash.FolderUID = cmd.FolderUID
+		if change.Action == ActionUpdate {
+			dash.SetID(change.id)
+			dash.SetVersion(change.Version)
+		}
+		_, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+			OrgID:     user.GetOrgID(),
+			User:      user,
+			Message:   cmd.Message,
+			Dashboard: dash,
+		}, false)
+		return err
+	case ActionDelete:
+		ok, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(dashboards.ActionDashboardsDelete, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(change.UID)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return dashboards.ErrDashboardUpdateAccessDenied
+		}
+		return s.dashboardService.DeleteDashboard(ctx, change.id, user.GetOrgID())
+	}
+	return nil
+}
+
+// Plan returns the changes that applying a bundle makes, without applying them.
+func (s *Service) Plan(ctx context.Context, user identity.Requester, cmd ApplyCommand) (*Plan, error) {
+	if err := validate(cmd); err != nil {
+		return nil, err
+	}
+	if cmd.FolderUID != "" {
+		if _, err := s.folderService.Get(ctx, &folder.GetFolderQuery{UID: &cmd.FolderUID, OrgID: user.GetOrgID(), SignedInUser: user}); err != nil {
+			return nil, err
+		}
+	}
+
+	uids := make([]string, 0, len(cmd.Dashboards))
+	for _, model := range cmd.Dashboards {
+		uids = append(uids, model.Get("uid").MustString())
+	}
+	current, err := s.dashboardService.GetDashboards(ctx, &dashboards.GetDashboardsQuery{DashboardUIDs: uids, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	byUID := make(map[string]*dashboards.Dashboard, len(current))
+	for _, dash := range current {
+		byUID[dash.UID] = dash
+	}
+
+	plan := &Plan{FolderUID: cmd.FolderUID, Changes: make([]*Change, 0, len(cmd.Dashboards))}
+	for _, model := range cmd.Dashboards {
+		change := &Change{
+			Action:    ActionCreate,
+			UID:       model.Get("uid").MustString(),
+			Title:     model.Get("title").MustString(),
+			dashboard: withoutVersion(model),
+		}
+		if dash, ok := byUID[change.UID]; ok {
+			change.id = dash.ID
+			change.Version = dash.Version
+			change.Action = ActionUpdate
+			if dash.FolderUID != cmd.FolderUID {
+				change.FromFolderUID = dash.FolderUID
+			} else if sameModel(dash.Data, model) {
+				change.Action = ActionUnchanged
+			}
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if cmd.Prune {
+		deletes, err := s.planDeletes(ctx, user, cmd.FolderUID, uids)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, deletes...)
+	}
+
+	plan.ID, err = planID(plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planDeletes returns the deletion of the dashboards of the folder that are not in the bundle.
+func (s *Service) planDeletes(ctx context.Context, user identity.Requester, folderUID string, uids []string) ([]*Change, error) {
+	inBundle := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		inBundle[uid] = true
+	}
+	if folderUID == "" {
+		folderUID = folder.GeneralFolderUID
+	}
+
+	deletes := make([]*Change, 0)
+	seen := map[int64]bool{}
+	for page := int64(1); ; page++ {
+		// the results have a row per tag of the dashboards
+		res, err := s.dashboardService.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+			SignedInUser: user,
+			FolderUIDs:   []string{folderUID},
+			Type:         searchstore.TypeDashboard,
+			Permission:   dashboardaccess.PERMISSION_VIEW,
+			Limit:        searchLimit,
+			Page:         page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		found := 0
+		for _, hit := range res {
+			if seen[hit.ID] {
+				continue
+			}
+			seen[hit.ID] = true
+			found++
+			if !inBundle[hit.UID] {
+				deletes = append(deletes, &Change{Action: ActionDelete, UID: hit.UID, Title: hit.Title, id: hit.ID})
+			}
+		}
+		if found < searchLimit {
+			break
+		}
+	}
+
+	if len(deletes) == 0 {
+		return deletes, nil
+	}
+	// the versions of the deleted dashboards are part of the plan ID
+	ids := make([]int64, 0, len(deletes))
+	for _, change := range deletes {
+		ids = append(ids, change.id)
+	}
+	current, err := s.dashboardService.GetDashboards(ctx, &dashboards.GetDashboardsQuery{DashboardIDs: ids, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[int64]int, len(current))
+	for _, dash := range current {
+		versions[dash.ID] = dash.Version
+	}
+	for _, change := range deletes {
+		change.Version = versions[change.id]
+	}
+	sort.Slice(deletes, func(i, j int) bool { return deletes[i].UID < deletes[j].UID })
+	return deletes, nil
+}
+
+func validate(cmd ApplyCommand) error {
+	if len(cmd.Dashboards) == 0 {
+		return fmt.Errorf("%w: the bundle has no dashboards", ErrInvalidBundle)
+	}
+	if len(cmd.Dashboards) > maxBundleSize {
+		return fmt.Errorf("%w: the bundle can't have more than %d dashboards", ErrInvalidBundle, maxBundleSize)
+	}
+	seen := make(map[string]bool, len(cmd.Dashboards))
+	for i, model := range cmd.Dashboards {
+		if model == nil {
+			return fmt.Errorf("%w: dashboard %d is empty", ErrInvalidBundle, i)
+		}
+		uid := model.Get("uid").MustString()
+		if uid == "" {
+			return fmt.Errorf("%w: dashboard %d has no uid", ErrInvalidBundle, i)
+		}
+		if model.Get("title").MustString() == "" {
+			return fmt.Errorf("%w: dashboard %q has no title", ErrInvalidBundle, uid)
+		}
+		if seen[uid] {
+			return fmt.Errorf("%w: dashboard %q is in the bundle more than once", ErrInvalidBundle, uid)
+		}
+		seen[uid] = true
+	}
+	return nil
+}
+
+// planID hashes the changes of the plan with the current versions and the new models of the dashboards.
+func planID(plan *Plan) (string, error) {
+	h := sha256.New()
+	if _, err := fmt.Fprintf(h, "%s\n", plan.FolderUID); err != nil {
+		return "", err
+	}
+	for _, change := range plan.Changes {
+		if _, err := fmt.Fprintf(h, "%s %s %d\n", change.Action, change.UID, change.Version); err != nil {
+			return "", err
+		}
+		if change.dashboard != nil {
+			if _, err := h.Write(normalized(change.dashboard)); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// withoutVersion returns a copy of the model of a dashboard of the bundle without its ID and version, which are
+// set from the current dashboard when it is saved.
+func withoutVersion(model *simplejson.Json) *simplejson.Json {
+	m := maps.Clone(model.MustMap())
+	delete(m, "id")
+	delete(m, "version")
+	return simplejson.NewFromAny(m)
+}
+
+func sameModel(current, model *simplejson.Json) bool {
+	return string(normalized(current)) == string(normalized(model))
+}
+
+func normalized(model *simplejson.Json) []byte {
+	m := maps.Clone(model.MustMap())
+	delete(m, "id")
+	delete(m, "version")
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestPlan(t *testing.T) {
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboards", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardsQuery) bool {
+		return len(q.DashboardUIDs) > 0
+	})).Return([]*dashboards.Dashboard{
+		{ID: 1, UID: "same", Version: 3, Data: simplejson.NewFromAny(map[string]any{"id": 1, "uid": "same", "title": "Same", "version": 3})},
+		{ID: 2, UID: "changed", Version: 5, Data: simplejson.NewFromAny(map[string]any{"id": 2, "uid": "changed", "title": "Old"})},
+		{ID: 3, UID: "moved", Version: 1, FolderUID: "other", Data: simplejson.NewFromAny(map[string]any{"uid": "moved", "title": "Moved"})},
+	}, nil)
+	dashboardService.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{
+		{ID: 1, UID: "same", Title: "Same", Term: "a"},
+		{ID: 1, UID: "same", Title: "Same", Term: "b"},
+		{ID: 4, UID: "removed", Title: "Removed"},
+	}, nil)
+	dashboardService.On("GetDashboards", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardsQuery) bool {
+		return len(q.DashboardIDs) > 0
+	})).Return([]*dashboards.Dashboard{{ID: 4, UID: "removed", Version: 7}}, nil)
+
+	s := &Service{dashboardService: dashboardService, log: log.NewNopLogger()}
+	cmd := ApplyCommand{
+		Dashboards: []*simplejson.Json{
+			simplejson.NewFromAny(map[string]any{"uid": "new", "title": "New"}),
+			simplejson.NewFromAny(map[string]any{"uid": "same", "title": "Same", "version": 1}),
+			simplejson.NewFromAny(map[string]any{"uid": "changed", "title": "New title"}),
+			simplejson.NewFromAny(map[string]any{"uid": "moved", "title": "Moved"}),
+		},
+		Prune: true,
+	}
+	signedInUser := &user.SignedInUser{OrgID: 1}
+
+	plan, err := s.Plan(context.Background(), signedInUser, cmd)
+	require.NoError(t, err)
+	require.NotEmpty(t, plan.ID)
+	actions := map[string]string{}
+	for _, change := range plan.Changes {
+		actions[change.UID] = change.Action
+	}
+	require.Equal(t, map[string]string{
+		"new":     ActionCreate,
+		"same":    ActionUnchanged,
+		"changed": ActionUpdate,
+		"moved":   ActionUpdate,
+		"removed": ActionDelete,
+	}, actions)
+	require.Equal(t, "other", plan.Changes[3].FromFolderUID)
+	require.Equal(t, 7, plan.Changes[4].Version)
+
+	again, err := s.Plan(context.Background(), signedInUser, cmd)
+	require.NoError(t, err)
+	require.Equal(t, plan.ID, again.ID, "the plan ID should be stable")
+
+	cmd.PlanID = "outdated"
+	_, err = s.Apply(context.Background(), signedInUser, cmd)
+	require.ErrorIs(t, err, ErrPlanChanged)
+	cmd.PlanID = ""
+	_, err = s.Apply(context.Background(), signedInUser, cmd)
+	require.ErrorIs(t, err, ErrPlanRequired)
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string][]*simplejson.Json{
+		"no dashboards": nil,
+		"no uid":        {simplejson.NewFromAny(map[string]any{"title": "A"})},
+		"no title":      {simplejson.NewFromAny(map[string]any{"uid": "a"})},
+		"duplicate uid": {
+			simplejson.NewFromAny(map[string]any{"uid": "a", "title": "A"}),
+			simplejson.NewFromAny(map[string]any{"uid": "a", "title": "B"}),
+		},
+	}
+	for name, bundle := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, validate(ApplyCommand{Dashboards: bundle}), ErrInvalidBundle)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package apply
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrInvalidBundle = errors.New("invalid dashboard bundle")
+	ErrPlanRequired  = errors.New("the plan ID is required to apply a bundle, get it with a dry run")
+	ErrPlanChanged   = errors.New("the dashboards changed since the plan was computed")
+	ErrQuotaReached  = errors.New("dashboard quota reached")
+)
+
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionUnchanged = "unchanged"
+)
+
+// ApplyCommand applies a bundle of dashboards to a folder.
+type ApplyCommand struct {
+	// FolderUID is the folder of the dashboards of the bundle, the General folder if it is empty.
+	FolderUID string `json:"folderUid"`
+	// Dashboards are the JSON models of the dashboards, matched with the current dashboards by UID.
+	Dashboards []*simplejson.Json `json:"dashboards"`
+	// Prune deletes the dashboards of the folder that are not in the bundle.
+	Prune bool `json:"prune"`
+	// DryRun returns the plan without applying it.
+	DryRun bool `json:"dryRun"`
+	// PlanID is the ID of the plan returned by a dry run. The bundle is only applied if the plan is unchanged,
+	// so that what is applied is what was previewed.
+	PlanID string `json:"planId"`
+	// Message is the message of the new dashboard versions.
+	Message string `json:"message"`
+}
+
+// Plan is the list of changes that applying a bundle makes.
+type Plan struct {
+	// ID identifies the plan and the current versions of the dashboards it changes.
+	ID        string    `json:"id"`
+	FolderUID string    `json:"folderUid"`
+	Changes   []*Change `json:"changes"`
+	// Applied is true if the plan was applied, false for a dry run.
+	Applied bool `json:"applied"`
+}
+
+// Change is the change of a dashboard.
+type Change struct {
+	Action string `json:"action"`
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	// FromFolderUID is the current folder of a dashboard that is moved to the folder of the bundle.
+	FromFolderUID string `json:"fromFolderUid,omitempty"`
+	// Version is the current version of an updated, deleted or unchanged dashboard.
+	Version int `json:"version,omitempty"`
+
+	id        int64
+	dashboard *simplejson.Json
+}
@@ -34,6 +34,15 @@ type DashboardService interface {
 	RestoreDashboard(ctx context.Context, dashboard *Dashboard, user identity.Requester, optionalFolderUID string) error
 	CleanUpDeletedDashboards(ctx context.Context) (int64, error)
 	GetSoftDeletedDashboard(ctx context.Context, orgID int64, uid string) (*Dashboard, error)
+	// GetSoftDeletedDashboards returns the dashboards and folders in the trash of an organization.
+	GetSoftDeletedDashboards(ctx context.Context, orgID int64) ([]*Dashboard, error)
+	// GetDashboardSizes returns the sizes of the dashboards of an organization, from the largest.
+	GetDashboardSizes(ctx context.Context, query *GetDashboardSizesQuery) ([]*DashboardSize, error)
+	// GetDashboardSizeLimit returns the maximum size in bytes of the dashboards of an organization, zero means no limit.
+	GetDashboardSizeLimit(ctx context.Context, orgID int64) (int64, error)
+	// SetDashboardSizeLimit sets the maximum size in bytes of the dashboards of an organization, nil resets it to the
+	// limit of the configuration.
+	SetDashboardSizeLimit(ctx context.Context, orgID int64, limit *int64) error
 }
 
 // PluginService is a service for operating on plugin dashboards.
@@ -89,4 +98,11 @@ type Store interface {
 	SoftDeleteDashboardsInFolders(ctx context.Context, orgID int64, folderUids []string) error
 	RestoreDashboard(ctx context.Context, orgID int64, dashboardUid string, folder *folder.Folder) error
 	GetSoftDeletedDashboard(ctx context.Context, orgID int64, uid string) (*Dashboard, error)
+	GetSoftDeletedDashboards(ctx context.Context, query *GetSoftDeletedDashboardsQuery) ([]*Dashboard, error)
+	// SoftDeleteFolders moves folders and the dashboards in them to the trash, keeping their permissions. The folders
+	// with a reason to be unrestorable can't be restored.
+	SoftDeleteFolders(ctx context.Context, orgID int64, folders []*folder.Folder, unrestorable string) error
+	GetDashboardSizes(ctx context.Context, query *GetDashboardSizesQuery) ([]*DashboardSize, error)
+	GetDashboardSizeLimit(ctx context.Context, orgID int64) (int64, error)
+	SetDashboardSizeLimit(ctx context.Context, orgID int64, limit *int64) error
 }
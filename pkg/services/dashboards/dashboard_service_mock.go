@@ -210,6 +210,64 @@ func (_m *FakeDashboardService) GetDashboard(ctx context.Context, query *GetDash
 	return r0, r1
 }
 
+// GetDashboardSizeLimit provides a mock function with given fields: ctx, orgID
+func (_m *FakeDashboardService) GetDashboardSizeLimit(ctx context.Context, orgID int64) (int64, error) {
+	ret := _m.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDashboardSizeLimit")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (int64, error)); ok {
+		return rf(ctx, orgID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDashboardSizes provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardService) GetDashboardSizes(ctx context.Context, query *GetDashboardSizesQuery) ([]*DashboardSize, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDashboardSizes")
+	}
+
+	var r0 []*DashboardSize
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardSizesQuery) ([]*DashboardSize, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardSizesQuery) []*DashboardSize); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DashboardSize)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetDashboardSizesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDashboardTags provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardService) GetDashboardTags(ctx context.Context, query *GetDashboardTagsQuery) ([]*DashboardTagCloudItem, error) {
 	ret := _m.Called(ctx, query)
@@ -360,6 +418,36 @@ func (_m *FakeDashboardService) GetSoftDeletedDashboard(ctx context.Context, org
 	return r0, r1
 }
 
+// GetSoftDeletedDashboards provides a mock function with given fields: ctx, orgID
+func (_m *FakeDashboardService) GetSoftDeletedDashboards(ctx context.Context, orgID int64) ([]*Dashboard, error) {
+	ret := _m.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSoftDeletedDashboards")
+	}
+
+	var r0 []*Dashboard
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*Dashboard, error)); ok {
+		return rf(ctx, orgID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*Dashboard); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Dashboard)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ImportDashboard provides a mock function with given fields: ctx, dto
 func (_m *FakeDashboardService) ImportDashboard(ctx context.Context, dto *SaveDashboardDTO) (*Dashboard, error) {
 	ret := _m.Called(ctx, dto)
@@ -468,6 +556,24 @@ func (_m *FakeDashboardService) SearchDashboards(ctx context.Context, query *Fin
 	return r0, r1
 }
 
+// SetDashboardSizeLimit provides a mock function with given fields: ctx, orgID, limit
+func (_m *FakeDashboardService) SetDashboardSizeLimit(ctx context.Context, orgID int64, limit *int64) error {
+	ret := _m.Called(ctx, orgID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDashboardSizeLimit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) error); ok {
+		r0 = rf(ctx, orgID, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SoftDeleteDashboard provides a mock function with given fields: ctx, orgID, dashboardUid
 func (_m *FakeDashboardService) SoftDeleteDashboard(ctx context.Context, orgID int64, dashboardUid string) error {
 	ret := _m.Called(ctx, orgID, dashboardUid)
@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -27,12 +31,27 @@ import (
 	"github.com/grafana/grafana/pkg/util"
 )
 
+const (
+	// sizeLimitNamespace is the kvstore namespace of the dashboard size limits set for the organizations.
+	sizeLimitNamespace = "dashboard-size"
+	sizeLimitKey       = "max-size"
+)
+
 type dashboardStore struct {
 	store      db.ReplDB
 	cfg        *setting.Cfg
 	log        log.Logger
 	features   featuremgmt.FeatureToggles
 	tagService tag.Service
+	kvStore    kvstore.KVStore
+}
+
+// dashboardSizeOptions are how the size of the JSON of a saved dashboard is limited and compressed.
+type dashboardSizeOptions struct {
+	// maxSize is the maximum size in bytes of the JSON, zero means no limit
+	maxSize int64
+	// compressMinSize is the size in bytes above which the JSON is stored compressed, zero means never
+	compressMinSize int64
 }
 
 // SQL bean helper to save tags
@@ -46,7 +65,7 @@ type dashboardTag struct {
 var _ dashboards.Store = (*dashboardStore)(nil)
 
 func ProvideDashboardStore(sqlStore db.ReplDB, cfg *setting.Cfg, features featuremgmt.FeatureToggles, tagService tag.Service, quotaService quota.Service) (dashboards.Store, error) {
-	s := &dashboardStore{store: sqlStore, cfg: cfg, log: log.New("dashboard-store"), features: features, tagService: tagService}
+	s := &dashboardStore{store: sqlStore, cfg: cfg, log: log.New("dashboard-store"), features: features, tagService: tagService, kvStore: kvstore.ProvideService(sqlStore.DB())}
 
 	defaultLimits, err := readQuotaConfig(cfg)
 	if err != nil {
@@ -137,10 +156,14 @@ func (d *dashboardStore) GetProvisionedDashboardData(ctx context.Context, name s
 }
 
 func (d *dashboardStore) SaveProvisionedDashboard(ctx context.Context, cmd dashboards.SaveDashboardCommand, provisioning *dashboards.DashboardProvisioning) (*dashboards.Dashboard, error) {
+	sizeOptions, err := d.sizeOptions(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
 	var result *dashboards.Dashboard
-	var err error
 	err = d.store.DB().WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		result, err = saveDashboard(sess, &cmd, d.emitEntityEvent())
+		result, err = saveDashboard(sess, &cmd, d.emitEntityEvent(), sizeOptions)
 		if err != nil {
 			return err
 		}
@@ -155,10 +178,14 @@ func (d *dashboardStore) SaveProvisionedDashboard(ctx context.Context, cmd dashb
 }
 
 func (d *dashboardStore) SaveDashboard(ctx context.Context, cmd dashboards.SaveDashboardCommand) (*dashboards.Dashboard, error) {
+	sizeOptions, err := d.sizeOptions(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
 	var result *dashboards.Dashboard
-	var err error
 	err = d.store.DB().WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		result, err = saveDashboard(sess, &cmd, d.emitEntityEvent())
+		result, err = saveDashboard(sess, &cmd, d.emitEntityEvent(), sizeOptions)
 		if err != nil {
 			return err
 		}
@@ -365,7 +392,7 @@ func getExistingDashboardByTitleAndFolder(sess *db.Session, dash *dashboards.Das
 	return isParentFolderChanged, nil
 }
 
-func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitEntityEvent bool) (*dashboards.Dashboard, error) {
+func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitEntityEvent bool, sizeOptions dashboardSizeOptions) (*dashboards.Dashboard, error) {
 	dash := cmd.GetDashboardModel()
 
 	userId := cmd.UserID
@@ -437,6 +464,18 @@ func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitE
 		return nil, dashboards.ErrDashboardNotFound
 	}
 
+	// the size is checked once the version is set in the JSON, the saved dashboard is rolled back if it is too large
+	data, err := dash.Data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(data))
+	if sizeOptions.maxSize > 0 && size > sizeOptions.maxSize {
+		return nil, dashboards.ErrDashboardTooLarge.Build(errutil.TemplateData{
+			Public: map[string]any{"Size": size, "Limit": sizeOptions.maxSize},
+		})
+	}
+
 	dashVersion := &dashver.DashboardVersion{
 		DashboardID:   dash.ID,
 		ParentVersion: parentVersion,
@@ -455,6 +494,10 @@ func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitE
 		return nil, dashboards.ErrDashboardNotFound
 	}
 
+	if err := saveDashboardSize(sess, dash, dashVersion, data, sizeOptions); err != nil {
+		return nil, err
+	}
+
 	// delete existing tags
 	if _, err = sess.Exec("DELETE FROM dashboard_tag WHERE dashboard_id=?", dash.ID); err != nil {
 		return nil, err
@@ -479,6 +522,28 @@ func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitE
 	return dash, nil
 }
 
+// saveDashboardSize compresses the JSON of a saved dashboard and of its version when it is large enough, and saves its
+// size.
+func saveDashboardSize(sess *db.Session, dash *dashboards.Dashboard, dashVersion *dashver.DashboardVersion, data []byte, sizeOptions dashboardSizeOptions) error {
+	stored := data
+	if sizeOptions.compressMinSize > 0 && int64(len(data)) >= sizeOptions.compressMinSize {
+		stored = simplejson.Compress(data)
+		if _, err := sess.Exec("UPDATE dashboard SET data = ? WHERE id = ?", string(stored), dash.ID); err != nil {
+			return err
+		}
+		if _, err := sess.Exec("UPDATE dashboard_version SET data = ? WHERE id = ?", string(stored), dashVersion.ID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := sess.Exec("DELETE FROM dashboard_size WHERE dashboard_id = ?", dash.ID); err != nil {
+		return err
+	}
+	_, err := sess.Exec("INSERT INTO dashboard_size (org_id, dashboard_id, size, stored_size, updated) VALUES (?, ?, ?, ?, ?)",
+		dash.OrgID, dash.ID, len(data), len(stored), dash.Updated)
+	return err
+}
+
 func saveProvisionedData(sess *db.Session, provisioning *dashboards.DashboardProvisioning, dashboard *dashboards.Dashboard) error {
 	result := &dashboards.DashboardProvisioning{}
 
@@ -564,7 +629,8 @@ func (d *dashboardStore) SoftDeleteDashboardsInFolders(ctx context.Context, orgI
 		s := strings.Builder{}
 		s.WriteString("UPDATE dashboard SET deleted=? WHERE ")
 		s.WriteString(fmt.Sprintf("folder_uid IN (%s)", strings.Repeat("?,", len(folderUids)-1)+"?"))
-		s.WriteString(" AND org_id = ? AND is_folder = ?")
+		// the dashboards already in the trash keep their deletion time
+		s.WriteString(" AND org_id = ? AND is_folder = ? AND deleted IS NULL")
 
 		sql := s.String()
 		args := make([]any, 0, 3)
@@ -579,6 +645,128 @@ func (d *dashboardStore) SoftDeleteDashboardsInFolders(ctx context.Context, orgI
 	})
 }
 
+// SoftDeleteFolders moves the folders and the dashboards in them to the trash with the same deletion time, so that
+// they can be restored together. The descriptions of the folders are kept in the data of their rows, as they are
+// only stored in the folder table. If unrestorable isn't empty, it is kept as the reason the folders can't be
+// restored.
+func (d *dashboardStore) SoftDeleteFolders(ctx context.Context, orgID int64, folders []*folder.Folder, unrestorable string) error {
+	if len(folders) == 0 {
+		return nil
+	}
+
+	return d.store.DB().WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		deleted := time.Now()
+		folderUIDs := make([]any, 0, len(folders))
+		for _, f := range folders {
+			dashboard := dashboards.Dashboard{OrgID: orgID, UID: f.UID}
+			has, err := sess.Where("is_folder = ? AND deleted IS NULL", d.store.DB().GetDialect().BooleanStr(true)).Get(&dashboard)
+			if err != nil {
+				return err
+			} else if !has {
+				return dashboards.ErrFolderNotFound
+			}
+
+			if dashboard.Data == nil {
+				dashboard.Data = simplejson.New()
+			}
+			if f.Description != "" {
+				dashboard.Data.Set("description", f.Description)
+			}
+			if unrestorable != "" {
+				dashboard.Data.Set(dashboards.UnrestorableDataKey, unrestorable)
+			}
+			data, err := dashboard.Data.Encode()
+			if err != nil {
+				return err
+			}
+			if _, err := sess.Exec("UPDATE dashboard SET data = ?, deleted = ? WHERE id = ?", string(data), deleted, dashboard.ID); err != nil {
+				return err
+			}
+			folderUIDs = append(folderUIDs, f.UID)
+		}
+
+		// the dashboards already in the trash keep their deletion time
+		sql := fmt.Sprintf("UPDATE dashboard SET deleted = ? WHERE org_id = ? AND is_folder = ? AND deleted IS NULL AND folder_uid IN (%s)", strings.Repeat("?,", len(folderUIDs)-1)+"?")
+		args := append([]any{sql, deleted, orgID, d.store.DB().GetDialect().BooleanStr(false)}, folderUIDs...)
+		_, err := sess.Exec(args...)
+		return err
+	})
+}
+
+func (d *dashboardStore) GetSoftDeletedDashboards(ctx context.Context, query *dashboards.GetSoftDeletedDashboardsQuery) ([]*dashboards.Dashboard, error) {
+	var queryResult = make([]*dashboards.Dashboard, 0)
+	err := d.store.DB().WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ? AND deleted IS NOT NULL", query.OrgID)
+		if len(query.FolderUIDs) > 0 {
+			sess.In("folder_uid", query.FolderUIDs)
+		}
+		if !query.DeletedSince.IsZero() {
+			sess.And("deleted >= ?", query.DeletedSince)
+		}
+		return sess.Desc("deleted").Asc("title").Find(&queryResult)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queryResult, nil
+}
+
+func (d *dashboardStore) GetDashboardSizes(ctx context.Context, query *dashboards.GetDashboardSizesQuery) ([]*dashboards.DashboardSize, error) {
+	sizes := make([]*dashboards.DashboardSize, 0)
+	err := d.store.ReadReplica().WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := d.store.ReadReplica().GetDialect()
+		var sql strings.Builder
+		sql.WriteString(`SELECT dashboard.uid, dashboard.title, dashboard.folder_uid, dashboard_size.size, dashboard_size.stored_size, dashboard_size.updated,
+			(SELECT COUNT(*) FROM dashboard_version WHERE dashboard_version.dashboard_id = dashboard.id) AS versions
+			FROM dashboard_size INNER JOIN dashboard ON dashboard.id = dashboard_size.dashboard_id
+			WHERE dashboard_size.org_id = ? AND dashboard.is_folder = ? AND dashboard.deleted IS NULL`)
+		args := []any{query.OrgID, dialect.BooleanStr(false)}
+		if len(query.UIDs) > 0 {
+			sql.WriteString(fmt.Sprintf(" AND dashboard.uid IN (%s)", strings.Repeat("?,", len(query.UIDs)-1)+"?"))
+			for _, uid := range query.UIDs {
+				args = append(args, uid)
+			}
+		}
+		sql.WriteString(" ORDER BY dashboard_size.size DESC, dashboard.title ASC")
+		if query.Limit > 0 {
+			sql.WriteString(dialect.Limit(int64(query.Limit)))
+		}
+		return sess.SQL(sql.String(), args...).Find(&sizes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+func (d *dashboardStore) GetDashboardSizeLimit(ctx context.Context, orgID int64) (int64, error) {
+	value, ok, err := kvstore.WithNamespace(d.kvStore, orgID, sizeLimitNamespace).Get(ctx, sizeLimitKey)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return d.cfg.DashboardSize.MaxSize, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func (d *dashboardStore) SetDashboardSizeLimit(ctx context.Context, orgID int64, limit *int64) error {
+	kv := kvstore.WithNamespace(d.kvStore, orgID, sizeLimitNamespace)
+	if limit == nil {
+		return kv.Del(ctx, sizeLimitKey)
+	}
+	return kv.Set(ctx, sizeLimitKey, strconv.FormatInt(*limit, 10))
+}
+
+// sizeOptions returns how the dashboards saved in an organization are limited and compressed.
+func (d *dashboardStore) sizeOptions(ctx context.Context, orgID int64) (dashboardSizeOptions, error) {
+	maxSize, err := d.GetDashboardSizeLimit(ctx, orgID)
+	if err != nil {
+		return dashboardSizeOptions{}, err
+	}
+	return dashboardSizeOptions{maxSize: maxSize, compressMinSize: d.cfg.DashboardSize.CompressMinSize}, nil
+}
+
 func (d *dashboardStore) DeleteDashboard(ctx context.Context, cmd *dashboards.DeleteDashboardCommand) error {
 	return d.store.DB().WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		return d.deleteDashboard(cmd, sess, d.emitEntityEvent())
@@ -610,6 +798,7 @@ func (d *dashboardStore) deleteDashboard(cmd *dashboards.DeleteDashboardCommand,
 		{SQL: "DELETE FROM dashboard WHERE id = ?", args: []any{dashboard.ID}},
 		{SQL: "DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?", args: []any{dashboard.ID}},
 		{SQL: "DELETE FROM dashboard_version WHERE dashboard_id = ?", args: []any{dashboard.ID}},
+		{SQL: "DELETE FROM dashboard_size WHERE dashboard_id = ?", args: []any{dashboard.ID}},
 		{SQL: "DELETE FROM dashboard_provisioning WHERE dashboard_id = ?", args: []any{dashboard.ID}},
 		{SQL: "DELETE FROM dashboard_acl WHERE dashboard_id = ?", args: []any{dashboard.ID}},
 	}
@@ -623,7 +812,7 @@ func (d *dashboardStore) deleteDashboard(cmd *dashboards.DeleteDashboardCommand,
 			}
 		} else {
 			// soft delete all dashboards in the folder
-			sqlStatements = append(sqlStatements, statement{SQL: "UPDATE dashboard SET deleted = ? WHERE org_id = ? AND folder_uid = ? AND is_folder = ? ", args: []any{time.Now(), dashboard.OrgID, dashboard.UID, d.store.DB().GetDialect().BooleanStr(false)}})
+			sqlStatements = append(sqlStatements, statement{SQL: "UPDATE dashboard SET deleted = ? WHERE org_id = ? AND folder_uid = ? AND is_folder = ? AND deleted IS NULL", args: []any{time.Now(), dashboard.OrgID, dashboard.UID, d.store.DB().GetDialect().BooleanStr(false)}})
 		}
 
 		// remove all access control permission with folder scope
@@ -915,7 +1104,7 @@ func (d *dashboardStore) FindDashboards(ctx context.Context, query *dashboards.F
 
 func (d *dashboardStore) GetDashboardTags(ctx context.Context, query *dashboards.GetDashboardTagsQuery) ([]*dashboards.DashboardTagCloudItem, error) {
 	queryResult := make([]*dashboards.DashboardTagCloudItem, 0)
-	err := d.store.DB().WithDbSession(ctx, func(dbSession *db.Session) error {
+	err := d.store.ReadReplica().WithDbSession(ctx, func(dbSession *db.Session) error {
 		sql := `SELECT
 					  COUNT(*) as count,
 						term
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -654,6 +655,46 @@ func TestIntegrationGetSoftDeletedDashboard(t *testing.T) {
 		assert.Equal(t, savedDash.FolderID, dash.FolderID)
 		assert.Equal(t, savedDash.FolderUID, dash.FolderUID)
 	})
+
+	t.Run("Should move a folder and its dashboards to the trash", func(t *testing.T) {
+		setup()
+		otherDash := insertTestDashboard(t, dashboardStore, "test dash 67", 1, savedFolder.ID, savedFolder.UID, false)
+
+		// a dashboard deleted on its own before the folder
+		err := sqlStore.DB().WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE dashboard SET deleted = ? WHERE uid = ?", time.Now().Add(-time.Hour), otherDash.UID)
+			return err
+		})
+		require.NoError(t, err)
+
+		err = dashboardStore.SoftDeleteFolders(context.Background(), 1, []*folder.Folder{{UID: savedFolder.UID, Description: "team folder"}}, "")
+		require.NoError(t, err)
+
+		amount, err := dashboardStore.CountDashboardsInFolders(context.Background(), &dashboards.CountDashboardsInFolderRequest{FolderUIDs: []string{savedFolder.UID}, OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), amount)
+
+		trashedFolder, err := dashboardStore.GetSoftDeletedDashboard(context.Background(), 1, savedFolder.UID)
+		require.NoError(t, err)
+		assert.True(t, trashedFolder.IsFolder)
+		assert.Equal(t, "team folder", trashedFolder.Data.Get("description").MustString())
+
+		trash, err := dashboardStore.GetSoftDeletedDashboards(context.Background(), &dashboards.GetSoftDeletedDashboardsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, trash, 4)
+
+		deletedWithFolder, err := dashboardStore.GetSoftDeletedDashboards(context.Background(), &dashboards.GetSoftDeletedDashboardsQuery{
+			OrgID:        1,
+			FolderUIDs:   []string{savedFolder.UID},
+			DeletedSince: trashedFolder.Deleted,
+		})
+		require.NoError(t, err)
+		require.Len(t, deletedWithFolder, 2)
+		for _, dash := range deletedWithFolder {
+			assert.NotEqual(t, otherDash.UID, dash.UID, "the dashboards already in the trash keep their deletion time")
+			assert.True(t, trashedFolder.Deleted.Equal(dash.Deleted))
+		}
+	})
 }
 
 func TestIntegrationDashboardDataAccessGivenPluginWithImportedDashboards(t *testing.T) {
@@ -681,6 +722,95 @@ func TestIntegrationDashboardDataAccessGivenPluginWithImportedDashboards(t *test
 	require.Equal(t, len(queryResult), 2)
 }
 
+func TestIntegrationDashboardSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestReplDB(t)
+	cfg := setting.NewCfg()
+	cfg.DashboardSize = setting.DashboardSizeSettings{MaxSize: 10000, CompressMinSize: 1000}
+	dashboardStore, err := ProvideDashboardStore(sqlStore, cfg, testFeatureToggles, tagimpl.ProvideService(sqlStore.DB()), quotatest.New(false, nil))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	save := func(orgID int64, title string, description string) (*dashboards.Dashboard, error) {
+		return dashboardStore.SaveDashboard(ctx, dashboards.SaveDashboardCommand{
+			OrgID: orgID,
+			Dashboard: simplejson.NewFromAny(map[string]any{
+				"title":       title,
+				"description": description,
+			}),
+		})
+	}
+
+	small, err := save(1, "small", "")
+	require.NoError(t, err)
+	large, err := save(1, "large", strings.Repeat("a", 5000))
+	require.NoError(t, err)
+
+	t.Run("Should store the large dashboards compressed", func(t *testing.T) {
+		var stored []string
+		err := sqlStore.DB().WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.SQL("SELECT data FROM dashboard WHERE id = ? UNION ALL SELECT data FROM dashboard_version WHERE dashboard_id = ?", large.ID, large.ID).Find(&stored)
+		})
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+		for _, data := range stored {
+			require.True(t, simplejson.IsCompressed([]byte(data)))
+		}
+
+		dash, err := dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: 1, UID: large.UID})
+		require.NoError(t, err)
+		require.Equal(t, strings.Repeat("a", 5000), dash.Data.Get("description").MustString(), "the dashboard is decompressed when it is read")
+	})
+
+	t.Run("Should list the dashboards from the largest", func(t *testing.T) {
+		sizes, err := dashboardStore.GetDashboardSizes(ctx, &dashboards.GetDashboardSizesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, sizes, 2)
+		require.Equal(t, large.UID, sizes[0].UID)
+		require.Greater(t, sizes[0].Size, int64(5000))
+		require.Less(t, sizes[0].StoredSize, sizes[0].Size)
+		require.Equal(t, int64(1), sizes[0].Versions)
+		require.Equal(t, small.UID, sizes[1].UID)
+		require.Equal(t, sizes[1].Size, sizes[1].StoredSize, "the small dashboards are not compressed")
+
+		sizes, err = dashboardStore.GetDashboardSizes(ctx, &dashboards.GetDashboardSizesQuery{OrgID: 1, UIDs: []string{small.UID}})
+		require.NoError(t, err)
+		require.Len(t, sizes, 1)
+	})
+
+	t.Run("Should reject the dashboards larger than the limit of the organization", func(t *testing.T) {
+		_, err := save(1, "too large", strings.Repeat("a", 20000))
+		require.ErrorIs(t, err, dashboards.ErrDashboardTooLarge)
+
+		limit := int64(30000)
+		require.NoError(t, dashboardStore.SetDashboardSizeLimit(ctx, 2, &limit))
+		maxSize, err := dashboardStore.GetDashboardSizeLimit(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, limit, maxSize)
+		_, err = save(2, "too large", strings.Repeat("a", 20000))
+		require.NoError(t, err, "the limit of the organization replaces the configured limit")
+
+		require.NoError(t, dashboardStore.SetDashboardSizeLimit(ctx, 2, nil))
+		maxSize, err = dashboardStore.GetDashboardSizeLimit(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(10000), maxSize)
+	})
+
+	t.Run("Should delete the size of a deleted dashboard", func(t *testing.T) {
+		err := dashboardStore.DeleteDashboard(ctx, &dashboards.DeleteDashboardCommand{ID: small.ID, OrgID: 1})
+		require.NoError(t, err)
+		var count int64
+		err = sqlStore.DB().WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.SQL("SELECT COUNT(*) FROM dashboard_size WHERE dashboard_id = ?", small.ID).Get(&count)
+			return err
+		})
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
+
 func TestIntegrationDashboard_SortingOptions(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
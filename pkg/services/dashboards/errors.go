@@ -122,6 +122,11 @@ var (
 		StatusCode: 400,
 		Status:     "bad-request",
 	}
+	ErrFolderNotRestorable = DashboardErr{
+		Reason:     "Folder cannot be restored, because its alert rules or library panels were deleted permanently with it",
+		StatusCode: 400,
+		Status:     "bad-request",
+	}
 
 	ErrFolderNotFound             = errors.New("folder not found")
 	ErrFolderVersionMismatch      = errors.New("the folder has been changed by someone else")
@@ -133,6 +138,11 @@ var (
 	ErrMoveAccessDenied           = errutil.Forbidden("folders.forbiddenMove", errutil.WithPublicMessage("Access denied to the destination folder"))
 	ErrFolderAccessEscalation     = errutil.Forbidden("folders.accessEscalation", errutil.WithPublicMessage("Cannot move a folder to a folder where you have higher permissions"))
 	ErrFolderCreationAccessDenied = errutil.Forbidden("folders.forbiddenCreation", errutil.WithPublicMessage("not enough permissions to create a folder in the selected location"))
+
+	ErrDashboardTooLarge = errutil.BadRequest("dashboards.tooLarge").MustTemplate(
+		"dashboard size of {{ .Public.Size }} bytes is above the limit of the organization of {{ .Public.Limit }} bytes",
+		errutil.WithPublic("The dashboard size of {{ .Public.Size }} bytes is above the limit of the organization of {{ .Public.Limit }} bytes"),
+	)
 )
 
 // DashboardErr represents a dashboard error.
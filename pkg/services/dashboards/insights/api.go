@@ -0,0 +1,73 @@
+package insights
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultDays  = 30
+	defaultLimit = 50
+	maxLimit     = 1000
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	canRead := authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))))
+
+	routeRegister.Group("/api/dashboards", func(dashboardRoute routing.RouteRegister) {
+		dashboardRoute.Get("/insights/least-used", middleware.ReqOrgAdmin, routing.Wrap(s.leastUsedHandler))
+		dashboardRoute.Get("/uid/:uid/insights", canRead, routing.Wrap(s.getUsageHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// period returns the first and the last day of the period of the days query parameter, 30 days by default.
+// The period ends today and can't start before the retention period.
+func (s *Service) period(c *contextmodel.ReqContext) (string, string) {
+	days := c.QueryInt("days")
+	if days <= 0 {
+		days = defaultDays
+	}
+	days = min(days, int(s.settings.Retention/(24*time.Hour)))
+
+	today := s.now().UTC()
+	return today.AddDate(0, 0, 1-days).Format(dayFormat), today.Format(dayFormat)
+}
+
+// getUsageHandler returns the usage of a dashboard, with its daily rollups.
+func (s *Service) getUsageHandler(c *contextmodel.ReqContext) response.Response {
+	from, to := s.period(c)
+	usage, err := s.usage(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], from, to)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard usage", err)
+	}
+	return response.JSON(http.StatusOK, usage)
+}
+
+// leastUsedHandler returns the dashboards of the organization with the fewest views, including the ones that were
+// never viewed.
+func (s *Service) leastUsedHandler(c *contextmodel.ReqContext) response.Response {
+	from, to := s.period(c)
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	summaries, err := s.leastUsed(c.Req.Context(), LeastUsedQuery{
+		OrgID: c.SignedInUser.GetOrgID(),
+		From:  from,
+		To:    to,
+		Limit: min(limit, maxLimit),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get least used dashboards", err)
+	}
+	return response.JSON(http.StatusOK, summaries)
+}
@@ -0,0 +1,270 @@
+// Package insights records the views, the queries and the query errors of the dashboards in daily rollups, and when
+// every dashboard was last viewed, so that admins can find the dashboards that are no longer used. The dashboards
+// that were not viewed for a configured period are tagged as stale.
+package insights
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	dayFormat = "2006-01-02"
+	// staleCheckInterval is how often the stale dashboards are tagged, by one Grafana instance at a time.
+	staleCheckInterval = time.Hour
+	// staleBatchSize is the maximum number of dashboards tagged or untagged in a check.
+	staleBatchSize = 100
+)
+
+// Service collects the usage of the dashboards in memory and periodically adds it to the daily rollups.
+type Service struct {
+	store            db.DB
+	settings         setting.DashboardInsightsSettings
+	dashboardService dashboards.DashboardService
+	accessControl    ac.AccessControl
+	serverLock       *serverlock.ServerLockService
+	log              log.Logger
+	now              func() time.Time
+
+	mu      sync.Mutex
+	pending map[usageKey]*pendingUsage
+}
+
+type usageKey struct {
+	orgID int64
+	uid   string
+	day   string
+}
+
+// pendingUsage is the usage of a dashboard during a day that is not written to the database yet.
+type pendingUsage struct {
+	views   int64
+	queries int64
+	errors  int64
+	// lastViewed is the Unix timestamp of the last view, 0 if the dashboard was not viewed.
+	lastViewed int64
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dashboardService dashboards.DashboardService,
+	accessControl ac.AccessControl, serverLock *serverlock.ServerLockService, routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		store:            sqlStore,
+		settings:         cfg.DashboardInsights,
+		dashboardService: dashboardService,
+		accessControl:    accessControl,
+		serverLock:       serverLock,
+		log:              log.New("dashboards.insights"),
+		now:              time.Now,
+		pending:          make(map[usageKey]*pendingUsage),
+	}
+
+	if s.settings.Enabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+// Enabled returns true if the usage of the dashboards is recorded.
+func (s *Service) Enabled() bool {
+	return s != nil && s.settings.Enabled
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Enabled()
+}
+
+// RecordView adds a view to the usage of a dashboard. The usage is written to the database on the next flush.
+func (s *Service) RecordView(orgID int64, uid string) {
+	if !s.Enabled() || uid == "" {
+		return
+	}
+	now := s.now()
+	s.record(orgID, uid, now, func(p *pendingUsage) {
+		p.views++
+		p.lastViewed = now.Unix()
+	})
+}
+
+// RecordQueries adds the queries of a dashboard and the number of failed ones to the usage of the dashboard.
+func (s *Service) RecordQueries(orgID int64, uid string, queries, failed int) {
+	if !s.Enabled() || uid == "" || queries == 0 {
+		return
+	}
+	s.record(orgID, uid, s.now(), func(p *pendingUsage) {
+		p.queries += int64(queries)
+		p.errors += int64(failed)
+	})
+}
+
+func (s *Service) record(orgID int64, uid string, now time.Time, update func(p *pendingUsage)) {
+	key := usageKey{orgID: orgID, uid: uid, day: now.UTC().Format(dayFormat)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[key]
+	if !ok {
+		p = &pendingUsage{}
+		s.pending[key] = p
+	}
+	update(p)
+}
+
+// Run periodically writes the collected usage to the database, deletes the rollups older than the retention period
+// and tags the stale dashboards, until ctx is done.
+func (s *Service) Run(ctx context.Context) error {
+	flush := time.NewTicker(s.settings.FlushInterval)
+	defer flush.Stop()
+	hourly := time.NewTicker(staleCheckInterval)
+	defer hourly.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// write what was collected since the last flush before shutting down
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return nil
+		case <-flush.C:
+			s.flush(ctx)
+		case <-hourly.C:
+			before := s.now().Add(-s.settings.Retention).UTC().Format(dayFormat)
+			if err := s.deleteBefore(ctx, before); err != nil {
+				s.log.Error("Failed to delete expired dashboard usage", "error", err)
+			}
+			if s.settings.StaleAfter == 0 {
+				continue
+			}
+			err := s.serverLock.LockAndExecute(ctx, "tag stale dashboards", staleCheckInterval/2, func(ctx context.Context) {
+				if err := s.tagStale(ctx); err != nil {
+					s.log.Error("Failed to tag stale dashboards", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to tag stale dashboards", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]*pendingUsage)
+	s.mu.Unlock()
+
+	for key, p := range pending {
+		if err := s.add(ctx, key, p); err != nil {
+			s.log.Error("Failed to write dashboard usage", "dashboardUid", key.uid, "orgId", key.orgID, "error", err)
+		}
+	}
+}
+
+// tagStale adds the stale tag to the dashboards that were not viewed since the stale period, and removes it from
+// the ones that were viewed again. The dashboards are only tagged once the usage was recorded for the whole period.
+func (s *Service) tagStale(ctx context.Context) error {
+	cutoff := s.now().Add(-s.settings.StaleAfter)
+	recordedSince, err := s.firstDay(ctx)
+	if err != nil {
+		return err
+	}
+
+	tagged, untagged := 0, 0
+	if recordedSince != "" && recordedSince <= cutoff.UTC().Format(dayFormat) {
+		stale, err := s.staleDashboards(ctx, cutoff, staleBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, ref := range stale {
+			if err := s.setStaleTag(ctx, ref, true); err != nil {
+				s.log.Warn("Failed to tag stale dashboard", "orgId", ref.OrgID, "dashboardUid", ref.UID, "error", err)
+				continue
+			}
+			tagged++
+		}
+	}
+
+	revived, err := s.revivedDashboards(ctx, cutoff, staleBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, ref := range revived {
+		if err := s.setStaleTag(ctx, ref, false); err != nil {
+			s.log.Warn("Failed to untag stale dashboard", "orgId", ref.OrgID, "dashboardUid", ref.UID, "error", err)
+			continue
+		}
+		untagged++
+	}
+
+	if tagged > 0 || untagged > 0 {
+		s.log.Info("Tagged stale dashboards", "tag", s.settings.StaleTag, "tagged", tagged, "untagged", untagged)
+	}
+	return nil
+}
+
+// setStaleTag adds or removes the stale tag of a dashboard, and saves it as a new version.
+func (s *Service) setStaleTag(ctx context.Context, ref dashboardRef, stale bool) error {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: ref.OrgID, UID: ref.UID})
+	if err != nil {
+		return err
+	}
+	tags := dash.Data.Get("tags").MustStringArray()
+	hasTag := slices.Contains(tags, s.settings.StaleTag)
+	if hasTag == stale {
+		return nil
+	}
+
+	message := "Tagged as not viewed recently"
+	if stale {
+		tags = append(tags, s.settings.StaleTag)
+	} else {
+		tags = slices.DeleteFunc(tags, func(tag string) bool { return tag == s.settings.StaleTag })
+		message = "Untagged as viewed again"
+	}
+	dash.Data.Set("tags", tags)
+
+	updated := dashboards.NewDashboardFromJson(dash.Data)
+	updated.OrgID = dash.OrgID
+	updated.FolderUID = dash.FolderUID
+	updated.SetID(dash.ID)
+	updated.SetUID(dash.UID)
+	updated.SetVersion(dash.Version)
+	_, err = s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     dash.OrgID,
+		User:      backgroundUser(dash.OrgID),
+		Message:   message,
+		Dashboard: updated,
+	}, false)
+	return err
+}
+
+// backgroundUser is the identity of the stale dashboard tagging in an organization.
+func backgroundUser(orgID int64) identity.Requester {
+	return ac.BackgroundUser("dashboard_insights", orgID, org.RoleAdmin, []ac.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsAll},
+		{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeDashboardsAll},
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+	})
+}
+
+func errorRate(errors, queries int64) float64 {
+	if queries == 0 {
+		return 0
+	}
+	return float64(errors) / float64(queries)
+}
@@ -0,0 +1,122 @@
+package insights
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)
+	s := &Service{
+		settings: setting.DashboardInsightsSettings{Enabled: true},
+		now:      func() time.Time { return now },
+		pending:  make(map[usageKey]*pendingUsage),
+	}
+
+	s.RecordView(1, "dash")
+	s.RecordQueries(1, "dash", 3, 1)
+	s.RecordQueries(1, "dash", 2, 0)
+	s.RecordQueries(1, "", 2, 0)
+	s.RecordQueries(2, "other", 0, 0)
+
+	require.Len(t, s.pending, 1)
+	require.Equal(t, &pendingUsage{views: 1, queries: 5, errors: 1, lastViewed: now.Unix()}, s.pending[usageKey{orgID: 1, uid: "dash", day: "2024-05-01"}])
+
+	s.settings.Enabled = false
+	s.RecordView(1, "other")
+	require.Len(t, s.pending, 1)
+
+	var disabled *Service
+	require.False(t, disabled.Enabled())
+	disabled.RecordView(1, "dash")
+}
+
+func TestIntegrationInsights(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	sqlStore := db.InitTestDB(t)
+	s := &Service{
+		store:    sqlStore,
+		settings: setting.DashboardInsightsSettings{Enabled: true, Retention: 90 * 24 * time.Hour, StaleAfter: 24 * time.Hour, StaleTag: "stale"},
+		log:      log.NewNopLogger(),
+		now:      func() time.Time { return now },
+		pending:  make(map[usageKey]*pendingUsage),
+	}
+	ctx := context.Background()
+
+	for _, uid := range []string{"viewed", "old", "unused"} {
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"title": uid}))
+		dash.OrgID = 1
+		dash.UID = uid
+		dash.Created = now.AddDate(0, 0, -10)
+		dash.Updated = dash.Created
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Insert(dash)
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	s.now = func() time.Time { return now.AddDate(0, 0, -5) }
+	s.RecordView(1, "old")
+	s.flush(ctx)
+
+	s.now = func() time.Time { return now }
+	s.RecordView(1, "viewed")
+	s.RecordQueries(1, "viewed", 4, 1)
+	s.flush(ctx)
+	// a second flush adds to the rollups of the day
+	s.RecordView(1, "viewed")
+	s.flush(ctx)
+	require.Empty(t, s.pending)
+
+	usage, err := s.usage(ctx, 1, "viewed", "2024-04-01", "2024-05-02")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage.Views)
+	require.Equal(t, int64(4), usage.Queries)
+	require.Equal(t, 0.25, usage.ErrorRate)
+	require.Equal(t, now.Unix(), usage.LastViewed.Unix())
+	require.Equal(t, []Day{{Day: "2024-05-02", Views: 2, Queries: 4, Errors: 1, ErrorRate: 0.25}}, usage.Daily)
+
+	summaries, err := s.leastUsed(ctx, LeastUsedQuery{OrgID: 1, From: "2024-05-01", To: "2024-05-02", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, summaries, 3)
+	require.Equal(t, "unused", summaries[0].UID, "the dashboards never viewed are the least used")
+	require.Nil(t, summaries[0].LastViewed)
+	require.Equal(t, "old", summaries[1].UID, "the views before the period are not counted")
+	require.Equal(t, int64(0), summaries[1].Views)
+	require.NotNil(t, summaries[1].LastViewed)
+	require.Equal(t, "viewed", summaries[2].UID)
+	require.Equal(t, int64(2), summaries[2].Views)
+
+	first, err := s.firstDay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "2024-04-27", first)
+
+	stale, err := s.staleDashboards(ctx, now.Add(-s.settings.StaleAfter), 10)
+	require.NoError(t, err)
+	require.Equal(t, []dashboardRef{{OrgID: 1, UID: "old"}, {OrgID: 1, UID: "unused"}}, stale)
+
+	require.NoError(t, s.deleteBefore(ctx, "2024-05-01"))
+	usage, err = s.usage(ctx, 1, "old", "2024-04-01", "2024-05-02")
+	require.NoError(t, err)
+	require.Zero(t, usage.Views)
+	require.NotNil(t, usage.LastViewed, "the last view is kept after the rollups expire")
+}
@@ -0,0 +1,89 @@
+package insights
+
+import (
+	"time"
+)
+
+// dailyUsage is the usage of a dashboard during a day.
+type dailyUsage struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	// Day is the UTC date, formatted as 2006-01-02.
+	Day     string `xorm:"day"`
+	Views   int64  `xorm:"views"`
+	Queries int64  `xorm:"queries"`
+	Errors  int64  `xorm:"errors"`
+}
+
+func (dailyUsage) TableName() string {
+	return "dashboard_usage"
+}
+
+// lastViewed is when a dashboard was last viewed. It is kept after the daily rollups expire, to find the
+// dashboards that were not viewed for longer than the retention period.
+type lastViewed struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	// LastViewed is a Unix timestamp in seconds.
+	LastViewed int64 `xorm:"last_viewed"`
+}
+
+func (lastViewed) TableName() string {
+	return "dashboard_usage_last_viewed"
+}
+
+// Usage is the usage of a dashboard over a period.
+type Usage struct {
+	DashboardUID string `json:"dashboardUid"`
+	// From and To are the first and the last day of the period, formatted as 2006-01-02.
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Views     int64   `json:"views"`
+	Queries   int64   `json:"queries"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+	// LastViewed is when the dashboard was last viewed, even before the period.
+	LastViewed *time.Time `json:"lastViewed,omitempty"`
+	Daily      []Day      `json:"daily"`
+}
+
+// Day is the usage of a dashboard during a day.
+type Day struct {
+	Day       string  `json:"day"`
+	Views     int64   `json:"views"`
+	Queries   int64   `json:"queries"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// LeastUsedQuery lists the least viewed dashboards of an organization over a period.
+type LeastUsedQuery struct {
+	OrgID int64
+	// From and To are the first and the last day of the period, formatted as 2006-01-02.
+	From  string
+	To    string
+	Limit int
+}
+
+// Summary is the usage of a dashboard over a period, as listed for the least used dashboards.
+type Summary struct {
+	UID        string     `xorm:"uid" json:"uid"`
+	Title      string     `xorm:"title" json:"title"`
+	FolderUID  string     `xorm:"folder_uid" json:"folderUid,omitempty"`
+	Views      int64      `xorm:"views" json:"views"`
+	Queries    int64      `xorm:"queries" json:"queries"`
+	Errors     int64      `xorm:"errors" json:"errors"`
+	ErrorRate  float64    `xorm:"-" json:"errorRate"`
+	LastViewed *time.Time `xorm:"-" json:"lastViewed,omitempty"`
+	Created    time.Time  `xorm:"created" json:"created"`
+	// LastViewedUnix is the LastViewed Unix timestamp, 0 if the dashboard was never viewed.
+	LastViewedUnix int64 `xorm:"last_viewed" json:"-"`
+}
+
+// dashboardRef identifies a dashboard tagged or untagged as stale.
+type dashboardRef struct {
+	OrgID int64  `xorm:"org_id"`
+	UID   string `xorm:"uid"`
+}
@@ -0,0 +1,192 @@
+package insights
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// add adds the pending usage of a dashboard to its daily rollup and last viewed time. If it fails, the usage is kept
+// in memory and added again on the next flush.
+func (s *Service) add(ctx context.Context, key usageKey, p *pendingUsage) error {
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := upsert(sess,
+			"UPDATE dashboard_usage SET views = views + ?, queries = queries + ?, errors = errors + ? "+
+				"WHERE org_id = ? AND dashboard_uid = ? AND day = ?",
+			[]any{p.views, p.queries, p.errors, key.orgID, key.uid, key.day},
+			&dailyUsage{OrgID: key.orgID, DashboardUID: key.uid, Day: key.day, Views: p.views, Queries: p.queries, Errors: p.errors},
+		); err != nil {
+			return err
+		}
+		if p.lastViewed == 0 {
+			return nil
+		}
+		return upsert(sess,
+			"UPDATE dashboard_usage_last_viewed SET last_viewed = CASE WHEN last_viewed < ? THEN ? ELSE last_viewed END "+
+				"WHERE org_id = ? AND dashboard_uid = ?",
+			[]any{p.lastViewed, p.lastViewed, key.orgID, key.uid},
+			&lastViewed{OrgID: key.orgID, DashboardUID: key.uid, LastViewed: p.lastViewed},
+		)
+	})
+	if err != nil {
+		s.requeue(key, p)
+	}
+	return err
+}
+
+// upsert runs the update, and inserts the row if the update did not match any row.
+func upsert(sess *db.Session, update string, args []any, row any) error {
+	res, err := sess.Exec(append([]any{update}, args...)...)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		return nil
+	}
+	_, err = sess.Insert(row)
+	return err
+}
+
+func (s *Service) requeue(key usageKey, p *pendingUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.pending[key]
+	if !ok {
+		s.pending[key] = p
+		return
+	}
+	current.views += p.views
+	current.queries += p.queries
+	current.errors += p.errors
+	current.lastViewed = max(current.lastViewed, p.lastViewed)
+}
+
+// deleteBefore deletes the daily rollups before a day, and the last viewed times of the deleted dashboards.
+func (s *Service) deleteBefore(ctx context.Context, day string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM dashboard_usage WHERE day < ?", day); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM dashboard_usage_last_viewed WHERE NOT EXISTS " +
+			"(SELECT 1 FROM dashboard WHERE dashboard.org_id = dashboard_usage_last_viewed.org_id AND dashboard.uid = dashboard_usage_last_viewed.dashboard_uid)")
+		return err
+	})
+}
+
+// usage returns the usage of a dashboard between two days, both included.
+func (s *Service) usage(ctx context.Context, orgID int64, uid string, from, to string) (*Usage, error) {
+	result := &Usage{DashboardUID: uid, From: from, To: to, Daily: []Day{}}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		days := make([]*dailyUsage, 0)
+		if err := sess.Where("org_id = ? AND dashboard_uid = ? AND day >= ? AND day <= ?", orgID, uid, from, to).
+			Asc("day").Find(&days); err != nil {
+			return err
+		}
+		for _, d := range days {
+			result.Views += d.Views
+			result.Queries += d.Queries
+			result.Errors += d.Errors
+			result.Daily = append(result.Daily, Day{
+				Day:       d.Day,
+				Views:     d.Views,
+				Queries:   d.Queries,
+				Errors:    d.Errors,
+				ErrorRate: errorRate(d.Errors, d.Queries),
+			})
+		}
+		result.ErrorRate = errorRate(result.Errors, result.Queries)
+
+		viewed := &lastViewed{}
+		exists, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, uid).Get(viewed)
+		if err != nil {
+			return err
+		}
+		if exists {
+			t := time.Unix(viewed.LastViewed, 0).UTC()
+			result.LastViewed = &t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// leastUsed returns the dashboards of an organization with the fewest views during a period, including the ones
+// that were never viewed, the least recently viewed first when they have as many views.
+func (s *Service) leastUsed(ctx context.Context, query LeastUsedQuery) ([]*Summary, error) {
+	summaries := make([]*Summary, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.store.GetDialect()
+		return sess.SQL("SELECT d.uid, d.title, d.folder_uid, d.created, "+
+			"COALESCE(u.views, 0) AS views, COALESCE(u.queries, 0) AS queries, COALESCE(u.errors, 0) AS errors, "+
+			"COALESCE(l.last_viewed, 0) AS last_viewed "+
+			"FROM dashboard d "+
+			"LEFT JOIN (SELECT dashboard_uid, SUM(views) AS views, SUM(queries) AS queries, SUM(errors) AS errors "+
+			"FROM dashboard_usage WHERE org_id = ? AND day >= ? AND day <= ? GROUP BY dashboard_uid) u ON u.dashboard_uid = d.uid "+
+			"LEFT JOIN dashboard_usage_last_viewed l ON l.org_id = d.org_id AND l.dashboard_uid = d.uid "+
+			"WHERE d.org_id = ? AND d.is_folder = "+dialect.BooleanStr(false)+" AND d.deleted IS NULL "+
+			"ORDER BY views ASC, last_viewed ASC, d.title ASC "+dialect.Limit(int64(query.Limit)),
+			query.OrgID, query.From, query.To, query.OrgID).Find(&summaries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		summary.ErrorRate = errorRate(summary.Errors, summary.Queries)
+		if summary.LastViewedUnix > 0 {
+			t := time.Unix(summary.LastViewedUnix, 0).UTC()
+			summary.LastViewed = &t
+		}
+	}
+	return summaries, nil
+}
+
+// firstDay returns the first day with recorded usage, or an empty string if no usage was recorded.
+func (s *Service) firstDay(ctx context.Context) (string, error) {
+	var day string
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT COALESCE(MIN(day), '') FROM dashboard_usage").Get(&day)
+		return err
+	})
+	return day, err
+}
+
+// staleDashboards returns the dashboards created and last viewed before the cutoff that are not tagged as stale.
+// The provisioned dashboards are not returned, since they can't be saved.
+func (s *Service) staleDashboards(ctx context.Context, cutoff time.Time, limit int) ([]dashboardRef, error) {
+	refs := make([]dashboardRef, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.store.GetDialect()
+		return sess.SQL("SELECT d.org_id, d.uid FROM dashboard d "+
+			"LEFT JOIN dashboard_usage_last_viewed l ON l.org_id = d.org_id AND l.dashboard_uid = d.uid "+
+			"WHERE d.is_folder = "+dialect.BooleanStr(false)+" AND d.deleted IS NULL AND d.created < ? "+
+			"AND (l.last_viewed IS NULL OR l.last_viewed < ?) "+
+			"AND NOT EXISTS (SELECT 1 FROM dashboard_tag t WHERE t.dashboard_id = d.id AND t.term = ?) "+
+			"AND NOT EXISTS (SELECT 1 FROM dashboard_provisioning p WHERE p.dashboard_id = d.id) "+
+			"ORDER BY d.id ASC "+dialect.Limit(int64(limit)),
+			cutoff, cutoff.Unix(), s.settings.StaleTag).Find(&refs)
+	})
+	return refs, err
+}
+
+// revivedDashboards returns the dashboards tagged as stale that were viewed since the cutoff.
+func (s *Service) revivedDashboards(ctx context.Context, cutoff time.Time, limit int) ([]dashboardRef, error) {
+	refs := make([]dashboardRef, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT d.org_id, d.uid FROM dashboard d "+
+			"INNER JOIN dashboard_tag t ON t.dashboard_id = d.id AND t.term = ? "+
+			"INNER JOIN dashboard_usage_last_viewed l ON l.org_id = d.org_id AND l.dashboard_uid = d.uid "+
+			"WHERE d.deleted IS NULL AND l.last_viewed >= ? "+
+			"AND NOT EXISTS (SELECT 1 FROM dashboard_provisioning p WHERE p.dashboard_id = d.id) "+
+			"ORDER BY d.id ASC "+s.store.GetDialect().Limit(int64(limit)),
+			s.settings.StaleTag, cutoff.Unix()).Find(&refs)
+	})
+	return refs, err
+}
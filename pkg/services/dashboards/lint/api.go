@@ -0,0 +1,47 @@
+package lint
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	canRead := authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))))
+
+	routeRegister.Group("/api/dashboards", func(dashboardRoute routing.RouteRegister) {
+		dashboardRoute.Post("/lint", routing.Wrap(s.lintHandler))
+		dashboardRoute.Get("/uid/:uid/lint", canRead, routing.Wrap(s.getResultHandler))
+	}, middleware.ReqSignedIn)
+}
+
+func (s *Service) lintHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := LintCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	warnings, err := s.Lint(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Dashboard)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to lint dashboard", err)
+	}
+	return response.JSON(http.StatusOK, Result{DashboardUID: cmd.Dashboard.Get("uid").MustString(), Warnings: warnings})
+}
+
+func (s *Service) getResultHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetResult(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get dashboard lint warnings", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
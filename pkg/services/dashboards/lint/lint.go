@@ -0,0 +1,197 @@
+// Package lint checks dashboards for problems: references to undefined template variables, deprecated panel types,
+// queries of data sources that don't exist and too many panels. The dashboards are linted when they are saved with
+// the dashboard HTTP API, and by a job for the other save paths, such as the Kubernetes API and the provisioning. The
+// warnings are stored with the dashboard and surfaced in the search results.
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/jobs"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Service lints the dashboards and stores their warnings.
+type Service struct {
+	settings          setting.DashboardLintSettings
+	store             db.DB
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	accessControl     ac.AccessControl
+	log               log.Logger
+	now               func() time.Time
+}
+
+const (
+	// lintStaleJob lints the dashboards saved without the dashboard HTTP API since they were last linted.
+	lintStaleJob = "dashboards.lint-saved"
+	// staleBatchSize is the number of dashboards linted by a run of the job.
+	staleBatchSize = 500
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dashboardService dashboards.DashboardService,
+	dataSourceService datasources.DataSourceService, accessControl ac.AccessControl, routeRegister routing.RouteRegister,
+	jobService *jobs.Service,
+) *Service {
+	s := &Service{
+		settings:          cfg.DashboardLint,
+		store:             sqlStore,
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		accessControl:     accessControl,
+		log:               log.New("dashboards.lint"),
+		now:               time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	if s.settings.Enabled {
+		if err := jobService.Register(jobs.Definition{
+			Name:        lintStaleJob,
+			Description: "Lint the dashboards saved since they were last linted",
+			Schedule:    "@every 1m",
+			Timeout:     time.Minute,
+			Handler: func(ctx context.Context, _ []byte) error {
+				return s.lintStale(ctx)
+			},
+		}); err != nil {
+			s.log.Error("Failed to register the lint job", "job", lintStaleJob, "error", err)
+		}
+	}
+	return s
+}
+
+// Lint returns the warnings of a dashboard JSON model of an organization.
+func (s *Service) Lint(ctx context.Context, orgID int64, data *simplejson.Json) ([]Warning, error) {
+	dataSources, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{maxPanels: s.settings.MaxPanels, dataSources: make(map[string]bool, 2*len(dataSources))}
+	for _, ds := range dataSources {
+		l.dataSources[ds.UID] = true
+		l.dataSources[ds.Name] = true
+	}
+	return l.lint(data), nil
+}
+
+// LintSaved lints a dashboard that was saved and stores its warnings. It returns the warnings, or nil if the linting
+// of the saved dashboards is disabled or failed: a failure is logged, it doesn't fail the save.
+func (s *Service) LintSaved(ctx context.Context, dash *dashboards.Dashboard) []Warning {
+	if s == nil || !s.settings.Enabled || dash == nil || dash.IsFolder {
+		return nil
+	}
+	result, err := s.lintAndStore(ctx, dash)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to lint saved dashboard", "dashboardUid", dash.UID, "error", err)
+		return nil
+	}
+	return result.Warnings
+}
+
+// GetResult returns the warnings of a stored dashboard. The dashboard is linted if it was not linted since it was
+// last saved.
+func (s *Service) GetResult(ctx context.Context, orgID int64, uid string) (*Result, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: orgID, UID: uid})
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.getState(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.DashboardVersion == dash.Version {
+		return stateResult(state)
+	}
+	return s.lintAndStore(ctx, dash)
+}
+
+// AnnotateHits sets the number of warnings of the dashboard hits that were linted. A failure is logged, the hits are
+// returned without warnings.
+func (s *Service) AnnotateHits(ctx context.Context, orgID int64, hits model.HitList) {
+	if s == nil || len(hits) == 0 {
+		return
+	}
+	uids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Type == model.DashHitDB {
+			uids = append(uids, hit.UID)
+		}
+	}
+	if len(uids) == 0 {
+		return
+	}
+	counts, err := s.countWarnings(ctx, orgID, uids)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to get the lint warnings of the search hits", "error", err)
+		return
+	}
+	for _, hit := range hits {
+		if hit.Type == model.DashHitDB {
+			hit.LintWarnings = counts[hit.UID]
+		}
+	}
+}
+
+// lintStale lints a batch of the dashboards that were never linted or were saved since they were last linted, by the
+// save paths which don't call LintSaved.
+func (s *Service) lintStale(ctx context.Context) error {
+	stale, err := s.findStale(ctx, staleBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find the dashboards to lint: %w", err)
+	}
+	for _, d := range stale {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: d.OrgID, UID: d.UID})
+		if err != nil {
+			s.log.FromContext(ctx).Warn("Failed to get dashboard to lint", "orgId", d.OrgID, "dashboardUid", d.UID, "error", err)
+			continue
+		}
+		if _, err := s.lintAndStore(ctx, dash); err != nil {
+			s.log.FromContext(ctx).Warn("Failed to lint dashboard", "orgId", d.OrgID, "dashboardUid", d.UID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) lintAndStore(ctx context.Context, dash *dashboards.Dashboard) (*Result, error) {
+	warnings, err := s.Lint(ctx, dash.OrgID, dash.Data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(warnings)
+	if err != nil {
+		return nil, err
+	}
+	state := &lintState{
+		OrgID:            dash.OrgID,
+		DashboardUID:     dash.UID,
+		DashboardVersion: dash.Version,
+		WarningCount:     len(warnings),
+		Warnings:         string(encoded),
+		Updated:          s.now().Unix(),
+	}
+	if err := s.saveState(ctx, state); err != nil {
+		return nil, err
+	}
+	return &Result{DashboardUID: dash.UID, Version: dash.Version, Warnings: warnings, Updated: state.Updated}, nil
+}
+
+func stateResult(state *lintState) (*Result, error) {
+	warnings := make([]Warning, 0, state.WarningCount)
+	if err := json.Unmarshal([]byte(state.Warnings), &warnings); err != nil {
+		return nil, err
+	}
+	return &Result{DashboardUID: state.DashboardUID, Version: state.DashboardVersion, Warnings: warnings, Updated: state.Updated}, nil
+}
@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLint(t *testing.T) {
+	s := &Service{
+		settings: setting.DashboardLintSettings{Enabled: true, MaxPanels: 3},
+		dataSourceService: &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 1, UID: "prom", Name: "Prometheus"},
+			{OrgID: 2, UID: "loki", Name: "Loki"},
+		}},
+	}
+
+	t.Run("reports the problems of the dashboard", func(t *testing.T) {
+		warnings, err := s.Lint(context.Background(), 1, simplejson.MustJson([]byte(`{
+			"templating": {"list": [
+				{"name": "host", "type": "query", "datasource": {"uid": "prom"}, "query": "label_values(up{env=\"$env\"}, host)"},
+				{"name": "ds", "type": "datasource", "query": "prometheus"}
+			]},
+			"annotations": {"list": [
+				{"name": "Deploys", "datasource": {"type": "loki", "uid": "loki"}}
+			]},
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU $host", "datasource": {"uid": "prom"}, "targets": [
+					{"refId": "A", "expr": "rate(cpu{host=~\"${host:regex}\", dc=\"[[dc]]\"}[$__rate_interval])"},
+					{"refId": "B", "expr": "label_replace(up, \"a\", \"$1\", \"b\", \"(.*)\")"}
+				]},
+				{"id": 2, "type": "row", "title": "Details", "collapsed": true, "repeat": "region", "panels": [
+					{"id": 3, "type": "timeseries", "datasource": {"uid": "-- Mixed --"}, "targets": [
+						{"refId": "A", "datasource": {"uid": "gone"}},
+						{"refId": "B", "datasource": {"uid": "gone"}},
+						{"refId": "C", "datasource": {"uid": "${ds}"}},
+						{"refId": "D", "datasource": {"uid": "__expr__"}, "expression": "$A + 1"}
+					]}
+				]},
+				{"id": 4, "type": "text", "title": "Notes"},
+				{"id": 5, "type": "singlestat", "datasource": "Prometheus"}
+			]
+		}`)))
+		require.NoError(t, err)
+		require.Equal(t, []Warning{
+			{Rule: RuleUndefinedVariable, Message: `Variable "host" references the undefined variable $env`},
+			{Rule: RuleMissingDataSource, Message: `Annotation "Deploys" queries the data source "loki", which doesn't exist`},
+			{Rule: RuleDeprecatedPanel, PanelID: 1, Message: `Panel "CPU $host" uses the deprecated graph panel, use timeseries instead`},
+			{Rule: RuleUndefinedVariable, PanelID: 1, Message: `Panel "CPU $host" references the undefined variable $dc`},
+			{Rule: RuleUndefinedVariable, PanelID: 2, Message: `Row "Details" is repeated for the undefined variable $region`},
+			{Rule: RuleMissingDataSource, PanelID: 3, Message: `Panel 3 queries the data source "gone", which doesn't exist`},
+			{Rule: RuleDeprecatedPanel, PanelID: 5, Message: `Panel 5 uses the deprecated singlestat panel, use stat instead`},
+			{Rule: RuleTooManyPanels, Message: "The dashboard has 4 panels, more than the maximum of 3"},
+		}, warnings)
+	})
+
+	t.Run("reports no warnings for a valid dashboard", func(t *testing.T) {
+		warnings, err := s.Lint(context.Background(), 2, simplejson.MustJson([]byte(`{
+			"panels": [
+				{"id": 1, "type": "logs", "datasource": {"uid": "loki"}, "targets": [{"refId": "A", "expr": "{app=\"grafana\"} |= \"$__interval\""}]},
+				{"id": 2, "type": "timeseries", "datasource": {"uid": "grafana"}, "targets": [{"refId": "A", "queryType": "randomWalk"}]}
+			]
+		}`)))
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+}
@@ -0,0 +1,58 @@
+package lint
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Rule identifies a check of the linter.
+type Rule string
+
+const (
+	// RuleUndefinedVariable reports the references to template variables that the dashboard doesn't define.
+	RuleUndefinedVariable Rule = "undefined-variable"
+	// RuleDeprecatedPanel reports the panels of deprecated types.
+	RuleDeprecatedPanel Rule = "deprecated-panel"
+	// RuleMissingDataSource reports the queries of data sources that don't exist in the organization.
+	RuleMissingDataSource Rule = "missing-datasource"
+	// RuleTooManyPanels reports the dashboards with more panels than the configured maximum.
+	RuleTooManyPanels Rule = "too-many-panels"
+)
+
+// Warning is a problem found in a dashboard.
+type Warning struct {
+	Rule Rule `json:"rule"`
+	// PanelID is the ID of the panel with the problem, 0 if the problem is not in a panel.
+	PanelID int64  `json:"panelId,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result is the result of the linting of a dashboard.
+type Result struct {
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	// Version is the version of the dashboard that was linted, 0 for dashboards that are not saved.
+	Version  int       `json:"version,omitempty"`
+	Warnings []Warning `json:"warnings"`
+	// Updated is when the stored dashboard was linted.
+	Updated int64 `json:"updated,omitempty"`
+}
+
+// LintCommand lints a dashboard without saving it.
+type LintCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+}
+
+// lintState is the stored result of the linting of a dashboard.
+type lintState struct {
+	ID               int64  `xorm:"pk autoincr 'id'"`
+	OrgID            int64  `xorm:"org_id"`
+	DashboardUID     string `xorm:"dashboard_uid"`
+	DashboardVersion int    `xorm:"dashboard_version"`
+	WarningCount     int    `xorm:"warning_count"`
+	// Warnings is the JSON encoded list of warnings.
+	Warnings string `xorm:"warnings"`
+	Updated  int64  `xorm:"updated"`
+}
+
+func (lintState) TableName() string {
+	return "dashboard_lint"
+}
@@ -0,0 +1,229 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+// variableRegex matches the references to template variables, like the frontend template service.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+// deprecatedPanels maps the deprecated panel types to the types replacing them.
+var deprecatedPanels = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+// legacyVariables are replaced by the query editors of some data sources, they are not template variables.
+var legacyVariables = map[string]bool{
+	"timeFilter":  true,
+	"interval":    true,
+	"col":         true,
+	"m":           true,
+	"measurement": true,
+}
+
+// builtInDataSources are the data sources that are not stored in the organization.
+var builtInDataSources = map[string]bool{
+	grafanads.DatasourceUID:  true,
+	grafanads.DatasourceName: true,
+	"-- Mixed --":            true,
+	"-- Dashboard --":        true,
+	"default":                true,
+}
+
+type linter struct {
+	maxPanels int
+	// dataSources has the UIDs and the names of the data sources of the organization.
+	dataSources map[string]bool
+	// variables has the names of the template variables of the dashboard.
+	variables map[string]bool
+	warnings  []Warning
+}
+
+// lint returns the warnings of a dashboard JSON model, in the order of the variables, annotations and panels.
+func (l *linter) lint(data *simplejson.Json) []Warning {
+	l.variables = make(map[string]bool)
+	l.warnings = make([]Warning, 0)
+
+	templating := data.GetPath("templating", "list").MustArray()
+	for _, v := range templating {
+		if name := simplejson.NewFromAny(v).Get("name").MustString(); name != "" {
+			l.variables[name] = true
+		}
+	}
+	for _, v := range templating {
+		variable := simplejson.NewFromAny(v)
+		subject := fmt.Sprintf("Variable %q", variable.Get("name").MustString())
+		l.checkDataSource(0, subject, variable.Get("datasource"))
+		l.checkVariables(0, subject, variable.Get("query").Interface(), variable.Get("definition").Interface(), variable.Get("regex").Interface())
+	}
+
+	for _, a := range data.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(a)
+		l.checkDataSource(0, fmt.Sprintf("Annotation %q", annotation.Get("name").MustString()), annotation.Get("datasource"))
+	}
+
+	count := 0
+	for _, panel := range flattenPanels(data) {
+		if panel.Get("type").MustString() == "row" {
+			l.checkRepeat(panel)
+			continue
+		}
+		count++
+		l.lintPanel(panel)
+	}
+	if count > l.maxPanels {
+		l.warn(RuleTooManyPanels, 0, "The dashboard has %d panels, more than the maximum of %d", count, l.maxPanels)
+	}
+
+	return l.warnings
+}
+
+func (l *linter) lintPanel(panel *simplejson.Json) {
+	id := panel.Get("id").MustInt64()
+	subject := panelSubject(panel)
+
+	panelType := panel.Get("type").MustString()
+	if replacement, ok := deprecatedPanels[panelType]; ok {
+		l.warn(RuleDeprecatedPanel, id, "%s uses the deprecated %s panel, use %s instead", subject, panelType, replacement)
+	}
+
+	l.checkRepeat(panel)
+
+	reported := l.checkDataSource(id, subject, panel.Get("datasource"))
+	// the expressions reference the other queries with $refId, they are not checked for variables
+	queries := make([]any, 0)
+	for _, t := range panel.Get("targets").MustArray() {
+		target := simplejson.NewFromAny(t)
+		if expr.IsDataSource(target.GetPath("datasource", "uid").MustString()) {
+			continue
+		}
+		queries = append(queries, t)
+		if ref, missing := l.missingDataSource(target.Get("datasource")); missing && !reported[ref] {
+			l.warn(RuleMissingDataSource, id, "%s queries the data source %q, which doesn't exist", subject, ref)
+			reported[ref] = true
+		}
+	}
+
+	l.checkVariables(id, subject, panel.Get("title").Interface(), queries)
+}
+
+// checkRepeat reports the panels and rows repeated for a variable that doesn't exist.
+func (l *linter) checkRepeat(panel *simplejson.Json) {
+	if repeat := panel.Get("repeat").MustString(); repeat != "" && !l.variables[repeat] {
+		l.warn(RuleUndefinedVariable, panel.Get("id").MustInt64(), "%s is repeated for the undefined variable $%s", panelSubject(panel), repeat)
+	}
+}
+
+// checkDataSource reports the data source reference if it doesn't exist, and returns the reported references.
+func (l *linter) checkDataSource(panelID int64, subject string, ds *simplejson.Json) map[string]bool {
+	reported := make(map[string]bool)
+	if ref, missing := l.missingDataSource(ds); missing {
+		l.warn(RuleMissingDataSource, panelID, "%s queries the data source %q, which doesn't exist", subject, ref)
+		reported[ref] = true
+	}
+	return reported
+}
+
+// missingDataSource returns the UID or the legacy name of a data source reference, and whether the data source
+// doesn't exist. The references to variables are not checked, since they are resolved when the dashboard is loaded.
+func (l *linter) missingDataSource(ds *simplejson.Json) (string, bool) {
+	var ref string
+	switch v := ds.Interface().(type) {
+	case string:
+		ref = v
+	case map[string]any:
+		ref, _ = v["uid"].(string)
+	}
+	if ref == "" || variableRegex.MatchString(ref) || expr.IsDataSource(ref) || builtInDataSources[ref] || l.dataSources[ref] {
+		return ref, false
+	}
+	return ref, true
+}
+
+// checkVariables reports the undefined variables referenced in the strings of the values, once per subject.
+func (l *linter) checkVariables(panelID int64, subject string, values ...any) {
+	undefined := make(map[string]bool)
+	for _, value := range values {
+		l.collectUndefined(value, undefined)
+	}
+	names := make([]string, 0, len(undefined))
+	for name := range undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l.warn(RuleUndefinedVariable, panelID, "%s references the undefined variable $%s", subject, name)
+	}
+}
+
+func (l *linter) collectUndefined(value any, undefined map[string]bool) {
+	switch v := value.(type) {
+	case string:
+		for _, match := range variableRegex.FindAllStringSubmatch(v, -1) {
+			name := match[1] + match[2] + match[4]
+			if !l.isDefined(name) {
+				undefined[name] = true
+			}
+		}
+	case map[string]any:
+		for _, item := range v {
+			l.collectUndefined(item, undefined)
+		}
+	case []any:
+		for _, item := range v {
+			l.collectUndefined(item, undefined)
+		}
+	}
+}
+
+// isDefined returns whether a referenced variable is defined by the dashboard or by Grafana, like $__interval. The
+// numbered references, like $1 in regular expression replacements, are not variables.
+func (l *linter) isDefined(name string) bool {
+	return l.variables[name] || strings.HasPrefix(name, "__") || strings.HasPrefix(name, "tag_") ||
+		legacyVariables[name] || strings.Trim(name, "0123456789") == ""
+}
+
+func (l *linter) warn(rule Rule, panelID int64, format string, args ...any) {
+	l.warnings = append(l.warnings, Warning{Rule: rule, PanelID: panelID, Message: fmt.Sprintf(format, args...)})
+}
+
+func panelSubject(panel *simplejson.Json) string {
+	kind := "Panel"
+	if panel.Get("type").MustString() == "row" {
+		kind = "Row"
+	}
+	if title := panel.Get("title").MustString(); title != "" {
+		return fmt.Sprintf("%s %q", kind, title)
+	}
+	return fmt.Sprintf("%s %d", kind, panel.Get("id").MustInt64())
+}
+
+// flattenPanels returns the panels of a dashboard, including the ones of the collapsed rows and of the legacy rows.
+func flattenPanels(data *simplejson.Json) []*simplejson.Json {
+	panels := make([]*simplejson.Json, 0)
+	for _, p := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		panels = append(panels, panel)
+		for _, nested := range panel.Get("panels").MustArray() {
+			panels = append(panels, simplejson.NewFromAny(nested))
+		}
+	}
+	for _, row := range data.Get("rows").MustArray() {
+		for _, p := range simplejson.NewFromAny(row).Get("panels").MustArray() {
+			panels = append(panels, simplejson.NewFromAny(p))
+		}
+	}
+	return panels
+}
@@ -0,0 +1,76 @@
+package lint
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// getState returns the stored lint result of a dashboard, nil if it was never linted.
+func (s *Service) getState(ctx context.Context, orgID int64, uid string) (*lintState, error) {
+	state := &lintState{}
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND dashboard_uid = ?", orgID, uid).Get(state)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return state, nil
+}
+
+// saveState replaces the stored lint result of a dashboard.
+func (s *Service) saveState(ctx context.Context, state *lintState) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &lintState{}
+		exists, err := sess.Where("org_id = ? AND dashboard_uid = ?", state.OrgID, state.DashboardUID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = sess.Insert(state)
+			return err
+		}
+		state.ID = existing.ID
+		_, err = sess.ID(state.ID).AllCols().Omit("id", "org_id", "dashboard_uid").Update(state)
+		return err
+	})
+}
+
+// countWarnings returns the number of warnings of the linted dashboards of an organization by UID.
+func (s *Service) countWarnings(ctx context.Context, orgID int64, uids []string) (map[string]int, error) {
+	states := make([]*lintState, 0, len(uids))
+	err := s.store.WithReadOnlyDbSession(ctx, func(sess *db.Session) error {
+		return sess.Cols("dashboard_uid", "warning_count").Where("org_id = ?", orgID).In("dashboard_uid", uids).Find(&states)
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(states))
+	for _, state := range states {
+		counts[state.DashboardUID] = state.WarningCount
+	}
+	return counts, nil
+}
+
+// staleDashboard is a dashboard to lint.
+type staleDashboard struct {
+	OrgID int64  `xorm:"org_id"`
+	UID   string `xorm:"uid"`
+}
+
+// findStale returns the dashboards that were never linted, or were saved since they were last linted, the least
+// recently updated first.
+func (s *Service) findStale(ctx context.Context, limit int) ([]staleDashboard, error) {
+	stale := make([]staleDashboard, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(`SELECT d.org_id, d.uid FROM dashboard AS d
+LEFT JOIN dashboard_lint AS l ON l.org_id = d.org_id AND l.dashboard_uid = d.uid
+WHERE d.is_folder = ? AND d.deleted IS NULL AND (l.id IS NULL OR l.dashboard_version <> d.version)
+ORDER BY d.updated
+LIMIT ?`, s.store.GetDialect().BooleanStr(false), limit).Find(&stale)
+	})
+	return stale, err
+}
@@ -214,6 +214,41 @@ type RestoreDeletedDashboardCommand struct {
 	FolderUID string `json:"folderUid" xorm:"folder_uid"`
 }
 
+// UnrestorableDataKey is the key of the data of the folders in the trash which can't be restored, with the reason
+// they can't be.
+const UnrestorableDataKey = "unrestorable"
+
+// GetSoftDeletedDashboardsQuery is used to list the dashboards and folders in the trash of an organization.
+type GetSoftDeletedDashboardsQuery struct {
+	OrgID int64
+	// FolderUIDs restricts the list to the dashboards and folders in these folders.
+	FolderUIDs []string
+	// DeletedSince restricts the list to the dashboards and folders deleted at or after this time.
+	DeletedSince time.Time
+}
+
+// DashboardSize is the size of the JSON of a dashboard.
+type DashboardSize struct {
+	UID       string `json:"uid" xorm:"uid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid" xorm:"folder_uid"`
+	// Size is the size in bytes of the JSON of the dashboard.
+	Size int64 `json:"size"`
+	// StoredSize is the size in bytes of the JSON stored in the database, smaller than Size when it is compressed.
+	StoredSize int64 `json:"storedSize"`
+	// Versions is the number of versions of the dashboard.
+	Versions int64     `json:"versions"`
+	Updated  time.Time `json:"updated"`
+}
+
+// GetDashboardSizesQuery is used to list the sizes of the dashboards of an organization, from the largest.
+type GetDashboardSizesQuery struct {
+	OrgID int64
+	// UIDs restricts the list to these dashboards.
+	UIDs  []string
+	Limit int
+}
+
 type DashboardProvisioning struct {
 	ID          int64 `xorm:"pk autoincr 'id'"`
 	DashboardID int64 `xorm:"dashboard_id"`
@@ -0,0 +1,87 @@
+package schemamigration
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	canWrite := authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))))
+
+	routeRegister.Group("/api/dashboards", func(dashboardRoute routing.RouteRegister) {
+		dashboardRoute.Get("/schema/report", middleware.ReqOrgAdmin, routing.Wrap(s.reportHandler))
+		dashboardRoute.Post("/uid/:uid/schema/migrate", canWrite, routing.Wrap(s.migrateHandler))
+		dashboardRoute.Post("/uid/:uid/schema/rollback", canWrite, routing.Wrap(s.rollbackHandler))
+		dashboardRoute.Put("/uid/:uid/schema/pin", canWrite, routing.Wrap(s.pinHandler))
+		dashboardRoute.Delete("/uid/:uid/schema/pin", canWrite, routing.Wrap(s.unpinHandler))
+	}, middleware.ReqSignedIn)
+}
+
+func (s *Service) migrateHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.MigrateDashboard(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to migrate dashboard schema")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) rollbackHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.Rollback(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to roll back dashboard schema migration")
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) pinHandler(c *contextmodel.ReqContext) response.Response {
+	state, err := s.SetPinned(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], true)
+	if err != nil {
+		return errorResponse(err, "Failed to pin dashboard schema version")
+	}
+	return response.JSON(http.StatusOK, state)
+}
+
+func (s *Service) unpinHandler(c *contextmodel.ReqContext) response.Response {
+	state, err := s.SetPinned(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], false)
+	if err != nil {
+		return errorResponse(err, "Failed to unpin dashboard schema version")
+	}
+	return response.JSON(http.StatusOK, state)
+}
+
+func (s *Service) reportHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.Report(c.Req.Context(), ReportQuery{
+		OrgID:            c.SignedInUser.GetOrgID(),
+		MaxSchemaVersion: c.QueryInt("maxSchemaVersion"),
+		PinnedOnly:       c.QueryBool("pinned"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard schema report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+func errorResponse(err error, message string) response.Response {
+	var dashboardErr dashboards.DashboardErr
+	switch {
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	case errors.Is(err, ErrUnsupportedSchemaVersion), errors.Is(err, ErrNothingToRollback):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrDashboardPinned):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.As(err, &dashboardErr):
+		return response.Error(dashboardErr.StatusCode, err.Error(), err)
+	default:
+		return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+	}
+}
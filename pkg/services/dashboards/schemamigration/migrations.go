@@ -0,0 +1,178 @@
+package schemamigration
+
+import (
+	"encoding/json"
+	"fmt"
+
+	dashboardkind "github.com/grafana/grafana/pkg/kinds/dashboard"
+)
+
+const (
+	// LatestSchemaVersion is the schema version the dashboards are migrated to. It is the
+	// DASHBOARD_SCHEMA_VERSION of the frontend.
+	LatestSchemaVersion = 39
+	// MinSchemaVersion is the oldest schema version the backend can migrate. Older dashboards need the frontend
+	// migrations, which depend on the data sources of the browser.
+	MinSchemaVersion = dashboardkind.HandoffSchemaVersion
+)
+
+// panelMigrations upgrade a panel from the previous schema version to the version they are keyed by. They are
+// the backend equivalent of the panel upgrades of DashboardMigrator.ts.
+var panelMigrations = map[int]func(panel map[string]any){
+	37: normalizeLegend,
+	38: tableDisplayModeToCellOptions,
+	39: timeSeriesTableStatsToOptions,
+}
+
+// SchemaVersion returns the schema version of the JSON model of a dashboard, 0 if it has none.
+func SchemaVersion(dashboard map[string]any) int {
+	switch v := dashboard["schemaVersion"].(type) {
+	case json.Number:
+		i, _ := v.Int64()
+		return int(i)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	}
+	return 0
+}
+
+// Migrate upgrades the JSON model of a dashboard in place to LatestSchemaVersion, and returns the schema version
+// it was migrated from.
+func Migrate(dashboard map[string]any) (int, error) {
+	from := SchemaVersion(dashboard)
+	if from >= LatestSchemaVersion {
+		return from, nil
+	}
+	if from < MinSchemaVersion {
+		return from, fmt.Errorf("%w: %d is older than %d", ErrUnsupportedSchemaVersion, from, MinSchemaVersion)
+	}
+
+	for version := from + 1; version <= LatestSchemaVersion; version++ {
+		migrate, ok := panelMigrations[version]
+		if !ok {
+			continue
+		}
+		for _, panel := range panels(dashboard) {
+			migrate(panel)
+		}
+	}
+	dashboard["schemaVersion"] = LatestSchemaVersion
+	return from, nil
+}
+
+// panels returns the panels of a dashboard, including the panels of the collapsed rows.
+func panels(dashboard map[string]any) []map[string]any {
+	result := make([]map[string]any, 0)
+	for _, p := range list(dashboard["panels"]) {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		result = append(result, panel)
+		if panel["type"] != "row" {
+			continue
+		}
+		for _, nested := range list(panel["panels"]) {
+			if nestedPanel, ok := nested.(map[string]any); ok {
+				result = append(result, nestedPanel)
+			}
+		}
+	}
+	return result
+}
+
+// normalizeLegend normalizes the two ways of hiding the legend of a panel to legend.showLegend.
+func normalizeLegend(panel map[string]any) {
+	legend := object(object(panel["options"])["legend"])
+	if legend == nil {
+		return
+	}
+	if legend["displayMode"] == "hidden" || legend["showLegend"] == false {
+		legend["displayMode"] = "list"
+		legend["showLegend"] = false
+		return
+	}
+	legend["showLegend"] = true
+}
+
+// tableDisplayModeToCellOptions replaces the display mode of the cells of a table panel with the cell options.
+func tableDisplayModeToCellOptions(panel map[string]any) {
+	if panel["type"] != "table" {
+		return
+	}
+	fieldConfig := object(panel["fieldConfig"])
+	if fieldConfig == nil {
+		return
+	}
+
+	custom := object(object(fieldConfig["defaults"])["custom"])
+	if displayMode, ok := custom["displayMode"]; ok {
+		custom["cellOptions"] = cellOptions(displayMode)
+		delete(custom, "displayMode")
+	}
+
+	for _, o := range list(fieldConfig["overrides"]) {
+		for _, p := range list(object(o)["properties"]) {
+			property := object(p)
+			if property == nil || property["id"] != "custom.displayMode" {
+				continue
+			}
+			property["id"] = "custom.cellOptions"
+			property["value"] = cellOptions(property["value"])
+		}
+	}
+}
+
+// cellOptions returns the cell options of a table cell display mode, like migrateTableDisplayModeToCellOptions.
+func cellOptions(displayMode any) map[string]any {
+	switch displayMode {
+	case "basic":
+		return map[string]any{"type": "gauge", "mode": "basic"}
+	case "gradient-gauge":
+		return map[string]any{"type": "gauge", "mode": "gradient"}
+	case "lcd-gauge":
+		return map[string]any{"type": "gauge", "mode": "lcd"}
+	case "color-background":
+		// somewhat confusingly, color-background is the gradient display
+		return map[string]any{"type": "color-background", "mode": "gradient"}
+	case "color-background-solid":
+		return map[string]any{"type": "color-background", "mode": "basic"}
+	default:
+		return map[string]any{"type": displayMode}
+	}
+}
+
+// timeSeriesTableStatsToOptions replaces the refIdToStat option of the time series table transformations with
+// an option per refId.
+func timeSeriesTableStatsToOptions(panel map[string]any) {
+	for _, t := range list(panel["transformations"]) {
+		transformation := object(t)
+		if transformation == nil || transformation["id"] != "timeSeriesTable" {
+			continue
+		}
+		options := object(transformation["options"])
+		refIDToStat, ok := options["refIdToStat"].(map[string]any)
+		if !ok {
+			continue
+		}
+		newOptions := make(map[string]any, len(refIDToStat))
+		for refID, stat := range refIDToStat {
+			newOptions[refID] = map[string]any{"stat": stat}
+		}
+		transformation["options"] = newOptions
+	}
+}
+
+func object(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
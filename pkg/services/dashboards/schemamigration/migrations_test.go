@@ -0,0 +1,96 @@
+package schemamigration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := map[string]struct {
+		panel    string
+		expected string
+	}{
+		"hidden legend": {
+			panel:    `{"options": {"legend": {"displayMode": "hidden"}}}`,
+			expected: `{"options": {"legend": {"displayMode": "list", "showLegend": false}}}`,
+		},
+		"visible legend": {
+			panel:    `{"options": {"legend": {"displayMode": "table"}}}`,
+			expected: `{"options": {"legend": {"displayMode": "table", "showLegend": true}}}`,
+		},
+		"table display mode": {
+			panel: `{"type": "table", "fieldConfig": {
+				"defaults": {"custom": {"displayMode": "color-background"}},
+				"overrides": [{"properties": [{"id": "custom.displayMode", "value": "lcd-gauge"}, {"id": "unit", "value": "s"}]}]
+			}}`,
+			expected: `{"type": "table", "fieldConfig": {
+				"defaults": {"custom": {"cellOptions": {"type": "color-background", "mode": "gradient"}}},
+				"overrides": [{"properties": [{"id": "custom.cellOptions", "value": {"type": "gauge", "mode": "lcd"}}, {"id": "unit", "value": "s"}]}]
+			}}`,
+		},
+		"display mode of another panel type": {
+			panel:    `{"type": "stat", "fieldConfig": {"defaults": {"custom": {"displayMode": "basic"}}}}`,
+			expected: `{"type": "stat", "fieldConfig": {"defaults": {"custom": {"displayMode": "basic"}}}}`,
+		},
+		"time series table stats": {
+			panel:    `{"transformations": [{"id": "timeSeriesTable", "options": {"refIdToStat": {"A": "mean", "B": "max"}}}]}`,
+			expected: `{"transformations": [{"id": "timeSeriesTable", "options": {"A": {"stat": "mean"}, "B": {"stat": "max"}}}]}`,
+		},
+		"panel in a collapsed row": {
+			panel:    `{"type": "row", "panels": [{"options": {"legend": {"showLegend": false}}}]}`,
+			expected: `{"type": "row", "panels": [{"options": {"legend": {"displayMode": "list", "showLegend": false}}}]}`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dashboard := model(t, `{"schemaVersion": 36, "panels": [`+tc.panel+`]}`)
+			from, err := Migrate(dashboard)
+			require.NoError(t, err)
+			require.Equal(t, 36, from)
+			requireModel(t, `{"schemaVersion": 39, "panels": [`+tc.expected+`]}`, dashboard)
+		})
+	}
+}
+
+func TestMigrateFromSchemaVersion(t *testing.T) {
+	t.Run("only runs the newer migrations", func(t *testing.T) {
+		dashboard := model(t, `{"schemaVersion": 37, "panels": [{"options": {"legend": {"displayMode": "hidden"}}}]}`)
+		from, err := Migrate(dashboard)
+		require.NoError(t, err)
+		require.Equal(t, 37, from)
+		requireModel(t, `{"schemaVersion": 39, "panels": [{"options": {"legend": {"displayMode": "hidden"}}}]}`, dashboard)
+	})
+
+	t.Run("leaves up to date dashboards unchanged", func(t *testing.T) {
+		dashboard := model(t, `{"schemaVersion": 39, "panels": [{"options": {"legend": {"displayMode": "hidden"}}}]}`)
+		from, err := Migrate(dashboard)
+		require.NoError(t, err)
+		require.Equal(t, LatestSchemaVersion, from)
+		requireModel(t, `{"schemaVersion": 39, "panels": [{"options": {"legend": {"displayMode": "hidden"}}}]}`, dashboard)
+	})
+
+	t.Run("fails for the versions migrated by the frontend", func(t *testing.T) {
+		dashboard := model(t, `{"schemaVersion": 30, "panels": []}`)
+		_, err := Migrate(dashboard)
+		require.ErrorIs(t, err, ErrUnsupportedSchemaVersion)
+		require.Equal(t, 30, SchemaVersion(dashboard))
+	})
+}
+
+func model(t *testing.T, s string) map[string]any {
+	t.Helper()
+	j, err := simplejson.NewJson([]byte(s))
+	require.NoError(t, err)
+	return j.MustMap()
+}
+
+func requireModel(t *testing.T, expected string, actual map[string]any) {
+	t.Helper()
+	b, err := json.Marshal(actual)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(b))
+}
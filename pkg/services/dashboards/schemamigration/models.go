@@ -0,0 +1,76 @@
+package schemamigration
+
+import (
+	"errors"
+)
+
+var (
+	ErrUnsupportedSchemaVersion = errors.New("unsupported dashboard schema version")
+	ErrDashboardPinned          = errors.New("the dashboard is pinned to its schema version")
+	ErrNothingToRollback        = errors.New("the dashboard has no schema migration to roll back")
+)
+
+// State is the schema migration state of a dashboard.
+type State struct {
+	ID           int64  `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID        int64  `xorm:"org_id" json:"orgId"`
+	DashboardUID string `xorm:"dashboard_uid" json:"dashboardUid"`
+	// SchemaVersion is the schema version of the dashboard when it was last migrated, rolled back or pinned.
+	SchemaVersion int `xorm:"schema_version" json:"schemaVersion"`
+	// Pinned dashboards keep their schema version: they are skipped by the background migration, and can't be
+	// migrated on demand until they are unpinned.
+	Pinned bool `xorm:"pinned" json:"pinned"`
+	// MigratedFromSchema and MigratedFromVersion are the schema version and the dashboard version before the last
+	// migration, 0 if there is no migration to roll back.
+	MigratedFromSchema  int   `xorm:"migrated_from_schema" json:"migratedFromSchema,omitempty"`
+	MigratedFromVersion int   `xorm:"migrated_from_version" json:"migratedFromVersion,omitempty"`
+	MigratedAt          int64 `xorm:"migrated_at" json:"migratedAt,omitempty"`
+	// LastError is the error of the last failed migration.
+	LastError string `xorm:"last_error" json:"lastError,omitempty"`
+	Updated   int64  `xorm:"updated" json:"updated"`
+}
+
+func (State) TableName() string {
+	return "dashboard_schema_state"
+}
+
+// Result is the result of the migration or the rollback of a dashboard.
+type Result struct {
+	DashboardUID string `json:"dashboardUid"`
+	// FromSchemaVersion and ToSchemaVersion are equal if the dashboard was already up to date.
+	FromSchemaVersion int `json:"fromSchemaVersion"`
+	ToSchemaVersion   int `json:"toSchemaVersion"`
+	// Version is the version of the saved dashboard.
+	Version int `json:"version"`
+}
+
+// ReportQuery lists the dashboards of an organization on an old schema version.
+type ReportQuery struct {
+	OrgID int64
+	// MaxSchemaVersion only lists the dashboards up to that schema version, all the outdated ones if it is 0.
+	MaxSchemaVersion int
+	// PinnedOnly only lists the pinned dashboards.
+	PinnedOnly bool
+}
+
+// Report lists the dashboards on an old schema version.
+type Report struct {
+	LatestSchemaVersion int `json:"latestSchemaVersion"`
+	MinSchemaVersion    int `json:"minSchemaVersion"`
+	// Count is the number of outdated dashboards per schema version.
+	Count      map[int]int        `json:"count"`
+	Dashboards []*ReportDashboard `json:"dashboards"`
+}
+
+// ReportDashboard is a dashboard on an old schema version.
+type ReportDashboard struct {
+	UID           string `json:"uid"`
+	Title         string `json:"title"`
+	FolderUID     string `json:"folderUid"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Pinned        bool   `json:"pinned"`
+	// Migratable is false if the schema version is older than the backend supports, the dashboard is then
+	// migrated when it is saved from the frontend.
+	Migratable bool   `json:"migratable"`
+	LastError  string `json:"lastError,omitempty"`
+}
@@ -0,0 +1,355 @@
+// Package schemamigration upgrades the JSON models of the stored dashboards to the latest schema version, so that
+// the frontend doesn't have to migrate them every time they are loaded. Dashboards are migrated on demand or in
+// the background, a batch at a time. A migration can be rolled back, which restores the dashboard version saved
+// before it and pins the dashboard to its schema version.
+package schemamigration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// reportBatchSize is the number of dashboards scanned at a time for a report.
+const reportBatchSize = 500
+
+// Service migrates the dashboards to the latest schema version.
+type Service struct {
+	settings         setting.DashboardSchemaMigrationSettings
+	store            db.DB
+	dashboardService dashboards.DashboardService
+	versionService   dashver.Service
+	accessControl    ac.AccessControl
+	serverLock       *serverlock.ServerLockService
+	log              log.Logger
+	now              func() time.Time
+
+	// afterID is the ID of the last dashboard of the previous background batch.
+	afterID int64
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, dashboardService dashboards.DashboardService,
+	versionService dashver.Service, accessControl ac.AccessControl, serverLock *serverlock.ServerLockService,
+	routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		settings:         cfg.DashboardSchemaMigration,
+		store:            sqlStore,
+		dashboardService: dashboardService,
+		versionService:   versionService,
+		accessControl:    accessControl,
+		serverLock:       serverLock,
+		log:              log.New("dashboards.schemamigration"),
+		now:              time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// IsDisabled returns true if the background migration is disabled. The dashboards can still be migrated on demand.
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled
+}
+
+// Run migrates a batch of dashboards every interval until ctx is done. Only one Grafana instance migrates
+// dashboards at a time.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.Interval)
+	defer ticker.Stop()
+	for {
+		err := s.serverLock.LockAndExecute(ctx, "migrate dashboard schemas", s.settings.Interval/2, func(ctx context.Context) {
+			if err := s.migrateBatch(ctx); err != nil {
+				s.log.Error("Failed to migrate dashboard schemas", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to migrate dashboard schemas", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// migrateBatch migrates the outdated dashboards of the next batch that are not pinned. The failed migrations are
+// recorded in the state of the dashboards, and are retried with the next scan of every dashboard.
+func (s *Service) migrateBatch(ctx context.Context) error {
+	batch, err := s.listDashboards(ctx, 0, s.afterID, s.settings.BatchSize)
+	if err != nil {
+		return err
+	}
+	if len(batch) < s.settings.BatchSize {
+		// start over with the next batch
+		s.afterID = 0
+		if err := s.deleteStates(ctx); err != nil {
+			s.log.Warn("Failed to delete the schema state of deleted dashboards", "error", err)
+		}
+	} else {
+		s.afterID = batch[len(batch)-1].ID
+	}
+
+	uids := make([]string, 0, len(batch))
+	for _, dash := range batch {
+		uids = append(uids, dash.UID)
+	}
+	states, err := s.listStates(ctx, 0, uids)
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, dash := range batch {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		version := SchemaVersion(dash.Data.MustMap())
+		if version >= LatestSchemaVersion || version < MinSchemaVersion {
+			continue
+		}
+		if state, ok := states[stateKey(dash.OrgID, dash.UID)]; ok && state.Pinned {
+			continue
+		}
+		if _, err := s.MigrateDashboard(ctx, backgroundUser(dash.OrgID), dash.UID); err != nil {
+			s.log.Warn("Failed to migrate dashboard schema", "orgId", dash.OrgID, "uid", dash.UID, "error", err)
+			continue
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		s.log.Info("Migrated dashboard schemas", "dashboards", migrated, "schemaVersion", LatestSchemaVersion)
+	}
+	return nil
+}
+
+// MigrateDashboard migrates a dashboard to the latest schema version and saves it as a new version. The state of
+// the dashboard keeps the version before the migration to roll it back.
+func (s *Service) MigrateDashboard(ctx context.Context, user identity.Requester, uid string) (*Result, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.getState(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	if state.Pinned {
+		return nil, ErrDashboardPinned
+	}
+
+	model, err := copyModel(dash.Data)
+	if err != nil {
+		return nil, err
+	}
+	from, err := Migrate(model)
+	if err != nil {
+		s.recordError(ctx, state, from, err)
+		return nil, err
+	}
+	result := &Result{DashboardUID: uid, FromSchemaVersion: from, ToSchemaVersion: LatestSchemaVersion, Version: dash.Version}
+	if from >= LatestSchemaVersion {
+		result.ToSchemaVersion = from
+		return result, nil
+	}
+
+	saved, err := s.save(ctx, user, dash, model, fmt.Sprintf("Migrated schema version %d to %d", from, LatestSchemaVersion))
+	if err != nil {
+		s.recordError(ctx, state, from, err)
+		return nil, err
+	}
+	state.SchemaVersion = LatestSchemaVersion
+	state.MigratedFromSchema = from
+	state.MigratedFromVersion = dash.Version
+	state.MigratedAt = s.now().Unix()
+	state.LastError = ""
+	if err := s.saveState(ctx, state); err != nil {
+		return nil, err
+	}
+	result.Version = saved.Version
+	return result, nil
+}
+
+// Rollback restores the version of a dashboard saved before its last schema migration, as a new version, and pins
+// the dashboard to the restored schema version so that it isn't migrated again in the background.
+func (s *Service) Rollback(ctx context.Context, user identity.Requester, uid string) (*Result, error) {
+	state, err := s.getState(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	if state.MigratedFromVersion == 0 {
+		return nil, ErrNothingToRollback
+	}
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.versionService.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardUID: uid,
+		OrgID:        user.GetOrgID(),
+		Version:      state.MigratedFromVersion,
+	})
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return nil, fmt.Errorf("%w: version %d no longer exists", ErrNothingToRollback, state.MigratedFromVersion)
+		}
+		return nil, err
+	}
+
+	model, err := copyModel(previous.Data)
+	if err != nil {
+		return nil, err
+	}
+	from := SchemaVersion(dash.Data.MustMap())
+	saved, err := s.save(ctx, user, dash, model, fmt.Sprintf("Rolled back schema migration to version %d", previous.Version))
+	if err != nil {
+		return nil, err
+	}
+	state.SchemaVersion = SchemaVersion(model)
+	state.Pinned = true
+	state.MigratedFromSchema = 0
+	state.MigratedFromVersion = 0
+	state.LastError = ""
+	if err := s.saveState(ctx, state); err != nil {
+		return nil, err
+	}
+	return &Result{DashboardUID: uid, FromSchemaVersion: from, ToSchemaVersion: state.SchemaVersion, Version: saved.Version}, nil
+}
+
+// recordError records the error of a failed migration in the state of a dashboard.
+func (s *Service) recordError(ctx context.Context, state *State, schemaVersion int, err error) {
+	state.SchemaVersion = schemaVersion
+	state.LastError = err.Error()
+	if err := s.saveState(ctx, state); err != nil {
+		s.log.Warn("Failed to save dashboard schema state", "uid", state.DashboardUID, "error", err)
+	}
+}
+
+// SetPinned pins a dashboard to its schema version, or unpins it.
+func (s *Service) SetPinned(ctx context.Context, orgID int64, uid string, pinned bool) (*State, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.getState(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	state.SchemaVersion = SchemaVersion(dash.Data.MustMap())
+	state.Pinned = pinned
+	if err := s.saveState(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Report lists the dashboards of an organization on an old schema version.
+func (s *Service) Report(ctx context.Context, query ReportQuery) (*Report, error) {
+	report := &Report{
+		LatestSchemaVersion: LatestSchemaVersion,
+		MinSchemaVersion:    MinSchemaVersion,
+		Count:               map[int]int{},
+		Dashboards:          make([]*ReportDashboard, 0),
+	}
+	for afterID := int64(0); ; {
+		batch, err := s.listDashboards(ctx, query.OrgID, afterID, reportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		outdated := make([]*ReportDashboard, 0)
+		uids := make([]string, 0)
+		for _, dash := range batch {
+			version := SchemaVersion(dash.Data.MustMap())
+			if version >= LatestSchemaVersion || (query.MaxSchemaVersion > 0 && version > query.MaxSchemaVersion) {
+				continue
+			}
+			outdated = append(outdated, &ReportDashboard{
+				UID:           dash.UID,
+				Title:         dash.Title,
+				FolderUID:     dash.FolderUID,
+				SchemaVersion: version,
+				Migratable:    version >= MinSchemaVersion,
+			})
+			uids = append(uids, dash.UID)
+		}
+		if len(uids) > 0 {
+			states, err := s.listStates(ctx, query.OrgID, uids)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range outdated {
+				if state, ok := states[stateKey(query.OrgID, d.UID)]; ok {
+					d.Pinned = state.Pinned
+					d.LastError = state.LastError
+				}
+				if query.PinnedOnly && !d.Pinned {
+					continue
+				}
+				report.Count[d.SchemaVersion]++
+				report.Dashboards = append(report.Dashboards, d)
+			}
+		}
+		if len(batch) < reportBatchSize {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+	return report, nil
+}
+
+// save saves the migrated or the restored model of a dashboard as a new version of the current dashboard.
+func (s *Service) save(ctx context.Context, user identity.Requester, current *dashboards.Dashboard, model map[string]any, message string) (*dashboards.Dashboard, error) {
+	dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(model))
+	dash.OrgID = current.OrgID
+	dash.FolderUID = current.FolderUID
+	dash.SetID(current.ID)
+	dash.SetUID(current.UID)
+	dash.SetVersion(current.Version)
+	return s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     current.OrgID,
+		User:      user,
+		Message:   message,
+		Dashboard: dash,
+	}, false)
+}
+
+// backgroundUser is the identity of the background migration in an organization.
+func backgroundUser(orgID int64) identity.Requester {
+	return ac.BackgroundUser("dashboard_schema_migration", orgID, org.RoleAdmin, []ac.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsAll},
+		{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeDashboardsAll},
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+	})
+}
+
+func stateKey(orgID int64, uid string) string {
+	return fmt.Sprintf("%d/%s", orgID, uid)
+}
+
+// copyModel returns a deep copy of the JSON model of a dashboard, which is migrated in place.
+func copyModel(data *simplejson.Json) (map[string]any, error) {
+	b, err := data.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	copied, err := simplejson.NewJson(b)
+	if err != nil {
+		return nil, err
+	}
+	return copied.MustMap(), nil
+}
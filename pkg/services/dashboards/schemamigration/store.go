@@ -0,0 +1,81 @@
+package schemamigration
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func (s *Service) getState(ctx context.Context, orgID int64, uid string) (*State, error) {
+	state := &State{}
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND dashboard_uid = ?", orgID, uid).Get(state)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &State{OrgID: orgID, DashboardUID: uid}, nil
+	}
+	return state, nil
+}
+
+func (s *Service) saveState(ctx context.Context, state *State) error {
+	state.Updated = s.now().Unix()
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if state.ID == 0 {
+			_, err := sess.Insert(state)
+			return err
+		}
+		_, err := sess.ID(state.ID).AllCols().Omit("id", "org_id", "dashboard_uid").Update(state)
+		return err
+	})
+}
+
+// listStates returns the states of the dashboards of an organization by UID, of every organization if orgID is 0.
+func (s *Service) listStates(ctx context.Context, orgID int64, uids []string) (map[string]*State, error) {
+	states := make([]*State, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if orgID != 0 {
+			sess.Where("org_id = ?", orgID)
+		}
+		return sess.In("dashboard_uid", uids).Find(&states)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byUID := make(map[string]*State, len(states))
+	for _, state := range states {
+		byUID[stateKey(state.OrgID, state.DashboardUID)] = state
+	}
+	return byUID, nil
+}
+
+// deleteStates deletes the states of the dashboards that no longer exist.
+func (s *Service) deleteStates(ctx context.Context) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec(`DELETE FROM dashboard_schema_state WHERE NOT EXISTS (
+			SELECT 1 FROM dashboard WHERE dashboard.org_id = dashboard_schema_state.org_id AND dashboard.uid = dashboard_schema_state.dashboard_uid
+		)`)
+		return err
+	})
+}
+
+// listDashboards returns the next batch of dashboards ordered by ID, of an organization or of every organization
+// if orgID is 0.
+func (s *Service) listDashboards(ctx context.Context, orgID int64, afterID int64, limit int) ([]*dashboards.Dashboard, error) {
+	result := make([]*dashboards.Dashboard, 0, limit)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("dashboard").Cols("id", "org_id", "uid", "title", "folder_uid", "data").
+			Where("id > ? AND is_folder = ? AND deleted IS NULL", afterID, s.store.GetDialect().BooleanStr(false))
+		if orgID != 0 {
+			sess.And("org_id = ?", orgID)
+		}
+		return sess.Asc("id").Limit(limit).Find(&result)
+	})
+	return result, err
+}
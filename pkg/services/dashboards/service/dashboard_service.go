@@ -41,8 +41,6 @@ var (
 	_ dashboards.DashboardService             = (*DashboardServiceImpl)(nil)
 	_ dashboards.DashboardProvisioningService = (*DashboardServiceImpl)(nil)
 	_ dashboards.PluginService                = (*DashboardServiceImpl)(nil)
-
-	daysInTrash = 24 * 30 * time.Hour
 )
 
 type DashboardServiceImpl struct {
@@ -358,11 +356,39 @@ func (dr *DashboardServiceImpl) GetSoftDeletedDashboard(ctx context.Context, org
 	return dr.dashboardStore.GetSoftDeletedDashboard(ctx, orgID, uid)
 }
 
+// GetSoftDeletedDashboards returns the dashboards and folders in the trash of an organization, the most recently
+// deleted first.
+func (dr *DashboardServiceImpl) GetSoftDeletedDashboards(ctx context.Context, orgID int64) ([]*dashboards.Dashboard, error) {
+	return dr.dashboardStore.GetSoftDeletedDashboards(ctx, &dashboards.GetSoftDeletedDashboardsQuery{OrgID: orgID})
+}
+
+func (dr *DashboardServiceImpl) GetDashboardSizes(ctx context.Context, query *dashboards.GetDashboardSizesQuery) ([]*dashboards.DashboardSize, error) {
+	return dr.dashboardStore.GetDashboardSizes(ctx, query)
+}
+
+func (dr *DashboardServiceImpl) GetDashboardSizeLimit(ctx context.Context, orgID int64) (int64, error) {
+	return dr.dashboardStore.GetDashboardSizeLimit(ctx, orgID)
+}
+
+func (dr *DashboardServiceImpl) SetDashboardSizeLimit(ctx context.Context, orgID int64, limit *int64) error {
+	return dr.dashboardStore.SetDashboardSizeLimit(ctx, orgID, limit)
+}
+
 func (dr *DashboardServiceImpl) RestoreDashboard(ctx context.Context, dashboard *dashboards.Dashboard, user identity.Requester, optionalFolderUID string) error {
 	if !dr.features.IsEnabledGlobally(featuremgmt.FlagDashboardRestore) {
 		return fmt.Errorf("feature flag %s is not enabled", featuremgmt.FlagDashboardRestore)
 	}
 
+	// the folders are restored by the folder service, which restores their folder tree too
+	if dashboard.IsFolder {
+		cmd := &folder.RestoreFolderCommand{UID: dashboard.UID, OrgID: dashboard.OrgID, SignedInUser: user}
+		if optionalFolderUID != "" {
+			cmd.ParentUID = &optionalFolderUID
+		}
+		_, err := dr.folderService.Restore(ctx, cmd)
+		return err
+	}
+
 	// if the optionalFolder is provided we need to check if the folder exists and user has access to it
 	if optionalFolderUID != "" {
 		restoringFolder, err := dr.folderService.Get(ctx, &folder.GetFolderQuery{
@@ -670,7 +696,7 @@ func (dr *DashboardServiceImpl) SearchDashboards(ctx context.Context, query *das
 		return nil, err
 	}
 
-	hits := makeQueryResult(query, res)
+	hits := makeQueryResult(query, res, dr.trashRetention())
 
 	return hits, nil
 }
@@ -690,7 +716,7 @@ func getHitType(item dashboards.DashboardSearchProjection) model.HitType {
 	return hitType
 }
 
-func makeQueryResult(query *dashboards.FindPersistedDashboardsQuery, res []dashboards.DashboardSearchProjection) model.HitList {
+func makeQueryResult(query *dashboards.FindPersistedDashboardsQuery, res []dashboards.DashboardSearchProjection, trashRetention time.Duration) model.HitList {
 	hitList := make([]*model.Hit, 0)
 	hits := make(map[int64]*model.Hit)
 
@@ -728,7 +754,7 @@ func makeQueryResult(query *dashboards.FindPersistedDashboardsQuery, res []dashb
 			hit.Tags = append(hit.Tags, item.Term)
 		}
 		if item.Deleted != nil {
-			deletedDate := (*item.Deleted).Add(daysInTrash)
+			deletedDate := (*item.Deleted).Add(trashRetention)
 			hit.IsDeleted = true
 			hit.PermanentlyDeleteDate = &deletedDate
 		}
@@ -754,9 +780,19 @@ func (dr *DashboardServiceImpl) DeleteInFolders(ctx context.Context, orgID int64
 
 func (dr *DashboardServiceImpl) Kind() string { return entity.StandardKindDashboard }
 
+// trashRetention returns how long the deleted dashboards and folders are kept in the trash.
+func (dr *DashboardServiceImpl) trashRetention() time.Duration {
+	if dr.cfg == nil || dr.cfg.DashboardTrash.Retention <= 0 {
+		return setting.DefaultDashboardTrashRetention
+	}
+	return dr.cfg.DashboardTrash.Retention
+}
+
+// CleanUpDeletedDashboards purges the dashboards and folders that have been in the trash for longer than the
+// retention of the trash.
 func (dr *DashboardServiceImpl) CleanUpDeletedDashboards(ctx context.Context) (int64, error) {
 	var deletedDashboardsCount int64
-	deletedDashboards, err := dr.dashboardStore.GetSoftDeletedExpiredDashboards(ctx, daysInTrash)
+	deletedDashboards, err := dr.dashboardStore.GetSoftDeletedExpiredDashboards(ctx, dr.trashRetention())
 	if err != nil {
 		return 0, err
 	}
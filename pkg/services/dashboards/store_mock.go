@@ -220,6 +220,64 @@ func (_m *FakeDashboardStore) GetDashboard(ctx context.Context, query *GetDashbo
 	return r0, r1
 }
 
+// GetDashboardSizeLimit provides a mock function with given fields: ctx, orgID
+func (_m *FakeDashboardStore) GetDashboardSizeLimit(ctx context.Context, orgID int64) (int64, error) {
+	ret := _m.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDashboardSizeLimit")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (int64, error)); ok {
+		return rf(ctx, orgID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDashboardSizes provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardStore) GetDashboardSizes(ctx context.Context, query *GetDashboardSizesQuery) ([]*DashboardSize, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDashboardSizes")
+	}
+
+	var r0 []*DashboardSize
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardSizesQuery) ([]*DashboardSize, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardSizesQuery) []*DashboardSize); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DashboardSize)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetDashboardSizesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDashboardTags provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardStore) GetDashboardTags(ctx context.Context, query *GetDashboardTagsQuery) ([]*DashboardTagCloudItem, error) {
 	ret := _m.Called(ctx, query)
@@ -460,6 +518,36 @@ func (_m *FakeDashboardStore) GetSoftDeletedDashboard(ctx context.Context, orgID
 	return r0, r1
 }
 
+// GetSoftDeletedDashboards provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardStore) GetSoftDeletedDashboards(ctx context.Context, query *GetSoftDeletedDashboardsQuery) ([]*Dashboard, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSoftDeletedDashboards")
+	}
+
+	var r0 []*Dashboard
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetSoftDeletedDashboardsQuery) ([]*Dashboard, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetSoftDeletedDashboardsQuery) []*Dashboard); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Dashboard)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetSoftDeletedDashboardsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSoftDeletedExpiredDashboards provides a mock function with given fields: ctx, duration
 func (_m *FakeDashboardStore) GetSoftDeletedExpiredDashboards(ctx context.Context, duration time.Duration) ([]*Dashboard, error) {
 	ret := _m.Called(ctx, duration)
@@ -568,6 +656,24 @@ func (_m *FakeDashboardStore) SaveProvisionedDashboard(ctx context.Context, cmd
 	return r0, r1
 }
 
+// SetDashboardSizeLimit provides a mock function with given fields: ctx, orgID, limit
+func (_m *FakeDashboardStore) SetDashboardSizeLimit(ctx context.Context, orgID int64, limit *int64) error {
+	ret := _m.Called(ctx, orgID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDashboardSizeLimit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) error); ok {
+		r0 = rf(ctx, orgID, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SoftDeleteDashboard provides a mock function with given fields: ctx, orgID, dashboardUid
 func (_m *FakeDashboardStore) SoftDeleteDashboard(ctx context.Context, orgID int64, dashboardUid string) error {
 	ret := _m.Called(ctx, orgID, dashboardUid)
@@ -604,6 +710,24 @@ func (_m *FakeDashboardStore) SoftDeleteDashboardsInFolders(ctx context.Context,
 	return r0
 }
 
+// SoftDeleteFolders provides a mock function with given fields: ctx, orgID, folders, unrestorable
+func (_m *FakeDashboardStore) SoftDeleteFolders(ctx context.Context, orgID int64, folders []*folder.Folder, unrestorable string) error {
+	ret := _m.Called(ctx, orgID, folders, unrestorable)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SoftDeleteFolders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []*folder.Folder, string) error); ok {
+		r0 = rf(ctx, orgID, folders, unrestorable)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UnprovisionDashboard provides a mock function with given fields: ctx, id
 func (_m *FakeDashboardStore) UnprovisionDashboard(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	routeRegister.Post("/api/dashboards/uid/:uid/snapshots/render", middleware.ReqSignedIn, authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, uidScope)), s.renderHandler)
+}
+
+// renderHandler responds with the rendered snapshot of a dashboard, or creates the snapshot and responds like the
+// create snapshot API if the command saves it.
+func (s *Service) renderHandler(c *contextmodel.ReqContext) {
+	cmd := RenderCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		c.JsonApiErr(http.StatusBadRequest, "bad request data", err)
+		return
+	}
+	uid := web.Params(c.Req)[":uid"]
+
+	if cmd.Save {
+		if !s.cfg.SnapshotEnabled {
+			c.JsonApiErr(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+			return
+		}
+		// Do not check permissions when the instance snapshot public mode is enabled, like the create snapshot API
+		if !s.cfg.SnapshotPublicMode {
+			evaluator := ac.EvalPermission(dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
+			if canSave, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil || !canSave {
+				c.JsonApiErr(http.StatusForbidden, "forbidden", err)
+				return
+			}
+		}
+	}
+
+	result, err := s.Render(c.Req.Context(), c.SignedInUser, uid, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, dashboards.ErrDashboardNotFound):
+			c.JsonApiErr(http.StatusNotFound, "Dashboard not found", err)
+		case errors.Is(err, ErrInvalidTimeRange), errors.Is(err, ErrInvalidVariables), errors.Is(err, ErrPanelNotFound):
+			c.JsonApiErr(http.StatusBadRequest, err.Error(), err)
+		default:
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to render snapshot", err)
+		}
+		return
+	}
+	if !cmd.Save {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		s.log.Warn("Saving snapshot with failed panel queries", "dashboardUid", uid, "errors", len(result.Errors))
+	}
+	name := cmd.Name
+	if name == "" {
+		name = result.Dashboard.Get("title").MustString()
+	}
+	dashboardsnapshots.CreateDashboardSnapshot(c, dashboardsnapshot.SnapshotSharingOptions{
+		SnapshotsEnabled: s.cfg.SnapshotEnabled,
+	}, dashboardsnapshots.CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Name:      name,
+			Dashboard: &common.Unstructured{Object: result.Dashboard.MustMap()},
+			Expires:   cmd.Expires,
+		},
+	}, s.snapshotService)
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	ErrInvalidVariables = errors.New("invalid variables")
+	ErrPanelNotFound    = errors.New("panel not found")
+)
+
+// RenderCommand renders a snapshot of a dashboard.
+type RenderCommand struct {
+	// From and To are the time range of the snapshot, epoch milliseconds or relative such as now-6h. The time range
+	// of the dashboard is used if they are empty.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Variables are the values of the dashboard variables by name, the current values of the dashboard are used
+	// for the others.
+	Variables map[string][]string `json:"variables"`
+	// PanelIDs limits the snapshot to some panels of the dashboard.
+	PanelIDs []int64 `json:"panelIds"`
+
+	// Save creates a snapshot of the rendered dashboard, named Name and expiring after Expires seconds.
+	Save    bool   `json:"save"`
+	Name    string `json:"name"`
+	Expires int64  `json:"expires"`
+}
+
+// RenderResult is a snapshot of a dashboard, with the data of its panels embedded.
+type RenderResult struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	// Errors are the errors of the panel queries. The panels keep the data of the queries that succeeded.
+	Errors []PanelError `json:"errors,omitempty"`
+}
+
+// PanelError is the error of a query of a panel, or of all its queries if RefID is empty.
+type PanelError struct {
+	PanelID int64  `json:"panelId"`
+	RefID   string `json:"refId,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,371 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+const (
+	// defaultMaxDataPoints is the resolution of the panels without max data points, the frontend using the width
+	// of the panels instead.
+	defaultMaxDataPoints = 1000
+	// maxConcurrentPanels is the number of panels whose queries are executed at the same time.
+	maxConcurrentPanels = 4
+)
+
+// Service renders snapshots of dashboards without a browser, executing the queries of their panels and embedding
+// the resulting frames like the snapshots created by the frontend.
+type Service struct {
+	cfg                *setting.Cfg
+	dashboardService   dashboards.DashboardService
+	dataSourceService  datasources.DataSourceService
+	queryService       query.Service
+	snapshotService    dashboardsnapshots.Service
+	accessControl      ac.AccessControl
+	intervalCalculator intervalv2.Calculator
+	log                log.Logger
+	now                func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	routeRegister routing.RouteRegister,
+	dashboardService dashboards.DashboardService,
+	dataSourceService datasources.DataSourceService,
+	queryService query.Service,
+	snapshotService dashboardsnapshots.Service,
+	accessControl ac.AccessControl,
+) *Service {
+	s := &Service{
+		cfg:                cfg,
+		dashboardService:   dashboardService,
+		dataSourceService:  dataSourceService,
+		queryService:       queryService,
+		snapshotService:    snapshotService,
+		accessControl:      accessControl,
+		intervalCalculator: intervalv2.NewCalculator(),
+		log:                log.New("dashboardsnapshots.render"),
+		now:                time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Render executes the queries of the panels of a dashboard as the user, and returns the dashboard with the
+// resulting frames embedded. The queries of the data sources the user can't query fail like any other query.
+func (s *Service) Render(ctx context.Context, user identity.Requester, dashboardUID string, cmd RenderCommand) (*RenderResult, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	doc := dash.Data.DeepCopy()
+
+	from, to, err := s.timeRange(doc, cmd)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := resolveVariables(doc, cmd.Variables)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd.PanelIDs) > 0 {
+		if err := selectPanels(doc, cmd.PanelIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		errs    []PanelError
+		g, gCtx = errgroup.WithContext(ctx)
+	)
+	g.SetLimit(maxConcurrentPanels)
+	for _, panel := range flattenPanels(doc) {
+		g.Go(func() error {
+			panelErrs := s.renderPanel(gCtx, user, panel, from, to, variables)
+			mu.Lock()
+			errs = append(errs, panelErrs...)
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	trimDashboard(doc, from, to, cmd.Variables)
+	return &RenderResult{Dashboard: doc, Errors: errs}, nil
+}
+
+// timeRange returns the time range of a snapshot, in the time zone of the dashboard for the relative time ranges.
+func (s *Service) timeRange(doc *simplejson.Json, cmd RenderCommand) (time.Time, time.Time, error) {
+	from, to := cmd.From, cmd.To
+	if from == "" || to == "" {
+		from, to = doc.GetPath("time", "from").MustString("now-6h"), doc.GetPath("time", "to").MustString("now")
+	}
+	location, err := time.LoadLocation(doc.Get("timezone").MustString())
+	if err != nil {
+		location = time.UTC
+	}
+
+	tr := gtime.TimeRange{From: from, To: to, Now: s.now()}
+	fromTime, err := tr.ParseFrom(gtime.WithLocation(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTimeRange, err)
+	}
+	toTime, err := tr.ParseTo(gtime.WithLocation(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTimeRange, err)
+	}
+	if !toTime.After(fromTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the end is not after the start", ErrInvalidTimeRange)
+	}
+	return fromTime, toTime, nil
+}
+
+// renderPanel executes the queries of a panel and replaces them with a snapshot query of the Grafana data source
+// embedding the resulting frames.
+func (s *Service) renderPanel(ctx context.Context, user identity.Requester, panel *simplejson.Json, from, to time.Time, variables map[string]variable) []PanelError {
+	panelID := panel.Get("id").MustInt64()
+	targets := panel.Get("targets").MustArray()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+	minInterval, _ := gtime.ParseIntervalStringToTimeDuration(interpolateString(panel.Get("interval").MustString(), variables))
+	interval := s.intervalCalculator.Calculate(backend.TimeRange{From: from, To: to}, minInterval, maxDataPoints)
+
+	queryVariables := builtInVariables(from.UnixMilli(), to.UnixMilli(), interval.Milliseconds(), interval.Text)
+	for name, v := range variables {
+		queryVariables[name] = v
+	}
+
+	var errs []PanelError
+	frames := make([]any, 0)
+	queries, err := s.panelQueries(ctx, user, panel, targets, queryVariables)
+	if err != nil {
+		errs = append(errs, PanelError{PanelID: panelID, Message: err.Error()})
+	}
+	if len(queries) > 0 {
+		for _, q := range queries {
+			q.Set("intervalMs", interval.Milliseconds())
+			q.Set("maxDataPoints", maxDataPoints)
+		}
+		res, err := s.queryService.QueryData(ctx, user, false, dtos.MetricRequest{
+			From:    strconv.FormatInt(from.UnixMilli(), 10),
+			To:      strconv.FormatInt(to.UnixMilli(), 10),
+			Queries: queries,
+		})
+		if err != nil {
+			s.log.Debug("Failed to query panel", "panelId", panelID, "error", err)
+			errs = append(errs, PanelError{PanelID: panelID, Message: err.Error()})
+			res = &backend.QueryDataResponse{}
+		}
+		for _, q := range queries {
+			refID := q.Get("refId").MustString()
+			resp, ok := res.Responses[refID]
+			if !ok {
+				continue
+			}
+			if resp.Error != nil {
+				errs = append(errs, PanelError{PanelID: panelID, RefID: refID, Message: resp.Error.Error()})
+			}
+			embedded, err := embedFrames(resp)
+			if err != nil {
+				errs = append(errs, PanelError{PanelID: panelID, RefID: refID, Message: err.Error()})
+				continue
+			}
+			frames = append(frames, embedded...)
+		}
+	}
+
+	// the panels keep no live query, even when theirs failed
+	grafanaDatasource := map[string]any{"type": "grafana", "uid": grafanads.DatasourceUID}
+	panel.Set("datasource", grafanaDatasource)
+	panel.Set("targets", []any{map[string]any{
+		"refId":      "A",
+		"datasource": grafanaDatasource,
+		"queryType":  "snapshot",
+		"snapshot":   frames,
+	}})
+	return errs
+}
+
+// panelQueries returns the queries of the targets of a panel with their variables replaced, the targets without
+// data source using the data source of the panel.
+func (s *Service) panelQueries(ctx context.Context, user identity.Requester, panel *simplejson.Json, targets []any, variables map[string]variable) ([]*simplejson.Json, error) {
+	panelDatasource := panel.Get("datasource").Interface()
+	if panelDatasource == nil {
+		ds, err := s.defaultDatasource(ctx, user.GetOrgID())
+		if err != nil {
+			return nil, err
+		}
+		panelDatasource = ds
+	}
+
+	hasExpression := false
+	for _, target := range targets {
+		if expr.IsDataSource(simplejson.NewFromAny(target).GetPath("datasource", "uid").MustString()) {
+			hasExpression = true
+		}
+	}
+
+	queries := make([]*simplejson.Json, 0, len(targets))
+	for _, target := range targets {
+		q := simplejson.NewFromAny(target).DeepCopy()
+		// the expressions remove the hidden queries they depend on from their results
+		if !hasExpression && q.Get("hide").MustBool() {
+			continue
+		}
+		if _, ok := q.CheckGet("datasource"); !ok {
+			q.Set("datasource", panelDatasource)
+		}
+		queries = append(queries, simplejson.NewFromAny(interpolate(q.Interface(), variables)))
+	}
+	return queries, nil
+}
+
+// embedFrames returns the frames of a response as JSON values, so that the snapshot is plain JSON.
+func embedFrames(resp backend.DataResponse) ([]any, error) {
+	b, err := json.Marshal(resp.Frames)
+	if err != nil {
+		return nil, err
+	}
+	var frames []any
+	if err := json.Unmarshal(b, &frames); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+func (s *Service) defaultDatasource(ctx context.Context, orgID int64) (map[string]any, error) {
+	dataSources, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range dataSources {
+		if ds.IsDefault {
+			return map[string]any{"type": ds.Type, "uid": ds.UID}, nil
+		}
+	}
+	return nil, datasources.ErrDataSourceNotFound
+}
+
+// flattenPanels returns the panels of a dashboard, including the panels of the collapsed rows.
+func flattenPanels(doc *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	for _, obj := range doc.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(obj)
+		if panel.Get("type").MustString() == "row" {
+			for _, nested := range panel.Get("panels").MustArray() {
+				panels = append(panels, simplejson.NewFromAny(nested))
+			}
+			continue
+		}
+		panels = append(panels, panel)
+	}
+	return panels
+}
+
+// selectPanels removes the panels of a dashboard but some, and the rows.
+func selectPanels(doc *simplejson.Json, panelIDs []int64) error {
+	byID := make(map[int64]any)
+	for _, panel := range flattenPanels(doc) {
+		byID[panel.Get("id").MustInt64()] = panel.Interface()
+	}
+	selected := make([]any, 0, len(panelIDs))
+	for _, id := range panelIDs {
+		panel, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrPanelNotFound, id)
+		}
+		selected = append(selected, panel)
+	}
+	// a single panel takes the whole width of the dashboard, like the snapshots of a panel in the frontend
+	if len(selected) == 1 {
+		simplejson.NewFromAny(selected[0]).Set("gridPos", map[string]any{"x": 0, "y": 0, "w": 24, "h": 20})
+	}
+	doc.Set("panels", selected)
+	return nil
+}
+
+// trimDashboard removes what a snapshot doesn't need from a dashboard, like the frontend does: the links, the
+// annotation queries and the variable queries, the variables keeping the values the snapshot was rendered with.
+func trimDashboard(doc *simplejson.Json, from, to time.Time, requested map[string][]string) {
+	doc.Set("time", map[string]any{
+		"from": from.UTC().Format(time.RFC3339Nano),
+		"to":   to.UTC().Format(time.RFC3339Nano),
+	})
+	doc.Set("links", []any{})
+	for _, panel := range flattenPanels(doc) {
+		if _, ok := panel.CheckGet("links"); ok {
+			panel.Set("links", []any{})
+		}
+	}
+
+	annotations := make([]any, 0)
+	for _, obj := range doc.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(obj)
+		if !annotation.Get("enable").MustBool() {
+			continue
+		}
+		annotations = append(annotations, map[string]any{
+			"name":         annotation.Get("name").Interface(),
+			"enable":       true,
+			"iconColor":    annotation.Get("iconColor").Interface(),
+			"type":         annotation.Get("type").Interface(),
+			"builtIn":      annotation.Get("builtIn").Interface(),
+			"hide":         annotation.Get("hide").Interface(),
+			"snapshotData": []any{},
+		})
+	}
+	doc.SetPath([]string{"annotations", "list"}, annotations)
+
+	for _, obj := range doc.GetPath("templating", "list").MustArray() {
+		v := simplejson.NewFromAny(obj)
+		if _, ok := v.CheckGet("query"); ok {
+			v.Set("query", "")
+		}
+		if _, ok := v.CheckGet("refresh"); ok {
+			v.Set("refresh", 0)
+		}
+		if values, ok := requested[v.Get("name").MustString()]; ok {
+			current := map[string]any{"text": strings.Join(values, " + "), "value": values}
+			if len(values) == 1 && !v.Get("multi").MustBool() {
+				current["value"] = values[0]
+			}
+			v.Set("current", current)
+		}
+		if current, ok := v.CheckGet("current"); ok && len(current.MustMap()) > 0 {
+			v.Set("options", []any{current.Interface()})
+		} else {
+			v.Set("options", []any{})
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string]variable{
+		"host":   {values: []string{"a.1", "b"}, multi: true},
+		"single": {values: []string{"it's"}},
+		"all":    {values: []string{".*"}, raw: true},
+	}
+	tests := map[string]string{
+		"$host":                 "{a.1,b}",
+		"[[host]]":              "{a.1,b}",
+		"${host:regex}":         `(a\.1|b)`,
+		"${host:pipe}":          "a.1|b",
+		"${host:csv}":           "a.1,b",
+		"${host:singlequote}":   `'a.1','b'`,
+		"${host:json}":          `["a.1","b"]`,
+		"${single:sqlstring}":   `'it''s'`,
+		"${single:json}":        `"it's"`,
+		"${all:regex}":          ".*",
+		"$unknown and ${other}": "$unknown and ${other}",
+		"host=$single":          "host=it's",
+	}
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			require.Equal(t, expected, interpolateString(s, variables))
+		})
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	dashboard := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{"list": []any{
+			map[string]any{"name": "host", "type": "custom", "multi": true, "current": map[string]any{"value": []any{"a", "b"}}},
+			map[string]any{"name": "env", "type": "custom", "current": map[string]any{"value": "$__all"}, "allValue": ".*"},
+			map[string]any{"name": "region", "type": "query", "current": map[string]any{"value": "$__all"}},
+			map[string]any{"name": "limit", "type": "constant", "query": "10"},
+		}},
+	})
+
+	t.Run("uses the current values and the values of the request", func(t *testing.T) {
+		variables, err := resolveVariables(dashboard, map[string][]string{"host": {"c"}, "region": {"eu"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"c"}, variables["host"].values)
+		require.Equal(t, variable{values: []string{".*"}, raw: true}, variables["env"])
+		require.Equal(t, []string{"eu"}, variables["region"].values)
+		require.Equal(t, []string{"10"}, variables["limit"].values)
+	})
+
+	t.Run("fails on unknown variables and unresolvable All values", func(t *testing.T) {
+		_, err := resolveVariables(dashboard, map[string][]string{"region": {"eu"}, "unknown": {"a"}})
+		require.ErrorIs(t, err, ErrInvalidVariables)
+		_, err = resolveVariables(dashboard, nil)
+		require.ErrorIs(t, err, ErrInvalidVariables)
+	})
+}
+
+func TestRender(t *testing.T) {
+	dashboard := &dashboards.Dashboard{UID: "dash", OrgID: 1, Data: simplejson.MustJson([]byte(`{
+		"uid": "dash",
+		"title": "Dash",
+		"time": {"from": "now-1h", "to": "now"},
+		"links": [{"title": "docs"}],
+		"templating": {"list": [
+			{"name": "host", "type": "query", "multi": true, "query": "label_values(host)", "refresh": 1, "current": {"text": "a + b", "value": ["a", "b"]}}
+		]},
+		"annotations": {"list": [
+			{"name": "Annotations", "enable": true, "builtIn": 1, "type": "dashboard", "datasource": {"type": "grafana", "uid": "-- Grafana --"}},
+			{"name": "Deploys", "enable": false, "datasource": {"type": "prometheus", "uid": "prom"}, "expr": "deploys"}
+		]},
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}, "targets": [
+				{"refId": "A", "expr": "up{host=~\"${host:regex}\"}"},
+				{"refId": "B", "expr": "hidden", "hide": true}
+			]},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "type": "stat", "datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"refId": "A", "expr": "fail"}]}
+			]},
+			{"id": 4, "type": "text"}
+		]
+	}`))}
+
+	newTestService := func(t *testing.T) (*Service, *sync.Map) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(dashboard, nil)
+
+		queries := &sync.Map{}
+		queryService := &query.FakeQueryService{}
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(
+			func(_ context.Context, _ identity.Requester, _ bool, req dtos.MetricRequest) *backend.QueryDataResponse {
+				expr := req.Queries[0].Get("expr").MustString()
+				queries.Store(expr, req.Queries)
+				if expr == "fail" {
+					return &backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: errors.New("bad query")}}}
+				}
+				return &backend.QueryDataResponse{Responses: backend.Responses{
+					"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1}))}},
+				}}
+			}, nil)
+
+		now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		return &Service{
+			dashboardService:   dashboardService,
+			queryService:       queryService,
+			intervalCalculator: intervalv2.NewCalculator(),
+			log:                log.NewNopLogger(),
+			now:                func() time.Time { return now },
+		}, queries
+	}
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	t.Run("embeds the frames of the panel queries", func(t *testing.T) {
+		s, queries := newTestService(t)
+		result, err := s.Render(context.Background(), signedInUser, "dash", RenderCommand{})
+		require.NoError(t, err)
+
+		sent, ok := queries.Load(`up{host=~"(a|b)"}`)
+		require.True(t, ok, "the variables of the queries are replaced")
+		require.Len(t, sent, 1, "the hidden queries are not executed")
+		require.Positive(t, sent.([]*simplejson.Json)[0].Get("intervalMs").MustInt64())
+
+		doc := result.Dashboard
+		require.Equal(t, "2024-06-01T11:00:00Z", doc.GetPath("time", "from").MustString())
+		require.Equal(t, "2024-06-01T12:00:00Z", doc.GetPath("time", "to").MustString())
+		require.Empty(t, doc.Get("links").MustArray())
+		require.Len(t, doc.GetPath("annotations", "list").MustArray(), 1, "the disabled annotations are removed")
+		require.Equal(t, "", doc.GetPath("templating", "list").GetIndex(0).Get("query").MustString())
+
+		target := doc.Get("panels").GetIndex(0).Get("targets").GetIndex(0)
+		require.Equal(t, "snapshot", target.Get("queryType").MustString())
+		require.Equal(t, "grafana", target.GetPath("datasource", "uid").MustString())
+		require.Len(t, target.Get("snapshot").MustArray(), 1)
+
+		failed := doc.Get("panels").GetIndex(1).Get("panels").GetIndex(0).Get("targets").GetIndex(0)
+		require.Empty(t, failed.Get("snapshot").MustArray(), "the panels of the collapsed rows are rendered")
+		require.Equal(t, []PanelError{{PanelID: 3, RefID: "A", Message: "bad query"}}, result.Errors)
+	})
+
+	t.Run("uses the time range, variables and panels of the command", func(t *testing.T) {
+		s, queries := newTestService(t)
+		result, err := s.Render(context.Background(), signedInUser, "dash", RenderCommand{
+			From:      "now-2d",
+			To:        "now-1d",
+			Variables: map[string][]string{"host": {"c"}},
+			PanelIDs:  []int64{1},
+		})
+		require.NoError(t, err)
+
+		_, ok := queries.Load(`up{host=~"c"}`)
+		require.True(t, ok)
+		_, ok = queries.Load("fail")
+		require.False(t, ok, "the other panels are not rendered")
+		require.Len(t, result.Dashboard.Get("panels").MustArray(), 1)
+		require.Equal(t, "2024-05-30T12:00:00Z", result.Dashboard.GetPath("time", "from").MustString())
+		require.Equal(t, []string{"c"}, result.Dashboard.GetPath("templating", "list").GetIndex(0).GetPath("current", "value").Interface())
+	})
+
+	t.Run("rejects invalid commands", func(t *testing.T) {
+		s, _ := newTestService(t)
+		_, err := s.Render(context.Background(), signedInUser, "dash", RenderCommand{From: "now", To: "now-1h"})
+		require.ErrorIs(t, err, ErrInvalidTimeRange)
+		_, err = s.Render(context.Background(), signedInUser, "dash", RenderCommand{PanelIDs: []int64{42}})
+		require.ErrorIs(t, err, ErrPanelNotFound)
+	})
+}
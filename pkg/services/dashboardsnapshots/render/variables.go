@@ -0,0 +1,199 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// variableRegex matches the variable syntaxes of the frontend: $var, [[var:format]] and ${var.fieldPath:format}.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+const allValue = "$__all"
+
+// variable is the value of a dashboard variable, or of a built-in variable such as $__from.
+type variable struct {
+	values []string
+	// multi is true for the variables that can have several values, which the json and percentencode formats
+	// format as a list even when only one is selected.
+	multi bool
+	// raw is true for custom all values, which are never formatted.
+	raw bool
+}
+
+// resolveVariables returns the values of the variables of a dashboard, the values of the request taking
+// precedence over the current values saved with the dashboard.
+func resolveVariables(dashboard *simplejson.Json, values map[string][]string) (map[string]variable, error) {
+	variables := make(map[string]variable)
+	for _, obj := range dashboard.GetPath("templating", "list").MustArray() {
+		v := simplejson.NewFromAny(obj)
+		name := v.Get("name").MustString()
+		varType := v.Get("type").MustString()
+		if name == "" || varType == "adhoc" || varType == "groupby" {
+			continue
+		}
+
+		current, ok := values[name]
+		if !ok {
+			current = currentValues(v)
+		}
+		resolved := variable{values: current, multi: v.Get("multi").MustBool()}
+
+		if len(current) == 1 && current[0] == allValue {
+			if custom := v.Get("allValue").MustString(); custom != "" {
+				resolved = variable{values: []string{custom}, raw: true}
+			} else {
+				resolved = variable{values: optionValues(v), multi: true}
+				if len(resolved.values) == 0 {
+					return nil, fmt.Errorf("%w: the value of variable %q is All, which requires its values", ErrInvalidVariables, name)
+				}
+			}
+		}
+		variables[name] = resolved
+	}
+
+	for name := range values {
+		if _, ok := variables[name]; !ok {
+			return nil, fmt.Errorf("%w: the dashboard has no variable %q", ErrInvalidVariables, name)
+		}
+	}
+	return variables, nil
+}
+
+// currentValues returns the values a variable is saved with, from its current value or its query for the
+// constant and textbox variables.
+func currentValues(v *simplejson.Json) []string {
+	if value, ok := v.Get("current").CheckGet("value"); ok {
+		if values, err := value.StringArray(); err == nil {
+			return values
+		}
+		if s, err := value.String(); err == nil {
+			return []string{s}
+		}
+	}
+	switch v.Get("type").MustString() {
+	case "constant", "textbox":
+		return []string{v.Get("query").MustString()}
+	}
+	return nil
+}
+
+// optionValues returns the values of the options of a variable, except All.
+func optionValues(v *simplejson.Json) []string {
+	var values []string
+	for _, option := range v.Get("options").MustArray() {
+		value := simplejson.NewFromAny(option).Get("value").MustString()
+		if value != "" && value != allValue {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// interpolate replaces the variables of every string of a query, leaving the unknown variables as they are.
+func interpolate(value any, variables map[string]variable) any {
+	switch v := value.(type) {
+	case string:
+		return interpolateString(v, variables)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = interpolate(item, variables)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = interpolate(item, variables)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func interpolateString(s string, variables map[string]variable) string {
+	if !strings.ContainsAny(s, "$[") {
+		return s
+	}
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[6]
+		}
+		v, ok := variables[name]
+		if !ok {
+			return match
+		}
+		return formatValues(v, format)
+	})
+}
+
+// formatValues formats the values of a variable like the formats of the frontend, defaulting to the glob format.
+func formatValues(v variable, format string) string {
+	if v.raw || format == "raw" || format == "text" {
+		return strings.Join(v.values, ",")
+	}
+	single := len(v.values) == 1 && !v.multi
+	quote := func(quoteValue func(string) string) []string {
+		quoted := make([]string, len(v.values))
+		for i, value := range v.values {
+			quoted[i] = quoteValue(value)
+		}
+		return quoted
+	}
+
+	switch format {
+	case "csv":
+		return strings.Join(v.values, ",")
+	case "pipe":
+		return strings.Join(v.values, "|")
+	case "regex":
+		quoted := quote(regexp.QuoteMeta)
+		if len(quoted) == 1 {
+			return quoted[0]
+		}
+		return "(" + strings.Join(quoted, "|") + ")"
+	case "singlequote":
+		return strings.Join(quote(func(s string) string { return "'" + strings.ReplaceAll(s, "'", `\'`) + "'" }), ",")
+	case "doublequote":
+		return strings.Join(quote(func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"` }), ",")
+	case "sqlstring":
+		return strings.Join(quote(func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }), ",")
+	case "json":
+		var b []byte
+		if single {
+			b, _ = json.Marshal(v.values[0])
+		} else {
+			b, _ = json.Marshal(v.values)
+		}
+		return string(b)
+	case "percentencode":
+		if single {
+			return url.QueryEscape(v.values[0])
+		}
+		return url.QueryEscape("{" + strings.Join(v.values, ",") + "}")
+	default:
+		if len(v.values) == 1 {
+			return v.values[0]
+		}
+		return "{" + strings.Join(v.values, ",") + "}"
+	}
+}
+
+// builtInVariables returns the built-in variables of the queries of a panel.
+func builtInVariables(from, to, intervalMs int64, interval string) map[string]variable {
+	return map[string]variable{
+		"__from":        {values: []string{strconv.FormatInt(from, 10)}},
+		"__to":          {values: []string{strconv.FormatInt(to, 10)}},
+		"__interval":    {values: []string{interval}},
+		"__interval_ms": {values: []string{strconv.FormatInt(intervalMs, 10)}},
+	}
+}
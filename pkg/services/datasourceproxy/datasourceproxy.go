@@ -9,11 +9,14 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/datasource"
 	"github.com/grafana/grafana/pkg/api/pluginproxy"
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/labelaccess"
+	"github.com/grafana/grafana/pkg/services/datasources/ratelimit"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
@@ -27,7 +30,8 @@ import (
 func ProvideService(dataSourceCache datasources.CacheService, plugReqValidator validations.PluginRequestValidator,
 	pluginStore pluginstore.Store, cfg *setting.Cfg, httpClientProvider httpclient.Provider,
 	oauthTokenService *oauthtoken.Service, dsService datasources.DataSourceService,
-	tracer tracing.Tracer, secretsService secrets.Service, features featuremgmt.FeatureToggles) *DataSourceProxyService {
+	tracer tracing.Tracer, secretsService secrets.Service, features featuremgmt.FeatureToggles,
+	rateLimiter *ratelimit.Service) *DataSourceProxyService {
 	return &DataSourceProxyService{
 		DataSourceCache:        dataSourceCache,
 		PluginRequestValidator: plugReqValidator,
@@ -39,6 +43,7 @@ func ProvideService(dataSourceCache datasources.CacheService, plugReqValidator v
 		tracer:                 tracer,
 		secretsService:         secretsService,
 		features:               features,
+		rateLimiter:            rateLimiter,
 	}
 }
 
@@ -53,6 +58,7 @@ type DataSourceProxyService struct {
 	tracer                 tracing.Tracer
 	secretsService         secrets.Service
 	features               featuremgmt.FeatureToggles
+	rateLimiter            *ratelimit.Service
 }
 
 func (p *DataSourceProxyService) ProxyDataSourceRequest(c *contextmodel.ReqContext) {
@@ -114,6 +120,11 @@ func (p *DataSourceProxyService) proxyDatasourceRequest(c *contextmodel.ReqConte
 		return
 	}
 
+	if err := labelaccess.CheckProxy(ds.Type, ds.JsonData, c.SignedInUser); err != nil {
+		response.Err(err).WriteTo(c)
+		return
+	}
+
 	// find plugin
 	plugin, exists := p.pluginStore.Plugin(c.Req.Context(), ds.Type)
 	if !exists {
@@ -133,6 +144,15 @@ func (p *DataSourceProxyService) proxyDatasourceRequest(c *contextmodel.ReqConte
 		}
 		return
 	}
+
+	release, err := p.rateLimiter.Acquire(c.Req.Context(), ds.UID)
+	if err != nil {
+		c.Resp.Header().Set("Retry-After", strconv.Itoa(int(p.Cfg.DataSourceRateLimit.QueueTimeout.Seconds())))
+		response.Err(err).WriteTo(c)
+		return
+	}
+	defer release()
+
 	proxy.HandleRequest()
 }
 
@@ -19,4 +19,6 @@ var (
 	ErrDataSourceURLInvalid              = errutil.ValidationFailed("datasource.urlInvalid", errutil.WithPublicMessage("Invalid datasource url."))
 	ErrDataSourceAPIVersionInvalid       = errutil.ValidationFailed("datasource.apiVersionInvalid", errutil.WithPublicMessage("Invalid datasource apiVersion."))
 	ErrDataSourceUIDInvalid              = errutil.ValidationFailed("datasource.uidInvalid", errutil.WithPublicMessage("Invalid datasource UID."))
+	ErrDataSourceTransformationsInvalid  = errutil.ValidationFailed("datasource.transformationsInvalid", errutil.WithPublicMessage("Invalid datasource response transformations."))
+	ErrDataSourceLabelAccessRulesInvalid = errutil.ValidationFailed("datasource.labelAccessRulesInvalid", errutil.WithPublicMessage("Invalid datasource label access rules."))
 )
@@ -0,0 +1,36 @@
+package healthcheck
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+
+	routeRegister.Group("/api/datasources", func(subrouter routing.RouteRegister) {
+		subrouter.Get("/health", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.listStatusesHandler))
+	})
+}
+
+// listStatusesHandler returns the last health check results of the data sources the user can read.
+func (s *Service) listStatusesHandler(c *contextmodel.ReqContext) response.Response {
+	statuses := s.Statuses(c.SignedInUser.GetOrgID())
+
+	visible := make([]Status, 0, len(statuses))
+	for _, status := range statuses {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(status.UID)))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if ok {
+			visible = append(visible, status)
+		}
+	}
+	return response.JSON(http.StatusOK, visible)
+}
@@ -0,0 +1,256 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/httpclient/tunnel"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type dataSourceLister interface {
+	GetAllDataSources(ctx context.Context, query *datasources.GetAllDataSourcesQuery) ([]*datasources.DataSource, error)
+}
+
+type pluginContextProvider interface {
+	GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error)
+}
+
+type healthChecker interface {
+	CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error)
+}
+
+// Status is the result of the last health check of a data source.
+type Status struct {
+	OrgID     int64     `json:"orgId"`
+	UID       string    `json:"uid"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+	LatencyMs int64     `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt"`
+	// Tunnel is the status of the tunnel of the data source, if it has one
+	Tunnel *tunnel.Status `json:"tunnel,omitempty"`
+}
+
+// Healthy returns true if the health check of the data source succeeded.
+func (s Status) Healthy() bool {
+	return s.Status == backend.HealthStatusOk.String()
+}
+
+// Service periodically runs the health check of every data source and keeps the last result.
+type Service struct {
+	settings              setting.DataSourceHealthCheckSettings
+	dataSources           dataSourceLister
+	pluginContextProvider pluginContextProvider
+	pluginClient          healthChecker
+	bus                   bus.Bus
+	accessControl         ac.AccessControl
+	metrics               *metrics
+	log                   log.Logger
+	now                   func() time.Time
+
+	mu       sync.RWMutex
+	statuses map[statusKey]Status
+}
+
+type statusKey struct {
+	orgID int64
+	uid   string
+}
+
+func ProvideService(cfg *setting.Cfg, dataSourceService datasources.DataSourceService, pluginContextProvider *plugincontext.Provider,
+	pluginClient plugins.Client, bus bus.Bus, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	registerer prometheus.Registerer,
+) *Service {
+	s := &Service{
+		settings:              cfg.DataSourceHealthCheck,
+		dataSources:           dataSourceService,
+		pluginContextProvider: pluginContextProvider,
+		pluginClient:          pluginClient,
+		bus:                   bus,
+		accessControl:         accessControl,
+		metrics:               newMetrics(registerer),
+		log:                   log.New("datasources.healthcheck"),
+		now:                   time.Now,
+		statuses:              make(map[statusKey]Status),
+	}
+
+	if s.settings.Enabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled
+}
+
+// Run checks the health of every data source until ctx is done.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.checkAll(ctx); err != nil {
+			s.log.Error("Failed to check data source health", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Statuses returns the last health check results of the data sources of an organization, sorted by name.
+func (s *Service) Statuses(orgID int64) []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, 0)
+	for key, status := range s.statuses {
+		if key.orgID == orgID {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+func (s *Service) checkAll(ctx context.Context) error {
+	dss, err := s.dataSources.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		return fmt.Errorf("failed to list data sources: %w", err)
+	}
+	s.forgetDeleted(dss)
+
+	return concurrency.ForEachJob(ctx, len(dss), s.settings.Concurrency, func(ctx context.Context, idx int) error {
+		if status, ok := s.check(ctx, dss[idx]); ok {
+			s.record(ctx, status)
+		}
+		return nil
+	})
+}
+
+// check runs the health check of a data source. It returns false if the data source does not support health checks.
+func (s *Service) check(ctx context.Context, ds *datasources.DataSource) (Status, bool) {
+	user := ac.BackgroundUser("datasource_health_check", ds.OrgID, org.RoleAdmin, []ac.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(ds.UID)},
+	})
+	pCtx, err := s.pluginContextProvider.GetWithDataSource(ctx, ds.Type, user, ds)
+	if err != nil {
+		if !errors.Is(err, plugins.ErrPluginNotRegistered) {
+			s.log.Warn("Failed to get plugin context", "datasource", ds.UID, "error", err)
+		}
+		return Status{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.settings.Timeout)
+	defer cancel()
+
+	start := s.now()
+	resp, err := s.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{
+		PluginContext: pCtx,
+		Headers:       map[string]string{},
+	})
+	latency := s.now().Sub(start)
+	if errors.Is(err, plugins.ErrMethodNotImplemented) || errors.Is(err, plugins.ErrPluginNotRegistered) {
+		return Status{}, false
+	}
+
+	status := Status{
+		OrgID:     ds.OrgID,
+		UID:       ds.UID,
+		Name:      ds.Name,
+		Type:      ds.Type,
+		LatencyMs: latency.Milliseconds(),
+		CheckedAt: start,
+	}
+	switch {
+	case err != nil:
+		status.Status = backend.HealthStatusError.String()
+		status.Message = err.Error()
+	case resp == nil:
+		status.Status = backend.HealthStatusUnknown.String()
+	default:
+		status.Status = resp.Status.String()
+		status.Message = resp.Message
+	}
+	if ds.JsonData != nil && ds.JsonData.Get("enableTunnel").MustBool(false) {
+		if tunnelStatus, ok := tunnel.GetStatus(ds.UID); ok {
+			status.Tunnel = &tunnelStatus
+		}
+	}
+	s.metrics.duration.WithLabelValues(ds.Type).Observe(latency.Seconds())
+	return status, true
+}
+
+// record stores the result of a health check and emits an event if the data source became healthy or unhealthy.
+func (s *Service) record(ctx context.Context, status Status) {
+	key := statusKey{orgID: status.OrgID, uid: status.UID}
+	s.mu.Lock()
+	prev, known := s.statuses[key]
+	s.statuses[key] = status
+	s.mu.Unlock()
+
+	healthy := 0.0
+	if status.Healthy() {
+		healthy = 1
+	}
+	s.metrics.status.WithLabelValues(orgLabel(status.OrgID), status.UID, status.Type).Set(healthy)
+	s.metrics.checks.WithLabelValues(status.Type, status.Status).Inc()
+
+	if !known || prev.Healthy() == status.Healthy() {
+		return
+	}
+	s.log.Info("Data source health changed", "datasource", status.UID, "orgId", status.OrgID, "healthy", status.Healthy(), "message", status.Message)
+	if err := s.bus.Publish(ctx, &events.DataSourceHealthChanged{
+		Timestamp: status.CheckedAt,
+		Name:      status.Name,
+		UID:       status.UID,
+		OrgID:     status.OrgID,
+		Healthy:   status.Healthy(),
+		Message:   status.Message,
+	}); err != nil {
+		s.log.Error("Failed to publish data source health change", "datasource", status.UID, "error", err)
+	}
+}
+
+// forgetDeleted drops the statuses of data sources that no longer exist.
+func (s *Service) forgetDeleted(dss []*datasources.DataSource) {
+	existing := make(map[statusKey]struct{}, len(dss))
+	for _, ds := range dss {
+		existing[statusKey{orgID: ds.OrgID, uid: ds.UID}] = struct{}{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, status := range s.statuses {
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		delete(s.statuses, key)
+		s.metrics.status.DeleteLabelValues(orgLabel(key.orgID), key.uid, status.Type)
+	}
+}
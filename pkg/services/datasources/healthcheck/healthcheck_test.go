@@ -0,0 +1,106 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeDataSources []*datasources.DataSource
+
+func (f *fakeDataSources) GetAllDataSources(_ context.Context, _ *datasources.GetAllDataSourcesQuery) ([]*datasources.DataSource, error) {
+	return *f, nil
+}
+
+type fakePluginContextProvider struct{}
+
+func (fakePluginContextProvider) GetWithDataSource(_ context.Context, pluginID string, _ identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	if pluginID == "frontend-only" {
+		return backend.PluginContext{}, plugins.ErrPluginNotRegistered
+	}
+	return backend.PluginContext{
+		OrgID:                      ds.OrgID,
+		PluginID:                   pluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: ds.UID},
+	}, nil
+}
+
+type fakeHealthChecker map[string]error
+
+func (f fakeHealthChecker) CheckHealth(_ context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if err := f[req.PluginContext.DataSourceInstanceSettings.UID]; err != nil {
+		return nil, err
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "ok"}, nil
+}
+
+func TestService(t *testing.T) {
+	dss := &fakeDataSources{
+		{OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus"},
+		{OrgID: 1, UID: "loki", Name: "Loki", Type: "loki"},
+		{OrgID: 1, UID: "static", Name: "Static", Type: "frontend-only"},
+		{OrgID: 2, UID: "prom", Name: "Prometheus", Type: "prometheus"},
+	}
+	checker := fakeHealthChecker{}
+
+	eventBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	var changes []*events.DataSourceHealthChanged
+	eventBus.AddEventListener(func(_ context.Context, e *events.DataSourceHealthChanged) error {
+		changes = append(changes, e)
+		return nil
+	})
+
+	s := &Service{
+		settings:              setting.DataSourceHealthCheckSettings{Enabled: true, Timeout: time.Second, Concurrency: 1},
+		dataSources:           dss,
+		pluginContextProvider: fakePluginContextProvider{},
+		pluginClient:          checker,
+		bus:                   eventBus,
+		metrics:               newMetrics(prometheus.NewRegistry()),
+		log:                   log.NewNopLogger(),
+		now:                   time.Now,
+		statuses:              make(map[statusKey]Status),
+	}
+	ctx := context.Background()
+
+	require.NoError(t, s.checkAll(ctx))
+	statuses := s.Statuses(1)
+	require.Len(t, statuses, 2, "data sources without a backend are not checked")
+	require.Equal(t, "loki", statuses[0].UID)
+	require.True(t, statuses[0].Healthy())
+	require.Empty(t, changes, "the first check does not emit an event")
+
+	checker["loki"] = errors.New("connection refused")
+	require.NoError(t, s.checkAll(ctx))
+	statuses = s.Statuses(1)
+	require.False(t, statuses[0].Healthy())
+	require.Equal(t, "connection refused", statuses[0].Message)
+	require.Len(t, changes, 1)
+	require.Equal(t, "loki", changes[0].UID)
+	require.False(t, changes[0].Healthy)
+
+	require.NoError(t, s.checkAll(ctx))
+	require.Len(t, changes, 1, "no event while the data source stays unhealthy")
+
+	delete(checker, "loki")
+	*dss = (*dss)[1:]
+	require.NoError(t, s.checkAll(ctx))
+	require.Len(t, changes, 2)
+	require.True(t, changes[1].Healthy)
+	require.Len(t, s.Statuses(1), 1, "deleted data sources are forgotten")
+	require.Len(t, s.Statuses(2), 1)
+}
@@ -0,0 +1,42 @@
+package healthcheck
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	status   *prometheus.GaugeVec
+	checks   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		status: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_health_check",
+			Name:      "status",
+			Help:      "Result of the last health check of a data source, 1 if it is healthy and 0 otherwise.",
+		}, []string{"org_id", "datasource_uid", "datasource_type"}),
+		checks: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_health_check",
+			Name:      "checks_total",
+			Help:      "Number of data source health checks by status.",
+		}, []string{"datasource_type", "status"}),
+		duration: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_health_check",
+			Name:      "duration_seconds",
+			Help:      "Duration of data source health checks.",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"datasource_type"}),
+	}
+}
+
+func orgLabel(orgID int64) string {
+	return strconv.FormatInt(orgID, 10)
+}
@@ -0,0 +1,95 @@
+package labelaccess
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// InjectLogQL adds the matchers to every stream selector of a LogQL expression. The expression is not parsed:
+// the matchers are added to the braces outside string literals and ${...} template variables, which are the
+// stream selectors of LogQL.
+func InjectLogQL(expr string, matchers []*labels.Matcher) (string, error) {
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		parts = append(parts, m.String())
+	}
+	injected := strings.Join(parts, ", ")
+
+	var sb strings.Builder
+	selectorStart := -1
+	found := false
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '`':
+			end := stringEnd(expr, i)
+			if end < 0 {
+				return "", errors.New("unterminated string in LogQL expression")
+			}
+			sb.WriteString(expr[i : end+1])
+			i = end
+			continue
+		case c == '{' && i > 0 && expr[i-1] == '$':
+			end := strings.IndexByte(expr[i:], '}')
+			if end < 0 {
+				return "", errors.New("unterminated variable in LogQL expression")
+			}
+			sb.WriteString(expr[i : i+end+1])
+			i += end
+			continue
+		case c == '{':
+			if selectorStart >= 0 {
+				return "", errors.New("unexpected { in LogQL stream selector")
+			}
+			selectorStart = sb.Len()
+		case c == '}' && selectorStart >= 0:
+			if strings.TrimSpace(sb.String()[selectorStart+1:]) != "" {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(injected)
+			selectorStart = -1
+			found = true
+		}
+		sb.WriteByte(c)
+	}
+	if selectorStart >= 0 {
+		return "", errors.New("unterminated LogQL stream selector")
+	}
+	if !found {
+		return "", errors.New("no stream selector in LogQL expression")
+	}
+	return sb.String(), nil
+}
+
+// stringEnd returns the index of the quote ending the string literal starting at start, or -1.
+func stringEnd(expr string, start int) int {
+	quote := expr[start]
+	for i := start + 1; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\' && quote == '"':
+			i++
+		case expr[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// InjectSeriesSelector adds the matchers to a Prometheus series selector, like the match[] parameters of
+// the series and labels APIs.
+func InjectSeriesSelector(selector string, matchers []*labels.Matcher) (string, error) {
+	existing, err := parser.ParseMetricSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	vs := &parser.VectorSelector{LabelMatchers: append(existing, matchers...)}
+	for _, m := range existing {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			vs.Name = m.Value
+		}
+	}
+	return vs.String(), nil
+}
@@ -0,0 +1,193 @@
+// Package labelaccess enforces the label access rules of Prometheus and Loki data sources at query time.
+// Each rule grants a team or a user access to the series matching a label selector, and the selector is
+// injected in every query of the users the rules apply to.
+package labelaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// JSONDataKey is the key of the label access rules in the JsonData of a data source.
+const JSONDataKey = "labelAccessRules"
+
+var (
+	ErrInvalidRule = errors.New("invalid label access rule")
+
+	ErrAccessDenied = errutil.Forbidden("datasources.labelAccessDenied",
+		errutil.WithPublicMessage("The label access rules of the data source grant you no access"))
+)
+
+// Rule grants a team or a user access to the series matching the selector.
+type Rule struct {
+	// TeamID is the team the rule applies to.
+	TeamID int64 `json:"teamId,omitempty"`
+	// UserLogin is the user the rule applies to, when the rule is not for a team.
+	UserLogin string `json:"userLogin,omitempty"`
+	// Selector is a label selector, for example {namespace=~"team-a-.*"}.
+	Selector string `json:"selector"`
+
+	matchers []*labels.Matcher
+}
+
+// Rules are the label access rules of a data source.
+type Rules []Rule
+
+// SupportsPlugin returns true if label access rules are enforced for the data source plugin.
+func SupportsPlugin(pluginID string) bool {
+	return pluginID == "prometheus" || pluginID == "loki"
+}
+
+// FromJSONData returns the label access rules in the JsonData of a data source.
+func FromJSONData(jsonData *simplejson.Json) (Rules, error) {
+	if jsonData == nil {
+		return nil, nil
+	}
+	raw, ok := jsonData.CheckGet(JSONDataKey)
+	if !ok {
+		return nil, nil
+	}
+	b, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRule, err)
+	}
+	for i := range rules {
+		if err := rules[i].parse(); err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrInvalidRule, i, err)
+		}
+	}
+	return rules, nil
+}
+
+// FromInstanceSettings returns the label access rules of a data source.
+func FromInstanceSettings(settings *backend.DataSourceInstanceSettings) (Rules, error) {
+	if settings == nil || len(settings.JSONData) == 0 {
+		return nil, nil
+	}
+	jsonData, err := simplejson.NewJson(settings.JSONData)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSONData(jsonData)
+}
+
+func (r *Rule) parse() error {
+	if (r.TeamID == 0) == (r.UserLogin == "") {
+		return errors.New("exactly one of teamId and userLogin is required")
+	}
+	matchers, err := parser.ParseMetricSelector(r.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector %q: %w", r.Selector, err)
+	}
+	for _, m := range matchers {
+		if m.Name == labels.MetricName {
+			return errors.New("selectors can't match the metric name")
+		}
+	}
+	r.matchers = matchers
+	return nil
+}
+
+func (r Rule) appliesTo(user identity.Requester) bool {
+	if r.UserLogin != "" {
+		return r.UserLogin == user.GetLogin()
+	}
+	for _, teamID := range user.GetTeams() {
+		if teamID == r.TeamID {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchersFor returns the label matchers injected in the queries of the user. It returns no matchers if the
+// user is not restricted, either because there are no rules or because the user is an organization admin, and
+// ErrAccessDenied if no rule applies to the user.
+//
+// The access of a user several rules apply to is the union of their selectors. It can only be expressed as a
+// single selector when each of the rules has one equality or regular expression matcher on the same label.
+func (r Rules) MatchersFor(user identity.Requester) ([]*labels.Matcher, error) {
+	if len(r) == 0 || user.GetOrgRole() == identity.RoleAdmin {
+		return nil, nil
+	}
+
+	var applied []Rule
+	for _, rule := range r {
+		if rule.appliesTo(user) {
+			applied = append(applied, rule)
+		}
+	}
+	switch len(applied) {
+	case 0:
+		return nil, ErrAccessDenied.Errorf("no label access rule applies to the user")
+	case 1:
+		return applied[0].matchers, nil
+	}
+
+	name := applied[0].matchers[0].Name
+	alternatives := make([]string, 0, len(applied))
+	for _, rule := range applied {
+		if len(rule.matchers) != 1 || rule.matchers[0].Name != name {
+			return nil, ErrAccessDenied.Errorf("the label access rules of the user restrict different labels")
+		}
+		m := rule.matchers[0]
+		switch m.Type {
+		case labels.MatchEqual:
+			alternatives = append(alternatives, regexp.QuoteMeta(m.Value))
+		case labels.MatchRegexp:
+			alternatives = append(alternatives, "(?:"+m.Value+")")
+		default:
+			return nil, ErrAccessDenied.Errorf("negative label access rules can't be combined")
+		}
+	}
+	merged, err := labels.NewMatcher(labels.MatchRegexp, name, strings.Join(alternatives, "|"))
+	if err != nil {
+		return nil, err
+	}
+	return []*labels.Matcher{merged}, nil
+}
+
+// CheckProxy returns ErrAccessDenied if the label access rules of a data source restrict the user. The data
+// source proxy forwards the requests as they are, so the rules can't be enforced on it.
+func CheckProxy(pluginID string, jsonData *simplejson.Json, user identity.Requester) error {
+	if !SupportsPlugin(pluginID) {
+		return nil
+	}
+	rules, err := FromJSONData(jsonData)
+	if err != nil {
+		return err
+	}
+	matchers, err := rules.MatchersFor(user)
+	if err != nil {
+		return err
+	}
+	if len(matchers) > 0 {
+		return ErrAccessDenied.Errorf("the data source proxy is not allowed to users restricted by label access rules")
+	}
+	return nil
+}
+
+// Selector returns the selector of the matchers, for example {namespace=~"team-a-.*"}.
+func Selector(matchers []*labels.Matcher) string {
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		parts = append(parts, m.String())
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,163 @@
+package labelaccess
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestFromJSONData(t *testing.T) {
+	rules, err := FromJSONData(simplejson.NewFromAny(map[string]any{}))
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	rules, err = FromJSONData(simplejson.NewFromAny(map[string]any{
+		JSONDataKey: []any{
+			map[string]any{"teamId": 1, "selector": `{namespace="team-a"}`},
+			map[string]any{"userLogin": "alice", "selector": `{namespace=~"team-b-.*"}`},
+		},
+	}))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	invalid := []map[string]any{
+		{"selector": `{namespace="team-a"}`},
+		{"teamId": 1, "userLogin": "alice", "selector": `{namespace="team-a"}`},
+		{"teamId": 1, "selector": `{namespace=}`},
+		{"teamId": 1, "selector": `up{namespace="team-a"}`},
+	}
+	for _, rule := range invalid {
+		_, err := FromJSONData(simplejson.NewFromAny(map[string]any{JSONDataKey: []any{rule}}))
+		require.ErrorIs(t, err, ErrInvalidRule)
+	}
+}
+
+func TestMatchersFor(t *testing.T) {
+	rules, err := FromJSONData(simplejson.NewFromAny(map[string]any{
+		JSONDataKey: []any{
+			map[string]any{"teamId": 1, "selector": `{namespace="team-a"}`},
+			map[string]any{"teamId": 2, "selector": `{namespace=~"team-b-.*"}`},
+			map[string]any{"teamId": 3, "selector": `{cluster="eu"}`},
+			map[string]any{"userLogin": "bob", "selector": `{namespace="bob", cluster="us"}`},
+		},
+	}))
+	require.NoError(t, err)
+
+	t.Run("organization admins are not restricted", func(t *testing.T) {
+		matchers, err := rules.MatchersFor(&user.SignedInUser{OrgRole: identity.RoleAdmin})
+		require.NoError(t, err)
+		require.Empty(t, matchers)
+	})
+
+	t.Run("users without rule are denied", func(t *testing.T) {
+		_, err := rules.MatchersFor(&user.SignedInUser{OrgRole: identity.RoleViewer, Teams: []int64{4}})
+		require.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("single rule", func(t *testing.T) {
+		matchers, err := rules.MatchersFor(&user.SignedInUser{OrgRole: identity.RoleViewer, Login: "bob"})
+		require.NoError(t, err)
+		require.Equal(t, `{namespace="bob", cluster="us"}`, Selector(matchers))
+	})
+
+	t.Run("rules on the same label are merged", func(t *testing.T) {
+		matchers, err := rules.MatchersFor(&user.SignedInUser{OrgRole: identity.RoleEditor, Teams: []int64{1, 2}})
+		require.NoError(t, err)
+		require.Len(t, matchers, 1)
+		require.Equal(t, labels.MatchRegexp, matchers[0].Type)
+		require.True(t, matchers[0].Matches("team-a"))
+		require.True(t, matchers[0].Matches("team-b-prod"))
+		require.False(t, matchers[0].Matches("team-c"))
+	})
+
+	t.Run("rules on different labels are denied", func(t *testing.T) {
+		_, err := rules.MatchersFor(&user.SignedInUser{OrgRole: identity.RoleViewer, Teams: []int64{1, 3}})
+		require.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
+
+func TestCheckProxy(t *testing.T) {
+	jsonData := simplejson.NewFromAny(map[string]any{
+		JSONDataKey: []any{map[string]any{"teamId": 1, "selector": `{namespace="team-a"}`}},
+	})
+
+	require.ErrorIs(t, CheckProxy("prometheus", jsonData, &user.SignedInUser{OrgRole: identity.RoleViewer, Teams: []int64{1}}), ErrAccessDenied)
+	require.ErrorIs(t, CheckProxy("loki", jsonData, &user.SignedInUser{OrgRole: identity.RoleViewer}), ErrAccessDenied)
+	require.NoError(t, CheckProxy("prometheus", jsonData, &user.SignedInUser{OrgRole: identity.RoleAdmin}))
+	require.NoError(t, CheckProxy("prometheus", simplejson.New(), &user.SignedInUser{OrgRole: identity.RoleViewer}))
+	require.NoError(t, CheckProxy("elasticsearch", jsonData, &user.SignedInUser{OrgRole: identity.RoleViewer}))
+}
+
+func TestInjectLogQL(t *testing.T) {
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "namespace", "team-a")}
+
+	tests := []struct {
+		name      string
+		expr      string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "log query",
+			expr:     `{app="api"} |= "error {code}"`,
+			expected: `{app="api", namespace="team-a"} |= "error {code}"`,
+		},
+		{
+			name:     "metric query with several selectors",
+			expr:     `sum(rate({app="api"}[5m])) / sum(rate({app="web"} | json | line_format ` + "`{{.msg}}`" + `[5m]))`,
+			expected: `sum(rate({app="api", namespace="team-a"}[5m])) / sum(rate({app="web", namespace="team-a"} | json | line_format ` + "`{{.msg}}`" + `[5m]))`,
+		},
+		{
+			name:     "template variables are kept",
+			expr:     `{app="${app}"} |= "${search}"`,
+			expected: `{app="${app}", namespace="team-a"} |= "${search}"`,
+		},
+		{
+			name:     "empty selector",
+			expr:     `{}`,
+			expected: `{namespace="team-a"}`,
+		},
+		{
+			name:      "no selector",
+			expr:      `vector(1)`,
+			expectErr: true,
+		},
+		{
+			name:      "unterminated string",
+			expr:      `{app="api}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := InjectLogQL(tt.expr, matchers)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, expr)
+		})
+	}
+}
+
+func TestInjectSeriesSelector(t *testing.T) {
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "namespace", "team-a")}
+
+	selector, err := InjectSeriesSelector(`up{job="api"}`, matchers)
+	require.NoError(t, err)
+	require.Equal(t, `up{job="api",namespace="team-a"}`, selector)
+
+	selector, err = InjectSeriesSelector(`{job="api"}`, matchers)
+	require.NoError(t, err)
+	require.Equal(t, `{job="api",namespace="team-a"}`, selector)
+
+	_, err = InjectSeriesSelector(`up{job=}`, matchers)
+	require.Error(t, err)
+}
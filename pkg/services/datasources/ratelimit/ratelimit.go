@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrRateLimited = errutil.TooManyRequests("datasources.rateLimited").MustTemplate(
+	"Too many requests to data source {{ .Public.datasource }}",
+	errutil.WithPublic("Too many requests to data source {{ .Public.datasource }}, try again later"),
+)
+
+func errRateLimited(uid string, err error) error {
+	return ErrRateLimited.Build(errutil.TemplateData{
+		Public: map[string]any{"datasource": uid},
+		Error:  err,
+	})
+}
+
+// Service limits the number of concurrent requests and the request rate per data source.
+// The same limits apply to the data source proxy and to backend queries.
+type Service struct {
+	settings setting.DataSourceRateLimitSettings
+	metrics  *metrics
+
+	mtx      sync.Mutex
+	limiters map[string]*limiter
+}
+
+func ProvideService(cfg *setting.Cfg, registerer prometheus.Registerer) *Service {
+	return &Service{
+		settings: cfg.DataSourceRateLimit,
+		metrics:  newMetrics(registerer),
+		limiters: make(map[string]*limiter),
+	}
+}
+
+// Acquire blocks until a request to the data source is allowed by its limits, the queue timeout passes
+// or ctx is done. The returned release function must be called once the request completes.
+func (s *Service) Acquire(ctx context.Context, uid string) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	l := s.limiter(uid)
+	if l == nil {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	release, err := l.acquire(ctx, s.settings.QueueTimeout, s.settings.MaxQueueSize, s.metrics.queued.WithLabelValues(uid))
+	s.metrics.waitDuration.WithLabelValues(uid).Observe(time.Since(start).Seconds())
+	if err != nil {
+		s.metrics.rejected.WithLabelValues(uid).Inc()
+		return nil, errRateLimited(uid, err)
+	}
+	s.metrics.inflight.WithLabelValues(uid).Inc()
+	return func() {
+		s.metrics.inflight.WithLabelValues(uid).Dec()
+		release()
+	}, nil
+}
+
+func (s *Service) limiter(uid string) *limiter {
+	cfg := s.settings.Limit(uid)
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	l, ok := s.limiters[uid]
+	if !ok {
+		l = newLimiter(cfg)
+		s.limiters[uid] = l
+	}
+	return l
+}
+
+var errQueueFull = errors.New("request queue is full")
+
+type limiter struct {
+	slots   chan struct{}
+	rate    *rate.Limiter
+	mtx     sync.Mutex
+	waiting int
+}
+
+func newLimiter(cfg setting.DataSourceRateLimit) *limiter {
+	l := &limiter{}
+	if cfg.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	if cfg.QPS > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(cfg.QPS), cfg.Burst)
+	}
+	return l
+}
+
+func (l *limiter) acquire(ctx context.Context, timeout time.Duration, maxQueue int, queued prometheus.Gauge) (func(), error) {
+	l.mtx.Lock()
+	if maxQueue > 0 && l.waiting >= maxQueue {
+		l.mtx.Unlock()
+		return nil, errQueueFull
+	}
+	l.waiting++
+	l.mtx.Unlock()
+	queued.Inc()
+	defer func() {
+		l.mtx.Lock()
+		l.waiting--
+		l.mtx.Unlock()
+		queued.Dec()
+	}()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if l.slots != nil {
+			<-l.slots
+		}
+	}
+
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+type metrics struct {
+	queued       *prometheus.GaugeVec
+	inflight     *prometheus.GaugeVec
+	rejected     *prometheus.CounterVec
+	waitDuration *prometheus.HistogramVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		queued: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_rate_limit",
+			Name:      "queued_requests",
+			Help:      "Number of requests waiting for the rate limit of a data source.",
+		}, []string{"datasource_uid"}),
+		inflight: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_rate_limit",
+			Name:      "inflight_requests",
+			Help:      "Number of requests to a rate limited data source that are in progress.",
+		}, []string{"datasource_uid"}),
+		rejected: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_rate_limit",
+			Name:      "rejected_requests_total",
+			Help:      "Number of requests rejected because of the rate limit of a data source.",
+		}, []string{"datasource_uid"}),
+		waitDuration: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "datasource_rate_limit",
+			Name:      "wait_duration_seconds",
+			Help:      "Time requests waited for the rate limit of a data source.",
+			Buckets:   []float64{.001, .01, .1, .5, 1, 2.5, 5, 10},
+		}, []string{"datasource_uid"}),
+	}
+}
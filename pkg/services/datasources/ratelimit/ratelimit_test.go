@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataSourceRateLimit = setting.DataSourceRateLimitSettings{
+		Overrides: map[string]setting.DataSourceRateLimit{
+			"limited": {MaxConcurrent: 1},
+		},
+		QueueTimeout: 50 * time.Millisecond,
+	}
+	s := ProvideService(cfg, prometheus.NewRegistry())
+
+	t.Run("does not limit data sources without limits", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			_, err := s.Acquire(context.Background(), "unlimited")
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("rejects requests over the concurrency limit once the queue timeout passes", func(t *testing.T) {
+		release, err := s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+
+		_, err = s.Acquire(context.Background(), "limited")
+		require.ErrorIs(t, err, ErrRateLimited)
+
+		release()
+		release, err = s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("queued requests get the slot once it is released", func(t *testing.T) {
+		release, err := s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		time.AfterFunc(10*time.Millisecond, release)
+
+		release, err = s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("rejects requests if the queue is full", func(t *testing.T) {
+		cfg.DataSourceRateLimit.MaxQueueSize = 1
+		s := ProvideService(cfg, prometheus.NewRegistry())
+		l := s.limiter("limited")
+		l.waiting = 1
+
+		_, err := s.Acquire(context.Background(), "limited")
+		require.ErrorIs(t, err, ErrRateLimited)
+	})
+}
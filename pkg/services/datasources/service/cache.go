@@ -2,37 +2,43 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
-	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/guardian"
+	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
 const (
 	DefaultCacheTTL = 5 * time.Second
+	// CacheName is the name of the data source cache, configured in the [remote_cache.datasources] section.
+	CacheName = "datasources"
 )
 
-func ProvideCacheService(cacheService *localcache.CacheService, sqlStore db.DB, dsGuardian guardian.DatasourceGuardianProvider) *CacheServiceImpl {
+func ProvideCacheService(caches namedcache.Provider, sqlStore db.DB, dsGuardian guardian.DatasourceGuardianProvider,
+	secretsService secrets.Service) *CacheServiceImpl {
 	return &CacheServiceImpl{
-		logger:       log.New("datasources"),
-		cacheTTL:     DefaultCacheTTL,
-		CacheService: cacheService,
-		SQLStore:     sqlStore,
-		dsGuardian:   dsGuardian,
+		logger:         log.New("datasources"),
+		cache:          caches.NamedCache(CacheName, DefaultCacheTTL),
+		SQLStore:       sqlStore,
+		dsGuardian:     dsGuardian,
+		secretsService: secretsService,
 	}
 }
 
 type CacheServiceImpl struct {
-	logger       log.Logger
-	cacheTTL     time.Duration
-	CacheService *localcache.CacheService
-	SQLStore     db.DB
-	dsGuardian   guardian.DatasourceGuardianProvider
+	logger         log.Logger
+	cache          *namedcache.Cache
+	SQLStore       db.DB
+	dsGuardian     guardian.DatasourceGuardianProvider
+	secretsService secrets.Service
 }
 
 func (dc *CacheServiceImpl) GetDatasource(
@@ -44,8 +50,7 @@ func (dc *CacheServiceImpl) GetDatasource(
 	cacheKey := idKey(datasourceID)
 
 	if !skipCache {
-		if cached, found := dc.CacheService.Get(cacheKey); found {
-			ds := cached.(*datasources.DataSource)
+		if ds, found := dc.getCached(ctx, cacheKey); found {
 			if ds.OrgID == user.GetOrgID() {
 				if err := dc.canQuery(user, ds); err != nil {
 					return nil, err
@@ -65,9 +70,9 @@ func (dc *CacheServiceImpl) GetDatasource(
 	}
 
 	if ds.UID != "" {
-		dc.CacheService.Set(uidKey(ds.OrgID, ds.UID), ds, time.Second*5)
+		dc.setCached(ctx, uidKey(ds.OrgID, ds.UID), ds)
 	}
-	dc.CacheService.Set(cacheKey, ds, dc.cacheTTL)
+	dc.setCached(ctx, cacheKey, ds)
 
 	if err = dc.canQuery(user, ds); err != nil {
 		return nil, err
@@ -91,8 +96,7 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 	uidCacheKey := uidKey(user.GetOrgID(), datasourceUID)
 
 	if !skipCache {
-		if cached, found := dc.CacheService.Get(uidCacheKey); found {
-			ds := cached.(*datasources.DataSource)
+		if ds, found := dc.getCached(ctx, uidCacheKey); found {
 			if ds.OrgID == user.GetOrgID() {
 				if err := dc.canQuery(user, ds); err != nil {
 					return nil, err
@@ -110,8 +114,8 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 		return nil, err
 	}
 
-	dc.CacheService.Set(uidCacheKey, ds, dc.cacheTTL)
-	dc.CacheService.Set(idKey(ds.ID), ds, dc.cacheTTL)
+	dc.setCached(ctx, uidCacheKey, ds)
+	dc.setCached(ctx, idKey(ds.ID), ds)
 
 	if err = dc.canQuery(user, ds); err != nil {
 		return nil, err
@@ -120,6 +124,72 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 	return ds, nil
 }
 
+// cachedDataSource keeps the legacy password fields, which are not serialized with the data source. They are
+// stored encrypted, as the cache can be shared through the remote cache.
+type cachedDataSource struct {
+	*datasources.DataSource
+	EncryptedPassword          []byte `json:"encryptedPassword,omitempty"`
+	EncryptedBasicAuthPassword []byte `json:"encryptedBasicAuthPassword,omitempty"`
+}
+
+func (dc *CacheServiceImpl) getCached(ctx context.Context, key string) (*datasources.DataSource, bool) {
+	data, err := dc.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, namedcache.ErrCacheItemNotFound) {
+			dc.logger.FromContext(ctx).Warn("Failed to get data source from cache", "key", key, "error", err)
+		}
+		return nil, false
+	}
+
+	cached := cachedDataSource{DataSource: &datasources.DataSource{}}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		dc.logger.FromContext(ctx).Warn("Failed to decode cached data source", "key", key, "error", err)
+		return nil, false
+	}
+	if cached.DataSource.Password, err = dc.decrypt(ctx, cached.EncryptedPassword); err == nil {
+		cached.DataSource.BasicAuthPassword, err = dc.decrypt(ctx, cached.EncryptedBasicAuthPassword)
+	}
+	if err != nil {
+		dc.logger.FromContext(ctx).Warn("Failed to decrypt cached data source", "key", key, "error", err)
+		return nil, false
+	}
+	return cached.DataSource, true
+}
+
+func (dc *CacheServiceImpl) setCached(ctx context.Context, key string, ds *datasources.DataSource) {
+	cached := cachedDataSource{DataSource: ds}
+	password, err := dc.encrypt(ctx, ds.Password)
+	if err == nil {
+		cached.EncryptedPassword = password
+		cached.EncryptedBasicAuthPassword, err = dc.encrypt(ctx, ds.BasicAuthPassword)
+	}
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(cached)
+	}
+	if err == nil {
+		err = dc.cache.Set(ctx, key, data, 0)
+	}
+	if err != nil {
+		dc.logger.FromContext(ctx).Warn("Failed to cache data source", "key", key, "error", err)
+	}
+}
+
+func (dc *CacheServiceImpl) encrypt(ctx context.Context, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return dc.secretsService.Encrypt(ctx, []byte(value), secrets.WithoutScope())
+}
+
+func (dc *CacheServiceImpl) decrypt(ctx context.Context, value []byte) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	decrypted, err := dc.secretsService.Decrypt(ctx, value)
+	return string(decrypted), err
+}
+
 func idKey(id int64) string {
 	return fmt.Sprintf("ds-%d", id)
 }
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/guardian"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsmng "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestCacheService_CachedDataSource(t *testing.T) {
+	ctx := context.Background()
+	secretsService := secretsmng.SetupTestService(t, fakes.NewFakeSecretsStore())
+	dc := ProvideCacheService(namedcache.FakeProvider{}, nil, guardian.ProvideGuardian(), secretsService)
+
+	ds := &datasources.DataSource{
+		ID:                1,
+		OrgID:             2,
+		UID:               "uid",
+		Name:              "prometheus",
+		Password:          "password",
+		BasicAuthPassword: "basic auth password",
+		JsonData:          simplejson.NewFromAny(map[string]any{"httpMethod": "POST"}),
+		SecureJsonData:    map[string][]byte{"token": []byte("encrypted")},
+	}
+	dc.setCached(ctx, idKey(ds.ID), ds)
+
+	// the legacy passwords aren't cached in plaintext
+	raw, err := dc.cache.Get(ctx, idKey(ds.ID))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), ds.Password)
+
+	cached, found := dc.getCached(ctx, idKey(ds.ID))
+	require.True(t, found)
+	require.Equal(t, ds.UID, cached.UID)
+	require.Equal(t, ds.Password, cached.Password)
+	require.Equal(t, ds.BasicAuthPassword, cached.BasicAuthPassword)
+	require.Equal(t, "POST", cached.JsonData.Get("httpMethod").MustString())
+	require.Equal(t, ds.SecureJsonData, cached.SecureJsonData)
+
+	_, found = dc.getCached(ctx, uidKey(ds.OrgID, ds.UID))
+	require.False(t, found)
+}
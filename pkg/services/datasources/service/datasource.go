@@ -19,10 +19,13 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/httpclient/tunnel"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/labelaccess"
+	"github.com/grafana/grafana/pkg/services/datasources/transformations"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/adapters"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
@@ -314,6 +317,19 @@ func (s *Service) prepareInstanceSettings(ctx context.Context, settings *backend
 		return nil, datasources.ErrDataSourceURLInvalid.Errorf("max length is %d", maxDatasourceUrlLen)
 	}
 
+	if len(settings.JSONData) > 0 {
+		jsonData, err := simplejson.NewJson(settings.JSONData)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := transformations.FromJSONData(jsonData); err != nil {
+			return nil, datasources.ErrDataSourceTransformationsInvalid.Errorf("%w", err)
+		}
+		if _, err := labelaccess.FromJSONData(jsonData); err != nil {
+			return nil, datasources.ErrDataSourceLabelAccessRulesInvalid.Errorf("%w", err)
+		}
+	}
+
 	if settings.Type == "" {
 		return settings, nil // NOOP used in tests
 	}
@@ -442,7 +458,18 @@ func getAvailableName(dsType string, dataSources []*datasources.DataSource) stri
 }
 
 func (s *Service) DeleteDataSource(ctx context.Context, cmd *datasources.DeleteDataSourceCommand) error {
-	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+	uid := cmd.UID
+	err := s.db.InTransaction(ctx, func(ctx context.Context) error {
+		if uid == "" {
+			ds, err := s.SQLStore.GetDataSource(ctx, &datasources.GetDataSourceQuery{ID: cmd.ID, Name: cmd.Name, OrgID: cmd.OrgID})
+			if err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return err
+			}
+			if ds != nil {
+				uid = ds.UID
+			}
+		}
+
 		cmd.UpdateSecretFn = func() error {
 			return s.SecretsStore.Del(ctx, cmd.OrgID, cmd.Name, kvstore.DataSourceSecretType)
 		}
@@ -453,6 +480,11 @@ func (s *Service) DeleteDataSource(ctx context.Context, cmd *datasources.DeleteD
 
 		return s.permissionsService.DeleteResourcePermissions(ctx, cmd.OrgID, cmd.UID)
 	})
+	if err == nil && uid != "" {
+		// close the connection to the SSH bastion of the data source
+		tunnel.Remove(uid)
+	}
+	return err
 }
 
 func (s *Service) decryptSecureJsonDataFn(ctx context.Context) func(ds *datasources.DataSource) (map[string]string, error) {
@@ -559,6 +591,10 @@ func (s *Service) UpdateDataSource(ctx context.Context, cmd *datasources.UpdateD
 		}
 
 		dataSource, err = s.SQLStore.UpdateDataSource(ctx, cmd)
+		if err == nil {
+			// the tunnel settings may have changed, the connection to the SSH bastion is opened again
+			tunnel.Remove(dataSource.UID)
+		}
 		return err
 	})
 }
@@ -753,6 +789,17 @@ func (s *Service) httpClientOptions(ctx context.Context, ds *datasources.DataSou
 		opts.ProxyOptions = proxyOpts
 	}
 
+	tunnelOpts, err := tunnel.OptionsFromJSONData(ds.UID, ds.JsonData, decryptedValues)
+	if err != nil {
+		return opts, fmt.Errorf("invalid tunnel settings: %w", err)
+	}
+	if tunnelOpts != nil {
+		if opts.ProxyOptions != nil {
+			return opts, errors.New("tunnel and secure socks proxy cannot be enabled at the same time")
+		}
+		opts.CustomOptions[tunnel.OptionsKey] = tunnelOpts
+	}
+
 	if ds.JsonData != nil && ds.JsonData.Get("sigV4Auth").MustBool(false) && s.cfg.SigV4AuthEnabled {
 		opts.SigV4 = &sdkhttpclient.SigV4Config{
 			Service:       awsServiceNamespace(ds.Type, ds.JsonData),
@@ -104,6 +104,22 @@ func TestService_AddDataSource(t *testing.T) {
 		require.EqualError(t, err, "[datasource.urlInvalid] max length is 255")
 	})
 
+	t.Run("should fail if the response transformations are invalid", func(t *testing.T) {
+		dsService := initDSService(t)
+
+		cmd := &datasources.AddDataSourceCommand{
+			OrgID: 1,
+			JsonData: simplejson.NewFromAny(map[string]any{
+				"responseTransformations": []any{
+					map[string]any{"type": "normalizeUnit", "from": "ms", "to": "bytes"},
+				},
+			}),
+		}
+
+		_, err := dsService.AddDataSource(context.Background(), cmd)
+		require.ErrorIs(t, err, datasources.ErrDataSourceTransformationsInvalid)
+	})
+
 	t.Run("if a plugin has an API version defined (EXPERIMENTAL)", func(t *testing.T) {
 		t.Run("should success to run admission hooks", func(t *testing.T) {
 			dsService := initDSService(t)
@@ -170,15 +170,18 @@ func (ss *SqlStore) DeleteDataSource(ctx context.Context, cmd *datasources.Delet
 			}
 		}
 
-		// Publish data source deletion event
+		// Publish data source deletion event through the outbox, so that the correlations of the data source
+		// are deleted even if Grafana stops right after the commit
 		if cmd.DeletedDatasourcesCount > 0 && !cmd.SkipPublish {
-			sess.PublishAfterCommit(&events.DataSourceDeleted{
+			if err := sess.AppendOutboxEvent(ds.OrgID, &events.DataSourceDeleted{
 				Timestamp: time.Now(),
 				Name:      ds.Name,
 				ID:        ds.ID,
 				UID:       ds.UID,
 				OrgID:     ds.OrgID,
-			})
+			}); err != nil {
+				return err
+			}
 		}
 
 		return nil
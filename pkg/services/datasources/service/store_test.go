@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
 func TestIntegrationDataAccess(t *testing.T) {
@@ -283,48 +285,47 @@ func TestIntegrationDataAccess(t *testing.T) {
 		})
 	})
 
-	t.Run("fires an event when the datasource is deleted", func(t *testing.T) {
+	outboxEvents := func(t *testing.T, db db.DB) []*events.DataSourceDeleted {
+		t.Helper()
+		rows := make([]*sqlstore.OutboxEvent, 0)
+		require.NoError(t, db.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Where("event_type = ?", "DataSourceDeleted").Find(&rows)
+		}))
+		deleted := make([]*events.DataSourceDeleted, 0, len(rows))
+		for _, row := range rows {
+			e := &events.DataSourceDeleted{}
+			require.NoError(t, json.Unmarshal([]byte(row.Payload), e))
+			deleted = append(deleted, e)
+		}
+		return deleted
+	}
+
+	t.Run("appends an event to the outbox when the datasource is deleted", func(t *testing.T) {
 		db := db.InitTestDB(t)
 		ds := initDatasource(db)
 		ss := SqlStore{db: db}
 
-		var deleted *events.DataSourceDeleted
-		db.Bus().AddEventListener(func(ctx context.Context, e *events.DataSourceDeleted) error {
-			deleted = e
-			return nil
-		})
-
 		err := ss.DeleteDataSource(context.Background(),
 			&datasources.DeleteDataSourceCommand{ID: ds.ID, UID: ds.UID, Name: ds.Name, OrgID: ds.OrgID})
 		require.NoError(t, err)
 
-		require.Eventually(t, func() bool {
-			return assert.NotNil(t, deleted)
-		}, time.Second, time.Millisecond)
-
-		require.Equal(t, ds.ID, deleted.ID)
-		require.Equal(t, ds.OrgID, deleted.OrgID)
-		require.Equal(t, ds.Name, deleted.Name)
-		require.Equal(t, ds.UID, deleted.UID)
+		deleted := outboxEvents(t, db)
+		require.Len(t, deleted, 1)
+		require.Equal(t, ds.ID, deleted[0].ID)
+		require.Equal(t, ds.OrgID, deleted[0].OrgID)
+		require.Equal(t, ds.Name, deleted[0].Name)
+		require.Equal(t, ds.UID, deleted[0].UID)
 	})
 
-	t.Run("does not fire an event when the datasource is not deleted", func(t *testing.T) {
+	t.Run("does not append an event when the datasource is not deleted", func(t *testing.T) {
 		db := db.InitTestDB(t)
 		ss := SqlStore{db: db}
 
-		var called bool
-		db.Bus().AddEventListener(func(ctx context.Context, e *events.DataSourceDeleted) error {
-			called = true
-			return nil
-		})
-
 		err := ss.DeleteDataSource(context.Background(),
 			&datasources.DeleteDataSourceCommand{ID: 1, UID: "non-existing", Name: "non-existing", OrgID: int64(10)})
 		require.NoError(t, err)
 
-		require.Never(t, func() bool {
-			return called
-		}, time.Second, time.Millisecond)
+		require.Empty(t, outboxEvents(t, db))
 	})
 
 	t.Run("DeleteDataSourceByName", func(t *testing.T) {
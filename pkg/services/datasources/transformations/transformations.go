@@ -0,0 +1,235 @@
+// Package transformations applies the response transformation rules of a data source to the frames
+// its queries return, so that data sources with different conventions can be normalized on the server.
+package transformations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// JSONDataKey is the key of the transformation rules in the JsonData of a data source.
+const JSONDataKey = "responseTransformations"
+
+const (
+	TypeRenameField   = "renameField"
+	TypeNormalizeUnit = "normalizeUnit"
+	TypeDropLabels    = "dropLabels"
+)
+
+var ErrInvalidRule = errors.New("invalid response transformation")
+
+// Rule is a single transformation of the frames of a data source.
+type Rule struct {
+	// Type is one of renameField, normalizeUnit or dropLabels.
+	Type string `json:"type"`
+	// Field is the name of the field to rename or normalize. For normalizeUnit it is optional,
+	// every numeric field with the From unit is normalized without it.
+	Field string `json:"field,omitempty"`
+	// From is the unit the values are converted from.
+	From string `json:"from,omitempty"`
+	// To is the new name of the field for renameField and the unit the values are converted to for normalizeUnit.
+	To string `json:"to,omitempty"`
+	// Labels are the labels removed from the fields by dropLabels.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Pipeline is the list of rules of a data source, applied in order.
+type Pipeline []Rule
+
+// FromJSONData returns the transformation rules in the JsonData of a data source.
+func FromJSONData(jsonData *simplejson.Json) (Pipeline, error) {
+	if jsonData == nil {
+		return nil, nil
+	}
+	raw, ok := jsonData.CheckGet(JSONDataKey)
+	if !ok {
+		return nil, nil
+	}
+	b, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var p Pipeline
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRule, err)
+	}
+	for i, rule := range p {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrInvalidRule, i, err)
+		}
+	}
+	return p, nil
+}
+
+func (r Rule) validate() error {
+	switch r.Type {
+	case TypeRenameField:
+		if r.Field == "" || r.To == "" {
+			return errors.New("renameField requires field and to")
+		}
+	case TypeNormalizeUnit:
+		if _, err := conversionFactor(r.From, r.To); err != nil {
+			return err
+		}
+	case TypeDropLabels:
+		if len(r.Labels) == 0 {
+			return errors.New("dropLabels requires labels")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", r.Type)
+	}
+	return nil
+}
+
+// Apply transforms the frames in place.
+func (p Pipeline) Apply(frames data.Frames) error {
+	for _, rule := range p {
+		for _, frame := range frames {
+			if frame == nil {
+				continue
+			}
+			if err := rule.apply(frame); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) apply(frame *data.Frame) error {
+	switch r.Type {
+	case TypeRenameField:
+		for _, field := range frame.Fields {
+			if field.Name == r.Field {
+				field.Name = r.To
+			}
+		}
+	case TypeNormalizeUnit:
+		return r.normalizeUnit(frame)
+	case TypeDropLabels:
+		for _, field := range frame.Fields {
+			for _, label := range r.Labels {
+				delete(field.Labels, label)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) normalizeUnit(frame *data.Frame) error {
+	factor, err := conversionFactor(r.From, r.To)
+	if err != nil {
+		return err
+	}
+
+	for i, field := range frame.Fields {
+		if !field.Type().Numeric() {
+			continue
+		}
+		var unit string
+		if field.Config != nil {
+			unit = field.Config.Unit
+		}
+		if r.Field != "" {
+			// a field selected by name may not have a unit yet
+			if field.Name != r.Field || (unit != "" && unit != r.From) {
+				continue
+			}
+		} else if unit != r.From {
+			continue
+		}
+
+		converted, err := scale(field, factor)
+		if err != nil {
+			return err
+		}
+		config := data.FieldConfig{}
+		if field.Config != nil {
+			config = *field.Config
+		}
+		config.Unit = r.To
+		converted.SetConfig(&config)
+		frame.Fields[i] = converted
+	}
+	return nil
+}
+
+// scale returns a float64 copy of a numeric field with its values multiplied by factor.
+func scale(field *data.Field, factor float64) (*data.Field, error) {
+	fieldType := data.FieldTypeFloat64
+	if field.Nullable() {
+		fieldType = data.FieldTypeNullableFloat64
+	}
+	converted := data.NewFieldFromFieldType(fieldType, field.Len())
+	converted.Name = field.Name
+	converted.Labels = field.Labels
+
+	for i := 0; i < field.Len(); i++ {
+		v, err := field.NullableFloatAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		value := *v * factor
+		if field.Nullable() {
+			converted.Set(i, &value)
+		} else {
+			converted.Set(i, value)
+		}
+	}
+	return converted, nil
+}
+
+// units are the unit IDs of the panel editor that can be converted into each other, with their
+// dimension and their size in the base unit of the dimension.
+var units = map[string]struct {
+	dimension string
+	size      float64
+}{
+	"ns": {"time", 1e-9},
+	"µs": {"time", 1e-6},
+	"us": {"time", 1e-6},
+	"ms": {"time", 1e-3},
+	"s":  {"time", 1},
+	"m":  {"time", 60},
+	"h":  {"time", 3600},
+	"d":  {"time", 86400},
+
+	"bits":        {"data", 1.0 / 8},
+	"bytes":       {"data", 1},
+	"kbytes":      {"data", 1 << 10},
+	"mbytes":      {"data", 1 << 20},
+	"gbytes":      {"data", 1 << 30},
+	"tbytes":      {"data", 1 << 40},
+	"decbits":     {"data", 1.0 / 8},
+	"decbytes":    {"data", 1},
+	"deckbytes":   {"data", 1e3},
+	"decmbytes":   {"data", 1e6},
+	"decgbytes":   {"data", 1e9},
+	"dectbytes":   {"data", 1e12},
+	"percent":     {"ratio", 0.01},
+	"percentunit": {"ratio", 1},
+}
+
+func conversionFactor(from, to string) (float64, error) {
+	f, ok := units[from]
+	if !ok {
+		return 0, fmt.Errorf("unsupported unit %q", from)
+	}
+	t, ok := units[to]
+	if !ok {
+		return 0, fmt.Errorf("unsupported unit %q", to)
+	}
+	if f.dimension != t.dimension {
+		return 0, fmt.Errorf("can't convert %s to %s", from, to)
+	}
+	return f.size / t.size, nil
+}
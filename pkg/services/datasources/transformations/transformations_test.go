@@ -0,0 +1,93 @@
+package transformations
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestFromJSONData(t *testing.T) {
+	t.Run("returns no rules without transformations", func(t *testing.T) {
+		p, err := FromJSONData(simplejson.New())
+		require.NoError(t, err)
+		require.Empty(t, p)
+	})
+
+	t.Run("parses the rules", func(t *testing.T) {
+		p, err := FromJSONData(simplejson.NewFromAny(map[string]any{
+			JSONDataKey: []any{
+				map[string]any{"type": "renameField", "field": "Value", "to": "latency"},
+				map[string]any{"type": "normalizeUnit", "from": "ms", "to": "s"},
+				map[string]any{"type": "dropLabels", "labels": []string{"tenant"}},
+			},
+		}))
+		require.NoError(t, err)
+		require.Equal(t, Pipeline{
+			{Type: TypeRenameField, Field: "Value", To: "latency"},
+			{Type: TypeNormalizeUnit, From: "ms", To: "s"},
+			{Type: TypeDropLabels, Labels: []string{"tenant"}},
+		}, p)
+	})
+
+	for name, rule := range map[string]map[string]any{
+		"unknown type":           {"type": "sort"},
+		"rename without target":  {"type": "renameField", "field": "Value"},
+		"unsupported unit":       {"type": "normalizeUnit", "from": "ms", "to": "lightyears"},
+		"different dimensions":   {"type": "normalizeUnit", "from": "ms", "to": "bytes"},
+		"drop without any label": {"type": "dropLabels"},
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := FromJSONData(simplejson.NewFromAny(map[string]any{JSONDataKey: []any{rule}}))
+			require.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func TestPipelineApply(t *testing.T) {
+	t.Run("renames fields and drops labels", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("Time", nil, []int64{1}),
+			data.NewField("Value", data.Labels{"job": "api", "tenant": "a"}, []float64{1}),
+		)
+		err := Pipeline{
+			{Type: TypeRenameField, Field: "Value", To: "requests"},
+			{Type: TypeDropLabels, Labels: []string{"tenant"}},
+		}.Apply(data.Frames{frame})
+		require.NoError(t, err)
+		require.Equal(t, "requests", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"job": "api"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("normalizes the fields with the source unit", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("a", nil, []int64{1500}).SetConfig(&data.FieldConfig{Unit: "ms", DisplayName: "A"}),
+			data.NewField("b", nil, []*float64{nil}).SetConfig(&data.FieldConfig{Unit: "ms"}),
+			data.NewField("c", nil, []float64{2}).SetConfig(&data.FieldConfig{Unit: "bytes"}),
+		)
+		err := Pipeline{{Type: TypeNormalizeUnit, From: "ms", To: "s"}}.Apply(data.Frames{frame})
+		require.NoError(t, err)
+
+		require.Equal(t, data.FieldTypeFloat64, frame.Fields[0].Type())
+		require.Equal(t, 1.5, frame.Fields[0].At(0))
+		require.Equal(t, &data.FieldConfig{Unit: "s", DisplayName: "A"}, frame.Fields[0].Config)
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Nil(t, frame.Fields[1].At(0))
+		require.Equal(t, "bytes", frame.Fields[2].Config.Unit)
+	})
+
+	t.Run("normalizes a field selected by name without a unit", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("Value", nil, []float64{2}),
+			data.NewField("Other", nil, []float64{2}),
+		)
+		err := Pipeline{{Type: TypeNormalizeUnit, Field: "Value", From: "kbytes", To: "bytes"}}.Apply(data.Frames{frame})
+		require.NoError(t, err)
+		require.Equal(t, 2048.0, frame.Fields[0].At(0))
+		require.Equal(t, "bytes", frame.Fields[0].Config.Unit)
+		require.Equal(t, 2.0, frame.Fields[1].At(0))
+		require.Nil(t, frame.Fields[1].Config)
+	})
+}
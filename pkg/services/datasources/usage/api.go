@@ -0,0 +1,93 @@
+package usage
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const defaultDays = 30
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	routeRegister.Group("/api/datasources", func(subrouter routing.RouteRegister) {
+		subrouter.Get("/usage", authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(s.listUsageHandler))
+		subrouter.Get("/uid/:uid/usage", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.getUsageHandler))
+	})
+}
+
+// period returns the first and the last day of the period of the days query parameter, 30 days by default.
+// The period ends today and can't start before the retention period.
+func (s *Service) period(c *contextmodel.ReqContext) (string, string) {
+	days := c.QueryInt("days")
+	if days <= 0 {
+		days = defaultDays
+	}
+	days = min(days, int(s.settings.Retention/(24*time.Hour)))
+
+	today := s.now().UTC()
+	return today.AddDate(0, 0, 1-days).Format(dayFormat), today.Format(dayFormat)
+}
+
+// getUsageHandler returns the usage of a data source, with its daily rollups and top dashboards and users.
+func (s *Service) getUsageHandler(c *contextmodel.ReqContext) response.Response {
+	from, to := s.period(c)
+	usage, err := s.usage(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], from, to)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+	return response.JSON(http.StatusOK, usage)
+}
+
+// listUsageHandler returns the usage of every data source the user can edit, the least used first, so that unused
+// data sources are listed even if they were never queried.
+func (s *Service) listUsageHandler(c *contextmodel.ReqContext) response.Response {
+	from, to := s.period(c)
+	orgID := c.SignedInUser.GetOrgID()
+
+	dss, err := s.listDataSources(c.Req.Context(), orgID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list data sources", err)
+	}
+	totals, err := s.totals(c.Req.Context(), orgID, from, to)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+
+	summaries := make([]Summary, 0, len(dss))
+	for _, ds := range dss {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(ds.UID)))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !ok {
+			continue
+		}
+		t := totals[ds.UID]
+		summaries = append(summaries, Summary{
+			DataSourceUID:  ds.UID,
+			DataSourceName: ds.Name,
+			DataSourceType: ds.Type,
+			Queries:        t.Queries,
+			Errors:         t.Errors,
+			ErrorRate:      errorRate(t.Errors, t.Queries),
+			LastQueriedOn:  t.LastDay,
+		})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Queries != summaries[j].Queries {
+			return summaries[i].Queries < summaries[j].Queries
+		}
+		return summaries[i].DataSourceName < summaries[j].DataSourceName
+	})
+	return response.JSON(http.StatusOK, summaries)
+}
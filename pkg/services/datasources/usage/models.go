@@ -0,0 +1,97 @@
+package usage
+
+// dailyUsage is the usage of a data source during a day.
+type dailyUsage struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	DataSourceUID string `xorm:"datasource_uid"`
+	// Day is the UTC date, formatted as 2006-01-02.
+	Day           string `xorm:"day"`
+	Queries       int64  `xorm:"queries"`
+	Errors        int64  `xorm:"errors"`
+	DurationMs    int64  `xorm:"duration_ms"`
+	MaxDurationMs int64  `xorm:"max_duration_ms"`
+}
+
+func (dailyUsage) TableName() string {
+	return "datasource_usage"
+}
+
+// dailyLatency is the number of queries of a data source during a day that took at most LeMs milliseconds,
+// and more than the previous bucket. The last bucket has no upper bound and an LeMs of 0.
+type dailyLatency struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	DataSourceUID string `xorm:"datasource_uid"`
+	Day           string `xorm:"day"`
+	LeMs          int64  `xorm:"le_ms"`
+	Queries       int64  `xorm:"queries"`
+}
+
+func (dailyLatency) TableName() string {
+	return "datasource_usage_latency"
+}
+
+const (
+	sourceDashboard = "dashboard"
+	sourceUser      = "user"
+)
+
+// dailySource is the number of queries a dashboard or a user issued to a data source during a day.
+type dailySource struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	DataSourceUID string `xorm:"datasource_uid"`
+	Day           string `xorm:"day"`
+	// Kind is either dashboard or user, and Name the UID of the dashboard or the login of the user.
+	Kind    string `xorm:"kind"`
+	Name    string `xorm:"name"`
+	Queries int64  `xorm:"queries"`
+}
+
+func (dailySource) TableName() string {
+	return "datasource_usage_source"
+}
+
+// Usage is the usage of a data source over a period.
+type Usage struct {
+	DataSourceUID string `json:"datasourceUid"`
+	// From and To are the first and the last day of the period, formatted as 2006-01-02.
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Queries       int64   `json:"queries"`
+	Errors        int64   `json:"errors"`
+	ErrorRate     float64 `json:"errorRate"`
+	P95LatencyMs  int64   `json:"p95LatencyMs"`
+	MaxLatencyMs  int64   `json:"maxLatencyMs"`
+	LastQueriedOn string  `json:"lastQueriedOn,omitempty"`
+	Daily         []Day   `json:"daily"`
+	TopDashboards []Top   `json:"topDashboards"`
+	TopUsers      []Top   `json:"topUsers"`
+}
+
+// Day is the usage of a data source during a day.
+type Day struct {
+	Day          string  `json:"day"`
+	Queries      int64   `json:"queries"`
+	Errors       int64   `json:"errors"`
+	ErrorRate    float64 `json:"errorRate"`
+	P95LatencyMs int64   `json:"p95LatencyMs"`
+}
+
+// Top is a dashboard or a user issuing queries to a data source. Name is the UID of the dashboard or the login of the user.
+type Top struct {
+	Name    string `xorm:"name" json:"name"`
+	Queries int64  `xorm:"queries" json:"queries"`
+}
+
+// Summary is the usage of a data source over a period, as listed for all the data sources of an organization.
+type Summary struct {
+	DataSourceUID  string  `json:"datasourceUid"`
+	DataSourceName string  `json:"datasourceName"`
+	DataSourceType string  `json:"datasourceType"`
+	Queries        int64   `json:"queries"`
+	Errors         int64   `json:"errors"`
+	ErrorRate      float64 `json:"errorRate"`
+	LastQueriedOn  string  `json:"lastQueriedOn,omitempty"`
+}
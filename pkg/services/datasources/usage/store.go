@@ -0,0 +1,230 @@
+package usage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// add adds the pending usage of a data source to its daily rollups. If it fails, the usage is kept in memory and
+// added again on the next flush.
+func (s *Service) add(ctx context.Context, key usageKey, p *pendingUsage) error {
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := increment(sess,
+			"UPDATE datasource_usage SET queries = queries + ?, errors = errors + ?, duration_ms = duration_ms + ?, "+
+				"max_duration_ms = CASE WHEN max_duration_ms < ? THEN ? ELSE max_duration_ms END "+
+				"WHERE org_id = ? AND datasource_uid = ? AND day = ?",
+			[]any{p.queries, p.errors, p.durationMs, p.maxDurationMs, p.maxDurationMs, key.orgID, key.uid, key.day},
+			&dailyUsage{
+				OrgID:         key.orgID,
+				DataSourceUID: key.uid,
+				Day:           key.day,
+				Queries:       p.queries,
+				Errors:        p.errors,
+				DurationMs:    p.durationMs,
+				MaxDurationMs: p.maxDurationMs,
+			},
+		); err != nil {
+			return err
+		}
+
+		for i, n := range p.latency {
+			if n == 0 {
+				continue
+			}
+			var le int64
+			if i < len(latencyBuckets) {
+				le = latencyBuckets[i]
+			}
+			if err := increment(sess,
+				"UPDATE datasource_usage_latency SET queries = queries + ? WHERE org_id = ? AND datasource_uid = ? AND day = ? AND le_ms = ?",
+				[]any{n, key.orgID, key.uid, key.day, le},
+				&dailyLatency{OrgID: key.orgID, DataSourceUID: key.uid, Day: key.day, LeMs: le, Queries: n},
+			); err != nil {
+				return err
+			}
+		}
+
+		for kind, counts := range map[string]map[string]int64{sourceDashboard: p.dashboards, sourceUser: p.users} {
+			for name, n := range counts {
+				if err := increment(sess,
+					"UPDATE datasource_usage_source SET queries = queries + ? WHERE org_id = ? AND datasource_uid = ? AND day = ? AND kind = ? AND name = ?",
+					[]any{n, key.orgID, key.uid, key.day, kind, name},
+					&dailySource{OrgID: key.orgID, DataSourceUID: key.uid, Day: key.day, Kind: kind, Name: name, Queries: n},
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.requeue(key, p)
+	}
+	return err
+}
+
+// increment runs the update, and inserts the row if the update did not match any row.
+func increment(sess *db.Session, update string, args []any, row any) error {
+	res, err := sess.Exec(append([]any{update}, args...)...)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		return nil
+	}
+	_, err = sess.Insert(row)
+	return err
+}
+
+func (s *Service) requeue(key usageKey, p *pendingUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.pending[key]
+	if !ok {
+		s.pending[key] = p
+		return
+	}
+	current.queries += p.queries
+	current.errors += p.errors
+	current.durationMs += p.durationMs
+	current.maxDurationMs = max(current.maxDurationMs, p.maxDurationMs)
+	for i, n := range p.latency {
+		current.latency[i] += n
+	}
+	for name, n := range p.dashboards {
+		current.dashboards[name] += n
+	}
+	for name, n := range p.users {
+		current.users[name] += n
+	}
+}
+
+func (s *Service) deleteBefore(ctx context.Context, day string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, table := range []string{"datasource_usage", "datasource_usage_latency", "datasource_usage_source"} {
+			if _, err := sess.Exec("DELETE FROM "+table+" WHERE day < ?", day); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// usage returns the usage of a data source between two days, both included.
+func (s *Service) usage(ctx context.Context, orgID int64, uid string, from, to string) (*Usage, error) {
+	result := &Usage{
+		DataSourceUID: uid,
+		From:          from,
+		To:            to,
+		Daily:         []Day{},
+		TopDashboards: []Top{},
+		TopUsers:      []Top{},
+	}
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		days := make([]*dailyUsage, 0)
+		if err := sess.Where("org_id = ? AND datasource_uid = ? AND day >= ? AND day <= ?", orgID, uid, from, to).
+			Asc("day").Find(&days); err != nil {
+			return err
+		}
+		latencies := make([]*dailyLatency, 0)
+		if err := sess.Where("org_id = ? AND datasource_uid = ? AND day >= ? AND day <= ?", orgID, uid, from, to).
+			Find(&latencies); err != nil {
+			return err
+		}
+
+		total := make([]int64, len(latencyBuckets)+1)
+		perDay := map[string][]int64{}
+		for _, l := range latencies {
+			i := len(latencyBuckets)
+			if l.LeMs > 0 {
+				i = sort.Search(len(latencyBuckets), func(i int) bool { return latencyBuckets[i] >= l.LeMs })
+			}
+			if _, ok := perDay[l.Day]; !ok {
+				perDay[l.Day] = make([]int64, len(latencyBuckets)+1)
+			}
+			perDay[l.Day][i] += l.Queries
+			total[i] += l.Queries
+		}
+
+		for _, d := range days {
+			result.Queries += d.Queries
+			result.Errors += d.Errors
+			result.MaxLatencyMs = max(result.MaxLatencyMs, d.MaxDurationMs)
+			if d.Queries > 0 {
+				result.LastQueriedOn = d.Day
+			}
+			result.Daily = append(result.Daily, Day{
+				Day:          d.Day,
+				Queries:      d.Queries,
+				Errors:       d.Errors,
+				ErrorRate:    errorRate(d.Errors, d.Queries),
+				P95LatencyMs: p95(perDay[d.Day], d.MaxDurationMs),
+			})
+		}
+		result.ErrorRate = errorRate(result.Errors, result.Queries)
+		result.P95LatencyMs = p95(total, result.MaxLatencyMs)
+
+		var err error
+		if result.TopDashboards, err = s.top(sess, orgID, uid, sourceDashboard, from, to); err != nil {
+			return err
+		}
+		result.TopUsers, err = s.top(sess, orgID, uid, sourceUser, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) top(sess *db.Session, orgID int64, uid string, kind string, from, to string) ([]Top, error) {
+	top := make([]Top, 0)
+	err := sess.SQL("SELECT name, SUM(queries) AS queries FROM datasource_usage_source "+
+		"WHERE org_id = ? AND datasource_uid = ? AND kind = ? AND day >= ? AND day <= ? "+
+		"GROUP BY name ORDER BY SUM(queries) DESC, name ASC "+s.store.GetDialect().Limit(int64(s.settings.TopLimit)),
+		orgID, uid, kind, from, to).Find(&top)
+	return top, err
+}
+
+type totals struct {
+	DataSourceUID string `xorm:"datasource_uid"`
+	Queries       int64  `xorm:"queries"`
+	Errors        int64  `xorm:"errors"`
+	LastDay       string `xorm:"last_day"`
+}
+
+// totals returns the number of queries and errors of the data sources of an organization between two days,
+// both included, by data source UID.
+func (s *Service) totals(ctx context.Context, orgID int64, from, to string) (map[string]totals, error) {
+	rows := make([]totals, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT datasource_uid, SUM(queries) AS queries, SUM(errors) AS errors, MAX(day) AS last_day "+
+			"FROM datasource_usage WHERE org_id = ? AND day >= ? AND day <= ? AND queries > 0 GROUP BY datasource_uid",
+			orgID, from, to).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byUID := make(map[string]totals, len(rows))
+	for _, row := range rows {
+		byUID[row.DataSourceUID] = row
+	}
+	return byUID, nil
+}
+
+func (s *Service) listDataSources(ctx context.Context, orgID int64) ([]*datasources.DataSource, error) {
+	dss := make([]*datasources.DataSource, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("data_source").Cols("uid", "name", "type").Where("org_id = ?", orgID).Find(&dss)
+	})
+	return dss, err
+}
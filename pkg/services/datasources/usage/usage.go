@@ -0,0 +1,206 @@
+// Package usage records the query count, error rate and latency of every data source, and the dashboards and
+// users issuing the queries, in daily rollups, so that admins can find unused or overloaded data sources.
+package usage
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const dayFormat = "2006-01-02"
+
+// latencyBuckets are the upper bounds of the latency buckets, in milliseconds. The p95 latency is estimated from
+// the number of queries in each bucket, since percentiles can't be added up across instances and days.
+var latencyBuckets = []int64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// Query is one query sent to a data source.
+type Query struct {
+	OrgID         int64
+	DataSourceUID string
+	// DashboardUID and UserLogin are empty when the query was not issued by a dashboard or a user.
+	DashboardUID string
+	UserLogin    string
+	Duration     time.Duration
+	Failed       bool
+}
+
+// Service collects the usage of the data sources in memory and periodically adds it to the daily rollups.
+type Service struct {
+	store         db.DB
+	settings      setting.DataSourceUsageSettings
+	accessControl ac.AccessControl
+	log           log.Logger
+	now           func() time.Time
+
+	mu      sync.Mutex
+	pending map[usageKey]*pendingUsage
+}
+
+type usageKey struct {
+	orgID int64
+	uid   string
+	day   string
+}
+
+// pendingUsage is the usage of a data source during a day that is not written to the database yet.
+type pendingUsage struct {
+	queries       int64
+	errors        int64
+	durationMs    int64
+	maxDurationMs int64
+	// latency counts the queries per latency bucket, the last one being the queries slower than every bucket.
+	latency    []int64
+	dashboards map[string]int64
+	users      map[string]int64
+}
+
+// ProvideService is a dependency of the plugin client, which records the queries, so it reads the data sources
+// from the database rather than through the data source service.
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl) *Service {
+	s := &Service{
+		store:         sqlStore,
+		settings:      cfg.DataSourceUsage,
+		accessControl: accessControl,
+		log:           log.New("datasources.usage"),
+		now:           time.Now,
+		pending:       make(map[usageKey]*pendingUsage),
+	}
+
+	if s.settings.Enabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+// Enabled returns true if the usage of the data sources is recorded.
+func (s *Service) Enabled() bool {
+	return s != nil && s.settings.Enabled
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Enabled()
+}
+
+// Record adds a query to the usage of its data source. The usage is written to the database on the next flush.
+func (s *Service) Record(q Query) {
+	if !s.Enabled() || q.DataSourceUID == "" {
+		return
+	}
+
+	key := usageKey{orgID: q.OrgID, uid: q.DataSourceUID, day: s.now().UTC().Format(dayFormat)}
+	durationMs := q.Duration.Milliseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[key]
+	if !ok {
+		p = &pendingUsage{
+			latency:    make([]int64, len(latencyBuckets)+1),
+			dashboards: map[string]int64{},
+			users:      map[string]int64{},
+		}
+		s.pending[key] = p
+	}
+	p.queries++
+	if q.Failed {
+		p.errors++
+	}
+	p.durationMs += durationMs
+	if durationMs > p.maxDurationMs {
+		p.maxDurationMs = durationMs
+	}
+	p.latency[sort.Search(len(latencyBuckets), func(i int) bool { return latencyBuckets[i] >= durationMs })]++
+	if q.DashboardUID != "" {
+		p.dashboards[q.DashboardUID]++
+	}
+	if q.UserLogin != "" {
+		p.users[q.UserLogin]++
+	}
+}
+
+// Run periodically writes the collected usage to the database and deletes the rollups older than the retention
+// period, until ctx is done.
+func (s *Service) Run(ctx context.Context) error {
+	flush := time.NewTicker(s.settings.FlushInterval)
+	defer flush.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// write what was collected since the last flush before shutting down
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return nil
+		case <-flush.C:
+			s.flush(ctx)
+		case <-cleanup.C:
+			before := s.now().Add(-s.settings.Retention).UTC().Format(dayFormat)
+			if err := s.deleteBefore(ctx, before); err != nil {
+				s.log.Error("Failed to delete expired data source usage", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]*pendingUsage)
+	s.mu.Unlock()
+
+	for key, p := range pending {
+		if err := s.add(ctx, key, p); err != nil {
+			s.log.Error("Failed to write data source usage", "datasource", key.uid, "orgId", key.orgID, "error", err)
+		}
+	}
+}
+
+// p95 estimates the 95th percentile latency from the number of queries per latency bucket, interpolating linearly
+// within the bucket like histogram_quantile in Prometheus. Queries slower than every bucket are estimated at max.
+func p95(latency []int64, max int64) int64 {
+	var total int64
+	for _, n := range latency {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := 0.95 * float64(total)
+	var seen int64
+	for i, n := range latency {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		if i == len(latencyBuckets) {
+			return max
+		}
+		var lower int64
+		if i > 0 {
+			lower = latencyBuckets[i-1]
+		}
+		estimate := float64(lower) + float64(latencyBuckets[i]-lower)*(rank-float64(seen))/float64(n)
+		return int64(math.Min(math.Round(estimate), float64(max)))
+	}
+	return max
+}
+
+func errorRate(errors, queries int64) float64 {
+	if queries == 0 {
+		return 0
+	}
+	return float64(errors) / float64(queries)
+}
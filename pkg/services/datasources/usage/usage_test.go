@@ -0,0 +1,110 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestP95(t *testing.T) {
+	latency := make([]int64, len(latencyBuckets)+1)
+	require.Equal(t, int64(0), p95(latency, 0))
+
+	// 100 queries between 50ms and 100ms: the 95th is estimated at 95% of the bucket
+	latency[3] = 100
+	require.Equal(t, int64(98), p95(latency, 100))
+	require.Equal(t, int64(90), p95(latency, 90), "the estimate is capped at the slowest query")
+
+	// queries slower than every bucket are estimated at the slowest query
+	latency[len(latencyBuckets)] = 100
+	require.Equal(t, int64(120000), p95(latency, 120000))
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)
+	s := &Service{
+		settings: setting.DataSourceUsageSettings{Enabled: true},
+		now:      func() time.Time { return now },
+		pending:  make(map[usageKey]*pendingUsage),
+	}
+
+	s.Record(Query{OrgID: 1, DataSourceUID: "prom", DashboardUID: "dash", UserLogin: "alice", Duration: 20 * time.Millisecond})
+	s.Record(Query{OrgID: 1, DataSourceUID: "prom", UserLogin: "alice", Duration: 3 * time.Second, Failed: true})
+	s.Record(Query{OrgID: 1, Duration: time.Second})
+
+	require.Len(t, s.pending, 1)
+	p := s.pending[usageKey{orgID: 1, uid: "prom", day: "2024-05-01"}]
+	require.NotNil(t, p)
+	require.Equal(t, int64(2), p.queries)
+	require.Equal(t, int64(1), p.errors)
+	require.Equal(t, int64(3000), p.maxDurationMs)
+	require.Equal(t, int64(1), p.latency[1])
+	require.Equal(t, int64(1), p.latency[8])
+	require.Equal(t, map[string]int64{"dash": 1}, p.dashboards)
+	require.Equal(t, map[string]int64{"alice": 2}, p.users)
+
+	s.settings.Enabled = false
+	s.Record(Query{OrgID: 2, DataSourceUID: "prom"})
+	require.Len(t, s.pending, 1)
+}
+
+func TestIntegrationUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	s := &Service{
+		store:    db.InitTestDB(t),
+		settings: setting.DataSourceUsageSettings{Enabled: true, Retention: 90 * 24 * time.Hour, TopLimit: 1},
+		log:      log.NewNopLogger(),
+		now:      func() time.Time { return now },
+		pending:  make(map[usageKey]*pendingUsage),
+	}
+	ctx := context.Background()
+
+	s.now = func() time.Time { return now.AddDate(0, 0, -1) }
+	s.Record(Query{OrgID: 1, DataSourceUID: "prom", DashboardUID: "dash-a", UserLogin: "alice", Duration: 40 * time.Millisecond})
+	s.flush(ctx)
+
+	s.now = func() time.Time { return now }
+	s.Record(Query{OrgID: 1, DataSourceUID: "prom", DashboardUID: "dash-b", UserLogin: "bob", Duration: 40 * time.Millisecond, Failed: true})
+	s.flush(ctx)
+	// a second flush adds to the rollups of the day
+	s.Record(Query{OrgID: 1, DataSourceUID: "prom", DashboardUID: "dash-b", UserLogin: "bob", Duration: 40 * time.Millisecond})
+	s.Record(Query{OrgID: 2, DataSourceUID: "prom", Duration: 40 * time.Millisecond})
+	s.flush(ctx)
+	require.Empty(t, s.pending)
+
+	usage, err := s.usage(ctx, 1, "prom", "2024-04-01", "2024-05-02")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), usage.Queries)
+	require.Equal(t, int64(1), usage.Errors)
+	require.InDelta(t, 1.0/3, usage.ErrorRate, 0.001)
+	require.Equal(t, int64(40), usage.MaxLatencyMs)
+	require.Equal(t, "2024-05-02", usage.LastQueriedOn)
+	require.Len(t, usage.Daily, 2)
+	require.Equal(t, Day{Day: "2024-05-02", Queries: 2, Errors: 1, ErrorRate: 0.5, P95LatencyMs: 40}, usage.Daily[1])
+	require.Equal(t, []Top{{Name: "dash-b", Queries: 2}}, usage.TopDashboards)
+	require.Equal(t, []Top{{Name: "bob", Queries: 2}}, usage.TopUsers)
+
+	totals, err := s.totals(ctx, 1, "2024-05-02", "2024-05-02")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), totals["prom"].Queries)
+
+	require.NoError(t, s.deleteBefore(ctx, "2024-05-02"))
+	usage, err = s.usage(ctx, 1, "prom", "2024-04-01", "2024-05-02")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage.Queries)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
@@ -24,6 +25,12 @@ type FeatureManager struct {
 	startup  map[string]bool   // the explicit values registered at startup
 	warnings map[string]string // potential warnings about the flag
 	log      log.Logger
+
+	// the rollouts changed at runtime, which take precedence over the startup values
+	overrides atomic.Pointer[map[string]Rollout]
+	// the values of the flags changed by a reload of the configuration, which take precedence over the startup
+	// values but not over the rollouts
+	reloaded atomic.Pointer[map[string]bool]
 }
 
 // This will merge the flags with the current configuration
@@ -101,14 +108,21 @@ func (fm *FeatureManager) update() {
 	fm.enabled = enabled
 }
 
-// IsEnabled checks if a feature is enabled
+// IsEnabled checks if a feature is enabled, for the organization of the requester of the context when the
+// flag is rolled out at runtime
 func (fm *FeatureManager) IsEnabled(ctx context.Context, flag string) bool {
-	return fm.enabled[flag]
+	if r, ok := fm.override(flag); ok {
+		return r.IsEnabledForOrg(flag, orgIDFromContext(ctx))
+	}
+	return fm.configured(flag)
 }
 
 // IsEnabledGlobally checks if a feature is for all tenants
 func (fm *FeatureManager) IsEnabledGlobally(flag string) bool {
-	return fm.enabled[flag]
+	if r, ok := fm.override(flag); ok {
+		return r.Enabled
+	}
+	return fm.configured(flag)
 }
 
 // GetEnabled returns a map containing only the features that are enabled
@@ -119,6 +133,26 @@ func (fm *FeatureManager) GetEnabled(ctx context.Context) map[string]bool {
 			enabled[key] = true
 		}
 	}
+	for key, val := range fm.GetReloaded() {
+		if val {
+			enabled[key] = true
+		} else {
+			delete(enabled, key)
+		}
+	}
+
+	overrides := fm.GetOverrides()
+	if len(overrides) == 0 {
+		return enabled
+	}
+	orgID := orgIDFromContext(ctx)
+	for key, r := range overrides {
+		if r.IsEnabledForOrg(key, orgID) {
+			enabled[key] = true
+		} else {
+			delete(enabled, key)
+		}
+	}
 	return enabled
 }
 
@@ -177,6 +211,11 @@ func (fm *FeatureManager) IsHiddenFromAdminPage(key string, lenient bool) bool {
 		flag.Stage == FeatureStagePrivatePreview
 }
 
+// IsEnabledAtStartup checks if a feature is enabled by the configuration, regardless of the runtime overrides
+func (fm *FeatureManager) IsEnabledAtStartup(flag string) bool {
+	return fm.configured(flag)
+}
+
 // Get the flags that were explicitly set on startup
 func (fm *FeatureManager) GetStartupFlags() map[string]bool {
 	return fm.startup
@@ -221,7 +260,7 @@ func WithManager(spec ...any) *FeatureManager {
 		}
 	}
 
-	return &FeatureManager{enabled: enabled, flags: features, startup: enabled, warnings: map[string]string{}}
+	return &FeatureManager{enabled: enabled, flags: features, startup: enabled, warnings: map[string]string{}, log: log.New("featuremgmt")}
 }
 
 // WithFeatureManager is used to define feature toggle manager for testing.
@@ -251,5 +290,6 @@ func WithFeatureManager(cfg setting.FeatureMgmtSettings, flags []*FeatureFlag, d
 		flags:    features,
 		startup:  enabled,
 		warnings: map[string]string{},
+		log:      log.New("featuremgmt"),
 	}
 }
@@ -5,6 +5,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestFeatureManager(t *testing.T) {
@@ -65,4 +70,105 @@ func TestFeatureManager(t *testing.T) {
 		require.False(t, ft.IsEnabledGlobally("b"))
 		require.False(t, ft.IsEnabledGlobally("c"))
 	})
+	t.Run("check runtime overrides", func(t *testing.T) {
+		ft := WithFeatureManager(setting.FeatureMgmtSettings{
+			ReadOnlyToggles: map[string]struct{}{"readonly": {}},
+		}, []*FeatureFlag{
+			{Name: "a", Reloadable: true},
+			{Name: "c", Reloadable: true},
+			{Name: "restart", RequiresRestart: true},
+			{Name: "readonly", Reloadable: true},
+			{Name: "dev", RequiresDevMode: true, Reloadable: true},
+			{Name: "startup"},
+		}, "a", "c", "restart", "startup")
+		require.ErrorIs(t, ft.CanOverride("unknown"), ErrUnknownFlag)
+		require.ErrorIs(t, ft.CanOverride("restart"), ErrFlagNotOverridable)
+		require.ErrorIs(t, ft.CanOverride("readonly"), ErrFlagNotOverridable)
+		require.ErrorIs(t, ft.CanOverride("dev"), ErrFlagNotOverridable)
+		require.ErrorIs(t, ft.CanOverride("startup"), ErrFlagNotOverridable, "only the flags marked as reloadable can be overridden")
+		require.NoError(t, ft.CanOverride("a"))
+
+		ft.SetOverrides(map[string]Rollout{
+			"a":       {Enabled: true},
+			"c":       {OrgIDs: []int64{2}},
+			"restart": {Enabled: true},
+			"startup": {Enabled: true},
+		})
+		require.Equal(t, map[string]Rollout{"a": {Enabled: true}, "c": {OrgIDs: []int64{2}}}, ft.GetOverrides())
+		require.True(t, ft.IsEnabledGlobally("a"))
+		require.False(t, ft.IsEnabledGlobally("c"))
+		require.False(t, ft.IsEnabledGlobally("restart"))
+		require.False(t, ft.IsEnabledGlobally("startup"))
+		require.True(t, ft.IsEnabled(identity.WithRequester(context.Background(), &user.SignedInUser{OrgID: 2}), "c"))
+		require.False(t, ft.IsEnabled(identity.WithRequester(context.Background(), &user.SignedInUser{OrgID: 3}), "c"))
+		require.False(t, ft.IsEnabledAtStartup("a"))
+
+		ft.SetOverrides(nil)
+		require.False(t, ft.IsEnabledGlobally("a"))
+	})
+
+	t.Run("check reload of the configuration", func(t *testing.T) {
+		ft := WithFeatureManager(setting.FeatureMgmtSettings{}, []*FeatureFlag{
+			{Name: "a", Reloadable: true},
+			{Name: "b", Reloadable: true},
+			{Name: "c", Reloadable: true},
+			{Name: "restart", RequiresRestart: true},
+			{Name: "startup"},
+		}, "b", "restart", "startup")
+		section := func(t *testing.T, config string) setting.Section {
+			cfg := setting.NewCfg()
+			raw, err := ini.Load([]byte(config))
+			require.NoError(t, err)
+			cfg.Raw = raw
+			return (&setting.OSSImpl{Cfg: cfg}).Section("feature_toggles")
+		}
+
+		handler := ft.ReloadHandler()
+		require.Error(t, handler.ValidateSection(section(t, "[feature_toggles]\na = maybe")))
+		require.True(t, handler.IsReloadable("feature_toggles", "enable"))
+		require.True(t, handler.IsReloadable("feature_toggles", "a"))
+		require.False(t, handler.IsReloadable("feature_toggles", "restart"))
+		require.False(t, handler.IsReloadable("feature_toggles", "startup"), "only the flags marked as reloadable are reloaded")
+
+		ft.SetOverrides(map[string]Rollout{"c": {Enabled: true}})
+		require.NoError(t, handler.ReloadSection(section(t, "[feature_toggles]\nenable = b, restart, startup\na = false\nc = false")))
+		require.False(t, ft.IsEnabledGlobally("a"))
+		require.True(t, ft.IsEnabledGlobally("b"))
+		require.True(t, ft.IsEnabledGlobally("c"), "the runtime overrides take precedence")
+		require.False(t, ft.IsEnabledAtStartup("c"))
+		require.False(t, ft.IsEnabledGlobally("restart"), "the flags requiring a restart are not reloaded")
+		require.False(t, ft.IsEnabledGlobally("startup"))
+		require.Equal(t, map[string]bool{"a": false, "b": true, "c": false}, ft.GetReloaded())
+
+		// the flags removed from the configuration get their default value
+		require.NoError(t, handler.ReloadSection(section(t, "[feature_toggles]")))
+		require.False(t, ft.IsEnabledGlobally("b"))
+		require.Equal(t, map[string]bool{"a": false, "c": false}, ft.GetReloaded())
+	})
+
+	t.Run("check percentage rollouts", func(t *testing.T) {
+		require.Error(t, Rollout{Percentage: 101}.Validate())
+		require.Error(t, Rollout{OrgIDs: []int64{0}}.Validate())
+		require.NoError(t, Rollout{Percentage: 50, OrgIDs: []int64{1}}.Validate())
+
+		count := func(r Rollout) int {
+			enabled := 0
+			for orgID := int64(1); orgID <= 1000; orgID++ {
+				if r.IsEnabledForOrg("flag", orgID) {
+					enabled++
+				}
+			}
+			return enabled
+		}
+		require.Equal(t, 0, count(Rollout{}))
+		require.Equal(t, 1000, count(Rollout{Percentage: 100}))
+		require.InDelta(t, 250, count(Rollout{Percentage: 25}), 50)
+
+		// increasing the percentage keeps the flag enabled for the same organizations
+		for orgID := int64(1); orgID <= 1000; orgID++ {
+			if (Rollout{Percentage: 10}).IsEnabledForOrg("flag", orgID) {
+				require.True(t, Rollout{Percentage: 20}.IsEnabledForOrg("flag", orgID))
+			}
+		}
+	})
 }
@@ -130,6 +130,9 @@ type FeatureFlag struct {
 
 	// The server must be initialized with the value
 	RequiresRestart bool `json:"requiresRestart,omitempty"`
+	// The value can be changed at runtime, by a reload of the configuration or a runtime override: the flag is
+	// checked each time it's used, never only at startup. The other flags are applied on the next restart.
+	Reloadable bool `json:"reloadable,omitempty"`
 }
 
 type FeatureToggleWebhookPayload struct {
@@ -32,6 +32,7 @@ var (
 			Description:  "This will use a webworker thread to processes events rather than the main thread",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaAppPlatformSquad,
 		},
 		{
@@ -39,6 +40,7 @@ var (
 			Description:  "Use Grafana Live WebSocket to execute backend queries",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaAppPlatformSquad,
 		},
 		{
@@ -69,6 +71,7 @@ var (
 			Description:  "Enables public dashboard rendering using scenes",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaSharingSquad,
 		},
 		{
@@ -104,6 +107,7 @@ var (
 			Description:  "Migrate old angular panels to supported versions (graph, table-old, worldmap, etc)",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -111,6 +115,7 @@ var (
 			Description:  "Migrate old graph panel to supported time series panel - broken out from autoMigrateOldPanels to enable granular tracking",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -118,6 +123,7 @@ var (
 			Description:  "Migrate old table panel to supported table panel - broken out from autoMigrateOldPanels to enable granular tracking",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -125,6 +131,7 @@ var (
 			Description:  "Migrate old piechart panel to supported piechart panel - broken out from autoMigrateOldPanels to enable granular tracking",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -132,6 +139,7 @@ var (
 			Description:  "Migrate old worldmap panel to supported geomap panel - broken out from autoMigrateOldPanels to enable granular tracking",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -139,6 +147,7 @@ var (
 			Description:  "Migrate old stat panel to supported stat panel - broken out from autoMigrateOldPanels to enable granular tracking",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -146,6 +155,7 @@ var (
 			Description:  "Migrate old XYChart panel to new XYChart2 model",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Expression:   "true", // enabled by default
 			Owner:        grafanaDatavizSquad,
 		},
@@ -154,6 +164,7 @@ var (
 			Description:       "Dynamic flag to disable angular at runtime. The preferred method is to set `angular_support_enabled` to `false` in the [security] settings, which allows you to change the state at runtime.",
 			Stage:             FeatureStagePublicPreview,
 			FrontendOnly:      true,
+			Reloadable:        true,
 			Owner:             grafanaDatavizSquad,
 			HideFromAdminPage: true,
 		},
@@ -162,6 +173,7 @@ var (
 			Description:       "Allow elements nesting",
 			Stage:             FeatureStageExperimental,
 			FrontendOnly:      true,
+			Reloadable:        true,
 			Owner:             grafanaDatavizSquad,
 			HideFromAdminPage: true,
 		},
@@ -244,6 +256,7 @@ var (
 			Name:         "editPanelCSVDragAndDrop",
 			Description:  "Enables drag and drop for CSV and Excel files",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaDatavizSquad,
 		},
@@ -260,6 +273,7 @@ var (
 			Description:    "Allow datasource to provide custom UI for context view",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Owner:          grafanaObservabilityLogsSquad,
 			Expression:     "true", // turned on by default
 			AllowSelfServe: true,
@@ -269,6 +283,7 @@ var (
 			Description:    "Split large interval queries into subqueries with smaller time intervals",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Owner:          grafanaObservabilityLogsSquad,
 			Expression:     "true", // turned on by default
 			AllowSelfServe: true,
@@ -278,6 +293,7 @@ var (
 			Description:  "Give users the option to configure split durations for Loki queries",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityLogsSquad,
 		},
 		{
@@ -292,6 +308,7 @@ var (
 			Expression:     "true",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Owner:          grafanaObservabilityMetricsSquad,
 			AllowSelfServe: true,
 		},
@@ -300,6 +317,7 @@ var (
 			Description:    "Query InfluxDB InfluxQL without the proxy",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Owner:          grafanaObservabilityMetricsSquad,
 			Expression:     "true", // enabled by default
 			AllowSelfServe: false,
@@ -343,6 +361,7 @@ var (
 			Description:    "Support dataplane contract field name change for transformations and field name matchers where the name is different",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Expression:     "true",
 			Owner:          grafanaObservabilityMetricsSquad,
 			AllowSelfServe: true,
@@ -396,12 +415,14 @@ var (
 			Description:  "Enable the data source selector within the Frontend Apps section of the Frontend Observability",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        appO11ySquad,
 		},
 		{
 			Name:         "enableDatagridEditing",
 			Description:  "Enables the edit functionality in the datagrid panel",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStagePublicPreview,
 			Owner:        grafanaDatavizSquad,
 		},
@@ -409,6 +430,7 @@ var (
 			Name:         "extraThemes",
 			Description:  "Enables extra themes",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaFrontendPlatformSquad,
 		},
@@ -416,6 +438,7 @@ var (
 			Name:         "lokiPredefinedOperations",
 			Description:  "Adds predefined query operations to Loki query editor",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaObservabilityLogsSquad,
 		},
@@ -424,6 +447,7 @@ var (
 			Description:  "Enables the plugins frontend sandbox",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaPluginsPlatformSquad,
 		},
 		{
@@ -431,6 +455,7 @@ var (
 			Description:  "Enables monitor only in the plugin frontend sandbox (if enabled)",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaPluginsPlatformSquad,
 		},
 		{
@@ -438,12 +463,14 @@ var (
 			Description:  "Enables right panel for the plugins details page",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaPluginsPlatformSquad,
 		},
 		{
 			Name:              "sqlDatasourceDatabaseSelection",
 			Description:       "Enables previous SQL data source dataset dropdown behavior",
 			FrontendOnly:      true,
+			Reloadable:        true,
 			Stage:             FeatureStagePublicPreview,
 			Owner:             grafanaDatavizSquad,
 			HideFromAdminPage: true,
@@ -461,6 +488,7 @@ var (
 			Description:  "Split panels between visualizations and widgets",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 		},
 		{
@@ -469,6 +497,7 @@ var (
 			Stage:        FeatureStageGeneralAvailability,
 			Expression:   "true", // enabled by default,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityLogsSquad,
 		},
 		{
@@ -482,6 +511,7 @@ var (
 			Description:    "Enables the transformations redesign",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Expression:     "true", // enabled by default
 			Owner:          grafanaObservabilityMetricsSquad,
 			AllowSelfServe: true,
@@ -498,6 +528,7 @@ var (
 			Description:  "Enables response streaming of TraceQL queries of the Tempo data source",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityTracesAndProfilingSquad,
 			Expression:   "false",
 		},
@@ -506,6 +537,7 @@ var (
 			Description:  "Enables metrics summary queries in the Tempo data source",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityTracesAndProfilingSquad,
 		},
 		{
@@ -577,6 +609,7 @@ var (
 			Description:  "Display Angular warnings in dashboards and panels",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaPluginsPlatformSquad,
 			Expression:   "true", // Enabled by default
 		},
@@ -585,6 +618,7 @@ var (
 			Description:  "Enable AI powered features in dashboards",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 			Expression:   "true", // enabled by default
 		},
@@ -593,6 +627,7 @@ var (
 			Description:  "Enable AI powered features for dashboards to auto-summary changes when saving",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 		},
 		{
@@ -635,6 +670,7 @@ var (
 			Name:              "alertingInsights",
 			Description:       "Show the new alerting insights landing page",
 			FrontendOnly:      true,
+			Reloadable:        true,
 			Stage:             FeatureStageGeneralAvailability,
 			Owner:             grafanaAlertingSquad,
 			Expression:        "true", // enabled by default
@@ -651,6 +687,7 @@ var (
 			Name:         "pluginsAPIMetrics",
 			Description:  "Sends metrics of public grafana packages usage by plugins",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaPluginsPlatformSquad,
 		},
@@ -668,6 +705,7 @@ var (
 			Expression:   "true", // enabled by default
 			Owner:        grafanaDatavizSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:              "enableNativeHTTPHistogram",
@@ -694,6 +732,7 @@ var (
 			Description:  "Enable format string transformer",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 			Expression:   "true", // enabled by default
 		},
@@ -701,6 +740,7 @@ var (
 			Name:         "transformationsVariableSupport",
 			Description:  "Allows using variables in transformations",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageGeneralAvailability,
 			Owner:        grafanaDatavizSquad,
 			Expression:   "true", // Enabled by default
@@ -726,6 +766,7 @@ var (
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaAppPlatformSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:            "datasourceQueryTypes",
@@ -753,7 +794,8 @@ var (
 			Description:  "Routes requests to the new query service",
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaAppPlatformSquad,
-			FrontendOnly: true, // and can change at startup
+			FrontendOnly: true,
+			Reloadable:   true, // and can change at startup
 		},
 		{
 			Name:        "cloudWatchBatchQueries",
@@ -812,12 +854,14 @@ var (
 			Description:  "Prometheus and AI/ML to assist users in creating a query",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityMetricsSquad,
 		},
 		{
 			Name:         "prometheusCodeModeMetricNamesSearch",
 			Description:  "Enables search for metric names in Code Mode, to improve performance when working with an enormous number of metric names",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaObservabilityMetricsSquad,
 		},
@@ -826,6 +870,7 @@ var (
 			Description:  "Add cumulative and window functions to the add field from calculation transformation",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 			Expression:   "true", // enabled by default
 		},
@@ -860,6 +905,7 @@ var (
 			Description:  "Make sure extracted field names are unique in the dataframe",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -867,6 +913,7 @@ var (
 			Description:  "Enables dashboard rendering using Scenes for viewer roles",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 		},
 		{
@@ -874,6 +921,7 @@ var (
 			Description:  "Enables rendering dashboards using scenes for solo panels",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 		},
 		{
@@ -881,6 +929,7 @@ var (
 			Description:  "Enables dashboard rendering using scenes for all roles",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 		},
 		{
@@ -888,6 +937,7 @@ var (
 			Description:  "Enables use of the `systemPanelFilterVar` variable to filter panels in a dashboard",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 			HideFromDocs: true,
 		},
@@ -912,6 +962,7 @@ var (
 			Description:  "Allow pan and zoom in canvas panel",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -920,6 +971,7 @@ var (
 			Stage:        FeatureStageGeneralAvailability,
 			Expression:   "true",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityLogsSquad,
 		},
 		{
@@ -928,6 +980,7 @@ var (
 			Stage:        FeatureStageGeneralAvailability,
 			Expression:   "true", // enabled by default
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDashboardsSquad,
 		},
 		{
@@ -943,6 +996,7 @@ var (
 			Description:  "Enable filtering menu displayed when text of a log line is selected",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Expression:   "true",
 			Owner:        grafanaObservabilityLogsSquad,
 		},
@@ -957,6 +1011,7 @@ var (
 			Name:         "tableSharedCrosshair",
 			Description:  "Enables shared crosshair in table panel",
 			FrontendOnly: true,
+			Reloadable:   true,
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaDatavizSquad,
 		},
@@ -965,6 +1020,7 @@ var (
 			Description:  "Enables regression analysis transformation",
 			Stage:        FeatureStagePublicPreview,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 		},
 		{
@@ -973,6 +1029,7 @@ var (
 			Description:    "Enables query hints for Loki",
 			Stage:          FeatureStageGeneralAvailability,
 			FrontendOnly:   true,
+			Reloadable:     true,
 			Expression:     "true",
 			Owner:          grafanaObservabilityLogsSquad,
 			AllowSelfServe: false,
@@ -982,6 +1039,7 @@ var (
 			Description:       "Use the kubernetes API for feature toggle management in the frontend",
 			Stage:             FeatureStageExperimental,
 			FrontendOnly:      true,
+			Reloadable:        true,
 			Owner:             grafanaOperatorExperienceSquad,
 			AllowSelfServe:    false,
 			HideFromAdminPage: true,
@@ -1010,6 +1068,7 @@ var (
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaFrontendPlatformSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:              "jitterAlertRulesWithinGroups",
@@ -1054,6 +1113,7 @@ var (
 			Description:  "Changed the layout algorithm for the node graph",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaObservabilityTracesAndProfilingSquad,
 		},
 		{
@@ -1061,6 +1121,7 @@ var (
 			Description:  "Enables the group to nested table transformation",
 			Stage:        FeatureStageGeneralAvailability,
 			FrontendOnly: true,
+			Reloadable:   true,
 			Owner:        grafanaDatavizSquad,
 			Expression:   "true", // enabled by default,
 		},
@@ -1190,6 +1251,7 @@ var (
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaObservabilityLogsSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:         "newDashboardSharingComponent",
@@ -1197,6 +1259,7 @@ var (
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaSharingSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:         "alertingListViewV2",
@@ -1204,6 +1267,7 @@ var (
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaAlertingSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:         "notificationBanner",
@@ -1250,6 +1314,7 @@ var (
 			Stage:        FeatureStageExperimental,
 			Owner:        grafanaAlertingSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:        "pluginProxyPreserveTrailingSlash",
@@ -1353,6 +1418,7 @@ var (
 			Owner:             grafanaFrontendPlatformSquad,
 			Expression:        "false", // enabled by default
 			FrontendOnly:      true,
+			Reloadable:        true,
 			AllowSelfServe:    true,
 			HideFromDocs:      true,
 			HideFromAdminPage: false,
@@ -1363,6 +1429,7 @@ var (
 			Stage:        FeatureStagePublicPreview,
 			Owner:        awsDatasourcesSquad,
 			FrontendOnly: true,
+			Reloadable:   true,
 		},
 		{
 			Name:        "prometheusAzureOverrideAudience",
@@ -0,0 +1,121 @@
+package featuremgmt
+
+import (
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ReloadHandler returns the handler reloading the [feature_toggles] section of the configuration. Only the flags
+// which don't require a restart are reloaded.
+func (fm *FeatureManager) ReloadHandler() setting.PartialReloadHandler {
+	return &reloadHandler{fm: fm}
+}
+
+// GetReloaded returns the values of the flags changed by a reload of the configuration.
+func (fm *FeatureManager) GetReloaded() map[string]bool {
+	reloaded := fm.reloaded.Load()
+	if reloaded == nil {
+		return map[string]bool{}
+	}
+	return *reloaded
+}
+
+// configured returns the value of a flag in the configuration, reloaded or read at startup.
+func (fm *FeatureManager) configured(flag string) bool {
+	if reloaded := fm.reloaded.Load(); reloaded != nil {
+		if enabled, ok := (*reloaded)[flag]; ok {
+			return enabled
+		}
+	}
+	return fm.enabled[flag]
+}
+
+// isReloadable returns true if the value of a flag in the configuration can be reloaded: only the flags marked as
+// reloadable in the registry are.
+func (fm *FeatureManager) isReloadable(flag *FeatureFlag) bool {
+	if !flag.Reloadable || flag.RequiresRestart || flag.Name == FlagFeatureToggleAdminPage {
+		return false
+	}
+	ok, _ := fm.meetsRequirements(flag)
+	return ok
+}
+
+// reload applies the values of the reloadable flags in the configuration. The flags which aren't in the
+// configuration get their default value.
+func (fm *FeatureManager) reload(toggles map[string]bool) {
+	overrides := fm.GetOverrides()
+	reloaded := make(map[string]bool)
+	for name, flag := range fm.flags {
+		if !fm.isReloadable(flag) {
+			continue
+		}
+		enabled, ok := toggles[name]
+		if !ok {
+			enabled = flag.Expression == "true"
+		}
+		if enabled != fm.enabled[name] {
+			reloaded[name] = enabled
+		}
+		if enabled != fm.configured(name) {
+			fm.log.Info("Reloaded feature flag", "flag", name, "enabled", enabled)
+		}
+		if _, overridden := overrides[name]; !overridden {
+			fm.trackEnabled(name, enabled)
+		}
+	}
+	for name, enabled := range toggles {
+		if flag, ok := fm.flags[name]; ok && !fm.isReloadable(flag) && enabled != fm.configured(name) {
+			fm.log.Warn("Feature flag changed in the configuration requires a restart", "flag", name, "enabled", enabled)
+		}
+	}
+	fm.reloaded.Store(&reloaded)
+}
+
+type reloadHandler struct {
+	fm *FeatureManager
+}
+
+func (h *reloadHandler) ValidateSection(section setting.Section) error {
+	_, err := readToggles(section)
+	return err
+}
+
+func (h *reloadHandler) ReloadSection(section setting.Section) error {
+	toggles, err := readToggles(section)
+	if err != nil {
+		return err
+	}
+	h.fm.reload(toggles)
+	return nil
+}
+
+// IsReloadable returns false for the flags which aren't reloadable. The list of enabled flags is reloadable, the
+// flags it lists which require a restart are not reloaded.
+func (h *reloadHandler) IsReloadable(_, key string) bool {
+	if key == "enable" {
+		return true
+	}
+	flag, ok := h.fm.flags[key]
+	return ok && h.fm.isReloadable(flag)
+}
+
+// readToggles reads the flags of the [feature_toggles] section like setting.ReadFeatureTogglesFromInitFile.
+func readToggles(section setting.Section) (map[string]bool, error) {
+	toggles := make(map[string]bool)
+	for _, name := range util.SplitString(section.KeyValue("enable").Value()) {
+		toggles[name] = true
+	}
+	for _, key := range section.Keys() {
+		if key == "enable" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(section.KeyValue(key).Value())
+		if err != nil {
+			return nil, err
+		}
+		toggles[key] = enabled
+	}
+	return toggles, nil
+}
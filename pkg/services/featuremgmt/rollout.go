@@ -0,0 +1,144 @@
+package featuremgmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+var (
+	ErrUnknownFlag          = errors.New("unknown feature flag")
+	ErrFlagNotOverridable   = errors.New("feature flag cannot be changed at runtime")
+	ErrInvalidRolloutConfig = errors.New("invalid rollout")
+)
+
+// Rollout overrides the state of a flag at runtime, without restarting Grafana. A flag is enabled for an
+// organization when it is enabled for all of them, when the organization is listed, or when the organization
+// falls in the rolled out percentage. A rollout that enables the flag for none of them disables it.
+type Rollout struct {
+	Enabled bool `json:"enabled"`
+	// OrgIDs are the organizations the flag is enabled for.
+	OrgIDs []int64 `json:"orgIds,omitempty"`
+	// Percentage of the organizations the flag is enabled for, from 0 to 100. The organizations are selected
+	// by a hash of their ID and the flag name, so that increasing the percentage keeps the flag enabled for
+	// the organizations it was enabled for.
+	Percentage int `json:"percentage,omitempty"`
+}
+
+func (r Rollout) Validate() error {
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidRolloutConfig)
+	}
+	for _, id := range r.OrgIDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: invalid organization id %d", ErrInvalidRolloutConfig, id)
+		}
+	}
+	return nil
+}
+
+// IsEnabledForOrg returns true if the rollout enables the flag for an organization.
+func (r Rollout) IsEnabledForOrg(flag string, orgID int64) bool {
+	if r.Enabled {
+		return true
+	}
+	if orgID <= 0 {
+		return false
+	}
+	for _, id := range r.OrgIDs {
+		if id == orgID {
+			return true
+		}
+	}
+	return r.Percentage > 0 && rolloutBucket(flag, orgID) < r.Percentage
+}
+
+// rolloutBucket returns the bucket of an organization for a flag, from 0 to 99.
+func rolloutBucket(flag string, orgID int64) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flag + ":" + strconv.FormatInt(orgID, 10)))
+	return int(h.Sum32() % 100)
+}
+
+// CanOverride returns an error if a flag cannot be changed at runtime. Only the flags marked as reloadable,
+// whose code checks them on each use, can be changed: not the ones that require a restart, that are configured
+// as read only, or whose requirements Grafana doesn't meet.
+func (fm *FeatureManager) CanOverride(name string) error {
+	flag, ok := fm.flags[name]
+	if !ok {
+		return ErrUnknownFlag
+	}
+	if !flag.Reloadable {
+		return fmt.Errorf("%w: not reloadable", ErrFlagNotOverridable)
+	}
+	if _, readOnly := fm.Settings.ReadOnlyToggles[name]; readOnly || flag.RequiresRestart || name == FlagFeatureToggleAdminPage {
+		return ErrFlagNotOverridable
+	}
+	if ok, reason := fm.meetsRequirements(flag); !ok {
+		return fmt.Errorf("%w: %s", ErrFlagNotOverridable, reason)
+	}
+	return nil
+}
+
+// SetOverrides replaces the runtime overrides of the flags. The flags that cannot be overridden are ignored.
+func (fm *FeatureManager) SetOverrides(overrides map[string]Rollout) {
+	valid := make(map[string]Rollout, len(overrides))
+	for name, r := range overrides {
+		if err := fm.CanOverride(name); err != nil {
+			fm.log.Warn("Ignoring feature flag override", "flag", name, "error", err)
+			continue
+		}
+		valid[name] = r
+	}
+
+	previous := fm.GetOverrides()
+	fm.overrides.Store(&valid)
+
+	// update the metric of the flags whose global state changed
+	for name := range previous {
+		if _, ok := valid[name]; !ok {
+			fm.trackEnabled(name, fm.configured(name))
+		}
+	}
+	for name, r := range valid {
+		fm.trackEnabled(name, r.Enabled)
+	}
+}
+
+// GetOverrides returns the runtime overrides of the flags.
+func (fm *FeatureManager) GetOverrides() map[string]Rollout {
+	overrides := fm.overrides.Load()
+	if overrides == nil {
+		return map[string]Rollout{}
+	}
+	return *overrides
+}
+
+func (fm *FeatureManager) override(flag string) (Rollout, bool) {
+	overrides := fm.overrides.Load()
+	if overrides == nil {
+		return Rollout{}, false
+	}
+	r, ok := (*overrides)[flag]
+	return r, ok
+}
+
+func (fm *FeatureManager) trackEnabled(name string, enabled bool) {
+	track := 0.0
+	if enabled {
+		track = 1
+	}
+	featureToggleInfo.WithLabelValues(name).Set(track)
+}
+
+func orgIDFromContext(ctx context.Context) int64 {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil || requester == nil {
+		return 0
+	}
+	return requester.GetOrgID()
+}
@@ -190,6 +190,9 @@ func verifyFlagsConfiguration(t *testing.T) {
 		if flag.AllowSelfServe && !(flag.Stage == FeatureStageGeneralAvailability || flag.Stage == FeatureStagePublicPreview || flag.Stage == FeatureStageDeprecated) {
 			t.Errorf("only allow self-serving GA, PublicPreview and Deprecated toggles")
 		}
+		if flag.Reloadable && flag.RequiresRestart {
+			t.Errorf("flags requiring a restart can not be reloadable.  See: %s", flag.Name)
+		}
 		if flag.Owner == "" {
 			t.Errorf("feature %s does not have an owner. please fill the FeatureFlag.Owner property", flag.Name)
 		}
@@ -0,0 +1,96 @@
+package featureoverrides
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	canRead := authorize(ac.EvalPermission(ac.ActionFeatureManagementRead))
+	canWrite := authorize(ac.EvalPermission(ac.ActionFeatureManagementWrite))
+
+	// the flags are shared by all the organizations, so only Grafana admins can change them
+	routeRegister.Group("/api/featuremgmt", func(featureRoute routing.RouteRegister) {
+		featureRoute.Get("/overrides", canRead, routing.Wrap(s.listHandler))
+		featureRoute.Put("/overrides/:name", middleware.ReqGrafanaAdmin, canWrite, routing.Wrap(s.setHandler))
+		featureRoute.Delete("/overrides/:name", middleware.ReqGrafanaAdmin, canWrite, routing.Wrap(s.deleteHandler))
+		featureRoute.Get("/changes", canRead, routing.Wrap(s.changesHandler))
+	}, middleware.ReqSignedIn)
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	overrides, err := s.list(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list feature flag overrides", err)
+	}
+	for _, o := range overrides {
+		o.StartupEnabled = s.features.IsEnabledAtStartup(o.Name)
+	}
+	return response.JSON(http.StatusOK, overrides)
+}
+
+func (s *Service) setHandler(c *contextmodel.ReqContext) response.Response {
+	name := web.Params(c.Req)[":name"]
+	rollout := featuremgmt.Rollout{}
+	if err := web.Bind(c.Req, &rollout); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := rollout.Validate(); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	if err := s.features.CanOverride(name); err != nil {
+		if errors.Is(err, featuremgmt.ErrUnknownFlag) {
+			return response.Error(http.StatusNotFound, "Feature flag not found", err)
+		}
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	override, err := s.set(c.Req.Context(), name, rollout, c.SignedInUser)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save feature flag override", err)
+	}
+	s.log.Info("Feature flag overridden", "flag", name, "enabled", rollout.Enabled, "orgIds", rollout.OrgIDs, "percentage", rollout.Percentage, "user", c.SignedInUser.GetLogin())
+
+	// apply the change on this instance right away, the others apply it on their next reload
+	if err := s.reload(c.Req.Context()); err != nil {
+		s.log.Warn("Failed to reload the feature flag overrides", "error", err)
+	}
+	override.StartupEnabled = s.features.IsEnabledAtStartup(name)
+	return response.JSON(http.StatusOK, override)
+}
+
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	name := web.Params(c.Req)[":name"]
+	if err := s.delete(c.Req.Context(), name, c.SignedInUser); err != nil {
+		if errors.Is(err, ErrOverrideNotFound) {
+			return response.Error(http.StatusNotFound, "Feature flag override not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete feature flag override", err)
+	}
+	s.log.Info("Feature flag override deleted", "flag", name, "user", c.SignedInUser.GetLogin())
+
+	if err := s.reload(c.Req.Context()); err != nil {
+		s.log.Warn("Failed to reload the feature flag overrides", "error", err)
+	}
+	return response.Success("Feature flag override deleted")
+}
+
+func (s *Service) changesHandler(c *contextmodel.ReqContext) response.Response {
+	changes, err := s.changes(c.Req.Context(), ChangesQuery{
+		Name:  c.Query("name"),
+		Limit: c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list feature flag changes", err)
+	}
+	return response.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,83 @@
+// Package featureoverrides lets Grafana admins change the state of feature flags at runtime, for all
+// organizations, a list of them or a percentage of them, without editing the configuration and restarting
+// Grafana. The overrides are stored in the database, applied by every instance, and their changes are kept
+// for auditing.
+package featureoverrides
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Service struct {
+	store         db.DB
+	features      *featuremgmt.FeatureManager
+	accessControl ac.AccessControl
+	settings      setting.FeatureMgmtSettings
+	log           log.Logger
+	now           func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, features *featuremgmt.FeatureManager, routeRegister routing.RouteRegister, accessControl ac.AccessControl) *Service {
+	s := &Service{
+		store:         sqlStore,
+		features:      features,
+		accessControl: accessControl,
+		settings:      cfg.FeatureManagement,
+		log:           log.New("featureoverrides"),
+		now:           time.Now,
+	}
+
+	if s.settings.RuntimeOverrides {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.RuntimeOverrides
+}
+
+// Run applies the overrides stored in the database, and reloads them periodically to apply the changes made
+// on the other instances.
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.settings.RuntimeOverridesSyncInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.reload(ctx); err != nil {
+			s.log.Error("Failed to load the feature flag overrides", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) reload(ctx context.Context) error {
+	overrides, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	rollouts := make(map[string]featuremgmt.Rollout, len(overrides))
+	for _, o := range overrides {
+		rollouts[o.Name] = o.Rollout()
+	}
+	s.features.SetOverrides(rollouts)
+	return nil
+}
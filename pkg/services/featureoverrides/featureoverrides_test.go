@@ -0,0 +1,91 @@
+package featureoverrides
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationFeatureOverrides(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	features := featuremgmt.WithFeatureManager(setting.FeatureMgmtSettings{}, []*featuremgmt.FeatureFlag{
+		{Name: "a", Reloadable: true},
+		{Name: "b", Reloadable: true},
+	}, "a")
+	s := &Service{
+		store:    db.InitTestDB(t),
+		features: features,
+		settings: setting.FeatureMgmtSettings{RuntimeOverrides: true},
+		log:      log.NewNopLogger(),
+		now:      func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	admin := &user.SignedInUser{UserUID: "admin-uid", Login: "admin", OrgID: 1}
+	orgCtx := func(orgID int64) context.Context {
+		return identity.WithRequester(context.Background(), &user.SignedInUser{OrgID: orgID})
+	}
+	ctx := context.Background()
+
+	override, err := s.set(ctx, "a", featuremgmt.Rollout{OrgIDs: []int64{2}}, admin)
+	require.NoError(t, err)
+	require.Equal(t, "admin", override.UpdatedBy)
+	_, err = s.set(ctx, "b", featuremgmt.Rollout{Enabled: false}, admin)
+	require.NoError(t, err)
+	require.NoError(t, s.reload(ctx))
+
+	require.False(t, features.IsEnabled(orgCtx(1), "a"))
+	require.True(t, features.IsEnabled(orgCtx(2), "a"))
+	require.False(t, features.IsEnabledGlobally("b"))
+
+	// updating an override keeps a single override for the flag
+	_, err = s.set(ctx, "a", featuremgmt.Rollout{Enabled: true}, admin)
+	require.NoError(t, err)
+	overrides, err := s.list(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 2)
+	require.Equal(t, "a", overrides[0].Name)
+	require.True(t, overrides[0].Enabled)
+	require.Empty(t, overrides[0].OrgIDs)
+
+	// deleting an override restores the state configured at startup
+	require.NoError(t, s.delete(ctx, "b", admin))
+	require.ErrorIs(t, s.delete(ctx, "b", admin), ErrOverrideNotFound)
+	require.NoError(t, s.reload(ctx))
+	require.True(t, features.IsEnabledGlobally("a"))
+	require.True(t, features.IsEnabledGlobally("b"))
+
+	changes, err := s.changes(ctx, ChangesQuery{Name: "a"})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, ActionSet, changes[0].Action)
+	require.JSONEq(t, `{"enabled":false,"orgIds":[2]}`, changes[0].PreviousRollout)
+	require.JSONEq(t, `{"enabled":true}`, changes[0].Rollout)
+	require.Equal(t, "admin-uid", changes[0].UserUID)
+	require.Empty(t, changes[1].PreviousRollout)
+
+	changes, err = s.changes(ctx, ChangesQuery{Name: "b"})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, ActionDelete, changes[0].Action)
+	require.Empty(t, changes[0].Rollout)
+
+	changes, err = s.changes(ctx, ChangesQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
@@ -0,0 +1,59 @@
+package featureoverrides
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+var ErrOverrideNotFound = errors.New("feature flag override not found")
+
+const (
+	ActionSet    = "set"
+	ActionDelete = "delete"
+)
+
+// Override is the rollout of a feature flag changed at runtime.
+type Override struct {
+	ID         int64   `xorm:"pk autoincr 'id'" json:"-"`
+	Name       string  `xorm:"name" json:"name"`
+	Enabled    bool    `xorm:"enabled" json:"enabled"`
+	OrgIDs     []int64 `xorm:"org_ids" json:"orgIds"`
+	Percentage int     `xorm:"percentage" json:"percentage"`
+	Updated    int64   `xorm:"updated" json:"updated"`
+	UpdatedBy  string  `xorm:"updated_by" json:"updatedBy"`
+	// StartupEnabled is the state of the flag configured at startup, which applies again once the
+	// override is deleted.
+	StartupEnabled bool `xorm:"-" json:"startupEnabled"`
+}
+
+func (Override) TableName() string {
+	return "feature_toggle_override"
+}
+
+func (o *Override) Rollout() featuremgmt.Rollout {
+	return featuremgmt.Rollout{Enabled: o.Enabled, OrgIDs: o.OrgIDs, Percentage: o.Percentage}
+}
+
+// Change records a change of the override of a flag, for auditing.
+type Change struct {
+	ID     int64  `xorm:"pk autoincr 'id'" json:"id"`
+	Name   string `xorm:"name" json:"name"`
+	Action string `xorm:"action" json:"action"`
+	// PreviousRollout and Rollout are the JSON of the rollouts before and after the change, empty when
+	// there is no override.
+	PreviousRollout string `xorm:"previous_rollout" json:"previousRollout,omitempty"`
+	Rollout         string `xorm:"rollout" json:"rollout,omitempty"`
+	UserUID         string `xorm:"user_uid" json:"userUid"`
+	UserLogin       string `xorm:"user_login" json:"userLogin"`
+	Created         int64  `xorm:"created" json:"created"`
+}
+
+func (Change) TableName() string {
+	return "feature_toggle_change"
+}
+
+type ChangesQuery struct {
+	Name  string
+	Limit int
+}
@@ -0,0 +1,117 @@
+package featureoverrides
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+const defaultChangesLimit = 100
+
+func (s *Service) list(ctx context.Context) ([]*Override, error) {
+	overrides := make([]*Override, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("name").Find(&overrides)
+	})
+	return overrides, err
+}
+
+// set creates or updates the override of a flag and records the change.
+func (s *Service) set(ctx context.Context, name string, rollout featuremgmt.Rollout, user identity.Requester) (*Override, error) {
+	override := &Override{}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("name = ?", name).Get(override)
+		if err != nil {
+			return err
+		}
+		change := s.newChange(name, ActionSet, user)
+		if exists {
+			change.PreviousRollout = encodeRollout(override.Rollout())
+		}
+
+		override.Name = name
+		override.Enabled = rollout.Enabled
+		override.OrgIDs = rollout.OrgIDs
+		if override.OrgIDs == nil {
+			override.OrgIDs = []int64{}
+		}
+		override.Percentage = rollout.Percentage
+		override.Updated = s.now().Unix()
+		override.UpdatedBy = user.GetLogin()
+		if exists {
+			_, err = sess.ID(override.ID).AllCols().Update(override)
+		} else {
+			_, err = sess.Insert(override)
+		}
+		if err != nil {
+			return err
+		}
+
+		change.Rollout = encodeRollout(override.Rollout())
+		_, err = sess.Insert(change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// delete removes the override of a flag and records the change.
+func (s *Service) delete(ctx context.Context, name string, user identity.Requester) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		override := &Override{}
+		exists, err := sess.Where("name = ?", name).Get(override)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrOverrideNotFound
+		}
+		if _, err := sess.ID(override.ID).Delete(&Override{}); err != nil {
+			return err
+		}
+
+		change := s.newChange(name, ActionDelete, user)
+		change.PreviousRollout = encodeRollout(override.Rollout())
+		_, err = sess.Insert(change)
+		return err
+	})
+}
+
+func (s *Service) changes(ctx context.Context, query ChangesQuery) ([]*Change, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultChangesLimit
+	}
+
+	changes := make([]*Change, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("feature_toggle_change")
+		if query.Name != "" {
+			q = q.Where("name = ?", query.Name)
+		}
+		return q.Desc("created", "id").Limit(query.Limit).Find(&changes)
+	})
+	return changes, err
+}
+
+func (s *Service) newChange(name, action string, user identity.Requester) *Change {
+	return &Change{
+		Name:      name,
+		Action:    action,
+		UserUID:   user.GetUID(),
+		UserLogin: user.GetLogin(),
+		Created:   s.now().Unix(),
+	}
+}
+
+func encodeRollout(r featuremgmt.Rollout) string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
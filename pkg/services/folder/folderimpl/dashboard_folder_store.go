@@ -11,7 +11,7 @@ import (
 )
 
 // DashboardStore implements the FolderStore interface
-// It fetches folders from the dashboard DB table
+// It fetches folders from the dashboard DB table, ignoring the folders in the trash
 type DashboardFolderStoreImpl struct {
 	store db.DB
 }
@@ -31,7 +31,7 @@ func (d *DashboardFolderStoreImpl) GetFolderByTitle(ctx context.Context, orgID i
 	// nolint:staticcheck
 	dashboard := dashboards.Dashboard{OrgID: orgID, FolderID: 0, Title: title}
 	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
-		s := sess.Table(&dashboards.Dashboard{}).Where("is_folder = " + d.store.GetDialect().BooleanStr(true))
+		s := sess.Table(&dashboards.Dashboard{}).Where("is_folder = " + d.store.GetDialect().BooleanStr(true) + " AND deleted IS NULL")
 		if folderUID != nil {
 			s = s.Where("folder_uid = ?", *folderUID)
 		} else {
@@ -56,7 +56,7 @@ func (d *DashboardFolderStoreImpl) GetFolderByID(ctx context.Context, orgID int6
 	// nolint:staticcheck
 	dashboard := dashboards.Dashboard{OrgID: orgID, FolderID: 0, ID: id}
 	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
-		has, err := sess.Table(&dashboards.Dashboard{}).Where("is_folder = " + d.store.GetDialect().BooleanStr(true)).Get(&dashboard)
+		has, err := sess.Table(&dashboards.Dashboard{}).Where("is_folder = " + d.store.GetDialect().BooleanStr(true) + " AND deleted IS NULL").Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -81,7 +81,7 @@ func (d *DashboardFolderStoreImpl) GetFolderByUID(ctx context.Context, orgID int
 	// nolint:staticcheck
 	dashboard := dashboards.Dashboard{OrgID: orgID, FolderID: 0, UID: uid}
 	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
-		has, err := sess.Table(&dashboards.Dashboard{}).Where("is_folder = " + d.store.GetDialect().BooleanStr(true)).Get(&dashboard)
+		has, err := sess.Table(&dashboards.Dashboard{}).Where("is_folder = " + d.store.GetDialect().BooleanStr(true) + " AND deleted IS NULL").Get(&dashboard)
 		if err != nil {
 			return err
 		}
@@ -111,7 +111,7 @@ func (d *DashboardFolderStoreImpl) GetFolders(ctx context.Context, orgID int64,
 		b := strings.Builder{}
 		args := make([]any, 0, len(uids)+1)
 
-		b.WriteString("SELECT * FROM dashboard WHERE org_id=? AND is_folder = " + d.store.GetDialect().BooleanStr(true))
+		b.WriteString("SELECT * FROM dashboard WHERE org_id=? AND is_folder = " + d.store.GetDialect().BooleanStr(true) + " AND deleted IS NULL")
 		args = append(args, orgID)
 
 		if len(uids) == 1 {
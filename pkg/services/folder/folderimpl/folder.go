@@ -535,6 +535,13 @@ func (s *Service) GetParents(ctx context.Context, q folder.GetParentsQuery) ([]*
 	return s.store.GetParents(ctx, q)
 }
 
+func (s *Service) GetDescendants(ctx context.Context, q folder.GetDescendantsQuery) ([]*folder.Folder, error) {
+	if !s.features.IsEnabled(ctx, featuremgmt.FlagNestedFolders) || q.UID == accesscontrol.GeneralFolderUID {
+		return nil, nil
+	}
+	return s.store.GetDescendants(ctx, q.OrgID, q.UID)
+}
+
 func (s *Service) getFolderByID(ctx context.Context, id int64, orgID int64) (*folder.Folder, error) {
 	if id == 0 {
 		return &folder.GeneralFolder, nil
@@ -817,14 +824,32 @@ func (s *Service) Delete(ctx context.Context, cmd *folder.DeleteFolderCommand) e
 	}
 
 	folders := []string{cmd.UID}
+	softDelete := s.features.IsEnabledGlobally(featuremgmt.FlagDashboardRestore)
 	err = s.db.InTransaction(ctx, func(ctx context.Context) error {
-		descendants, err := s.nestedFolderDelete(ctx, cmd)
+		deleted, err := s.nestedFolderDelete(ctx, cmd)
 
 		if err != nil {
 			s.log.ErrorContext(ctx, "the delete folder on folder table failed with err: ", "error", err)
 			return err
 		}
-		folders = append(folders, descendants...)
+		for _, f := range deleted[1:] {
+			folders = append(folders, f.UID)
+		}
+
+		// the folders and their dashboards are moved to the trash first, so that they all have the same
+		// deletion time and are restored together. The other resources of the folders can't be moved to the
+		// trash, so the folders can't be restored once they are deleted.
+		if softDelete {
+			var unrestorable string
+			if cmd.ForceDeleteRules {
+				if unrestorable, err = s.unrestorableChildren(ctx, cmd.OrgID, folders, cmd.SignedInUser); err != nil {
+					return err
+				}
+			}
+			if err := s.dashboardStore.SoftDeleteFolders(ctx, cmd.OrgID, deleted, unrestorable); err != nil {
+				return toFolderError(err)
+			}
+		}
 
 		if cmd.ForceDeleteRules {
 			if err := s.deleteChildrenInFolder(ctx, cmd.OrgID, folders, cmd.SignedInUser); err != nil {
@@ -845,6 +870,11 @@ func (s *Service) Delete(ctx context.Context, cmd *folder.DeleteFolderCommand) e
 			}
 		}
 
+		// the permissions of the folders in the trash are kept to restore them
+		if softDelete {
+			return nil
+		}
+
 		if err = s.legacyDelete(ctx, cmd, folders); err != nil {
 			return err
 		}
@@ -864,6 +894,36 @@ func (s *Service) deleteChildrenInFolder(ctx context.Context, orgID int64, folde
 	return nil
 }
 
+// unrestorableChildren describes the children of the folders which are deleted permanently when the folders are
+// moved to the trash, such as their alert rules and library panels. It is empty if there are none.
+func (s *Service) unrestorableChildren(ctx context.Context, orgID int64, folderUIDs []string, user identity.Requester) (string, error) {
+	names := map[string]string{
+		entity.StandardKindAlertRule:    "alert rules",
+		entity.StandardKindLibraryPanel: "library panels",
+	}
+	var children []string
+	for kind, v := range s.registry {
+		// the dashboards are moved to the trash with their folders
+		if kind == entity.StandardKindDashboard {
+			continue
+		}
+		count, err := v.CountInFolders(ctx, orgID, folderUIDs, user)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			continue
+		}
+		name, ok := names[kind]
+		if !ok {
+			name = kind
+		}
+		children = append(children, fmt.Sprintf("%d %s", count, name))
+	}
+	slices.Sort(children)
+	return strings.Join(children, ", "), nil
+}
+
 func (s *Service) legacyDelete(ctx context.Context, cmd *folder.DeleteFolderCommand, folderUIDs []string) error {
 	// TODO use bulk delete
 	for _, folderUID := range folderUIDs {
@@ -876,6 +936,132 @@ func (s *Service) legacyDelete(ctx context.Context, cmd *folder.DeleteFolderComm
 	return nil
 }
 
+func (s *Service) Restore(ctx context.Context, cmd *folder.RestoreFolderCommand) (*folder.Folder, error) {
+	if cmd.SignedInUser == nil {
+		return nil, folder.ErrBadRequest.Errorf("missing signed in user")
+	}
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagDashboardRestore) {
+		return nil, folder.ErrBadRequest.Errorf("feature flag %s is not enabled", featuremgmt.FlagDashboardRestore)
+	}
+
+	deleted, err := s.dashboardStore.GetSoftDeletedDashboard(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, dashboards.ErrFolderNotFound
+		}
+		return nil, toFolderError(err)
+	}
+	if !deleted.IsFolder {
+		return nil, dashboards.ErrFolderNotFound
+	}
+
+	// the folder is restored in its original parent folder unless another one is requested,
+	// which must exist and be accessible to the user
+	var parent *folder.Folder
+	parentUID := deleted.FolderUID
+	if cmd.ParentUID != nil {
+		parentUID = *cmd.ParentUID
+	}
+	if parentUID != "" {
+		if !s.features.IsEnabled(ctx, featuremgmt.FlagNestedFolders) {
+			return nil, folder.ErrBadRequest.Errorf("folders can only be restored at the root level without nested folders")
+		}
+		parent, err = s.Get(ctx, &folder.GetFolderQuery{UID: &parentUID, OrgID: cmd.OrgID, SignedInUser: cmd.SignedInUser})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrFolderNotFound) {
+				return nil, dashboards.ErrFolderRestoreNotFound
+			}
+			return nil, err
+		}
+	}
+
+	if err := checkRestorable(deleted); err != nil {
+		return nil, err
+	}
+
+	// the subfolders and dashboards deleted with the folder have the same deletion time, the ones deleted
+	// before it were deleted on their own and stay in the trash
+	folders := []*dashboards.Dashboard{deleted}
+	dashes := []*dashboards.Dashboard{}
+	for level := []string{deleted.UID}; len(level) > 0; {
+		children, err := s.dashboardStore.GetSoftDeletedDashboards(ctx, &dashboards.GetSoftDeletedDashboardsQuery{
+			OrgID:        cmd.OrgID,
+			FolderUIDs:   level,
+			DeletedSince: deleted.Deleted,
+		})
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		level = nil
+		for _, child := range children {
+			if child.IsFolder {
+				if err := checkRestorable(child); err != nil {
+					return nil, err
+				}
+				folders = append(folders, child)
+				level = append(level, child.UID)
+			} else {
+				dashes = append(dashes, child)
+			}
+		}
+	}
+
+	s.log.InfoContext(ctx, "restoring folder and its descendants", "org_id", cmd.OrgID, "uid", cmd.UID, "folders", len(folders), "dashboards", len(dashes))
+	err = s.db.InTransaction(ctx, func(ctx context.Context) error {
+		// the folders are ordered from the top, so the parent of each folder is restored before it
+		restored := make(map[string]*folder.Folder, len(folders))
+		for _, f := range folders {
+			restoringParent := parent
+			if f.UID != deleted.UID {
+				restoringParent = restored[f.FolderUID]
+			}
+			if err := s.dashboardStore.RestoreDashboard(ctx, cmd.OrgID, f.UID, restoringParent); err != nil {
+				return toFolderError(err)
+			}
+
+			// the description was kept in the data of the folder when it was deleted
+			createCmd := &folder.CreateFolderCommand{UID: f.UID, OrgID: cmd.OrgID, Title: f.Title}
+			if f.Data != nil {
+				createCmd.Description = f.Data.Get("description").MustString()
+			}
+			if restoringParent != nil {
+				createCmd.ParentUID = restoringParent.UID
+			}
+			if _, err := s.nestedFolderCreate(ctx, createCmd); err != nil {
+				if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+					return folder.ErrConflict.Errorf("%w", dashboards.ErrFolderSameNameExists)
+				}
+				return err
+			}
+			// nolint:staticcheck
+			restored[f.UID] = &folder.Folder{ID: f.ID, UID: f.UID}
+		}
+
+		for _, d := range dashes {
+			if err := s.dashboardStore.RestoreDashboard(ctx, cmd.OrgID, d.UID, restored[d.FolderUID]); err != nil {
+				return toFolderError(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, &folder.GetFolderQuery{UID: &cmd.UID, OrgID: cmd.OrgID, SignedInUser: cmd.SignedInUser})
+}
+
+// checkRestorable returns an error if the folder in the trash can't be restored.
+func checkRestorable(f *dashboards.Dashboard) error {
+	if f.Data == nil {
+		return nil
+	}
+	if reason := f.Data.Get(dashboards.UnrestorableDataKey).MustString(); reason != "" {
+		return fmt.Errorf("%w: %s were deleted permanently with folder %s", dashboards.ErrFolderNotRestorable, reason, f.UID)
+	}
+	return nil
+}
+
 func (s *Service) Move(ctx context.Context, cmd *folder.MoveFolderCommand) (*folder.Folder, error) {
 	ctx, span := s.tracer.Start(ctx, "folder.Move")
 	defer span.End()
@@ -1067,39 +1253,39 @@ func (s *Service) getFolderAndParentUIDScopes(ctx context.Context, folderUID str
 
 // nestedFolderDelete inspects the folder referenced by the cmd argument, deletes all the entries for
 // its descendant folders (folders which are nested within it either directly or indirectly) from
-// the folder store and returns the UIDs for all its descendants.
-func (s *Service) nestedFolderDelete(ctx context.Context, cmd *folder.DeleteFolderCommand) ([]string, error) {
-	descendantUIDs := []string{}
+// the folder store and returns the folder followed by all its descendants.
+func (s *Service) nestedFolderDelete(ctx context.Context, cmd *folder.DeleteFolderCommand) ([]*folder.Folder, error) {
 	if cmd.SignedInUser == nil {
-		return descendantUIDs, folder.ErrBadRequest.Errorf("missing signed in user")
+		return nil, folder.ErrBadRequest.Errorf("missing signed in user")
 	}
 
-	_, err := s.Get(ctx, &folder.GetFolderQuery{
+	f, err := s.Get(ctx, &folder.GetFolderQuery{
 		UID:          &cmd.UID,
 		OrgID:        cmd.OrgID,
 		SignedInUser: cmd.SignedInUser,
 	})
 	if err != nil {
-		return descendantUIDs, err
+		return nil, err
 	}
 
 	descendants, err := s.store.GetDescendants(ctx, cmd.OrgID, cmd.UID)
 	if err != nil {
 		s.log.ErrorContext(ctx, "failed to get descendant folders", "error", err)
-		return descendantUIDs, err
+		return nil, err
 	}
 
-	for _, f := range descendants {
-		descendantUIDs = append(descendantUIDs, f.UID)
+	descendantUIDs := []string{}
+	for _, descendant := range descendants {
+		descendantUIDs = append(descendantUIDs, descendant.UID)
 	}
 	s.log.InfoContext(ctx, "deleting folder and its descendants", "org_id", cmd.OrgID, "uid", cmd.UID)
 	toDelete := append(descendantUIDs, cmd.UID)
 	err = s.store.Delete(ctx, toDelete, cmd.OrgID)
 	if err != nil {
 		s.log.InfoContext(ctx, "failed deleting folder", "org_id", cmd.OrgID, "uid", cmd.UID, "err", err)
-		return descendantUIDs, err
+		return nil, err
 	}
-	return descendantUIDs, nil
+	return append([]*folder.Folder{f}, descendants...), nil
 }
 
 func (s *Service) GetDescendantCounts(ctx context.Context, q *folder.GetDescendantCountsQuery) (folder.DescendantCounts, error) {
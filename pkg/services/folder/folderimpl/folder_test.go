@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
@@ -281,14 +282,14 @@ func TestIntegrationFolderService(t *testing.T) {
 				require.Equal(t, expectedForceDeleteRules, actualCmd.ForceDeleteFolderRules)
 			})
 
-			t.Run("When deleting folder by uid, expectedForceDeleteRules as false, and dashboard Restore turned on should not return access denied error", func(t *testing.T) {
+			t.Run("When deleting folder by uid, expectedForceDeleteRules as false, and dashboard Restore turned on should move the folder to the trash", func(t *testing.T) {
 				f := folder.NewFolder(util.GenerateShortUID(), "")
 				f.UID = util.GenerateShortUID()
 				folderStore.On("GetFolderByUID", mock.Anything, orgID, f.UID).Return(f, nil)
 
-				var actualCmd *dashboards.DeleteDashboardCommand
-				dashStore.On("DeleteDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
-					actualCmd = args.Get(1).(*dashboards.DeleteDashboardCommand)
+				var trashed []*folder.Folder
+				dashStore.On("SoftDeleteFolders", mock.Anything, orgID, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					trashed = args.Get(2).([]*folder.Folder)
 				}).Return(nil).Once()
 				service.features = featuremgmt.WithFeatures(featuremgmt.FlagDashboardRestore)
 
@@ -300,19 +301,19 @@ func TestIntegrationFolderService(t *testing.T) {
 					SignedInUser:     usr,
 				})
 				require.NoError(t, err)
-				require.NotNil(t, actualCmd)
-				require.Equal(t, orgID, actualCmd.OrgID)
-				require.Equal(t, expectedForceDeleteRules, actualCmd.ForceDeleteFolderRules)
+				require.Len(t, trashed, 1)
+				require.Equal(t, f.UID, trashed[0].UID)
+				dashStore.AssertNotCalled(t, "DeleteDashboard", mock.Anything, &dashboards.DeleteDashboardCommand{OrgID: orgID, UID: f.UID, ForceDeleteFolderRules: expectedForceDeleteRules})
 			})
 
-			t.Run("When deleting folder by uid, expectedForceDeleteRules as true, and dashboard Restore turned on should not return access denied error", func(t *testing.T) {
+			t.Run("When deleting folder by uid, expectedForceDeleteRules as true, and dashboard Restore turned on should move the folder to the trash", func(t *testing.T) {
 				f := folder.NewFolder(util.GenerateShortUID(), "")
 				f.UID = util.GenerateShortUID()
 				folderStore.On("GetFolderByUID", mock.Anything, orgID, f.UID).Return(f, nil)
 
-				var actualCmd *dashboards.DeleteDashboardCommand
-				dashStore.On("DeleteDashboard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
-					actualCmd = args.Get(1).(*dashboards.DeleteDashboardCommand)
+				var trashed []*folder.Folder
+				dashStore.On("SoftDeleteFolders", mock.Anything, orgID, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					trashed = args.Get(2).([]*folder.Folder)
 				}).Return(nil).Once()
 				service.features = featuremgmt.WithFeatures(featuremgmt.FlagDashboardRestore)
 
@@ -324,9 +325,9 @@ func TestIntegrationFolderService(t *testing.T) {
 					SignedInUser:     usr,
 				})
 				require.NoError(t, err)
-				require.NotNil(t, actualCmd)
-				require.Equal(t, orgID, actualCmd.OrgID)
-				require.Equal(t, expectedForceDeleteRules, actualCmd.ForceDeleteFolderRules)
+				require.Len(t, trashed, 1)
+				require.Equal(t, f.UID, trashed[0].UID)
+				dashStore.AssertNotCalled(t, "DeleteDashboard", mock.Anything, &dashboards.DeleteDashboardCommand{OrgID: orgID, UID: f.UID, ForceDeleteFolderRules: expectedForceDeleteRules})
 			})
 
 			t.Cleanup(func() {
@@ -491,7 +492,7 @@ func TestIntegrationNestedFolderService(t *testing.T) {
 			alertStore, err := ngstore.ProvideDBStore(cfg, featuresFlagOn, db, serviceWithFlagOn, dashSrv, ac)
 			require.NoError(t, err)
 
-			elementService := libraryelements.ProvideService(cfg, db, routeRegister, serviceWithFlagOn, featuresFlagOn, ac)
+			elementService := libraryelements.ProvideService(cfg, db, routeRegister, serviceWithFlagOn, dashSrv, featuresFlagOn, ac)
 			lps, err := librarypanels.ProvideService(cfg, db, routeRegister, elementService, serviceWithFlagOn)
 			require.NoError(t, err)
 
@@ -573,7 +574,7 @@ func TestIntegrationNestedFolderService(t *testing.T) {
 			alertStore, err := ngstore.ProvideDBStore(cfg, featuresFlagOff, db, serviceWithFlagOff, dashSrv, ac)
 			require.NoError(t, err)
 
-			elementService := libraryelements.ProvideService(cfg, db, routeRegister, serviceWithFlagOff, featuresFlagOff, ac)
+			elementService := libraryelements.ProvideService(cfg, db, routeRegister, serviceWithFlagOff, dashSrv, featuresFlagOff, ac)
 			lps, err := librarypanels.ProvideService(cfg, db, routeRegister, elementService, serviceWithFlagOff)
 			require.NoError(t, err)
 
@@ -702,10 +703,6 @@ func TestIntegrationNestedFolderService(t *testing.T) {
 					CanEditValue: true,
 				})
 
-				elementService := libraryelements.ProvideService(cfg, db, routeRegister, tc.service, tc.featuresFlag, ac)
-				lps, err := librarypanels.ProvideService(cfg, db, routeRegister, elementService, tc.service)
-				require.NoError(t, err)
-
 				dashStore, err := database.ProvideDashboardStore(db, cfg, tc.featuresFlag, tagimpl.ProvideService(db), quotaService)
 				require.NoError(t, err)
 				nestedFolderStore := ProvideStore(db)
@@ -714,6 +711,11 @@ func TestIntegrationNestedFolderService(t *testing.T) {
 
 				dashSrv, err := dashboardservice.ProvideDashboardServiceImpl(cfg, dashStore, folderStore, tc.featuresFlag, folderPermissions, dashboardPermissions, ac, tc.service, nil)
 				require.NoError(t, err)
+
+				elementService := libraryelements.ProvideService(cfg, db, routeRegister, tc.service, dashSrv, tc.featuresFlag, ac)
+				lps, err := librarypanels.ProvideService(cfg, db, routeRegister, elementService, tc.service)
+				require.NoError(t, err)
+
 				alertStore, err := ngstore.ProvideDBStore(cfg, tc.featuresFlag, db, tc.service, dashSrv, ac)
 				require.NoError(t, err)
 
@@ -784,6 +786,125 @@ func TestIntegrationNestedFolderService(t *testing.T) {
 	})
 }
 
+func TestIntegrationFolderTrash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	db, cfg := sqlstore.InitTestReplDB(t)
+	features := featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders, featuremgmt.FlagDashboardRestore)
+	dashStore, err := database.ProvideDashboardStore(db, cfg, features, tagimpl.ProvideService(db), quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	service := &Service{
+		log:                  slog.New(logtest.NewTestHandler(t)).With("logger", "test-folder-service"),
+		dashboardStore:       dashStore,
+		dashboardFolderStore: ProvideDashboardFolderStore(db),
+		store:                ProvideStore(db),
+		features:             features,
+		bus:                  bus.ProvideBus(tracing.InitializeTracerForTest()),
+		db:                   db,
+		accessControl:        acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()),
+		registry:             make(map[string]folder.RegistryService),
+		metrics:              newFoldersMetrics(nil),
+		tracer:               tracing.InitializeTracerForTest(),
+	}
+
+	origNewGuardian := guardian.New
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true, CanViewValue: true})
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: orgID, Permissions: map[int64]map[string][]string{
+		orgID: {
+			dashboards.ActionFoldersCreate: {dashboards.ScopeFoldersAll},
+			dashboards.ActionFoldersRead:   {dashboards.ScopeFoldersAll},
+			dashboards.ActionFoldersWrite:  {dashboards.ScopeFoldersAll},
+		},
+	}}
+	ancestors := CreateSubtreeInStore(t, service.store, service, 2, "trash", folder.CreateFolderCommand{Description: "team folder"})
+	parent, err := service.dashboardFolderStore.GetFolderByUID(context.Background(), orgID, ancestors[0].UID)
+	require.NoError(t, err)
+	subfolder, err := service.dashboardFolderStore.GetFolderByUID(context.Background(), orgID, ancestors[1].UID)
+	require.NoError(t, err)
+	// nolint:staticcheck
+	parentDash := insertTestDashboard(t, dashStore, "dashboard in parent", orgID, parent.ID, parent.UID, "prod")
+	// nolint:staticcheck
+	subDash := insertTestDashboard(t, dashStore, "dashboard in subfolder", orgID, subfolder.ID, subfolder.UID, "prod")
+
+	err = service.Delete(context.Background(), &folder.DeleteFolderCommand{
+		UID:              parent.UID,
+		OrgID:            orgID,
+		ForceDeleteRules: true,
+		SignedInUser:     signedInUser,
+	})
+	require.NoError(t, err)
+
+	_, err = service.Get(context.Background(), &folder.GetFolderQuery{UID: &subfolder.UID, OrgID: orgID, SignedInUser: signedInUser})
+	require.Error(t, err)
+	trash, err := dashStore.GetSoftDeletedDashboards(context.Background(), &dashboards.GetSoftDeletedDashboardsQuery{OrgID: orgID})
+	require.NoError(t, err)
+	require.Len(t, trash, 4, "the folders and their dashboards are in the trash")
+
+	_, err = service.Restore(context.Background(), &folder.RestoreFolderCommand{UID: subfolder.UID, OrgID: orgID, SignedInUser: signedInUser})
+	require.ErrorIs(t, err, dashboards.ErrFolderRestoreNotFound, "a subfolder cannot be restored in its deleted parent")
+
+	restored, err := service.Restore(context.Background(), &folder.RestoreFolderCommand{UID: parent.UID, OrgID: orgID, SignedInUser: signedInUser})
+	require.NoError(t, err)
+	require.Equal(t, parent.UID, restored.UID)
+
+	restoredSubfolder, err := service.store.Get(context.Background(), folder.GetFolderQuery{UID: &subfolder.UID, OrgID: orgID})
+	require.NoError(t, err)
+	require.Equal(t, parent.UID, restoredSubfolder.ParentUID)
+	require.Equal(t, "team folder", restoredSubfolder.Description)
+
+	for _, dash := range []*dashboards.Dashboard{parentDash, subDash} {
+		restoredDash, err := dashStore.GetDashboard(context.Background(), &dashboards.GetDashboardQuery{UID: dash.UID, OrgID: orgID})
+		require.NoError(t, err)
+		require.Equal(t, dash.FolderUID, restoredDash.FolderUID)
+	}
+	trash, err = dashStore.GetSoftDeletedDashboards(context.Background(), &dashboards.GetSoftDeletedDashboardsQuery{OrgID: orgID})
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	t.Run("folders deleted with their alert rules can't be restored", func(t *testing.T) {
+		rules := &fakeRegistryService{kind: entity.StandardKindAlertRule, count: 2}
+		require.NoError(t, service.RegisterService(rules))
+		t.Cleanup(func() { delete(service.registry, rules.kind) })
+
+		err := service.Delete(context.Background(), &folder.DeleteFolderCommand{
+			UID:              parent.UID,
+			OrgID:            orgID,
+			ForceDeleteRules: true,
+			SignedInUser:     signedInUser,
+		})
+		require.NoError(t, err)
+		require.Zero(t, rules.count, "the alert rules are deleted")
+
+		_, err = service.Restore(context.Background(), &folder.RestoreFolderCommand{UID: parent.UID, OrgID: orgID, SignedInUser: signedInUser})
+		require.ErrorIs(t, err, dashboards.ErrFolderNotRestorable)
+		require.ErrorContains(t, err, "2 alert rules")
+	})
+}
+
+type fakeRegistryService struct {
+	kind  string
+	count int64
+}
+
+func (f *fakeRegistryService) DeleteInFolders(context.Context, int64, []string, identity.Requester) error {
+	f.count = 0
+	return nil
+}
+
+func (f *fakeRegistryService) CountInFolders(context.Context, int64, []string, identity.Requester) (int64, error) {
+	return f.count, nil
+}
+
+func (f *fakeRegistryService) Kind() string {
+	return f.kind
+}
+
 func TestNestedFolderServiceFeatureToggle(t *testing.T) {
 	g := guardian.New
 	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
@@ -27,6 +27,10 @@ func (s *FakeService) GetParents(ctx context.Context, q folder.GetParentsQuery)
 	return s.ExpectedFolders, s.ExpectedError
 }
 
+func (s *FakeService) GetDescendants(ctx context.Context, q folder.GetDescendantsQuery) ([]*folder.Folder, error) {
+	return s.ExpectedFolders, s.ExpectedError
+}
+
 func (s *FakeService) Create(ctx context.Context, cmd *folder.CreateFolderCommand) (*folder.Folder, error) {
 	return s.ExpectedFolder, s.ExpectedError
 }
@@ -40,6 +44,10 @@ func (s *FakeService) Delete(ctx context.Context, cmd *folder.DeleteFolderComman
 	return s.ExpectedError
 }
 
+func (s *FakeService) Restore(ctx context.Context, cmd *folder.RestoreFolderCommand) (*folder.Folder, error) {
+	return s.ExpectedFolder, s.ExpectedError
+}
+
 func (s *FakeService) Move(ctx context.Context, cmd *folder.MoveFolderCommand) (*folder.Folder, error) {
 	return s.ExpectedFolder, s.ExpectedError
 }
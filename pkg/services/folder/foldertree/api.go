@@ -0,0 +1,81 @@
+package foldertree
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	routeRegister.Group("/api/folders", func(folderRoute routing.RouteRegister) {
+		folderRoute.Get("/tree/operations/:operationId", routing.Wrap(s.getOperationHandler))
+		folderRoute.Post("/:uid/tree/move", authorize(ac.EvalPermission(dashboards.ActionFoldersWrite, uidScope)), routing.Wrap(s.moveTreeHandler))
+		folderRoute.Post("/:uid/tree/permissions", authorize(ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite, uidScope)), routing.Wrap(s.applyPermissionsHandler))
+	}, middleware.ReqSignedIn)
+}
+
+func (s *Service) moveTreeHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := MoveTreeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	result, err := s.MoveTree(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to move folder tree")
+	}
+	if len(result.Conflicts) > 0 && !cmd.DryRun {
+		return response.JSON(http.StatusConflict, result)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) applyPermissionsHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ApplyPermissionsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	op, err := s.ApplyPermissions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to apply folder tree permissions")
+	}
+	if cmd.DryRun {
+		return response.JSON(http.StatusOK, op)
+	}
+	return response.JSON(http.StatusAccepted, op)
+}
+
+func (s *Service) getOperationHandler(c *contextmodel.ReqContext) response.Response {
+	op, err := s.GetOperation(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":operationId"])
+	if err != nil {
+		return errorResponse(err, "Failed to get folder tree operation")
+	}
+	return response.JSON(http.StatusOK, op)
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrInvalidPermissions):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrOperationNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrTooManyOperations):
+		return response.Error(http.StatusTooManyRequests, err.Error(), err)
+	case errors.Is(err, dashboards.ErrFolderNotFound), errors.Is(err, folder.ErrFolderNotFound):
+		return response.Error(http.StatusNotFound, "Folder not found", err)
+	case errors.Is(err, dashboards.ErrFolderAccessDenied):
+		return response.Error(http.StatusForbidden, "Access denied", err)
+	default:
+		return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+	}
+}
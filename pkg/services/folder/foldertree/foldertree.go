@@ -0,0 +1,374 @@
+// Package foldertree runs operations on whole folder trees: moving a folder with all of its descendants under a
+// new parent, and applying a permission set to a folder and all of its descendant folders. Operations report the
+// folders that conflict with the requested change. The permissions are applied in batches of folders, each in its
+// own transaction, and the progress of the operations is shared with the other instances through the remote cache.
+package foldertree
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// maxRunningOperations is the number of permission operations that can run at the same time on an instance.
+	maxRunningOperations = 10
+	// operationRetention is how long the operations are kept in the remote cache for their progress to be read.
+	operationRetention = time.Hour
+	// permissionBatchSize is the number of folders of which the permissions are applied in a transaction.
+	permissionBatchSize = 100
+)
+
+// folderPermissionNames are the permissions that can be set on folders, the empty permission removes the permission of
+// a principal.
+var folderPermissionNames = map[string]bool{"": true, "View": true, "Edit": true, "Admin": true}
+
+// Service runs operations on folder trees.
+type Service struct {
+	store             db.DB
+	folderService     folder.Service
+	folderPermissions ac.FolderPermissionsService
+	accessControl     ac.AccessControl
+	cache             remotecache.CacheStorage
+	log               log.Logger
+	now               func() time.Time
+
+	mu sync.Mutex
+	// operations are the operations running on this instance, the other ones are read from the remote cache.
+	operations map[string]*Operation
+}
+
+// sharedOperation is an operation in the remote cache, shared with the other instances.
+type sharedOperation struct {
+	CreatedBy string     `json:"createdBy"`
+	Operation *Operation `json:"operation"`
+}
+
+func ProvideService(sqlStore db.DB, folderService folder.Service, folderPermissions ac.FolderPermissionsService,
+	accessControl ac.AccessControl, cache remotecache.CacheStorage, routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		store:             sqlStore,
+		folderService:     folderService,
+		folderPermissions: folderPermissions,
+		accessControl:     accessControl,
+		cache:             cache,
+		log:               log.New("folder.tree"),
+		now:               time.Now,
+		operations:        make(map[string]*Operation),
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// MoveTree moves a folder and all of its descendants under a new parent. The folder service checks that the user
+// can move the folder, the depth of the tree and circular references.
+func (s *Service) MoveTree(ctx context.Context, user identity.Requester, uid string, cmd MoveTreeCommand) (*MoveTreeResult, error) {
+	f, err := s.folderService.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.folderService.GetDescendantCounts(ctx, &folder.GetDescendantCountsQuery{UID: &uid, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil {
+		return nil, err
+	}
+	result := &MoveTreeResult{Folder: f, Descendants: counts, Conflicts: make([]*Conflict, 0)}
+
+	if cmd.ParentUID != "" {
+		if _, err := s.folderService.Get(ctx, &folder.GetFolderQuery{UID: &cmd.ParentUID, OrgID: user.GetOrgID(), SignedInUser: user}); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := s.folderService.Get(ctx, &folder.GetFolderQuery{Title: &f.Title, ParentUID: &cmd.ParentUID, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil && !errors.Is(err, dashboards.ErrFolderNotFound) && !errors.Is(err, folder.ErrFolderNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.UID != f.UID {
+		result.Conflicts = append(result.Conflicts, &Conflict{FolderUID: f.UID, Title: f.Title, Reason: ConflictTitleExists})
+	}
+	if cmd.DryRun || len(result.Conflicts) > 0 {
+		return result, nil
+	}
+
+	moved, err := s.folderService.Move(ctx, &folder.MoveFolderCommand{UID: uid, NewParentUID: cmd.ParentUID, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil {
+		return nil, err
+	}
+	result.Folder = moved
+	result.Moved = true
+	s.log.Info("Moved folder tree", "orgId", user.GetOrgID(), "uid", uid, "parentUid", cmd.ParentUID, "descendants", counts)
+	return result, nil
+}
+
+// ApplyPermissions applies a permission set to a folder and all of its descendant folders. A dry run returns the
+// finished operation with the conflicts. Otherwise the permissions are applied in the background, in batches of
+// folders, and the progress of the returned operation can be read with GetOperation on any instance.
+func (s *Service) ApplyPermissions(ctx context.Context, user identity.Requester, uid string, cmd ApplyPermissionsCommand) (*Operation, error) {
+	if err := validatePermissions(cmd.Permissions); err != nil {
+		return nil, err
+	}
+	root, err := s.folderService.Get(ctx, &folder.GetFolderQuery{UID: &uid, OrgID: user.GetOrgID(), SignedInUser: user})
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := s.folderService.GetDescendants(ctx, folder.GetDescendantsQuery{UID: uid, OrgID: user.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+	folders := append([]*folder.Folder{root}, descendants...)
+
+	op := &Operation{
+		ID:        util.GenerateShortUID(),
+		OrgID:     user.GetOrgID(),
+		FolderUID: uid,
+		Status:    StatusRunning,
+		DryRun:    cmd.DryRun,
+		Total:     len(folders),
+		Conflicts: make([]*Conflict, 0),
+		Started:   s.now(),
+		createdBy: user.GetUID(),
+	}
+	if cmd.DryRun {
+		s.applyPermissions(ctx, user, op, folders, cmd)
+		return s.snapshot(op), nil
+	}
+	if err := s.track(op); err != nil {
+		return nil, err
+	}
+	s.share(ctx, op)
+	// the operation outlives the request
+	go s.applyPermissions(context.WithoutCancel(ctx), user, op, folders, cmd)
+	return s.snapshot(op), nil
+}
+
+// GetOperation returns the progress of an operation started by the user. The operations running on other instances
+// and the finished ones are read from the remote cache.
+func (s *Service) GetOperation(ctx context.Context, user identity.Requester, id string) (*Operation, error) {
+	s.mu.Lock()
+	op, ok := s.operations[id]
+	s.mu.Unlock()
+	if ok {
+		if op.OrgID != user.GetOrgID() || op.createdBy != user.GetUID() {
+			return nil, ErrOperationNotFound
+		}
+		return s.snapshot(op), nil
+	}
+
+	value, err := s.cache.Get(ctx, operationCacheKey(user.GetOrgID(), id))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("failed to get folder tree operation: %w", err)
+	}
+	shared := &sharedOperation{}
+	if err := json.Unmarshal(value, shared); err != nil {
+		return nil, fmt.Errorf("failed to decode folder tree operation: %w", err)
+	}
+	if shared.Operation == nil || shared.CreatedBy != user.GetUID() {
+		return nil, ErrOperationNotFound
+	}
+	shared.Operation.OrgID = user.GetOrgID()
+	shared.Operation.createdBy = shared.CreatedBy
+	return shared.Operation, nil
+}
+
+// track adds an operation to the operations running on this instance.
+func (s *Service) track(op *Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.operations) >= maxRunningOperations {
+		return ErrTooManyOperations
+	}
+	s.operations[op.ID] = op
+	return nil
+}
+
+// share writes the progress of an operation to the remote cache, for the other instances to read it.
+func (s *Service) share(ctx context.Context, op *Operation) {
+	c := s.snapshot(op)
+	value, err := json.Marshal(sharedOperation{CreatedBy: c.createdBy, Operation: c})
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, operationCacheKey(c.OrgID, c.ID), value, operationRetention); err != nil {
+		s.log.Warn("Failed to share folder tree operation", "operation", c.ID, "error", err)
+	}
+}
+
+// applyPermissions applies the permissions to the folders in batches of permissionBatchSize folders, each in its
+// own transaction, and records the progress and the conflicts in the operation once a batch is committed. The
+// batches committed before a failing one stay applied.
+func (s *Service) applyPermissions(ctx context.Context, user identity.Requester, op *Operation, folders []*folder.Folder, cmd ApplyPermissionsCommand) {
+	var err error
+	for start := 0; start < len(folders) && err == nil; start += permissionBatchSize {
+		batch := folders[start:min(start+permissionBatchSize, len(folders))]
+		var conflicts []*Conflict
+		err = s.store.InTransaction(ctx, func(ctx context.Context) error {
+			conflicts = conflicts[:0]
+			for _, f := range batch {
+				c, commands, err := s.planFolder(ctx, user, f, cmd)
+				if err != nil {
+					return fmt.Errorf("failed to plan the permissions of folder %q: %w", f.UID, err)
+				}
+				if !cmd.DryRun && len(commands) > 0 {
+					if _, err := s.folderPermissions.SetPermissions(ctx, user.GetOrgID(), f.UID, commands...); err != nil {
+						return fmt.Errorf("failed to set the permissions of folder %q: %w", f.UID, err)
+					}
+				}
+				conflicts = append(conflicts, c...)
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		s.mu.Lock()
+		op.Conflicts = append(op.Conflicts, conflicts...)
+		op.Done += len(batch)
+		s.mu.Unlock()
+		if !cmd.DryRun {
+			s.share(ctx, op)
+		}
+	}
+
+	s.mu.Lock()
+	finished := s.now()
+	op.Finished = &finished
+	op.Status = StatusDone
+	if err != nil {
+		op.Status = StatusFailed
+		op.Error = err.Error()
+	}
+	s.mu.Unlock()
+	if cmd.DryRun {
+		return
+	}
+
+	// the finished operation is read from the remote cache by every instance
+	s.share(ctx, op)
+	s.mu.Lock()
+	delete(s.operations, op.ID)
+	s.mu.Unlock()
+	if err != nil {
+		s.log.Error("Failed to apply folder tree permissions", "orgId", op.OrgID, "uid", op.FolderUID, "operation", op.ID, "done", op.Done, "error", err)
+		return
+	}
+	s.log.Info("Applied folder tree permissions", "orgId", op.OrgID, "uid", op.FolderUID, "operation", op.ID, "folders", op.Total, "conflicts", len(op.Conflicts))
+}
+
+// planFolder returns the conflicts of a folder and the permission commands to apply to it.
+func (s *Service) planFolder(ctx context.Context, user identity.Requester, f *folder.Folder, cmd ApplyPermissionsCommand) ([]*Conflict, []ac.SetResourcePermissionCommand, error) {
+	canWrite, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(f.UID)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canWrite {
+		return []*Conflict{{FolderUID: f.UID, Title: f.Title, Reason: ConflictAccessDenied}}, nil, nil
+	}
+
+	current, err := s.folderPermissions.GetPermissions(ctx, user, f.UID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the permissions inherited from the parents are not set on the folder itself
+	managed := make(map[string]string, len(current))
+	for _, p := range current {
+		if p.IsManaged && !p.IsInherited {
+			managed[principal(p.UserId, p.TeamId, p.BuiltInRole)] = s.folderPermissions.MapActions(p)
+		}
+	}
+
+	conflicts := make([]*Conflict, 0)
+	commands := make([]ac.SetResourcePermissionCommand, 0, len(cmd.Permissions))
+	for _, c := range cmd.Permissions {
+		key := principal(c.UserID, c.TeamID, c.BuiltinRole)
+		existing, ok := managed[key]
+		if ok && existing == c.Permission {
+			continue
+		}
+		if ok {
+			conflicts = append(conflicts, &Conflict{
+				FolderUID:   f.UID,
+				Title:       f.Title,
+				Reason:      ConflictPermissionDiffers,
+				Principal:   key,
+				Current:     existing,
+				Requested:   c.Permission,
+				Overwritten: cmd.Overwrite,
+			})
+			if !cmd.Overwrite {
+				continue
+			}
+		}
+		commands = append(commands, c)
+	}
+	return conflicts, commands, nil
+}
+
+// snapshot returns a copy of an operation that isn't changed by the running operation.
+func (s *Service) snapshot(op *Operation) *Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *op
+	c.Conflicts = append(make([]*Conflict, 0, len(op.Conflicts)), op.Conflicts...)
+	sort.SliceStable(c.Conflicts, func(i, j int) bool { return c.Conflicts[i].FolderUID < c.Conflicts[j].FolderUID })
+	return &c
+}
+
+func operationCacheKey(orgID int64, id string) string {
+	return fmt.Sprintf("folder-tree-operation-%d-%s", orgID, id)
+}
+
+func validatePermissions(permissions []ac.SetResourcePermissionCommand) error {
+	if len(permissions) == 0 {
+		return fmt.Errorf("%w: no permissions", ErrInvalidPermissions)
+	}
+	seen := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		set := 0
+		for _, ok := range []bool{p.UserID != 0, p.TeamID != 0, p.BuiltinRole != ""} {
+			if ok {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("%w: a permission must have one of userId, teamId or builtInRole", ErrInvalidPermissions)
+		}
+		key := principal(p.UserID, p.TeamID, p.BuiltinRole)
+		if !folderPermissionNames[p.Permission] {
+			return fmt.Errorf("%w: unknown permission %q for %s", ErrInvalidPermissions, p.Permission, key)
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: %s has more than one permission", ErrInvalidPermissions, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// principal identifies the user, the team or the built-in role of a permission.
+func principal(userID, teamID int64, builtInRole string) string {
+	switch {
+	case userID != 0:
+		return fmt.Sprintf("user:%d", userID)
+	case teamID != 0:
+		return fmt.Sprintf("team:%d", teamID)
+	default:
+		return "builtInRole:" + builtInRole
+	}
+}
@@ -0,0 +1,110 @@
+package foldertree
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestPlanFolder(t *testing.T) {
+	permissions := &actest.FakePermissionsService{
+		ExpectedPermissions: []ac.ResourcePermission{
+			{UserId: 1, IsManaged: true},
+			// inherited permissions are not conflicts
+			{TeamId: 3, IsManaged: true, IsInherited: true},
+		},
+		ExpectedMappedAction: "Edit",
+	}
+	f := &folder.Folder{UID: "a", Title: "A"}
+	cmd := ApplyPermissionsCommand{Permissions: []ac.SetResourcePermissionCommand{
+		{UserID: 1, Permission: "View"},
+		{TeamID: 2, Permission: "View"},
+		{TeamID: 3, Permission: "View"},
+	}}
+	signedInUser := &user.SignedInUser{OrgID: 1}
+
+	t.Run("keeps the different permissions", func(t *testing.T) {
+		s := &Service{folderPermissions: permissions, accessControl: actest.FakeAccessControl{ExpectedEvaluate: true}, log: log.NewNopLogger()}
+		conflicts, commands, err := s.planFolder(context.Background(), signedInUser, f, cmd)
+		require.NoError(t, err)
+		require.Equal(t, []*Conflict{{FolderUID: "a", Title: "A", Reason: ConflictPermissionDiffers, Principal: "user:1", Current: "Edit", Requested: "View"}}, conflicts)
+		require.Equal(t, cmd.Permissions[1:], commands)
+	})
+
+	t.Run("overwrites the different permissions", func(t *testing.T) {
+		s := &Service{folderPermissions: permissions, accessControl: actest.FakeAccessControl{ExpectedEvaluate: true}, log: log.NewNopLogger()}
+		cmd := cmd
+		cmd.Overwrite = true
+		conflicts, commands, err := s.planFolder(context.Background(), signedInUser, f, cmd)
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		require.True(t, conflicts[0].Overwritten)
+		require.Equal(t, cmd.Permissions, commands)
+	})
+
+	t.Run("skips the folders the user can't change", func(t *testing.T) {
+		s := &Service{folderPermissions: permissions, accessControl: actest.FakeAccessControl{ExpectedEvaluate: false}, log: log.NewNopLogger()}
+		conflicts, commands, err := s.planFolder(context.Background(), signedInUser, f, cmd)
+		require.NoError(t, err)
+		require.Equal(t, []*Conflict{{FolderUID: "a", Title: "A", Reason: ConflictAccessDenied}}, conflicts)
+		require.Empty(t, commands)
+	})
+}
+
+func TestValidatePermissions(t *testing.T) {
+	tests := map[string][]ac.SetResourcePermissionCommand{
+		"no permissions":     nil,
+		"no principal":       {{Permission: "View"}},
+		"two principals":     {{UserID: 1, TeamID: 2, Permission: "View"}},
+		"unknown permission": {{UserID: 1, Permission: "Owner"}},
+		"duplicate principal": {
+			{BuiltinRole: "Viewer", Permission: "View"},
+			{BuiltinRole: "Viewer", Permission: "Edit"},
+		},
+	}
+	for name, permissions := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, validatePermissions(permissions), ErrInvalidPermissions)
+		})
+	}
+	require.NoError(t, validatePermissions([]ac.SetResourcePermissionCommand{{UserID: 1, Permission: "Admin"}, {TeamID: 1, Permission: ""}}))
+}
+
+func TestGetOperationAcrossInstances(t *testing.T) {
+	cache := remotecache.NewFakeCacheStorage()
+	newService := func() *Service {
+		return &Service{cache: cache, log: log.NewNopLogger(), now: time.Now, operations: make(map[string]*Operation)}
+	}
+	running, other := newService(), newService()
+	creator := &user.SignedInUser{OrgID: 1, UserUID: "creator"}
+
+	op := &Operation{ID: "op", OrgID: 1, FolderUID: "a", Status: StatusRunning, Total: 2, Done: 1, Conflicts: make([]*Conflict, 0), createdBy: creator.GetUID()}
+	require.NoError(t, running.track(op))
+	running.share(context.Background(), op)
+
+	got, err := other.GetOperation(context.Background(), creator, "op")
+	require.NoError(t, err)
+	require.Equal(t, 1, got.Done)
+	require.Equal(t, StatusRunning, got.Status)
+
+	t.Run("only the creator can read the operation", func(t *testing.T) {
+		_, err := other.GetOperation(context.Background(), &user.SignedInUser{OrgID: 1, UserUID: "other"}, "op")
+		require.ErrorIs(t, err, ErrOperationNotFound)
+		_, err = other.GetOperation(context.Background(), &user.SignedInUser{OrgID: 2, UserUID: "creator"}, "op")
+		require.ErrorIs(t, err, ErrOperationNotFound)
+	})
+
+	t.Run("unknown operations are not found", func(t *testing.T) {
+		_, err := other.GetOperation(context.Background(), creator, "unknown")
+		require.ErrorIs(t, err, ErrOperationNotFound)
+	})
+}
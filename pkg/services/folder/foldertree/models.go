@@ -0,0 +1,90 @@
+package foldertree
+
+import (
+	"errors"
+	"time"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+var (
+	ErrOperationNotFound  = errors.New("folder tree operation not found")
+	ErrInvalidPermissions = errors.New("invalid permissions")
+	ErrTooManyOperations  = errors.New("too many folder tree operations are running")
+)
+
+const (
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+const (
+	// ConflictAccessDenied is the conflict of a folder whose permissions the user can't change, the folder is
+	// skipped.
+	ConflictAccessDenied = "access_denied"
+	// ConflictPermissionDiffers is the conflict of a principal who already has a different permission on a
+	// folder, which is replaced with overwrite and kept otherwise.
+	ConflictPermissionDiffers = "permission_differs"
+	// ConflictTitleExists is the conflict of a folder moved to a parent with a folder of the same title.
+	ConflictTitleExists = "title_exists"
+)
+
+// MoveTreeCommand moves a folder and all of its descendants under a new parent.
+type MoveTreeCommand struct {
+	// ParentUID is the new parent of the folder, the root if it is empty.
+	ParentUID string `json:"parentUid"`
+	// DryRun returns what is moved without moving it.
+	DryRun bool `json:"dryRun"`
+}
+
+// MoveTreeResult is the result of the move of a folder tree.
+type MoveTreeResult struct {
+	Folder *folder.Folder `json:"folder"`
+	// Descendants is the number of descendants moved with the folder by kind, like folders and dashboards.
+	Descendants folder.DescendantCounts `json:"descendants"`
+	Conflicts   []*Conflict             `json:"conflicts"`
+	Moved       bool                    `json:"moved"`
+}
+
+// ApplyPermissionsCommand applies a permission set to a folder and all of its descendant folders.
+type ApplyPermissionsCommand struct {
+	Permissions []ac.SetResourcePermissionCommand `json:"permissions"`
+	// Overwrite replaces the different permissions the principals already have on the folders. They are kept
+	// otherwise.
+	Overwrite bool `json:"overwrite"`
+	// DryRun returns the conflicts without applying the permissions.
+	DryRun bool `json:"dryRun"`
+}
+
+// Operation is a folder tree operation and its progress.
+type Operation struct {
+	ID        string `json:"id"`
+	OrgID     int64  `json:"-"`
+	FolderUID string `json:"folderUid"`
+	Status    string `json:"status"`
+	DryRun    bool   `json:"dryRun"`
+	// Total is the number of folders of the tree, and Done the number of folders processed.
+	Total     int         `json:"total"`
+	Done      int         `json:"done"`
+	Conflicts []*Conflict `json:"conflicts"`
+	Error     string      `json:"error,omitempty"`
+	Started   time.Time   `json:"started"`
+	Finished  *time.Time  `json:"finished,omitempty"`
+
+	createdBy string
+}
+
+// Conflict is a folder of a tree operation that couldn't be changed as requested.
+type Conflict struct {
+	FolderUID string `json:"folderUid"`
+	Title     string `json:"title"`
+	Reason    string `json:"reason"`
+	// Principal and Current are the principal and its current permission of a permission conflict.
+	Principal string `json:"principal,omitempty"`
+	Current   string `json:"current,omitempty"`
+	Requested string `json:"requested,omitempty"`
+	// Overwritten is true if the current permission was replaced.
+	Overwritten bool `json:"overwritten,omitempty"`
+}
@@ -141,6 +141,18 @@ type DeleteFolderCommand struct {
 	SignedInUser identity.Requester `json:"-"`
 }
 
+// RestoreFolderCommand captures the information required by the folder service
+// to restore a folder from the trash.
+type RestoreFolderCommand struct {
+	UID   string `json:"uid" xorm:"uid"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	// ParentUID is the folder to restore the folder in, instead of its original parent folder.
+	// An empty string restores it at the root level.
+	ParentUID *string `json:"parentUid"`
+
+	SignedInUser identity.Requester `json:"-"`
+}
+
 // GetFolderQuery is used for all folder Get requests. Only one of UID, ID, or
 // Title should be set; if multiple fields are set by the caller the dashboard
 // service will select the field with the most specificity, in order: ID, UID,
@@ -178,6 +190,13 @@ type GetParentsQuery struct {
 	OrgID int64  `xorm:"org_id"`
 }
 
+// GetDescendantsQuery captures the information required by the folder service to
+// return a list of all descendant folders of a given folder.
+type GetDescendantsQuery struct {
+	UID   string
+	OrgID int64
+}
+
 // GetChildrenQuery captures the information required by the folder service to
 // return a list of child folders of the given folder.
 
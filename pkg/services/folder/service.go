@@ -10,6 +10,9 @@ type Service interface {
 	// GetParents returns an array containing add parent folders if nested folders are enabled
 	// otherwise it returns an empty array
 	GetParents(ctx context.Context, q GetParentsQuery) ([]*Folder, error)
+	// GetDescendants returns an array containing all descendant folders, direct and indirect, if nested folders
+	// are enabled otherwise it returns an empty array
+	GetDescendants(ctx context.Context, q GetDescendantsQuery) ([]*Folder, error)
 	Create(ctx context.Context, cmd *CreateFolderCommand) (*Folder, error)
 
 	// GetFolder takes a GetFolderCommand and returns a folder matching the
@@ -25,6 +28,9 @@ type Service interface {
 	// a folder's parent folder, use Move.
 	Update(ctx context.Context, cmd *UpdateFolderCommand) (*Folder, error)
 	Delete(ctx context.Context, cmd *DeleteFolderCommand) error
+	// Restore restores a folder from the trash with its original UID and permissions, together with the subfolders
+	// and dashboards deleted with it.
+	Restore(ctx context.Context, cmd *RestoreFolderCommand) (*Folder, error)
 	// Move changes a folder's parent folder to the requested new parent.
 	Move(ctx context.Context, cmd *MoveFolderCommand) (*Folder, error)
 	RegisterService(service RegistryService) error
@@ -0,0 +1,44 @@
+package groupmapping
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/group-mapping", func(entities routing.RouteRegister) {
+		entities.Get("/rules", middleware.ReqGrafanaAdmin, routing.Wrap(s.getRulesHandler))
+		entities.Post("/evaluate", middleware.ReqGrafanaAdmin, routing.Wrap(s.evaluateHandler))
+	})
+}
+
+func (s *Service) getRulesHandler(c *contextmodel.ReqContext) response.Response {
+	rules := s.rules
+	if rules == nil {
+		rules = []Rule{}
+	}
+	return response.JSON(http.StatusOK, Config{Rules: rules})
+}
+
+// evaluateHandler is the dry run of the rules: it returns the roles and team memberships the rules
+// would assign and remove, without changing them.
+func (s *Service) evaluateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := EvaluateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.UserID == 0 && len(cmd.Groups) == 0 {
+		return response.Error(http.StatusBadRequest, "userId or groups are required", nil)
+	}
+
+	plan, err := s.Evaluate(c.Req.Context(), cmd.UserID, cmd.Groups)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate group mapping", err)
+	}
+	return response.JSON(http.StatusOK, plan)
+}
@@ -0,0 +1,487 @@
+package groupmapping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// hookPriority runs the sync after the user and org syncs, and before the permissions of the user are fetched.
+	hookPriority  = 115
+	syncLockName  = "group-mapping-sync"
+	syncBatchSize = 500
+)
+
+// Service maps the IdP groups of the users to RBAC roles and team memberships. The users are synced when
+// they log in, and on schedule with the groups of their last login, so that the changes of the rules
+// reach the users who haven't logged in recently.
+type Service struct {
+	settings        setting.GroupMappingSettings
+	store           db.DB
+	ac              accesscontrol.Service
+	teamService     team.Service
+	teamPermissions accesscontrol.TeamPermissionsService
+	userService     user.Service
+	serverLock      *serverlock.ServerLockService
+	log             log.Logger
+	now             func() time.Time
+	rules           []Rule
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, authnService authn.Service,
+	ac accesscontrol.Service, teamService team.Service, teamPermissions accesscontrol.TeamPermissionsService,
+	userService user.Service, serverLock *serverlock.ServerLockService) (*Service, error) {
+	s := &Service{
+		settings:        cfg.GroupMapping,
+		store:           sqlStore,
+		ac:              ac,
+		teamService:     teamService,
+		teamPermissions: teamPermissions,
+		userService:     userService,
+		serverLock:      serverLock,
+		log:             log.New("group-mapping"),
+		now:             time.Now,
+	}
+	if !s.settings.Enabled {
+		return s, nil
+	}
+
+	rules, err := loadRules(s.settings.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	s.rules = rules
+
+	authnService.RegisterPostAuthHook(s.syncHook, hookPriority)
+	s.registerAPIEndpoints(routeRegister)
+	return s, nil
+}
+
+func loadRules(file string) ([]Rule, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path comes from the Grafana configuration
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group mapping config file %q: %w", file, err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse group mapping config file %q: %w", file, err)
+	}
+	for i, rule := range config.Rules {
+		if err := validateRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %d of %q: %w", i+1, file, err)
+		}
+	}
+	return config.Rules, nil
+}
+
+func validateRule(rule Rule) error {
+	if rule.Group == "" {
+		return fmt.Errorf("%w: missing group", ErrInvalidRule)
+	}
+	if _, err := path.Match(rule.Group, ""); err != nil {
+		return fmt.Errorf("%w: invalid group pattern %q", ErrInvalidRule, rule.Group)
+	}
+	if rule.OrgID < 1 {
+		return fmt.Errorf("%w: missing orgId", ErrInvalidRule)
+	}
+	if len(rule.Roles) == 0 && len(rule.Teams) == 0 {
+		return fmt.Errorf("%w: no roles or teams for group %q", ErrInvalidRule, rule.Group)
+	}
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled || s.settings.SyncInterval == 0
+}
+
+// Run syncs the users who haven't been synced since SyncAfter, with the groups of their last login.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.serverLock.LockAndExecute(ctx, syncLockName, s.settings.SyncInterval, s.syncStaleUsers); err != nil {
+				s.log.Error("Failed to sync the group mapping of the users", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) syncStaleUsers(ctx context.Context) {
+	states, err := s.getStaleStates(ctx, s.now().Add(-s.settings.SyncAfter).Unix(), syncBatchSize)
+	if err != nil {
+		s.log.Error("Failed to get the users to sync", "error", err)
+		return
+	}
+
+	for _, state := range states {
+		if _, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: state.UserID}); err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				if err := s.deleteState(ctx, state.UserID); err != nil {
+					s.log.Error("Failed to delete the group mapping of a deleted user", "userID", state.UserID, "error", err)
+				}
+				continue
+			}
+			s.log.Error("Failed to get user", "userID", state.UserID, "error", err)
+			continue
+		}
+
+		groups, _, err := decodeState(state)
+		if err != nil {
+			s.log.Error("Failed to read the group mapping of user", "userID", state.UserID, "error", err)
+			continue
+		}
+		if err := s.SyncUser(ctx, state.UserID, state.AuthModule, groups); err != nil {
+			s.log.Error("Failed to sync the group mapping of user", "userID", state.UserID, "error", err)
+		}
+	}
+	s.log.Debug("Synced the group mapping of the users", "count", len(states))
+}
+
+// syncHook syncs the users logging in with an identity provider that gives their groups.
+func (s *Service) syncHook(ctx context.Context, ident *authn.Identity, _ *authn.Request) error {
+	if !ident.ClientParams.SyncTeams || !ident.IsIdentityType(claims.TypeUser) {
+		return nil
+	}
+
+	userID, err := ident.GetInternalID()
+	if err != nil {
+		return nil
+	}
+
+	// a failing sync doesn't prevent the user from logging in, the sync is retried on schedule
+	if err := s.SyncUser(ctx, userID, ident.GetAuthenticatedBy(), ident.Groups); err != nil {
+		s.log.FromContext(ctx).Error("Failed to sync the group mapping of user", "userID", userID, "error", err)
+	}
+	return nil
+}
+
+// SyncUser applies the rules matched by the groups to the roles and team memberships of the user, and
+// records the groups and the grants for the next syncs.
+func (s *Service) SyncUser(ctx context.Context, userID int64, authModule string, groups []string) error {
+	state, err := s.getState(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var previous map[int64]Grant
+	if state != nil {
+		if _, previous, err = decodeState(state); err != nil {
+			return err
+		}
+	}
+
+	plan := s.evaluate(userID, groups, previous)
+	grants, applyErr := s.apply(ctx, plan, previous)
+
+	// the grants are recorded even when some of them failed, so they are removed once the user loses them
+	if err := s.saveState(ctx, userID, authModule, groups, grants); err != nil {
+		return errors.Join(applyErr, err)
+	}
+	return applyErr
+}
+
+// Evaluate returns how the rules would change the roles and team memberships of the user with the
+// groups, without changing them. When userID is 0, nothing is removed under the keep policy.
+func (s *Service) Evaluate(ctx context.Context, userID int64, groups []string) (Plan, error) {
+	var previous map[int64]Grant
+	if userID > 0 {
+		state, err := s.getState(ctx, userID)
+		if err != nil {
+			return Plan{}, err
+		}
+		if state != nil {
+			lastGroups, grants, err := decodeState(state)
+			if err != nil {
+				return Plan{}, err
+			}
+			if len(groups) == 0 {
+				groups = lastGroups
+			}
+			previous = grants
+		}
+	}
+	return s.evaluate(userID, groups, previous), nil
+}
+
+// evaluate returns the plan of the user from the rules matched by the groups and the previous grants.
+func (s *Service) evaluate(userID int64, groups []string, previous map[int64]Grant) Plan {
+	plan := Plan{
+		UserID:         userID,
+		Groups:         groups,
+		Rules:          []Rule{},
+		ConflictPolicy: s.settings.ConflictPolicy,
+		Orgs:           []OrgPlan{},
+	}
+
+	desired := map[int64]*grantSet{}
+	managed := map[int64]*grantSet{}
+	for _, rule := range s.rules {
+		managed[rule.OrgID] = managed[rule.OrgID].add(rule.Roles, rule.Teams)
+		if !matchesGroups(rule, groups) {
+			continue
+		}
+		plan.Rules = append(plan.Rules, rule)
+		desired[rule.OrgID] = desired[rule.OrgID].add(rule.Roles, rule.Teams)
+	}
+
+	// the assignments a user no longer matches are the ones the mapping granted them before, or with the
+	// override policy, every assignment of the rules, including the ones made by hand
+	removable := map[int64]*grantSet{}
+	if s.settings.ConflictPolicy == setting.GroupMappingPolicyOverride {
+		removable = managed
+	} else {
+		for orgID, grant := range previous {
+			removable[orgID] = removable[orgID].add(grant.Roles, grant.Teams)
+		}
+	}
+
+	orgIDs := make([]int64, 0, len(desired)+len(removable))
+	for orgID := range desired {
+		orgIDs = append(orgIDs, orgID)
+	}
+	for orgID := range removable {
+		if _, ok := desired[orgID]; !ok {
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	for _, orgID := range orgIDs {
+		want := desired[orgID]
+		orgPlan := OrgPlan{
+			OrgID:         orgID,
+			Roles:         want.roleList(),
+			Teams:         want.teamList(),
+			RolesToRemove: removable[orgID].rolesNotIn(want),
+			TeamsToRemove: removable[orgID].teamsNotIn(want),
+		}
+		if len(orgPlan.Roles)+len(orgPlan.Teams)+len(orgPlan.RolesToRemove)+len(orgPlan.TeamsToRemove) == 0 {
+			continue
+		}
+		plan.Orgs = append(plan.Orgs, orgPlan)
+	}
+	return plan
+}
+
+func matchesGroups(rule Rule, groups []string) bool {
+	for _, group := range groups {
+		if ok, _ := path.Match(rule.Group, group); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply changes the roles and team memberships of the user as planned, and returns the grants of the mapping
+// per organization: the assignments it created in this sync or in the previous ones. The assignments the user
+// already had, like the ones made by hand, are not recorded, so they are kept when the user loses the groups.
+func (s *Service) apply(ctx context.Context, plan Plan, previous map[int64]Grant) (map[int64]Grant, error) {
+	grants := map[int64]Grant{}
+	var errs []error
+	for _, orgPlan := range plan.Orgs {
+		granted := (*grantSet)(nil).add(previous[orgPlan.OrgID].Roles, previous[orgPlan.OrgID].Teams)
+		grant := Grant{Roles: []string{}, Teams: []string{}}
+
+		if len(orgPlan.Roles)+len(orgPlan.RolesToRemove) > 0 {
+			created, err := s.syncRoles(ctx, plan.UserID, orgPlan)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for _, role := range orgPlan.Roles {
+				if _, ok := granted.roles[role]; ok || created[role] {
+					grant.Roles = append(grant.Roles, role)
+				}
+			}
+		}
+		for _, name := range orgPlan.Teams {
+			created, err := s.setTeamMembership(ctx, orgPlan.OrgID, plan.UserID, name, true)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if _, ok := granted.teams[name]; ok || created {
+				grant.Teams = append(grant.Teams, name)
+			}
+		}
+		for _, name := range orgPlan.TeamsToRemove {
+			if _, err := s.setTeamMembership(ctx, orgPlan.OrgID, plan.UserID, name, false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(grant.Roles)+len(grant.Teams) > 0 {
+			grants[orgPlan.OrgID] = grant
+		}
+	}
+	return grants, errors.Join(errs...)
+}
+
+// syncRoles adds and removes the roles of the user in the organization of the plan, and returns the roles it
+// assigned, which the user didn't have before.
+func (s *Service) syncRoles(ctx context.Context, userID int64, orgPlan OrgPlan) (map[string]bool, error) {
+	assigned, err := s.getAssignedRoles(ctx, orgPlan.OrgID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles in org %d: %w", orgPlan.OrgID, err)
+	}
+	err = s.ac.SyncUserRoles(ctx, orgPlan.OrgID, accesscontrol.SyncUserRolesCommand{
+		UserID:        userID,
+		RolesToAdd:    orgPlan.Roles,
+		RolesToRemove: orgPlan.RolesToRemove,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync roles in org %d: %w", orgPlan.OrgID, err)
+	}
+
+	created := make(map[string]bool, len(orgPlan.Roles))
+	for _, role := range orgPlan.Roles {
+		if _, ok := assigned[role]; !ok {
+			created[role] = true
+		}
+	}
+	return created, nil
+}
+
+// setTeamMembership adds the user to the team, or removes them from it, and returns true if the membership
+// changed. The users who are already members keep their permission, so the admins of a team are not demoted.
+func (s *Service) setTeamMembership(ctx context.Context, orgID, userID int64, name string, member bool) (bool, error) {
+	teamID, err := s.getTeamID(ctx, orgID, name)
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			s.log.FromContext(ctx).Warn("Team of the group mapping not found", "orgID", orgID, "team", name)
+			return false, nil
+		}
+		return false, err
+	}
+
+	isMember, err := s.teamService.IsTeamMember(ctx, orgID, teamID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get membership of team %q in org %d: %w", name, orgID, err)
+	}
+	if isMember == member {
+		return false, nil
+	}
+
+	permission := ""
+	if member {
+		permission = team.PermissionTypeMember.String()
+	}
+	if _, err := s.teamPermissions.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, strconv.FormatInt(teamID, 10), permission); err != nil {
+		return false, fmt.Errorf("failed to set membership of team %q in org %d: %w", name, orgID, err)
+	}
+	return true, nil
+}
+
+func (s *Service) getTeamID(ctx context.Context, orgID int64, name string) (int64, error) {
+	// the teams are searched on behalf of the group mapping, which can read all the teams of the org
+	requester := &user.SignedInUser{
+		OrgID: orgID,
+		Permissions: map[int64]map[string][]string{
+			orgID: {accesscontrol.ActionTeamsRead: {accesscontrol.ScopeTeamsAll}},
+		},
+	}
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        orgID,
+		Name:         name,
+		Limit:        1,
+		Page:         1,
+		SignedInUser: requester,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team %q in org %d: %w", name, orgID, err)
+	}
+	if len(result.Teams) == 0 {
+		return 0, team.ErrTeamNotFound
+	}
+	return result.Teams[0].ID, nil
+}
+
+// grantSet is a set of roles and teams.
+type grantSet struct {
+	roles map[string]struct{}
+	teams map[string]struct{}
+}
+
+// add returns the set with the roles and teams, creating it when it's nil.
+func (g *grantSet) add(roles, teams []string) *grantSet {
+	if g == nil {
+		g = &grantSet{roles: map[string]struct{}{}, teams: map[string]struct{}{}}
+	}
+	for _, role := range roles {
+		g.roles[role] = struct{}{}
+	}
+	for _, t := range teams {
+		g.teams[t] = struct{}{}
+	}
+	return g
+}
+
+func (g *grantSet) roleList() []string {
+	if g == nil {
+		return []string{}
+	}
+	return sortedKeys(g.roles, nil)
+}
+
+func (g *grantSet) teamList() []string {
+	if g == nil {
+		return []string{}
+	}
+	return sortedKeys(g.teams, nil)
+}
+
+func (g *grantSet) rolesNotIn(other *grantSet) []string {
+	if g == nil {
+		return []string{}
+	}
+	if other == nil {
+		return sortedKeys(g.roles, nil)
+	}
+	return sortedKeys(g.roles, other.roles)
+}
+
+func (g *grantSet) teamsNotIn(other *grantSet) []string {
+	if g == nil {
+		return []string{}
+	}
+	if other == nil {
+		return sortedKeys(g.teams, nil)
+	}
+	return sortedKeys(g.teams, other.teams)
+}
+
+// sortedKeys returns the sorted keys of set which are not in exclude.
+func sortedKeys(set, exclude map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		if _, ok := exclude[key]; ok {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
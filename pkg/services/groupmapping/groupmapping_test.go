@@ -0,0 +1,187 @@
+package groupmapping
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+var testRules = []Rule{
+	{Group: "admins", OrgID: 1, Roles: []string{"fixed:users:writer"}, Teams: []string{"Admins"}},
+	{Group: "ops-*", OrgID: 1, Roles: []string{"fixed:alerting:writer", "fixed:dashboards:writer"}},
+	{Group: "sales", OrgID: 2, Teams: []string{"Sales"}},
+}
+
+func TestLoadRules(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		file := filepath.Join(t.TempDir(), "group_mapping.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+		return file
+	}
+
+	rules, err := loadRules(write(t, `
+rules:
+  - group: ops-*
+    orgId: 1
+    roles: ["fixed:dashboards:writer"]
+    teams: ["Ops"]
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Rule{{Group: "ops-*", OrgID: 1, Roles: []string{"fixed:dashboards:writer"}, Teams: []string{"Ops"}}}, rules)
+
+	_, err = loadRules(write(t, `rules: [{group: ops, roles: ["fixed:dashboards:writer"]}]`))
+	require.ErrorIs(t, err, ErrInvalidRule)
+
+	_, err = loadRules(write(t, `rules: [{group: "ops-[", orgId: 1, roles: ["fixed:dashboards:writer"]}]`))
+	require.ErrorIs(t, err, ErrInvalidRule)
+
+	_, err = loadRules(write(t, `rules: [{group: ops, orgId: 1}]`))
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Run("should assign the roles and teams of the matched rules", func(t *testing.T) {
+		s := &Service{settings: setting.GroupMappingSettings{ConflictPolicy: setting.GroupMappingPolicyKeep}, rules: testRules}
+
+		plan := s.evaluate(1, []string{"ops-eu", "admins"}, nil)
+		require.Equal(t, testRules[:2], plan.Rules)
+		require.Equal(t, []OrgPlan{{
+			OrgID:         1,
+			Roles:         []string{"fixed:alerting:writer", "fixed:dashboards:writer", "fixed:users:writer"},
+			Teams:         []string{"Admins"},
+			RolesToRemove: []string{},
+			TeamsToRemove: []string{},
+		}}, plan.Orgs)
+	})
+
+	t.Run("should only remove the previous grants with the keep policy", func(t *testing.T) {
+		s := &Service{settings: setting.GroupMappingSettings{ConflictPolicy: setting.GroupMappingPolicyKeep}, rules: testRules}
+
+		previous := map[int64]Grant{
+			1: {Roles: []string{"fixed:alerting:writer", "fixed:dashboards:writer"}},
+			2: {Teams: []string{"Sales"}},
+		}
+		plan := s.evaluate(1, []string{"admins"}, previous)
+		require.Equal(t, []OrgPlan{
+			{
+				OrgID:         1,
+				Roles:         []string{"fixed:users:writer"},
+				Teams:         []string{"Admins"},
+				RolesToRemove: []string{"fixed:alerting:writer", "fixed:dashboards:writer"},
+				TeamsToRemove: []string{},
+			},
+			{OrgID: 2, Roles: []string{}, Teams: []string{}, RolesToRemove: []string{}, TeamsToRemove: []string{"Sales"}},
+		}, plan.Orgs)
+	})
+
+	t.Run("should remove every assignment of the rules with the override policy", func(t *testing.T) {
+		s := &Service{settings: setting.GroupMappingSettings{ConflictPolicy: setting.GroupMappingPolicyOverride}, rules: testRules}
+
+		plan := s.evaluate(1, []string{"sales"}, nil)
+		require.Equal(t, []OrgPlan{
+			{
+				OrgID:         1,
+				Roles:         []string{},
+				Teams:         []string{},
+				RolesToRemove: []string{"fixed:alerting:writer", "fixed:dashboards:writer", "fixed:users:writer"},
+				TeamsToRemove: []string{"Admins"},
+			},
+			{OrgID: 2, Roles: []string{}, Teams: []string{"Sales"}, RolesToRemove: []string{}, TeamsToRemove: []string{}},
+		}, plan.Orgs)
+	})
+}
+
+func TestIntegrationSyncUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	synced := map[int64]accesscontrol.SyncUserRolesCommand{}
+	ac := acmock.New()
+	ac.SyncUserRolesFunc = func(ctx context.Context, orgID int64, cmd accesscontrol.SyncUserRolesCommand) error {
+		synced[orgID] = cmd
+		return nil
+	}
+
+	now := time.Unix(1700000000, 0)
+	store := db.InitTestDB(t)
+	s := &Service{
+		settings:    setting.GroupMappingSettings{ConflictPolicy: setting.GroupMappingPolicyKeep, SyncAfter: time.Hour},
+		store:       store,
+		ac:          ac,
+		teamService: &teamtest.FakeService{},
+		log:         log.NewNopLogger(),
+		now:         func() time.Time { return now },
+		rules:       testRules,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, s.SyncUser(ctx, 10, "oauth_generic_oauth", []string{"ops-eu"}))
+	require.Equal(t, []string{"fixed:alerting:writer", "fixed:dashboards:writer"}, synced[1].RolesToAdd)
+	require.Empty(t, synced[1].RolesToRemove)
+
+	// the dry run removes the grants of the last sync which the groups no longer match
+	plan, err := s.Evaluate(ctx, 10, []string{"admins"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"fixed:alerting:writer", "fixed:dashboards:writer"}, plan.Orgs[0].RolesToRemove)
+
+	// without groups, the dry run uses the groups of the last sync
+	plan, err = s.Evaluate(ctx, 10, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops-eu"}, plan.Groups)
+
+	states, err := s.getStaleStates(ctx, now.Add(-time.Hour).Unix(), syncBatchSize)
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	now = now.Add(2 * time.Hour)
+	states, err = s.getStaleStates(ctx, now.Add(-time.Hour).Unix(), syncBatchSize)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.Equal(t, int64(10), states[0].UserID)
+
+	// the user was given the role of the admins group by hand
+	require.NoError(t, store.WithDbSession(ctx, func(sess *db.Session) error {
+		role := &accesscontrol.Role{OrgID: 1, UID: "users_writer", Name: "fixed:users:writer", Created: now, Updated: now}
+		if _, err := sess.Insert(role); err != nil {
+			return err
+		}
+		_, err := sess.Insert(&accesscontrol.UserRole{OrgID: 1, RoleID: role.ID, UserID: 10, Created: now})
+		return err
+	}))
+
+	// the user lost the ops group
+	require.NoError(t, s.SyncUser(ctx, 10, "oauth_generic_oauth", []string{"admins"}))
+	require.Equal(t, []string{"fixed:users:writer"}, synced[1].RolesToAdd)
+	require.Equal(t, []string{"fixed:alerting:writer", "fixed:dashboards:writer"}, synced[1].RolesToRemove)
+
+	// the role given by hand is not a grant of the mapping, and the Admins team doesn't exist
+	state, err := s.getState(ctx, 10)
+	require.NoError(t, err)
+	groups, grants, err := decodeState(state)
+	require.NoError(t, err)
+	require.Equal(t, []string{"admins"}, groups)
+	require.Empty(t, grants)
+	require.Equal(t, now.Unix(), state.SyncedAt)
+
+	// so it is kept when the user loses the admins group
+	synced = map[int64]accesscontrol.SyncUserRolesCommand{}
+	require.NoError(t, s.SyncUser(ctx, 10, "oauth_generic_oauth", []string{}))
+	require.Empty(t, synced)
+}
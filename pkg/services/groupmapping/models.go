@@ -0,0 +1,71 @@
+package groupmapping
+
+import (
+	"errors"
+)
+
+var ErrInvalidRule = errors.New("invalid group mapping rule")
+
+// Rule assigns roles and team memberships of an organization to the members of an IdP group.
+type Rule struct {
+	// Group is the name of the IdP group, it can contain the wildcards of path.Match, e.g. ops-*.
+	Group string   `yaml:"group" json:"group"`
+	OrgID int64    `yaml:"orgId" json:"orgId"`
+	Roles []string `yaml:"roles" json:"roles,omitempty"`
+	// Teams are the names of the teams of the organization.
+	Teams []string `yaml:"teams" json:"teams,omitempty"`
+}
+
+// Config is the content of the group mapping config file.
+type Config struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Grant is what the rules matched by the groups of a user assign in an organization.
+type Grant struct {
+	Roles []string `json:"roles"`
+	Teams []string `json:"teams"`
+}
+
+// OrgPlan is how the roles and team memberships of a user change in an organization.
+type OrgPlan struct {
+	OrgID         int64    `json:"orgId"`
+	Roles         []string `json:"roles"`
+	Teams         []string `json:"teams"`
+	RolesToRemove []string `json:"rolesToRemove"`
+	TeamsToRemove []string `json:"teamsToRemove"`
+}
+
+// Plan is the outcome of the evaluation of the rules for the groups of a user.
+type Plan struct {
+	UserID int64    `json:"userId,omitempty"`
+	Groups []string `json:"groups"`
+	// Rules are the rules matched by the groups.
+	Rules          []Rule    `json:"rules"`
+	ConflictPolicy string    `json:"conflictPolicy"`
+	Orgs           []OrgPlan `json:"orgs"`
+}
+
+// userState is what the group mapping knows of a user from their last sync.
+type userState struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	UserID     int64  `xorm:"user_id"`
+	AuthModule string `xorm:"auth_module"`
+	// Groups are the IdP groups of the user when they last logged in, as JSON.
+	Groups string `xorm:"idp_groups"`
+	// Granted is the map of the grants of the group mapping per organization, as JSON.
+	Granted  string `xorm:"granted"`
+	SyncedAt int64  `xorm:"synced_at"`
+}
+
+func (userState) TableName() string {
+	return "group_mapping_user"
+}
+
+// EvaluateCommand is the body of the dry-run API.
+type EvaluateCommand struct {
+	// UserID is the user the removals are computed for, from the grants of their last sync. Optional.
+	UserID int64 `json:"userId"`
+	// Groups are the IdP groups to evaluate. When empty, the groups of the last login of the user are used.
+	Groups []string `json:"groups"`
+}
@@ -0,0 +1,105 @@
+package groupmapping
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// getState returns the state of the user, or nil when they were never synced.
+func (s *Service) getState(ctx context.Context, userID int64) (*userState, error) {
+	state := &userState{}
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("user_id = ?", userID).Get(state)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *Service) saveState(ctx context.Context, userID int64, authModule string, groups []string, grants map[int64]Grant) error {
+	if groups == nil {
+		groups = []string{}
+	}
+	rawGroups, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	rawGrants, err := json.Marshal(grants)
+	if err != nil {
+		return err
+	}
+
+	state := &userState{
+		UserID:     userID,
+		AuthModule: authModule,
+		Groups:     string(rawGroups),
+		Granted:    string(rawGrants),
+		SyncedAt:   s.now().Unix(),
+	}
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &userState{}
+		exists, err := sess.Where("user_id = ?", userID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = sess.Insert(state)
+			return err
+		}
+		_, err = sess.ID(existing.ID).AllCols().Update(state)
+		return err
+	})
+}
+
+func (s *Service) deleteState(ctx context.Context, userID int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM group_mapping_user WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+// getStaleStates returns the states of the users synced before syncedBefore, the oldest first.
+func (s *Service) getStaleStates(ctx context.Context, syncedBefore int64, limit int) ([]*userState, error) {
+	states := make([]*userState, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("synced_at < ?", syncedBefore).Asc("synced_at").Limit(limit).Find(&states)
+	})
+	return states, err
+}
+
+func decodeState(state *userState) ([]string, map[int64]Grant, error) {
+	var groups []string
+	if err := json.Unmarshal([]byte(state.Groups), &groups); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode groups of user %d: %w", state.UserID, err)
+	}
+	grants := map[int64]Grant{}
+	if err := json.Unmarshal([]byte(state.Granted), &grants); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode grants of user %d: %w", state.UserID, err)
+	}
+	return groups, grants, nil
+}
+
+// getAssignedRoles returns the names of the roles assigned to the user in the organization, or globally.
+func (s *Service) getAssignedRoles(ctx context.Context, orgID, userID int64) (map[string]struct{}, error) {
+	names := make([]string, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT role.name FROM user_role INNER JOIN role ON role.id = user_role.role_id"+
+			" WHERE user_role.user_id = ? AND user_role.org_id IN (?, ?)", userID, orgID, accesscontrol.GlobalOrgID).Find(&names)
+	})
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		assigned[name] = struct{}{}
+	}
+	return assigned, nil
+}
@@ -0,0 +1,221 @@
+// Package health checks the dependencies of Grafana for the readiness endpoint. The core services register a
+// checker for the dependency they own; a failing critical dependency makes Grafana not ready, and a failing
+// non-critical dependency makes it degraded, so that load balancers can tell the two apart.
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginlimits"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// cacheTTL is how long a report is reused, so that frequent probes don't load the dependencies.
+	cacheTTL = 5 * time.Second
+	// checkTimeout is the timeout of each checker.
+	checkTimeout = 3 * time.Second
+)
+
+// errNotConfigured is returned by the checkers of the optional dependencies which are not configured, which are
+// left out of the report.
+var errNotConfigured = errors.New("not configured")
+
+// Checker checks a dependency, and returns an error if it's unavailable.
+type Checker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckerFunc is a function implementing Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Dependency is a dependency of Grafana checked for the readiness.
+type Dependency struct {
+	Name string
+	// Critical dependencies make Grafana not ready when they fail, the others make it degraded.
+	Critical bool
+	Checker  Checker
+}
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFailing  Status = "failing"
+)
+
+// DependencyStatus is the result of the check of a dependency.
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	// Error isn't exposed by the unauthenticated endpoints, it's logged when the status changes.
+	Error string `json:"-"`
+}
+
+// Report is the readiness of Grafana with the status of each dependency.
+type Report struct {
+	Status       Status             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	checked      time.Time
+}
+
+// Service runs the checkers of the registered dependencies.
+type Service struct {
+	metrics *metrics
+	log     log.Logger
+	now     func() time.Time
+
+	mtx          sync.Mutex
+	dependencies []Dependency
+	last         *Report
+	// statuses is the last status of each dependency, to log the changes.
+	statuses map[string]Status
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, remoteCache *remotecache.RemoteCache,
+	renderer *rendering.RenderingService, pluginLimits *pluginlimits.Service, registerer prometheus.Registerer) *Service {
+	s := NewService(registerer)
+	s.Register(Dependency{Name: "database", Critical: true, Checker: CheckerFunc(func(ctx context.Context) error {
+		return sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("SELECT 1")
+			return err
+		})
+	})})
+	// The database remote cache is checked with the database.
+	if cfg.RemoteCacheOptions != nil && cfg.RemoteCacheOptions.Name != "database" {
+		s.Register(Dependency{Name: "remote-cache", Critical: true, Checker: remoteCache})
+	}
+	s.Register(Dependency{Name: "plugins", Checker: pluginLimits})
+	s.Register(Dependency{Name: "renderer", Checker: CheckerFunc(func(ctx context.Context) error {
+		if !renderer.IsAvailable(ctx) {
+			return errNotConfigured
+		}
+		return renderer.CheckHealth(ctx)
+	})})
+	return s
+}
+
+// NewService returns a service without dependencies.
+func NewService(registerer prometheus.Registerer) *Service {
+	return &Service{
+		metrics:  newMetrics(registerer),
+		log:      log.New("health"),
+		now:      time.Now,
+		statuses: map[string]Status{},
+	}
+}
+
+// Register registers a dependency checked for the readiness.
+func (s *Service) Register(dep Dependency) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.dependencies = append(s.dependencies, dep)
+	s.last = nil
+}
+
+// Check checks the registered dependencies at the same time, or returns the report of the last check if it's
+// recent enough.
+func (s *Service) Check(ctx context.Context) *Report {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.last != nil && s.now().Sub(s.last.checked) < cacheTTL {
+		return s.last
+	}
+
+	// The report is shared by the probes until it expires, so a probe which goes away must not fail the checks: they
+	// run detached from the request, bounded by checkTimeout.
+	ctx = context.WithoutCancel(ctx)
+	results := make([]DependencyStatus, len(s.dependencies))
+	var wg sync.WaitGroup
+	for i, dep := range s.dependencies {
+		wg.Add(1)
+		go func(i int, dep Dependency) {
+			defer wg.Done()
+			results[i] = s.check(ctx, dep)
+		}(i, dep)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Dependencies: make([]DependencyStatus, 0, len(results)), checked: s.now()}
+	for _, result := range results {
+		if result.Status == "" {
+			continue
+		}
+		report.Dependencies = append(report.Dependencies, result)
+		if result.Status == StatusFailing {
+			if result.Critical {
+				report.Status = StatusFailing
+			} else if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}
+		s.logChange(result)
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool { return report.Dependencies[i].Name < report.Dependencies[j].Name })
+	s.last = report
+	return report
+}
+
+// check runs the checker of a dependency, and returns an empty status for the dependencies not configured.
+func (s *Service) check(ctx context.Context, dep Dependency) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := s.now()
+	err := dep.Checker.CheckHealth(ctx)
+	latency := s.now().Sub(start)
+	if errors.Is(err, errNotConfigured) {
+		return DependencyStatus{}
+	}
+
+	result := DependencyStatus{
+		Name:      dep.Name,
+		Status:    StatusOK,
+		Critical:  dep.Critical,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	s.metrics.up.WithLabelValues(dep.Name).Set(boolToFloat(err == nil))
+	s.metrics.duration.WithLabelValues(dep.Name).Observe(latency.Seconds())
+	return result
+}
+
+func (s *Service) logChange(result DependencyStatus) {
+	previous, ok := s.statuses[result.Name]
+	s.statuses[result.Name] = result.Status
+	if ok && previous == result.Status {
+		return
+	}
+	if result.Status == StatusFailing {
+		s.log.Warn("Dependency is failing", "dependency", result.Name, "critical", result.Critical, "error", result.Error)
+	} else if ok {
+		s.log.Info("Dependency recovered", "dependency", result.Name)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
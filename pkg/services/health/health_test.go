@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(now *time.Time, deps ...Dependency) *Service {
+	s := NewService(nil)
+	s.now = func() time.Time { return *now }
+	for _, dep := range deps {
+		s.Register(dep)
+	}
+	return s
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ok := CheckerFunc(func(context.Context) error { return nil })
+	failing := CheckerFunc(func(context.Context) error { return errors.New("unreachable") })
+	notConfigured := CheckerFunc(func(context.Context) error { return errNotConfigured })
+
+	t.Run("all dependencies ok", func(t *testing.T) {
+		s := newTestService(&now,
+			Dependency{Name: "database", Critical: true, Checker: ok},
+			Dependency{Name: "renderer", Checker: notConfigured},
+		)
+		report := s.Check(context.Background())
+		require.Equal(t, StatusOK, report.Status)
+		require.Len(t, report.Dependencies, 1, "the dependencies not configured are left out")
+		require.Equal(t, "database", report.Dependencies[0].Name)
+	})
+
+	t.Run("failing non-critical dependency is degraded", func(t *testing.T) {
+		s := newTestService(&now,
+			Dependency{Name: "database", Critical: true, Checker: ok},
+			Dependency{Name: "plugins", Checker: failing},
+		)
+		report := s.Check(context.Background())
+		require.Equal(t, StatusDegraded, report.Status)
+		require.Equal(t, DependencyStatus{Name: "plugins", Status: StatusFailing, Error: "unreachable", LatencyMs: 0}, report.Dependencies[1])
+	})
+
+	t.Run("failing critical dependency is failing", func(t *testing.T) {
+		s := newTestService(&now,
+			Dependency{Name: "database", Critical: true, Checker: failing},
+			Dependency{Name: "plugins", Checker: failing},
+		)
+		require.Equal(t, StatusFailing, s.Check(context.Background()).Status)
+	})
+
+	t.Run("report is cached", func(t *testing.T) {
+		calls := 0
+		s := newTestService(&now, Dependency{Name: "database", Critical: true, Checker: CheckerFunc(func(context.Context) error {
+			calls++
+			return nil
+		})})
+		s.Check(context.Background())
+		s.Check(context.Background())
+		require.Equal(t, 1, calls)
+
+		now = now.Add(cacheTTL)
+		s.Check(context.Background())
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("checks are not cancelled with the request", func(t *testing.T) {
+		s := newTestService(&now, Dependency{Name: "database", Critical: true, Checker: CheckerFunc(func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("no timeout")
+			}
+			return ctx.Err()
+		})})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.Equal(t, StatusOK, s.Check(ctx).Status)
+	})
+}
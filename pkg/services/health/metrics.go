@@ -0,0 +1,29 @@
+package health
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	up       *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		up: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "health",
+			Name:      "dependency_up",
+			Help:      "1 if the last check of the dependency succeeded, 0 otherwise.",
+		}, []string{"dependency"}),
+		duration: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "health",
+			Name:      "check_duration_seconds",
+			Help:      "Duration of the health checks of the dependencies.",
+			Buckets:   []float64{.001, .005, .01, .05, .1, .5, 1, 3},
+		}, []string{"dependency"}),
+	}
+}
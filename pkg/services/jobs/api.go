@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultRunsLimit = 50
+	maxRunsLimit     = 500
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/admin/jobs", func(entities routing.RouteRegister) {
+		entities.Get("/", routing.Wrap(s.listHandler))
+		entities.Get("/runs", routing.Wrap(s.listRunsHandler))
+		entities.Post("/:name/run", routing.Wrap(s.runHandler))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	jobs, err := s.Jobs(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list jobs", err)
+	}
+	return response.JSON(http.StatusOK, jobs)
+}
+
+func (s *Service) listRunsHandler(c *contextmodel.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+	runs, err := s.ListRuns(c.Req.Context(), RunsQuery{
+		Name:   c.Query("name"),
+		Status: RunStatus(c.Query("status")),
+		Limit:  limit,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list job runs", err)
+	}
+	return response.JSON(http.StatusOK, runs)
+}
+
+func (s *Service) runHandler(c *contextmodel.ReqContext) response.Response {
+	run, err := s.Enqueue(c.Req.Context(), web.Params(c.Req)[":name"], nil)
+	if err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			return response.Error(http.StatusNotFound, "Job not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to enqueue job", err)
+	}
+	return response.JSON(http.StatusAccepted, run)
+}
@@ -0,0 +1,399 @@
+// Package jobs runs the background jobs of Grafana from a queue stored in the SQL store, so that the instances
+// sharing the database run each job once. Jobs are enqueued on their cron schedule or on demand, run by a pool of
+// workers with a timeout, and retried with a backoff when they fail. The runs are kept for the admin API.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultRetryBackoff = 30 * time.Second
+	// lockGrace is added to the timeout of a run before the run is considered lost by the instance running it.
+	lockGrace = time.Minute
+	// cleanupInterval is how often the expired runs are deleted.
+	cleanupInterval = time.Hour
+)
+
+// definition is a registered job with its parsed schedule.
+type definition struct {
+	Definition
+	schedule cron.Schedule
+}
+
+// Service runs the registered jobs.
+type Service struct {
+	store    db.DB
+	settings setting.JobsSettings
+	metrics  *metrics
+	log      log.Logger
+	now      func() time.Time
+
+	mtx         sync.RWMutex
+	definitions map[string]*definition
+
+	// slots bounds the number of runs executed at the same time by this instance.
+	slots chan struct{}
+	// wake triggers a dispatch of the due runs, when a run is enqueued or a worker is free.
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, registerer prometheus.Registerer) *Service {
+	s := &Service{
+		store:       sqlStore,
+		settings:    cfg.Jobs,
+		metrics:     newMetrics(registerer),
+		log:         log.New("jobs"),
+		now:         time.Now,
+		definitions: map[string]*definition{},
+		wake:        make(chan struct{}, 1),
+	}
+	if s.settings.Workers <= 0 {
+		s.settings.Workers = 4
+	}
+	if s.settings.PollInterval <= 0 {
+		s.settings.PollInterval = 10 * time.Second
+	}
+	if s.settings.DefaultTimeout <= 0 {
+		s.settings.DefaultTimeout = 10 * time.Minute
+	}
+	s.slots = make(chan struct{}, s.settings.Workers)
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Register registers a job, which is enqueued on its schedule once the service runs.
+func (s *Service) Register(def Definition) error {
+	if def.Name == "" {
+		return fmt.Errorf("%w: the name is required", ErrInvalidJob)
+	}
+	if def.Handler == nil {
+		return fmt.Errorf("%w: job %s has no handler", ErrInvalidJob, def.Name)
+	}
+	if def.Timeout <= 0 {
+		def.Timeout = s.settings.DefaultTimeout
+	}
+	if def.MaxAttempts <= 0 {
+		def.MaxAttempts = 1
+	}
+	if def.RetryBackoff <= 0 {
+		def.RetryBackoff = defaultRetryBackoff
+	}
+
+	d := &definition{Definition: def}
+	if def.Schedule != "" {
+		sched, err := cron.ParseStandard(def.Schedule)
+		if err != nil {
+			return fmt.Errorf("%w: invalid schedule %q of job %s: %s", ErrInvalidJob, def.Schedule, def.Name, err)
+		}
+		d.schedule = sched
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.definitions[def.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, def.Name)
+	}
+	s.definitions[def.Name] = d
+	return nil
+}
+
+func (s *Service) definition(name string) (*definition, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	d, ok := s.definitions[name]
+	return d, ok
+}
+
+// sortedDefinitions returns the registered jobs sorted by name.
+func (s *Service) sortedDefinitions() []*definition {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	defs := make([]*definition, 0, len(s.definitions))
+	for _, d := range s.definitions {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// Enqueue enqueues a run of a registered job with a payload, which is passed to its handler.
+func (s *Service) Enqueue(ctx context.Context, name string, payload []byte) (*Run, error) {
+	d, ok := s.definition(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	run, err := s.enqueue(ctx, d, payload, true)
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+	return run, nil
+}
+
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run enqueues the scheduled jobs and runs the due runs of the registered jobs until ctx is done, then waits for
+// the running jobs, whose context is cancelled, to return.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.PollInterval)
+	defer ticker.Stop()
+	var lastCleanup time.Time
+	for {
+		if err := s.recoverLost(ctx); err != nil {
+			s.log.Error("Failed to recover the lost job runs", "error", err)
+		}
+		if err := s.scheduleDue(ctx); err != nil {
+			s.log.Error("Failed to enqueue the scheduled jobs", "error", err)
+		}
+		if err := s.dispatch(ctx); err != nil {
+			s.log.Error("Failed to dispatch the job runs", "error", err)
+		}
+		if now := s.now(); now.Sub(lastCleanup) >= cleanupInterval {
+			lastCleanup = now
+			deleted, err := s.deleteRunsBefore(ctx, now.Add(-s.settings.RunHistoryRetention).Unix())
+			if err != nil {
+				s.log.Error("Failed to delete the expired job runs", "error", err)
+			} else if deleted > 0 {
+				s.log.Debug("Deleted expired job runs", "count", deleted)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return nil
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// scheduleDue enqueues a run of the scheduled jobs whose next run has passed, moving their next run first so that
+// they are enqueued once when several instances share the database. A run isn't enqueued while the previous one
+// is still pending or running.
+func (s *Service) scheduleDue(ctx context.Context) error {
+	now := s.now()
+	for _, d := range s.sortedDefinitions() {
+		if d.schedule == nil {
+			continue
+		}
+		sched, err := s.getSchedule(ctx, d.Name)
+		if err != nil {
+			return err
+		}
+		if sched == nil {
+			if err := s.insertSchedule(ctx, &schedule{Name: d.Name, Schedule: d.Schedule, NextRun: d.schedule.Next(now).Unix()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if sched.Schedule != d.Schedule {
+			// The schedule changed with an upgrade, the next run follows the new schedule.
+			if _, err := s.claimSchedule(ctx, sched, d.Schedule, d.schedule.Next(now).Unix()); err != nil {
+				return err
+			}
+			continue
+		}
+		if sched.NextRun > now.Unix() {
+			continue
+		}
+
+		claimed, err := s.claimSchedule(ctx, sched, d.Schedule, d.schedule.Next(now).Unix())
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		active, err := s.countActive(ctx, d.Name)
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			s.log.Debug("Skipping scheduled job, the previous run is not finished", "job", d.Name)
+			continue
+		}
+		if _, err := s.enqueue(ctx, d, nil, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) enqueue(ctx context.Context, d *definition, payload []byte, manual bool) (*Run, error) {
+	now := s.now().Unix()
+	run := &Run{
+		Name:        d.Name,
+		Payload:     string(payload),
+		Manual:      manual,
+		Status:      RunStatusPending,
+		MaxAttempts: d.MaxAttempts,
+		Created:     now,
+		RunAt:       now,
+	}
+	if err := s.insertRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// dispatch claims as many due runs as there are free workers, and runs them.
+func (s *Service) dispatch(ctx context.Context) error {
+	free := cap(s.slots) - len(s.slots)
+	if free <= 0 || ctx.Err() != nil {
+		return nil
+	}
+	runs, err := s.claimDue(ctx, free)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		s.slots <- struct{}{}
+		s.wg.Add(1)
+		go func(run *Run) {
+			defer func() {
+				<-s.slots
+				s.wg.Done()
+				s.notify()
+			}()
+			s.execute(ctx, run)
+		}(run)
+	}
+	return nil
+}
+
+// execute runs a claimed run with the timeout of its job, and stores its result. A run interrupted by the shutdown
+// of Grafana is released for another attempt.
+func (s *Service) execute(ctx context.Context, run *Run) {
+	d, ok := s.definition(run.Name)
+	if !ok {
+		return
+	}
+	logger := s.log.New("job", run.Name, "runId", run.ID, "attempt", run.Attempts)
+	logger.Debug("Running job")
+
+	started := s.now()
+	runCtx, cancel := context.WithTimeout(ctx, d.Timeout)
+	err := call(runCtx, d.Handler, []byte(run.Payload))
+	timedOut := runCtx.Err() == context.DeadlineExceeded
+	cancel()
+	s.metrics.duration.WithLabelValues(run.Name).Observe(s.now().Sub(started).Seconds())
+
+	storeCtx := context.WithoutCancel(ctx)
+	if ctx.Err() != nil {
+		if err := s.release(storeCtx, run); err != nil {
+			logger.Warn("Failed to release interrupted job run", "error", err)
+		}
+		return
+	}
+	if err != nil && timedOut {
+		err = fmt.Errorf("timed out after %s: %w", d.Timeout, err)
+	}
+
+	var result RunStatus
+	switch {
+	case err == nil:
+		result = RunStatusSucceeded
+		logger.Debug("Job succeeded", "duration", s.now().Sub(started))
+	case run.Attempts < run.MaxAttempts:
+		result = RunStatusPending
+		logger.Warn("Job failed, retrying later", "error", err)
+	default:
+		result = RunStatusFailed
+		logger.Error("Job failed", "error", err)
+	}
+	s.metrics.runs.WithLabelValues(run.Name, metricResult(result)).Inc()
+	if err := s.finish(storeCtx, run, d, result, err); err != nil {
+		logger.Error("Failed to store the result of the job run", "error", err)
+	}
+}
+
+// call calls the handler, and returns the panic of the handler as an error.
+func call(ctx context.Context, handler Handler, payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+// retryBackoff is the delay before the retry of a run which failed its attempt.
+func retryBackoff(d *definition, attempt int) time.Duration {
+	backoff := d.RetryBackoff
+	for i := 1; i < attempt && backoff < time.Hour; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
+func metricResult(status RunStatus) string {
+	if status == RunStatusPending {
+		return "retried"
+	}
+	return string(status)
+}
+
+// Jobs returns the registered jobs with their next scheduled run, and their last runs and failures.
+func (s *Service) Jobs(ctx context.Context) ([]JobInfo, error) {
+	counts, err := s.countByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := s.sortedDefinitions()
+	infos := make([]JobInfo, 0, len(defs))
+	for _, d := range defs {
+		info := JobInfo{
+			Name:        d.Name,
+			Description: d.Description,
+			Schedule:    d.Schedule,
+			Timeout:     d.Timeout.String(),
+			MaxAttempts: d.MaxAttempts,
+			Pending:     counts[d.Name][RunStatusPending],
+			Running:     counts[d.Name][RunStatusRunning],
+			Failures:    counts[d.Name][RunStatusFailed],
+		}
+		if d.schedule != nil {
+			sched, err := s.getSchedule(ctx, d.Name)
+			if err != nil {
+				return nil, err
+			}
+			if sched != nil {
+				info.NextRun = sched.NextRun
+			}
+		}
+		if info.LastRun, err = s.lastRun(ctx, d.Name, RunStatusSucceeded, RunStatusFailed); err != nil {
+			return nil, err
+		}
+		if info.LastFailure, err = s.lastRun(ctx, d.Name, RunStatusFailed); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListRuns returns the runs of the jobs, the most recent first.
+func (s *Service) ListRuns(ctx context.Context, query RunsQuery) ([]*Run, error) {
+	return s.listRuns(ctx, query)
+}
@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestRegister(t *testing.T) {
+	s := &Service{settings: setting.JobsSettings{DefaultTimeout: time.Minute}, definitions: map[string]*definition{}}
+	noop := func(ctx context.Context, payload []byte) error { return nil }
+
+	require.NoError(t, s.Register(Definition{Name: "job", Schedule: "@every 10m", Handler: noop}))
+	d, ok := s.definition("job")
+	require.True(t, ok)
+	require.Equal(t, time.Minute, d.Timeout)
+	require.Equal(t, 1, d.MaxAttempts)
+	require.Equal(t, defaultRetryBackoff, d.RetryBackoff)
+
+	require.ErrorIs(t, s.Register(Definition{Name: "job", Handler: noop}), ErrAlreadyRegistered)
+	require.ErrorIs(t, s.Register(Definition{Name: "no-handler"}), ErrInvalidJob)
+	require.ErrorIs(t, s.Register(Definition{Name: "bad-schedule", Schedule: "every day", Handler: noop}), ErrInvalidJob)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	d := &definition{Definition: Definition{RetryBackoff: 30 * time.Second}}
+	require.Equal(t, 30*time.Second, retryBackoff(d, 1))
+	require.Equal(t, time.Minute, retryBackoff(d, 2))
+	require.Equal(t, 2*time.Minute, retryBackoff(d, 3))
+	require.Equal(t, 64*time.Minute, retryBackoff(d, 20), "the backoff stops doubling after an hour")
+}
+
+func TestIntegrationJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Unix(1700000000, 0)
+	newService := func(t *testing.T) *Service {
+		return &Service{
+			store:       db.InitTestDB(t),
+			settings:    setting.JobsSettings{Workers: 2, PollInterval: time.Second, DefaultTimeout: time.Minute, RunHistoryRetention: time.Hour},
+			metrics:     newMetrics(nil),
+			log:         log.NewNopLogger(),
+			now:         func() time.Time { return now },
+			definitions: map[string]*definition{},
+			slots:       make(chan struct{}, 2),
+			wake:        make(chan struct{}, 1),
+		}
+	}
+	runOnce := func(t *testing.T, s *Service) []*Run {
+		runs, err := s.claimDue(context.Background(), 10)
+		require.NoError(t, err)
+		for _, run := range runs {
+			s.execute(context.Background(), run)
+		}
+		return runs
+	}
+	getRun := func(t *testing.T, s *Service, id int64) *Run {
+		runs, err := s.ListRuns(context.Background(), RunsQuery{Limit: 100})
+		require.NoError(t, err)
+		for _, run := range runs {
+			if run.ID == id {
+				return run
+			}
+		}
+		t.Fatalf("run %d not found", id)
+		return nil
+	}
+
+	t.Run("enqueued run is run once with its payload", func(t *testing.T) {
+		s := newService(t)
+		var payloads []string
+		require.NoError(t, s.Register(Definition{Name: "job", Handler: func(ctx context.Context, payload []byte) error {
+			payloads = append(payloads, string(payload))
+			return nil
+		}}))
+
+		run, err := s.Enqueue(context.Background(), "job", []byte("payload"))
+		require.NoError(t, err)
+		require.True(t, run.Manual)
+
+		require.Len(t, runOnce(t, s), 1)
+		require.Empty(t, runOnce(t, s))
+		require.Equal(t, []string{"payload"}, payloads)
+		require.Equal(t, RunStatusSucceeded, getRun(t, s, run.ID).Status)
+
+		_, err = s.Enqueue(context.Background(), "unknown", nil)
+		require.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("failed run is retried after the backoff", func(t *testing.T) {
+		s := newService(t)
+		attempts := 0
+		require.NoError(t, s.Register(Definition{Name: "job", MaxAttempts: 2, RetryBackoff: time.Minute, Handler: func(ctx context.Context, payload []byte) error {
+			attempts++
+			return errors.New("boom")
+		}}))
+		run, err := s.Enqueue(context.Background(), "job", nil)
+		require.NoError(t, err)
+
+		runOnce(t, s)
+		retried := getRun(t, s, run.ID)
+		require.Equal(t, RunStatusPending, retried.Status)
+		require.Equal(t, now.Add(time.Minute).Unix(), retried.RunAt)
+		require.Equal(t, "boom", retried.Error)
+		require.Empty(t, runOnce(t, s), "the retry is not due yet")
+
+		now = now.Add(time.Minute)
+		runOnce(t, s)
+		failed := getRun(t, s, run.ID)
+		require.Equal(t, RunStatusFailed, failed.Status)
+		require.Equal(t, 2, failed.Attempts)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("run times out and panics are failures", func(t *testing.T) {
+		s := newService(t)
+		require.NoError(t, s.Register(Definition{Name: "slow", Timeout: 10 * time.Millisecond, Handler: func(ctx context.Context, payload []byte) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}))
+		require.NoError(t, s.Register(Definition{Name: "panic", Handler: func(ctx context.Context, payload []byte) error {
+			panic("oops")
+		}}))
+		slow, err := s.Enqueue(context.Background(), "slow", nil)
+		require.NoError(t, err)
+		panicked, err := s.Enqueue(context.Background(), "panic", nil)
+		require.NoError(t, err)
+
+		runOnce(t, s)
+		require.Equal(t, RunStatusFailed, getRun(t, s, slow.ID).Status)
+		require.Contains(t, getRun(t, s, slow.ID).Error, "timed out after 10ms")
+		require.Equal(t, RunStatusFailed, getRun(t, s, panicked.ID).Status)
+		require.Contains(t, getRun(t, s, panicked.ID).Error, "panic: oops")
+	})
+
+	t.Run("scheduled job is enqueued once per schedule", func(t *testing.T) {
+		s := newService(t)
+		runs := 0
+		require.NoError(t, s.Register(Definition{Name: "job", Schedule: "@every 10m", Handler: func(ctx context.Context, payload []byte) error {
+			runs++
+			return nil
+		}}))
+		other := newService(t)
+		other.store = s.store
+		require.NoError(t, other.Register(Definition{Name: "job", Schedule: "@every 10m", Handler: func(ctx context.Context, payload []byte) error {
+			runs++
+			return nil
+		}}))
+
+		require.NoError(t, s.scheduleDue(context.Background()))
+		require.Empty(t, runOnce(t, s), "the first run is on the schedule")
+
+		now = now.Add(10 * time.Minute)
+		require.NoError(t, s.scheduleDue(context.Background()))
+		require.NoError(t, other.scheduleDue(context.Background()))
+		runOnce(t, s)
+		runOnce(t, other)
+		require.Equal(t, 1, runs)
+
+		infos, err := s.Jobs(context.Background())
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		require.Equal(t, now.Add(10*time.Minute).Unix(), infos[0].NextRun)
+		require.NotNil(t, infos[0].LastRun)
+		require.Nil(t, infos[0].LastFailure)
+	})
+
+	t.Run("lost run is recovered", func(t *testing.T) {
+		s := newService(t)
+		require.NoError(t, s.Register(Definition{Name: "job", Timeout: time.Minute, MaxAttempts: 2, Handler: func(ctx context.Context, payload []byte) error {
+			return nil
+		}}))
+		run, err := s.Enqueue(context.Background(), "job", nil)
+		require.NoError(t, err)
+		claimed, err := s.claimDue(context.Background(), 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+
+		// The instance running the job stopped, the run is recovered once its lock expires.
+		require.NoError(t, s.recoverLost(context.Background()))
+		require.Equal(t, RunStatusRunning, getRun(t, s, run.ID).Status)
+		now = now.Add(time.Minute + lockGrace + time.Second)
+		require.NoError(t, s.recoverLost(context.Background()))
+		require.Equal(t, RunStatusPending, getRun(t, s, run.ID).Status)
+
+		runOnce(t, s)
+		recovered := getRun(t, s, run.ID)
+		require.Equal(t, RunStatusSucceeded, recovered.Status)
+		require.Equal(t, 2, recovered.Attempts)
+
+		deleted, err := s.deleteRunsBefore(context.Background(), now.Add(time.Second).Unix())
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+	})
+}
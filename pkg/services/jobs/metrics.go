@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	runs     *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		runs: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "jobs",
+			Name:      "runs_total",
+			Help:      "Number of attempts of job runs by job and result.",
+		}, []string{"job", "result"}),
+		duration: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "jobs",
+			Name:      "run_duration_seconds",
+			Help:      "Duration of the attempts of job runs by job.",
+			Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900},
+		}, []string{"job"}),
+	}
+}
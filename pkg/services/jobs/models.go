@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrInvalidJob        = errors.New("invalid job")
+	ErrAlreadyRegistered = errors.New("job already registered")
+)
+
+// Handler runs a job with the payload it was enqueued with. The context is cancelled when the job times out.
+type Handler func(ctx context.Context, payload []byte) error
+
+// Definition is a job which can be enqueued, and is enqueued on its schedule if it has one.
+type Definition struct {
+	// Name identifies the job, such as "cleanup.delete-expired-snapshots".
+	Name        string
+	Description string
+	// Schedule is a cron expression such as "0 3 * * *", or a descriptor such as "@every 10m", in UTC. The jobs
+	// without a schedule only run when they are enqueued.
+	Schedule string
+	// Timeout is the timeout of a run. Defaults to the default timeout of the settings.
+	Timeout time.Duration
+	// MaxAttempts is how many times a run is attempted before it fails. Defaults to 1, no retry.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled on each retry. Defaults to 30 seconds.
+	RetryBackoff time.Duration
+	Handler      Handler
+}
+
+type RunStatus string
+
+const (
+	RunStatusPending   RunStatus = "pending"
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+)
+
+// Run is a run of a job, queued until a worker runs it.
+type Run struct {
+	ID      int64  `xorm:"pk autoincr 'id'" json:"id"`
+	Name    string `xorm:"name" json:"name"`
+	Payload string `xorm:"payload" json:"-"`
+	// Manual is true for the runs enqueued outside of the schedule of the job.
+	Manual      bool      `xorm:"manual" json:"manual"`
+	Status      RunStatus `xorm:"status" json:"status"`
+	Attempts    int       `xorm:"attempts" json:"attempts"`
+	MaxAttempts int       `xorm:"max_attempts" json:"maxAttempts"`
+	// Error is the error of the last attempt.
+	Error   string `xorm:"error_message" json:"error,omitempty"`
+	Created int64  `xorm:"created" json:"created"`
+	// RunAt is when the run is due, which is delayed on a retry.
+	RunAt    int64 `xorm:"run_at" json:"runAt"`
+	Started  int64 `xorm:"started" json:"started"`
+	Finished int64 `xorm:"finished" json:"finished"`
+	// LockedUntil is when a running run is considered lost, if the instance running it stopped.
+	LockedUntil int64 `xorm:"locked_until" json:"-"`
+}
+
+func (Run) TableName() string {
+	return "job_run"
+}
+
+// schedule is the next scheduled run of a job, shared by the instances.
+type schedule struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	Name     string `xorm:"name"`
+	Schedule string `xorm:"schedule"`
+	NextRun  int64  `xorm:"next_run"`
+}
+
+func (schedule) TableName() string {
+	return "job_schedule"
+}
+
+// JobInfo is the state of a job for the admin API.
+type JobInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Schedule    string `json:"schedule,omitempty"`
+	Timeout     string `json:"timeout"`
+	MaxAttempts int    `json:"maxAttempts"`
+	// NextRun is when the job is next enqueued on its schedule, in epoch seconds.
+	NextRun int64 `json:"nextRun,omitempty"`
+	Pending int64 `json:"pending"`
+	Running int64 `json:"running"`
+	// Failures is the number of failed runs in the run history.
+	Failures    int64 `json:"failures"`
+	LastRun     *Run  `json:"lastRun,omitempty"`
+	LastFailure *Run  `json:"lastFailure,omitempty"`
+}
+
+type RunsQuery struct {
+	Name   string
+	Status RunStatus
+	Limit  int
+}
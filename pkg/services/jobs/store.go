@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) getSchedule(ctx context.Context, name string) (*schedule, error) {
+	sched := &schedule{}
+	exists := false
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("name = ?", name).Get(sched)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// insertSchedule inserts the schedule of a job, unless another instance already inserted it.
+func (s *Service) insertSchedule(ctx context.Context, sched *schedule) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(sched)
+		if err != nil && s.store.GetDialect().IsUniqueConstraintViolation(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// claimSchedule moves the next run of a job, and returns false if another instance already moved it.
+func (s *Service) claimSchedule(ctx context.Context, sched *schedule, spec string, nextRun int64) (bool, error) {
+	claimed := false
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE job_schedule SET schedule = ?, next_run = ? WHERE id = ? AND schedule = ? AND next_run = ?",
+			spec, nextRun, sched.ID, sched.Schedule, sched.NextRun)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	return claimed, err
+}
+
+// countActive returns the number of pending and running runs of a job.
+func (s *Service) countActive(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("name = ?", name).In("status", RunStatusPending, RunStatusRunning).Count(&Run{})
+		return err
+	})
+	return count, err
+}
+
+func (s *Service) insertRun(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(run)
+		return err
+	})
+}
+
+// claimDue marks up to limit due runs of the registered jobs as running, and returns them. A run is claimed by
+// one instance when several instances share the database.
+func (s *Service) claimDue(ctx context.Context, limit int) ([]*Run, error) {
+	names := make([]any, 0)
+	for _, d := range s.sortedDefinitions() {
+		names = append(names, d.Name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	now := s.now().Unix()
+	claimed := make([]*Run, 0, limit)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		due := make([]*Run, 0)
+		// More runs than needed are listed, since some may be claimed by another instance meanwhile.
+		if err := sess.Where("status = ? AND run_at <= ?", RunStatusPending, now).In("name", names...).
+			Asc("run_at").Asc("id").Limit(limit * 2).Find(&due); err != nil {
+			return err
+		}
+		for _, run := range due {
+			if len(claimed) == limit {
+				break
+			}
+			d, ok := s.definition(run.Name)
+			if !ok {
+				continue
+			}
+			lockedUntil := now + int64((d.Timeout + lockGrace).Seconds())
+			res, err := sess.Exec("UPDATE job_run SET status = ?, attempts = ?, started = ?, locked_until = ? WHERE id = ? AND status = ?",
+				RunStatusRunning, run.Attempts+1, now, lockedUntil, run.ID, RunStatusPending)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected == 1 {
+				run.Status = RunStatusRunning
+				run.Attempts++
+				run.Started = now
+				run.LockedUntil = lockedUntil
+				claimed = append(claimed, run)
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// finish stores the result of an attempt of a run: succeeded, failed, or pending for a retry after the backoff.
+func (s *Service) finish(ctx context.Context, run *Run, d *definition, status RunStatus, runErr error) error {
+	now := s.now().Unix()
+	run.Status = status
+	run.Error = ""
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	if status == RunStatusPending {
+		run.RunAt = now + int64(retryBackoff(d, run.Attempts).Seconds())
+	} else {
+		run.Finished = now
+	}
+	run.LockedUntil = 0
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		// The run is only updated if it wasn't recovered as lost meanwhile.
+		_, err := sess.Exec("UPDATE job_run SET status = ?, error_message = ?, run_at = ?, finished = ?, locked_until = 0 WHERE id = ? AND status = ? AND attempts = ?",
+			run.Status, run.Error, run.RunAt, run.Finished, run.ID, RunStatusRunning, run.Attempts)
+		return err
+	})
+}
+
+// release puts back a run interrupted by the shutdown of Grafana, without counting the interrupted attempt.
+func (s *Service) release(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE job_run SET status = ?, attempts = ?, locked_until = 0 WHERE id = ? AND status = ? AND attempts = ?",
+			RunStatusPending, run.Attempts-1, run.ID, RunStatusRunning, run.Attempts)
+		return err
+	})
+}
+
+// recoverLost puts back the running runs whose lock expired, because the instance running them stopped, for
+// another attempt if they have attempts left and fails them otherwise.
+func (s *Service) recoverLost(ctx context.Context) error {
+	now := s.now().Unix()
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("UPDATE job_run SET status = ?, run_at = ?, locked_until = 0 WHERE status = ? AND locked_until < ? AND attempts < max_attempts",
+			RunStatusPending, now, RunStatusRunning, now); err != nil {
+			return err
+		}
+		_, err := sess.Exec("UPDATE job_run SET status = ?, error_message = ?, finished = ?, locked_until = 0 WHERE status = ? AND locked_until < ?",
+			RunStatusFailed, "the run was lost, the instance running it stopped", now, RunStatusRunning, now)
+		return err
+	})
+}
+
+// countByStatus returns the number of runs of each job by status.
+func (s *Service) countByStatus(ctx context.Context) (map[string]map[RunStatus]int64, error) {
+	type statusCount struct {
+		Name   string    `xorm:"name"`
+		Status RunStatus `xorm:"status"`
+		Count  int64     `xorm:"count"`
+	}
+	rows := make([]statusCount, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT name, status, COUNT(*) AS count FROM job_run GROUP BY name, status").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]map[RunStatus]int64{}
+	for _, row := range rows {
+		if counts[row.Name] == nil {
+			counts[row.Name] = map[RunStatus]int64{}
+		}
+		counts[row.Name][row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// lastRun returns the last finished run of a job with one of the statuses, or nil.
+func (s *Service) lastRun(ctx context.Context, name string, statuses ...RunStatus) (*Run, error) {
+	args := make([]any, 0, len(statuses))
+	for _, status := range statuses {
+		args = append(args, status)
+	}
+	run := &Run{}
+	exists := false
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("name = ?", name).In("status", args...).Desc("finished").Desc("id").Limit(1).Get(run)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return run, nil
+}
+
+// listRuns returns the runs matching the query, the most recent first.
+func (s *Service) listRuns(ctx context.Context, query RunsQuery) ([]*Run, error) {
+	runs := make([]*Run, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if query.Name != "" {
+			sess.Where("name = ?", query.Name)
+		}
+		if query.Status != "" {
+			sess.And("status = ?", query.Status)
+		}
+		return sess.Desc("created").Desc("id").Limit(query.Limit).Find(&runs)
+	})
+	return runs, err
+}
+
+// deleteRunsBefore deletes the runs finished before a time in epoch seconds.
+func (s *Service) deleteRunsBefore(ctx context.Context, before int64) (int64, error) {
+	var affected int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affected, err = sess.Where("finished > 0 AND finished < ?", before).
+			In("status", RunStatusSucceeded, RunStatusFailed).Delete(&Run{})
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,150 @@
+package hashicorpvault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Kind is the provider kind of Hashicorp Vault transit engine keys, configured in
+// [security.encryption.hashicorpvault.<key name>] sections.
+const Kind = "hashicorpvault"
+
+var (
+	_ secrets.Provider           = (*provider)(nil)
+	_ secrets.BackgroundProvider = (*provider)(nil)
+)
+
+type provider struct {
+	client          *http.Client
+	url             string
+	token           string
+	transitPath     string
+	keyRing         string
+	renewalInterval time.Duration
+	log             log.Logger
+}
+
+// New returns a provider encrypting data keys with a named key of the Vault transit secrets engine.
+// The token is renewed in the background, so it should be a periodic service token.
+func New(section *setting.DynamicSection) (secrets.Provider, error) {
+	p := &provider{
+		client:          &http.Client{Timeout: 30 * time.Second},
+		url:             strings.TrimSuffix(section.Key("url").MustString(""), "/"),
+		token:           section.Key("token").MustString(""),
+		transitPath:     strings.Trim(section.Key("transit_engine_path").MustString("transit"), "/"),
+		keyRing:         section.Key("key_ring").MustString(""),
+		renewalInterval: section.Key("token_renewal_interval").MustDuration(5 * time.Minute),
+		log:             log.New("kmsproviders.hashicorpvault"),
+	}
+
+	if _, err := url.ParseRequestURI(p.url); err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if p.token == "" {
+		return nil, fmt.Errorf("missing token")
+	}
+	if p.keyRing == "" {
+		return nil, fmt.Errorf("missing key_ring")
+	}
+
+	return p, nil
+}
+
+func (p *provider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(blob)}
+	if err := p.do(ctx, fmt.Sprintf("%s/encrypt/%s", p.transitPath, url.PathEscape(p.keyRing)), body, &resp); err != nil {
+		return nil, err
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (p *provider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(blob)}
+	if err := p.do(ctx, fmt.Sprintf("%s/decrypt/%s", p.transitPath, url.PathEscape(p.keyRing)), body, &resp); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+// Run renews the token periodically, so that it doesn't expire while Grafana is running.
+func (p *provider) Run(ctx context.Context) error {
+	if p.renewalInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.renewalInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.do(ctx, "auth/token/renew-self", map[string]string{}, nil); err != nil {
+				p.log.Error("Failed to renew the Vault token", "error", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *provider) do(ctx context.Context, path string, body any, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/%s", p.url, path), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			p.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault request failed with status %d: %s", resp.StatusCode, strings.Join(vaultErr.Errors, ", "))
+		}
+		return fmt.Errorf("vault request failed with status %d", resp.StatusCode)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,81 @@
+package hashicorpvault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/grafana":
+			_, _ = w.Write([]byte(`{"data":{"ciphertext":"vault:v1:` + body["plaintext"] + `"}}`))
+		case "/v1/transit/decrypt/grafana":
+			_, _ = w.Write([]byte(`{"data":{"plaintext":"` + strings.TrimPrefix(body["ciphertext"], "vault:v1:") + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	newProvider := func(t *testing.T, token string) *provider {
+		raw, err := ini.Load([]byte(`
+			[security.encryption.hashicorpvault.v1]
+			url = ` + server.URL + `
+			token = ` + token + `
+			key_ring = grafana`))
+		require.NoError(t, err)
+
+		p, err := New((&setting.Cfg{Raw: raw}).SectionWithEnvOverrides("security.encryption.hashicorpvault.v1"))
+		require.NoError(t, err)
+		return p.(*provider)
+	}
+
+	t.Run("encrypts and decrypts with the transit engine", func(t *testing.T) {
+		p := newProvider(t, "token")
+
+		encrypted, err := p.Encrypt(context.Background(), []byte("data key"))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(encrypted), "vault:v1:"))
+
+		decrypted, err := p.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		require.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("returns the errors of vault", func(t *testing.T) {
+		p := newProvider(t, "other")
+
+		_, err := p.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "permission denied")
+	})
+
+	t.Run("requires a key ring", func(t *testing.T) {
+		raw, err := ini.Load([]byte(`
+			[security.encryption.hashicorpvault.v1]
+			url = ` + server.URL + `
+			token = token`))
+		require.NoError(t, err)
+
+		_, err = New((&setting.Cfg{Raw: raw}).SectionWithEnvOverrides("security.encryption.hashicorpvault.v1"))
+		require.Error(t, err)
+	})
+}
@@ -1,10 +1,18 @@
 package osskmsproviders
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/awskms"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/googlekms"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/hashicorpvault"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -13,6 +21,7 @@ type Service struct {
 	enc      encryption.Internal
 	cfg      *setting.Cfg
 	features featuremgmt.FeatureToggles
+	log      log.Logger
 }
 
 func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featuremgmt.FeatureToggles) Service {
@@ -20,11 +29,49 @@ func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featurem
 		enc:      enc,
 		cfg:      cfg,
 		features: features,
+		log:      log.New("kmsproviders"),
 	}
 }
 
+// Provide returns the default provider along with the external providers listed in
+// available_encryption_providers, each configured in its [security.encryption.<provider>.<key name>] section.
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
-	return map[secrets.ProviderID]secrets.Provider{
+	providers := map[secrets.ProviderID]secrets.Provider{
 		kmsproviders.Default: grafana.New(s.cfg, s.enc),
-	}, nil
+	}
+
+	available := s.cfg.SectionWithEnvOverrides("security").Key("available_encryption_providers").MustString("")
+	for _, id := range strings.Fields(available) {
+		providerID := kmsproviders.NormalizeProviderID(secrets.ProviderID(id))
+		if providerID == kmsproviders.Default {
+			continue
+		}
+
+		kind, err := providerID.Kind()
+		if err != nil {
+			return nil, err
+		}
+
+		section := s.cfg.SectionWithEnvOverrides(fmt.Sprintf("security.encryption.%s", providerID))
+
+		var provider secrets.Provider
+		switch kind {
+		case awskms.Kind:
+			provider, err = awskms.New(section)
+		case googlekms.Kind:
+			provider, err = googlekms.New(context.Background(), section)
+		case hashicorpvault.Kind:
+			provider, err = hashicorpvault.New(section)
+		default:
+			s.log.Warn("Skipping unsupported encryption provider", "provider", providerID)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure encryption provider %s: %w", providerID, err)
+		}
+
+		providers[providerID] = provider
+	}
+
+	return providers, nil
 }
@@ -0,0 +1,49 @@
+package teamsync
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	routeRegister.Group("/api/admin/ldap/team-sync", func(teamSyncRoute routing.RouteRegister) {
+		teamSyncRoute.Get("/drift", authorize(ac.EvalPermission(ac.ActionLDAPUsersRead)), routing.Wrap(s.getDriftHandler))
+		teamSyncRoute.Post("/", authorize(ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(s.syncHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// getDriftHandler returns the members the sync would add to and remove from the teams, of all the groups
+// or of the group of the groupDn query parameter.
+func (s *Service) getDriftHandler(c *contextmodel.ReqContext) response.Response {
+	return s.sync(c, true)
+}
+
+// syncHandler syncs the teams now, of all the groups or of the group of the groupDn query parameter. With
+// the dryRun query parameter, it only returns the drift.
+func (s *Service) syncHandler(c *contextmodel.ReqContext) response.Response {
+	return s.sync(c, c.QueryBool("dryRun"))
+}
+
+func (s *Service) sync(c *contextmodel.ReqContext, dryRun bool) response.Response {
+	groups := s.Groups(c.Query("groupDn"))
+	if len(groups) == 0 {
+		return response.Error(http.StatusNotFound, "LDAP group not found", nil)
+	}
+
+	report, err := s.Sync(c.Req.Context(), groups, dryRun)
+	if err != nil {
+		if errors.Is(err, errNoLDAPUsers) {
+			return response.Error(http.StatusServiceUnavailable, "No user found in LDAP", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to sync the teams with LDAP", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
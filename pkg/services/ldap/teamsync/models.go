@@ -0,0 +1,48 @@
+package teamsync
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidGroup = errors.New("invalid LDAP team sync group")
+
+// Group syncs the members of a team with the members of an LDAP group.
+type Group struct {
+	// GroupDN is the DN of the LDAP group, as in the memberOf attribute of its members.
+	GroupDN string `yaml:"groupDn"`
+	OrgID   int64  `yaml:"orgId"`
+	// Team is the name of the team of the organization.
+	Team string `yaml:"team"`
+	// Interval is how often the team is synced, the default interval of the settings when it's 0.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Config is the content of the LDAP team sync config file.
+type Config struct {
+	Groups []Group `yaml:"groups"`
+}
+
+// Member is a user added to or removed from a team.
+type Member struct {
+	UserID int64  `json:"userId"`
+	Login  string `json:"login"`
+}
+
+// TeamDrift is how the members of a team differ from the members of its LDAP groups.
+type TeamDrift struct {
+	OrgID    int64    `json:"orgId"`
+	Team     string   `json:"team"`
+	TeamID   int64    `json:"teamId,omitempty"`
+	Groups   []string `json:"groups"`
+	ToAdd    []Member `json:"toAdd"`
+	ToRemove []Member `json:"toRemove"`
+	// Error is why the team couldn't be compared or synced.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a sync, or of a dry run when DryRun is true.
+type Report struct {
+	DryRun bool        `json:"dryRun"`
+	Teams  []TeamDrift `json:"teams"`
+}
@@ -0,0 +1,478 @@
+package teamsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// tickInterval is how often the service looks for the groups to sync, so it's the minimum interval of a group.
+	tickInterval = time.Minute
+	lockPrefix   = "ldap-team-sync-"
+	// syncLeaseName is the lease held by the instance running the sync, renewed while the sync runs.
+	syncLeaseName = "ldap-team-sync"
+	syncLeaseTTL  = 2 * time.Minute
+)
+
+var errNoLDAPUsers = errors.New("no user found in LDAP, the sync is skipped to not empty the teams")
+
+// Service syncs the members of the teams with the members of LDAP groups on schedule, so that the users
+// who join or leave a group join or leave its team without logging in again. Only the users who log in
+// with LDAP are removed from the teams; the members added by hand are kept.
+type Service struct {
+	settings        setting.LDAPTeamSyncSettings
+	ldapEnabled     bool
+	ldapService     service.LDAP
+	store           db.DB
+	teamService     team.Service
+	teamPermissions accesscontrol.TeamPermissionsService
+	serverLock      *serverlock.ServerLockService
+	log             log.Logger
+	groups          []Group
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	ldapService service.LDAP, teamService team.Service, teamPermissions accesscontrol.TeamPermissionsService,
+	serverLock *serverlock.ServerLockService) (*Service, error) {
+	s := &Service{
+		settings:        cfg.LDAPTeamSync,
+		ldapEnabled:     cfg.LDAPAuthEnabled,
+		ldapService:     ldapService,
+		store:           sqlStore,
+		teamService:     teamService,
+		teamPermissions: teamPermissions,
+		serverLock:      serverLock,
+		log:             log.New("ldap.team-sync"),
+	}
+	if s.IsDisabled() {
+		return s, nil
+	}
+
+	groups, err := loadGroups(s.settings.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	s.groups = groups
+
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	return s, nil
+}
+
+func loadGroups(file string) ([]Group, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path comes from the Grafana configuration
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LDAP team sync config file %q: %w", file, err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse LDAP team sync config file %q: %w", file, err)
+	}
+	for i, group := range config.Groups {
+		if err := validateGroup(group); err != nil {
+			return nil, fmt.Errorf("group %d of %q: %w", i+1, file, err)
+		}
+	}
+	return config.Groups, nil
+}
+
+func validateGroup(group Group) error {
+	if group.GroupDN == "" {
+		return fmt.Errorf("%w: missing groupDn", ErrInvalidGroup)
+	}
+	if group.OrgID < 1 {
+		return fmt.Errorf("%w: missing orgId", ErrInvalidGroup)
+	}
+	if group.Team == "" {
+		return fmt.Errorf("%w: missing team for group %q", ErrInvalidGroup, group.GroupDN)
+	}
+	if group.Interval != 0 && group.Interval < tickInterval {
+		return fmt.Errorf("%w: the interval of group %q is shorter than %s", ErrInvalidGroup, group.GroupDN, tickInterval)
+	}
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled || !s.ldapEnabled
+}
+
+// Run syncs the teams of the groups whose interval elapsed.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// The lease ensures that one instance syncs at a time, even when a sync takes longer than the interval.
+			err := s.serverLock.LeaseAndExecute(ctx, syncLeaseName, syncLeaseTTL, func(ctx context.Context, lease *serverlock.Lease) {
+				s.syncDue(ctx, lease)
+			})
+			var heldErr *serverlock.LeaseHeldError
+			if err != nil && !errors.As(err, &heldErr) {
+				s.log.Warn("Failed to acquire the lease of the LDAP team sync", "error", err)
+			}
+		}
+	}
+}
+
+// syncDue syncs the teams of the groups whose interval elapsed, with the fencing token of the lease.
+func (s *Service) syncDue(ctx context.Context, lease *serverlock.Lease) {
+	due := s.dueGroups(ctx)
+	if len(due) == 0 {
+		return
+	}
+	report, err := s.sync(ctx, due, false, lease)
+	if err != nil {
+		s.log.Error("Failed to sync the teams with LDAP", "error", err)
+		return
+	}
+	for _, drift := range report.Teams {
+		if drift.Error != "" {
+			s.log.Error("Failed to sync team with LDAP", "orgID", drift.OrgID, "team", drift.Team, "error", drift.Error)
+		} else if len(drift.ToAdd)+len(drift.ToRemove) > 0 {
+			s.log.Info("Synced team with LDAP", "orgID", drift.OrgID, "team", drift.Team, "added", len(drift.ToAdd), "removed", len(drift.ToRemove))
+		}
+	}
+}
+
+// dueGroups returns the groups which no Grafana instance synced during their interval.
+func (s *Service) dueGroups(ctx context.Context) []Group {
+	due := make([]Group, 0)
+	for _, group := range s.groups {
+		err := s.serverLock.LockAndExecute(ctx, lockName(group), s.interval(group), func(context.Context) {
+			due = append(due, group)
+		})
+		if err != nil {
+			s.log.Warn("Failed to lock the sync of LDAP group", "group", group.GroupDN, "error", err)
+		}
+	}
+	return due
+}
+
+func (s *Service) interval(group Group) time.Duration {
+	if group.Interval == 0 {
+		return s.settings.DefaultInterval
+	}
+	return group.Interval
+}
+
+// lockName is the name of the server lock of the group, hashed to fit the lock table whatever the length of the DN.
+func lockName(group Group) string {
+	return fmt.Sprintf("%s%x", lockPrefix, sha256.Sum256([]byte(fmt.Sprintf("%d/%s/%s", group.OrgID, group.Team, group.GroupDN))))[:len(lockPrefix)+32]
+}
+
+// Groups returns the groups matching the DN, or all the groups when it's empty.
+func (s *Service) Groups(groupDN string) []Group {
+	if groupDN == "" {
+		return s.groups
+	}
+	groups := make([]Group, 0)
+	for _, group := range s.groups {
+		if strings.EqualFold(group.GroupDN, groupDN) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+type teamKey struct {
+	orgID int64
+	name  string
+}
+
+// Sync syncs the members of the teams of the groups with the members of all the LDAP groups of the teams.
+// When dryRun is true, it only reports the members it would add and remove.
+func (s *Service) Sync(ctx context.Context, groups []Group, dryRun bool) (*Report, error) {
+	return s.sync(ctx, groups, dryRun, nil)
+}
+
+// sync syncs the members of the teams. When the sync runs under a lease, the memberships are only changed
+// while the lease is held, so that a former holder doesn't undo the changes of the next sync.
+func (s *Service) sync(ctx context.Context, groups []Group, dryRun bool, lease *serverlock.Lease) (*Report, error) {
+	report := &Report{DryRun: dryRun, Teams: make([]TeamDrift, 0)}
+	if len(groups) == 0 {
+		return report, nil
+	}
+
+	users, err := s.ldapUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// a team synced with several groups is synced with all of them, whichever of them is due
+	keys := make([]teamKey, 0)
+	groupDNs := make(map[teamKey][]string)
+	for _, group := range groups {
+		key := teamKey{orgID: group.OrgID, name: group.Team}
+		if _, ok := groupDNs[key]; !ok {
+			keys = append(keys, key)
+			groupDNs[key] = []string{}
+		}
+	}
+	for _, group := range s.groups {
+		key := teamKey{orgID: group.OrgID, name: group.Team}
+		if dns, ok := groupDNs[key]; ok {
+			groupDNs[key] = append(dns, group.GroupDN)
+		}
+	}
+
+	for _, key := range keys {
+		report.Teams = append(report.Teams, s.syncTeam(ctx, key, groupDNs[key], users, dryRun, lease))
+	}
+	return report, nil
+}
+
+func (s *Service) syncTeam(ctx context.Context, key teamKey, groupDNs []string, users []ldapUser, dryRun bool, lease *serverlock.Lease) TeamDrift {
+	drift := TeamDrift{OrgID: key.orgID, Team: key.name, Groups: groupDNs, ToAdd: []Member{}, ToRemove: []Member{}}
+
+	teamID, err := s.getTeamID(ctx, key.orgID, key.name)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift
+	}
+	drift.TeamID = teamID
+
+	// the members are read on behalf of the team sync, which can read all the users of the org
+	requester := &user.SignedInUser{
+		OrgID: key.orgID,
+		Permissions: map[int64]map[string][]string{
+			key.orgID: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}},
+		},
+	}
+	members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: key.orgID, TeamID: teamID, SignedInUser: requester})
+	if err != nil {
+		drift.Error = fmt.Sprintf("failed to get the members of the team: %s", err)
+		return drift
+	}
+
+	toAdd, toRemove := diff(groupDNs, users, members)
+
+	// the users who are not members of the org are not added to its teams
+	orgMembers, err := s.getOrgMembers(ctx, key.orgID, toAdd)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift
+	}
+	for _, member := range toAdd {
+		if _, ok := orgMembers[member.UserID]; ok {
+			drift.ToAdd = append(drift.ToAdd, member)
+		}
+	}
+	drift.ToRemove = toRemove
+
+	if dryRun {
+		return drift
+	}
+
+	var errs []error
+	// set changes a membership, and returns false once the lease is lost
+	set := func(member Member, isMember bool) bool {
+		err := s.guard(ctx, lease, func(ctx context.Context) error {
+			return s.setTeamMembership(ctx, key.orgID, teamID, member.UserID, isMember)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return !errors.Is(err, serverlock.ErrLeaseLost)
+	}
+	ok := true
+	for _, member := range drift.ToAdd {
+		if ok = set(member, true); !ok {
+			break
+		}
+	}
+	for _, member := range drift.ToRemove {
+		if !ok || !set(member, false) {
+			break
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		drift.Error = err.Error()
+	}
+	return drift
+}
+
+// diff returns the users of the groups who are not members of the team, and the members of the team
+// who log in with LDAP and are not in the groups.
+func diff(groupDNs []string, users []ldapUser, members []*team.TeamMemberDTO) ([]Member, []Member) {
+	wanted := make(map[string]struct{}, len(groupDNs))
+	for _, dn := range groupDNs {
+		wanted[strings.ToLower(dn)] = struct{}{}
+	}
+
+	desired := make(map[int64]struct{})
+	for _, u := range users {
+		for _, group := range u.groups {
+			if _, ok := wanted[group]; ok {
+				desired[u.id] = struct{}{}
+				break
+			}
+		}
+	}
+
+	current := make(map[int64]struct{}, len(members))
+	toRemove := make([]Member, 0)
+	for _, member := range members {
+		current[member.UserID] = struct{}{}
+		if _, ok := desired[member.UserID]; !ok && member.AuthModule == login.LDAPAuthModule {
+			toRemove = append(toRemove, Member{UserID: member.UserID, Login: member.Login})
+		}
+	}
+	sort.Slice(toRemove, func(i, j int) bool { return toRemove[i].UserID < toRemove[j].UserID })
+
+	toAdd := make([]Member, 0)
+	for _, u := range users {
+		_, isDesired := desired[u.id]
+		_, isMember := current[u.id]
+		if isDesired && !isMember {
+			toAdd = append(toAdd, Member{UserID: u.id, Login: u.login})
+		}
+	}
+	return toAdd, toRemove
+}
+
+// guard executes fn with the fencing token of the lease, or as is without lease.
+func (s *Service) guard(ctx context.Context, lease *serverlock.Lease, fn func(ctx context.Context) error) error {
+	if lease == nil {
+		return fn(ctx)
+	}
+	return lease.Guard(ctx, fn)
+}
+
+// setTeamMembership adds the user to the team, or removes them from it.
+func (s *Service) setTeamMembership(ctx context.Context, orgID, teamID, userID int64, member bool) error {
+	permission := ""
+	if member {
+		permission = team.PermissionTypeMember.String()
+	}
+	if _, err := s.teamPermissions.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, strconv.FormatInt(teamID, 10), permission); err != nil {
+		return fmt.Errorf("failed to set the membership of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// ldapUser is a Grafana user who logs in with LDAP, with the lowercased DNs of their LDAP groups.
+type ldapUser struct {
+	id     int64
+	login  string
+	groups []string
+}
+
+// ldapUsers returns the Grafana users who log in with LDAP, with their current groups in LDAP. The users
+// who are not found in LDAP anymore have no group.
+func (s *Service) ldapUsers(ctx context.Context) ([]ldapUser, error) {
+	type userRow struct {
+		ID    int64  `xorm:"id"`
+		Login string `xorm:"login"`
+	}
+	rows := make([]userRow, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(`SELECT DISTINCT u.id, u.login FROM user_auth
+			INNER JOIN `+s.store.GetDialect().Quote("user")+` AS u ON u.id = user_auth.user_id
+			WHERE user_auth.auth_module = ? ORDER BY u.id`, login.LDAPAuthModule).Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the users who log in with LDAP: %w", err)
+	}
+	if len(rows) == 0 {
+		return []ldapUser{}, nil
+	}
+
+	client := s.ldapService.Client()
+	if client == nil {
+		return nil, errors.New("failed to find the LDAP server")
+	}
+	logins := make([]string, 0, len(rows))
+	for _, row := range rows {
+		logins = append(logins, row.Login)
+	}
+	infos, err := client.Users(logins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search the users in LDAP: %w", err)
+	}
+	// an unavailable server returns no user rather than an error, which would remove every user from the teams
+	if len(infos) == 0 {
+		return nil, errNoLDAPUsers
+	}
+
+	// a user can be found in several servers
+	groupsByLogin := make(map[string][]string, len(infos))
+	for _, info := range infos {
+		key := strings.ToLower(info.Login)
+		for _, group := range info.Groups {
+			groupsByLogin[key] = append(groupsByLogin[key], strings.ToLower(group))
+		}
+	}
+
+	users := make([]ldapUser, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, ldapUser{id: row.ID, login: row.Login, groups: groupsByLogin[strings.ToLower(row.Login)]})
+	}
+	return users, nil
+}
+
+func (s *Service) getTeamID(ctx context.Context, orgID int64, name string) (int64, error) {
+	var teamID int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		found, err := sess.SQL("SELECT id FROM team WHERE org_id = ? AND name = ?", orgID, name).Get(&teamID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return team.ErrTeamNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team %q in org %d: %w", name, orgID, err)
+	}
+	return teamID, nil
+}
+
+func (s *Service) getOrgMembers(ctx context.Context, orgID int64, members []Member) (map[int64]struct{}, error) {
+	result := make(map[int64]struct{}, len(members))
+	if len(members) == 0 {
+		return result, nil
+	}
+
+	params := make([]any, 0, len(members)+1)
+	params = append(params, orgID)
+	for _, member := range members {
+		params = append(params, member.UserID)
+	}
+	userIDs := make([]int64, 0, len(members))
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT user_id FROM org_user WHERE org_id = ? AND user_id IN (?"+strings.Repeat(", ?", len(members)-1)+")", params...).Find(&userIDs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the members of org %d: %w", orgID, err)
+	}
+	for _, id := range userIDs {
+		result[id] = struct{}{}
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package teamsync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestLoadGroups(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		file := filepath.Join(t.TempDir(), "ldap_team_sync.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+		return file
+	}
+
+	groups, err := loadGroups(write(t, `
+groups:
+  - groupDn: cn=admins,ou=groups,dc=grafana,dc=org
+    orgId: 1
+    team: Admins
+    interval: 15m
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Group{{GroupDN: "cn=admins,ou=groups,dc=grafana,dc=org", OrgID: 1, Team: "Admins", Interval: 15 * time.Minute}}, groups)
+
+	_, err = loadGroups(write(t, `groups: [{orgId: 1, team: Admins}]`))
+	require.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = loadGroups(write(t, `groups: [{groupDn: "cn=admins", team: Admins}]`))
+	require.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = loadGroups(write(t, `groups: [{groupDn: "cn=admins", orgId: 1}]`))
+	require.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = loadGroups(write(t, `groups: [{groupDn: "cn=admins", orgId: 1, team: Admins, interval: 10s}]`))
+	require.ErrorIs(t, err, ErrInvalidGroup)
+}
+
+func TestDiff(t *testing.T) {
+	users := []ldapUser{
+		{id: 1, login: "alice", groups: []string{"cn=admins,dc=grafana,dc=org"}},
+		{id: 2, login: "bob", groups: []string{"cn=editors,dc=grafana,dc=org"}},
+		{id: 3, login: "carol", groups: []string{"cn=viewers,dc=grafana,dc=org"}},
+		{id: 4, login: "dave"},
+	}
+	members := []*team.TeamMemberDTO{
+		{UserID: 2, Login: "bob", AuthModule: login.LDAPAuthModule},
+		{UserID: 4, Login: "dave", AuthModule: login.LDAPAuthModule},
+		{UserID: 3, Login: "carol", AuthModule: login.LDAPAuthModule},
+		{UserID: 5, Login: "erin"},
+	}
+
+	toAdd, toRemove := diff([]string{"CN=Admins,DC=grafana,DC=org", "cn=editors,dc=grafana,dc=org"}, users, members)
+	require.Equal(t, []Member{{UserID: 1, Login: "alice"}}, toAdd)
+	require.Equal(t, []Member{{UserID: 3, Login: "carol"}, {UserID: 4, Login: "dave"}}, toRemove, "the members who don't log in with LDAP are kept")
+}
+
+func TestLockName(t *testing.T) {
+	group := Group{GroupDN: "cn=" + strings.Repeat("a", 200) + ",dc=grafana,dc=org", OrgID: 1, Team: "Admins"}
+	require.LessOrEqual(t, len(lockName(group)), 100)
+	require.NotEqual(t, lockName(group), lockName(Group{GroupDN: group.GroupDN, OrgID: 2, Team: "Admins"}))
+}
@@ -1,7 +1,9 @@
 package libraryelements
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -106,7 +108,8 @@ func (l *LibraryElementService) createHandler(c *contextmodel.ReqContext) respon
 // Delete library element.
 //
 // Deletes an existing library element as specified by the UID. This operation cannot be reverted.
-// You cannot delete a library element that is connected. This operation cannot be reverted.
+// You cannot delete a library element that is connected, unless the `detach` query parameter is set: the library
+// element is then replaced with a copy of its model in the dashboards it is connected to. This operation cannot be reverted.
 //
 // Responses:
 // 200: okResponse
@@ -116,7 +119,20 @@ func (l *LibraryElementService) createHandler(c *contextmodel.ReqContext) respon
 // 404: notFoundError
 // 500: internalServerError
 func (l *LibraryElementService) deleteHandler(c *contextmodel.ReqContext) response.Response {
-	id, err := l.deleteLibraryElement(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	uid := web.Params(c.Req)[":uid"]
+	var id int64
+	// the dashboards are only detached when the library element is deleted
+	err := l.SQLStore.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		if c.QueryBool("detach") {
+			if err := l.detachLibraryElement(ctx, c.SignedInUser, uid); err != nil {
+				return fmt.Errorf("failed to detach library element: %w", err)
+			}
+		}
+
+		var err error
+		id, err = l.deleteLibraryElement(ctx, c.SignedInUser, uid)
+		return err
+	})
 	if err != nil {
 		return toLibraryElementError(err, "Failed to delete library element")
 	}
@@ -265,7 +281,9 @@ func (l *LibraryElementService) patchHandler(c *contextmodel.ReqContext) respons
 //
 // Get library element connections.
 //
-// Returns a list of connections for a library element based on the UID specified.
+// Returns a list of connections for a library element based on the UID specified, ordered by dashboard title.
+// Use the `perPage` query parameter to control the maximum number of connections returned; all of them are returned by default.
+// You can also use the `page` query parameter to fetch connections from any page other than the first one.
 //
 // Responses:
 // 200: getLibraryElementConnectionsResponse
@@ -274,12 +292,16 @@ func (l *LibraryElementService) patchHandler(c *contextmodel.ReqContext) respons
 // 404: notFoundError
 // 500: internalServerError
 func (l *LibraryElementService) getConnectionsHandler(c *contextmodel.ReqContext) response.Response {
-	connections, err := l.getConnections(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	connections, err := l.getConnections(c.Req.Context(), c.SignedInUser, model.GetLibraryElementConnectionsQuery{
+		UID:     web.Params(c.Req)[":uid"],
+		PerPage: c.QueryInt("perPage"),
+		Page:    c.QueryInt("page"),
+	})
 	if err != nil {
 		return toLibraryElementError(err, "Failed to get connections")
 	}
 
-	return response.JSON(http.StatusOK, model.LibraryElementConnectionsResponse{Result: connections})
+	return response.JSON(http.StatusOK, connections)
 }
 
 // swagger:route GET /library-elements/name/{library_element_name} library_elements getLibraryElementByName
@@ -354,6 +376,11 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, model.ErrLibraryElementUIDTooLong) {
 		return response.Error(http.StatusBadRequest, model.ErrLibraryElementUIDTooLong.Error(), err)
 	}
+	// errors of the dashboards the library element is detached from
+	var dashboardErr dashboards.DashboardErr
+	if errors.As(err, &dashboardErr) {
+		return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), err)
+	}
 	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
 }
 
@@ -376,6 +403,16 @@ type GetLibraryElementConnectionsParams struct {
 	// in:path
 	// required:true
 	UID string `json:"library_element_uid"`
+	// The number of results per page, all of them if it is 0.
+	// in:query
+	// required:false
+	// default: 0
+	PerPage int `json:"perPage"`
+	// The page for a set of records, given that only perPage records are returned at a time. Numbering starts at 1.
+	// in:query
+	// required:false
+	// default: 1
+	Page int `json:"page"`
 }
 
 // swagger:parameters deleteLibraryElementByUID
@@ -383,6 +420,11 @@ type DeleteLibraryElementByUIDParams struct {
 	// in:path
 	// required:true
 	UID string `json:"library_element_uid"`
+	// Replace the library element with a copy of its model in the dashboards it is connected to before deleting it.
+	// in:query
+	// required:false
+	// default: false
+	Detach bool `json:"detach"`
 }
 
 // swagger:parameters getLibraryElementByName
@@ -275,6 +275,99 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 	return elementID, err
 }
 
+// detachLibraryElement replaces a library element with a copy of its model in the dashboards it is connected to,
+// saving them as the user, so that it can be deleted.
+func (l *LibraryElementService) detachLibraryElement(c context.Context, signedInUser identity.Requester, uid string) error {
+	// the dashboards are saved in the transaction, so that they are all detached or none is
+	return l.SQLStore.InTransaction(c, func(ctx context.Context) error {
+		var element model.LibraryElementWithMeta
+		var dashboardIDs []int64
+		err := l.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+			var err error
+			element, err = GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
+			if err != nil {
+				return err
+			}
+			return session.Table(model.LibraryElementConnectionTableName).Where("element_id=? AND kind=1", element.ID).Cols("connection_id").Find(&dashboardIDs)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, dashboardID := range dashboardIDs {
+			// nolint:staticcheck
+			dash, err := l.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dashboardID, OrgID: signedInUser.GetOrgID()})
+			if err != nil {
+				// the connections of deleted dashboards are deleted with the library element
+				if errors.Is(err, dashboards.ErrDashboardNotFound) {
+					continue
+				}
+				return err
+			}
+
+			// the panels are replaced in place in the dashboard data
+			detached, err := detachPanels(dash.Data.Get("panels").MustArray(), element)
+			if err != nil {
+				return err
+			}
+			if detached {
+				if _, err := l.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+					OrgID:     dash.OrgID,
+					User:      signedInUser,
+					Message:   fmt.Sprintf("Detached library panel %s", element.Name),
+					Dashboard: dash,
+				}, false); err != nil {
+					return err
+				}
+			}
+
+			err = l.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+				_, err := session.Exec("DELETE FROM "+model.LibraryElementConnectionTableName+" WHERE element_id=? AND kind=1 AND connection_id=?", element.ID, dashboardID)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// detachPanels replaces the panels of a library element, including the panels of the collapsed rows, with a copy of
+// its model keeping their ID and position. It returns whether any panel was replaced.
+func detachPanels(panels []any, element model.LibraryElementWithMeta) (bool, error) {
+	detached := false
+	for i, obj := range panels {
+		panel, ok := obj.(map[string]any)
+		if !ok {
+			continue
+		}
+		if nested, ok := panel["panels"].([]any); ok {
+			nestedDetached, err := detachPanels(nested, element)
+			if err != nil {
+				return false, err
+			}
+			detached = detached || nestedDetached
+		}
+
+		libraryPanel, ok := panel["libraryPanel"].(map[string]any)
+		if !ok || libraryPanel["uid"] != element.UID {
+			continue
+		}
+		var copied map[string]any
+		if err := json.Unmarshal(element.Model, &copied); err != nil {
+			return false, err
+		}
+		delete(copied, "libraryPanel")
+		copied["id"] = panel["id"]
+		copied["gridPos"] = panel["gridPos"]
+		panels[i] = copied
+		detached = true
+	}
+	return detached, nil
+}
+
 // getLibraryElements gets a Library Element where param == value
 func (l *LibraryElementService) getLibraryElements(c context.Context, store db.DB, cfg *setting.Cfg, signedInUser identity.Requester, params []Pair, features featuremgmt.FeatureToggles, cmd model.GetLibraryElementCommand) ([]model.LibraryElementDTO, error) {
 	libraryElements := make([]model.LibraryElementWithMeta, 0)
@@ -668,41 +761,60 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 	return dto, err
 }
 
-// getConnections gets all connections for a Library Element.
-func (l *LibraryElementService) getConnections(c context.Context, signedInUser identity.Requester, uid string) ([]model.LibraryElementConnectionDTO, error) {
-	connections := make([]model.LibraryElementConnectionDTO, 0)
+// getConnections gets the connections of a Library Element, ordered by dashboard title.
+func (l *LibraryElementService) getConnections(c context.Context, signedInUser identity.Requester, query model.GetLibraryElementConnectionsQuery) (model.LibraryElementConnectionsResponse, error) {
+	result := model.LibraryElementConnectionsResponse{Result: make([]model.LibraryElementConnectionDTO, 0)}
 	recursiveQueriesAreSupported, err := l.SQLStore.RecursiveQueriesAreSupported()
 	if err != nil {
-		return nil, err
+		return result, err
+	}
+	if query.Page <= 0 {
+		query.Page = 1
 	}
 
 	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
-		element, err := GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
+		element, err := GetLibraryElement(l.SQLStore.GetDialect(), session, query.UID, signedInUser.GetOrgID())
 		if err != nil {
 			return err
 		}
+		writeFromSQL := func(builder *db.SQLBuilder) {
+			builder.Write(" FROM " + model.LibraryElementConnectionTableName + " AS lec")
+			builder.Write(" LEFT JOIN " + l.SQLStore.GetDialect().Quote("user") + " AS u1 ON lec.created_by = u1.id")
+			builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id")
+			builder.Write(` WHERE lec.element_id=?`, element.ID)
+			if signedInUser.GetOrgRole() != org.RoleAdmin {
+				builder.WriteDashboardPermissionFilter(signedInUser, dashboardaccess.PERMISSION_VIEW, "")
+			}
+		}
+
 		var libraryElementConnections []model.LibraryElementConnectionWithMeta
 		builder := db.NewSqlBuilder(l.Cfg, l.features, l.SQLStore.GetDialect(), recursiveQueriesAreSupported)
-		builder.Write("SELECT lec.*, u1.login AS created_by_name, u1.email AS created_by_email, dashboard.uid AS connection_uid")
-		builder.Write(" FROM " + model.LibraryElementConnectionTableName + " AS lec")
-		builder.Write(" LEFT JOIN " + l.SQLStore.GetDialect().Quote("user") + " AS u1 ON lec.created_by = u1.id")
-		builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id")
-		builder.Write(` WHERE lec.element_id=?`, element.ID)
-		if signedInUser.GetOrgRole() != org.RoleAdmin {
-			builder.WriteDashboardPermissionFilter(signedInUser, dashboardaccess.PERMISSION_VIEW, "")
+		builder.Write("SELECT lec.*, u1.login AS created_by_name, u1.email AS created_by_email, dashboard.uid AS connection_uid, dashboard.title AS connection_title")
+		writeFromSQL(&builder)
+		builder.Write(" ORDER BY dashboard.title ASC, lec.id ASC")
+		if query.PerPage > 0 {
+			builder.Write(l.SQLStore.GetDialect().LimitOffset(int64(query.PerPage), int64(query.PerPage*(query.Page-1))))
 		}
 		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&libraryElementConnections); err != nil {
 			return err
 		}
 
+		countBuilder := db.NewSqlBuilder(l.Cfg, l.features, l.SQLStore.GetDialect(), recursiveQueriesAreSupported)
+		countBuilder.Write("SELECT COUNT(*)")
+		writeFromSQL(&countBuilder)
+		if _, err := session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Get(&result.TotalCount); err != nil {
+			return err
+		}
+
 		for _, connection := range libraryElementConnections {
-			connections = append(connections, model.LibraryElementConnectionDTO{
-				ID:            connection.ID,
-				Kind:          connection.Kind,
-				ElementID:     connection.ElementID,
-				ConnectionID:  connection.ConnectionID,
-				ConnectionUID: connection.ConnectionUID,
-				Created:       connection.Created,
+			result.Result = append(result.Result, model.LibraryElementConnectionDTO{
+				ID:              connection.ID,
+				Kind:            connection.Kind,
+				ElementID:       connection.ElementID,
+				ConnectionID:    connection.ConnectionID,
+				ConnectionUID:   connection.ConnectionUID,
+				ConnectionTitle: connection.ConnectionTitle,
+				Created:         connection.Created,
 				CreatedBy: librarypanel.LibraryElementDTOMetaUser{
 					Id:        connection.CreatedBy,
 					Name:      connection.CreatedByName,
@@ -713,8 +825,10 @@ func (l *LibraryElementService) getConnections(c context.Context, signedInUser i
 
 		return nil
 	})
+	result.Page = query.Page
+	result.PerPage = query.PerPage
 
-	return connections, err
+	return result, err
 }
 
 // getElementsForDashboardID gets all elements for a specific dashboard
@@ -9,21 +9,23 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/libraryelements/model"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, folderService folder.Service, features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl) *LibraryElementService {
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, folderService folder.Service, dashboardService dashboards.DashboardService, features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl) *LibraryElementService {
 	l := &LibraryElementService{
-		Cfg:           cfg,
-		SQLStore:      sqlStore,
-		RouteRegister: routeRegister,
-		folderService: folderService,
-		log:           log.New("library-elements"),
-		features:      features,
-		AccessControl: ac,
+		Cfg:              cfg,
+		SQLStore:         sqlStore,
+		RouteRegister:    routeRegister,
+		folderService:    folderService,
+		dashboardService: dashboardService,
+		log:              log.New("library-elements"),
+		features:         features,
+		AccessControl:    ac,
 	}
 
 	l.registerAPIEndpoints()
@@ -48,9 +50,11 @@ type LibraryElementService struct {
 	SQLStore      db.DB
 	RouteRegister routing.RouteRegister
 	folderService folder.Service
-	log           log.Logger
-	features      featuremgmt.FeatureToggles
-	AccessControl accesscontrol.AccessControl
+	// dashboardService saves the dashboards library elements are detached from.
+	dashboardService dashboards.DashboardService
+	log              log.Logger
+	features         featuremgmt.FeatureToggles
+	AccessControl    accesscontrol.AccessControl
 }
 
 var _ Service = (*LibraryElementService)(nil)
@@ -2,6 +2,7 @@ package libraryelements
 
 import (
 	"encoding/json"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -83,4 +84,53 @@ func TestDeleteLibraryElement(t *testing.T) {
 			resp := sc.service.deleteHandler(sc.reqContext)
 			require.Equal(t, 403, resp.Status())
 		})
+	scenarioWithPanel(t, "When an admin deletes a connected library panel with detach, it should succeed and inline the panel in the dashboards",
+		func(t *testing.T, sc scenarioContext) {
+			dashJSON := map[string]any{
+				"panels": []any{
+					map[string]any{
+						"id":   int64(1),
+						"type": "row",
+						"panels": []any{
+							map[string]any{
+								"id": int64(2),
+								"gridPos": map[string]any{
+									"h": 6,
+									"w": 6,
+									"x": 6,
+									"y": 0,
+								},
+								"libraryPanel": map[string]any{
+									"uid":  sc.initialResult.Result.UID,
+									"name": sc.initialResult.Result.Name,
+								},
+							},
+						},
+					},
+				},
+			}
+			dash := dashboards.Dashboard{
+				Title: "Testing deleteHandler with detach",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			// nolint:staticcheck
+			dashInDB := createDashboard(t, sc.replStore, sc.user, &dash, sc.folder.ID, sc.folder.UID)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, []string{sc.initialResult.Result.UID}, dashInDB.ID)
+			require.NoError(t, err)
+			sc.reqContext.SignedInUser.Permissions[sc.user.OrgID][dashboards.ActionDashboardsWrite] = []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(sc.folder.UID)}
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.ctx.Req.URL = &url.URL{RawQuery: "detach=true"}
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			detached, err := sc.service.dashboardService.GetDashboard(sc.reqContext.Req.Context(), &dashboards.GetDashboardQuery{ID: dashInDB.ID, OrgID: sc.user.OrgID})
+			require.NoError(t, err)
+			panel := detached.Data.Get("panels").GetIndex(0).Get("panels").GetIndex(0)
+			_, connected := panel.CheckGet("libraryPanel")
+			require.False(t, connected)
+			require.Equal(t, "text", panel.Get("type").MustString())
+			require.Equal(t, int64(2), panel.Get("id").MustInt64())
+			require.Equal(t, 6, panel.GetPath("gridPos", "x").MustInt())
+		})
 }
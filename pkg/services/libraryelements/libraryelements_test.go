@@ -172,12 +172,13 @@ func TestGetLibraryPanelConnections(t *testing.T) {
 				return model.LibraryElementConnectionsResponse{
 					Result: []model.LibraryElementConnectionDTO{
 						{
-							ID:            sc.initialResult.Result.ID,
-							Kind:          sc.initialResult.Result.Kind,
-							ElementID:     1,
-							ConnectionID:  dashInDB.ID,
-							ConnectionUID: dashInDB.UID,
-							Created:       res.Result[0].Created,
+							ID:              sc.initialResult.Result.ID,
+							Kind:            sc.initialResult.Result.Kind,
+							ElementID:       1,
+							ConnectionID:    dashInDB.ID,
+							ConnectionUID:   dashInDB.UID,
+							ConnectionTitle: dashInDB.Title,
+							Created:         res.Result[0].Created,
 							CreatedBy: librarypanel.LibraryElementDTOMetaUser{
 								Id:        1,
 								Name:      userInDbName,
@@ -185,6 +186,8 @@ func TestGetLibraryPanelConnections(t *testing.T) {
 							},
 						},
 					},
+					TotalCount: 1,
+					Page:       1,
 				}
 			}
 
@@ -460,10 +463,11 @@ func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioCo
 		require.NoError(t, dashSvcErr)
 		guardian.InitAccessControlGuardian(cfg, ac, dashService)
 		service := LibraryElementService{
-			Cfg:           cfg,
-			features:      featuremgmt.WithFeatures(),
-			SQLStore:      sqlStore,
-			folderService: folderimpl.ProvideService(ac, bus.ProvideBus(tracer), dashboardStore, folderStore, sqlStore, features, supportbundlestest.NewFakeBundleService(), nil, tracing.InitializeTracerForTest()),
+			Cfg:              cfg,
+			features:         featuremgmt.WithFeatures(),
+			SQLStore:         sqlStore,
+			folderService:    folderimpl.ProvideService(ac, bus.ProvideBus(tracer), dashboardStore, folderStore, sqlStore, features, supportbundlestest.NewFakeBundleService(), nil, tracing.InitializeTracerForTest()),
+			dashboardService: dashService,
 		}
 
 		// deliberate difference between signed in user and user in db to make it crystal clear
@@ -115,26 +115,28 @@ type LibraryElementConnection struct {
 
 // libraryElementConnectionWithMeta is the model for library element connections with meta.
 type LibraryElementConnectionWithMeta struct {
-	ID             int64  `xorm:"pk autoincr 'id'"`
-	ElementID      int64  `xorm:"element_id"`
-	Kind           int64  `xorm:"kind"`
-	ConnectionID   int64  `xorm:"connection_id"`
-	ConnectionUID  string `xorm:"connection_uid"`
-	Created        time.Time
-	CreatedBy      int64
-	CreatedByName  string
-	CreatedByEmail string
+	ID              int64  `xorm:"pk autoincr 'id'"`
+	ElementID       int64  `xorm:"element_id"`
+	Kind            int64  `xorm:"kind"`
+	ConnectionID    int64  `xorm:"connection_id"`
+	ConnectionUID   string `xorm:"connection_uid"`
+	ConnectionTitle string `xorm:"connection_title"`
+	Created         time.Time
+	CreatedBy       int64
+	CreatedByName   string
+	CreatedByEmail  string
 }
 
 // LibraryElementConnectionDTO is the frontend DTO for element connections.
 type LibraryElementConnectionDTO struct {
-	ID            int64                                  `json:"id"`
-	Kind          int64                                  `json:"kind"`
-	ElementID     int64                                  `json:"elementId"`
-	ConnectionID  int64                                  `json:"connectionId"`
-	ConnectionUID string                                 `json:"connectionUid"`
-	Created       time.Time                              `json:"created"`
-	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+	ID              int64                                  `json:"id"`
+	Kind            int64                                  `json:"kind"`
+	ElementID       int64                                  `json:"elementId"`
+	ConnectionID    int64                                  `json:"connectionId"`
+	ConnectionUID   string                                 `json:"connectionUid"`
+	ConnectionTitle string                                 `json:"connectionTitle"`
+	Created         time.Time                              `json:"created"`
+	CreatedBy       librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
 var (
@@ -230,6 +232,14 @@ type SearchLibraryElementsQuery struct {
 	FolderFilterUIDs string
 }
 
+// GetLibraryElementConnectionsQuery is the query for getting the connections of a library element.
+type GetLibraryElementConnectionsQuery struct {
+	UID string
+	// PerPage is the maximum number of connections returned, all of them if it is 0.
+	PerPage int
+	Page    int
+}
+
 // LibraryElementResponse is a response struct for LibraryElementDTO.
 type LibraryElementResponse struct {
 	Result LibraryElementDTO `json:"result"`
@@ -247,7 +257,10 @@ type LibraryElementArrayResponse struct {
 
 // LibraryElementConnectionsResponse is a response struct for an array of LibraryElementConnectionDTO.
 type LibraryElementConnectionsResponse struct {
-	Result []LibraryElementConnectionDTO `json:"result"`
+	Result     []LibraryElementConnectionDTO `json:"result"`
+	TotalCount int64                         `json:"totalCount"`
+	Page       int                           `json:"page"`
+	PerPage    int                           `json:"perPage"`
 }
 
 // DeleteLibraryElementResponse is the response struct for deleting a library element.
@@ -0,0 +1,71 @@
+package channelauth
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	routeRegister.Group("/api/live/auth-rules", func(rules routing.RouteRegister) {
+		rules.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.listRulesHandler))
+		rules.Post("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.createRuleHandler))
+		rules.Get("/:uid", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.getRuleHandler))
+		rules.Put("/:uid", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.updateRuleHandler))
+		rules.Delete("/:uid", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.deleteRuleHandler))
+	})
+}
+
+func (s *Service) listRulesHandler(c *contextmodel.ReqContext) response.Response {
+	rules, err := s.ListRules(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list channel authorization rules", err)
+	}
+	return response.JSON(http.StatusOK, rules)
+}
+
+func (s *Service) getRuleHandler(c *contextmodel.ReqContext) response.Response {
+	rule, err := s.GetRule(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get channel authorization rule", err)
+	}
+	return response.JSON(http.StatusOK, rule)
+}
+
+func (s *Service) createRuleHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := RuleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	rule, err := s.CreateRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create channel authorization rule", err)
+	}
+	return response.JSON(http.StatusOK, rule)
+}
+
+func (s *Service) updateRuleHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := RuleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	rule, err := s.UpdateRule(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update channel authorization rule", err)
+	}
+	return response.JSON(http.StatusOK, rule)
+}
+
+func (s *Service) deleteRuleHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteRule(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete channel authorization rule", err)
+	}
+	return response.Success("Channel authorization rule deleted")
+}
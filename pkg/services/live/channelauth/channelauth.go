@@ -0,0 +1,263 @@
+package channelauth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const cacheTTL = 30 * time.Second
+
+// Service evaluates the authorization rules the org admins configure for the Live channels of their
+// organization. The rules only restrict the operations the channels allow by themselves: an operation
+// on a channel matched by rules is rejected unless one of them allows it to the user and none denies it.
+type Service struct {
+	store   db.DB
+	cache   *localcache.CacheService
+	metrics *metrics
+	log     log.Logger
+	now     func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	accesscontrolService ac.Service, reg prometheus.Registerer) (*Service, error) {
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+	s := &Service{
+		store:   sqlStore,
+		cache:   localcache.New(cacheTTL, 2*cacheTTL),
+		metrics: newMetrics(reg),
+		log:     log.New("live.channel-auth"),
+		now:     time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	return s, nil
+}
+
+// Authorize evaluates the rules of the organization of the user for the operation on the channel, which
+// is the channel without its organization. The size of the payload is only checked for publications.
+func (s *Service) Authorize(ctx context.Context, user identity.Requester, channel string, op Operation, payloadSize int) (Decision, error) {
+	rules, err := s.getRules(ctx, user.GetOrgID())
+	if err != nil {
+		return DecisionDeny, fmt.Errorf("failed to get the channel authorization rules of org %d: %w", user.GetOrgID(), err)
+	}
+
+	decision := evaluate(rules, user, channel, op, payloadSize)
+	s.metrics.decisions.WithLabelValues(namespace(channel), string(op), string(decision)).Inc()
+	if op == OperationPublish {
+		s.metrics.payloadBytes.WithLabelValues(namespace(channel)).Observe(float64(payloadSize))
+	}
+	if decision.Denied() {
+		s.log.FromContext(ctx).Debug("Channel operation denied by authorization rules", "orgId", user.GetOrgID(), "channel", channel,
+			"operation", op, "decision", decision, "id", user.GetID())
+	}
+	return decision, nil
+}
+
+func evaluate(rules []*Rule, user identity.Requester, channel string, op Operation, payloadSize int) Decision {
+	matched, allowed := false, false
+	var maxPayloadBytes int64
+	for _, rule := range rules {
+		if !slices.Contains(rule.Operations, op) || !rule.glob.Match(channel) {
+			continue
+		}
+		matched = true
+		if !appliesTo(rule, user) {
+			continue
+		}
+		if rule.Effect == EffectDeny {
+			return DecisionDeny
+		}
+		allowed = true
+		if rule.MaxPayloadBytes > 0 && (maxPayloadBytes == 0 || rule.MaxPayloadBytes < maxPayloadBytes) {
+			maxPayloadBytes = rule.MaxPayloadBytes
+		}
+	}
+
+	switch {
+	case !matched:
+		return DecisionNoRule
+	case !allowed:
+		return DecisionDeny
+	case op == OperationPublish && maxPayloadBytes > 0 && int64(payloadSize) > maxPayloadBytes:
+		return DecisionTooLarge
+	default:
+		return DecisionAllow
+	}
+}
+
+// appliesTo returns true when the user has one of the roles of the rule, or is a member of one of its
+// teams. The rules without roles and teams apply to everyone. A role of an allow rule includes the roles
+// above it, while the roles of a deny rule are compared exactly, so that denying a role doesn't deny the
+// roles above it.
+func appliesTo(rule *Rule, user identity.Requester) bool {
+	if len(rule.Roles) == 0 && len(rule.TeamIDs) == 0 {
+		return true
+	}
+	for _, role := range rule.Roles {
+		if rule.Effect == EffectDeny && user.GetOrgRole() == role {
+			return true
+		}
+		if rule.Effect != EffectDeny && user.HasRole(role) {
+			return true
+		}
+	}
+	for _, teamID := range user.GetTeams() {
+		if slices.Contains(rule.TeamIDs, teamID) {
+			return true
+		}
+	}
+	return false
+}
+
+// namespace returns the scope and namespace of the channel, which label the metrics.
+func namespace(channel string) string {
+	addr, err := live.ParseChannel(channel)
+	if err != nil {
+		return "invalid"
+	}
+	return addr.Scope + "/" + addr.Namespace
+}
+
+// getRules returns the compiled rules of the organization. They are cached on this instance for a short
+// while, so that the changes made on another instance are enforced everywhere within the TTL of the cache.
+func (s *Service) getRules(ctx context.Context, orgID int64) ([]*Rule, error) {
+	cacheKey := fmt.Sprintf("live-channel-auth-%d", orgID)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		return cached.([]*Rule), nil
+	}
+
+	rules, err := s.listRules(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.glob, err = compilePattern(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern of rule %s: %w", rule.UID, err)
+		}
+	}
+	s.cache.SetDefault(cacheKey, rules)
+	return rules, nil
+}
+
+func compilePattern(pattern string) (glob.Glob, error) {
+	return glob.Compile(pattern, '/')
+}
+
+// ListRules returns the rules of the organization.
+func (s *Service) ListRules(ctx context.Context, orgID int64) ([]*Rule, error) {
+	return s.listRules(ctx, orgID)
+}
+
+// GetRule returns the rule of the organization with the UID.
+func (s *Service) GetRule(ctx context.Context, orgID int64, uid string) (*Rule, error) {
+	return s.getRule(ctx, orgID, uid)
+}
+
+// CreateRule adds a rule to the organization.
+func (s *Service) CreateRule(ctx context.Context, orgID int64, cmd RuleCommand) (*Rule, error) {
+	now := s.now()
+	rule := &Rule{UID: util.GenerateShortUID(), OrgID: orgID, Created: now, Updated: now}
+	if err := applyCommand(rule, cmd); err != nil {
+		return nil, err
+	}
+	if err := s.insertRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.invalidate(orgID)
+	return rule, nil
+}
+
+// UpdateRule replaces the rule of the organization with the UID.
+func (s *Service) UpdateRule(ctx context.Context, orgID int64, uid string, cmd RuleCommand) (*Rule, error) {
+	rule, err := s.getRule(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCommand(rule, cmd); err != nil {
+		return nil, err
+	}
+	rule.Updated = s.now()
+	if err := s.updateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.invalidate(orgID)
+	return rule, nil
+}
+
+// DeleteRule removes the rule of the organization with the UID.
+func (s *Service) DeleteRule(ctx context.Context, orgID int64, uid string) error {
+	if err := s.deleteRule(ctx, orgID, uid); err != nil {
+		return err
+	}
+	s.invalidate(orgID)
+	return nil
+}
+
+func (s *Service) invalidate(orgID int64) {
+	s.cache.Delete(fmt.Sprintf("live-channel-auth-%d", orgID))
+}
+
+// applyCommand validates the command and sets it to the rule.
+func applyCommand(rule *Rule, cmd RuleCommand) error {
+	if cmd.Pattern == "" {
+		return ErrInvalidRule.Errorf("missing pattern")
+	}
+	if len(cmd.Pattern) > 190 {
+		return ErrInvalidRule.Errorf("the pattern is longer than 190 characters")
+	}
+	if _, err := compilePattern(cmd.Pattern); err != nil {
+		return ErrInvalidRule.Errorf("invalid pattern %q: %w", cmd.Pattern, err)
+	}
+	if len(cmd.Operations) == 0 {
+		return ErrInvalidRule.Errorf("missing operations")
+	}
+	for _, op := range cmd.Operations {
+		if !op.IsValid() {
+			return ErrInvalidRule.Errorf("unknown operation %q", op)
+		}
+	}
+	if cmd.Effect != EffectAllow && cmd.Effect != EffectDeny {
+		return ErrInvalidRule.Errorf("unknown effect %q", cmd.Effect)
+	}
+	for _, role := range cmd.Roles {
+		if !role.IsValid() {
+			return ErrInvalidRule.Errorf("unknown role %q", role)
+		}
+	}
+	if cmd.MaxPayloadBytes < 0 {
+		return ErrInvalidRule.Errorf("negative maxPayloadBytes")
+	}
+	if cmd.MaxPayloadBytes > 0 && !slices.Contains(cmd.Operations, OperationPublish) {
+		return ErrInvalidRule.Errorf("maxPayloadBytes only applies to the rules of the publish operation")
+	}
+
+	rule.Pattern = cmd.Pattern
+	rule.Operations = slices.Compact(slices.Sorted(slices.Values(cmd.Operations)))
+	rule.Effect = cmd.Effect
+	rule.Roles = cmd.Roles
+	if rule.Roles == nil {
+		rule.Roles = []org.RoleType{}
+	}
+	rule.TeamIDs = cmd.TeamIDs
+	if rule.TeamIDs == nil {
+		rule.TeamIDs = []int64{}
+	}
+	rule.MaxPayloadBytes = cmd.MaxPayloadBytes
+	return nil
+}
@@ -0,0 +1,149 @@
+package channelauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestEvaluate(t *testing.T) {
+	rule := func(pattern string, ops []Operation, effect Effect, roles []org.RoleType, teamIDs []int64, maxPayloadBytes int64) *Rule {
+		r := &Rule{Pattern: pattern, Operations: ops, Effect: effect, Roles: roles, TeamIDs: teamIDs, MaxPayloadBytes: maxPayloadBytes}
+		var err error
+		r.glob, err = compilePattern(pattern)
+		require.NoError(t, err)
+		return r
+	}
+	publish := []Operation{OperationPublish}
+	subscribe := []Operation{OperationSubscribe}
+
+	rules := []*Rule{
+		rule("grafana/dashboard/*", publish, EffectAllow, []org.RoleType{org.RoleEditor}, nil, 100),
+		rule("grafana/dashboard/secret", subscribe, EffectDeny, nil, []int64{7}, 0),
+		rule("grafana/dashboard/*", subscribe, EffectAllow, nil, nil, 0),
+		rule("ds/**", publish, EffectAllow, nil, []int64{3}, 0),
+		rule("grafana/dashboard/internal", subscribe, EffectDeny, []org.RoleType{org.RoleViewer}, nil, 0),
+	}
+
+	viewer := &user.SignedInUser{OrgID: 1, OrgRole: org.RoleViewer, Teams: []int64{7}}
+	editor := &user.SignedInUser{OrgID: 1, OrgRole: org.RoleEditor}
+	admin := &user.SignedInUser{OrgID: 1, OrgRole: org.RoleAdmin, Teams: []int64{3}}
+
+	tests := []struct {
+		name        string
+		user        *user.SignedInUser
+		channel     string
+		op          Operation
+		payloadSize int
+		expected    Decision
+	}{
+		{"no rule matches the channel", viewer, "plugin/testdata/random", OperationSubscribe, 0, DecisionNoRule},
+		{"no rule matches the operation", viewer, "ds/abc/path", OperationSubscribe, 0, DecisionNoRule},
+		{"a rule allows everyone", editor, "grafana/dashboard/abc", OperationSubscribe, 0, DecisionAllow},
+		{"a deny rule takes precedence", viewer, "grafana/dashboard/secret", OperationSubscribe, 0, DecisionDeny},
+		{"a deny rule only applies to its teams", editor, "grafana/dashboard/secret", OperationSubscribe, 0, DecisionAllow},
+		{"no rule applies to the role", viewer, "grafana/dashboard/abc", OperationPublish, 10, DecisionDeny},
+		{"a rule applies to the higher roles", admin, "grafana/dashboard/abc", OperationPublish, 10, DecisionAllow},
+		{"the payload is larger than the limit", editor, "grafana/dashboard/abc", OperationPublish, 101, DecisionTooLarge},
+		{"* matches a single segment", editor, "grafana/dashboard/abc/def", OperationSubscribe, 0, DecisionNoRule},
+		{"** matches any number of segments", admin, "ds/abc/path/to/stream", OperationPublish, 1000, DecisionAllow},
+		{"a rule applies to its teams", editor, "ds/abc/path", OperationPublish, 0, DecisionDeny},
+		{"a deny rule applies to its roles", viewer, "grafana/dashboard/internal", OperationSubscribe, 0, DecisionDeny},
+		{"a deny rule doesn't apply to the higher roles", editor, "grafana/dashboard/internal", OperationSubscribe, 0, DecisionAllow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, evaluate(rules, tt.user, tt.channel, tt.op, tt.payloadSize))
+		})
+	}
+}
+
+func TestIntegrationRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s, err := ProvideService(db.InitTestDB(t), routing.NewRouteRegister(), actest.FakeAccessControl{}, actest.FakeService{}, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	viewer := &user.SignedInUser{OrgID: 1, OrgRole: org.RoleViewer}
+
+	created, err := s.CreateRule(ctx, 1, RuleCommand{
+		Pattern:    "grafana/dashboard/*",
+		Operations: []Operation{OperationSubscribe},
+		Effect:     EffectAllow,
+		Roles:      []org.RoleType{org.RoleViewer},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.UID)
+
+	decision, err := s.Authorize(ctx, viewer, "grafana/dashboard/abc", OperationSubscribe, 0)
+	require.NoError(t, err)
+	assert.Equal(t, DecisionAllow, decision)
+
+	t.Run("should not create invalid rules", func(t *testing.T) {
+		for _, cmd := range []RuleCommand{
+			{Operations: []Operation{OperationSubscribe}, Effect: EffectAllow},
+			{Pattern: "grafana/[", Operations: []Operation{OperationSubscribe}, Effect: EffectAllow},
+			{Pattern: "grafana/*", Effect: EffectAllow},
+			{Pattern: "grafana/*", Operations: []Operation{"history"}, Effect: EffectAllow},
+			{Pattern: "grafana/*", Operations: []Operation{OperationSubscribe}, Effect: "maybe"},
+			{Pattern: "grafana/*", Operations: []Operation{OperationSubscribe}, Effect: EffectAllow, Roles: []org.RoleType{"Owner"}},
+			{Pattern: "grafana/*", Operations: []Operation{OperationSubscribe}, Effect: EffectAllow, MaxPayloadBytes: 10},
+		} {
+			_, err := s.CreateRule(ctx, 1, cmd)
+			require.ErrorIs(t, err, ErrInvalidRule)
+		}
+	})
+
+	t.Run("should enforce the updated rule", func(t *testing.T) {
+		updated, err := s.UpdateRule(ctx, 1, created.UID, RuleCommand{
+			Pattern:    "grafana/dashboard/*",
+			Operations: []Operation{OperationSubscribe},
+			Effect:     EffectDeny,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, EffectDeny, updated.Effect)
+		assert.Empty(t, updated.Roles)
+
+		decision, err := s.Authorize(ctx, viewer, "grafana/dashboard/abc", OperationSubscribe, 0)
+		require.NoError(t, err)
+		assert.Equal(t, DecisionDeny, decision)
+	})
+
+	t.Run("should only get the rules of the org", func(t *testing.T) {
+		rules, err := s.ListRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, created.UID, rules[0].UID)
+
+		rules, err = s.ListRules(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, rules)
+
+		_, err = s.GetRule(ctx, 2, created.UID)
+		require.ErrorIs(t, err, ErrRuleNotFound)
+	})
+
+	t.Run("should stop enforcing the deleted rule", func(t *testing.T) {
+		require.NoError(t, s.DeleteRule(ctx, 1, created.UID))
+		require.ErrorIs(t, s.DeleteRule(ctx, 1, created.UID), ErrRuleNotFound)
+
+		decision, err := s.Authorize(ctx, viewer, "grafana/dashboard/abc", OperationSubscribe, 0)
+		require.NoError(t, err)
+		assert.Equal(t, DecisionNoRule, decision)
+	})
+}
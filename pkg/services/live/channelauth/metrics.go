@@ -0,0 +1,40 @@
+package channelauth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "live_channel_auth"
+)
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "decisions_total",
+			Help:      "Number of channel operations evaluated by the authorization rules, by channel namespace and decision",
+		}, []string{"namespace", "operation", "decision"}),
+		payloadBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "publish_payload_bytes",
+			Help:      "Size of the payloads published to the channels, by channel namespace",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 8),
+		}, []string{"namespace"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.decisions)
+		reg.MustRegister(m.payloadBytes)
+	}
+
+	return m
+}
+
+type metrics struct {
+	decisions    *prometheus.CounterVec
+	payloadBytes *prometheus.HistogramVec
+}
@@ -0,0 +1,101 @@
+package channelauth
+
+import (
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// Operation is what a client does with a channel.
+type Operation string
+
+const (
+	OperationSubscribe Operation = "subscribe"
+	OperationPublish   Operation = "publish"
+)
+
+func (o Operation) IsValid() bool {
+	return o == OperationSubscribe || o == OperationPublish
+}
+
+// Effect is whether a rule allows or denies the operations it matches.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Decision is the outcome of the evaluation of the rules of an operation.
+type Decision string
+
+const (
+	// DecisionNoRule is the decision of the channels which no rule matches, which keep their own permissions.
+	DecisionNoRule Decision = "no_rule"
+	DecisionAllow  Decision = "allow"
+	DecisionDeny   Decision = "deny"
+	// DecisionTooLarge denies a publication larger than the payload limit of the rules.
+	DecisionTooLarge Decision = "too_large"
+)
+
+// Denied returns true when the operation must be rejected.
+func (d Decision) Denied() bool {
+	return d == DecisionDeny || d == DecisionTooLarge
+}
+
+var (
+	ErrRuleNotFound = errutil.NotFound("live.channel-auth.rule-not-found", errutil.WithPublicMessage("Channel authorization rule not found"))
+	ErrInvalidRule  = errutil.BadRequest("live.channel-auth.invalid-rule")
+)
+
+// Rule allows or denies the operations on the channels matching its pattern to the users who have one of
+// its roles or are members of one of its teams, or to everyone when it has neither.
+type Rule struct {
+	UID   string `json:"uid"`
+	OrgID int64  `json:"orgId"`
+	// Pattern is a glob matched against the channel, without its organization, for example
+	// "grafana/dashboard/*". "*" matches a single segment of the channel, and "**" any number of them.
+	Pattern    string         `json:"pattern"`
+	Operations []Operation    `json:"operations"`
+	Effect     Effect         `json:"effect"`
+	Roles      []org.RoleType `json:"roles"`
+	TeamIDs    []int64        `json:"teamIds"`
+	// MaxPayloadBytes limits the size of the publications of the users the rule applies to, 0 for no limit.
+	MaxPayloadBytes int64     `json:"maxPayloadBytes"`
+	Created         time.Time `json:"created"`
+	Updated         time.Time `json:"updated"`
+
+	glob glob.Glob
+}
+
+// RuleCommand is the body of the APIs creating and updating a rule.
+type RuleCommand struct {
+	Pattern         string         `json:"pattern"`
+	Operations      []Operation    `json:"operations"`
+	Effect          Effect         `json:"effect"`
+	Roles           []org.RoleType `json:"roles"`
+	TeamIDs         []int64        `json:"teamIds"`
+	MaxPayloadBytes int64          `json:"maxPayloadBytes"`
+}
+
+type ruleRow struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	UID     string `xorm:"uid"`
+	Pattern string `xorm:"pattern"`
+	// Operations, Roles and TeamIDs are stored as JSON.
+	Operations      string    `xorm:"operations"`
+	Effect          string    `xorm:"effect"`
+	Roles           string    `xorm:"roles"`
+	TeamIDs         string    `xorm:"team_ids"`
+	MaxPayloadBytes int64     `xorm:"max_payload_bytes"`
+	Created         time.Time `xorm:"created"`
+	Updated         time.Time `xorm:"updated"`
+}
+
+func (ruleRow) TableName() string {
+	return "live_channel_auth_rule"
+}
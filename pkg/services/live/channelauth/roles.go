@@ -0,0 +1,41 @@
+package channelauth
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionRead  = "live.authrules:read"
+	ActionWrite = "live.authrules:write"
+)
+
+var (
+	ruleReaderRole = ac.RoleDTO{
+		Name:        "fixed:live.authrules:reader",
+		DisplayName: "Live channel authorization rule reader",
+		Description: "List the authorization rules of the Live channels of the organization",
+		Group:       "Live",
+		Permissions: []ac.Permission{
+			{Action: ActionRead},
+		},
+	}
+
+	ruleWriterRole = ac.RoleDTO{
+		Name:        "fixed:live.authrules:writer",
+		DisplayName: "Live channel authorization rule writer",
+		Description: "List, create, update and delete the authorization rules of the Live channels of the organization",
+		Group:       "Live",
+		Permissions: []ac.Permission{
+			{Action: ActionRead},
+			{Action: ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(service ac.Service) error {
+	return service.DeclareFixedRoles(
+		ac.RoleRegistration{Role: ruleReaderRole, Grants: []string{string(org.RoleAdmin)}},
+		ac.RoleRegistration{Role: ruleWriterRole, Grants: []string{string(org.RoleAdmin)}},
+	)
+}
@@ -0,0 +1,141 @@
+package channelauth
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func (s *Service) listRules(ctx context.Context, orgID int64) ([]*Rule, error) {
+	var rows []ruleRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("pattern", "id").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*Rule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := row.toRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *Service) getRule(ctx context.Context, orgID int64, uid string) (*Rule, error) {
+	row := &ruleRow{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(row)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRuleNotFound.Errorf("channel authorization rule %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row.toRule()
+}
+
+func (s *Service) insertRule(ctx context.Context, rule *Rule) error {
+	row, err := newRuleRow(rule)
+	if err != nil {
+		return err
+	}
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(row)
+		return err
+	})
+}
+
+func (s *Service) updateRule(ctx context.Context, rule *Rule) error {
+	row, err := newRuleRow(rule)
+	if err != nil {
+		return err
+	}
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", rule.OrgID, rule.UID).
+			Cols("pattern", "operations", "effect", "roles", "team_ids", "max_payload_bytes", "updated").Update(row)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrRuleNotFound.Errorf("channel authorization rule %s not found", rule.UID)
+		}
+		return nil
+	})
+}
+
+func (s *Service) deleteRule(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&ruleRow{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrRuleNotFound.Errorf("channel authorization rule %s not found", uid)
+		}
+		return nil
+	})
+}
+
+func newRuleRow(rule *Rule) (*ruleRow, error) {
+	operations, err := json.Marshal(rule.Operations)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := json.Marshal(rule.Roles)
+	if err != nil {
+		return nil, err
+	}
+	teamIDs, err := json.Marshal(rule.TeamIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &ruleRow{
+		OrgID:           rule.OrgID,
+		UID:             rule.UID,
+		Pattern:         rule.Pattern,
+		Operations:      string(operations),
+		Effect:          string(rule.Effect),
+		Roles:           string(roles),
+		TeamIDs:         string(teamIDs),
+		MaxPayloadBytes: rule.MaxPayloadBytes,
+		Created:         rule.Created,
+		Updated:         rule.Updated,
+	}, nil
+}
+
+func (row ruleRow) toRule() (*Rule, error) {
+	rule := &Rule{
+		UID:             row.UID,
+		OrgID:           row.OrgID,
+		Pattern:         row.Pattern,
+		Effect:          Effect(row.Effect),
+		Operations:      []Operation{},
+		Roles:           []org.RoleType{},
+		TeamIDs:         []int64{},
+		MaxPayloadBytes: row.MaxPayloadBytes,
+		Created:         row.Created,
+		Updated:         row.Updated,
+	}
+	if err := json.Unmarshal([]byte(row.Operations), &rule.Operations); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(row.Roles), &rule.Roles); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(row.TeamIDs), &rule.TeamIDs); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,35 @@
+package features
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+// EventsChannelPrefix is the prefix of the channels publishing the events of the outbox, followed by the event type.
+const EventsChannelPrefix = "grafana/events/"
+
+// EventsHandler lets organization admins follow the events of their organization published from the outbox
+// on `grafana/events/<event type>` channels. Clients can't publish on these channels.
+type EventsHandler struct{}
+
+// GetHandlerForPath called on init
+func (h *EventsHandler) GetHandlerForPath(_ string) (model.ChannelHandler, error) {
+	return h, nil // all event types share the same handler
+}
+
+// OnSubscribe lets organization admins subscribe to the events.
+func (h *EventsHandler) OnSubscribe(_ context.Context, user identity.Requester, _ model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if !user.GetOrgRole().Includes(identity.RoleAdmin) {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	return model.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish denies the publications of clients, the events are only published by the outbox.
+func (h *EventsHandler) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
+	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
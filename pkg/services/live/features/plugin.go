@@ -88,6 +88,10 @@ func (r *PluginPathRunner) OnSubscribe(ctx context.Context, user identity.Reques
 
 	submitResult, err := r.runStreamManager.SubmitStream(ctx, user, orgchannel.PrependOrgID(user.GetOrgID(), e.Channel), r.path, e.Data, pCtx, r.handler, false)
 	if err != nil {
+		if errors.Is(err, runstream.ErrOrgStreamLimitReached) {
+			logger.Warn("Stream limit reached", "orgId", user.GetOrgID(), "pluginId", r.pluginID, "path", r.path)
+			return model.SubscribeReply{}, 0, centrifuge.ErrorLimitExceeded
+		}
 		logger.Error("Error submitting stream to manager", "error", err, "path", r.path)
 		return model.SubscribeReply{}, 0, centrifuge.ErrorInternal
 	}
@@ -0,0 +1,79 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/query/progress"
+)
+
+// QueryChannelPrefix is the prefix of the channels publishing the progress of query requests, followed by the query ID.
+const QueryChannelPrefix = "grafana/query/"
+
+type queryAction struct {
+	Action string `json:"action"`
+}
+
+// QueryProgressHandler publishes the progress of the query requests sent with an X-Query-Id header on
+// `grafana/query/<query id>` channels. Publishing {"action":"cancel"} on the channel cancels the query.
+type QueryProgressHandler struct {
+	Publisher model.ChannelPublisher
+	Tracker   *progress.Tracker
+}
+
+func NewQueryProgressHandler(publisher model.ChannelPublisher, tracker *progress.Tracker) *QueryProgressHandler {
+	h := &QueryProgressHandler{Publisher: publisher, Tracker: tracker}
+	tracker.OnUpdate(h.publish)
+	return h
+}
+
+// GetHandlerForPath called on init
+func (h *QueryProgressHandler) GetHandlerForPath(_ string) (model.ChannelHandler, error) {
+	return h, nil // all queries share the same handler
+}
+
+// OnSubscribe lets the user who sent a query follow its progress, starting with the current one.
+func (h *QueryProgressHandler) OnSubscribe(ctx context.Context, user identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	p, err := h.Tracker.Get(ctx, user, e.Path)
+	if err != nil {
+		if errors.Is(err, progress.ErrQueryNotFound) {
+			return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+		}
+		return model.SubscribeReply{}, 0, err
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	return model.SubscribeReply{Data: data}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish cancels the query when the user who sent it publishes a cancel action.
+func (h *QueryProgressHandler) OnPublish(ctx context.Context, user identity.Requester, e model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
+	action := queryAction{}
+	if err := json.Unmarshal(e.Data, &action); err != nil || action.Action != "cancel" {
+		return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+	}
+	if err := h.Tracker.Cancel(ctx, user, e.Path); err != nil {
+		if errors.Is(err, progress.ErrQueryNotFound) {
+			return model.PublishReply{}, backend.PublishStreamStatusNotFound, nil
+		}
+		return model.PublishReply{}, 0, err
+	}
+	return model.PublishReply{}, backend.PublishStreamStatusOK, nil
+}
+
+func (h *QueryProgressHandler) publish(orgID int64, p progress.Progress) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	if err := h.Publisher(orgID, QueryChannelPrefix+p.QueryID, data); err != nil {
+		logger.Warn("Failed to publish query progress", "queryId", p.QueryID, "error", err)
+	}
+}
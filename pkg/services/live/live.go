@@ -39,9 +39,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/channelauth"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
+	"github.com/grafana/grafana/pkg/services/live/livehistory"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/model"
@@ -54,6 +56,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/query/progress"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -78,7 +81,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, queryProgress *progress.Tracker, channelAuth *channelauth.Service,
+	liveHistory *livehistory.Service) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -97,6 +101,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		},
 		usageStatsService: usageStatsService,
 		orgService:        orgService,
+		channelAuth:       channelAuth,
 	}
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
@@ -148,26 +153,30 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	var frameCache managedstream.FrameCache
 	if redisClient != nil {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
-		)
+		frameCache = managedstream.NewRedisFrameCache(redisClient)
 	} else {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
-		)
+		frameCache = managedstream.NewMemoryFrameCache()
 	}
+	if liveHistory != nil {
+		// Replays the recent frames of the channels with a history retention to the subscribers.
+		frameCache = liveHistory.WrapFrameCache(frameCache)
+	}
+	managedStreamRunner = managedstream.NewRunner(
+		g.Publish,
+		channelLocalPublisher,
+		frameCache,
+	)
 
 	g.ManagedStreamRunner = managedStreamRunner
 
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
-	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter)
+	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter,
+		runstream.WithOrgStreamLimit("loki", cfg.LiveLokiTailMaxStreamsPerOrg),
+	)
 
 	// Initialize the main features
 	dash := &features.DashboardHandler{
@@ -180,6 +189,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
+	g.GrafanaScope.Features["query"] = features.NewQueryProgressHandler(g.Publish, queryProgress)
+	g.GrafanaScope.Features["events"] = &features.EventsHandler{}
 
 	g.surveyCaller = survey.NewCaller(managedStreamRunner, node)
 	err = g.surveyCaller.SetupHandlers()
@@ -270,7 +281,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		CheckOrigin:     checkOrigin,
 	})
 
-	pushWSHandler := pushws.NewHandler(g.ManagedStreamRunner, pushws.Config{
+	pushWSHandler := pushws.NewHandler(g.ManagedStreamRunner, g, pushws.Config{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
@@ -383,6 +394,8 @@ type GrafanaLive struct {
 	pluginClient          plugins.Client
 	queryDataService      query.Service
 	orgService            org.Service
+	channelAuth           *channelauth.Service
+	pushProcessor         pushws.PushProcessor
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
@@ -623,6 +636,13 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if code, err := g.checkChannelAuthRules(ctx, user, channel, channelauth.OperationSubscribe, 0); err != nil {
+		logger.Error("Error checking channel authorization rules", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+	} else if code != http.StatusOK {
+		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: http.StatusText(code)}
+	}
+
 	var reply model.SubscribeReply
 	var status backend.SubscribeStreamStatus
 	var ruleFound bool
@@ -724,6 +744,13 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if code, err := g.checkChannelAuthRules(ctx, user, channel, channelauth.OperationPublish, len(e.Data)); err != nil {
+		logger.Error("Error checking channel authorization rules", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.PublishReply{}, centrifuge.ErrorInternal
+	} else if code != http.StatusOK {
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: http.StatusText(code)}
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -944,6 +971,41 @@ func (g *GrafanaLive) ClientCount(orgID int64, channel string) (int, error) {
 	return len(p.Presence), nil
 }
 
+// SetPushProcessor sets the processor of the data pushed to the streams which have a push pipeline.
+func (g *GrafanaLive) SetPushProcessor(processor pushws.PushProcessor) {
+	g.pushProcessor = processor
+}
+
+// ProcessPush processes the data pushed to the stream with its push pipeline, and returns false when the
+// stream has no pipeline.
+func (g *GrafanaLive) ProcessPush(ctx context.Context, orgID int64, streamID string, body []byte) (bool, error) {
+	if g.pushProcessor == nil {
+		return false, nil
+	}
+	return g.pushProcessor.ProcessPush(ctx, orgID, streamID, body)
+}
+
+// checkChannelAuthRules returns the HTTP status of the operation on the channel, without its organization,
+// according to the channel authorization rules of the organization of the user. The rules only restrict
+// the operations, which the channel still has to allow on its own.
+func (g *GrafanaLive) checkChannelAuthRules(ctx context.Context, user identity.Requester, channel string, op channelauth.Operation, payloadSize int) (int, error) {
+	if g.channelAuth == nil {
+		return http.StatusOK, nil
+	}
+	decision, err := g.channelAuth.Authorize(ctx, user, channel, op, payloadSize)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	switch decision {
+	case channelauth.DecisionDeny:
+		return http.StatusForbidden, nil
+	case channelauth.DecisionTooLarge:
+		return http.StatusRequestEntityTooLarge, nil
+	default:
+		return http.StatusOK, nil
+	}
+}
+
 func (g *GrafanaLive) HandleHTTPPublish(ctx *contextmodel.ReqContext) response.Response {
 	cmd := dtos.LivePublishCmd{}
 	if err := web.Bind(ctx.Req, &cmd); err != nil {
@@ -958,6 +1020,13 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *contextmodel.ReqContext) response.R
 	user := ctx.SignedInUser
 	channel := cmd.Channel
 
+	if code, err := g.checkChannelAuthRules(ctx.Req.Context(), user, channel, channelauth.OperationPublish, len(cmd.Data)); err != nil {
+		logger.Error("Error checking channel authorization rules", "user", user, "channel", channel, "error", err)
+		return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	} else if code != http.StatusOK {
+		return response.Error(code, http.StatusText(code), nil)
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
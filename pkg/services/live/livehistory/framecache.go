@@ -0,0 +1,90 @@
+package livehistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+)
+
+// WrapFrameCache returns a frame cache which keeps the frames pushed to the channels which have a history
+// retention, and which replays them to the clients subscribing as a single frame.
+func (s *Service) WrapFrameCache(cache managedstream.FrameCache) managedstream.FrameCache {
+	if !s.enabled {
+		return cache
+	}
+	return &frameCache{FrameCache: cache, history: s}
+}
+
+type frameCache struct {
+	managedstream.FrameCache
+	history *Service
+}
+
+func (c *frameCache) Update(ctx context.Context, orgID int64, channel string, frameJSON data.FrameJSONCache) (bool, error) {
+	updated, err := c.FrameCache.Update(ctx, orgID, channel, frameJSON)
+	if err != nil {
+		return updated, err
+	}
+	// the frame is still published when it can't be kept
+	if err := c.history.Append(ctx, orgID, channel, frameJSON.Bytes(data.IncludeAll)); err != nil {
+		c.history.log.FromContext(ctx).Error("Failed to keep the history of the channel", "orgId", orgID, "channel", channel, "error", err)
+	}
+	return updated, nil
+}
+
+func (c *frameCache) GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	frames, err := c.history.Messages(ctx, orgID, channel)
+	if err != nil {
+		// the subscription falls back to the last frame
+		c.history.log.FromContext(ctx).Error("Failed to get the history of the channel", "orgId", orgID, "channel", channel, "error", err)
+	} else if len(frames) > 0 {
+		merged, err := mergeFrames(frames)
+		if err == nil {
+			return merged, true, nil
+		}
+		c.history.log.FromContext(ctx).Error("Failed to merge the history of the channel", "orgId", orgID, "channel", channel, "error", err)
+	}
+	return c.FrameCache.GetFrame(ctx, orgID, channel)
+}
+
+// mergeFrames appends the rows of the frames, from the oldest to the most recent, into a single frame. The
+// frames before a change of the schema are dropped.
+func mergeFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	var merged *data.Frame
+	for _, frameJSON := range frames {
+		frame := &data.Frame{}
+		if err := json.Unmarshal(frameJSON, frame); err != nil {
+			return nil, err
+		}
+		if merged == nil || !sameSchema(merged, frame) {
+			merged = frame
+			continue
+		}
+		for i, field := range frame.Fields {
+			for j := 0; j < field.Len(); j++ {
+				merged.Fields[i].Append(field.At(j))
+			}
+		}
+	}
+	if merged == nil {
+		return nil, errors.New("no frame to merge")
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+func sameSchema(a, b *data.Frame) bool {
+	if a.Name != b.Name || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() ||
+			a.Fields[i].Labels.String() != b.Fields[i].Labels.String() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package livehistory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const cleanupInterval = 10 * time.Minute
+
+// retention is how many messages of a channel, and for how long, are kept.
+type retention struct {
+	glob        glob.Glob
+	maxMessages int
+	maxAge      time.Duration
+}
+
+// message is a frame published to a channel.
+type message struct {
+	Time  time.Time       `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// Service keeps the recent messages of the managed stream channels which have a history retention, and
+// replays them to the clients subscribing, so that the panels show the recent points right away, for
+// example after a reconnect.
+type Service struct {
+	enabled     bool
+	storageType string
+	retentions  []retention
+	storage     storage
+	log         log.Logger
+	now         func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, remoteCache remotecache.CacheStorage, sqlStore db.DB) (*Service, error) {
+	s := &Service{
+		enabled:     cfg.LiveHistory.Enabled && len(cfg.LiveHistory.Channels) > 0,
+		storageType: cfg.LiveHistory.Storage,
+		log:         log.New("live.history"),
+		now:         time.Now,
+	}
+	for _, channel := range cfg.LiveHistory.Channels {
+		g, err := glob.Compile(channel.Pattern, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of the history of the Live channels: %w", err)
+		}
+		s.retentions = append(s.retentions, retention{glob: g, maxMessages: channel.MaxMessages, maxAge: channel.MaxAge})
+	}
+
+	if s.storageType == setting.LiveHistoryStorageDatabase {
+		s.storage = &sqlStorage{store: sqlStore}
+	} else {
+		s.storage = &remoteCacheStorage{cache: remoteCache}
+	}
+	return s, nil
+}
+
+// IsDisabled returns true unless the messages are kept in the database, whose expired messages are deleted
+// in the background. The remote cache expires them on its own.
+func (s *Service) IsDisabled() bool {
+	return !s.enabled || s.storageType != setting.LiveHistoryStorageDatabase
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.storage.deleteBefore(ctx, s.now().Add(-s.maxAge())); err != nil {
+				s.log.Error("Failed to delete the expired messages of the Live channels", "error", err)
+			}
+		}
+	}
+}
+
+// retention returns the retention of the channel, without its organization.
+func (s *Service) retention(channel string) (retention, bool) {
+	if !s.enabled {
+		return retention{}, false
+	}
+	for _, r := range s.retentions {
+		if r.glob.Match(channel) {
+			return r, true
+		}
+	}
+	return retention{}, false
+}
+
+// maxAge returns the longest retention, beyond which the messages of every channel are expired.
+func (s *Service) maxAge() time.Duration {
+	var maxAge time.Duration
+	for _, r := range s.retentions {
+		maxAge = max(maxAge, r.maxAge)
+	}
+	return maxAge
+}
+
+// Append keeps the frame published to the channel when it has a history retention.
+func (s *Service) Append(ctx context.Context, orgID int64, channel string, frame json.RawMessage) error {
+	r, ok := s.retention(channel)
+	if !ok {
+		return nil
+	}
+	if err := s.storage.append(ctx, orgID, channel, message{Time: s.now(), Frame: frame}, r); err != nil {
+		return fmt.Errorf("failed to keep the message of channel %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Messages returns the frames kept for the channel, from the oldest to the most recent.
+func (s *Service) Messages(ctx context.Context, orgID int64, channel string) ([]json.RawMessage, error) {
+	r, ok := s.retention(channel)
+	if !ok {
+		return nil, nil
+	}
+	messages, err := s.storage.list(ctx, orgID, channel, s.now().Add(-r.maxAge))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the messages of channel %s: %w", channel, err)
+	}
+	// the retention may have been lowered since the messages were kept
+	if r.maxMessages > 0 && len(messages) > r.maxMessages {
+		messages = messages[len(messages)-r.maxMessages:]
+	}
+
+	frames := make([]json.RawMessage, 0, len(messages))
+	for _, m := range messages {
+		frames = append(frames, m.Frame)
+	}
+	return frames, nil
+}
@@ -0,0 +1,170 @@
+package livehistory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func newTestService(t *testing.T, storage string, store db.DB) (*Service, *time.Time) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.LiveHistory = setting.LiveHistorySettings{
+		Enabled: true,
+		Storage: storage,
+		Channels: []setting.LiveHistoryChannel{
+			{Pattern: "stream/telegraf/*", MaxMessages: 3, MaxAge: 10 * time.Minute},
+			{Pattern: "stream/**", MaxMessages: 0, MaxAge: time.Minute},
+		},
+	}
+	s, err := ProvideService(cfg, remotecache.NewFakeCacheStorage(), store)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func testRetention(t *testing.T, s *Service, now *time.Time) {
+	ctx := context.Background()
+	frame := func(i int) json.RawMessage {
+		return json.RawMessage(`{"i":` + string(rune('0'+i)) + `}`)
+	}
+
+	t.Run("should keep the last messages of the channel", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			require.NoError(t, s.Append(ctx, 1, "stream/telegraf/cpu", frame(i)))
+			*now = now.Add(time.Second)
+		}
+
+		frames, err := s.Messages(ctx, 1, "stream/telegraf/cpu")
+		require.NoError(t, err)
+		assert.Equal(t, []json.RawMessage{frame(2), frame(3), frame(4)}, frames)
+
+		frames, err = s.Messages(ctx, 2, "stream/telegraf/cpu")
+		require.NoError(t, err)
+		assert.Empty(t, frames)
+	})
+
+	t.Run("should keep the messages of the last minutes", func(t *testing.T) {
+		require.NoError(t, s.Append(ctx, 1, "stream/app/metrics", frame(1)))
+		*now = now.Add(45 * time.Second)
+		require.NoError(t, s.Append(ctx, 1, "stream/app/metrics", frame(2)))
+		*now = now.Add(30 * time.Second)
+
+		frames, err := s.Messages(ctx, 1, "stream/app/metrics")
+		require.NoError(t, err)
+		assert.Equal(t, []json.RawMessage{frame(2)}, frames)
+	})
+
+	t.Run("should not keep the channels without retention", func(t *testing.T) {
+		require.NoError(t, s.Append(ctx, 1, "grafana/dashboard/abc", frame(1)))
+
+		frames, err := s.Messages(ctx, 1, "grafana/dashboard/abc")
+		require.NoError(t, err)
+		assert.Empty(t, frames)
+	})
+}
+
+func TestRemoteCacheRetention(t *testing.T) {
+	s, now := newTestService(t, setting.LiveHistoryStorageRemoteCache, nil)
+	testRetention(t, s, now)
+}
+
+func TestIntegrationDatabaseRetention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s, now := newTestService(t, setting.LiveHistoryStorageDatabase, db.InitTestDB(t))
+	testRetention(t, s, now)
+
+	t.Run("should delete the expired messages", func(t *testing.T) {
+		*now = now.Add(time.Hour)
+		require.NoError(t, s.storage.deleteBefore(context.Background(), now.Add(-s.maxAge())))
+
+		messages, err := s.storage.list(context.Background(), 1, "stream/telegraf/cpu", time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+}
+
+func TestFrameCache(t *testing.T) {
+	ctx := context.Background()
+	s, now := newTestService(t, setting.LiveHistoryStorageRemoteCache, nil)
+	cache := s.WrapFrameCache(managedstream.NewMemoryFrameCache())
+
+	push := func(channel string, frame *data.Frame) {
+		frameJSON, err := data.FrameToJSONCache(frame)
+		require.NoError(t, err)
+		_, err = cache.Update(ctx, 1, channel, frameJSON)
+		require.NoError(t, err)
+		*now = now.Add(time.Second)
+	}
+	frame := func(value float64) *data.Frame {
+		return data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{*now}),
+			data.NewField("value", nil, []float64{value}),
+		)
+	}
+
+	t.Run("should replay the history as a single frame", func(t *testing.T) {
+		push("stream/telegraf/cpu", frame(1))
+		push("stream/telegraf/cpu", frame(2))
+
+		frameJSON, ok, err := cache.GetFrame(ctx, 1, "stream/telegraf/cpu")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		replayed := &data.Frame{}
+		require.NoError(t, json.Unmarshal(frameJSON, replayed))
+		require.Equal(t, 2, replayed.Rows())
+		assert.Equal(t, 1.0, replayed.Fields[1].At(0))
+		assert.Equal(t, 2.0, replayed.Fields[1].At(1))
+	})
+
+	t.Run("should replay from the last change of the schema", func(t *testing.T) {
+		push("stream/telegraf/cpu", data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{*now}),
+			data.NewField("value", nil, []float64{3}),
+			data.NewField("max", nil, []float64{4}),
+		))
+
+		frameJSON, ok, err := cache.GetFrame(ctx, 1, "stream/telegraf/cpu")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		replayed := &data.Frame{}
+		require.NoError(t, json.Unmarshal(frameJSON, replayed))
+		require.Equal(t, 1, replayed.Rows())
+		assert.Len(t, replayed.Fields, 3)
+	})
+
+	t.Run("should return the last frame of the channels without retention", func(t *testing.T) {
+		push("plugin/testdata/random", frame(5))
+
+		frameJSON, ok, err := cache.GetFrame(ctx, 1, "plugin/testdata/random")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		replayed := &data.Frame{}
+		require.NoError(t, json.Unmarshal(frameJSON, replayed))
+		require.Equal(t, 1, replayed.Rows())
+	})
+}
@@ -0,0 +1,150 @@
+package livehistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+type storage interface {
+	// append keeps the message, and removes the messages of the channel beyond its retention.
+	append(ctx context.Context, orgID int64, channel string, m message, r retention) error
+	// list returns the messages of the channel kept since the time, from the oldest to the most recent.
+	list(ctx context.Context, orgID int64, channel string, since time.Time) ([]message, error)
+	// deleteBefore removes the messages of every channel kept before the time.
+	deleteBefore(ctx context.Context, before time.Time) error
+}
+
+// remoteCacheStorage keeps the messages of each channel in a single entry of the remote cache, which expires
+// with the retention of the channel. Concurrent appends on several instances may lose a message.
+type remoteCacheStorage struct {
+	cache remotecache.CacheStorage
+}
+
+func cacheKey(orgID int64, channel string) string {
+	return fmt.Sprintf("live-history-%d-%s", orgID, channel)
+}
+
+func (s *remoteCacheStorage) get(ctx context.Context, key string) ([]message, error) {
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var messages []message
+	if err := json.Unmarshal(value, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (s *remoteCacheStorage) append(ctx context.Context, orgID int64, channel string, m message, r retention) error {
+	key := cacheKey(orgID, channel)
+	messages, err := s.get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	messages = append(messages, m)
+	first := 0
+	for first < len(messages) && messages[first].Time.Before(m.Time.Add(-r.maxAge)) {
+		first++
+	}
+	if r.maxMessages > 0 && len(messages)-first > r.maxMessages {
+		first = len(messages) - r.maxMessages
+	}
+
+	value, err := json.Marshal(messages[first:])
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, key, value, r.maxAge)
+}
+
+func (s *remoteCacheStorage) list(ctx context.Context, orgID int64, channel string, since time.Time) ([]message, error) {
+	messages, err := s.get(ctx, cacheKey(orgID, channel))
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range messages {
+		if !m.Time.Before(since) {
+			return messages[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *remoteCacheStorage) deleteBefore(context.Context, time.Time) error {
+	return nil
+}
+
+type messageRow struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	OrgID   int64     `xorm:"org_id"`
+	Channel string    `xorm:"channel"`
+	Frame   string    `xorm:"frame"`
+	Created time.Time `xorm:"created"`
+}
+
+func (messageRow) TableName() string {
+	return "live_message_history"
+}
+
+// sqlStorage keeps each message in a row of the database.
+type sqlStorage struct {
+	store db.DB
+}
+
+func (s *sqlStorage) append(ctx context.Context, orgID int64, channel string, m message, r retention) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(&messageRow{OrgID: orgID, Channel: channel, Frame: string(m.Frame), Created: m.Time}); err != nil {
+			return err
+		}
+		if _, err := sess.Where("org_id = ? AND channel = ? AND created < ?", orgID, channel, m.Time.Add(-r.maxAge)).Delete(&messageRow{}); err != nil {
+			return err
+		}
+		if r.maxMessages == 0 {
+			return nil
+		}
+
+		// the oldest message to keep
+		var ids []int64
+		err := sess.Table("live_message_history").Where("org_id = ? AND channel = ?", orgID, channel).
+			Desc("id").Limit(1, r.maxMessages-1).Cols("id").Find(&ids)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		_, err = sess.Where("org_id = ? AND channel = ? AND id < ?", orgID, channel, ids[0]).Delete(&messageRow{})
+		return err
+	})
+}
+
+func (s *sqlStorage) list(ctx context.Context, orgID int64, channel string, since time.Time) ([]message, error) {
+	var rows []messageRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND channel = ? AND created >= ?", orgID, channel, since).Asc("id").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, message{Time: row.Created, Frame: json.RawMessage(row.Frame)})
+	}
+	return messages, nil
+}
+
+func (s *sqlStorage) deleteBefore(ctx context.Context, before time.Time) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("created < ?", before).Delete(&messageRow{})
+		return err
+	})
+}
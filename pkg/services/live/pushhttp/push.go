@@ -8,6 +8,7 @@ import (
 
 	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
 
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/live"
@@ -48,23 +49,40 @@ func (g *Gateway) Run(ctx context.Context) error {
 func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 	streamID := web.Params(ctx.Req)[":streamId"]
 
-	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgID, liveDto.ScopeStream, streamID)
+	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
-		logger.Error("Error getting stream", "error", err)
+		logger.Error("Error reading body", "error", err)
 		ctx.Resp.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	// TODO Grafana 8: decide which formats to use or keep all.
-	urlValues := ctx.Req.URL.Query()
-	frameFormat := pushurl.FrameFormatFromValues(urlValues)
+	// The streams with a push pipeline are processed by it.
+	processed, err := g.GrafanaLive.ProcessPush(ctx.Req.Context(), ctx.SignedInUser.OrgID, streamID, body)
+	if err != nil {
+		logger.Error("Error processing push pipeline", "error", err, "streamId", streamID)
+		var grafanaErr errutil.Error
+		if errors.As(err, &grafanaErr) {
+			ctx.Resp.WriteHeader(grafanaErr.Reason.Status().HTTPStatus())
+		} else {
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+	if processed {
+		ctx.Resp.WriteHeader(http.StatusOK)
+		return
+	}
 
-	body, err := io.ReadAll(ctx.Req.Body)
+	stream, err := g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgID, liveDto.ScopeStream, streamID)
 	if err != nil {
-		logger.Error("Error reading body", "error", err)
+		logger.Error("Error getting stream", "error", err)
 		ctx.Resp.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+
+	// TODO Grafana 8: decide which formats to use or keep all.
+	urlValues := ctx.Req.URL.Query()
+	frameFormat := pushurl.FrameFormatFromValues(urlValues)
 	logger.Debug("Live Push request",
 		"protocol", "http",
 		"streamId", streamID,
@@ -0,0 +1,56 @@
+package pushpipeline
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/live/push-pipelines", func(pipelines routing.RouteRegister) {
+		pipelines.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.listPipelinesHandler))
+		pipelines.Get("/:streamId", middleware.ReqOrgAdmin, routing.Wrap(s.getPipelineHandler))
+		pipelines.Put("/:streamId", middleware.ReqOrgAdmin, routing.Wrap(s.setPipelineHandler))
+		pipelines.Delete("/:streamId", middleware.ReqOrgAdmin, routing.Wrap(s.deletePipelineHandler))
+	})
+}
+
+func (s *Service) listPipelinesHandler(c *contextmodel.ReqContext) response.Response {
+	pipelines, err := s.ListPipelines(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list push pipelines", err)
+	}
+	return response.JSON(http.StatusOK, pipelines)
+}
+
+func (s *Service) getPipelineHandler(c *contextmodel.ReqContext) response.Response {
+	pipeline, err := s.GetPipeline(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":streamId"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get push pipeline", err)
+	}
+	return response.JSON(http.StatusOK, pipeline)
+}
+
+func (s *Service) setPipelineHandler(c *contextmodel.ReqContext) response.Response {
+	spec := PipelineSpec{}
+	if err := web.Bind(c.Req, &spec); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	pipeline, err := s.SetPipeline(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":streamId"], spec)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set push pipeline", err)
+	}
+	return response.JSON(http.StatusOK, pipeline)
+}
+
+func (s *Service) deletePipelineHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeletePipeline(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":streamId"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete push pipeline", err)
+	}
+	return response.Success("Push pipeline deleted")
+}
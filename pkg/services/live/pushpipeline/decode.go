@@ -0,0 +1,123 @@
+package pushpipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+)
+
+const (
+	jsonKey = "json"
+	// maxDecodedProtobufSize is the size of a remote write request once decompressed above which it's rejected,
+	// before it's decompressed.
+	maxDecodedProtobufSize = 32 << 20
+)
+
+// keyedFrame is a frame decoded from the pushed data, with the key the channels of the pipeline are
+// templated with.
+type keyedFrame struct {
+	key   string
+	frame *data.Frame
+}
+
+func (s *Service) decode(ctx context.Context, input Input, body []byte) ([]keyedFrame, error) {
+	switch input.Format {
+	case InputFormatInflux:
+		return decodeInflux(s.converter, input.FrameFormat, body)
+	case InputFormatJSON:
+		return decodeJSON(ctx, body)
+	case InputFormatProtobuf:
+		return decodeProtobuf(body)
+	default:
+		return nil, ErrInvalidPipeline.Errorf("unknown input format %q", input.Format)
+	}
+}
+
+func decodeInflux(converter *convert.Converter, frameFormat string, body []byte) ([]keyedFrame, error) {
+	if frameFormat == "" {
+		frameFormat = "labels_column"
+	}
+	metricFrames, err := converter.Convert(body, frameFormat)
+	if err != nil {
+		return nil, ErrInvalidInput.Errorf("invalid Influx line protocol: %w", err)
+	}
+	frames := make([]keyedFrame, 0, len(metricFrames))
+	for _, mf := range metricFrames {
+		frames = append(frames, keyedFrame{key: mf.Key(), frame: mf.Frame()})
+	}
+	return frames, nil
+}
+
+// decodeJSON flattens the values of the document into the fields of a frame, named after their path, for
+// example "cpu.load" or "disks[0].free", with the current time.
+func decodeJSON(ctx context.Context, body []byte) ([]keyedFrame, error) {
+	channelFrames, err := pipeline.NewAutoJsonConverter(pipeline.AutoJsonConverterConfig{}).
+		Convert(ctx, pipeline.Vars{Path: jsonKey}, body)
+	if err != nil {
+		return nil, ErrInvalidInput.Errorf("invalid JSON document: %w", err)
+	}
+	frames := make([]keyedFrame, 0, len(channelFrames))
+	for _, cf := range channelFrames {
+		frames = append(frames, keyedFrame{key: jsonKey, frame: cf.Frame})
+	}
+	return frames, nil
+}
+
+// decodeProtobuf converts the samples of a Prometheus remote write request into a frame per metric, with the
+// labels, time and value columns.
+func decodeProtobuf(body []byte) ([]keyedFrame, error) {
+	size, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, ErrInvalidInput.Errorf("invalid snappy compression: %w", err)
+	}
+	if size > maxDecodedProtobufSize {
+		return nil, ErrInvalidInput.Errorf("the remote write request is larger than %d bytes once decompressed", maxDecodedProtobufSize)
+	}
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, ErrInvalidInput.Errorf("invalid snappy compression: %w", err)
+	}
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(decoded, &req); err != nil {
+		return nil, ErrInvalidInput.Errorf("invalid remote write request: %w", err)
+	}
+
+	var frames []keyedFrame
+	byName := map[string]*data.Frame{}
+	for _, ts := range req.Timeseries {
+		var name string
+		labels := data.Labels{}
+		for _, label := range ts.Labels {
+			if label.Name == "__name__" {
+				name = label.Value
+			} else {
+				labels[label.Name] = label.Value
+			}
+		}
+		if name == "" {
+			continue
+		}
+
+		frame, ok := byName[name]
+		if !ok {
+			frame = data.NewFrame(name,
+				data.NewField("labels", nil, []string{}),
+				data.NewField("time", nil, []time.Time{}),
+				data.NewField("value", nil, []float64{}),
+			)
+			byName[name] = frame
+			frames = append(frames, keyedFrame{key: name, frame: frame})
+		}
+		for _, sample := range ts.Samples {
+			frame.AppendRow(labels.String(), time.UnixMilli(sample.Timestamp), sample.Value)
+		}
+	}
+	return frames, nil
+}
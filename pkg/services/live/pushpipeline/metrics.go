@@ -0,0 +1,39 @@
+package pushpipeline
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "live_push_pipeline"
+)
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		pushes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "pushes_total",
+			Help:      "Number of pushes processed by a push pipeline, by input format and result",
+		}, []string{"format", "result"}),
+		remoteWriteFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "remote_write_failures_total",
+			Help:      "Number of pushes whose frames couldn't be written to the remote write endpoint of their pipeline",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.pushes)
+		reg.MustRegister(m.remoteWriteFailures)
+	}
+
+	return m
+}
+
+type metrics struct {
+	pushes              *prometheus.CounterVec
+	remoteWriteFailures prometheus.Counter
+}
@@ -0,0 +1,98 @@
+package pushpipeline
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+// InputFormat is the encoding of the data pushed to a stream.
+type InputFormat string
+
+const (
+	// InputFormatInflux is the Influx line protocol, as sent by Telegraf.
+	InputFormatInflux InputFormat = "influx"
+	// InputFormatJSON is a JSON document, whose values are flattened into the fields of a single frame.
+	InputFormatJSON InputFormat = "json"
+	// InputFormatProtobuf is a snappy compressed Prometheus remote write request.
+	InputFormatProtobuf InputFormat = "protobuf"
+)
+
+// TransformType is the kind of change a transform makes to the fields of the frames.
+type TransformType string
+
+const (
+	TransformTypeKeep   TransformType = "keep"
+	TransformTypeDrop   TransformType = "drop"
+	TransformTypeRename TransformType = "rename"
+)
+
+// KeyPlaceholder is replaced in the channels of a pipeline with the key of each frame: the measurement of the
+// Influx lines, the metric name of the Prometheus samples, or "json" for the JSON documents.
+const KeyPlaceholder = "${key}"
+
+var (
+	ErrPipelineNotFound = errutil.NotFound("live.push-pipeline.not-found", errutil.WithPublicMessage("Push pipeline not found"))
+	ErrInvalidPipeline  = errutil.BadRequest("live.push-pipeline.invalid")
+	ErrInvalidInput     = errutil.BadRequest("live.push-pipeline.invalid-input")
+)
+
+// Pipeline is how the data pushed to a stream is decoded, transformed and published.
+type Pipeline struct {
+	OrgID    int64  `json:"orgId"`
+	StreamID string `json:"streamId"`
+	PipelineSpec
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// PipelineSpec is the body of the API setting the pipeline of a stream.
+type PipelineSpec struct {
+	Input Input `json:"input"`
+	// Transforms change the fields of every frame, in order.
+	Transforms []Transform `json:"transforms"`
+	// Channels are the channels every frame is published to, by default stream/<streamId>/${key}.
+	Channels    []string     `json:"channels"`
+	RemoteWrite *RemoteWrite `json:"remoteWrite,omitempty"`
+}
+
+type Input struct {
+	Format InputFormat `json:"format"`
+	// FrameFormat is the layout of the frames of the Influx lines, labels_column by default or wide.
+	FrameFormat string `json:"frameFormat,omitempty"`
+}
+
+type Transform struct {
+	Type TransformType `json:"type"`
+	// Fields are the names of the fields kept or dropped.
+	Fields []string `json:"fields,omitempty"`
+	// Field is renamed To.
+	Field string `json:"field,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// RemoteWrite sends the frames to a Prometheus remote write endpoint as well.
+type RemoteWrite struct {
+	URL  string `json:"url"`
+	User string `json:"user,omitempty"`
+	// Password is only set by the requests, the pipelines only tell whether it's set. An empty password
+	// keeps the one set before.
+	Password    string `json:"password,omitempty"`
+	PasswordSet bool   `json:"passwordSet"`
+}
+
+type pipelineRow struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	OrgID    int64  `xorm:"org_id"`
+	StreamID string `xorm:"stream_id"`
+	// Spec is the JSON of the spec of the pipeline, without the password of the remote write.
+	Spec string `xorm:"spec"`
+	// RemoteWritePassword is encrypted with the secrets service, and base64 encoded.
+	RemoteWritePassword string    `xorm:"remote_write_password"`
+	Created             time.Time `xorm:"created"`
+	Updated             time.Time `xorm:"updated"`
+}
+
+func (pipelineRow) TableName() string {
+	return "live_push_pipeline"
+}
@@ -0,0 +1,372 @@
+package pushpipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+const (
+	cacheTTL           = 30 * time.Second
+	maxChannels        = 10
+	remoteWriteTimeout = 5 * time.Second
+	// remoteWriteQueueSize is the number of pushes waiting to be written to their remote write endpoint, above
+	// which the frames of the pushes are not written.
+	remoteWriteQueueSize = 1000
+	remoteWriteWorkers   = 4
+)
+
+// compiledPipeline is a pipeline with the decrypted password of its remote write.
+type compiledPipeline struct {
+	spec     PipelineSpec
+	password string
+}
+
+// remoteWrite is the frames of a push waiting to be written to the remote write endpoint of its pipeline.
+type remoteWrite struct {
+	orgID    int64
+	pipeline *compiledPipeline
+	frames   []*data.Frame
+}
+
+// Service processes the data pushed to the streams of /api/live/push which have a pipeline: the data is
+// decoded, transformed, published to the channels of the pipeline and written to its remote write endpoint in
+// the background. The streams without a pipeline keep publishing the Influx lines pushed to them to
+// stream/<streamId>.
+type Service struct {
+	store      db.DB
+	secrets    secrets.Service
+	live       *live.GrafanaLive
+	converter  *convert.Converter
+	cache      *localcache.CacheService
+	httpClient *http.Client
+	writes     chan remoteWrite
+	metrics    *metrics
+	log        log.Logger
+	now        func() time.Time
+}
+
+func ProvideService(sqlStore db.DB, secretsService secrets.Service, routeRegister routing.RouteRegister,
+	grafanaLive *live.GrafanaLive, reg prometheus.Registerer) *Service {
+	s := &Service{
+		store:      sqlStore,
+		secrets:    secretsService,
+		live:       grafanaLive,
+		converter:  convert.NewConverter(),
+		cache:      localcache.New(cacheTTL, 2*cacheTTL),
+		httpClient: &http.Client{Timeout: remoteWriteTimeout},
+		writes:     make(chan remoteWrite, remoteWriteQueueSize),
+		metrics:    newMetrics(reg),
+		log:        log.New("live.push-pipeline"),
+		now:        time.Now,
+	}
+	grafanaLive.SetPushProcessor(s)
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Run writes the queued pushes to the remote write endpoints of their pipelines.
+func (s *Service) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < remoteWriteWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case w := <-s.writes:
+					if err := s.remoteWrite(ctx, w.pipeline, w.frames); err != nil {
+						s.metrics.remoteWriteFailures.Inc()
+						s.log.Error("Failed to write the pushed frames to the remote write endpoint", "orgId", w.orgID, "error", err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// ProcessPush processes the data pushed to the stream with its pipeline, and returns false when the stream
+// has no pipeline.
+func (s *Service) ProcessPush(ctx context.Context, orgID int64, streamID string, body []byte) (bool, error) {
+	p, err := s.getCompiled(ctx, orgID, streamID)
+	if err != nil {
+		return false, err
+	}
+	if p == nil {
+		return false, nil
+	}
+
+	err = s.process(ctx, orgID, p, body)
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	s.metrics.pushes.WithLabelValues(string(p.spec.Input.Format), result).Inc()
+	return true, err
+}
+
+func (s *Service) process(ctx context.Context, orgID int64, p *compiledPipeline, body []byte) error {
+	decoded, err := s.decode(ctx, p.spec.Input, body)
+	if err != nil {
+		return err
+	}
+
+	frames := make([]*data.Frame, 0, len(decoded))
+	for _, kf := range decoded {
+		frame := transform(p.spec.Transforms, kf.frame)
+		for _, channel := range p.spec.Channels {
+			channel = strings.ReplaceAll(channel, KeyPlaceholder, kf.key)
+			addr, err := liveDto.ParseChannel(channel)
+			if err != nil {
+				return ErrInvalidInput.Errorf("invalid channel %q for the key %q: %w", channel, kf.key, err)
+			}
+			stream, err := s.live.ManagedStreamRunner.GetOrCreateStream(orgID, addr.Scope, addr.Namespace)
+			if err != nil {
+				return err
+			}
+			if err := stream.Push(ctx, addr.Path, frame); err != nil {
+				return fmt.Errorf("failed to push to channel %s: %w", channel, err)
+			}
+		}
+		frames = append(frames, frame)
+	}
+
+	// the remote write is best effort, the frames are published already and are dropped when the queue is full
+	if p.spec.RemoteWrite != nil && len(frames) > 0 {
+		select {
+		case s.writes <- remoteWrite{orgID: orgID, pipeline: p, frames: frames}:
+		default:
+			s.metrics.remoteWriteFailures.Inc()
+			s.log.FromContext(ctx).Warn("Dropped the pushed frames, the remote write queue is full", "orgId", orgID)
+		}
+	}
+	return nil
+}
+
+func (s *Service) remoteWrite(ctx context.Context, p *compiledPipeline, frames []*data.Frame) error {
+	body, err := remotewrite.SerializeLabelsColumn(frames...)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.spec.RemoteWrite.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if p.spec.RemoteWrite.User != "" {
+		req.SetBasicAuth(p.spec.RemoteWrite.User, p.password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// getCompiled returns the pipeline of the stream, or nil when it has none. The pipelines are cached on this
+// instance for a short while, so that the changes made on another instance apply within the TTL of the cache.
+func (s *Service) getCompiled(ctx context.Context, orgID int64, streamID string) (*compiledPipeline, error) {
+	cacheKey := fmt.Sprintf("live-push-pipeline-%d-%s", orgID, streamID)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		return cached.(*compiledPipeline), nil
+	}
+
+	var p *compiledPipeline
+	row, err := s.getRow(ctx, orgID, streamID)
+	switch {
+	case errors.Is(err, ErrPipelineNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		p = &compiledPipeline{}
+		if err := json.Unmarshal([]byte(row.Spec), &p.spec); err != nil {
+			return nil, err
+		}
+		if p.password, err = s.decryptPassword(ctx, row.RemoteWritePassword); err != nil {
+			return nil, err
+		}
+	}
+	s.cache.SetDefault(cacheKey, p)
+	return p, nil
+}
+
+// ListPipelines returns the pipelines of the organization.
+func (s *Service) ListPipelines(ctx context.Context, orgID int64) ([]*Pipeline, error) {
+	rows, err := s.listRows(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	pipelines := make([]*Pipeline, 0, len(rows))
+	for _, row := range rows {
+		p, err := row.toPipeline()
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, p)
+	}
+	return pipelines, nil
+}
+
+// GetPipeline returns the pipeline of the stream.
+func (s *Service) GetPipeline(ctx context.Context, orgID int64, streamID string) (*Pipeline, error) {
+	row, err := s.getRow(ctx, orgID, streamID)
+	if err != nil {
+		return nil, err
+	}
+	return row.toPipeline()
+}
+
+// SetPipeline creates or replaces the pipeline of the stream.
+func (s *Service) SetPipeline(ctx context.Context, orgID int64, streamID string, spec PipelineSpec) (*Pipeline, error) {
+	if err := validate(streamID, &spec); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	row := &pipelineRow{OrgID: orgID, StreamID: streamID, Created: now, Updated: now}
+	if spec.RemoteWrite != nil {
+		password := spec.RemoteWrite.Password
+		if password == "" {
+			// keep the password set before
+			existing, err := s.getRow(ctx, orgID, streamID)
+			if err != nil && !errors.Is(err, ErrPipelineNotFound) {
+				return nil, err
+			}
+			if existing != nil {
+				row.RemoteWritePassword = existing.RemoteWritePassword
+			}
+		} else {
+			encrypted, err := s.secrets.Encrypt(ctx, []byte(password), secrets.WithoutScope())
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt the remote write password: %w", err)
+			}
+			row.RemoteWritePassword = base64.StdEncoding.EncodeToString(encrypted)
+		}
+		spec.RemoteWrite.Password = ""
+		spec.RemoteWrite.PasswordSet = false
+	}
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	row.Spec = string(specJSON)
+	if err := s.upsertRow(ctx, row); err != nil {
+		return nil, err
+	}
+	s.invalidate(orgID, streamID)
+	return row.toPipeline()
+}
+
+// DeletePipeline removes the pipeline of the stream, whose data is published to stream/<streamId> again.
+func (s *Service) DeletePipeline(ctx context.Context, orgID int64, streamID string) error {
+	if err := s.deleteRow(ctx, orgID, streamID); err != nil {
+		return err
+	}
+	s.invalidate(orgID, streamID)
+	return nil
+}
+
+func (s *Service) invalidate(orgID int64, streamID string) {
+	s.cache.Delete(fmt.Sprintf("live-push-pipeline-%d-%s", orgID, streamID))
+}
+
+func (s *Service) decryptPassword(ctx context.Context, encoded string) (string, error) {
+	if encoded == "" {
+		return "", nil
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	password, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the remote write password: %w", err)
+	}
+	return string(password), nil
+}
+
+// validate checks the spec of the pipeline of the stream, and sets its default channel.
+func validate(streamID string, spec *PipelineSpec) error {
+	if _, err := liveDto.ParseChannel(liveDto.ScopeStream + "/" + streamID + "/path"); err != nil {
+		return ErrInvalidPipeline.Errorf("invalid stream ID %q", streamID)
+	}
+
+	switch spec.Input.Format {
+	case InputFormatInflux:
+		if spec.Input.FrameFormat != "" && spec.Input.FrameFormat != "labels_column" && spec.Input.FrameFormat != "wide" {
+			return ErrInvalidPipeline.Errorf("unknown frame format %q", spec.Input.FrameFormat)
+		}
+	case InputFormatJSON, InputFormatProtobuf:
+		if spec.Input.FrameFormat != "" {
+			return ErrInvalidPipeline.Errorf("the frame format only applies to the influx input")
+		}
+	default:
+		return ErrInvalidPipeline.Errorf("unknown input format %q", spec.Input.Format)
+	}
+
+	if spec.Transforms == nil {
+		spec.Transforms = []Transform{}
+	}
+	for _, t := range spec.Transforms {
+		if err := validateTransform(t); err != nil {
+			return err
+		}
+	}
+
+	if len(spec.Channels) == 0 {
+		spec.Channels = []string{liveDto.ScopeStream + "/" + streamID + "/" + KeyPlaceholder}
+	}
+	if len(spec.Channels) > maxChannels {
+		return ErrInvalidPipeline.Errorf("more than %d channels", maxChannels)
+	}
+	for _, channel := range spec.Channels {
+		addr, err := liveDto.ParseChannel(strings.ReplaceAll(channel, KeyPlaceholder, "key"))
+		if err != nil {
+			return ErrInvalidPipeline.Errorf("invalid channel %q: %w", channel, err)
+		}
+		if addr.Scope != liveDto.ScopeStream {
+			return ErrInvalidPipeline.Errorf("the channel %q is not in the %s scope", channel, liveDto.ScopeStream)
+		}
+	}
+
+	if spec.RemoteWrite != nil {
+		u, err := url.Parse(spec.RemoteWrite.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidPipeline.Errorf("invalid remote write URL %q", spec.RemoteWrite.URL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package pushpipeline
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	secretsfakes "github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("should set the default channel", func(t *testing.T) {
+		spec := PipelineSpec{Input: Input{Format: InputFormatInflux}}
+		require.NoError(t, validate("telegraf", &spec))
+		assert.Equal(t, []string{"stream/telegraf/${key}"}, spec.Channels)
+		assert.Equal(t, []Transform{}, spec.Transforms)
+	})
+
+	invalid := map[string]PipelineSpec{
+		"unknown format":        {Input: Input{Format: "csv"}},
+		"unknown frame format":  {Input: Input{Format: InputFormatInflux, FrameFormat: "long"}},
+		"frame format of json":  {Input: Input{Format: InputFormatJSON, FrameFormat: "wide"}},
+		"unknown transform":     {Input: Input{Format: InputFormatJSON}, Transforms: []Transform{{Type: "upper"}}},
+		"keep without fields":   {Input: Input{Format: InputFormatJSON}, Transforms: []Transform{{Type: TransformTypeKeep}}},
+		"rename without to":     {Input: Input{Format: InputFormatJSON}, Transforms: []Transform{{Type: TransformTypeRename, Field: "a"}}},
+		"channel out of stream": {Input: Input{Format: InputFormatJSON}, Channels: []string{"grafana/dashboard/${key}"}},
+		"invalid remote write":  {Input: Input{Format: InputFormatJSON}, RemoteWrite: &RemoteWrite{URL: "ftp://example.org"}},
+	}
+	for name, spec := range invalid {
+		t.Run("should reject "+name, func(t *testing.T) {
+			require.ErrorIs(t, validate("telegraf", &spec), ErrInvalidPipeline)
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	frame := data.NewFrame("cpu",
+		data.NewField("labels", nil, []string{"host=a"}),
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("usage_user", nil, []float64{1}),
+		data.NewField("usage_system", nil, []float64{2}),
+		data.NewField("usage_idle", nil, []float64{97}),
+	)
+
+	frame = transform([]Transform{
+		{Type: TransformTypeDrop, Fields: []string{"usage_idle", "time"}},
+		{Type: TransformTypeKeep, Fields: []string{"usage_user"}},
+		{Type: TransformTypeRename, Field: "usage_user", To: "user"},
+	}, frame)
+
+	names := make([]string, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		names = append(names, field.Name)
+	}
+	assert.Equal(t, []string{"labels", "time", "user"}, names)
+}
+
+func TestDecodeProtobuf(t *testing.T) {
+	body, err := remotewrite.TimeSeriesToBytes([]prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "a"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 0, Timestamp: 2000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "b"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "job", Value: "unnamed"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	})
+	require.NoError(t, err)
+
+	frames, err := decodeProtobuf(body)
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, "up", frames[0].key)
+	rows, err := frames[0].frame.RowLen()
+	require.NoError(t, err)
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, "job=b", frames[0].frame.Fields[0].At(2))
+
+	_, err = decodeProtobuf([]byte("not snappy"))
+	require.ErrorIs(t, err, ErrInvalidInput)
+
+	// the size is checked before the request is decompressed
+	_, err = decodeProtobuf(binary.AppendUvarint(nil, maxDecodedProtobufSize+1))
+	require.ErrorIs(t, err, ErrInvalidInput)
+}
+
+type published struct {
+	mu       sync.Mutex
+	channels []string
+}
+
+func (p *published) publish(_ int64, channel string, _ []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.channels = append(p.channels, channel)
+	return nil
+}
+
+func TestIntegrationProcessPush(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var (
+		mu     sync.Mutex
+		writes []*http.Request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		writes = append(writes, r)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	pub := &published{}
+	s := &Service{
+		store:      db.InitTestDB(t),
+		secrets:    secretsfakes.NewFakeSecretsService(),
+		live:       &live.GrafanaLive{ManagedStreamRunner: managedstream.NewRunner(pub.publish, nil, managedstream.NewMemoryFrameCache())},
+		converter:  convert.NewConverter(),
+		cache:      localcache.New(cacheTTL, 2*cacheTTL),
+		httpClient: server.Client(),
+		writes:     make(chan remoteWrite, remoteWriteQueueSize),
+		metrics:    newMetrics(nil),
+		log:        log.NewNopLogger(),
+		now:        time.Now,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = s.Run(ctx) }()
+	body := []byte("cpu,host=a usage_user=1,usage_idle=99 1700000000000000000\nmem,host=a used=10 1700000000000000000")
+
+	t.Run("should not process the streams without pipeline", func(t *testing.T) {
+		processed, err := s.ProcessPush(ctx, 1, "telegraf", body)
+		require.NoError(t, err)
+		require.False(t, processed)
+	})
+
+	t.Run("should keep the remote write password when it's not set again", func(t *testing.T) {
+		spec := PipelineSpec{
+			Input:       Input{Format: InputFormatInflux},
+			Transforms:  []Transform{{Type: TransformTypeDrop, Fields: []string{"usage_idle"}}},
+			Channels:    []string{"stream/telegraf/${key}", "stream/metrics/all"},
+			RemoteWrite: &RemoteWrite{URL: server.URL, User: "user", Password: "secret"},
+		}
+		p, err := s.SetPipeline(ctx, 1, "telegraf", spec)
+		require.NoError(t, err)
+		assert.True(t, p.RemoteWrite.PasswordSet)
+		assert.Empty(t, p.RemoteWrite.Password)
+
+		spec.RemoteWrite = &RemoteWrite{URL: server.URL, User: "user"}
+		p, err = s.SetPipeline(ctx, 1, "telegraf", spec)
+		require.NoError(t, err)
+		assert.True(t, p.RemoteWrite.PasswordSet)
+	})
+
+	t.Run("should publish the frames to the channels of the pipeline and write them", func(t *testing.T) {
+		processed, err := s.ProcessPush(ctx, 1, "telegraf", body)
+		require.NoError(t, err)
+		require.True(t, processed)
+		assert.ElementsMatch(t, []string{"stream/telegraf/cpu", "stream/metrics/all", "stream/telegraf/mem", "stream/metrics/all"}, pub.channels)
+
+		// the frames are written in the background
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(writes) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		user, password, ok := writes[0].BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "secret", password)
+	})
+
+	t.Run("should reject the invalid data", func(t *testing.T) {
+		processed, err := s.ProcessPush(ctx, 1, "telegraf", []byte("not influx"))
+		require.True(t, processed)
+		require.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("should not process the stream anymore once its pipeline is deleted", func(t *testing.T) {
+		require.NoError(t, s.DeletePipeline(ctx, 1, "telegraf"))
+		_, err := s.GetPipeline(ctx, 1, "telegraf")
+		require.ErrorIs(t, err, ErrPipelineNotFound)
+
+		processed, err := s.ProcessPush(ctx, 1, "telegraf", body)
+		require.NoError(t, err)
+		require.False(t, processed)
+	})
+}
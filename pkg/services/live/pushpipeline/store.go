@@ -0,0 +1,78 @@
+package pushpipeline
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) listRows(ctx context.Context, orgID int64) ([]pipelineRow, error) {
+	rows := make([]pipelineRow, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("stream_id").Find(&rows)
+	})
+	return rows, err
+}
+
+func (s *Service) getRow(ctx context.Context, orgID int64, streamID string) (*pipelineRow, error) {
+	row := &pipelineRow{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND stream_id = ?", orgID, streamID).Get(row)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrPipelineNotFound.Errorf("push pipeline of stream %s not found", streamID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// upsertRow creates or replaces the pipeline of the stream, and sets the ID and created time of the row.
+func (s *Service) upsertRow(ctx context.Context, row *pipelineRow) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &pipelineRow{}
+		exists, err := sess.Where("org_id = ? AND stream_id = ?", row.OrgID, row.StreamID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err := sess.Insert(row)
+			return err
+		}
+
+		row.ID = existing.ID
+		row.Created = existing.Created
+		_, err = sess.ID(row.ID).Cols("spec", "remote_write_password", "updated").Update(row)
+		return err
+	})
+}
+
+func (s *Service) deleteRow(ctx context.Context, orgID int64, streamID string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND stream_id = ?", orgID, streamID).Delete(&pipelineRow{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPipelineNotFound.Errorf("push pipeline of stream %s not found", streamID)
+		}
+		return nil
+	})
+}
+
+func (row pipelineRow) toPipeline() (*Pipeline, error) {
+	p := &Pipeline{OrgID: row.OrgID, StreamID: row.StreamID, Created: row.Created, Updated: row.Updated}
+	if err := json.Unmarshal([]byte(row.Spec), &p.PipelineSpec); err != nil {
+		return nil, err
+	}
+	if p.RemoteWrite != nil {
+		p.RemoteWrite.PasswordSet = row.RemoteWritePassword != ""
+	}
+	return p, nil
+}
@@ -0,0 +1,53 @@
+package pushpipeline
+
+import (
+	"slices"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+func validateTransform(t Transform) error {
+	switch t.Type {
+	case TransformTypeKeep, TransformTypeDrop:
+		if len(t.Fields) == 0 {
+			return ErrInvalidPipeline.Errorf("missing fields of the %s transform", t.Type)
+		}
+	case TransformTypeRename:
+		if t.Field == "" || t.To == "" {
+			return ErrInvalidPipeline.Errorf("missing field or to of the rename transform")
+		}
+	default:
+		return ErrInvalidPipeline.Errorf("unknown transform type %q", t.Type)
+	}
+	return nil
+}
+
+// transform applies the transforms to the frame, in order. The time and labels fields of the frames are
+// always kept, so that the frames can still be published and written.
+func transform(transforms []Transform, frame *data.Frame) *data.Frame {
+	for _, t := range transforms {
+		switch t.Type {
+		case TransformTypeKeep, TransformTypeDrop:
+			keep := t.Type == TransformTypeKeep
+			fields := make([]*data.Field, 0, len(frame.Fields))
+			for i, field := range frame.Fields {
+				if isKeyField(i, field) || slices.Contains(t.Fields, field.Name) == keep {
+					fields = append(fields, field)
+				}
+			}
+			frame = data.NewFrame(frame.Name, fields...)
+		case TransformTypeRename:
+			for i, field := range frame.Fields {
+				if field.Name == t.Field && !isKeyField(i, field) {
+					field.Name = t.To
+				}
+			}
+		}
+	}
+	return frame
+}
+
+// isKeyField returns true for the time fields, and the labels column of the frames in the labels_column layout.
+func isKeyField(i int, field *data.Field) bool {
+	return field.Type().Time() || (i == 0 && field.Name == "labels" && field.Type() == data.FieldTypeString)
+}
@@ -1,6 +1,7 @@
 package pushws
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -13,16 +14,23 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
 )
 
+// PushProcessor processes the data pushed to the streams which have their own pipeline.
+type PushProcessor interface {
+	// ProcessPush returns false when the stream has no pipeline.
+	ProcessPush(ctx context.Context, orgID int64, streamID string, body []byte) (bool, error)
+}
+
 // Handler handles WebSocket client connections that push data to Live.
 type Handler struct {
 	managedStreamRunner *managedstream.Runner
+	processor           PushProcessor
 	config              Config
 	upgrade             *websocket.Upgrader
 	converter           *convert.Converter
 }
 
 // NewHandler creates new Handler.
-func NewHandler(managedStreamRunner *managedstream.Runner, c Config) *Handler {
+func NewHandler(managedStreamRunner *managedstream.Runner, processor PushProcessor, c Config) *Handler {
 	if c.CheckOrigin == nil {
 		c.CheckOrigin = sameHostOriginCheck()
 	}
@@ -33,6 +41,7 @@ func NewHandler(managedStreamRunner *managedstream.Runner, c Config) *Handler {
 	}
 	return &Handler{
 		managedStreamRunner: managedStreamRunner,
+		processor:           processor,
 		config:              c,
 		upgrade:             upgrade,
 		converter:           convert.NewConverter(),
@@ -70,6 +79,15 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		processed, err := s.processor.ProcessPush(r.Context(), user.GetOrgID(), streamID, body)
+		if err != nil {
+			logger.Error("Error processing push pipeline", "error", err, "streamId", streamID)
+			continue
+		}
+		if processed {
+			continue
+		}
+
 		stream, err := s.managedStreamRunner.GetOrCreateStream(user.GetOrgID(), liveDto.ScopeStream, streamID)
 		if err != nil {
 			logger.Error("Error getting stream", "error", err)
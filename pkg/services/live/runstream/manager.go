@@ -61,6 +61,7 @@ type Manager struct {
 	checkInterval           time.Duration
 	maxChecks               int
 	datasourceCheckInterval time.Duration
+	orgStreamLimits         map[string]int
 }
 
 // ManagerOption modifies Manager behavior (used for tests for example).
@@ -74,6 +75,16 @@ func WithCheckConfig(interval time.Duration, maxChecks int) ManagerOption {
 	}
 }
 
+// WithOrgStreamLimit limits the number of streams of a plugin an organization can have open at once.
+// A limit of 0 means unlimited.
+func WithOrgStreamLimit(pluginID string, limit int) ManagerOption {
+	return func(sm *Manager) {
+		if limit > 0 {
+			sm.orgStreamLimits[pluginID] = limit
+		}
+	}
+}
+
 const (
 	defaultCheckInterval           = 5 * time.Second
 	defaultDatasourceCheckInterval = time.Minute
@@ -93,6 +104,7 @@ func NewManager(channelSender ChannelLocalPublisher, presenceGetter NumLocalSubs
 		checkInterval:           defaultCheckInterval,
 		maxChecks:               defaultMaxChecks,
 		datasourceCheckInterval: defaultDatasourceCheckInterval,
+		orgStreamLimits:         map[string]int{},
 	}
 	for _, opt := range opts {
 		opt(sm)
@@ -326,6 +338,9 @@ func (s *Manager) runStream(ctx context.Context, cancelFn func(), sr streamReque
 
 var errClosed = errors.New("stream manager closed")
 
+// ErrOrgStreamLimitReached is returned when submitting a stream would exceed the stream limit of an organization.
+var ErrOrgStreamLimitReached = errors.New("organization stream limit reached")
+
 type streamContext struct {
 	CloseCh       chan struct{}
 	cancelFn      func()
@@ -339,6 +354,11 @@ func (s *Manager) registerStream(ctx context.Context, sr submitRequest) {
 		sr.responseCh <- submitResponse{Result: submitResult{StreamExists: true, CloseNotify: streamCtx.CloseCh}}
 		return
 	}
+	if s.orgStreamLimitReached(sr.streamRequest.PluginContext) {
+		s.mu.Unlock()
+		sr.responseCh <- submitResponse{Error: ErrOrgStreamLimitReached}
+		return
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	closeCh := make(chan struct{})
@@ -361,6 +381,22 @@ func (s *Manager) registerStream(ctx context.Context, sr submitRequest) {
 	s.runStream(ctx, cancel, sr.streamRequest)
 }
 
+// orgStreamLimitReached must be called with s.mu locked.
+func (s *Manager) orgStreamLimitReached(pCtx backend.PluginContext) bool {
+	limit, ok := s.orgStreamLimits[pCtx.PluginID]
+	if !ok {
+		return false
+	}
+	var numStreams int
+	for _, streamCtx := range s.streams {
+		other := streamCtx.streamRequest.PluginContext
+		if other.OrgID == pCtx.OrgID && other.PluginID == pCtx.PluginID {
+			numStreams++
+		}
+	}
+	return numStreams >= limit
+}
+
 // Run Manager till context canceled.
 func (s *Manager) Run(ctx context.Context) error {
 	s.baseCtx = ctx
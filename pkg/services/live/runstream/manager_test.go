@@ -183,6 +183,55 @@ func TestStreamManager_SubmitStream_DifferentOrgID(t *testing.T) {
 	waitWithTimeout(t, doneCh2, time.Second)
 }
 
+func TestStreamManager_SubmitStream_OrgStreamLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockPacketSender := NewMockChannelLocalPublisher(mockCtrl)
+	mockNumSubscribersGetter := NewMockNumLocalSubscribersGetter(mockCtrl)
+	mockContextGetter := NewMockPluginContextGetter(mockCtrl)
+
+	manager := NewManager(mockPacketSender, mockNumSubscribersGetter, mockContextGetter, WithOrgStreamLimit("test-plugin", 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = manager.Run(ctx)
+	}()
+
+	mockStreamRunner := NewMockStreamRunner(mockCtrl)
+	mockStreamRunner.EXPECT().RunStream(
+		gomock.Any(), gomock.Any(), gomock.Any(),
+	).DoAndReturn(func(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}).AnyTimes()
+
+	org1 := backend.PluginContext{OrgID: 1, PluginID: "test-plugin"}
+	org2 := backend.PluginContext{OrgID: 2, PluginID: "test-plugin"}
+	otherPlugin := backend.PluginContext{OrgID: 1, PluginID: "other-plugin"}
+
+	result, err := manager.SubmitStream(context.Background(), &user.SignedInUser{UserID: 2, OrgID: 1}, "1/a", "a", nil, org1, mockStreamRunner, false)
+	require.NoError(t, err)
+	require.False(t, result.StreamExists)
+
+	// subscribing to an existing stream does not count towards the limit.
+	result, err = manager.SubmitStream(context.Background(), &user.SignedInUser{UserID: 3, OrgID: 1}, "1/a", "a", nil, org1, mockStreamRunner, false)
+	require.NoError(t, err)
+	require.True(t, result.StreamExists)
+
+	_, err = manager.SubmitStream(context.Background(), &user.SignedInUser{UserID: 2, OrgID: 1}, "1/b", "b", nil, org1, mockStreamRunner, false)
+	require.ErrorIs(t, err, ErrOrgStreamLimitReached)
+
+	result, err = manager.SubmitStream(context.Background(), &user.SignedInUser{UserID: 2, OrgID: 2}, "2/b", "b", nil, org2, mockStreamRunner, false)
+	require.NoError(t, err)
+	require.False(t, result.StreamExists)
+
+	result, err = manager.SubmitStream(context.Background(), &user.SignedInUser{UserID: 2, OrgID: 1}, "1/c", "c", nil, otherPlugin, mockStreamRunner, false)
+	require.NoError(t, err)
+	require.False(t, result.StreamExists)
+}
+
 func TestStreamManager_SubmitStream_CloseNoSubscribers(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
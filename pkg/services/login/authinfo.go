@@ -10,6 +10,7 @@ import (
 type AuthInfoService interface {
 	GetAuthInfo(ctx context.Context, query *GetAuthInfoQuery) (*UserAuth, error)
 	GetUserLabels(ctx context.Context, query GetUserLabelsQuery) (map[int64]string, error)
+	GetExpiringOAuthAuthInfo(ctx context.Context, query *GetExpiringOAuthAuthInfoQuery) ([]*UserAuth, error)
 	SetAuthInfo(ctx context.Context, cmd *SetAuthInfoCommand) error
 	UpdateAuthInfo(ctx context.Context, cmd *UpdateAuthInfoCommand) error
 	DeleteUserAuthInfo(ctx context.Context, userID int64) error
@@ -18,6 +19,7 @@ type AuthInfoService interface {
 type Store interface {
 	GetAuthInfo(ctx context.Context, query *GetAuthInfoQuery) (*UserAuth, error)
 	GetUserLabels(ctx context.Context, query GetUserLabelsQuery) (map[int64]string, error)
+	GetExpiringOAuthAuthInfo(ctx context.Context, query *GetExpiringOAuthAuthInfoQuery) ([]*UserAuth, error)
 	SetAuthInfo(ctx context.Context, cmd *SetAuthInfoCommand) error
 	UpdateAuthInfo(ctx context.Context, cmd *UpdateAuthInfoCommand) error
 	DeleteUserAuthInfo(ctx context.Context, userID int64) error
@@ -33,6 +35,7 @@ const (
 	JWTModule           = "jwt"
 	ExtendedJWTModule   = "extendedjwt"
 	RenderModule        = "render"
+	SignedURLModule     = "signedurl"
 	// OAuth provider modules
 	AzureADAuthModule    = "oauth_azuread"
 	GoogleAuthModule     = "oauth_google"
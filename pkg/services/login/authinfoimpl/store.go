@@ -106,6 +106,27 @@ func (s *Store) GetUserLabels(ctx context.Context, query login.GetUserLabelsQuer
 	return labelMap, nil
 }
 
+// GetExpiringOAuthAuthInfo returns the OAuth auth info with an access token about to expire. The tokens are
+// not read, the auth info of a user has to be read with GetAuthInfo to refresh them.
+func (s *Store) GetExpiringOAuthAuthInfo(ctx context.Context, query *login.GetExpiringOAuthAuthInfoQuery) ([]*login.UserAuth, error) {
+	userAuths := make([]*login.UserAuth, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("user_auth").
+			Cols("id", "user_id", "auth_module", "auth_id", "created", "o_auth_expiry").
+			Where("auth_module LIKE ?", "oauth%").
+			And("o_auth_expiry > ? AND o_auth_expiry <= ?", GetTime(), query.ExpiresBefore).
+			OrderBy("o_auth_expiry")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Find(&userAuths)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userAuths, nil
+}
+
 func (s *Store) SetAuthInfo(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
 	authUser := &login.UserAuth{
 		UserId:     cmd.UserId,
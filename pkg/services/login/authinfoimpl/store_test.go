@@ -124,6 +124,41 @@ func TestIntegrationAuthInfoStore(t *testing.T) {
 		count = countEntries(t, sql, setCmd.AuthModule, setCmd.AuthId, setCmd.UserId)
 		require.Equal(t, 1, count)
 	})
+
+	t.Run("should get the oauth auth info about to expire", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Now()
+		setAuthInfo := func(authModule string, userID int64, expiry time.Time) {
+			require.NoError(t, store.SetAuthInfo(ctx, &login.SetAuthInfoCommand{
+				AuthModule: authModule,
+				AuthId:     "expiring",
+				UserId:     userID,
+				OAuthToken: &oauth2.Token{AccessToken: "atoken", RefreshToken: "rtoken", Expiry: expiry},
+			}))
+		}
+		setAuthInfo(login.GenericOAuthModule, 20, now.Add(4*time.Minute))
+		setAuthInfo(login.GoogleAuthModule, 21, now.Add(2*time.Minute))
+		setAuthInfo(login.GenericOAuthModule, 22, now.Add(time.Hour))
+		setAuthInfo(login.GenericOAuthModule, 23, now.Add(-time.Minute))
+		setAuthInfo(login.LDAPAuthModule, 24, now.Add(time.Minute))
+
+		userAuths, err := store.GetExpiringOAuthAuthInfo(ctx, &login.GetExpiringOAuthAuthInfoQuery{
+			ExpiresBefore: now.Add(5 * time.Minute),
+		})
+		require.NoError(t, err)
+		require.Len(t, userAuths, 2)
+		require.Equal(t, int64(21), userAuths[0].UserId)
+		require.Equal(t, login.GoogleAuthModule, userAuths[0].AuthModule)
+		require.Equal(t, int64(20), userAuths[1].UserId)
+		require.Empty(t, userAuths[1].OAuthRefreshToken)
+
+		userAuths, err = store.GetExpiringOAuthAuthInfo(ctx, &login.GetExpiringOAuthAuthInfoQuery{
+			ExpiresBefore: now.Add(5 * time.Minute),
+			Limit:         1,
+		})
+		require.NoError(t, err)
+		require.Len(t, userAuths, 1)
+	})
 }
 
 func countEntries(t *testing.T, sql db.DB, authModule, authID string, userID int64) int {
@@ -13,6 +13,7 @@ type FakeService struct {
 	ExpectedExternalUser *login.ExternalUserInfo
 	ExpectedError        error
 	ExpectedLabels       map[int64]string
+	ExpectedUserAuths    []*login.UserAuth
 
 	SetAuthInfoFn    func(ctx context.Context, cmd *login.SetAuthInfoCommand) error
 	UpdateAuthInfoFn func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error
@@ -27,6 +28,10 @@ func (a *FakeService) GetUserLabels(ctx context.Context, query login.GetUserLabe
 	return a.ExpectedLabels, a.ExpectedError
 }
 
+func (a *FakeService) GetExpiringOAuthAuthInfo(ctx context.Context, query *login.GetExpiringOAuthAuthInfoQuery) ([]*login.UserAuth, error) {
+	return a.ExpectedUserAuths, a.ExpectedError
+}
+
 func (a *FakeService) SetAuthInfo(ctx context.Context, cmd *login.SetAuthInfoCommand) error {
 	if a.SetAuthInfoFn != nil {
 		return a.SetAuthInfoFn(ctx, cmd)
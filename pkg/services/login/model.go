@@ -117,3 +117,10 @@ type GetAuthInfoQuery struct {
 type GetUserLabelsQuery struct {
 	UserIDs []int64
 }
+
+// GetExpiringOAuthAuthInfoQuery looks up the OAuth auth info with an access token that has not expired yet,
+// but expires before ExpiresBefore. The soonest to expire come first.
+type GetExpiringOAuthAuthInfoQuery struct {
+	ExpiresBefore time.Time
+	Limit         int
+}
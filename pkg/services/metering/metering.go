@@ -0,0 +1,63 @@
+// Package metering aggregates the usage of the organizations into hourly records, for chargeback in multi-tenant
+// installations.
+package metering
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidFormat = errors.New("invalid export format")
+
+// Metric is a usage metric of an organization.
+type Metric string
+
+const (
+	// MetricDashboards is the number of dashboards of the organization.
+	MetricDashboards Metric = "dashboards"
+	// MetricActiveUsers is the number of members of the organization seen during the hour.
+	MetricActiveUsers Metric = "active_users"
+	// MetricQueries is the number of data source queries run for the organization.
+	MetricQueries Metric = "queries"
+	// MetricAlertEvaluations is the number of evaluations of the alert and recording rules of the organization.
+	MetricAlertEvaluations Metric = "alert_evaluations"
+	// MetricRenderedImages is the number of images rendered for the organization.
+	MetricRenderedImages Metric = "rendered_images"
+)
+
+// Recorder records the usage events of the organizations.
+type Recorder interface {
+	// Record adds count events of a metric to the usage of an organization in the current hour.
+	Record(orgID int64, metric Metric, count int64)
+}
+
+type Service interface {
+	Recorder
+	// GetRecords returns the hourly usage records matching the query, sorted by hour, organization and metric.
+	GetRecords(ctx context.Context, query *GetRecordsQuery) ([]*Record, error)
+}
+
+// Record is the usage of an organization for a metric during an hour. The counts of the events are summed over the
+// hour, and the counts of the resources are the last values counted during the hour.
+type Record struct {
+	OrgID  int64     `json:"orgId"`
+	Hour   time.Time `json:"hour"`
+	Metric Metric    `json:"metric"`
+	Value  int64     `json:"value"`
+}
+
+type GetRecordsQuery struct {
+	// OrgID filters the records of an organization, all the organizations are returned when it's 0.
+	OrgID int64
+	// From and To are the bounds of the hours of the records, From is inclusive and To exclusive.
+	From time.Time
+	To   time.Time
+}
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
@@ -0,0 +1,60 @@
+package meteringimpl
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/metering"
+)
+
+// defaultRange is the range of the records returned when the query doesn't set one.
+const defaultRange = 24 * time.Hour
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/admin/metering", func(entities routing.RouteRegister) {
+		entities.Get("/records", routing.Wrap(s.getRecordsHandler))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+func (s *Service) getRecordsHandler(c *contextmodel.ReqContext) response.Response {
+	to := s.now()
+	if ms := c.QueryInt64("to"); ms > 0 {
+		to = time.UnixMilli(ms)
+	}
+	from := to.Add(-defaultRange)
+	if ms := c.QueryInt64("from"); ms > 0 {
+		from = time.UnixMilli(ms)
+	}
+	if !from.Before(to) {
+		return response.Error(http.StatusBadRequest, "from must be before to", nil)
+	}
+
+	records, err := s.GetRecords(c.Req.Context(), &metering.GetRecordsQuery{
+		OrgID: c.QueryInt64("orgId"),
+		From:  from,
+		To:    to,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get usage records", err)
+	}
+
+	format := metering.Format(c.Query("format"))
+	if format == "" || format == metering.FormatJSON {
+		return response.JSON(http.StatusOK, records)
+	}
+	body, contentType, err := encode(records, format)
+	if err != nil {
+		if errors.Is(err, metering.ErrInvalidFormat) {
+			return response.Error(http.StatusBadRequest, "format must be json or csv", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to encode usage records", err)
+	}
+	return response.Respond(http.StatusOK, body).
+		SetHeader("Content-Type", contentType).
+		SetHeader("Content-Disposition", `attachment; filename="usage.csv"`)
+}
@@ -0,0 +1,142 @@
+package meteringimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/metering"
+)
+
+// maxErrorBodySize is the size of the response body of the webhook kept in the error when a push fails.
+const maxErrorBodySize = 1024
+
+// encode encodes the records as a JSON array, or as CSV with a header line.
+func encode(records []*metering.Record, format metering.Format) ([]byte, string, error) {
+	switch format {
+	case metering.FormatJSON, "":
+		body, err := json.Marshal(records)
+		return body, "application/json", err
+	case metering.FormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write([]string{"org_id", "hour", "metric", "value"}); err != nil {
+			return nil, "", err
+		}
+		for _, record := range records {
+			if err := w.Write([]string{
+				strconv.FormatInt(record.OrgID, 10),
+				record.Hour.UTC().Format(time.RFC3339),
+				string(record.Metric),
+				strconv.FormatInt(record.Value, 10),
+			}); err != nil {
+				return nil, "", err
+			}
+		}
+		w.Flush()
+		return buf.Bytes(), "text/csv", w.Error()
+	default:
+		return nil, "", fmt.Errorf("%w: %q", metering.ErrInvalidFormat, format)
+	}
+}
+
+// lastPushedHourKey is the key of the start of the last hour pushed to the webhook, in epoch seconds.
+const lastPushedHourKey = "last_pushed_hour"
+
+// pushPreviousHour pushes the records of each hour since the last one pushed, up to the hour before the current one,
+// so that the hours missed while Grafana was down or the webhook was failing are caught up. The hours older than the
+// retention are not caught up, their records are deleted.
+func (s *Service) pushPreviousHour(ctx context.Context) error {
+	previous := s.hour(s.now()).Add(-time.Hour)
+	from, err := s.nextHourToPush(ctx, previous)
+	if err != nil {
+		return err
+	}
+
+	for hour := from; !hour.After(previous); hour = hour.Add(time.Hour) {
+		if err := s.pushHour(ctx, hour); err != nil {
+			return err
+		}
+		if err := s.kv.Set(ctx, lastPushedHourKey, strconv.FormatInt(hour.Unix(), 10)); err != nil {
+			return fmt.Errorf("failed to store the last pushed hour: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextHourToPush returns the hour after the last one pushed, or the previous hour if none was pushed yet.
+func (s *Service) nextHourToPush(ctx context.Context, previous time.Time) (time.Time, error) {
+	value, ok, err := s.kv.Get(ctx, lastPushedHourKey)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get the last pushed hour: %w", err)
+	}
+	if !ok {
+		return previous, nil
+	}
+	last, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		s.log.Warn("Invalid last pushed hour, pushing the previous hour", "value", value, "error", err)
+		return previous, nil
+	}
+
+	next := time.Unix(last, 0).UTC().Add(time.Hour)
+	if oldest := s.hour(s.now().Add(-s.settings.Retention)); next.Before(oldest) {
+		next = oldest
+	}
+	return next, nil
+}
+
+// pushHour pushes the records of an hour to the webhook.
+func (s *Service) pushHour(ctx context.Context, from time.Time) error {
+	records, err := s.store.getRecords(ctx, &metering.GetRecordsQuery{From: from, To: from.Add(time.Hour)})
+	if err != nil {
+		return fmt.Errorf("failed to get usage records: %w", err)
+	}
+
+	err = s.push(ctx, records)
+	if err != nil {
+		s.metrics.pushes.WithLabelValues("failure").Inc()
+		return fmt.Errorf("failed to push usage records of %s: %w", from.Format(time.RFC3339), err)
+	}
+	s.metrics.pushes.WithLabelValues("success").Inc()
+	s.log.Debug("Pushed usage records", "hour", from, "count", len(records))
+	return nil
+}
+
+func (s *Service) push(ctx context.Context, records []*metering.Record) error {
+	body, contentType, err := encode(records, metering.Format(s.settings.WebhookFormat))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.settings.WebhookAuthorizationHeader != "" {
+		req.Header.Set("Authorization", s.settings.WebhookAuthorizationHeader)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package meteringimpl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/jobs"
+	"github.com/grafana/grafana/pkg/services/metering"
+	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// flushTimeout is the timeout of the flush of the counted events when Grafana stops.
+const flushTimeout = 10 * time.Second
+
+// usageKey identifies the usage of an organization for a metric during an hour.
+type usageKey struct {
+	orgID int64
+	// periodStart is the start of the hour, in epoch seconds.
+	periodStart int64
+	metric      metering.Metric
+}
+
+// Service counts the usage events of the organizations in memory, and adds them to the hourly records stored in the
+// database on an interval, so that the records sum the events of all the instances. The resources of the
+// organizations are counted by a job, which runs on one instance.
+type Service struct {
+	store    store
+	settings setting.MeteringSettings
+	metrics  *metrics
+	log      log.Logger
+	now      func() time.Time
+	client   *http.Client
+
+	// kv stores the last hour pushed to the webhook.
+	kv *kvstore.NamespacedKVStore
+	// evalTotal is the counter of the rule evaluations of each organization, whose increase is recorded.
+	evalTotal *prometheus.CounterVec
+
+	mtx     sync.Mutex
+	pending map[usageKey]int64
+	// evaluations is the last value of the evaluation counter of each organization.
+	evaluations map[string]float64
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, kvStore kvstore.KVStore, jobService *jobs.Service,
+	ngMetrics *ngmetrics.NGAlert, routeRegister routing.RouteRegister, registerer prometheus.Registerer) *Service {
+	s := &Service{
+		store:       &dbStore{db: sqlStore},
+		kv:          kvstore.WithNamespace(kvStore, 0, "metering"),
+		settings:    cfg.Metering,
+		metrics:     newMetrics(registerer),
+		log:         log.New("metering"),
+		now:         time.Now,
+		client:      &http.Client{Timeout: cfg.Metering.WebhookTimeout},
+		pending:     map[usageKey]int64{},
+		evaluations: map[string]float64{},
+	}
+	if ngMetrics != nil {
+		s.evalTotal = ngMetrics.GetSchedulerMetrics().EvalTotal
+	}
+	if s.settings.FlushInterval <= 0 {
+		s.settings.FlushInterval = time.Minute
+	}
+	if !s.settings.Enabled {
+		return s
+	}
+
+	s.registerJobs(jobService)
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+func (s *Service) registerJobs(jobService *jobs.Service) {
+	definitions := []jobs.Definition{
+		{
+			Name:        "metering.count-resources",
+			Description: "Counts the dashboards and the active users of the organizations for the usage records.",
+			Schedule:    "@every 5m",
+			Timeout:     time.Minute,
+			Handler: func(ctx context.Context, _ []byte) error {
+				return s.countResources(ctx)
+			},
+		},
+		{
+			Name:        "metering.delete-expired-records",
+			Description: "Deletes the usage records older than the retention.",
+			Schedule:    "@daily",
+			Timeout:     5 * time.Minute,
+			MaxAttempts: 3,
+			Handler: func(ctx context.Context, _ []byte) error {
+				return s.deleteExpiredRecords(ctx)
+			},
+		},
+	}
+	if s.settings.WebhookURL != "" {
+		definitions = append(definitions, jobs.Definition{
+			Name:        "metering.push",
+			Description: "Pushes the usage records of the hours since the last push to the webhook.",
+			Schedule:    s.settings.WebhookSchedule,
+			Timeout:     2 * s.settings.WebhookTimeout,
+			MaxAttempts: 3,
+			Handler: func(ctx context.Context, _ []byte) error {
+				return s.pushPreviousHour(ctx)
+			},
+		})
+	}
+
+	for _, def := range definitions {
+		if err := jobService.Register(def); err != nil {
+			s.log.Error("Failed to register metering job", "job", def.Name, "error", err)
+		}
+	}
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled
+}
+
+// Run adds the counted events to the records on the flush interval, and a last time when Grafana stops.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(ctx); err != nil {
+				s.log.Error("Failed to flush usage events", "error", err)
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			if err := s.flush(flushCtx); err != nil {
+				s.log.Error("Failed to flush usage events", "error", err)
+			}
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// Record counts events of an organization in the current hour, they are stored on the next flush.
+func (s *Service) Record(orgID int64, metric metering.Metric, count int64) {
+	if !s.settings.Enabled || orgID <= 0 || count <= 0 {
+		return
+	}
+	key := usageKey{orgID: orgID, periodStart: s.hour(s.now()).Unix(), metric: metric}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[key] += count
+}
+
+func (s *Service) GetRecords(ctx context.Context, query *metering.GetRecordsQuery) ([]*metering.Record, error) {
+	return s.store.getRecords(ctx, query)
+}
+
+// flush adds the counted events to the records. The events which failed to be stored are kept for the next flush.
+func (s *Service) flush(ctx context.Context) error {
+	s.recordEvaluations()
+
+	s.mtx.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]int64{}
+	s.mtx.Unlock()
+
+	for key, count := range pending {
+		if err := s.store.add(ctx, key, count); err != nil {
+			s.mtx.Lock()
+			for key, count := range pending {
+				s.pending[key] += count
+			}
+			s.mtx.Unlock()
+			s.metrics.flushes.WithLabelValues("failure").Inc()
+			return err
+		}
+		delete(pending, key)
+	}
+	s.metrics.flushes.WithLabelValues("success").Inc()
+	return nil
+}
+
+// recordEvaluations records the increase of the evaluation counter of each organization since the last flush. The
+// counter starts at zero with Grafana, like the records.
+func (s *Service) recordEvaluations() {
+	if s.evalTotal == nil {
+		return
+	}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		s.evalTotal.Collect(ch)
+		close(ch)
+	}()
+
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			continue
+		}
+		org := ""
+		for _, label := range pb.GetLabel() {
+			if label.GetName() == "org" {
+				org = label.GetValue()
+			}
+		}
+		orgID, err := strconv.ParseInt(org, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		value := pb.GetCounter().GetValue()
+		s.mtx.Lock()
+		previous := s.evaluations[org]
+		s.evaluations[org] = value
+		s.mtx.Unlock()
+		if delta := int64(value - previous); delta > 0 {
+			s.Record(orgID, metering.MetricAlertEvaluations, delta)
+		}
+	}
+}
+
+// countResources sets the dashboards and the active users of the organizations in the records of the current hour.
+func (s *Service) countResources(ctx context.Context) error {
+	hour := s.hour(s.now())
+
+	dashboards, err := s.store.countDashboards(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count dashboards: %w", err)
+	}
+	activeUsers, err := s.store.countActiveUsers(ctx, hour)
+	if err != nil {
+		return fmt.Errorf("failed to count active users: %w", err)
+	}
+
+	for metric, counts := range map[metering.Metric][]orgCount{
+		metering.MetricDashboards:  dashboards,
+		metering.MetricActiveUsers: activeUsers,
+	} {
+		for _, count := range counts {
+			key := usageKey{orgID: count.OrgID, periodStart: hour.Unix(), metric: metric}
+			if err := s.store.set(ctx, key, count.Count); err != nil {
+				return fmt.Errorf("failed to store %s usage of org %d: %w", metric, count.OrgID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteExpiredRecords(ctx context.Context) error {
+	deleted, err := s.store.deleteBefore(ctx, s.now().Add(-s.settings.Retention))
+	if err != nil {
+		return fmt.Errorf("failed to delete expired usage records: %w", err)
+	}
+	s.log.Debug("Deleted expired usage records", "count", deleted)
+	return nil
+}
+
+// hour returns the start of the hour of a time, in UTC.
+func (s *Service) hour(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Hour)
+}
@@ -0,0 +1,285 @@
+package meteringimpl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/metering"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func newTestService(t *testing.T, s store, now *time.Time) *Service {
+	t.Helper()
+	return &Service{
+		store:       s,
+		kv:          kvstore.WithNamespace(kvstore.NewFakeKVStore(), 0, "metering"),
+		settings:    setting.MeteringSettings{Enabled: true, FlushInterval: time.Minute, Retention: 24 * time.Hour},
+		metrics:     newMetrics(nil),
+		log:         log.NewNopLogger(),
+		now:         func() time.Time { return *now },
+		client:      &http.Client{},
+		pending:     map[usageKey]int64{},
+		evaluations: map[string]float64{},
+	}
+}
+
+func TestFlush(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	fake := newFakeStore()
+	s := newTestService(t, fake, &now)
+	s.evalTotal = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "evaluations"}, []string{"org"})
+
+	s.Record(1, metering.MetricQueries, 3)
+	s.Record(1, metering.MetricQueries, 2)
+	s.Record(2, metering.MetricRenderedImages, 1)
+	s.Record(0, metering.MetricQueries, 1)
+	s.evalTotal.WithLabelValues("1").Add(4)
+
+	require.NoError(t, s.flush(context.Background()))
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Unix()
+	require.Equal(t, map[usageKey]int64{
+		{orgID: 1, periodStart: hour, metric: metering.MetricQueries}:          5,
+		{orgID: 2, periodStart: hour, metric: metering.MetricRenderedImages}:   1,
+		{orgID: 1, periodStart: hour, metric: metering.MetricAlertEvaluations}: 4,
+	}, fake.values)
+
+	t.Run("the events are added to the records of their hour", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		s.Record(1, metering.MetricQueries, 1)
+		s.evalTotal.WithLabelValues("1").Add(2)
+		require.NoError(t, s.flush(context.Background()))
+
+		next := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC).Unix()
+		require.Equal(t, int64(5), fake.values[usageKey{orgID: 1, periodStart: hour, metric: metering.MetricQueries}])
+		require.Equal(t, int64(1), fake.values[usageKey{orgID: 1, periodStart: next, metric: metering.MetricQueries}])
+		require.Equal(t, int64(2), fake.values[usageKey{orgID: 1, periodStart: next, metric: metering.MetricAlertEvaluations}],
+			"the increase of the evaluation counter is recorded")
+	})
+
+	t.Run("the events which failed to be stored are kept for the next flush", func(t *testing.T) {
+		s.Record(3, metering.MetricQueries, 7)
+		fake.err = errors.New("database is locked")
+		require.Error(t, s.flush(context.Background()))
+
+		fake.err = nil
+		require.NoError(t, s.flush(context.Background()))
+		require.Equal(t, int64(7), fake.values[usageKey{orgID: 3, periodStart: s.hour(now).Unix(), metric: metering.MetricQueries}])
+	})
+
+	t.Run("nothing is recorded when metering is disabled", func(t *testing.T) {
+		disabled := newTestService(t, newFakeStore(), &now)
+		disabled.settings.Enabled = false
+		disabled.Record(1, metering.MetricQueries, 1)
+		require.Empty(t, disabled.pending)
+	})
+}
+
+func TestCountResources(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	fake := newFakeStore()
+	fake.dashboards = []orgCount{{OrgID: 1, Count: 10}, {OrgID: 2, Count: 3}}
+	fake.activeUsers = []orgCount{{OrgID: 1, Count: 4}}
+	s := newTestService(t, fake, &now)
+
+	require.NoError(t, s.countResources(context.Background()))
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.Equal(t, hour, fake.activeSince, "the users seen since the start of the hour are counted")
+	require.Equal(t, map[usageKey]int64{
+		{orgID: 1, periodStart: hour.Unix(), metric: metering.MetricDashboards}:  10,
+		{orgID: 2, periodStart: hour.Unix(), metric: metering.MetricDashboards}:  3,
+		{orgID: 1, periodStart: hour.Unix(), metric: metering.MetricActiveUsers}: 4,
+	}, fake.values)
+
+	fake.dashboards = []orgCount{{OrgID: 1, Count: 12}}
+	require.NoError(t, s.countResources(context.Background()))
+	require.Equal(t, int64(12), fake.values[usageKey{orgID: 1, periodStart: hour.Unix(), metric: metering.MetricDashboards}],
+		"the last count of the hour is kept")
+}
+
+func TestEncode(t *testing.T) {
+	records := []*metering.Record{
+		{OrgID: 1, Hour: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Metric: metering.MetricQueries, Value: 5},
+		{OrgID: 2, Hour: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Metric: metering.MetricDashboards, Value: 3},
+	}
+
+	body, contentType, err := encode(records, metering.FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, "text/csv", contentType)
+	require.Equal(t, "org_id,hour,metric,value\n1,2024-05-01T10:00:00Z,queries,5\n2,2024-05-01T10:00:00Z,dashboards,3\n", string(body))
+
+	body, contentType, err = encode(records, metering.FormatJSON)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", contentType)
+	require.JSONEq(t, `[
+		{"orgId": 1, "hour": "2024-05-01T10:00:00Z", "metric": "queries", "value": 5},
+		{"orgId": 2, "hour": "2024-05-01T10:00:00Z", "metric": "dashboards", "value": 3}
+	]`, string(body))
+
+	_, _, err = encode(records, "xml")
+	require.ErrorIs(t, err, metering.ErrInvalidFormat)
+}
+
+func TestPushPreviousHour(t *testing.T) {
+	now := time.Date(2024, 5, 1, 11, 15, 0, 0, time.UTC)
+	fake := newFakeStore()
+	previous := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Unix()
+	fake.values[usageKey{orgID: 1, periodStart: previous, metric: metering.MetricQueries}] = 5
+	fake.values[usageKey{orgID: 1, periodStart: now.Truncate(time.Hour).Unix(), metric: metering.MetricQueries}] = 2
+
+	var received []string
+	var authorization string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			received = append(received, string(body))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := newTestService(t, fake, &now)
+	s.settings.WebhookURL = server.URL
+	s.settings.WebhookFormat = "csv"
+	s.settings.WebhookAuthorizationHeader = "Bearer token"
+
+	require.NoError(t, s.pushPreviousHour(context.Background()))
+	require.Equal(t, []string{"org_id,hour,metric,value\n1,2024-05-01T10:00:00Z,queries,5\n"}, received, "only the previous hour is pushed")
+	require.Equal(t, "Bearer token", authorization)
+
+	t.Run("an hour is not pushed twice", func(t *testing.T) {
+		received = nil
+		require.NoError(t, s.pushPreviousHour(context.Background()))
+		require.Empty(t, received)
+	})
+
+	t.Run("the hours which failed to be pushed are caught up", func(t *testing.T) {
+		received = nil
+		now = now.Add(time.Hour)
+		status = http.StatusServiceUnavailable
+		require.ErrorContains(t, s.pushPreviousHour(context.Background()), "unexpected status code 503")
+
+		now = now.Add(time.Hour)
+		status = http.StatusOK
+		require.NoError(t, s.pushPreviousHour(context.Background()))
+		require.Equal(t, []string{
+			"org_id,hour,metric,value\n1,2024-05-01T11:00:00Z,queries,2\n",
+			"org_id,hour,metric,value\n",
+		}, received)
+	})
+
+	t.Run("the hours older than the retention are not caught up", func(t *testing.T) {
+		received = nil
+		now = now.Add(48 * time.Hour)
+		require.NoError(t, s.pushPreviousHour(context.Background()))
+		require.Len(t, received, 24)
+	})
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ss := &dbStore{db: db.InitTestDB(t)}
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	key := usageKey{orgID: 1, periodStart: hour.Unix(), metric: metering.MetricQueries}
+
+	require.NoError(t, ss.add(ctx, key, 3))
+	require.NoError(t, ss.add(ctx, key, 2))
+	dashboards := usageKey{orgID: 2, periodStart: hour.Unix(), metric: metering.MetricDashboards}
+	require.NoError(t, ss.set(ctx, dashboards, 10))
+	require.NoError(t, ss.set(ctx, dashboards, 12))
+	old := usageKey{orgID: 1, periodStart: hour.Add(-48 * time.Hour).Unix(), metric: metering.MetricQueries}
+	require.NoError(t, ss.add(ctx, old, 1))
+
+	records, err := ss.getRecords(ctx, &metering.GetRecordsQuery{From: hour, To: hour.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, []*metering.Record{
+		{OrgID: 1, Hour: hour, Metric: metering.MetricQueries, Value: 5},
+		{OrgID: 2, Hour: hour, Metric: metering.MetricDashboards, Value: 12},
+	}, records)
+
+	records, err = ss.getRecords(ctx, &metering.GetRecordsQuery{OrgID: 2, From: hour, To: hour.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	deleted, err := ss.deleteBefore(ctx, hour.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
+
+type fakeStore struct {
+	values      map[usageKey]int64
+	dashboards  []orgCount
+	activeUsers []orgCount
+	activeSince time.Time
+	err         error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[usageKey]int64{}}
+}
+
+func (f *fakeStore) add(_ context.Context, key usageKey, delta int64) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.values[key] += delta
+	return nil
+}
+
+func (f *fakeStore) set(_ context.Context, key usageKey, value int64) error {
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeStore) countDashboards(context.Context) ([]orgCount, error) {
+	return f.dashboards, nil
+}
+
+func (f *fakeStore) countActiveUsers(_ context.Context, since time.Time) ([]orgCount, error) {
+	f.activeSince = since
+	return f.activeUsers, nil
+}
+
+func (f *fakeStore) getRecords(_ context.Context, query *metering.GetRecordsQuery) ([]*metering.Record, error) {
+	records := make([]*metering.Record, 0)
+	for key, value := range f.values {
+		if key.periodStart >= query.From.Unix() && key.periodStart < query.To.Unix() {
+			records = append(records, &metering.Record{OrgID: key.orgID, Hour: time.Unix(key.periodStart, 0).UTC(), Metric: key.metric, Value: value})
+		}
+	}
+	return records, nil
+}
+
+func (f *fakeStore) deleteBefore(_ context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for key := range f.values {
+		if key.periodStart < before.Unix() {
+			delete(f.values, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
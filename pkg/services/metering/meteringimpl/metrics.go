@@ -0,0 +1,28 @@
+package meteringimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	flushes *prometheus.CounterVec
+	pushes  *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		flushes: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "metering",
+			Name:      "flushes_total",
+			Help:      "Number of flushes of the usage events to the usage records by result.",
+		}, []string{"result"}),
+		pushes: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "metering",
+			Name:      "pushes_total",
+			Help:      "Number of pushes of the usage records to the webhook by result.",
+		}, []string{"result"}),
+	}
+}
@@ -0,0 +1,154 @@
+package meteringimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/metering"
+)
+
+// usage is the stored usage of an organization for a metric during an hour.
+type usage struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// PeriodStart is the start of the hour, in epoch seconds.
+	PeriodStart int64  `xorm:"period_start"`
+	Metric      string `xorm:"metric"`
+	Value       int64  `xorm:"value"`
+}
+
+func (usage) TableName() string {
+	return "org_usage"
+}
+
+// orgCount is the result of the queries counting the resources of each organization.
+type orgCount struct {
+	OrgID int64 `xorm:"org_id"`
+	Count int64 `xorm:"count"`
+}
+
+type store interface {
+	add(ctx context.Context, key usageKey, delta int64) error
+	set(ctx context.Context, key usageKey, value int64) error
+	countDashboards(ctx context.Context) ([]orgCount, error)
+	countActiveUsers(ctx context.Context, since time.Time) ([]orgCount, error)
+	getRecords(ctx context.Context, query *metering.GetRecordsQuery) ([]*metering.Record, error)
+	deleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type dbStore struct {
+	db db.DB
+}
+
+// add adds a delta to the usage of an hour, which is summed over the instances sharing the database.
+func (ss *dbStore) add(ctx context.Context, key usageKey, delta int64) error {
+	return ss.retryOnConflict(func() error { return ss.addOnce(ctx, key, delta) })
+}
+
+func (ss *dbStore) addOnce(ctx context.Context, key usageKey, delta int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE org_usage SET value = value + ? WHERE org_id = ? AND period_start = ? AND metric = ?",
+			delta, key.orgID, key.periodStart, string(key.metric))
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+			return err
+		}
+		_, err = sess.Insert(&usage{OrgID: key.orgID, PeriodStart: key.periodStart, Metric: string(key.metric), Value: delta})
+		return err
+	})
+}
+
+// set sets the usage of an hour to the last value counted.
+func (ss *dbStore) set(ctx context.Context, key usageKey, value int64) error {
+	return ss.retryOnConflict(func() error { return ss.setOnce(ctx, key, value) })
+}
+
+func (ss *dbStore) setOnce(ctx context.Context, key usageKey, value int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &usage{}
+		exists, err := sess.Where("org_id = ? AND period_start = ? AND metric = ?", key.orgID, key.periodStart, string(key.metric)).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = sess.Insert(&usage{OrgID: key.orgID, PeriodStart: key.periodStart, Metric: string(key.metric), Value: value})
+			return err
+		}
+		_, err = sess.Exec("UPDATE org_usage SET value = ? WHERE id = ?", value, existing.ID)
+		return err
+	})
+}
+
+// retryOnConflict retries a write once when another instance inserted the same record meanwhile.
+func (ss *dbStore) retryOnConflict(write func() error) error {
+	err := write()
+	if err != nil && ss.db.GetDialect().IsUniqueConstraintViolation(err) {
+		err = write()
+	}
+	return err
+}
+
+// countDashboards counts the dashboards of each organization, the deleted dashboards kept for restore are left out.
+func (ss *dbStore) countDashboards(ctx context.Context) ([]orgCount, error) {
+	counts := make([]orgCount, 0)
+	err := ss.db.WithReadOnlyDbSession(ctx, func(sess *db.Session) error {
+		dialect := ss.db.GetDialect()
+		return sess.SQL(`SELECT org_id, COUNT(id) AS count FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ? AND deleted IS NULL GROUP BY org_id`,
+			dialect.BooleanStr(false)).Find(&counts)
+	})
+	return counts, err
+}
+
+// countActiveUsers counts the members of each organization seen since a time, the service accounts are left out.
+func (ss *dbStore) countActiveUsers(ctx context.Context, since time.Time) ([]orgCount, error) {
+	counts := make([]orgCount, 0)
+	err := ss.db.WithReadOnlyDbSession(ctx, func(sess *db.Session) error {
+		dialect := ss.db.GetDialect()
+		return sess.SQL(`SELECT org_user.org_id AS org_id, COUNT(u.id) AS count FROM org_user
+			INNER JOIN `+dialect.Quote("user")+` AS u ON u.id = org_user.user_id
+			WHERE u.is_service_account = ? AND u.last_seen_at >= ? GROUP BY org_user.org_id`,
+			dialect.BooleanStr(false), since).Find(&counts)
+	})
+	return counts, err
+}
+
+func (ss *dbStore) getRecords(ctx context.Context, query *metering.GetRecordsQuery) ([]*metering.Record, error) {
+	rows := make([]*usage, 0)
+	err := ss.db.WithReadOnlyDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("period_start >= ? AND period_start < ?", query.From.Unix(), query.To.Unix())
+		if query.OrgID > 0 {
+			sess.And("org_id = ?", query.OrgID)
+		}
+		return sess.Asc("period_start").Asc("org_id").Asc("metric").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*metering.Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, &metering.Record{
+			OrgID:  row.OrgID,
+			Hour:   time.Unix(row.PeriodStart, 0).UTC(),
+			Metric: metering.Metric(row.Metric),
+			Value:  row.Value,
+		})
+	}
+	return records, nil
+}
+
+func (ss *dbStore) deleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM org_usage WHERE period_start < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
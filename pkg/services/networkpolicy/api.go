@@ -0,0 +1,50 @@
+package networkpolicy
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	routeRegister.Group("/api/network-policies", func(policies routing.RouteRegister) {
+		policies.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.listPoliciesHandler))
+		policies.Put("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.setPolicyHandler))
+	})
+}
+
+func (s *Service) listPoliciesHandler(c *contextmodel.ReqContext) response.Response {
+	policies, err := s.listPolicies(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list network policies", err)
+	}
+	return response.JSON(http.StatusOK, policies)
+}
+
+func (s *Service) setPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := SetPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	// Refuse the org policies which would block the admin setting them, who couldn't undo it.
+	if cmd.Scope == ScopeOrg && len(cmd.CIDRs) > 0 {
+		cidrs, err := web.ParseCIDRs(cmd.CIDRs)
+		if err != nil {
+			return response.Err(ErrInvalidPolicy.Errorf("%w", err))
+		}
+		if addr := s.clientIP(c.Req); !contains(cidrs, addr) {
+			return response.Err(ErrLockout.Errorf("address %s is not in the policy", addr))
+		}
+	}
+
+	if err := s.SetPolicy(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set network policy", err)
+	}
+	return response.Success("Network policy updated")
+}
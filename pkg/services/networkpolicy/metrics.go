@@ -0,0 +1,39 @@
+package networkpolicy
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "network_policy"
+)
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		blockedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "blocked_requests_total",
+			Help:      "Number of requests blocked by a network policy",
+		}, []string{"scope"}),
+		failedEvaluations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "failed_evaluations_total",
+			Help:      "Number of requests rejected because their network policies couldn't be loaded",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.blockedRequests)
+		reg.MustRegister(m.failedEvaluations)
+	}
+
+	return m
+}
+
+type metrics struct {
+	blockedRequests   *prometheus.CounterVec
+	failedEvaluations prometheus.Counter
+}
@@ -0,0 +1,65 @@
+package networkpolicy
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+// Scope is what a network policy applies to.
+type Scope string
+
+const (
+	// ScopeOrg applies to every request to the organization.
+	ScopeOrg Scope = "org"
+	// ScopeServiceAccount applies to the requests authenticated with any token of the service account.
+	ScopeServiceAccount Scope = "service_account"
+	// ScopeAPIKey applies to the requests authenticated with the API key or service account token.
+	ScopeAPIKey Scope = "api_key"
+)
+
+func (s Scope) IsValid() bool {
+	return s == ScopeOrg || s == ScopeServiceAccount || s == ScopeAPIKey
+}
+
+var (
+	ErrAddressNotAllowed = errutil.Forbidden("network-policy.address-not-allowed").MustTemplate(
+		"address {{ .Public.remoteAddr }} is not allowed by the {{ .Public.scope }} network policy",
+		errutil.WithPublic("Requests from {{ .Public.remoteAddr }} are not allowed by the network policy of the {{ .Public.scope }}"),
+	)
+	ErrInvalidPolicy = errutil.BadRequest("network-policy.invalid")
+	ErrLockout       = errutil.BadRequest("network-policy.lockout", errutil.WithPublicMessage("The policy would block the address of this request"))
+)
+
+// Policy is the list of the CIDRs a scope of an organization can be requested from.
+type Policy struct {
+	OrgID   int64     `json:"orgId"`
+	Scope   Scope     `json:"scope"`
+	ScopeID int64     `json:"scopeId"`
+	CIDRs   []string  `json:"cidrs"`
+	Updated time.Time `json:"updated"`
+}
+
+// SetPolicyCommand is the body of the API setting a policy of the organization of the request.
+type SetPolicyCommand struct {
+	Scope Scope `json:"scope"`
+	// ScopeID is the id of the service account or API key, 0 for the organization.
+	ScopeID int64 `json:"scopeId"`
+	// CIDRs the scope can be requested from. The IP addresses are read as single host CIDRs.
+	// An empty list removes the policy.
+	CIDRs []string `json:"cidrs"`
+}
+
+type policyRow struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	Scope   string `xorm:"scope"`
+	ScopeID int64  `xorm:"scope_id"`
+	// CIDRs is the list of the CIDRs, as JSON.
+	CIDRs   string    `xorm:"cidrs"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (policyRow) TableName() string {
+	return "network_policy"
+}
@@ -0,0 +1,196 @@
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// hookPriority runs the check once the user is fetched, which sets the organization of the request,
+	// and before the permissions of the identity are synced.
+	hookPriority = 105
+	cacheTTL     = 30 * time.Second
+)
+
+// Service enforces the network policies of the organizations: the requests to an organization, or
+// authenticated with a service account or an API key which has a policy, are rejected unless their
+// client address is in the CIDRs of every policy which applies to them.
+type Service struct {
+	store          db.DB
+	cache          *localcache.CacheService
+	trustedProxies []*net.IPNet
+	metrics        *metrics
+	log            log.Logger
+	now            func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, authnService authn.Service,
+	accessControl ac.AccessControl, accesscontrolService ac.Service, reg prometheus.Registerer) (*Service, error) {
+	trustedProxies, err := web.ParseCIDRs(cfg.NetworkPolicy.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted_proxies of [network_policy]: %w", err)
+	}
+
+	s := &Service{
+		store:          sqlStore,
+		cache:          localcache.New(cacheTTL, 2*cacheTTL),
+		trustedProxies: trustedProxies,
+		metrics:        newMetrics(reg),
+		log:            log.New("network-policy"),
+		now:            time.Now,
+	}
+
+	if cfg.NetworkPolicy.Enabled {
+		if err := declareFixedRoles(accesscontrolService); err != nil {
+			return nil, err
+		}
+		authnService.RegisterPostAuthHook(s.enforceHook, hookPriority)
+		s.registerAPIEndpoints(routeRegister, accessControl)
+	}
+	return s, nil
+}
+
+func (s *Service) enforceHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	// The renderer requests Grafana on behalf of the users, from wherever it runs.
+	if r.HTTPRequest == nil || id.OrgID < 1 || id.IsAuthenticatedBy(login.RenderModule) {
+		return nil
+	}
+
+	policies, err := s.getPolicies(ctx, id.OrgID)
+	if err != nil {
+		s.metrics.failedEvaluations.Inc()
+		return fmt.Errorf("failed to get network policies of org %d: %w", id.OrgID, err)
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	addr := s.clientIP(r.HTTPRequest)
+	for _, key := range applicableKeys(id, r) {
+		cidrs, ok := policies[key]
+		if !ok || contains(cidrs, addr) {
+			continue
+		}
+
+		s.metrics.blockedRequests.WithLabelValues(string(key.scope)).Inc()
+		s.log.FromContext(ctx).Warn("Request blocked by network policy", "orgId", id.OrgID, "scope", key.scope,
+			"scopeId", key.scopeID, "id", id.GetID(), "remoteAddr", addr.String())
+		return ErrAddressNotAllowed.Build(errutil.TemplateData{
+			Public: map[string]any{"remoteAddr": addr.String(), "scope": scopeName(key.scope)},
+		})
+	}
+	return nil
+}
+
+type policyKey struct {
+	scope   Scope
+	scopeID int64
+}
+
+// applicableKeys returns the keys of the policies which apply to the identity, the organization first.
+func applicableKeys(id *authn.Identity, r *authn.Request) []policyKey {
+	keys := []policyKey{{scope: ScopeOrg}}
+	if id.IsIdentityType(claims.TypeServiceAccount) {
+		if saID, err := strconv.ParseInt(id.ID, 10, 64); err == nil {
+			keys = append(keys, policyKey{scope: ScopeServiceAccount, scopeID: saID})
+		}
+	}
+	if keyID, err := strconv.ParseInt(r.GetMeta(authn.MetaKeyAPIKeyID), 10, 64); err == nil {
+		keys = append(keys, policyKey{scope: ScopeAPIKey, scopeID: keyID})
+	}
+	return keys
+}
+
+func scopeName(scope Scope) string {
+	switch scope {
+	case ScopeServiceAccount:
+		return "service account"
+	case ScopeAPIKey:
+		return "API key"
+	default:
+		return "organization"
+	}
+}
+
+// getPolicies returns the parsed policies of the organization. They are cached on this instance for a short
+// while, so that the changes made on another instance are enforced everywhere within the TTL of the cache.
+func (s *Service) getPolicies(ctx context.Context, orgID int64) (map[policyKey][]*net.IPNet, error) {
+	cacheKey := fmt.Sprintf("network-policy-%d", orgID)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		return cached.(map[policyKey][]*net.IPNet), nil
+	}
+
+	policies, err := s.listPolicies(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(map[policyKey][]*net.IPNet, len(policies))
+	for _, p := range policies {
+		cidrs, err := web.ParseCIDRs(p.CIDRs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s policy %d: %w", p.Scope, p.ScopeID, err)
+		}
+		parsed[policyKey{scope: p.Scope, scopeID: p.ScopeID}] = cidrs
+	}
+	s.cache.SetDefault(cacheKey, parsed)
+	return parsed, nil
+}
+
+// SetPolicy replaces the policy of the scope of the organization, or removes it when the command has no CIDRs.
+func (s *Service) SetPolicy(ctx context.Context, orgID int64, cmd SetPolicyCommand) error {
+	if !cmd.Scope.IsValid() {
+		return ErrInvalidPolicy.Errorf("unknown scope %q", cmd.Scope)
+	}
+	if cmd.Scope == ScopeOrg && cmd.ScopeID != 0 {
+		return ErrInvalidPolicy.Errorf("the scopeId of the org policy must be 0")
+	}
+	if cmd.Scope != ScopeOrg && cmd.ScopeID < 1 {
+		return ErrInvalidPolicy.Errorf("missing scopeId of the %s policy", cmd.Scope)
+	}
+	if _, err := web.ParseCIDRs(cmd.CIDRs); err != nil {
+		return ErrInvalidPolicy.Errorf("%w", err)
+	}
+
+	if err := s.savePolicy(ctx, orgID, cmd); err != nil {
+		return err
+	}
+	s.cache.Delete(fmt.Sprintf("network-policy-%d", orgID))
+	return nil
+}
+
+// clientIP returns the address of the client of the request. The forwarded headers are only read when
+// the request comes from a trusted proxy.
+func (s *Service) clientIP(r *http.Request) net.IP {
+	return web.TrustedRemoteAddr(r, s.trustedProxies)
+}
+
+func contains(cidrs []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
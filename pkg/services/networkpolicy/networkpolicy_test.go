@@ -0,0 +1,112 @@
+package networkpolicy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := web.ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	s := &Service{trustedProxies: trusted}
+
+	request := func(remoteAddr, forwardedFor string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/search", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		return r
+	}
+
+	assert.Equal(t, "203.0.113.5", s.clientIP(request("203.0.113.5:1234", "")).String())
+	assert.Equal(t, "198.51.100.7", s.clientIP(request("10.1.2.3:1234", "198.51.100.7, 10.1.2.3")).String())
+	assert.Equal(t, "203.0.113.5", s.clientIP(request("203.0.113.5:1234", "198.51.100.7")).String(), "the headers of the untrusted peers are ignored")
+	assert.Equal(t, "198.51.100.7", s.clientIP(request("10.1.2.3:1234", "192.0.2.1, 198.51.100.7")).String(), "the entries set by the client are ignored")
+}
+
+func TestIntegrationEnforceHook(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := &Service{
+		store:   db.InitTestDB(t),
+		cache:   localcache.New(cacheTTL, 2*cacheTTL),
+		metrics: newMetrics(nil),
+		log:     log.NewNopLogger(),
+		now:     time.Now,
+	}
+	ctx := context.Background()
+
+	request := func(remoteAddr string, apiKeyID string) *authn.Request {
+		r := &authn.Request{HTTPRequest: httptest.NewRequest(http.MethodGet, "/api/search", nil)}
+		r.HTTPRequest.RemoteAddr = remoteAddr + ":1234"
+		if apiKeyID != "" {
+			r.SetMeta(authn.MetaKeyAPIKeyID, apiKeyID)
+		}
+		return r
+	}
+	usr := &authn.Identity{ID: "2", Type: claims.TypeUser, OrgID: 1}
+	sa := &authn.Identity{ID: "3", Type: claims.TypeServiceAccount, OrgID: 1}
+
+	t.Run("should allow every address when the org has no policy", func(t *testing.T) {
+		require.NoError(t, s.enforceHook(ctx, usr, request("203.0.113.5", "")))
+	})
+
+	require.NoError(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: ScopeOrg, CIDRs: []string{"10.0.0.0/8", "203.0.113.0/24"}}))
+	require.NoError(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: ScopeServiceAccount, ScopeID: 3, CIDRs: []string{"10.0.0.0/8"}}))
+	require.NoError(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: ScopeAPIKey, ScopeID: 7, CIDRs: []string{"10.1.0.0/16"}}))
+
+	t.Run("should enforce the org policy", func(t *testing.T) {
+		require.NoError(t, s.enforceHook(ctx, usr, request("203.0.113.5", "")))
+
+		err := s.enforceHook(ctx, usr, request("198.51.100.7", ""))
+		require.ErrorIs(t, err, ErrAddressNotAllowed)
+
+		require.NoError(t, s.enforceHook(ctx, &authn.Identity{ID: "2", Type: claims.TypeUser, OrgID: 2}, request("198.51.100.7", "")),
+			"the other orgs are not restricted")
+	})
+
+	t.Run("should enforce the service account and API key policies on top of the org policy", func(t *testing.T) {
+		require.NoError(t, s.enforceHook(ctx, sa, request("10.2.0.1", "")))
+		require.ErrorIs(t, s.enforceHook(ctx, sa, request("203.0.113.5", "")), ErrAddressNotAllowed)
+
+		require.NoError(t, s.enforceHook(ctx, sa, request("10.1.0.1", "7")))
+		require.ErrorIs(t, s.enforceHook(ctx, sa, request("10.2.0.1", "7")), ErrAddressNotAllowed)
+		require.NoError(t, s.enforceHook(ctx, sa, request("10.2.0.1", "8")))
+	})
+
+	t.Run("should remove the policy without CIDRs", func(t *testing.T) {
+		require.NoError(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: ScopeServiceAccount, ScopeID: 3}))
+		require.NoError(t, s.enforceHook(ctx, sa, request("203.0.113.5", "")))
+
+		policies, err := s.listPolicies(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, policies, 2)
+	})
+
+	t.Run("should reject the invalid policies", func(t *testing.T) {
+		require.ErrorIs(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: "team", ScopeID: 1, CIDRs: []string{"10.0.0.0/8"}}), ErrInvalidPolicy)
+		require.ErrorIs(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: ScopeAPIKey, CIDRs: []string{"10.0.0.0/8"}}), ErrInvalidPolicy)
+		require.ErrorIs(t, s.SetPolicy(ctx, 1, SetPolicyCommand{Scope: ScopeOrg, CIDRs: []string{"10.0.0.0/33"}}), ErrInvalidPolicy)
+	})
+}
@@ -0,0 +1,41 @@
+package networkpolicy
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionRead  = "networkpolicies:read"
+	ActionWrite = "networkpolicies:write"
+)
+
+var (
+	policyReaderRole = ac.RoleDTO{
+		Name:        "fixed:networkpolicies:reader",
+		DisplayName: "Network policy reader",
+		Description: "List the network policies of the organization",
+		Group:       "Network policies",
+		Permissions: []ac.Permission{
+			{Action: ActionRead},
+		},
+	}
+
+	policyWriterRole = ac.RoleDTO{
+		Name:        "fixed:networkpolicies:writer",
+		DisplayName: "Network policy writer",
+		Description: "List, set and remove the network policies of the organization",
+		Group:       "Network policies",
+		Permissions: []ac.Permission{
+			{Action: ActionRead},
+			{Action: ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(service ac.Service) error {
+	return service.DeclareFixedRoles(
+		ac.RoleRegistration{Role: policyReaderRole, Grants: []string{string(org.RoleAdmin)}},
+		ac.RoleRegistration{Role: policyWriterRole, Grants: []string{string(org.RoleAdmin)}},
+	)
+}
@@ -0,0 +1,69 @@
+package networkpolicy
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) listPolicies(ctx context.Context, orgID int64) ([]Policy, error) {
+	var rows []policyRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("scope", "scope_id").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rows))
+	for _, row := range rows {
+		var cidrs []string
+		if err := json.Unmarshal([]byte(row.CIDRs), &cidrs); err != nil {
+			return nil, err
+		}
+		policies = append(policies, Policy{
+			OrgID:   row.OrgID,
+			Scope:   Scope(row.Scope),
+			ScopeID: row.ScopeID,
+			CIDRs:   cidrs,
+			Updated: row.Updated,
+		})
+	}
+	return policies, nil
+}
+
+func (s *Service) savePolicy(ctx context.Context, orgID int64, cmd SetPolicyCommand) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &policyRow{}
+		exists, err := sess.Where("org_id = ? AND scope = ? AND scope_id = ?", orgID, string(cmd.Scope), cmd.ScopeID).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if len(cmd.CIDRs) == 0 {
+			if exists {
+				_, err = sess.ID(existing.ID).Delete(&policyRow{})
+			}
+			return err
+		}
+
+		rawCIDRs, err := json.Marshal(cmd.CIDRs)
+		if err != nil {
+			return err
+		}
+		row := &policyRow{
+			OrgID:   orgID,
+			Scope:   string(cmd.Scope),
+			ScopeID: cmd.ScopeID,
+			CIDRs:   string(rawCIDRs),
+			Updated: s.now(),
+		}
+		if !exists {
+			_, err = sess.Insert(row)
+			return err
+		}
+		_, err = sess.ID(existing.ID).AllCols().Update(row)
+		return err
+	})
+}
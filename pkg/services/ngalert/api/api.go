@@ -58,6 +58,9 @@ type API struct {
 	TransactionManager   provisioning.TransactionManager
 	ProvenanceStore      provisioning.ProvisioningStore
 	RuleStore            RuleStore
+	RuleVersions         RuleVersionStore
+	Scheduler            RuleScheduler
+	RuleRoutines         RuleRoutineManager
 	AlertingStore        store.AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
@@ -75,6 +78,9 @@ type API struct {
 	ConditionValidator   *eval.ConditionValidator
 	FeatureManager       featuremgmt.FeatureToggles
 	Historian            Historian
+	MaintenanceWindows   MaintenanceWindowStore
+	SilenceSchedules     SilenceScheduleStore
+	RecurringSilences    RecurringSilenceMaterializer
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
 
@@ -117,23 +123,27 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, authz: ruleAuthzService},
 	), m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
+	rulerSrv := RulerSrv{
+		conditionValidator: api.ConditionValidator,
+		QuotaService:       api.QuotaService,
+		store:              api.RuleStore,
+		provenanceStore:    api.ProvenanceStore,
+		xactManager:        api.TransactionManager,
+		log:                logger,
+		cfg:                &api.Cfg.UnifiedAlerting,
+		authz:              ruleAuthzService,
+		amConfigStore:      api.AlertingStore,
+		amRefresher:        api.MultiOrgAlertmanager,
+		featureManager:     api.FeatureManager,
+		versions:           api.RuleVersions,
+		scheduler:          api.Scheduler,
+	}
 	api.RegisterRulerApiEndpoints(NewForkingRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		&RulerSrv{
-			conditionValidator: api.ConditionValidator,
-			QuotaService:       api.QuotaService,
-			store:              api.RuleStore,
-			provenanceStore:    api.ProvenanceStore,
-			xactManager:        api.TransactionManager,
-			log:                logger,
-			cfg:                &api.Cfg.UnifiedAlerting,
-			authz:              ruleAuthzService,
-			amConfigStore:      api.AlertingStore,
-			amRefresher:        api.MultiOrgAlertmanager,
-			featureManager:     api.FeatureManager,
-		},
+		&rulerSrv,
 	), m)
+	api.RegisterRuleVersionApiEndpoints(rulerSrv, m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
 		&TestingApiSrv{
 			AlertingProxy:   proxy,
@@ -158,7 +168,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		},
 	), m)
 
-	api.RegisterProvisioningApiEndpoints(NewProvisioningApi(&ProvisioningSrv{
+	provisioningSrv := &ProvisioningSrv{
 		log:                 logger,
 		policies:            api.Policies,
 		contactPointService: api.ContactPointService,
@@ -167,7 +177,9 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		alertRules:          api.AlertRules,
 		// XXX: Used to flag recording rules, remove when FT is removed
 		featureManager: api.FeatureManager,
-	}), m)
+	}
+	api.RegisterProvisioningApiEndpoints(NewProvisioningApi(provisioningSrv), m)
+	api.RegisterProvisioningHashApiEndpoints(provisioningSrv, m)
 
 	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
 		logger: logger,
@@ -179,4 +191,42 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		receiverService:   api.ReceiverService,
 		muteTimingService: api.MuteTimings,
 	}), m)
+
+	api.RegisterProvisioningBundleApiEndpoints(&ProvisioningBundleSrv{
+		log:                 logger,
+		policies:            api.Policies,
+		contactPointService: api.ContactPointService,
+		alertRules:          api.AlertRules,
+		namespaces:          api.RuleStore,
+		xact:                api.TransactionManager,
+	}, m)
+
+	api.RegisterMaintenanceWindowApiEndpoints(&MaintenanceWindowSrv{
+		log:   logger,
+		store: api.MaintenanceWindows,
+	}, m)
+
+	api.RegisterRoutingDryRunApiEndpoints(&RoutingDryRunSrv{
+		log:    logger,
+		router: api.MultiOrgAlertmanager,
+	}, m)
+
+	api.RegisterSilenceScheduleApiEndpoints(&SilenceScheduleSrv{
+		log:          logger,
+		store:        api.SilenceSchedules,
+		materializer: api.RecurringSilences,
+	}, m)
+
+	api.RegisterSchedulerAdminApiEndpoints(&SchedulerAdminSrv{
+		log:      logger,
+		routines: api.RuleRoutines,
+	}, m)
+
+	api.RegisterPrometheusConversionApiEndpoints(&PrometheusConversionSrv{
+		log:             logger,
+		cfg:             &api.Cfg.UnifiedAlerting,
+		datasourceCache: api.DatasourceCache,
+		alertRules:      api.AlertRules,
+		provenanceStore: api.ProvenanceStore,
+	}, m)
 }
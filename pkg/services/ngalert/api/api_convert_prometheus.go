@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	errInvalidPrometheusRuleMsg  = "invalid PrometheusRule: {{ .Public.Reason }}"
+	errConvertedGroupConflictMsg = "rule group {{ .Public.Group }} exists and was not converted from a Prometheus rule"
+)
+
+var (
+	errInvalidPrometheusRuleBase = errutil.BadRequest("alerting.convert.invalidPrometheusRule").
+					MustTemplate(errInvalidPrometheusRuleMsg, errutil.WithPublic(errInvalidPrometheusRuleMsg))
+	errConvertedGroupConflictBase = errutil.Conflict("alerting.convert.groupConflict").
+					MustTemplate(errConvertedGroupConflictMsg, errutil.WithPublic(errConvertedGroupConflictMsg))
+)
+
+func errInvalidPrometheusRule(reason error) error {
+	return errInvalidPrometheusRuleBase.Build(errutil.TemplateData{Public: map[string]any{"Reason": reason.Error()}, Error: reason})
+}
+
+func errConvertedGroupConflict(group string) error {
+	return errConvertedGroupConflictBase.Build(errutil.TemplateData{Public: map[string]any{"Group": group}})
+}
+
+// ConvertedRuleGroup is the change the conversion of a PrometheusRule makes to a rule group.
+type ConvertedRuleGroup struct {
+	Name   string       `json:"name"`
+	Action BundleAction `json:"action"`
+}
+
+// PrometheusConversionResult is the changes made by the conversion of a PrometheusRule, or the changes it would make
+// in a dry run, and the differences between the Prometheus rules and the converted rules.
+type PrometheusConversionResult struct {
+	DryRun   bool                 `json:"dryRun"`
+	Groups   []ConvertedRuleGroup `json:"groups"`
+	Warnings []string             `json:"warnings"`
+}
+
+// PrometheusConversionSrv converts the PrometheusRule resources of the Prometheus operator to Grafana-managed rules,
+// which query a Prometheus data source. A folder mirrors a PrometheusRule: the groups of the folder are kept in sync
+// with the groups of the PrometheusRule each time it is converted again.
+type PrometheusConversionSrv struct {
+	log             log.Logger
+	cfg             *setting.UnifiedAlertingSettings
+	datasourceCache datasources.CacheService
+	alertRules      AlertRuleService
+	provenanceStore provisioning.ProvisioningStore
+}
+
+// RoutePostConvertPrometheusRules converts the PrometheusRule in the body to the rule groups of the folderUid folder,
+// querying the datasourceUid data source. The converted rules have the converted provenance, so that they can only be
+// changed by converting the PrometheusRule again.
+func (srv *PrometheusConversionSrv) RoutePostConvertPrometheusRules(c *contextmodel.ReqContext) response.Response {
+	folderUID, datasourceUID := c.Query("folderUid"), c.Query("datasourceUid")
+	if folderUID == "" || datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("folderUid and datasourceUid are required"), "")
+	}
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the PrometheusRule")
+	}
+	var pr prom.PrometheusRule
+	if err := yaml.Unmarshal(body, &pr); err != nil {
+		return response.Err(errInvalidPrometheusRule(err))
+	}
+
+	ctx := c.Req.Context()
+	ds, err := srv.datasourceCache.GetDatasourceByUID(ctx, datasourceUID, c.SignedInUser, false)
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get the data source", err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("data source %s is of type %s, expected %s", ds.UID, ds.Type, datasources.DS_PROMETHEUS), "")
+	}
+
+	converter := prom.Converter{
+		DatasourceUID:   ds.UID,
+		DatasourceType:  ds.Type,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+		BaseInterval:    srv.cfg.BaseInterval,
+	}
+	converted, err := converter.Convert(c.SignedInUser.GetOrgID(), folderUID, pr)
+	if err != nil {
+		return response.Err(errInvalidPrometheusRule(err))
+	}
+
+	plan, err := srv.planConversion(ctx, c.SignedInUser, folderUID, converted.Groups)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to compare the converted rules", err)
+	}
+	result := PrometheusConversionResult{DryRun: c.QueryBool("dryRun"), Groups: plan.changes, Warnings: converted.Warnings}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	if err := srv.applyConversion(ctx, c.SignedInUser, folderUID, plan); err != nil {
+		switch {
+		case errors.Is(err, provisioning.ErrValidation),
+			errors.Is(err, alerting_models.ErrAlertRuleFailedValidation),
+			errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation):
+			return ErrResp(http.StatusBadRequest, err, "")
+		case errors.Is(err, store.ErrOptimisticLock):
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to save the converted rules", err)
+	}
+	srv.log.FromContext(ctx).Info("Converted PrometheusRule", "name", pr.Metadata.Name, "namespace", pr.Metadata.Namespace, "folder", folderUID, "groups", len(converted.Groups), "warnings", len(converted.Warnings))
+	return response.JSON(http.StatusOK, result)
+}
+
+// prometheusConversion is the rule groups of a folder which differ from the converted rule groups.
+type prometheusConversion struct {
+	changes []ConvertedRuleGroup
+	replace []alerting_models.AlertRuleGroup
+	delete  []string
+}
+
+// planConversion compares the converted rule groups to the rule groups of the folder. The converted rules get the UIDs
+// of the converted rules of the folder with the same titles, so that they are updated, or moved if they changed group.
+// The converted rule groups of the folder which are not in the PrometheusRule anymore are deleted. The rule groups of
+// the folder which were not converted are never changed.
+func (srv *PrometheusConversionSrv) planConversion(ctx context.Context, user identity.Requester, folderUID string, groups []alerting_models.AlertRuleGroup) (*prometheusConversion, error) {
+	existing, err := srv.alertRules.GetAlertGroupsWithFolderFullpath(ctx, user, []string{folderUID})
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := srv.provenanceStore.GetProvenances(ctx, user.GetOrgID(), (&alerting_models.AlertRule{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+	isConverted := func(g *alerting_models.AlertRuleGroup) bool {
+		for _, r := range g.Rules {
+			if provenances[r.UID] != alerting_models.ProvenanceConvertedPrometheus {
+				return false
+			}
+		}
+		return true
+	}
+	current := make(map[string]*alerting_models.AlertRuleGroup, len(existing))
+	// the titles of the rules are unique in the folder
+	byTitle := make(map[string]alerting_models.AlertRule)
+	for _, g := range existing {
+		current[g.Title] = g.AlertRuleGroup
+		if !isConverted(g.AlertRuleGroup) {
+			continue
+		}
+		for _, r := range g.Rules {
+			byTitle[r.Title] = r
+		}
+	}
+
+	plan := &prometheusConversion{changes: make([]ConvertedRuleGroup, 0, len(groups))}
+	for _, group := range groups {
+		change := ConvertedRuleGroup{Name: group.Title, Action: BundleActionUnchanged}
+		currentGroup, ok := current[group.Title]
+		delete(current, group.Title)
+		if ok && !isConverted(currentGroup) {
+			return nil, errConvertedGroupConflict(group.Title)
+		}
+
+		changed := !ok || currentGroup.Interval != group.Interval || len(currentGroup.Rules) != len(group.Rules)
+		for i, rule := range group.Rules {
+			currentRule, found := byTitle[rule.Title]
+			if !found {
+				changed = true
+				continue
+			}
+			group.Rules[i].UID = currentRule.UID
+			if currentRule.RuleGroup != group.Title {
+				changed = true
+				continue
+			}
+			equal, err := rulesEqual(currentRule, group.Rules[i])
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || !equal
+		}
+		if !ok {
+			change.Action = BundleActionCreate
+			plan.replace = append(plan.replace, group)
+			plan.changes = append(plan.changes, change)
+			continue
+		}
+		if changed {
+			change.Action = BundleActionUpdate
+			plan.replace = append(plan.replace, group)
+		}
+		plan.changes = append(plan.changes, change)
+	}
+	for title, g := range current {
+		if isConverted(g) {
+			plan.delete = append(plan.delete, title)
+			plan.changes = append(plan.changes, ConvertedRuleGroup{Name: title, Action: BundleActionDelete})
+		}
+	}
+	return plan, nil
+}
+
+// applyConversion saves the converted rule groups before it deletes the stale ones, so that the rules which moved out
+// of a stale group are moved rather than deleted.
+func (srv *PrometheusConversionSrv) applyConversion(ctx context.Context, user identity.Requester, folderUID string, plan *prometheusConversion) error {
+	for _, group := range plan.replace {
+		if err := srv.alertRules.ReplaceRuleGroup(ctx, user, group, alerting_models.ProvenanceConvertedPrometheus); err != nil {
+			return fmt.Errorf("failed to save rule group %q: %w", group.Title, err)
+		}
+	}
+	for _, title := range plan.delete {
+		if err := srv.alertRules.DeleteRuleGroup(ctx, user, folderUID, title, alerting_models.ProvenanceConvertedPrometheus); err != nil {
+			return fmt.Errorf("failed to delete rule group %q: %w", title, err)
+		}
+	}
+	return nil
+}
+
+func (api *API) RegisterPrometheusConversionApiEndpoints(srv *PrometheusConversionSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/convert/prometheus/rules"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/convert/prometheus/rules"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/convert/prometheus/rules",
+				api.Hooks.Wrap(srv.RoutePostConvertPrometheusRules),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type MaintenanceWindowStore interface {
+	ListMaintenanceWindows(ctx context.Context, query models.ListMaintenanceWindowsQuery) ([]*models.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error)
+	SaveMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error
+}
+
+type MaintenanceWindowSrv struct {
+	log   log.Logger
+	store MaintenanceWindowStore
+}
+
+func (srv *MaintenanceWindowSrv) RouteGetMaintenanceWindows(c *contextmodel.ReqContext) response.Response {
+	query := models.ListMaintenanceWindowsQuery{OrgID: c.SignedInUser.GetOrgID()}
+	if c.QueryBool("active") {
+		now := timeNow()
+		query.ActiveAt = &now
+	}
+	windows, err := srv.store.ListMaintenanceWindows(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list maintenance windows", err)
+	}
+	return response.JSON(http.StatusOK, windows)
+}
+
+func (srv *MaintenanceWindowSrv) RouteGetMaintenanceWindow(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	window, err := srv.store.GetMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get maintenance window", err)
+	}
+	return response.JSON(http.StatusOK, window)
+}
+
+func (srv *MaintenanceWindowSrv) RoutePostMaintenanceWindow(c *contextmodel.ReqContext) response.Response {
+	var window models.MaintenanceWindow
+	if err := web.Bind(c.Req, &window); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	window.ID = 0
+	window.OrgID = c.SignedInUser.GetOrgID()
+	window.CreatedBy = c.SignedInUser.GetLogin()
+	return srv.save(c, &window, http.StatusCreated)
+}
+
+func (srv *MaintenanceWindowSrv) RoutePutMaintenanceWindow(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	existing, err := srv.store.GetMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get maintenance window", err)
+	}
+	var window models.MaintenanceWindow
+	if err := web.Bind(c.Req, &window); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	window.ID = existing.ID
+	window.UID = existing.UID
+	window.OrgID = existing.OrgID
+	window.CreatedBy = existing.CreatedBy
+	return srv.save(c, &window, http.StatusOK)
+}
+
+func (srv *MaintenanceWindowSrv) RouteDeleteMaintenanceWindow(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	if err := srv.store.DeleteMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete maintenance window", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "maintenance window deleted"})
+}
+
+func (srv *MaintenanceWindowSrv) save(c *contextmodel.ReqContext, window *models.MaintenanceWindow, status int) response.Response {
+	if err := window.Validate(); err != nil {
+		return response.Err(models.ErrMaintenanceWindowInvalid(err))
+	}
+	if err := srv.store.SaveMaintenanceWindow(c.Req.Context(), window); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to save maintenance window", err)
+	}
+	return response.JSON(status, window)
+}
+
+func (api *API) RegisterMaintenanceWindowApiEndpoints(srv *MaintenanceWindowSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/maintenance-windows"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/maintenance-windows"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/maintenance-windows",
+				api.Hooks.Wrap(srv.RouteGetMaintenanceWindows),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/maintenance-windows/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/maintenance-windows/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/maintenance-windows/{UID}",
+				api.Hooks.Wrap(srv.RouteGetMaintenanceWindow),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/maintenance-windows"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/maintenance-windows"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/maintenance-windows",
+				api.Hooks.Wrap(srv.RoutePostMaintenanceWindow),
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/maintenance-windows/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPut, "/api/v1/maintenance-windows/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/maintenance-windows/{UID}",
+				api.Hooks.Wrap(srv.RoutePutMaintenanceWindow),
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/maintenance-windows/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodDelete, "/api/v1/maintenance-windows/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/maintenance-windows/{UID}",
+				api.Hooks.Wrap(srv.RouteDeleteMaintenanceWindow),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
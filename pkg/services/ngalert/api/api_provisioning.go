@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/resourcehash"
 )
 
 const disableProvenanceHeaderName = "X-Disable-Provenance"
@@ -109,6 +110,20 @@ func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *contextmodel.ReqContext)
 
 func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree definitions.Route) response.Response {
 	provenance := determineProvenance(c)
+	var before resourcehash.State
+	// the policy tree has no version to check, a tree equal to the current one is valid and is a no-op
+	if current, err := srv.policies.GetPolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID()); err == nil {
+		candidate := tree
+		candidate.Provenance = provenance
+		state, noop, err := unchanged(current, candidate)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		if noop {
+			return updateResponse(http.StatusAccepted, util.DynMap{"message": "policies unchanged"}, state, current)
+		}
+		before = state
+	}
 	err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID(), tree, alerting_models.Provenance(provenance))
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
 		return ErrResp(http.StatusNotFound, err, "")
@@ -120,7 +135,11 @@ func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"})
+	updated, err := srv.policies.GetPolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return updateResponse(http.StatusAccepted, util.DynMap{"message": "policies updated"}, before, updated)
 }
 
 func (srv *ProvisioningSrv) RouteResetPolicyTree(c *contextmodel.ReqContext) response.Response {
@@ -178,7 +197,21 @@ func (srv *ProvisioningSrv) RoutePostContactPoint(c *contextmodel.ReqContext, cp
 func (srv *ProvisioningSrv) RoutePutContactPoint(c *contextmodel.ReqContext, cp definitions.EmbeddedContactPoint, UID string) response.Response {
 	cp.UID = UID
 	provenance := determineProvenance(c)
-	err := srv.contactPointService.UpdateContactPoint(c.Req.Context(), c.SignedInUser.GetOrgID(), cp, alerting_models.Provenance(provenance))
+	var before resourcehash.State
+	// the secure settings are redacted in the current contact point, so they are assumed to change when they are set
+	var secureChanged bool
+	current, ok, err := srv.getContactPoint(c.Req.Context(), c.SignedInUser, UID)
+	if err == nil && ok {
+		before, err = newResourceState(current)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		secureChanged = secureSettingsChanged(current, cp)
+		if !secureChanged && current.Provenance == string(provenance) && contactPointsEqual(current, cp) {
+			return updateResponse(http.StatusAccepted, util.DynMap{"message": "contactpoint unchanged"}, before, current)
+		}
+	}
+	err = srv.contactPointService.UpdateContactPoint(c.Req.Context(), c.SignedInUser.GetOrgID(), cp, alerting_models.Provenance(provenance))
 	if errors.Is(err, provisioning.ErrValidation) {
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
@@ -188,7 +221,18 @@ func (srv *ProvisioningSrv) RoutePutContactPoint(c *contextmodel.ReqContext, cp
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "contactpoint updated"})
+	updated, ok, err := srv.getContactPoint(c.Req.Context(), c.SignedInUser, UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get the updated contact point", err)
+	}
+	if !ok {
+		return ErrResp(http.StatusNotFound, provisioning.ErrNotFound, "")
+	}
+	var changed []string
+	if secureChanged {
+		changed = append(changed, "settings")
+	}
+	return updateResponse(http.StatusAccepted, util.DynMap{"message": "contactpoint updated"}, before, updated, changed...)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteContactPoint(c *contextmodel.ReqContext, UID string) response.Response {
@@ -222,11 +266,26 @@ func (srv *ProvisioningSrv) RoutePutTemplate(c *contextmodel.ReqContext, body de
 		Provenance:      determineProvenance(c),
 		ResourceVersion: body.ResourceVersion,
 	}
+	var before resourcehash.State
+	if current, err := srv.templates.GetTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), name); err == nil {
+		// the version is left out of the comparison, so a stale version must fail before a no-op is returned
+		if err := checkVersion("template", name, tmpl.ResourceVersion, current.ResourceVersion); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+		}
+		state, noop, err := unchanged(current, tmpl)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		if noop {
+			return updateResponse(http.StatusAccepted, current, state, current)
+		}
+		before = state
+	}
 	modified, err := srv.templates.UpsertTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), tmpl)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
-	return response.JSON(http.StatusAccepted, modified)
+	return updateResponse(http.StatusAccepted, modified, before, modified)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteTemplate(c *contextmodel.ReqContext, nameOrUid string) response.Response {
@@ -296,11 +355,26 @@ func (srv *ProvisioningSrv) RoutePutMuteTiming(c *contextmodel.ReqContext, mt de
 		mt.UID = name
 	}
 	mt.Provenance = determineProvenance(c)
+	var before resourcehash.State
+	if current, err := srv.muteTimings.GetMuteTiming(c.Req.Context(), name, c.SignedInUser.GetOrgID()); err == nil {
+		// the version is left out of the comparison, so a stale version must fail before a no-op is returned
+		if err := checkVersion("time interval", name, mt.Version, current.Version); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to update mute timing", err)
+		}
+		state, noop, err := unchanged(current, mt)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		if noop {
+			return updateResponse(http.StatusAccepted, current, state, current)
+		}
+		before = state
+	}
 	updated, err := srv.muteTimings.UpdateMuteTiming(c.Req.Context(), mt, c.SignedInUser.GetOrgID())
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update mute timing", err)
 	}
-	return response.JSON(http.StatusAccepted, updated)
+	return updateResponse(http.StatusAccepted, updated, before, updated)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, name string) response.Response {
@@ -385,6 +459,18 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	updated.OrgID = c.SignedInUser.GetOrgID()
 	updated.UID = UID
 	provenance := determineProvenance(c)
+	var before resourcehash.State
+	if current, currentProvenance, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.SignedInUser, UID); err == nil {
+		resp := ProvisionedAlertRuleFromAlertRule(current, currentProvenance)
+		state, noop, err := unchanged(resp, ProvisionedAlertRuleFromAlertRule(updated, alerting_models.Provenance(provenance)))
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		if noop {
+			return updateResponse(http.StatusOK, resp, state, resp)
+		}
+		before = state
+	}
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), c.SignedInUser, updated, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -403,7 +489,7 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	}
 
 	resp := ProvisionedAlertRuleFromAlertRule(updatedAlertRule, alerting_models.Provenance(provenance))
-	return response.JSON(http.StatusOK, resp)
+	return updateResponse(http.StatusOK, resp, before, resp)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID string) response.Response {
@@ -503,6 +589,19 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 		ErrResp(http.StatusBadRequest, err, "")
 	}
 	provenance := determineProvenance(c)
+	var before resourcehash.State
+	if current, err := srv.getRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group); err == nil {
+		candidate := groupModel
+		candidate.Provenance = alerting_models.Provenance(provenance)
+		state, noop, err := unchanged(ruleGroupResource(current), ruleGroupResource(candidate))
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		if noop {
+			return updateResponse(http.StatusOK, ag, state, ruleGroupResource(current))
+		}
+		before = state
+	}
 	err = srv.alertRules.ReplaceRuleGroup(c.Req.Context(), c.SignedInUser, groupModel, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -516,7 +615,15 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
-	return response.JSON(http.StatusOK, ag)
+	updated, err := srv.getRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group)
+	if errors.Is(err, alerting_models.ErrAlertRuleGroupNotFound) {
+		// the group is deleted when it is replaced by a group without rules
+		updated, err = alerting_models.AlertRuleGroup{Title: group, FolderUID: folderUID}, nil
+	}
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get the updated rule group", err)
+	}
+	return updateResponse(http.StatusOK, ag, before, ruleGroupResource(updated))
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string) response.Response {
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const errInvalidBundleMsg = "invalid provisioning bundle: {{ .Public.Reason }}"
+
+var errInvalidBundleBase = errutil.BadRequest("alerting.provisioning.invalidBundle").
+	MustTemplate(errInvalidBundleMsg, errutil.WithPublic(errInvalidBundleMsg))
+
+func errInvalidBundle(reason error) error {
+	return errInvalidBundleBase.Build(errutil.TemplateData{Public: map[string]any{"Reason": reason.Error()}, Error: reason})
+}
+
+type BundleResourceKind string
+
+const (
+	BundleResourceRuleGroup    BundleResourceKind = "rule-group"
+	BundleResourceRule         BundleResourceKind = "rule"
+	BundleResourceContactPoint BundleResourceKind = "contact-point"
+	BundleResourcePolicies     BundleResourceKind = "policies"
+)
+
+type BundleAction string
+
+const (
+	BundleActionCreate    BundleAction = "create"
+	BundleActionUpdate    BundleAction = "update"
+	BundleActionDelete    BundleAction = "delete"
+	BundleActionUnchanged BundleAction = "unchanged"
+)
+
+// BundleChange is the change the import of a bundle makes to a resource.
+type BundleChange struct {
+	Kind BundleResourceKind `json:"kind"`
+	// Name is the folder and name of the rule groups, the title of the rules and the name of the contact points.
+	Name string `json:"name"`
+	// UID is the UID of the rules and of the integrations of the contact points.
+	UID    string       `json:"uid,omitempty"`
+	Action BundleAction `json:"action"`
+}
+
+// BundleImportResult is the changes made by the import of a bundle, or the changes it would make in a dry run.
+type BundleImportResult struct {
+	DryRun  bool           `json:"dryRun"`
+	Changes []BundleChange `json:"changes"`
+}
+
+type NamespaceStore interface {
+	GetUserVisibleNamespaces(context.Context, int64, identity.Requester) (map[string]*folder.Folder, error)
+}
+
+// ProvisioningBundleSrv exports and imports the alerting configuration of an organization as a single file in the
+// provisioning format, to promote it between environments.
+type ProvisioningBundleSrv struct {
+	log                 log.Logger
+	policies            NotificationPolicyService
+	contactPointService ContactPointService
+	alertRules          AlertRuleService
+	namespaces          NamespaceStore
+	xact                provisioning.TransactionManager
+}
+
+// RouteGetBundleExport exports the rule groups, contact points and notification policies of the organization. The
+// rule groups are filtered by the folderUid and matcher query parameters, and a group is exported whole when one of
+// its rules matches. All the resources are filtered by the provenance query parameter.
+func (srv *ProvisioningBundleSrv) RouteGetBundleExport(c *contextmodel.ReqContext) response.Response {
+	provenances, err := getProvenanceFilterFromQuery(c.QueryStrings("provenance"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	matchers, err := getMatchersFromQuery(c.Req.Form)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	groups, err := srv.alertRules.GetAlertGroupsWithFolderFullpath(ctx, c.SignedInUser, c.QueryStrings("folderUid"))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rules", err)
+	}
+	var ruleProvenances map[string]alerting_models.Provenance
+	if provenances != nil {
+		if _, ruleProvenances, err = srv.alertRules.GetAlertRules(ctx, c.SignedInUser); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rules", err)
+		}
+	}
+	filtered := make([]alerting_models.AlertRuleGroupWithFolderFullpath, 0, len(groups))
+	for _, group := range groups {
+		if slices.ContainsFunc(group.Rules, func(rule alerting_models.AlertRule) bool {
+			return matchersMatch(matchers, rule.Labels) && provenances.matches(ruleProvenances[rule.UID])
+		}) {
+			filtered = append(filtered, group)
+		}
+	}
+	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath(filtered)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create alerting file export", err)
+	}
+
+	cps, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{
+		OrgID:   orgID,
+		Decrypt: c.QueryBoolWithDefault("decrypt", false),
+	}, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get contact points", err)
+	}
+	cps = slices.DeleteFunc(cps, func(cp definitions.EmbeddedContactPoint) bool {
+		return !provenances.matches(alerting_models.Provenance(cp.Provenance))
+	})
+	cpExport, err := AlertingFileExportFromEmbeddedContactPoints(orgID, cps)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+	e.ContactPoints = cpExport.ContactPoints
+
+	tree, err := srv.policies.GetPolicyTree(ctx, orgID)
+	if err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	if err == nil && provenances.matches(alerting_models.Provenance(tree.Provenance)) {
+		policiesExport, err := AlertingFileExportFromRoute(orgID, tree)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+		}
+		e.Policies = policiesExport.Policies
+	}
+
+	return exportResponse(c, e)
+}
+
+// RoutePostBundleImport imports a bundle exported by RouteGetBundleExport, in YAML or JSON, into the organization of
+// the user. The folders of the rule groups must exist. With the dryRun query parameter, it only returns the changes
+// the import would make.
+func (srv *ProvisioningBundleSrv) RoutePostBundleImport(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the bundle")
+	}
+	var bundle definitions.AlertingFileExport
+	if err := yaml.Unmarshal(body, &bundle); err != nil {
+		return response.Err(errInvalidBundle(err))
+	}
+
+	ctx := c.Req.Context()
+	plan, err := srv.planImport(ctx, c.SignedInUser, bundle)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to compare the bundle", err)
+	}
+	result := BundleImportResult{DryRun: c.QueryBool("dryRun"), Changes: plan.changes}
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	if err := srv.applyImport(ctx, c.SignedInUser, plan, alerting_models.Provenance(determineProvenance(c))); err != nil {
+		switch {
+		case errors.Is(err, provisioning.ErrValidation),
+			errors.Is(err, alerting_models.ErrAlertRuleFailedValidation),
+			errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation):
+			return ErrResp(http.StatusBadRequest, err, "")
+		case errors.Is(err, store.ErrOptimisticLock):
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to import the bundle", err)
+	}
+	srv.log.FromContext(ctx).Info("Imported alerting provisioning bundle", "changes", len(plan.changes))
+	return response.JSON(http.StatusOK, result)
+}
+
+// bundleImport is the resources of a bundle which differ from the organization.
+type bundleImport struct {
+	changes       []BundleChange
+	contactPoints []contactPointImport
+	policies      *definitions.Route
+	groups        []alerting_models.AlertRuleGroup
+}
+
+type contactPointImport struct {
+	contactPoint definitions.EmbeddedContactPoint
+	create       bool
+}
+
+func (srv *ProvisioningBundleSrv) planImport(ctx context.Context, user identity.Requester, bundle definitions.AlertingFileExport) (*bundleImport, error) {
+	plan := &bundleImport{changes: make([]BundleChange, 0)}
+	if err := srv.planContactPoints(ctx, user, bundle.ContactPoints, plan); err != nil {
+		return nil, err
+	}
+	if err := srv.planPolicies(ctx, user.GetOrgID(), bundle.Policies, plan); err != nil {
+		return nil, err
+	}
+	if err := srv.planGroups(ctx, user, bundle.Groups, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (srv *ProvisioningBundleSrv) planContactPoints(ctx context.Context, user identity.Requester, exports []definitions.ContactPointExport, plan *bundleImport) error {
+	if len(exports) == 0 {
+		return nil
+	}
+	existing, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: user.GetOrgID()}, user)
+	if err != nil {
+		return err
+	}
+	byUID := make(map[string]definitions.EmbeddedContactPoint, len(existing))
+	for _, cp := range existing {
+		byUID[cp.UID] = cp
+	}
+
+	for _, export := range exports {
+		cps, err := EmbeddedContactPointsFromContactPointExport(export)
+		if err != nil {
+			return errInvalidBundle(err)
+		}
+		for _, cp := range cps {
+			change := BundleChange{Kind: BundleResourceContactPoint, Name: cp.Name, UID: cp.UID, Action: BundleActionUnchanged}
+			current, ok := byUID[cp.UID]
+			switch {
+			case !ok:
+				if hasRedactedSettings(cp) {
+					return errInvalidBundle(fmt.Errorf("the secure settings of the %s integration of contact point %q are redacted, export the bundle with decrypt=true", cp.Type, cp.Name))
+				}
+				change.Action = BundleActionCreate
+				plan.contactPoints = append(plan.contactPoints, contactPointImport{contactPoint: cp, create: true})
+			case !contactPointsEqual(current, cp):
+				change.Action = BundleActionUpdate
+				plan.contactPoints = append(plan.contactPoints, contactPointImport{contactPoint: cp})
+			}
+			plan.changes = append(plan.changes, change)
+		}
+	}
+	return nil
+}
+
+func (srv *ProvisioningBundleSrv) planPolicies(ctx context.Context, orgID int64, exports []definitions.NotificationPolicyExport, plan *bundleImport) error {
+	if len(exports) == 0 {
+		return nil
+	}
+	if len(exports) > 1 || exports[0].RouteExport == nil {
+		return errInvalidBundle(errors.New("a bundle has a single notification policy tree"))
+	}
+	tree, err := RouteFromRouteExport(exports[0].RouteExport)
+	if err != nil {
+		return errInvalidBundle(err)
+	}
+
+	change := BundleChange{Kind: BundleResourcePolicies, Name: "policies", Action: BundleActionUnchanged}
+	current, err := srv.policies.GetPolicyTree(ctx, orgID)
+	if err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return err
+	}
+	equal, err := jsonEqual(RouteExportFromRoute(&current), RouteExportFromRoute(&tree))
+	if err != nil {
+		return err
+	}
+	if !equal {
+		change.Action = BundleActionUpdate
+		plan.policies = &tree
+	}
+	plan.changes = append(plan.changes, change)
+	return nil
+}
+
+func (srv *ProvisioningBundleSrv) planGroups(ctx context.Context, user identity.Requester, exports []definitions.AlertRuleGroupExport, plan *bundleImport) error {
+	if len(exports) == 0 {
+		return nil
+	}
+	namespaces, err := srv.namespaces.GetUserVisibleNamespaces(ctx, user.GetOrgID(), user)
+	if err != nil {
+		return err
+	}
+	folderUIDs := make(map[string]string, len(namespaces))
+	for uid, f := range namespaces {
+		path := f.Fullpath
+		if path == "" {
+			path = f.Title
+		}
+		folderUIDs[path] = uid
+	}
+
+	for _, export := range exports {
+		name := export.Folder + "/" + export.Name
+		folderUID, ok := folderUIDs[export.Folder]
+		if !ok {
+			return errInvalidBundle(fmt.Errorf("the folder %q of rule group %q does not exist", export.Folder, export.Name))
+		}
+		group, err := AlertRuleGroupFromAlertRuleGroupExport(export, folderUID)
+		if err != nil {
+			return errInvalidBundle(fmt.Errorf("rule group %q: %w", name, err))
+		}
+
+		current, err := srv.alertRules.GetRuleGroup(ctx, user, folderUID, export.Name)
+		if err != nil && !errors.Is(err, alerting_models.ErrAlertRuleGroupNotFound) {
+			return err
+		}
+		groupChange := BundleChange{Kind: BundleResourceRuleGroup, Name: name, Action: BundleActionUnchanged}
+		if err != nil {
+			groupChange.Action = BundleActionCreate
+		} else if current.Interval != group.Interval {
+			groupChange.Action = BundleActionUpdate
+		}
+
+		ruleChanges := make([]BundleChange, 0, len(group.Rules))
+		currentRules := make(map[string]alerting_models.AlertRule, len(current.Rules))
+		for _, rule := range current.Rules {
+			currentRules[rule.UID] = rule
+		}
+		for _, rule := range group.Rules {
+			change := BundleChange{Kind: BundleResourceRule, Name: rule.Title, UID: rule.UID, Action: BundleActionUnchanged}
+			if currentRule, ok := currentRules[rule.UID]; !ok {
+				change.Action = BundleActionCreate
+			} else {
+				delete(currentRules, rule.UID)
+				equal, err := rulesEqual(currentRule, rule)
+				if err != nil {
+					return err
+				}
+				if !equal {
+					change.Action = BundleActionUpdate
+				}
+			}
+			ruleChanges = append(ruleChanges, change)
+		}
+		// the rules of the group which are not in the bundle are deleted
+		for _, rule := range current.Rules {
+			if _, ok := currentRules[rule.UID]; ok {
+				ruleChanges = append(ruleChanges, BundleChange{Kind: BundleResourceRule, Name: rule.Title, UID: rule.UID, Action: BundleActionDelete})
+			}
+		}
+
+		if groupChange.Action == BundleActionUnchanged && slices.ContainsFunc(ruleChanges, func(change BundleChange) bool {
+			return change.Action != BundleActionUnchanged
+		}) {
+			groupChange.Action = BundleActionUpdate
+		}
+		if groupChange.Action != BundleActionUnchanged {
+			plan.groups = append(plan.groups, group)
+		}
+		plan.changes = append(plan.changes, groupChange)
+		plan.changes = append(plan.changes, ruleChanges...)
+	}
+	return nil
+}
+
+// applyImport applies the changes in the order of their dependencies: the contact points, the notification policies
+// which route to them, and the rule groups which route to them too. The changes are applied in a single transaction,
+// which the services join, so that nothing is changed when one of them fails.
+func (srv *ProvisioningBundleSrv) applyImport(ctx context.Context, user identity.Requester, plan *bundleImport, provenance alerting_models.Provenance) error {
+	orgID := user.GetOrgID()
+	return srv.xact.InTransaction(ctx, func(ctx context.Context) error {
+		for _, cp := range plan.contactPoints {
+			var err error
+			if cp.create {
+				_, err = srv.contactPointService.CreateContactPoint(ctx, orgID, cp.contactPoint, provenance)
+			} else {
+				err = srv.contactPointService.UpdateContactPoint(ctx, orgID, cp.contactPoint, provenance)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to import contact point %q: %w", cp.contactPoint.Name, err)
+			}
+		}
+		if plan.policies != nil {
+			if err := srv.policies.UpdatePolicyTree(ctx, orgID, *plan.policies, provenance); err != nil {
+				return fmt.Errorf("failed to import the notification policies: %w", err)
+			}
+		}
+		for _, group := range plan.groups {
+			if err := srv.alertRules.ReplaceRuleGroup(ctx, user, group, provenance); err != nil {
+				return fmt.Errorf("failed to import rule group %q: %w", group.Title, err)
+			}
+		}
+		return nil
+	})
+}
+
+// rulesEqual compares the rules in the export format, which only has the fields set by the users.
+func rulesEqual(a, b alerting_models.AlertRule) (bool, error) {
+	normalize := func(rule alerting_models.AlertRule) (definitions.AlertRuleExport, error) {
+		if len(rule.Labels) == 0 {
+			rule.Labels = nil
+		}
+		if len(rule.Annotations) == 0 {
+			rule.Annotations = nil
+		}
+		return AlertRuleExportFromAlertRule(rule)
+	}
+	exportA, err := normalize(a)
+	if err != nil {
+		return false, err
+	}
+	exportB, err := normalize(b)
+	if err != nil {
+		return false, err
+	}
+	return jsonEqual(exportA, exportB)
+}
+
+// contactPointsEqual compares the integrations of the contact points. The secure settings, which are redacted in
+// the existing integration, are not compared.
+func contactPointsEqual(current, cp definitions.EmbeddedContactPoint) bool {
+	if current.Name != cp.Name || current.Type != cp.Type || current.DisableResolveMessage != cp.DisableResolveMessage {
+		return false
+	}
+	currentSettings, settings := map[string]any{}, map[string]any{}
+	if current.Settings != nil {
+		currentSettings = current.Settings.MustMap()
+	}
+	if cp.Settings != nil {
+		for k, v := range cp.Settings.MustMap() {
+			settings[k] = v
+		}
+	}
+	for k, v := range currentSettings {
+		if v == definitions.RedactedValue {
+			settings[k] = v
+		}
+	}
+	equal, err := jsonEqual(currentSettings, settings)
+	return err == nil && equal
+}
+
+func hasRedactedSettings(cp definitions.EmbeddedContactPoint) bool {
+	if cp.Settings == nil {
+		return false
+	}
+	for _, v := range cp.Settings.MustMap() {
+		if v == definitions.RedactedValue {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEqual(a, b any) (bool, error) {
+	rawA, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	rawB, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(rawA, rawB), nil
+}
+
+// provenanceFilter is the provenances of the resources to export, or nil for all of them.
+type provenanceFilter map[alerting_models.Provenance]struct{}
+
+func (f provenanceFilter) matches(p alerting_models.Provenance) bool {
+	if f == nil {
+		return true
+	}
+	_, ok := f[p]
+	return ok
+}
+
+func getProvenanceFilterFromQuery(values []string) (provenanceFilter, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	f := make(provenanceFilter, len(values))
+	for _, v := range values {
+		switch v {
+		case "none":
+			f[alerting_models.ProvenanceNone] = struct{}{}
+		case string(alerting_models.ProvenanceAPI), string(alerting_models.ProvenanceFile), string(alerting_models.ProvenanceConvertedPrometheus):
+			f[alerting_models.Provenance(v)] = struct{}{}
+		default:
+			return nil, fmt.Errorf("unknown provenance %q, expected api, file, converted or none", v)
+		}
+	}
+	return f, nil
+}
+
+func (api *API) RegisterProvisioningBundleApiEndpoints(srv *ProvisioningBundleSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/export"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/export",
+				api.Hooks.Wrap(srv.RouteGetBundleExport),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/import"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/import"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/import",
+				api.Hooks.Wrap(srv.RoutePostBundleImport),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestProvisioningBundle(t *testing.T) {
+	env := createTestEnv(t, testConfig)
+	sut := createProvisioningSrvSutFromEnv(t, &env)
+	srv := &ProvisioningBundleSrv{
+		log:                 sut.log,
+		policies:            sut.policies,
+		contactPointService: sut.contactPointService,
+		alertRules:          sut.alertRules,
+		namespaces:          env.store,
+		xact:                env.xact,
+	}
+
+	rule := createTestAlertRuleWithFolderAndGroup("rule-a", 1, "folder-uid", "group-a")
+	rule.Labels = map[string]string{"team": "a"}
+	insertRule(t, sut, rule)
+	insertRule(t, sut, createTestAlertRuleWithFolderAndGroup("rule-b", 1, "folder-uid", "group-b"))
+
+	export := func(t *testing.T, query url.Values) []byte {
+		t.Helper()
+		rc := createTestRequestCtx()
+		for k, v := range query {
+			rc.Req.Form[k] = v
+		}
+		resp := srv.RouteGetBundleExport(&rc)
+		require.Equal(t, 200, resp.Status(), string(resp.Body()))
+		return resp.Body()
+	}
+	importBundle := func(t *testing.T, body []byte, dryRun bool) (int, BundleImportResult) {
+		t.Helper()
+		rc := createTestRequestCtx()
+		rc.Req.Body = io.NopCloser(bytes.NewReader(body))
+		if dryRun {
+			rc.Req.Form.Set("dryRun", "true")
+		}
+		resp := srv.RoutePostBundleImport(&rc)
+		var result BundleImportResult
+		if resp.Status() == 200 {
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		}
+		return resp.Status(), result
+	}
+	actions := func(result BundleImportResult) map[string]BundleAction {
+		m := make(map[string]BundleAction, len(result.Changes))
+		for _, change := range result.Changes {
+			m[string(change.Kind)+":"+change.Name] = change.Action
+		}
+		return m
+	}
+
+	t.Run("should export the groups with a rule matching the filters", func(t *testing.T) {
+		var bundle definitions.AlertingFileExport
+		require.NoError(t, yaml.Unmarshal(export(t, url.Values{"matcher": {`{"name": "team", "value": "a"}`}}), &bundle))
+		require.Len(t, bundle.Groups, 1)
+		assert.Equal(t, "group-a", bundle.Groups[0].Name)
+		assert.Len(t, bundle.ContactPoints, 1)
+		assert.Len(t, bundle.Policies, 1)
+
+		require.NoError(t, yaml.Unmarshal(export(t, url.Values{"provenance": {"file"}}), &bundle))
+		assert.Empty(t, bundle.Groups)
+		assert.Empty(t, bundle.ContactPoints)
+		assert.Empty(t, bundle.Policies)
+	})
+
+	t.Run("should reject an unknown provenance", func(t *testing.T) {
+		rc := createTestRequestCtx()
+		rc.Req.Form.Set("provenance", "terraform")
+		assert.Equal(t, 400, srv.RouteGetBundleExport(&rc).Status())
+	})
+
+	t.Run("should report no change when the bundle is imported in its own organization", func(t *testing.T) {
+		status, result := importBundle(t, export(t, url.Values{}), true)
+		require.Equal(t, 200, status)
+		require.True(t, result.DryRun)
+		require.NotEmpty(t, result.Changes)
+		for _, change := range result.Changes {
+			assert.Equal(t, BundleActionUnchanged, change.Action, "%s %s", change.Kind, change.Name)
+		}
+	})
+
+	t.Run("should preview and apply the changes of the bundle", func(t *testing.T) {
+		var bundle definitions.AlertingFileExport
+		require.NoError(t, yaml.Unmarshal(export(t, url.Values{}), &bundle))
+		for i, group := range bundle.Groups {
+			if group.Name == "group-a" {
+				bundle.Groups[i].Rules[0].Title = "rule-a renamed"
+				bundle.Groups[i].Rules = append(bundle.Groups[i].Rules, definitions.AlertRuleExport{})
+				bundle.Groups[i].Rules[1] = bundle.Groups[i].Rules[0]
+				bundle.Groups[i].Rules[1].UID = "rule-c"
+				bundle.Groups[i].Rules[1].Title = "rule-c"
+			}
+		}
+		bundle.Policies[0].RouteExport.Receiver = "other-receiver"
+		body, err := yaml.Marshal(bundle)
+		require.NoError(t, err)
+
+		status, preview := importBundle(t, body, true)
+		require.Equal(t, 200, status)
+		assert.Equal(t, map[string]BundleAction{
+			"rule-group:Folder Title/group-a":     BundleActionUpdate,
+			"rule:rule-a renamed":                 BundleActionUpdate,
+			"rule:rule-c":                         BundleActionCreate,
+			"rule-group:Folder Title/group-b":     BundleActionUnchanged,
+			"rule:rule-b":                         BundleActionUnchanged,
+			"contact-point:grafana-default-email": BundleActionUnchanged,
+			"policies:policies":                   BundleActionUpdate,
+		}, actions(preview))
+
+		status, result := importBundle(t, body, false)
+		require.Equal(t, 200, status)
+		assert.False(t, result.DryRun)
+		assert.Equal(t, actions(preview), actions(result))
+
+		status, result = importBundle(t, body, true)
+		require.Equal(t, 200, status)
+		for _, change := range result.Changes {
+			assert.Equal(t, BundleActionUnchanged, change.Action, "%s %s", change.Kind, change.Name)
+		}
+	})
+
+	t.Run("should reject the groups of unknown folders", func(t *testing.T) {
+		body := []byte(`
+apiVersion: 1
+groups:
+  - orgId: 1
+    name: group
+    folder: Unknown
+    interval: 1m
+    rules: []
+`)
+		status, _ := importBundle(t, body, true)
+		assert.Equal(t, 400, status)
+	})
+}
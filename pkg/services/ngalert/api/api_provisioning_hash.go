@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/resourcehash"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ResourceHash is the hash of a provisioned resource. The hash only changes when the resource changes, so clients can
+// detect drift by comparing it with the hash they got when they last wrote the resource.
+type ResourceHash struct {
+	Hash string `json:"hash"`
+}
+
+// volatileFields are the fields the server sets on each write, which are left out of the hash and the changes of a
+// resource.
+var volatileFields = []string{"id", "updated", "version"}
+
+func newResourceState(resource any) (resourcehash.State, error) {
+	return resourcehash.New(resource, volatileFields...)
+}
+
+// unchanged returns the state of the current resource, and true if writing the candidate would not change it.
+func unchanged(current, candidate any) (resourcehash.State, bool, error) {
+	return resourcehash.Unchanged(current, candidate, volatileFields...)
+}
+
+// checkVersion returns a conflict if the version of a write is set and is not the current version of the resource,
+// like the services do for the writes which are not a no-op.
+func checkVersion(kind, name, desired, current string) error {
+	if desired == "" || desired == current {
+		return nil
+	}
+	return provisioning.ErrVersionConflict.Errorf("provided version %s of %s %s does not match current version %s", desired, kind, name, current)
+}
+
+// updateResponse adds the fields of the resource changed by a write and its hash to the body of the response, so
+// that clients can tell a no-op from an update. The hash is also returned in a header. The state before is nil if
+// the resource did not exist or could not be read. Fields which cannot be compared, such as secure settings, are
+// passed as changed.
+func updateResponse(status int, body any, before resourcehash.State, resource any, changed ...string) response.Response {
+	after, err := newResourceState(resource)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to hash the resource")
+	}
+	result, err := after.Compare(before, changed...)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to hash the resource")
+	}
+	fields := map[string]any{}
+	raw, err := json.Marshal(body)
+	if err == nil {
+		err = json.Unmarshal(raw, &fields)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	fields["fieldsChanged"] = result.FieldsChanged
+	fields["hash"] = result.Hash
+	return response.JSON(status, fields).SetHeader(resourcehash.Header, result.Hash)
+}
+
+func hashResponse(resource any) response.Response {
+	state, err := newResourceState(resource)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to hash the resource")
+	}
+	hash, err := state.Hash()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to hash the resource")
+	}
+	return response.JSON(http.StatusOK, ResourceHash{Hash: hash}).SetHeader(resourcehash.Header, hash)
+}
+
+// ruleGroupResource returns the state of a rule group in the provisioning API. The rules are compared without the
+// fields set by the server, and with the folder and the group they inherit from the group.
+func ruleGroupResource(g alerting_models.AlertRuleGroup) definitions.AlertRuleGroup {
+	group := ApiAlertRuleGroupFromAlertRuleGroup(g)
+	for i := range group.Rules {
+		group.Rules[i].ID = 0
+		group.Rules[i].OrgID = 0
+		group.Rules[i].Updated = time.Time{}
+		group.Rules[i].FolderUID = group.FolderUID
+		group.Rules[i].RuleGroup = group.Title
+	}
+	return group
+}
+
+// getRuleGroup returns a rule group with the provenance of its rules. The rules of a group are written together, so
+// they have the same provenance.
+func (srv *ProvisioningSrv) getRuleGroup(ctx context.Context, user identity.Requester, folderUID, group string) (alerting_models.AlertRuleGroup, error) {
+	g, err := srv.alertRules.GetRuleGroup(ctx, user, folderUID, group)
+	if err != nil {
+		return alerting_models.AlertRuleGroup{}, err
+	}
+	if len(g.Rules) > 0 {
+		_, provenance, err := srv.alertRules.GetAlertRule(ctx, user, g.Rules[0].UID)
+		if err != nil {
+			return alerting_models.AlertRuleGroup{}, err
+		}
+		g.Provenance = provenance
+	}
+	return g, nil
+}
+
+func (srv *ProvisioningSrv) getContactPoint(ctx context.Context, user identity.Requester, uid string) (definitions.EmbeddedContactPoint, bool, error) {
+	cps, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: user.GetOrgID()}, user)
+	if err != nil {
+		return definitions.EmbeddedContactPoint{}, false, err
+	}
+	for _, cp := range cps {
+		if cp.UID == uid {
+			return cp, true, nil
+		}
+	}
+	return definitions.EmbeddedContactPoint{}, false, nil
+}
+
+// secureSettingsChanged returns true if the contact point sets a secure setting which is redacted in the current
+// contact point. The current value cannot be compared, so the setting is assumed to change.
+func secureSettingsChanged(current, cp definitions.EmbeddedContactPoint) bool {
+	if current.Settings == nil || cp.Settings == nil {
+		return false
+	}
+	settings := cp.Settings.MustMap()
+	for k, v := range current.Settings.MustMap() {
+		if v != definitions.RedactedValue {
+			continue
+		}
+		if value, ok := settings[k]; ok && value != definitions.RedactedValue {
+			return true
+		}
+	}
+	return false
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleHash(c *contextmodel.ReqContext) response.Response {
+	rule, provenance, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":UID"])
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	return hashResponse(ProvisionedAlertRuleFromAlertRule(rule, provenance))
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleGroupHash(c *contextmodel.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	g, err := srv.getRuleGroup(c.Req.Context(), c.SignedInUser, params[":FolderUID"], params[":Group"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	return hashResponse(ruleGroupResource(g))
+}
+
+func (srv *ProvisioningSrv) RouteGetContactPointHash(c *contextmodel.ReqContext) response.Response {
+	cp, ok, err := srv.getContactPoint(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":UID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get contact points", err)
+	}
+	if !ok {
+		return response.Empty(http.StatusNotFound)
+	}
+	return hashResponse(cp)
+}
+
+func (srv *ProvisioningSrv) RouteGetTemplateHash(c *contextmodel.ReqContext) response.Response {
+	template, err := srv.templates.GetTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":name"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	return hashResponse(template)
+}
+
+func (srv *ProvisioningSrv) RouteGetMuteTimingHash(c *contextmodel.ReqContext) response.Response {
+	timing, err := srv.muteTimings.GetMuteTiming(c.Req.Context(), web.Params(c.Req)[":name"], c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get mute timing by name", err)
+	}
+	return hashResponse(timing)
+}
+
+func (srv *ProvisioningSrv) RouteGetPolicyTreeHash(c *contextmodel.ReqContext) response.Response {
+	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return hashResponse(policies)
+}
+
+func (api *API) RegisterProvisioningHashApiEndpoints(srv *ProvisioningSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		for _, route := range []struct {
+			path    string
+			handler RequestHandlerFunc
+		}{
+			{"/api/v1/provisioning/alert-rules/{UID}/hash", srv.RouteGetAlertRuleHash},
+			{"/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/hash", srv.RouteGetAlertRuleGroupHash},
+			{"/api/v1/provisioning/contact-points/{UID}/hash", srv.RouteGetContactPointHash},
+			{"/api/v1/provisioning/templates/{name}/hash", srv.RouteGetTemplateHash},
+			{"/api/v1/provisioning/mute-timings/{name}/hash", srv.RouteGetMuteTimingHash},
+			{"/api/v1/provisioning/policies/hash", srv.RouteGetPolicyTreeHash},
+		} {
+			group.Get(
+				toMacaronPath(route.path),
+				requestmeta.SetOwner(requestmeta.TeamAlerting),
+				api.authorize(http.MethodGet, route.path),
+				metrics.Instrument(
+					http.MethodGet,
+					route.path,
+					api.Hooks.Wrap(route.handler),
+					m,
+				),
+			)
+		}
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util/resourcehash"
+)
+
+func TestResourceState(t *testing.T) {
+	tmpl := definitions.NotificationTemplate{
+		Name:            "slack",
+		Template:        `{{ define "slack" }}{{ end }}`,
+		ResourceVersion: "1",
+	}
+
+	before, err := newResourceState(tmpl)
+	require.NoError(t, err)
+	require.NotContains(t, before, "version")
+
+	t.Run("hash ignores the volatile fields", func(t *testing.T) {
+		updated := tmpl
+		updated.ResourceVersion = "2"
+		after, err := newResourceState(updated)
+		require.NoError(t, err)
+
+		beforeHash, err := before.Hash()
+		require.NoError(t, err)
+		afterHash, err := after.Hash()
+		require.NoError(t, err)
+		require.Equal(t, beforeHash, afterHash)
+		require.Empty(t, after.FieldsChanged(before))
+	})
+
+	t.Run("fields changed are sorted", func(t *testing.T) {
+		updated := tmpl
+		updated.Template = `{{ define "slack" }}{{ .Status }}{{ end }}`
+		updated.Provenance = definitions.Provenance("api")
+		after, err := newResourceState(updated)
+		require.NoError(t, err)
+
+		beforeHash, err := before.Hash()
+		require.NoError(t, err)
+		afterHash, err := after.Hash()
+		require.NoError(t, err)
+		require.NotEqual(t, beforeHash, afterHash)
+		require.Equal(t, []string{"provenance", "template"}, after.FieldsChanged(before))
+	})
+
+	t.Run("all fields changed if the resource did not exist", func(t *testing.T) {
+		require.Equal(t, []string{"name", "template"}, before.FieldsChanged(nil))
+	})
+}
+
+func TestProvisioningApiUpdateResponses(t *testing.T) {
+	t.Run("PUT of an unchanged policy tree is a no-op", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		rc.Req.Header.Set(disableProvenanceHeaderName, "true")
+
+		resp := sut.RoutePutPolicyTree(&rc, definitions.Route{Receiver: "some-receiver"})
+
+		require.Equal(t, 202, resp.Status())
+		body := map[string]any{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &body))
+		require.Equal(t, []any{}, body["fieldsChanged"])
+		require.NotEmpty(t, body["hash"])
+
+		hash := sut.RouteGetPolicyTreeHash(&rc)
+		require.Equal(t, 200, hash.Status())
+		require.JSONEq(t, `{"hash":"`+body["hash"].(string)+`"}`, string(hash.Body()))
+	})
+
+	t.Run("PUT of a changed policy tree returns the fields changed", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		rc.Req.Header.Set(disableProvenanceHeaderName, "true")
+
+		resp := sut.RoutePutPolicyTree(&rc, definitions.Route{Receiver: "other-receiver"})
+
+		require.Equal(t, 202, resp.Status())
+		body := map[string]any{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &body))
+		require.Equal(t, []any{"receiver"}, body["fieldsChanged"])
+		require.Equal(t, body["hash"], resp.(*response.NormalResponse).Header().Get(resourcehash.Header))
+	})
+
+	t.Run("PUT of an unchanged template with a stale version is a conflict", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		rc.Req.Header.Set(disableProvenanceHeaderName, "true")
+
+		resp := sut.RoutePutTemplate(&rc, definitions.NotificationTemplateContent{Template: "template", ResourceVersion: "stale"}, "a")
+
+		require.Equal(t, 409, resp.Status())
+	})
+
+	t.Run("PUT of an unchanged mute timing with a stale version is a conflict", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := createTestRequestCtx()
+		rc.Req.Header.Set(disableProvenanceHeaderName, "true")
+		current, err := sut.muteTimings.GetMuteTiming(rc.Req.Context(), "interval", rc.SignedInUser.GetOrgID())
+		require.NoError(t, err)
+		current.Version = "stale"
+
+		resp := sut.RoutePutMuteTiming(&rc, current, "interval")
+
+		require.Equal(t, 409, resp.Status())
+	})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type RoutingDryRunner interface {
+	DryRunRouting(ctx context.Context, orgID int64, route *definitions.Route, lbls model.LabelSet, now time.Time) (*notifier.RoutingDryRunResult, error)
+}
+
+// PostableRoutingDryRun is the request of a routing dry run.
+type PostableRoutingDryRun struct {
+	Labels model.LabelSet `json:"labels"`
+	// Route is the notification policy tree to route the labels through instead of the current one.
+	Route *definitions.Route `json:"route,omitempty"`
+	// Time is the time the mute timings are checked at, the current time by default.
+	Time *time.Time `json:"time,omitempty"`
+}
+
+type RoutingDryRunSrv struct {
+	log    log.Logger
+	router RoutingDryRunner
+}
+
+// RoutePostRoutingDryRun returns the routes of the notification policy tree that an alert with the labels is sent to,
+// with their contact points, grouping and mute timings. Nothing is sent.
+func (srv *RoutingDryRunSrv) RoutePostRoutingDryRun(c *contextmodel.ReqContext) response.Response {
+	var body PostableRoutingDryRun
+	if err := web.Bind(c.Req, &body); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	now := timeNow()
+	if body.Time != nil {
+		now = *body.Time
+	}
+	result, err := srv.router.DryRunRouting(c.Req.Context(), c.SignedInUser.GetOrgID(), body.Route, body.Labels, now)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to route the labels", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (api *API) RegisterRoutingDryRunApiEndpoints(srv *RoutingDryRunSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/notifications/routing/dry-run"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/notifications/routing/dry-run"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/notifications/routing/dry-run",
+				api.Hooks.Wrap(srv.RoutePostRoutingDryRun),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
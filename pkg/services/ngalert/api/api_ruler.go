@@ -57,6 +57,9 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+
+	versions  RuleVersionStore
+	scheduler RuleScheduler
 }
 
 var (
@@ -397,22 +400,9 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
-		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
-		if len(newOrUpdatedNotificationSettings) > 0 {
-			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
-			if err != nil {
-				return fmt.Errorf("failed to get latest configuration: %w", err)
-			}
-			cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
-			if err != nil {
-				return fmt.Errorf("failed to parse configuration: %w", err)
-			}
-			validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
-			for _, s := range newOrUpdatedNotificationSettings {
-				if err := validator.Validate(s); err != nil {
-					return errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
-				}
-			}
+		dbConfig, err = srv.validateNotificationSettings(c.Req.Context(), groupChanges)
+		if err != nil {
+			return err
 		}
 
 		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
@@ -510,6 +500,30 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 	return changesToResponse(finalChanges)
 }
 
+// validateNotificationSettings checks that the new and updated notification settings of the changes use the receivers
+// and timings of the Alertmanager configuration. It returns the configuration, or nil when there are no new settings.
+func (srv RulerSrv) validateNotificationSettings(ctx context.Context, groupChanges *store.GroupDelta) (*ngmodels.AlertConfiguration, error) {
+	newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
+	if len(newOrUpdatedNotificationSettings) == 0 {
+		return nil, nil
+	}
+	dbConfig, err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(ctx, groupChanges.GroupKey.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
+	for _, s := range newOrUpdatedNotificationSettings {
+		if err := validator.Validate(s); err != nil {
+			return nil, errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+	}
+	return dbConfig, nil
+}
+
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
 	body := apimodels.UpdateRuleGroupResponse{
 		Message: "rule group updated successfully",
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type RuleVersionStore interface {
+	GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error)
+}
+
+// RuleScheduler is notified of the rules updated outside of the ruler API, so that the changes take effect
+// immediately.
+type RuleScheduler interface {
+	UpdateAlertRule(rule *ngmodels.AlertRule)
+}
+
+// GettableRuleVersion is a version of an alert rule and its changes from the previous version.
+type GettableRuleVersion struct {
+	Version       int64 `json:"version"`
+	ParentVersion int64 `json:"parentVersion"`
+	// RestoredFrom is the version this version was restored from, if it's a rollback.
+	RestoredFrom int64                       `json:"restoredFrom,omitempty"`
+	Created      time.Time                   `json:"created"`
+	CreatedBy    string                      `json:"createdBy,omitempty"`
+	Rule         definitions.AlertRuleExport `json:"rule"`
+	Diff         []RuleVersionDiff           `json:"diff"`
+}
+
+// RuleVersionDiff is a field of the rule that changed between two versions.
+type RuleVersionDiff struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// ruleVersionFieldsToIgnoreInDiff are the fields that change with every version.
+var ruleVersionFieldsToIgnoreInDiff = [...]string{"Version", "Updated", "RuleGroupIndex"}
+
+// RouteGetRuleVersions returns the versions of an alert rule, the latest first, with the changes made by each of them.
+func (srv RulerSrv) RouteGetRuleVersions(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	ruleUID := web.Params(c.Req)[":RuleUID"]
+	if _, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	versions, err := srv.versions.GetAlertRuleVersions(ctx, c.SignedInUser.GetOrgID(), ruleUID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+
+	byVersion := make(map[int64]*ngmodels.AlertRuleVersion, len(versions))
+	for _, v := range versions {
+		byVersion[v.Version] = v
+	}
+	result := make([]GettableRuleVersion, 0, len(versions))
+	for _, v := range versions {
+		rule := v.AlertRule()
+		export, err := AlertRuleExportFromAlertRule(rule)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to convert rule version", err)
+		}
+		var previous ngmodels.AlertRule
+		if parent, ok := byVersion[v.ParentVersion]; ok {
+			previous = parent.AlertRule()
+		}
+		result = append(result, GettableRuleVersion{
+			Version:       v.Version,
+			ParentVersion: v.ParentVersion,
+			RestoredFrom:  v.RestoredFrom,
+			Created:       v.Created,
+			CreatedBy:     v.CreatedBy,
+			Rule:          export,
+			Diff:          ruleVersionDiff(previous, rule),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RoutePostRestoreRuleVersion rolls an alert rule back to one of its versions. The definition of the version is saved
+// as a new version, and the rule stays in its current folder and group.
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	ruleUID := web.Params(c.Req)[":RuleUID"]
+	version, err := strconv.ParseInt(web.Params(c.Req)[":Version"], 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid version")
+	}
+
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	if version == rule.Version {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("version %d is the current version of the rule", version), "")
+	}
+	versions, err := srv.versions.GetAlertRuleVersions(ctx, orgID, ruleUID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+	idx := slices.IndexFunc(versions, func(v *ngmodels.AlertRuleVersion) bool {
+		return v.Version == version
+	})
+	if idx < 0 {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("version %d of rule %s not found", version, ruleUID), "")
+	}
+	restored := restoredRule(rule, versions[idx])
+
+	var dbConfig *ngmodels.AlertConfiguration
+	err = srv.xactManager.InTransaction(ctx, func(tranCtx context.Context) error {
+		group, err := srv.getAuthorizedRuleGroup(tranCtx, c, rule.GetGroupKey())
+		if err != nil {
+			return err
+		}
+		groupChanges := &store.GroupDelta{
+			GroupKey:       rule.GetGroupKey(),
+			AffectedGroups: map[ngmodels.AlertRuleGroupKey]ngmodels.RulesGroup{rule.GetGroupKey(): group},
+			Update: []store.RuleDelta{{
+				Existing: &rule,
+				New:      &restored,
+				Diff:     rule.Diff(&restored, store.AlertRuleFieldsToIgnoreInDiff[:]...),
+			}},
+		}
+		if err := srv.authz.AuthorizeRuleChanges(ctx, c.SignedInUser, groupChanges); err != nil {
+			return err
+		}
+		if err := validateQueries(ctx, groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
+			return err
+		}
+		if dbConfig, err = srv.validateNotificationSettings(ctx, groupChanges); err != nil {
+			return err
+		}
+		if err := verifyProvisionedRulesNotAffected(ctx, srv.provenanceStore, orgID, groupChanges); err != nil {
+			return err
+		}
+		return srv.store.UpdateAlertRules(tranCtx, []ngmodels.UpdateRule{{
+			Existing:     &rule,
+			New:          restored,
+			RestoredFrom: version,
+		}})
+	})
+	if err != nil {
+		if errors.As(err, &errutil.Error{}) {
+			return response.Err(err)
+		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+			return ErrResp(http.StatusBadRequest, err, "failed to restore rule version")
+		} else if errors.Is(err, store.ErrOptimisticLock) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to restore rule version")
+	}
+
+	updated, err := srv.store.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{UID: ruleUID, OrgID: orgID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	srv.scheduler.UpdateAlertRule(updated)
+	if srv.featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
+		if err := srv.amRefresher.ApplyConfig(ctx, orgID, dbConfig); err != nil {
+			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", orgID, "error", err)
+		}
+	}
+	srv.log.FromContext(ctx).Info("Restored alert rule version", "rule_uid", ruleUID, "restoredFrom", version, "version", updated.Version)
+	return response.JSON(http.StatusOK, toGettableExtendedRuleNode(*updated, nil))
+}
+
+// restoredRule returns the rule with the definition of the version. The placement of the rule, its dashboard and
+// its pause state are not versioned and are kept.
+func restoredRule(rule ngmodels.AlertRule, version *ngmodels.AlertRuleVersion) ngmodels.AlertRule {
+	restored := version.AlertRule()
+	restored.ID = rule.ID
+	restored.OrgID = rule.OrgID
+	restored.UID = rule.UID
+	restored.NamespaceUID = rule.NamespaceUID
+	restored.RuleGroup = rule.RuleGroup
+	restored.RuleGroupIndex = rule.RuleGroupIndex
+	restored.IntervalSeconds = rule.IntervalSeconds
+	restored.DashboardUID = rule.DashboardUID
+	restored.PanelID = rule.PanelID
+	restored.IsPaused = rule.IsPaused
+	restored.Version = rule.Version
+	restored.Updated = rule.Updated
+	return restored
+}
+
+func ruleVersionDiff(previous, current ngmodels.AlertRule) []RuleVersionDiff {
+	report := previous.Diff(&current, ruleVersionFieldsToIgnoreInDiff[:]...)
+	result := make([]RuleVersionDiff, 0, len(report))
+	for _, d := range report {
+		diff := RuleVersionDiff{Path: d.Path}
+		if d.Left.IsValid() && d.Left.CanInterface() {
+			diff.Old = d.Left.Interface()
+		}
+		if d.Right.IsValid() && d.Right.CanInterface() {
+			diff.New = d.Right.Interface()
+		}
+		result = append(result, diff)
+	}
+	return result
+}
+
+func (api *API) RegisterRuleVersionApiEndpoints(srv RulerSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/rules/{RuleUID}/versions"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/rules/{RuleUID}/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/{RuleUID}/versions",
+				api.Hooks.Wrap(srv.RouteGetRuleVersions),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/rules/{RuleUID}/versions/{Version}/restore"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/rules/{RuleUID}/versions/{Version}/restore"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/{RuleUID}/versions/{Version}/restore",
+				api.Hooks.Wrap(srv.RoutePostRestoreRuleVersion),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func versionOf(rule models.AlertRule, parent int64) *models.AlertRuleVersion {
+	return &models.AlertRuleVersion{
+		RuleOrgID:        rule.OrgID,
+		RuleUID:          rule.UID,
+		RuleNamespaceUID: rule.NamespaceUID,
+		RuleGroup:        rule.RuleGroup,
+		RuleGroupIndex:   rule.RuleGroupIndex,
+		ParentVersion:    parent,
+		Version:          rule.Version,
+		Created:          rule.Updated,
+		Title:            rule.Title,
+		Condition:        rule.Condition,
+		Data:             rule.Data,
+		IntervalSeconds:  rule.IntervalSeconds,
+		Record:           rule.Record,
+		NoDataState:      rule.NoDataState,
+		ExecErrState:     rule.ExecErrState,
+		For:              rule.For,
+		Annotations:      rule.Annotations,
+		Labels:           rule.Labels,
+	}
+}
+
+func TestRestoredRule(t *testing.T) {
+	gen := models.RuleGen
+	current := gen.With(gen.WithTitle("current"), gen.WithIsPaused(true)).Generate()
+	current.Version = 5
+	old := gen.With(gen.WithOrgID(current.OrgID), gen.WithTitle("old")).Generate()
+	old.UID = current.UID
+	old.Version = 2
+
+	restored := restoredRule(current, versionOf(old, 1))
+
+	assert.Equal(t, "old", restored.Title)
+	assert.Equal(t, old.Data, restored.Data)
+	assert.Equal(t, old.Labels, restored.Labels)
+	assert.Equal(t, old.Annotations, restored.Annotations)
+	assert.Equal(t, current.ID, restored.ID)
+	assert.Equal(t, current.Version, restored.Version)
+	assert.Equal(t, current.NamespaceUID, restored.NamespaceUID)
+	assert.Equal(t, current.RuleGroup, restored.RuleGroup)
+	assert.Equal(t, current.IntervalSeconds, restored.IntervalSeconds)
+	assert.True(t, restored.IsPaused)
+}
+
+func TestRuleVersionDiff(t *testing.T) {
+	gen := models.RuleGen
+	previous := gen.With(gen.WithTitle("before")).Generate()
+	previous.Version = 1
+	current := models.CopyRule(&previous)
+	current.Version = 2
+	current.Title = "after"
+
+	diff := ruleVersionDiff(previous, *current)
+	require.Len(t, diff, 1)
+	assert.Equal(t, RuleVersionDiff{Path: "Title", Old: "before", New: "after"}, diff[0])
+
+	t.Run("first version is compared with an empty rule", func(t *testing.T) {
+		diff := ruleVersionDiff(models.AlertRule{}, previous)
+		assert.NotEmpty(t, diff)
+	})
+}
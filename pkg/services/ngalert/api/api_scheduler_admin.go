@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RuleRoutineManager gives access to the evaluation routines of the rules running in the scheduler.
+type RuleRoutineManager interface {
+	RuleRoutines(orgID int64) []schedule.RuleRoutine
+	EvaluateRuleNow(key ngmodels.AlertRuleKey) error
+	RestartRuleRoutine(key ngmodels.AlertRuleKey) error
+}
+
+// GettableRuleRoutine is the evaluation routine of a rule running in the scheduler.
+type GettableRuleRoutine struct {
+	OrgID           int64  `json:"orgId"`
+	RuleUID         string `json:"ruleUid"`
+	Type            string `json:"type"`
+	IntervalSeconds int64  `json:"intervalSeconds"`
+	IsPaused        bool   `json:"isPaused"`
+	// NextEvaluation is the time of the next tick the rule is evaluated at.
+	NextEvaluation *time.Time `json:"nextEvaluation,omitempty"`
+	// LastEvaluation is the time the last evaluation of the rule finished at.
+	LastEvaluation                *time.Time `json:"lastEvaluation,omitempty"`
+	LastEvaluationDurationSeconds float64    `json:"lastEvaluationDurationSeconds"`
+	// PendingEvaluations is the number of evaluations waiting for the routine to pick them up.
+	PendingEvaluations int64 `json:"pendingEvaluations"`
+	// Health and LastError are only reported for recording rules.
+	Health    string `json:"health,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+type SchedulerAdminSrv struct {
+	log      log.Logger
+	routines RuleRoutineManager
+}
+
+// RouteGetRuleRoutines returns the evaluation routines running in the scheduler. They can be filtered by organization
+// with the orgId query parameter.
+func (srv *SchedulerAdminSrv) RouteGetRuleRoutines(c *contextmodel.ReqContext) response.Response {
+	orgID := c.QueryInt64("orgId")
+	routines := srv.routines.RuleRoutines(orgID)
+	result := make([]GettableRuleRoutine, 0, len(routines))
+	for _, r := range routines {
+		result = append(result, toGettableRuleRoutine(r))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RoutePostEvaluateRuleRoutine evaluates the rule immediately, without waiting for its next tick.
+func (srv *SchedulerAdminSrv) RoutePostEvaluateRuleRoutine(c *contextmodel.ReqContext) response.Response {
+	key, err := ruleRoutineKey(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err := srv.routines.EvaluateRuleNow(key); err != nil {
+		return ruleRoutineErrResp(err, "failed to evaluate the rule")
+	}
+	srv.log.FromContext(c.Req.Context()).Info("Rule evaluation requested", append(key.LogContext(), "user", c.SignedInUser.GetLogin())...)
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule evaluation requested"})
+}
+
+// RoutePostRestartRuleRoutine stops the evaluation routine of the rule. A new routine is started on the next tick.
+func (srv *SchedulerAdminSrv) RoutePostRestartRuleRoutine(c *contextmodel.ReqContext) response.Response {
+	key, err := ruleRoutineKey(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err := srv.routines.RestartRuleRoutine(key); err != nil {
+		return ruleRoutineErrResp(err, "failed to restart the rule routine")
+	}
+	srv.log.FromContext(c.Req.Context()).Info("Rule routine restarted", append(key.LogContext(), "user", c.SignedInUser.GetLogin())...)
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule routine restarted"})
+}
+
+func ruleRoutineKey(c *contextmodel.ReqContext) (ngmodels.AlertRuleKey, error) {
+	params := web.Params(c.Req)
+	orgID, err := strconv.ParseInt(params[":OrgID"], 10, 64)
+	if err != nil {
+		return ngmodels.AlertRuleKey{}, fmt.Errorf("invalid organization ID %q", params[":OrgID"])
+	}
+	return ngmodels.AlertRuleKey{OrgID: orgID, UID: params[":RuleUID"]}, nil
+}
+
+func ruleRoutineErrResp(err error, message string) response.Response {
+	if errors.Is(err, schedule.ErrRuleRoutineNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+}
+
+func toGettableRuleRoutine(r schedule.RuleRoutine) GettableRuleRoutine {
+	result := GettableRuleRoutine{
+		OrgID:                         r.Key.OrgID,
+		RuleUID:                       r.Key.UID,
+		Type:                          string(r.Type),
+		IntervalSeconds:               int64(r.Interval.Seconds()),
+		IsPaused:                      r.IsPaused,
+		LastEvaluationDurationSeconds: r.Status.EvaluationDuration.Seconds(),
+		PendingEvaluations:            r.Status.PendingEvaluations,
+		Health:                        r.Status.Health,
+	}
+	if !r.NextEvaluation.IsZero() {
+		result.NextEvaluation = &r.NextEvaluation
+	}
+	if !r.Status.EvaluationTimestamp.IsZero() {
+		result.LastEvaluation = &r.Status.EvaluationTimestamp
+	}
+	if r.Status.LastError != nil {
+		result.LastError = r.Status.LastError.Error()
+	}
+	return result
+}
+
+func (api *API) RegisterSchedulerAdminApiEndpoints(srv *SchedulerAdminSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/scheduler/rules"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/scheduler/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/scheduler/rules",
+				api.Hooks.Wrap(srv.RouteGetRuleRoutines),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/evaluate"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/evaluate"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/evaluate",
+				api.Hooks.Wrap(srv.RoutePostEvaluateRuleRoutine),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/restart"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/restart"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/restart",
+				api.Hooks.Wrap(srv.RoutePostRestartRuleRoutine),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type SilenceScheduleStore interface {
+	ListSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error)
+	SaveSilenceTemplate(ctx context.Context, template *models.SilenceTemplate) error
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error
+	ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error)
+	GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error)
+	SaveRecurringSilence(ctx context.Context, silence *models.RecurringSilence) error
+	DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) error
+}
+
+// RecurringSilenceMaterializer creates and expires the Alertmanager silences of the recurring silences.
+type RecurringSilenceMaterializer interface {
+	Materialize(ctx context.Context, s *models.RecurringSilence) error
+	Expire(ctx context.Context, s *models.RecurringSilence) error
+}
+
+type SilenceScheduleSrv struct {
+	log          log.Logger
+	store        SilenceScheduleStore
+	materializer RecurringSilenceMaterializer
+}
+
+func (srv *SilenceScheduleSrv) RouteGetSilenceTemplates(c *contextmodel.ReqContext) response.Response {
+	templates, err := srv.store.ListSilenceTemplates(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list silence templates", err)
+	}
+	return response.JSON(http.StatusOK, templates)
+}
+
+func (srv *SilenceScheduleSrv) RouteGetSilenceTemplate(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	template, err := srv.store.GetSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get silence template", err)
+	}
+	return response.JSON(http.StatusOK, template)
+}
+
+func (srv *SilenceScheduleSrv) RoutePostSilenceTemplate(c *contextmodel.ReqContext) response.Response {
+	var template models.SilenceTemplate
+	if err := web.Bind(c.Req, &template); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	if err := template.Validate(); err != nil {
+		return response.Err(models.ErrSilenceTemplateInvalid(err))
+	}
+	template.ID = 0
+	template.OrgID = c.SignedInUser.GetOrgID()
+	template.CreatedBy = c.SignedInUser.GetLogin()
+	if err := srv.store.SaveSilenceTemplate(c.Req.Context(), &template); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to save silence template", err)
+	}
+	return response.JSON(http.StatusCreated, template)
+}
+
+// RoutePutSilenceTemplate updates a template and the silences of the current occurrences of the recurring silences
+// that use it. All of them must set the variables of the updated template.
+func (srv *SilenceScheduleSrv) RoutePutSilenceTemplate(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	uid := web.Params(c.Req)[":UID"]
+	existing, err := srv.store.GetSilenceTemplate(ctx, c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get silence template", err)
+	}
+	var template models.SilenceTemplate
+	if err := web.Bind(c.Req, &template); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	if err := template.Validate(); err != nil {
+		return response.Err(models.ErrSilenceTemplateInvalid(err))
+	}
+	template.ID = existing.ID
+	template.UID = existing.UID
+	template.OrgID = existing.OrgID
+	template.CreatedBy = existing.CreatedBy
+
+	silences, err := srv.store.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{OrgID: template.OrgID, TemplateUID: template.UID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list recurring silences", err)
+	}
+	for _, s := range silences {
+		if _, err := s.RenderMatchers(&template); err != nil {
+			return response.Err(models.ErrSilenceTemplateInvalid(fmt.Errorf("recurring silence %q: %w", s.Title, err)))
+		}
+	}
+	if err := srv.store.SaveSilenceTemplate(ctx, &template); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to save silence template", err)
+	}
+	for _, s := range silences {
+		srv.rematerialize(ctx, s)
+	}
+	return response.JSON(http.StatusOK, template)
+}
+
+func (srv *SilenceScheduleSrv) RouteDeleteSilenceTemplate(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	if err := srv.store.DeleteSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete silence template", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "silence template deleted"})
+}
+
+func (srv *SilenceScheduleSrv) RouteGetRecurringSilences(c *contextmodel.ReqContext) response.Response {
+	silences, err := srv.store.ListRecurringSilences(c.Req.Context(), models.ListRecurringSilencesQuery{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		TemplateUID: c.Query("templateUid"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list recurring silences", err)
+	}
+	return response.JSON(http.StatusOK, silences)
+}
+
+func (srv *SilenceScheduleSrv) RouteGetRecurringSilence(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	silence, err := srv.store.GetRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get recurring silence", err)
+	}
+	return response.JSON(http.StatusOK, silence)
+}
+
+func (srv *SilenceScheduleSrv) RoutePostRecurringSilence(c *contextmodel.ReqContext) response.Response {
+	var silence models.RecurringSilence
+	if err := web.Bind(c.Req, &silence); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	silence.ID = 0
+	silence.OrgID = c.SignedInUser.GetOrgID()
+	silence.CreatedBy = c.SignedInUser.GetLogin()
+	return srv.saveRecurringSilence(c, &silence, nil, http.StatusCreated)
+}
+
+// RoutePutRecurringSilence updates a recurring silence. The silence of its current occurrence is expired and created
+// again with the updated matchers and schedule.
+func (srv *SilenceScheduleSrv) RoutePutRecurringSilence(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":UID"]
+	existing, err := srv.store.GetRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get recurring silence", err)
+	}
+	var silence models.RecurringSilence
+	if err := web.Bind(c.Req, &silence); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "bad request data")
+	}
+	silence.ID = existing.ID
+	silence.UID = existing.UID
+	silence.OrgID = existing.OrgID
+	silence.CreatedBy = existing.CreatedBy
+	return srv.saveRecurringSilence(c, &silence, existing, http.StatusOK)
+}
+
+func (srv *SilenceScheduleSrv) RouteDeleteRecurringSilence(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	uid := web.Params(c.Req)[":UID"]
+	existing, err := srv.store.GetRecurringSilence(ctx, c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get recurring silence", err)
+	}
+	if err := srv.materializer.Expire(ctx, existing); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to expire the silence of the recurring silence", err)
+	}
+	if err := srv.store.DeleteRecurringSilence(ctx, existing.OrgID, existing.UID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete recurring silence", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "recurring silence deleted"})
+}
+
+func (srv *SilenceScheduleSrv) saveRecurringSilence(c *contextmodel.ReqContext, silence *models.RecurringSilence, existing *models.RecurringSilence, status int) response.Response {
+	ctx := c.Req.Context()
+	var template *models.SilenceTemplate
+	if silence.TemplateUID != "" {
+		var err error
+		template, err = srv.store.GetSilenceTemplate(ctx, silence.OrgID, silence.TemplateUID)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to get silence template", err)
+		}
+	}
+	if err := silence.Validate(template); err != nil {
+		return response.Err(models.ErrRecurringSilenceInvalid(err))
+	}
+	silence.SilenceID = ""
+	silence.SilenceStartsAt = nil
+	if existing != nil {
+		if err := srv.materializer.Expire(ctx, existing); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to expire the silence of the recurring silence", err)
+		}
+		silence.SilenceID = existing.SilenceID
+		silence.SilenceStartsAt = existing.SilenceStartsAt
+	}
+	if err := srv.store.SaveRecurringSilence(ctx, silence); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to save recurring silence", err)
+	}
+	// the silence of an occurrence that is already active is created right away
+	if err := srv.materializer.Materialize(ctx, silence); err != nil {
+		srv.log.Warn("Failed to create the silence of the recurring silence, it will be retried", "uid", silence.UID, "error", err)
+	}
+	return response.JSON(status, silence)
+}
+
+// rematerialize replaces the silence of the current occurrence of a recurring silence whose template changed.
+func (srv *SilenceScheduleSrv) rematerialize(ctx context.Context, s *models.RecurringSilence) {
+	if s.SilenceID == "" {
+		return
+	}
+	if err := srv.materializer.Expire(ctx, s); err != nil {
+		srv.log.Warn("Failed to expire the silence of the recurring silence", "uid", s.UID, "error", err)
+		return
+	}
+	if err := srv.store.SaveRecurringSilence(ctx, s); err != nil {
+		srv.log.Warn("Failed to save recurring silence", "uid", s.UID, "error", err)
+		return
+	}
+	if err := srv.materializer.Materialize(ctx, s); err != nil {
+		srv.log.Warn("Failed to create the silence of the recurring silence, it will be retried", "uid", s.UID, "error", err)
+	}
+}
+
+func (api *API) RegisterSilenceScheduleApiEndpoints(srv *SilenceScheduleSrv, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/silence-templates"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/silence-templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/silence-templates",
+				api.Hooks.Wrap(srv.RouteGetSilenceTemplates),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/silence-templates/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/silence-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/silence-templates/{UID}",
+				api.Hooks.Wrap(srv.RouteGetSilenceTemplate),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/silence-templates"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/silence-templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/silence-templates",
+				api.Hooks.Wrap(srv.RoutePostSilenceTemplate),
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/silence-templates/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPut, "/api/v1/silence-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/silence-templates/{UID}",
+				api.Hooks.Wrap(srv.RoutePutSilenceTemplate),
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/silence-templates/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodDelete, "/api/v1/silence-templates/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/silence-templates/{UID}",
+				api.Hooks.Wrap(srv.RouteDeleteSilenceTemplate),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/recurring-silences"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/recurring-silences"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/recurring-silences",
+				api.Hooks.Wrap(srv.RouteGetRecurringSilences),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/recurring-silences/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodGet, "/api/v1/recurring-silences/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/recurring-silences/{UID}",
+				api.Hooks.Wrap(srv.RouteGetRecurringSilence),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/recurring-silences"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPost, "/api/v1/recurring-silences"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/recurring-silences",
+				api.Hooks.Wrap(srv.RoutePostRecurringSilence),
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/recurring-silences/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodPut, "/api/v1/recurring-silences/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/recurring-silences/{UID}",
+				api.Hooks.Wrap(srv.RoutePutRecurringSilence),
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/recurring-silences/{UID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			api.authorize(http.MethodDelete, "/api/v1/recurring-silences/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/recurring-silences/{UID}",
+				api.Hooks.Wrap(srv.RouteDeleteRecurringSilence),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -68,6 +68,60 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodGet + "/api/v1/rules/history":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
+	// Grafana rule version history paths
+	case http.MethodGet + "/api/v1/rules/{RuleUID}/versions":
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead),
+			ac.EvalPermission(dashboards.ActionFoldersRead),
+		)
+	case http.MethodPost + "/api/v1/rules/{RuleUID}/versions/{Version}/restore":
+		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead),
+			ac.EvalPermission(dashboards.ActionFoldersRead),
+			ac.EvalPermission(ac.ActionAlertingRuleUpdate),
+		)
+
+	// Grafana maintenance windows paths
+	case http.MethodGet + "/api/v1/maintenance-windows",
+		http.MethodGet + "/api/v1/maintenance-windows/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingInstanceRead),
+			ac.EvalPermission(ac.ActionAlertingSilencesRead),
+		)
+	case http.MethodPost + "/api/v1/maintenance-windows",
+		http.MethodPut + "/api/v1/maintenance-windows/{UID}",
+		http.MethodDelete + "/api/v1/maintenance-windows/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingInstanceCreate),
+			ac.EvalPermission(ac.ActionAlertingInstanceUpdate),
+		)
+
+	// Grafana silence templates and recurring silences paths
+	case http.MethodGet + "/api/v1/silence-templates",
+		http.MethodGet + "/api/v1/silence-templates/{UID}",
+		http.MethodGet + "/api/v1/recurring-silences",
+		http.MethodGet + "/api/v1/recurring-silences/{UID}":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingInstanceRead),
+			ac.EvalPermission(ac.ActionAlertingSilencesRead),
+		)
+	case http.MethodPost + "/api/v1/silence-templates",
+		http.MethodPut + "/api/v1/silence-templates/{UID}",
+		http.MethodDelete + "/api/v1/silence-templates/{UID}",
+		http.MethodPost + "/api/v1/recurring-silences",
+		http.MethodPut + "/api/v1/recurring-silences/{UID}",
+		http.MethodDelete + "/api/v1/recurring-silences/{UID}":
+		// recurring silences create silences of any alert, like silences without a rule
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingInstanceCreate),
+			ac.EvalPermission(ac.ActionAlertingInstanceUpdate),
+		)
+
+	// Grafana notification policy routing paths
+	case http.MethodPost + "/api/v1/notifications/routing/dry-run":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+
 	// Grafana receivers paths
 	case http.MethodGet + "/api/v1/notifications/receivers":
 		// additional authorization is done at the service level
@@ -242,6 +296,12 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodGet + "/api/v1/ngalert/alertmanagers":
 		return middleware.ReqOrgAdmin
 
+	// Scheduler admin paths. The scheduler runs the rules of all organizations.
+	case http.MethodGet + "/api/v1/ngalert/scheduler/rules",
+		http.MethodPost + "/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/evaluate",
+		http.MethodPost + "/api/v1/ngalert/scheduler/rules/{OrgID}/{RuleUID}/restart":
+		return middleware.ReqGrafanaAdmin
+
 	// Grafana-only Provisioning Read Paths
 	case http.MethodGet + "/api/v1/provisioning/policies/export",
 		http.MethodGet + "/api/v1/provisioning/contact-points/export",
@@ -254,6 +314,16 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets),       // organization scope
 		)
 
+	case http.MethodGet + "/api/v1/provisioning/export":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
+			ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets),
+			ac.EvalAll(
+				ac.EvalPermission(ac.ActionAlertingRulesProvisioningRead),
+				ac.EvalPermission(ac.ActionAlertingNotificationsProvisioningRead),
+			),
+		)
+
 	case http.MethodGet + "/api/v1/provisioning/alert-rules",
 		http.MethodGet + "/api/v1/provisioning/alert-rules/export":
 		eval = ac.EvalAny(
@@ -266,7 +336,8 @@ func (api *API) authorize(method, path string) web.Handler {
 			),
 		)
 	case http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}",
-		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}/export":
+		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}/export",
+		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}/hash":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
 			ac.EvalPermission(ac.ActionAlertingRulesProvisioningRead),
@@ -278,7 +349,8 @@ func (api *API) authorize(method, path string) web.Handler {
 		)
 
 	case http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
-		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export":
+		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export",
+		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/hash":
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":FolderUID"))
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
@@ -291,11 +363,15 @@ func (api *API) authorize(method, path string) web.Handler {
 		)
 
 	case http.MethodGet + "/api/v1/provisioning/policies",
+		http.MethodGet + "/api/v1/provisioning/policies/hash",
 		http.MethodGet + "/api/v1/provisioning/contact-points",
+		http.MethodGet + "/api/v1/provisioning/contact-points/{UID}/hash",
 		http.MethodGet + "/api/v1/provisioning/templates",
 		http.MethodGet + "/api/v1/provisioning/templates/{name}",
+		http.MethodGet + "/api/v1/provisioning/templates/{name}/hash",
 		http.MethodGet + "/api/v1/provisioning/mute-timings",
-		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}":
+		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}",
+		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}/hash":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
 			ac.EvalPermission(ac.ActionAlertingNotificationsProvisioningRead), // organization scope
@@ -360,6 +436,21 @@ func (api *API) authorize(method, path string) web.Handler {
 			),
 		)
 
+	case http.MethodPost + "/api/v1/convert/prometheus/rules":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),
+			ac.EvalPermission(ac.ActionAlertingRulesProvisioningWrite),
+		)
+
+	case http.MethodPost + "/api/v1/provisioning/import":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),
+			ac.EvalAll(
+				ac.EvalPermission(ac.ActionAlertingRulesProvisioningWrite),
+				ac.EvalPermission(ac.ActionAlertingNotificationsProvisioningWrite),
+			),
+		)
+
 	case http.MethodPut + "/api/v1/provisioning/policies",
 		http.MethodDelete + "/api/v1/provisioning/policies",
 		http.MethodPost + "/api/v1/provisioning/contact-points",
@@ -3,12 +3,14 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	amConfig "github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
@@ -264,6 +266,132 @@ func AlertQueryExportFromAlertQuery(query models.AlertQuery) (definitions.AlertQ
 	}, nil
 }
 
+// AlertRuleGroupFromAlertRuleGroupExport converts a definitions.AlertRuleGroupExport to a models.AlertRuleGroup of the folder.
+// The rules without states get the default states of the file provisioning.
+func AlertRuleGroupFromAlertRuleGroupExport(g definitions.AlertRuleGroupExport, folderUID string) (models.AlertRuleGroup, error) {
+	group := models.AlertRuleGroup{
+		Title:     g.Name,
+		FolderUID: folderUID,
+		Interval:  int64(time.Duration(g.Interval).Seconds()),
+		Rules:     make([]models.AlertRule, 0, len(g.Rules)),
+	}
+	for _, r := range g.Rules {
+		rule, err := AlertRuleFromAlertRuleExport(r)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("rule %q: %w", r.Title, err)
+		}
+		rule.NamespaceUID = folderUID
+		rule.RuleGroup = group.Title
+		rule.IntervalSeconds = group.Interval
+		group.Rules = append(group.Rules, rule)
+	}
+	return group, nil
+}
+
+// AlertRuleFromAlertRuleExport converts a definitions.AlertRuleExport to a models.AlertRule without folder and group.
+func AlertRuleFromAlertRuleExport(r definitions.AlertRuleExport) (models.AlertRule, error) {
+	rule := models.AlertRule{
+		UID:          r.UID,
+		Title:        r.Title,
+		Data:         make([]models.AlertQuery, 0, len(r.Data)),
+		DashboardUID: r.DashboardUID,
+		PanelID:      r.PanelID,
+		For:          time.Duration(r.For),
+		IsPaused:     r.IsPaused,
+		Record:       ModelRecordFromAlertRuleRecordExport(r.Record),
+	}
+	if r.Condition != nil {
+		rule.Condition = *r.Condition
+	}
+	if r.Annotations != nil {
+		rule.Annotations = *r.Annotations
+	}
+	if r.Labels != nil {
+		rule.Labels = *r.Labels
+	}
+	if r.NoDataState != nil {
+		rule.NoDataState = models.NoDataState(*r.NoDataState)
+	} else if r.Record == nil {
+		rule.NoDataState = models.NoData
+	}
+	if r.ExecErrState != nil {
+		rule.ExecErrState = models.ExecutionErrorState(*r.ExecErrState)
+	} else if r.Record == nil {
+		rule.ExecErrState = models.AlertingErrState
+	}
+
+	for _, q := range r.Data {
+		mdl, err := json.Marshal(q.Model)
+		if err != nil {
+			return models.AlertRule{}, err
+		}
+		query := models.AlertQuery{
+			RefID: q.RefID,
+			RelativeTimeRange: models.RelativeTimeRange{
+				From: models.Duration(time.Duration(q.RelativeTimeRange.FromSeconds) * time.Second),
+				To:   models.Duration(time.Duration(q.RelativeTimeRange.ToSeconds) * time.Second),
+			},
+			DatasourceUID: q.DatasourceUID,
+			Model:         mdl,
+		}
+		if q.QueryType != nil {
+			query.QueryType = *q.QueryType
+		}
+		rule.Data = append(rule.Data, query)
+	}
+
+	ns, err := NotificationSettingsFromAlertRuleNotificationSettingsExport(r.NotificationSettings)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule.NotificationSettings = ns
+	return rule, nil
+}
+
+// NotificationSettingsFromAlertRuleNotificationSettingsExport converts definitions.AlertRuleNotificationSettingsExport to []models.NotificationSettings
+func NotificationSettingsFromAlertRuleNotificationSettingsExport(ns *definitions.AlertRuleNotificationSettingsExport) ([]models.NotificationSettings, error) {
+	if ns == nil {
+		return nil, nil
+	}
+	parseIfNotNil := func(s *string) (*model.Duration, error) {
+		if s == nil {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(*s)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+
+	result := models.NotificationSettings{
+		Receiver:          ns.Receiver,
+		GroupBy:           ns.GroupBy,
+		MuteTimeIntervals: ns.MuteTimeIntervals,
+	}
+	var err error
+	if result.GroupWait, err = parseIfNotNil(ns.GroupWait); err != nil {
+		return nil, fmt.Errorf("failed to parse group wait: %w", err)
+	}
+	if result.GroupInterval, err = parseIfNotNil(ns.GroupInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse group interval: %w", err)
+	}
+	if result.RepeatInterval, err = parseIfNotNil(ns.RepeatInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse repeat interval: %w", err)
+	}
+	return []models.NotificationSettings{result}, nil
+}
+
+func ModelRecordFromAlertRuleRecordExport(r *definitions.AlertRuleRecordExport) *models.Record {
+	if r == nil {
+		return nil
+	}
+	return &models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
 // AlertingFileExportFromEmbeddedContactPoints creates a definitions.AlertingFileExport DTO from []definitions.EmbeddedContactPoint.
 func AlertingFileExportFromEmbeddedContactPoints(orgID int64, ecps []definitions.EmbeddedContactPoint) (definitions.AlertingFileExport, error) {
 	f := definitions.AlertingFileExport{APIVersion: 1}
@@ -309,6 +437,26 @@ func ReceiverExportFromEmbeddedContactPoint(contact definitions.EmbeddedContactP
 	}, nil
 }
 
+// EmbeddedContactPointsFromContactPointExport converts the receivers of a definitions.ContactPointExport to
+// []definitions.EmbeddedContactPoint.
+func EmbeddedContactPointsFromContactPointExport(export definitions.ContactPointExport) ([]definitions.EmbeddedContactPoint, error) {
+	result := make([]definitions.EmbeddedContactPoint, 0, len(export.Receivers))
+	for _, recv := range export.Receivers {
+		settings, err := simplejson.NewJson(recv.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the settings of %s integration (uid:%s): %w", recv.Type, recv.UID, err)
+		}
+		result = append(result, definitions.EmbeddedContactPoint{
+			UID:                   recv.UID,
+			Name:                  export.Name,
+			Type:                  recv.Type,
+			Settings:              settings,
+			DisableResolveMessage: recv.DisableResolveMessage,
+		})
+	}
+	return result, nil
+}
+
 // AlertingFileExportFromRoute creates a definitions.AlertingFileExport DTO from definitions.Route.
 func AlertingFileExportFromRoute(orgID int64, route definitions.Route) (definitions.AlertingFileExport, error) {
 	f := definitions.AlertingFileExport{
@@ -365,6 +513,18 @@ func RouteExportFromRoute(route *definitions.Route) *definitions.RouteExport {
 	return &export
 }
 
+// RouteFromRouteExport converts a definitions.RouteExport to a definitions.Route. The export has the JSON
+// representation of the route, as in the provisioning files.
+func RouteFromRouteExport(export *definitions.RouteExport) (definitions.Route, error) {
+	var route definitions.Route
+	raw, err := json.Marshal(export)
+	if err != nil {
+		return route, err
+	}
+	err = json.Unmarshal(raw, &route)
+	return route, err
+}
+
 // OmitDefault returns nil if the value is the default.
 func OmitDefault[T comparable](v *T) *T {
 	var def T
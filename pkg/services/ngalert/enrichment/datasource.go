@@ -0,0 +1,183 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// DefaultDataSourceAnnotation is the annotation set by the datasource enricher when none is configured.
+	DefaultDataSourceAnnotation = "context_value"
+
+	dataSourceQueryRefID = "A"
+)
+
+// QueryRunner runs the query of the datasource enricher, it's implemented by the expression service.
+type QueryRunner interface {
+	TransformData(ctx context.Context, now time.Time, req *expr.Request) (*backend.QueryDataResponse, error)
+}
+
+// DataSourceEnricher runs a query against a data source for every alert, and sets an annotation to the last value it
+// returns. The query is a Go template of the JSON model of the query, which has access to the alert's labels and
+// annotations, so it can select the series of the alert.
+type DataSourceEnricher struct {
+	dataSourceUID string
+	query         *template.Template
+	queryRange    time.Duration
+	annotation    string
+
+	dataSources datasources.CacheService
+	runner      QueryRunner
+	// userFor returns the identity the queries of the alerts of an organization are run as.
+	userFor func(orgID int64) identity.Requester
+	now     func() time.Time
+}
+
+func NewDataSourceEnricher(dataSourceUID, queryTemplate string, queryRange time.Duration, annotation string,
+	dataSources datasources.CacheService, runner QueryRunner, userFor func(orgID int64) identity.Requester) (*DataSourceEnricher, error) {
+	if dataSourceUID == "" || queryTemplate == "" {
+		return nil, fmt.Errorf("enricher %s requires datasource_uid and datasource_query to be set", DataSourceEnricherName)
+	}
+	tmpl, err := template.New(DataSourceEnricherName).Option("missingkey=zero").Parse(queryTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse datasource_query: %w", err)
+	}
+	if annotation == "" {
+		annotation = DefaultDataSourceAnnotation
+	}
+	return &DataSourceEnricher{
+		dataSourceUID: dataSourceUID,
+		query:         tmpl,
+		queryRange:    queryRange,
+		annotation:    annotation,
+		dataSources:   dataSources,
+		runner:        runner,
+		userFor:       userFor,
+		now:           time.Now,
+	}, nil
+}
+
+func (e *DataSourceEnricher) Name() string {
+	return DataSourceEnricherName
+}
+
+func (e *DataSourceEnricher) Enrich(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) ([]map[string]string, error) {
+	user := e.userFor(key.OrgID)
+	ds, err := e.dataSources.GetDatasourceByUID(ctx, e.dataSourceUID, user, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data source %s: %w", e.dataSourceUID, err)
+	}
+
+	now := e.now()
+	// alerts with the same labels and annotations render the same query, it's run once
+	values := map[string]string{}
+	result := make([]map[string]string, 0, len(alerts))
+	for _, alert := range alerts {
+		query, err := e.render(alert)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := values[query]
+		if !ok {
+			if value, err = e.run(ctx, key.OrgID, user, ds, query, now); err != nil {
+				return nil, err
+			}
+			values[query] = value
+		}
+		if value == "" {
+			result = append(result, nil)
+			continue
+		}
+		result = append(result, map[string]string{e.annotation: value})
+	}
+	return result, nil
+}
+
+func (e *DataSourceEnricher) render(alert amv2.PostableAlert) (string, error) {
+	data := struct {
+		Labels      map[string]string
+		Annotations map[string]string
+	}{
+		Labels:      alert.Labels,
+		Annotations: alert.Annotations,
+	}
+	var buf bytes.Buffer
+	if err := e.query.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute datasource_query: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return "", fmt.Errorf("datasource_query is not valid JSON once executed: %s", buf.String())
+	}
+	return buf.String(), nil
+}
+
+// run runs the query over the query range before now, and returns the last value of the first frame it returns.
+func (e *DataSourceEnricher) run(ctx context.Context, orgID int64, user identity.Requester, ds *datasources.DataSource, query string, now time.Time) (string, error) {
+	resp, err := e.runner.TransformData(ctx, now, &expr.Request{
+		OrgId: orgID,
+		User:  user,
+		Queries: []expr.Query{{
+			RefID:         dataSourceQueryRefID,
+			DataSource:    ds,
+			JSON:          json.RawMessage(query),
+			TimeRange:     expr.AbsoluteTimeRange{From: now.Add(-e.queryRange), To: now},
+			Interval:      time.Second,
+			MaxDataPoints: 100,
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query data source %s: %w", ds.UID, err)
+	}
+	res, ok := resp.Responses[dataSourceQueryRefID]
+	if !ok {
+		return "", nil
+	}
+	if res.Error != nil {
+		return "", fmt.Errorf("failed to query data source %s: %w", ds.UID, res.Error)
+	}
+	for _, frame := range res.Frames {
+		if value, ok := lastValue(frame); ok {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// lastValue returns the last value of the first field of the frame which isn't a time.
+func lastValue(frame *data.Frame) (string, bool) {
+	for _, field := range frame.Fields {
+		if field.Type().Time() {
+			continue
+		}
+		for i := field.Len() - 1; i >= 0; i-- {
+			v, ok := field.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			switch v := v.(type) {
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64), true
+			case float32:
+				return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+			default:
+				return fmt.Sprint(v), true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
@@ -0,0 +1,73 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeQueryRunner struct {
+	queries []string
+	values  map[string]float64
+}
+
+func (f *fakeQueryRunner) TransformData(_ context.Context, _ time.Time, req *expr.Request) (*backend.QueryDataResponse, error) {
+	query := string(req.Queries[0].JSON)
+	f.queries = append(f.queries, query)
+	var model struct {
+		Expr string `json:"expr"`
+	}
+	if err := json.Unmarshal(req.Queries[0].JSON, &model); err != nil {
+		return nil, err
+	}
+	frames := data.Frames{}
+	if value, ok := f.values[model.Expr]; ok {
+		frames = append(frames, data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(0, 0), time.Unix(60, 0)}),
+			data.NewField("value", nil, []*float64{&value, nil}),
+		))
+	}
+	return &backend.QueryDataResponse{Responses: backend.Responses{
+		req.Queries[0].RefID: backend.DataResponse{Frames: frames},
+	}}, nil
+}
+
+func TestDataSourceEnricher(t *testing.T) {
+	dataSources := &fakes.FakeCacheService{DataSources: []*datasources.DataSource{{UID: "prom", OrgID: 1}}}
+	userFor := func(orgID int64) identity.Requester { return &user.SignedInUser{OrgID: orgID} }
+
+	runner := &fakeQueryRunner{values: map[string]float64{"errors_a": 0.25}}
+	e, err := NewDataSourceEnricher("prom", `{"expr": "errors_{{ .Labels.team }}"}`, 10*time.Minute, "", dataSources, runner, userFor)
+	require.NoError(t, err)
+
+	alerts := testAlerts().PostableAlerts
+	alerts = append(alerts, alerts[0])
+	result, err := e.Enrich(context.Background(), models.AlertRuleKey{OrgID: 1, UID: "rule"}, alerts)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{{DefaultDataSourceAnnotation: "0.25"}, nil, {DefaultDataSourceAnnotation: "0.25"}}, result)
+	require.Len(t, runner.queries, 2, "the alerts rendering the same query run it once")
+
+	t.Run("requires the data source and the query", func(t *testing.T) {
+		_, err := NewDataSourceEnricher("prom", "", time.Minute, "", dataSources, runner, userFor)
+		require.Error(t, err)
+	})
+
+	t.Run("fails when the data source does not exist", func(t *testing.T) {
+		e, err := NewDataSourceEnricher("missing", `{}`, time.Minute, "", dataSources, runner, userFor)
+		require.NoError(t, err)
+		_, err = e.Enrich(context.Background(), models.AlertRuleKey{OrgID: 1}, alerts)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,162 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	RunbookEnricherName    = "runbook"
+	WebhookEnricherName    = "webhook"
+	DataSourceEnricherName = "datasource"
+)
+
+// Enricher computes additional annotations for a batch of alerts that belong to the same alert rule.
+type Enricher interface {
+	// Name returns the name of the enricher used in logs.
+	Name() string
+	// Enrich returns the annotations to add for each alert. The returned slice must either be empty
+	// or have the same length as alerts. Annotations already set on an alert are never overwritten.
+	Enrich(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) ([]map[string]string, error)
+}
+
+// AlertsSender is the interface of the component the enriched alerts are handed over to.
+type AlertsSender interface {
+	Send(ctx context.Context, key models.AlertRuleKey, alerts definitions.PostableAlerts)
+}
+
+// Pipeline runs a list of enrichers one after another and merges their results into the alerts' annotations.
+// A failing enricher does not prevent the alerts from being sent, its annotations are simply skipped.
+type Pipeline struct {
+	enrichers []Enricher
+	timeout   time.Duration
+	logger    log.Logger
+}
+
+func NewPipeline(timeout time.Duration, logger log.Logger, enrichers ...Enricher) *Pipeline {
+	return &Pipeline{
+		enrichers: enrichers,
+		timeout:   timeout,
+		logger:    logger,
+	}
+}
+
+// Dependencies are the services used by the enrichers.
+type Dependencies struct {
+	HTTPClientProvider httpclient.Provider
+	DataSources        datasources.CacheService
+	QueryRunner        QueryRunner
+	// UserFor returns the identity the data source queries of the alerts of an organization are run as.
+	UserFor func(orgID int64) identity.Requester
+}
+
+// NewPipelineFromSettings builds the pipeline configured in the [unified_alerting.enrichment] section.
+func NewPipelineFromSettings(cfg setting.UnifiedAlertingEnrichmentSettings, deps Dependencies, logger log.Logger) (*Pipeline, error) {
+	enrichers := make([]Enricher, 0, len(cfg.Enrichers))
+	for _, name := range cfg.Enrichers {
+		switch name {
+		case RunbookEnricherName:
+			e, err := NewRunbookEnricher(cfg.RunbookURLTemplate)
+			if err != nil {
+				return nil, err
+			}
+			enrichers = append(enrichers, e)
+		case WebhookEnricherName:
+			if len(cfg.WebhookURLs) == 0 {
+				return nil, fmt.Errorf("enricher %s requires at least one URL in webhook_urls", WebhookEnricherName)
+			}
+			client, err := deps.HTTPClientProvider.New()
+			if err != nil {
+				return nil, fmt.Errorf("failed to create HTTP client for enricher %s: %w", WebhookEnricherName, err)
+			}
+			for _, u := range cfg.WebhookURLs {
+				enrichers = append(enrichers, NewWebhookEnricher(u, client))
+			}
+		case DataSourceEnricherName:
+			e, err := NewDataSourceEnricher(cfg.DataSourceUID, cfg.DataSourceQuery, cfg.DataSourceQueryRange, cfg.DataSourceAnnotation,
+				deps.DataSources, deps.QueryRunner, deps.UserFor)
+			if err != nil {
+				return nil, err
+			}
+			enrichers = append(enrichers, e)
+		default:
+			return nil, fmt.Errorf("unknown alert enricher %q", name)
+		}
+	}
+	return NewPipeline(cfg.Timeout, logger, enrichers...), nil
+}
+
+// Enrich applies all enrichers to the alerts in place.
+func (p *Pipeline) Enrich(ctx context.Context, key models.AlertRuleKey, alerts definitions.PostableAlerts) {
+	if len(alerts.PostableAlerts) == 0 {
+		return
+	}
+	for _, e := range p.enrichers {
+		logger := p.logger.New(append(key.LogContext(), "enricher", e.Name())...)
+		annotations, err := p.run(ctx, e, key, alerts.PostableAlerts)
+		if err != nil {
+			logger.Warn("Failed to enrich alerts, sending them without the enricher's annotations", "error", err)
+			continue
+		}
+		if len(annotations) == 0 {
+			continue
+		}
+		if len(annotations) != len(alerts.PostableAlerts) {
+			logger.Warn("Enricher returned an unexpected number of results, ignoring them", "expected", len(alerts.PostableAlerts), "actual", len(annotations))
+			continue
+		}
+		for i := range alerts.PostableAlerts {
+			mergeAnnotations(&alerts.PostableAlerts[i], annotations[i])
+		}
+	}
+}
+
+func (p *Pipeline) run(ctx context.Context, e Enricher, key models.AlertRuleKey, alerts []amv2.PostableAlert) ([]map[string]string, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return e.Enrich(ctx, key, alerts)
+}
+
+func mergeAnnotations(alert *amv2.PostableAlert, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	if alert.Annotations == nil {
+		alert.Annotations = make(amv2.LabelSet, len(annotations))
+	}
+	for k, v := range annotations {
+		if _, ok := alert.Annotations[k]; ok {
+			continue
+		}
+		alert.Annotations[k] = v
+	}
+}
+
+// Sender enriches alerts before passing them to the wrapped sender.
+type Sender struct {
+	pipeline *Pipeline
+	next     AlertsSender
+}
+
+func NewSender(pipeline *Pipeline, next AlertsSender) *Sender {
+	return &Sender{pipeline: pipeline, next: next}
+}
+
+func (s *Sender) Send(ctx context.Context, key models.AlertRuleKey, alerts definitions.PostableAlerts) {
+	s.pipeline.Enrich(ctx, key, alerts)
+	s.next.Send(ctx, key, alerts)
+}
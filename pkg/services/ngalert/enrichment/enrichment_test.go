@@ -0,0 +1,130 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeEnricher struct {
+	result []map[string]string
+	err    error
+}
+
+func (f fakeEnricher) Name() string { return "fake" }
+
+func (f fakeEnricher) Enrich(context.Context, models.AlertRuleKey, []amv2.PostableAlert) ([]map[string]string, error) {
+	return f.result, f.err
+}
+
+type fakeSender struct {
+	sent []definitions.PostableAlerts
+}
+
+func (f *fakeSender) Send(_ context.Context, _ models.AlertRuleKey, alerts definitions.PostableAlerts) {
+	f.sent = append(f.sent, alerts)
+}
+
+func testAlerts() definitions.PostableAlerts {
+	return definitions.PostableAlerts{PostableAlerts: []amv2.PostableAlert{
+		{
+			Annotations: amv2.LabelSet{"summary": "existing"},
+			Alert:       amv2.Alert{Labels: amv2.LabelSet{"alertname": "HighLatency", "team": "a"}},
+		},
+		{
+			Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "HighLatency", "team": "b"}},
+		},
+	}}
+}
+
+func TestPipeline(t *testing.T) {
+	key := models.AlertRuleKey{OrgID: 1, UID: "rule"}
+
+	t.Run("merges annotations without overwriting existing ones", func(t *testing.T) {
+		p := NewPipeline(0, log.NewNopLogger(), fakeEnricher{result: []map[string]string{
+			{"summary": "new", "context": "a"},
+			{"summary": "new", "context": "b"},
+		}})
+		alerts := testAlerts()
+		p.Enrich(context.Background(), key, alerts)
+
+		require.Equal(t, amv2.LabelSet{"summary": "existing", "context": "a"}, alerts.PostableAlerts[0].Annotations)
+		require.Equal(t, amv2.LabelSet{"summary": "new", "context": "b"}, alerts.PostableAlerts[1].Annotations)
+	})
+
+	t.Run("skips failing enrichers and enrichers with mismatched results", func(t *testing.T) {
+		p := NewPipeline(0, log.NewNopLogger(),
+			fakeEnricher{err: errors.New("boom")},
+			fakeEnricher{result: []map[string]string{{"context": "a"}}},
+			fakeEnricher{result: []map[string]string{{"ok": "1"}, {"ok": "2"}}},
+		)
+		alerts := testAlerts()
+		p.Enrich(context.Background(), key, alerts)
+
+		require.Equal(t, amv2.LabelSet{"summary": "existing", "ok": "1"}, alerts.PostableAlerts[0].Annotations)
+		require.Equal(t, amv2.LabelSet{"ok": "2"}, alerts.PostableAlerts[1].Annotations)
+	})
+
+	t.Run("sender enriches alerts before forwarding them", func(t *testing.T) {
+		next := &fakeSender{}
+		s := NewSender(NewPipeline(0, log.NewNopLogger(), fakeEnricher{result: []map[string]string{{"a": "1"}, {"a": "2"}}}), next)
+		s.Send(context.Background(), key, testAlerts())
+
+		require.Len(t, next.sent, 1)
+		require.Equal(t, "1", next.sent[0].PostableAlerts[0].Annotations["a"])
+		require.Equal(t, "2", next.sent[0].PostableAlerts[1].Annotations["a"])
+	})
+}
+
+func TestRunbookEnricher(t *testing.T) {
+	_, err := NewRunbookEnricher("")
+	require.Error(t, err)
+
+	e, err := NewRunbookEnricher("https://runbooks.example.com/{{ .Labels.alertname }}/{{ .Labels.team }}")
+	require.NoError(t, err)
+
+	result, err := e.Enrich(context.Background(), models.AlertRuleKey{}, testAlerts().PostableAlerts)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{RunbookURLAnnotation: "https://runbooks.example.com/HighLatency/a"},
+		{RunbookURLAnnotation: "https://runbooks.example.com/HighLatency/b"},
+	}, result)
+}
+
+func TestWebhookEnricher(t *testing.T) {
+	var received WebhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte(`{"alerts":[{"annotations":{"owner":"team-a"}},{"annotations":{"owner":"team-b"}}]}`))
+	}))
+	defer server.Close()
+
+	e := NewWebhookEnricher(server.URL, server.Client())
+	result, err := e.Enrich(context.Background(), models.AlertRuleKey{OrgID: 2, UID: "rule"}, testAlerts().PostableAlerts)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(2), received.OrgID)
+	require.Equal(t, "rule", received.RuleUID)
+	require.Len(t, received.Alerts, 2)
+	require.Equal(t, []map[string]string{{"owner": "team-a"}, {"owner": "team-b"}}, result)
+
+	t.Run("returns error on non-2xx status", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+
+		_, err := NewWebhookEnricher(failing.URL, failing.Client()).Enrich(context.Background(), models.AlertRuleKey{}, testAlerts().PostableAlerts)
+		require.Error(t, err)
+	})
+}
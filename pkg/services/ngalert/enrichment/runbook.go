@@ -0,0 +1,58 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RunbookURLAnnotation is the annotation set by the runbook enricher.
+const RunbookURLAnnotation = "runbook_url"
+
+// RunbookEnricher builds a runbook URL for every alert from a template that has access to the alert's labels and annotations.
+type RunbookEnricher struct {
+	tmpl *template.Template
+}
+
+func NewRunbookEnricher(urlTemplate string) (*RunbookEnricher, error) {
+	if urlTemplate == "" {
+		return nil, fmt.Errorf("enricher %s requires runbook_url_template to be set", RunbookEnricherName)
+	}
+	tmpl, err := template.New(RunbookEnricherName).Option("missingkey=zero").Parse(urlTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse runbook_url_template: %w", err)
+	}
+	return &RunbookEnricher{tmpl: tmpl}, nil
+}
+
+func (e *RunbookEnricher) Name() string {
+	return RunbookEnricherName
+}
+
+func (e *RunbookEnricher) Enrich(_ context.Context, _ models.AlertRuleKey, alerts []amv2.PostableAlert) ([]map[string]string, error) {
+	result := make([]map[string]string, 0, len(alerts))
+	for _, alert := range alerts {
+		data := struct {
+			Labels      map[string]string
+			Annotations map[string]string
+		}{
+			Labels:      alert.Labels,
+			Annotations: alert.Annotations,
+		}
+		var buf bytes.Buffer
+		if err := e.tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to execute runbook_url_template: %w", err)
+		}
+		if buf.Len() == 0 {
+			result = append(result, nil)
+			continue
+		}
+		result = append(result, map[string]string{RunbookURLAnnotation: buf.String()})
+	}
+	return result, nil
+}
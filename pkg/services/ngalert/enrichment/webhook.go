@@ -0,0 +1,98 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// maxWebhookResponseSize limits how much of the enrichment endpoint's response is read.
+const maxWebhookResponseSize = 1 << 20
+
+// WebhookRequest is the payload sent to an enrichment endpoint.
+type WebhookRequest struct {
+	OrgID   int64          `json:"orgId"`
+	RuleUID string         `json:"ruleUid"`
+	Alerts  []WebhookAlert `json:"alerts"`
+}
+
+type WebhookAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// WebhookResponse is the payload expected from an enrichment endpoint.
+// Alerts must be in the same order as in the request.
+type WebhookResponse struct {
+	Alerts []struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"alerts"`
+}
+
+// WebhookEnricher calls an HTTP endpoint with the alerts and uses the annotations it responds with.
+type WebhookEnricher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookEnricher(url string, client *http.Client) *WebhookEnricher {
+	return &WebhookEnricher{url: url, client: client}
+}
+
+func (e *WebhookEnricher) Name() string {
+	return WebhookEnricherName
+}
+
+func (e *WebhookEnricher) Enrich(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) ([]map[string]string, error) {
+	payload := WebhookRequest{
+		OrgID:   key.OrgID,
+		RuleUID: key.UID,
+		Alerts:  make([]WebhookAlert, 0, len(alerts)),
+	}
+	for _, alert := range alerts {
+		payload.Alerts = append(payload.Alerts, WebhookAlert{
+			Labels:      alert.Labels,
+			Annotations: alert.Annotations,
+		})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("enrichment endpoint responded with status %d", resp.StatusCode)
+	}
+
+	var result WebhookResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxWebhookResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode enrichment response: %w", err)
+	}
+
+	annotations := make([]map[string]string, 0, len(result.Alerts))
+	for _, a := range result.Alerts {
+		annotations = append(annotations, a.Annotations)
+	}
+	return annotations, nil
+}
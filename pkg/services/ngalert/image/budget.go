@@ -0,0 +1,63 @@
+package image
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+// RenderBudget limits the number of screenshots each organization can take.
+type RenderBudget interface {
+	// Allow spends a screenshot of the budget of the organization. It returns false if the budget is spent.
+	Allow(orgID int64) bool
+}
+
+// OrgRenderBudget is a budget of screenshots per hour for each organization. The budget refills continuously, so
+// an organization that spent its budget can take a screenshot again after an hour divided by the budget.
+type OrgRenderBudget struct {
+	perHour  int64
+	exceeded *prometheus.CounterVec
+
+	mtx      sync.Mutex
+	limiters map[int64]*rate.Limiter
+}
+
+func NewOrgRenderBudget(perHour int64, r prometheus.Registerer) *OrgRenderBudget {
+	return &OrgRenderBudget{
+		perHour:  perHour,
+		limiters: make(map[int64]*rate.Limiter),
+		exceeded: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name:      "image_render_budget_exceeded_total",
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Help:      "The number of screenshots that were not taken because the organization spent its render budget.",
+		}, []string{"org"}),
+	}
+}
+
+func (b *OrgRenderBudget) Allow(orgID int64) bool {
+	b.mtx.Lock()
+	limiter, ok := b.limiters[orgID]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(time.Hour/time.Duration(b.perHour)), int(b.perHour))
+		b.limiters[orgID] = limiter
+	}
+	b.mtx.Unlock()
+
+	if !limiter.Allow() {
+		b.exceeded.WithLabelValues(strconv.FormatInt(orgID, 10)).Inc()
+		return false
+	}
+	return true
+}
+
+// NoOpRenderBudget is a budget without limits.
+type NoOpRenderBudget struct{}
+
+func (b *NoOpRenderBudget) Allow(_ int64) bool {
+	return true
+}
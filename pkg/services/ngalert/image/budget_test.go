@@ -0,0 +1,22 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgRenderBudget(t *testing.T) {
+	b := NewOrgRenderBudget(2, prometheus.NewRegistry())
+
+	assert.True(t, b.Allow(1))
+	assert.True(t, b.Allow(1))
+	assert.False(t, b.Allow(1))
+
+	// each organization has its own budget
+	assert.True(t, b.Allow(2))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(b.exceeded.WithLabelValues("1")))
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/singleflight"
 
@@ -20,10 +21,6 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-const (
-	screenshotCacheTTL = time.Minute
-)
-
 // DeleteExpiredService is a service to delete expired images.
 type DeleteExpiredService struct {
 	store store.ImageAdminStore
@@ -46,8 +43,14 @@ type ImageService interface {
 // image in the store. The image contains a unique token that can be passed
 // as an annotation or label to the Alertmanager. This service cannot take
 // screenshots of alert rules that are not associated with a dashboard panel.
+//
+// Images are cached for each rule and panel within a time bucket, and the
+// screenshots taken by each organization are limited by a render budget.
 type ScreenshotImageService struct {
+	budget            RenderBudget
 	cache             CacheService
+	cacheTimeBucket   time.Duration
+	clock             clock.Clock
 	limiter           screenshot.RateLimiter
 	logger            log.Logger
 	screenshots       screenshot.ScreenshotService
@@ -59,7 +62,9 @@ type ScreenshotImageService struct {
 
 // NewScreenshotImageService returns a new ScreenshotImageService.
 func NewScreenshotImageService(
+	budget RenderBudget,
 	cache CacheService,
+	cacheTimeBucket time.Duration,
 	limiter screenshot.RateLimiter,
 	logger log.Logger,
 	screenshots screenshot.ScreenshotService,
@@ -67,7 +72,10 @@ func NewScreenshotImageService(
 	store store.ImageStore,
 	uploads *UploadingService) ImageService {
 	return &ScreenshotImageService{
+		budget:            budget,
 		cache:             cache,
+		cacheTimeBucket:   cacheTimeBucket,
+		clock:             clock.New(),
 		limiter:           limiter,
 		logger:            logger,
 		screenshots:       screenshots,
@@ -82,6 +90,7 @@ func NewScreenshotImageService(
 func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds dashboards.DashboardService,
 	rs rendering.Service, r prometheus.Registerer) (ImageService, error) {
 	var (
+		budget            RenderBudget                 = &NoOpRenderBudget{}
 		cache             CacheService                 = &NoOpCacheService{}
 		limiter           screenshot.RateLimiter       = &screenshot.NoOpRateLimiter{}
 		screenshots       screenshot.ScreenshotService = &screenshot.ScreenshotUnavailableService{}
@@ -91,7 +100,10 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 
 	// If screenshots are enabled
 	if cfg.UnifiedAlerting.Screenshots.Capture {
-		cache = NewInmemCacheService(screenshotCacheTTL, r)
+		cache = NewInmemCacheService(cfg.UnifiedAlerting.Screenshots.CacheTimeBucket, r)
+		if cfg.UnifiedAlerting.Screenshots.MaxScreenshotsPerOrgPerHour > 0 {
+			budget = NewOrgRenderBudget(cfg.UnifiedAlerting.Screenshots.MaxScreenshotsPerOrgPerHour, r)
+		}
 		limiter = screenshot.NewTokenRateLimiter(cfg.UnifiedAlerting.Screenshots.MaxConcurrentScreenshots)
 		screenshots = screenshot.NewHeadlessScreenshotService(cfg, ds, rs, r)
 		screenshotTimeout = cfg.UnifiedAlerting.Screenshots.CaptureTimeout
@@ -106,7 +118,7 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 		}
 	}
 
-	return NewScreenshotImageService(cache, limiter, log.New("ngalert.image"),
+	return NewScreenshotImageService(budget, cache, cfg.UnifiedAlerting.Screenshots.CacheTimeBucket, limiter, log.New("ngalert.image"),
 		screenshots, screenshotTimeout, db, uploads), nil
 }
 
@@ -116,7 +128,9 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 // taken. If the alert rule does not have a Dashboard UID in its annotations,
 // or the dashboard does not exist, a models.ErrNoDashboard error is returned. If the
 // alert rule has a Dashboard UID and the dashboard exists, but does not have a
-// Panel ID in its annotations then a models.ErrNoPanel error is returned.
+// Panel ID in its annotations then a models.ErrNoPanel error is returned. If the
+// organization of the alert rule has spent its render budget, a
+// models.ErrImageRenderBudgetExceeded error is returned.
 func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error) {
 	logger := s.logger.FromContext(ctx)
 
@@ -144,9 +158,10 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 	// To prevent concurrent screenshots of the same dashboard panel we use singleflight,
 	// deduplicated on a base64 hash of the screenshot options.
 	optsHash := base64.StdEncoding.EncodeToString(opts.Hash())
+	cacheKey := imageCacheKey(r, panelID, s.clock.Now().Truncate(s.cacheTimeBucket))
 
 	// If there is an image is in the cache return it instead of taking another screenshot
-	if image, ok := s.cache.Get(ctx, optsHash); ok {
+	if image, ok := s.cache.Get(ctx, cacheKey); ok {
 		logger.Debug("Found cached image", "token", image.Token)
 		return &image, nil
 	}
@@ -162,6 +177,11 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 		screenshotCtx, cancelFunc := context.WithTimeout(ctx, s.screenshotTimeout)
 		defer cancelFunc()
 
+		if !s.budget.Allow(r.OrgID) {
+			logger.Warn("Cannot take screenshot as the organization has spent its render budget")
+			return nil, models.ErrImageRenderBudgetExceeded
+		}
+
 		// Once deduplicated concurrent screenshots are then rate-limited
 		screenshot, err := s.limiter.Do(screenshotCtx, opts, s.screenshots.Take)
 		if err != nil {
@@ -195,7 +215,7 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 	}
 
 	image := result.(models.Image)
-	if err = s.cache.Set(ctx, optsHash, image); err != nil {
+	if err = s.cache.Set(ctx, cacheKey, image); err != nil {
 		s.logger.Warn("Failed to cache image",
 			"token", image.Token,
 			"error", err)
@@ -203,3 +223,8 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 
 	return &image, nil
 }
+
+// imageCacheKey returns the key of the images of the rule and panel taken within the time bucket.
+func imageCacheKey(r *models.AlertRule, panelID int64, bucket time.Time) string {
+	return fmt.Sprintf("%d/%s/%s/%d/%d", r.OrgID, r.UID, r.GetDashboardUID(), panelID, bucket.Unix())
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/golang/mock/gomock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -31,14 +32,15 @@ func TestScreenshotImageService(t *testing.T) {
 		uploads     = imguploader.NewMockImageUploader(ctrl)
 	)
 
-	s := NewScreenshotImageService(cache, &limiter, log.NewNopLogger(), screenshots, 5*time.Second, images,
+	s := NewScreenshotImageService(&NoOpRenderBudget{}, cache, time.Minute, &limiter, log.NewNopLogger(), screenshots, 5*time.Second, images,
 		NewUploadingService(uploads, prometheus.NewRegistry()))
+	s.(*ScreenshotImageService).clock = clock.NewMock()
 
 	ctx := context.Background()
 
 	t.Run("image is taken, uploaded, saved to database and cached", func(t *testing.T) {
 		// assert that the cache is checked for an existing image
-		cache.EXPECT().Get(gomock.Any(), "oyh1kYgaJwM=").Return(models.Image{}, false)
+		cache.EXPECT().Get(gomock.Any(), "1/foo/foo/1/0").Return(models.Image{}, false)
 
 		// assert that a screenshot is taken
 		screenshots.EXPECT().Take(gomock.Any(), screenshot.ScreenshotOptions{
@@ -63,7 +65,7 @@ func TestScreenshotImageService(t *testing.T) {
 		}
 
 		// assert that the image is saved into the cache
-		cache.EXPECT().Set(gomock.Any(), "oyh1kYgaJwM=", expected).Return(nil)
+		cache.EXPECT().Set(gomock.Any(), "1/foo/foo/1/0", expected).Return(nil)
 
 		image, err := s.NewImage(ctx, &models.AlertRule{
 			OrgID:        1,
@@ -76,7 +78,7 @@ func TestScreenshotImageService(t *testing.T) {
 
 	t.Run("image is taken, upload return error, saved to database without URL and cached", func(t *testing.T) {
 		// assert that the cache is checked for an existing image
-		cache.EXPECT().Get(gomock.Any(), "yszV9tgmKAo=").Return(models.Image{}, false)
+		cache.EXPECT().Get(gomock.Any(), "1/bar/bar/1/0").Return(models.Image{}, false)
 
 		// assert that a screenshot is taken
 		screenshots.EXPECT().Take(gomock.Any(), screenshot.ScreenshotOptions{
@@ -100,7 +102,7 @@ func TestScreenshotImageService(t *testing.T) {
 		}
 
 		// assert that the image is saved into the cache, but without a URL
-		cache.EXPECT().Set(gomock.Any(), "yszV9tgmKAo=", expected).Return(nil)
+		cache.EXPECT().Set(gomock.Any(), "1/bar/bar/1/0", expected).Return(nil)
 
 		image, err := s.NewImage(ctx, &models.AlertRule{
 			OrgID:        1,
@@ -115,7 +117,7 @@ func TestScreenshotImageService(t *testing.T) {
 		expected := models.Image{Path: "baz.png", URL: "https://example.com/baz.png"}
 
 		// assert that the cache is checked for an existing image and it is returned
-		cache.EXPECT().Get(gomock.Any(), "he399rFDBPI=").Return(expected, true)
+		cache.EXPECT().Get(gomock.Any(), "1/baz/baz/1/0").Return(expected, true)
 
 		image, err := s.NewImage(ctx, &models.AlertRule{
 			OrgID:        1,
@@ -128,7 +130,7 @@ func TestScreenshotImageService(t *testing.T) {
 
 	t.Run("error is returned when timeout is exceeded", func(t *testing.T) {
 		// assert that the cache is checked for an existing image
-		cache.EXPECT().Get(gomock.Any(), "TTHub8HUe2U=").Return(models.Image{}, false)
+		cache.EXPECT().Get(gomock.Any(), "1/qux/qux/1/0").Return(models.Image{}, false)
 
 		// assert that when the timeout is exceeded an error is returned
 		screenshots.EXPECT().Take(gomock.Any(), screenshot.ScreenshotOptions{
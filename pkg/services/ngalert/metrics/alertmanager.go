@@ -11,18 +11,55 @@ type Alertmanager struct {
 	Registerer prometheus.Registerer
 	*metrics.Alerts
 	*AlertmanagerConfigMetrics
+	*NotificationRateLimitMetrics
 }
 
 // NewAlertmanagerMetrics creates a set of metrics for the Alertmanager of each organization.
 func NewAlertmanagerMetrics(r prometheus.Registerer) *Alertmanager {
 	other := prometheus.WrapRegistererWithPrefix(fmt.Sprintf("%s_%s_", Namespace, Subsystem), r)
 	return &Alertmanager{
-		Registerer:                r,
-		Alerts:                    metrics.NewAlerts(other),
-		AlertmanagerConfigMetrics: NewAlertmanagerConfigMetrics(r),
+		Registerer:                   r,
+		Alerts:                       metrics.NewAlerts(other),
+		AlertmanagerConfigMetrics:    NewAlertmanagerConfigMetrics(r),
+		NotificationRateLimitMetrics: NewNotificationRateLimitMetrics(r),
 	}
 }
 
+type NotificationRateLimitMetrics struct {
+	// RateLimitedNotifications counts the notifications over the rate limit of their integration, by how they are
+	// handled.
+	RateLimitedNotifications   *prometheus.CounterVec
+	DigestNotifications        *prometheus.CounterVec
+	DigestNotificationFailures *prometheus.CounterVec
+}
+
+func NewNotificationRateLimitMetrics(r prometheus.Registerer) *NotificationRateLimitMetrics {
+	m := &NotificationRateLimitMetrics{
+		RateLimitedNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "notifications_rate_limited_total",
+			Help:      "The total number of notifications over the rate limit of their integration, dropped or batched in a digest.",
+		}, []string{"integration", "overflow"}),
+		DigestNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "notification_digests_total",
+			Help:      "The total number of digest notifications of the rate limited integrations.",
+		}, []string{"integration"}),
+		DigestNotificationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "notification_digests_failed_total",
+			Help:      "The total number of digest notifications of the rate limited integrations that failed to be sent.",
+		}, []string{"integration"}),
+	}
+	if r != nil {
+		r.MustRegister(m.RateLimitedNotifications, m.DigestNotifications, m.DigestNotificationFailures)
+	}
+	return m
+}
+
 type AlertmanagerConfigMetrics struct {
 	ConfigHash     *prometheus.GaugeVec
 	Matchers       prometheus.Gauge
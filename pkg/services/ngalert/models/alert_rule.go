@@ -657,6 +657,33 @@ type AlertRuleVersion struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// CreatedBy is the login of the user who made the change, empty for the changes made by Grafana itself.
+	CreatedBy string `xorm:"created_by"`
+}
+
+// AlertRule returns the rule as it was at this version. The fields which are not versioned, such as the ID or the
+// dashboard of the rule, are not set.
+func (v *AlertRuleVersion) AlertRule() AlertRule {
+	return AlertRule{
+		OrgID:                v.RuleOrgID,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		RuleGroupIndex:       v.RuleGroupIndex,
+		Version:              v.Version,
+		Updated:              v.Created,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		IntervalSeconds:      v.IntervalSeconds,
+		Record:               v.Record,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		NotificationSettings: v.NotificationSettings,
+	}
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -722,6 +749,8 @@ type ListNamespaceAlertRulesQuery struct {
 type UpdateRule struct {
 	Existing *AlertRule
 	New      AlertRule
+	// RestoredFrom is the version the new rule is restored from, if any.
+	RestoredFrom int64
 }
 
 // Condition contains backend expressions and queries and the RefID
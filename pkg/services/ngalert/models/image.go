@@ -8,6 +8,8 @@ import (
 var (
 	// ErrImageNotFound is returned when the image does not exist.
 	ErrImageNotFound = errors.New("image not found")
+	// ErrImageRenderBudgetExceeded is returned when the organization has spent its budget of screenshots.
+	ErrImageRenderBudgetExceeded = errors.New("image render budget exceeded")
 )
 
 type Image struct {
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+// StateReasonSuppressed is the state reason of alert instances that matched an active maintenance window.
+// The UID of the window is appended to it, see SuppressedStateReason.
+const StateReasonSuppressed = "Suppressed"
+
+var (
+	errMaintenanceWindowInvalidMsg  = "invalid maintenance window: {{ .Public.Reason }}"
+	ErrMaintenanceWindowNotFound    = errutil.NotFound("alerting.maintenance-window.notFound", errutil.WithPublicMessage("Maintenance window not found"))
+	ErrMaintenanceWindowInvalidBase = errutil.BadRequest("alerting.maintenance-window.invalid").
+					MustTemplate(errMaintenanceWindowInvalidMsg, errutil.WithPublic(errMaintenanceWindowInvalidMsg))
+)
+
+func ErrMaintenanceWindowInvalid(underlying error) error {
+	return ErrMaintenanceWindowInvalidBase.Build(errutil.TemplateData{Public: map[string]any{"Reason": underlying.Error()}, Error: underlying})
+}
+
+// MaintenanceWindow is a time range during which notifications for matching alert instances are withheld.
+// A window matches an alert instance when all of its non-empty scopes match: the rule is stored in one of
+// FolderUIDs, the rule queries one of DatasourceUIDs, and the instance labels satisfy all Matchers.
+type MaintenanceWindow struct {
+	ID             int64     `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID          int64     `xorm:"org_id" json:"-"`
+	UID            string    `xorm:"uid" json:"uid"`
+	Title          string    `xorm:"title" json:"title"`
+	StartsAt       time.Time `xorm:"starts_at" json:"startsAt"`
+	EndsAt         time.Time `xorm:"ends_at" json:"endsAt"`
+	FolderUIDs     []string  `xorm:"folder_uids" json:"folderUids,omitempty"`
+	DatasourceUIDs []string  `xorm:"datasource_uids" json:"datasourceUids,omitempty"`
+	// Matchers are label matchers in the Prometheus format, e.g. team="backend".
+	Matchers  []string  `xorm:"matchers" json:"matchers,omitempty"`
+	CreatedBy string    `xorm:"created_by" json:"createdBy"`
+	Updated   time.Time `xorm:"updated" json:"updated"`
+
+	parsedMatchers labels.Matchers `xorm:"-"`
+}
+
+func (w *MaintenanceWindow) TableName() string {
+	return "alert_maintenance_window"
+}
+
+// Validate checks that the window has a valid time range and at least one scope.
+func (w *MaintenanceWindow) Validate() error {
+	if w.Title == "" {
+		return errors.New("title is required")
+	}
+	if w.StartsAt.IsZero() || w.EndsAt.IsZero() {
+		return errors.New("startsAt and endsAt are required")
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+	if len(w.FolderUIDs) == 0 && len(w.DatasourceUIDs) == 0 && len(w.Matchers) == 0 {
+		return errors.New("at least one of folderUids, datasourceUids or matchers must be set")
+	}
+	_, err := w.LabelMatchers()
+	return err
+}
+
+// LabelMatchers returns the parsed Matchers of the window. The result is cached, so it should be called
+// once before the window is shared between goroutines.
+func (w *MaintenanceWindow) LabelMatchers() (labels.Matchers, error) {
+	if w.parsedMatchers != nil || len(w.Matchers) == 0 {
+		return w.parsedMatchers, nil
+	}
+	result := make(labels.Matchers, 0, len(w.Matchers))
+	for _, s := range w.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", s, err)
+		}
+		result = append(result, m)
+	}
+	w.parsedMatchers = result
+	return result, nil
+}
+
+// IsActive returns true if t is within the window's time range.
+func (w *MaintenanceWindow) IsActive(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// Matches returns true if the alert instance of the rule with the given labels is in the scope of the window.
+func (w *MaintenanceWindow) Matches(rule *AlertRule, lbls data.Labels) bool {
+	if rule == nil || rule.OrgID != w.OrgID {
+		return false
+	}
+	if len(w.FolderUIDs) > 0 && !slices.Contains(w.FolderUIDs, rule.NamespaceUID) {
+		return false
+	}
+	if len(w.DatasourceUIDs) > 0 && !slices.ContainsFunc(rule.Data, func(q AlertQuery) bool {
+		return slices.Contains(w.DatasourceUIDs, q.DatasourceUID)
+	}) {
+		return false
+	}
+	matchers, err := w.LabelMatchers()
+	if err != nil {
+		return false
+	}
+	for _, m := range matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SuppressedStateReason returns the state reason recorded for instances suppressed by the window with the given UID.
+func SuppressedStateReason(windowUID string) string {
+	return fmt.Sprintf("%s (window=%s)", StateReasonSuppressed, windowUID)
+}
+
+// IsSuppressedStateReason returns true if the state reason was set because of a maintenance window.
+func IsSuppressedStateReason(reason string) bool {
+	return strings.Contains(reason, StateReasonSuppressed)
+}
+
+// WithoutSuppressedStateReason returns the state reason without the reasons set because of maintenance windows.
+func WithoutSuppressedStateReason(reason string) string {
+	if !IsSuppressedStateReason(reason) {
+		return reason
+	}
+	var kept []string
+	for _, r := range strings.Split(reason, ", ") {
+		if !strings.HasPrefix(r, StateReasonSuppressed) {
+			kept = append(kept, r)
+		}
+	}
+	return ConcatReasons(kept...)
+}
+
+type ListMaintenanceWindowsQuery struct {
+	OrgID int64
+	// ActiveAt, if set, returns only windows active at the given time.
+	ActiveAt *time.Time
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	now := time.Now()
+	valid := func() *MaintenanceWindow {
+		return &MaintenanceWindow{
+			Title:    "upgrade",
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+			Matchers: []string{`team="backend"`},
+		}
+	}
+
+	require.NoError(t, valid().Validate())
+
+	w := valid()
+	w.Title = ""
+	require.Error(t, w.Validate())
+
+	w = valid()
+	w.EndsAt = w.StartsAt
+	require.Error(t, w.Validate())
+
+	w = valid()
+	w.Matchers = nil
+	require.ErrorContains(t, w.Validate(), "at least one of")
+
+	w = valid()
+	w.Matchers = []string{"not a matcher"}
+	require.Error(t, w.Validate())
+}
+
+func TestMaintenanceWindowMatches(t *testing.T) {
+	rule := &AlertRule{
+		OrgID:        1,
+		NamespaceUID: "folder-a",
+		Data:         []AlertQuery{{RefID: "A", DatasourceUID: "prom"}, {RefID: "B", DatasourceUID: "__expr__"}},
+	}
+	lbls := data.Labels{"team": "backend", "env": "prod"}
+
+	testCases := []struct {
+		name     string
+		window   MaintenanceWindow
+		expected bool
+	}{
+		{name: "folder", window: MaintenanceWindow{OrgID: 1, FolderUIDs: []string{"folder-a"}}, expected: true},
+		{name: "other folder", window: MaintenanceWindow{OrgID: 1, FolderUIDs: []string{"folder-b"}}, expected: false},
+		{name: "datasource", window: MaintenanceWindow{OrgID: 1, DatasourceUIDs: []string{"prom"}}, expected: true},
+		{name: "other datasource", window: MaintenanceWindow{OrgID: 1, DatasourceUIDs: []string{"loki"}}, expected: false},
+		{name: "matchers", window: MaintenanceWindow{OrgID: 1, Matchers: []string{`team="backend"`, `env=~"prod|staging"`}}, expected: true},
+		{name: "not matching matchers", window: MaintenanceWindow{OrgID: 1, Matchers: []string{`team="frontend"`}}, expected: false},
+		{name: "all scopes", window: MaintenanceWindow{OrgID: 1, FolderUIDs: []string{"folder-a"}, DatasourceUIDs: []string{"prom"}, Matchers: []string{`env="prod"`}}, expected: true},
+		{name: "one scope does not match", window: MaintenanceWindow{OrgID: 1, FolderUIDs: []string{"folder-a"}, Matchers: []string{`env="dev"`}}, expected: false},
+		{name: "other org", window: MaintenanceWindow{OrgID: 2, FolderUIDs: []string{"folder-a"}}, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.window.Matches(rule, lbls))
+		})
+	}
+}
+
+func TestSuppressedStateReason(t *testing.T) {
+	reason := SuppressedStateReason("abc")
+	require.Equal(t, "Suppressed (window=abc)", reason)
+	require.True(t, IsSuppressedStateReason(reason))
+	require.True(t, IsSuppressedStateReason(ConcatReasons(StateReasonNoData, reason)))
+	require.False(t, IsSuppressedStateReason(StateReasonNoData))
+
+	require.Equal(t, StateReasonNoData, WithoutSuppressedStateReason(ConcatReasons(StateReasonNoData, reason)))
+	require.Empty(t, WithoutSuppressedStateReason(ConcatReasons(reason, SuppressedStateReason("def"))))
+	require.Equal(t, StateReasonNoData, WithoutSuppressedStateReason(StateReasonNoData))
+}
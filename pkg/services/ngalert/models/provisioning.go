@@ -8,10 +8,12 @@ const (
 	ProvenanceNone Provenance = ""
 	ProvenanceAPI  Provenance = "api"
 	ProvenanceFile Provenance = "file"
+	// ProvenanceConvertedPrometheus is the provenance of the rules converted from Prometheus rules.
+	ProvenanceConvertedPrometheus Provenance = "converted"
 )
 
 var (
-	KnownProvenances = []Provenance{ProvenanceNone, ProvenanceAPI, ProvenanceFile}
+	KnownProvenances = []Provenance{ProvenanceNone, ProvenanceAPI, ProvenanceFile, ProvenanceConvertedPrometheus}
 )
 
 // Provisionable represents a resource that can be created through a provisioning mechanism, such as Terraform or config file.
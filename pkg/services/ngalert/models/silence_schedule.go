@@ -0,0 +1,291 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	errSilenceScheduleInvalidMsg  = "invalid {{ .Public.Kind }}: {{ .Public.Reason }}"
+	ErrSilenceTemplateNotFound    = errutil.NotFound("alerting.silence-template.notFound", errutil.WithPublicMessage("Silence template not found"))
+	ErrSilenceTemplateInUse       = errutil.Conflict("alerting.silence-template.inUse", errutil.WithPublicMessage("Silence template is used by recurring silences"))
+	ErrRecurringSilenceNotFound   = errutil.NotFound("alerting.recurring-silence.notFound", errutil.WithPublicMessage("Recurring silence not found"))
+	ErrSilenceScheduleInvalidBase = errutil.BadRequest("alerting.silence-schedule.invalid").
+					MustTemplate(errSilenceScheduleInvalidMsg, errutil.WithPublic(errSilenceScheduleInvalidMsg))
+)
+
+// silenceTemplateVariable matches the references to variables in the matchers of silence templates, e.g. ${host}.
+var silenceTemplateVariable = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// recurringSilenceOverlapChecks is the number of occurrences checked for overlaps when a recurring silence is saved.
+const recurringSilenceOverlapChecks = 10
+
+// ErrSilenceTemplateInvalid returns the error of an invalid silence template.
+func ErrSilenceTemplateInvalid(underlying error) error {
+	return ErrSilenceScheduleInvalidBase.Build(errutil.TemplateData{Public: map[string]any{"Kind": "silence template", "Reason": underlying.Error()}, Error: underlying})
+}
+
+// ErrRecurringSilenceInvalid returns the error of an invalid recurring silence.
+func ErrRecurringSilenceInvalid(underlying error) error {
+	return ErrSilenceScheduleInvalidBase.Build(errutil.TemplateData{Public: map[string]any{"Kind": "recurring silence", "Reason": underlying.Error()}, Error: underlying})
+}
+
+// SilenceTemplate is a reusable set of silence matchers. The matchers are in the Prometheus format and their values
+// can reference variables, e.g. instance="${host}", which are set by the recurring silences that use the template.
+type SilenceTemplate struct {
+	ID        int64     `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID     int64     `xorm:"org_id" json:"-"`
+	UID       string    `xorm:"uid" json:"uid"`
+	Title     string    `xorm:"title" json:"title"`
+	Comment   string    `xorm:"comment" json:"comment,omitempty"`
+	Matchers  []string  `xorm:"matchers" json:"matchers"`
+	CreatedBy string    `xorm:"created_by" json:"createdBy"`
+	Updated   time.Time `xorm:"updated" json:"updated"`
+}
+
+func (t *SilenceTemplate) TableName() string {
+	return "alert_silence_template"
+}
+
+// Validate checks that the template has matchers which are valid once their variables are set.
+func (t *SilenceTemplate) Validate() error {
+	if t.Title == "" {
+		return errors.New("title is required")
+	}
+	if len(t.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	vars := make(map[string]string)
+	for _, name := range t.Variables() {
+		vars[name] = "value"
+	}
+	_, err := t.Render(vars)
+	return err
+}
+
+// Variables returns the sorted names of the variables referenced by the matchers.
+func (t *SilenceTemplate) Variables() []string {
+	result := make([]string, 0)
+	for _, m := range t.Matchers {
+		for _, match := range silenceTemplateVariable.FindAllStringSubmatch(m, -1) {
+			if !slices.Contains(result, match[1]) {
+				result = append(result, match[1])
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Render returns the matchers of the template with their variables replaced by the given values. All the variables
+// must be set.
+func (t *SilenceTemplate) Render(vars map[string]string) ([]string, error) {
+	var missing []string
+	result := make([]string, 0, len(t.Matchers))
+	for _, m := range t.Matchers {
+		rendered := silenceTemplateVariable.ReplaceAllStringFunc(m, func(s string) string {
+			name := silenceTemplateVariable.FindStringSubmatch(s)[1]
+			value, ok := vars[name]
+			if !ok {
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+				return s
+			}
+			// the values are in a quoted matcher value
+			return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+		})
+		if _, err := labels.ParseMatcher(rendered); err != nil && len(missing) == 0 {
+			return nil, fmt.Errorf("invalid matcher %q: %w", rendered, err)
+		}
+		result = append(result, rendered)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the variables %s are not set", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+// RecurringSilence silences the matching alerts on a schedule. Each occurrence starts at a time of the cron Schedule,
+// in the Timezone, and lasts for Duration. The matchers are either set with Matchers, or rendered from the template
+// TemplateUID with Variables.
+//
+// The concrete Alertmanager silence of an occurrence is created just before it starts, and the silence of the last
+// occurrence is recorded in SilenceID and SilenceStartsAt.
+type RecurringSilence struct {
+	ID          int64             `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID       int64             `xorm:"org_id" json:"-"`
+	UID         string            `xorm:"uid" json:"uid"`
+	Title       string            `xorm:"title" json:"title"`
+	Comment     string            `xorm:"comment" json:"comment,omitempty"`
+	Schedule    string            `xorm:"schedule" json:"schedule"`
+	Timezone    string            `xorm:"timezone" json:"timezone"`
+	Duration    string            `xorm:"duration" json:"duration"`
+	Matchers    []string          `xorm:"matchers" json:"matchers,omitempty"`
+	TemplateUID string            `xorm:"template_uid" json:"templateUid,omitempty"`
+	Variables   map[string]string `xorm:"variables" json:"variables,omitempty"`
+	CreatedBy   string            `xorm:"created_by" json:"createdBy"`
+	Updated     time.Time         `xorm:"updated" json:"updated"`
+
+	SilenceID       string     `xorm:"silence_id" json:"silenceId,omitempty"`
+	SilenceStartsAt *time.Time `xorm:"silence_starts_at" json:"silenceStartsAt,omitempty"`
+}
+
+func (s *RecurringSilence) TableName() string {
+	return "alert_recurring_silence"
+}
+
+// Validate checks the schedule and the matchers of the silence. The template, if any, must be the one the silence
+// references.
+func (s *RecurringSilence) Validate(template *SilenceTemplate) error {
+	if s.Title == "" {
+		return errors.New("title is required")
+	}
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	schedule, err := s.parseSchedule()
+	if err != nil {
+		return err
+	}
+	duration, err := s.ParseDuration()
+	if err != nil {
+		return err
+	}
+	// consecutive occurrences would be materialized as a single silence
+	next := schedule.Next(time.Now())
+	for i := 0; i < recurringSilenceOverlapChecks && !next.IsZero(); i++ {
+		after := schedule.Next(next)
+		if !after.IsZero() && after.Sub(next) < duration {
+			return errors.New("the occurrences of the schedule overlap, the duration must be shorter than the interval between them")
+		}
+		next = after
+	}
+	if (s.TemplateUID == "") == (len(s.Matchers) == 0) {
+		return errors.New("either matchers or templateUid must be set")
+	}
+	_, err = s.RenderMatchers(template)
+	return err
+}
+
+// ParseDuration returns the duration of the occurrences.
+func (s *RecurringSilence) ParseDuration() (time.Duration, error) {
+	d, err := time.ParseDuration(s.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s.Duration, err)
+	}
+	if d <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	return d, nil
+}
+
+func (s *RecurringSilence) parseSchedule() (cron.Schedule, error) {
+	spec := strings.TrimSpace(s.Schedule)
+	if spec == "" {
+		return nil, errors.New("schedule is required")
+	}
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, errors.New("the time zone of the schedule is set with the timezone field")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", s.Timezone)
+	}
+	schedule, err := cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", s.Timezone, spec))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// RenderMatchers returns the matchers of the silence, rendered from the template if it uses one.
+func (s *RecurringSilence) RenderMatchers(template *SilenceTemplate) ([]string, error) {
+	if s.TemplateUID == "" {
+		for _, m := range s.Matchers {
+			if _, err := labels.ParseMatcher(m); err != nil {
+				return nil, fmt.Errorf("invalid matcher %q: %w", m, err)
+			}
+		}
+		return s.Matchers, nil
+	}
+	if template == nil || template.UID != s.TemplateUID {
+		return nil, fmt.Errorf("silence template %s not found", s.TemplateUID)
+	}
+	return template.Render(s.Variables)
+}
+
+// NextOccurrence returns the start of the first occurrence whose silence was not created yet, if it's active at now
+// or starts before now+lookahead, and false otherwise.
+func (s *RecurringSilence) NextOccurrence(now time.Time, lookahead time.Duration) (time.Time, bool) {
+	schedule, err := s.parseSchedule()
+	if err != nil {
+		return time.Time{}, false
+	}
+	duration, err := s.ParseDuration()
+	if err != nil {
+		return time.Time{}, false
+	}
+	// the first start after now-duration is the start of the active occurrence, if it's not in the future
+	start := schedule.Next(now.Add(-duration))
+	if s.IsMaterialized(start) {
+		start = schedule.Next(start)
+	}
+	if start.IsZero() || !start.Before(now.Add(lookahead)) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// IsMaterialized returns true if the silence of the occurrence that starts at start was created.
+func (s *RecurringSilence) IsMaterialized(start time.Time) bool {
+	return s.SilenceStartsAt != nil && s.SilenceStartsAt.Equal(start)
+}
+
+// SilenceForOccurrence returns the Alertmanager silence of the occurrence that starts at start.
+func (s *RecurringSilence) SilenceForOccurrence(matchers []string, start time.Time) (Silence, error) {
+	duration, err := s.ParseDuration()
+	if err != nil {
+		return Silence{}, err
+	}
+	silence := Silence{}
+	for _, m := range matchers {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return Silence{}, fmt.Errorf("invalid matcher %q: %w", m, err)
+		}
+		silence.Matchers = append(silence.Matchers, &amv2.Matcher{
+			Name:    util.Pointer(matcher.Name),
+			Value:   util.Pointer(matcher.Value),
+			IsRegex: util.Pointer(matcher.Type == labels.MatchRegexp || matcher.Type == labels.MatchNotRegexp),
+			IsEqual: util.Pointer(matcher.Type == labels.MatchEqual || matcher.Type == labels.MatchRegexp),
+		})
+	}
+	comment := fmt.Sprintf("Recurring silence %s (uid=%s)", s.Title, s.UID)
+	if s.Comment != "" {
+		comment += ": " + s.Comment
+	}
+	silence.Comment = util.Pointer(comment)
+	silence.CreatedBy = util.Pointer(s.CreatedBy)
+	silence.StartsAt = util.Pointer(strfmt.DateTime(start))
+	silence.EndsAt = util.Pointer(strfmt.DateTime(start.Add(duration)))
+	return silence, nil
+}
+
+type ListRecurringSilencesQuery struct {
+	// OrgID, if set, returns only the silences of the organization.
+	OrgID int64
+	// TemplateUID, if set, returns only the silences that use the template.
+	TemplateUID string
+}
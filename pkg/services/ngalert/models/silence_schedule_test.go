@@ -0,0 +1,99 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceTemplateRender(t *testing.T) {
+	template := &SilenceTemplate{
+		Title:    "host maintenance",
+		Matchers: []string{`instance="${host}:${port}"`, `env="prod"`, `job=~"${host}.*"`},
+	}
+	require.NoError(t, template.Validate())
+	assert.Equal(t, []string{"host", "port"}, template.Variables())
+
+	matchers, err := template.Render(map[string]string{"host": `db"1`, "port": "5432"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`instance="db\"1:5432"`, `env="prod"`, `job=~"db\"1.*"`}, matchers)
+
+	_, err = template.Render(map[string]string{"host": "db1"})
+	require.ErrorContains(t, err, "port")
+
+	template.Matchers = []string{"not a matcher ${host}"}
+	require.Error(t, template.Validate())
+}
+
+func TestRecurringSilenceValidate(t *testing.T) {
+	template := &SilenceTemplate{UID: "template", Matchers: []string{`instance="${host}"`}}
+	valid := func() *RecurringSilence {
+		return &RecurringSilence{
+			Title:    "weekly maintenance",
+			Schedule: "0 22 * * 6",
+			Duration: "2h",
+			Matchers: []string{`team="backend"`},
+		}
+	}
+
+	s := valid()
+	require.NoError(t, s.Validate(nil))
+	assert.Equal(t, "UTC", s.Timezone)
+
+	s = valid()
+	s.Timezone = "Mars/Olympus"
+	require.ErrorContains(t, s.Validate(nil), "timezone")
+
+	s = valid()
+	s.Schedule = "CRON_TZ=UTC 0 22 * * 6"
+	require.Error(t, s.Validate(nil))
+
+	s = valid()
+	s.Schedule = "0 * * * *"
+	require.ErrorContains(t, s.Validate(nil), "overlap")
+
+	s = valid()
+	s.Matchers = nil
+	require.ErrorContains(t, s.Validate(nil), "either matchers or templateUid")
+
+	s = valid()
+	s.Matchers = nil
+	s.TemplateUID = "template"
+	require.ErrorContains(t, s.Validate(template), "host")
+	s.Variables = map[string]string{"host": "db1"}
+	require.NoError(t, s.Validate(template))
+}
+
+func TestRecurringSilenceNextOccurrence(t *testing.T) {
+	s := &RecurringSilence{Schedule: "0 22 * * *", Timezone: "Europe/Paris", Duration: "2h"}
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	start := time.Date(2024, 6, 1, 22, 0, 0, 0, paris)
+
+	_, ok := s.NextOccurrence(start.Add(-time.Hour), time.Minute)
+	assert.False(t, ok, "the occurrence does not start within the lookahead")
+
+	next, ok := s.NextOccurrence(start.Add(-30*time.Second), time.Minute)
+	require.True(t, ok)
+	assert.True(t, start.Equal(next))
+
+	next, ok = s.NextOccurrence(start.Add(time.Hour), time.Minute)
+	require.True(t, ok, "the occurrence is active")
+	assert.True(t, start.Equal(next))
+
+	s.SilenceStartsAt = &next
+	_, ok = s.NextOccurrence(start.Add(time.Hour), time.Minute)
+	assert.False(t, ok, "the silence of the occurrence was created")
+
+	_, ok = s.NextOccurrence(start.Add(2*time.Hour), time.Minute)
+	assert.False(t, ok, "the occurrence ended")
+
+	silence, err := s.SilenceForOccurrence([]string{`team!="backend"`}, start)
+	require.NoError(t, err)
+	require.Len(t, silence.Matchers, 1)
+	assert.False(t, *silence.Matchers[0].IsEqual)
+	assert.False(t, *silence.Matchers[0].IsRegex)
+	assert.Equal(t, start.Add(2*time.Hour).UTC(), time.Time(*silence.EndsAt).UTC())
+}
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/expr"
@@ -30,6 +31,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/enrichment"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -146,6 +148,7 @@ type AlertNG struct {
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	AlertsRouter         *sender.AlertsRouter
+	recurringSilences    *notifier.RecurringSilenceMaterializer
 	accesscontrol        accesscontrol.AccessControl
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
@@ -343,6 +346,25 @@ func (ng *AlertNG) init() error {
 
 	ng.AlertsRouter = alertsRouter
 
+	// The silences of the recurring silences are created up to two base intervals before they start.
+	ng.recurringSilences = notifier.NewRecurringSilenceMaterializer(ng.store, ng.MultiOrgAlertmanager, clk, ng.Cfg.UnifiedAlerting.BaseInterval, log.New("ngalert.notifier.recurring-silences"))
+
+	var alertsSender schedule.AlertsSender = alertsRouter
+	if ng.Cfg.UnifiedAlerting.Enrichment.Enabled {
+		pipeline, err := enrichment.NewPipelineFromSettings(ng.Cfg.UnifiedAlerting.Enrichment, enrichment.Dependencies{
+			HTTPClientProvider: ng.httpClientProvider,
+			DataSources:        ng.DataSourceCache,
+			QueryRunner:        ng.ExpressionService,
+			UserFor: func(orgID int64) identity.Requester {
+				return schedule.SchedulerUserFor(orgID)
+			},
+		}, log.New("ngalert.enrichment"))
+		if err != nil {
+			return fmt.Errorf("failed to initialize alert enrichment: %w", err)
+		}
+		alertsSender = enrichment.NewSender(pipeline, alertsRouter)
+	}
+
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService)
 	conditionValidator := eval.NewConditionValidator(ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
 
@@ -368,7 +390,7 @@ func (ng *AlertNG) init() error {
 		RuleStore:            ng.store,
 		RecordingRulesCfg:    ng.Cfg.UnifiedAlerting.RecordingRules,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
-		AlertSender:          alertsRouter,
+		AlertSender:          alertsSender,
 		Tracer:               ng.tracer,
 		Log:                  log.New("ngalert.scheduler"),
 		RecordingWriter:      ng.RecordingWriter,
@@ -381,6 +403,11 @@ func (ng *AlertNG) init() error {
 	if err != nil {
 		return err
 	}
+	stateExporter, err := createStateExporter(ng.Cfg.UnifiedAlerting.StateExport, ng.httpClientProvider, clk, ng.Metrics.GetRemoteWriterMetrics())
+	if err != nil {
+		return fmt.Errorf("failed to initialize alert state exporter: %w", err)
+	}
+
 	cfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
@@ -389,6 +416,7 @@ func (ng *AlertNG) init() error {
 		Images:                         ng.ImageService,
 		Clock:                          clk,
 		Historian:                      history,
+		StateExporter:                  stateExporter,
 		DoNotSaveNormalState:           ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingNoNormalState),
 		ApplyNoDataAndErrorToAllStates: ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingNoDataErrorExecution),
 		MaxStateSaveConcurrency:        ng.Cfg.UnifiedAlerting.MaxStateSaveConcurrency,
@@ -396,6 +424,8 @@ func (ng *AlertNG) init() error {
 		Tracer:                         ng.tracer,
 		Log:                            log.New("ngalert.state.manager"),
 		ResolvedRetention:              ng.Cfg.UnifiedAlerting.ResolvedAlertRetention,
+		// Changes to maintenance windows are picked up within one base interval.
+		MaintenanceWindows: state.NewCachedMaintenanceWindows(ng.store, clk, ng.Cfg.UnifiedAlerting.BaseInterval, log.New("ngalert.state.maintenance")),
 	}
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
@@ -454,6 +484,9 @@ func (ng *AlertNG) init() error {
 		QuotaService:         ng.QuotaService,
 		TransactionManager:   ng.store,
 		RuleStore:            ng.store,
+		RuleVersions:         ng.store,
+		Scheduler:            ng.schedule,
+		RuleRoutines:         scheduler,
 		AlertingStore:        ng.store,
 		AdminConfigStore:     ng.store,
 		ProvenanceStore:      ng.store,
@@ -472,6 +505,9 @@ func (ng *AlertNG) init() error {
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		MaintenanceWindows:   ng.store,
+		SilenceSchedules:     ng.store,
+		RecurringSilences:    ng.recurringSilences,
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 	}
@@ -519,6 +555,9 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.recurringSilences.Run(subCtx)
+	})
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -679,3 +718,23 @@ func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings s
 
 	return writer.NoopWriter{}, nil
 }
+
+func createStateExporter(settings setting.AlertStateExportSettings, httpClientProvider httpclient.Provider, clock clock.Clock, m *metrics.RemoteWriter) (state.StateExporter, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	logger := log.New("ngalert.state.exporter")
+	w, err := writer.NewPrometheusWriter(setting.RecordingRuleSettings{
+		Enabled:           true,
+		URL:               settings.URL,
+		BasicAuthUsername: settings.BasicAuthUsername,
+		BasicAuthPassword: settings.BasicAuthPassword,
+		CustomHeaders:     settings.CustomHeaders,
+		Timeout:           settings.Timeout,
+	}, httpClientProvider, clock, logger, m)
+	if err != nil {
+		return nil, err
+	}
+	return state.NewRemoteWriteStateExporter(w, settings.ExternalLabels, settings.Timeout, logger), nil
+}
@@ -59,6 +59,8 @@ type alertmanager struct {
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
 
+	rateLimiters *notificationRateLimiters
+
 	withAutogen bool
 }
 
@@ -149,6 +151,7 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		decryptFn:           decryptFn,
 		stateStore:          stateStore,
 		logger:              l,
+		rateLimiters:        newNotificationRateLimiters(cfg.UnifiedAlerting.NotificationRateLimits, m.NotificationRateLimitMetrics, l),
 
 		// TODO: Preferably, logic around autogen would be outside of the specific alertmanager implementation so that remote alertmanager will get it for free.
 		withAutogen: withAutogen,
@@ -166,6 +169,7 @@ func (am *alertmanager) Ready() bool {
 
 func (am *alertmanager) StopAndWait() {
 	am.Base.StopAndWait()
+	am.rateLimiters.stop()
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration to the database and applies it to the Alertmanager.
@@ -337,6 +341,7 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 	}
 
 	am.logger.Info("Applying new configuration to Alertmanager", "configHash", fmt.Sprintf("%x", configHash))
+	am.rateLimiters.startApply()
 	err = am.Base.ApplyConfig(AlertingConfiguration{
 		rawAlertmanagerConfig:    rawConfig,
 		configHash:               configHash,
@@ -351,6 +356,7 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 	if err != nil {
 		return false, err
 	}
+	am.rateLimiters.finishApply()
 
 	am.updateConfigMetrics(cfg)
 	return true, nil
@@ -403,7 +409,7 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	return integrations, nil
+	return am.rateLimiters.wrap(receiver.Name, integrations), nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
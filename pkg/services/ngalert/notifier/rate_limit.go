@@ -0,0 +1,271 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// digestTimeout is the maximum time a digest notification can take to be sent.
+const digestTimeout = time.Minute
+
+var (
+	// errRateLimited is returned for the notifications over the rate limit. It is retryable, so that the notification
+	// is retried and not recorded as sent.
+	errRateLimited = errors.New("notification over the rate limit")
+	// errRateLimiterStopped is returned for the notifications of an integration removed from the configuration, or
+	// of a stopped Alertmanager.
+	errRateLimiterStopped = errors.New("notification rate limiter stopped")
+)
+
+// notificationRateLimiters limits the notifications sent by the integrations of an Alertmanager according to the
+// rate limits of their contact point type. The limiters are kept when the configuration is applied again, so that
+// a configuration change does not reset the limits.
+type notificationRateLimiters struct {
+	limits  map[string]setting.NotificationRateLimit
+	metrics *metrics.NotificationRateLimitMetrics
+	logger  log.Logger
+
+	mtx       sync.Mutex
+	notifiers map[string]*rateLimitedNotifier
+	// wrapped are the keys of the notifiers wrapped since the configuration started to be applied.
+	wrapped map[string]bool
+}
+
+func newNotificationRateLimiters(limits map[string]setting.NotificationRateLimit, m *metrics.NotificationRateLimitMetrics, logger log.Logger) *notificationRateLimiters {
+	return &notificationRateLimiters{
+		limits:    limits,
+		metrics:   m,
+		logger:    logger,
+		notifiers: make(map[string]*rateLimitedNotifier),
+		wrapped:   make(map[string]bool),
+	}
+}
+
+// wrap returns the integrations of the receiver, with the integrations of the rate limited contact point types
+// wrapped in a rate limited notifier.
+func (l *notificationRateLimiters) wrap(receiverName string, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	if l == nil || len(l.limits) == 0 {
+		return integrations
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		limit, ok := l.limits[integration.Name()]
+		if !ok {
+			result = append(result, integration)
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%d", receiverName, integration.Name(), integration.Index())
+		n, ok := l.notifiers[key]
+		if !ok {
+			n = &rateLimitedNotifier{
+				limiter: rate.NewLimiter(rate.Limit(float64(limit.Count)/limit.Interval.Seconds()), limit.Count),
+				batch:   limit.Batch,
+				metrics: l.metrics,
+				logger:  l.logger.New("receiver", receiverName, "integration", integration.Name(), "index", integration.Index()),
+				digests: make(map[string]*digest),
+			}
+			l.notifiers[key] = n
+		}
+		n.setIntegration(integration)
+		l.wrapped[key] = true
+		wrapped := notify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiverName)
+		result = append(result, &wrapped)
+	}
+	return result
+}
+
+// startApply is called before a configuration is applied, see finishApply.
+func (l *notificationRateLimiters) startApply() {
+	if l == nil {
+		return
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.wrapped = make(map[string]bool)
+}
+
+// finishApply is called once a configuration is applied. It stops and removes the notifiers of the integrations which
+// were not wrapped since startApply, as they are no longer in the configuration.
+func (l *notificationRateLimiters) finishApply() {
+	if l == nil {
+		return
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for key, n := range l.notifiers {
+		if !l.wrapped[key] {
+			n.stop()
+			delete(l.notifiers, key)
+		}
+	}
+}
+
+// stop stops all the notifiers, the pending digests are not sent.
+func (l *notificationRateLimiters) stop() {
+	if l == nil {
+		return
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for key, n := range l.notifiers {
+		n.stop()
+		delete(l.notifiers, key)
+	}
+}
+
+// rateLimitedNotifier sends the notifications of an integration within its rate limit. The notifications over the
+// limit are either dropped, or their alerts are batched and sent in a digest notification of their aggregation group
+// as soon as the limit allows it.
+type rateLimitedNotifier struct {
+	limiter *rate.Limiter
+	batch   bool
+	metrics *metrics.NotificationRateLimitMetrics
+	logger  log.Logger
+
+	mtx         sync.Mutex
+	integration *alertingNotify.Integration
+	// digests are the pending digest notifications by aggregation group key.
+	digests map[string]*digest
+	stopped bool
+}
+
+// digest is a pending digest notification of an aggregation group.
+type digest struct {
+	// ctx is the notification context of the first notification of the digest, without its cancellation.
+	ctx   context.Context
+	timer *time.Timer
+	// alerts are the alerts of the digest by fingerprint, sent in the order they were added.
+	alerts map[model.Fingerprint]*types.Alert
+	order  []model.Fingerprint
+
+	// done is closed once the digest is sent, or given up, with its result.
+	done  chan struct{}
+	retry bool
+	err   error
+}
+
+func (d *digest) add(alerts []*types.Alert) {
+	for _, alert := range alerts {
+		fp := alert.Fingerprint()
+		if _, ok := d.alerts[fp]; !ok {
+			d.order = append(d.order, fp)
+		}
+		// the latest state of the alert is sent
+		d.alerts[fp] = alert
+	}
+}
+
+func (d *digest) finish(retry bool, err error) {
+	d.retry, d.err = retry, err
+	close(d.done)
+}
+
+func (n *rateLimitedNotifier) setIntegration(integration *alertingNotify.Integration) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.integration = integration
+}
+
+// Notify sends the notification if the rate limit allows it. Otherwise it returns a retryable error, so that the
+// notification is not recorded as sent, or, when the notifications are batched, it waits for the digest with the
+// alerts of the notification to be sent.
+func (n *rateLimitedNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	groupKey, _ := notify.GroupKey(ctx)
+
+	n.mtx.Lock()
+	if n.stopped {
+		n.mtx.Unlock()
+		return false, errRateLimiterStopped
+	}
+	integration := n.integration
+	// while a digest of the group is pending the notifications join it, so that they are not sent before the older ones
+	d := n.digests[groupKey]
+	if d == nil && n.limiter.Allow() {
+		n.mtx.Unlock()
+		return integration.Notify(ctx, alerts...)
+	}
+
+	if !n.batch {
+		n.mtx.Unlock()
+		n.metrics.RateLimitedNotifications.WithLabelValues(integration.Name(), "drop").Inc()
+		n.logger.Warn("Dropped notification over the rate limit", "alerts", len(alerts))
+		return true, errRateLimited
+	}
+	n.metrics.RateLimitedNotifications.WithLabelValues(integration.Name(), "batch").Inc()
+	if d == nil {
+		d = &digest{
+			ctx:    context.WithoutCancel(ctx),
+			alerts: make(map[model.Fingerprint]*types.Alert, len(alerts)),
+			done:   make(chan struct{}),
+		}
+		delay := n.limiter.Reserve().Delay()
+		d.timer = time.AfterFunc(delay, func() { n.sendDigest(groupKey, d) })
+		n.digests[groupKey] = d
+		n.logger.Debug("Batching notifications over the rate limit in a digest", "group", groupKey, "delay", delay)
+	}
+	d.add(alerts)
+	n.mtx.Unlock()
+
+	select {
+	case <-d.done:
+		return d.retry, d.err
+	case <-ctx.Done():
+		// the digest is still sent, the notification is retried and joins it if it is still pending
+		return true, fmt.Errorf("%w: the digest was not sent before the notification timed out", errRateLimited)
+	}
+}
+
+func (n *rateLimitedNotifier) sendDigest(groupKey string, d *digest) {
+	n.mtx.Lock()
+	if n.digests[groupKey] == d {
+		delete(n.digests, groupKey)
+	}
+	integration := n.integration
+	alerts := make([]*types.Alert, 0, len(d.order))
+	for _, fp := range d.order {
+		alerts = append(alerts, d.alerts[fp])
+	}
+	n.mtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(d.ctx, digestTimeout)
+	defer cancel()
+	n.metrics.DigestNotifications.WithLabelValues(integration.Name()).Inc()
+	retry, err := integration.Notify(ctx, alerts...)
+	if err != nil {
+		n.metrics.DigestNotificationFailures.WithLabelValues(integration.Name()).Inc()
+		n.logger.Error("Failed to send digest notification", "group", groupKey, "alerts", len(alerts), "error", err)
+	} else {
+		n.logger.Info("Sent digest notification", "group", groupKey, "alerts", len(alerts))
+	}
+	d.finish(retry, err)
+}
+
+// stop gives up the pending digests and rejects the next notifications.
+func (n *rateLimitedNotifier) stop() {
+	n.mtx.Lock()
+	digests := n.digests
+	n.digests, n.stopped = nil, true
+	n.mtx.Unlock()
+
+	for _, d := range digests {
+		// a digest whose timer already fired is being sent, and finished by sendDigest
+		if d.timer.Stop() {
+			d.finish(false, errRateLimiterStopped)
+		}
+	}
+}
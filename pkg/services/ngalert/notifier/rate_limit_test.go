@@ -0,0 +1,209 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type recordingNotifier struct {
+	mtx           sync.Mutex
+	notifications [][]*types.Alert
+	groupLabels   []model.LabelSet
+	err           error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.notifications = append(n.notifications, alerts)
+	lbls, _ := notify.GroupLabels(ctx)
+	n.groupLabels = append(n.groupLabels, lbls)
+	if n.err != nil {
+		return true, n.err
+	}
+	return false, nil
+}
+
+func (n *recordingNotifier) SendResolved() bool {
+	return true
+}
+
+func (n *recordingNotifier) sent() [][]*types.Alert {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.notifications
+}
+
+func rateLimitTestAlert(name, team string) *types.Alert {
+	return &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": model.LabelValue(name), "team": model.LabelValue(team)}}}
+}
+
+func newRateLimitTestIntegration(t *testing.T, limit setting.NotificationRateLimit) (*alertingNotify.Integration, *recordingNotifier, *metrics.NotificationRateLimitMetrics, *notificationRateLimiters) {
+	t.Helper()
+	recorder := &recordingNotifier{}
+	integration := notify.NewIntegration(recorder, recorder, "slack", 0, "receiver")
+	m := metrics.NewNotificationRateLimitMetrics(prometheus.NewRegistry())
+	limiters := newNotificationRateLimiters(map[string]setting.NotificationRateLimit{"slack": limit}, m, log.NewNopLogger())
+	wrapped := limiters.wrap("receiver", []*alertingNotify.Integration{&integration})
+	require.Len(t, wrapped, 1)
+	return wrapped[0], recorder, m, limiters
+}
+
+func rateLimitTestContext(group string) context.Context {
+	ctx := notify.WithGroupKey(context.Background(), group)
+	return notify.WithGroupLabels(ctx, model.LabelSet{"team": model.LabelValue(group)})
+}
+
+func pendingDigests(limiters *notificationRateLimiters) int {
+	limiters.mtx.Lock()
+	defer limiters.mtx.Unlock()
+	result := 0
+	for _, n := range limiters.notifiers {
+		n.mtx.Lock()
+		result += len(n.digests)
+		n.mtx.Unlock()
+	}
+	return result
+}
+
+func TestRateLimitedNotifier(t *testing.T) {
+	ctx := rateLimitTestContext("1")
+
+	t.Run("the notifications over the limit are dropped with a retryable error", func(t *testing.T) {
+		integration, recorder, m, _ := newRateLimitTestIntegration(t, setting.NotificationRateLimit{Count: 1, Interval: time.Hour})
+
+		_, err := integration.Notify(ctx, rateLimitTestAlert("a", "1"))
+		require.NoError(t, err)
+		retry, err := integration.Notify(ctx, rateLimitTestAlert("b", "1"))
+		require.ErrorIs(t, err, errRateLimited)
+		assert.True(t, retry)
+
+		assert.Len(t, recorder.sent(), 1)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.RateLimitedNotifications.WithLabelValues("slack", "drop")))
+	})
+
+	t.Run("batches the notifications over the limit in a digest", func(t *testing.T) {
+		integration, recorder, m, _ := newRateLimitTestIntegration(t, setting.NotificationRateLimit{Count: 1, Interval: 200 * time.Millisecond, Batch: true})
+
+		_, err := integration.Notify(ctx, rateLimitTestAlert("a", "1"))
+		require.NoError(t, err)
+
+		// a notification which times out before the digest is sent is retried, its alerts are still in the digest
+		short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		retry, err := integration.Notify(short, rateLimitTestAlert("b", "1"), rateLimitTestAlert("c", "1"))
+		require.ErrorIs(t, err, errRateLimited)
+		assert.True(t, retry)
+		require.Len(t, recorder.sent(), 1)
+
+		// the alerts are deduplicated in the digest, and the notification succeeds once the digest is sent
+		_, err = integration.Notify(ctx, rateLimitTestAlert("b", "1"))
+		require.NoError(t, err)
+
+		require.Len(t, recorder.sent(), 2)
+		digest := recorder.sent()[1]
+		require.Len(t, digest, 2)
+		assert.Equal(t, model.LabelValue("b"), digest[0].Labels["alertname"])
+		assert.Equal(t, model.LabelValue("c"), digest[1].Labels["alertname"])
+
+		recorder.mtx.Lock()
+		assert.Equal(t, model.LabelSet{"team": "1"}, recorder.groupLabels[1])
+		recorder.mtx.Unlock()
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.RateLimitedNotifications.WithLabelValues("slack", "batch")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.DigestNotifications.WithLabelValues("slack")))
+	})
+
+	t.Run("the failure of a digest is returned to its notifications", func(t *testing.T) {
+		integration, recorder, m, _ := newRateLimitTestIntegration(t, setting.NotificationRateLimit{Count: 1, Interval: 50 * time.Millisecond, Batch: true})
+		_, err := integration.Notify(ctx, rateLimitTestAlert("a", "1"))
+		require.NoError(t, err)
+
+		recorder.mtx.Lock()
+		recorder.err = errors.New("slack is down")
+		recorder.mtx.Unlock()
+		retry, err := integration.Notify(ctx, rateLimitTestAlert("b", "1"))
+		require.EqualError(t, err, "slack is down")
+		assert.True(t, retry)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.DigestNotificationFailures.WithLabelValues("slack")))
+	})
+
+	t.Run("the digests are sent by aggregation group", func(t *testing.T) {
+		integration, recorder, _, _ := newRateLimitTestIntegration(t, setting.NotificationRateLimit{Count: 1, Interval: 50 * time.Millisecond, Batch: true})
+		_, err := integration.Notify(ctx, rateLimitTestAlert("a", "1"))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for _, team := range []string{"1", "2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := integration.Notify(rateLimitTestContext(team), rateLimitTestAlert("b", team))
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		sent := recorder.sent()
+		require.Len(t, sent, 3)
+		assert.Len(t, sent[1], 1)
+		assert.Len(t, sent[2], 1)
+		assert.NotEqual(t, sent[1][0].Labels["team"], sent[2][0].Labels["team"])
+	})
+
+	t.Run("the pending digests are given up when stopped", func(t *testing.T) {
+		integration, recorder, _, limiters := newRateLimitTestIntegration(t, setting.NotificationRateLimit{Count: 1, Interval: time.Hour, Batch: true})
+		_, err := integration.Notify(ctx, rateLimitTestAlert("a", "1"))
+		require.NoError(t, err)
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := integration.Notify(ctx, rateLimitTestAlert("b", "1"))
+			result <- err
+		}()
+		require.Eventually(t, func() bool {
+			return pendingDigests(limiters) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		limiters.stop()
+		require.ErrorIs(t, <-result, errRateLimiterStopped)
+		assert.Empty(t, limiters.notifiers)
+		assert.Len(t, recorder.sent(), 1)
+	})
+
+	t.Run("the notifiers of the integrations removed from the configuration are stopped", func(t *testing.T) {
+		_, _, _, limiters := newRateLimitTestIntegration(t, setting.NotificationRateLimit{Count: 1, Interval: time.Hour, Batch: true})
+		recorder := &recordingNotifier{}
+		kept := notify.NewIntegration(recorder, recorder, "slack", 0, "other")
+
+		limiters.startApply()
+		limiters.wrap("other", []*alertingNotify.Integration{&kept})
+		limiters.finishApply()
+
+		require.Len(t, limiters.notifiers, 1)
+		assert.Contains(t, limiters.notifiers, "other/slack/0")
+	})
+
+	t.Run("integrations without a limit are not wrapped", func(t *testing.T) {
+		recorder := &recordingNotifier{}
+		integration := notify.NewIntegration(recorder, recorder, "email", 0, "receiver")
+		limiters := newNotificationRateLimiters(map[string]setting.NotificationRateLimit{"slack": {Count: 1, Interval: time.Hour}}, nil, log.NewNopLogger())
+		wrapped := limiters.wrap("receiver", []*alertingNotify.Integration{&integration})
+		assert.Same(t, &integration, wrapped[0])
+	})
+}
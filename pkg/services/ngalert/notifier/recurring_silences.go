@@ -0,0 +1,128 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type RecurringSilenceStore interface {
+	ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error)
+	ClaimRecurringSilenceOccurrence(ctx context.Context, silence *models.RecurringSilence, start time.Time) (bool, error)
+	ReleaseRecurringSilenceOccurrence(ctx context.Context, silence *models.RecurringSilence, previous models.RecurringSilence) error
+	SetRecurringSilenceSilenceID(ctx context.Context, silence *models.RecurringSilence, silenceID string) error
+}
+
+// RecurringSilenceMaterializer creates the Alertmanager silences of the occurrences of the recurring silences. The
+// silence of an occurrence is created up to two intervals before it starts, and expires on its own when it ends.
+//
+// Every replica runs the materializer, the occurrences are claimed in the database so that each silence is created
+// once.
+type RecurringSilenceMaterializer struct {
+	store    RecurringSilenceStore
+	silences SilenceStore
+	clock    clock.Clock
+	interval time.Duration
+	logger   log.Logger
+}
+
+func NewRecurringSilenceMaterializer(store RecurringSilenceStore, silences SilenceStore, clk clock.Clock, interval time.Duration, logger log.Logger) *RecurringSilenceMaterializer {
+	return &RecurringSilenceMaterializer{
+		store:    store,
+		silences: silences,
+		clock:    clk,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+func (m *RecurringSilenceMaterializer) Run(ctx context.Context) error {
+	ticker := m.clock.Ticker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.MaterializeAll(ctx)
+		}
+	}
+}
+
+// MaterializeAll creates the silences of the upcoming occurrences of all the recurring silences.
+func (m *RecurringSilenceMaterializer) MaterializeAll(ctx context.Context) {
+	silences, err := m.store.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{})
+	if err != nil {
+		m.logger.Error("Failed to list recurring silences", "error", err)
+		return
+	}
+	for _, s := range silences {
+		if err := m.Materialize(ctx, s); err != nil {
+			m.logger.Error("Failed to create the silence of the recurring silence", "org", s.OrgID, "uid", s.UID, "error", err)
+		}
+	}
+}
+
+// Materialize creates the silence of the upcoming occurrence of the recurring silence, if it was not created yet.
+func (m *RecurringSilenceMaterializer) Materialize(ctx context.Context, s *models.RecurringSilence) error {
+	start, ok := s.NextOccurrence(m.clock.Now(), 2*m.interval)
+	if !ok {
+		return nil
+	}
+	var template *models.SilenceTemplate
+	if s.TemplateUID != "" {
+		var err error
+		if template, err = m.store.GetSilenceTemplate(ctx, s.OrgID, s.TemplateUID); err != nil {
+			return err
+		}
+	}
+	matchers, err := s.RenderMatchers(template)
+	if err != nil {
+		return err
+	}
+	silence, err := s.SilenceForOccurrence(matchers, start)
+	if err != nil {
+		return err
+	}
+
+	previous := *s
+	claimed, err := m.store.ClaimRecurringSilenceOccurrence(ctx, s, start)
+	if err != nil || !claimed {
+		return err
+	}
+	silenceID, err := m.silences.CreateSilence(ctx, s.OrgID, silence)
+	if err != nil {
+		if releaseErr := m.store.ReleaseRecurringSilenceOccurrence(ctx, s, previous); releaseErr != nil {
+			m.logger.Warn("Failed to release the occurrence of the recurring silence", "org", s.OrgID, "uid", s.UID, "error", releaseErr)
+		}
+		return fmt.Errorf("failed to create silence: %w", err)
+	}
+	m.logger.Info("Created the silence of the recurring silence", "org", s.OrgID, "uid", s.UID, "silenceID", silenceID, "startsAt", start)
+	return m.store.SetRecurringSilenceSilenceID(ctx, s, silenceID)
+}
+
+// Expire expires the silence of the last occurrence of the recurring silence if it has not ended yet, e.g. because
+// the recurring silence is deleted or changed. The changed silence is materialized again on the next run.
+func (m *RecurringSilenceMaterializer) Expire(ctx context.Context, s *models.RecurringSilence) error {
+	if s.SilenceID == "" || s.SilenceStartsAt == nil {
+		return nil
+	}
+	duration, err := s.ParseDuration()
+	if err == nil && !s.SilenceStartsAt.Add(duration).After(m.clock.Now()) {
+		return nil
+	}
+	if err := m.silences.DeleteSilence(ctx, s.OrgID, s.SilenceID); err != nil && !errors.Is(err, ErrSilenceNotFound) {
+		return err
+	}
+	m.logger.Info("Expired the silence of the recurring silence", "org", s.OrgID, "uid", s.UID, "silenceID", s.SilenceID)
+	s.SilenceID = ""
+	s.SilenceStartsAt = nil
+	return nil
+}
@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type fakeRecurringSilenceStore struct {
+	silences  []*models.RecurringSilence
+	templates map[string]*models.SilenceTemplate
+	// claims is the start of the occurrences claimed by other replicas.
+	claims map[string]time.Time
+}
+
+func (f *fakeRecurringSilenceStore) ListRecurringSilences(context.Context, models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error) {
+	return f.silences, nil
+}
+
+func (f *fakeRecurringSilenceStore) GetSilenceTemplate(_ context.Context, _ int64, uid string) (*models.SilenceTemplate, error) {
+	if t, ok := f.templates[uid]; ok {
+		return t, nil
+	}
+	return nil, models.ErrSilenceTemplateNotFound.Errorf("silence template %s not found", uid)
+}
+
+func (f *fakeRecurringSilenceStore) ClaimRecurringSilenceOccurrence(_ context.Context, s *models.RecurringSilence, start time.Time) (bool, error) {
+	if claimed, ok := f.claims[s.UID]; ok && claimed.Equal(start) {
+		return false, nil
+	}
+	s.SilenceID = ""
+	s.SilenceStartsAt = &start
+	return true, nil
+}
+
+func (f *fakeRecurringSilenceStore) ReleaseRecurringSilenceOccurrence(_ context.Context, s *models.RecurringSilence, previous models.RecurringSilence) error {
+	s.SilenceID = previous.SilenceID
+	s.SilenceStartsAt = previous.SilenceStartsAt
+	return nil
+}
+
+func (f *fakeRecurringSilenceStore) SetRecurringSilenceSilenceID(_ context.Context, s *models.RecurringSilence, silenceID string) error {
+	s.SilenceID = silenceID
+	return nil
+}
+
+type failingSilenceStore struct {
+	*ngfakes.FakeSilenceStore
+}
+
+func (failingSilenceStore) CreateSilence(context.Context, int64, models.Silence) (string, error) {
+	return "", errors.New("alertmanager unavailable")
+}
+
+func TestRecurringSilenceMaterializer(t *testing.T) {
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 6, 1, 21, 59, 50, 0, time.UTC))
+	start := time.Date(2024, 6, 1, 22, 0, 0, 0, time.UTC)
+
+	newSilence := func(uid string) *models.RecurringSilence {
+		return &models.RecurringSilence{
+			OrgID:       1,
+			UID:         uid,
+			Title:       uid,
+			Schedule:    "0 22 * * *",
+			Timezone:    "UTC",
+			Duration:    "2h",
+			TemplateUID: "template",
+			Variables:   map[string]string{"host": "db1"},
+			CreatedBy:   "admin",
+		}
+	}
+	newStore := func(silences ...*models.RecurringSilence) *fakeRecurringSilenceStore {
+		return &fakeRecurringSilenceStore{
+			silences:  silences,
+			templates: map[string]*models.SilenceTemplate{"template": {UID: "template", Matchers: []string{`instance="${host}"`}}},
+			claims:    map[string]time.Time{},
+		}
+	}
+
+	t.Run("should create the silence of the upcoming occurrence once", func(t *testing.T) {
+		s := newSilence("nightly")
+		silences := &ngfakes.FakeSilenceStore{Silences: map[string]*models.Silence{}}
+		m := NewRecurringSilenceMaterializer(newStore(s), silences, clk, 10*time.Second, log.NewNopLogger())
+
+		m.MaterializeAll(context.Background())
+		m.MaterializeAll(context.Background())
+
+		require.Len(t, silences.Silences, 1)
+		created := silences.Silences[s.SilenceID]
+		require.NotNil(t, created)
+		assert.Equal(t, "instance", *created.Matchers[0].Name)
+		assert.Equal(t, "db1", *created.Matchers[0].Value)
+		assert.Equal(t, start, time.Time(*created.StartsAt).UTC())
+		assert.Equal(t, start.Add(2*time.Hour), time.Time(*created.EndsAt).UTC())
+		assert.True(t, start.Equal(*s.SilenceStartsAt))
+	})
+
+	t.Run("should not create the silence of an occurrence claimed by another replica", func(t *testing.T) {
+		s := newSilence("claimed")
+		store := newStore(s)
+		store.claims["claimed"] = start
+		silences := &ngfakes.FakeSilenceStore{Silences: map[string]*models.Silence{}}
+		m := NewRecurringSilenceMaterializer(store, silences, clk, 10*time.Second, log.NewNopLogger())
+
+		m.MaterializeAll(context.Background())
+		assert.Empty(t, silences.Silences)
+	})
+
+	t.Run("should retry the occurrence when the silence could not be created", func(t *testing.T) {
+		s := newSilence("failing")
+		store := newStore(s)
+		m := NewRecurringSilenceMaterializer(store, failingSilenceStore{}, clk, 10*time.Second, log.NewNopLogger())
+
+		require.Error(t, m.Materialize(context.Background(), s))
+		assert.Nil(t, s.SilenceStartsAt)
+	})
+
+	t.Run("should expire the silence of the active occurrence only", func(t *testing.T) {
+		s := newSilence("expired")
+		silences := &ngfakes.FakeSilenceStore{Silences: map[string]*models.Silence{}}
+		m := NewRecurringSilenceMaterializer(newStore(s), silences, clk, 10*time.Second, log.NewNopLogger())
+		require.NoError(t, m.Materialize(context.Background(), s))
+		silenceID := s.SilenceID
+
+		require.NoError(t, m.Expire(context.Background(), s))
+		assert.NotContains(t, silences.Silences, silenceID)
+		assert.Empty(t, s.SilenceID)
+		assert.Nil(t, s.SilenceStartsAt)
+
+		ended := start.Add(-24 * time.Hour)
+		s.SilenceID = "ended"
+		s.SilenceStartsAt = &ended
+		require.NoError(t, m.Expire(context.Background(), s))
+		assert.Equal(t, "ended", s.SilenceID)
+	})
+}
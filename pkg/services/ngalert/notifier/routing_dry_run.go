@@ -0,0 +1,179 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var ErrRoutingDryRunBadRequest = errutil.BadRequest("alerting.notifications.routing.badRequest")
+
+// RoutingDryRunResult is the routing of a set of labels through the notification policy tree.
+type RoutingDryRunResult struct {
+	Labels model.LabelSet `json:"labels"`
+	// Routes are the routes the alert is sent to, in the order they match.
+	Routes []RoutingDryRunRoute `json:"routes"`
+}
+
+// RoutingDryRunRoute is a route the alert matches, with the settings it inherits from its parents.
+type RoutingDryRunRoute struct {
+	// Path is the indexes of the route in the tree, e.g. [0, 2] is the third child of the first child of the root.
+	// The path of the root is empty.
+	Path     []int    `json:"path"`
+	Matchers []string `json:"matchers,omitempty"`
+	Receiver string   `json:"receiver"`
+	// GroupBy is the labels the alerts are grouped by, or ["..."] if they are grouped by all labels.
+	GroupBy        []string       `json:"groupBy"`
+	GroupLabels    model.LabelSet `json:"groupLabels"`
+	GroupKey       string         `json:"groupKey"`
+	GroupWait      model.Duration `json:"groupWait"`
+	GroupInterval  model.Duration `json:"groupInterval"`
+	RepeatInterval model.Duration `json:"repeatInterval"`
+
+	MuteTimeIntervals   []string `json:"muteTimeIntervals,omitempty"`
+	ActiveTimeIntervals []string `json:"activeTimeIntervals,omitempty"`
+	// Muted is true if the notifications of the route are muted at the time of the dry run, either by one of the
+	// mute time intervals or because none of the active time intervals is active.
+	Muted bool `json:"muted"`
+	// MutedBy are the mute time intervals that are active at the time of the dry run.
+	MutedBy []string `json:"mutedBy,omitempty"`
+}
+
+// DryRunRouting returns the routing of the labels through the notification policy tree of the organization, with
+// the mute timings checked at now. If route is set, it's used instead of the current policy tree, which allows
+// testing a change of the tree before saving it.
+func (moa *MultiOrgAlertmanager) DryRunRouting(ctx context.Context, orgID int64, route *definitions.Route, lbls model.LabelSet, now time.Time) (*RoutingDryRunResult, error) {
+	if err := lbls.Validate(); err != nil {
+		return nil, WithPublicError(ErrRoutingDryRunBadRequest.Errorf("invalid labels: %w", err))
+	}
+	cfg, err := moa.GetAlertmanagerConfiguration(ctx, orgID, route == nil)
+	if err != nil {
+		return nil, err
+	}
+	if route != nil {
+		if err := validateDryRunRoute(route, &cfg.AlertmanagerConfig); err != nil {
+			return nil, WithPublicError(ErrRoutingDryRunBadRequest.Errorf("invalid notification policy tree: %w", err))
+		}
+		cfg.AlertmanagerConfig.Route = route
+		if moa.featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting) {
+			if err := AddAutogenConfig(ctx, moa.logger, moa.configStore, orgID, &cfg.AlertmanagerConfig, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return DryRunRouting(&cfg.AlertmanagerConfig, lbls, now)
+}
+
+func validateDryRunRoute(route *definitions.Route, cfg *definitions.GettableApiAlertingConfig) error {
+	if err := route.Validate(); err != nil {
+		return err
+	}
+	receivers := map[string]struct{}{
+		"": {}, // the routes without a receiver inherit it from their parent
+	}
+	for _, r := range cfg.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	if err := route.ValidateReceivers(receivers); err != nil {
+		return err
+	}
+	timeIntervals := map[string]struct{}{}
+	for _, mt := range cfg.MuteTimeIntervals {
+		timeIntervals[mt.Name] = struct{}{}
+	}
+	for _, ti := range cfg.TimeIntervals {
+		timeIntervals[ti.Name] = struct{}{}
+	}
+	return route.ValidateMuteTimes(timeIntervals)
+}
+
+// DryRunRouting returns the routing of the labels through the policy tree of the configuration, like the Alertmanager
+// dispatcher does, with the mute timings checked at now.
+func DryRunRouting(cfg *definitions.GettableApiAlertingConfig, lbls model.LabelSet, now time.Time) (*RoutingDryRunResult, error) {
+	if cfg.Route == nil {
+		return nil, fmt.Errorf("no route present in the alertmanager configuration")
+	}
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		intervals[mt.Name] = mt.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	isActive := func(name string) bool {
+		return slices.ContainsFunc(intervals[name], func(ti timeinterval.TimeInterval) bool {
+			return ti.ContainsTime(now)
+		})
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	result := &RoutingDryRunResult{
+		Labels: lbls,
+		Routes: make([]RoutingDryRunRoute, 0),
+	}
+	for _, r := range root.Match(lbls) {
+		path, _ := routePath(root, r)
+		matched := RoutingDryRunRoute{
+			Path:                path,
+			Receiver:            r.RouteOpts.Receiver,
+			GroupBy:             make([]string, 0, len(r.RouteOpts.GroupBy)),
+			GroupLabels:         model.LabelSet{},
+			GroupWait:           model.Duration(r.RouteOpts.GroupWait),
+			GroupInterval:       model.Duration(r.RouteOpts.GroupInterval),
+			RepeatInterval:      model.Duration(r.RouteOpts.RepeatInterval),
+			MuteTimeIntervals:   r.RouteOpts.MuteTimeIntervals,
+			ActiveTimeIntervals: r.RouteOpts.ActiveTimeIntervals,
+		}
+		for _, m := range r.Matchers {
+			matched.Matchers = append(matched.Matchers, m.String())
+		}
+		if r.RouteOpts.GroupByAll {
+			matched.GroupBy = append(matched.GroupBy, "...")
+			matched.GroupLabels = lbls.Clone()
+		} else {
+			for ln := range r.RouteOpts.GroupBy {
+				matched.GroupBy = append(matched.GroupBy, string(ln))
+				if v, ok := lbls[ln]; ok {
+					matched.GroupLabels[ln] = v
+				}
+			}
+			slices.Sort(matched.GroupBy)
+		}
+		// the same key as the aggregation group of the dispatcher
+		matched.GroupKey = fmt.Sprintf("%s:%s", r.Key(), matched.GroupLabels)
+
+		for _, name := range r.RouteOpts.MuteTimeIntervals {
+			if isActive(name) {
+				matched.MutedBy = append(matched.MutedBy, name)
+			}
+		}
+		matched.Muted = len(matched.MutedBy) > 0
+		if len(r.RouteOpts.ActiveTimeIntervals) > 0 && !slices.ContainsFunc(r.RouteOpts.ActiveTimeIntervals, isActive) {
+			matched.Muted = true
+		}
+		result.Routes = append(result.Routes, matched)
+	}
+	return result, nil
+}
+
+// routePath returns the indexes of the route in the tree of the root.
+func routePath(root, route *dispatch.Route) ([]int, bool) {
+	if root == route {
+		return []int{}, true
+	}
+	for i, child := range root.Routes {
+		if path, ok := routePath(child, route); ok {
+			return append([]int{i}, path...), true
+		}
+	}
+	return nil, false
+}
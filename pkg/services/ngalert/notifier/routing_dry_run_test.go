@@ -0,0 +1,114 @@
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const routingDryRunConfig = `{
+	"route": {
+		"receiver": "default",
+		"group_by": ["alertname"],
+		"routes": [
+			{
+				"receiver": "team-a",
+				"object_matchers": [["team", "=", "a"]],
+				"group_by": ["alertname", "instance"],
+				"continue": true,
+				"mute_time_intervals": ["weekends"]
+			},
+			{
+				"receiver": "critical",
+				"object_matchers": [["severity", "=", "critical"]],
+				"group_by": ["..."],
+				"routes": [
+					{
+						"object_matchers": [["env", "=", "prod"]],
+						"active_time_intervals": ["business-hours"]
+					}
+				]
+			}
+		]
+	},
+	"time_intervals": [
+		{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]},
+		{"name": "business-hours", "time_intervals": [{"times": [{"start_time": "09:00", "end_time": "17:00"}]}]}
+	],
+	"receivers": [{"name": "default"}, {"name": "team-a"}, {"name": "critical"}]
+}`
+
+func TestDryRunRouting(t *testing.T) {
+	var cfg definitions.GettableApiAlertingConfig
+	require.NoError(t, json.Unmarshal([]byte(routingDryRunConfig), &cfg))
+	saturdayNight := time.Date(2024, 6, 1, 22, 0, 0, 0, time.UTC)
+	mondayMorning := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	t.Run("unmatched labels are routed to the root", func(t *testing.T) {
+		result, err := DryRunRouting(&cfg, model.LabelSet{"alertname": "test"}, mondayMorning)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		r := result.Routes[0]
+		assert.Empty(t, r.Path)
+		assert.Equal(t, "default", r.Receiver)
+		assert.Equal(t, []string{"alertname"}, r.GroupBy)
+		assert.Equal(t, model.LabelSet{"alertname": "test"}, r.GroupLabels)
+		assert.False(t, r.Muted)
+	})
+
+	t.Run("continue routes to the following siblings", func(t *testing.T) {
+		lbls := model.LabelSet{"alertname": "test", "team": "a", "severity": "critical", "instance": "host-1"}
+		result, err := DryRunRouting(&cfg, lbls, mondayMorning)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 2)
+
+		assert.Equal(t, []int{0}, result.Routes[0].Path)
+		assert.Equal(t, "team-a", result.Routes[0].Receiver)
+		assert.Equal(t, []string{"alertname", "instance"}, result.Routes[0].GroupBy)
+		assert.Equal(t, model.LabelSet{"alertname": "test", "instance": "host-1"}, result.Routes[0].GroupLabels)
+
+		assert.Equal(t, []int{1}, result.Routes[1].Path)
+		assert.Equal(t, "critical", result.Routes[1].Receiver)
+		assert.Equal(t, []string{"..."}, result.Routes[1].GroupBy)
+		assert.Equal(t, lbls, result.Routes[1].GroupLabels)
+		assert.NotEqual(t, result.Routes[0].GroupKey, result.Routes[1].GroupKey)
+	})
+
+	t.Run("mute time intervals are checked at the time", func(t *testing.T) {
+		lbls := model.LabelSet{"alertname": "test", "team": "a"}
+		result, err := DryRunRouting(&cfg, lbls, saturdayNight)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		assert.True(t, result.Routes[0].Muted)
+		assert.Equal(t, []string{"weekends"}, result.Routes[0].MutedBy)
+
+		result, err = DryRunRouting(&cfg, lbls, mondayMorning)
+		require.NoError(t, err)
+		assert.False(t, result.Routes[0].Muted)
+		assert.Empty(t, result.Routes[0].MutedBy)
+	})
+
+	t.Run("nested routes inherit the settings of their parents", func(t *testing.T) {
+		lbls := model.LabelSet{"alertname": "test", "severity": "critical", "env": "prod"}
+		result, err := DryRunRouting(&cfg, lbls, mondayMorning)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		r := result.Routes[0]
+		assert.Equal(t, []int{1, 0}, r.Path)
+		assert.Equal(t, "critical", r.Receiver)
+		assert.Equal(t, []string{"..."}, r.GroupBy)
+		assert.Equal(t, []string{"business-hours"}, r.ActiveTimeIntervals)
+		assert.False(t, r.Muted)
+
+		result, err = DryRunRouting(&cfg, lbls, saturdayNight)
+		require.NoError(t, err)
+		assert.True(t, result.Routes[0].Muted)
+		assert.Empty(t, result.Routes[0].MutedBy)
+	})
+}
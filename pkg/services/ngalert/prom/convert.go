@@ -0,0 +1,266 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// PrometheusRuleKind is the kind of the PrometheusRule custom resources of the Prometheus operator.
+	PrometheusRuleKind = "PrometheusRule"
+
+	queryRefID     = "A"
+	conditionRefID = "B"
+	// conditionExpression fires for every series returned by the query, like Prometheus does.
+	conditionExpression = "is_number($A) || is_nan($A) || is_inf($A)"
+	// queryTimeRange is the time range of the instant query of the converted rules.
+	queryTimeRange = 10 * time.Minute
+)
+
+var ErrInvalidPrometheusRule = errors.New("invalid PrometheusRule")
+
+// PrometheusRule is a PrometheusRule custom resource of the Prometheus operator.
+type PrometheusRule struct {
+	APIVersion string                 `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string                 `yaml:"kind" json:"kind"`
+	Metadata   PrometheusRuleMetadata `yaml:"metadata" json:"metadata"`
+	Spec       PrometheusRuleSpec     `yaml:"spec" json:"spec"`
+}
+
+type PrometheusRuleMetadata struct {
+	Name      string `yaml:"name" json:"name"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+type PrometheusRuleSpec struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+type PrometheusRuleGroup struct {
+	Name                    string               `yaml:"name" json:"name"`
+	Interval                string               `yaml:"interval,omitempty" json:"interval,omitempty"`
+	QueryOffset             string               `yaml:"query_offset,omitempty" json:"query_offset,omitempty"`
+	Limit                   int                  `yaml:"limit,omitempty" json:"limit,omitempty"`
+	PartialResponseStrategy string               `yaml:"partial_response_strategy,omitempty" json:"partial_response_strategy,omitempty"`
+	Rules                   []PrometheusRuleItem `yaml:"rules" json:"rules"`
+}
+
+type PrometheusRuleItem struct {
+	Alert         string            `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Record        string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Expr          string            `yaml:"expr" json:"expr"`
+	For           string            `yaml:"for,omitempty" json:"for,omitempty"`
+	KeepFiringFor string            `yaml:"keep_firing_for,omitempty" json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
+
+// Converter converts the rules of PrometheusRule resources to Grafana-managed rules which query a Prometheus
+// data source.
+type Converter struct {
+	DatasourceUID  string
+	DatasourceType string
+	// DefaultInterval is the evaluation interval of the groups without one.
+	DefaultInterval time.Duration
+	// BaseInterval is the interval of the scheduler, which the evaluation intervals must be a multiple of.
+	BaseInterval time.Duration
+}
+
+// ConversionResult is the rule groups converted from a PrometheusRule, and the differences between the Prometheus
+// rules and the Grafana-managed rules that the conversion could not avoid.
+type ConversionResult struct {
+	Groups   []models.AlertRuleGroup
+	Warnings []string
+}
+
+// Convert converts the groups of the PrometheusRule to rule groups of the folder. The rule groups have the names of
+// the Prometheus groups, and the alert rules have the names of the Prometheus alerts, which are made unique in the
+// folder. The rules do not have UIDs.
+func (c Converter) Convert(orgID int64, folderUID string, pr PrometheusRule) (ConversionResult, error) {
+	if pr.Kind != "" && pr.Kind != PrometheusRuleKind {
+		return ConversionResult{}, fmt.Errorf("%w: expected kind %s, got %s", ErrInvalidPrometheusRule, PrometheusRuleKind, pr.Kind)
+	}
+	result := ConversionResult{Groups: make([]models.AlertRuleGroup, 0, len(pr.Spec.Groups))}
+	warn := func(format string, args ...any) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+	}
+	groupNames := make(map[string]struct{}, len(pr.Spec.Groups))
+	titles := make(map[string]int)
+	for _, g := range pr.Spec.Groups {
+		if g.Name == "" {
+			return ConversionResult{}, fmt.Errorf("%w: group name is empty", ErrInvalidPrometheusRule)
+		}
+		if _, ok := groupNames[g.Name]; ok {
+			return ConversionResult{}, fmt.Errorf("%w: duplicate group %q", ErrInvalidPrometheusRule, g.Name)
+		}
+		groupNames[g.Name] = struct{}{}
+
+		interval, err := c.groupInterval(g, warn)
+		if err != nil {
+			return ConversionResult{}, err
+		}
+		if g.QueryOffset != "" {
+			warn("group %q: query_offset is not supported and is ignored", g.Name)
+		}
+		if g.Limit != 0 {
+			warn("group %q: limit is not supported and is ignored", g.Name)
+		}
+		if g.PartialResponseStrategy != "" {
+			warn("group %q: partial_response_strategy is not supported and is ignored", g.Name)
+		}
+
+		group := models.AlertRuleGroup{
+			Title:     g.Name,
+			FolderUID: folderUID,
+			Interval:  int64(interval.Seconds()),
+			Rules:     make([]models.AlertRule, 0, len(g.Rules)),
+		}
+		for i, r := range g.Rules {
+			rule, err := c.convertRule(r, warn)
+			if err != nil {
+				return ConversionResult{}, fmt.Errorf("%w: group %q rule %d: %w", ErrInvalidPrometheusRule, g.Name, i, err)
+			}
+			// the titles of the rules must be unique in the folder
+			titles[rule.Title]++
+			if n := titles[rule.Title]; n > 1 {
+				title := fmt.Sprintf("%s (%d)", rule.Title, n)
+				warn("group %q: rule %q is renamed to %q because the titles of the rules must be unique in the folder", g.Name, rule.Title, title)
+				rule.Title = title
+			}
+			rule.OrgID = orgID
+			rule.NamespaceUID = folderUID
+			rule.RuleGroup = g.Name
+			rule.RuleGroupIndex = i + 1
+			rule.IntervalSeconds = group.Interval
+			group.Rules = append(group.Rules, rule)
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	return result, nil
+}
+
+func (c Converter) groupInterval(g PrometheusRuleGroup, warn func(string, ...any)) (time.Duration, error) {
+	interval := c.DefaultInterval
+	if g.Interval != "" {
+		d, err := prommodel.ParseDuration(g.Interval)
+		if err != nil {
+			return 0, fmt.Errorf("%w: group %q: invalid interval: %w", ErrInvalidPrometheusRule, g.Name, err)
+		}
+		interval = time.Duration(d)
+	}
+	if interval < c.BaseInterval {
+		warn("group %q: interval %s is less than the minimum interval %s and is increased", g.Name, interval, c.BaseInterval)
+		return c.BaseInterval, nil
+	}
+	if rem := interval % c.BaseInterval; rem != 0 {
+		rounded := interval - rem + c.BaseInterval
+		warn("group %q: interval %s is not a multiple of %s and is rounded up to %s", g.Name, interval, c.BaseInterval, rounded)
+		return rounded, nil
+	}
+	return interval, nil
+}
+
+func (c Converter) convertRule(r PrometheusRuleItem, warn func(string, ...any)) (models.AlertRule, error) {
+	if (r.Alert == "") == (r.Record == "") {
+		return models.AlertRule{}, errors.New("a rule must have either alert or record")
+	}
+	if strings.TrimSpace(r.Expr) == "" {
+		return models.AlertRule{}, errors.New("expr is empty")
+	}
+	query, err := c.query(r.Expr)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	if r.Record != "" {
+		return models.AlertRule{
+			Title:  r.Record,
+			Data:   []models.AlertQuery{query},
+			Labels: r.Labels,
+			Record: &models.Record{
+				Metric: r.Record,
+				From:   queryRefID,
+			},
+			// the fields of alert rules must be valid for recording rules too
+			NoDataState:  models.OK,
+			ExecErrState: models.ErrorErrState,
+		}, nil
+	}
+
+	var forDuration time.Duration
+	if r.For != "" {
+		d, err := prommodel.ParseDuration(r.For)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("invalid for: %w", err)
+		}
+		forDuration = time.Duration(d)
+	}
+	if r.KeepFiringFor != "" {
+		warn("rule %q: keep_firing_for is not supported and is ignored", r.Alert)
+	}
+	condition, err := conditionQuery()
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	return models.AlertRule{
+		Title:       r.Alert,
+		Condition:   conditionRefID,
+		Data:        []models.AlertQuery{query, condition},
+		For:         forDuration,
+		Labels:      r.Labels,
+		Annotations: r.Annotations,
+		// Prometheus does not fire alerts when the query returns no data or fails
+		NoDataState:  models.OK,
+		ExecErrState: models.OkErrState,
+	}, nil
+}
+
+func (c Converter) query(expression string) (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId":   queryRefID,
+		"expr":    expression,
+		"instant": true,
+		"range":   false,
+		"datasource": map[string]string{
+			"type": c.DatasourceType,
+			"uid":  c.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:             queryRefID,
+		DatasourceUID:     c.DatasourceUID,
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(queryTimeRange)},
+		Model:             model,
+	}, nil
+}
+
+func conditionQuery() (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId":      conditionRefID,
+		"type":       "math",
+		"expression": conditionExpression,
+		"datasource": map[string]string{
+			"type": expr.DatasourceType,
+			"uid":  expr.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         conditionRefID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}, nil
+}
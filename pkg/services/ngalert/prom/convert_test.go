@@ -0,0 +1,131 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const prometheusRuleYAML = `
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  name: node-rules
+  namespace: monitoring
+spec:
+  groups:
+    - name: node
+      interval: 45s
+      rules:
+        - record: instance:node_cpu:rate5m
+          expr: sum by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))
+        - alert: HighCPU
+          expr: instance:node_cpu:rate5m > 0.9
+          for: 5m
+          keep_firing_for: 10m
+          labels:
+            severity: warning
+          annotations:
+            summary: "CPU of {{ $labels.instance }} is {{ $value }}"
+        - alert: HighCPU
+          expr: instance:node_cpu:rate5m > 0.99
+          labels:
+            severity: critical
+    - name: disk
+      rules:
+        - alert: DiskFull
+          expr: node_filesystem_avail_bytes == 0
+`
+
+func TestConvert(t *testing.T) {
+	var pr PrometheusRule
+	require.NoError(t, yaml.Unmarshal([]byte(prometheusRuleYAML), &pr))
+	c := Converter{DatasourceUID: "prom", DatasourceType: "prometheus", DefaultInterval: time.Minute, BaseInterval: 10 * time.Second}
+
+	result, err := c.Convert(1, "folder", pr)
+	require.NoError(t, err)
+	require.Len(t, result.Groups, 2)
+
+	node := result.Groups[0]
+	assert.Equal(t, "node", node.Title)
+	assert.Equal(t, "folder", node.FolderUID)
+	assert.Equal(t, int64(50), node.Interval)
+	require.Len(t, node.Rules, 3)
+
+	recording := node.Rules[0]
+	assert.Equal(t, &models.Record{Metric: "instance:node_cpu:rate5m", From: "A"}, recording.Record)
+	require.Len(t, recording.Data, 1)
+	assert.Equal(t, "prom", recording.Data[0].DatasourceUID)
+
+	alert := node.Rules[1]
+	assert.Equal(t, "HighCPU", alert.Title)
+	assert.Equal(t, "B", alert.Condition)
+	assert.Equal(t, 5*time.Minute, alert.For)
+	assert.Equal(t, int64(50), alert.IntervalSeconds)
+	assert.Equal(t, 2, alert.RuleGroupIndex)
+	assert.Equal(t, map[string]string{"severity": "warning"}, alert.Labels)
+	assert.Equal(t, "CPU of {{ $labels.instance }} is {{ $value }}", alert.Annotations["summary"])
+	require.Len(t, alert.Data, 2)
+	var model map[string]any
+	require.NoError(t, json.Unmarshal(alert.Data[0].Model, &model))
+	assert.Equal(t, "instance:node_cpu:rate5m > 0.9", model["expr"])
+	assert.Equal(t, true, model["instant"])
+	assert.Equal(t, "__expr__", alert.Data[1].DatasourceUID)
+
+	assert.Equal(t, "HighCPU (2)", node.Rules[2].Title)
+
+	assert.Equal(t, int64(60), result.Groups[1].Interval)
+	assert.Equal(t, []string{
+		`group "node": interval 45s is not a multiple of 10s and is rounded up to 50s`,
+		`rule "HighCPU": keep_firing_for is not supported and is ignored`,
+		`group "node": rule "HighCPU" is renamed to "HighCPU (2)" because the titles of the rules must be unique in the folder`,
+	}, result.Warnings)
+
+	for _, g := range result.Groups {
+		for _, r := range g.Rules {
+			assert.NoError(t, r.ValidateAlertRule(setting.UnifiedAlertingSettings{BaseInterval: c.BaseInterval}), r.Title)
+		}
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	c := Converter{DatasourceUID: "prom", DatasourceType: "prometheus", DefaultInterval: time.Minute, BaseInterval: 10 * time.Second}
+	testCases := []struct {
+		name string
+		pr   PrometheusRule
+	}{
+		{
+			name: "other kind",
+			pr:   PrometheusRule{Kind: "ConfigMap"},
+		},
+		{
+			name: "duplicate groups",
+			pr:   PrometheusRule{Spec: PrometheusRuleSpec{Groups: []PrometheusRuleGroup{{Name: "a"}, {Name: "a"}}}},
+		},
+		{
+			name: "rule without alert or record",
+			pr:   PrometheusRule{Spec: PrometheusRuleSpec{Groups: []PrometheusRuleGroup{{Name: "a", Rules: []PrometheusRuleItem{{Expr: "up"}}}}}},
+		},
+		{
+			name: "rule without expr",
+			pr:   PrometheusRule{Spec: PrometheusRuleSpec{Groups: []PrometheusRuleGroup{{Name: "a", Rules: []PrometheusRuleItem{{Alert: "a"}}}}}},
+		},
+		{
+			name: "invalid for",
+			pr:   PrometheusRule{Spec: PrometheusRuleSpec{Groups: []PrometheusRuleGroup{{Name: "a", Rules: []PrometheusRuleItem{{Alert: "a", Expr: "up", For: "soon"}}}}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.Convert(1, "folder", tc.pr)
+			require.ErrorIs(t, err, ErrInvalidPrometheusRule)
+		})
+	}
+}
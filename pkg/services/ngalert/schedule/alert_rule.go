@@ -12,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -40,6 +41,8 @@ type Rule interface {
 	Update(lastVersion RuleVersionAndPauseStatus) bool
 	// Type gives the type of the rule.
 	Type() ngmodels.RuleType
+	// Status returns the status of the rule's last evaluation.
+	Status() RuleStatus
 }
 
 type ruleFactoryFunc func(context.Context, *ngmodels.AlertRule) Rule
@@ -117,6 +120,10 @@ type alertRule struct {
 	ctx      context.Context
 	stopFn   util.CancelCauseFunc
 
+	evaluationTimestamp *atomic.Time
+	evaluationDuration  *atomic.Duration
+	pendingEvaluations  *atomic.Int64
+
 	appURL               *url.URL
 	disableGrafanaFolder bool
 	maxAttempts          int64
@@ -160,6 +167,9 @@ func newAlertRule(
 		updateCh:             make(chan RuleVersionAndPauseStatus),
 		ctx:                  ctx,
 		stopFn:               stop,
+		evaluationTimestamp:  atomic.NewTime(time.Time{}),
+		evaluationDuration:   atomic.NewDuration(0),
+		pendingEvaluations:   atomic.NewInt64(0),
 		appURL:               appURL,
 		disableGrafanaFolder: disableGrafanaFolder,
 		maxAttempts:          maxAttempts,
@@ -180,6 +190,16 @@ func (a *alertRule) Type() ngmodels.RuleType {
 	return ngmodels.RuleTypeAlerting
 }
 
+// Status returns the time and duration of the last evaluation of the rule. The health of alert rules is reported by
+// their states, so it is not part of the status.
+func (a *alertRule) Status() RuleStatus {
+	return RuleStatus{
+		EvaluationTimestamp: a.evaluationTimestamp.Load(),
+		EvaluationDuration:  a.evaluationDuration.Load(),
+		PendingEvaluations:  a.pendingEvaluations.Load(),
+	}
+}
+
 // eval signals the rule evaluation routine to perform the evaluation of the rule. Does nothing if the loop is stopped.
 // Before sending a message into the channel, it does non-blocking read to make sure that there is no concurrent send operation.
 // Returns a tuple where first element is
@@ -193,6 +213,8 @@ func (a *alertRule) Eval(eval *Evaluation) (bool, *Evaluation) {
 		a.logger.Error("Invalid rule sent for evaluating. Skipping", "ruleKeyToEvaluate", eval.rule.GetKey().String())
 		return false, eval
 	}
+	a.pendingEvaluations.Inc()
+	defer a.pendingEvaluations.Dec()
 	// read the channel in unblocking manner to make sure that there is no concurrent send operation.
 	var droppedMsg *Evaluation
 	select {
@@ -269,7 +291,10 @@ func (a *alertRule) Run() error {
 
 				evalStart := a.clock.Now()
 				defer func() {
-					evalDuration.Observe(a.clock.Now().Sub(evalStart).Seconds())
+					end := a.clock.Now()
+					evalDuration.Observe(end.Sub(evalStart).Seconds())
+					a.evaluationTimestamp.Store(end)
+					a.evaluationDuration.Store(end.Sub(evalStart))
 					a.evalApplied(ctx.scheduledAt)
 				}()
 
@@ -27,6 +27,8 @@ type RuleStatus struct {
 	LastError           error
 	EvaluationTimestamp time.Time
 	EvaluationDuration  time.Duration
+	// PendingEvaluations is the number of evaluations waiting for the routine of the rule to pick them up.
+	PendingEvaluations int64
 }
 
 type recordingRule struct {
@@ -39,6 +41,7 @@ type recordingRule struct {
 	lastError           *atomic.Error
 	evaluationTimestamp *atomic.Time
 	evaluationDuration  *atomic.Duration
+	pendingEvaluations  *atomic.Int64
 
 	maxAttempts int64
 
@@ -67,6 +70,7 @@ func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAtte
 		lastError:           atomic.NewError(nil),
 		evaluationTimestamp: atomic.NewTime(time.Time{}),
 		evaluationDuration:  atomic.NewDuration(0),
+		pendingEvaluations:  atomic.NewInt64(0),
 		clock:               clock,
 		evalFactory:         evalFactory,
 		cfg:                 cfg,
@@ -90,10 +94,13 @@ func (r *recordingRule) Status() RuleStatus {
 		LastError:           r.lastError.Load(),
 		EvaluationTimestamp: r.evaluationTimestamp.Load(),
 		EvaluationDuration:  r.evaluationDuration.Load(),
+		PendingEvaluations:  r.pendingEvaluations.Load(),
 	}
 }
 
 func (r *recordingRule) Eval(eval *Evaluation) (bool, *Evaluation) {
+	r.pendingEvaluations.Inc()
+	defer r.pendingEvaluations.Dec()
 	// read the channel in unblocking manner to make sure that there is no concurrent send operation.
 	var droppedMsg *Evaluation
 	select {
@@ -48,6 +48,14 @@ func (r *ruleRegistry) getOrCreate(context context.Context, item *models.AlertRu
 	return rule, !ok
 }
 
+// get returns the rule routine of the rule with the given key, if it exists.
+func (r *ruleRegistry) get(key models.AlertRuleKey) (Rule, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rule, ok := r.rules[key]
+	return rule, ok
+}
+
 func (r *ruleRegistry) exists(key models.AlertRuleKey) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -132,6 +140,13 @@ func (r *alertRulesRegistry) set(rules []*models.AlertRule, folders map[models.F
 	return d
 }
 
+// folderTitle returns the title of the folder of the rules.
+func (r *alertRulesRegistry) folderTitle(k models.FolderKey) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.folderTitles[k]
+}
+
 // update inserts or replaces a rule in the registry.
 func (r *alertRulesRegistry) update(rule *models.AlertRule) {
 	r.mu.Lock()
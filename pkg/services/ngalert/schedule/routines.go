@@ -0,0 +1,121 @@
+package schedule
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrRuleRoutineNotFound is returned when the scheduler does not run a routine for a rule.
+var ErrRuleRoutineNotFound = errors.New("rule routine not found")
+
+// RuleRoutine is the evaluation routine of a rule that is running in the scheduler.
+type RuleRoutine struct {
+	Key      ngmodels.AlertRuleKey
+	Type     ngmodels.RuleType
+	Interval time.Duration
+	IsPaused bool
+	// NextEvaluation is the time of the next tick the rule is evaluated at. It is zero if the rule is not scheduled yet.
+	NextEvaluation time.Time
+	Status         RuleStatus
+}
+
+// RuleRoutines returns the routines of the rules of the organization that are running in the scheduler, sorted by
+// rule UID. If orgID is zero, the routines of all organizations are returned.
+func (sch *schedule) RuleRoutines(orgID int64) []RuleRoutine {
+	now := sch.clock.Now()
+	keys := sch.registry.keyMap()
+	result := make([]RuleRoutine, 0, len(keys))
+	for key := range keys {
+		if orgID != 0 && key.OrgID != orgID {
+			continue
+		}
+		routine, ok := sch.registry.get(key)
+		if !ok {
+			continue
+		}
+		r := RuleRoutine{
+			Key:    key,
+			Type:   routine.Type(),
+			Status: routine.Status(),
+		}
+		if rule := sch.schedulableAlertRules.get(key); rule != nil {
+			r.Interval = time.Duration(sch.evaluationInterval(rule)) * time.Second
+			r.IsPaused = rule.IsPaused
+			r.NextEvaluation = sch.nextEvaluation(rule, now)
+		}
+		result = append(result, r)
+	}
+	slices.SortFunc(result, func(a, b RuleRoutine) int {
+		return cmp.Or(cmp.Compare(a.Key.OrgID, b.Key.OrgID), strings.Compare(a.Key.UID, b.Key.UID))
+	})
+	return result
+}
+
+// EvaluateRuleNow sends an evaluation of the current version of the rule to its routine, without waiting for the
+// next tick the rule is scheduled at. The evaluation is sent in the background, because the routine picks it up only
+// after the evaluation in progress, if any, is done.
+func (sch *schedule) EvaluateRuleNow(key ngmodels.AlertRuleKey) error {
+	routine, ok := sch.registry.get(key)
+	if !ok {
+		return ErrRuleRoutineNotFound
+	}
+	rule := sch.schedulableAlertRules.get(key)
+	if rule == nil {
+		return ErrRuleRoutineNotFound
+	}
+	var folderTitle string
+	if !sch.disableGrafanaFolder {
+		folderTitle = sch.schedulableAlertRules.folderTitle(rule.GetFolderKey())
+	}
+	sch.log.Info("Evaluating rule on demand", key.LogContext()...)
+	go routine.Eval(&Evaluation{
+		scheduledAt: sch.clock.Now(),
+		rule:        rule,
+		folderTitle: folderTitle,
+	})
+	return nil
+}
+
+// RestartRuleRoutine stops the routine of the rule and removes it from the registry. The state of the rule is kept, and
+// the next tick starts a new routine for the rule.
+func (sch *schedule) RestartRuleRoutine(key ngmodels.AlertRuleKey) error {
+	routine, ok := sch.registry.del(key)
+	if !ok {
+		return ErrRuleRoutineNotFound
+	}
+	sch.log.Info("Restarting rule routine on demand", key.LogContext()...)
+	routine.Stop(errRuleRestarted)
+	return nil
+}
+
+// evaluationInterval returns the evaluation interval of the rule in seconds, adjusted to the minimum interval like
+// processTick does.
+func (sch *schedule) evaluationInterval(rule *ngmodels.AlertRule) int64 {
+	return max(rule.IntervalSeconds, int64(sch.minRuleInterval.Seconds()))
+}
+
+// nextEvaluation returns the time of the first tick after now the rule is evaluated at, or zero if the rule is never
+// evaluated because its interval is invalid.
+func (sch *schedule) nextEvaluation(rule *ngmodels.AlertRule, now time.Time) time.Time {
+	baseInterval := int64(sch.baseInterval.Seconds())
+	interval := sch.evaluationInterval(rule)
+	if baseInterval <= 0 || interval == 0 || interval%baseInterval != 0 {
+		return time.Time{}
+	}
+	itemFrequency := interval / baseInterval
+	withInterval := *rule
+	withInterval.IntervalSeconds = interval
+	offset := jitterOffsetInTicks(&withInterval, sch.baseInterval, sch.jitterEvaluations)
+
+	tickNum := now.Unix() / baseInterval
+	next := tickNum - tickNum%itemFrequency + offset
+	if next <= tickNum {
+		next += itemFrequency
+	}
+	return time.Unix(next*baseInterval, 0)
+}
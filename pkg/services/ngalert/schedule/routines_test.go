@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeRuleRoutine struct {
+	stopReason error
+}
+
+func (r *fakeRuleRoutine) Run() error                                        { return nil }
+func (r *fakeRuleRoutine) Stop(reason error)                                 { r.stopReason = reason }
+func (r *fakeRuleRoutine) Eval(eval *Evaluation) (bool, *Evaluation)         { return true, nil }
+func (r *fakeRuleRoutine) Update(lastVersion RuleVersionAndPauseStatus) bool { return true }
+func (r *fakeRuleRoutine) Type() models.RuleType                             { return models.RuleTypeAlerting }
+func (r *fakeRuleRoutine) Status() RuleStatus {
+	return RuleStatus{EvaluationDuration: time.Second, PendingEvaluations: 1}
+}
+
+func TestNextEvaluation(t *testing.T) {
+	sch := &schedule{baseInterval: 10 * time.Second, minRuleInterval: 10 * time.Second, jitterEvaluations: JitterNever}
+	now := time.Unix(1000, 0)
+
+	t.Run("next tick the interval divides", func(t *testing.T) {
+		rule := models.RuleGen.With(models.RuleGen.WithIntervalSeconds(60)).GenerateRef()
+		assert.Equal(t, time.Unix(1020, 0), sch.nextEvaluation(rule, now))
+		assert.Equal(t, time.Unix(1080, 0), sch.nextEvaluation(rule, time.Unix(1020, 0)))
+	})
+
+	t.Run("minimum interval is applied", func(t *testing.T) {
+		rule := models.RuleGen.With(models.RuleGen.WithIntervalSeconds(1)).GenerateRef()
+		assert.Equal(t, time.Unix(1010, 0), sch.nextEvaluation(rule, now))
+	})
+
+	t.Run("invalid interval is never evaluated", func(t *testing.T) {
+		rule := models.RuleGen.With(models.RuleGen.WithIntervalSeconds(15)).GenerateRef()
+		assert.True(t, sch.nextEvaluation(rule, now).IsZero())
+	})
+}
+
+func TestRuleRoutines(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	rule := models.RuleGen.With(models.RuleGen.WithOrgID(1), models.RuleGen.WithIntervalSeconds(10)).GenerateRef()
+	other := models.RuleGen.With(models.RuleGen.WithOrgID(2)).GenerateRef()
+	sch.schedulableAlertRules.set([]*models.AlertRule{rule, other}, nil)
+	routine := &fakeRuleRoutine{}
+	factory := ruleFactoryFunc(func(context.Context, *models.AlertRule) Rule { return routine })
+	sch.registry.getOrCreate(context.Background(), rule, factory)
+	sch.registry.getOrCreate(context.Background(), other, factory)
+
+	t.Run("lists the routines of the organization", func(t *testing.T) {
+		routines := sch.RuleRoutines(1)
+		require.Len(t, routines, 1)
+		assert.Equal(t, rule.GetKey(), routines[0].Key)
+		assert.Equal(t, 10*time.Second, routines[0].Interval)
+		assert.False(t, routines[0].NextEvaluation.IsZero())
+		assert.Equal(t, int64(1), routines[0].Status.PendingEvaluations)
+
+		assert.Len(t, sch.RuleRoutines(0), 2)
+	})
+
+	t.Run("evaluating a rule that is not running fails", func(t *testing.T) {
+		key := models.RuleGen.GenerateRef().GetKey()
+		require.ErrorIs(t, sch.EvaluateRuleNow(key), ErrRuleRoutineNotFound)
+	})
+
+	t.Run("restart stops the routine and removes it from the registry", func(t *testing.T) {
+		require.NoError(t, sch.RestartRuleRoutine(rule.GetKey()))
+		assert.ErrorIs(t, routine.stopReason, errRuleRestarted)
+		assert.False(t, sch.registry.exists(rule.GetKey()))
+		require.ErrorIs(t, sch.RestartRuleRoutine(rule.GetKey()), ErrRuleRoutineNotFound)
+	})
+}
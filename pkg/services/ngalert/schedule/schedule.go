@@ -30,6 +30,8 @@ type ScheduleService interface {
 	// Run the scheduler until the context is canceled or the scheduler returns
 	// an error. The scheduler is terminated when this function returns.
 	Run(context.Context) error
+	// UpdateAlertRule notifies the scheduler that a rule was updated.
+	UpdateAlertRule(rule *ngmodels.AlertRule)
 }
 
 // retryDelay represents how long to wait between each failed rule evaluation.
@@ -176,6 +178,30 @@ func (sch *schedule) Rules() ([]*ngmodels.AlertRule, map[ngmodels.FolderKey]stri
 	return sch.schedulableAlertRules.all()
 }
 
+// UpdateAlertRule replaces the scheduled version of a rule by the given one and notifies its evaluation routine, so
+// that a change takes effect without waiting for the next tick to fetch the rules. Rules that are not scheduled yet
+// are picked up by the next tick.
+func (sch *schedule) UpdateAlertRule(rule *ngmodels.AlertRule) {
+	key := rule.GetKey()
+	if sch.schedulableAlertRules.get(key) == nil {
+		return
+	}
+	sch.schedulableAlertRules.update(rule)
+	routine, ok := sch.registry.get(key)
+	if !ok {
+		return
+	}
+	var folderTitle string
+	if !sch.disableGrafanaFolder {
+		folderTitle = sch.schedulableAlertRules.folderTitle(rule.GetFolderKey())
+	}
+	sch.log.Debug("Rule has been updated. Notifying evaluation routine", append(key.LogContext(), "version", rule.Version)...)
+	go routine.Update(RuleVersionAndPauseStatus{
+		Fingerprint: ruleWithFolder{rule: rule, folderTitle: folderTitle}.Fingerprint(),
+		IsPaused:    rule.IsPaused,
+	})
+}
+
 // deleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
 func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	for _, key := range keys {
@@ -0,0 +1,133 @@
+package state
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+const (
+	// AlertsMetricName is the name of the series of the pending and firing alerts, like the ALERTS series of Prometheus.
+	AlertsMetricName = "ALERTS"
+	// AlertsForStateMetricName is the name of the series of the time the alerts entered their state, like the
+	// ALERTS_FOR_STATE series of Prometheus.
+	AlertsForStateMetricName = "ALERTS_FOR_STATE"
+
+	alertStateLabel = "alertstate"
+)
+
+// PointsWriter writes points to a Prometheus remote write endpoint.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []writer.Point, orgID int64) error
+}
+
+// RemoteWriteStateExporter writes the states of the alert rules as the ALERTS and ALERTS_FOR_STATE series, like
+// Prometheus does for its alerting rules, so that external tools can consume them.
+type RemoteWriteStateExporter struct {
+	writer         PointsWriter
+	externalLabels map[string]string
+	timeout        time.Duration
+	log            log.Logger
+}
+
+func NewRemoteWriteStateExporter(w PointsWriter, externalLabels map[string]string, timeout time.Duration, l log.Logger) *RemoteWriteStateExporter {
+	return &RemoteWriteStateExporter{
+		writer:         w,
+		externalLabels: externalLabels,
+		timeout:        timeout,
+		log:            l,
+	}
+}
+
+// Export writes the series of the states of an evaluation of a rule in the background.
+func (e *RemoteWriteStateExporter) Export(ctx context.Context, evaluatedAt time.Time, states StateTransitions) {
+	points := StatesToPoints(evaluatedAt, states, e.externalLabels)
+	if len(points) == 0 {
+		return
+	}
+	orgID, ruleUID := states[0].OrgID, states[0].AlertRuleUID
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.timeout)
+		defer cancel()
+		if err := e.writer.WritePoints(ctx, points, orgID); err != nil {
+			e.log.FromContext(ctx).Error("Failed to export alert states", "org_id", orgID, "rule_uid", ruleUID, "error", err)
+		}
+	}()
+}
+
+// StatesToPoints returns the points of the series of the states. The pending and firing states have an ALERTS series
+// with the value 1, and an ALERTS_FOR_STATE series with the time they entered their state in seconds. The series of
+// the states that are no longer pending or firing, or changed from pending to firing, get a stale marker.
+func StatesToPoints(evaluatedAt time.Time, states StateTransitions, externalLabels map[string]string) []writer.Point {
+	stale := math.Float64frombits(value.StaleNaN)
+	points := make([]writer.Point, 0, 2*len(states))
+	for _, s := range states {
+		current, active := exportedAlertState(s.State.State)
+		previous, wasActive := exportedAlertState(s.PreviousState)
+		if !active && !wasActive {
+			continue
+		}
+		lbls := exportedLabels(s.Labels, externalLabels)
+		if wasActive && (!active || previous != current) {
+			points = append(points, alertsPoint(lbls, previous, evaluatedAt, stale))
+		}
+		if !active {
+			points = append(points, alertsForStatePoint(lbls, evaluatedAt, stale))
+			continue
+		}
+		points = append(points,
+			alertsPoint(lbls, current, evaluatedAt, 1),
+			alertsForStatePoint(lbls, evaluatedAt, float64(s.StartsAt.Unix())),
+		)
+	}
+	return points
+}
+
+// exportedAlertState returns the alertstate label of the state, and false if the state is not exported. The states
+// that are sent to the Alertmanager as firing alerts are firing.
+func exportedAlertState(s eval.State) (string, bool) {
+	switch s {
+	case eval.Pending:
+		return "pending", true
+	case eval.Alerting, eval.NoData, eval.Error:
+		return "firing", true
+	default:
+		return "", false
+	}
+}
+
+// exportedLabels returns the labels of the state without the internal labels, and the external labels that the state
+// does not have.
+func exportedLabels(stateLabels map[string]string, externalLabels map[string]string) map[string]string {
+	result := make(map[string]string, len(stateLabels)+len(externalLabels))
+	for name, v := range externalLabels {
+		result[name] = v
+	}
+	for name, v := range stateLabels {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		result[name] = v
+	}
+	return result
+}
+
+func alertsPoint(lbls map[string]string, alertState string, t time.Time, v float64) writer.Point {
+	withState := make(map[string]string, len(lbls)+1)
+	for name, value := range lbls {
+		withState[name] = value
+	}
+	withState[alertStateLabel] = alertState
+	return writer.Point{Name: AlertsMetricName, Labels: withState, Metric: writer.Metric{T: t, V: v}}
+}
+
+func alertsForStatePoint(lbls map[string]string, t time.Time, v float64) writer.Point {
+	return writer.Point{Name: AlertsForStateMetricName, Labels: lbls, Metric: writer.Metric{T: t, V: v}}
+}
@@ -0,0 +1,75 @@
+package state
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+func TestStatesToPoints(t *testing.T) {
+	evaluatedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	startsAt := evaluatedAt.Add(-5 * time.Minute)
+	labels := data.Labels{"alertname": "HighCPU", "instance": "host-1", "__alert_rule_uid__": "rule-uid"}
+	externalLabels := map[string]string{"cluster": "eu", "instance": "ignored"}
+	transition := func(previous, current eval.State) StateTransition {
+		return StateTransition{
+			State:         &State{OrgID: 1, AlertRuleUID: "rule-uid", State: current, Labels: labels, StartsAt: startsAt},
+			PreviousState: previous,
+		}
+	}
+	exported := map[string]string{"alertname": "HighCPU", "instance": "host-1", "cluster": "eu"}
+	withState := func(alertState string) map[string]string {
+		result := map[string]string{alertStateLabel: alertState}
+		for k, v := range exported {
+			result[k] = v
+		}
+		return result
+	}
+	isStale := func(p writer.Point) bool {
+		return value.IsStaleNaN(p.Metric.V)
+	}
+
+	t.Run("firing and pending states are exported", func(t *testing.T) {
+		points := StatesToPoints(evaluatedAt, StateTransitions{transition(eval.Alerting, eval.Alerting)}, externalLabels)
+		require.Len(t, points, 2)
+		assert.Equal(t, writer.Point{Name: AlertsMetricName, Labels: withState("firing"), Metric: writer.Metric{T: evaluatedAt, V: 1}}, points[0])
+		assert.Equal(t, writer.Point{Name: AlertsForStateMetricName, Labels: exported, Metric: writer.Metric{T: evaluatedAt, V: float64(startsAt.Unix())}}, points[1])
+
+		points = StatesToPoints(evaluatedAt, StateTransitions{transition(eval.Normal, eval.Pending)}, externalLabels)
+		require.Len(t, points, 2)
+		assert.Equal(t, withState("pending"), points[0].Labels)
+	})
+
+	t.Run("normal states are not exported", func(t *testing.T) {
+		points := StatesToPoints(evaluatedAt, StateTransitions{transition(eval.Normal, eval.Normal)}, externalLabels)
+		assert.Empty(t, points)
+	})
+
+	t.Run("resolved states get stale markers", func(t *testing.T) {
+		points := StatesToPoints(evaluatedAt, StateTransitions{transition(eval.Alerting, eval.Normal)}, externalLabels)
+		require.Len(t, points, 2)
+		assert.Equal(t, AlertsMetricName, points[0].Name)
+		assert.Equal(t, withState("firing"), points[0].Labels)
+		assert.True(t, isStale(points[0]))
+		assert.Equal(t, AlertsForStateMetricName, points[1].Name)
+		assert.True(t, isStale(points[1]))
+	})
+
+	t.Run("pending series gets a stale marker when the state fires", func(t *testing.T) {
+		points := StatesToPoints(evaluatedAt, StateTransitions{transition(eval.Pending, eval.Alerting)}, externalLabels)
+		require.Len(t, points, 3)
+		assert.Equal(t, withState("pending"), points[0].Labels)
+		assert.True(t, isStale(points[0]))
+		assert.Equal(t, withState("firing"), points[1].Labels)
+		assert.Equal(t, 1.0, points[1].Metric.V)
+		assert.False(t, math.IsNaN(points[2].Metric.V))
+	})
+}
@@ -0,0 +1,95 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MaintenanceWindowProvider returns the maintenance windows of an organization that are active at the given time.
+type MaintenanceWindowProvider interface {
+	ActiveMaintenanceWindows(ctx context.Context, orgID int64, at time.Time) []*models.MaintenanceWindow
+}
+
+type MaintenanceWindowStore interface {
+	ListMaintenanceWindows(ctx context.Context, query models.ListMaintenanceWindowsQuery) ([]*models.MaintenanceWindow, error)
+}
+
+type noopMaintenanceWindows struct{}
+
+func (noopMaintenanceWindows) ActiveMaintenanceWindows(context.Context, int64, time.Time) []*models.MaintenanceWindow {
+	return nil
+}
+
+type orgMaintenanceWindows struct {
+	windows   []*models.MaintenanceWindow
+	fetchedAt time.Time
+}
+
+// CachedMaintenanceWindows loads maintenance windows from the store and keeps them in memory for a short time,
+// so that evaluating many rules of the same organization does not hit the database every time.
+type CachedMaintenanceWindows struct {
+	store  MaintenanceWindowStore
+	clock  clock.Clock
+	ttl    time.Duration
+	logger log.Logger
+
+	mtx   sync.Mutex
+	byOrg map[int64]orgMaintenanceWindows
+}
+
+func NewCachedMaintenanceWindows(store MaintenanceWindowStore, clk clock.Clock, ttl time.Duration, logger log.Logger) *CachedMaintenanceWindows {
+	return &CachedMaintenanceWindows{
+		store:  store,
+		clock:  clk,
+		ttl:    ttl,
+		logger: logger,
+		byOrg:  make(map[int64]orgMaintenanceWindows),
+	}
+}
+
+func (c *CachedMaintenanceWindows) ActiveMaintenanceWindows(ctx context.Context, orgID int64, at time.Time) []*models.MaintenanceWindow {
+	windows := c.orgWindows(ctx, orgID)
+	result := make([]*models.MaintenanceWindow, 0, len(windows))
+	for _, w := range windows {
+		if w.IsActive(at) {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+func (c *CachedMaintenanceWindows) orgWindows(ctx context.Context, orgID int64) []*models.MaintenanceWindow {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.clock.Now()
+	if cached, ok := c.byOrg[orgID]; ok && now.Sub(cached.fetchedAt) < c.ttl {
+		return cached.windows
+	}
+
+	windows, err := c.store.ListMaintenanceWindows(ctx, models.ListMaintenanceWindowsQuery{OrgID: orgID})
+	if err != nil {
+		c.logger.Error("Failed to load maintenance windows, using the previously loaded ones", "org", orgID, "error", err)
+		return c.byOrg[orgID].windows
+	}
+	active := make([]*models.MaintenanceWindow, 0, len(windows))
+	for _, w := range windows {
+		// Windows that already ended will never match again.
+		if !w.EndsAt.After(now) {
+			continue
+		}
+		if _, err := w.LabelMatchers(); err != nil {
+			c.logger.Warn("Ignoring maintenance window with invalid matchers", "org", orgID, "window", w.UID, "error", err)
+			continue
+		}
+		active = append(active, w)
+	}
+	c.byOrg[orgID] = orgMaintenanceWindows{windows: active, fetchedAt: now}
+	return active
+}
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeMaintenanceWindowStore struct {
+	windows []*models.MaintenanceWindow
+	err     error
+	calls   int
+}
+
+func (f *fakeMaintenanceWindowStore) ListMaintenanceWindows(_ context.Context, query models.ListMaintenanceWindowsQuery) ([]*models.MaintenanceWindow, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var result []*models.MaintenanceWindow
+	for _, w := range f.windows {
+		if w.OrgID == query.OrgID {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func TestCachedMaintenanceWindows(t *testing.T) {
+	clk := clock.NewMock()
+	now := clk.Now()
+	store := &fakeMaintenanceWindowStore{windows: []*models.MaintenanceWindow{
+		{OrgID: 1, UID: "past", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour), FolderUIDs: []string{"f"}},
+		{OrgID: 1, UID: "current", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), FolderUIDs: []string{"f"}},
+		{OrgID: 1, UID: "future", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), FolderUIDs: []string{"f"}},
+		{OrgID: 2, UID: "other-org", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), FolderUIDs: []string{"f"}},
+	}}
+	c := NewCachedMaintenanceWindows(store, clk, time.Minute, log.NewNopLogger())
+
+	active := c.ActiveMaintenanceWindows(context.Background(), 1, now)
+	require.Len(t, active, 1)
+	require.Equal(t, "current", active[0].UID)
+
+	active = c.ActiveMaintenanceWindows(context.Background(), 1, now.Add(90*time.Minute))
+	require.Len(t, active, 1)
+	require.Equal(t, "future", active[0].UID)
+	require.Equal(t, 1, store.calls, "windows should be served from the cache")
+
+	t.Run("keeps previous windows if the store fails", func(t *testing.T) {
+		clk.Add(2 * time.Minute)
+		store.err = errors.New("db is down")
+		active := c.ActiveMaintenanceWindows(context.Background(), 1, clk.Now())
+		require.Len(t, active, 1)
+		require.Equal(t, 2, store.calls)
+	})
+}
+
+type staticMaintenanceWindows []*models.MaintenanceWindow
+
+func (s staticMaintenanceWindows) ActiveMaintenanceWindows(context.Context, int64, time.Time) []*models.MaintenanceWindow {
+	return s
+}
+
+func TestManagerMaintenanceWindows(t *testing.T) {
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", NamespaceUID: "folder", IntervalSeconds: 10}
+	window := &models.MaintenanceWindow{OrgID: 1, UID: "mw1", FolderUIDs: []string{"folder"}, Matchers: []string{`team="a"`}}
+	st := &Manager{
+		maintenanceWindows: staticMaintenanceWindows{window},
+		ResendDelay:        ResendDelay,
+	}
+
+	alerting := StateTransition{State: &State{State: eval.Alerting, Labels: data.Labels{"team": "a"}}, PreviousState: eval.Pending}
+	otherTeam := StateTransition{State: &State{State: eval.Alerting, Labels: data.Labels{"team": "b"}}, PreviousState: eval.Pending}
+	normal := StateTransition{State: &State{State: eval.Normal, Labels: data.Labels{"team": "a"}}, PreviousState: eval.Normal}
+	noData := StateTransition{State: &State{State: eval.NoData, StateReason: models.StateReasonNoData, Labels: data.Labels{"team": "a"}}, PreviousState: eval.Normal}
+
+	evaluatedAt := time.Now()
+	transitions := []StateTransition{alerting, otherTeam, normal, noData}
+	st.applyMaintenanceWindows(context.Background(), rule, evaluatedAt, transitions)
+
+	require.Equal(t, models.SuppressedStateReason("mw1"), alerting.StateReason)
+	require.Empty(t, otherTeam.StateReason)
+	require.Empty(t, normal.StateReason)
+	require.Equal(t, models.ConcatReasons(models.StateReasonNoData, models.SuppressedStateReason("mw1")), noData.StateReason)
+
+	toSend := st.updateLastSentAt(transitions, evaluatedAt)
+	require.Len(t, toSend, 1)
+	require.Equal(t, data.Labels{"team": "b"}, toSend[0].Labels)
+
+	t.Run("keeps a single reason during the window and clears it once it ends", func(t *testing.T) {
+		st := &Manager{
+			maintenanceWindows: staticMaintenanceWindows{window},
+			ResendDelay:        ResendDelay,
+		}
+		// the state and its reason are kept in the cache between evaluations
+		state := &State{State: eval.Alerting, Labels: data.Labels{"team": "a"}}
+		evaluate := func(at time.Time) (StateTransition, StateTransitions) {
+			transition := StateTransition{State: state, PreviousState: state.State, PreviousStateReason: state.StateReason}
+			transitions := []StateTransition{transition}
+			st.applyMaintenanceWindows(context.Background(), rule, at, transitions)
+			return transition, st.updateLastSentAt(transitions, at)
+		}
+
+		for i := 0; i < 2; i++ {
+			transition, toSend := evaluate(evaluatedAt.Add(time.Duration(i) * time.Minute))
+			require.Equal(t, models.SuppressedStateReason("mw1"), state.StateReason)
+			require.Equal(t, i == 0, transition.Changed(), "only the first evaluation in the window changes the state")
+			require.Empty(t, toSend)
+		}
+
+		st.maintenanceWindows = staticMaintenanceWindows{}
+		transition, toSend := evaluate(evaluatedAt.Add(2 * time.Minute))
+		require.Empty(t, state.StateReason)
+		require.True(t, transition.Changed())
+		require.Len(t, toSend, 1)
+	})
+}
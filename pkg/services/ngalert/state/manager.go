@@ -51,13 +51,15 @@ type Manager struct {
 	instanceStore InstanceStore
 	images        ImageCapturer
 	historian     Historian
+	exporter      StateExporter
 	externalURL   *url.URL
 
 	doNotSaveNormalState           bool
 	applyNoDataAndErrorToAllStates bool
 	rulesPerRuleGroupLimit         int64
 
-	persister StatePersister
+	persister          StatePersister
+	maintenanceWindows MaintenanceWindowProvider
 }
 
 type ManagerCfg struct {
@@ -67,6 +69,8 @@ type ManagerCfg struct {
 	Images        ImageCapturer
 	Clock         clock.Clock
 	Historian     Historian
+	// StateExporter is optional. If set, the states are exported after each evaluation.
+	StateExporter StateExporter
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -81,6 +85,9 @@ type ManagerCfg struct {
 	// Duration for which a resolved alert state transition will continue to be sent to the Alertmanager.
 	ResolvedRetention time.Duration
 
+	// MaintenanceWindows is optional. If set, states that match an active maintenance window are suppressed.
+	MaintenanceWindows MaintenanceWindowProvider
+
 	Tracer tracing.Tracer
 	Log    log.Logger
 }
@@ -102,15 +109,21 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		instanceStore:                  cfg.InstanceStore,
 		images:                         cfg.Images,
 		historian:                      cfg.Historian,
+		exporter:                       cfg.StateExporter,
 		clock:                          cfg.Clock,
 		externalURL:                    cfg.ExternalURL,
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
 		applyNoDataAndErrorToAllStates: cfg.ApplyNoDataAndErrorToAllStates,
 		rulesPerRuleGroupLimit:         cfg.RulesPerRuleGroupLimit,
 		persister:                      statePersister,
+		maintenanceWindows:             cfg.MaintenanceWindows,
 		tracer:                         cfg.Tracer,
 	}
 
+	if m.maintenanceWindows == nil {
+		m.maintenanceWindows = noopMaintenanceWindows{}
+	}
+
 	if m.applyNoDataAndErrorToAllStates {
 		m.log.Info("Running in alternative execution of Error/NoData mode")
 	}
@@ -283,6 +296,9 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 		}
 	}
 	logger.Info("Rules state was reset", "states", len(states))
+	if st.exporter != nil {
+		st.exporter.Export(ctx, now, transitions)
+	}
 
 	return transitions
 }
@@ -331,6 +347,7 @@ func (st *Manager) ProcessEvalResults(
 	logger := st.log.FromContext(ctx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
 	states := st.setNextStateForRule(ctx, alertRule, results, extraLabels, logger)
+	st.applyMaintenanceWindows(ctx, alertRule, evaluatedAt, states)
 
 	staleStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
 	span.AddEvent("results processed", trace.WithAttributes(
@@ -351,6 +368,9 @@ func (st *Manager) ProcessEvalResults(
 	if st.historian != nil {
 		st.historian.Record(ctx, history_model.NewRuleMeta(alertRule, logger), allChanges)
 	}
+	if st.exporter != nil {
+		st.exporter.Export(ctx, evaluatedAt, allChanges)
+	}
 
 	// Optional callback intended for sending the states to an alertmanager.
 	// Some uses ,such as backtesting or the testing api, do not send.
@@ -366,6 +386,10 @@ func (st *Manager) ProcessEvalResults(
 func (st *Manager) updateLastSentAt(states StateTransitions, evaluatedAt time.Time) StateTransitions {
 	var result StateTransitions
 	for _, t := range states {
+		// Notifications for states suppressed by a maintenance window are withheld.
+		if ngModels.IsSuppressedStateReason(t.StateReason) {
+			continue
+		}
 		if t.NeedsSending(st.ResendDelay, st.ResolvedRetention) {
 			t.LastSentAt = &evaluatedAt
 			result = append(result, t)
@@ -374,6 +398,31 @@ func (st *Manager) updateLastSentAt(states StateTransitions, evaluatedAt time.Ti
 	return result
 }
 
+// applyMaintenanceWindows marks the states that are not Normal and match an active maintenance window as suppressed.
+// The UID of the window is recorded in the state reason so that it is visible in the state history. The states keep
+// their reason between evaluations, so the reason of the previous evaluation is removed first: it stays the same
+// while the window is active, and is cleared once it ends.
+func (st *Manager) applyMaintenanceWindows(ctx context.Context, alertRule *ngModels.AlertRule, evaluatedAt time.Time, transitions []StateTransition) {
+	windows := st.maintenanceWindows.ActiveMaintenanceWindows(ctx, alertRule.OrgID, evaluatedAt)
+	for _, t := range transitions {
+		t.State.StateReason = ngModels.WithoutSuppressedStateReason(t.State.StateReason)
+		if t.State.State == eval.Normal {
+			continue
+		}
+		for _, w := range windows {
+			if !w.Matches(alertRule, t.State.Labels) {
+				continue
+			}
+			reason := ngModels.SuppressedStateReason(w.UID)
+			if t.State.StateReason != "" {
+				reason = ngModels.ConcatReasons(t.State.StateReason, reason)
+			}
+			t.State.StateReason = reason
+			break
+		}
+	}
+}
+
 func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels, logger log.Logger) []StateTransition {
 	if st.applyNoDataAndErrorToAllStates && results.IsNoData() && (alertRule.NoDataState == ngModels.Alerting || alertRule.NoDataState == ngModels.OK || alertRule.NoDataState == ngModels.KeepLast) { // If it is no data, check the mapping and switch all results to the new state
 		// aggregate UID of datasources that returned NoData into one and provide as auxiliary info via annotationa. See: https://github.com/grafana/grafana/issues/88184
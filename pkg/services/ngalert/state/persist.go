@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
@@ -30,6 +31,12 @@ type Historian interface {
 	Record(ctx context.Context, rule history_model.RuleMeta, states []StateTransition) <-chan error
 }
 
+// StateExporter exports the states of the alert rules, e.g. as time series.
+type StateExporter interface {
+	// Export exports the states of an evaluation of a rule. It must not block.
+	Export(ctx context.Context, evaluatedAt time.Time, states StateTransitions)
+}
+
 // ImageCapturer captures images.
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
@@ -553,14 +553,15 @@ func shouldTakeImage(state, previousState eval.State, previousImage *models.Imag
 		state == eval.Alerting && previousImage == nil
 }
 
-// takeImage takes an image for the alert rule. It returns nil if screenshots are disabled or
-// the rule is not associated with a dashboard panel.
+// takeImage takes an image for the alert rule. It returns nil if screenshots are disabled, the
+// rule is not associated with a dashboard panel, or the organization has spent its screenshot budget.
 func takeImage(ctx context.Context, s ImageCapturer, r *models.AlertRule) (*models.Image, error) {
 	img, err := s.NewImage(ctx, r)
 	if err != nil {
 		if errors.Is(err, screenshot.ErrScreenshotsUnavailable) ||
 			errors.Is(err, models.ErrNoDashboard) ||
-			errors.Is(err, models.ErrNoPanel) {
+			errors.Is(err, models.ErrNoPanel) ||
+			errors.Is(err, models.ErrImageRenderBudgetExceeded) {
 			return nil, nil
 		}
 		return nil, err
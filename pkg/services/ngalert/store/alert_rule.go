@@ -153,6 +153,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 	return ids, st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		newRules := make([]ngmodels.AlertRule, 0, len(rules))
 		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+		author := versionAuthor(ctx)
 		for i := range rules {
 			r := rules[i]
 			if r.UID == "" {
@@ -189,6 +190,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				Labels:               r.Labels,
 				Record:               r.Record,
 				NotificationSettings: r.NotificationSettings,
+				CreatedBy:            author,
 			})
 		}
 		if len(newRules) > 0 {
@@ -226,6 +228,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 		}
 
 		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+		author := versionAuthor(ctx)
 		for i := range rules {
 			// We do indexed access way to avoid "G601: Implicit memory aliasing in for loop."
 			// Doing this will be unnecessary with go 1.22 https://stackoverflow.com/a/68247837/767660
@@ -257,6 +260,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				RuleGroup:            r.New.RuleGroup,
 				RuleGroupIndex:       r.New.RuleGroupIndex,
 				ParentVersion:        parentVersion,
+				RestoredFrom:         r.RestoredFrom,
 				Version:              r.New.Version + 1,
 				Created:              r.New.Updated,
 				Condition:            r.New.Condition,
@@ -270,6 +274,7 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				CreatedBy:            author,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -281,6 +286,28 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 	})
 }
 
+// versionAuthor returns the login of the user who changes the rules, or an empty string for the changes made by
+// Grafana itself, such as file provisioning.
+func versionAuthor(ctx context.Context) string {
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return ""
+	}
+	return user.GetLogin()
+}
+
+// GetAlertRuleVersions returns the versions of the rule, the latest first.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	versions := make([]*ngmodels.AlertRuleVersion, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("version").Find(&versions)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get versions of alert rule %s: %w", ruleUID, err)
+	}
+	return versions, nil
+}
+
 // preventIntermediateUniqueConstraintViolations prevents unique constraint violations caused by an intermediate update.
 // The uniqueness constraint for titles within an org+folder is enforced on every update within a transaction
 // instead of on commit (deferred constraint). This means that there could be a set of updates that will throw
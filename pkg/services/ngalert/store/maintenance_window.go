@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ListMaintenanceWindows returns the maintenance windows of an organization ordered by start time.
+func (st DBstore) ListMaintenanceWindows(ctx context.Context, query models.ListMaintenanceWindowsQuery) ([]*models.MaintenanceWindow, error) {
+	result := make([]*models.MaintenanceWindow, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.ActiveAt != nil {
+			q = q.And("starts_at <= ? AND ends_at > ?", query.ActiveAt.UTC(), query.ActiveAt.UTC())
+		}
+		return q.Asc("starts_at").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return result, nil
+}
+
+// GetMaintenanceWindow returns the maintenance window with the given UID or models.ErrMaintenanceWindowNotFound.
+func (st DBstore) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&window)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance window: %w", err)
+		}
+		if !exists {
+			return models.ErrMaintenanceWindowNotFound.Errorf("maintenance window %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// SaveMaintenanceWindow inserts the window if it has no ID, and updates it otherwise.
+// A UID is generated for new windows that do not have one.
+func (st DBstore) SaveMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		window.Updated = TimeNow().UTC()
+		window.StartsAt = window.StartsAt.UTC()
+		window.EndsAt = window.EndsAt.UTC()
+		if window.ID == 0 {
+			if window.UID == "" {
+				window.UID = util.GenerateShortUID()
+			}
+			if _, err := sess.Insert(window); err != nil {
+				return fmt.Errorf("failed to insert maintenance window: %w", err)
+			}
+			return nil
+		}
+		updated, err := sess.ID(window.ID).Where("org_id = ?", window.OrgID).AllCols().Update(window)
+		if err != nil {
+			return fmt.Errorf("failed to update maintenance window: %w", err)
+		}
+		if updated == 0 {
+			return models.ErrMaintenanceWindowNotFound.Errorf("maintenance window %s not found", window.UID)
+		}
+		return nil
+	})
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window with the given UID.
+func (st DBstore) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.MaintenanceWindow{})
+		if err != nil {
+			return fmt.Errorf("failed to delete maintenance window: %w", err)
+		}
+		if deleted == 0 {
+			return models.ErrMaintenanceWindowNotFound.Errorf("maintenance window %s not found", uid)
+		}
+		return nil
+	})
+}
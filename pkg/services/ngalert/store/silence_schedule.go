@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ListSilenceTemplates returns the silence templates of an organization ordered by title.
+func (st DBstore) ListSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error) {
+	result := make([]*models.SilenceTemplate, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("title").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list silence templates: %w", err)
+	}
+	return result, nil
+}
+
+// GetSilenceTemplate returns the silence template with the given UID or models.ErrSilenceTemplateNotFound.
+func (st DBstore) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error) {
+	var template models.SilenceTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&template)
+		if err != nil {
+			return fmt.Errorf("failed to get silence template: %w", err)
+		}
+		if !exists {
+			return models.ErrSilenceTemplateNotFound.Errorf("silence template %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// SaveSilenceTemplate inserts the template if it has no ID, and updates it otherwise.
+// A UID is generated for new templates that do not have one.
+func (st DBstore) SaveSilenceTemplate(ctx context.Context, template *models.SilenceTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		template.Updated = TimeNow().UTC()
+		if template.ID == 0 {
+			if template.UID == "" {
+				template.UID = util.GenerateShortUID()
+			}
+			if _, err := sess.Insert(template); err != nil {
+				return fmt.Errorf("failed to insert silence template: %w", err)
+			}
+			return nil
+		}
+		updated, err := sess.ID(template.ID).Where("org_id = ?", template.OrgID).AllCols().Update(template)
+		if err != nil {
+			return fmt.Errorf("failed to update silence template: %w", err)
+		}
+		if updated == 0 {
+			return models.ErrSilenceTemplateNotFound.Errorf("silence template %s not found", template.UID)
+		}
+		return nil
+	})
+}
+
+// DeleteSilenceTemplate deletes the silence template with the given UID. It returns models.ErrSilenceTemplateInUse
+// if recurring silences use the template.
+func (st DBstore) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		used, err := sess.Where("org_id = ? AND template_uid = ?", orgID, uid).Count(&models.RecurringSilence{})
+		if err != nil {
+			return fmt.Errorf("failed to count the recurring silences of the silence template: %w", err)
+		}
+		if used > 0 {
+			return models.ErrSilenceTemplateInUse.Errorf("silence template %s is used by %d recurring silences", uid, used)
+		}
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.SilenceTemplate{})
+		if err != nil {
+			return fmt.Errorf("failed to delete silence template: %w", err)
+		}
+		if deleted == 0 {
+			return models.ErrSilenceTemplateNotFound.Errorf("silence template %s not found", uid)
+		}
+		return nil
+	})
+}
+
+// ListRecurringSilences returns the recurring silences matching the query ordered by title.
+func (st DBstore) ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error) {
+	result := make([]*models.RecurringSilence, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(&models.RecurringSilence{})
+		if query.OrgID != 0 {
+			q = q.Where("org_id = ?", query.OrgID)
+		}
+		if query.TemplateUID != "" {
+			q = q.And("template_uid = ?", query.TemplateUID)
+		}
+		return q.Asc("title").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring silences: %w", err)
+	}
+	return result, nil
+}
+
+// GetRecurringSilence returns the recurring silence with the given UID or models.ErrRecurringSilenceNotFound.
+func (st DBstore) GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error) {
+	var silence models.RecurringSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&silence)
+		if err != nil {
+			return fmt.Errorf("failed to get recurring silence: %w", err)
+		}
+		if !exists {
+			return models.ErrRecurringSilenceNotFound.Errorf("recurring silence %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// SaveRecurringSilence inserts the silence if it has no ID, and updates it otherwise.
+// A UID is generated for new silences that do not have one.
+func (st DBstore) SaveRecurringSilence(ctx context.Context, silence *models.RecurringSilence) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		silence.Updated = TimeNow().UTC()
+		if silence.ID == 0 {
+			if silence.UID == "" {
+				silence.UID = util.GenerateShortUID()
+			}
+			if _, err := sess.Insert(silence); err != nil {
+				return fmt.Errorf("failed to insert recurring silence: %w", err)
+			}
+			return nil
+		}
+		updated, err := sess.ID(silence.ID).Where("org_id = ?", silence.OrgID).AllCols().Update(silence)
+		if err != nil {
+			return fmt.Errorf("failed to update recurring silence: %w", err)
+		}
+		if updated == 0 {
+			return models.ErrRecurringSilenceNotFound.Errorf("recurring silence %s not found", silence.UID)
+		}
+		return nil
+	})
+}
+
+// DeleteRecurringSilence deletes the recurring silence with the given UID.
+func (st DBstore) DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.RecurringSilence{})
+		if err != nil {
+			return fmt.Errorf("failed to delete recurring silence: %w", err)
+		}
+		if deleted == 0 {
+			return models.ErrRecurringSilenceNotFound.Errorf("recurring silence %s not found", uid)
+		}
+		return nil
+	})
+}
+
+// ClaimRecurringSilenceOccurrence records that the silence of the occurrence starting at start is being created,
+// and returns false if it was already claimed, e.g. by another replica in a high availability setup.
+func (st DBstore) ClaimRecurringSilenceOccurrence(ctx context.Context, silence *models.RecurringSilence, start time.Time) (bool, error) {
+	start = start.UTC()
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		updated, err := sess.ID(silence.ID).
+			Where("silence_starts_at IS NULL OR silence_starts_at <> ?", start).
+			Cols("silence_id", "silence_starts_at").
+			Update(&models.RecurringSilence{SilenceStartsAt: &start})
+		if err != nil {
+			return fmt.Errorf("failed to claim the occurrence of the recurring silence: %w", err)
+		}
+		claimed = updated > 0
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if claimed {
+		silence.SilenceID = ""
+		silence.SilenceStartsAt = &start
+	}
+	return claimed, nil
+}
+
+// SetRecurringSilenceSilenceID records the ID of the Alertmanager silence created for the claimed occurrence.
+func (st DBstore) SetRecurringSilenceSilenceID(ctx context.Context, silence *models.RecurringSilence, silenceID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.ID(silence.ID).Cols("silence_id").Update(&models.RecurringSilence{SilenceID: silenceID}); err != nil {
+			return fmt.Errorf("failed to set the silence of the recurring silence: %w", err)
+		}
+		silence.SilenceID = silenceID
+		return nil
+	})
+}
+
+// ReleaseRecurringSilenceOccurrence restores the previous state of a silence whose claimed occurrence could not be
+// created, so that it is retried.
+func (st DBstore) ReleaseRecurringSilenceOccurrence(ctx context.Context, silence *models.RecurringSilence, previous models.RecurringSilence) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.ID(silence.ID).Cols("silence_id", "silence_starts_at").Update(&models.RecurringSilence{
+			SilenceID:       previous.SilenceID,
+			SilenceStartsAt: previous.SilenceStartsAt,
+		}); err != nil {
+			return fmt.Errorf("failed to release the occurrence of the recurring silence: %w", err)
+		}
+		silence.SilenceID = previous.SilenceID
+		silence.SilenceStartsAt = previous.SilenceStartsAt
+		return nil
+	})
+}
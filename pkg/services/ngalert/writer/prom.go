@@ -174,14 +174,20 @@ func createAuthOpts(username, password string) *httpclient.BasicAuthOptions {
 
 // Write writes the given frames to the Prometheus remote write endpoint.
 func (w PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
-	l := w.logger.FromContext(ctx)
-	lvs := []string{fmt.Sprint(orgID), backendType}
-
 	points, err := PointsFromFrames(name, t, frames, extraLabels)
 	if err != nil {
 		return err
 	}
 
+	w.logger.FromContext(ctx).Debug("Writing metric", "name", name)
+	return w.WritePoints(ctx, points, orgID)
+}
+
+// WritePoints writes the given points to the Prometheus remote write endpoint.
+func (w PrometheusWriter) WritePoints(ctx context.Context, points []Point, orgID int64) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), backendType}
+
 	series := make([]promremote.TimeSeries, 0, len(points))
 	for _, p := range points {
 		series = append(series, promremote.TimeSeries{
@@ -193,7 +199,6 @@ func (w PrometheusWriter) Write(ctx context.Context, name string, t time.Time, f
 		})
 	}
 
-	l.Debug("Writing metric", "name", name)
 	writeStart := w.clock.Now()
 	res, writeErr := w.client.WriteTimeSeries(ctx, series, promremote.WriteOptions{})
 	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
@@ -55,7 +55,8 @@ func (ns *NotificationService) Send(ctx context.Context, msg *Message) (int, err
 }
 
 func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Message, error) {
-	if !ns.Cfg.Smtp.Enabled {
+	smtp := ns.smtpSettings()
+	if !smtp.Enabled {
 		return nil, ErrSmtpNotEnabled
 	}
 
@@ -67,7 +68,7 @@ func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Messag
 	setDefaultTemplateData(ns.Cfg, data, nil)
 
 	body := make(map[string]string)
-	for _, contentType := range ns.Cfg.Smtp.ContentTypes {
+	for _, contentType := range smtp.ContentTypes {
 		fileExtension, err := getFileExtensionByContentType(contentType)
 		if err != nil {
 			return nil, err
@@ -110,7 +111,7 @@ func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Messag
 		}
 	}
 
-	addr := mail.Address{Name: ns.Cfg.Smtp.FromName, Address: ns.Cfg.Smtp.FromAddress}
+	addr := mail.Address{Name: smtp.FromName, Address: smtp.FromAddress}
 	return &Message{
 		To:            cmd.To,
 		SingleEmail:   cmd.SingleEmail,
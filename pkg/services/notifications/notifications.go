@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/Masterminds/sprig/v3"
 
@@ -113,6 +114,26 @@ type NotificationService struct {
 	mailer       Mailer
 	log          log.Logger
 	store        TempUserStore
+
+	// smtp are the SMTP settings reloaded at runtime, the emails are built with Cfg.Smtp until they are reloaded
+	smtpMtx sync.RWMutex
+	smtp    *setting.SmtpSettings
+}
+
+// SetSmtpSettings replaces the SMTP settings used to build the emails, when they are reloaded.
+func (ns *NotificationService) SetSmtpSettings(cfg setting.SmtpSettings) {
+	ns.smtpMtx.Lock()
+	defer ns.smtpMtx.Unlock()
+	ns.smtp = &cfg
+}
+
+func (ns *NotificationService) smtpSettings() setting.SmtpSettings {
+	ns.smtpMtx.RLock()
+	defer ns.smtpMtx.RUnlock()
+	if ns.smtp != nil {
+		return *ns.smtp
+	}
+	return ns.Cfg.Smtp
 }
 
 func (ns *NotificationService) Run(ctx context.Context) error {
@@ -314,7 +335,7 @@ func (ns *NotificationService) signUpStartedHandler(ctx context.Context, evt *ev
 }
 
 func (ns *NotificationService) signUpCompletedHandler(ctx context.Context, evt *events.SignUpCompleted) error {
-	if evt.Email == "" || !ns.Cfg.Smtp.SendWelcomeEmailOnSignUp {
+	if evt.Email == "" || !ns.smtpSettings().SendWelcomeEmailOnSignUp {
 		return nil
 	}
 
@@ -152,6 +152,29 @@ func TestSendEmailSync(t *testing.T) {
 		require.Empty(t, mailer.Sent)
 	})
 
+	t.Run("When SMTP settings are reloaded", func(t *testing.T) {
+		cfg := createSmtpConfig()
+		ns, mailer, err := createSutWithConfig(t, bus, cfg)
+		require.NoError(t, err)
+		reloaded := cfg.Smtp
+		reloaded.FromAddress = "reloaded@address.com"
+		ns.SetSmtpSettings(reloaded)
+		cmd := &SendEmailCommandSync{
+			SendEmailCommand: SendEmailCommand{
+				Subject:  "subject",
+				To:       []string{"asdf@grafana.com"},
+				Template: "welcome_on_signup",
+			},
+		}
+
+		err = ns.SendEmailCommandHandlerSync(context.Background(), cmd)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, mailer.Sent)
+		require.Contains(t, mailer.Sent[len(mailer.Sent)-1].From, "reloaded@address.com")
+		require.Equal(t, "from@address.com", cfg.Smtp.FromAddress)
+	})
+
 	t.Run("When invalid content type in configuration", func(t *testing.T) {
 		cfg := createSmtpConfig()
 		cfg.Smtp.ContentTypes = append(cfg.Smtp.ContentTypes, "multipart/form-data")
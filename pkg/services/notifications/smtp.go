@@ -11,6 +11,7 @@ import (
 	"net/textproto"
 	"strconv"
 	"strings"
+	"sync"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -26,6 +27,10 @@ var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/notifications"
 
 type SmtpClient struct {
 	cfg setting.SmtpSettings
+
+	// settings are the settings reloaded at runtime, the messages are sent with a copy of the client using them
+	mtx      sync.RWMutex
+	settings *setting.SmtpSettings
 }
 
 func ProvideSmtpService(cfg *setting.Cfg) (Mailer, error) {
@@ -40,7 +45,22 @@ func NewSmtpClient(cfg setting.SmtpSettings) (*SmtpClient, error) {
 	return client, nil
 }
 
+// SetSettings replaces the settings of the client, when they are reloaded. The messages being sent keep the
+// previous settings.
+func (sc *SmtpClient) SetSettings(cfg setting.SmtpSettings) {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	sc.settings = &cfg
+}
+
 func (sc *SmtpClient) Send(ctx context.Context, messages ...*Message) (int, error) {
+	sc.mtx.RLock()
+	settings := sc.settings
+	sc.mtx.RUnlock()
+	if settings != nil {
+		return (&SmtpClient{cfg: *settings}).Send(ctx, messages...)
+	}
+
 	ctx, span := tracer.Start(ctx, "notifications.SmtpClient.Send",
 		trace.WithAttributes(attribute.Int("messages", len(messages))),
 	)
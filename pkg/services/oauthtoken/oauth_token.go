@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -35,6 +36,12 @@ var (
 	ErrCouldntRefreshToken = errors.New("could not refresh token")
 )
 
+const (
+	backgroundRefreshLockName = "oauth-token-background-refresh"
+	// backgroundRefreshBatchSize is the maximum number of tokens refreshed in the background at each interval
+	backgroundRefreshBatchSize = 500
+)
+
 type Service struct {
 	Cfg             *setting.Cfg
 	SocialService   social.Service
@@ -43,6 +50,8 @@ type Service struct {
 	tracer          tracing.Tracer
 
 	tokenRefreshDuration *prometheus.HistogramVec
+	tokenRefreshFailures *prometheus.CounterVec
+	userTokenService     auth.UserTokenService
 }
 
 //go:generate mockery --name OAuthTokenService --structname MockService --outpkg oauthtokentest --filename service_mock.go --output ./oauthtokentest/
@@ -55,14 +64,110 @@ type OAuthTokenService interface {
 }
 
 func ProvideService(socialService social.Service, authInfoService login.AuthInfoService, cfg *setting.Cfg, registerer prometheus.Registerer,
-	serverLockService *serverlock.ServerLockService, tracer tracing.Tracer) *Service {
+	serverLockService *serverlock.ServerLockService, tracer tracing.Tracer, userTokenService auth.UserTokenService) *Service {
 	return &Service{
 		AuthInfoService:      authInfoService,
 		Cfg:                  cfg,
 		SocialService:        socialService,
 		serverLock:           serverLockService,
 		tokenRefreshDuration: newTokenRefreshDurationMetric(registerer),
+		tokenRefreshFailures: newTokenRefreshFailuresMetric(registerer),
 		tracer:               tracer,
+		userTokenService:     userTokenService,
+	}
+}
+
+// IsDisabled returns true when the access tokens are not refreshed in the background.
+func (o *Service) IsDisabled() bool {
+	return !o.Cfg.OAuthRefreshTokenBackgroundEnabled
+}
+
+// Run refreshes the access tokens about to expire in the background, so the requests don't wait for the refresh.
+// A single instance refreshes the tokens at each interval.
+func (o *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.Cfg.OAuthRefreshTokenBackgroundInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := o.serverLock.LockAndExecute(ctx, backgroundRefreshLockName, o.Cfg.OAuthRefreshTokenBackgroundInterval, o.refreshExpiringTokens)
+			if err != nil {
+				logger.Error("Failed to refresh the expiring OAuth tokens", "error", err)
+			}
+		}
+	}
+}
+
+func (o *Service) refreshExpiringTokens(ctx context.Context) {
+	ctx, span := o.tracer.Start(ctx, "oauthtoken.refreshExpiringTokens")
+	defer span.End()
+
+	authInfos, err := o.AuthInfoService.GetExpiringOAuthAuthInfo(ctx, &login.GetExpiringOAuthAuthInfoQuery{
+		ExpiresBefore: time.Now().Add(o.Cfg.OAuthRefreshTokenBackgroundWindow),
+		Limit:         backgroundRefreshBatchSize,
+	})
+	if err != nil {
+		logger.Error("Failed to get the expiring OAuth tokens", "error", err)
+		return
+	}
+
+	for _, authInfo := range authInfos {
+		if ctx.Err() != nil {
+			return
+		}
+		o.refreshExpiringToken(ctx, authInfo.UserId, authInfo.AuthModule)
+	}
+}
+
+// refreshExpiringToken refreshes the access token of a user before it expires. It holds the same server lock
+// as TryTokenRefresh, so the instances don't refresh the token of a user at the same time: the refresh token
+// could be rotated by the provider, which would invalidate the one used by the other instance.
+func (o *Service) refreshExpiringToken(ctx context.Context, userID int64, authModule string) {
+	ctxLogger := logger.FromContext(ctx).New("userID", userID)
+
+	oauthInfo := o.SocialService.GetOAuthInfoProvider(strings.TrimPrefix(authModule, "oauth_"))
+	if oauthInfo == nil || !oauthInfo.UseRefreshToken {
+		return
+	}
+
+	// the token of a user who is logged out is refreshed when it's used, once they log in again
+	userIDParam := userID
+	sessions, err := o.userTokenService.ActiveTokenCount(ctx, &userIDParam)
+	if err != nil {
+		ctxLogger.Debug("Failed to count the active sessions", "error", err)
+		return
+	}
+	if sessions == 0 {
+		return
+	}
+
+	lockErr := o.serverLock.LockExecuteAndRelease(ctx, refreshLockKey(userID), 30*time.Second, func(ctx context.Context) {
+		authInfo, err := o.AuthInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: userID})
+		if err != nil {
+			ctxLogger.Debug("Failed to fetch oauth entry", "error", err)
+			return
+		}
+		// the user has logged in another way since, or the token was refreshed by another instance
+		if authInfo.AuthModule != authModule || authInfo.OAuthExpiry.After(time.Now().Add(o.Cfg.OAuthRefreshTokenBackgroundWindow)) {
+			return
+		}
+		if err := checkOAuthRefreshToken(authInfo); err != nil {
+			return
+		}
+
+		persistedToken := buildOAuthTokenFromAuthInfo(authInfo)
+		// the token has not expired yet, so it is only refreshed without an access token
+		persistedToken.AccessToken = ""
+		if _, err := o.refreshOAuthToken(ctx, authInfo, persistedToken); err != nil {
+			ctxLogger.Warn("Failed to refresh the expiring OAuth token", "provider", authModule, "error", err)
+		}
+	})
+	if lockErr != nil {
+		// the token is being refreshed by another request
+		ctxLogger.Debug("Skipping the refresh of the expiring OAuth token", "error", lockErr)
 	}
 }
 
@@ -193,7 +298,7 @@ func (o *Service) TryTokenRefresh(ctx context.Context, usr identity.Requester) (
 		return nil, nil
 	}
 
-	lockKey := fmt.Sprintf("oauth-refresh-token-%d", userID)
+	lockKey := refreshLockKey(userID)
 
 	lockTimeConfig := serverlock.LockTimeConfig{
 		MaxInterval: 30 * time.Second,
@@ -298,8 +403,6 @@ func (o *Service) tryGetOrRefreshOAuthToken(ctx context.Context, authInfo *login
 		trace.WithAttributes(attribute.Int64("userID", authInfo.UserId)))
 	defer span.End()
 
-	ctxLogger := logger.FromContext(ctx).New("userID", authInfo.UserId)
-
 	if err := checkOAuthRefreshToken(authInfo); err != nil {
 		return nil, err
 	}
@@ -309,6 +412,15 @@ func (o *Service) tryGetOrRefreshOAuthToken(ctx context.Context, authInfo *login
 		return persistedToken, nil
 	}
 
+	return o.refreshOAuthToken(ctx, authInfo, persistedToken)
+}
+
+// refreshOAuthToken gets a new token from the provider with the refresh token of the persisted token, and
+// stores it. When the provider rejects the refresh token, the stored tokens are invalidated, the other
+// errors, such as a provider which is not reachable, keep them.
+func (o *Service) refreshOAuthToken(ctx context.Context, authInfo *login.UserAuth, persistedToken *oauth2.Token) (*oauth2.Token, error) {
+	ctxLogger := logger.FromContext(ctx).New("userID", authInfo.UserId)
+
 	authProvider := authInfo.AuthModule
 	connect, err := o.SocialService.GetConnector(authProvider)
 	if err != nil {
@@ -330,12 +442,15 @@ func (o *Service) tryGetOrRefreshOAuthToken(ctx context.Context, authInfo *login
 	o.tokenRefreshDuration.WithLabelValues(authProvider, fmt.Sprintf("%t", err == nil)).Observe(duration.Seconds())
 
 	if err != nil {
+		o.tokenRefreshFailures.WithLabelValues(authProvider).Inc()
 		ctxLogger.Error("Failed to retrieve oauth access token",
 			"provider", authInfo.AuthModule, "userId", authInfo.UserId, "error", err)
 
-		// token refresh failed, invalidate the old token
-		if err := o.InvalidateOAuthTokens(ctx, authInfo); err != nil {
-			ctxLogger.Warn("Failed to invalidate OAuth tokens", "id", authInfo.Id, "error", err)
+		// the refresh token was revoked or has expired, invalidate the old token
+		if isInvalidGrant(err) {
+			if err := o.InvalidateOAuthTokens(ctx, authInfo); err != nil {
+				ctxLogger.Warn("Failed to invalidate OAuth tokens", "id", authInfo.Id, "error", err)
+			}
 		}
 
 		return nil, err
@@ -388,6 +503,30 @@ func newTokenRefreshDurationMetric(registerer prometheus.Registerer) *prometheus
 	return tokenRefreshDuration
 }
 
+func newTokenRefreshFailuresMetric(registerer prometheus.Registerer) *prometheus.CounterVec {
+	tokenRefreshFailures := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "oauth",
+		Name:      "token_refresh_failures_total",
+		Help:      "Number of failed refreshes of access tokens using refresh token",
+	},
+		[]string{"auth_provider"})
+	if registerer != nil {
+		registerer.MustRegister(tokenRefreshFailures)
+	}
+	return tokenRefreshFailures
+}
+
+// isInvalidGrant returns true when the provider rejected the refresh token, see RFC 6749, section 5.2.
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
+func refreshLockKey(userID int64) string {
+	return fmt.Sprintf("oauth-refresh-token-%d", userID)
+}
+
 // tokensEq checks for OAuth2 token equivalence given the fields of the struct Grafana is interested in
 func tokensEq(t1, t2 *oauth2.Token) bool {
 	t1IdToken, ok1 := t1.Extra("id_token").(string)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/authlib/claims"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/oauth2"
@@ -20,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/login/social/socialtest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoimpl"
@@ -131,6 +133,7 @@ func setupOAuthTokenService(t *testing.T) (*Service, *FakeAuthInfoStore, *social
 		AuthInfoService:      authInfoService,
 		serverLock:           serverlock.ProvideService(store, tracing.InitializeTracerForTest()),
 		tokenRefreshDuration: newTokenRefreshDurationMetric(prometheus.NewRegistry()),
+		tokenRefreshFailures: newTokenRefreshFailuresMetric(prometheus.NewRegistry()),
 		tracer:               tracing.InitializeTracerForTest(),
 	}, authInfoStore, socialConnector
 }
@@ -358,6 +361,7 @@ func TestService_TryTokenRefresh(t *testing.T) {
 				prometheus.NewRegistry(),
 				env.serverLock,
 				tracing.InitializeTracerForTest(),
+				authtest.NewFakeUserAuthTokenService(),
 			)
 
 			// token refresh
@@ -518,6 +522,7 @@ func TestOAuthTokenSync_tryGetOrRefreshOAuthToken(t *testing.T) {
 				prometheus.NewRegistry(),
 				env.serverLock,
 				tracing.InitializeTracerForTest(),
+				authtest.NewFakeUserAuthTokenService(),
 			)
 
 			token, err := env.service.tryGetOrRefreshOAuthToken(context.Background(), tt.usr)
@@ -530,3 +535,118 @@ func TestOAuthTokenSync_tryGetOrRefreshOAuthToken(t *testing.T) {
 		})
 	}
 }
+
+type errTokenSource struct {
+	err error
+}
+
+func (s errTokenSource) Token() (*oauth2.Token, error) {
+	return nil, s.err
+}
+
+func TestService_refreshExpiringTokens(t *testing.T) {
+	now := time.Now()
+	newToken := &oauth2.Token{
+		AccessToken:  "new_access_token",
+		RefreshToken: "new_refresh_token",
+		Expiry:       now.Add(time.Hour),
+		TokenType:    "Bearer",
+	}
+	expiring := &login.UserAuth{
+		UserId:            1234,
+		AuthModule:        login.GenericOAuthModule,
+		OAuthAccessToken:  "access_token",
+		OAuthRefreshToken: "refresh_token",
+		OAuthExpiry:       now.Add(2 * time.Minute),
+		OAuthTokenType:    "Bearer",
+	}
+
+	setup := func(t *testing.T, authInfo *login.UserAuth, sessions int64) (*Service, *socialtest.MockSocialConnector, *[]*login.UpdateAuthInfoCommand) {
+		t.Helper()
+		userTokenService := authtest.NewFakeUserAuthTokenService()
+		userTokenService.ActiveTokenCountProvider = func(ctx context.Context, userID *int64) (int64, error) {
+			return sessions, nil
+		}
+		updates := []*login.UpdateAuthInfoCommand{}
+		socialConnector := &socialtest.MockSocialConnector{}
+		cfg := setting.NewCfg()
+		cfg.OAuthRefreshTokenBackgroundWindow = 5 * time.Minute
+
+		service := ProvideService(
+			&socialtest.FakeSocialService{
+				ExpectedConnector:        socialConnector,
+				ExpectedAuthInfoProvider: &social.OAuthInfo{UseRefreshToken: true},
+			},
+			&authinfotest.FakeService{
+				ExpectedUserAuths: []*login.UserAuth{{UserId: authInfo.UserId, AuthModule: authInfo.AuthModule}},
+				ExpectedUserAuth:  authInfo,
+				UpdateAuthInfoFn: func(ctx context.Context, cmd *login.UpdateAuthInfoCommand) error {
+					updates = append(updates, cmd)
+					return nil
+				},
+			},
+			cfg,
+			prometheus.NewRegistry(),
+			serverlock.ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest()),
+			tracing.InitializeTracerForTest(),
+			userTokenService,
+		)
+		return service, socialConnector, &updates
+	}
+
+	t.Run("should refresh the tokens about to expire", func(t *testing.T) {
+		service, socialConnector, updates := setup(t, expiring, 1)
+		socialConnector.On("TokenSource", mock.Anything, mock.MatchedBy(func(token *oauth2.Token) bool {
+			// the access token is left out to force the refresh
+			return token.AccessToken == "" && token.RefreshToken == "refresh_token"
+		})).Return(oauth2.StaticTokenSource(newToken)).Once()
+
+		service.refreshExpiringTokens(context.Background())
+
+		socialConnector.AssertExpectations(t)
+		assert.Len(t, *updates, 1)
+		assert.Equal(t, newToken, (*updates)[0].OAuthToken)
+	})
+
+	t.Run("should skip the tokens refreshed in the meantime", func(t *testing.T) {
+		refreshed := *expiring
+		refreshed.OAuthExpiry = now.Add(time.Hour)
+		service, socialConnector, updates := setup(t, &refreshed, 1)
+
+		service.refreshExpiringTokens(context.Background())
+
+		socialConnector.AssertNotCalled(t, "TokenSource", mock.Anything, mock.Anything)
+		assert.Empty(t, *updates)
+	})
+
+	t.Run("should skip the users without an active session", func(t *testing.T) {
+		service, socialConnector, updates := setup(t, expiring, 0)
+
+		service.refreshExpiringTokens(context.Background())
+
+		socialConnector.AssertNotCalled(t, "TokenSource", mock.Anything, mock.Anything)
+		assert.Empty(t, *updates)
+	})
+
+	t.Run("should count the failed refreshes per provider", func(t *testing.T) {
+		service, socialConnector, updates := setup(t, expiring, 1)
+		socialConnector.On("TokenSource", mock.Anything, mock.Anything).Return(errTokenSource{err: &oauth2.RetrieveError{ErrorCode: "invalid_grant"}}).Once()
+
+		service.refreshExpiringTokens(context.Background())
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(service.tokenRefreshFailures.WithLabelValues(login.GenericOAuthModule)))
+		// the refresh token was rejected, the tokens are invalidated
+		assert.Len(t, *updates, 1)
+		assert.Empty(t, (*updates)[0].OAuthToken.RefreshToken)
+	})
+
+	t.Run("should keep the tokens when the provider fails", func(t *testing.T) {
+		service, socialConnector, updates := setup(t, expiring, 1)
+		socialConnector.On("TokenSource", mock.Anything, mock.Anything).Return(errTokenSource{err: errors.New("connection refused")}).Once()
+
+		service.refreshExpiringTokens(context.Background())
+
+		assert.Equal(t, float64(1), testutil.ToFloat64(service.tokenRefreshFailures.WithLabelValues(login.GenericOAuthModule)))
+		assert.Empty(t, *updates)
+	})
+}
@@ -0,0 +1,21 @@
+package tokenexchange
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	exchanges *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		exchanges: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "oauth_token_exchange",
+			Name:      "tokens_total",
+			Help:      "Number of data source tokens requested by result: success or failure of an exchange, or cached.",
+		}, []string{"result"}),
+	}
+}
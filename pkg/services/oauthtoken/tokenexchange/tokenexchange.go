@@ -0,0 +1,207 @@
+package tokenexchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// JSONDataEnabled enables token exchange for a data source.
+	JSONDataEnabled = "oauthTokenExchange"
+	// JSONDataAudience is the audience of the exchanged tokens of a data source. Defaults to the data source URL.
+	JSONDataAudience = "tokenExchangeAudience"
+	// JSONDataScopes are the space separated scopes of the exchanged tokens of a data source.
+	JSONDataScopes = "tokenExchangeScopes"
+
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+
+	// defaultTTL is how long exchanged tokens without an expiry are cached.
+	defaultTTL = 5 * time.Minute
+)
+
+var (
+	ErrNoSubjectToken = errutil.Unauthorized("oauth.tokenExchange.noSubjectToken",
+		errutil.WithPublicMessage("The data source requires an OAuth login to forward your identity"))
+	ErrExchangeFailed = errutil.Unauthorized("oauth.tokenExchange.failed",
+		errutil.WithPublicMessage("Failed to exchange your OAuth token for the data source"))
+)
+
+// Service exchanges the OAuth token of the signed-in user for a short-lived token minted for a data source,
+// following the OAuth 2.0 token exchange grant (RFC 8693). Exchanged tokens are cached until shortly before
+// their expiry, after which they are exchanged again.
+type Service struct {
+	settings          setting.TokenExchangeSettings
+	oauthTokenService oauthtoken.OAuthTokenService
+	client            *http.Client
+	cache             *gocache.Cache
+	group             singleflight.Group
+	metrics           *metrics
+	log               log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, oauthTokenService oauthtoken.OAuthTokenService, registerer prometheus.Registerer) *Service {
+	return &Service{
+		settings:          cfg.TokenExchange,
+		oauthTokenService: oauthTokenService,
+		client:            &http.Client{Timeout: cfg.TokenExchange.Timeout},
+		cache:             gocache.New(defaultTTL, time.Minute),
+		metrics:           newMetrics(registerer),
+		log:               log.New("oauthtoken.tokenexchange"),
+	}
+}
+
+// IsEnabled returns true if token exchange is enabled for the data source.
+func (s *Service) IsEnabled(settings *backend.DataSourceInstanceSettings) bool {
+	if s == nil || !s.settings.Enabled || settings == nil || len(settings.JSONData) == 0 {
+		return false
+	}
+	jsonData := map[string]any{}
+	if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+		return false
+	}
+	enabled, _ := jsonData[JSONDataEnabled].(bool)
+	return enabled
+}
+
+// Token returns a token of the user for the data source, exchanging the OAuth token of the user if there is no
+// valid exchanged token in the cache.
+func (s *Service) Token(ctx context.Context, user identity.Requester, settings *backend.DataSourceInstanceSettings) (*oauth2.Token, error) {
+	subject := s.oauthTokenService.GetCurrentOAuthToken(ctx, user)
+	if subject == nil || subject.AccessToken == "" {
+		return nil, ErrNoSubjectToken.Errorf("user has no OAuth token")
+	}
+	audience, scopes := s.target(settings)
+
+	// The subject token identifies the user and changes when it is refreshed, which exchanges the token again.
+	sum := sha256.Sum256([]byte(subject.AccessToken + "\x00" + audience + "\x00" + scopes))
+	key := hex.EncodeToString(sum[:])
+	if cached, ok := s.cache.Get(key); ok {
+		s.metrics.exchanges.WithLabelValues("cached").Inc()
+		return cached.(*oauth2.Token), nil
+	}
+
+	v, err, _ := s.group.Do(key, func() (any, error) {
+		token, err := s.exchange(ctx, subject.AccessToken, audience, scopes)
+		if err != nil {
+			s.metrics.exchanges.WithLabelValues("failure").Inc()
+			return nil, err
+		}
+		s.metrics.exchanges.WithLabelValues("success").Inc()
+
+		ttl := defaultTTL
+		if !token.Expiry.IsZero() {
+			ttl = time.Until(token.Expiry) - s.settings.RefreshBefore
+		}
+		if ttl > 0 {
+			s.cache.Set(key, token, ttl)
+		}
+		return token, nil
+	})
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to exchange OAuth token", "datasource", settings.UID, "audience", audience, "error", err)
+		return nil, ErrExchangeFailed.Errorf("failed to exchange token: %w", err)
+	}
+	return v.(*oauth2.Token), nil
+}
+
+// target returns the audience and the scopes of the exchanged tokens of the data source.
+func (s *Service) target(settings *backend.DataSourceInstanceSettings) (string, string) {
+	jsonData := map[string]any{}
+	_ = json.Unmarshal(settings.JSONData, &jsonData)
+
+	audience, _ := jsonData[JSONDataAudience].(string)
+	if audience == "" {
+		audience = settings.URL
+	}
+	dsScopes, _ := jsonData[JSONDataScopes].(string)
+	scopes := append(append([]string{}, s.settings.Scopes...), strings.Fields(dsScopes)...)
+	return audience, strings.Join(scopes, " ")
+}
+
+type exchangeResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *Service) exchange(ctx context.Context, subjectToken, audience, scopes string) (*oauth2.Token, error) {
+	form := url.Values{
+		"grant_type":           {grantTypeTokenExchange},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {tokenTypeAccessToken},
+		"requested_token_type": {tokenTypeAccessToken},
+	}
+	if audience != "" {
+		form.Set("audience", audience)
+	}
+	if scopes != "" {
+		form.Set("scope", scopes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.settings.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.settings.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(s.settings.ClientID), url.QueryEscape(s.settings.ClientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	res := exchangeResponse{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("invalid token endpoint response with status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || res.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned status %d: %s %s", resp.StatusCode, res.Error, res.ErrorDescription)
+	}
+	if res.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no access token")
+	}
+
+	token := &oauth2.Token{AccessToken: res.AccessToken, TokenType: res.TokenType}
+	if token.TokenType == "" {
+		token.TokenType = "Bearer"
+	}
+	if res.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
@@ -0,0 +1,119 @@
+package tokenexchange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/oauthtokentest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestToken(t *testing.T) {
+	requests := 0
+	expiresIn := 3600
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, grantTypeTokenExchange, r.PostForm.Get("grant_type"))
+		require.Equal(t, "user-token", r.PostForm.Get("subject_token"))
+		require.Equal(t, tokenTypeAccessToken, r.PostForm.Get("subject_token_type"))
+		require.Equal(t, "https://prometheus.example.com", r.PostForm.Get("audience"))
+		require.Equal(t, "openid metrics:read", r.PostForm.Get("scope"))
+		clientID, clientSecret, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "grafana", clientID)
+		require.Equal(t, "secret", clientSecret)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "exchanged-token",
+			"issued_token_type": tokenTypeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        expiresIn,
+		})
+	}))
+	defer server.Close()
+
+	cfg := setting.NewCfg()
+	cfg.TokenExchange = setting.TokenExchangeSettings{
+		Enabled:       true,
+		TokenURL:      server.URL,
+		ClientID:      "grafana",
+		ClientSecret:  "secret",
+		Scopes:        []string{"openid"},
+		RefreshBefore: 30 * time.Second,
+		Timeout:       time.Second,
+	}
+	s := ProvideService(cfg, &oauthtokentest.MockOauthTokenService{
+		GetCurrentOauthTokenFunc: func(_ context.Context, _ identity.Requester) *oauth2.Token {
+			return &oauth2.Token{AccessToken: "user-token"}
+		},
+	}, prometheus.NewRegistry())
+
+	settings := &backend.DataSourceInstanceSettings{
+		UID:      "prom",
+		URL:      "https://prometheus.example.com",
+		JSONData: []byte(`{"oauthTokenExchange": true, "tokenExchangeScopes": "metrics:read"}`),
+	}
+	require.True(t, s.IsEnabled(settings))
+	require.False(t, s.IsEnabled(&backend.DataSourceInstanceSettings{JSONData: []byte(`{}`)}))
+
+	usr := &user.SignedInUser{UserID: 1, OrgID: 1}
+	token, err := s.Token(context.Background(), usr, settings)
+	require.NoError(t, err)
+	require.Equal(t, "exchanged-token", token.AccessToken)
+	require.Equal(t, "Bearer", token.Type())
+
+	_, err = s.Token(context.Background(), usr, settings)
+	require.NoError(t, err)
+	require.Equal(t, 1, requests, "exchanged tokens are cached")
+
+	// Tokens expiring within the refresh period are exchanged again on every request.
+	s.cache.Flush()
+	expiresIn = 10
+	for i := 0; i < 2; i++ {
+		_, err = s.Token(context.Background(), usr, settings)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, requests)
+}
+
+func TestTokenErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid_target", "error_description": "unknown audience"}`))
+	}))
+	defer server.Close()
+
+	cfg := setting.NewCfg()
+	cfg.TokenExchange = setting.TokenExchangeSettings{Enabled: true, TokenURL: server.URL, Timeout: time.Second}
+	oauthTokenService := &oauthtokentest.MockOauthTokenService{}
+	s := ProvideService(cfg, oauthTokenService, prometheus.NewRegistry())
+	settings := &backend.DataSourceInstanceSettings{JSONData: []byte(`{"oauthTokenExchange": true}`)}
+
+	_, err := s.Token(context.Background(), &user.SignedInUser{}, settings)
+	require.ErrorIs(t, err, ErrNoSubjectToken)
+
+	oauthTokenService.GetCurrentOauthTokenFunc = func(_ context.Context, _ identity.Requester) *oauth2.Token {
+		return &oauth2.Token{AccessToken: "user-token"}
+	}
+	_, err = s.Token(context.Background(), &user.SignedInUser{}, settings)
+	require.ErrorIs(t, err, ErrExchangeFailed)
+	require.ErrorContains(t, err, "unknown audience")
+
+	var grafanaErr errutil.Error
+	require.ErrorAs(t, err, &grafanaErr)
+	require.Equal(t, errutil.StatusUnauthorized, grafanaErr.Reason.Status())
+}
@@ -0,0 +1,125 @@
+package orgmigration
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	manifestFile    = "manifest.json"
+	usersFile       = "users.json"
+	teamsFile       = "teams.json"
+	foldersFile     = "folders.json"
+	dashboardsFile  = "dashboards.json"
+	dataSourcesFile = "datasources.json"
+	alertRulesFile  = "alert-rules.json"
+
+	// maxFileSize is the maximum size of a file of an archive, to not exhaust the memory on a malicious archive.
+	maxFileSize = 1 << 30
+)
+
+// writeArchive writes the archive as a gzipped tarball with a JSON file for each kind of resources.
+func writeArchive(w io.Writer, archive *Archive) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	files := []struct {
+		name    string
+		content any
+	}{
+		{manifestFile, archive.Manifest},
+		{usersFile, archive.Users},
+		{teamsFile, archive.Teams},
+		{foldersFile, archive.Folders},
+		{dashboardsFile, archive.Dashboards},
+		{dataSourcesFile, archive.DataSources},
+		{alertRulesFile, archive.AlertRuleGroups},
+	}
+	for _, f := range files {
+		content, err := json.MarshalIndent(f.content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", f.name, err)
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0o600,
+			Size:    int64(len(content)),
+			ModTime: archive.Manifest.ExportedAt,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func readArchive(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	archive := &Archive{}
+	targets := map[string]any{
+		manifestFile:    &archive.Manifest,
+		usersFile:       &archive.Users,
+		teamsFile:       &archive.Teams,
+		foldersFile:     &archive.Folders,
+		dashboardsFile:  &archive.Dashboards,
+		dataSourcesFile: &archive.DataSources,
+		alertRulesFile:  &archive.AlertRuleGroups,
+	}
+	found := map[string]bool{}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		target, ok := targets[header.Name]
+		if !ok {
+			continue
+		}
+		if header.Size > maxFileSize {
+			return nil, fmt.Errorf("invalid archive: %s is larger than %d bytes", header.Name, maxFileSize)
+		}
+		if err := json.NewDecoder(io.LimitReader(tr, maxFileSize)).Decode(target); err != nil {
+			return nil, fmt.Errorf("invalid archive: failed to read %s: %w", header.Name, err)
+		}
+		found[header.Name] = true
+	}
+
+	if !found[manifestFile] {
+		return nil, fmt.Errorf("invalid archive: %s is missing", manifestFile)
+	}
+	if archive.Manifest.Version < 1 || archive.Manifest.Version > archiveVersion {
+		return nil, fmt.Errorf("%w %d, expected at most %d", ErrUnsupportedVersion, archive.Manifest.Version, archiveVersion)
+	}
+	return archive, nil
+}
+
+func newManifest(orgName, buildVersion string, now time.Time) Manifest {
+	return Manifest{
+		Version:      archiveVersion,
+		OrgName:      orgName,
+		ExportedAt:   now.UTC(),
+		BuildVersion: buildVersion,
+		Counts:       map[string]int{},
+	}
+}
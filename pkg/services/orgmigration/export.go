@@ -0,0 +1,271 @@
+package orgmigration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+// Export writes the archive of the org to w, and returns its manifest.
+func (s *Service) Export(ctx context.Context, w io.Writer, opts ExportOptions) (*Manifest, error) {
+	o, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: opts.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{Manifest: newManifest(o.Name, s.buildVersion, s.now())}
+	if opts.SecretsKey != "" {
+		archive.Manifest.SecretsKeyCheck, err = encryptSecret(secretsKeyCheck, opts.SecretsKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if archive.Users, err = s.exportUsers(ctx, opts.OrgID); err != nil {
+		return nil, fmt.Errorf("failed to export users: %w", err)
+	}
+	if archive.Teams, err = s.exportTeams(ctx, opts.OrgID); err != nil {
+		return nil, fmt.Errorf("failed to export teams: %w", err)
+	}
+	if archive.Folders, archive.Dashboards, err = s.exportDashboards(ctx, opts.OrgID); err != nil {
+		return nil, fmt.Errorf("failed to export dashboards: %w", err)
+	}
+	if archive.DataSources, err = s.exportDataSources(ctx, opts.OrgID, opts.SecretsKey); err != nil {
+		return nil, fmt.Errorf("failed to export data sources: %w", err)
+	}
+	if archive.AlertRuleGroups, err = s.exportAlertRules(ctx, opts.OrgID); err != nil {
+		return nil, fmt.Errorf("failed to export alert rules: %w", err)
+	}
+
+	archive.Manifest.Counts[KindUser] = len(archive.Users)
+	archive.Manifest.Counts[KindTeam] = len(archive.Teams)
+	archive.Manifest.Counts[KindFolder] = len(archive.Folders)
+	archive.Manifest.Counts[KindDashboard] = len(archive.Dashboards)
+	archive.Manifest.Counts[KindDataSource] = len(archive.DataSources)
+	for _, g := range archive.AlertRuleGroups {
+		archive.Manifest.Counts[KindAlertRule] += len(g.Rules)
+	}
+
+	if err := writeArchive(w, archive); err != nil {
+		return nil, fmt.Errorf("failed to write the archive: %w", err)
+	}
+	s.log.Info("Exported organization", "orgID", opts.OrgID, "counts", archive.Manifest.Counts)
+	return &archive.Manifest, nil
+}
+
+func (s *Service) exportUsers(ctx context.Context, orgID int64) ([]User, error) {
+	orgUsers, err := s.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID:                    orgID,
+		DontEnforceAccessControl: true,
+		User:                     requester(orgID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, 0, len(orgUsers))
+	for _, u := range orgUsers {
+		users = append(users, User{
+			Login:      u.Login,
+			Email:      u.Email,
+			Name:       u.Name,
+			Role:       org.RoleType(u.Role),
+			IsDisabled: u.IsDisabled,
+		})
+	}
+	return users, nil
+}
+
+func (s *Service) exportTeams(ctx context.Context, orgID int64) ([]Team, error) {
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        orgID,
+		SignedInUser: requester(orgID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	teams := make([]Team, 0, len(result.Teams))
+	for _, t := range result.Teams {
+		members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{
+			OrgID:        orgID,
+			TeamID:       t.ID,
+			SignedInUser: requester(orgID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		exported := Team{Name: t.Name, Email: t.Email}
+		for _, m := range members {
+			exported.Members = append(exported.Members, TeamMember{Login: m.Login, Permission: m.Permission})
+		}
+		teams = append(teams, exported)
+	}
+	return teams, nil
+}
+
+// exportDashboards returns the folders, parents first, and the dashboards of the org. The dashboards and folders in
+// the trash are not exported.
+func (s *Service) exportDashboards(ctx context.Context, orgID int64) ([]Folder, []Dashboard, error) {
+	dashs, err := s.dashboardService.GetAllDashboards(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	folderUIDs := make([]string, 0)
+	exported := make([]Dashboard, 0)
+	for _, d := range dashs {
+		if d.OrgID != orgID || !d.Deleted.IsZero() {
+			continue
+		}
+		if d.IsFolder {
+			folderUIDs = append(folderUIDs, d.UID)
+			continue
+		}
+		data := d.Data
+		if data != nil {
+			data.Del("id")
+		}
+		exported = append(exported, Dashboard{
+			UID:       d.UID,
+			Title:     d.Title,
+			FolderUID: d.FolderUID,
+			Dashboard: data,
+		})
+	}
+
+	folders := make([]Folder, 0, len(folderUIDs))
+	if len(folderUIDs) > 0 {
+		fs, err := s.folderService.GetFolders(ctx, folder.GetFoldersQuery{
+			OrgID:        orgID,
+			UIDs:         folderUIDs,
+			SignedInUser: requester(orgID),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range fs {
+			folders = append(folders, Folder{
+				UID:         f.UID,
+				Title:       f.Title,
+				Description: f.Description,
+				ParentUID:   f.ParentUID,
+			})
+		}
+	}
+	return sortFolders(folders), exported, nil
+}
+
+// sortFolders sorts the folders so the parents come before their children.
+func sortFolders(folders []Folder) []Folder {
+	byUID := make(map[string]Folder, len(folders))
+	for _, f := range folders {
+		byUID[f.UID] = f
+	}
+	depths := make(map[string]int, len(folders))
+	var depth func(uid string, seen int) int
+	depth = func(uid string, seen int) int {
+		if d, ok := depths[uid]; ok {
+			return d
+		}
+		f, ok := byUID[uid]
+		// seen stops on a cycle of parents, which the folder service doesn't allow.
+		if !ok || f.ParentUID == "" || seen > len(folders) {
+			return 0
+		}
+		return 1 + depth(f.ParentUID, seen+1)
+	}
+	for _, f := range folders {
+		depths[f.UID] = depth(f.UID, 0)
+	}
+	sort.SliceStable(folders, func(i, j int) bool {
+		return depths[folders[i].UID] < depths[folders[j].UID]
+	})
+	return folders
+}
+
+// exportDataSources returns the data sources of the org. Their secrets are encrypted with the secrets key, and left
+// out if the key is empty.
+func (s *Service) exportDataSources(ctx context.Context, orgID int64, secretsKey string) ([]DataSource, error) {
+	dss, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	exported := make([]DataSource, 0, len(dss))
+	for _, ds := range dss {
+		d := DataSource{
+			UID:             ds.UID,
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			URL:             ds.URL,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			ReadOnly:        ds.ReadOnly,
+			JsonData:        ds.JsonData,
+		}
+		if secretsKey != "" {
+			secrets, err := s.dataSourceService.DecryptedValues(ctx, ds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt the secrets of data source %s: %w", ds.UID, err)
+			}
+			if d.SecureJsonData, err = encryptSecrets(secrets, secretsKey); err != nil {
+				return nil, fmt.Errorf("failed to encrypt the secrets of data source %s: %w", ds.UID, err)
+			}
+		}
+		exported = append(exported, d)
+	}
+	return exported, nil
+}
+
+// exportAlertRules returns the alert rules of the org by group, sorted by folder and group.
+func (s *Service) exportAlertRules(ctx context.Context, orgID int64) ([]definitions.AlertRuleGroup, error) {
+	rules, err := s.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[ngmodels.AlertRuleGroupKey]*ngmodels.AlertRuleGroup{}
+	keys := make([]ngmodels.AlertRuleGroupKey, 0)
+	for _, rule := range rules {
+		key := rule.GetGroupKey()
+		g, ok := groups[key]
+		if !ok {
+			g = &ngmodels.AlertRuleGroup{
+				Title:     rule.RuleGroup,
+				FolderUID: rule.NamespaceUID,
+				Interval:  rule.IntervalSeconds,
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.Rules = append(g.Rules, *rule)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].NamespaceUID != keys[j].NamespaceUID {
+			return keys[i].NamespaceUID < keys[j].NamespaceUID
+		}
+		return keys[i].RuleGroup < keys[j].RuleGroup
+	})
+
+	exported := make([]definitions.AlertRuleGroup, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		sort.SliceStable(g.Rules, func(i, j int) bool {
+			return g.Rules[i].RuleGroupIndex < g.Rules[j].RuleGroupIndex
+		})
+		exported = append(exported, api.ApiAlertRuleGroupFromAlertRuleGroup(*g))
+	}
+	return exported, nil
+}
@@ -0,0 +1,556 @@
+package orgmigration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// importedNameSuffix is added to the name of a data source imported with a new UID whose name is taken.
+const importedNameSuffix = " (imported)"
+
+// Import imports the archive read from r into the org, in a single transaction. Users are matched by email and teams
+// by name. Existing users are only added to the org if the options merge them, otherwise the import fails. Folders,
+// dashboards, data sources and alert rules which already exist are handled with the collision policy of the options.
+// With the fail policy, nothing is written if any of them exists.
+func (s *Service) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if opts.Collision == "" {
+		opts.Collision = CollisionFail
+	}
+	if _, err := ParseCollisionPolicy(string(opts.Collision)); err != nil {
+		return nil, err
+	}
+
+	archive, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSecretsKey(archive.Manifest, opts.SecretsKey); err != nil {
+		return nil, err
+	}
+	if _, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: opts.OrgID}); err != nil {
+		return nil, err
+	}
+
+	state, err := s.loadState(ctx, opts.OrgID, archive)
+	if err != nil {
+		return nil, err
+	}
+	p := newPlan(archive, state, opts.Collision, util.GenerateShortUID)
+	if opts.Collision == CollisionFail && len(p.collisions) > 0 {
+		return nil, &CollisionError{Resources: p.collisions}
+	}
+	if len(p.existingUsers) > 0 && !opts.MergeUsers {
+		return nil, fmt.Errorf("%w: %s", ErrUsersExist, strings.Join(p.existingUsers, ", "))
+	}
+
+	result := &ImportResult{DryRun: opts.DryRun, Resources: p.results}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	err = s.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.importUsers(ctx, opts.OrgID, archive.Users, state); err != nil {
+			return fmt.Errorf("failed to import users: %w", err)
+		}
+		if err := s.importTeams(ctx, opts.OrgID, archive.Teams, state); err != nil {
+			return fmt.Errorf("failed to import teams: %w", err)
+		}
+		if err := s.importFolders(ctx, opts.OrgID, archive.Folders, p); err != nil {
+			return fmt.Errorf("failed to import folders: %w", err)
+		}
+		if err := s.importDataSources(ctx, opts.OrgID, archive.DataSources, opts.SecretsKey, p, state); err != nil {
+			return fmt.Errorf("failed to import data sources: %w", err)
+		}
+		if err := s.importDashboards(ctx, opts.OrgID, archive.Dashboards, p); err != nil {
+			return fmt.Errorf("failed to import dashboards: %w", err)
+		}
+		if err := s.importAlertRules(ctx, opts.OrgID, archive, p, state); err != nil {
+			return fmt.Errorf("failed to import alert rules: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("Imported organization archive", "orgID", opts.OrgID, "policy", opts.Collision, "resources", len(result.Resources))
+	return result, nil
+}
+
+// orgState is the state of the instance and the org an archive is imported into.
+type orgState struct {
+	// users are the IDs of the users of the instance with the email of a user of the archive, by login of the archive.
+	users map[string]int64
+	// teams are the IDs of the teams of the org by name.
+	teams      map[string]int64
+	folders    map[string]bool
+	dashboards map[string]bool
+	// dataSources are the data sources of the org by UID, and dataSourceUIDs their UIDs by name.
+	dataSources    map[string]*datasources.DataSource
+	dataSourceUIDs map[string]string
+	rules          map[string]*ngmodels.AlertRule
+}
+
+func (s *Service) loadState(ctx context.Context, orgID int64, archive *Archive) (*orgState, error) {
+	state := &orgState{
+		users:          map[string]int64{},
+		teams:          map[string]int64{},
+		folders:        map[string]bool{},
+		dashboards:     map[string]bool{},
+		dataSources:    map[string]*datasources.DataSource{},
+		dataSourceUIDs: map[string]string{},
+		rules:          map[string]*ngmodels.AlertRule{},
+	}
+
+	for _, u := range archive.Users {
+		if u.Email == "" {
+			continue
+		}
+		existing, err := s.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: u.Email})
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		state.users[u.Login] = existing.ID
+	}
+
+	teams, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{OrgID: orgID, SignedInUser: requester(orgID)})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range teams.Teams {
+		state.teams[t.Name] = t.ID
+	}
+
+	dashs, err := s.dashboardService.GetAllDashboards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dashs {
+		if d.OrgID != orgID {
+			continue
+		}
+		if d.IsFolder {
+			state.folders[d.UID] = true
+		} else {
+			state.dashboards[d.UID] = true
+		}
+	}
+
+	dss, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range dss {
+		state.dataSources[ds.UID] = ds
+		state.dataSourceUIDs[ds.Name] = ds.UID
+	}
+
+	rules, err := s.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		state.rules[rule.UID] = rule
+	}
+	return state, nil
+}
+
+// decision is what an import does with a resource of the archive, and the UID and name it's imported with.
+type decision struct {
+	action Action
+	uid    string
+	name   string
+}
+
+// plan is what an import does with each resource of an archive. It's computed before anything is written, so a
+// collision can fail the import, and a dry run can return it.
+type plan struct {
+	// decisions are the decisions by kind and UID of the archive.
+	decisions  map[string]map[string]decision
+	results    []ResourceResult
+	collisions []ResourceResult
+	// existingUsers are the emails of the users of the archive which already exist.
+	existingUsers []string
+}
+
+func newPlan(archive *Archive, state *orgState, policy CollisionPolicy, newUID func() string) *plan {
+	p := &plan{decisions: map[string]map[string]decision{}}
+
+	for _, u := range archive.Users {
+		action := ActionCreate
+		if _, ok := state.users[u.Login]; ok {
+			action = ActionSkip
+			p.existingUsers = append(p.existingUsers, u.Email)
+		}
+		p.results = append(p.results, ResourceResult{Kind: KindUser, Name: u.Login, Action: action})
+	}
+	for _, t := range archive.Teams {
+		action := ActionCreate
+		if _, ok := state.teams[t.Name]; ok {
+			action = ActionSkip
+		}
+		p.results = append(p.results, ResourceResult{Kind: KindTeam, Name: t.Name, Action: action})
+	}
+
+	for _, f := range archive.Folders {
+		p.set(KindFolder, f.UID, p.decide(KindFolder, f.UID, f.Title, state.folders[f.UID], policy, newUID))
+	}
+
+	names := make(map[string]bool, len(state.dataSourceUIDs))
+	for name := range state.dataSourceUIDs {
+		names[name] = true
+	}
+	for _, ds := range archive.DataSources {
+		_, uidExists := state.dataSources[ds.UID]
+		nameUID, nameExists := state.dataSourceUIDs[ds.Name]
+		d := p.decide(KindDataSource, ds.UID, ds.Name, uidExists || nameExists, policy, newUID)
+		switch {
+		case d.action == ActionCreate && names[d.name]:
+			// The name of a data source imported with a new UID is taken by the data source it collided with.
+			d.name += importedNameSuffix
+		case !uidExists && nameExists:
+			// The data source collided by name, the existing one is kept or overwritten with its UID.
+			d.uid = nameUID
+		}
+		names[d.name] = true
+		p.set(KindDataSource, ds.UID, d)
+	}
+
+	for _, d := range archive.Dashboards {
+		p.set(KindDashboard, d.UID, p.decide(KindDashboard, d.UID, d.Title, state.dashboards[d.UID], policy, newUID))
+	}
+	for _, g := range archive.AlertRuleGroups {
+		for _, rule := range g.Rules {
+			_, exists := state.rules[rule.UID]
+			p.set(KindAlertRule, rule.UID, p.decide(KindAlertRule, rule.UID, rule.Title, exists, policy, newUID))
+		}
+	}
+	return p
+}
+
+// decide returns the decision for a resource of the archive, given whether it collides with an existing resource,
+// and records the collision.
+func (p *plan) decide(kind, uid, name string, exists bool, policy CollisionPolicy, newUID func() string) decision {
+	d := decision{action: ActionCreate, uid: uid, name: name}
+	if !exists {
+		return d
+	}
+	switch policy {
+	case CollisionOverwrite:
+		d.action = ActionOverwrite
+	case CollisionNewUID:
+		d.uid = newUID()
+	default:
+		d.action = ActionSkip
+	}
+	p.collisions = append(p.collisions, ResourceResult{Kind: kind, UID: uid, Name: name, Action: d.action})
+	return d
+}
+
+// set records the decision for a resource of the archive.
+func (p *plan) set(kind, uid string, d decision) {
+	if p.decisions[kind] == nil {
+		p.decisions[kind] = map[string]decision{}
+	}
+	p.decisions[kind][uid] = d
+
+	result := ResourceResult{Kind: kind, UID: uid, Name: d.name, Action: d.action}
+	if d.uid != uid {
+		result.NewUID = d.uid
+	}
+	p.results = append(p.results, result)
+}
+
+func (p *plan) get(kind, uid string) decision {
+	if d, ok := p.decisions[kind][uid]; ok {
+		return d
+	}
+	return decision{action: ActionSkip, uid: uid}
+}
+
+// uid returns the UID a resource of the archive is imported with, or the UID if the resource isn't in the archive.
+func (p *plan) uid(kind, uid string) string {
+	if uid == "" {
+		return uid
+	}
+	return p.get(kind, uid).uid
+}
+
+func (s *Service) importUsers(ctx context.Context, orgID int64, users []User, state *orgState) error {
+	for _, u := range users {
+		if id, ok := state.users[u.Login]; ok {
+			err := s.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{OrgID: orgID, UserID: id, Role: u.Role})
+			if err != nil && !errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+				return fmt.Errorf("failed to add user %s to the organization: %w", u.Login, err)
+			}
+			continue
+		}
+		created, err := s.userService.Create(ctx, &user.CreateUserCommand{
+			Login:          u.Login,
+			Email:          u.Email,
+			Name:           u.Name,
+			IsDisabled:     u.IsDisabled,
+			OrgID:          orgID,
+			DefaultOrgRole: string(u.Role),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create user %s: %w", u.Login, err)
+		}
+		state.users[u.Login] = created.ID
+	}
+	return nil
+}
+
+func (s *Service) importTeams(ctx context.Context, orgID int64, teams []Team, state *orgState) error {
+	for _, t := range teams {
+		id, ok := state.teams[t.Name]
+		if !ok {
+			created, err := s.teamService.CreateTeam(ctx, t.Name, t.Email, orgID)
+			if err != nil {
+				return fmt.Errorf("failed to create team %s: %w", t.Name, err)
+			}
+			id = created.ID
+			state.teams[t.Name] = id
+		}
+		for _, m := range t.Members {
+			userID, ok := state.users[m.Login]
+			if !ok {
+				s.log.Warn("Skipping member of team, the user is not in the archive", "team", t.Name, "login", m.Login)
+				continue
+			}
+			if _, err := s.teamPermissions.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID},
+				strconv.FormatInt(id, 10), m.Permission.String()); err != nil {
+				return fmt.Errorf("failed to add %s to team %s: %w", m.Login, t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// importFolders imports the folders, which are sorted parents first in the archive.
+func (s *Service) importFolders(ctx context.Context, orgID int64, folders []Folder, p *plan) error {
+	for _, f := range folders {
+		d := p.get(KindFolder, f.UID)
+		parentUID := p.uid(KindFolder, f.ParentUID)
+		switch d.action {
+		case ActionCreate:
+			if _, err := s.folderService.Create(ctx, &folder.CreateFolderCommand{
+				UID:          d.uid,
+				OrgID:        orgID,
+				Title:        f.Title,
+				Description:  f.Description,
+				ParentUID:    parentUID,
+				SignedInUser: requester(orgID),
+			}); err != nil {
+				return fmt.Errorf("failed to create folder %s: %w", f.UID, err)
+			}
+		case ActionOverwrite:
+			existing, err := s.folderService.Get(ctx, &folder.GetFolderQuery{UID: &d.uid, OrgID: orgID, SignedInUser: requester(orgID)})
+			if err != nil {
+				return fmt.Errorf("failed to get folder %s: %w", f.UID, err)
+			}
+			if _, err := s.folderService.Update(ctx, &folder.UpdateFolderCommand{
+				UID:            d.uid,
+				OrgID:          orgID,
+				NewTitle:       &f.Title,
+				NewDescription: &f.Description,
+				Overwrite:      true,
+				SignedInUser:   requester(orgID),
+			}); err != nil {
+				return fmt.Errorf("failed to update folder %s: %w", f.UID, err)
+			}
+			if existing.ParentUID != parentUID {
+				if _, err := s.folderService.Move(ctx, &folder.MoveFolderCommand{
+					UID:          d.uid,
+					NewParentUID: parentUID,
+					OrgID:        orgID,
+					SignedInUser: requester(orgID),
+				}); err != nil {
+					return fmt.Errorf("failed to move folder %s: %w", f.UID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) importDataSources(ctx context.Context, orgID int64, dss []DataSource, secretsKey string, p *plan, state *orgState) error {
+	for _, ds := range dss {
+		d := p.get(KindDataSource, ds.UID)
+		if d.action == ActionSkip {
+			continue
+		}
+		secrets, err := decryptSecrets(ds.SecureJsonData, secretsKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt the secrets of data source %s: %w", ds.UID, err)
+		}
+		if d.action == ActionCreate {
+			if _, err := s.dataSourceService.AddDataSource(ctx, &datasources.AddDataSourceCommand{
+				OrgID:           orgID,
+				UID:             d.uid,
+				Name:            d.name,
+				Type:            ds.Type,
+				Access:          ds.Access,
+				URL:             ds.URL,
+				User:            ds.User,
+				Database:        ds.Database,
+				BasicAuth:       ds.BasicAuth,
+				BasicAuthUser:   ds.BasicAuthUser,
+				WithCredentials: ds.WithCredentials,
+				IsDefault:       ds.IsDefault,
+				ReadOnly:        ds.ReadOnly,
+				JsonData:        ds.JsonData,
+				SecureJsonData:  secrets,
+			}); err != nil {
+				return fmt.Errorf("failed to create data source %s: %w", ds.UID, err)
+			}
+			continue
+		}
+		existing := state.dataSources[d.uid]
+		if _, err := s.dataSourceService.UpdateDataSource(ctx, &datasources.UpdateDataSourceCommand{
+			OrgID:           orgID,
+			ID:              existing.ID,
+			UID:             existing.UID,
+			Version:         existing.Version,
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			URL:             ds.URL,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			ReadOnly:        ds.ReadOnly,
+			JsonData:        ds.JsonData,
+			SecureJsonData:  secrets,
+		}); err != nil {
+			return fmt.Errorf("failed to update data source %s: %w", ds.UID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) importDashboards(ctx context.Context, orgID int64, dashs []Dashboard, p *plan) error {
+	for _, d := range dashs {
+		dec := p.get(KindDashboard, d.UID)
+		if dec.action == ActionSkip {
+			continue
+		}
+		data := d.Dashboard
+		if data == nil {
+			data = simplejson.New()
+		}
+		remapDashboard(data, dec.uid, p)
+		dash := dashboards.NewDashboardFromJson(data)
+		dash.OrgID = orgID
+		dash.FolderUID = p.uid(KindFolder, d.FolderUID)
+		if _, err := s.dashboardService.ImportDashboard(ctx, &dashboards.SaveDashboardDTO{
+			OrgID:     orgID,
+			User:      requester(orgID),
+			Overwrite: dec.action == ActionOverwrite,
+			Message:   "Imported from an organization archive",
+			Dashboard: dash,
+		}); err != nil {
+			return fmt.Errorf("failed to import dashboard %s: %w", d.UID, err)
+		}
+	}
+	return nil
+}
+
+// remapDashboard sets the UID of the dashboard JSON, and updates the references to the data sources imported with a
+// new UID.
+func remapDashboard(data *simplejson.Json, uid string, p *plan) {
+	data.Del("id")
+	data.Set("uid", uid)
+	remapDataSourceRefs(data.Interface(), p)
+}
+
+func remapDataSourceRefs(v any, p *plan) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if ref, ok := child.(map[string]any); ok && k == "datasource" {
+				if uid, ok := ref["uid"].(string); ok {
+					ref["uid"] = p.uid(KindDataSource, uid)
+				}
+			}
+			remapDataSourceRefs(child, p)
+		}
+	case []any:
+		for _, child := range v {
+			remapDataSourceRefs(child, p)
+		}
+	}
+}
+
+func (s *Service) importAlertRules(ctx context.Context, orgID int64, archive *Archive, p *plan, state *orgState) error {
+	inserts := make([]ngmodels.AlertRule, 0)
+	updates := make([]ngmodels.UpdateRule, 0)
+	for _, g := range archive.AlertRuleGroups {
+		group, err := api.AlertRuleGroupFromApiAlertRuleGroup(g)
+		if err != nil {
+			return fmt.Errorf("invalid rule group %s: %w", g.Title, err)
+		}
+		for i, rule := range group.Rules {
+			d := p.get(KindAlertRule, rule.UID)
+			if d.action == ActionSkip {
+				continue
+			}
+			remapAlertRule(&rule, orgID, group, p)
+			rule.UID = d.uid
+			rule.RuleGroupIndex = i + 1
+			if d.action == ActionOverwrite {
+				updates = append(updates, ngmodels.UpdateRule{Existing: state.rules[d.uid], New: rule})
+			} else {
+				inserts = append(inserts, rule)
+			}
+		}
+	}
+	if len(updates) > 0 {
+		if err := s.ruleStore.UpdateAlertRules(ctx, updates); err != nil {
+			return err
+		}
+	}
+	if len(inserts) > 0 {
+		if _, err := s.ruleStore.InsertAlertRules(ctx, inserts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remapAlertRule moves the rule to the org and group, and updates its references to the folders, data sources and
+// dashboards imported with a new UID.
+func remapAlertRule(rule *ngmodels.AlertRule, orgID int64, group ngmodels.AlertRuleGroup, p *plan) {
+	rule.ID = 0
+	rule.OrgID = orgID
+	rule.NamespaceUID = p.uid(KindFolder, group.FolderUID)
+	rule.RuleGroup = group.Title
+	rule.IntervalSeconds = group.Interval
+	for i := range rule.Data {
+		rule.Data[i].DatasourceUID = p.uid(KindDataSource, rule.Data[i].DatasourceUID)
+	}
+	if dashboardUID, ok := rule.Annotations[ngmodels.DashboardUIDAnnotation]; ok {
+		rule.Annotations[ngmodels.DashboardUIDAnnotation] = p.uid(KindDashboard, dashboardUID)
+	}
+}
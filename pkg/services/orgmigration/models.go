@@ -0,0 +1,187 @@
+package orgmigration
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+// archiveVersion is the version of the format of the archives. Archives of a later version can't be imported.
+const archiveVersion = 1
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	ErrSecretsKeyRequired = errors.New("the archive contains encrypted secrets, a secrets key is required to import it")
+	ErrInvalidSecretsKey  = errors.New("the secrets key does not match the key the archive was exported with")
+	ErrInvalidPolicy      = errors.New("invalid collision policy")
+	ErrUsersExist         = errors.New("users of the archive already exist, merge them to add them to the organization")
+)
+
+// CollisionPolicy is what an import does with a resource whose UID already exists in the org.
+type CollisionPolicy string
+
+const (
+	// CollisionFail fails the import before anything is written if any resource exists.
+	CollisionFail CollisionPolicy = "fail"
+	// CollisionSkip keeps the existing resources.
+	CollisionSkip CollisionPolicy = "skip"
+	// CollisionOverwrite replaces the existing resources with the ones of the archive.
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// CollisionNewUID imports the resources with new UIDs, and updates the references to them.
+	CollisionNewUID CollisionPolicy = "new-uid"
+)
+
+func ParseCollisionPolicy(s string) (CollisionPolicy, error) {
+	switch p := CollisionPolicy(s); p {
+	case CollisionFail, CollisionSkip, CollisionOverwrite, CollisionNewUID:
+		return p, nil
+	case "":
+		return CollisionFail, nil
+	default:
+		return "", fmt.Errorf("%w %q, expected one of fail, skip, overwrite, new-uid", ErrInvalidPolicy, s)
+	}
+}
+
+// CollisionError lists the resources of an archive which already exist in the org.
+type CollisionError struct {
+	Resources []ResourceResult
+}
+
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("%d resources of the archive already exist in the organization", len(e.Resources))
+}
+
+type ExportOptions struct {
+	OrgID int64
+	// SecretsKey is the key the secrets of the data sources are encrypted with in the archive. The secrets are not
+	// exported if it's empty.
+	SecretsKey string
+}
+
+type ImportOptions struct {
+	OrgID      int64
+	SecretsKey string
+	Collision  CollisionPolicy
+	// MergeUsers adds the existing users with the email of a user of the archive to the org. The import fails if
+	// any exists without it.
+	MergeUsers bool
+	// DryRun returns what the import would do without writing anything.
+	DryRun bool
+}
+
+// Action is what an import does with a resource of the archive.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionOverwrite Action = "overwrite"
+	ActionSkip      Action = "skip"
+)
+
+// ResourceResult is what an import did, or would do on a dry run, with a resource of the archive.
+type ResourceResult struct {
+	Kind   string `json:"kind"`
+	UID    string `json:"uid,omitempty"`
+	Name   string `json:"name"`
+	Action Action `json:"action"`
+	// NewUID is the UID the resource is imported with, if the collision policy gave it a new one.
+	NewUID string `json:"newUid,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun    bool             `json:"dryRun"`
+	Resources []ResourceResult `json:"resources"`
+}
+
+const (
+	KindUser       = "user"
+	KindTeam       = "team"
+	KindFolder     = "folder"
+	KindDashboard  = "dashboard"
+	KindDataSource = "datasource"
+	KindAlertRule  = "alert-rule"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Version    int       `json:"version"`
+	OrgName    string    `json:"orgName"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// BuildVersion is the version of Grafana the archive was exported from.
+	BuildVersion string `json:"buildVersion"`
+	// SecretsKeyCheck is a known value encrypted with the secrets key, so an import can tell a wrong key from corrupted
+	// secrets. It's empty if the secrets were not exported.
+	SecretsKeyCheck string         `json:"secretsKeyCheck,omitempty"`
+	Counts          map[string]int `json:"counts"`
+}
+
+// Archive is the content of an archive of an org.
+type Archive struct {
+	Manifest        Manifest
+	Users           []User
+	Teams           []Team
+	Folders         []Folder
+	Dashboards      []Dashboard
+	DataSources     []DataSource
+	AlertRuleGroups []definitions.AlertRuleGroup
+}
+
+// User is a member of the org. Users are matched by email, and the users who don't exist are created without a
+// password, so they have to reset it or log in with an external provider.
+type User struct {
+	Login      string       `json:"login"`
+	Email      string       `json:"email"`
+	Name       string       `json:"name"`
+	Role       org.RoleType `json:"role"`
+	IsDisabled bool         `json:"isDisabled,omitempty"`
+}
+
+// Team is matched by name, the members of the archive are added to the existing team.
+type Team struct {
+	Name    string       `json:"name"`
+	Email   string       `json:"email,omitempty"`
+	Members []TeamMember `json:"members,omitempty"`
+}
+
+type TeamMember struct {
+	Login      string              `json:"login"`
+	Permission team.PermissionType `json:"permission"`
+}
+
+type Folder struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ParentUID   string `json:"parentUid,omitempty"`
+}
+
+type Dashboard struct {
+	UID       string           `json:"uid"`
+	Title     string           `json:"title"`
+	FolderUID string           `json:"folderUid,omitempty"`
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+type DataSource struct {
+	UID             string               `json:"uid"`
+	Name            string               `json:"name"`
+	Type            string               `json:"type"`
+	Access          datasources.DsAccess `json:"access"`
+	URL             string               `json:"url,omitempty"`
+	User            string               `json:"user,omitempty"`
+	Database        string               `json:"database,omitempty"`
+	BasicAuth       bool                 `json:"basicAuth,omitempty"`
+	BasicAuthUser   string               `json:"basicAuthUser,omitempty"`
+	WithCredentials bool                 `json:"withCredentials,omitempty"`
+	IsDefault       bool                 `json:"isDefault,omitempty"`
+	ReadOnly        bool                 `json:"readOnly,omitempty"`
+	JsonData        *simplejson.Json     `json:"jsonData,omitempty"`
+	// SecureJsonData are the secrets of the data source encrypted with the secrets key, base64 encoded.
+	SecureJsonData map[string]string `json:"secureJsonData,omitempty"`
+}
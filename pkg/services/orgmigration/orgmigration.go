@@ -0,0 +1,79 @@
+package orgmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// RuleStore is the part of the alert rule store the migration reads and writes the alert rules with.
+type RuleStore interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+	InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRule) ([]ngmodels.AlertRuleKeyWithId, error)
+	UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateRule) error
+}
+
+// Service exports an org, with its users, teams, folders, dashboards, data sources and alert rules, into a portable
+// archive, and imports an archive into an org of another instance.
+type Service struct {
+	buildVersion      string
+	db                db.DB
+	orgService        org.Service
+	userService       user.Service
+	teamService       team.Service
+	teamPermissions   accesscontrol.TeamPermissionsService
+	folderService     folder.Service
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	ruleStore         RuleStore
+	log               log.Logger
+	now               func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, orgService org.Service, userService user.Service, teamService team.Service,
+	teamPermissions accesscontrol.TeamPermissionsService, folderService folder.Service,
+	dashboardService dashboards.DashboardService, dataSourceService datasources.DataSourceService,
+	ruleStore *ngstore.DBstore) *Service {
+	return &Service{
+		buildVersion:      cfg.BuildVersion,
+		db:                db,
+		orgService:        orgService,
+		userService:       userService,
+		teamService:       teamService,
+		teamPermissions:   teamPermissions,
+		folderService:     folderService,
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		ruleStore:         ruleStore,
+		log:               log.New("orgmigration"),
+		now:               time.Now,
+	}
+}
+
+// requester is the identity the resources of the org are read and written with.
+func requester(orgID int64) identity.Requester {
+	return accesscontrol.BackgroundUser("org_migration", orgID, org.RoleAdmin, []accesscontrol.Permission{
+		{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionFoldersCreate, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersAll},
+		{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+		{Action: datasources.ActionRead, Scope: datasources.ScopeAll},
+	})
+}
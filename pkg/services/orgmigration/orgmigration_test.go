@@ -0,0 +1,219 @@
+package orgmigration
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestArchive(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{"uid":"dash","title":"Dashboard"}`))
+	require.NoError(t, err)
+	archive := &Archive{
+		Manifest:   newManifest("Main Org.", "11.0.0", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Users:      []User{{Login: "admin", Role: org.RoleAdmin}},
+		Folders:    []Folder{{UID: "folder", Title: "Folder"}},
+		Dashboards: []Dashboard{{UID: "dash", Title: "Dashboard", FolderUID: "folder", Dashboard: dashboard}},
+		AlertRuleGroups: []definitions.AlertRuleGroup{{
+			Title:     "group",
+			FolderUID: "folder",
+			Interval:  60,
+			Rules:     []definitions.ProvisionedAlertRule{{UID: "rule", Title: "Rule"}},
+		}},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		require.NoError(t, writeArchive(&buf, archive))
+
+		read, err := readArchive(&buf)
+		require.NoError(t, err)
+		require.Equal(t, archive.Manifest, read.Manifest)
+		require.Equal(t, archive.Users, read.Users)
+		require.Equal(t, archive.Folders, read.Folders)
+		require.Equal(t, "Dashboard", read.Dashboards[0].Dashboard.Get("title").MustString())
+		require.Equal(t, "rule", read.AlertRuleGroups[0].Rules[0].UID)
+	})
+
+	t.Run("later version is rejected", func(t *testing.T) {
+		later := *archive
+		later.Manifest.Version = archiveVersion + 1
+		buf := bytes.Buffer{}
+		require.NoError(t, writeArchive(&buf, &later))
+
+		_, err := readArchive(&buf)
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		_, err := readArchive(bytes.NewBufferString("not an archive"))
+		require.Error(t, err)
+	})
+}
+
+func TestSecrets(t *testing.T) {
+	encrypted, err := encryptSecrets(map[string]string{"password": "secret"}, "key")
+	require.NoError(t, err)
+	require.NotEqual(t, "secret", encrypted["password"])
+
+	decrypted, err := decryptSecrets(encrypted, "key")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"password": "secret"}, decrypted)
+
+	_, err = decryptSecrets(encrypted, "other key")
+	require.Error(t, err)
+
+	check, err := encryptSecret(secretsKeyCheck, "key")
+	require.NoError(t, err)
+	manifest := Manifest{SecretsKeyCheck: check}
+	require.NoError(t, checkSecretsKey(manifest, "key"))
+	require.ErrorIs(t, checkSecretsKey(manifest, ""), ErrSecretsKeyRequired)
+	require.ErrorIs(t, checkSecretsKey(manifest, "other key"), ErrInvalidSecretsKey)
+	require.NoError(t, checkSecretsKey(Manifest{}, ""))
+}
+
+func TestParseCollisionPolicy(t *testing.T) {
+	p, err := ParseCollisionPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, CollisionFail, p)
+
+	p, err = ParseCollisionPolicy("new-uid")
+	require.NoError(t, err)
+	require.Equal(t, CollisionNewUID, p)
+
+	_, err = ParseCollisionPolicy("merge")
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestPlan(t *testing.T) {
+	archive := &Archive{
+		Users:       []User{{Login: "existing", Email: "existing@example.com"}, {Login: "new", Email: "new@example.com"}},
+		Folders:     []Folder{{UID: "folder", Title: "Folder"}, {UID: "other-folder", Title: "Other"}},
+		Dashboards:  []Dashboard{{UID: "dash", Title: "Dashboard"}},
+		DataSources: []DataSource{{UID: "prom", Name: "Prometheus"}, {UID: "loki-archive", Name: "Loki"}},
+		AlertRuleGroups: []definitions.AlertRuleGroup{{
+			Rules: []definitions.ProvisionedAlertRule{{UID: "rule", Title: "Rule"}},
+		}},
+	}
+	state := &orgState{
+		users:      map[string]int64{"existing": 1},
+		teams:      map[string]int64{},
+		folders:    map[string]bool{"folder": true},
+		dashboards: map[string]bool{"dash": true},
+		dataSources: map[string]*datasources.DataSource{
+			"prom": {UID: "prom", Name: "Prometheus"},
+			"loki": {UID: "loki", Name: "Loki"},
+		},
+		dataSourceUIDs: map[string]string{"Prometheus": "prom", "Loki": "loki"},
+		rules:          map[string]*ngmodels.AlertRule{},
+	}
+	uids := 0
+	newUID := func() string {
+		uids++
+		return fmt.Sprintf("new-%d", uids)
+	}
+
+	t.Run("collisions are listed for every policy", func(t *testing.T) {
+		for _, policy := range []CollisionPolicy{CollisionFail, CollisionSkip, CollisionOverwrite, CollisionNewUID} {
+			p := newPlan(archive, state, policy, newUID)
+			require.Len(t, p.collisions, 4, policy)
+		}
+	})
+
+	t.Run("skip keeps the existing resources", func(t *testing.T) {
+		p := newPlan(archive, state, CollisionSkip, newUID)
+		require.Equal(t, ActionSkip, p.get(KindFolder, "folder").action)
+		require.Equal(t, ActionCreate, p.get(KindFolder, "other-folder").action)
+		require.Equal(t, ActionSkip, p.get(KindDashboard, "dash").action)
+		require.Equal(t, ActionCreate, p.get(KindAlertRule, "rule").action)
+		// The data source which collided by name is referenced by the UID of the existing one.
+		require.Equal(t, "loki", p.uid(KindDataSource, "loki-archive"))
+		require.Contains(t, p.results, ResourceResult{Kind: KindUser, Name: "existing", Action: ActionSkip})
+		require.Contains(t, p.results, ResourceResult{Kind: KindUser, Name: "new", Action: ActionCreate})
+	})
+
+	t.Run("existing users are listed to be merged", func(t *testing.T) {
+		p := newPlan(archive, state, CollisionSkip, newUID)
+		require.Equal(t, []string{"existing@example.com"}, p.existingUsers)
+	})
+
+	t.Run("overwrite replaces the existing resources", func(t *testing.T) {
+		p := newPlan(archive, state, CollisionOverwrite, newUID)
+		require.Equal(t, ActionOverwrite, p.get(KindFolder, "folder").action)
+		require.Equal(t, ActionOverwrite, p.get(KindDataSource, "loki-archive").action)
+		require.Equal(t, "loki", p.uid(KindDataSource, "loki-archive"))
+	})
+
+	t.Run("new-uid creates the resources with new UIDs", func(t *testing.T) {
+		uids = 0
+		p := newPlan(archive, state, CollisionNewUID, newUID)
+		require.Equal(t, decision{action: ActionCreate, uid: "new-1", name: "Folder"}, p.get(KindFolder, "folder"))
+		require.Equal(t, decision{action: ActionCreate, uid: "new-2", name: "Prometheus (imported)"}, p.get(KindDataSource, "prom"))
+		require.Equal(t, "other-folder", p.uid(KindFolder, "other-folder"))
+		require.Contains(t, p.results, ResourceResult{Kind: KindDashboard, UID: "dash", Name: "Dashboard", Action: ActionCreate, NewUID: "new-4"})
+	})
+}
+
+func TestRemap(t *testing.T) {
+	p := &plan{decisions: map[string]map[string]decision{
+		KindFolder:     {"folder": {action: ActionCreate, uid: "new-folder"}},
+		KindDashboard:  {"dash": {action: ActionCreate, uid: "new-dash"}},
+		KindDataSource: {"prom": {action: ActionCreate, uid: "new-prom"}},
+	}}
+
+	t.Run("dashboard", func(t *testing.T) {
+		data, err := simplejson.NewJson([]byte(`{
+			"id": 12,
+			"uid": "dash",
+			"panels": [
+				{"datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"datasource": {"uid": "prom"}}]},
+				{"datasource": {"uid": "loki"}}
+			],
+			"templating": {"list": [{"datasource": {"uid": "prom"}}]}
+		}`))
+		require.NoError(t, err)
+
+		remapDashboard(data, "new-dash", p)
+
+		out, err := data.MarshalJSON()
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"uid": "new-dash",
+			"panels": [
+				{"datasource": {"type": "prometheus", "uid": "new-prom"}, "targets": [{"datasource": {"uid": "new-prom"}}]},
+				{"datasource": {"uid": "loki"}}
+			],
+			"templating": {"list": [{"datasource": {"uid": "new-prom"}}]}
+		}`, string(out))
+	})
+
+	t.Run("alert rule", func(t *testing.T) {
+		rule := ngmodels.AlertRule{
+			ID:          3,
+			OrgID:       2,
+			Data:        []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prom"}, {RefID: "B", DatasourceUID: "__expr__"}},
+			Annotations: map[string]string{ngmodels.DashboardUIDAnnotation: "dash"},
+		}
+		group := ngmodels.AlertRuleGroup{Title: "group", FolderUID: "folder", Interval: 60}
+
+		remapAlertRule(&rule, 1, group, p)
+
+		require.Zero(t, rule.ID)
+		require.Equal(t, int64(1), rule.OrgID)
+		require.Equal(t, "new-folder", rule.NamespaceUID)
+		require.Equal(t, "group", rule.RuleGroup)
+		require.Equal(t, int64(60), rule.IntervalSeconds)
+		require.Equal(t, "new-prom", rule.Data[0].DatasourceUID)
+		require.Equal(t, "__expr__", rule.Data[1].DatasourceUID)
+		require.Equal(t, "new-dash", rule.Annotations[ngmodels.DashboardUIDAnnotation])
+	})
+}
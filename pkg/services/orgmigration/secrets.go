@@ -0,0 +1,118 @@
+package orgmigration
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// secretsKeyCheck is encrypted in the manifest to check the secrets key of an import.
+	secretsKeyCheck = "grafana-org-migration"
+	saltLength      = 16
+)
+
+// newCipher returns the AES-256-GCM cipher of the secrets key, derived with the salt.
+func newCipher(key string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(key), salt, 10000, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptSecret encrypts a secret with the secrets key. The encrypted secret is the salt of the key, the nonce and the
+// sealed secret, base64 encoded.
+func encryptSecret(value, key string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	gcm, err := newCipher(key, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	encrypted := append(salt, nonce...)
+	encrypted = gcm.Seal(encrypted, nonce, []byte(value), nil)
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func decryptSecret(value, key string) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	if len(encrypted) < saltLength {
+		return "", errors.New("encrypted secret is too short")
+	}
+	gcm, err := newCipher(key, encrypted[:saltLength])
+	if err != nil {
+		return "", err
+	}
+	encrypted = encrypted[saltLength:]
+	if len(encrypted) < gcm.NonceSize() {
+		return "", errors.New("encrypted secret is too short")
+	}
+	decrypted, err := gcm.Open(nil, encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
+
+func encryptSecrets(values map[string]string, key string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	encrypted := make(map[string]string, len(values))
+	for k, v := range values {
+		e, err := encryptSecret(v, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %w", k, err)
+		}
+		encrypted[k] = e
+	}
+	return encrypted, nil
+}
+
+func decryptSecrets(values map[string]string, key string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	decrypted := make(map[string]string, len(values))
+	for k, v := range values {
+		d, err := decryptSecret(v, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", k, err)
+		}
+		decrypted[k] = d
+	}
+	return decrypted, nil
+}
+
+// checkSecretsKey returns an error if the archive has secrets which can't be decrypted with the key. The key is
+// ignored if the archive has no secrets.
+func checkSecretsKey(manifest Manifest, key string) error {
+	if manifest.SecretsKeyCheck == "" {
+		return nil
+	}
+	if key == "" {
+		return ErrSecretsKeyRequired
+	}
+	check, err := decryptSecret(manifest.SecretsKeyCheck, key)
+	if err != nil || check != secretsKeyCheck {
+		return ErrInvalidSecretsKey
+	}
+	return nil
+}
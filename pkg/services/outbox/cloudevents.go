@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// cloudEventsTypePrefix prefixes the outbox event types in the type attribute of CloudEvents.
+const cloudEventsTypePrefix = "com.grafana."
+
+// mainOrgID is the organization of the credentials referenced by the settings of the sink.
+const mainOrgID = 1
+
+// cloudEvent is a CloudEvent v1.0 in the structured JSON format.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// credentialResolver resolves the references to the credentials managed by Grafana, see credentials.Service.
+type credentialResolver interface {
+	Resolve(ctx context.Context, orgID int64, value string) (string, error)
+}
+
+// cloudEventsSink sends the events of the outbox to an HTTP endpoint receiving CloudEvents, such as a
+// Knative broker.
+type cloudEventsSink struct {
+	client *http.Client
+	url    string
+	source string
+	// authorization is the Authorization header of the requests, whose credential references are resolved
+	// on every request so that the rotated secrets are sent.
+	authorization string
+	credentials   credentialResolver
+}
+
+func newCloudEventsSink(url string, source string, authorization string, credentials credentialResolver) *cloudEventsSink {
+	return &cloudEventsSink{
+		client:        &http.Client{Timeout: 30 * time.Second},
+		url:           url,
+		source:        source,
+		authorization: authorization,
+		credentials:   credentials,
+	}
+}
+
+func (s *cloudEventsSink) send(ctx context.Context, e *sqlstore.OutboxEvent) error {
+	ce := cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              strconv.FormatInt(e.ID, 10),
+		Source:          s.source,
+		Type:            cloudEventsTypePrefix + e.EventType,
+		Time:            time.Unix(e.Created, 0).UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data:            json.RawMessage(e.Payload),
+	}
+	if e.OrgID > 0 {
+		ce.Subject = fmt.Sprintf("orgs/%d", e.OrgID)
+	}
+
+	body, err := json.Marshal(ce)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+	if s.authorization != "" {
+		authorization, err := s.resolveAuthorization(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// resolveAuthorization resolves the credentials referenced by the Authorization header, which belong to the
+// main organization since the sink receives the events of all the organizations.
+func (s *cloudEventsSink) resolveAuthorization(ctx context.Context) (string, error) {
+	if s.credentials == nil {
+		return s.authorization, nil
+	}
+	authorization, err := s.credentials.Resolve(ctx, mainOrgID, s.authorization)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the authorization of the sink: %w", err)
+	}
+	return authorization, nil
+}
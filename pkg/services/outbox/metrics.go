@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	published *prometheus.CounterVec
+	lag       prometheus.Histogram
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		published: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "outbox",
+			Name:      "publish_attempts_total",
+			Help:      "Number of attempts to publish the events of the outbox by result.",
+		}, []string{"result"}),
+		lag: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "outbox",
+			Name:      "delivery_lag_seconds",
+			Help:      "Time between the commit of events to the outbox and their publication.",
+			Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+		}),
+	}
+}
@@ -0,0 +1,250 @@
+// Package outbox publishes the events appended to the transactional outbox of the SQL store: on the bus, on
+// Grafana Live channels, and to a CloudEvents sink. Events are deleted from the outbox once they are published,
+// and retried with a backoff otherwise, so they are delivered at least once, even when Grafana stops after
+// committing them. Receivers should tolerate duplicates.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/credentials"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// claimLease is how long an event is reserved for the instance publishing it, before another instance
+	// may publish it again.
+	claimLease = time.Minute
+	// minRetryBackoff is the delay before the first retry of an event.
+	minRetryBackoff = time.Second
+)
+
+var (
+	eventTypesMtx sync.RWMutex
+	eventTypes    = map[string]reflect.Type{}
+)
+
+// RegisterEvent registers the types of the events published on the bus from the outbox, for example
+// RegisterEvent(&events.UserCreated{}). Events of types that aren't registered are only published on
+// Grafana Live and to the CloudEvents sink.
+func RegisterEvent(msgs ...any) {
+	eventTypesMtx.Lock()
+	defer eventTypesMtx.Unlock()
+	for _, msg := range msgs {
+		eventTypes[sqlstore.OutboxEventType(msg)] = reflect.TypeOf(msg).Elem()
+	}
+}
+
+func eventType(name string) (reflect.Type, bool) {
+	eventTypesMtx.RLock()
+	defer eventTypesMtx.RUnlock()
+	t, ok := eventTypes[name]
+	return t, ok
+}
+
+type livePublisher interface {
+	Publish(orgID int64, channel string, data []byte) error
+}
+
+// outboxNotifier notifies when events are committed to the outbox.
+type outboxNotifier interface {
+	OutboxNotifications() <-chan struct{}
+}
+
+// Service dispatches the events of the outbox.
+type Service struct {
+	store      db.DB
+	bus        bus.Bus
+	live       livePublisher
+	cloudEvent *cloudEventsSink
+	settings   setting.OutboxSettings
+	metrics    *metrics
+	log        log.Logger
+	now        func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, grafanaLive *live.GrafanaLive, registerer prometheus.Registerer,
+	credentialsService *credentials.Service,
+) *Service {
+	// the events appended to the outbox by the services
+	RegisterEvent(&events.DataSourceDeleted{})
+
+	s := &Service{
+		store:    sqlStore,
+		bus:      bus,
+		settings: cfg.Outbox,
+		metrics:  newMetrics(registerer),
+		log:      log.New("outbox"),
+		now:      time.Now,
+	}
+	if s.settings.PollInterval <= 0 {
+		s.settings.PollInterval = 5 * time.Second
+	}
+	if s.settings.BatchSize <= 0 {
+		s.settings.BatchSize = 100
+	}
+	if s.settings.MaxRetryBackoff < minRetryBackoff {
+		s.settings.MaxRetryBackoff = minRetryBackoff
+	}
+	if s.settings.PublishToLive && grafanaLive != nil {
+		s.live = grafanaLive
+	}
+	if s.settings.CloudEventsSinkURL != "" {
+		s.cloudEvent = newCloudEventsSink(s.settings.CloudEventsSinkURL, s.settings.CloudEventsSource,
+			s.settings.CloudEventsSinkAuthorization, credentialsService)
+	}
+	return s
+}
+
+// Run publishes the events of the outbox until ctx is done: right away for the events committed by this
+// instance, and on every poll for the others and for the retries.
+func (s *Service) Run(ctx context.Context) error {
+	var notifications <-chan struct{}
+	if n, ok := s.store.(outboxNotifier); ok {
+		notifications = n.OutboxNotifications()
+	}
+
+	ticker := time.NewTicker(s.settings.PollInterval)
+	defer ticker.Stop()
+	for {
+		s.dispatchAll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-notifications:
+		}
+	}
+}
+
+// dispatchAll publishes batches of due events until fewer than a full batch is due.
+func (s *Service) dispatchAll(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := s.dispatchDue(ctx)
+		if err != nil {
+			s.log.Error("Failed to dispatch the outbox events", "error", err)
+			return
+		}
+		if n < s.settings.BatchSize {
+			return
+		}
+	}
+}
+
+// dispatchDue claims a batch of due events and publishes them, and returns how many were claimed.
+func (s *Service) dispatchDue(ctx context.Context) (int, error) {
+	events, err := s.claimDue(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range events {
+		if err := s.publish(ctx, e); err != nil {
+			s.metrics.published.WithLabelValues("failure").Inc()
+			s.retryLater(ctx, e, err)
+			continue
+		}
+		s.metrics.published.WithLabelValues("success").Inc()
+		s.metrics.lag.Observe(s.now().Sub(time.Unix(e.Created, 0)).Seconds())
+		if err := s.delete(ctx, e.ID); err != nil {
+			// the event will be published again once its claim expires
+			s.log.Warn("Failed to delete published outbox event", "id", e.ID, "type", e.EventType, "error", err)
+		}
+	}
+	return len(events), nil
+}
+
+// publish publishes an event on the bus, Grafana Live and to the CloudEvents sink. An event that fails to
+// publish anywhere is published everywhere again on the next attempt.
+func (s *Service) publish(ctx context.Context, e *sqlstore.OutboxEvent) error {
+	if t, ok := eventType(e.EventType); ok {
+		msg := reflect.New(t).Interface()
+		if err := json.Unmarshal([]byte(e.Payload), msg); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		if err := s.bus.Publish(ctx, msg); err != nil {
+			return fmt.Errorf("failed to publish event on the bus: %w", err)
+		}
+	}
+
+	if s.live != nil && e.OrgID > 0 {
+		if err := s.live.Publish(e.OrgID, features.EventsChannelPrefix+e.EventType, []byte(e.Payload)); err != nil {
+			return fmt.Errorf("failed to publish event on Grafana Live: %w", err)
+		}
+	}
+
+	if s.cloudEvent != nil {
+		if err := s.cloudEvent.send(ctx, e); err != nil {
+			return fmt.Errorf("failed to send CloudEvent: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) retryLater(ctx context.Context, e *sqlstore.OutboxEvent, cause error) {
+	attempts := e.Attempts + 1
+	backoff := s.settings.MaxRetryBackoff
+	if attempts < 32 {
+		if d := minRetryBackoff << (attempts - 1); d < backoff {
+			backoff = d
+		}
+	}
+	s.log.Warn("Failed to publish outbox event, retrying later", "id", e.ID, "type", e.EventType, "attempts", attempts, "retryIn", backoff, "error", cause)
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE outbox_event SET attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?",
+			attempts, cause.Error(), s.now().Add(backoff).Unix(), e.ID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("Failed to schedule the retry of outbox event", "id", e.ID, "error", err)
+	}
+}
+
+// claimDue reserves the due events for this instance, so that other instances don't publish them at the
+// same time, unless the claim expires.
+func (s *Service) claimDue(ctx context.Context) ([]*sqlstore.OutboxEvent, error) {
+	now := s.now().Unix()
+	claimed := make([]*sqlstore.OutboxEvent, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		due := make([]*sqlstore.OutboxEvent, 0)
+		if err := sess.Where("next_attempt_at <= ?", now).Asc("id").Limit(s.settings.BatchSize).Find(&due); err != nil {
+			return err
+		}
+
+		leaseUntil := s.now().Add(claimLease).Unix()
+		for _, e := range due {
+			res, err := sess.Exec("UPDATE outbox_event SET next_attempt_at = ? WHERE id = ? AND next_attempt_at = ?", leaseUntil, e.ID, e.NextAttemptAt)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err != nil || n != 1 {
+				continue // claimed by another instance
+			}
+			claimed = append(claimed, e)
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (s *Service) delete(ctx context.Context, id int64) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM outbox_event WHERE id = ?", id)
+		return err
+	})
+}
@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func TestIntegrationOutbox(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	RegisterEvent(&testEvent{})
+
+	now := time.Unix(1700000000, 0)
+	newService := func(t *testing.T) (*Service, *sqlstore.SQLStore, *bus.InProcBus) {
+		store := db.InitTestDB(t)
+		b := bus.ProvideBus(tracing.InitializeTracerForTest())
+		return &Service{
+			store:    store,
+			bus:      b,
+			settings: setting.OutboxSettings{PollInterval: time.Second, BatchSize: 10, MaxRetryBackoff: time.Minute},
+			metrics:  newMetrics(nil),
+			log:      log.NewNopLogger(),
+			now:      func() time.Time { return now },
+		}, store, b
+	}
+	appendEvent := func(t *testing.T, store *sqlstore.SQLStore, name string, fail bool) {
+		err := store.WithTransactionalDbSession(context.Background(), func(sess *db.Session) error {
+			require.NoError(t, sess.AppendOutboxEvent(1, &testEvent{Name: name}))
+			if fail {
+				return errors.New("rolled back")
+			}
+			return nil
+		})
+		require.Equal(t, fail, err != nil)
+	}
+	pending := func(t *testing.T, store *sqlstore.SQLStore) []*sqlstore.OutboxEvent {
+		events := make([]*sqlstore.OutboxEvent, 0)
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.Find(&events)
+		}))
+		return events
+	}
+
+	t.Run("publishes committed events on the bus and deletes them", func(t *testing.T) {
+		s, store, b := newService(t)
+		var received []string
+		b.AddEventListener(func(ctx context.Context, e *testEvent) error {
+			received = append(received, e.Name)
+			return nil
+		})
+
+		appendEvent(t, store, "committed", false)
+		appendEvent(t, store, "rolled back", true)
+		select {
+		case <-store.OutboxNotifications():
+		default:
+			t.Fatal("expected a notification for the committed event")
+		}
+
+		n, err := s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []string{"committed"}, received)
+		require.Empty(t, pending(t, store))
+	})
+
+	t.Run("retries failed events with a backoff", func(t *testing.T) {
+		s, store, b := newService(t)
+		failures := 2
+		b.AddEventListener(func(ctx context.Context, e *testEvent) error {
+			if failures > 0 {
+				failures--
+				return errors.New("listener failed")
+			}
+			return nil
+		})
+		appendEvent(t, store, "event", false)
+
+		_, err := s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		events := pending(t, store)
+		require.Len(t, events, 1)
+		require.Equal(t, 1, events[0].Attempts)
+		require.Contains(t, events[0].LastError, "listener failed")
+		require.Equal(t, now.Add(time.Second).Unix(), events[0].NextAttemptAt)
+
+		n, err := s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, n, "the event isn't due yet")
+
+		now = now.Add(time.Second)
+		_, err = s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		events = pending(t, store)
+		require.Equal(t, 2, events[0].Attempts)
+		require.Equal(t, now.Add(2*time.Second).Unix(), events[0].NextAttemptAt)
+
+		now = now.Add(2 * time.Second)
+		_, err = s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, pending(t, store))
+	})
+
+	t.Run("sends CloudEvents to the sink", func(t *testing.T) {
+		var received cloudEvent
+		sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "application/cloudevents+json; charset=utf-8", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		t.Cleanup(sink.Close)
+
+		s, store, _ := newService(t)
+		s.cloudEvent = newCloudEventsSink(sink.URL, "https://grafana.example.com/", "", nil)
+		appendEvent(t, store, "event", false)
+
+		_, err := s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "1.0", received.SpecVersion)
+		require.Equal(t, "com.grafana.testEvent", received.Type)
+		require.Equal(t, "https://grafana.example.com/", received.Source)
+		require.Equal(t, "orgs/1", received.Subject)
+		require.JSONEq(t, `{"name":"event"}`, string(received.Data))
+		require.Empty(t, pending(t, store))
+	})
+
+	t.Run("resolves the credentials of the Authorization header of the sink", func(t *testing.T) {
+		var authorization string
+		sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		t.Cleanup(sink.Close)
+
+		s, store, _ := newService(t)
+		s.cloudEvent = newCloudEventsSink(sink.URL, "https://grafana.example.com/", "Bearer $__credential{sink}", fakeCredentials{"Bearer $__credential{sink}": "Bearer secret"})
+		appendEvent(t, store, "event", false)
+
+		_, err := s.dispatchDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "Bearer secret", authorization)
+		require.Empty(t, pending(t, store))
+	})
+}
+
+type fakeCredentials map[string]string
+
+func (f fakeCredentials) Resolve(_ context.Context, orgID int64, value string) (string, error) {
+	if orgID != mainOrgID {
+		return "", errors.New("unexpected organization")
+	}
+	return f[value], nil
+}
@@ -0,0 +1,21 @@
+package playlist
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	ScopeRoot = "playlists"
+
+	ActionCreate           = "playlists:create"
+	ActionRead             = "playlists:read"
+	ActionWrite            = "playlists:write"
+	ActionDelete           = "playlists:delete"
+	ActionPermissionsRead  = "playlists.permissions:read"
+	ActionPermissionsWrite = "playlists.permissions:write"
+)
+
+var (
+	ScopeProvider = ac.NewScopeProvider(ScopeRoot)
+	ScopeAll      = ScopeProvider.GetResourceAllScope()
+)
@@ -0,0 +1,70 @@
+package clientmiddleware
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/util/proxyutil"
+)
+
+// NewForwardHeadersMiddleware creates a new plugins.ClientMiddleware that will
+// forward the headers configured in the header forwarding policy of a data source
+// on outgoing plugins.Client and HTTP requests.
+func NewForwardHeadersMiddleware() plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &ForwardHeadersMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			log: log.New("forward_headers_middleware"),
+		}
+	})
+}
+
+type ForwardHeadersMiddleware struct {
+	baseMiddleware
+
+	log log.Logger
+}
+
+func (m *ForwardHeadersMiddleware) applyHeaders(ctx context.Context, pCtx backend.PluginContext, req backend.ForwardHTTPHeaders) {
+	reqCtx := contexthandler.FromContext(ctx)
+	// If no HTTP request context or data source then skip middleware.
+	if req == nil || reqCtx == nil || reqCtx.Req == nil || pCtx.DataSourceInstanceSettings == nil {
+		return
+	}
+
+	ds := pCtx.DataSourceInstanceSettings
+	policy, err := proxyutil.ParseForwardHeadersPolicy(ds.JSONData)
+	if err != nil {
+		m.log.FromContext(ctx).Warn("Invalid header forwarding policy", "datasource", ds.UID, "error", err)
+		return
+	}
+
+	data := proxyutil.NewForwardHeadersData(ctx, reqCtx.Req.Header, reqCtx.SignedInUser, ds.UID)
+	if err := policy.Apply(data, req.SetHTTPHeader); err != nil {
+		m.log.FromContext(ctx).Warn("Failed to forward headers", "datasource", ds.UID, "error", err)
+	}
+}
+
+func (m *ForwardHeadersMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	m.applyHeaders(ctx, req.PluginContext, req)
+	return m.next.QueryData(ctx, req)
+}
+
+func (m *ForwardHeadersMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if req == nil {
+		return m.next.CheckHealth(ctx, req)
+	}
+
+	m.applyHeaders(ctx, req.PluginContext, req)
+	return m.next.CheckHealth(ctx, req)
+}
@@ -0,0 +1,236 @@
+package clientmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources/labelaccess"
+	"github.com/grafana/grafana/pkg/services/live/livecontext"
+)
+
+// NewLabelAccessMiddleware creates a new plugins.ClientMiddleware that will
+// restrict the queries and the label lookups of Prometheus and Loki datasources
+// with label access rules to the series the rules grant the signed-in user.
+// It must precede the caching middleware, as it changes the queries.
+func NewLabelAccessMiddleware() plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &LabelAccessMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+		}
+	})
+}
+
+var errResourceNotAllowed = errors.New("resource not allowed")
+
+type LabelAccessMiddleware struct {
+	baseMiddleware
+}
+
+// matchers returns the label matchers restricting the requests of the signed-in user, if any.
+func (m *LabelAccessMiddleware) matchers(ctx context.Context, pCtx backend.PluginContext) ([]*labels.Matcher, error) {
+	if pCtx.DataSourceInstanceSettings == nil || !labelaccess.SupportsPlugin(pCtx.PluginID) {
+		return nil, nil
+	}
+	// the user is in the HTTP request context, or in the Live context for streams
+	var user identity.Requester
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SignedInUser != nil {
+		user = reqCtx.SignedInUser
+	} else if liveUser, ok := livecontext.GetContextSignedUser(ctx); ok {
+		user = liveUser
+	} else {
+		return nil, nil
+	}
+
+	rules, err := labelaccess.FromInstanceSettings(pCtx.DataSourceInstanceSettings)
+	if err != nil {
+		return nil, err
+	}
+	return rules.MatchersFor(user)
+}
+
+func restrictQueries(pluginID string, queries []backend.DataQuery, matchers []*labels.Matcher) error {
+	for i, q := range queries {
+		model := map[string]any{}
+		if err := json.Unmarshal(q.JSON, &model); err != nil {
+			return err
+		}
+		if pluginID == "loki" {
+			expr, _ := model["expr"].(string)
+			if expr == "" {
+				continue
+			}
+			restricted, err := labelaccess.InjectLogQL(expr, matchers)
+			if err != nil {
+				return err
+			}
+			model["expr"] = restricted
+		} else {
+			// PromQL can only be parsed once the datasource has interpolated the Grafana variables,
+			// so the datasource injects the selector itself.
+			model["labelAccessSelector"] = labelaccess.Selector(matchers)
+		}
+
+		b, err := json.Marshal(model)
+		if err != nil {
+			return err
+		}
+		queries[i].JSON = b
+	}
+	return nil
+}
+
+// unrestrictedResources are the resources of the datasources which return no series data, so they are
+// allowed to restricted users as they are.
+var unrestrictedResources = map[string][]string{
+	"prometheus": {"api/v1/status/buildinfo", "api/v1/metadata"},
+	"loki":       {"status/buildinfo"},
+}
+
+// selectorParam returns the parameter holding the series selectors of a resource of the datasource, if any.
+func selectorParam(pluginID string, path string) (string, bool) {
+	path = strings.TrimPrefix(path, "/")
+	if pluginID == "prometheus" {
+		if path == "api/v1/series" || path == "api/v1/labels" || strings.HasPrefix(path, "api/v1/label/") {
+			return "match[]", true
+		}
+		return "", false
+	}
+
+	switch {
+	case path == "series":
+		return "match[]", true
+	case path == "labels" || strings.HasPrefix(path, "label/") || strings.HasPrefix(path, "index/") ||
+		strings.HasPrefix(path, "detected_") || path == "patterns":
+		return "query", true
+	}
+	return "", false
+}
+
+func restrictValues(values url.Values, pluginID string, param string, matchers []*labels.Matcher) error {
+	selectors := values[param]
+	if len(selectors) == 0 {
+		values.Set(param, labelaccess.Selector(matchers))
+		return nil
+	}
+	for i, selector := range selectors {
+		var err error
+		if pluginID == "loki" {
+			selectors[i], err = labelaccess.InjectLogQL(selector, matchers)
+		} else {
+			selectors[i], err = labelaccess.InjectSeriesSelector(selector, matchers)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restrictResource restricts the series selectors of a resource request. The resources which are neither
+// restricted nor known to return no series data, such as the instant and range queries, are denied.
+func restrictResource(req *backend.CallResourceRequest, matchers []*labels.Matcher) error {
+	param, ok := selectorParam(req.PluginContext.PluginID, req.Path)
+	if !ok {
+		if slices.Contains(unrestrictedResources[req.PluginContext.PluginID], strings.TrimPrefix(req.Path, "/")) {
+			return nil
+		}
+		return errResourceNotAllowed
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+	values := u.Query()
+	if err := restrictValues(values, req.PluginContext.PluginID, param, matchers); err != nil {
+		return err
+	}
+	u.RawQuery = values.Encode()
+	req.URL = u.String()
+
+	contentType := ""
+	for k, v := range req.Headers {
+		if strings.EqualFold(k, "Content-Type") && len(v) > 0 {
+			contentType = v[0]
+		}
+	}
+	if len(req.Body) > 0 && strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(req.Body))
+		if err != nil {
+			return err
+		}
+		if err := restrictValues(form, req.PluginContext.PluginID, param, matchers); err != nil {
+			return err
+		}
+		req.Body = []byte(form.Encode())
+	}
+	return nil
+}
+
+func (m *LabelAccessMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	matchers, err := m.matchers(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchers) > 0 {
+		if err := restrictQueries(req.PluginContext.PluginID, req.Queries, matchers); err != nil {
+			return nil, labelaccess.ErrAccessDenied.Errorf("failed to apply label access rules: %w", err)
+		}
+	}
+
+	return m.next.QueryData(ctx, req)
+}
+
+func (m *LabelAccessMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if req == nil {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	matchers, err := m.matchers(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	if len(matchers) > 0 {
+		if err := restrictResource(req, matchers); err != nil {
+			if errors.Is(err, errResourceNotAllowed) {
+				return labelaccess.ErrAccessDenied.Errorf("resource %q is not allowed by the label access rules", req.Path)
+			}
+			return labelaccess.ErrAccessDenied.Errorf("failed to apply label access rules: %w", err)
+		}
+	}
+
+	return m.next.CallResource(ctx, req, sender)
+}
+
+// SubscribeStream denies streams to restricted users, since the queries of the streams are kept by the datasource.
+func (m *LabelAccessMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if req == nil {
+		return m.next.SubscribeStream(ctx, req)
+	}
+
+	matchers, err := m.matchers(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchers) > 0 {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusPermissionDenied}, nil
+	}
+
+	return m.next.SubscribeStream(ctx, req)
+}
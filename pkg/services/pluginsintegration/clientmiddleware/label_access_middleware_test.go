@@ -0,0 +1,136 @@
+package clientmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources/labelaccess"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLabelAccessMiddleware(t *testing.T) {
+	jsonData := []byte(`{"labelAccessRules": [{"teamId": 1, "selector": "{namespace=\"team-a\"}"}]}`)
+	withUser := func(u *user.SignedInUser) context.Context {
+		return context.WithValue(context.Background(), ctxkey.Key{}, &contextmodel.ReqContext{
+			Context:      &web.Context{Req: &http.Request{}},
+			SignedInUser: u,
+		})
+	}
+	member := &user.SignedInUser{OrgRole: identity.RoleViewer, Teams: []int64{1}}
+
+	t.Run("Should inject the selector in Loki queries", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(withUser(member), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "loki",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+			},
+			Queries: []backend.DataQuery{{JSON: []byte(`{"expr": "{app=\"api\"}"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"expr": "{app=\"api\", namespace=\"team-a\"}"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should pass the selector to Prometheus queries", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(withUser(member), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+			},
+			Queries: []backend.DataQuery{{JSON: []byte(`{"expr": "up"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"expr": "up", "labelAccessSelector": "{namespace=\"team-a\"}"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+
+	t.Run("Should restrict Prometheus series lookups", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+		err := cdt.Decorator.CallResource(withUser(member), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+			},
+			Path: "api/v1/series",
+			URL:  "api/v1/series?match%5B%5D=up",
+		}, nopCallResourceSender)
+		require.NoError(t, err)
+
+		u, err := url.Parse(cdt.CallResourceReq.URL)
+		require.NoError(t, err)
+		require.Equal(t, `up{namespace="team-a"}`, u.Query().Get("match[]"))
+	})
+
+	t.Run("Should deny the resources which are not restricted", func(t *testing.T) {
+		for _, path := range []string{"api/v1/query", "api/v1/query_range", "/api/v1/query_exemplars"} {
+			cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+			err := cdt.Decorator.CallResource(withUser(member), &backend.CallResourceRequest{
+				PluginContext: backend.PluginContext{
+					PluginID:                   "prometheus",
+					DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+				},
+				Path: path,
+				URL:  path + "?query=up",
+			}, nopCallResourceSender)
+			require.ErrorIs(t, err, labelaccess.ErrAccessDenied, path)
+			require.Nil(t, cdt.CallResourceReq, path)
+		}
+	})
+
+	t.Run("Should allow the resources without series data", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+		err := cdt.Decorator.CallResource(withUser(member), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+			},
+			Path: "api/v1/status/buildinfo",
+			URL:  "api/v1/status/buildinfo",
+		}, nopCallResourceSender)
+		require.NoError(t, err)
+		require.Equal(t, "api/v1/status/buildinfo", cdt.CallResourceReq.URL)
+	})
+
+	t.Run("Should deny users no rule applies to", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(withUser(&user.SignedInUser{OrgRole: identity.RoleViewer}), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "loki",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+			},
+			Queries: []backend.DataQuery{{JSON: []byte(`{"expr": "{app=\"api\"}"}`)}},
+		})
+		require.ErrorIs(t, err, labelaccess.ErrAccessDenied)
+		require.Nil(t, cdt.QueryDataReq)
+	})
+
+	t.Run("Should not restrict organization admins", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewLabelAccessMiddleware()))
+
+		_, err := cdt.Decorator.QueryData(withUser(&user.SignedInUser{OrgRole: identity.RoleAdmin}), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "loki",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: jsonData},
+			},
+			Queries: []backend.DataQuery{{JSON: []byte(`{"expr": "{app=\"api\"}"}`)}},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"expr": "{app=\"api\"}"}`, string(cdt.QueryDataReq.Queries[0].JSON))
+	})
+}
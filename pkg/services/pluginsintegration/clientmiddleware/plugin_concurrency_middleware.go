@@ -0,0 +1,75 @@
+package clientmiddleware
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// PluginLimiter limits the requests handled at once by a backend plugin.
+type PluginLimiter interface {
+	Acquire(ctx context.Context, pluginID string) (func(), error)
+}
+
+// NewPluginConcurrencyMiddleware creates a new plugins.ClientMiddleware that applies
+// the per plugin concurrency limits to queries, resource calls and health checks.
+func NewPluginConcurrencyMiddleware(limiter PluginLimiter) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &PluginConcurrencyMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			limiter: limiter,
+		}
+	})
+}
+
+type PluginConcurrencyMiddleware struct {
+	baseMiddleware
+
+	limiter PluginLimiter
+}
+
+func (m *PluginConcurrencyMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	release, err := m.limiter.Acquire(ctx, req.PluginContext.PluginID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return m.next.QueryData(ctx, req)
+}
+
+func (m *PluginConcurrencyMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if req == nil {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	release, err := m.limiter.Acquire(ctx, req.PluginContext.PluginID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *PluginConcurrencyMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if req == nil {
+		return m.next.CheckHealth(ctx, req)
+	}
+
+	release, err := m.limiter.Acquire(ctx, req.PluginContext.PluginID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return m.next.CheckHealth(ctx, req)
+}
@@ -0,0 +1,246 @@
+package clientmiddleware
+
+import (
+	"context"
+	"fmt"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// NewQueryBatchingMiddleware creates a new plugins.ClientMiddleware that coalesces the QueryData requests
+// sent to the same data source within a short window into one call, for the plugins that declare the
+// queryBatching capability.
+func NewQueryBatchingMiddleware(settings setting.PluginQueryBatchingSettings, pluginRegistry registry.Service, promRegisterer prometheus.Registerer) plugins.ClientMiddleware {
+	batchSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "plugin_query_batch_requests",
+		Help:      "Number of QueryData requests coalesced into one call to a plugin.",
+		Buckets:   []float64{1, 2, 4, 8, 16, 32, 64},
+	}, []string{"plugin_id"})
+	promRegisterer.MustRegister(batchSize)
+
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &QueryBatchingMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			settings:       settings,
+			pluginRegistry: pluginRegistry,
+			batchSize:      batchSize,
+			pending:        map[string]*queryBatch{},
+		}
+	})
+}
+
+type QueryBatchingMiddleware struct {
+	baseMiddleware
+
+	settings       setting.PluginQueryBatchingSettings
+	pluginRegistry registry.Service
+	batchSize      *prometheus.HistogramVec
+
+	mtx     sync.Mutex
+	pending map[string]*queryBatch
+}
+
+// queryBatch holds the requests coalesced into one QueryData call. The call is made with the context of
+// the first request, without its cancellation, since the other requests still wait for the response.
+type queryBatch struct {
+	key     string
+	ctx     context.Context
+	reqs    []*backend.QueryDataRequest
+	queries int
+	timer   *time.Timer
+	once    sync.Once
+
+	done  chan struct{}
+	resps []*backend.QueryDataResponse
+	err   error
+}
+
+func (m *QueryBatchingMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if !m.settings.Enabled || req == nil || len(req.Queries) == 0 || !m.supportsBatching(ctx, req.PluginContext) {
+		return m.next.QueryData(ctx, req)
+	}
+
+	b, idx := m.add(ctx, req)
+
+	select {
+	case <-b.done:
+		if b.err != nil {
+			return nil, b.err
+		}
+		return b.resps[idx], nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *QueryBatchingMiddleware) supportsBatching(ctx context.Context, pCtx backend.PluginContext) bool {
+	if pCtx.DataSourceInstanceSettings == nil {
+		return false
+	}
+	p, exists := m.pluginRegistry.Plugin(ctx, pCtx.PluginID, pCtx.PluginVersion)
+	return exists && p.QueryBatching
+}
+
+// add adds the request to the pending batch of its data source, starting a new batch if there is none,
+// and returns the batch and the index of the request in it.
+func (m *QueryBatchingMiddleware) add(ctx context.Context, req *backend.QueryDataRequest) (*queryBatch, int) {
+	key := queryBatchKey(req)
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	b, exists := m.pending[key]
+	if !exists {
+		b = &queryBatch{
+			key:  key,
+			ctx:  context.WithoutCancel(ctx),
+			done: make(chan struct{}),
+		}
+		m.pending[key] = b
+		b.timer = time.AfterFunc(m.settings.Window, func() { m.flush(b) })
+	}
+
+	b.reqs = append(b.reqs, req)
+	b.queries += len(req.Queries)
+	idx := len(b.reqs) - 1
+
+	if m.settings.MaxQueries > 0 && b.queries >= m.settings.MaxQueries {
+		b.timer.Stop()
+		delete(m.pending, key)
+		go m.flush(b)
+	}
+	return b, idx
+}
+
+func (m *QueryBatchingMiddleware) flush(b *queryBatch) {
+	b.once.Do(func() {
+		m.mtx.Lock()
+		if m.pending[b.key] == b {
+			delete(m.pending, b.key)
+		}
+		m.mtx.Unlock()
+
+		m.batchSize.WithLabelValues(b.reqs[0].PluginContext.PluginID).Observe(float64(len(b.reqs)))
+		b.resps, b.err = m.execute(b.ctx, b.reqs)
+		close(b.done)
+	})
+}
+
+// execute sends the requests in one QueryData call. The refId of each query is made unique within the
+// batch, and restored in the responses split per request.
+func (m *QueryBatchingMiddleware) execute(ctx context.Context, reqs []*backend.QueryDataRequest) ([]*backend.QueryDataResponse, error) {
+	if len(reqs) == 1 {
+		resp, err := m.next.QueryData(ctx, reqs[0])
+		return []*backend.QueryDataResponse{resp}, err
+	}
+
+	batched := &backend.QueryDataRequest{
+		PluginContext: reqs[0].PluginContext,
+		Headers:       reqs[0].Headers,
+	}
+	for i, req := range reqs {
+		for _, q := range req.Queries {
+			q.RefID = batchedRefID(i, q.RefID)
+			batched.Queries = append(batched.Queries, q)
+		}
+	}
+
+	resp, err := m.next.QueryData(ctx, batched)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := make([]*backend.QueryDataResponse, len(reqs))
+	for i, req := range reqs {
+		resps[i] = backend.NewQueryDataResponse()
+		for _, q := range req.Queries {
+			refID := batchedRefID(i, q.RefID)
+			dr, exists := resp.Responses[refID]
+			if !exists {
+				continue
+			}
+			for _, frame := range dr.Frames {
+				if frame != nil && frame.RefID == refID {
+					frame.RefID = q.RefID
+				}
+			}
+			resps[i].Responses[q.RefID] = dr
+		}
+	}
+	return resps, nil
+}
+
+func batchedRefID(idx int, refID string) string {
+	return fmt.Sprintf("%s~%d", refID, idx)
+}
+
+// queryBatchKey identifies the requests that can be sent in one call: the requests to the same data source,
+// on behalf of the same user and with the same headers, except the headers which don't change the response.
+func queryBatchKey(req *backend.QueryDataRequest) string {
+	pCtx := req.PluginContext
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s|%d|%s|%d", pCtx.PluginID, pCtx.OrgID, pCtx.DataSourceInstanceSettings.UID,
+		pCtx.DataSourceInstanceSettings.Updated.UnixNano())
+	if pCtx.User != nil {
+		fmt.Fprintf(&sb, "|%s", pCtx.User.Login)
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		if !ignoredInQueryBatchKey(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "|%s=%s", name, req.Headers[name])
+	}
+	return sb.String()
+}
+
+// queryBatchIgnoredHeaders are the headers which only identify the request, for tracing and debugging, the
+// requests which only differ by them are coalesced. The headers forwarded to the data source, such as the
+// credentials of the user or the headers of the forwarding policy, are part of the key.
+var queryBatchIgnoredHeaders = map[string]bool{
+	query.HeaderQueryGroupID:  true,
+	query.HeaderPanelID:       true,
+	query.HeaderPanelPluginId: true,
+	query.HeaderDashboardUID:  true,
+	query.HeaderDatasourceUID: true,
+	"X-Grafana-Org-Id":        true,
+	GrafanaRequestID:          true,
+	GrafanaSignedRequestID:    true,
+	"Traceparent":             true,
+	"Tracestate":              true,
+	"Baggage":                 true,
+}
+
+func ignoredInQueryBatchKey(name string) bool {
+	// the headers set with SetHTTPHeader are prefixed
+	name = textproto.CanonicalMIMEHeaderKey(strings.TrimPrefix(name, "http_"))
+	if queryBatchIgnoredHeaders[name] {
+		return true
+	}
+	// the headers of the configured trace propagators, such as uber-trace-id
+	for _, field := range otel.GetTextMapPropagator().Fields() {
+		if textproto.CanonicalMIMEHeaderKey(field) == name {
+			return true
+		}
+	}
+	return false
+}
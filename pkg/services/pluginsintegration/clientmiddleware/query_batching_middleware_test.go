@@ -0,0 +1,142 @@
+package clientmiddleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryBatchingMiddleware(t *testing.T) {
+	newMiddleware := func(t *testing.T, settings setting.PluginQueryBatchingSettings, queryBatching bool) (plugins.Client, *[]*backend.QueryDataRequest) {
+		t.Helper()
+
+		reg := registry.ProvideService()
+		require.NoError(t, reg.Add(context.Background(), &plugins.Plugin{
+			JSONData: plugins.JSONData{ID: "test-datasource", Backend: true, QueryBatching: queryBatching},
+		}))
+
+		var mtx sync.Mutex
+		var calls []*backend.QueryDataRequest
+		next := &clienttest.TestClient{
+			QueryDataFunc: func(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				mtx.Lock()
+				calls = append(calls, req)
+				mtx.Unlock()
+
+				resp := backend.NewQueryDataResponse()
+				for _, q := range req.Queries {
+					resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{
+						data.NewFrame(string(q.JSON)).SetRefID(q.RefID),
+					}}
+				}
+				return resp, nil
+			},
+		}
+		mw := NewQueryBatchingMiddleware(settings, reg, prometheus.NewRegistry())
+		return mw.CreateClientMiddleware(next), &calls
+	}
+
+	newRequest := func(expr string, headers map[string]string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				PluginID:                   "test-datasource",
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds1"},
+			},
+			Headers: headers,
+			Queries: []backend.DataQuery{{RefID: "A", JSON: []byte(expr)}},
+		}
+	}
+
+	queryConcurrently := func(t *testing.T, c plugins.Client, reqs ...*backend.QueryDataRequest) []*backend.QueryDataResponse {
+		t.Helper()
+
+		resps := make([]*backend.QueryDataResponse, len(reqs))
+		var wg sync.WaitGroup
+		for i, req := range reqs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := c.QueryData(context.Background(), req)
+				require.NoError(t, err)
+				resps[i] = resp
+			}()
+		}
+		wg.Wait()
+		return resps
+	}
+
+	t.Run("Should coalesce requests to the same data source", func(t *testing.T) {
+		c, calls := newMiddleware(t, setting.PluginQueryBatchingSettings{Enabled: true, Window: 50 * time.Millisecond}, true)
+
+		resps := queryConcurrently(t, c, newRequest("one", nil), newRequest("two", nil))
+
+		require.Len(t, *calls, 1)
+		require.Len(t, (*calls)[0].Queries, 2)
+		for i, expr := range []string{"one", "two"} {
+			require.Len(t, resps[i].Responses, 1)
+			frame := resps[i].Responses["A"].Frames[0]
+			require.Equal(t, expr, frame.Name)
+			require.Equal(t, "A", frame.RefID)
+		}
+	})
+
+	t.Run("Should send the batch once it reaches the maximum number of queries", func(t *testing.T) {
+		c, calls := newMiddleware(t, setting.PluginQueryBatchingSettings{Enabled: true, Window: time.Hour, MaxQueries: 2}, true)
+
+		queryConcurrently(t, c, newRequest("one", nil), newRequest("two", nil))
+
+		require.Len(t, *calls, 1)
+	})
+
+	t.Run("Should not coalesce requests with different headers", func(t *testing.T) {
+		c, calls := newMiddleware(t, setting.PluginQueryBatchingSettings{Enabled: true, Window: 50 * time.Millisecond}, true)
+
+		queryConcurrently(t, c,
+			newRequest("one", map[string]string{"Authorization": "Bearer a"}),
+			newRequest("two", map[string]string{"Authorization": "Bearer b"}))
+
+		require.Len(t, *calls, 2)
+		for _, call := range *calls {
+			require.Equal(t, "A", call.Queries[0].RefID)
+		}
+	})
+
+	t.Run("Should coalesce requests which only differ by the tracing headers", func(t *testing.T) {
+		c, calls := newMiddleware(t, setting.PluginQueryBatchingSettings{Enabled: true, Window: 50 * time.Millisecond}, true)
+
+		queryConcurrently(t, c,
+			newRequest("one", map[string]string{"Authorization": "Bearer a", "http_X-Panel-Id": "1", "http_traceparent": "00-a-01"}),
+			newRequest("two", map[string]string{"Authorization": "Bearer a", "http_X-Panel-Id": "2", "http_traceparent": "00-b-01"}))
+
+		require.Len(t, *calls, 1)
+	})
+
+	t.Run("Should not coalesce requests to plugins without the capability", func(t *testing.T) {
+		c, calls := newMiddleware(t, setting.PluginQueryBatchingSettings{Enabled: true, Window: time.Hour}, false)
+
+		queryConcurrently(t, c, newRequest("one", nil), newRequest("two", nil))
+
+		require.Len(t, *calls, 2)
+	})
+
+	t.Run("Should return when the context of the request is done", func(t *testing.T) {
+		c, _ := newMiddleware(t, setting.PluginQueryBatchingSettings{Enabled: true, Window: time.Hour}, true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := c.QueryData(ctx, newRequest("one", nil))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,47 @@
+package clientmiddleware
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// DataSourceLimiter limits the requests sent to a data source.
+type DataSourceLimiter interface {
+	Acquire(ctx context.Context, uid string) (func(), error)
+}
+
+// NewRateLimitMiddleware creates a new plugins.ClientMiddleware that applies
+// the per data source concurrency and rate limits to queries.
+func NewRateLimitMiddleware(limiter DataSourceLimiter) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &RateLimitMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			limiter: limiter,
+		}
+	})
+}
+
+type RateLimitMiddleware struct {
+	baseMiddleware
+
+	limiter DataSourceLimiter
+}
+
+func (m *RateLimitMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	release, err := m.limiter.Acquire(ctx, req.PluginContext.DataSourceInstanceSettings.UID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return m.next.QueryData(ctx, req)
+}
@@ -0,0 +1,103 @@
+package clientmiddleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/tokenexchange"
+)
+
+// NewTokenExchangeMiddleware creates a new plugins.ClientMiddleware that will
+// set a token exchanged for the signed-in user as the OAuth identity of outgoing
+// plugins.Client requests if the datasource has enabled token exchange.
+// It must follow the OAuthTokenMiddleware, as the exchanged token replaces the forwarded one.
+func NewTokenExchangeMiddleware(tokenExchange *tokenexchange.Service) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &TokenExchangeMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			tokenExchange: tokenExchange,
+		}
+	})
+}
+
+type TokenExchangeMiddleware struct {
+	baseMiddleware
+	tokenExchange *tokenexchange.Service
+}
+
+func (m *TokenExchangeMiddleware) applyToken(ctx context.Context, pCtx backend.PluginContext, req interface{}) error {
+	reqCtx := contexthandler.FromContext(ctx)
+	// if request not for a datasource or no HTTP request context skip middleware
+	if req == nil || pCtx.DataSourceInstanceSettings == nil || reqCtx == nil || reqCtx.Req == nil {
+		return nil
+	}
+	if !m.tokenExchange.IsEnabled(pCtx.DataSourceInstanceSettings) {
+		return nil
+	}
+
+	token, err := m.tokenExchange.Token(ctx, reqCtx.SignedInUser, pCtx.DataSourceInstanceSettings)
+	if err != nil {
+		return err
+	}
+	authorizationHeader := fmt.Sprintf("%s %s", token.Type(), token.AccessToken)
+
+	// The ID token of the user is not meant for the datasource, only the exchanged token is forwarded.
+	switch t := req.(type) {
+	case *backend.QueryDataRequest:
+		t.Headers[backend.OAuthIdentityTokenHeaderName] = authorizationHeader
+		delete(t.Headers, backend.OAuthIdentityIDTokenHeaderName)
+	case *backend.CheckHealthRequest:
+		t.Headers[backend.OAuthIdentityTokenHeaderName] = authorizationHeader
+		delete(t.Headers, backend.OAuthIdentityIDTokenHeaderName)
+	case *backend.CallResourceRequest:
+		t.Headers[backend.OAuthIdentityTokenHeaderName] = []string{authorizationHeader}
+		delete(t.Headers, backend.OAuthIdentityIDTokenHeaderName)
+	}
+
+	return nil
+}
+
+func (m *TokenExchangeMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	err := m.applyToken(ctx, req.PluginContext, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.next.QueryData(ctx, req)
+}
+
+func (m *TokenExchangeMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	if req == nil {
+		return m.next.CallResource(ctx, req, sender)
+	}
+
+	err := m.applyToken(ctx, req.PluginContext, req)
+	if err != nil {
+		return err
+	}
+
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *TokenExchangeMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if req == nil {
+		return m.next.CheckHealth(ctx, req)
+	}
+
+	err := m.applyToken(ctx, req.PluginContext, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.next.CheckHealth(ctx, req)
+}
@@ -2,8 +2,11 @@ package clientmiddleware
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
@@ -14,7 +17,7 @@ import (
 // populate useful tracing headers on outgoing plugins.Client and HTTP
 // requests.
 // Tracing headers are X-Datasource-Uid, X-Dashboard-Uid,
-// X-Panel-Id, X-Grafana-Org-Id.
+// X-Panel-Id, X-Grafana-Org-Id and the trace context headers, such as traceparent.
 func NewTracingHeaderMiddleware() plugins.ClientMiddleware {
 	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
 		return &TracingHeaderMiddleware{
@@ -45,6 +48,13 @@ func (m *TracingHeaderMiddleware) applyHeaders(ctx context.Context, req backend.
 		}
 		req.SetHTTPHeader(headerName, gotVal)
 	}
+
+	// Propagate the trace of the request, so that the requests the plugin sends to the data source join it.
+	carrier := propagation.HeaderCarrier(http.Header{})
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	for _, key := range carrier.Keys() {
+		req.SetHTTPHeader(key, carrier.Get(key))
+	}
 }
 
 func (m *TracingHeaderMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -8,6 +8,9 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestTracingHeaderMiddleware(t *testing.T) {
@@ -167,4 +170,32 @@ func TestTracingHeaderMiddleware(t *testing.T) {
 			require.Equal(t, `true`, cdt.CheckHealthReq.GetHTTPHeader(`X-Grafana-From-Expr`))
 		})
 	})
+
+	t.Run("When a request comes in with a trace", func(t *testing.T) {
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		req, err := http.NewRequest(http.MethodGet, "/some/thing", nil)
+		require.NoError(t, err)
+
+		spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x0a, 0x0b},
+			SpanID:     trace.SpanID{0x0c},
+			TraceFlags: trace.FlagsSampled,
+		})
+
+		cdt := clienttest.NewClientDecoratorTest(t,
+			clienttest.WithReqContext(req, &user.SignedInUser{
+				IsAnonymous: true,
+				Login:       "anonymous"},
+			),
+			clienttest.WithMiddlewares(NewTracingHeaderMiddleware()),
+		)
+
+		_, err = cdt.Decorator.QueryData(trace.ContextWithSpanContext(req.Context(), spanCtx), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Headers:       map[string]string{},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "00-"+spanCtx.TraceID().String()+"-"+spanCtx.SpanID().String()+"-01", cdt.QueryDataReq.GetHTTPHeader("Traceparent"))
+	})
 }
@@ -0,0 +1,64 @@
+package clientmiddleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	dsusage "github.com/grafana/grafana/pkg/services/datasources/usage"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+// NewUsageMiddleware creates a new plugins.ClientMiddleware that will record
+// the queries sent to data sources in their usage analytics.
+// It must follow the TracingHeaderMiddleware, as the dashboard issuing the queries is read from its headers.
+func NewUsageMiddleware(usage *dsusage.Service) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &UsageMiddleware{
+			baseMiddleware: baseMiddleware{
+				next: next,
+			},
+			usage: usage,
+		}
+	})
+}
+
+type UsageMiddleware struct {
+	baseMiddleware
+	usage *dsusage.Service
+}
+
+func (m *UsageMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil || req.PluginContext.DataSourceInstanceSettings == nil || !m.usage.Enabled() {
+		return m.next.QueryData(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := m.next.QueryData(ctx, req)
+	duration := time.Since(start)
+
+	userLogin := ""
+	if req.PluginContext.User != nil {
+		userLogin = req.PluginContext.User.Login
+	}
+	for _, q := range req.Queries {
+		failed := err != nil
+		if resp != nil {
+			if r, ok := resp.Responses[q.RefID]; ok && r.Error != nil {
+				failed = true
+			}
+		}
+		m.usage.Record(dsusage.Query{
+			OrgID:         req.PluginContext.OrgID,
+			DataSourceUID: req.PluginContext.DataSourceInstanceSettings.UID,
+			DashboardUID:  req.GetHTTPHeader(query.HeaderDashboardUID),
+			UserLogin:     userLogin,
+			Duration:      duration,
+			Failed:        failed,
+		})
+	}
+
+	return resp, err
+}
@@ -38,6 +38,7 @@ func ProvidePluginManagementConfig(cfg *setting.Cfg, settingProvider setting.Pro
 		cfg.DisablePlugins,
 		cfg.HideAngularDeprecation,
 		cfg.ForwardHostEnvVars,
+		cfg.PluginLimits,
 	), nil
 }
 
@@ -50,6 +51,7 @@ type PluginInstanceCfg struct {
 	Tracing config.Tracing
 
 	PluginSettings setting.PluginSettings
+	PluginLimits   setting.PluginLimitsSettings
 
 	AWSAllowedAuthProviders   []string
 	AWSAssumeRoleEnabled      bool
@@ -106,6 +108,7 @@ func ProvidePluginInstanceConfig(cfg *setting.Cfg, settingProvider setting.Provi
 		Features:                            features,
 		Tracing:                             tracingCfg,
 		PluginSettings:                      extractPluginSettings(settingProvider),
+		PluginLimits:                        cfg.PluginLimits,
 		AWSAllowedAuthProviders:             allowedAuth,
 		AWSAssumeRoleEnabled:                aws.KeyValue("assume_role_enabled").MustBool(cfg.AWSAssumeRoleEnabled),
 		AWSExternalId:                       aws.KeyValue("external_id").Value(),
@@ -3,6 +3,7 @@ package pluginconfig
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"slices"
 	"strconv"
@@ -66,6 +67,7 @@ func (p *EnvVarsProvider) PluginEnvVars(ctx context.Context, plugin *plugins.Plu
 	hostEnv = append(hostEnv, azsettings.WriteToEnvStr(p.cfg.Azure)...)
 	hostEnv = append(hostEnv, p.tracingEnvVars(plugin)...)
 	hostEnv = append(hostEnv, p.pluginSettingsEnvVars(plugin.PluginID())...)
+	hostEnv = append(hostEnv, p.resourceLimitEnvVars(plugin.PluginID())...)
 
 	// If SkipHostEnvVars is enabled, get some allowed variables from the current process and pass
 	// them down to the plugin. If the flag is not set, do not add anything else because ALL env vars
@@ -183,6 +185,23 @@ func (p *EnvVarsProvider) pluginSettingsEnvVars(pluginID string) []string {
 	return env
 }
 
+// resourceLimitEnvVars makes the Go runtime of the plugin process stay within its resource limits: it runs
+// the garbage collector more often when the process gets close to its memory limit, above which the process
+// is restarted, and runs Go code on as many threads as its advisory CPU limit.
+func (p *EnvVarsProvider) resourceLimitEnvVars(pluginID string) []string {
+	limits := p.cfg.PluginLimits.Limits(pluginID)
+
+	var variables []string
+	if limits.MaxMemoryMB > 0 {
+		softLimit := int64(limits.MaxMemoryMB) * 1024 * 1024 * 9 / 10
+		variables = append(variables, p.envVar("GOMEMLIMIT", strconv.FormatInt(softLimit, 10)))
+	}
+	if limits.MaxCPU > 0 {
+		variables = append(variables, p.envVar("GOMAXPROCS", strconv.Itoa(int(math.Ceil(limits.MaxCPU)))))
+	}
+	return variables
+}
+
 func (p *EnvVarsProvider) envVar(key, value string) string {
 	if strings.Contains(value, "\x00") {
 		p.logger.Error("Variable with key '%s' contains NUL", key)
@@ -10,8 +10,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
-	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/adapters"
@@ -24,14 +24,17 @@ import (
 const (
 	pluginSettingsCacheTTL    = 5 * time.Second
 	pluginSettingsCachePrefix = "plugin-setting-"
+	// pluginSettingsCacheName is the name of the plugin settings cache, configured in the
+	// [remote_cache.plugin_settings] section.
+	pluginSettingsCacheName = "plugin_settings"
 )
 
-func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, pluginStore pluginstore.Store,
+func ProvideService(cfg *setting.Cfg, caches namedcache.Provider, pluginStore pluginstore.Store,
 	dataSourceCache datasources.CacheService, dataSourceService datasources.DataSourceService,
 	pluginSettingsService pluginsettings.Service, pluginRequestConfigProvider pluginconfig.PluginRequestConfigProvider) *Provider {
 	return &Provider{
 		BaseProvider:          newBaseProvider(cfg, pluginRequestConfigProvider),
-		cacheService:          cacheService,
+		cache:                 caches.NamedCache(pluginSettingsCacheName, pluginSettingsCacheTTL),
 		pluginStore:           pluginStore,
 		dataSourceCache:       dataSourceCache,
 		dataSourceService:     dataSourceService,
@@ -42,7 +45,7 @@ func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, plu
 
 type Provider struct {
 	*BaseProvider
-	cacheService          *localcache.CacheService
+	cache                 *namedcache.Cache
 	pluginStore           pluginstore.Store
 	dataSourceCache       datasources.CacheService
 	dataSourceService     datasources.DataSourceService
@@ -152,17 +155,21 @@ func (p *Provider) appInstanceSettings(ctx context.Context, pluginID string, org
 	}, nil
 }
 
-func (p *Provider) InvalidateSettingsCache(_ context.Context, pluginID string) {
-	p.cacheService.Delete(getCacheKey(pluginID))
+func (p *Provider) InvalidateSettingsCache(ctx context.Context, pluginID string) {
+	if err := p.cache.Delete(ctx, getCacheKey(pluginID)); err != nil {
+		p.logger.FromContext(ctx).Warn("Failed to invalidate plugin settings cache", "pluginId", pluginID, "error", err)
+	}
 }
 
 func (p *Provider) getCachedPluginSettings(ctx context.Context, pluginID string, orgID int64) (*pluginsettings.DTO, error) {
 	cacheKey := getCacheKey(pluginID)
 
-	if cached, found := p.cacheService.Get(cacheKey); found {
-		ps := cached.(*pluginsettings.DTO)
-		if ps.OrgID == orgID {
-			return ps, nil
+	if data, err := p.cache.Get(ctx, cacheKey); err == nil {
+		var ps pluginsettings.DTO
+		if err := json.Unmarshal(data, &ps); err != nil {
+			p.logger.FromContext(ctx).Warn("Failed to decode cached plugin settings", "pluginId", pluginID, "error", err)
+		} else if ps.OrgID == orgID {
+			return &ps, nil
 		}
 	}
 
@@ -174,7 +181,13 @@ func (p *Provider) getCachedPluginSettings(ctx context.Context, pluginID string,
 		return nil, err
 	}
 
-	p.cacheService.Set(cacheKey, ps, pluginSettingsCacheTTL)
+	data, err := json.Marshal(ps)
+	if err == nil {
+		err = p.cache.Set(ctx, cacheKey, data, 0)
+	}
+	if err != nil {
+		p.logger.FromContext(ctx).Warn("Failed to cache plugin settings", "pluginId", pluginID, "error", err)
+	}
 	return ps, nil
 }
 
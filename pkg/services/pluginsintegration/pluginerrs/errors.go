@@ -2,6 +2,7 @@ package pluginerrs
 
 import (
 	"context"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/log"
@@ -39,6 +40,7 @@ func (s *Store) PluginError(ctx context.Context, pluginID string) *plugins.Error
 }
 
 type ErrorRegistry struct {
+	mu   sync.RWMutex
 	errs map[string]*plugins.Error
 	log  log.Logger
 }
@@ -62,14 +64,20 @@ func newErrorRegistry() *ErrorRegistry {
 }
 
 func (r *ErrorRegistry) Record(_ context.Context, err *plugins.Error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.errs[err.PluginID] = err
 }
 
 func (r *ErrorRegistry) Clear(_ context.Context, pluginID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	delete(r.errs, pluginID)
 }
 
 func (r *ErrorRegistry) Errors(_ context.Context) []*plugins.Error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	errs := make([]*plugins.Error, 0, len(r.errs))
 	for _, err := range r.errs {
 		errs = append(errs, err)
@@ -78,5 +86,7 @@ func (r *ErrorRegistry) Errors(_ context.Context) []*plugins.Error {
 }
 
 func (r *ErrorRegistry) Error(_ context.Context, pluginID string) *plugins.Error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.errs[pluginID]
 }
@@ -0,0 +1,125 @@
+package pluginlimits
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/plugins/manager/process"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// PluginStatusDTO reports the state, resource usage and limits of a backend plugin process.
+type PluginStatusDTO struct {
+	PluginID         string     `json:"pluginId"`
+	PID              int        `json:"pid,omitempty"`
+	Running          bool       `json:"running"`
+	Disabled         bool       `json:"disabled"`
+	Restarts         int        `json:"restarts"`
+	LastRestart      *time.Time `json:"lastRestart,omitempty"`
+	MemoryBytes      uint64     `json:"memoryBytes"`
+	CPUSeconds       float64    `json:"cpuSeconds"`
+	CPUUsage         float64    `json:"cpuUsage"`
+	SampledAt        *time.Time `json:"sampledAt,omitempty"`
+	InflightRequests int        `json:"inflightRequests"`
+	QueuedRequests   int        `json:"queuedRequests"`
+	Limits           LimitsDTO  `json:"limits"`
+}
+
+type LimitsDTO struct {
+	MaxConcurrentRequests int     `json:"maxConcurrentRequests"`
+	MaxMemoryMB           int     `json:"maxMemoryMB"`
+	MaxCPU                float64 `json:"maxCPU"`
+	RestartBudget         int     `json:"restartBudget"`
+	RestartBudgetWindow   string  `json:"restartBudgetWindow"`
+}
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	// the plugin processes are shared by all the organizations, so only Grafana admins can see them
+	routeRegister.Group("/api/admin/plugins/status", func(statusRoute routing.RouteRegister) {
+		statusRoute.Get("/", routing.Wrap(s.listStatusHandler))
+		statusRoute.Get("/:pluginId", routing.Wrap(s.getStatusHandler))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+func (s *Service) listStatusHandler(_ *contextmodel.ReqContext) response.Response {
+	statuses := s.processes.Statuses()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].PluginID < statuses[j].PluginID })
+
+	result := make([]PluginStatusDTO, 0, len(statuses))
+	for _, st := range statuses {
+		result = append(result, s.toDTO(st))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) getStatusHandler(c *contextmodel.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	status, exists := s.Status(pluginID)
+	if !exists {
+		return response.Error(http.StatusNotFound, "Backend plugin process not found", nil)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// Status returns the state, resource usage and limits of the backend plugin process of the provided plugin.
+func (s *Service) Status(pluginID string) (PluginStatusDTO, bool) {
+	st, exists := s.processes.Status(pluginID)
+	if !exists {
+		return PluginStatusDTO{}, false
+	}
+	return s.toDTO(st), true
+}
+
+// CheckHealth returns an error listing the backend plugins which exceeded their restart budget and are no longer
+// restarted.
+func (s *Service) CheckHealth(_ context.Context) error {
+	disabled := make([]string, 0)
+	for _, st := range s.processes.Statuses() {
+		if st.Disabled {
+			disabled = append(disabled, st.PluginID)
+		}
+	}
+	if len(disabled) > 0 {
+		sort.Strings(disabled)
+		return fmt.Errorf("backend plugins disabled after too many restarts: %s", strings.Join(disabled, ", "))
+	}
+	return nil
+}
+
+func (s *Service) toDTO(st process.Status) PluginStatusDTO {
+	inflight, queued := s.requests(st.PluginID)
+	dto := PluginStatusDTO{
+		PluginID:         st.PluginID,
+		PID:              st.PID,
+		Running:          st.Running,
+		Disabled:         st.Disabled,
+		Restarts:         st.Restarts,
+		MemoryBytes:      st.MemoryBytes,
+		CPUSeconds:       st.CPUSeconds,
+		CPUUsage:         st.CPUUsage,
+		InflightRequests: inflight,
+		QueuedRequests:   queued,
+		Limits: LimitsDTO{
+			MaxConcurrentRequests: st.Limits.MaxConcurrentRequests,
+			MaxMemoryMB:           st.Limits.MaxMemoryMB,
+			MaxCPU:                st.Limits.MaxCPU,
+			RestartBudget:         st.Limits.RestartBudget,
+			RestartBudgetWindow:   st.Limits.RestartBudgetWindow.String(),
+		},
+	}
+	if !st.LastRestart.IsZero() {
+		dto.LastRestart = &st.LastRestart
+	}
+	if !st.SampledAt.IsZero() {
+		dto.SampledAt = &st.SampledAt
+	}
+	return dto
+}
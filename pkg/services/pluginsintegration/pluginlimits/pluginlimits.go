@@ -0,0 +1,219 @@
+package pluginlimits
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/plugins/manager/process"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrTooManyRequests = errutil.TooManyRequests("plugins.tooManyRequests").MustTemplate(
+	"Too many concurrent requests to plugin {{ .Public.pluginId }}",
+	errutil.WithPublic("Too many concurrent requests to plugin {{ .Public.pluginId }}, try again later"),
+)
+
+func errTooManyRequests(pluginID string, err error) error {
+	return ErrTooManyRequests.Build(errutil.TemplateData{
+		Public: map[string]any{"pluginId": pluginID},
+		Error:  err,
+	})
+}
+
+// ProcessStatusProvider reports the state and resource usage of the backend plugin processes.
+type ProcessStatusProvider interface {
+	Status(pluginID string) (process.Status, bool)
+	Statuses() []process.Status
+}
+
+// Service caps the number of requests handled at once by each backend plugin, and reports the
+// resource usage of the backend plugin processes.
+type Service struct {
+	settings  setting.PluginLimitsSettings
+	processes ProcessStatusProvider
+	metrics   *metrics
+
+	mtx      sync.Mutex
+	limiters map[string]*limiter
+}
+
+func ProvideService(cfg *setting.Cfg, processManager *process.Service, registerer prometheus.Registerer,
+	routeRegister routing.RouteRegister) *Service {
+	s := newService(cfg.PluginLimits, processManager, registerer)
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+func newService(settings setting.PluginLimitsSettings, processes ProcessStatusProvider, registerer prometheus.Registerer) *Service {
+	s := &Service{
+		settings:  settings,
+		processes: processes,
+		metrics:   newMetrics(registerer),
+		limiters:  make(map[string]*limiter),
+	}
+	registerer.MustRegister(&processCollector{processes: processes})
+	return s
+}
+
+// Acquire blocks until the plugin handles fewer requests than its concurrency limit, the queue timeout
+// passes or ctx is done. The returned release function must be called once the request completes.
+func (s *Service) Acquire(ctx context.Context, pluginID string) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	l := s.limiter(pluginID)
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release, err := l.acquire(ctx, s.settings.QueueTimeout, s.metrics.queued.WithLabelValues(pluginID))
+	if err != nil {
+		s.metrics.rejected.WithLabelValues(pluginID).Inc()
+		return nil, errTooManyRequests(pluginID, err)
+	}
+	s.metrics.inflight.WithLabelValues(pluginID).Inc()
+	return func() {
+		s.metrics.inflight.WithLabelValues(pluginID).Dec()
+		release()
+	}, nil
+}
+
+func (s *Service) limiter(pluginID string) *limiter {
+	limits := s.settings.Limits(pluginID)
+	if limits.MaxConcurrentRequests <= 0 {
+		return nil
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	l, ok := s.limiters[pluginID]
+	if !ok {
+		l = newLimiter(limits.MaxConcurrentRequests)
+		s.limiters[pluginID] = l
+	}
+	return l
+}
+
+// requests returns the number of requests in progress and waiting for the plugin.
+func (s *Service) requests(pluginID string) (int, int) {
+	s.mtx.Lock()
+	l, ok := s.limiters[pluginID]
+	s.mtx.Unlock()
+	if !ok {
+		return 0, 0
+	}
+	return l.counts()
+}
+
+type limiter struct {
+	slots   chan struct{}
+	mtx     sync.Mutex
+	waiting int
+}
+
+func newLimiter(maxConcurrent int) *limiter {
+	return &limiter{slots: make(chan struct{}, maxConcurrent)}
+}
+
+func (l *limiter) acquire(ctx context.Context, timeout time.Duration, queued prometheus.Gauge) (func(), error) {
+	l.mtx.Lock()
+	l.waiting++
+	l.mtx.Unlock()
+	queued.Inc()
+	defer func() {
+		l.mtx.Lock()
+		l.waiting--
+		l.mtx.Unlock()
+		queued.Dec()
+	}()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return func() { <-l.slots }, nil
+}
+
+func (l *limiter) counts() (int, int) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return len(l.slots), l.waiting
+}
+
+type metrics struct {
+	queued   *prometheus.GaugeVec
+	inflight *prometheus.GaugeVec
+	rejected *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		queued: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "plugins_backend",
+			Name:      "queued_requests",
+			Help:      "Number of requests waiting for the concurrency limit of a backend plugin.",
+		}, []string{"plugin_id"}),
+		inflight: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "plugins_backend",
+			Name:      "inflight_requests",
+			Help:      "Number of requests to a concurrency limited backend plugin that are in progress.",
+		}, []string{"plugin_id"}),
+		rejected: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "plugins_backend",
+			Name:      "rejected_requests_total",
+			Help:      "Number of requests rejected because of the concurrency limit of a backend plugin.",
+		}, []string{"plugin_id"}),
+	}
+}
+
+var (
+	memoryDesc = prometheus.NewDesc("grafana_plugins_backend_memory_bytes",
+		"Resident memory of the backend plugin process.", []string{"plugin_id"}, nil)
+	cpuDesc = prometheus.NewDesc("grafana_plugins_backend_cpu_seconds_total",
+		"CPU time used by the backend plugin process.", []string{"plugin_id"}, nil)
+	restartsDesc = prometheus.NewDesc("grafana_plugins_backend_restarts_total",
+		"Number of times the backend plugin process was restarted.", []string{"plugin_id"}, nil)
+	disabledDesc = prometheus.NewDesc("grafana_plugins_backend_disabled",
+		"1 if the backend plugin was disabled after exceeding its restart budget.", []string{"plugin_id"}, nil)
+)
+
+// processCollector exposes the state and resource usage of the backend plugin processes.
+type processCollector struct {
+	processes ProcessStatusProvider
+}
+
+func (c *processCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- memoryDesc
+	ch <- cpuDesc
+	ch <- restartsDesc
+	ch <- disabledDesc
+}
+
+func (c *processCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, st := range c.processes.Statuses() {
+		disabled := 0.0
+		if st.Disabled {
+			disabled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(memoryDesc, prometheus.GaugeValue, float64(st.MemoryBytes), st.PluginID)
+		ch <- prometheus.MustNewConstMetric(cpuDesc, prometheus.CounterValue, st.CPUSeconds, st.PluginID)
+		ch <- prometheus.MustNewConstMetric(restartsDesc, prometheus.CounterValue, float64(st.Restarts), st.PluginID)
+		ch <- prometheus.MustNewConstMetric(disabledDesc, prometheus.GaugeValue, disabled, st.PluginID)
+	}
+}
@@ -0,0 +1,106 @@
+package pluginlimits
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/manager/process"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService(t *testing.T) {
+	settings := setting.PluginLimitsSettings{
+		Overrides: map[string]setting.PluginLimits{
+			"limited": {MaxConcurrentRequests: 1},
+		},
+		QueueTimeout: 50 * time.Millisecond,
+	}
+	s := newService(settings, &fakeProcesses{}, prometheus.NewRegistry())
+
+	t.Run("does not limit plugins without a concurrency limit", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			_, err := s.Acquire(context.Background(), "unlimited")
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("rejects requests over the concurrency limit once the queue timeout passes", func(t *testing.T) {
+		release, err := s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+
+		inflight, queued := s.requests("limited")
+		require.Equal(t, 1, inflight)
+		require.Equal(t, 0, queued)
+
+		_, err = s.Acquire(context.Background(), "limited")
+		require.ErrorIs(t, err, ErrTooManyRequests)
+
+		release()
+		release, err = s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("queued requests get the slot once it is released", func(t *testing.T) {
+		release, err := s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		time.AfterFunc(10*time.Millisecond, release)
+
+		release, err = s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("nil service does not limit requests", func(t *testing.T) {
+		var s *Service
+		release, err := s.Acquire(context.Background(), "limited")
+		require.NoError(t, err)
+		release()
+	})
+}
+
+func TestProcessCollector(t *testing.T) {
+	processes := &fakeProcesses{statuses: []process.Status{
+		{PluginID: "crashing", Disabled: true, Restarts: 5},
+		{PluginID: "healthy", Running: true, MemoryBytes: 1024, CPUSeconds: 2},
+	}}
+	registry := prometheus.NewRegistry()
+	newService(setting.PluginLimitsSettings{}, processes, registry)
+
+	expected := `
+# HELP grafana_plugins_backend_disabled 1 if the backend plugin was disabled after exceeding its restart budget.
+# TYPE grafana_plugins_backend_disabled gauge
+grafana_plugins_backend_disabled{plugin_id="crashing"} 1
+grafana_plugins_backend_disabled{plugin_id="healthy"} 0
+# HELP grafana_plugins_backend_memory_bytes Resident memory of the backend plugin process.
+# TYPE grafana_plugins_backend_memory_bytes gauge
+grafana_plugins_backend_memory_bytes{plugin_id="crashing"} 0
+grafana_plugins_backend_memory_bytes{plugin_id="healthy"} 1024
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"grafana_plugins_backend_disabled", "grafana_plugins_backend_memory_bytes")
+	require.NoError(t, err)
+}
+
+type fakeProcesses struct {
+	statuses []process.Status
+}
+
+func (f *fakeProcesses) Status(pluginID string) (process.Status, bool) {
+	for _, st := range f.statuses {
+		if st.PluginID == pluginID {
+			return st, true
+		}
+	}
+	return process.Status{}, false
+}
+
+func (f *fakeProcesses) Statuses() []process.Status {
+	return f.statuses
+}
@@ -30,8 +30,11 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/pluginscdn"
 	"github.com/grafana/grafana/pkg/plugins/repo"
 	"github.com/grafana/grafana/pkg/services/caching"
+	"github.com/grafana/grafana/pkg/services/datasources/ratelimit"
+	dsusage "github.com/grafana/grafana/pkg/services/datasources/usage"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/tokenexchange"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angulardetectorsprovider"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angularinspector"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angularpatternsstore"
@@ -48,8 +51,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginerrs"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginexternal"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugininstaller"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginlimits"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings/service"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstatus"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/renderer"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/serviceregistration"
@@ -71,6 +76,8 @@ var WireSet = wire.NewSet(
 	wire.Bind(new(plugins.StaticRouteResolver), new(*pluginstore.Service)),
 	process.ProvideService,
 	wire.Bind(new(process.Manager), new(*process.Service)),
+	pluginlimits.ProvideService,
+	pluginstatus.ProvideService,
 	coreplugin.ProvideCoreRegistry,
 	pluginscdn.ProvideService,
 	assetpath.ProvideService,
@@ -151,22 +158,27 @@ func ProvideClientDecorator(
 	cachingService caching.CachingService,
 	features featuremgmt.FeatureToggles,
 	promRegisterer prometheus.Registerer,
+	rateLimiter *ratelimit.Service,
+	tokenExchange *tokenexchange.Service,
+	usage *dsusage.Service,
+	pluginLimiter *pluginlimits.Service,
 ) (*client.Decorator, error) {
-	return NewClientDecorator(cfg, pluginRegistry, oAuthTokenService, tracer, cachingService, features, promRegisterer, pluginRegistry)
+	return NewClientDecorator(cfg, pluginRegistry, oAuthTokenService, tracer, cachingService, features, promRegisterer, pluginRegistry, rateLimiter, tokenExchange, usage, pluginLimiter)
 }
 
 func NewClientDecorator(
 	cfg *setting.Cfg,
 	pluginRegistry registry.Service, oAuthTokenService oauthtoken.OAuthTokenService,
 	tracer tracing.Tracer, cachingService caching.CachingService, features featuremgmt.FeatureToggles,
-	promRegisterer prometheus.Registerer, registry registry.Service,
+	promRegisterer prometheus.Registerer, registry registry.Service, rateLimiter *ratelimit.Service,
+	tokenExchange *tokenexchange.Service, usage *dsusage.Service, pluginLimiter *pluginlimits.Service,
 ) (*client.Decorator, error) {
 	c := client.ProvideService(pluginRegistry)
-	middlewares := CreateMiddlewares(cfg, oAuthTokenService, tracer, cachingService, features, promRegisterer, registry)
+	middlewares := CreateMiddlewares(cfg, oAuthTokenService, tracer, cachingService, features, promRegisterer, registry, rateLimiter, tokenExchange, usage, pluginLimiter)
 	return client.NewDecorator(c, middlewares...)
 }
 
-func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthTokenService, tracer tracing.Tracer, cachingService caching.CachingService, features featuremgmt.FeatureToggles, promRegisterer prometheus.Registerer, registry registry.Service) []plugins.ClientMiddleware {
+func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthTokenService, tracer tracing.Tracer, cachingService caching.CachingService, features featuremgmt.FeatureToggles, promRegisterer prometheus.Registerer, registry registry.Service, rateLimiter *ratelimit.Service, tokenExchange *tokenexchange.Service, usage *dsusage.Service, pluginLimiter *pluginlimits.Service) []plugins.ClientMiddleware {
 	middlewares := []plugins.ClientMiddleware{
 		clientmiddleware.NewPluginRequestMetaMiddleware(),
 		clientmiddleware.NewTracingMiddleware(tracer),
@@ -182,11 +194,17 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 
 	middlewares = append(middlewares,
 		clientmiddleware.NewTracingHeaderMiddleware(),
+		clientmiddleware.NewUsageMiddleware(usage),
+		clientmiddleware.NewForwardHeadersMiddleware(),
 		clientmiddleware.NewClearAuthHeadersMiddleware(),
 		clientmiddleware.NewOAuthTokenMiddleware(oAuthTokenService),
+		clientmiddleware.NewTokenExchangeMiddleware(tokenExchange),
+		clientmiddleware.NewLabelAccessMiddleware(),
 		clientmiddleware.NewCookiesMiddleware(skipCookiesNames),
 		clientmiddleware.NewResourceResponseMiddleware(),
 		clientmiddleware.NewCachingMiddlewareWithFeatureManager(cachingService, features),
+		clientmiddleware.NewRateLimitMiddleware(rateLimiter),
+		clientmiddleware.NewPluginConcurrencyMiddleware(pluginLimiter),
 		clientmiddleware.NewForwardIDMiddleware(),
 	)
 
@@ -198,6 +216,10 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 		middlewares = append(middlewares, clientmiddleware.NewHostedGrafanaACHeaderMiddleware(cfg))
 	}
 
+	// QueryBatchingMiddleware should be below the middlewares setting the headers, since only
+	// the requests with the same headers are batched
+	middlewares = append(middlewares, clientmiddleware.NewQueryBatchingMiddleware(cfg.PluginQueryBatching, registry, promRegisterer))
+
 	middlewares = append(middlewares, clientmiddleware.NewHTTPClientMiddleware())
 
 	// StatusSourceMiddleware should be at the very bottom, or any middlewares below it won't see the
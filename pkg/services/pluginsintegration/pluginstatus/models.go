@@ -0,0 +1,87 @@
+package pluginstatus
+
+import (
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginlimits"
+)
+
+type PluginStatus struct {
+	PluginID string       `json:"pluginId"`
+	Type     plugins.Type `json:"type"`
+	Version  string       `json:"version"`
+	Backend  bool         `json:"backend"`
+	// Error is the error that prevents the plugin from loading or running, if any.
+	Error   *ErrorStatus                  `json:"error,omitempty"`
+	Health  *HealthStatus                 `json:"health,omitempty"`
+	Process *pluginlimits.PluginStatusDTO `json:"process,omitempty"`
+	Metrics []MetricFamily                `json:"metrics,omitempty"`
+	// MetricsError is set when the metrics of the plugin could not be collected.
+	MetricsError string `json:"metricsError,omitempty"`
+}
+
+type ErrorStatus struct {
+	ErrorCode plugins.ErrorCode `json:"errorCode"`
+	Message   string            `json:"message"`
+}
+
+type HealthStatus struct {
+	Status  string         `json:"status"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type MetricFamily struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Help    string   `json:"help,omitempty"`
+	Samples []Sample `json:"samples"`
+}
+
+type Sample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// toMetricDTOs converts the metric families exposed by a plugin. Histograms and summaries are reduced
+// to their _sum and _count samples.
+func toMetricDTOs(families map[string]*dto.MetricFamily) []MetricFamily {
+	result := make([]MetricFamily, 0, len(families))
+	for _, family := range families {
+		f := MetricFamily{
+			Name: family.GetName(),
+			Type: family.GetType().String(),
+			Help: family.GetHelp(),
+		}
+		for _, m := range family.Metric {
+			labels := make(map[string]string, len(m.Label))
+			for _, l := range m.Label {
+				labels[l.GetName()] = l.GetValue()
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				f.Samples = append(f.Samples, Sample{Name: f.Name, Labels: labels, Value: m.GetCounter().GetValue()})
+			case dto.MetricType_GAUGE:
+				f.Samples = append(f.Samples, Sample{Name: f.Name, Labels: labels, Value: m.GetGauge().GetValue()})
+			case dto.MetricType_UNTYPED:
+				f.Samples = append(f.Samples, Sample{Name: f.Name, Labels: labels, Value: m.GetUntyped().GetValue()})
+			case dto.MetricType_SUMMARY:
+				f.Samples = append(f.Samples,
+					Sample{Name: f.Name + "_sum", Labels: labels, Value: m.GetSummary().GetSampleSum()},
+					Sample{Name: f.Name + "_count", Labels: labels, Value: float64(m.GetSummary().GetSampleCount())})
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				f.Samples = append(f.Samples,
+					Sample{Name: f.Name + "_sum", Labels: labels, Value: m.GetHistogram().GetSampleSum()},
+					Sample{Name: f.Name + "_count", Labels: labels, Value: float64(m.GetHistogram().GetSampleCount())})
+			}
+		}
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
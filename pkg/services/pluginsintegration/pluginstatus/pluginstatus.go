@@ -0,0 +1,205 @@
+package pluginstatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginlimits"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+)
+
+const pluginIDLabel = "plugin_id"
+
+var ErrPluginNotFound = errors.New("plugin not found")
+
+// Service aggregates the health, process state and metrics of backend plugins.
+type Service struct {
+	pluginStore   pluginstore.Store
+	pluginClient  plugins.Client
+	errorResolver plugins.ErrorResolver
+	limits        ProcessStatusProvider
+	log           log.Logger
+}
+
+// ProcessStatusProvider reports the state and resource usage of the backend plugin processes.
+type ProcessStatusProvider interface {
+	Status(pluginID string) (pluginlimits.PluginStatusDTO, bool)
+}
+
+func ProvideService(pluginStore pluginstore.Store, pluginClient plugins.Client, errorResolver plugins.ErrorResolver,
+	limits *pluginlimits.Service) *Service {
+	return &Service{
+		pluginStore:   pluginStore,
+		pluginClient:  pluginClient,
+		errorResolver: errorResolver,
+		limits:        limits,
+		log:           log.New("plugins.status"),
+	}
+}
+
+// Status collects the health, process state and metrics of the plugin. Failing to reach the plugin
+// is reported in the status rather than returned as an error. The health of a data source plugin is only
+// checked when the plugin context has the settings of one of its data sources.
+func (s *Service) Status(ctx context.Context, pCtx backend.PluginContext) (*PluginStatus, error) {
+	p, exists := s.pluginStore.Plugin(ctx, pCtx.PluginID)
+	if !exists {
+		return nil, ErrPluginNotFound
+	}
+
+	status := &PluginStatus{
+		PluginID: p.ID,
+		Type:     p.Type,
+		Version:  p.Info.Version,
+		Backend:  p.Backend,
+	}
+	if err := s.errorResolver.PluginError(ctx, p.ID); err != nil {
+		status.Error = &ErrorStatus{ErrorCode: err.ErrorCode, Message: err.PublicMessage()}
+	}
+	if !p.Backend {
+		return status, nil
+	}
+
+	if process, exists := s.limits.Status(p.ID); exists {
+		status.Process = &process
+	}
+
+	// the health of a data source plugin is the health of one of its data sources, it's only checked for one
+	if p.Type != plugins.TypeDataSource || pCtx.DataSourceInstanceSettings != nil {
+		health, err := s.checkHealth(ctx, pCtx)
+		if err != nil {
+			status.Health = &HealthStatus{Status: backend.HealthStatusError.String(), Message: err.Error()}
+		} else {
+			status.Health = health
+		}
+	}
+
+	families, err := s.collectMetrics(ctx, p.ID)
+	if err != nil {
+		status.MetricsError = err.Error()
+	} else {
+		status.Metrics = toMetricDTOs(families)
+	}
+
+	return status, nil
+}
+
+func (s *Service) checkHealth(ctx context.Context, pCtx backend.PluginContext) (*HealthStatus, error) {
+	resp, err := s.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{
+		PluginContext: pCtx,
+		Headers:       map[string]string{},
+	})
+	if err != nil {
+		if errors.Is(err, plugins.ErrMethodNotImplemented) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	health := &HealthStatus{Status: resp.Status.String(), Message: resp.Message}
+	if len(resp.JSONDetails) > 0 {
+		if err := json.Unmarshal(resp.JSONDetails, &health.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal health check details: %w", err)
+		}
+	}
+	return health, nil
+}
+
+func (s *Service) collectMetrics(ctx context.Context, pluginID string) (map[string]*dto.MetricFamily, error) {
+	resp, err := s.pluginClient.CollectMetrics(ctx, &backend.CollectMetricsRequest{
+		PluginContext: backend.PluginContext{PluginID: pluginID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.PrometheusMetrics) == 0 {
+		return map[string]*dto.MetricFamily{}, nil
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(resp.PrometheusMetrics))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plugin metrics: %w", err)
+	}
+	return families, nil
+}
+
+// Gather collects the metrics exposed by every backend plugin, adds the plugin_id label to them and
+// reports whether each plugin could be scraped in grafana_plugins_backend_up.
+func (s *Service) Gather(ctx context.Context) ([]*dto.MetricFamily, error) {
+	up := &dto.MetricFamily{
+		Name: proto.String("grafana_plugins_backend_up"),
+		Help: proto.String("1 if the metrics of the backend plugin could be collected."),
+		Type: dto.MetricType_GAUGE.Enum(),
+	}
+	merged := map[string]*dto.MetricFamily{}
+
+	backendPlugins := s.pluginStore.Plugins(ctx)
+	sort.Slice(backendPlugins, func(i, j int) bool { return backendPlugins[i].ID < backendPlugins[j].ID })
+	for _, p := range backendPlugins {
+		if !p.Backend {
+			continue
+		}
+
+		families, err := s.collectMetrics(ctx, p.ID)
+		value := 1.0
+		if err != nil {
+			s.log.Debug("Failed to collect plugin metrics", "pluginId", p.ID, "error", err)
+			value = 0
+		}
+		up.Metric = append(up.Metric, &dto.Metric{
+			Label: []*dto.LabelPair{{Name: proto.String(pluginIDLabel), Value: proto.String(p.ID)}},
+			Gauge: &dto.Gauge{Value: proto.Float64(value)},
+		})
+
+		for name, family := range families {
+			existing, ok := merged[name]
+			if !ok {
+				existing = &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type}
+				merged[name] = existing
+			} else if existing.GetType() != family.GetType() {
+				s.log.Debug("Skipping plugin metric with a conflicting type", "pluginId", p.ID, "metric", name)
+				continue
+			}
+			for _, m := range family.Metric {
+				m.Label = withPluginID(m.Label, p.ID)
+				existing.Metric = append(existing.Metric, m)
+			}
+		}
+	}
+
+	result := make([]*dto.MetricFamily, 0, len(merged)+1)
+	result = append(result, up)
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, merged[name])
+	}
+	return result, nil
+}
+
+// withPluginID sets the plugin_id label, replacing the one set by the plugin, if any.
+func withPluginID(labels []*dto.LabelPair, pluginID string) []*dto.LabelPair {
+	result := make([]*dto.LabelPair, 0, len(labels)+1)
+	for _, l := range labels {
+		if l.GetName() != pluginIDLabel {
+			result = append(result, l)
+		}
+	}
+	result = append(result, &dto.LabelPair{Name: proto.String(pluginIDLabel), Value: proto.String(pluginID)})
+	sort.Slice(result, func(i, j int) bool { return result[i].GetName() < result[j].GetName() })
+	return result
+}
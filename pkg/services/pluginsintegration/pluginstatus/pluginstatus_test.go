@@ -0,0 +1,139 @@
+package pluginstatus
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/plugins/manager/fakes"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginlimits"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+)
+
+func TestService_Status(t *testing.T) {
+	client := &clienttest.TestClient{
+		CheckHealthFunc: func(_ context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+			return &backend.CheckHealthResult{
+				Status:      backend.HealthStatusError,
+				Message:     "connection refused",
+				JSONDetails: []byte(`{"host":"localhost"}`),
+			}, nil
+		},
+		CollectMetricsFunc: func(_ context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+			return &backend.CollectMetricsResult{PrometheusMetrics: []byte("# TYPE http_errors_total counter\nhttp_errors_total{code=\"500\"} 2\n")}, nil
+		},
+	}
+	errorResolver := fakes.NewFakeErrorResolver()
+	errorResolver.Errors["crashing-datasource"] = &plugins.Error{PluginID: "crashing-datasource", ErrorCode: plugins.ErrorCodeCrashLoop}
+	limits := &fakeLimits{statuses: map[string]pluginlimits.PluginStatusDTO{
+		"test-datasource": {PluginID: "test-datasource", Running: true, MemoryBytes: 1024},
+	}}
+	s := newTestService(client, errorResolver, limits,
+		backendPlugin("test-datasource"),
+		backendPlugin("crashing-datasource"),
+		pluginstore.Plugin{JSONData: plugins.JSONData{ID: "test-panel", Type: plugins.TypePanel}},
+	)
+
+	t.Run("aggregates health, process and metrics of a backend plugin", func(t *testing.T) {
+		status, err := s.Status(context.Background(), backend.PluginContext{
+			PluginID:                   "test-datasource",
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds"},
+		})
+		require.NoError(t, err)
+		require.Nil(t, status.Error)
+		require.Equal(t, "ERROR", status.Health.Status)
+		require.Equal(t, "connection refused", status.Health.Message)
+		require.Equal(t, map[string]any{"host": "localhost"}, status.Health.Details)
+		require.Equal(t, uint64(1024), status.Process.MemoryBytes)
+		require.Equal(t, []MetricFamily{{
+			Name:    "http_errors_total",
+			Type:    "COUNTER",
+			Samples: []Sample{{Name: "http_errors_total", Labels: map[string]string{"code": "500"}, Value: 2}},
+		}}, status.Metrics)
+	})
+
+	t.Run("reports the plugin error", func(t *testing.T) {
+		status, err := s.Status(context.Background(), backend.PluginContext{PluginID: "crashing-datasource"})
+		require.NoError(t, err)
+		require.Equal(t, plugins.ErrorCodeCrashLoop, status.Error.ErrorCode)
+		require.Nil(t, status.Process)
+	})
+
+	t.Run("checks the health of data source plugins only for a data source", func(t *testing.T) {
+		status, err := s.Status(context.Background(), backend.PluginContext{PluginID: "test-datasource"})
+		require.NoError(t, err)
+		require.Nil(t, status.Health)
+		require.NotEmpty(t, status.Metrics)
+	})
+
+	t.Run("does not call frontend plugins", func(t *testing.T) {
+		status, err := s.Status(context.Background(), backend.PluginContext{PluginID: "test-panel"})
+		require.NoError(t, err)
+		require.Nil(t, status.Health)
+		require.Empty(t, status.Metrics)
+	})
+
+	t.Run("returns an error for unknown plugins", func(t *testing.T) {
+		_, err := s.Status(context.Background(), backend.PluginContext{PluginID: "unknown"})
+		require.ErrorIs(t, err, ErrPluginNotFound)
+	})
+}
+
+func TestService_Gather(t *testing.T) {
+	client := &clienttest.TestClient{
+		CollectMetricsFunc: func(_ context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+			if req.PluginContext.PluginID == "down-datasource" {
+				return nil, plugins.ErrPluginUnavailable
+			}
+			return &backend.CollectMetricsResult{PrometheusMetrics: []byte("# TYPE go_goroutines gauge\ngo_goroutines 7\n")}, nil
+		},
+	}
+	s := newTestService(client, fakes.NewFakeErrorResolver(), &fakeLimits{},
+		backendPlugin("b-datasource"),
+		backendPlugin("a-datasource"),
+		backendPlugin("down-datasource"),
+	)
+
+	families, err := s.Gather(context.Background())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, f := range families {
+		require.NoError(t, enc.Encode(f))
+	}
+	require.Equal(t, `# HELP grafana_plugins_backend_up 1 if the metrics of the backend plugin could be collected.
+# TYPE grafana_plugins_backend_up gauge
+grafana_plugins_backend_up{plugin_id="a-datasource"} 1
+grafana_plugins_backend_up{plugin_id="b-datasource"} 1
+grafana_plugins_backend_up{plugin_id="down-datasource"} 0
+# TYPE go_goroutines gauge
+go_goroutines{plugin_id="a-datasource"} 7
+go_goroutines{plugin_id="b-datasource"} 7
+`, buf.String())
+}
+
+func newTestService(client plugins.Client, errorResolver plugins.ErrorResolver, limits ProcessStatusProvider, ps ...pluginstore.Plugin) *Service {
+	s := ProvideService(pluginstore.NewFakePluginStore(ps...), client, errorResolver, nil)
+	s.limits = limits
+	return s
+}
+
+func backendPlugin(id string) pluginstore.Plugin {
+	return pluginstore.Plugin{JSONData: plugins.JSONData{ID: id, Type: plugins.TypeDataSource, Backend: true}}
+}
+
+type fakeLimits struct {
+	statuses map[string]pluginlimits.PluginStatusDTO
+}
+
+func (f *fakeLimits) Status(pluginID string) (pluginlimits.PluginStatusDTO, bool) {
+	st, ok := f.statuses[pluginID]
+	return st, ok
+}
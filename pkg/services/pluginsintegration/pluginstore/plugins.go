@@ -52,6 +52,12 @@ func (p Plugin) IsCorePlugin() bool {
 	return p.Class == plugins.ClassCore
 }
 
+// RequiresRestart returns true for the plugins that are only started on startup, so that installing or
+// updating them at runtime only takes effect after restarting Grafana.
+func (p Plugin) RequiresRestart() bool {
+	return p.Type == plugins.TypeRenderer || p.Type == plugins.TypeSecretsManager
+}
+
 func ToGrafanaDTO(p *plugins.Plugin) Plugin {
 	supportsStreaming := false
 	pc, exists := p.Client()
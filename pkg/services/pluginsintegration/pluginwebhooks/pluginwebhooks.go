@@ -0,0 +1,102 @@
+package pluginwebhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrSignatureMissing = errors.New("webhook signature is missing")
+	ErrSignatureInvalid = errors.New("webhook signature is invalid")
+)
+
+// VerifySignature checks that the signature header of the request is the HMAC-SHA256 of the body
+// computed with the secret of the webhook.
+func VerifySignature(webhook plugins.Webhook, secret string, header http.Header, body []byte) error {
+	value := header.Get(webhook.SignatureHeader)
+	if value == "" {
+		return ErrSignatureMissing
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, webhook.SignaturePrefix))
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// maxLimiters bounds the number of webhooks whose rate is tracked at once.
+const maxLimiters = 10000
+
+// Limiter limits the rate of the requests each webhook accepts.
+type Limiter struct {
+	settings setting.PluginWebhooksSettings
+
+	mtx      sync.Mutex
+	limiters map[string]*rate.Limiter
+	// maxLimiters is the maximum number of entries of limiters
+	maxLimiters int
+}
+
+func NewLimiter(settings setting.PluginWebhooksSettings) *Limiter {
+	return &Limiter{
+		settings:    settings,
+		limiters:    make(map[string]*rate.Limiter),
+		maxLimiters: maxLimiters,
+	}
+}
+
+// Allow reports whether a request to the webhook of the plugin in the organization can be handled now. The
+// caller validates the organization, the plugin and the webhook first, since each of them is tracked. Once the
+// maximum number of webhooks is tracked, the requests to new webhooks are refused until the limiters of the
+// idle webhooks are removed.
+func (l *Limiter) Allow(orgID int64, pluginID, name string) bool {
+	if l.settings.RateLimit <= 0 {
+		return true
+	}
+
+	key := strings.Join([]string{pluginID, name, strconv.FormatInt(orgID, 10)}, "/")
+	l.mtx.Lock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		if len(l.limiters) >= l.maxLimiters {
+			l.prune(time.Now())
+		}
+		if len(l.limiters) >= l.maxLimiters {
+			l.mtx.Unlock()
+			return false
+		}
+		limiter = rate.NewLimiter(rate.Limit(l.settings.RateLimit), max(l.settings.Burst, 1))
+		l.limiters[key] = limiter
+	}
+	l.mtx.Unlock()
+
+	return limiter.Allow()
+}
+
+// prune removes the limiters which are back to their full burst, they allow as many requests as new ones.
+func (l *Limiter) prune(now time.Time) {
+	for key, limiter := range l.limiters {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(l.limiters, key)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package pluginwebhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"event":"push"}`)
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	webhook := plugins.Webhook{Name: "events", SignatureHeader: "X-Hub-Signature-256", SignaturePrefix: "sha256=", SecretKey: "webhookSecret"}
+
+	tcs := []struct {
+		name        string
+		header      http.Header
+		expectedErr error
+	}{
+		{
+			name:   "valid signature",
+			header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("secret")}},
+		},
+		{
+			name:        "missing signature",
+			header:      http.Header{},
+			expectedErr: ErrSignatureMissing,
+		},
+		{
+			name:        "signature computed with another secret",
+			header:      http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("other")}},
+			expectedErr: ErrSignatureInvalid,
+		},
+		{
+			name:        "signature that isn't hex encoded",
+			header:      http.Header{"X-Hub-Signature-256": []string{"sha256=not-hex"}},
+			expectedErr: ErrSignatureInvalid,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifySignature(webhook, "secret", tc.header, body)
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+
+	t.Run("signature without a prefix", func(t *testing.T) {
+		webhook := plugins.Webhook{Name: "events", SignatureHeader: "X-Signature", SecretKey: "webhookSecret"}
+		header := http.Header{"X-Signature": []string{sign("secret")}}
+		require.NoError(t, VerifySignature(webhook, "secret", header, body))
+	})
+}
+
+func TestLimiter(t *testing.T) {
+	t.Run("unlimited when the rate limit is 0", func(t *testing.T) {
+		l := NewLimiter(setting.PluginWebhooksSettings{RateLimit: 0})
+		for i := 0; i < 100; i++ {
+			require.True(t, l.Allow(1, "test-app", "events"))
+		}
+	})
+
+	t.Run("limits each webhook of each organization", func(t *testing.T) {
+		l := NewLimiter(setting.PluginWebhooksSettings{RateLimit: 0.001, Burst: 1})
+		require.True(t, l.Allow(1, "test-app", "events"))
+		require.False(t, l.Allow(1, "test-app", "events"))
+
+		require.True(t, l.Allow(2, "test-app", "events"))
+		require.True(t, l.Allow(1, "test-app", "alerts"))
+		require.True(t, l.Allow(1, "other-app", "events"))
+	})
+	t.Run("bounds the number of limiters", func(t *testing.T) {
+		l := NewLimiter(setting.PluginWebhooksSettings{RateLimit: 1000, Burst: 1})
+		l.maxLimiters = 2
+		require.True(t, l.Allow(1, "test-app", "events"))
+		require.True(t, l.Allow(2, "test-app", "events"))
+
+		// the limiters are pruned once they are back to their full burst
+		time.Sleep(5 * time.Millisecond)
+		require.True(t, l.Allow(3, "test-app", "events"))
+		require.Len(t, l.limiters, 1)
+
+		l = NewLimiter(setting.PluginWebhooksSettings{RateLimit: 0.001, Burst: 1})
+		l.maxLimiters = 2
+		require.True(t, l.Allow(1, "test-app", "events"))
+		require.True(t, l.Allow(2, "test-app", "events"))
+		require.False(t, l.Allow(3, "test-app", "events"), "no limiter can be pruned")
+	})
+}
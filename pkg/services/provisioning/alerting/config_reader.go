@@ -11,10 +11,14 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
 )
 
 type rulesConfigReader struct {
 	log log.Logger
+	// literal turns off the interpolation of the files, see utils.ConfigDir.
+	literal bool
 }
 
 func newRulesConfigReader(logger log.Logger) rulesConfigReader {
@@ -49,6 +53,7 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 			if err != nil {
 				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, err)
 			}
+			alertFile.Hash = status.HashConfig(alertFile)
 			alertFiles = append(alertFiles, &alertFile)
 		}
 	}
@@ -72,7 +77,11 @@ func (cr *rulesConfigReader) parseConfig(path string, file fs.DirEntry) (*Alerti
 		return nil, err
 	}
 	var cfg *AlertingFileV1
-	err = yaml.Unmarshal(yamlFile, &cfg)
+	if cr.literal {
+		err = values.UnmarshalLiteral(yamlFile, &cfg)
+	} else {
+		err = yaml.Unmarshal(yamlFile, &cfg)
+	}
 	if err != nil {
 		return nil, err
 	}
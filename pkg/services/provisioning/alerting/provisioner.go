@@ -8,10 +8,16 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 )
 
+// ProvisionerName is the name of the alerting provisioner in the provisioning status.
+const ProvisionerName = "alerting"
+
 type ProvisionerConfig struct {
-	Path                       string
+	// Dirs are the directories of the alerting provisioning files.
+	Dirs                       []utils.ConfigDir
 	FolderService              folder.Service
 	DashboardProvService       dashboards.DashboardProvisioningService
 	RuleService                provisioning.AlertRuleService
@@ -19,46 +25,98 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	// Tracker keeps track of the files that changed since they were last applied.
+	Tracker *status.Tracker
+	// Prune deletes the resources of the files that were removed from Dirs.
+	Prune bool
 }
 
-func Provision(ctx context.Context, cfg ProvisionerConfig) error {
+// Provision applies the alerting files of the directories that changed since they were last
+// applied, and deletes the resources of the removed files if pruning is enabled.
+func Provision(ctx context.Context, cfg ProvisionerConfig) (err error) {
 	logger := log.New("provisioning.alerting")
+	run := cfg.Tracker.Start(ctx, ProvisionerName)
+	defer func() {
+		run.Finish(ctx, err)
+	}()
 	cfgReader := newRulesConfigReader(logger)
-	files, err := cfgReader.readConfig(ctx, cfg.Path)
-	if err != nil {
-		return err
+	var files []*AlertingFile
+	for _, dir := range cfg.Dirs {
+		if dir.Unavailable {
+			run.Keep(dir.Prefix)
+			continue
+		}
+		cfgReader.literal = dir.Literal
+		dirFiles, err := cfgReader.readConfig(ctx, dir.Path)
+		if err != nil {
+			return err
+		}
+		for _, file := range dirFiles {
+			file.Filename = dir.Prefix + file.Filename
+		}
+		files = append(files, dirFiles...)
 	}
 	logger.Info("starting to provision alerting")
 	logger.Debug("read all alerting files", "file_count", len(files))
+
+	changed := make([]*AlertingFile, 0, len(files))
+	for _, file := range files {
+		if run.Changed(file.Filename, file.Hash) {
+			changed = append(changed, file)
+			continue
+		}
+		logger.Debug("skipping unchanged alerting file", "file", file.Filename)
+	}
+	var pruned []string
+	if cfg.Prune {
+		var prune *AlertingFile
+		prune, pruned = pruneFile(logger, run.Removed(), files)
+		if len(pruned) > 0 {
+			changed = append(changed, prune)
+		}
+	}
+
+	// each file is applied on its own, so that the errors are reported for the file that caused them
+	apply := func(f func(context.Context, []*AlertingFile) error) error {
+		for _, file := range changed {
+			if err := f(ctx, []*AlertingFile{file}); err != nil {
+				if file.Hash != "" {
+					run.Failed(file.Filename, file.Hash, err)
+				}
+				return err
+			}
+		}
+		return nil
+	}
 	cpProvisioner := NewContactPointProvisoner(logger, cfg.ContactPointService)
-	err = cpProvisioner.Provision(ctx, files)
+	err = apply(cpProvisioner.Provision)
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
 	mtProvisioner := NewMuteTimesProvisioner(logger, cfg.MuteTimingService)
-	err = mtProvisioner.Provision(ctx, files)
+	err = apply(mtProvisioner.Provision)
 	if err != nil {
 		return fmt.Errorf("mute times: %w", err)
 	}
 	ttProvsioner := NewTextTemplateProvisioner(logger, cfg.TemplateService)
-	err = ttProvsioner.Provision(ctx, files)
+	err = apply(ttProvsioner.Provision)
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
 	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
-	err = npProvisioner.Provision(ctx, files)
+	err = apply(npProvisioner.Provision)
 	if err != nil {
 		return fmt.Errorf("notification policies: %w", err)
 	}
-	err = npProvisioner.Unprovision(ctx, files)
+	err = apply(npProvisioner.Unprovision)
 	if err != nil {
 		return fmt.Errorf("notification policies: %w", err)
 	}
-	err = mtProvisioner.Unprovision(ctx, files)
+	err = apply(mtProvisioner.Unprovision)
 	if err != nil {
 		return fmt.Errorf("mute times: %w", err)
 	}
-	err = ttProvsioner.Unprovision(ctx, files)
+	err = apply(ttProvsioner.Unprovision)
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
@@ -67,14 +125,26 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 		cfg.FolderService,
 		cfg.DashboardProvService,
 		cfg.RuleService)
-	err = ruleProvisioner.Provision(ctx, files)
+	err = apply(ruleProvisioner.Provision)
 	if err != nil {
 		return fmt.Errorf("alert rules: %w", err)
 	}
-	err = cpProvisioner.Unprovision(ctx, files) // Unprovision contact points after rules to make sure all references in rules are updated
+	err = apply(cpProvisioner.Unprovision) // Unprovision contact points after rules to make sure all references in rules are updated
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
+
+	for _, file := range files {
+		if run.Changed(file.Filename, file.Hash) {
+			run.Applied(file.Filename, file.Hash, resourcesOf(file))
+		} else {
+			run.Skipped(file.Filename, file.Hash)
+		}
+	}
+	for _, path := range pruned {
+		logger.Info("pruned resources of removed alerting file", "file", path)
+		run.Pruned(path)
+	}
 	logger.Info("finished to provision alerting")
 	return nil
 }
@@ -0,0 +1,114 @@
+package alerting
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+)
+
+// provisionedResources are the resources provisioned by an alerting file, which are
+// deleted when the file is removed if pruning is enabled.
+type provisionedResources struct {
+	Rules         []RuleDelete         `json:"rules,omitempty"`
+	ContactPoints []DeleteContactPoint `json:"contactPoints,omitempty"`
+	Policies      []OrgID              `json:"policies,omitempty"`
+	MuteTimes     []DeleteMuteTime     `json:"muteTimes,omitempty"`
+	Templates     []DeleteTemplate     `json:"templates,omitempty"`
+}
+
+func resourcesOf(file *AlertingFile) provisionedResources {
+	var r provisionedResources
+	for _, group := range file.Groups {
+		for _, rule := range group.Rules {
+			r.Rules = append(r.Rules, RuleDelete{UID: rule.UID, OrgID: group.OrgID})
+		}
+	}
+	for _, cp := range file.ContactPoints {
+		for _, receiver := range cp.ContactPoints {
+			r.ContactPoints = append(r.ContactPoints, DeleteContactPoint{OrgID: cp.OrgID, UID: receiver.UID})
+		}
+	}
+	for _, np := range file.Policies {
+		r.Policies = append(r.Policies, OrgID(np.OrgID))
+	}
+	for _, mt := range file.MuteTimes {
+		r.MuteTimes = append(r.MuteTimes, DeleteMuteTime{OrgID: mt.OrgID, Name: mt.MuteTime.Name})
+	}
+	for _, t := range file.Templates {
+		r.Templates = append(r.Templates, DeleteTemplate{OrgID: t.OrgID, Name: t.Data.Name})
+	}
+	return r
+}
+
+// pruneFile returns a file which deletes the resources of the removed files that none of
+// the files provision anymore, and the paths of the removed files.
+func pruneFile(logger log.Logger, removed map[string]status.FileState, files []*AlertingFile) (*AlertingFile, []string) {
+	rules := map[RuleDelete]bool{}
+	contactPoints := map[DeleteContactPoint]bool{}
+	policies := map[OrgID]bool{}
+	muteTimes := map[DeleteMuteTime]bool{}
+	templates := map[DeleteTemplate]bool{}
+	for _, file := range files {
+		r := resourcesOf(file)
+		for _, k := range r.Rules {
+			rules[k] = true
+		}
+		for _, k := range r.ContactPoints {
+			contactPoints[k] = true
+		}
+		for _, k := range r.Policies {
+			policies[k] = true
+		}
+		for _, k := range r.MuteTimes {
+			muteTimes[k] = true
+		}
+		for _, k := range r.Templates {
+			templates[k] = true
+		}
+	}
+
+	prune := &AlertingFile{Filename: "pruned files"}
+	paths := make([]string, 0, len(removed))
+	for path, state := range removed {
+		var r provisionedResources
+		if len(state.Resources) > 0 {
+			if err := json.Unmarshal(state.Resources, &r); err != nil {
+				logger.Warn("failed to read the resources of a removed file, they are not pruned", "file", path, "error", err)
+				continue
+			}
+		}
+		for _, k := range r.Rules {
+			if !rules[k] {
+				rules[k] = true
+				prune.DeleteRules = append(prune.DeleteRules, k)
+			}
+		}
+		for _, k := range r.ContactPoints {
+			if !contactPoints[k] {
+				contactPoints[k] = true
+				prune.DeleteContactPoints = append(prune.DeleteContactPoints, k)
+			}
+		}
+		for _, k := range r.Policies {
+			if !policies[k] {
+				policies[k] = true
+				prune.ResetPolicies = append(prune.ResetPolicies, k)
+			}
+		}
+		for _, k := range r.MuteTimes {
+			if !muteTimes[k] {
+				muteTimes[k] = true
+				prune.DeleteMuteTimes = append(prune.DeleteMuteTimes, k)
+			}
+		}
+		for _, k := range r.Templates {
+			if !templates[k] {
+				templates[k] = true
+				prune.DeleteTemplates = append(prune.DeleteTemplates, k)
+			}
+		}
+		paths = append(paths, path)
+	}
+	return prune, paths
+}
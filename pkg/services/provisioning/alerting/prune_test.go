@@ -0,0 +1,42 @@
+package alerting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+)
+
+func TestPruneFile(t *testing.T) {
+	removedFile := &AlertingFile{
+		Groups: []models.AlertRuleGroupWithFolderFullpath{{
+			AlertRuleGroup: &models.AlertRuleGroup{Rules: []models.AlertRule{{UID: "moved"}, {UID: "removed"}}},
+			OrgID:          1,
+		}},
+		Templates: []Template{{OrgID: 1, Data: definitions.NotificationTemplate{Name: "removed"}}},
+		Policies:  []NotificiationPolicy{{OrgID: 2}},
+	}
+	resources, err := json.Marshal(resourcesOf(removedFile))
+	require.NoError(t, err)
+
+	current := &AlertingFile{
+		Groups: []models.AlertRuleGroupWithFolderFullpath{{
+			AlertRuleGroup: &models.AlertRuleGroup{Rules: []models.AlertRule{{UID: "moved"}}},
+			OrgID:          1,
+		}},
+	}
+	prune, paths := pruneFile(log.NewNopLogger(), map[string]status.FileState{"removed.yaml": {Resources: resources}}, []*AlertingFile{current})
+
+	assert.Equal(t, []string{"removed.yaml"}, paths)
+	assert.Equal(t, []RuleDelete{{UID: "removed", OrgID: 1}}, prune.DeleteRules)
+	assert.Equal(t, []DeleteTemplate{{OrgID: 1, Name: "removed"}}, prune.DeleteTemplates)
+	assert.Equal(t, []OrgID{2}, prune.ResetPolicies)
+	assert.Empty(t, prune.DeleteContactPoints)
+	assert.Empty(t, prune.DeleteMuteTimes)
+}
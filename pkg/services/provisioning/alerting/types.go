@@ -16,6 +16,7 @@ type OrgID int64
 type AlertingFile struct {
 	configVersion
 	Filename            string
+	Hash                string
 	Groups              []models.AlertRuleGroupWithFolderFullpath
 	DeleteRules         []RuleDelete
 	ContactPoints       []ContactPoint
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 )
 
@@ -21,10 +22,24 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	Status() []status.ProvisionerStatus
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(context.Context, string, []ProviderConfig, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
+
+// ProviderConfig is a file provider of dashboards which is not defined in the dashboards
+// provisioning files, such as the dashboards of a Git repository.
+type ProviderConfig struct {
+	Name                      string
+	OrgID                     int64
+	Folder                    string
+	Path                      string
+	FoldersFromFilesStructure bool
+	UpdateIntervalSeconds     int64
+	// Origin returns where a dashboard file comes from, such as the commit of a Git repository.
+	Origin func(path string) string
+}
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -40,7 +55,7 @@ func (provider *Provisioner) HasDashboardSources() bool {
 }
 
 // New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
+func New(ctx context.Context, configDirectory string, providers []ProviderConfig, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger, orgService: orgService}
 	configs, err := cfgReader.readConfig(ctx)
@@ -48,10 +63,28 @@ func New(ctx context.Context, configDirectory string, provisioner dashboards.Das
 		return nil, fmt.Errorf("%v: %w", "Failed to read dashboards config", err)
 	}
 
+	origins := map[string]func(string) string{}
+	for _, p := range providers {
+		for _, cfg := range configs {
+			if cfg.Name == p.Name {
+				return nil, fmt.Errorf("dashboard provider %q is already defined in the provisioning files", p.Name)
+			}
+		}
+		configs = append(configs, p.config())
+		if p.Origin != nil {
+			origins[p.Name] = p.Origin
+		}
+	}
+
 	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService)
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", "Failed to initialize file readers", err)
 	}
+	for _, reader := range fileReaders {
+		if origin, ok := origins[reader.Cfg.Name]; ok {
+			reader.origin = origin
+		}
+	}
 
 	d := &Provisioner{
 		log:                logger,
@@ -64,6 +97,27 @@ func New(ctx context.Context, configDirectory string, provisioner dashboards.Das
 	return d, nil
 }
 
+func (p ProviderConfig) config() *config {
+	cfg := &config{
+		Name:                  p.Name,
+		Type:                  "file",
+		OrgID:                 p.OrgID,
+		Folder:                p.Folder,
+		UpdateIntervalSeconds: p.UpdateIntervalSeconds,
+		Options: map[string]any{
+			"path":                      p.Path,
+			"foldersFromFilesStructure": p.FoldersFromFilesStructure,
+		},
+	}
+	if cfg.OrgID == 0 {
+		cfg.OrgID = 1
+	}
+	if cfg.UpdateIntervalSeconds == 0 {
+		cfg.UpdateIntervalSeconds = 10
+	}
+	return cfg
+}
+
 // Provision scans the disk for dashboards and updates
 // the database with the latest versions of those dashboards.
 func (provider *Provisioner) Provision(ctx context.Context) error {
@@ -74,7 +128,7 @@ func (provider *Provisioner) Provision(ctx context.Context) error {
 			if os.IsNotExist(err) {
 				// don't stop the provisioning service in case the folder is missing. The folder can appear after the startup
 				provider.log.Warn("Failed to provision config", "name", reader.Cfg.Name, "error", err)
-				return nil
+				continue
 			}
 
 			return fmt.Errorf("failed to provision config %v: %w", reader.Cfg.Name, err)
@@ -130,6 +184,15 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// Status returns the status of the last walk of the disk of each dashboard provider.
+func (provider *Provisioner) Status() []status.ProvisionerStatus {
+	result := make([]status.ProvisionerStatus, 0, len(provider.fileReaders))
+	for _, reader := range provider.fileReaders {
+		result = append(result, reader.getStatus())
+	}
+	return result
+}
+
 func getFileReaders(
 	configs []*config,
 	logger log.Logger,
@@ -1,6 +1,10 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -67,3 +71,8 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// Status not implemented for mocks
+func (dpm *ProvisionerMock) Status() []status.ProvisionerStatus {
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -43,6 +44,12 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+	status                  status.ProvisionerStatus
+
+	// files are the results of the files of the current walk of the disk
+	files []status.FileStatus
+	// origin returns where a dashboard file comes from, if the provider is not defined in the provisioning files
+	origin func(path string) string
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -73,6 +80,7 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		folderService:                folderService,
 		FoldersFromFilesStructure:    foldersFromFilesStructure,
 		usageTracker:                 newUsageTracker(),
+		status:                       status.ProvisionerStatus{Name: "dashboards/" + cfg.Name, Files: []status.FileStatus{}},
 	}, nil
 }
 
@@ -93,8 +101,13 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
-func (fr *FileReader) walkDisk(ctx context.Context) error {
+func (fr *FileReader) walkDisk(ctx context.Context) (err error) {
 	fr.log.Debug("Start walking disk", "path", fr.Path)
+	started := time.Now()
+	fr.files = []status.FileStatus{}
+	defer func() {
+		fr.setStatus(started, err)
+	}()
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
 		return err
@@ -155,6 +168,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 	// save dashboards based on json files
 	for path, fileInfo := range filesFoundOnDisk {
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, folderUID, fileInfo, dashboardRefs)
+		fr.recordFile(path, provisioningMetadata, err)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
 			continue
@@ -183,6 +197,7 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Cont
 		}
 
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, folderUID, fileInfo, dashboardRefs)
+		fr.recordFile(path, provisioningMetadata, err)
 		usageTracker.track(provisioningMetadata)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
@@ -238,9 +253,9 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 
 	jsonFile, err := fr.readDashboardFromFile(path, resolvedFileInfo.ModTime(), folderID, folderUID)
 	if err != nil {
-		fr.log.Error("failed to load dashboard from ", "file", path, "error", err)
-		return provisioningMetadata, nil
+		return provisioningMetadata, fmt.Errorf("failed to load dashboard: %w", err)
 	}
+	provisioningMetadata.checkSum = jsonFile.checkSum
 
 	upToDate := alreadyProvisioned
 	if provisionedData != nil {
@@ -309,6 +324,7 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 		if err != nil {
 			return provisioningMetadata, err
 		}
+		provisioningMetadata.saved = true
 	} else {
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Provisioning).Inc()
 		// nolint:staticcheck
@@ -500,6 +516,41 @@ func (fr *FileReader) resolvedPath() string {
 	return path
 }
 
+// recordFile records the result of a dashboard file in the status of the current walk of the disk.
+func (fr *FileReader) recordFile(path string, pm provisioningMetadata, err error) {
+	file := status.FileStatus{Path: path, Hash: pm.checkSum, Applied: pm.saved}
+	if fr.origin != nil {
+		file.Origin = fr.origin(path)
+	}
+	if err != nil {
+		file.Error = err.Error()
+	}
+	fr.files = append(fr.files, file)
+}
+
+func (fr *FileReader) setStatus(started time.Time, err error) {
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+
+	fr.status = status.ProvisionerStatus{
+		Name:       fr.status.Name,
+		LastRun:    started,
+		DurationMs: time.Since(started).Milliseconds(),
+		Files:      fr.files,
+	}
+	if err != nil {
+		fr.status.Error = err.Error()
+	}
+}
+
+// getStatus returns the status of the last walk of the disk.
+func (fr *FileReader) getStatus() status.ProvisionerStatus {
+	fr.mux.RLock()
+	defer fr.mux.RUnlock()
+
+	return fr.status
+}
+
 func (fr *FileReader) getUsageTracker() *usageTracker {
 	fr.mux.RLock()
 	defer fr.mux.RUnlock()
@@ -510,6 +561,9 @@ func (fr *FileReader) getUsageTracker() *usageTracker {
 type provisioningMetadata struct {
 	uid      string
 	identity dashboardIdentity
+	checkSum string
+	// saved is true if the dashboard changed and was saved
+	saved bool
 }
 
 type dashboardIdentity struct {
@@ -13,12 +13,16 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
 )
 
 type configReader struct {
 	log        log.Logger
 	orgService org.Service
+	// literal turns off the interpolation of the files, see utils.ConfigDir.
+	literal bool
 }
 
 func (cr *configReader) readConfig(ctx context.Context, path string) ([]*configs, error) {
@@ -51,6 +55,35 @@ func (cr *configReader) readConfig(ctx context.Context, path string) ([]*configs
 	return datasources, nil
 }
 
+// readConfigs reads the configs of all the directories. The default datasources must be
+// unique across the directories.
+func (cr *configReader) readConfigs(ctx context.Context, dirs []utils.ConfigDir) ([]*configs, error) {
+	var datasources []*configs
+	for _, dir := range dirs {
+		if dir.Unavailable {
+			continue
+		}
+		dirReader := *cr
+		dirReader.literal = dir.Literal
+		dirConfigs, err := dirReader.readConfig(ctx, dir.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, cfg := range dirConfigs {
+			cfg.Filename = dir.Prefix + cfg.Filename
+		}
+		datasources = append(datasources, dirConfigs...)
+	}
+
+	if len(dirs) > 1 {
+		if err := cr.validateDefaultUniqueness(ctx, datasources); err != nil {
+			return nil, err
+		}
+	}
+
+	return datasources, nil
+}
+
 func (cr *configReader) parseDatasourceConfig(path string, file fs.DirEntry) (*configs, error) {
 	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
 
@@ -62,7 +95,7 @@ func (cr *configReader) parseDatasourceConfig(path string, file fs.DirEntry) (*c
 	}
 
 	var apiVersion *configVersion
-	err = yaml.Unmarshal(yamlFile, &apiVersion)
+	err = cr.unmarshal(yamlFile, &apiVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -73,23 +106,34 @@ func (cr *configReader) parseDatasourceConfig(path string, file fs.DirEntry) (*c
 
 	if apiVersion.APIVersion > 0 {
 		v1 := &configsV1{log: cr.log}
-		err = yaml.Unmarshal(yamlFile, v1)
+		err = cr.unmarshal(yamlFile, v1)
 		if err != nil {
 			return nil, err
 		}
 
-		return v1.mapToDatasourceFromConfig(apiVersion.APIVersion), nil
+		cfg := v1.mapToDatasourceFromConfig(apiVersion.APIVersion)
+		cfg.Filename, cfg.Hash = file.Name(), status.HashConfig(cfg)
+		return cfg, nil
 	}
 
 	var v0 *configsV0
-	err = yaml.Unmarshal(yamlFile, &v0)
+	err = cr.unmarshal(yamlFile, &v0)
 	if err != nil {
 		return nil, err
 	}
 
 	cr.log.Warn("[Deprecated] the datasource provisioning config is outdated. please upgrade", "filename", filename)
 
-	return v0.mapToDatasourceFromConfig(apiVersion.APIVersion), nil
+	cfg := v0.mapToDatasourceFromConfig(apiVersion.APIVersion)
+	cfg.Filename, cfg.Hash = file.Name(), status.HashConfig(cfg)
+	return cfg, nil
+}
+
+func (cr *configReader) unmarshal(data []byte, v any) error {
+	if cr.literal {
+		return values.UnmarshalLiteral(data, v)
+	}
+	return yaml.Unmarshal(data, v)
 }
 
 func (cr *configReader) validateDefaultUniqueness(ctx context.Context, datasources []*configs) error {
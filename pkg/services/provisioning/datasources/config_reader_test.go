@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -256,6 +257,29 @@ func TestDatasourceAsConfig(t *testing.T) {
 		require.Equal(t, delDsCount, 1)
 	})
 
+	t.Run("the hash of a file changes with the environment variables it refers to", func(t *testing.T) {
+		cfgProvider := &configReader{log: log.New("test logger"), orgService: &orgtest.FakeOrgService{}}
+		t.Setenv("TEST_VAR", "name")
+		before, err := cfgProvider.readConfig(context.Background(), allProperties)
+		require.NoError(t, err)
+		t.Setenv("TEST_VAR", "other name")
+		after, err := cfgProvider.readConfig(context.Background(), allProperties)
+		require.NoError(t, err)
+
+		require.Equal(t, before[0].Filename, after[0].Filename)
+		require.NotEmpty(t, before[0].Hash)
+		require.NotEqual(t, before[0].Hash, after[0].Hash)
+	})
+
+	t.Run("does not interpolate the files of literal directories", func(t *testing.T) {
+		t.Setenv("TEST_VAR", "name")
+		cfgProvider := &configReader{log: log.New("test logger"), orgService: &orgtest.FakeOrgService{}}
+		cfg, err := cfgProvider.readConfigs(context.Background(), []utils.ConfigDir{{Path: allProperties, Prefix: "git/repo/", Literal: true}})
+		require.NoError(t, err)
+		require.Equal(t, "$TEST_VAR", cfg[0].Datasources[0].Name)
+		require.Equal(t, "git/repo/all-properties.yaml", cfg[0].Filename)
+	})
+
 	t.Run("can read all properties from version 0", func(t *testing.T) {
 		cfgProvider := &configReader{log: log.New("test logger"), orgService: &orgtest.FakeOrgService{}}
 		cfg, err := cfgProvider.readConfig(context.Background(), versionZero)
@@ -2,13 +2,17 @@ package datasources
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -33,11 +37,21 @@ var (
 	ErrInvalidConfigToManyDefault = errors.New("datasource.yaml config is invalid. Only one datasource per organization can be marked as default")
 )
 
-// Provision scans a directory for provisioning config files
-// and provisions the datasource in those files.
-func Provision(ctx context.Context, configDirectory string, dsService BaseDataSourceService, correlationsStore CorrelationsStore, orgService org.Service) error {
+// ProvisionerName is the name of the datasource provisioner in the provisioning status.
+const ProvisionerName = "datasources"
+
+// Provision scans directories for provisioning config files
+// and provisions the datasource in those files. The files which did not change since
+// they were last applied are skipped, and the datasources of the files removed from
+// the directories are deleted if prune is true.
+func Provision(ctx context.Context, configDirectories []utils.ConfigDir, dsService BaseDataSourceService, correlationsStore CorrelationsStore, orgService org.Service, tracker *status.Tracker, prune bool) (err error) {
 	dc := newDatasourceProvisioner(log.New("provisioning.datasources"), dsService, correlationsStore, orgService)
-	return dc.applyChanges(ctx, configDirectory)
+	dc.run = tracker.Start(ctx, ProvisionerName)
+	dc.prune = prune
+	defer func() {
+		dc.run.Finish(ctx, err)
+	}()
+	return dc.applyDirs(ctx, configDirectories)
 }
 
 // DatasourceProvisioner is responsible for provisioning datasources based on
@@ -47,6 +61,8 @@ type DatasourceProvisioner struct {
 	cfgProvider       *configReader
 	dsService         BaseDataSourceService
 	correlationsStore CorrelationsStore
+	run               *status.Run
+	prune             bool
 }
 
 func newDatasourceProvisioner(log log.Logger, dsService BaseDataSourceService, correlationsStore CorrelationsStore, orgService org.Service) DatasourceProvisioner {
@@ -55,6 +71,8 @@ func newDatasourceProvisioner(log log.Logger, dsService BaseDataSourceService, c
 		cfgProvider:       &configReader{log: log, orgService: orgService},
 		dsService:         dsService,
 		correlationsStore: correlationsStore,
+		// without a tracker, all the files are applied
+		run: status.NewTracker(nil, false).Start(context.Background(), ProvisionerName),
 	}
 }
 
@@ -138,7 +156,16 @@ type DataSourceMapKey struct {
 }
 
 func (dc *DatasourceProvisioner) applyChanges(ctx context.Context, configPath string) error {
-	configs, err := dc.cfgProvider.readConfig(ctx, configPath)
+	return dc.applyDirs(ctx, []utils.ConfigDir{{Path: configPath}})
+}
+
+func (dc *DatasourceProvisioner) applyDirs(ctx context.Context, configDirectories []utils.ConfigDir) error {
+	for _, dir := range configDirectories {
+		if dir.Unavailable {
+			dc.run.Keep(dir.Prefix)
+		}
+	}
+	configs, err := dc.cfgProvider.readConfigs(ctx, configDirectories)
 	if err != nil {
 		return err
 	}
@@ -172,25 +199,105 @@ func (dc *DatasourceProvisioner) applyChanges(ctx context.Context, configPath st
 		}
 	}
 
+	var pruned []string
+	if dc.prune {
+		for path, state := range dc.run.Removed() {
+			var removed []*deleteDatasourceConfig
+			if len(state.Resources) > 0 {
+				if err := json.Unmarshal(state.Resources, &removed); err != nil {
+					dc.log.Warn("failed to read the datasources of a removed file, they are not pruned", "file", path, "error", err)
+					continue
+				}
+			}
+			for _, ds := range removed {
+				key := DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}
+				if _, ok := willExistAfterProvisioning[key]; !ok {
+					staleProvisionedDataSources = append(staleProvisionedDataSources, ds)
+					willExistAfterProvisioning[key] = false
+				}
+			}
+			pruned = append(pruned, path)
+		}
+	}
+
 	if err := dc.deleteDatasources(ctx, staleProvisionedDataSources, willExistAfterProvisioning); err != nil {
 		return err
 	}
+	for _, path := range pruned {
+		dc.log.Info("pruned datasources of removed file", "file", path)
+		dc.run.Pruned(path)
+	}
 
-	for _, cfg := range configs {
+	changed := dc.changedConfigs(configs, staleProvisionedDataSources)
+	for _, cfg := range changed {
 		if err := dc.provisionDataSources(ctx, cfg, willExistAfterProvisioning); err != nil {
+			dc.run.Failed(cfg.Filename, cfg.Hash, err)
 			return err
 		}
 	}
 
-	for _, cfg := range configs {
+	for _, cfg := range changed {
 		if err := dc.provisionCorrelations(ctx, cfg); err != nil {
+			dc.run.Failed(cfg.Filename, cfg.Hash, err)
 			return err
 		}
 	}
 
+	for _, cfg := range configs {
+		if slices.Contains(changed, cfg) {
+			dc.run.Applied(cfg.Filename, cfg.Hash, provisionedDataSources(cfg))
+		} else {
+			dc.run.Skipped(cfg.Filename, cfg.Hash)
+		}
+	}
+
 	return nil
 }
 
+// changedConfigs returns the configs of the files which changed since they were last
+// applied, and of the files whose datasources are deleted before they are provisioned
+// again.
+func (dc *DatasourceProvisioner) changedConfigs(configs []*configs, stale []*deleteDatasourceConfig) []*configs {
+	deleted := map[DataSourceMapKey]bool{}
+	for _, ds := range stale {
+		deleted[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] = true
+	}
+	changed := make([]bool, len(configs))
+	for i, cfg := range configs {
+		if dc.run.Changed(cfg.Filename, cfg.Hash) {
+			changed[i] = true
+			for _, ds := range cfg.DeleteDatasources {
+				deleted[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] = true
+			}
+		}
+	}
+
+	var result []*configs
+	for i, cfg := range configs {
+		for _, ds := range cfg.Datasources {
+			if deleted[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] {
+				changed[i] = true
+			}
+		}
+		if changed[i] {
+			result = append(result, cfg)
+		} else {
+			dc.log.Debug("skipping unchanged datasource provisioning file", "file", cfg.Filename)
+		}
+	}
+	return result
+}
+
+// provisionedDataSources returns the datasources provisioned by the file of cfg, which
+// are deleted when the file is removed if pruning is enabled.
+func provisionedDataSources(cfg *configs) []*deleteDatasourceConfig {
+	result := make([]*deleteDatasourceConfig, 0, len(cfg.Datasources))
+	for _, ds := range cfg.Datasources {
+		result = append(result, &deleteDatasourceConfig{OrgID: ds.OrgID, Name: ds.Name})
+	}
+	return result
+}
+
 func makeCreateCorrelationCommand(correlation map[string]any, SourceUID string, OrgId int64) (correlations.CreateCorrelationCommand, error) {
 	// we look for a correlation type at the root if it is defined, if not use default
 	// we ignore the legacy config.type value - the only valid value at that version was "query"
@@ -20,6 +20,10 @@ type configs struct {
 	APIVersion int64
 	Prune      bool
 
+	// Filename is the name of the file the configs were read from, and Hash is the hash of its content.
+	Filename string
+	Hash     string
+
 	Datasources       []*upsertDataSourceFromConfig
 	DeleteDatasources []*deleteDatasourceConfig
 }
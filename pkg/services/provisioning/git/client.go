@@ -0,0 +1,149 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// remote is a branch of a Git repository, and the credentials to fetch it.
+type remote struct {
+	URL        string
+	Branch     string
+	AuthType   string
+	Username   string
+	Token      string
+	PrivateKey string
+	KnownHosts string
+}
+
+// client checks out the last commit of a remote branch in a directory.
+type client interface {
+	// checkout clones the remote branch in dir, or updates the existing clone, and returns the SHA of the commit.
+	checkout(ctx context.Context, dir string, r remote) (string, error)
+}
+
+// execClient runs the git binary, so that the repositories can use all the transports and authentication
+// methods it supports.
+type execClient struct{}
+
+func (c execClient) checkout(ctx context.Context, dir string, r remote) (string, error) {
+	env, cleanup, err := authEnv(r)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0o750); err != nil {
+			return "", err
+		}
+		if _, err := c.run(ctx, "", env, r, "clone", "--depth", "1", "--single-branch", "--branch", r.Branch, "--", r.URL, dir); err != nil {
+			return "", err
+		}
+	} else {
+		for _, args := range [][]string{
+			{"remote", "set-url", "origin", r.URL},
+			{"fetch", "--depth", "1", "origin", r.Branch},
+			{"reset", "--hard", "FETCH_HEAD"},
+			{"clean", "-ffdx"},
+		} {
+			if _, err := c.run(ctx, dir, env, r, args...); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	out, err := c.run(ctx, dir, env, r, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (c execClient) run(ctx context.Context, dir string, env []string, r remote, args ...string) (string, error) {
+	// nolint:gosec
+	// The arguments come from the provisioning files, and the URL and branch are validated when they are read.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if r.Token != "" {
+			msg = strings.ReplaceAll(msg, r.Token, "[redacted]")
+		}
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+	}
+	return stdout.String(), nil
+}
+
+// authEnv returns the environment of the git commands which authenticate to the remote, and a function which
+// deletes the files it created.
+func authEnv(r remote) ([]string, func(), error) {
+	env := []string{
+		"GIT_TERMINAL_PROMPT=0",
+		// ext:: and the other transports which run commands are not allowed
+		"GIT_ALLOW_PROTOCOL=file:git:http:https:ssh",
+	}
+	switch r.AuthType {
+	case authTypeToken:
+		credentials := base64.StdEncoding.EncodeToString([]byte(r.Username + ":" + r.Token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	case authTypeSSH:
+		key, err := writeTempFile("grafana-git-key-*", r.PrivateKey)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		knownHosts, err := writeTempFile("grafana-git-known-hosts-*", r.KnownHosts)
+		if err != nil {
+			_ = os.Remove(key)
+			return nil, func() {}, err
+		}
+		cleanup := func() {
+			_ = os.Remove(key)
+			_ = os.Remove(knownHosts)
+		}
+		// the host keys must be known, a remote whose key is not in knownHosts is refused
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i '%s' -o IdentitiesOnly=yes -o StrictHostKeyChecking=yes -o UserKnownHostsFile='%s'", key, knownHosts))
+		return env, cleanup, nil
+	}
+	return env, func() {}, nil
+}
+
+// writeTempFile writes content to a new temporary file only readable by its owner, and returns its name.
+func writeTempFile(pattern string, content string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0o600)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
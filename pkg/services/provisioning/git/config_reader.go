@@ -0,0 +1,189 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+const (
+	defaultBranch   = "main"
+	defaultInterval = 5 * time.Minute
+	defaultUsername = "git"
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type configReader struct {
+	log     log.Logger
+	secrets secrets.Service
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*repositoryConfig, error) {
+	var repositories []*repositoryConfig
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			cr.log.Error("Can't read Git provisioning files from directory", "path", path, "error", err)
+		}
+		return repositories, nil
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+
+		parsed, err := cr.parseConfig(ctx, path, file)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %w", file.Name(), err)
+		}
+		repositories = append(repositories, parsed...)
+	}
+
+	names := map[string]bool{}
+	for _, repo := range repositories {
+		if names[repo.Name] {
+			return nil, fmt.Errorf("git repository %q is defined more than once", repo.Name)
+		}
+		names[repo.Name] = true
+	}
+
+	return repositories, nil
+}
+
+func (cr *configReader) parseConfig(ctx context.Context, path string, file fs.DirEntry) ([]*repositoryConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiVersion *configVersion
+	if err := yaml.Unmarshal(yamlFile, &apiVersion); err != nil {
+		return nil, err
+	}
+	if apiVersion == nil || apiVersion.APIVersion != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion, expected 1")
+	}
+
+	var v1 *configV1
+	if err := yaml.Unmarshal(yamlFile, &v1); err != nil {
+		return nil, err
+	}
+	if v1 == nil {
+		return nil, nil
+	}
+
+	repositories := make([]*repositoryConfig, 0, len(v1.Repositories))
+	for _, r := range v1.Repositories {
+		repo, err := cr.mapToRepositoryConfig(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, repo)
+	}
+	return repositories, nil
+}
+
+func (cr *configReader) mapToRepositoryConfig(ctx context.Context, r *repositoryFromConfigV1) (*repositoryConfig, error) {
+	repo := &repositoryConfig{
+		Name:   r.Name.Value(),
+		URL:    r.URL.Value(),
+		Branch: r.Branch.Value(),
+		Path:   r.Path.Value(),
+		Auth: authConfig{
+			Type:       r.Auth.Type.Value(),
+			Username:   r.Auth.Username.Value(),
+			KnownHosts: r.Auth.KnownHosts.Value(),
+		},
+		Dashboards: dashboardsConfig{
+			OrgID:                     r.Dashboards.OrgID.Value(),
+			Folder:                    r.Dashboards.Folder.Value(),
+			FoldersFromFilesStructure: r.Dashboards.FoldersFromFilesStructure.Value(),
+			UpdateIntervalSeconds:     r.Dashboards.UpdateIntervalSeconds.Value(),
+		},
+		SecureJSONData: map[string][]byte{},
+	}
+
+	if !validName.MatchString(repo.Name) {
+		return nil, fmt.Errorf("git repository name %q is invalid, only letters, digits, '-' and '_' are allowed", repo.Name)
+	}
+	if repo.URL == "" || strings.HasPrefix(repo.URL, "-") {
+		return nil, fmt.Errorf("git repository %q: url is invalid", repo.Name)
+	}
+	if repo.Branch == "" {
+		repo.Branch = defaultBranch
+	}
+	if strings.HasPrefix(repo.Branch, "-") || strings.ContainsAny(repo.Branch, " \t\n:") {
+		return nil, fmt.Errorf("git repository %q: branch %q is invalid", repo.Name, repo.Branch)
+	}
+	if repo.Path == "" {
+		repo.Path = "."
+	}
+	if !filepath.IsLocal(repo.Path) {
+		return nil, fmt.Errorf("git repository %q: path %q must be relative to the root of the repository", repo.Name, repo.Path)
+	}
+
+	repo.Interval = defaultInterval
+	if interval := r.Interval.Value(); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("git repository %q: interval %q is invalid", repo.Name, interval)
+		}
+		repo.Interval = d
+	}
+
+	secureJSONData := r.SecureJSONData.Value()
+	switch repo.Auth.Type {
+	case "":
+	case authTypeToken:
+		if secureJSONData[secureToken] == "" {
+			return nil, fmt.Errorf("git repository %q: token authentication requires secureJsonData.%s", repo.Name, secureToken)
+		}
+		if repo.Auth.Username == "" {
+			repo.Auth.Username = defaultUsername
+		}
+	case authTypeSSH:
+		if secureJSONData[securePrivateKey] == "" {
+			return nil, fmt.Errorf("git repository %q: SSH authentication requires secureJsonData.%s", repo.Name, securePrivateKey)
+		}
+		if strings.TrimSpace(repo.Auth.KnownHosts) == "" {
+			return nil, fmt.Errorf("git repository %q: SSH authentication requires auth.knownHosts", repo.Name)
+		}
+	default:
+		return nil, fmt.Errorf("git repository %q: auth type %q is not supported", repo.Name, repo.Auth.Type)
+	}
+
+	for key, value := range secureJSONData {
+		if value == "" {
+			continue
+		}
+		encrypted, err := cr.secrets.Encrypt(ctx, []byte(value), secrets.WithoutScope())
+		if err != nil {
+			return nil, fmt.Errorf("git repository %q: failed to encrypt %s: %w", repo.Name, key, err)
+		}
+		repo.SecureJSONData[key] = encrypted
+	}
+
+	if repo.Dashboards.OrgID == 0 {
+		repo.Dashboards.OrgID = 1
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+const (
+	correctProperties = "./testdata/test-configs/correct-properties"
+	duplicateNames    = "./testdata/test-configs/duplicate-names"
+	missingToken      = "./testdata/test-configs/missing-token"
+	missingKnownHosts = "./testdata/test-configs/missing-known-hosts"
+	invalidPath       = "./testdata/test-configs/invalid-path"
+)
+
+func TestConfigReader(t *testing.T) {
+	reader := &configReader{log: log.New("test logger"), secrets: fakes.NewFakeSecretsService()}
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		repos, err := reader.readConfig(context.Background(), correctProperties)
+		require.NoError(t, err)
+		require.Len(t, repos, 2)
+
+		require.Equal(t, &repositoryConfig{
+			Name:     "ops",
+			URL:      "https://github.com/example/ops.git",
+			Branch:   "production",
+			Path:     "grafana",
+			Interval: time.Minute,
+			Auth:     authConfig{Type: authTypeToken, Username: defaultUsername},
+			Dashboards: dashboardsConfig{
+				OrgID:  2,
+				Folder: "Ops",
+			},
+			SecureJSONData: map[string][]byte{
+				secureToken:         []byte("secret-token"),
+				secureWebhookSecret: []byte("webhook-secret"),
+			},
+		}, repos[0])
+
+		require.Equal(t, defaultBranch, repos[1].Branch)
+		require.Equal(t, ".", repos[1].Path)
+		require.Equal(t, defaultInterval, repos[1].Interval)
+		require.Equal(t, int64(1), repos[1].Dashboards.OrgID)
+	})
+
+	t.Run("Skip missing directory", func(t *testing.T) {
+		repos, err := reader.readConfig(context.Background(), "./testdata/test-configs/missing")
+		require.NoError(t, err)
+		require.Len(t, repos, 0)
+	})
+
+	t.Run("Invalid configs should return error", func(t *testing.T) {
+		for _, path := range []string{duplicateNames, missingToken, missingKnownHosts, invalidPath} {
+			_, err := reader.readConfig(context.Background(), path)
+			require.Error(t, err, path)
+		}
+	})
+}
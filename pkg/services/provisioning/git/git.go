@@ -0,0 +1,339 @@
+package git
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const syncTimeout = 5 * time.Minute
+
+var (
+	ErrRepositoryNotFound      = errors.New("git repository not found")
+	ErrWebhookNotConfigured    = errors.New("webhook secret is not configured for the Git repository")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// Service keeps a checkout of each Git repository defined in the provisioning files, and syncs it on an interval or
+// when a webhook is received. The dashboards, data sources and alerting resources of the checkouts are provisioned
+// like the ones of the provisioning directory.
+type Service struct {
+	log      log.Logger
+	secrets  secrets.Service
+	client   client
+	dataPath string
+	repos    map[string]*repository
+
+	// syncMtx makes sure that only one repository is synced at a time
+	syncMtx sync.Mutex
+}
+
+type repository struct {
+	cfg     *repositoryConfig
+	dir     string
+	trigger chan struct{}
+
+	mtx    sync.RWMutex
+	status RepositoryStatus
+}
+
+// NewService reads the Git repositories of the provisioning files.
+func NewService(ctx context.Context, cfg *setting.Cfg, secretsService secrets.Service) (*Service, error) {
+	logger := log.New("provisioning.git")
+	reader := &configReader{log: logger, secrets: secretsService}
+	configs, err := reader.readConfig(ctx, filepath.Join(cfg.ProvisioningPath, "git"))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "Failed to read Git repositories config", err)
+	}
+
+	s := &Service{
+		log:      logger,
+		secrets:  secretsService,
+		client:   execClient{},
+		dataPath: filepath.Join(cfg.DataPath, "provisioning", "git"),
+		repos:    make(map[string]*repository, len(configs)),
+	}
+	for _, c := range configs {
+		s.repos[c.Name] = &repository{
+			cfg:     c,
+			dir:     filepath.Join(s.dataPath, c.Name),
+			trigger: make(chan struct{}, 1),
+			status: RepositoryStatus{
+				Name:   c.Name,
+				URL:    redactURL(c.URL),
+				Branch: c.Branch,
+				Path:   c.Path,
+			},
+		}
+	}
+	return s, nil
+}
+
+// Run syncs each repository when it starts, then on its interval or when it is triggered, and calls onChange after a sync which checked
+// out a new commit. It returns when ctx is done.
+func (s *Service) Run(ctx context.Context, onChange func(ctx context.Context, name string)) {
+	var wg sync.WaitGroup
+	for _, repo := range s.repos {
+		wg.Add(1)
+		go func(repo *repository) {
+			defer wg.Done()
+			s.poll(ctx, repo, onChange)
+		}(repo)
+	}
+	wg.Wait()
+}
+
+func (s *Service) poll(ctx context.Context, repo *repository, onChange func(ctx context.Context, name string)) {
+	ticker := time.NewTicker(repo.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		changed, err := s.sync(ctx, repo)
+		if err != nil {
+			s.log.Error("Failed to sync Git repository", "repository", repo.cfg.Name, "error", err)
+		} else if changed {
+			onChange(ctx, repo.cfg.Name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-repo.trigger:
+		}
+	}
+}
+
+// sync checks out the last commit of the branch of the repository, and returns true if the commit changed.
+func (s *Service) sync(ctx context.Context, repo *repository) (bool, error) {
+	s.syncMtx.Lock()
+	defer s.syncMtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+
+	started := time.Now()
+	commit, err := s.checkout(ctx, repo)
+
+	repo.mtx.Lock()
+	defer repo.mtx.Unlock()
+	repo.status.LastSync = started
+	if err != nil {
+		repo.status.Error = err.Error()
+		return false, err
+	}
+	changed := commit != repo.status.Commit
+	if changed {
+		s.log.Info("Checked out new commit of Git repository", "repository", repo.cfg.Name, "commit", commit)
+		repo.status.LastChange = started
+	}
+	repo.status.Commit = commit
+	repo.status.Error = ""
+	return changed, nil
+}
+
+func (s *Service) checkout(ctx context.Context, repo *repository) (string, error) {
+	r := remote{
+		URL:        repo.cfg.URL,
+		Branch:     repo.cfg.Branch,
+		AuthType:   repo.cfg.Auth.Type,
+		Username:   repo.cfg.Auth.Username,
+		KnownHosts: repo.cfg.Auth.KnownHosts,
+	}
+	var err error
+	switch r.AuthType {
+	case authTypeToken:
+		r.Token, err = s.decrypt(ctx, repo, secureToken)
+	case authTypeSSH:
+		r.PrivateKey, err = s.decrypt(ctx, repo, securePrivateKey)
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.client.checkout(ctx, repo.dir, r)
+}
+
+func (s *Service) decrypt(ctx context.Context, repo *repository, key string) (string, error) {
+	encrypted, ok := repo.cfg.SecureJSONData[key]
+	if !ok {
+		return "", nil
+	}
+	decrypted, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", key, err)
+	}
+	return string(decrypted), nil
+}
+
+// Trigger syncs the repository in the background.
+func (s *Service) Trigger(name string) error {
+	repo, ok := s.repos[name]
+	if !ok {
+		return ErrRepositoryNotFound
+	}
+	select {
+	case repo.trigger <- struct{}{}:
+	default:
+		// a sync is already pending
+	}
+	return nil
+}
+
+// HandleWebhook verifies a push webhook of GitHub or GitLab with the webhook secret of the repository, and triggers a
+// sync of the repository if the push is to its branch. It returns false if the webhook was ignored.
+func (s *Service) HandleWebhook(ctx context.Context, name string, header http.Header, body []byte) (bool, error) {
+	repo, ok := s.repos[name]
+	if !ok {
+		return false, ErrRepositoryNotFound
+	}
+	secret, err := s.decrypt(ctx, repo, secureWebhookSecret)
+	if err != nil {
+		return false, err
+	}
+	if secret == "" {
+		return false, ErrWebhookNotConfigured
+	}
+	if !verifyWebhook(secret, header, body) {
+		return false, ErrInvalidWebhookSignature
+	}
+
+	var push struct {
+		Ref string `json:"ref"`
+	}
+	if err := json.Unmarshal(body, &push); err == nil && push.Ref != "" && push.Ref != "refs/heads/"+repo.cfg.Branch {
+		s.log.Debug("Ignoring push to another branch", "repository", name, "ref", push.Ref)
+		return false, nil
+	}
+	return true, s.Trigger(name)
+}
+
+// verifyWebhook verifies the signature of a GitHub webhook, or the token of a GitLab webhook.
+func verifyWebhook(secret string, header http.Header, body []byte) bool {
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+// Status returns the status of the last sync of each repository, sorted by name.
+func (s *Service) Status() []RepositoryStatus {
+	result := make([]RepositoryStatus, 0, len(s.repos))
+	for _, name := range s.names() {
+		repo := s.repos[name]
+		repo.mtx.RLock()
+		result = append(result, repo.status)
+		repo.mtx.RUnlock()
+	}
+	return result
+}
+
+// Origin returns the commit of the repository a provisioning file was read from, or an empty string if the file was
+// not read from a repository.
+func (s *Service) Origin(path string) string {
+	rest, ok := strings.CutPrefix(path, "git/")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return s.commit(name)
+}
+
+func (s *Service) commit(name string) string {
+	repo, ok := s.repos[name]
+	if !ok {
+		return ""
+	}
+	repo.mtx.RLock()
+	defer repo.mtx.RUnlock()
+	return repo.status.Commit
+}
+
+// Dirs returns the directories of the provisioning files of kind, such as "datasources" or "alerting", in the
+// checkouts of the repositories. The names of their files are prefixed with "git/<repository>/".
+func (s *Service) Dirs(kind string) []utils.ConfigDir {
+	var dirs []utils.ConfigDir
+	for _, name := range s.names() {
+		repo := s.repos[name]
+		dir := utils.ConfigDir{
+			Path:   filepath.Join(repo.dir, repo.cfg.Path, kind),
+			Prefix: prefix(name),
+			// anyone who can push to the repository must not be able to read the environment or the files
+			// of the server
+			Literal: true,
+		}
+		if _, err := os.Stat(filepath.Join(repo.dir, ".git")); err != nil {
+			dir.Unavailable = true
+		} else if _, err := os.Stat(dir.Path); err != nil {
+			// the repository has no files of this kind
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// DashboardProviders returns a dashboard provider for the dashboards directory of each repository.
+func (s *Service) DashboardProviders() []dashboards.ProviderConfig {
+	providers := make([]dashboards.ProviderConfig, 0, len(s.repos))
+	for _, name := range s.names() {
+		repo := s.repos[name]
+		providers = append(providers, dashboards.ProviderConfig{
+			Name:                      strings.TrimSuffix(prefix(name), "/"),
+			OrgID:                     repo.cfg.Dashboards.OrgID,
+			Folder:                    repo.cfg.Dashboards.Folder,
+			Path:                      filepath.Join(repo.dir, repo.cfg.Path, "dashboards"),
+			FoldersFromFilesStructure: repo.cfg.Dashboards.FoldersFromFilesStructure,
+			UpdateIntervalSeconds:     repo.cfg.Dashboards.UpdateIntervalSeconds,
+			Origin: func(string) string {
+				return s.commit(name)
+			},
+		})
+	}
+	return providers
+}
+
+func (s *Service) names() []string {
+	names := make([]string, 0, len(s.repos))
+	for name := range s.repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func prefix(name string) string {
+	return "git/" + name + "/"
+}
+
+// redactURL removes the credentials from the URL of a repository.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+type fakeClient struct {
+	commit string
+	err    error
+	remote remote
+}
+
+func (c *fakeClient) checkout(_ context.Context, dir string, r remote) (string, error) {
+	c.remote = r
+	if c.err != nil {
+		return "", c.err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o750); err != nil {
+		return "", err
+	}
+	return c.commit, nil
+}
+
+func setupService(t *testing.T, client client) *Service {
+	t.Helper()
+	dataPath := t.TempDir()
+	s := &Service{
+		log:      log.NewNopLogger(),
+		secrets:  fakes.NewFakeSecretsService(),
+		client:   client,
+		dataPath: dataPath,
+		repos:    map[string]*repository{},
+	}
+	s.repos["ops"] = &repository{
+		cfg: &repositoryConfig{
+			Name:   "ops",
+			URL:    "https://github.com/example/ops.git",
+			Branch: "main",
+			Path:   "grafana",
+			Auth:   authConfig{Type: authTypeToken, Username: "git"},
+			SecureJSONData: map[string][]byte{
+				secureToken:         []byte("token"),
+				secureWebhookSecret: []byte("secret"),
+			},
+		},
+		dir:     filepath.Join(dataPath, "ops"),
+		trigger: make(chan struct{}, 1),
+		status:  RepositoryStatus{Name: "ops"},
+	}
+	return s
+}
+
+func TestSync(t *testing.T) {
+	client := &fakeClient{commit: "abc"}
+	s := setupService(t, client)
+	repo := s.repos["ops"]
+
+	assert.True(t, s.Dirs("datasources")[0].Unavailable, "the repository was never checked out")
+
+	changed, err := s.sync(context.Background(), repo)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "token", client.remote.Token)
+	assert.Equal(t, "abc", s.Status()[0].Commit)
+	assert.Equal(t, "abc", s.Origin("git/ops/datasources.yaml"))
+	assert.Empty(t, s.Origin("datasources.yaml"))
+	assert.Empty(t, s.Dirs("datasources"), "the repository has no data sources")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repo.dir, "grafana", "datasources"), 0o750))
+	assert.Equal(t, "git/ops/", s.Dirs("datasources")[0].Prefix)
+
+	changed, err = s.sync(context.Background(), repo)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	client.err = errors.New("network error")
+	_, err = s.sync(context.Background(), repo)
+	require.Error(t, err)
+	assert.Equal(t, "network error", s.Status()[0].Error)
+	assert.Equal(t, "abc", s.Status()[0].Commit, "the previous checkout is kept")
+}
+
+func TestHandleWebhook(t *testing.T) {
+	s := setupService(t, &fakeClient{})
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	t.Run("GitHub push with a valid signature triggers a sync", func(t *testing.T) {
+		ok, err := s.HandleWebhook(context.Background(), "ops", http.Header{"X-Hub-Signature-256": {signature}}, body)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Len(t, s.repos["ops"].trigger, 1)
+	})
+
+	t.Run("GitLab push with a valid token triggers a sync", func(t *testing.T) {
+		ok, err := s.HandleWebhook(context.Background(), "ops", http.Header{"X-Gitlab-Token": {"secret"}}, body)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("push to another branch is ignored", func(t *testing.T) {
+		ok, err := s.HandleWebhook(context.Background(), "ops", http.Header{"X-Gitlab-Token": {"secret"}}, []byte(`{"ref":"refs/heads/dev"}`))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid signature is rejected", func(t *testing.T) {
+		_, err := s.HandleWebhook(context.Background(), "ops", http.Header{"X-Hub-Signature-256": {"sha256=00"}}, body)
+		require.ErrorIs(t, err, ErrInvalidWebhookSignature)
+
+		_, err = s.HandleWebhook(context.Background(), "ops", http.Header{}, body)
+		require.ErrorIs(t, err, ErrInvalidWebhookSignature)
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		_, err := s.HandleWebhook(context.Background(), "unknown", http.Header{}, body)
+		require.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+const (
+	authTypeToken = "token"
+	authTypeSSH   = "ssh"
+
+	secureToken         = "token"
+	securePrivateKey    = "privateKey"
+	secureWebhookSecret = "webhookSecret"
+)
+
+// repositoryConfig is a normalized Git repository of the provisioning files. Any config version should be mappable
+// to this type.
+type repositoryConfig struct {
+	Name     string
+	URL      string
+	Branch   string
+	Path     string
+	Interval time.Duration
+	Auth     authConfig
+	// Dashboards are the options of the dashboard provider of the repository.
+	Dashboards dashboardsConfig
+	// SecureJSONData are the secrets of the repository, encrypted with the secrets service.
+	SecureJSONData map[string][]byte
+}
+
+type authConfig struct {
+	Type     string
+	Username string
+	// KnownHosts are the known_hosts lines of the host keys of the SSH remote.
+	KnownHosts string
+}
+
+type dashboardsConfig struct {
+	OrgID                     int64
+	Folder                    string
+	FoldersFromFilesStructure bool
+	UpdateIntervalSeconds     int64
+}
+
+type configVersion struct {
+	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
+}
+
+type configV1 struct {
+	Repositories []*repositoryFromConfigV1 `json:"repositories" yaml:"repositories"`
+}
+
+type repositoryFromConfigV1 struct {
+	Name           values.StringValue     `json:"name" yaml:"name"`
+	URL            values.StringValue     `json:"url" yaml:"url"`
+	Branch         values.StringValue     `json:"branch" yaml:"branch"`
+	Path           values.StringValue     `json:"path" yaml:"path"`
+	Interval       values.StringValue     `json:"interval" yaml:"interval"`
+	Auth           authFromConfigV1       `json:"auth" yaml:"auth"`
+	Dashboards     dashboardsFromConfigV1 `json:"dashboards" yaml:"dashboards"`
+	SecureJSONData values.StringMapValue  `json:"secureJsonData" yaml:"secureJsonData"`
+}
+
+type authFromConfigV1 struct {
+	Type       values.StringValue `json:"type" yaml:"type"`
+	Username   values.StringValue `json:"username" yaml:"username"`
+	KnownHosts values.StringValue `json:"knownHosts" yaml:"knownHosts"`
+}
+
+type dashboardsFromConfigV1 struct {
+	OrgID                     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Folder                    values.StringValue `json:"folder" yaml:"folder"`
+	FoldersFromFilesStructure values.BoolValue   `json:"foldersFromFilesStructure" yaml:"foldersFromFilesStructure"`
+	UpdateIntervalSeconds     values.Int64Value  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
+}
+
+// RepositoryStatus is the status of the last sync of a Git repository.
+type RepositoryStatus struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+	// Commit is the SHA of the commit the provisioning files are read from.
+	Commit   string    `json:"commit,omitempty"`
+	LastSync time.Time `json:"lastSync"`
+	// LastChange is the time of the last sync which checked out a new commit.
+	LastChange time.Time `json:"lastChange"`
+	Error      string    `json:"error,omitempty"`
+}
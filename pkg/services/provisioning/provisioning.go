@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/correlations"
@@ -29,13 +33,22 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	prov_git "github.com/grafana/grafana/pkg/services/provisioning/git"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+const (
+	provisioningLeaseName          = "provisioning"
+	provisioningLeaseTTL           = time.Minute
+	provisioningLeaseRetryInterval = time.Second
+)
+
 func ProvideService(
 	ac accesscontrol.AccessControl,
 	cfg *setting.Cfg,
@@ -53,6 +66,8 @@ func ProvideService(
 	quotaService quota.Service,
 	secrectService secrets.Service,
 	orgService org.Service,
+	kvStore kvstore.KVStore,
+	serverLock *serverlock.ServerLockService,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -76,7 +91,16 @@ func ProvideService(
 		log:                          log.New("provisioning"),
 		orgService:                   orgService,
 		folderService:                folderService,
+		tracker:                      status.NewTracker(kvStore, cfg.ProvisioningChangeDetection),
+		serverLock:                   serverLock,
+	}
+
+	gitService, err := prov_git.NewService(context.Background(), cfg, secrectService)
+	if err != nil {
+		return nil, err
 	}
+	s.git = gitService
+	s.tracker.SetOrigin(gitService.Origin)
 
 	if err := s.setDashboardProvisioner(); err != nil {
 		return nil, err
@@ -87,7 +111,7 @@ func ProvideService(
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.git.DashboardProviders(), ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -104,22 +128,33 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetProvisioningStatus() []status.ProvisionerStatus
+	GetGitRepositoriesStatus() []prov_git.RepositoryStatus
+	SyncGitRepository(name string) error
+	HandleGitWebhook(ctx context.Context, name string, header http.Header, body []byte) (bool, error)
 }
 
 // Used for testing purposes
 func newProvisioningServiceImpl(
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory,
-	provisionDatasources func(context.Context, string, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service) error,
+	provisionDatasources func(context.Context, []utils.ConfigDir, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service, *status.Tracker, bool) error,
 	provisionPlugins func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error,
 	searchService searchV2.SearchService,
 ) (*ProvisioningServiceImpl, error) {
+	cfg := setting.NewCfg()
+	gitService, err := prov_git.NewService(context.Background(), cfg, nil)
+	if err != nil {
+		return nil, err
+	}
 	s := &ProvisioningServiceImpl{
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: newDashboardProvisioner,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
-		Cfg:                     setting.NewCfg(),
+		Cfg:                     cfg,
 		searchService:           searchService,
+		tracker:                 status.NewTracker(nil, true),
+		git:                     gitService,
 	}
 
 	if err := s.setDashboardProvisioner(); err != nil {
@@ -141,7 +176,7 @@ type ProvisioningServiceImpl struct {
 	pollingCtxCancel             context.CancelFunc
 	newDashboardProvisioner      dashboards.DashboardProvisionerFactory
 	dashboardProvisioner         dashboards.DashboardProvisioner
-	provisionDatasources         func(context.Context, string, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service) error
+	provisionDatasources         func(context.Context, []utils.ConfigDir, datasources.BaseDataSourceService, datasources.CorrelationsStore, org.Service, *status.Tracker, bool) error
 	provisionPlugins             func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
 	mutex                        sync.Mutex
@@ -154,6 +189,13 @@ type ProvisioningServiceImpl struct {
 	quotaService                 quota.Service
 	secretService                secrets.Service
 	folderService                folder.Service
+	tracker                      *status.Tracker
+	git                          *prov_git.Service
+	// resourcesMutex serializes the provisioning of the data sources and the alerting resources, which runs at
+	// startup, from the reload API and after a Git repository checked out a new commit.
+	resourcesMutex sync.Mutex
+	// serverLock serializes the provisioning across the Grafana instances, see withLease.
+	serverLock *serverlock.ServerLockService
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -192,6 +234,11 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		ps.searchService.TriggerReIndex()
 	}
 
+	// The repositories are synced in the background, so that an unreachable remote doesn't delay the startup. The
+	// data sources and alerting resources are provisioned from the previous checkouts until then, and the dashboards
+	// of the repositories are picked up by the polling of their providers.
+	go ps.git.Run(ctx, ps.provisionGitRepository)
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -215,8 +262,15 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) error {
+	ps.resourcesMutex.Lock()
+	defer ps.resourcesMutex.Unlock()
+
 	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
-	if err := ps.provisionDatasources(ctx, datasourcePath, ps.datasourceService, ps.correlationsService, ps.orgService); err != nil {
+	dirs := append([]utils.ConfigDir{{Path: datasourcePath}}, ps.git.Dirs("datasources")...)
+	err := ps.withLease(ctx, func(ctx context.Context) error {
+		return ps.provisionDatasources(ctx, dirs, ps.datasourceService, ps.correlationsService, ps.orgService, ps.tracker, ps.Cfg.ProvisioningPrune)
+	})
+	if err != nil {
 		err = fmt.Errorf("%v: %w", "Datasource provisioning error", err)
 		ps.log.Error("Failed to provision data sources", "error", err)
 		return err
@@ -226,7 +280,10 @@ func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) err
 
 func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	appPath := filepath.Join(ps.Cfg.ProvisioningPath, "plugins")
-	if err := ps.provisionPlugins(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService); err != nil {
+	err := ps.withLease(ctx, func(ctx context.Context) error {
+		return ps.provisionPlugins(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService)
+	})
+	if err != nil {
 		err = fmt.Errorf("%v: %w", "app provisioning error", err)
 		ps.log.Error("Failed to provision plugins", "error", err)
 		return err
@@ -239,9 +296,10 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 	defer ps.mutex.Unlock()
 
 	ps.cancelPolling()
-	ps.dashboardProvisioner.CleanUpOrphanedDashboards(ctx)
-
-	err := ps.dashboardProvisioner.Provision(ctx)
+	err := ps.withLease(ctx, func(ctx context.Context) error {
+		ps.dashboardProvisioner.CleanUpOrphanedDashboards(ctx)
+		return ps.dashboardProvisioner.Provision(ctx)
+	})
 	if err != nil {
 		// If we fail to provision with the new provisioner, the mutex will unlock and the polling will restart with the
 		// old provisioner as we did not switch them yet.
@@ -251,6 +309,9 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	ps.resourcesMutex.Lock()
+	defer ps.resourcesMutex.Unlock()
+
 	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
 	st := store.DBstore{
 		Cfg:              ps.Cfg.UnifiedAlerting,
@@ -290,7 +351,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 	mutetimingsService := provisioning.NewMuteTimingService(configStore, st, &st, ps.log, &st)
 	templateService := provisioning.NewTemplateService(configStore, st, &st, ps.log)
 	cfg := prov_alerting.ProvisionerConfig{
-		Path:                       alertingPath,
+		Dirs:                       append([]utils.ConfigDir{{Path: alertingPath}}, ps.git.Dirs("alerting")...),
 		RuleService:                *ruleService,
 		FolderService:              ps.folderService,
 		DashboardProvService:       ps.dashboardProvisioningService,
@@ -298,8 +359,39 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		Tracker:                    ps.tracker,
+		Prune:                      ps.Cfg.ProvisioningPrune,
+	}
+	return ps.withLease(ctx, func(ctx context.Context) error {
+		return ps.provisionAlerting(ctx, cfg)
+	})
+}
+
+// withLease executes fn holding the provisioning lease, so that the Grafana instances sharing the database don't
+// provision the same resources at the same time, for example when they start together. It waits while another
+// instance holds the lease, since each instance provisions its own files. The context of fn is cancelled if the
+// lease is lost.
+func (ps *ProvisioningServiceImpl) withLease(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ps.serverLock == nil {
+		return fn(ctx)
+	}
+	for {
+		var fnErr error
+		err := ps.serverLock.LeaseAndExecute(ctx, provisioningLeaseName, provisioningLeaseTTL, func(ctx context.Context, _ *serverlock.Lease) {
+			fnErr = fn(ctx)
+		})
+		var heldErr *serverlock.LeaseHeldError
+		if !errors.As(err, &heldErr) {
+			return errors.Join(err, fnErr)
+		}
+
+		ps.log.Debug("Waiting for the provisioning lease", "holder", heldErr.Holder)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(provisioningLeaseRetryInterval):
+		}
 	}
-	return ps.provisionAlerting(ctx, cfg)
 }
 
 func (ps *ProvisioningServiceImpl) GetDashboardProvisionerResolvedPath(name string) string {
@@ -310,6 +402,42 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+// GetProvisioningStatus returns the status of the last run of each provisioner.
+func (ps *ProvisioningServiceImpl) GetProvisioningStatus() []status.ProvisionerStatus {
+	result := ps.tracker.Status()
+	if ps.dashboardProvisioner != nil {
+		result = append(result, ps.dashboardProvisioner.Status()...)
+	}
+	return result
+}
+
+// GetGitRepositoriesStatus returns the status of the last sync of each Git repository.
+func (ps *ProvisioningServiceImpl) GetGitRepositoriesStatus() []prov_git.RepositoryStatus {
+	return ps.git.Status()
+}
+
+// SyncGitRepository syncs a Git repository in the background.
+func (ps *ProvisioningServiceImpl) SyncGitRepository(name string) error {
+	return ps.git.Trigger(name)
+}
+
+// HandleGitWebhook verifies a push webhook of a Git repository, and syncs the repository in the background.
+func (ps *ProvisioningServiceImpl) HandleGitWebhook(ctx context.Context, name string, header http.Header, body []byte) (bool, error) {
+	return ps.git.HandleWebhook(ctx, name, header, body)
+}
+
+// provisionGitRepository provisions the data sources and the alerting resources after a Git repository checked out a
+// new commit.
+func (ps *ProvisioningServiceImpl) provisionGitRepository(ctx context.Context, name string) {
+	ps.log.Info("Provisioning new commit of Git repository", "repository", name)
+	if err := ps.ProvisionDatasources(ctx); err != nil {
+		ps.log.Error("Failed to provision data sources of Git repository", "repository", name, "error", err)
+	}
+	if err := ps.ProvisionAlerting(ctx); err != nil {
+		ps.log.Error("Failed to provision alerting of Git repository", "repository", name, "error", err)
+	}
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
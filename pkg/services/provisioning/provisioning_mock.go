@@ -1,6 +1,12 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+	"net/http"
+
+	prov_git "github.com/grafana/grafana/pkg/services/provisioning/git"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -10,6 +16,10 @@ type Calls struct {
 	ProvisionAlerting                   []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	GetProvisioningStatus               []any
+	GetGitRepositoriesStatus            []any
+	SyncGitRepository                   []any
+	HandleGitWebhook                    []any
 	Run                                 []any
 }
 
@@ -21,6 +31,10 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	GetProvisioningStatusFunc               func() []status.ProvisionerStatus
+	GetGitRepositoriesStatusFunc            func() []prov_git.RepositoryStatus
+	SyncGitRepositoryFunc                   func(name string) error
+	HandleGitWebhookFunc                    func(ctx context.Context, name string, header http.Header, body []byte) (bool, error)
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -90,3 +104,35 @@ func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (mock *ProvisioningServiceMock) GetProvisioningStatus() []status.ProvisionerStatus {
+	mock.Calls.GetProvisioningStatus = append(mock.Calls.GetProvisioningStatus, nil)
+	if mock.GetProvisioningStatusFunc != nil {
+		return mock.GetProvisioningStatusFunc()
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) GetGitRepositoriesStatus() []prov_git.RepositoryStatus {
+	mock.Calls.GetGitRepositoriesStatus = append(mock.Calls.GetGitRepositoriesStatus, nil)
+	if mock.GetGitRepositoriesStatusFunc != nil {
+		return mock.GetGitRepositoriesStatusFunc()
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) SyncGitRepository(name string) error {
+	mock.Calls.SyncGitRepository = append(mock.Calls.SyncGitRepository, name)
+	if mock.SyncGitRepositoryFunc != nil {
+		return mock.SyncGitRepositoryFunc(name)
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) HandleGitWebhook(ctx context.Context, name string, header http.Header, body []byte) (bool, error) {
+	mock.Calls.HandleGitWebhook = append(mock.Calls.HandleGitWebhook, name)
+	if mock.HandleGitWebhookFunc != nil {
+		return mock.HandleGitWebhookFunc(ctx, name, header, body)
+	}
+	return false, nil
+}
@@ -0,0 +1,301 @@
+package status
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const kvNamespace = "provisioning"
+
+// FileState is the state of a provisioning file when it was last applied.
+type FileState struct {
+	// Hash is the hash of the content of the file. It is empty if the file failed to apply, so that it is applied
+	// again in the next run.
+	Hash string `json:"hash"`
+	// Origin is where the file came from when it was last applied, such as the commit of a Git repository.
+	Origin string `json:"origin,omitempty"`
+	// Resources are the resources provisioned by the file, which are deleted when the file is removed if pruning is
+	// enabled.
+	Resources json.RawMessage `json:"resources,omitempty"`
+}
+
+// FileStatus is the result of a provisioning file in the last run of a provisioner.
+type FileStatus struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	// Origin is where the file came from when it was last applied, such as the commit of a Git repository.
+	Origin string `json:"origin,omitempty"`
+	// Applied is true if the file changed and was applied, and false if it was skipped because it did not change.
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ProvisionerStatus is the status of the last run of a provisioner.
+type ProvisionerStatus struct {
+	Name       string       `json:"name"`
+	LastRun    time.Time    `json:"lastRun"`
+	DurationMs int64        `json:"durationMs"`
+	Error      string       `json:"error,omitempty"`
+	Files      []FileStatus `json:"files"`
+	// Pruned are the files removed from disk whose resources were deleted.
+	Pruned []string `json:"pruned,omitempty"`
+}
+
+// Hash returns the hash of the content of a provisioning file.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashConfig returns the hash of the config read from a provisioning file, once its values are interpolated, so
+// that the changes of the environment variables and the files it refers to are detected. It returns an empty hash
+// if the config can't be encoded, and the files with an empty hash are always applied.
+func HashConfig(cfg any) string {
+	content, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	return Hash(content)
+}
+
+// Tracker keeps the status of the last run of each provisioner, and the state of the files they applied. All the
+// files are applied in the first run of a provisioner after a restart, and only the files which changed in the next
+// runs. The state is stored in the database, so that the resources of the files removed while Grafana was stopped
+// can still be pruned.
+type Tracker struct {
+	kv              *kvstore.NamespacedKVStore
+	changeDetection bool
+	log             log.Logger
+	now             func() time.Time
+
+	mtx    sync.RWMutex
+	origin func(path string) string
+	status map[string]ProvisionerStatus
+	// applied are the provisioners which had a successful run since the start.
+	applied map[string]bool
+	// runs serialise the runs of each provisioner.
+	runs map[string]*sync.Mutex
+}
+
+// NewTracker returns a tracker that stores the state of the files in kv. If changeDetection is false, all the files
+// are applied in each run.
+func NewTracker(kv kvstore.KVStore, changeDetection bool) *Tracker {
+	t := &Tracker{
+		changeDetection: changeDetection,
+		log:             log.New("provisioning.status"),
+		now:             time.Now,
+		origin:          func(string) string { return "" },
+		status:          make(map[string]ProvisionerStatus),
+		applied:         make(map[string]bool),
+		runs:            make(map[string]*sync.Mutex),
+	}
+	if kv != nil {
+		t.kv = kvstore.WithNamespace(kv, 0, kvNamespace)
+	}
+	return t
+}
+
+// Start starts a run of the provisioner name, once its previous run is finished. If the state of the files of the
+// last run cannot be read, all the files are applied.
+func (t *Tracker) Start(ctx context.Context, name string) *Run {
+	t.mtx.Lock()
+	lock, ok := t.runs[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		t.runs[name] = lock
+	}
+	t.mtx.Unlock()
+	lock.Lock()
+
+	t.mtx.RLock()
+	applied := t.applied[name]
+	t.mtx.RUnlock()
+	r := &Run{
+		tracker:  t,
+		name:     name,
+		started:  t.now(),
+		unlock:   lock.Unlock,
+		detect:   t.changeDetection && applied,
+		previous: make(map[string]FileState),
+		states:   make(map[string]FileState),
+	}
+	if t.kv == nil {
+		return r
+	}
+	value, ok, err := t.kv.Get(ctx, name)
+	if err != nil {
+		t.log.Warn("Failed to read the state of the provisioning files, all files will be applied", "provisioner", name, "error", err)
+		return r
+	}
+	if ok {
+		if err := json.Unmarshal([]byte(value), &r.previous); err != nil {
+			t.log.Warn("Failed to parse the state of the provisioning files, all files will be applied", "provisioner", name, "error", err)
+			r.previous = make(map[string]FileState)
+		}
+	}
+	return r
+}
+
+// SetOrigin sets the function that returns where a file comes from when it is applied.
+func (t *Tracker) SetOrigin(origin func(path string) string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.origin = origin
+}
+
+func (t *Tracker) originOf(path string) string {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	return t.origin(path)
+}
+
+// Set sets the status of a provisioner that keeps track of the changes of its files itself.
+func (t *Tracker) Set(status ProvisionerStatus) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.status[status.Name] = status
+}
+
+// Status returns the status of the last run of each provisioner, sorted by name.
+func (t *Tracker) Status() []ProvisionerStatus {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	result := make([]ProvisionerStatus, 0, len(t.status))
+	for _, s := range t.status {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Run is a run of a provisioner. The provisioner records what it did with each file it read, and the run is
+// finished once all the files are recorded.
+type Run struct {
+	tracker *Tracker
+	name    string
+	started time.Time
+	unlock  func()
+	// detect is true if the files which did not change are skipped.
+	detect   bool
+	previous map[string]FileState
+	states   map[string]FileState
+	files    []FileStatus
+	pruned   []string
+}
+
+// Changed returns true if the file changed since it was last applied, if it is the first run of the provisioner
+// since the start, or if change detection is disabled.
+func (r *Run) Changed(path, hash string) bool {
+	if !r.detect || hash == "" {
+		return true
+	}
+	prev, ok := r.previous[path]
+	return !ok || prev.Hash != hash
+}
+
+// Applied records that the file was applied and provisioned the resources.
+func (r *Run) Applied(path, hash string, resources any) {
+	state := FileState{Hash: hash, Origin: r.tracker.originOf(path)}
+	if resources != nil {
+		raw, err := json.Marshal(resources)
+		if err != nil {
+			r.tracker.log.Warn("Failed to marshal the resources of the provisioning file, they will not be pruned", "provisioner", r.name, "path", path, "error", err)
+		}
+		state.Resources = raw
+	}
+	r.states[path] = state
+	r.files = append(r.files, FileStatus{Path: path, Hash: hash, Origin: state.Origin, Applied: true})
+}
+
+// Skipped records that the file was not applied because it did not change.
+func (r *Run) Skipped(path, hash string) {
+	r.states[path] = r.previous[path]
+	r.files = append(r.files, FileStatus{Path: path, Hash: hash, Origin: r.previous[path].Origin})
+}
+
+// Failed records that the file failed to apply. The file keeps the resources it provisioned before, and is applied
+// again in the next run.
+func (r *Run) Failed(path, hash string, err error) {
+	r.states[path] = FileState{Resources: r.previous[path].Resources}
+	r.files = append(r.files, FileStatus{Path: path, Hash: hash, Error: err.Error()})
+}
+
+// Keep keeps the state of the files whose path starts with prefix, because they cannot be read in this run.
+func (r *Run) Keep(prefix string) {
+	for path, state := range r.previous {
+		if _, ok := r.states[path]; !ok && strings.HasPrefix(path, prefix) {
+			r.states[path] = state
+		}
+	}
+}
+
+// Removed returns the state of the files which were applied before, and which were not recorded in this run.
+func (r *Run) Removed() map[string]FileState {
+	removed := make(map[string]FileState)
+	for path, state := range r.previous {
+		if _, ok := r.states[path]; !ok {
+			removed[path] = state
+		}
+	}
+	return removed
+}
+
+// Pruned records that the resources of a removed file were deleted.
+func (r *Run) Pruned(path string) {
+	delete(r.previous, path)
+	r.pruned = append(r.pruned, path)
+}
+
+// Finish stores the state of the files and the status of the run, and lets the next run start. If the run failed,
+// the files which were not recorded keep their state, so that their resources can still be pruned.
+func (r *Run) Finish(ctx context.Context, runErr error) {
+	defer r.unlock()
+	t := r.tracker
+	status := ProvisionerStatus{
+		Name:       r.name,
+		LastRun:    r.started,
+		DurationMs: t.now().Sub(r.started).Milliseconds(),
+		Files:      r.files,
+		Pruned:     r.pruned,
+	}
+	if status.Files == nil {
+		status.Files = []FileStatus{}
+	}
+	if runErr != nil {
+		status.Error = runErr.Error()
+		for path, state := range r.Removed() {
+			r.states[path] = state
+		}
+	}
+	t.Set(status)
+	if runErr == nil {
+		t.mtx.Lock()
+		t.applied[r.name] = true
+		t.mtx.Unlock()
+	}
+
+	if t.kv == nil {
+		return
+	}
+	value, err := json.Marshal(r.states)
+	if err == nil {
+		err = t.kv.Set(ctx, r.name, string(value))
+	}
+	if err != nil {
+		t.log.Warn("Failed to store the state of the provisioning files, all files will be applied in the next run", "provisioner", r.name, "error", err)
+		if err := t.kv.Del(ctx, r.name); err != nil {
+			t.log.Warn("Failed to delete the state of the provisioning files", "provisioner", r.name, "error", err)
+		}
+	}
+}
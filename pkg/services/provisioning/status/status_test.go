@@ -0,0 +1,112 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	kv := kvstore.NewFakeKVStore()
+	tracker := NewTracker(kv, true)
+
+	t.Run("all files are changed in the first run", func(t *testing.T) {
+		run := tracker.Start(ctx, "test")
+		assert.True(t, run.Changed("a.yaml", "1"))
+		run.Applied("a.yaml", "1", []string{"a"})
+		assert.True(t, run.Changed("b.yaml", "2"))
+		run.Applied("b.yaml", "2", []string{"b"})
+		run.Finish(ctx, nil)
+
+		status := tracker.Status()
+		require.Len(t, status, 1)
+		assert.Equal(t, "test", status[0].Name)
+		assert.Equal(t, []FileStatus{
+			{Path: "a.yaml", Hash: "1", Applied: true},
+			{Path: "b.yaml", Hash: "2", Applied: true},
+		}, status[0].Files)
+	})
+
+	t.Run("the files which did not change are skipped in the next runs", func(t *testing.T) {
+		run := tracker.Start(ctx, "test")
+		assert.False(t, run.Changed("a.yaml", "1"))
+		run.Skipped("a.yaml", "1")
+		assert.True(t, run.Changed("c.yaml", "3"))
+		run.Failed("c.yaml", "3", errors.New("boom"))
+		assert.True(t, run.Changed("d.yaml", ""), "files without hash are always applied")
+
+		removed := run.Removed()
+		require.Contains(t, removed, "b.yaml")
+		assert.JSONEq(t, `["b"]`, string(removed["b.yaml"].Resources))
+		run.Pruned("b.yaml")
+		run.Finish(ctx, nil)
+
+		status := tracker.Status()[0]
+		assert.Equal(t, []string{"b.yaml"}, status.Pruned)
+		assert.Equal(t, "boom", status.Files[1].Error)
+	})
+
+	t.Run("failed files are applied again", func(t *testing.T) {
+		run := tracker.Start(ctx, "test")
+		defer run.Finish(ctx, errors.New("interrupted"))
+		assert.False(t, run.Changed("a.yaml", "1"))
+		assert.True(t, run.Changed("c.yaml", "3"))
+		assert.NotContains(t, run.Removed(), "b.yaml", "pruned files are forgotten")
+	})
+
+	t.Run("all files are applied after a restart, and the removed files can still be pruned", func(t *testing.T) {
+		restarted := NewTracker(kv, true)
+		run := restarted.Start(ctx, "test")
+		assert.True(t, run.Changed("a.yaml", "1"))
+		assert.Contains(t, run.Removed(), "a.yaml")
+		run.Finish(ctx, errors.New("failed to read the files"))
+		assert.Equal(t, "failed to read the files", restarted.Status()[0].Error)
+
+		run = restarted.Start(ctx, "test")
+		assert.True(t, run.Changed("a.yaml", "1"), "all files are applied until a run succeeds")
+		assert.Contains(t, run.Removed(), "a.yaml", "the files not recorded by a failed run keep their state")
+	})
+
+	t.Run("all files are changed without change detection", func(t *testing.T) {
+		tracker := NewTracker(kv, false)
+		tracker.Start(ctx, "test").Finish(ctx, nil)
+		assert.True(t, tracker.Start(ctx, "test").Changed("a.yaml", "1"))
+	})
+
+	t.Run("the runs of a provisioner are serialised", func(t *testing.T) {
+		tracker := NewTracker(nil, true)
+		run := tracker.Start(ctx, "test")
+
+		started := make(chan struct{})
+		go func() {
+			tracker.Start(ctx, "test").Finish(ctx, nil)
+			close(started)
+		}()
+		other := tracker.Start(ctx, "other")
+		other.Finish(ctx, nil)
+
+		select {
+		case <-started:
+			t.Fatal("the second run started before the first one finished")
+		case <-time.After(50 * time.Millisecond):
+		}
+		run.Finish(ctx, nil)
+		<-started
+	})
+}
+
+func TestHashConfig(t *testing.T) {
+	type config struct {
+		URL string
+	}
+	assert.Equal(t, HashConfig(config{URL: "http://a"}), HashConfig(config{URL: "http://a"}))
+	assert.NotEqual(t, HashConfig(config{URL: "http://a"}), HashConfig(config{URL: "http://b"}))
+	assert.Empty(t, HashConfig(func() {}))
+}
@@ -24,3 +24,16 @@ func CheckOrgExists(ctx context.Context, orgService org.Service, orgID int64) er
 	}
 	return nil
 }
+
+// ConfigDir is a directory of provisioning files. The names of its files are prefixed
+// with Prefix, so that the files of different directories have different names.
+type ConfigDir struct {
+	Path   string
+	Prefix string
+	// Unavailable is true if the directory cannot be read, such as the directory of a Git
+	// repository which was never cloned. The files provisioned from it are kept as they are.
+	Unavailable bool
+	// Literal is true if the files are read without the interpolation of the environment variables and the
+	// expanders, such as the files of Git repositories, which are not as trusted as the Grafana configuration.
+	Literal bool
+}
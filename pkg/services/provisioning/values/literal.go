@@ -0,0 +1,174 @@
+package values
+
+import (
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalLiteral unmarshals a YAML document like yaml.Unmarshal, but without interpolation: the environment
+// variables and the expanders, such as $__env{} and $__file{}, are kept as they are written and never evaluated.
+// It is used for the files which are not as trusted as the Grafana configuration, such as the files of Git
+// repositories.
+func UnmarshalLiteral(data []byte, v any) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	if node.Kind == 0 {
+		// empty document, yaml.Unmarshal leaves v as it is
+		return nil
+	}
+
+	// '$$' is interpolated as a literal '$', so escaping every '$' of the values turns off the interpolation of
+	// the value types. The other strings are unescaped once decoded.
+	escapeNode(&node, map[*yaml.Node]bool{})
+	if err := node.Decode(v); err != nil {
+		return err
+	}
+	unescapeValue(reflect.ValueOf(v))
+	return nil
+}
+
+func escapeNode(node *yaml.Node, visited map[*yaml.Node]bool) {
+	if node == nil || visited[node] {
+		return
+	}
+	visited[node] = true
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		node.Value = strings.ReplaceAll(node.Value, "$", "$$")
+	case yaml.MappingNode:
+		// the keys are never interpolated
+		for i := 1; i < len(node.Content); i += 2 {
+			escapeNode(node.Content[i], visited)
+		}
+	case yaml.AliasNode:
+		escapeNode(node.Alias, visited)
+	default:
+		for _, child := range node.Content {
+			escapeNode(child, visited)
+		}
+	}
+}
+
+func unescape(s string) string {
+	return strings.ReplaceAll(s, "$$", "$")
+}
+
+// literal is implemented by the value types, whose raw value is unescaped while the value itself was
+// interpolated from the escaped value.
+type literal interface {
+	unescapeRaw()
+}
+
+func unescapeValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			unescapeValue(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.CanSet() {
+			switch i := v.Interface().(type) {
+			case string, []any, map[string]any:
+				v.Set(reflect.ValueOf(unescapeAny(i)))
+				return
+			}
+		}
+		unescapeValue(v.Elem())
+	case reflect.Struct:
+		if v.CanAddr() {
+			if l, ok := v.Addr().Interface().(literal); ok {
+				l.unescapeRaw()
+				return
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				unescapeValue(v.Field(i))
+			}
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(unescape(v.String()))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			unescapeValue(v.Index(i))
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values are not addressable, they are unescaped in a copy
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			unescapeValue(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
+
+// unescapeAny returns a copy of a value decoded in an any, with its strings unescaped.
+func unescapeAny(i any) any {
+	switch value := i.(type) {
+	case string:
+		return unescape(value)
+	case []any:
+		result := make([]any, 0, len(value))
+		for _, v := range value {
+			result = append(result, unescapeAny(v))
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for k, v := range value {
+			result[k] = unescapeAny(v)
+		}
+		return result
+	default:
+		return i
+	}
+}
+
+func (val *IntValue) unescapeRaw() {
+	val.Raw = unescape(val.Raw)
+}
+
+func (val *Int64Value) unescapeRaw() {
+	val.Raw = unescape(val.Raw)
+}
+
+func (val *StringValue) unescapeRaw() {
+	val.Raw = unescape(val.Raw)
+}
+
+func (val *BoolValue) unescapeRaw() {
+	val.Raw = unescape(val.Raw)
+}
+
+func (val *JSONValue) unescapeRaw() {
+	if val.Raw != nil {
+		val.Raw = unescapeAny(val.Raw).(map[string]any)
+	}
+}
+
+func (val *StringMapValue) unescapeRaw() {
+	for k, v := range val.Raw {
+		val.Raw[k] = unescape(v)
+	}
+}
+
+func (val *JSONSliceValue) unescapeRaw() {
+	for i, v := range val.Raw {
+		val.Raw[i] = unescapeAny(v).(map[string]any)
+	}
+}
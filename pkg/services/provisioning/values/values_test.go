@@ -351,3 +351,56 @@ func (f failExpander) SetupExpander(file *ini.File) error {
 func (f failExpander) Expand(s string) (string, error) {
 	return "", errExpand
 }
+
+func TestUnmarshalLiteral(t *testing.T) {
+	t.Setenv("STRING", "test")
+	file, err := os.CreateTemp(t.TempDir(), "secret")
+	require.NoError(t, err)
+	_, err = file.WriteString("secret")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	type Data struct {
+		String    StringValue    `yaml:"string"`
+		File      StringValue    `yaml:"file"`
+		Escaped   StringValue    `yaml:"escaped"`
+		JSON      JSONValue      `yaml:"json"`
+		StringMap StringMapValue `yaml:"stringMap"`
+		Plain     string         `yaml:"plain"`
+		PlainMap  map[string]any `yaml:"plainMap"`
+	}
+	doc := fmt.Sprintf(`
+string: $STRING
+file: $__file{%s}
+escaped: a$$b
+json:
+  password: ${STRING}
+  nested:
+    - $__env{STRING}
+stringMap:
+  summary: '{{ $labels.instance }}'
+plain: '{{ $value }}'
+plainMap:
+  key: $STRING
+`, file.Name())
+
+	d := &Data{}
+	require.NoError(t, UnmarshalLiteral([]byte(doc), d))
+
+	require.Equal(t, "$STRING", d.String.Value())
+	require.Equal(t, "$STRING", d.String.Raw)
+	require.Equal(t, "$__file{"+file.Name()+"}", d.File.Value())
+	require.Equal(t, "a$$b", d.Escaped.Value())
+	require.Equal(t, map[string]any{"password": "${STRING}", "nested": []any{"$__env{STRING}"}}, d.JSON.Value())
+	require.Equal(t, d.JSON.Value(), d.JSON.Raw)
+	require.Equal(t, map[string]string{"summary": "{{ $labels.instance }}"}, d.StringMap.Value())
+	require.Equal(t, d.StringMap.Value(), d.StringMap.Raw)
+	require.Equal(t, "{{ $value }}", d.Plain)
+	require.Equal(t, map[string]any{"key": "$STRING"}, d.PlainMap)
+
+	t.Run("Should leave the value as it is for an empty document", func(t *testing.T) {
+		d := &Data{Plain: "value"}
+		require.NoError(t, UnmarshalLiteral([]byte(""), d))
+		require.Equal(t, "value", d.Plain)
+	})
+}
@@ -14,8 +14,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
-	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
@@ -123,7 +123,7 @@ func buildQueryDataService(t *testing.T, cs datasources.CacheService, fpc *fakeP
 
 	// default cache service
 	if cs == nil {
-		cs = datasourceService.ProvideCacheService(localcache.ProvideService(), store, guardian.ProvideGuardian())
+		cs = datasourceService.ProvideCacheService(namedcache.FakeProvider{}, store, guardian.ProvideGuardian(), fakeSecrets.NewFakeSecretsService())
 	}
 
 	// default fakePluginClient
@@ -142,7 +142,7 @@ func buildQueryDataService(t *testing.T, cs datasources.CacheService, fpc *fakeP
 
 	ds := &fakeDatasources.FakeDataSourceService{}
 	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(),
-		localcache.ProvideService(), &pluginstore.FakePluginStore{
+		namedcache.FakeProvider{}, &pluginstore.FakePluginStore{
 			PluginList: []pluginstore.Plugin{
 				{
 					JSONData: plugins.JSONData{
@@ -160,6 +160,7 @@ func buildQueryDataService(t *testing.T, cs datasources.CacheService, fpc *fakeP
 		&fakePluginRequestValidator{},
 		fpc,
 		pCtxProvider,
+		nil,
 	)
 }
 
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -28,16 +29,31 @@ const (
 	QueryFailure                                  = "failure"
 	EmailShareType                      ShareType = "email"
 	PublicShareType                     ShareType = "public"
+	LinkShareType                       ShareType = "link" // only shared through share links, see WithShareLinkAccess
 	FeaturePublicDashboardsEmailSharing           = "publicDashboardsEmailSharing"
 )
 
 var (
 	QueryResultStatuses = []string{QuerySuccess, QueryFailure}
-	ValidShareTypes     = []ShareType{EmailShareType, PublicShareType}
+	ValidShareTypes     = []ShareType{EmailShareType, PublicShareType, LinkShareType}
 )
 
 type ShareType string
 
+type shareLinkAccessKey struct{}
+
+// WithShareLinkAccess returns a context that can access the public dashboards shared only through share links. It
+// is set by the share link API once the share link of the request is validated.
+func WithShareLinkAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shareLinkAccessKey{}, true)
+}
+
+// HasShareLinkAccess returns true if the context can access the public dashboards shared only through share links.
+func HasShareLinkAccess(ctx context.Context) bool {
+	ok, _ := ctx.Value(shareLinkAccessKey{}).(bool)
+	return ok
+}
+
 type PublicDashboard struct {
 	Uid          string    `json:"uid" xorm:"pk uid"`
 	DashboardUid string    `json:"dashboardUid" xorm:"dashboard_uid"`
@@ -181,6 +181,10 @@ func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAc
 		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
 
+	if pubdash.Share == LinkShareType && !HasShareLinkAccess(ctx) {
+		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard is only shared with share links accessToken: %s", accessToken)
+	}
+
 	return pubdash, dash, err
 }
 
@@ -551,6 +551,17 @@ func TestGetEnabledPublicDashboard(t *testing.T) {
 			ErrResp:  ErrPublicDashboardNotFound,
 			DashResp: nil,
 		},
+		{
+			Name:        "returns ErrPublicDashboardNotFound when only shared with share links",
+			AccessToken: "abc123",
+			StoreResp: &storeResp{
+				pd:  &PublicDashboard{AccessToken: "abcdToken", IsEnabled: true, Share: LinkShareType},
+				d:   &dashboards.Dashboard{UID: "mydashboard", Data: dashboardData},
+				err: nil,
+			},
+			ErrResp:  ErrPublicDashboardNotFound,
+			DashResp: nil,
+		},
 	}
 
 	for _, test := range testCases {
@@ -0,0 +1,154 @@
+package sharelinks
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// SessionHeader is the header of the session token of a share link with an email address.
+const SessionHeader = "X-Grafana-Share-Link-Session"
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":dashboardUid"))
+
+	routeRegister.Group("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/share-links", func(route routing.RouteRegister) {
+		route.Get("/", authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, uidScope)), routing.Wrap(s.listHandler))
+		route.Post("/", authorize(ac.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)), routing.Wrap(s.createHandler))
+		route.Delete("/:linkUid", authorize(ac.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)), routing.Wrap(s.revokeHandler))
+	})
+
+	// anonymous endpoints, the token of the link is the credential
+	routeRegister.Group("/api/public/share-links/:token", func(route routing.RouteRegister) {
+		route.Get("/", routing.Wrap(s.statusHandler))
+		route.Post("/code", routing.Wrap(s.sendCodeHandler))
+		route.Post("/verify", routing.Wrap(s.verifyHandler))
+		route.Get("/dashboard", routing.Wrap(s.viewDashboardHandler))
+		route.Get("/annotations", routing.Wrap(s.annotationsHandler))
+		route.Post("/panels/:panelId/query", routing.Wrap(s.queryHandler))
+	})
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	links, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID(), params[":dashboardUid"], params[":uid"])
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, links)
+}
+
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateShareLinkCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(ErrBadRequest.Errorf("bad request data: %v", err))
+	}
+	params := web.Params(c.Req)
+	link, err := s.Create(c.Req.Context(), c.SignedInUser, params[":dashboardUid"], params[":uid"], cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, link)
+}
+
+func (s *Service) revokeHandler(c *contextmodel.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	if err := s.Revoke(c.Req.Context(), c.SignedInUser.GetOrgID(), params[":dashboardUid"], params[":uid"], params[":linkUid"]); err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Share link revoked")
+}
+
+func (s *Service) statusHandler(c *contextmodel.ReqContext) response.Response {
+	status, err := s.Status(c.Req.Context(), web.Params(c.Req)[":token"])
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+func (s *Service) sendCodeHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.SendCode(c.Req.Context(), web.Params(c.Req)[":token"]); err != nil {
+		return response.Err(err)
+	}
+	return response.Respond(http.StatusAccepted, nil)
+}
+
+func (s *Service) verifyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := VerifyCodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(ErrBadRequest.Errorf("bad request data: %v", err))
+	}
+	session, err := s.Verify(c.Req.Context(), web.Params(c.Req)[":token"], cmd.Code)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, session)
+}
+
+// viewDashboardHandler returns the public dashboard of a share link, and counts the usage of the link.
+func (s *Service) viewDashboardHandler(c *contextmodel.ReqContext) response.Response {
+	token := web.Params(c.Req)[":token"]
+	ctx, accessToken, err := s.Authorize(c.Req.Context(), token, c.Req.Header.Get(SessionHeader))
+	if err != nil {
+		return response.Err(err)
+	}
+	dto, err := s.publicDashboardService.GetPublicDashboardForView(ctx, accessToken)
+	if err != nil {
+		return response.Err(err)
+	}
+	if err := s.CountUsage(ctx, token); err != nil {
+		s.log.Warn("Failed to count share link usage", "error", err)
+	}
+	return response.JSON(http.StatusOK, dto)
+}
+
+func (s *Service) annotationsHandler(c *contextmodel.ReqContext) response.Response {
+	ctx, accessToken, err := s.Authorize(c.Req.Context(), web.Params(c.Req)[":token"], c.Req.Header.Get(SessionHeader))
+	if err != nil {
+		return response.Err(err)
+	}
+	annotations, err := s.publicDashboardService.FindAnnotations(ctx, AnnotationsQueryDTO{
+		From: c.QueryInt64("from"),
+		To:   c.QueryInt64("to"),
+	}, accessToken)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, annotations)
+}
+
+func (s *Service) queryHandler(c *contextmodel.ReqContext) response.Response {
+	ctx, accessToken, err := s.Authorize(c.Req.Context(), web.Params(c.Req)[":token"], c.Req.Header.Get(SessionHeader))
+	if err != nil {
+		return response.Err(err)
+	}
+	panelID, err := strconv.ParseInt(web.Params(c.Req)[":panelId"], 10, 64)
+	if err != nil {
+		return response.Err(ErrInvalidPanelId.Errorf("error parsing panelId %v", err))
+	}
+	reqDTO := PublicDashboardQueryDTO{}
+	if err := web.Bind(c.Req, &reqDTO); err != nil {
+		return response.Err(ErrBadRequest.Errorf("error parsing request: %v", err))
+	}
+	resp, err := s.publicDashboardService.GetQueryDataResponse(ctx, c.SkipDSCache, reqDTO, panelID, accessToken)
+	if err != nil {
+		return response.Err(err)
+	}
+	statusCode := http.StatusOK
+	for _, res := range resp.Responses {
+		if res.Error != nil {
+			statusCode = http.StatusBadRequest
+			break
+		}
+	}
+	return response.JSONStreaming(statusCode, resp)
+}
@@ -0,0 +1,105 @@
+package sharelinks
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrShareLinkNotFound  = errutil.NotFound("publicdashboards.shareLinkNotFound", errutil.WithPublicMessage("Share link not found"))
+	ErrShareLinkExpired   = errutil.NotFound("publicdashboards.shareLinkExpired", errutil.WithPublicMessage("Share link expired"))
+	ErrShareLinkRevoked   = errutil.NotFound("publicdashboards.shareLinkRevoked", errutil.WithPublicMessage("Share link revoked"))
+	ErrInvalidShareLink   = errutil.BadRequest("publicdashboards.invalidShareLink")
+	ErrNoEmail            = errutil.BadRequest("publicdashboards.shareLinkNoEmail", errutil.WithPublicMessage("Share link doesn't require email verification"))
+	ErrInvalidCode        = errutil.Unauthorized("publicdashboards.shareLinkInvalidCode", errutil.WithPublicMessage("Invalid or expired verification code"))
+	ErrVerificationNeeded = errutil.Unauthorized("publicdashboards.shareLinkVerificationRequired", errutil.WithPublicMessage("Email verification required"))
+	ErrCodeRecentlySent   = errutil.TooManyRequests("publicdashboards.shareLinkCodeRecentlySent", errutil.WithPublicMessage("A verification code was sent recently, try again later"))
+	ErrTooManyCodes       = errutil.TooManyRequests("publicdashboards.shareLinkTooManyCodes", errutil.WithPublicMessage("Too many verification codes were requested, try again later"))
+	ErrTooManyAttempts    = errutil.TooManyRequests("publicdashboards.shareLinkTooManyAttempts", errutil.WithPublicMessage("Too many attempts, try again later"))
+)
+
+// ShareLink is a link to a public dashboard that expires, and that can be limited to an email address verified with
+// a one-time code. Only the hash of its token is stored: the token is returned once, when the link is created.
+type ShareLink struct {
+	ID                 int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID                string `xorm:"uid" json:"uid"`
+	OrgID              int64  `xorm:"org_id" json:"-"`
+	PublicDashboardUID string `xorm:"public_dashboard_uid" json:"publicDashboardUid"`
+	TokenHash          string `xorm:"token_hash" json:"-"`
+	// Email is the email address that must be verified to open the link, anyone with the link can open it if empty.
+	Email     string `xorm:"email" json:"email,omitempty"`
+	ExpiresAt int64  `xorm:"expires_at" json:"-"`
+	RevokedAt int64  `xorm:"revoked_at" json:"-"`
+	// UsageCount is the number of times the dashboard was opened with the link.
+	UsageCount    int64  `xorm:"usage_count" json:"usageCount"`
+	LastUsedAt    int64  `xorm:"last_used_at" json:"-"`
+	CodeHash      string `xorm:"code_hash" json:"-"`
+	CodeExpiresAt int64  `xorm:"code_expires_at" json:"-"`
+	// CodeAttempts is the number of attempts to verify a code since CodeWindowStart, it isn't reset by new codes.
+	CodeAttempts int   `xorm:"code_attempts" json:"-"`
+	CodeSentAt   int64 `xorm:"code_sent_at" json:"-"`
+	// CodesSent is the number of codes sent since CodeWindowStart.
+	CodesSent       int   `xorm:"codes_sent" json:"-"`
+	CodeWindowStart int64 `xorm:"code_window_start" json:"-"`
+	CreatedBy       int64 `xorm:"created_by" json:"createdBy"`
+	Created         int64 `xorm:"created" json:"-"`
+}
+
+func (ShareLink) TableName() string {
+	return "dashboard_public_share_link"
+}
+
+// DTO returns the API representation of the link.
+func (l *ShareLink) DTO() *ShareLinkDTO {
+	dto := &ShareLinkDTO{
+		ShareLink: l,
+		ExpiresAt: time.Unix(l.ExpiresAt, 0).UTC(),
+		Created:   time.Unix(l.Created, 0).UTC(),
+	}
+	if l.RevokedAt != 0 {
+		revokedAt := time.Unix(l.RevokedAt, 0).UTC()
+		dto.RevokedAt = &revokedAt
+	}
+	if l.LastUsedAt != 0 {
+		lastUsedAt := time.Unix(l.LastUsedAt, 0).UTC()
+		dto.LastUsedAt = &lastUsedAt
+	}
+	return dto
+}
+
+type ShareLinkDTO struct {
+	*ShareLink
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Created    time.Time  `json:"created"`
+	// Token is only set when the link is created.
+	Token string `json:"token,omitempty"`
+}
+
+// CreateShareLinkCommand creates a share link to a public dashboard.
+type CreateShareLinkCommand struct {
+	// Email limits the link to an email address, verified with a one-time code sent to it.
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// VerifyCodeCommand verifies the one-time code sent to the email address of a share link.
+type VerifyCodeCommand struct {
+	Code string `json:"code"`
+}
+
+// LinkStatus is what the viewer of a share link needs to know to open it.
+type LinkStatus struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	// VerificationRequired is true if the viewer must verify the email address of the link before opening it.
+	VerificationRequired bool `json:"verificationRequired"`
+}
+
+// Session is returned once the email address of a share link is verified. Its token must be sent in the
+// X-Grafana-Share-Link-Session header to open the link.
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
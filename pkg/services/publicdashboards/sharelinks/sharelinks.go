@@ -0,0 +1,388 @@
+// Package sharelinks shares public dashboards with links that expire, can be revoked, and can be limited to an
+// email address verified with a one-time code. Public dashboards with the "link" share type can only be opened
+// through their share links.
+package sharelinks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	maxLinkLifetime    = 365 * 24 * time.Hour
+	codeLifetime       = 10 * time.Minute
+	codeResendInterval = time.Minute
+	// codeWindow is the period in which at most maxCodesPerWindow codes are sent and maxCodeAttempts attempts
+	// are made to verify them, so that the codes of a link can't be guessed by requesting new ones.
+	codeWindow        = time.Hour
+	maxCodesPerWindow = 5
+	maxCodeAttempts   = 5
+	sessionLifetime   = 24 * time.Hour
+
+	codeEmailTemplate = "public_dashboard_share_link_code"
+)
+
+// Service manages the share links of public dashboards.
+type Service struct {
+	store                  db.DB
+	publicDashboardService publicdashboards.Service
+	mailer                 notifications.EmailSender
+	accessControl          ac.AccessControl
+	secretKey              string
+	log                    log.Logger
+	now                    func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, sqlStore db.DB,
+	publicDashboardService publicdashboards.Service, mailer notifications.EmailSender, accessControl ac.AccessControl,
+	routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		store:                  sqlStore,
+		publicDashboardService: publicDashboardService,
+		mailer:                 mailer,
+		accessControl:          accessControl,
+		secretKey:              cfg.SecretKey,
+		log:                    log.New("publicdashboards.sharelinks"),
+		now:                    time.Now,
+	}
+	if features.IsEnabledGlobally(featuremgmt.FlagPublicDashboards) && cfg.PublicDashboardsEnabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+// Create creates a share link to the public dashboard of a dashboard. The returned link is the only one with the
+// token of the link.
+func (s *Service) Create(ctx context.Context, user identity.Requester, dashboardUID, publicDashboardUID string, cmd CreateShareLinkCommand) (*ShareLinkDTO, error) {
+	pubdash, err := s.findPublicDashboard(ctx, user.GetOrgID(), dashboardUID, publicDashboardUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !cmd.ExpiresAt.After(now) {
+		return nil, ErrInvalidShareLink.Errorf("the expiration of the share link must be in the future")
+	}
+	if cmd.ExpiresAt.Sub(now) > maxLinkLifetime {
+		return nil, ErrInvalidShareLink.Errorf("the share link can't expire in more than %d days", int(maxLinkLifetime.Hours()/24))
+	}
+	email := strings.TrimSpace(cmd.Email)
+	if email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil {
+			return nil, ErrInvalidShareLink.Errorf("invalid email address %q", cmd.Email)
+		}
+		email = address.Address
+	}
+
+	token, err := service.GenerateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	link := &ShareLink{
+		UID:                util.GenerateShortUID(),
+		OrgID:              user.GetOrgID(),
+		PublicDashboardUID: pubdash.Uid,
+		TokenHash:          hashToken(token),
+		Email:              email,
+		ExpiresAt:          cmd.ExpiresAt.Unix(),
+		Created:            now.Unix(),
+	}
+	if id, err := identity.UserIdentifier(user.GetID()); err == nil {
+		link.CreatedBy = id
+	}
+	if err := s.insert(ctx, link); err != nil {
+		return nil, err
+	}
+	s.log.Info("Created public dashboard share link", "orgId", link.OrgID, "publicDashboardUid", pubdash.Uid, "uid", link.UID, "expiresAt", cmd.ExpiresAt)
+
+	dto := link.DTO()
+	dto.Token = token
+	return dto, nil
+}
+
+// List returns the share links of the public dashboard of a dashboard, including the expired and revoked ones.
+func (s *Service) List(ctx context.Context, orgID int64, dashboardUID, publicDashboardUID string) ([]*ShareLinkDTO, error) {
+	if _, err := s.findPublicDashboard(ctx, orgID, dashboardUID, publicDashboardUID); err != nil {
+		return nil, err
+	}
+	links, err := s.list(ctx, orgID, publicDashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ShareLinkDTO, 0, len(links))
+	for _, link := range links {
+		result = append(result, link.DTO())
+	}
+	return result, nil
+}
+
+// Revoke revokes a share link. Revoked links are kept with their usage count.
+func (s *Service) Revoke(ctx context.Context, orgID int64, dashboardUID, publicDashboardUID, uid string) error {
+	if _, err := s.findPublicDashboard(ctx, orgID, dashboardUID, publicDashboardUID); err != nil {
+		return err
+	}
+	link, err := s.getByUID(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if link == nil || link.PublicDashboardUID != publicDashboardUID {
+		return ErrShareLinkNotFound.Errorf("share link %q not found", uid)
+	}
+	if link.RevokedAt != 0 {
+		return nil
+	}
+	link.RevokedAt = s.now().Unix()
+	if err := s.update(ctx, link, "revoked_at"); err != nil {
+		return err
+	}
+	s.log.Info("Revoked public dashboard share link", "orgId", orgID, "publicDashboardUid", publicDashboardUID, "uid", uid)
+	return nil
+}
+
+// Status returns the status of the share link of a token.
+func (s *Service) Status(ctx context.Context, token string) (*LinkStatus, error) {
+	link, err := s.getValid(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &LinkStatus{
+		ExpiresAt:            time.Unix(link.ExpiresAt, 0).UTC(),
+		VerificationRequired: link.Email != "",
+	}, nil
+}
+
+// SendCode sends a one-time code to the email address of the share link of a token. A new code replaces the
+// previous one, but the failed attempts to verify the previous codes still count until the code window ends.
+func (s *Service) SendCode(ctx context.Context, token string) error {
+	link, err := s.getValid(ctx, token)
+	if err != nil {
+		return err
+	}
+	if link.Email == "" {
+		return ErrNoEmail.Errorf("share link %q has no email address", link.UID)
+	}
+	now := s.now()
+	if link.CodeSentAt != 0 && now.Sub(time.Unix(link.CodeSentAt, 0)) < codeResendInterval {
+		return ErrCodeRecentlySent.Errorf("a code was sent for share link %q less than %s ago", link.UID, codeResendInterval)
+	}
+	newWindow := now.Sub(time.Unix(link.CodeWindowStart, 0)) >= codeWindow
+	if !newWindow && link.CodesSent >= maxCodesPerWindow {
+		return ErrTooManyCodes.Errorf("%d codes were sent for share link %q in less than %s", link.CodesSent, link.UID, codeWindow)
+	}
+
+	title, err := s.dashboardTitle(ctx, link)
+	if err != nil {
+		return err
+	}
+	code, err := generateCode()
+	if err != nil {
+		return err
+	}
+	issued, err := s.issueCode(ctx, link, hashCode(link.UID, code), now, newWindow)
+	if err != nil {
+		return err
+	}
+	if !issued {
+		return ErrCodeRecentlySent.Errorf("a code was sent concurrently for share link %q", link.UID)
+	}
+	return s.mailer.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       []string{link.Email},
+			Template: codeEmailTemplate,
+			Data: map[string]any{
+				"DashboardTitle":      title,
+				"Code":                code,
+				"CodeLifetimeMinutes": int(codeLifetime.Minutes()),
+			},
+		},
+	})
+}
+
+// Verify verifies the one-time code of the share link of a token, and returns a session to open the link.
+func (s *Service) Verify(ctx context.Context, token string, code string) (*Session, error) {
+	link, err := s.getValid(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if link.Email == "" {
+		return nil, ErrNoEmail.Errorf("share link %q has no email address", link.UID)
+	}
+	now := s.now()
+	if link.CodeHash == "" || now.Unix() > link.CodeExpiresAt {
+		return nil, ErrInvalidCode.Errorf("share link %q has no valid code", link.UID)
+	}
+	// the attempt is counted before the code is compared, so that concurrent attempts can't exceed the limit
+	counted, err := s.countAttempt(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	if !counted {
+		return nil, ErrTooManyAttempts.Errorf("too many attempts for share link %q", link.UID)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCode(link.UID, strings.TrimSpace(code))), []byte(link.CodeHash)) != 1 {
+		return nil, ErrInvalidCode.Errorf("invalid code for share link %q", link.UID)
+	}
+
+	// the code can only be used once
+	consumed, err := s.consumeCode(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidCode.Errorf("share link %q has no valid code", link.UID)
+	}
+	expiresAt := now.Add(sessionLifetime)
+	if linkExpiresAt := time.Unix(link.ExpiresAt, 0); linkExpiresAt.Before(expiresAt) {
+		expiresAt = linkExpiresAt
+	}
+	return &Session{Token: s.signSession(link, expiresAt), ExpiresAt: expiresAt.UTC()}, nil
+}
+
+// Authorize returns the access token of the public dashboard of the share link of a token, and a context to access
+// it with. The session must be the token of a session returned by Verify if the link has an email address.
+func (s *Service) Authorize(ctx context.Context, token string, session string) (context.Context, string, error) {
+	link, err := s.getValid(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	if link.Email != "" && !s.validSession(link, session) {
+		return nil, "", ErrVerificationNeeded.Errorf("share link %q requires a valid session", link.UID)
+	}
+	pubdash, err := s.publicDashboardService.Find(ctx, link.PublicDashboardUID)
+	if err != nil {
+		return nil, "", err
+	}
+	if pubdash == nil {
+		return nil, "", ErrShareLinkNotFound.Errorf("public dashboard %q of share link %q not found", link.PublicDashboardUID, link.UID)
+	}
+	return WithShareLinkAccess(ctx), pubdash.AccessToken, nil
+}
+
+// CountUsage increments the usage count of the share link of a token.
+func (s *Service) CountUsage(ctx context.Context, token string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE dashboard_public_share_link SET usage_count = usage_count + 1, last_used_at = ? WHERE token_hash = ?",
+			s.now().Unix(), hashToken(token))
+		return err
+	})
+}
+
+// findPublicDashboard returns the public dashboard of a dashboard, if it has the given UID.
+func (s *Service) findPublicDashboard(ctx context.Context, orgID int64, dashboardUID, publicDashboardUID string) (*PublicDashboard, error) {
+	pubdash, err := s.publicDashboardService.FindByDashboardUid(ctx, orgID, dashboardUID)
+	if err != nil {
+		return nil, err
+	}
+	if pubdash == nil || pubdash.Uid != publicDashboardUID {
+		return nil, ErrPublicDashboardNotFound.Errorf("public dashboard %q of dashboard %q not found", publicDashboardUID, dashboardUID)
+	}
+	return pubdash, nil
+}
+
+// getValid returns the share link of a token if it is neither expired nor revoked.
+func (s *Service) getValid(ctx context.Context, token string) (*ShareLink, error) {
+	link, err := s.getByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound.Errorf("share link not found")
+	}
+	if link.RevokedAt != 0 {
+		return nil, ErrShareLinkRevoked.Errorf("share link %q is revoked", link.UID)
+	}
+	if s.now().Unix() >= link.ExpiresAt {
+		return nil, ErrShareLinkExpired.Errorf("share link %q is expired", link.UID)
+	}
+	return link, nil
+}
+
+func (s *Service) dashboardTitle(ctx context.Context, link *ShareLink) (string, error) {
+	pubdash, err := s.publicDashboardService.Find(ctx, link.PublicDashboardUID)
+	if err != nil {
+		return "", err
+	}
+	if pubdash == nil {
+		return "", ErrShareLinkNotFound.Errorf("public dashboard %q of share link %q not found", link.PublicDashboardUID, link.UID)
+	}
+	dash, err := s.publicDashboardService.FindDashboard(ctx, pubdash.OrgId, pubdash.DashboardUid)
+	if err != nil {
+		return "", err
+	}
+	return dash.Title, nil
+}
+
+// signSession returns a session token of a link: its UID and expiration, signed with the secret key.
+func (s *Service) signSession(link *ShareLink, expiresAt time.Time) string {
+	payload := link.UID + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.sign(link, payload)
+}
+
+func (s *Service) validSession(link *ShareLink, session string) bool {
+	i := strings.LastIndex(session, ".")
+	if i < 0 {
+		return false
+	}
+	payload, signature := session[:i], session[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.sign(link, payload))) {
+		return false
+	}
+	uid, exp, ok := strings.Cut(payload, ".")
+	if !ok || uid != link.UID {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(exp, 10, 64)
+	return err == nil && s.now().Unix() < expiresAt
+}
+
+// sign signs a payload for a link. The signature depends on the email address of the link, so that the sessions of
+// a link are invalidated if its email address changes.
+func (s *Service) sign(link *ShareLink, payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.secretKey))
+	_, _ = fmt.Fprintf(mac, "%s\n%s\n%s", link.TokenHash, link.Email, payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashCode(uid, code string) string {
+	sum := sha256.Sum256([]byte(uid + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateCode returns a random code of 6 digits.
+func generateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
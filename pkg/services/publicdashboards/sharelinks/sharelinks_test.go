@@ -0,0 +1,170 @@
+package sharelinks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationShareLinks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dash", OrgId: 1, AccessToken: "accesstoken"}
+	publicDashboardService := publicdashboards.NewFakePublicDashboardService(t)
+	publicDashboardService.On("FindByDashboardUid", mock.Anything, int64(1), "dash").Return(pubdash, nil).Maybe()
+	publicDashboardService.On("Find", mock.Anything, "pubdash").Return(pubdash, nil).Maybe()
+	publicDashboardService.On("FindDashboard", mock.Anything, int64(1), "dash").Return(&dashboards.Dashboard{UID: "dash", Title: "Dash"}, nil).Maybe()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	mailer := &notifications.NotificationServiceMock{}
+	s := &Service{
+		store:                  db.InitTestDB(t),
+		publicDashboardService: publicDashboardService,
+		mailer:                 mailer,
+		secretKey:              "secret",
+		log:                    log.NewNopLogger(),
+		now:                    func() time.Time { return now },
+	}
+	ctx := context.Background()
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	t.Run("creating a link validates its expiration and email", func(t *testing.T) {
+		_, err := s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{ExpiresAt: now.Add(-time.Hour)})
+		require.ErrorIs(t, err, ErrInvalidShareLink)
+		_, err = s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{ExpiresAt: now.Add(2 * maxLinkLifetime)})
+		require.ErrorIs(t, err, ErrInvalidShareLink)
+		_, err = s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{Email: "not an email", ExpiresAt: now.Add(time.Hour)})
+		require.ErrorIs(t, err, ErrInvalidShareLink)
+		_, err = s.Create(ctx, admin, "dash", "other", CreateShareLinkCommand{ExpiresAt: now.Add(time.Hour)})
+		require.ErrorIs(t, err, ErrPublicDashboardNotFound)
+	})
+
+	t.Run("links without email can be opened until they expire", func(t *testing.T) {
+		link, err := s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{ExpiresAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+		require.NotEmpty(t, link.Token)
+
+		accessCtx, accessToken, err := s.Authorize(ctx, link.Token, "")
+		require.NoError(t, err)
+		require.Equal(t, "accesstoken", accessToken)
+		require.True(t, HasShareLinkAccess(accessCtx))
+		require.NoError(t, s.CountUsage(ctx, link.Token))
+		require.NoError(t, s.CountUsage(ctx, link.Token))
+
+		links, err := s.List(ctx, 1, "dash", "pubdash")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), links[0].UsageCount)
+		require.Empty(t, links[0].Token)
+
+		_, _, err = s.Authorize(ctx, "unknown", "")
+		require.ErrorIs(t, err, ErrShareLinkNotFound)
+
+		s.now = func() time.Time { return now.Add(time.Hour) }
+		defer func() { s.now = func() time.Time { return now } }()
+		_, _, err = s.Authorize(ctx, link.Token, "")
+		require.ErrorIs(t, err, ErrShareLinkExpired)
+	})
+
+	t.Run("revoked links can't be opened", func(t *testing.T) {
+		link, err := s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{ExpiresAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+		require.NoError(t, s.Revoke(ctx, 1, "dash", "pubdash", link.UID))
+
+		_, _, err = s.Authorize(ctx, link.Token, "")
+		require.ErrorIs(t, err, ErrShareLinkRevoked)
+		require.ErrorIs(t, s.Revoke(ctx, 1, "dash", "pubdash", "unknown"), ErrShareLinkNotFound)
+	})
+
+	t.Run("links with email require a verified code", func(t *testing.T) {
+		link, err := s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{Email: "Viewer <viewer@example.com>", ExpiresAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, "viewer@example.com", link.Email)
+
+		status, err := s.Status(ctx, link.Token)
+		require.NoError(t, err)
+		require.True(t, status.VerificationRequired)
+		_, _, err = s.Authorize(ctx, link.Token, "")
+		require.ErrorIs(t, err, ErrVerificationNeeded)
+
+		require.NoError(t, s.SendCode(ctx, link.Token))
+		require.Equal(t, []string{"viewer@example.com"}, mailer.EmailSync.To)
+		require.Equal(t, "Dash", mailer.EmailSync.Data["DashboardTitle"])
+		code := mailer.EmailSync.Data["Code"].(string)
+		require.ErrorIs(t, s.SendCode(ctx, link.Token), ErrCodeRecentlySent)
+
+		_, err = s.Verify(ctx, link.Token, "wrong")
+		require.ErrorIs(t, err, ErrInvalidCode)
+		session, err := s.Verify(ctx, link.Token, code)
+		require.NoError(t, err)
+		require.Equal(t, now.Add(time.Hour), session.ExpiresAt)
+		_, err = s.Verify(ctx, link.Token, code)
+		require.ErrorIs(t, err, ErrInvalidCode, "a code can only be used once")
+
+		_, accessToken, err := s.Authorize(ctx, link.Token, session.Token)
+		require.NoError(t, err)
+		require.Equal(t, "accesstoken", accessToken)
+		_, _, err = s.Authorize(ctx, link.Token, session.Token+"0")
+		require.ErrorIs(t, err, ErrVerificationNeeded)
+	})
+
+	t.Run("codes are invalidated after too many attempts", func(t *testing.T) {
+		link, err := s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{Email: "viewer@example.com", ExpiresAt: now.Add(2 * time.Hour)})
+		require.NoError(t, err)
+		require.NoError(t, s.SendCode(ctx, link.Token))
+		code := mailer.EmailSync.Data["Code"].(string)
+
+		for i := 0; i < maxCodeAttempts; i++ {
+			_, err = s.Verify(ctx, link.Token, "wrong")
+			require.ErrorIs(t, err, ErrInvalidCode)
+		}
+		_, err = s.Verify(ctx, link.Token, code)
+		require.ErrorIs(t, err, ErrTooManyAttempts)
+
+		// a new code doesn't give new attempts until the code window ends
+		s.now = func() time.Time { return now.Add(codeResendInterval) }
+		defer func() { s.now = func() time.Time { return now } }()
+		require.NoError(t, s.SendCode(ctx, link.Token))
+		_, err = s.Verify(ctx, link.Token, mailer.EmailSync.Data["Code"].(string))
+		require.ErrorIs(t, err, ErrTooManyAttempts)
+
+		s.now = func() time.Time { return now.Add(codeWindow) }
+		require.NoError(t, s.SendCode(ctx, link.Token))
+		_, err = s.Verify(ctx, link.Token, mailer.EmailSync.Data["Code"].(string))
+		require.NoError(t, err)
+	})
+
+	t.Run("the number of codes sent is limited", func(t *testing.T) {
+		link, err := s.Create(ctx, admin, "dash", "pubdash", CreateShareLinkCommand{Email: "viewer@example.com", ExpiresAt: now.Add(2 * time.Hour)})
+		require.NoError(t, err)
+		defer func() { s.now = func() time.Time { return now } }()
+
+		for i := 0; i < maxCodesPerWindow; i++ {
+			sentAt := now.Add(time.Duration(i) * codeResendInterval)
+			s.now = func() time.Time { return sentAt }
+			require.NoError(t, s.SendCode(ctx, link.Token))
+		}
+		s.now = func() time.Time { return now.Add(maxCodesPerWindow * codeResendInterval) }
+		require.ErrorIs(t, s.SendCode(ctx, link.Token), ErrTooManyCodes)
+
+		s.now = func() time.Time { return now.Add(codeWindow) }
+		require.NoError(t, s.SendCode(ctx, link.Token))
+	})
+}
@@ -0,0 +1,94 @@
+package sharelinks
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) insert(ctx context.Context, link *ShareLink) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(link)
+		return err
+	})
+}
+
+func (s *Service) update(ctx context.Context, link *ShareLink, cols ...string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(link.ID).Cols(cols...).Update(link)
+		return err
+	})
+}
+
+// issueCode stores a new code of a link, unless another code was sent since the link was read. When newWindow is
+// true, a new code window starts and the codes and attempts of the previous one are no longer counted.
+func (s *Service) issueCode(ctx context.Context, link *ShareLink, codeHash string, now time.Time, newWindow bool) (bool, error) {
+	query := "UPDATE dashboard_public_share_link SET code_hash = ?, code_expires_at = ?, code_sent_at = ?, codes_sent = codes_sent + 1 WHERE id = ? AND code_sent_at = ? AND codes_sent < ?"
+	args := []any{codeHash, now.Add(codeLifetime).Unix(), now.Unix(), link.ID, link.CodeSentAt, maxCodesPerWindow}
+	if newWindow {
+		query = "UPDATE dashboard_public_share_link SET code_hash = ?, code_expires_at = ?, code_sent_at = ?, codes_sent = 1, code_attempts = 0, code_window_start = ? WHERE id = ? AND code_sent_at = ?"
+		args = []any{codeHash, now.Add(codeLifetime).Unix(), now.Unix(), now.Unix(), link.ID, link.CodeSentAt}
+	}
+	return s.exec(ctx, append([]any{query}, args...)...)
+}
+
+// countAttempt counts an attempt to verify the code of a link, and returns false if the link has no attempt left.
+func (s *Service) countAttempt(ctx context.Context, link *ShareLink) (bool, error) {
+	return s.exec(ctx, "UPDATE dashboard_public_share_link SET code_attempts = code_attempts + 1 WHERE id = ? AND code_attempts < ?",
+		link.ID, maxCodeAttempts)
+}
+
+// consumeCode removes the code of a link once it is verified, and returns false if it was already removed.
+func (s *Service) consumeCode(ctx context.Context, link *ShareLink) (bool, error) {
+	return s.exec(ctx, "UPDATE dashboard_public_share_link SET code_hash = '', code_expires_at = 0, code_attempts = 0 WHERE id = ? AND code_hash = ?",
+		link.ID, link.CodeHash)
+}
+
+// exec executes an update and returns true if it updated a row.
+func (s *Service) exec(ctx context.Context, sqlOrArgs ...any) (bool, error) {
+	var updated bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec(sqlOrArgs...)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		updated = rows > 0
+		return err
+	})
+	return updated, err
+}
+
+// getByTokenHash returns the share link of a token hash, nil if there is none.
+func (s *Service) getByTokenHash(ctx context.Context, tokenHash string) (*ShareLink, error) {
+	return s.get(ctx, "token_hash = ?", tokenHash)
+}
+
+// getByUID returns the share link of an organization with a UID, nil if there is none.
+func (s *Service) getByUID(ctx context.Context, orgID int64, uid string) (*ShareLink, error) {
+	return s.get(ctx, "org_id = ? AND uid = ?", orgID, uid)
+}
+
+func (s *Service) get(ctx context.Context, query string, args ...any) (*ShareLink, error) {
+	link := &ShareLink{}
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where(query, args...).Get(link)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	return link, nil
+}
+
+// list returns the share links of a public dashboard, the most recent first.
+func (s *Service) list(ctx context.Context, orgID int64, publicDashboardUID string) ([]*ShareLink, error) {
+	links := make([]*ShareLink, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND public_dashboard_uid = ?", orgID, publicDashboardUID).Desc("id").Find(&links)
+	})
+	return links, err
+}
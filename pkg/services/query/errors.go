@@ -10,4 +10,6 @@ var (
 	ErrMissingDataSourceInfo = errutil.BadRequest("query.missingDataSourceInfo").MustTemplate("query missing datasource info: {{ .Public.RefId }}", errutil.WithPublic("Query {{ .Public.RefId }} is missing datasource information"))
 	ErrQueryParamMismatch    = errutil.BadRequest("query.headerMismatch", errutil.WithPublicMessage("The request headers point to a different plugin than is defined in the request body")).Errorf("plugin header/body mismatch")
 	ErrDuplicateRefId        = errutil.BadRequest("query.duplicateRefId", errutil.WithPublicMessage("Multiple queries using the same RefId is not allowed ")).Errorf("multiple queries using the same RefId is not allowed")
+	ErrQueryTimeout          = errutil.Timeout("query.timeout").MustTemplate("query exceeded the timeout of {{ .Public.Timeout }}", errutil.WithPublic("Query exceeded the time limit of {{ .Public.Timeout }}"))
+	ErrResultLimitExceeded   = errutil.BadRequest("query.resultLimitExceeded").MustTemplate("query result exceeded the limit of {{ .Public.Limit }}", errutil.WithPublic("Query result exceeds the limit of {{ .Public.Limit }}, narrow down the query or its time range"))
 )
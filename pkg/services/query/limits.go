@@ -0,0 +1,214 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// errQueryTimeoutCause is the cause of the context of requests cancelled by the query timeout.
+var errQueryTimeoutCause = errors.New("query timeout")
+
+// queryLimit returns the result limits of the organization of the user.
+func (s *ServiceImpl) queryLimit(user identity.Requester) setting.QueryLimit {
+	var orgID int64
+	if user != nil {
+		orgID = user.GetOrgID()
+	}
+	return s.cfg.QueryLimits.Limit(orgID)
+}
+
+// withQueryTimeout returns a context that is cancelled once the timeout of the limit passed.
+func withQueryTimeout(ctx context.Context, limit setting.QueryLimit) (context.Context, context.CancelFunc) {
+	if limit.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, limit.Timeout, errQueryTimeoutCause)
+}
+
+// queryTimedOut returns true if the context was cancelled by the query timeout.
+func queryTimedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errQueryTimeoutCause)
+}
+
+func queryTimeoutError(limit setting.QueryLimit) error {
+	return ErrQueryTimeout.Build(errutil.TemplateData{
+		Public: map[string]any{
+			"Timeout": limit.Timeout.String(),
+		},
+	})
+}
+
+// markTimedOut replaces the errors of the responses that failed because of the query timeout.
+func markTimedOut(ctx context.Context, limit setting.QueryLimit, responses backend.Responses) {
+	if !queryTimedOut(ctx) {
+		return
+	}
+	err := queryTimeoutError(limit)
+	for refID, res := range responses {
+		if res.Error != nil {
+			responses[refID] = backend.DataResponse{Error: err, Status: backend.StatusTimeout}
+		}
+	}
+}
+
+// markTooLarge replaces the errors of the responses that the backend plugins could not send, or that the core
+// plugins could not read from their data sources, because they exceed the size limit.
+func markTooLarge(limit setting.QueryLimit, responses backend.Responses) {
+	for refID, res := range responses {
+		if res.Error != nil && errors.Is(res.Error, plugins.ErrResponseTooLarge) {
+			responses[refID] = backend.DataResponse{Error: newResultBudget(limit).sizeError(), Status: backend.StatusBadRequest}
+		}
+	}
+}
+
+// resultBudget keeps track of the frames returned by a request, so that the size limits apply to the
+// whole request even when its responses are sent in several parts.
+type resultBudget struct {
+	limit    setting.QueryLimit
+	bytes    int64
+	frames   int
+	exceeded error
+}
+
+func newResultBudget(limit setting.QueryLimit) *resultBudget {
+	return &resultBudget{limit: limit}
+}
+
+// apply enforces the limits on the responses in refID order. Once the limits are exceeded, the responses
+// that do not fit are either replaced with an error or truncated with a warning notice.
+func (b *resultBudget) apply(responses backend.Responses) {
+	if b.limit.MaxResponseBytes <= 0 && b.limit.MaxFrames <= 0 {
+		return
+	}
+
+	refIDs := make([]string, 0, len(responses))
+	for refID := range responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	for _, refID := range refIDs {
+		res := responses[refID]
+		if res.Error != nil || len(res.Frames) == 0 {
+			continue
+		}
+		responses[refID] = b.applyResponse(res)
+	}
+}
+
+func (b *resultBudget) applyResponse(res backend.DataResponse) backend.DataResponse {
+	frames := make(data.Frames, 0, len(res.Frames))
+	for _, frame := range res.Frames {
+		if b.exceeded != nil {
+			break
+		}
+		if b.limit.MaxFrames > 0 && b.frames >= b.limit.MaxFrames {
+			b.exceeded = b.limitError(fmt.Sprintf("%d frames", b.limit.MaxFrames))
+			break
+		}
+
+		kept, size, complete := b.fit(frame)
+		if !complete {
+			b.exceeded = b.sizeError()
+			if b.limit.Truncate && kept.Rows() > 0 {
+				frames = append(frames, kept)
+				b.frames++
+			}
+			break
+		}
+		frames = append(frames, frame)
+		b.frames++
+		b.bytes += size
+	}
+
+	if b.exceeded == nil {
+		return res
+	}
+	if !b.limit.Truncate {
+		return backend.DataResponse{Error: b.exceeded, Status: backend.StatusBadRequest}
+	}
+
+	if len(frames) == 0 {
+		frames = append(frames, res.Frames[0].EmptyCopy())
+	}
+	frames[len(frames)-1].AppendNotices(data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "The result was truncated because it exceeds the query limits",
+	})
+	res.Frames = frames
+	return res
+}
+
+// fit returns the frame if it fits in the remaining bytes, or a copy of the rows that fit otherwise.
+func (b *resultBudget) fit(frame *data.Frame) (*data.Frame, int64, bool) {
+	if b.limit.MaxResponseBytes <= 0 {
+		return frame, 0, true
+	}
+
+	remaining := b.limit.MaxResponseBytes - b.bytes
+	var size int64
+	rows := frame.Rows()
+	for i := 0; i < rows; i++ {
+		rowSize := frameRowSize(frame, i)
+		if size+rowSize > remaining {
+			if !b.limit.Truncate {
+				return nil, size, false
+			}
+			truncated := frame.EmptyCopy()
+			for j := 0; j < i; j++ {
+				truncated.AppendRow(frame.RowCopy(j)...)
+			}
+			b.bytes += size
+			return truncated, size, false
+		}
+		size += rowSize
+	}
+	return frame, size, true
+}
+
+func (b *resultBudget) sizeError() error {
+	return b.limitError(fmt.Sprintf("%d bytes", b.limit.MaxResponseBytes))
+}
+
+func (b *resultBudget) limitError(limit string) error {
+	return ErrResultLimitExceeded.Build(errutil.TemplateData{
+		Public: map[string]any{
+			"Limit": limit,
+		},
+	})
+}
+
+// frameRowSize approximates the memory used by the values of a row.
+func frameRowSize(frame *data.Frame, i int) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		switch v := field.At(i).(type) {
+		case string:
+			size += int64(len(v))
+		case *string:
+			if v != nil {
+				size += int64(len(*v))
+			}
+		case json.RawMessage:
+			size += int64(len(v))
+		case *json.RawMessage:
+			if v != nil {
+				size += int64(len(*v))
+			}
+		default:
+			size += 8
+		}
+	}
+	return size
+}
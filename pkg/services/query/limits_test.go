@@ -0,0 +1,124 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResultBudget(t *testing.T) {
+	newFrame := func(name string, rows int) *data.Frame {
+		values := make([]string, rows)
+		for i := range values {
+			values[i] = "0123456789"
+		}
+		return data.NewFrame(name, data.NewField("line", nil, values))
+	}
+	newResponses := func() backend.Responses {
+		return backend.Responses{
+			"A": {Frames: data.Frames{newFrame("a1", 3), newFrame("a2", 3)}},
+			"B": {Frames: data.Frames{newFrame("b1", 3)}},
+		}
+	}
+
+	t.Run("keeps responses within the limits", func(t *testing.T) {
+		responses := newResponses()
+		newResultBudget(setting.QueryLimit{MaxResponseBytes: 90, MaxFrames: 3}).apply(responses)
+		require.Len(t, responses["A"].Frames, 2)
+		require.Len(t, responses["B"].Frames, 1)
+		require.NoError(t, responses["B"].Error)
+	})
+
+	t.Run("rejects responses over the size limit", func(t *testing.T) {
+		responses := newResponses()
+		newResultBudget(setting.QueryLimit{MaxResponseBytes: 50}).apply(responses)
+		require.ErrorIs(t, responses["A"].Error, ErrResultLimitExceeded)
+		require.Equal(t, backend.StatusBadRequest, responses["A"].Status)
+		require.ErrorIs(t, responses["B"].Error, ErrResultLimitExceeded)
+	})
+
+	t.Run("rejects responses over the frame limit", func(t *testing.T) {
+		responses := newResponses()
+		newResultBudget(setting.QueryLimit{MaxFrames: 2}).apply(responses)
+		require.NoError(t, responses["A"].Error)
+		require.Len(t, responses["A"].Frames, 2)
+		require.ErrorIs(t, responses["B"].Error, ErrResultLimitExceeded)
+	})
+
+	t.Run("truncates responses over the size limit", func(t *testing.T) {
+		responses := newResponses()
+		newResultBudget(setting.QueryLimit{MaxResponseBytes: 50, Truncate: true}).apply(responses)
+
+		a := responses["A"]
+		require.NoError(t, a.Error)
+		require.Len(t, a.Frames, 2)
+		require.Equal(t, 3, a.Frames[0].Rows())
+		require.Equal(t, 2, a.Frames[1].Rows())
+		require.Len(t, a.Frames[1].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, a.Frames[1].Meta.Notices[0].Severity)
+
+		b := responses["B"]
+		require.NoError(t, b.Error)
+		require.Len(t, b.Frames, 1)
+		require.Equal(t, 0, b.Frames[0].Rows())
+		require.Len(t, b.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("applies the limits across several parts of a request", func(t *testing.T) {
+		budget := newResultBudget(setting.QueryLimit{MaxFrames: 1})
+
+		first := backend.Responses{"A": {Frames: data.Frames{newFrame("a", 1)}}}
+		budget.apply(first)
+		require.NoError(t, first["A"].Error)
+
+		second := backend.Responses{"B": {Frames: data.Frames{newFrame("b", 1)}}}
+		budget.apply(second)
+		require.ErrorIs(t, second["B"].Error, ErrResultLimitExceeded)
+	})
+}
+
+func TestMarkTooLarge(t *testing.T) {
+	responses := backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("a")}},
+		"B": {Error: plugins.ErrPluginDownstreamErrorBase.Errorf("client: failed to query data: %w", plugins.ErrResponseTooLarge)},
+	}
+	markTooLarge(setting.QueryLimit{MaxResponseBytes: 100}, responses)
+	require.NoError(t, responses["A"].Error)
+	require.ErrorIs(t, responses["B"].Error, ErrResultLimitExceeded)
+	require.Equal(t, backend.StatusBadRequest, responses["B"].Status)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	t.Run("marks failed responses as timed out", func(t *testing.T) {
+		limit := setting.QueryLimit{Timeout: time.Millisecond}
+		ctx, cancel := withQueryTimeout(context.Background(), limit)
+		defer cancel()
+		<-ctx.Done()
+
+		responses := backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("a")}},
+			"B": {Error: context.DeadlineExceeded},
+		}
+		markTimedOut(ctx, limit, responses)
+		require.NoError(t, responses["A"].Error)
+		require.ErrorIs(t, responses["B"].Error, ErrQueryTimeout)
+		require.Equal(t, backend.StatusTimeout, responses["B"].Status)
+	})
+
+	t.Run("ignores cancellations by the caller", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel := withQueryTimeout(parent, setting.QueryLimit{Timeout: time.Hour})
+		defer cancel()
+		cancelParent()
+		<-ctx.Done()
+
+		require.False(t, queryTimedOut(ctx))
+	})
+}
@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/tsdb/queryerror"
+)
+
+var queryErrorsCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "query",
+		Name:      "errors_total",
+		Help:      "A counter for failed queries by error type",
+	},
+	[]string{"type"},
+)
+
+// annotateErrors adds the error type to the responses of the failed queries and counts them.
+func annotateErrors(responses backend.Responses) {
+	for refID, res := range responses {
+		if res.Error == nil {
+			continue
+		}
+		t := queryerror.Annotate(&res)
+		responses[refID] = res
+		queryErrorsCounter.WithLabelValues(string(t)).Inc()
+	}
+}
@@ -21,6 +21,8 @@ type parsedRequest struct {
 	hasExpression bool
 	parsedQueries map[string][]parsedQuery
 	dsTypes       map[string]bool
+	// failedQueries are the errors of the queries whose datasource could not be resolved, by refID
+	failedQueries map[string]error
 }
 
 func (pr parsedRequest) getFlattenedQueries() []parsedQuery {
@@ -31,8 +33,19 @@ func (pr parsedRequest) getFlattenedQueries() []parsedQuery {
 	return queries
 }
 
+func (pr parsedRequest) getRawQueries() []*simplejson.Json {
+	queries := make([]*simplejson.Json, 0)
+	for _, pq := range pr.getFlattenedQueries() {
+		queries = append(queries, pq.rawQuery)
+	}
+	return queries
+}
+
 func (pr parsedRequest) validateRequest(ctx context.Context) error {
 	refIds := make(map[string]bool)
+	for refId := range pr.failedQueries {
+		refIds[refId] = true
+	}
 	for _, pq := range pr.parsedQueries {
 		for _, q := range pq {
 			if refIds[q.query.RefID] {
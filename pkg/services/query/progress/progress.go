@@ -0,0 +1,452 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+// HeaderQueryID attaches an ID to a query request, so that its progress can be followed and the query cancelled.
+const HeaderQueryID = "X-Query-Id"
+
+const (
+	// finishedRetention is how long the final progress of a query stays available after it completes.
+	finishedRetention = time.Minute
+	// runningRetention is how long the progress of a running query stays in the remote cache after its last change.
+	runningRetention = time.Hour
+	// pollInterval is how often the instance running a query checks whether it was cancelled on another instance,
+	// and how often the progress of the queries running on the other instances is fetched.
+	pollInterval = time.Second
+
+	cachePrefix       = "query-progress-"
+	cancelCachePrefix = "query-progress-cancel-"
+)
+
+var (
+	ErrQueryNotFound  = errors.New("query not found")
+	ErrQueryIDInUse   = errors.New("a query with this ID is already running")
+	ErrInvalidQueryID = errors.New("query IDs must be 1 to 64 letters, digits, dashes or underscores")
+
+	queryIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateDone      State = "done"
+	StateCancelled State = "cancelled"
+	StateFailed    State = "failed"
+)
+
+// Progress is the state of a query request with an ID.
+type Progress struct {
+	QueryID string `json:"queryId"`
+	State   State  `json:"state"`
+	// TotalQueries is the number of queries of the request, CompletedQueries the number of them with a response.
+	TotalQueries     int `json:"totalQueries"`
+	CompletedQueries int `json:"completedQueries"`
+	// Frames is the number of frames received so far.
+	Frames int `json:"frames"`
+	// BytesScanned is the number of bytes processed by the datasources that report it in their query stats.
+	BytesScanned int64  `json:"bytesScanned"`
+	Error        string `json:"error,omitempty"`
+}
+
+type key struct {
+	orgID   int64
+	queryID string
+}
+
+func (k key) cacheKey() string {
+	return cachePrefix + strconv.FormatInt(k.orgID, 10) + "-" + k.queryID
+}
+
+func (k key) cancelCacheKey() string {
+	return cancelCachePrefix + strconv.FormatInt(k.orgID, 10) + "-" + k.queryID
+}
+
+// sharedProgress is the progress of a query in the remote cache, shared with the other instances.
+type sharedProgress struct {
+	UserUID  string   `json:"userUid"`
+	Progress Progress `json:"progress"`
+}
+
+// update is a change of the progress of a query, handed to the listeners and the remote cache once the lock is
+// released.
+type update struct {
+	key       key
+	userUID   string
+	progress  Progress
+	listeners []func(orgID int64, p Progress)
+}
+
+type entry struct {
+	userUID     string
+	progress    Progress
+	cancel      context.CancelFunc
+	cancelled   bool
+	subscribers map[chan Progress]struct{}
+}
+
+// Tracker follows the progress of the query requests with an ID and lets their users cancel them. The queries run
+// on a single instance, which shares their progress with the other instances through the remote cache, so that
+// they can be followed and cancelled from any of them.
+type Tracker struct {
+	cache remotecache.CacheStorage
+	log   log.Logger
+
+	mu        sync.Mutex
+	queries   map[key]*entry
+	listeners []func(orgID int64, p Progress)
+}
+
+func ProvideTracker(cache remotecache.CacheStorage) *Tracker {
+	return &Tracker{
+		cache:   cache,
+		log:     log.New("query.progress"),
+		queries: make(map[key]*entry),
+	}
+}
+
+// OnUpdate registers a function called with every change of the progress of a query made on this instance. It's
+// called in the goroutine of the query, so it must not block.
+func (t *Tracker) OnUpdate(fn func(orgID int64, p Progress)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listeners = append(t.listeners, fn)
+}
+
+// Start tracks a query request of the user with the given number of queries. The returned context is cancelled
+// when the query is cancelled, and the query must be finished with Finish.
+func (t *Tracker) Start(ctx context.Context, user identity.Requester, queryID string, totalQueries int) (context.Context, *Query, error) {
+	if !queryIDRegexp.MatchString(queryID) {
+		return nil, nil, ErrInvalidQueryID
+	}
+
+	t.mu.Lock()
+	k := key{orgID: user.GetOrgID(), queryID: queryID}
+	if e, ok := t.queries[k]; ok && e.progress.State == StateRunning {
+		t.mu.Unlock()
+		return nil, nil, ErrQueryIDInUse
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e := &entry{
+		userUID:     user.GetUID(),
+		progress:    Progress{QueryID: queryID, State: StateRunning, TotalQueries: totalQueries},
+		cancel:      cancel,
+		subscribers: make(map[chan Progress]struct{}),
+	}
+	t.queries[k] = e
+	u := t.notify(k, e)
+	t.mu.Unlock()
+
+	t.publish(ctx, u)
+	go t.watchCancel(ctx, k, e)
+	return ctx, &Query{tracker: t, key: k, entry: e}, nil
+}
+
+// watchCancel cancels the query when it is cancelled on another instance, until the query is finished.
+func (t *Tracker) watchCancel(ctx context.Context, k key, e *entry) {
+	// a previous query with the same ID could have finished before its cancellation was seen
+	_ = t.cache.Delete(ctx, k.cancelCacheKey())
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := t.cache.Get(ctx, k.cancelCacheKey()); err != nil {
+			continue
+		}
+		_ = t.cache.Delete(ctx, k.cancelCacheKey())
+
+		t.mu.Lock()
+		if e.progress.State == StateRunning {
+			e.cancelled = true
+			e.cancel()
+		}
+		t.mu.Unlock()
+		return
+	}
+}
+
+// Get returns the progress of a query of the user.
+func (t *Tracker) Get(ctx context.Context, user identity.Requester, queryID string) (Progress, error) {
+	t.mu.Lock()
+	e, err := t.lookup(user, queryID)
+	if err == nil {
+		defer t.mu.Unlock()
+		return e.progress, nil
+	}
+	t.mu.Unlock()
+
+	shared, err := t.lookupShared(ctx, user, queryID)
+	if err != nil {
+		return Progress{}, err
+	}
+	return shared.Progress, nil
+}
+
+// Cancel cancels a running query of the user. The queries running on another instance are cancelled by it
+// within the poll interval.
+func (t *Tracker) Cancel(ctx context.Context, user identity.Requester, queryID string) error {
+	t.mu.Lock()
+	e, err := t.lookup(user, queryID)
+	if err == nil {
+		defer t.mu.Unlock()
+		if e.progress.State == StateRunning {
+			e.cancelled = true
+			e.cancel()
+		}
+		return nil
+	}
+	t.mu.Unlock()
+
+	shared, err := t.lookupShared(ctx, user, queryID)
+	if err != nil {
+		return err
+	}
+	if shared.Progress.State != StateRunning {
+		return nil
+	}
+	k := key{orgID: user.GetOrgID(), queryID: queryID}
+	if err := t.cache.Set(ctx, k.cancelCacheKey(), []byte{1}, finishedRetention); err != nil {
+		return fmt.Errorf("failed to cancel query: %w", err)
+	}
+	return nil
+}
+
+// Subscribe returns a channel receiving the current progress of a query of the user and its later changes.
+// Intermediate changes are dropped when the receiver is slow. The channel is closed once the query is finished
+// or unsubscribe is called. The changes of the queries running on another instance are polled from the remote
+// cache until ctx is done.
+func (t *Tracker) Subscribe(ctx context.Context, user identity.Requester, queryID string) (<-chan Progress, func(), error) {
+	t.mu.Lock()
+	e, err := t.lookup(user, queryID)
+	if err != nil {
+		t.mu.Unlock()
+		return t.subscribeShared(ctx, user, queryID)
+	}
+	defer t.mu.Unlock()
+
+	ch := make(chan Progress, 1)
+	ch <- e.progress
+	if e.progress.State != StateRunning {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	e.subscribers[ch] = struct{}{}
+	unsubscribe := func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := e.subscribers[ch]; ok {
+			delete(e.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+func (t *Tracker) subscribeShared(ctx context.Context, user identity.Requester, queryID string) (<-chan Progress, func(), error) {
+	shared, err := t.lookupShared(ctx, user, queryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan Progress, 1)
+	ch <- shared.Progress
+	if shared.Progress.State != StateRunning {
+		close(ch)
+		return ch, func() {}, nil
+	}
+
+	ctx, unsubscribe := context.WithCancel(ctx)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		last := shared.Progress
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			shared, err := t.lookupShared(ctx, user, queryID)
+			if err != nil {
+				return
+			}
+			if shared.Progress == last {
+				continue
+			}
+			last = shared.Progress
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- last
+			if last.State != StateRunning {
+				return
+			}
+		}
+	}()
+	return ch, unsubscribe, nil
+}
+
+func (t *Tracker) lookup(user identity.Requester, queryID string) (*entry, error) {
+	e, ok := t.queries[key{orgID: user.GetOrgID(), queryID: queryID}]
+	if !ok || e.userUID != user.GetUID() {
+		return nil, ErrQueryNotFound
+	}
+	return e, nil
+}
+
+// lookupShared returns the progress of a query of the user shared by the instance running it.
+func (t *Tracker) lookupShared(ctx context.Context, user identity.Requester, queryID string) (*sharedProgress, error) {
+	if !queryIDRegexp.MatchString(queryID) {
+		return nil, ErrQueryNotFound
+	}
+	k := key{orgID: user.GetOrgID(), queryID: queryID}
+	value, err := t.cache.Get(ctx, k.cacheKey())
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, ErrQueryNotFound
+		}
+		return nil, fmt.Errorf("failed to get query progress: %w", err)
+	}
+	shared := &sharedProgress{}
+	if err := json.Unmarshal(value, shared); err != nil {
+		return nil, fmt.Errorf("failed to decode query progress: %w", err)
+	}
+	if shared.UserUID != user.GetUID() {
+		return nil, ErrQueryNotFound
+	}
+	return shared, nil
+}
+
+// notify hands the progress of the entry to the subscribers, and returns the update for the listeners and the
+// remote cache, which are called by publish once the lock is released. It must be called with the lock held.
+func (t *Tracker) notify(k key, e *entry) update {
+	for ch := range e.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- e.progress
+	}
+	return update{key: k, userUID: e.userUID, progress: e.progress, listeners: slices.Clone(t.listeners)}
+}
+
+// publish hands an update to the listeners, and shares it with the other instances. It must be called without
+// the lock held.
+func (t *Tracker) publish(ctx context.Context, u update) {
+	for _, fn := range u.listeners {
+		fn(u.key.orgID, u.progress)
+	}
+
+	value, err := json.Marshal(sharedProgress{UserUID: u.userUID, Progress: u.progress})
+	if err != nil {
+		return
+	}
+	retention := runningRetention
+	if u.progress.State != StateRunning {
+		retention = finishedRetention
+	}
+	// the query context is cancelled once the query is finished, the final progress must still be shared
+	if err := t.cache.Set(context.WithoutCancel(ctx), u.key.cacheKey(), value, retention); err != nil {
+		t.log.Warn("Failed to share query progress", "queryId", u.key.queryID, "error", err)
+	}
+}
+
+// Query is a tracked query request.
+type Query struct {
+	tracker *Tracker
+	key     key
+	entry   *entry
+}
+
+// Update adds the responses received for some of the queries of the request to the progress.
+func (q *Query) Update(ctx context.Context, responses backend.Responses) {
+	q.tracker.mu.Lock()
+	p := &q.entry.progress
+	p.CompletedQueries += len(responses)
+	for _, res := range responses {
+		p.Frames += len(res.Frames)
+		p.BytesScanned += bytesScanned(res.Frames)
+	}
+	u := q.tracker.notify(q.key, q.entry)
+	q.tracker.mu.Unlock()
+
+	q.tracker.publish(ctx, u)
+}
+
+// Finish records the end of the query request and closes the subscriptions to its progress.
+func (q *Query) Finish(ctx context.Context, err error) {
+	t := q.tracker
+	t.mu.Lock()
+	e := q.entry
+	switch {
+	case e.cancelled:
+		e.progress.State = StateCancelled
+	case err != nil:
+		e.progress.State = StateFailed
+		e.progress.Error = err.Error()
+	default:
+		e.progress.State = StateDone
+	}
+	e.cancel()
+	u := t.notify(q.key, e)
+	for ch := range e.subscribers {
+		close(ch)
+	}
+	e.subscribers = nil
+	t.mu.Unlock()
+
+	t.publish(ctx, u)
+
+	time.AfterFunc(finishedRetention, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.queries[q.key] == e {
+			delete(t.queries, q.key)
+		}
+	})
+}
+
+// bytesScanned sums the query stats of the frames that count the processed bytes, like the
+// "Summary: total bytes processed" stat of Loki.
+func bytesScanned(frames data.Frames) int64 {
+	var total int64
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			continue
+		}
+		for _, stat := range frame.Meta.Stats {
+			name := strings.ToLower(stat.DisplayName)
+			if (stat.Unit == "decbytes" || stat.Unit == "bytes") &&
+				(strings.Contains(name, "total bytes processed") || strings.Contains(name, "bytes scanned")) {
+				total += int64(stat.Value)
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,150 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := ProvideTracker(newFakeCache())
+	owner := &user.SignedInUser{OrgID: 1, UserUID: "owner"}
+	other := &user.SignedInUser{OrgID: 1, UserUID: "other"}
+
+	var published []Progress
+	tracker.OnUpdate(func(orgID int64, p Progress) {
+		require.Equal(t, int64(1), orgID)
+		published = append(published, p)
+		_, err := tracker.Get(context.Background(), owner, p.QueryID)
+		require.NoError(t, err, "the listeners are called without the lock held")
+	})
+
+	_, _, err := tracker.Start(context.Background(), owner, "not a valid id", 2)
+	require.ErrorIs(t, err, ErrInvalidQueryID)
+
+	ctx, q, err := tracker.Start(context.Background(), owner, "q1", 2)
+	require.NoError(t, err)
+	_, _, err = tracker.Start(context.Background(), owner, "q1", 2)
+	require.ErrorIs(t, err, ErrQueryIDInUse)
+
+	_, err = tracker.Get(context.Background(), other, "q1")
+	require.ErrorIs(t, err, ErrQueryNotFound, "queries are only visible to the user who sent them")
+
+	updates, unsubscribe, err := tracker.Subscribe(context.Background(), owner, "q1")
+	require.NoError(t, err)
+	defer unsubscribe()
+	require.Equal(t, StateRunning, (<-updates).State)
+
+	frame := data.NewFrame("").SetMeta(&data.FrameMeta{Stats: []data.QueryStat{
+		{FieldConfig: data.FieldConfig{DisplayName: "Summary: total bytes processed", Unit: "decbytes"}, Value: 2048},
+		{FieldConfig: data.FieldConfig{DisplayName: "Store: compressed bytes", Unit: "decbytes"}, Value: 512},
+	}})
+	q.Update(context.Background(), backend.Responses{"A": backend.DataResponse{Frames: data.Frames{frame, data.NewFrame("")}}})
+	p := <-updates
+	require.Equal(t, 1, p.CompletedQueries)
+	require.Equal(t, 2, p.Frames)
+	require.Equal(t, int64(2048), p.BytesScanned)
+
+	require.ErrorIs(t, tracker.Cancel(context.Background(), other, "q1"), ErrQueryNotFound)
+	require.NoError(t, tracker.Cancel(context.Background(), owner, "q1"))
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	q.Finish(context.Background(), context.Canceled)
+	require.Equal(t, StateCancelled, (<-updates).State)
+	_, ok := <-updates
+	require.False(t, ok, "the subscription is closed once the query is finished")
+
+	require.Len(t, published, 3)
+	require.Equal(t, StateCancelled, published[2].State)
+}
+
+func TestQueryFinish(t *testing.T) {
+	tracker := ProvideTracker(newFakeCache())
+	u := &user.SignedInUser{OrgID: 1, UserUID: "u"}
+
+	_, q, err := tracker.Start(context.Background(), u, "q1", 1)
+	require.NoError(t, err)
+	q.Finish(context.Background(), errors.New("datasource not found"))
+	p, err := tracker.Get(context.Background(), u, "q1")
+	require.NoError(t, err)
+	require.Equal(t, StateFailed, p.State)
+	require.Equal(t, "datasource not found", p.Error)
+
+	_, q, err = tracker.Start(context.Background(), u, "q1", 1)
+	require.NoError(t, err, "the ID of a finished query can be reused")
+	q.Finish(context.Background(), nil)
+	updates, _, err := tracker.Subscribe(context.Background(), u, "q1")
+	require.NoError(t, err)
+	require.Equal(t, StateDone, (<-updates).State)
+	_, ok := <-updates
+	require.False(t, ok)
+}
+
+func TestTrackerAcrossInstances(t *testing.T) {
+	cache := newFakeCache()
+	running, other := ProvideTracker(cache), ProvideTracker(cache)
+	owner := &user.SignedInUser{OrgID: 1, UserUID: "owner"}
+
+	ctx, q, err := running.Start(context.Background(), owner, "q1", 2)
+	require.NoError(t, err)
+
+	_, err = other.Get(context.Background(), &user.SignedInUser{OrgID: 1, UserUID: "other"}, "q1")
+	require.ErrorIs(t, err, ErrQueryNotFound)
+	p, err := other.Get(context.Background(), owner, "q1")
+	require.NoError(t, err)
+	require.Equal(t, StateRunning, p.State)
+
+	updates, unsubscribe, err := other.Subscribe(context.Background(), owner, "q1")
+	require.NoError(t, err)
+	defer unsubscribe()
+	require.Equal(t, StateRunning, (<-updates).State)
+
+	q.Update(context.Background(), backend.Responses{"A": backend.DataResponse{Frames: data.Frames{data.NewFrame("")}}})
+	require.Equal(t, 1, (<-updates).CompletedQueries)
+
+	require.NoError(t, other.Cancel(context.Background(), owner, "q1"))
+	require.Eventually(t, func() bool { return ctx.Err() != nil }, 5*time.Second, 10*time.Millisecond,
+		"the query is cancelled by the instance running it")
+
+	q.Finish(context.Background(), context.Canceled)
+	require.Equal(t, StateCancelled, (<-updates).State)
+	_, ok := <-updates
+	require.False(t, ok)
+}
+
+// fakeCache is a remote cache shared by the trackers of the tests, safe for concurrent use.
+type fakeCache struct {
+	mu    sync.Mutex
+	cache remotecache.FakeCacheStorage
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{cache: remotecache.NewFakeCacheStorage()}
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(ctx, key)
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Set(ctx, key, value, expire)
+}
+
+func (c *fakeCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Delete(ctx, key)
+}
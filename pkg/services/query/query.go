@@ -20,9 +20,13 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/transformations"
+	"github.com/grafana/grafana/pkg/services/metering"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/templatevars"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -45,6 +49,7 @@ func ProvideService(
 	pluginRequestValidator validations.PluginRequestValidator,
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
+	usage metering.Recorder,
 ) *ServiceImpl {
 	g := &ServiceImpl{
 		cfg:                    cfg,
@@ -53,6 +58,7 @@ func ProvideService(
 		pluginRequestValidator: pluginRequestValidator,
 		pluginClient:           pluginClient,
 		pCtxProvider:           pCtxProvider,
+		usage:                  usage,
 		log:                    log.New("query_data"),
 		concurrentQueryLimit:   cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
 	}
@@ -64,6 +70,7 @@ func ProvideService(
 type Service interface {
 	Run(ctx context.Context) error
 	QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error)
+	QueryDataStream(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, send StreamFunc) error
 }
 
 // Gives us compile time error if the service does not adhere to the contract of the interface
@@ -76,8 +83,10 @@ type ServiceImpl struct {
 	pluginRequestValidator validations.PluginRequestValidator
 	pluginClient           plugins.Client
 	pCtxProvider           *plugincontext.Provider
-	log                    log.Logger
-	concurrentQueryLimit   int
+	// usage records the queries of the organizations, it's optional.
+	usage                metering.Recorder
+	log                  log.Logger
+	concurrentQueryLimit int
 }
 
 // Run ServiceImpl.
@@ -87,23 +96,69 @@ func (s *ServiceImpl) Run(ctx context.Context) error {
 }
 
 // QueryData processes queries and returns query responses. It handles queries to single or mixed datasources, as well as expressions.
+// The result is subject to the query limits of the organization of the user.
 func (s *ServiceImpl) QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	if s.usage != nil && user != nil {
+		s.usage.Record(user.GetOrgID(), metering.MetricQueries, int64(len(reqDTO.Queries)))
+	}
+
+	limit := s.queryLimit(user)
+	ctx, cancel := withQueryTimeout(ctx, limit)
+	defer cancel()
+	ctx = backendplugin.WithMaxResponseBytes(ctx, limit.MaxResponseBytes)
+
+	resp, err := s.queryData(ctx, user, skipDSCache, reqDTO)
+	if err != nil {
+		if queryTimedOut(ctx) {
+			return nil, queryTimeoutError(limit)
+		}
+		if errors.Is(err, plugins.ErrResponseTooLarge) {
+			return nil, newResultBudget(limit).sizeError()
+		}
+		return nil, err
+	}
+	markTimedOut(ctx, limit, resp.Responses)
+	markTooLarge(limit, resp.Responses)
+	newResultBudget(limit).apply(resp.Responses)
+	annotateErrors(resp.Responses)
+	return resp, nil
+}
+
+func (s *ServiceImpl) queryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
 	// Parse the request into parsed queries grouped by datasource uid
 	parsedReq, err := s.parseMetricRequest(ctx, user, skipDSCache, reqDTO)
 	if err != nil {
 		return nil, err
 	}
 
-	// If there are expressions, handle them and return
-	if parsedReq.hasExpression {
-		return s.handleExpressions(ctx, user, parsedReq)
+	var resp *backend.QueryDataResponse
+	switch {
+	case parsedReq.hasExpression:
+		// If there are expressions, handle them
+		resp, err = s.handleExpressions(ctx, user, parsedReq)
+	case len(parsedReq.parsedQueries) == 1:
+		// If there is only one datasource, query it
+		resp, err = s.handleQuerySingleDatasource(ctx, user, parsedReq)
+	default:
+		// If there are multiple datasources, handle their queries concurrently and return the aggregate result
+		resp, err = s.executeConcurrentQueries(ctx, user, skipDSCache, reqDTO, parsedReq.parsedQueries)
 	}
-	// If there is only one datasource, query it and return
-	if len(parsedReq.parsedQueries) == 1 {
-		return s.handleQuerySingleDatasource(ctx, user, parsedReq)
+	if len(parsedReq.failedQueries) == 0 {
+		return resp, err
 	}
-	// If there are multiple datasources, handle their queries concurrently and return the aggregate result
-	return s.executeConcurrentQueries(ctx, user, skipDSCache, reqDTO, parsedReq.parsedQueries)
+
+	// The request mixes datasources and some of them failed to resolve, so the error of the remaining
+	// datasource only fails its own queries.
+	if err != nil {
+		resp = &backend.QueryDataResponse{Responses: buildErrorResponses(err, parsedReq.getRawQueries()).responses}
+	}
+	if resp.Responses == nil {
+		resp.Responses = backend.Responses{}
+	}
+	for refID, queryErr := range parsedReq.failedQueries {
+		resp.Responses[refID] = errorResponse(queryErr)
+	}
+	return resp, nil
 }
 
 // splitResponse contains the results of a concurrent data source query - the response and any headers
@@ -147,7 +202,7 @@ func (s *ServiceImpl) executeConcurrentQueries(ctx context.Context, user identit
 			defer recoveryFn(subDTO.Queries)
 
 			ctxCopy := contexthandler.CopyWithReqContext(ctx)
-			subResp, err := s.QueryData(ctxCopy, user, skipDSCache, subDTO)
+			subResp, err := s.queryData(ctxCopy, user, skipDSCache, subDTO)
 			if err == nil {
 				reqCtx, header := contexthandler.FromContext(ctxCopy), http.Header{}
 				if reqCtx != nil {
@@ -192,13 +247,31 @@ func (s *ServiceImpl) executeConcurrentQueries(ctx context.Context, user identit
 func buildErrorResponses(err error, queries []*simplejson.Json) splitResponse {
 	er := backend.Responses{}
 	for _, query := range queries {
-		er[query.Get("refId").MustString("A")] = backend.DataResponse{
-			Error: err,
-		}
+		er[query.Get("refId").MustString("A")] = errorResponse(err)
 	}
 	return splitResponse{er, http.Header{}}
 }
 
+// errorResponse returns the response of a query that failed with err, with the status and the source of the error.
+func errorResponse(err error) backend.DataResponse {
+	res := backend.DataResponse{Error: err, Status: backend.StatusInternal}
+	var grafanaErr errutil.Error
+	switch {
+	case errors.As(err, &grafanaErr):
+		res.Status = backend.Status(grafanaErr.Reason.Status().HTTPStatus())
+		if grafanaErr.Source == errutil.SourceDownstream {
+			res.ErrorSource = backend.ErrorSourceDownstream
+		}
+	case errors.Is(err, datasources.ErrDataSourceAccessDenied):
+		res.Status = backend.StatusForbidden
+	case errors.Is(err, datasources.ErrDataSourceNotFound):
+		res.Status = backend.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		res.Status = backend.StatusTimeout
+	}
+	return res
+}
+
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Requester, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	exprReq := expr.Request{
@@ -269,7 +342,41 @@ func (s *ServiceImpl) handleQuerySingleDatasource(ctx context.Context, user iden
 		req.Queries = append(req.Queries, q.query)
 	}
 
-	return s.pluginClient.QueryData(ctx, req)
+	ctx, endSpans := startPanelQuerySpans(ctx, ds, req.Queries)
+	resp, err := s.pluginClient.QueryData(ctx, req)
+	if err == nil {
+		s.applyTransformations(ds, resp)
+	}
+	endSpans(resp, err)
+	return resp, err
+}
+
+// applyTransformations applies the response transformations configured for the datasource to the frames of the
+// successful responses.
+func (s *ServiceImpl) applyTransformations(ds *datasources.DataSource, resp *backend.QueryDataResponse) {
+	if resp == nil {
+		return
+	}
+	pipeline, err := transformations.FromJSONData(ds.JsonData)
+	if err != nil {
+		// the rules are validated when the datasource is saved, so this only happens for rules stored before
+		s.log.Warn("Skipped invalid response transformations", "datasource", ds.UID, "error", err)
+		return
+	}
+	if len(pipeline) == 0 {
+		return
+	}
+	for refID, res := range resp.Responses {
+		if res.Error != nil {
+			continue
+		}
+		if err := pipeline.Apply(res.Frames); err != nil {
+			res.Error = fmt.Errorf("response transformation failed: %w", err)
+			res.Status = backend.StatusInternal
+			res.Frames = nil
+			resp.Responses[refID] = res
+		}
+	}
 }
 
 // parseRequest parses a request into parsed queries grouped by datasource uid
@@ -283,17 +390,31 @@ func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requ
 		hasExpression: false,
 		parsedQueries: make(map[string][]parsedQuery),
 		dsTypes:       make(map[string]bool),
+		failedQueries: make(map[string]error),
 	}
 
 	// Parse the queries and store them by datasource
 	datasourcesByUid := map[string]*datasources.DataSource{}
+	var dsErr error
 	for _, query := range reqDTO.Queries {
+		if len(reqDTO.Variables) > 0 {
+			// the data source may be a variable too, resolve it before looking it up
+			query = interpolateDatasourceRef(query, reqDTO.Variables)
+		}
 		ds, err := s.getDataSourceFromQuery(ctx, user, skipDSCache, query, datasourcesByUid)
+		if err == nil && ds == nil {
+			err = ErrInvalidDatasourceID
+		}
 		if err != nil {
-			return nil, err
+			// Keep going, the queries of the other datasources may still run
+			req.failedQueries[query.Get("refId").MustString("A")] = err
+			if dsErr == nil {
+				dsErr = err
+			}
+			continue
 		}
-		if ds == nil {
-			return nil, ErrInvalidDatasourceID
+		if len(reqDTO.Variables) > 0 {
+			query = interpolateQuery(query, reqDTO.Variables, ds.Type)
 		}
 
 		datasourcesByUid[ds.UID] = ds
@@ -338,9 +459,48 @@ func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requ
 			"query", string(modelJSON))
 	}
 
+	// Expressions need the results of all their queries
+	if dsErr != nil && (req.hasExpression || len(req.parsedQueries) == 0) {
+		return nil, dsErr
+	}
+
 	return req, req.validateRequest(ctx)
 }
 
+// interpolateDatasourceRef returns a copy of the query with the variables in its datasource resolved.
+func interpolateDatasourceRef(query *simplejson.Json, vars templatevars.Variables) *simplejson.Json {
+	model, err := query.Map()
+	if err != nil {
+		return query
+	}
+	resolved := make(map[string]any, len(model))
+	for key, value := range model {
+		resolved[key] = value
+	}
+	if ref, ok := model["datasource"]; ok {
+		resolved["datasource"] = vars.InterpolateJSON(ref, "")
+	}
+	return simplejson.NewFromAny(resolved)
+}
+
+// interpolateQuery returns a copy of the query with the variables resolved in every string but the refId,
+// using the default variable format of the data source type.
+func interpolateQuery(query *simplejson.Json, vars templatevars.Variables, datasourceType string) *simplejson.Json {
+	model, err := query.Map()
+	if err != nil {
+		return query
+	}
+	resolved := make(map[string]any, len(model))
+	for key, value := range model {
+		if key == "refId" || key == "datasource" {
+			resolved[key] = value
+			continue
+		}
+		resolved[key] = vars.InterpolateJSON(value, datasourceType)
+	}
+	return simplejson.NewFromAny(resolved)
+}
+
 func (s *ServiceImpl) getDataSourceFromQuery(ctx context.Context, user identity.Requester, skipDSCache bool, query *simplejson.Json, history map[string]*datasources.DataSource) (*datasources.DataSource, error) {
 	var err error
 	uid := query.Get("datasource").Get("uid").MustString()
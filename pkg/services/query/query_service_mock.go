@@ -42,6 +42,20 @@ func (_m *FakeQueryService) QueryData(ctx context.Context, _a1 identity.Requeste
 	return r0, r1
 }
 
+// QueryDataStream provides a mock function with given fields: ctx, _a1, skipDSCache, reqDTO, send
+func (_m *FakeQueryService) QueryDataStream(ctx context.Context, _a1 identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, send StreamFunc) error {
+	ret := _m.Called(ctx, _a1, skipDSCache, reqDTO, send)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, identity.Requester, bool, dtos.MetricRequest, StreamFunc) error); ok {
+		r0 = rf(ctx, _a1, skipDSCache, reqDTO, send)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: ctx
 func (_m *FakeQueryService) Run(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -16,12 +16,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/db"
-	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache/namedcache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
@@ -37,9 +38,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsmng "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/templatevars"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/tsdb/queryerror"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -112,6 +115,34 @@ func TestParseMetricRequest(t *testing.T) {
 		})
 	})
 
+	t.Run("Test a query with template variables", func(t *testing.T) {
+		tc := setup(t)
+		mr := metricRequestWithQueries(t, `{
+			"refId": "A",
+			"datasource": {
+				"uid": "${ds}",
+				"type": "postgres"
+			},
+			"rawSql": "SELECT * FROM logs WHERE host IN ($host) AND level = '${level:raw}' AND $__timeFilter(time)"
+		}`)
+		mr.Variables = templatevars.Variables{
+			"ds":    {Value: templatevars.Values{Values: []string{"gIEkMvIVz"}}},
+			"host":  {Value: templatevars.Values{Values: []string{"a", "b"}, Multi: true}},
+			"level": {Value: templatevars.Values{Values: []string{"error"}}},
+		}
+		parsedReq, err := tc.queryService.parseMetricRequest(context.Background(), tc.signedInUser, true, mr)
+		require.NoError(t, err)
+		require.Contains(t, parsedReq.parsedQueries, "gIEkMvIVz")
+
+		queries := parsedReq.getFlattenedQueries()
+		require.Len(t, queries, 1)
+		require.Equal(t, "A", queries[0].query.RefID)
+		require.Equal(t, "SELECT * FROM logs WHERE host IN ('a','b') AND level = 'error' AND $__timeFilter(time)", queries[0].rawQuery.Get("rawSql").MustString())
+		require.Equal(t, "gIEkMvIVz", queries[0].rawQuery.Get("datasource").Get("uid").MustString())
+		// the caller's query is not modified
+		require.Equal(t, "${ds}", mr.Queries[0].Get("datasource").Get("uid").MustString())
+	})
+
 	t.Run("Test a simple mixed datasource query", func(t *testing.T) {
 		tc := setup(t)
 		mr := metricRequestWithQueries(t, `{
@@ -424,6 +455,46 @@ func TestQueryDataMultipleSources(t *testing.T) {
 		require.NotContains(t, res.Responses, "A")
 	})
 
+	t.Run("returns partial results when the datasource of a query can't be found", func(t *testing.T) {
+		tc := setup(t)
+		reqDTO := metricRequestWithQueries(t,
+			`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A"}`,
+			`{"datasource": {"type": "mysql", "uid": "ds2"}, "refId": "B"}`,
+			`{"datasource": {"type": "mysql", "uid": "missing"}, "refId": "C"}`,
+		)
+
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.NoError(t, err)
+		require.Len(t, res.Responses, 1)
+		require.Error(t, res.Responses["C"].Error)
+		require.Equal(t, backend.StatusInternal, res.Responses["C"].Status)
+		require.Equal(t, queryerror.TypeInternal, res.Responses["C"].Frames[0].Meta.Custom.(map[string]any)[queryerror.MetaKey])
+	})
+
+	t.Run("isolates the error of the remaining datasource when another can't be found", func(t *testing.T) {
+		tc := setup(t)
+		reqDTO := metricRequestWithQueries(t,
+			`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A", "queryType": "FAIL"}`,
+			`{"datasource": {"type": "mysql", "uid": "missing"}, "refId": "B"}`,
+		)
+
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Responses["A"].Error, "plugin client failed")
+		require.Error(t, res.Responses["B"].Error)
+	})
+
+	t.Run("fails the request when the datasource of an expression query can't be found", func(t *testing.T) {
+		tc := setup(t)
+		reqDTO := metricRequestWithQueries(t,
+			`{"datasource": {"type": "mysql", "uid": "missing"}, "refId": "A"}`,
+			`{"datasource": {"type": "__expr__", "uid": "__expr__"}, "refId": "B", "type": "math", "expression": "$A + 1"}`,
+		)
+
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.Error(t, err)
+	})
+
 	t.Run("ignores a deprecated datasourceID", func(t *testing.T) {
 		tc := setup(t)
 		query1, err := simplejson.NewJson([]byte(`
@@ -451,6 +522,28 @@ func TestQueryDataMultipleSources(t *testing.T) {
 	})
 }
 
+func TestErrorResponse(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err    error
+		status backend.Status
+		source backend.ErrorSource
+	}{
+		"unknown error":     {err: errors.New("boom"), status: backend.StatusInternal},
+		"access denied":     {err: datasources.ErrDataSourceAccessDenied, status: backend.StatusForbidden},
+		"not found":         {err: fmt.Errorf("lookup: %w", datasources.ErrDataSourceNotFound), status: backend.StatusNotFound},
+		"deadline exceeded": {err: context.DeadlineExceeded, status: backend.StatusTimeout},
+		"grafana error":     {err: ErrInvalidDatasourceID, status: backend.StatusBadRequest},
+		"downstream error":  {err: errutil.BadGateway("test.downstream").Errorf("bad gateway"), status: backend.StatusBadGateway, source: backend.ErrorSourceDownstream},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res := errorResponse(tc.err)
+			require.ErrorIs(t, res.Error, tc.err)
+			require.Equal(t, tc.status, res.Status)
+			require.Equal(t, tc.source, res.ErrorSource)
+		})
+	}
+}
+
 func setup(t *testing.T) *testContext {
 	dss := []*datasources.DataSource{
 		{UID: "gIEkMvIVz", Type: "postgres"},
@@ -473,7 +566,7 @@ func setup(t *testing.T) *testContext {
 	}
 
 	pCtxProvider := plugincontext.ProvideService(cfg,
-		localcache.ProvideService(), &pluginstore.FakePluginStore{
+		namedcache.FakeProvider{}, &pluginstore.FakePluginStore{
 			PluginList: []pluginstore.Plugin{
 				{JSONData: plugins.JSONData{ID: "postgres"}},
 				{JSONData: plugins.JSONData{ID: "testdata"}},
@@ -484,7 +577,7 @@ func setup(t *testing.T) *testContext {
 	)
 	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, pc, pCtxProvider,
 		featuremgmt.WithFeatures(), nil, tracing.InitializeTracerForTest())
-	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, pc, pCtxProvider) // provider belonging to this package
+	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, pc, pCtxProvider, nil) // provider belonging to this package
 	return &testContext{
 		pluginContext:          pc,
 		secretStore:            ss,
@@ -0,0 +1,55 @@
+package querygrpc
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/genproto/pluginv2"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// The messages of query.proto are the BytesValue of the well-known types and the QueryDataResponse of the plugin
+// SDK, so the service is declared here the way protoc-gen-go-grpc declares it, without generated messages.
+
+// QueryDataStreamFullMethodName is the full name of the QueryDataStream method.
+const QueryDataStreamFullMethodName = "/grafana.query.v1.Query/QueryDataStream"
+
+// QueryServer is the server of the Query service.
+type QueryServer interface {
+	QueryDataStream(*wrapperspb.BytesValue, QueryDataStreamServer) error
+}
+
+// QueryDataStreamServer sends the responses of a QueryDataStream call.
+type QueryDataStreamServer interface {
+	Send(*pluginv2.QueryDataResponse) error
+	grpc.ServerStream
+}
+
+type queryDataStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *queryDataStreamServer) Send(m *pluginv2.QueryDataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func queryDataStreamHandler(srv any, stream grpc.ServerStream) error {
+	m := new(wrapperspb.BytesValue)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueryServer).QueryDataStream(m, &queryDataStreamServer{stream})
+}
+
+// QueryServiceDesc is the grpc.ServiceDesc of the Query service.
+var QueryServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grafana.query.v1.Query",
+	HandlerType: (*QueryServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "QueryDataStream",
+			Handler:       queryDataStreamHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "query.proto",
+}
@@ -0,0 +1,116 @@
+// Package querygrpc serves the streaming data source queries on the Grafana gRPC server, so that the responses of
+// each data source reach the clients as soon as they complete, as with the /api/ds/query/stream HTTP API.
+package querygrpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+var _ QueryServer = (*Service)(nil)
+
+type Service struct {
+	queryService   query.Service
+	accessControl  accesscontrol.AccessControl
+	contextHandler grpccontext.ContextHandler
+	log            log.Logger
+}
+
+func ProvideService(grpcServer grpcserver.Provider, queryService query.Service, accessControl accesscontrol.AccessControl,
+	contextHandler grpccontext.ContextHandler) *Service {
+	s := &Service{
+		queryService:   queryService,
+		accessControl:  accessControl,
+		contextHandler: contextHandler,
+		log:            log.New("query-grpc-server"),
+	}
+	grpcServer.GetServer().RegisterService(&QueryServiceDesc, s)
+	return s
+}
+
+// QueryDataStream runs the queries like the /api/ds/query/stream HTTP API, and sends the responses of each data
+// source in a message.
+func (s *Service) QueryDataStream(req *wrapperspb.BytesValue, stream QueryDataStreamServer) error {
+	ctx := stream.Context()
+	user := s.contextHandler.GetUser(ctx)
+	if user == nil {
+		return status.Error(codes.Unauthenticated, "no signed in user")
+	}
+	ok, err := s.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(datasources.ActionQuery))
+	if err != nil {
+		return status.Error(codes.Internal, "failed to check the permissions")
+	}
+	if !ok {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	reqDTO := dtos.MetricRequest{}
+	if err := json.Unmarshal(req.GetValue(), &reqDTO); err != nil {
+		return status.Error(codes.InvalidArgument, "bad request data: "+err.Error())
+	}
+
+	err = s.queryService.QueryDataStream(ctx, user, false, reqDTO, func(responses backend.Responses) error {
+		msg, err := backend.ToProto().QueryDataResponse(&backend.QueryDataResponse{Responses: responses})
+		if err != nil {
+			return err
+		}
+		return stream.Send(msg)
+	})
+	if err != nil {
+		s.log.FromContext(ctx).Debug("Failed to stream query responses", "error", err)
+		return toStatus(err)
+	}
+	return nil
+}
+
+// toStatus converts the errors of the query service to the gRPC status matching the status code of the
+// /api/ds/query HTTP API.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
+		return status.Error(codes.PermissionDenied, "access denied to data source")
+	}
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return status.Error(codes.NotFound, "data source not found")
+	}
+
+	var gfErr errutil.Error
+	if !errors.As(err, &gfErr) {
+		return status.Error(codes.Internal, "query data error")
+	}
+	msg := gfErr.Public().Message
+	switch gfErr.Reason.Status() {
+	case errutil.StatusBadRequest, errutil.StatusValidationFailed, errutil.StatusUnprocessableEntity:
+		return status.Error(codes.InvalidArgument, msg)
+	case errutil.StatusUnauthorized:
+		return status.Error(codes.Unauthenticated, msg)
+	case errutil.StatusForbidden:
+		return status.Error(codes.PermissionDenied, msg)
+	case errutil.StatusNotFound:
+		return status.Error(codes.NotFound, msg)
+	case errutil.StatusTooManyRequests:
+		return status.Error(codes.ResourceExhausted, msg)
+	case errutil.StatusTimeout, errutil.StatusGatewayTimeout:
+		return status.Error(codes.DeadlineExceeded, msg)
+	case errutil.StatusClientClosedRequest:
+		return status.Error(codes.Canceled, msg)
+	default:
+		return status.Error(codes.Internal, msg)
+	}
+}
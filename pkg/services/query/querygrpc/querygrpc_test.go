@@ -0,0 +1,88 @@
+package querygrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/genproto/pluginv2"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pluginv2.QueryDataResponse
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) Send(m *pluginv2.QueryDataResponse) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestQueryDataStream(t *testing.T) {
+	contextHandler := grpccontext.ProvideContextHandler(tracing.InitializeTracerForTest())
+	signedIn := contextHandler.SetUser(context.Background(), &user.SignedInUser{UserID: 1, OrgID: 1})
+	body := wrapperspb.Bytes([]byte(`{"queries": [{"refId": "A", "datasource": {"uid": "a"}}, {"refId": "B", "datasource": {"uid": "b"}}]}`))
+
+	newService := func(queryService query.Service, canQuery bool) *Service {
+		return &Service{
+			queryService:   queryService,
+			accessControl:  &actest.FakeAccessControl{ExpectedEvaluate: canQuery},
+			contextHandler: contextHandler,
+			log:            log.NewNopLogger(),
+		}
+	}
+
+	t.Run("sends the responses of each data source in a message", func(t *testing.T) {
+		queryService := query.NewFakeQueryService(t)
+		queryService.On("QueryDataStream", mock.Anything, mock.Anything, false, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				send := args.Get(4).(query.StreamFunc)
+				require.NoError(t, send(backend.Responses{"A": backend.DataResponse{}}))
+				require.NoError(t, send(backend.Responses{"B": backend.DataResponse{}}))
+			}).Return(nil)
+
+		stream := &fakeStream{ctx: signedIn}
+		require.NoError(t, newService(queryService, true).QueryDataStream(body, stream))
+		require.Len(t, stream.sent, 2)
+		require.Contains(t, stream.sent[0].Responses, "A")
+		require.Contains(t, stream.sent[1].Responses, "B")
+	})
+
+	t.Run("requires the permission to query data sources", func(t *testing.T) {
+		err := newService(query.NewFakeQueryService(t), false).QueryDataStream(body, &fakeStream{ctx: signedIn})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		err := newService(query.NewFakeQueryService(t), true).QueryDataStream(wrapperspb.Bytes([]byte("{")), &fakeStream{ctx: signedIn})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("maps the errors of the query service", func(t *testing.T) {
+		queryService := query.NewFakeQueryService(t)
+		queryService.On("QueryDataStream", mock.Anything, mock.Anything, false, mock.Anything, mock.Anything).
+			Return(datasources.ErrDataSourceNotFound)
+
+		err := newService(queryService, true).QueryDataStream(body, &fakeStream{ctx: signedIn})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
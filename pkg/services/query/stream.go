@@ -0,0 +1,165 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+// StreamFunc receives the responses of the queries of one datasource as soon as they complete.
+// It is never called concurrently. Returning an error cancels the queries that are still running.
+type StreamFunc func(responses backend.Responses) error
+
+// QueryDataStream processes queries like QueryData, but instead of waiting for all datasources to respond
+// it hands the responses of each datasource to send as soon as they are available. Requests with
+// expressions and requests to a single datasource are sent in one piece.
+func (s *ServiceImpl) QueryDataStream(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, send StreamFunc) error {
+	limit := s.queryLimit(user)
+	ctx, cancel := withQueryTimeout(ctx, limit)
+	defer cancel()
+
+	budget := newResultBudget(limit)
+	sent := map[string]bool{}
+	err := s.queryDataStream(ctx, user, skipDSCache, reqDTO, func(responses backend.Responses) error {
+		for refID := range responses {
+			sent[refID] = true
+		}
+		markTimedOut(ctx, limit, responses)
+		budget.apply(responses)
+		annotateErrors(responses)
+		return send(responses)
+	})
+	if !queryTimedOut(ctx) {
+		return err
+	}
+	if err != nil {
+		return queryTimeoutError(limit)
+	}
+
+	// The responses of the datasources still running at the timeout are dropped, report them as timed out.
+	missing := backend.Responses{}
+	for _, query := range reqDTO.Queries {
+		if refID := query.Get("refId").MustString("A"); !sent[refID] {
+			missing[refID] = backend.DataResponse{Error: queryTimeoutError(limit), Status: backend.StatusTimeout}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	annotateErrors(missing)
+	return send(missing)
+}
+
+func (s *ServiceImpl) queryDataStream(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, send StreamFunc) error {
+	parsedReq, err := s.parseMetricRequest(ctx, user, skipDSCache, reqDTO)
+	if err != nil {
+		return err
+	}
+
+	// Queries whose datasource could not be resolved fail on their own, the others still run
+	if len(parsedReq.failedQueries) > 0 {
+		failed := backend.Responses{}
+		for refID, queryErr := range parsedReq.failedQueries {
+			failed[refID] = errorResponse(queryErr)
+		}
+		if err := send(failed); err != nil {
+			return err
+		}
+	}
+
+	if parsedReq.hasExpression || len(parsedReq.parsedQueries) == 1 {
+		var resp *backend.QueryDataResponse
+		if parsedReq.hasExpression {
+			resp, err = s.handleExpressions(ctx, user, parsedReq)
+		} else {
+			resp, err = s.handleQuerySingleDatasource(ctx, user, parsedReq)
+		}
+		if err != nil {
+			if len(parsedReq.failedQueries) == 0 {
+				return err
+			}
+			return send(buildErrorResponses(err, parsedReq.getRawQueries()).responses)
+		}
+		return send(resp.Responses)
+	}
+
+	return s.streamConcurrentQueries(ctx, user, skipDSCache, reqDTO, parsedReq.parsedQueries, send)
+}
+
+// streamConcurrentQueries executes queries to multiple datasources concurrently and sends the result of each datasource once it completes.
+func (s *ServiceImpl) streamConcurrentQueries(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, queriesbyDs map[string][]parsedQuery, send StreamFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.concurrentQueryLimit) // prevent too many concurrent requests
+	rchan := make(chan backend.Responses)
+
+	publish := func(responses backend.Responses) {
+		select {
+		case rchan <- responses:
+		case <-gctx.Done():
+		}
+	}
+
+	recoveryFn := func(queries []*simplejson.Json) {
+		if r := recover(); r != nil {
+			var err error
+			s.log.Error("query datasource panic", "error", r, "stack", log.Stack(1))
+			if theErr, ok := r.(error); ok {
+				err = theErr
+			} else if theErrString, ok := r.(string); ok {
+				err = errors.New(theErrString)
+			} else {
+				err = fmt.Errorf("unexpected error - %s", s.cfg.UserFacingDefaultError)
+			}
+			publish(buildErrorResponses(err, queries).responses)
+		}
+	}
+
+	go func() {
+		for _, queries := range queriesbyDs {
+			rawQueries := make([]*simplejson.Json, len(queries))
+			for i := 0; i < len(queries); i++ {
+				rawQueries[i] = queries[i].rawQuery
+			}
+			g.Go(func() error {
+				subDTO := reqDTO.CloneWithQueries(rawQueries)
+				defer recoveryFn(subDTO.Queries)
+
+				ctxCopy := contexthandler.CopyWithReqContext(gctx)
+				subResp, err := s.queryData(ctxCopy, user, skipDSCache, subDTO)
+				if err != nil {
+					// If there was an error, return an error response for each query for this datasource
+					publish(buildErrorResponses(err, subDTO.Queries).responses)
+					return nil
+				}
+				publish(subResp.Responses)
+				return nil
+			})
+		}
+		_ = g.Wait()
+		close(rchan)
+	}()
+
+	var sendErr error
+	for responses := range rchan {
+		if sendErr != nil {
+			continue
+		}
+		if sendErr = send(responses); sendErr != nil {
+			// Stop the remaining queries, the consumer is gone.
+			cancel()
+		}
+	}
+	return sendErr
+}
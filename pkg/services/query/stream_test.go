@@ -0,0 +1,64 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestQueryDataStream(t *testing.T) {
+	newRequest := func(t *testing.T, failB bool) dtos.MetricRequest {
+		query1, err := simplejson.NewJson([]byte(`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A"}`))
+		require.NoError(t, err)
+		query2, err := simplejson.NewJson([]byte(`{"datasource": {"type": "mysql", "uid": "ds2"}, "refId": "B"}`))
+		require.NoError(t, err)
+		if failB {
+			query2.Set("queryType", "FAIL")
+		}
+		return dtos.MetricRequest{
+			From:    "2022-01-01",
+			To:      "2022-01-02",
+			Queries: []*simplejson.Json{query1, query2},
+		}
+	}
+
+	t.Run("sends the responses of each datasource separately", func(t *testing.T) {
+		tc := setup(t)
+
+		var chunks []backend.Responses
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, true, newRequest(t, true), func(responses backend.Responses) error {
+			chunks = append(chunks, responses)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+
+		var failed int
+		for _, chunk := range chunks {
+			if resp, ok := chunk["B"]; ok {
+				require.Error(t, resp.Error)
+				failed++
+			}
+		}
+		require.Equal(t, 1, failed)
+	})
+
+	t.Run("returns the error of send", func(t *testing.T) {
+		tc := setup(t)
+
+		sendErr := errors.New("client went away")
+		calls := 0
+		err := tc.queryService.QueryDataStream(context.Background(), tc.signedInUser, true, newRequest(t, false), func(responses backend.Responses) error {
+			calls++
+			return sendErr
+		})
+		require.ErrorIs(t, err, sendErr)
+		require.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/query")
+
+// startPanelQuerySpans starts a span for the queries of a dashboard panel to a datasource, with a child span per
+// refID, so that a slow panel can be found in the trace of the dashboard. Queries that do not come from a dashboard
+// are not traced here. The returned context carries the datasource span and must be used for the datasource request,
+// and the returned function ends the spans with the result of the request.
+func startPanelQuerySpans(ctx context.Context, ds *datasources.DataSource, queries []backend.DataQuery) (context.Context, func(*backend.QueryDataResponse, error)) {
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.Req == nil {
+		return ctx, func(*backend.QueryDataResponse, error) {}
+	}
+	dashboardUID := reqCtx.Req.Header.Get(HeaderDashboardUID)
+	if dashboardUID == "" {
+		return ctx, func(*backend.QueryDataResponse, error) {}
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("dashboard_uid", dashboardUID),
+		attribute.String("datasource_uid", ds.UID),
+		attribute.String("datasource_type", ds.Type),
+	}
+	if panelID := reqCtx.Req.Header.Get(HeaderPanelID); panelID != "" {
+		attrs = append(attrs, attribute.String("panel_id", panelID))
+	}
+	if panelPluginID := reqCtx.Req.Header.Get(HeaderPanelPluginId); panelPluginID != "" {
+		attrs = append(attrs, attribute.String("panel_plugin_id", panelPluginID))
+	}
+
+	ctx, dsSpan := tracer.Start(ctx, "QueryService.queryDatasource", trace.WithAttributes(attrs...))
+	spans := make(map[string]trace.Span, len(queries))
+	for _, q := range queries {
+		_, span := tracer.Start(ctx, "QueryService.query", trace.WithAttributes(attrs...), trace.WithAttributes(
+			attribute.String("ref_id", q.RefID),
+			attribute.String("query_type", q.QueryType),
+			attribute.Int64("max_data_points", q.MaxDataPoints),
+			attribute.Int64("interval_ms", q.Interval.Milliseconds()),
+		))
+		spans[q.RefID] = span
+	}
+
+	return ctx, func(resp *backend.QueryDataResponse, err error) {
+		for refID, span := range spans {
+			switch {
+			case err != nil:
+				_ = tracing.Error(span, err)
+			case resp != nil:
+				res, ok := resp.Responses[refID]
+				if !ok {
+					break
+				}
+				if res.Error != nil {
+					_ = tracing.Error(span, res.Error)
+					break
+				}
+				span.SetAttributes(attribute.Int("frames", len(res.Frames)))
+			}
+			span.End()
+		}
+		if err != nil {
+			_ = tracing.Error(dsSpan, err)
+		}
+		dsSpan.End()
+	}
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestStartPanelQuerySpans(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+
+	ds := &datasources.DataSource{UID: "loki-uid", Type: "loki"}
+	queries := []backend.DataQuery{{RefID: "A"}, {RefID: "B"}}
+
+	newContext := func(t *testing.T, headers map[string]string) context.Context {
+		req, err := http.NewRequest(http.MethodPost, "/api/ds/query", nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		reqCtx := &contextmodel.ReqContext{Context: &web.Context{Req: req}}
+		return ctxkey.Set(context.Background(), reqCtx)
+	}
+
+	t.Run("does not trace queries outside of dashboards", func(t *testing.T) {
+		spanRecorder.Reset()
+		_, end := startPanelQuerySpans(newContext(t, nil), ds, queries)
+		end(&backend.QueryDataResponse{}, nil)
+		require.Empty(t, spanRecorder.Ended())
+	})
+
+	t.Run("traces each query of a dashboard panel", func(t *testing.T) {
+		spanRecorder.Reset()
+		ctx, end := startPanelQuerySpans(newContext(t, map[string]string{
+			HeaderDashboardUID: "dash-uid",
+			HeaderPanelID:      "4",
+		}), ds, queries)
+		end(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {},
+			"B": {Error: errors.New("parse error")},
+		}}, nil)
+
+		spans := spanRecorder.Ended()
+		require.Len(t, spans, 3)
+
+		dsSpan := spans[2]
+		require.Equal(t, "QueryService.queryDatasource", dsSpan.Name())
+		require.Contains(t, dsSpan.Attributes(), attribute.String("dashboard_uid", "dash-uid"))
+		require.Contains(t, dsSpan.Attributes(), attribute.String("panel_id", "4"))
+		require.Contains(t, dsSpan.Attributes(), attribute.String("datasource_uid", "loki-uid"))
+
+		byRefID := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range spans[:2] {
+			require.Equal(t, "QueryService.query", span.Name())
+			require.Equal(t, dsSpan.SpanContext().SpanID(), span.Parent().SpanID())
+			for _, attr := range span.Attributes() {
+				if attr.Key == "ref_id" {
+					byRefID[attr.Value.AsString()] = span
+				}
+			}
+		}
+		require.Contains(t, byRefID["A"].Attributes(), attribute.Int("frames", 0))
+		require.Len(t, byRefID["B"].Events(), 1)
+
+		// the datasource request is sent in the span of the panel queries
+		require.Equal(t, dsSpan.SpanContext().SpanID(), trace.SpanFromContext(ctx).SpanContext().SpanID())
+	})
+}
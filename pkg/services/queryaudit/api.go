@@ -0,0 +1,78 @@
+package queryaudit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/query-audit", func(entities routing.RouteRegister) {
+		entities.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.searchHandler))
+		entities.Get("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.getHandler))
+		entities.Post("/:uid/replay", middleware.ReqOrgAdmin, routing.Wrap(s.replayHandler))
+	})
+}
+
+func (s *Service) searchHandler(c *contextmodel.ReqContext) response.Response {
+	entries, err := s.search(c.Req.Context(), SearchQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		UserUID:       c.Query("userUid"),
+		DatasourceUID: c.Query("datasourceUid"),
+		From:          c.QueryInt64("from"),
+		To:            c.QueryInt64("to"),
+		MinDurationMs: c.QueryInt64("minDurationMs"),
+		Limit:         c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search query audit log", err)
+	}
+	return response.JSON(http.StatusOK, entries)
+}
+
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	entry, err := s.get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, ErrEntryNotFound) {
+			return response.Error(http.StatusNotFound, "Query audit entry not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get query audit entry", err)
+	}
+	return response.JSON(http.StatusOK, entry)
+}
+
+// replayHandler executes a recorded query again, with the permissions of the signed in user.
+func (s *Service) replayHandler(c *contextmodel.ReqContext) response.Response {
+	entry, err := s.get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, ErrEntryNotFound) {
+			return response.Error(http.StatusNotFound, "Query audit entry not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get query audit entry", err)
+	}
+
+	reqDTO := dtos.MetricRequest{}
+	if err := json.Unmarshal([]byte(entry.Request), &reqDTO); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to read recorded query", err)
+	}
+
+	start := time.Now()
+	// Skip the data source cache so the replay reflects the current configuration.
+	resp, err := s.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, true, reqDTO)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to replay query", err)
+	}
+	return response.JSON(http.StatusOK, ReplayResult{
+		DurationMs:         time.Since(start).Milliseconds(),
+		OriginalDurationMs: entry.DurationMs,
+		Response:           resp,
+	})
+}
@@ -0,0 +1,53 @@
+package queryaudit
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrEntryNotFound = errors.New("query audit entry not found")
+
+// Entry is one recorded execution of /api/ds/query.
+type Entry struct {
+	ID             int64    `xorm:"pk autoincr 'id'" json:"-"`
+	UID            string   `xorm:"uid" json:"uid"`
+	OrgID          int64    `xorm:"org_id" json:"orgId"`
+	UserUID        string   `xorm:"user_uid" json:"userUid"`
+	UserLogin      string   `xorm:"user_login" json:"userLogin"`
+	DatasourceUIDs []string `xorm:"datasource_uids" json:"datasourceUids"`
+	// Request is the raw JSON of the metric request, used to replay the query.
+	Request    string `xorm:"request" json:"request"`
+	DurationMs int64  `xorm:"duration_ms" json:"durationMs"`
+	Bytes      int64  `xorm:"bytes" json:"bytes"`
+	Status     int    `xorm:"status" json:"status"`
+	CreatedAt  int64  `xorm:"created_at" json:"createdAt"`
+}
+
+func (Entry) TableName() string {
+	return "query_audit"
+}
+
+// Result describes the outcome of a query execution.
+type Result struct {
+	Duration time.Duration
+	Bytes    int
+	Status   int
+}
+
+type SearchQuery struct {
+	OrgID         int64
+	UserUID       string
+	DatasourceUID string
+	// From and To limit the search to entries created in the time range, in unix seconds.
+	From int64
+	To   int64
+	// MinDurationMs limits the search to queries that took at least that long.
+	MinDurationMs int64
+	Limit         int
+}
+
+type ReplayResult struct {
+	DurationMs         int64 `json:"durationMs"`
+	OriginalDurationMs int64 `json:"originalDurationMs"`
+	Response           any   `json:"response"`
+}
@@ -0,0 +1,141 @@
+package queryaudit
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	recordTimeout   = 10 * time.Second
+	cleanupInterval = time.Hour
+)
+
+type Service struct {
+	store            db.DB
+	settings         setting.QueryAuditSettings
+	queryDataService query.Service
+	log              log.Logger
+	now              func() time.Time
+	sample           func() float64
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, queryDataService query.Service) *Service {
+	s := &Service{
+		store:            sqlStore,
+		settings:         cfg.QueryAudit,
+		queryDataService: queryDataService,
+		log:              log.New("query-audit"),
+		now:              time.Now,
+		sample:           rand.Float64,
+	}
+
+	if s.settings.Enabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+// Enabled returns true if queries are recorded.
+func (s *Service) Enabled() bool {
+	return s != nil && s.settings.Enabled
+}
+
+// Record stores the execution of a query request if it is selected by the sampling settings.
+// The entry is written in the background so that recording does not delay the response.
+func (s *Service) Record(ctx context.Context, user identity.Requester, req dtos.MetricRequest, result Result) {
+	if !s.Enabled() || !s.shouldRecord(result) {
+		return
+	}
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to marshal query request", "error", err)
+		return
+	}
+
+	entry := &Entry{
+		UID:            util.GenerateShortUID(),
+		OrgID:          user.GetOrgID(),
+		UserUID:        user.GetUID(),
+		UserLogin:      user.GetLogin(),
+		DatasourceUIDs: datasourceUIDs(req),
+		Request:        string(raw),
+		DurationMs:     result.Duration.Milliseconds(),
+		Bytes:          int64(result.Bytes),
+		Status:         result.Status,
+		CreatedAt:      s.now().Unix(),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+		defer cancel()
+		if err := s.insert(ctx, entry); err != nil {
+			s.log.FromContext(ctx).Error("Failed to record query", "error", err)
+		}
+	}()
+}
+
+func (s *Service) shouldRecord(result Result) bool {
+	if s.settings.RecordErrors && result.Status >= http.StatusBadRequest {
+		return true
+	}
+	if s.settings.SlowQueryThreshold > 0 && result.Duration >= s.settings.SlowQueryThreshold {
+		return true
+	}
+	return s.sample() < s.settings.SampleRate
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Enabled()
+}
+
+// Run deletes the entries older than the retention period.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			olderThan := s.now().Add(-s.settings.Retention).Unix()
+			deleted, err := s.deleteOlderThan(ctx, olderThan)
+			if err != nil {
+				s.log.Error("Failed to delete expired query audit entries", "error", err)
+				continue
+			}
+			s.log.Debug("Deleted expired query audit entries", "count", deleted)
+		}
+	}
+}
+
+func datasourceUIDs(req dtos.MetricRequest) []string {
+	seen := map[string]struct{}{}
+	uids := make([]string, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		uid := q.Get("datasource").Get("uid").MustString()
+		if uid == "" {
+			continue
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids
+}
@@ -0,0 +1,90 @@
+package queryaudit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestShouldRecord(t *testing.T) {
+	s := &Service{
+		settings: setting.QueryAuditSettings{
+			Enabled:            true,
+			SampleRate:         0.1,
+			SlowQueryThreshold: time.Second,
+			RecordErrors:       true,
+		},
+	}
+
+	s.sample = func() float64 { return 0.5 }
+	require.False(t, s.shouldRecord(Result{Duration: time.Millisecond, Status: http.StatusOK}))
+	require.True(t, s.shouldRecord(Result{Duration: 2 * time.Second, Status: http.StatusOK}), "slow queries are always recorded")
+	require.True(t, s.shouldRecord(Result{Duration: time.Millisecond, Status: http.StatusBadRequest}), "failed queries are always recorded")
+
+	s.sample = func() float64 { return 0.05 }
+	require.True(t, s.shouldRecord(Result{Duration: time.Millisecond, Status: http.StatusOK}))
+}
+
+func TestDatasourceUIDs(t *testing.T) {
+	req := dtos.MetricRequest{Queries: []*simplejson.Json{
+		simplejson.NewFromAny(map[string]any{"refId": "A", "datasource": map[string]any{"uid": "b"}}),
+		simplejson.NewFromAny(map[string]any{"refId": "B", "datasource": map[string]any{"uid": "a"}}),
+		simplejson.NewFromAny(map[string]any{"refId": "C", "datasource": map[string]any{"uid": "b"}}),
+		simplejson.NewFromAny(map[string]any{"refId": "D"}),
+	}}
+	require.Equal(t, []string{"a", "b"}, datasourceUIDs(req))
+}
+
+func TestIntegrationQueryAuditStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Unix(1700000000, 0)
+	s := &Service{
+		store: db.InitTestDB(t),
+		log:   log.NewNopLogger(),
+		now:   func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	entries := []*Entry{
+		{UID: "old", OrgID: 1, UserUID: "u1", DatasourceUIDs: []string{"prom"}, Request: "{}", DurationMs: 10, Status: 200, CreatedAt: now.Add(-48 * time.Hour).Unix()},
+		{UID: "slow", OrgID: 1, UserUID: "u1", DatasourceUIDs: []string{"loki", "prom"}, Request: "{}", DurationMs: 5000, Status: 200, CreatedAt: now.Unix()},
+		{UID: "other-org", OrgID: 2, UserUID: "u2", DatasourceUIDs: []string{"prom"}, Request: "{}", DurationMs: 10, Status: 200, CreatedAt: now.Unix()},
+	}
+	for _, e := range entries {
+		require.NoError(t, s.insert(ctx, e))
+	}
+
+	found, err := s.search(ctx, SearchQuery{OrgID: 1, DatasourceUID: "prom"})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, "slow", found[0].UID)
+	require.Equal(t, []string{"loki", "prom"}, found[0].DatasourceUIDs)
+
+	found, err = s.search(ctx, SearchQuery{OrgID: 1, MinDurationMs: 1000})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	_, err = s.get(ctx, 1, "other-org")
+	require.ErrorIs(t, err, ErrEntryNotFound)
+
+	deleted, err := s.deleteOlderThan(ctx, now.Add(-24*time.Hour).Unix())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
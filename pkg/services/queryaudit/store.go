@@ -0,0 +1,75 @@
+package queryaudit
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+const defaultSearchLimit = 100
+
+func (s *Service) insert(ctx context.Context, entry *Entry) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+func (s *Service) search(ctx context.Context, query SearchQuery) ([]*Entry, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+
+	entries := make([]*Entry, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("query_audit").Where("org_id = ?", query.OrgID)
+		if query.UserUID != "" {
+			q = q.And("user_uid = ?", query.UserUID)
+		}
+		if query.DatasourceUID != "" {
+			q = q.And("datasource_uids LIKE ?", "%\""+query.DatasourceUID+"\"%")
+		}
+		if query.From > 0 {
+			q = q.And("created_at >= ?", query.From)
+		}
+		if query.To > 0 {
+			q = q.And("created_at <= ?", query.To)
+		}
+		if query.MinDurationMs > 0 {
+			q = q.And("duration_ms >= ?", query.MinDurationMs)
+		}
+		return q.Desc("created_at").Limit(query.Limit).Find(&entries)
+	})
+	return entries, err
+}
+
+func (s *Service) get(ctx context.Context, orgID int64, uid string) (*Entry, error) {
+	entry := &Entry{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(entry)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrEntryNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Service) deleteOlderThan(ctx context.Context, olderThan int64) (int64, error) {
+	var deleted int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM query_audit WHERE created_at < ?", olderThan)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,58 @@
+package querycost
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+
+	routeRegister.Post("/api/ds/query/estimate", authorize(ac.EvalPermission(datasources.ActionQuery)), routing.Wrap(s.estimateHandler))
+	routeRegister.Group("/api/org/query-cost-policy", func(policy routing.RouteRegister) {
+		policy.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.getPolicyHandler))
+		policy.Put("/", middleware.ReqOrgAdmin, routing.Wrap(s.updatePolicyHandler))
+	})
+}
+
+// estimateHandler returns the estimated cost of a query request without executing it.
+func (s *Service) estimateHandler(c *contextmodel.ReqContext) response.Response {
+	reqDTO := dtos.MetricRequest{}
+	if err := web.Bind(c.Req, &reqDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := s.Estimate(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to estimate query cost", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) getPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.GetPolicy(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query cost policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) updatePolicyHandler(c *contextmodel.ReqContext) response.Response {
+	policy := Policy{}
+	if err := web.Bind(c.Req, &policy); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetPolicy(c.Req.Context(), c.SignedInUser.GetOrgID(), policy); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update query cost policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,94 @@
+package querycost
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrCostLimitExceeded = errutil.BadRequest("querycost.limitExceeded").MustTemplate(
+		"estimated {{ .Public.Unit }} of the query ({{ .Public.Estimate }}) is above the limit of the organization ({{ .Public.Limit }})",
+		errutil.WithPublic("The estimated {{ .Public.Unit }} of the query ({{ .Public.Estimate }}) is above the limit of the organization ({{ .Public.Limit }})"),
+	)
+	ErrInvalidPolicy = errutil.BadRequest("querycost.invalidPolicy").MustTemplate(
+		"invalid query cost policy: {{ .Public.Reason }}",
+		errutil.WithPublic("Invalid query cost policy: {{ .Public.Reason }}"),
+	)
+)
+
+// Policy is the query cost policy of an organization.
+type Policy struct {
+	// Enforce rejects queries with an estimate above one of the limits
+	Enforce bool `json:"enforce"`
+	// MaxBytes is the maximum number of bytes a request may scan, zero means no limit
+	MaxBytes int64 `json:"maxBytes"`
+	// MaxSamples is the maximum number of samples a request may return, zero means no limit
+	MaxSamples int64 `json:"maxSamples"`
+}
+
+func (p Policy) enforced() bool {
+	return p.Enforce && (p.MaxBytes > 0 || p.MaxSamples > 0)
+}
+
+// Check returns ErrCostLimitExceeded if the result is above one of the limits of the policy.
+func (p Policy) Check(r *Result) error {
+	if p.MaxBytes > 0 && r.TotalBytes > p.MaxBytes {
+		return ErrCostLimitExceeded.Build(errutil.TemplateData{
+			Public: map[string]any{"Unit": "bytes", "Estimate": r.TotalBytes, "Limit": p.MaxBytes},
+		})
+	}
+	if p.MaxSamples > 0 && r.TotalSamples > p.MaxSamples {
+		return ErrCostLimitExceeded.Build(errutil.TemplateData{
+			Public: map[string]any{"Unit": "samples", "Estimate": r.TotalSamples, "Limit": p.MaxSamples},
+		})
+	}
+	return nil
+}
+
+// Estimate is the estimated cost of a single query.
+type Estimate struct {
+	RefID         string `json:"refId"`
+	DatasourceUID string `json:"datasourceUid"`
+	// Supported is false if the data source cannot estimate the cost of its queries
+	Supported bool   `json:"supported"`
+	Bytes     int64  `json:"bytes,omitempty"`
+	Samples   int64  `json:"samples,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Result is the estimated cost of a query request.
+type Result struct {
+	Estimates    []Estimate `json:"estimates"`
+	TotalBytes   int64      `json:"totalBytes"`
+	TotalSamples int64      `json:"totalSamples"`
+	// Allowed is false if the request would be rejected by the policy of the organization
+	Allowed bool   `json:"allowed"`
+	Policy  Policy `json:"policy"`
+}
+
+// pluginEstimateRequest is the body of the query/estimate resource call of a plugin.
+type pluginEstimateRequest struct {
+	Queries []pluginEstimateQuery `json:"queries"`
+}
+
+type pluginEstimateQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType,omitempty"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	IntervalMS    int64           `json:"intervalMs"`
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Model         json.RawMessage `json:"model"`
+}
+
+// pluginEstimateResponse is the body of the response of the query/estimate resource call of a plugin.
+type pluginEstimateResponse struct {
+	Estimates []struct {
+		RefID   string `json:"refId"`
+		Bytes   int64  `json:"bytes"`
+		Samples int64  `json:"samples"`
+		Error   string `json:"error"`
+	} `json:"estimates"`
+}
@@ -0,0 +1,281 @@
+package querycost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+const (
+	kvNamespace = "query-cost"
+	kvPolicyKey = "policy"
+
+	// estimateResourcePath is the resource plugins with the queryCostEstimation capability handle.
+	estimateResourcePath = "query/estimate"
+
+	policyCacheTTL = time.Minute
+)
+
+type pluginContextProvider interface {
+	GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error)
+}
+
+type resourceCaller interface {
+	CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error
+}
+
+// Service estimates the cost of queries before they are executed and enforces the query cost policy of organizations.
+type Service struct {
+	dataSourceCache       datasources.CacheService
+	pluginStore           pluginstore.Store
+	pluginContextProvider pluginContextProvider
+	pluginClient          resourceCaller
+	kvStore               kvstore.KVStore
+	accessControl         ac.AccessControl
+	policies              *localcache.CacheService
+	log                   log.Logger
+}
+
+func ProvideService(dataSourceCache datasources.CacheService, pluginStore pluginstore.Store, pluginContextProvider *plugincontext.Provider,
+	pluginClient plugins.Client, kvStore kvstore.KVStore, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+) *Service {
+	s := &Service{
+		dataSourceCache:       dataSourceCache,
+		pluginStore:           pluginStore,
+		pluginContextProvider: pluginContextProvider,
+		pluginClient:          pluginClient,
+		kvStore:               kvStore,
+		accessControl:         accessControl,
+		policies:              localcache.New(policyCacheTTL, 2*policyCacheTTL),
+		log:                   log.New("query-cost"),
+	}
+
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Estimate asks the data sources of the request for the cost of their queries. Queries of data sources
+// that cannot estimate their cost are returned as not supported and do not count towards the totals.
+func (s *Service) Estimate(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*Result, error) {
+	timeRange := gtime.NewTimeRange(reqDTO.From, reqDTO.To)
+	from, to := timeRange.GetFromAsTimeUTC(), timeRange.GetToAsTimeUTC()
+
+	// Group the queries by data source, keeping the order of the request
+	var order []string
+	dataSources := map[string]*datasources.DataSource{}
+	queriesByDS := map[string][]*simplejson.Json{}
+	for _, query := range reqDTO.Queries {
+		uid := query.Get("datasource").Get("uid").MustString()
+		if uid == "" || uid == grafanads.DatasourceUID || expr.NodeTypeFromDatasourceUID(uid) != expr.TypeDatasourceNode {
+			continue
+		}
+		if _, ok := dataSources[uid]; !ok {
+			ds, err := s.dataSourceCache.GetDatasourceByUID(ctx, uid, user, skipDSCache)
+			if err != nil {
+				return nil, err
+			}
+			dataSources[uid] = ds
+			order = append(order, uid)
+		}
+		queriesByDS[uid] = append(queriesByDS[uid], query)
+	}
+
+	policy, err := s.GetPolicy(ctx, user.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Estimates: make([]Estimate, 0, len(reqDTO.Queries)), Allowed: true, Policy: policy}
+	for _, uid := range order {
+		estimates := s.estimateDataSource(ctx, user, dataSources[uid], queriesByDS[uid], from, to)
+		for _, e := range estimates {
+			result.TotalBytes += e.Bytes
+			result.TotalSamples += e.Samples
+		}
+		result.Estimates = append(result.Estimates, estimates...)
+	}
+
+	if policy.enforced() && policy.Check(result) != nil {
+		result.Allowed = false
+	}
+	return result, nil
+}
+
+// Enforce rejects the request with ErrCostLimitExceeded if the organization has a query cost policy and the
+// estimated cost of the request is above one of its limits. Failures to estimate the cost do not reject the request.
+func (s *Service) Enforce(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) error {
+	if s == nil {
+		return nil
+	}
+	logger := s.log.FromContext(ctx)
+
+	policy, err := s.GetPolicy(ctx, user.GetOrgID())
+	if err != nil {
+		logger.Warn("Failed to get query cost policy", "error", err)
+		return nil
+	}
+	if !policy.enforced() {
+		return nil
+	}
+
+	result, err := s.Estimate(ctx, user, skipDSCache, reqDTO)
+	if err != nil {
+		logger.Warn("Failed to estimate query cost", "error", err)
+		return nil
+	}
+	if err := policy.Check(result); err != nil {
+		logger.Info("Rejected query above the query cost policy", "bytes", result.TotalBytes, "samples", result.TotalSamples)
+		return err
+	}
+	return nil
+}
+
+// GetPolicy returns the query cost policy of an organization.
+func (s *Service) GetPolicy(ctx context.Context, orgID int64) (Policy, error) {
+	cacheKey := strconv.FormatInt(orgID, 10)
+	if cached, ok := s.policies.Get(cacheKey); ok {
+		return cached.(Policy), nil
+	}
+
+	policy := Policy{}
+	raw, exists, err := kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Get(ctx, kvPolicyKey)
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to get query cost policy: %w", err)
+	}
+	if exists {
+		if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+			return Policy{}, fmt.Errorf("failed to parse query cost policy: %w", err)
+		}
+	}
+	s.policies.Set(cacheKey, policy, policyCacheTTL)
+	return policy, nil
+}
+
+// SetPolicy stores the query cost policy of an organization.
+func (s *Service) SetPolicy(ctx context.Context, orgID int64, policy Policy) error {
+	if policy.MaxBytes < 0 || policy.MaxSamples < 0 {
+		return ErrInvalidPolicy.Build(errutil.TemplateData{Public: map[string]any{"Reason": "limits must not be negative"}})
+	}
+	if policy.Enforce && policy.MaxBytes == 0 && policy.MaxSamples == 0 {
+		return ErrInvalidPolicy.Build(errutil.TemplateData{Public: map[string]any{"Reason": "an enforced policy needs at least one limit"}})
+	}
+
+	raw, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	if err := kvstore.WithNamespace(s.kvStore, orgID, kvNamespace).Set(ctx, kvPolicyKey, string(raw)); err != nil {
+		return fmt.Errorf("failed to store query cost policy: %w", err)
+	}
+	s.policies.Set(strconv.FormatInt(orgID, 10), policy, policyCacheTTL)
+	return nil
+}
+
+// estimateDataSource asks a data source for the cost of its queries.
+func (s *Service) estimateDataSource(ctx context.Context, user identity.Requester, ds *datasources.DataSource, queries []*simplejson.Json, from, to time.Time) []Estimate {
+	estimates := make([]Estimate, 0, len(queries))
+	byRefID := make(map[string]int, len(queries))
+	pluginReq := pluginEstimateRequest{Queries: make([]pluginEstimateQuery, 0, len(queries))}
+	for _, query := range queries {
+		refID := query.Get("refId").MustString("A")
+		byRefID[refID] = len(estimates)
+		estimates = append(estimates, Estimate{RefID: refID, DatasourceUID: ds.UID})
+
+		model, err := query.MarshalJSON()
+		if err != nil {
+			estimates[len(estimates)-1].Error = err.Error()
+			continue
+		}
+		pluginReq.Queries = append(pluginReq.Queries, pluginEstimateQuery{
+			RefID:         refID,
+			QueryType:     query.Get("queryType").MustString(""),
+			MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
+			IntervalMS:    query.Get("intervalMs").MustInt64(1000),
+			From:          from,
+			To:            to,
+			Model:         model,
+		})
+	}
+
+	if p, ok := s.pluginStore.Plugin(ctx, ds.Type); !ok || !p.QueryCostEstimation {
+		return estimates
+	}
+
+	setError := func(err error) []Estimate {
+		s.log.FromContext(ctx).Warn("Failed to estimate query cost", "datasource", ds.UID, "error", err)
+		for i := range estimates {
+			estimates[i].Supported = true
+			estimates[i].Error = err.Error()
+		}
+		return estimates
+	}
+
+	body, err := json.Marshal(pluginReq)
+	if err != nil {
+		return setError(err)
+	}
+	pCtx, err := s.pluginContextProvider.GetWithDataSource(ctx, ds.Type, user, ds)
+	if err != nil {
+		return setError(err)
+	}
+
+	var resp *backend.CallResourceResponse
+	err = s.pluginClient.CallResource(ctx, &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          estimateResourcePath,
+		Method:        http.MethodPost,
+		URL:           estimateResourcePath,
+		Headers:       map[string][]string{"Content-Type": {"application/json"}},
+		Body:          body,
+	}, backend.CallResourceResponseSenderFunc(func(res *backend.CallResourceResponse) error {
+		resp = res
+		return nil
+	}))
+	if err != nil {
+		return setError(err)
+	}
+	if resp == nil || resp.Status != http.StatusOK {
+		status := 0
+		if resp != nil {
+			status = resp.Status
+		}
+		return setError(fmt.Errorf("unexpected status %d", status))
+	}
+
+	pluginResp := pluginEstimateResponse{}
+	if err := json.Unmarshal(resp.Body, &pluginResp); err != nil {
+		return setError(fmt.Errorf("failed to parse estimate: %w", err))
+	}
+	for _, pe := range pluginResp.Estimates {
+		i, ok := byRefID[pe.RefID]
+		if !ok {
+			continue
+		}
+		estimates[i].Supported = true
+		estimates[i].Bytes = pe.Bytes
+		estimates[i].Samples = pe.Samples
+		estimates[i].Error = pe.Error
+	}
+	return estimates
+}
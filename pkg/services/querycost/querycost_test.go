@@ -0,0 +1,159 @@
+package querycost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakePluginContextProvider struct{}
+
+func (fakePluginContextProvider) GetWithDataSource(_ context.Context, pluginID string, _ identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	return backend.PluginContext{
+		PluginID:                   pluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: ds.UID},
+	}, nil
+}
+
+// fakeEstimator returns the bytes of every query from its map, keyed by the refId.
+type fakeEstimator struct {
+	bytes map[string]int64
+	calls int
+	err   error
+}
+
+func (f *fakeEstimator) CallResource(_ context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	if req.Path != estimateResourcePath || req.Method != http.MethodPost {
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusNotFound})
+	}
+
+	pluginReq := pluginEstimateRequest{}
+	if err := json.Unmarshal(req.Body, &pluginReq); err != nil {
+		return err
+	}
+	resp := map[string]any{}
+	estimates := []map[string]any{}
+	for _, q := range pluginReq.Queries {
+		estimates = append(estimates, map[string]any{"refId": q.RefID, "bytes": f.bytes[q.RefID]})
+	}
+	resp["estimates"] = estimates
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{Status: http.StatusOK, Body: body})
+}
+
+func setupTestService(t *testing.T, estimator *fakeEstimator) *Service {
+	t.Helper()
+	return &Service{
+		dataSourceCache: &fakeDatasources.FakeCacheService{DataSources: []*datasources.DataSource{
+			{UID: "loki", Type: "loki"},
+			{UID: "testdata", Type: "grafana-testdata-datasource"},
+		}},
+		pluginStore: pluginstore.NewFakePluginStore(
+			pluginstore.Plugin{JSONData: plugins.JSONData{ID: "loki", QueryCostEstimation: true}},
+			pluginstore.Plugin{JSONData: plugins.JSONData{ID: "grafana-testdata-datasource"}},
+		),
+		pluginContextProvider: fakePluginContextProvider{},
+		pluginClient:          estimator,
+		kvStore:               kvstore.NewFakeKVStore(),
+		policies:              localcache.New(time.Minute, time.Minute),
+		log:                   log.NewNopLogger(),
+	}
+}
+
+func testMetricRequest() dtos.MetricRequest {
+	return dtos.MetricRequest{
+		From: "now-1h",
+		To:   "now",
+		Queries: []*simplejson.Json{
+			simplejson.NewFromAny(map[string]any{"refId": "A", "datasource": map[string]any{"uid": "loki"}, "expr": `{job="a"}`}),
+			simplejson.NewFromAny(map[string]any{"refId": "B", "datasource": map[string]any{"uid": "loki"}, "expr": `{job="b"}`}),
+			simplejson.NewFromAny(map[string]any{"refId": "C", "datasource": map[string]any{"uid": "testdata"}}),
+			simplejson.NewFromAny(map[string]any{"refId": "D", "datasource": map[string]any{"uid": "__expr__"}, "type": "math", "expression": "$A"}),
+		},
+	}
+}
+
+func TestService_Estimate(t *testing.T) {
+	estimator := &fakeEstimator{bytes: map[string]int64{"A": 100, "B": 50}}
+	s := setupTestService(t, estimator)
+	signedInUser := &user.SignedInUser{OrgID: 1}
+
+	result, err := s.Estimate(context.Background(), signedInUser, false, testMetricRequest())
+	require.NoError(t, err)
+	require.Equal(t, 1, estimator.calls, "queries of a data source are estimated together")
+	require.Equal(t, int64(150), result.TotalBytes)
+	require.True(t, result.Allowed)
+	require.Equal(t, []Estimate{
+		{RefID: "A", DatasourceUID: "loki", Supported: true, Bytes: 100},
+		{RefID: "B", DatasourceUID: "loki", Supported: true, Bytes: 50},
+		{RefID: "C", DatasourceUID: "testdata"},
+	}, result.Estimates)
+
+	t.Run("reports errors of the plugin", func(t *testing.T) {
+		estimator := &fakeEstimator{err: errors.New("unavailable")}
+		result, err := setupTestService(t, estimator).Estimate(context.Background(), signedInUser, false, testMetricRequest())
+		require.NoError(t, err)
+		require.Equal(t, "unavailable", result.Estimates[0].Error)
+		require.Zero(t, result.TotalBytes)
+	})
+}
+
+func TestService_Enforce(t *testing.T) {
+	estimator := &fakeEstimator{bytes: map[string]int64{"A": 100, "B": 50}}
+	s := setupTestService(t, estimator)
+	signedInUser := &user.SignedInUser{OrgID: 1}
+	ctx := context.Background()
+
+	require.NoError(t, s.Enforce(ctx, signedInUser, false, testMetricRequest()))
+	require.Zero(t, estimator.calls, "queries are not estimated without a policy")
+
+	require.ErrorIs(t, s.SetPolicy(ctx, 1, Policy{Enforce: true}), ErrInvalidPolicy)
+	require.ErrorIs(t, s.SetPolicy(ctx, 1, Policy{MaxBytes: -1}), ErrInvalidPolicy)
+
+	require.NoError(t, s.SetPolicy(ctx, 1, Policy{Enforce: true, MaxBytes: 100}))
+	err := s.Enforce(ctx, signedInUser, false, testMetricRequest())
+	require.ErrorIs(t, err, ErrCostLimitExceeded)
+
+	result, err := s.Estimate(ctx, signedInUser, false, testMetricRequest())
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+
+	require.NoError(t, s.Enforce(ctx, &user.SignedInUser{OrgID: 2}, false, testMetricRequest()), "policies are per organization")
+
+	t.Run("the policy is read from the store", func(t *testing.T) {
+		s.policies.Flush()
+		policy, err := s.GetPolicy(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, Policy{Enforce: true, MaxBytes: 100}, policy)
+	})
+
+	t.Run("estimate failures do not reject queries", func(t *testing.T) {
+		estimator.err = errors.New("unavailable")
+		require.NoError(t, s.Enforce(ctx, signedInUser, false, testMetricRequest()))
+	})
+}
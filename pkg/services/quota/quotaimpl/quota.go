@@ -273,6 +273,30 @@ func (s *service) RegisterQuotaReporter(e *quota.NewUsageReporter) error {
 	return nil
 }
 
+// ReloadDefaultLimits updates the default limits registered by the services after the [quota] section is reloaded.
+func (s *service) ReloadDefaultLimits(quotas setting.QuotaSettings) error {
+	items := make([]quota.Item, 0)
+	for item := range s.defaultLimits.Iter() {
+		items = append(items, item)
+	}
+
+	for _, item := range items {
+		scope, err := item.Tag.GetScope()
+		if err != nil {
+			return err
+		}
+		target, err := item.Tag.GetTarget()
+		if err != nil {
+			return err
+		}
+		if limit, ok := quotas.Limit(string(scope), string(target)); ok && limit != item.Value {
+			s.Logger.Info("Reloaded default quota", "tag", item.Tag, "limit", limit)
+			s.defaultLimits.Set(item.Tag, limit)
+		}
+	}
+	return nil
+}
+
 func (s *service) getReporter(target quota.TargetSrv) (quota.UsageReporterFunc, bool) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -0,0 +1,85 @@
+package recordedqueries
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	routeRegister.Group("/api/recorded-queries", func(entities routing.RouteRegister) {
+		entities.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.listHandler))
+		entities.Post("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.createHandler))
+		entities.Get("/:uid", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.getHandler))
+		entities.Put("/:uid", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.updateHandler))
+		entities.Delete("/:uid", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.deleteHandler))
+	})
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	queries, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list recorded queries", err)
+	}
+	return response.JSON(http.StatusOK, queries)
+}
+
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := RecordedQueryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	q, err := s.Create(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to create recorded query")
+	}
+	return response.JSON(http.StatusOK, q)
+}
+
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	q, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to get recorded query")
+	}
+	return response.JSON(http.StatusOK, q)
+}
+
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := RecordedQueryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	q, err := s.Update(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to update recorded query")
+	}
+	return response.JSON(http.StatusOK, q)
+}
+
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err, "Failed to delete recorded query")
+	}
+	return response.Success("Recorded query deleted")
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrRecordedQueryNotFound):
+		return response.Error(http.StatusNotFound, "Recorded query not found", err)
+	case errors.Is(err, ErrInvalidRecordedQuery):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrDataSourceAccess):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, ErrLimitReached):
+		return response.Error(http.StatusForbidden, "Maximum number of recorded queries reached", err)
+	default:
+		return response.Error(http.StatusInternalServerError, message, err)
+	}
+}
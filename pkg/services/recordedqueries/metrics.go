@@ -0,0 +1,36 @@
+package recordedqueries
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	evaluations *prometheus.CounterVec
+	duration    prometheus.Histogram
+	samples     prometheus.Counter
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		evaluations: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "recorded_queries",
+			Name:      "evaluations_total",
+			Help:      "Number of executions of recorded queries by result.",
+		}, []string{"result"}),
+		duration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "recorded_queries",
+			Name:      "evaluation_duration_seconds",
+			Help:      "Duration of the executions of recorded queries, including the remote write.",
+			Buckets:   []float64{.05, .1, .5, 1, 2.5, 5, 10, 30, 60},
+		}),
+		samples: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "recorded_queries",
+			Name:      "samples_written_total",
+			Help:      "Number of samples written to the remote write endpoint.",
+		}),
+	}
+}
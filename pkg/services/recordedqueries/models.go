@@ -0,0 +1,94 @@
+package recordedqueries
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+var (
+	ErrRecordedQueryNotFound = errors.New("recorded query not found")
+	ErrLimitReached          = errors.New("maximum number of recorded queries reached")
+	ErrInvalidRecordedQuery  = errors.New("invalid recorded query")
+	ErrDataSourceAccess      = errors.New("the data sources of the queries can't be queried by the user")
+)
+
+// RecordedQuery is a query that is executed on a schedule, with its result written as a metric.
+type RecordedQuery struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID   string `xorm:"uid" json:"uid"`
+	OrgID int64  `xorm:"org_id" json:"orgId"`
+	// Name is the name of the metric the result is written to.
+	Name        string `xorm:"name" json:"name"`
+	Description string `xorm:"description" json:"description"`
+	// Queries are the queries of a /api/ds/query request.
+	Queries []*simplejson.Json `xorm:"queries" json:"queries"`
+	// TargetRefID is the refId of the query whose result is recorded. Defaults to the first query.
+	TargetRefID string `xorm:"target_ref_id" json:"targetRefId"`
+	// IntervalSeconds is how often the queries are executed.
+	IntervalSeconds int64 `xorm:"interval_seconds" json:"intervalSeconds"`
+	// RangeSeconds is the time range of the queries, ending at the time of the execution.
+	RangeSeconds int64 `xorm:"range_seconds" json:"rangeSeconds"`
+	// Labels are added to every written series.
+	Labels    map[string]string `xorm:"labels" json:"labels"`
+	Active    bool              `xorm:"active" json:"active"`
+	CreatedBy string            `xorm:"created_by" json:"createdBy"`
+	Created   int64             `xorm:"created" json:"created"`
+	Updated   int64             `xorm:"updated" json:"updated"`
+}
+
+func (RecordedQuery) TableName() string {
+	return "recorded_query"
+}
+
+func (q *RecordedQuery) interval() time.Duration {
+	return time.Duration(q.IntervalSeconds) * time.Second
+}
+
+func (q *RecordedQuery) targetRefID() string {
+	if q.TargetRefID != "" {
+		return q.TargetRefID
+	}
+	return q.Queries[0].Get("refId").MustString("A")
+}
+
+// dataSourceUIDs returns the UIDs of the data sources of the queries, without the expressions.
+func dataSourceUIDs(queries []*simplejson.Json) []string {
+	uids := make([]string, 0, len(queries))
+	for _, query := range queries {
+		uid := query.GetPath("datasource", "uid").MustString()
+		if uid == "" || expr.IsDataSource(uid) || slices.Contains(uids, uid) {
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+// RecordedQueryCommand creates or updates a recorded query.
+type RecordedQueryCommand struct {
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	Queries         []*simplejson.Json `json:"queries"`
+	TargetRefID     string             `json:"targetRefId"`
+	IntervalSeconds int64              `json:"intervalSeconds"`
+	RangeSeconds    int64              `json:"rangeSeconds"`
+	Labels          map[string]string  `json:"labels"`
+	Active          bool               `json:"active"`
+}
+
+// State is the result of the last execution of a recorded query.
+type State struct {
+	LastEvaluation time.Time `json:"lastEvaluation"`
+	Series         int       `json:"series"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// RecordedQueryDTO is a recorded query with the result of its last execution.
+type RecordedQueryDTO struct {
+	*RecordedQuery
+	State *State `json:"state,omitempty"`
+}
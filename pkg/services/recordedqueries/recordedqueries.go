@@ -0,0 +1,337 @@
+package recordedqueries
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	tickInterval       = 10 * time.Second
+	evaluationParallel = 4
+)
+
+var (
+	metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRegexp  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Service executes the recorded queries of every organization at their interval and writes their results to a
+// Prometheus remote write endpoint. Each execution takes a server lock, so that a single instance executes a
+// recorded query at each interval.
+type Service struct {
+	store            db.DB
+	settings         setting.RecordedQueriesSettings
+	queryDataService query.Service
+	accessControl    ac.AccessControl
+	serverLock       *serverlock.ServerLockService
+	writer           sampleWriter
+	metrics          *metrics
+	log              log.Logger
+	now              func() time.Time
+
+	mu     sync.RWMutex
+	states map[string]State
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, queryDataService query.Service,
+	httpClientProvider httpclient.Provider, registerer prometheus.Registerer, accessControl ac.AccessControl,
+	accesscontrolService ac.Service, serverLock *serverlock.ServerLockService,
+) (*Service, error) {
+	s := &Service{
+		store:            sqlStore,
+		settings:         cfg.RecordedQueries,
+		queryDataService: queryDataService,
+		accessControl:    accessControl,
+		serverLock:       serverLock,
+		metrics:          newMetrics(registerer),
+		log:              log.New("recorded-queries"),
+		now:              time.Now,
+		states:           make(map[string]State),
+	}
+	if !s.settings.Enabled {
+		return s, nil
+	}
+
+	if s.settings.RemoteWriteURL == "" {
+		return nil, fmt.Errorf("recorded_queries.remote_write_url is required when recorded queries are enabled")
+	}
+	writer, err := newRemoteWriter(s.settings, httpClientProvider)
+	if err != nil {
+		return nil, err
+	}
+	s.writer = writer
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled
+}
+
+// Run executes the recorded queries that are due until ctx is done.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.evaluateDue(ctx); err != nil {
+			s.log.Error("Failed to evaluate recorded queries", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Create validates and stores a new recorded query, within the limit of the organization.
+func (s *Service) Create(ctx context.Context, user identity.Requester, cmd RecordedQueryCommand) (*RecordedQuery, error) {
+	if err := s.checkDataSources(ctx, user, cmd.Queries); err != nil {
+		return nil, err
+	}
+	now := s.now().Unix()
+	q := &RecordedQuery{
+		UID:       util.GenerateShortUID(),
+		OrgID:     user.GetOrgID(),
+		CreatedBy: user.GetUID(),
+		Created:   now,
+		Updated:   now,
+	}
+	if err := s.apply(q, cmd); err != nil {
+		return nil, err
+	}
+	if err := s.insert(ctx, q, s.settings.MaxQueriesForOrg(q.OrgID)); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Update replaces the definition of a recorded query.
+func (s *Service) Update(ctx context.Context, user identity.Requester, uid string, cmd RecordedQueryCommand) (*RecordedQuery, error) {
+	if err := s.checkDataSources(ctx, user, cmd.Queries); err != nil {
+		return nil, err
+	}
+	q, err := s.get(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(q, cmd); err != nil {
+		return nil, err
+	}
+	q.Updated = s.now().Unix()
+	if err := s.update(ctx, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Delete deletes a recorded query and forgets the result of its last execution.
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	if err := s.delete(ctx, orgID, uid); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.states, uid)
+	s.mu.Unlock()
+	return nil
+}
+
+// Get returns a recorded query of an organization with the result of its last execution.
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (RecordedQueryDTO, error) {
+	q, err := s.get(ctx, orgID, uid)
+	if err != nil {
+		return RecordedQueryDTO{}, err
+	}
+	return s.toDTO(q), nil
+}
+
+// List returns the recorded queries of an organization, sorted by name.
+func (s *Service) List(ctx context.Context, orgID int64) ([]RecordedQueryDTO, error) {
+	queries, err := s.list(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]RecordedQueryDTO, 0, len(queries))
+	for _, q := range queries {
+		result = append(result, s.toDTO(q))
+	}
+	return result, nil
+}
+
+func (s *Service) toDTO(q *RecordedQuery) RecordedQueryDTO {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dto := RecordedQueryDTO{RecordedQuery: q}
+	if state, ok := s.states[q.UID]; ok {
+		dto.State = &state
+	}
+	return dto
+}
+
+// checkDataSources checks the user can query the data sources of the queries, since the recorded queries are
+// executed with the permission to query them.
+func (s *Service) checkDataSources(ctx context.Context, user identity.Requester, queries []*simplejson.Json) error {
+	for _, uid := range dataSourceUIDs(queries) {
+		ok, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrDataSourceAccess, uid)
+		}
+	}
+	return nil
+}
+
+// apply validates the command and sets its fields on the recorded query.
+func (s *Service) apply(q *RecordedQuery, cmd RecordedQueryCommand) error {
+	if !metricNameRegexp.MatchString(cmd.Name) {
+		return fmt.Errorf("%w: %q is not a valid metric name", ErrInvalidRecordedQuery, cmd.Name)
+	}
+	if len(cmd.Queries) == 0 {
+		return fmt.Errorf("%w: at least one query is required", ErrInvalidRecordedQuery)
+	}
+	if cmd.IntervalSeconds <= 0 || time.Duration(cmd.IntervalSeconds)*time.Second < s.settings.MinInterval {
+		return fmt.Errorf("%w: the interval must be at least %s", ErrInvalidRecordedQuery, s.settings.MinInterval)
+	}
+	if cmd.RangeSeconds < 0 {
+		return fmt.Errorf("%w: the range can't be negative", ErrInvalidRecordedQuery)
+	}
+	if cmd.TargetRefID != "" {
+		found := false
+		for _, query := range cmd.Queries {
+			if query.Get("refId").MustString("A") == cmd.TargetRefID {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: no query with refId %q", ErrInvalidRecordedQuery, cmd.TargetRefID)
+		}
+	}
+	for name := range cmd.Labels {
+		if !labelNameRegexp.MatchString(name) || name == "__name__" {
+			return fmt.Errorf("%w: %q is not a valid label name", ErrInvalidRecordedQuery, name)
+		}
+	}
+
+	q.Name = cmd.Name
+	q.Description = cmd.Description
+	q.Queries = cmd.Queries
+	q.TargetRefID = cmd.TargetRefID
+	q.IntervalSeconds = cmd.IntervalSeconds
+	q.RangeSeconds = cmd.RangeSeconds
+	if q.RangeSeconds == 0 {
+		q.RangeSeconds = cmd.IntervalSeconds
+	}
+	q.Labels = cmd.Labels
+	q.Active = cmd.Active
+	return nil
+}
+
+// evaluateDue executes the active recorded queries whose interval has passed since their last execution.
+func (s *Service) evaluateDue(ctx context.Context) error {
+	queries, err := s.listActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list recorded queries: %w", err)
+	}
+
+	now := s.now()
+	due := make([]*RecordedQuery, 0, len(queries))
+	s.mu.RLock()
+	for _, q := range queries {
+		if state, ok := s.states[q.UID]; !ok || now.Sub(state.LastEvaluation) >= q.interval() {
+			due = append(due, q)
+		}
+	}
+	s.mu.RUnlock()
+
+	return concurrency.ForEachJob(ctx, len(due), evaluationParallel, func(ctx context.Context, idx int) error {
+		q := due[idx]
+		// the lock is kept for the interval, the other instances skip the recorded query until it's due again
+		err := s.serverLock.LockAndExecute(ctx, "recorded-query-"+q.UID, q.interval(), func(ctx context.Context) {
+			state := s.evaluate(ctx, q, now)
+			s.mu.Lock()
+			s.states[q.UID] = state
+			s.mu.Unlock()
+		})
+		if err != nil {
+			s.log.Warn("Failed to lock recorded query", "uid", q.UID, "orgId", q.OrgID, "error", err)
+		}
+		return nil
+	})
+}
+
+// evaluate executes a recorded query and writes its result.
+func (s *Service) evaluate(ctx context.Context, q *RecordedQuery, now time.Time) State {
+	ctx, cancel := context.WithTimeout(ctx, q.interval())
+	defer cancel()
+
+	state := State{LastEvaluation: now}
+	samples, err := s.execute(ctx, q, now)
+	if err == nil && len(samples) > 0 {
+		err = s.writer.Write(ctx, samples)
+	}
+	s.metrics.duration.Observe(s.now().Sub(now).Seconds())
+	if err != nil {
+		s.log.Warn("Failed to evaluate recorded query", "uid", q.UID, "orgId", q.OrgID, "error", err)
+		s.metrics.evaluations.WithLabelValues("failure").Inc()
+		state.Error = err.Error()
+		return state
+	}
+	s.metrics.evaluations.WithLabelValues("success").Inc()
+	s.metrics.samples.Add(float64(len(samples)))
+	state.Series = len(samples)
+	return state
+}
+
+func (s *Service) execute(ctx context.Context, q *RecordedQuery, now time.Time) ([]Sample, error) {
+	// the queries can only query the data sources they are made of, in the organization of the recorded query
+	permissions := make([]ac.Permission, 0, len(q.Queries))
+	for _, uid := range dataSourceUIDs(q.Queries) {
+		permissions = append(permissions, ac.Permission{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(uid)})
+	}
+	user := ac.BackgroundUser("recorded_queries", q.OrgID, org.RoleViewer, permissions)
+	from := now.Add(-time.Duration(q.RangeSeconds) * time.Second)
+	resp, err := s.queryDataService.QueryData(ctx, user, false, dtos.MetricRequest{
+		From:    strconv.FormatInt(from.UnixMilli(), 10),
+		To:      strconv.FormatInt(now.UnixMilli(), 10),
+		Queries: q.Queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := resp.Responses[q.targetRefID()]
+	if !ok {
+		return nil, fmt.Errorf("no response for query %s", q.targetRefID())
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return samplesFromFrames(q.Name, now, res.Frames, q.Labels), nil
+}
@@ -0,0 +1,153 @@
+package recordedqueries
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestSamplesFromFrames(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+			data.NewField("value", data.Labels{"job": "b", "__name__": "up"}, []*float64{ptr(1), nil}),
+		),
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{now}),
+			data.NewField("value", data.Labels{"job": "a"}, []float64{3}),
+			data.NewField("empty", nil, []*float64{nil}),
+			data.NewField("name", nil, []string{"ignored"}),
+		),
+	}
+
+	samples := samplesFromFrames("job:up", now, frames, map[string]string{"env": "prod"})
+	require.Equal(t, []Sample{
+		{Name: "job:up", Labels: map[string]string{"env": "prod", "job": "a"}, Value: 3, Time: now},
+		{Name: "job:up", Labels: map[string]string{"env": "prod", "job": "b"}, Value: 1, Time: now},
+	}, samples)
+}
+
+func TestApply(t *testing.T) {
+	s := &Service{settings: setting.RecordedQueriesSettings{MinInterval: time.Minute}}
+	valid := func() RecordedQueryCommand {
+		return RecordedQueryCommand{
+			Name:            "job:up:sum",
+			Queries:         []*simplejson.Json{simplejson.NewFromAny(map[string]any{"refId": "A"})},
+			IntervalSeconds: 60,
+			Labels:          map[string]string{"env": "prod"},
+		}
+	}
+
+	q := &RecordedQuery{}
+	require.NoError(t, s.apply(q, valid()))
+	require.Equal(t, int64(60), q.RangeSeconds, "the range defaults to the interval")
+	require.Equal(t, "A", q.targetRefID())
+
+	tests := map[string]func(cmd *RecordedQueryCommand){
+		"invalid name":       func(cmd *RecordedQueryCommand) { cmd.Name = "job up" },
+		"no queries":         func(cmd *RecordedQueryCommand) { cmd.Queries = nil },
+		"short interval":     func(cmd *RecordedQueryCommand) { cmd.IntervalSeconds = 10 },
+		"negative range":     func(cmd *RecordedQueryCommand) { cmd.RangeSeconds = -1 },
+		"unknown target":     func(cmd *RecordedQueryCommand) { cmd.TargetRefID = "B" },
+		"invalid label name": func(cmd *RecordedQueryCommand) { cmd.Labels = map[string]string{"__name__": "x"} },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := valid()
+			modify(&cmd)
+			require.ErrorIs(t, s.apply(&RecordedQuery{}, cmd), ErrInvalidRecordedQuery)
+		})
+	}
+}
+
+func TestCheckDataSources(t *testing.T) {
+	s := &Service{accessControl: acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())}
+	queries := []*simplejson.Json{
+		simplejson.NewFromAny(map[string]any{"refId": "A", "datasource": map[string]any{"uid": "prom"}}),
+		simplejson.NewFromAny(map[string]any{"refId": "B", "datasource": map[string]any{"uid": "__expr__"}}),
+		simplejson.NewFromAny(map[string]any{"refId": "C", "datasource": map[string]any{"uid": "prom"}}),
+	}
+	require.Equal(t, []string{"prom"}, dataSourceUIDs(queries), "the expressions are not data sources")
+
+	u := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID("prom")}},
+	}}
+	require.NoError(t, s.checkDataSources(context.Background(), u, queries))
+
+	u.Permissions[1][datasources.ActionQuery] = []string{datasources.ScopeProvider.GetResourceScopeUID("other")}
+	require.ErrorIs(t, s.checkDataSources(context.Background(), u, queries), ErrDataSourceAccess)
+}
+
+func TestIntegrationRecordedQueriesLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := &Service{
+		store: db.InitTestDB(t),
+		settings: setting.RecordedQueriesSettings{
+			MinInterval:         time.Minute,
+			MaxQueries:          1,
+			MaxQueriesOverrides: map[int64]int{2: 2},
+		},
+		log:    log.NewNopLogger(),
+		now:    time.Now,
+		states: make(map[string]State),
+	}
+	ctx := context.Background()
+	cmd := RecordedQueryCommand{
+		Name:            "job:up:sum",
+		Queries:         []*simplejson.Json{simplejson.NewFromAny(map[string]any{"refId": "A"})},
+		IntervalSeconds: 60,
+		Active:          true,
+	}
+
+	org1 := &user.SignedInUser{OrgID: 1, UserUID: "u1"}
+	org2 := &user.SignedInUser{OrgID: 2, UserUID: "u2"}
+	created, err := s.Create(ctx, org1, cmd)
+	require.NoError(t, err)
+	_, err = s.Create(ctx, org1, cmd)
+	require.ErrorIs(t, err, ErrLimitReached)
+	for i := 0; i < 2; i++ {
+		_, err = s.Create(ctx, org2, cmd)
+		require.NoError(t, err)
+	}
+
+	_, err = s.Get(ctx, 2, created.UID)
+	require.ErrorIs(t, err, ErrRecordedQueryNotFound)
+
+	cmd.Active = false
+	_, err = s.Update(ctx, org1, created.UID, cmd)
+	require.NoError(t, err)
+	active, err := s.listActive(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+
+	require.NoError(t, s.Delete(ctx, 1, created.UID))
+	queries, err := s.List(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, queries)
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
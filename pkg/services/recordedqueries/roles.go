@@ -0,0 +1,41 @@
+package recordedqueries
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionRead  = "recordedqueries:read"
+	ActionWrite = "recordedqueries:write"
+)
+
+var (
+	readerRole = ac.RoleDTO{
+		Name:        "fixed:recordedqueries:reader",
+		DisplayName: "Recorded query reader",
+		Description: "List the recorded queries of the organization and the result of their last execution",
+		Group:       "Recorded queries",
+		Permissions: []ac.Permission{
+			{Action: ActionRead},
+		},
+	}
+
+	writerRole = ac.RoleDTO{
+		Name:        "fixed:recordedqueries:writer",
+		DisplayName: "Recorded query writer",
+		Description: "List, create, update and delete the recorded queries of the organization",
+		Group:       "Recorded queries",
+		Permissions: []ac.Permission{
+			{Action: ActionRead},
+			{Action: ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(service ac.Service) error {
+	return service.DeclareFixedRoles(
+		ac.RoleRegistration{Role: readerRole, Grants: []string{string(org.RoleAdmin)}},
+		ac.RoleRegistration{Role: writerRole, Grants: []string{string(org.RoleAdmin)}},
+	)
+}
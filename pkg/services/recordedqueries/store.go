@@ -0,0 +1,85 @@
+package recordedqueries
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *Service) insert(ctx context.Context, q *RecordedQuery, maxQueries int) error {
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+			if maxQueries > 0 {
+				count, err := sess.Where("org_id = ?", q.OrgID).Count(&RecordedQuery{})
+				if err != nil {
+					return err
+				}
+				if count >= int64(maxQueries) {
+					return ErrLimitReached
+				}
+			}
+			_, err := sess.Insert(q)
+			return err
+		})
+	})
+}
+
+func (s *Service) update(ctx context.Context, q *RecordedQuery) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", q.OrgID, q.UID).AllCols().Omit("id", "created", "created_by").Update(q)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrRecordedQueryNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Service) get(ctx context.Context, orgID int64, uid string) (*RecordedQuery, error) {
+	q := &RecordedQuery{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(q)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRecordedQueryNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (s *Service) list(ctx context.Context, orgID int64) ([]*RecordedQuery, error) {
+	queries := make([]*RecordedQuery, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&queries)
+	})
+	return queries, err
+}
+
+func (s *Service) listActive(ctx context.Context) ([]*RecordedQuery, error) {
+	queries := make([]*RecordedQuery, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("active = ?", true).Find(&queries)
+	})
+	return queries, err
+}
+
+func (s *Service) delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&RecordedQuery{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrRecordedQueryNotFound
+		}
+		return nil
+	})
+}
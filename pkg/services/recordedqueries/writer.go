@@ -0,0 +1,124 @@
+package recordedqueries
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/m3db/prometheus_remote_client_golang/promremote"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Sample is a single value of a recorded series.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+	Time   time.Time
+}
+
+type sampleWriter interface {
+	Write(ctx context.Context, samples []Sample) error
+}
+
+type httpClientProvider interface {
+	New(options ...httpclient.Options) (*http.Client, error)
+}
+
+// remoteWriter writes samples to a Prometheus remote write endpoint.
+type remoteWriter struct {
+	client promremote.Client
+}
+
+func newRemoteWriter(settings setting.RecordedQueriesSettings, provider httpClientProvider) (*remoteWriter, error) {
+	opts := httpclient.Options{}
+	if settings.RemoteWriteUser != "" {
+		opts.BasicAuth = &httpclient.BasicAuthOptions{
+			User:     settings.RemoteWriteUser,
+			Password: settings.RemoteWritePassword,
+		}
+	}
+	cl, err := provider.New(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := promremote.NewClient(promremote.NewConfig(
+		promremote.UserAgent("grafana-recorded-queries"),
+		promremote.WriteURLOption(settings.RemoteWriteURL),
+		promremote.HTTPClientTimeoutOption(settings.RemoteWriteTimeout),
+		promremote.HTTPClientOption(cl),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &remoteWriter{client: client}, nil
+}
+
+func (w *remoteWriter) Write(ctx context.Context, samples []Sample) error {
+	series := make([]promremote.TimeSeries, 0, len(samples))
+	for _, sample := range samples {
+		labels := make([]promremote.Label, 0, len(sample.Labels)+1)
+		labels = append(labels, promremote.Label{Name: "__name__", Value: sample.Name})
+		for k, v := range sample.Labels {
+			labels = append(labels, promremote.Label{Name: k, Value: v})
+		}
+		series = append(series, promremote.TimeSeries{
+			Labels:    labels,
+			Datapoint: promremote.Datapoint{Timestamp: sample.Time, Value: sample.Value},
+		})
+	}
+
+	if _, err := w.client.WriteTimeSeries(ctx, series, promremote.WriteOptions{}); err != nil {
+		return fmt.Errorf("failed to write time series: %w", err)
+	}
+	return nil
+}
+
+// samplesFromFrames returns the last value of every numeric field of the frames, with the labels of the field and
+// the extra labels. Fields without a value are skipped.
+func samplesFromFrames(name string, t time.Time, frames data.Frames, extraLabels map[string]string) []Sample {
+	samples := make([]Sample, 0)
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, ok := lastValue(field)
+			if !ok {
+				continue
+			}
+
+			labels := make(map[string]string, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				labels[k] = v
+			}
+			delete(labels, "__name__")
+			for k, v := range extraLabels {
+				labels[k] = v
+			}
+			samples = append(samples, Sample{Name: name, Labels: labels, Value: value, Time: t})
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return data.Labels(samples[i].Labels).String() < data.Labels(samples[j].Labels).String()
+	})
+	return samples
+}
+
+func lastValue(field *data.Field) (float64, bool) {
+	for i := field.Len() - 1; i >= 0; i-- {
+		v, err := field.NullableFloatAt(i)
+		if err != nil || v == nil || math.IsNaN(*v) {
+			continue
+		}
+		return *v, true
+	}
+	return 0, false
+}
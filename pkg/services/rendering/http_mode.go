@@ -171,7 +171,7 @@ func (rs *RenderingService) doRequest(ctx context.Context, u *url.URL, headers m
 				return nil, ErrServerTimeout
 			}
 		}
-		return nil, fmt.Errorf("failed to send request to remote rendering service: %w", err)
+		return nil, fmt.Errorf("%w: %w", errRendererUnreachable, err)
 	}
 
 	return resp, nil
@@ -234,13 +234,17 @@ func (rs *RenderingService) getRemotePluginVersionWithRetry(callback func(string
 }
 
 func (rs *RenderingService) getRemotePluginVersion() (string, error) {
+	return rs.fetchRemotePluginVersion(context.Background())
+}
+
+func (rs *RenderingService) fetchRemotePluginVersion(ctx context.Context) (string, error) {
 	rendererURL, err := url.Parse(rs.Cfg.RendererUrl + "/version")
 	if err != nil {
 		return "", err
 	}
 
 	headers := make(map[string][]string)
-	resp, err := rs.doRequest(context.Background(), rendererURL, headers)
+	resp, err := rs.doRequest(ctx, rendererURL, headers)
 	if err != nil {
 		return "", err
 	}
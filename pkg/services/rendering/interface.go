@@ -13,6 +13,7 @@ import (
 var ErrTimeout = errors.New("timeout error - you can set timeout in seconds with &timeout url parameter")
 var ErrConcurrentLimitReached = errors.New("rendering concurrent limit reached")
 var ErrRenderUnavailable = errors.New("rendering plugin not available")
+var errRendererUnreachable = errors.New("failed to send request to remote rendering service")
 var ErrServerTimeout = errutil.NewBase(errutil.StatusUnknown, "rendering.serverTimeout", errutil.WithPublicMessage("error trying to connect to image-renderer service"))
 
 type RenderType string
@@ -48,6 +49,7 @@ type CommonOpts struct {
 	Path            string
 	Timezone        string
 	ConcurrentLimit int
+	Priority        Priority
 	Headers         map[string][]string
 }
 
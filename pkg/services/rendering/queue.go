@@ -0,0 +1,196 @@
+package rendering
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+// Priority decides the order in which queued render requests get a free renderer slot.
+type Priority int
+
+const (
+	// PriorityLow is used for renders nobody is actively waiting on, such as scheduled reports.
+	PriorityLow Priority = -1
+	// PriorityNormal is the default priority used for interactive renders.
+	PriorityNormal Priority = 0
+	// PriorityHigh is used for alert notification renders.
+	PriorityHigh Priority = 1
+)
+
+func (p Priority) String() string {
+	switch {
+	case p > PriorityNormal:
+		return "high"
+	case p < PriorityNormal:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// renderQueue limits the number of concurrent render requests, globally and per organization.
+// Requests exceeding the limits wait until a slot is released, highest priority first and
+// in arrival order within a priority.
+type renderQueue struct {
+	mtx      sync.Mutex
+	orgLimit int
+	timeout  time.Duration
+	running  int
+	perOrg   map[int64]int
+	waiting  []*renderJob
+	seq      uint64
+}
+
+type renderJob struct {
+	orgID    int64
+	limit    int
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+}
+
+func newRenderQueue(orgLimit int, timeout time.Duration) *renderQueue {
+	return &renderQueue{
+		orgLimit: orgLimit,
+		timeout:  timeout,
+		perOrg:   map[int64]int{},
+	}
+}
+
+// acquire waits for a free renderer slot and returns the function releasing it. It fails with
+// ErrConcurrentLimitReached when no slot frees up within the queue timeout.
+func (q *renderQueue) acquire(ctx context.Context, orgID int64, limit int, priority Priority) (func(), error) {
+	start := time.Now()
+	job := &renderJob{orgID: orgID, limit: limit, priority: priority, ready: make(chan struct{})}
+
+	q.mtx.Lock()
+	if q.canRun(job) && !q.hasWaitingBefore(job) {
+		q.start(job)
+		q.mtx.Unlock()
+		metrics.MRenderingQueueWaitDuration.WithLabelValues(priority.String(), "success").Observe(0)
+		return q.releaseFunc(job), nil
+	}
+	if q.timeout <= 0 {
+		q.mtx.Unlock()
+		metrics.MRenderingQueueWaitDuration.WithLabelValues(priority.String(), "limit").Observe(0)
+		return nil, ErrConcurrentLimitReached
+	}
+	q.seq++
+	job.seq = q.seq
+	q.enqueue(job)
+	q.mtx.Unlock()
+
+	timer := time.NewTimer(q.timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-job.ready:
+	case <-timer.C:
+		err = ErrConcurrentLimitReached
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		q.mtx.Lock()
+		started := q.dequeue(job)
+		q.mtx.Unlock()
+		// the job could have been started right before timing out, in which case it holds a slot
+		if !started {
+			metrics.MRenderingQueueWaitDuration.WithLabelValues(priority.String(), "limit").Observe(time.Since(start).Seconds())
+			return nil, err
+		}
+	}
+
+	metrics.MRenderingQueueWaitDuration.WithLabelValues(priority.String(), "success").Observe(time.Since(start).Seconds())
+	return q.releaseFunc(job), nil
+}
+
+func (q *renderQueue) releaseFunc(job *renderJob) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mtx.Lock()
+			defer q.mtx.Unlock()
+
+			q.running--
+			q.perOrg[job.orgID]--
+			if q.perOrg[job.orgID] <= 0 {
+				delete(q.perOrg, job.orgID)
+			}
+			q.dispatch()
+		})
+	}
+}
+
+func (q *renderQueue) canRun(job *renderJob) bool {
+	if q.running >= job.limit {
+		return false
+	}
+	return q.orgLimit <= 0 || q.perOrg[job.orgID] < q.orgLimit
+}
+
+// hasWaitingBefore reports whether a waiting job that would be started before the given job
+// could run now, so that new requests don't jump the queue.
+func (q *renderQueue) hasWaitingBefore(job *renderJob) bool {
+	for _, w := range q.waiting {
+		if w.priority < job.priority {
+			return false
+		}
+		if q.canRun(w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *renderQueue) start(job *renderJob) {
+	q.running++
+	q.perOrg[job.orgID]++
+}
+
+func (q *renderQueue) enqueue(job *renderJob) {
+	i := sort.Search(len(q.waiting), func(i int) bool {
+		return q.waiting[i].priority < job.priority
+	})
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = job
+	metrics.MRenderingQueueWaiting.WithLabelValues(job.priority.String()).Inc()
+}
+
+// dequeue removes a job that stopped waiting from the queue and reports whether it was already started.
+func (q *renderQueue) dequeue(job *renderJob) bool {
+	for i, w := range q.waiting {
+		if w == job {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			metrics.MRenderingQueueWaiting.WithLabelValues(job.priority.String()).Dec()
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch starts the waiting jobs fitting in the free slots, in queue order. A job blocked by the
+// limit of its organization doesn't block the jobs of other organizations.
+func (q *renderQueue) dispatch() {
+	waiting := q.waiting[:0]
+	for _, job := range q.waiting {
+		if q.canRun(job) {
+			q.start(job)
+			metrics.MRenderingQueueWaiting.WithLabelValues(job.priority.String()).Dec()
+			close(job.ready)
+			continue
+		}
+		waiting = append(waiting, job)
+	}
+	for i := len(waiting); i < len(q.waiting); i++ {
+		q.waiting[i] = nil
+	}
+	q.waiting = waiting
+}
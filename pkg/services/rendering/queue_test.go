@@ -0,0 +1,139 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRenderQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts waiting jobs by priority then arrival", func(t *testing.T) {
+		q := newRenderQueue(0, time.Minute)
+		release, err := q.acquire(ctx, 1, 1, PriorityNormal)
+		require.NoError(t, err)
+
+		order := make(chan string, 3)
+		acquire := func(name string, priority Priority) {
+			release, err := q.acquire(ctx, 1, 1, priority)
+			require.NoError(t, err)
+			order <- name
+			release()
+		}
+		go acquire("report", PriorityLow)
+		require.Eventually(t, func() bool { return waitingCount(q) == 1 }, time.Second, time.Millisecond)
+		go acquire("interactive", PriorityNormal)
+		require.Eventually(t, func() bool { return waitingCount(q) == 2 }, time.Second, time.Millisecond)
+		go acquire("alert", PriorityHigh)
+		require.Eventually(t, func() bool { return waitingCount(q) == 3 }, time.Second, time.Millisecond)
+
+		release()
+		require.Equal(t, "alert", <-order)
+		require.Equal(t, "interactive", <-order)
+		require.Equal(t, "report", <-order)
+	})
+
+	t.Run("limits the concurrent renders of an organization", func(t *testing.T) {
+		q := newRenderQueue(1, time.Minute)
+		release, err := q.acquire(ctx, 1, 10, PriorityNormal)
+		require.NoError(t, err)
+
+		started := make(chan struct{})
+		go func() {
+			release, err := q.acquire(ctx, 1, 10, PriorityHigh)
+			require.NoError(t, err)
+			close(started)
+			release()
+		}()
+		require.Eventually(t, func() bool { return waitingCount(q) == 1 }, time.Second, time.Millisecond)
+
+		other, err := q.acquire(ctx, 2, 10, PriorityNormal)
+		require.NoError(t, err, "a waiting job of another organization doesn't block the job")
+		other()
+
+		release()
+		<-started
+	})
+
+	t.Run("fails when no slot frees up in time", func(t *testing.T) {
+		q := newRenderQueue(0, 10*time.Millisecond)
+		release, err := q.acquire(ctx, 1, 1, PriorityNormal)
+		require.NoError(t, err)
+
+		_, err = q.acquire(ctx, 1, 1, PriorityHigh)
+		require.ErrorIs(t, err, ErrConcurrentLimitReached)
+		require.Zero(t, waitingCount(q))
+
+		release()
+		release()
+		next, err := q.acquire(ctx, 1, 1, PriorityNormal)
+		require.NoError(t, err, "releasing twice frees a single slot")
+		next()
+	})
+
+	t.Run("fails without waiting when the queue timeout is disabled", func(t *testing.T) {
+		q := newRenderQueue(0, 0)
+		_, err := q.acquire(ctx, 1, 1, PriorityNormal)
+		require.NoError(t, err)
+
+		_, err = q.acquire(ctx, 1, 1, PriorityHigh)
+		require.ErrorIs(t, err, ErrConcurrentLimitReached)
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	rs := &RenderingService{
+		Cfg: &setting.Cfg{
+			RendererRetryMaxAttempts: 3,
+			RendererRetryBackoff:     time.Millisecond,
+		},
+		log: log.New("test"),
+	}
+
+	t.Run("retries until the renderer can be reached", func(t *testing.T) {
+		attempts := 0
+		result, err := withRetry(context.Background(), rs, RenderPNG, func() (*RenderResult, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, ErrServerTimeout.Errorf("timeout")
+			}
+			return &RenderResult{FilePath: "a.png"}, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "a.png", result.FilePath)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("gives up after the maximum attempts", func(t *testing.T) {
+		attempts := 0
+		_, err := withRetry(context.Background(), rs, RenderPNG, func() (*RenderResult, error) {
+			attempts++
+			return nil, errRendererUnreachable
+		})
+		require.ErrorIs(t, err, errRendererUnreachable)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		attempts := 0
+		_, err := withRetry(context.Background(), rs, RenderPNG, func() (*RenderResult, error) {
+			attempts++
+			return nil, errors.New("bad request")
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+	})
+}
+
+func waitingCount(q *renderQueue) int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return len(q.waiting)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/metering"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -35,6 +36,7 @@ type RenderingService struct {
 	sanitizeURL       string
 	domain            string
 	inProgressCount   int32
+	queue             *renderQueue
 	version           string
 	versionMutex      sync.RWMutex
 	capabilities      []Capability
@@ -45,6 +47,8 @@ type RenderingService struct {
 	features                    featuremgmt.FeatureToggles
 	RemoteCacheService          *remotecache.RemoteCache
 	RendererPluginManager       PluginManager
+	// usage records the images rendered for the organizations, it's optional.
+	usage metering.Recorder
 }
 
 type PluginManager interface {
@@ -57,7 +61,8 @@ type Plugin interface {
 	Version() string
 }
 
-func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remoteCache *remotecache.RemoteCache, rm PluginManager) (*RenderingService, error) {
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remoteCache *remotecache.RemoteCache, rm PluginManager,
+	usage metering.Recorder) (*RenderingService, error) {
 	folders := []string{
 		cfg.ImagesDir,
 		cfg.CSVsDir,
@@ -141,6 +146,8 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remot
 		domain:                domain,
 		sanitizeURL:           sanitizeURL,
 		pluginAvailable:       exists,
+		queue:                 newRenderQueue(cfg.RendererConcurrentRequestPerOrgLimit, cfg.RendererQueueTimeout),
+		usage:                 usage,
 	}
 
 	gob.Register(&RenderUser{})
@@ -218,6 +225,18 @@ func (rs *RenderingService) IsAvailable(ctx context.Context) bool {
 	return rs.remoteAvailable() || rs.pluginAvailable
 }
 
+// CheckHealth returns an error if no renderer is available, or if the remote renderer can't be reached.
+func (rs *RenderingService) CheckHealth(ctx context.Context) error {
+	if rs.remoteAvailable() {
+		_, err := rs.fetchRemotePluginVersion(ctx)
+		return err
+	}
+	if !rs.pluginAvailable {
+		return ErrRenderUnavailable
+	}
+	return nil
+}
+
 func (rs *RenderingService) Version() string {
 	rs.versionMutex.RLock()
 	defer rs.versionMutex.RUnlock()
@@ -271,22 +290,6 @@ func (rs *RenderingService) Render(ctx context.Context, renderType RenderType, o
 }
 
 func (rs *RenderingService) render(ctx context.Context, renderType RenderType, opts Opts, renderKeyProvider renderKeyProvider) (*RenderResult, error) {
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
-		rs.log.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "path", opts.Path)
-		if opts.ErrorConcurrentLimitReached {
-			return nil, ErrConcurrentLimitReached
-		}
-
-		theme := models.ThemeDark
-		if opts.Theme != "" {
-			theme = opts.Theme
-		}
-		filePath := fmt.Sprintf("public/img/rendering_limit_%s.png", theme)
-		return &RenderResult{
-			FilePath: filepath.Join(rs.Cfg.HomePath, filePath),
-		}, nil
-	}
-
 	if !rs.IsAvailable(ctx) {
 		rs.log.Warn("Could not render image, no image renderer found/installed. " +
 			"For image rendering support please install the grafana-image-renderer plugin. " +
@@ -307,6 +310,27 @@ func (rs *RenderingService) render(ctx context.Context, renderType RenderType, o
 		}
 	}
 
+	release, err := rs.acquire(ctx, opts.CommonOpts)
+	if errors.Is(err, ErrConcurrentLimitReached) {
+		rs.log.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "path", opts.Path)
+		if opts.ErrorConcurrentLimitReached {
+			return nil, ErrConcurrentLimitReached
+		}
+
+		theme := models.ThemeDark
+		if opts.Theme != "" {
+			theme = opts.Theme
+		}
+		filePath := fmt.Sprintf("public/img/rendering_limit_%s.png", theme)
+		return &RenderResult{
+			FilePath: filepath.Join(rs.Cfg.HomePath, filePath),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rs.log.Info("Rendering", "path", opts.Path, "userID", opts.AuthOpts.UserID)
 	if math.IsInf(opts.DeviceScaleFactor, 0) || math.IsNaN(opts.DeviceScaleFactor) || opts.DeviceScaleFactor == 0 {
 		opts.DeviceScaleFactor = 1
@@ -323,7 +347,13 @@ func (rs *RenderingService) render(ctx context.Context, renderType RenderType, o
 	}()
 
 	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderAction(ctx, renderType, renderKey, opts)
+	result, err := withRetry(ctx, rs, renderType, func() (*RenderResult, error) {
+		return rs.renderAction(ctx, renderType, renderKey, opts)
+	})
+	if err == nil && rs.usage != nil {
+		rs.usage.Record(opts.AuthOpts.OrgID, metering.MetricRenderedImages, 1)
+	}
+	return result, err
 }
 
 func (rs *RenderingService) RenderCSV(ctx context.Context, opts CSVOpts, session Session) (*RenderCSVResult, error) {
@@ -360,14 +390,16 @@ func (rs *RenderingService) SanitizeSVG(ctx context.Context, req *SanitizeSVGReq
 }
 
 func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderKeyProvider renderKeyProvider) (*RenderCSVResult, error) {
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
-		return nil, ErrConcurrentLimitReached
-	}
-
 	if !rs.IsAvailable(ctx) {
 		return nil, ErrRenderUnavailable
 	}
 
+	release, err := rs.acquire(ctx, opts.CommonOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rs.log.Info("Rendering", "path", opts.Path)
 	renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
 	if err != nil {
@@ -381,7 +413,46 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderK
 	}()
 
 	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderCSVAction(ctx, renderKey, opts)
+	return withRetry(ctx, rs, RenderCSV, func() (*RenderCSVResult, error) {
+		return rs.renderCSVAction(ctx, renderKey, opts)
+	})
+}
+
+// acquire waits for a free renderer slot in the render queue.
+func (rs *RenderingService) acquire(ctx context.Context, opts CommonOpts) (func(), error) {
+	if rs.queue == nil {
+		return func() {}, nil
+	}
+	limit := opts.ConcurrentLimit
+	if limit <= 0 {
+		limit = rs.Cfg.RendererConcurrentRequestLimit
+	}
+	return rs.queue.acquire(ctx, opts.OrgID, limit, opts.Priority)
+}
+
+// withRetry calls the renderer until it succeeds, fails with an error other than the renderer
+// being unreachable or timing out, or the configured number of attempts is exhausted.
+func withRetry[T any](ctx context.Context, rs *RenderingService, renderType RenderType, fn func() (T, error)) (T, error) {
+	backoff := rs.Cfg.RendererRetryBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= rs.Cfg.RendererRetryMaxAttempts || !isRetryable(err) {
+			return result, err
+		}
+
+		rs.log.Warn("Render request failed, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		metrics.MRenderingRetriesTotal.WithLabelValues(string(renderType)).Inc()
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrServerTimeout) || errors.Is(err, errRendererUnreachable)
 }
 
 func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
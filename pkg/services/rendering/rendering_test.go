@@ -123,9 +123,12 @@ func TestRenderLimitImage(t *testing.T) {
 		Cfg: &setting.Cfg{
 			HomePath: path,
 		},
-		inProgressCount: 2,
+		queue:           newRenderQueue(0, 0),
+		pluginAvailable: true,
 		log:             log.New("test"),
 	}
+	_, err = rs.queue.acquire(context.Background(), 1, 1, PriorityNormal)
+	require.NoError(t, err)
 
 	tests := []struct {
 		name     string
@@ -162,9 +165,12 @@ func TestRenderLimitImage(t *testing.T) {
 func TestRenderLimitImageError(t *testing.T) {
 	rs := RenderingService{
 		Cfg:             &setting.Cfg{},
-		inProgressCount: 2,
+		queue:           newRenderQueue(0, 0),
+		pluginAvailable: true,
 		log:             log.New("test"),
 	}
+	_, err := rs.queue.acquire(context.Background(), 1, 1, PriorityNormal)
+	require.NoError(t, err)
 	opts := Opts{
 		CommonOpts: CommonOpts{ConcurrentLimit: 1},
 		ErrorOpts:  ErrorOpts{ErrorConcurrentLimitReached: true},
@@ -0,0 +1,112 @@
+package scheduledreports
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultRunsLimit = 50
+	maxRunsLimit     = 500
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/scheduled-reports", func(entities routing.RouteRegister) {
+		entities.Get("/", middleware.ReqOrgAdmin, routing.Wrap(s.listHandler))
+		entities.Post("/", middleware.ReqOrgAdmin, routing.Wrap(s.createHandler))
+		entities.Get("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.getHandler))
+		entities.Put("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.updateHandler))
+		entities.Delete("/:uid", middleware.ReqOrgAdmin, routing.Wrap(s.deleteHandler))
+		entities.Post("/:uid/run", middleware.ReqOrgAdmin, routing.Wrap(s.runHandler))
+		entities.Get("/:uid/runs", middleware.ReqOrgAdmin, routing.Wrap(s.listRunsHandler))
+	})
+}
+
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list reports", err)
+	}
+	return response.JSON(http.StatusOK, reports)
+}
+
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	r, err := s.Create(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to create report")
+	}
+	return response.JSON(http.StatusOK, r)
+}
+
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	r, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to get report")
+	}
+	return response.JSON(http.StatusOK, r)
+}
+
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	r, err := s.Update(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to update report")
+	}
+	return response.JSON(http.StatusOK, r)
+}
+
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse(err, "Failed to delete report")
+	}
+	return response.Success("Report deleted")
+}
+
+func (s *Service) runHandler(c *contextmodel.ReqContext) response.Response {
+	run, err := s.RunNow(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse(err, "Failed to run report")
+	}
+	return response.JSON(http.StatusOK, run)
+}
+
+func (s *Service) listRunsHandler(c *contextmodel.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+	runs, err := s.ListRuns(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], limit)
+	if err != nil {
+		return errorResponse(err, "Failed to list report runs")
+	}
+	return response.JSON(http.StatusOK, runs)
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, "Report not found", err)
+	case errors.Is(err, ErrInvalidReport):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrQuotaReached):
+		return response.Error(http.StatusForbidden, "Quota reached", err)
+	default:
+		return response.Error(http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,132 @@
+package scheduledreports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+)
+
+// csvFiles returns a CSV file per panel of a rendered snapshot with data, the frames of a panel following each
+// other separated by an empty line.
+func csvFiles(doc *simplejson.Json) ([]File, error) {
+	files := make([]File, 0)
+	for _, panel := range panels(doc) {
+		var frames data.Frames
+		for _, target := range panel.Get("targets").MustArray() {
+			for _, embedded := range simplejson.NewFromAny(target).Get("snapshot").MustArray() {
+				b, err := json.Marshal(embedded)
+				if err != nil {
+					return nil, err
+				}
+				frame := &data.Frame{}
+				if err := json.Unmarshal(b, frame); err != nil {
+					return nil, err
+				}
+				frames = append(frames, frame)
+			}
+		}
+		if len(frames) == 0 {
+			continue
+		}
+
+		content, err := framesToCSV(frames)
+		if err != nil {
+			return nil, err
+		}
+		id := panel.Get("id").MustInt64()
+		title := panel.Get("title").MustString()
+		if title == "" {
+			title = "panel"
+		}
+		files = append(files, File{
+			Name:        fmt.Sprintf("%s-%d.csv", slugify.Slugify(title), id),
+			ContentType: "text/csv",
+			Content:     content,
+		})
+	}
+	return files, nil
+}
+
+func framesToCSV(frames data.Frames) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for i, frame := range frames {
+		if i > 0 {
+			if err := w.Write(nil); err != nil {
+				return nil, err
+			}
+		}
+		header := make([]string, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			header = append(header, columnName(field))
+		}
+		if err := w.Write(header); err != nil {
+			return nil, err
+		}
+
+		rows, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		for row := 0; row < rows; row++ {
+			record := make([]string, 0, len(frame.Fields))
+			for _, field := range frame.Fields {
+				record = append(record, formatValue(field, row))
+			}
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// columnName returns the name of a field with its labels, such as value {job=api}.
+func columnName(field *data.Field) string {
+	if len(field.Labels) == 0 {
+		return field.Name
+	}
+	pairs := make([]string, 0, len(field.Labels))
+	for name, value := range field.Labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return fmt.Sprintf("%s {%s}", field.Name, strings.Join(pairs, ", "))
+}
+
+func formatValue(field *data.Field, row int) string {
+	value, ok := field.ConcreteAt(row)
+	if !ok {
+		return ""
+	}
+	if t, isTime := value.(time.Time); isTime {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// panels returns the panels of a rendered snapshot, including the panels of the collapsed rows.
+func panels(doc *simplejson.Json) []*simplejson.Json {
+	var result []*simplejson.Json
+	for _, obj := range doc.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(obj)
+		if panel.Get("type").MustString() == "row" {
+			for _, nested := range panel.Get("panels").MustArray() {
+				result = append(result, simplejson.NewFromAny(nested))
+			}
+			continue
+		}
+		result = append(result, panel)
+	}
+	return result
+}
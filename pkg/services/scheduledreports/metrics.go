@@ -0,0 +1,29 @@
+package scheduledreports
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	runs     *prometheus.CounterVec
+	duration prometheus.Histogram
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		runs: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "scheduled_reports",
+			Name:      "runs_total",
+			Help:      "Number of runs of reports by format and result.",
+		}, []string{"format", "result"}),
+		duration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "scheduled_reports",
+			Name:      "run_duration_seconds",
+			Help:      "Duration of the runs of reports, including the delivery.",
+			Buckets:   []float64{.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}),
+	}
+}
@@ -0,0 +1,113 @@
+package scheduledreports
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrInvalidReport  = errors.New("invalid report")
+	ErrQuotaReached   = errors.New("scheduled reports quota reached")
+)
+
+const (
+	QuotaTargetSrv quota.TargetSrv = "scheduled_report"
+	QuotaTarget    quota.Target    = "scheduled_report"
+)
+
+type Format string
+
+const (
+	FormatPDF Format = "pdf"
+	FormatCSV Format = "csv"
+)
+
+type RunStatus string
+
+const (
+	RunStatusSuccess RunStatus = "success"
+	RunStatusFailure RunStatus = "failure"
+)
+
+// Report renders a dashboard or exports the data of its panels on a schedule, and delivers the result by email
+// and webhook.
+type Report struct {
+	ID           int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID          string `xorm:"uid" json:"uid"`
+	OrgID        int64  `xorm:"org_id" json:"orgId"`
+	Name         string `xorm:"name" json:"name"`
+	DashboardUID string `xorm:"dashboard_uid" json:"dashboardUid"`
+	Format       Format `xorm:"format" json:"format"`
+	// From and To are the time range of the report, such as now-24h. The time range of the dashboard is used if
+	// they are empty.
+	From string `xorm:"time_from" json:"from"`
+	To   string `xorm:"time_to" json:"to"`
+	// Variables are the values of the dashboard variables by name.
+	Variables map[string][]string `xorm:"variables" json:"variables"`
+	// PanelIDs limits a CSV report to some panels of the dashboard.
+	PanelIDs []int64 `xorm:"panel_ids" json:"panelIds"`
+	// Schedule is a cron expression such as "0 8 * * 1", or a descriptor such as "@daily", in Timezone.
+	Schedule string `xorm:"schedule" json:"schedule"`
+	Timezone string `xorm:"timezone" json:"timezone"`
+	// Recipients are the email addresses the report is sent to.
+	Recipients []string `xorm:"recipients" json:"recipients"`
+	// WebhookURL is the URL the report is posted to.
+	WebhookURL string `xorm:"webhook_url" json:"webhookUrl"`
+	// FailureRecipients are the email addresses notified of the failed runs. Defaults to the email of the user.
+	FailureRecipients []string `xorm:"failure_recipients" json:"failureRecipients"`
+	Enabled           bool     `xorm:"enabled" json:"enabled"`
+	// NextRun is the time of the next scheduled run in epoch seconds, 0 when the report is disabled.
+	NextRun int64 `xorm:"next_run" json:"nextRun"`
+	// UserID is the user who last saved the report, the report is rendered with their permissions.
+	UserID  int64 `xorm:"user_id" json:"userId"`
+	Created int64 `xorm:"created" json:"created"`
+	Updated int64 `xorm:"updated" json:"updated"`
+}
+
+func (Report) TableName() string {
+	return "scheduled_report"
+}
+
+// ReportCommand creates or updates a report.
+type ReportCommand struct {
+	Name              string              `json:"name"`
+	DashboardUID      string              `json:"dashboardUid"`
+	Format            Format              `json:"format"`
+	From              string              `json:"from"`
+	To                string              `json:"to"`
+	Variables         map[string][]string `json:"variables"`
+	PanelIDs          []int64             `json:"panelIds"`
+	Schedule          string              `json:"schedule"`
+	Timezone          string              `json:"timezone"`
+	Recipients        []string            `json:"recipients"`
+	WebhookURL        string              `json:"webhookUrl"`
+	FailureRecipients []string            `json:"failureRecipients"`
+	Enabled           bool                `json:"enabled"`
+}
+
+// Run is an execution of a report.
+type Run struct {
+	ID        int64     `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID     int64     `xorm:"org_id" json:"-"`
+	ReportUID string    `xorm:"report_uid" json:"reportUid"`
+	Manual    bool      `xorm:"manual" json:"manual"`
+	Status    RunStatus `xorm:"status" json:"status"`
+	Error     string    `xorm:"error_message" json:"error,omitempty"`
+	// Size is the size of the delivered files in bytes.
+	Size     int64 `xorm:"size" json:"size"`
+	Started  int64 `xorm:"started" json:"started"`
+	Finished int64 `xorm:"finished" json:"finished"`
+}
+
+func (Run) TableName() string {
+	return "scheduled_report_run"
+}
+
+// File is a file a report delivers.
+type File struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
@@ -0,0 +1,298 @@
+package scheduledreports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots/render"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	tmplReport        = "scheduled_report"
+	tmplReportFailure = "scheduled_report_failure"
+
+	pdfWidth  = 1000
+	pdfHeight = 500
+)
+
+// webhookPayload is the body posted to the webhook of a report.
+type webhookPayload struct {
+	ReportUID      string        `json:"reportUid"`
+	ReportName     string        `json:"reportName"`
+	DashboardUID   string        `json:"dashboardUid"`
+	DashboardTitle string        `json:"dashboardTitle"`
+	DashboardURL   string        `json:"dashboardUrl"`
+	Format         Format        `json:"format"`
+	Generated      time.Time     `json:"generated"`
+	Files          []webhookFile `json:"files"`
+}
+
+type webhookFile struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	// Content is the base64 encoded content of the file.
+	Content []byte `json:"content"`
+}
+
+// run renders and delivers a report, notifies the failure recipients if it fails, and stores the run.
+func (s *Service) run(ctx context.Context, r *Report, manual bool) *Run {
+	started := s.now()
+	run := &Run{
+		OrgID:     r.OrgID,
+		ReportUID: r.UID,
+		Manual:    manual,
+		Started:   started.Unix(),
+	}
+
+	dash, files, err := s.execute(ctx, r)
+	if err == nil {
+		err = s.deliver(ctx, r, dash, files, started)
+	}
+	run.Finished = s.now().Unix()
+	s.metrics.duration.Observe(s.now().Sub(started).Seconds())
+	if err != nil {
+		s.log.Warn("Report run failed", "uid", r.UID, "orgId", r.OrgID, "error", err)
+		s.metrics.runs.WithLabelValues(string(r.Format), string(RunStatusFailure)).Inc()
+		run.Status = RunStatusFailure
+		run.Error = err.Error()
+		s.notifyFailure(ctx, r, err, started)
+	} else {
+		s.metrics.runs.WithLabelValues(string(r.Format), string(RunStatusSuccess)).Inc()
+		run.Status = RunStatusSuccess
+		for _, f := range files {
+			run.Size += int64(len(f.Content))
+		}
+	}
+
+	if err := s.insertRun(ctx, run); err != nil {
+		s.log.Error("Failed to store report run", "uid", r.UID, "orgId", r.OrgID, "error", err)
+	}
+	return run
+}
+
+// execute renders a report with the permissions of its user.
+func (s *Service) execute(ctx context.Context, r *Report) (*dashboards.Dashboard, []File, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.settings.RenderTimeout)
+	defer cancel()
+
+	usr, err := s.reportUser(ctx, r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the user of the report: %w", err)
+	}
+	canRead, err := s.accessControl.Evaluate(ctx, usr, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(r.DashboardUID)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canRead {
+		return nil, nil, fmt.Errorf("the user of the report can't read the dashboard %q", r.DashboardUID)
+	}
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: r.DashboardUID, OrgID: r.OrgID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []File
+	switch r.Format {
+	case FormatPDF:
+		files, err = s.renderPDF(ctx, usr, r, dash)
+	case FormatCSV:
+		files, err = s.exportCSV(ctx, usr, r, dash)
+	default:
+		err = fmt.Errorf("unknown format %q", r.Format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return dash, files, nil
+}
+
+// reportUser returns the user of a report with their permissions in the organization of the report.
+func (s *Service) reportUser(ctx context.Context, r *Report) (*user.SignedInUser, error) {
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{OrgID: r.OrgID, UserID: r.UserID})
+	if err != nil {
+		return nil, err
+	}
+	if usr.OrgID != r.OrgID {
+		return nil, fmt.Errorf("the user is not a member of the organization")
+	}
+	if usr.Permissions == nil {
+		usr.Permissions = make(map[int64]map[string][]string)
+	}
+	if _, ok := usr.Permissions[r.OrgID]; !ok {
+		permissions, err := s.acService.GetUserPermissions(ctx, usr, ac.Options{ReloadCache: false})
+		if err != nil {
+			return nil, err
+		}
+		usr.Permissions[r.OrgID] = ac.GroupScopesByActionContext(ctx, permissions)
+	}
+	return usr, nil
+}
+
+// renderPDF renders the dashboard of a report as PDF with the rendering service.
+func (s *Service) renderPDF(ctx context.Context, usr *user.SignedInUser, r *Report, dash *dashboards.Dashboard) ([]File, error) {
+	u := url.URL{Path: path.Join("d", dash.UID, dash.Slug)}
+	p := u.Query()
+	p.Add("orgId", strconv.FormatInt(r.OrgID, 10))
+	if r.From != "" && r.To != "" {
+		p.Add("from", r.From)
+		p.Add("to", r.To)
+	}
+	for name, values := range r.Variables {
+		for _, value := range values {
+			p.Add("var-"+name, value)
+		}
+	}
+	p.Add("kiosk", "")
+	u.RawQuery = p.Encode()
+
+	result, err := s.renderService.Render(ctx, rendering.RenderPDF, rendering.Opts{
+		CommonOpts: rendering.CommonOpts{
+			TimeoutOpts: rendering.TimeoutOpts{
+				Timeout: s.settings.RenderTimeout,
+			},
+			AuthOpts: rendering.AuthOpts{
+				OrgID:   r.OrgID,
+				UserID:  usr.UserID,
+				OrgRole: usr.OrgRole,
+			},
+			Path:            u.String(),
+			Timezone:        r.Timezone,
+			ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+			Priority:        rendering.PriorityLow,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		Width:             pdfWidth,
+		Height:            pdfHeight,
+		DeviceScaleFactor: 1,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the dashboard: %w", err)
+	}
+	content, err := os.ReadFile(filepath.Clean(result.FilePath))
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Name: slugify.Slugify(dash.Title) + ".pdf", ContentType: "application/pdf", Content: content}}, nil
+}
+
+// exportCSV executes the queries of the panels of the dashboard of a report and returns their data as a CSV file
+// per panel. The run fails if a query fails, rather than delivering partial data.
+func (s *Service) exportCSV(ctx context.Context, usr *user.SignedInUser, r *Report, dash *dashboards.Dashboard) ([]File, error) {
+	result, err := s.snapshotRenderer.Render(ctx, usr, dash.UID, render.RenderCommand{
+		From:      r.From,
+		To:        r.To,
+		Variables: r.Variables,
+		PanelIDs:  r.PanelIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the panels: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		e := result.Errors[0]
+		return nil, fmt.Errorf("failed to query panel %d: %s", e.PanelID, e.Message)
+	}
+	return csvFiles(result.Dashboard)
+}
+
+// deliver sends the files of a report to its recipients and its webhook.
+func (s *Service) deliver(ctx context.Context, r *Report, dash *dashboards.Dashboard, files []File, generated time.Time) error {
+	dashboardURL := dashboards.GetFullDashboardURL(dash.UID, dash.Slug)
+	var errs []error
+
+	if len(r.Recipients) > 0 {
+		attached := make([]*notifications.SendEmailAttachFile, 0, len(files))
+		for _, f := range files {
+			attached = append(attached, &notifications.SendEmailAttachFile{Name: f.Name, Content: f.Content})
+		}
+		err := s.notifications.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+			SendEmailCommand: notifications.SendEmailCommand{
+				To:       r.Recipients,
+				Template: tmplReport,
+				Data: map[string]any{
+					"ReportName":     r.Name,
+					"DashboardTitle": dash.Title,
+					"DashboardURL":   dashboardURL,
+					"Generated":      generated.UTC().Format(time.RFC1123),
+				},
+				AttachedFiles: attached,
+			},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send the report by email: %w", err))
+		}
+	}
+
+	if r.WebhookURL != "" {
+		payload := webhookPayload{
+			ReportUID:      r.UID,
+			ReportName:     r.Name,
+			DashboardUID:   dash.UID,
+			DashboardTitle: dash.Title,
+			DashboardURL:   dashboardURL,
+			Format:         r.Format,
+			Generated:      generated.UTC(),
+			Files:          make([]webhookFile, 0, len(files)),
+		}
+		for _, f := range files {
+			payload.Files = append(payload.Files, webhookFile{Name: f.Name, ContentType: f.ContentType, Content: f.Content})
+		}
+		body, err := json.Marshal(payload)
+		if err == nil {
+			err = s.notifications.SendWebhookSync(ctx, &notifications.SendWebhookSync{
+				Url:         r.WebhookURL,
+				Body:        string(body),
+				HttpMethod:  http.MethodPost,
+				ContentType: "application/json",
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to post the report to the webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyFailure emails the failure of a run to the failure recipients of a report, or to its user.
+func (s *Service) notifyFailure(ctx context.Context, r *Report, runErr error, started time.Time) {
+	recipients := r.FailureRecipients
+	if len(recipients) == 0 {
+		usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: r.UserID})
+		if err != nil || usr.Email == "" {
+			s.log.Warn("No recipient to notify of the report failure", "uid", r.UID, "orgId", r.OrgID)
+			return
+		}
+		recipients = []string{usr.Email}
+	}
+
+	err := s.notifications.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       recipients,
+		Template: tmplReportFailure,
+		Data: map[string]any{
+			"ReportName":   r.Name,
+			"DashboardUID": r.DashboardUID,
+			"Error":        runErr.Error(),
+			"Started":      started.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		s.log.Error("Failed to notify the report failure", "uid", r.UID, "orgId", r.OrgID, "error", err)
+	}
+}
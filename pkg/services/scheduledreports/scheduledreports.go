@@ -0,0 +1,391 @@
+package scheduledreports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots/render"
+	"github.com/grafana/grafana/pkg/services/jobs"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	runParallel = 2
+	// scheduleChecks is the number of scheduled runs whose interval is checked against the minimum interval.
+	scheduleChecks = 10
+)
+
+// snapshotRenderer renders the panel data of the CSV reports.
+type snapshotRenderer interface {
+	Render(ctx context.Context, user identity.Requester, dashboardUID string, cmd render.RenderCommand) (*render.RenderResult, error)
+}
+
+// Service runs the reports of every organization on their schedule, rendering their dashboard as PDF with the
+// rendering service or exporting the data of its panels as CSV, and delivers them by email and webhook.
+type Service struct {
+	cfg              *setting.Cfg
+	store            db.DB
+	settings         setting.ScheduledReportsSettings
+	accessControl    ac.AccessControl
+	acService        ac.Service
+	userService      user.Service
+	dashboardService dashboards.DashboardService
+	renderService    rendering.Service
+	snapshotRenderer snapshotRenderer
+	notifications    notifications.Service
+	quotaService     quota.Service
+	metrics          *metrics
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	acService ac.Service, userService user.Service, dashboardService dashboards.DashboardService,
+	renderService rendering.Service, snapshotRenderer *render.Service, notificationService notifications.Service,
+	quotaService quota.Service, jobService *jobs.Service, registerer prometheus.Registerer,
+) (*Service, error) {
+	s := &Service{
+		cfg:              cfg,
+		store:            sqlStore,
+		settings:         cfg.ScheduledReports,
+		accessControl:    accessControl,
+		acService:        acService,
+		userService:      userService,
+		dashboardService: dashboardService,
+		renderService:    renderService,
+		snapshotRenderer: snapshotRenderer,
+		notifications:    notificationService,
+		quotaService:     quotaService,
+		metrics:          newMetrics(registerer),
+		log:              log.New("scheduled-reports"),
+		now:              time.Now,
+	}
+	if !s.settings.Enabled {
+		return s, nil
+	}
+
+	defaultLimits, err := readQuotaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     QuotaTargetSrv,
+		DefaultLimits: defaultLimits,
+		Reporter:      s.Usage,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.registerJobs(jobService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s, nil
+}
+
+func (s *Service) Usage(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	return s.count(ctx, scopeParams)
+}
+
+// registerJobs registers the jobs running the reports that are due and deleting the expired run history.
+func (s *Service) registerJobs(jobService *jobs.Service) error {
+	if err := jobService.Register(jobs.Definition{
+		Name:        "scheduled-reports.run-due",
+		Description: "Runs the scheduled reports whose next run has passed.",
+		Schedule:    "@every 1m",
+		Timeout:     15 * time.Minute,
+		Handler: func(ctx context.Context, _ []byte) error {
+			return s.runDue(ctx)
+		},
+	}); err != nil {
+		return err
+	}
+	return jobService.Register(jobs.Definition{
+		Name:        "scheduled-reports.delete-expired-runs",
+		Description: "Deletes the report runs older than the run history retention.",
+		Schedule:    "@hourly",
+		MaxAttempts: 3,
+		Handler: func(ctx context.Context, _ []byte) error {
+			deleted, err := s.deleteRunsBefore(ctx, s.now().Add(-s.settings.RunHistoryRetention).Unix())
+			if err != nil {
+				return fmt.Errorf("failed to delete the expired report runs: %w", err)
+			}
+			if deleted > 0 {
+				s.log.Debug("Deleted expired report runs", "count", deleted)
+			}
+			return nil
+		},
+	})
+}
+
+// Create validates and stores a new report, within the quota of the organization.
+func (s *Service) Create(ctx context.Context, user identity.Requester, cmd ReportCommand) (*Report, error) {
+	orgID := user.GetOrgID()
+	reached, err := s.quotaService.CheckQuotaReached(ctx, QuotaTargetSrv, &quota.ScopeParameters{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	if reached {
+		return nil, ErrQuotaReached
+	}
+
+	now := s.now().Unix()
+	r := &Report{
+		UID:     util.GenerateShortUID(),
+		OrgID:   orgID,
+		Created: now,
+		Updated: now,
+	}
+	if err := s.apply(ctx, user, r, cmd); err != nil {
+		return nil, err
+	}
+	if err := s.insert(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Update replaces the definition of a report, which is then rendered with the permissions of the user.
+func (s *Service) Update(ctx context.Context, user identity.Requester, uid string, cmd ReportCommand) (*Report, error) {
+	r, err := s.get(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, user, r, cmd); err != nil {
+		return nil, err
+	}
+	r.Updated = s.now().Unix()
+	if err := s.update(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Delete deletes a report and its run history.
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.delete(ctx, orgID, uid)
+}
+
+// Get returns a report of an organization.
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	return s.get(ctx, orgID, uid)
+}
+
+// List returns the reports of an organization, sorted by name.
+func (s *Service) List(ctx context.Context, orgID int64) ([]*Report, error) {
+	return s.list(ctx, orgID)
+}
+
+// ListRuns returns the last runs of a report, the most recent first.
+func (s *Service) ListRuns(ctx context.Context, orgID int64, uid string, limit int) ([]*Run, error) {
+	if _, err := s.get(ctx, orgID, uid); err != nil {
+		return nil, err
+	}
+	return s.listRuns(ctx, orgID, uid, limit)
+}
+
+// RunNow runs a report outside of its schedule and returns the run.
+func (s *Service) RunNow(ctx context.Context, orgID int64, uid string) (*Run, error) {
+	r, err := s.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, r, true), nil
+}
+
+// apply validates the command and sets its fields on the report, with the user as the user of the report.
+func (s *Service) apply(ctx context.Context, user identity.Requester, r *Report, cmd ReportCommand) error {
+	if strings.TrimSpace(cmd.Name) == "" {
+		return fmt.Errorf("%w: the name is required", ErrInvalidReport)
+	}
+	switch cmd.Format {
+	case FormatPDF:
+		if len(cmd.PanelIDs) > 0 {
+			return fmt.Errorf("%w: the panels can only be selected for CSV reports", ErrInvalidReport)
+		}
+	case FormatCSV:
+	default:
+		return fmt.Errorf("%w: the format must be %q or %q", ErrInvalidReport, FormatPDF, FormatCSV)
+	}
+	if (cmd.From == "") != (cmd.To == "") {
+		return fmt.Errorf("%w: both from and to are required to set the time range", ErrInvalidReport)
+	}
+	if cmd.Timezone == "" {
+		cmd.Timezone = "UTC"
+	}
+	schedule, err := parseSchedule(cmd.Schedule, cmd.Timezone)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidReport, err)
+	}
+	if err := checkInterval(schedule, s.now(), s.settings.MinInterval); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidReport, err)
+	}
+	if len(cmd.Recipients) == 0 && cmd.WebhookURL == "" {
+		return fmt.Errorf("%w: at least one recipient or a webhook URL is required", ErrInvalidReport)
+	}
+	for _, addresses := range [][]string{cmd.Recipients, cmd.FailureRecipients} {
+		for _, address := range addresses {
+			if _, err := mail.ParseAddress(address); err != nil {
+				return fmt.Errorf("%w: %q is not a valid email address", ErrInvalidReport, address)
+			}
+		}
+	}
+	if cmd.WebhookURL != "" {
+		u, err := url.Parse(cmd.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %q is not a valid webhook URL", ErrInvalidReport, cmd.WebhookURL)
+		}
+	}
+	if err := s.checkDashboard(ctx, user, cmd.DashboardUID); err != nil {
+		return err
+	}
+
+	r.Name = cmd.Name
+	r.DashboardUID = cmd.DashboardUID
+	r.Format = cmd.Format
+	r.From = cmd.From
+	r.To = cmd.To
+	r.Variables = cmd.Variables
+	r.PanelIDs = cmd.PanelIDs
+	r.Schedule = cmd.Schedule
+	r.Timezone = cmd.Timezone
+	r.Recipients = cmd.Recipients
+	r.WebhookURL = cmd.WebhookURL
+	r.FailureRecipients = cmd.FailureRecipients
+	r.Enabled = cmd.Enabled
+	r.NextRun = 0
+	if r.Enabled {
+		r.NextRun = schedule.Next(s.now()).Unix()
+	}
+	if id, err := identity.UserIdentifier(user.GetID()); err == nil {
+		r.UserID = id
+	}
+	return nil
+}
+
+// checkDashboard returns an error if the dashboard doesn't exist or the user can't read it.
+func (s *Service) checkDashboard(ctx context.Context, user identity.Requester, dashboardUID string) error {
+	notFound := fmt.Errorf("%w: dashboard %q not found", ErrInvalidReport, dashboardUID)
+	if dashboardUID == "" {
+		return fmt.Errorf("%w: the dashboard is required", ErrInvalidReport)
+	}
+	canRead, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)))
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return notFound
+	}
+	if _, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: user.GetOrgID()}); err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return notFound
+		}
+		return err
+	}
+	return nil
+}
+
+// runDue runs the enabled reports whose next run has passed, moving their next run first so that they are run
+// once when several instances share the database.
+func (s *Service) runDue(ctx context.Context) error {
+	now := s.now()
+	reports, err := s.listDue(ctx, now.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	due := make([]*Report, 0, len(reports))
+	for _, r := range reports {
+		schedule, err := parseSchedule(r.Schedule, r.Timezone)
+		if err != nil {
+			s.log.Warn("Skipping report with an invalid schedule", "uid", r.UID, "orgId", r.OrgID, "error", err)
+			continue
+		}
+		claimed, err := s.claim(ctx, r, schedule.Next(now).Unix())
+		if err != nil {
+			return fmt.Errorf("failed to schedule report: %w", err)
+		}
+		if claimed {
+			due = append(due, r)
+		}
+	}
+
+	return concurrency.ForEachJob(ctx, len(due), runParallel, func(ctx context.Context, idx int) error {
+		s.run(ctx, due[idx], false)
+		return nil
+	})
+}
+
+// parseSchedule parses the cron expression of a report in its time zone.
+func parseSchedule(spec, timezone string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("the schedule is required")
+	}
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, fmt.Errorf("the time zone of the schedule is set with the timezone field")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", timezone)
+	}
+	schedule, err := cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", timezone, spec))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %s", spec, err)
+	}
+	return schedule, nil
+}
+
+// checkInterval returns an error if the next scheduled runs are closer than the minimum interval.
+func checkInterval(schedule cron.Schedule, now time.Time, minInterval time.Duration) error {
+	next := schedule.Next(now)
+	for i := 0; i < scheduleChecks; i++ {
+		after := schedule.Next(next)
+		if after.IsZero() {
+			break
+		}
+		if after.Sub(next) < minInterval {
+			return fmt.Errorf("the schedule must run at most every %s", minInterval)
+		}
+		next = after
+	}
+	if next.IsZero() {
+		return fmt.Errorf("the schedule never runs")
+	}
+	return nil
+}
+
+func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+
+	globalQuotaTag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.GlobalScope)
+	if err != nil {
+		return limits, err
+	}
+	orgQuotaTag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+
+	limits.Set(globalQuotaTag, cfg.Quota.Global.ScheduledReport)
+	limits.Set(orgQuotaTag, cfg.Quota.Org.ScheduledReport)
+	return limits, nil
+}
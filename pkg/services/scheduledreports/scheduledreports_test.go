@@ -0,0 +1,182 @@
+package scheduledreports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots/render"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC) // a Monday
+
+	schedule, err := parseSchedule("0 8 * * 1", "Europe/Paris")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC), schedule.Next(now).UTC(), "the schedule is in the time zone of the report")
+	require.NoError(t, checkInterval(schedule, now, time.Hour))
+
+	schedule, err = parseSchedule("*/10 * * * *", "UTC")
+	require.NoError(t, err)
+	require.Error(t, checkInterval(schedule, now, time.Hour))
+
+	for _, spec := range []string{"", "CRON_TZ=UTC @daily", "every day", "0 8 * *"} {
+		_, err := parseSchedule(spec, "UTC")
+		require.Error(t, err, spec)
+	}
+	_, err = parseSchedule("@daily", "Mars/Olympus")
+	require.Error(t, err)
+}
+
+func TestCSVFiles(t *testing.T) {
+	frame := data.NewFrame("up",
+		data.NewField("time", nil, []time.Time{time.Unix(1700000000, 0)}),
+		data.NewField("value", data.Labels{"job": "api"}, []*float64{nil}),
+	)
+	b, err := json.Marshal(frame)
+	require.NoError(t, err)
+	var embedded any
+	require.NoError(t, json.Unmarshal(b, &embedded))
+
+	doc := simplejson.NewFromAny(map[string]any{"panels": []any{
+		map[string]any{"id": 1, "title": "Up", "targets": []any{map[string]any{"snapshot": []any{embedded, embedded}}}},
+		map[string]any{"id": 2, "type": "row", "panels": []any{
+			map[string]any{"id": 3, "targets": []any{map[string]any{"snapshot": []any{embedded}}}},
+		}},
+		map[string]any{"id": 4, "type": "text"},
+	}})
+
+	files, err := csvFiles(doc)
+	require.NoError(t, err)
+	require.Len(t, files, 2, "the panels without data have no file")
+	require.Equal(t, "up-1.csv", files[0].Name)
+	require.Equal(t, "time,value {job=api}\n2023-11-14T22:13:20Z,\n\ntime,value {job=api}\n2023-11-14T22:13:20Z,\n", string(files[0].Content))
+	require.Equal(t, "panel-3.csv", files[1].Name, "the panels of the collapsed rows are exported")
+}
+
+type fakeSnapshotRenderer struct {
+	result *render.RenderResult
+	err    error
+}
+
+func (f *fakeSnapshotRenderer) Render(ctx context.Context, user identity.Requester, dashboardUID string, cmd render.RenderCommand) (*render.RenderResult, error) {
+	return f.result, f.err
+}
+
+func TestIntegrationReports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: "dash", OrgID: 1, Title: "Dash", Slug: "dash"}, nil)
+	notificationService := notifications.MockNotificationService()
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	s := &Service{
+		cfg:              setting.NewCfg(),
+		store:            db.InitTestDB(t),
+		settings:         setting.ScheduledReportsSettings{MinInterval: time.Hour, RenderTimeout: time.Minute},
+		accessControl:    actest.FakeAccessControl{ExpectedEvaluate: true},
+		acService:        actest.FakeService{},
+		userService:      &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin}, ExpectedUser: &user.User{ID: 1, Email: "owner@example.com"}},
+		dashboardService: dashboardService,
+		snapshotRenderer: &fakeSnapshotRenderer{result: &render.RenderResult{Dashboard: simplejson.NewFromAny(map[string]any{"panels": []any{}})}},
+		notifications:    notificationService,
+		quotaService:     quotatest.New(false, nil),
+		metrics:          newMetrics(nil),
+		log:              log.NewNopLogger(),
+		now:              func() time.Time { return now },
+	}
+	ctx := context.Background()
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin}
+	cmd := ReportCommand{
+		Name:         "Weekly",
+		DashboardUID: "dash",
+		Format:       FormatCSV,
+		Schedule:     "0 8 * * 1",
+		Recipients:   []string{"team@example.com"},
+		WebhookURL:   "https://example.com/reports",
+		Enabled:      true,
+	}
+
+	created, err := s.Create(ctx, signedInUser, cmd)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC).Unix(), created.NextRun)
+	require.Equal(t, int64(1), created.UserID)
+
+	invalid := cmd
+	invalid.Recipients, invalid.WebhookURL = nil, ""
+	_, err = s.Create(ctx, signedInUser, invalid)
+	require.ErrorIs(t, err, ErrInvalidReport)
+
+	s.quotaService = quotatest.New(true, nil)
+	_, err = s.Create(ctx, signedInUser, cmd)
+	require.ErrorIs(t, err, ErrQuotaReached)
+
+	t.Run("runs the due reports once", func(t *testing.T) {
+		require.NoError(t, s.runDue(ctx))
+		runs, err := s.ListRuns(ctx, 1, created.UID, 10)
+		require.NoError(t, err)
+		require.Empty(t, runs, "the report is not due yet")
+
+		now = now.Add(7 * 24 * time.Hour)
+		require.NoError(t, s.runDue(ctx))
+		require.NoError(t, s.runDue(ctx))
+		runs, err = s.ListRuns(ctx, 1, created.UID, 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, RunStatusSuccess, runs[0].Status)
+		require.False(t, runs[0].Manual)
+		require.Equal(t, []string{"team@example.com"}, notificationService.EmailSync.To)
+		require.Equal(t, "https://example.com/reports", notificationService.Webhook.Url)
+
+		report, err := s.Get(ctx, 1, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, 6, 17, 8, 0, 0, 0, time.UTC).Unix(), report.NextRun)
+	})
+
+	t.Run("notifies the failed runs", func(t *testing.T) {
+		notificationService.WebhookHandler = func(context.Context, *notifications.SendWebhookSync) error {
+			return errors.New("webhook unavailable")
+		}
+		run, err := s.RunNow(ctx, 1, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, RunStatusFailure, run.Status)
+		require.True(t, run.Manual)
+		require.Contains(t, run.Error, "webhook unavailable")
+		require.Equal(t, tmplReportFailure, notificationService.Email.Template)
+		require.Equal(t, []string{"owner@example.com"}, notificationService.Email.To, "the failures are sent to the user without failure recipients")
+	})
+
+	t.Run("deletes the run history with the report", func(t *testing.T) {
+		require.NoError(t, s.Delete(ctx, 1, created.UID))
+		_, err := s.ListRuns(ctx, 1, created.UID, 10)
+		require.ErrorIs(t, err, ErrReportNotFound)
+		deleted, err := s.deleteRunsBefore(ctx, now.Unix())
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+}
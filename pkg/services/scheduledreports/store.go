@@ -0,0 +1,150 @@
+package scheduledreports
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+func (s *Service) insert(ctx context.Context, r *Report) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(r)
+		return err
+	})
+}
+
+func (s *Service) update(ctx context.Context, r *Report) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", r.OrgID, r.UID).AllCols().Omit("id", "created").Update(r)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Service) get(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	r := &Report{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(r)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Service) list(ctx context.Context, orgID int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+// listDue returns the enabled reports whose next run is at or before now, in epoch seconds.
+func (s *Service) listDue(ctx context.Context, now int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND next_run <= ?", true, now).Find(&reports)
+	})
+	return reports, err
+}
+
+// claim moves the next run of a report, and returns false if another instance already moved it.
+func (s *Service) claim(ctx context.Context, r *Report, nextRun int64) (bool, error) {
+	claimed := false
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE scheduled_report SET next_run = ? WHERE id = ? AND next_run = ?", nextRun, r.ID, r.NextRun)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	return claimed, err
+}
+
+func (s *Service) delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+			affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Report{})
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				return ErrReportNotFound
+			}
+			_, err = sess.Where("org_id = ? AND report_uid = ?", orgID, uid).Delete(&Run{})
+			return err
+		})
+	})
+}
+
+func (s *Service) insertRun(ctx context.Context, run *Run) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(run)
+		return err
+	})
+}
+
+// listRuns returns the last runs of a report, the most recent first.
+func (s *Service) listRuns(ctx context.Context, orgID int64, uid string, limit int) ([]*Run, error) {
+	runs := make([]*Run, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND report_uid = ?", orgID, uid).Desc("started").Desc("id").Limit(limit).Find(&runs)
+	})
+	return runs, err
+}
+
+// deleteRunsBefore deletes the runs started before a time in epoch seconds.
+func (s *Service) deleteRunsBefore(ctx context.Context, before int64) (int64, error) {
+	var affected int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affected, err = sess.Where("started < ?", before).Delete(&Run{})
+		return err
+	})
+	return affected, err
+}
+
+func (s *Service) count(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		count, err := sess.Count(&Report{})
+		if err != nil {
+			return err
+		}
+		tag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.GlobalScope)
+		if err != nil {
+			return err
+		}
+		u.Set(tag, count)
+
+		if scopeParams != nil && scopeParams.OrgID != 0 {
+			count, err := sess.Where("org_id = ?", scopeParams.OrgID).Count(&Report{})
+			if err != nil {
+				return err
+			}
+			tag, err := quota.NewTag(QuotaTargetSrv, QuotaTarget, quota.OrgScope)
+			if err != nil {
+				return err
+			}
+			u.Set(tag, count)
+		}
+		return nil
+	})
+	return u, err
+}
@@ -117,6 +117,7 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 				Timeout: opts.Timeout,
 			},
 			ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+			Priority:        rendering.PriorityHigh,
 			Path:            u.String(),
 		},
 		ErrorOpts: rendering.ErrorOpts{
@@ -41,6 +41,14 @@ type FilterSelect interface {
 	Select() string
 }
 
+// FilterParams returns the parameters of the SQL of a FilterSelect or
+// FilterOrderBy, like the searched title when ordering by relevance.
+// They are bound to every occurrence of that SQL in the query, so a
+// filter with parameters can't be combined with a FilterGroupBy.
+type FilterParams interface {
+	Params() []any
+}
+
 type SortOption struct {
 	Name        string
 	DisplayName string
@@ -80,6 +88,8 @@ type Hit struct {
 	SortMetaName          string     `json:"sortMetaName,omitempty"`
 	IsDeleted             bool       `json:"isDeleted"`
 	PermanentlyDeleteDate *time.Time `json:"permanentlyDeleteDate,omitempty"`
+	// LintWarnings is the number of lint warnings of the dashboard when it was last saved.
+	LintWarnings int `json:"lintWarnings,omitempty"`
 }
 
 type HitList []*Hit
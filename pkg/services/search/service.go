@@ -2,13 +2,18 @@ package search
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"sort"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -17,12 +22,18 @@ import (
 
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/search")
 
+var ErrInvalidContinueToken = errors.New("invalid continue token")
+
+// defaultLimit is the number of hits of a page when the query has no limit, see the dashboard store.
+const defaultLimit = 1000
+
 func ProvideService(cfg *setting.Cfg, sqlstore db.DB, starService star.Service, dashboardService dashboards.DashboardService) *SearchService {
 	s := &SearchService{
 		Cfg: cfg,
 		sortOptions: map[string]model.SortOption{
 			SortAlphaAsc.Name:  SortAlphaAsc,
 			SortAlphaDesc.Name: SortAlphaDesc,
+			SortRelevance.Name: SortRelevance,
 		},
 		sqlstore:         sqlstore,
 		starService:      starService,
@@ -48,6 +59,9 @@ type Query struct {
 	FolderUIDs []string
 	Permission dashboardaccess.PermissionType
 	Sort       string
+	// ContinueToken is the token of the next page of results sorted by relevance, see NextContinueToken.
+	// Page is ignored when it is set.
+	ContinueToken string
 }
 
 type Service interface {
@@ -103,7 +117,16 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) (model.
 		IsDeleted:     query.IsDeleted,
 	}
 
-	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
+	if query.Sort == SortRelevance.Name {
+		sortOpt, err := s.relevanceSortOption(query)
+		if err != nil {
+			return nil, err
+		}
+		dashboardQuery.Sort = sortOpt
+		if query.ContinueToken != "" {
+			dashboardQuery.Page = 1
+		}
+	} else if sortOpt, exists := s.sortOptions[query.Sort]; exists {
 		dashboardQuery.Sort = sortOpt
 	}
 
@@ -136,6 +159,59 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) (model.
 	return result, nil
 }
 
+// relevanceSortOption returns the relevance sort option for the searched title, starting after the hit of the
+// continue token of the query.
+func (s *SearchService) relevanceSortOption(query *Query) (model.SortOption, error) {
+	sorter := searchstore.RelevanceSorter{
+		Dialect: s.sqlstore.GetDialect(),
+		OrgID:   query.SignedInUser.GetOrgID(),
+		Title:   query.Title,
+		Now:     time.Now(),
+	}
+	if query.ContinueToken != "" {
+		after, err := parseContinueToken(query.ContinueToken)
+		if err != nil {
+			return model.SortOption{}, err
+		}
+		sorter.After = after
+	}
+
+	sortOpt := SortRelevance
+	sortOpt.Filter = []model.SortOptionFilter{sorter}
+	return sortOpt, nil
+}
+
+// NextContinueToken returns the token of the page after hits when they are sorted by relevance, or an empty
+// string if hits is the last page.
+func NextContinueToken(query *Query, hits model.HitList) string {
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if query.Sort != SortRelevance.Name || int64(len(hits)) < limit {
+		return ""
+	}
+
+	last := hits[len(hits)-1]
+	token, err := json.Marshal(searchstore.RelevanceCursor{Score: last.SortMeta, Title: last.Title, ID: last.ID})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func parseContinueToken(token string) (*searchstore.RelevanceCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidContinueToken
+	}
+	cursor := &searchstore.RelevanceCursor{}
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return nil, ErrInvalidContinueToken
+	}
+	return cursor, nil
+}
+
 func sortedHits(unsorted model.HitList) model.HitList {
 	hits := make(model.HitList, 0)
 	hits = append(hits, unsorted...)
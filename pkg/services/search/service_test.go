@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -92,3 +93,35 @@ func TestSearch_StarredResults(t *testing.T) {
 	assert.Equal(t, "A", hits[0].Title)
 	assert.Equal(t, "C", hits[1].Title)
 }
+
+func TestSearch_RelevanceContinueToken(t *testing.T) {
+	ss := startest.NewStarServiceFake()
+	ds := dashboards.NewFakeDashboardService(t)
+	ds.On("SearchDashboards", mock.Anything, mock.MatchedBy(func(q *dashboards.FindPersistedDashboardsQuery) bool {
+		sorter, ok := q.Sort.Filter[0].(searchstore.RelevanceSorter)
+		return ok && q.Page == 1 && sorter.Title == "cpu" && sorter.After != nil && *sorter.After == searchstore.RelevanceCursor{Score: 330, Title: "CPU usage", ID: 2}
+	})).Return(model.HitList{}, nil)
+	ss.ExpectedUserStars = &star.GetUserStarsResult{UserStars: map[int64]bool{}}
+	svc := &SearchService{
+		sqlstore:         dbtest.NewFakeDB(),
+		starService:      ss,
+		dashboardService: ds,
+	}
+
+	query := &Query{Title: "cpu", Limit: 2, Sort: SortRelevance.Name, SignedInUser: &user.SignedInUser{OrgID: 1}}
+	hits := model.HitList{
+		&model.Hit{ID: 1, Title: "cpu", SortMeta: 420},
+		&model.Hit{ID: 2, Title: "CPU usage", SortMeta: 330},
+	}
+	require.Empty(t, NextContinueToken(query, hits[:1]), "the last page has no continue token")
+	query.ContinueToken = NextContinueToken(query, hits)
+	require.NotEmpty(t, query.ContinueToken)
+	query.Page = 3
+
+	_, err := svc.SearchHandler(context.Background(), query)
+	require.NoError(t, err)
+
+	query.ContinueToken = "not a token"
+	_, err = svc.SearchHandler(context.Background(), query)
+	require.ErrorIs(t, err, ErrInvalidContinueToken)
+}
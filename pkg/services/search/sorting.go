@@ -26,6 +26,15 @@ var (
 			searchstore.TitleSorter{Descending: true},
 		},
 	}
+	// SortRelevance has no filter of its own, the search service builds a searchstore.RelevanceSorter for the
+	// searched title when it is requested.
+	SortRelevance = model.SortOption{
+		Name:        "relevance",
+		DisplayName: "Relevance",
+		Description: "Sort results by title match, usage and recency",
+		Index:       0,
+		MetaName:    "relevance",
+	}
 )
 
 // RegisterSortOption allows for hooking in more search options from
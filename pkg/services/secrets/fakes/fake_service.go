@@ -48,6 +48,10 @@ func (f FakeSecretsService) ReEncryptDataKeys(_ context.Context) error {
 	return nil
 }
 
+func (f FakeSecretsService) GetEncryptionStatus(_ context.Context) (*secrets.EncryptionStatus, error) {
+	return &secrets.EncryptionStatus{}, nil
+}
+
 func (f FakeSecretsService) CurrentProviderID() string {
 	return "fakeProvider"
 }
@@ -132,6 +132,32 @@ func (_m *MockService) GetDecryptedValue(ctx context.Context, sjd map[string][]b
 	return r0
 }
 
+// GetEncryptionStatus provides a mock function with given fields: ctx
+func (_m *MockService) GetEncryptionStatus(ctx context.Context) (*secrets.EncryptionStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *secrets.EncryptionStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*secrets.EncryptionStatus, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *secrets.EncryptionStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*secrets.EncryptionStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReEncryptDataKeys provides a mock function with given fields: ctx
 func (_m *MockService) ReEncryptDataKeys(ctx context.Context) error {
 	ret := _m.Called(ctx)
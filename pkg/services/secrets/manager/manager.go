@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -499,6 +500,71 @@ func (s *SecretsService) ReEncryptDataKeys(ctx context.Context) error {
 	return nil
 }
 
+func (s *SecretsService) GetEncryptionStatus(ctx context.Context) (*secrets.EncryptionStatus, error) {
+	keys, err := s.store.GetAllDataKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &secrets.EncryptionStatus{
+		CurrentProvider: s.currentProviderID,
+		Providers:       make([]secrets.ProviderID, 0, len(s.providers)),
+		DataKeys:        []secrets.DataKeysStatus{},
+	}
+	for id := range s.providers {
+		status.Providers = append(status.Providers, id)
+	}
+	sort.Slice(status.Providers, func(i, j int) bool { return status.Providers[i] < status.Providers[j] })
+
+	byProvider := make(map[secrets.ProviderID]*secrets.DataKeysStatus)
+	for _, k := range keys {
+		id := kmsproviders.NormalizeProviderID(k.Provider)
+		ks, ok := byProvider[id]
+		if !ok {
+			_, configured := s.providers[id]
+			ks = &secrets.DataKeysStatus{Provider: id, Configured: configured}
+			byProvider[id] = ks
+		}
+
+		if k.Active {
+			ks.Active++
+		} else {
+			ks.Inactive++
+		}
+		if k.Created.After(ks.LastCreated) {
+			ks.LastCreated = k.Created
+		}
+		if id != s.currentProviderID {
+			status.ReEncryptionPending = true
+		}
+	}
+
+	for _, ks := range byProvider {
+		status.DataKeys = append(status.DataKeys, *ks)
+	}
+	sort.Slice(status.DataKeys, func(i, j int) bool { return status.DataKeys[i].Provider < status.DataKeys[j].Provider })
+
+	return status, nil
+}
+
+// reEncryptOnProviderChange re-encrypts the data keys encrypted by a previous provider with the current one,
+// so that switching providers doesn't require running the re-encryption manually.
+func (s *SecretsService) reEncryptOnProviderChange(ctx context.Context) {
+	status, err := s.GetEncryptionStatus(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the data keys encryption status", "error", err)
+		return
+	}
+	if !status.ReEncryptionPending {
+		return
+	}
+
+	s.log.Info("Data keys encrypted by a previous encryption provider found, re-encrypting them", "current provider", s.currentProviderID)
+	if err := s.ReEncryptDataKeys(ctx); err != nil {
+		s.log.Error("Failed to re-encrypt data keys with the current encryption provider", "error", err)
+	}
+}
+
 func (s *SecretsService) Run(ctx context.Context) error {
 	gc := time.NewTicker(
 		s.cfg.SectionWithEnvOverrides("security.encryption").Key("data_keys_cache_cleanup_interval").
@@ -515,6 +581,14 @@ func (s *SecretsService) Run(ctx context.Context) error {
 		}
 	}
 
+	reEncrypt := s.cfg.SectionWithEnvOverrides("security.encryption").Key("reencrypt_data_keys_on_provider_change").MustBool(true)
+	if reEncrypt && s.providersInitialized() {
+		grp.Go(func() error {
+			s.reEncryptOnProviderChange(gCtx)
+			return nil
+		})
+	}
+
 	for {
 		select {
 		case <-gc.C:
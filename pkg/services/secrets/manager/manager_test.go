@@ -370,6 +370,88 @@ func TestSecretsService_ReEncryptDataKeys(t *testing.T) {
 	})
 }
 
+func TestSecretsService_GetEncryptionStatus(t *testing.T) {
+	ctx := context.Background()
+	testDB := db.InitTestDB(t)
+	store := database.ProvideSecretsStore(testDB)
+	svc := SetupTestService(t, store)
+
+	_, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+
+	status, err := svc.GetEncryptionStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, secrets.ProviderID("secretKey.v1"), status.CurrentProvider)
+	assert.Equal(t, []secrets.ProviderID{"secretKey.v1"}, status.Providers)
+	require.Len(t, status.DataKeys, 1)
+	assert.Equal(t, 1, status.DataKeys[0].Active)
+	assert.True(t, status.DataKeys[0].Configured)
+	assert.False(t, status.ReEncryptionPending)
+
+	t.Run("should report data keys of providers missing from the configuration", func(t *testing.T) {
+		require.NoError(t, store.CreateDataKey(ctx, &secrets.DataKey{
+			Id:            util.GenerateShortUID(),
+			Label:         "2024-01-01/root@awskms.old",
+			Scope:         "root",
+			Provider:      "awskms.old",
+			EncryptedData: []byte("data key"),
+		}))
+
+		status, err := svc.GetEncryptionStatus(ctx)
+		require.NoError(t, err)
+		require.Len(t, status.DataKeys, 2)
+		assert.Equal(t, secrets.ProviderID("awskms.old"), status.DataKeys[0].Provider)
+		assert.False(t, status.DataKeys[0].Configured)
+		assert.True(t, status.ReEncryptionPending)
+	})
+}
+
+func TestSecretsService_ReEncryptOnProviderChange(t *testing.T) {
+	ctx := context.Background()
+	testDB := db.InitTestDB(t)
+	store := database.ProvideSecretsStore(testDB)
+
+	// Encrypt with the default provider to generate a data encryption key
+	_, err := SetupTestService(t, store).Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+
+	raw, err := ini.Load([]byte(`
+		[security]
+		secret_key = SdlklWklckeLS
+		encryption_provider = fakeProvider.v1`))
+	require.NoError(t, err)
+	cfg := &setting.Cfg{Raw: raw}
+
+	encryptionService, err := encryptionservice.ProvideEncryptionService(tracing.InitializeTracerForTest(), encryptionprovider.Provider{}, &usagestats.UsageStatsMock{}, cfg)
+	require.NoError(t, err)
+
+	features := featuremgmt.WithFeatures()
+	kms := newFakeKMS(osskmsproviders.ProvideService(encryptionService, cfg, features))
+	svc, err := ProvideSecretsService(
+		tracing.InitializeTracerForTest(),
+		store,
+		&kms,
+		encryptionService,
+		cfg,
+		features,
+		&usagestats.UsageStatsMock{T: t},
+	)
+	require.NoError(t, err)
+
+	status, err := svc.GetEncryptionStatus(ctx)
+	require.NoError(t, err)
+	require.True(t, status.ReEncryptionPending)
+
+	svc.reEncryptOnProviderChange(ctx)
+	assert.True(t, kms.fake.encryptCalled)
+
+	status, err = svc.GetEncryptionStatus(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.ReEncryptionPending)
+	require.Len(t, status.DataKeys, 1)
+	assert.Equal(t, secrets.ProviderID("fakeProvider.v1"), status.DataKeys[0].Provider)
+}
+
 func TestSecretsService_Decrypt(t *testing.T) {
 	ctx := context.Background()
 	testDB := db.InitTestDB(t)
@@ -32,6 +32,10 @@ type Service interface {
 
 	RotateDataKeys(ctx context.Context) error
 	ReEncryptDataKeys(ctx context.Context) error
+
+	// GetEncryptionStatus reports which providers encrypt the data keys, to follow key rotations
+	// and the re-encryption of data keys after switching providers.
+	GetEncryptionStatus(ctx context.Context) (*EncryptionStatus, error)
 }
 
 // Store defines methods to interact with secrets storage
@@ -18,6 +18,26 @@ type DataKey struct {
 	Updated       time.Time
 }
 
+// EncryptionStatus describes the encryption providers and the data keys encrypted by each of them.
+type EncryptionStatus struct {
+	CurrentProvider ProviderID       `json:"currentProvider"`
+	Providers       []ProviderID     `json:"providers"`
+	DataKeys        []DataKeysStatus `json:"dataKeys"`
+	// ReEncryptionPending is true while some data keys are encrypted by another provider than the current one.
+	ReEncryptionPending bool `json:"reEncryptionPending"`
+}
+
+// DataKeysStatus counts the data keys encrypted by a provider.
+type DataKeysStatus struct {
+	Provider ProviderID `json:"provider"`
+	// Configured is false when the provider is missing from the configuration,
+	// in which case its data keys, and the secrets encrypted with them, can't be decrypted.
+	Configured  bool      `json:"configured"`
+	Active      int       `json:"active"`
+	Inactive    int       `json:"inactive"`
+	LastCreated time.Time `json:"lastCreated"`
+}
+
 type EncryptionOptions func() string
 
 // WithoutScope uses a root level data key for encryption (DEK),
@@ -28,6 +28,10 @@ type TokenDTO struct {
 	Created *time.Time `json:"created"`
 	// example: 2022-03-23T10:31:02Z
 	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// example: 192.168.1.10
+	LastUsedIP *string `json:"lastUsedIp"`
+	// example: 42
+	UseCount int64 `json:"useCount"`
 	// example: 2022-03-23T10:31:02Z
 	Expiration *time.Time `json:"expiration"`
 	// example: 0
@@ -103,6 +107,8 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *contextmodel.ReqContext) response
 			SecondsUntilExpiration: &secondsUntilExpiration,
 			HasExpired:             isExpired,
 			LastUsedAt:             token.LastUsedAt,
+			LastUsedIP:             token.LastUsedIP,
+			UseCount:               token.UseCount,
 			IsRevoked:              token.IsRevoked,
 		}
 	}
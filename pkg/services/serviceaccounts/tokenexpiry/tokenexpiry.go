@@ -0,0 +1,242 @@
+package tokenexpiry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const tmplTokenExpiry = "service_account_token_expiry"
+
+// Token is a service account token which expires soon.
+type Token struct {
+	ID                 int64      `xorm:"id" json:"tokenId"`
+	OrgID              int64      `xorm:"org_id" json:"orgId"`
+	Name               string     `xorm:"name" json:"tokenName"`
+	ServiceAccountID   int64      `xorm:"service_account_id" json:"serviceAccountId"`
+	ServiceAccountName string     `xorm:"service_account_name" json:"serviceAccountName"`
+	Expires            int64      `xorm:"expires" json:"-"`
+	LastUsedAt         *time.Time `xorm:"last_used_at" json:"lastUsedAt"`
+	UseCount           int64      `xorm:"use_count" json:"useCount"`
+}
+
+// webhookPayload is the body posted to the webhook for each expiring token.
+type webhookPayload struct {
+	Token
+	ExpiresAt time.Time `json:"expiresAt"`
+	// ServiceAccountURL is the page of the service account, to rotate the token.
+	ServiceAccountURL string `json:"serviceAccountUrl"`
+}
+
+// Service warns the owners of the service accounts, and the webhook of the settings, before the tokens
+// of the service accounts expire. Each token is notified once.
+type Service struct {
+	settings      setting.SATokenExpiryNotificationSettings
+	appURL        string
+	store         db.DB
+	notifications notifications.Service
+	log           log.Logger
+	now           func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, notificationService notifications.Service) *Service {
+	return &Service{
+		settings:      cfg.SATokenExpiryNotification,
+		appURL:        cfg.AppURL,
+		store:         sqlStore,
+		notifications: notificationService,
+		log:           log.New("serviceaccounts.token-expiry"),
+		now:           time.Now,
+	}
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled || (!s.settings.NotifyOwners && s.settings.WebhookURL == "")
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.NotifyExpiringTokens(ctx); err != nil {
+				s.log.Error("Failed to notify the expiring service account tokens", "error", err)
+			}
+		}
+	}
+}
+
+// NotifyExpiringTokens notifies the tokens which expire within the notice of the settings and were not
+// notified yet.
+func (s *Service) NotifyExpiringTokens(ctx context.Context) error {
+	tokens, err := s.expiringTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		// the token is claimed before it's notified, so that it's notified by a single Grafana instance
+		claimed, err := s.claim(ctx, token.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.notify(ctx, token); err != nil {
+			s.log.Error("Failed to notify the expiring service account token", "tokenId", token.ID, "serviceAccountId", token.ServiceAccountID, "error", err)
+			// the token is notified again on the next check
+			if err := s.release(ctx, token.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) notify(ctx context.Context, token Token) error {
+	expiresAt := time.Unix(token.Expires, 0).UTC()
+	serviceAccountURL := fmt.Sprintf("%sorg/serviceaccounts/%d", s.appURL, token.ServiceAccountID)
+	var errs []error
+
+	if s.settings.NotifyOwners {
+		recipients, err := s.owners(ctx, token.OrgID, token.ServiceAccountID)
+		if err != nil {
+			errs = append(errs, err)
+		} else if len(recipients) == 0 {
+			s.log.Warn("No owner to notify of the expiring service account token", "tokenId", token.ID, "serviceAccountId", token.ServiceAccountID)
+		} else {
+			err := s.notifications.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+				SendEmailCommand: notifications.SendEmailCommand{
+					To:       recipients,
+					Template: tmplTokenExpiry,
+					Data: map[string]any{
+						"TokenName":          token.Name,
+						"ServiceAccountName": token.ServiceAccountName,
+						"ServiceAccountURL":  serviceAccountURL,
+						"Expires":            expiresAt.Format(time.RFC1123),
+					},
+				},
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to email the owners: %w", err))
+			}
+		}
+	}
+
+	if s.settings.WebhookURL != "" {
+		body, err := json.Marshal(webhookPayload{Token: token, ExpiresAt: expiresAt, ServiceAccountURL: serviceAccountURL})
+		if err == nil {
+			err = s.notifications.SendWebhookSync(ctx, &notifications.SendWebhookSync{
+				Url:         s.settings.WebhookURL,
+				Body:        string(body),
+				HttpMethod:  http.MethodPost,
+				ContentType: "application/json",
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to post to the webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) expiringTokens(ctx context.Context) ([]Token, error) {
+	now := s.now()
+	tokens := make([]Token, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.store.GetDialect()
+		return sess.SQL(`SELECT api_key.id, api_key.org_id, api_key.name, api_key.service_account_id, u.name AS service_account_name,
+			api_key.expires, api_key.last_used_at, api_key.use_count
+			FROM api_key INNER JOIN `+dialect.Quote("user")+` AS u ON u.id = api_key.service_account_id
+			WHERE api_key.expires > ? AND api_key.expires <= ? AND api_key.expiry_notified_at IS NULL
+			AND (api_key.is_revoked IS NULL OR api_key.is_revoked = ?) AND u.is_disabled = ?
+			ORDER BY api_key.expires`,
+			now.Unix(), now.Add(s.settings.NotifyBefore).Unix(), dialect.BooleanStr(false), dialect.BooleanStr(false)).Find(&tokens)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the expiring service account tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// claim marks the token as notified, and returns false when it already was.
+func (s *Service) claim(ctx context.Context, tokenID int64) (bool, error) {
+	var claimed bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE api_key SET expiry_notified_at = ? WHERE id = ? AND expiry_notified_at IS NULL", s.now(), tokenID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim service account token %d: %w", tokenID, err)
+	}
+	return claimed, nil
+}
+
+func (s *Service) release(ctx context.Context, tokenID int64) error {
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE api_key SET expiry_notified_at = NULL WHERE id = ?", tokenID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release service account token %d: %w", tokenID, err)
+	}
+	return nil
+}
+
+// owners returns the emails of the users who administer the service account, directly or through a team,
+// or of the admins of the org when there are none.
+func (s *Service) owners(ctx context.Context, orgID, serviceAccountID int64) ([]string, error) {
+	scope := accesscontrol.Scope("serviceaccounts", "id", strconv.FormatInt(serviceAccountID, 10))
+	emails := make([]string, 0)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.store.GetDialect()
+		quotedUser := dialect.Quote("user")
+		err := sess.SQL(`SELECT u.email FROM permission AS p
+			INNER JOIN user_role AS ur ON ur.role_id = p.role_id
+			INNER JOIN `+quotedUser+` AS u ON u.id = ur.user_id
+			WHERE p.action = ? AND p.scope = ? AND ur.org_id = ? AND u.is_disabled = ? AND u.email <> ''
+			UNION
+			SELECT u.email FROM permission AS p
+			INNER JOIN team_role AS tr ON tr.role_id = p.role_id
+			INNER JOIN team_member AS tm ON tm.team_id = tr.team_id
+			INNER JOIN `+quotedUser+` AS u ON u.id = tm.user_id
+			WHERE p.action = ? AND p.scope = ? AND tr.org_id = ? AND u.is_disabled = ? AND u.email <> ''`,
+			serviceaccounts.ActionPermissionsWrite, scope, orgID, dialect.BooleanStr(false),
+			serviceaccounts.ActionPermissionsWrite, scope, orgID, dialect.BooleanStr(false)).Find(&emails)
+		if err != nil || len(emails) > 0 {
+			return err
+		}
+
+		return sess.SQL(`SELECT u.email FROM org_user AS ou
+			INNER JOIN `+quotedUser+` AS u ON u.id = ou.user_id
+			WHERE ou.org_id = ? AND ou.role = ? AND u.is_service_account = ? AND u.is_disabled = ? AND u.email <> ''`,
+			orgID, string(org.RoleAdmin), dialect.BooleanStr(false), dialect.BooleanStr(false)).Find(&emails)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the owners of service account %d: %w", serviceAccountID, err)
+	}
+	return emails, nil
+}
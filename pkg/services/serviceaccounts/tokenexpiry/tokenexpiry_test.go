@@ -0,0 +1,140 @@
+package tokenexpiry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationNotifyExpiringTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Now()
+	store := db.InitTestDB(t)
+
+	var owned, unowned int64
+	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+		insertUser := func(login, email string, isServiceAccount bool) int64 {
+			u := &user.User{UID: login, Login: login, Email: email, Name: login, OrgID: 1, IsServiceAccount: isServiceAccount, Created: now, Updated: now}
+			_, err := sess.Insert(u)
+			require.NoError(t, err)
+			return u.ID
+		}
+		insertToken := func(name string, serviceAccountID int64, expires time.Time, revoked bool) {
+			expiresAt := expires.Unix()
+			_, err := sess.Insert(&apikey.APIKey{OrgID: 1, Name: name, Key: name, Role: org.RoleViewer, Created: now, Updated: now,
+				Expires: &expiresAt, ServiceAccountId: &serviceAccountID, IsRevoked: &revoked})
+			require.NoError(t, err)
+		}
+
+		owned = insertUser("sa-1-owned", "", true)
+		unowned = insertUser("sa-1-unowned", "", true)
+		owner := insertUser("owner", "owner@example.org", false)
+		admin := insertUser("admin", "admin@example.org", false)
+
+		// the owner administers the first service account, the second one has no owner
+		role := &accesscontrol.Role{OrgID: 1, UID: "managed-owner", Name: "managed:users:owner:permissions", Created: now, Updated: now}
+		_, err := sess.Insert(role)
+		require.NoError(t, err)
+		_, err = sess.Insert(&accesscontrol.Permission{RoleID: role.ID, Action: serviceaccounts.ActionPermissionsWrite,
+			Scope: accesscontrol.Scope("serviceaccounts", "id", strconv.FormatInt(owned, 10)), Created: now, Updated: now})
+		require.NoError(t, err)
+		_, err = sess.Insert(&accesscontrol.UserRole{OrgID: 1, RoleID: role.ID, UserID: owner, Created: now})
+		require.NoError(t, err)
+		_, err = sess.Insert(&org.OrgUser{OrgID: 1, UserID: admin, Role: org.RoleAdmin, Created: now, Updated: now})
+		require.NoError(t, err)
+
+		insertToken("expiring", owned, now.Add(48*time.Hour), false)
+		insertToken("unowned-expiring", unowned, now.Add(72*time.Hour), false)
+		insertToken("expiring-later", owned, now.Add(30*24*time.Hour), false)
+		insertToken("expired", owned, now.Add(-time.Hour), false)
+		insertToken("revoked", owned, now.Add(48*time.Hour), true)
+		return nil
+	})
+	require.NoError(t, err)
+
+	notificationService := notifications.MockNotificationService()
+	emails := map[string][]string{}
+	notificationService.EmailHandlerSync = func(_ context.Context, cmd *notifications.SendEmailCommandSync) error {
+		emails[cmd.Data["TokenName"].(string)] = cmd.To
+		return nil
+	}
+	webhooks := map[string]webhookPayload{}
+	webhookErr := errors.New("webhook unavailable")
+	notificationService.WebhookHandler = func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+		if webhookErr != nil {
+			return webhookErr
+		}
+		var payload webhookPayload
+		require.NoError(t, json.Unmarshal([]byte(cmd.Body), &payload))
+		webhooks[payload.Name] = payload
+		return nil
+	}
+
+	s := &Service{
+		settings: setting.SATokenExpiryNotificationSettings{
+			Enabled:      true,
+			NotifyBefore: 7 * 24 * time.Hour,
+			NotifyOwners: true,
+			WebhookURL:   "https://hooks.example.org/tokens",
+		},
+		appURL:        "https://grafana.example.org/",
+		store:         store,
+		notifications: notificationService,
+		log:           log.NewNopLogger(),
+		now:           func() time.Time { return now },
+	}
+
+	t.Run("should notify the tokens again when the notification failed", func(t *testing.T) {
+		require.NoError(t, s.NotifyExpiringTokens(context.Background()))
+		require.Empty(t, webhooks)
+
+		webhookErr = nil
+		require.NoError(t, s.NotifyExpiringTokens(context.Background()))
+	})
+
+	t.Run("should notify the expiring tokens to their owners and the webhook", func(t *testing.T) {
+		assert.Equal(t, map[string][]string{
+			"expiring":         {"owner@example.org"},
+			"unowned-expiring": {"admin@example.org"},
+		}, emails)
+
+		require.Len(t, webhooks, 2)
+		assert.Equal(t, owned, webhooks["expiring"].ServiceAccountID)
+		assert.Equal(t, fmt.Sprintf("https://grafana.example.org/org/serviceaccounts/%d", owned), webhooks["expiring"].ServiceAccountURL)
+		assert.Equal(t, unowned, webhooks["unowned-expiring"].ServiceAccountID)
+	})
+
+	t.Run("should notify each token once", func(t *testing.T) {
+		emails = map[string][]string{}
+		webhooks = map[string]webhookPayload{}
+
+		require.NoError(t, s.NotifyExpiringTokens(context.Background()))
+		assert.Empty(t, emails)
+		assert.Empty(t, webhooks)
+	})
+}
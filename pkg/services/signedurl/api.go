@@ -0,0 +1,28 @@
+package signedurl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Post("/api/signed-urls", middleware.ReqSignedInNoAnonymous, routing.Wrap(s.signHandler))
+}
+
+func (s *Service) signHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := SignCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	signed, err := s.Sign(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to sign URL", err)
+	}
+	return response.JSON(http.StatusOK, signed)
+}
@@ -0,0 +1,57 @@
+package signedurl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+)
+
+var _ authn.ContextAwareClient = new(Client)
+
+// Client authenticates the requests to the signed URLs as the identity which signed them.
+type Client struct {
+	s *Service
+}
+
+func (c *Client) Name() string {
+	return authn.ClientSignedURL
+}
+
+func (c *Client) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	urlClaims, err := c.s.verify(ctx, r.HTTPRequest)
+	if err != nil {
+		return nil, errInvalidSignature.Errorf("failed to verify signed URL: %w", err)
+	}
+
+	typ, id, err := identity.ParseTypeAndID(urlClaims.Subject)
+	if err != nil {
+		return nil, errInvalidSignature.Errorf("invalid subject of signed URL: %w", err)
+	}
+
+	return &authn.Identity{
+		ID:              id,
+		Type:            typ,
+		OrgID:           urlClaims.OrgID,
+		AuthenticatedBy: login.SignedURLModule,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+	}, nil
+}
+
+func (c *Client) IsEnabled() bool {
+	return c.s.settings.Enabled
+}
+
+func (c *Client) Test(ctx context.Context, r *authn.Request) bool {
+	if r.HTTPRequest == nil {
+		return false
+	}
+	return r.HTTPRequest.URL.Query().Get(SignatureParam) != ""
+}
+
+// Priority is higher than the one of the session client, so that the signed URLs are served with the
+// permissions of the identity which signed them, whoever requests them.
+func (c *Client) Priority() uint {
+	return 12
+}
@@ -0,0 +1,45 @@
+package signedurl
+
+import (
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrNotAllowed       = errutil.Forbidden("signed-url.not-allowed", errutil.WithPublicMessage("Only users and service accounts can sign URLs"))
+	ErrInvalidURL       = errutil.BadRequest("signed-url.invalid-url")
+	ErrPathNotAllowed   = errutil.BadRequest("signed-url.path-not-allowed")
+	ErrInvalidTTL       = errutil.BadRequest("signed-url.invalid-ttl")
+	ErrInvalidMethod    = errutil.BadRequest("signed-url.invalid-method")
+	ErrLimitReached     = errutil.TooManyRequests("signed-url.limit-reached", errutil.WithPublicMessage("The organization signed too many URLs, try again later"))
+	errInvalidSignature = errutil.Unauthorized("signed-url.invalid-signature", errutil.WithPublicMessage("Invalid or expired signed URL"))
+)
+
+// SignCommand is the body of the API signing URLs.
+type SignCommand struct {
+	// URL is the path of the resource and its query, relative to the root of Grafana, e.g. /render/d-solo/abc?panelId=2.
+	URL string `json:"url"`
+	// TTLSeconds is how long the URL is valid. When 0, it's valid for the default TTL of the configuration.
+	TTLSeconds int64 `json:"ttlSeconds"`
+	// Method is the HTTP method the URL can be requested with. When empty, it's GET.
+	Method string `json:"method"`
+}
+
+// SignedURL is a URL which can be requested without a session, until it expires.
+type SignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// urlClaims are the claims of the signature of a URL.
+type urlClaims struct {
+	jwt.Claims
+	OrgID int64 `json:"org_id"`
+	// URL is the path and the canonical query of the signed URL, without the signature.
+	URL string `json:"url"`
+	// Method is the HTTP method the URL can be requested with.
+	Method string `json:"method"`
+}
@@ -0,0 +1,279 @@
+package signedurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/signingkeys"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// SignatureParam is the query parameter of the signed URLs holding their signature.
+	SignatureParam = "signature"
+
+	// keyPrefix separates the signing keys of the URLs from the other keys of the signing keys service,
+	// which are rotated the same way: a new key every month, the previous one being kept to verify.
+	keyPrefix = "signed-url"
+	audience  = "grafana-signed-url"
+)
+
+// Service issues the signed URLs, and verifies them with an authn client, so that they can be requested
+// without a session. A signed URL authenticates the requests to this URL only, as the identity which
+// signed it, with the permissions it has when the URL is requested.
+type Service struct {
+	settings  setting.SignedURLsSettings
+	appURL    string
+	appSubURL string
+	keys      signingkeys.Service
+	limiter   *issuanceLimiter
+	log       log.Logger
+	now       func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, keys signingkeys.Service, authnService authn.Service, routeRegister routing.RouteRegister) *Service {
+	s := &Service{
+		settings:  cfg.SignedURLs,
+		appURL:    cfg.AppURL,
+		appSubURL: cfg.AppSubURL,
+		keys:      keys,
+		limiter:   newIssuanceLimiter(cfg.SignedURLs.MaxPerOrgPerHour),
+		log:       log.New("signed-url"),
+		now:       time.Now,
+	}
+
+	if s.settings.Enabled {
+		authnService.RegisterClient(&Client{s: s})
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s
+}
+
+// Sign returns a signed URL to the resource, valid for the identity of the requester.
+func (s *Service) Sign(ctx context.Context, requester identity.Requester, cmd SignCommand) (*SignedURL, error) {
+	if !requester.IsIdentityType(claims.TypeUser, claims.TypeServiceAccount) {
+		return nil, ErrNotAllowed.Errorf("identity type %s can't sign URLs", requester.GetIdentityType())
+	}
+
+	u, err := s.parseURL(cmd.URL)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isAllowed(u.Path) {
+		return nil, ErrPathNotAllowed.Errorf("path %s can't be signed", u.Path)
+	}
+
+	method := cmd.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !isSignableMethod(method) {
+		return nil, ErrInvalidMethod.Errorf("method %s can't be signed", method)
+	}
+
+	ttl := time.Duration(cmd.TTLSeconds) * time.Second
+	if ttl == 0 {
+		ttl = s.settings.DefaultTTL
+	}
+	if ttl < 0 || ttl > s.settings.MaxTTL {
+		return nil, ErrInvalidTTL.Errorf("ttl must be between 1s and %s", s.settings.MaxTTL)
+	}
+
+	now := s.now()
+	if !s.limiter.allow(requester.GetOrgID(), now) {
+		return nil, ErrLimitReached.Errorf("org %d reached the limit of %d signed URLs per hour", requester.GetOrgID(), s.settings.MaxPerOrgPerHour)
+	}
+
+	signer, err := s.getSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	signedPath := canonicalURL(u)
+	expiresAt := now.Add(ttl)
+	token, err := jwt.Signed(signer).Claims(urlClaims{
+		Claims: jwt.Claims{
+			ID:       util.GenerateShortUID(),
+			Subject:  requester.GetID(),
+			Audience: jwt.Audience{audience},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(expiresAt),
+		},
+		OrgID:  requester.GetOrgID(),
+		URL:    signedPath,
+		Method: method,
+	}).CompactSerialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign URL: %w", err)
+	}
+
+	separator := "?"
+	if strings.Contains(signedPath, "?") {
+		separator = "&"
+	}
+	return &SignedURL{
+		URL:       strings.TrimSuffix(s.appURL, "/") + signedPath + separator + SignatureParam + "=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *Service) getSigner(ctx context.Context) (jose.Signer, error) {
+	keyID, key, err := s.keys.GetOrCreatePrivateKey(ctx, keyPrefix, jose.ES256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+	return jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{
+			"kid":           keyID,
+			jose.HeaderType: "jwt",
+		},
+	})
+}
+
+// parseURL returns the URL relative to the root of Grafana. The absolute URLs must point to Grafana.
+func (s *Service) parseURL(raw string) (*url.URL, error) {
+	if u, err := url.Parse(s.appURL); err == nil && u.Host != "" && strings.HasPrefix(raw, u.Scheme+"://"+u.Host) {
+		raw = strings.TrimPrefix(raw, u.Scheme+"://"+u.Host)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, ErrInvalidURL.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return nil, ErrInvalidURL.Errorf("the URL must be a path of Grafana")
+	}
+	u.Path = s.trimSubURL(u.Path)
+	if path.Clean(u.Path) != u.Path {
+		return nil, ErrInvalidURL.Errorf("the path of the URL must be clean")
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+func (s *Service) trimSubURL(p string) string {
+	if s.appSubURL != "" && strings.HasPrefix(p, s.appSubURL+"/") {
+		return strings.TrimPrefix(p, s.appSubURL)
+	}
+	return p
+}
+
+func (s *Service) isAllowed(p string) bool {
+	for _, prefix := range s.settings.AllowedPaths {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// verify returns the claims of the signature of the request, once checked it is valid for its URL.
+func (s *Service) verify(ctx context.Context, r *http.Request) (*urlClaims, error) {
+	token, err := jwt.ParseSigned(r.URL.Query().Get(SignatureParam))
+	if err != nil {
+		return nil, err
+	}
+	if len(token.Headers) == 0 || !strings.HasPrefix(token.Headers[0].KeyID, keyPrefix+"-") {
+		return nil, errors.New("the signature was not made by a signing key of the URLs")
+	}
+
+	jwks, err := s.keys.GetJWKS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing keys: %w", err)
+	}
+	keys := jwks.Key(token.Headers[0].KeyID)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("unknown signing key %s", token.Headers[0].KeyID)
+	}
+
+	c := &urlClaims{}
+	if err := token.Claims(keys[0], c); err != nil {
+		return nil, err
+	}
+	if err := c.Claims.ValidateWithLeeway(jwt.Expected{Audience: jwt.Audience{audience}, Time: s.now()}, 0); err != nil {
+		return nil, err
+	}
+
+	u := *r.URL
+	u.Path = s.trimSubURL(u.Path)
+	if got := canonicalURL(&u); got != c.URL {
+		return nil, fmt.Errorf("the URL was signed for %s, not %s", c.URL, got)
+	}
+	if !methodMatches(c.Method, r.Method) {
+		return nil, fmt.Errorf("the URL was signed for the method %s, not %s", c.Method, r.Method)
+	}
+	return c, nil
+}
+
+func isSignableMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// methodMatches returns whether a request with the method can use a URL signed for the signed method. The URLs
+// signed for GET can also be requested with HEAD, which doesn't return more than GET.
+func methodMatches(signed, method string) bool {
+	if method == signed {
+		return true
+	}
+	return signed == http.MethodGet && method == http.MethodHead
+}
+
+// canonicalURL returns the path and the sorted query of the URL, without its signature.
+func canonicalURL(u *url.URL) string {
+	query := u.Query()
+	query.Del(SignatureParam)
+	if encoded := query.Encode(); encoded != "" {
+		return u.Path + "?" + encoded
+	}
+	return u.Path
+}
+
+// issuanceLimiter limits the number of URLs the organizations sign per hour, on this instance.
+type issuanceLimiter struct {
+	limit  int
+	mu     sync.Mutex
+	window time.Time
+	counts map[int64]int
+}
+
+func newIssuanceLimiter(limit int) *issuanceLimiter {
+	return &issuanceLimiter{limit: limit, counts: map[int64]int{}}
+}
+
+func (l *issuanceLimiter) allow(orgID int64, now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if window := now.Truncate(time.Hour); !window.Equal(l.window) {
+		l.window = window
+		l.counts = map[int64]int{}
+	}
+	if l.counts[orgID] >= l.limit {
+		return false
+	}
+	l.counts[orgID]++
+	return true
+}
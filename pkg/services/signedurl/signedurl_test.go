@@ -0,0 +1,185 @@
+package signedurl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/signingkeys/signingkeystest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupTestService(t *testing.T) *Service {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	const keyID = "signed-url-2024-06-es256"
+	keys := &signingkeystest.FakeSigningKeysService{
+		ExpectedKeyID:  keyID,
+		ExpectedSinger: key,
+		ExpectedJSONWebKeySet: jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: keyID, Algorithm: string(jose.ES256), Use: "sig"},
+		}},
+	}
+
+	return &Service{
+		settings: setting.SignedURLsSettings{
+			Enabled:          true,
+			AllowedPaths:     []string{"/render/"},
+			DefaultTTL:       5 * time.Minute,
+			MaxTTL:           time.Hour,
+			MaxPerOrgPerHour: 2,
+		},
+		appURL:    "https://grafana.example.com/grafana/",
+		appSubURL: "/grafana",
+		keys:      keys,
+		limiter:   newIssuanceLimiter(2),
+		log:       log.NewNopLogger(),
+		now:       time.Now,
+	}
+}
+
+func TestService_Sign(t *testing.T) {
+	s := setupTestService(t)
+	usr := &user.SignedInUser{UserID: 3, OrgID: 2}
+	ctx := context.Background()
+
+	signed, err := s.Sign(ctx, usr, SignCommand{URL: "/grafana/render/d-solo/abc?panelId=2&from=now-1h"})
+	require.NoError(t, err)
+	require.Contains(t, signed.URL, "https://grafana.example.com/grafana/render/d-solo/abc?from=now-1h&panelId=2&signature=")
+	require.WithinDuration(t, time.Now().Add(5*time.Minute), signed.ExpiresAt, time.Minute)
+
+	t.Run("should reject the paths which are not allowed", func(t *testing.T) {
+		_, err := s.Sign(ctx, usr, SignCommand{URL: "/api/dashboards/uid/abc"})
+		require.ErrorIs(t, err, ErrPathNotAllowed)
+
+		_, err = s.Sign(ctx, usr, SignCommand{URL: "/render/../api/dashboards/uid/abc"})
+		require.ErrorIs(t, err, ErrInvalidURL)
+
+		_, err = s.Sign(ctx, usr, SignCommand{URL: "https://evil.example.com/render/d-solo/abc"})
+		require.ErrorIs(t, err, ErrInvalidURL)
+	})
+
+	t.Run("should reject the identities which are not users or service accounts", func(t *testing.T) {
+		_, err := s.Sign(ctx, &user.SignedInUser{ApiKeyID: 1, OrgID: 2}, SignCommand{URL: "/render/d-solo/abc"})
+		require.ErrorIs(t, err, ErrNotAllowed)
+	})
+
+	t.Run("should reject the ttl longer than the max", func(t *testing.T) {
+		_, err := s.Sign(ctx, usr, SignCommand{URL: "/render/d-solo/abc", TTLSeconds: 7200})
+		require.ErrorIs(t, err, ErrInvalidTTL)
+	})
+
+	t.Run("should reject the methods which can't be signed", func(t *testing.T) {
+		_, err := s.Sign(ctx, usr, SignCommand{URL: "/render/d-solo/abc", Method: "get"})
+		require.ErrorIs(t, err, ErrInvalidMethod)
+	})
+
+	t.Run("should limit the URLs signed per org", func(t *testing.T) {
+		_, err := s.Sign(ctx, usr, SignCommand{URL: "/render/d-solo/abc"})
+		require.NoError(t, err)
+		_, err = s.Sign(ctx, usr, SignCommand{URL: "/render/d-solo/abc"})
+		require.ErrorIs(t, err, ErrLimitReached)
+
+		_, err = s.Sign(ctx, &user.SignedInUser{UserID: 3, OrgID: 3}, SignCommand{URL: "/render/d-solo/abc"})
+		require.NoError(t, err, "the other orgs have their own limit")
+	})
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	s := setupTestService(t)
+	s.limiter = newIssuanceLimiter(0)
+	client := &Client{s: s}
+	ctx := context.Background()
+
+	request := func(t *testing.T, rawURL string) *authn.Request {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		return &authn.Request{HTTPRequest: httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)}
+	}
+
+	signed, err := s.Sign(ctx, &user.SignedInUser{UserID: 3, OrgID: 2}, SignCommand{URL: "/render/d-solo/abc?panelId=2"})
+	require.NoError(t, err)
+
+	t.Run("should authenticate as the identity which signed the URL", func(t *testing.T) {
+		r := request(t, signed.URL)
+		require.True(t, client.Test(ctx, r))
+
+		ident, err := client.Authenticate(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, "3", ident.ID)
+		assert.Equal(t, claims.TypeUser, ident.Type)
+		assert.Equal(t, int64(2), ident.OrgID)
+		assert.True(t, ident.ClientParams.FetchSyncedUser)
+	})
+
+	t.Run("should reject the other URLs", func(t *testing.T) {
+		u, err := url.Parse(signed.URL)
+		require.NoError(t, err)
+		q := u.Query()
+		q.Set("panelId", "3")
+		u.RawQuery = q.Encode()
+
+		_, err = client.Authenticate(ctx, request(t, u.String()))
+		require.ErrorIs(t, err, errInvalidSignature)
+
+		u.Path = "/grafana/render/d-solo/other"
+		_, err = client.Authenticate(ctx, request(t, u.String()))
+		require.ErrorIs(t, err, errInvalidSignature)
+	})
+
+	t.Run("should reject the requests with another method", func(t *testing.T) {
+		r := request(t, signed.URL)
+		r.HTTPRequest.Method = http.MethodHead
+		_, err := client.Authenticate(ctx, r)
+		require.NoError(t, err)
+
+		r.HTTPRequest.Method = http.MethodPost
+		_, err = client.Authenticate(ctx, r)
+		require.ErrorIs(t, err, errInvalidSignature)
+
+		deleteSigned, err := s.Sign(ctx, &user.SignedInUser{UserID: 3, OrgID: 2}, SignCommand{URL: "/render/d-solo/abc?panelId=2", Method: http.MethodDelete})
+		require.NoError(t, err)
+		r = request(t, deleteSigned.URL)
+		r.HTTPRequest.Method = http.MethodDelete
+		_, err = client.Authenticate(ctx, r)
+		require.NoError(t, err)
+
+		r.HTTPRequest.Method = http.MethodGet
+		_, err = client.Authenticate(ctx, r)
+		require.ErrorIs(t, err, errInvalidSignature)
+	})
+
+	t.Run("should reject the expired URLs", func(t *testing.T) {
+		s.now = func() time.Time { return time.Now().Add(time.Hour) }
+		defer func() { s.now = time.Now }()
+
+		_, err := client.Authenticate(ctx, request(t, signed.URL))
+		require.ErrorIs(t, err, errInvalidSignature)
+	})
+
+	t.Run("should reject the URLs signed by another key", func(t *testing.T) {
+		other := setupTestService(t)
+		other.limiter = newIssuanceLimiter(0)
+		otherSigned, err := other.Sign(ctx, &user.SignedInUser{UserID: 3, OrgID: 2}, SignCommand{URL: "/render/d-solo/abc?panelId=2"})
+		require.NoError(t, err)
+
+		_, err = client.Authenticate(ctx, request(t, otherSigned.URL))
+		require.ErrorIs(t, err, errInvalidSignature)
+	})
+}
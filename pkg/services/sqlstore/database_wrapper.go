@@ -23,7 +23,8 @@ import (
 )
 
 var (
-	databaseQueryHistogram *prometheus.HistogramVec
+	databaseQueryHistogram       *prometheus.HistogramVec
+	databaseQueryCallerHistogram *prometheus.HistogramVec
 )
 
 func init() {
@@ -34,13 +35,20 @@ func init() {
 		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
 	}, []string{"status"})
 
-	prometheus.MustRegister(databaseQueryHistogram)
+	databaseQueryCallerHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "database_queries_by_caller_duration_seconds",
+		Help:      "Database query histogram by the service and method running the query",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 8),
+	}, []string{"service", "method"})
+
+	prometheus.MustRegister(databaseQueryHistogram, databaseQueryCallerHistogram)
 }
 
 // WrapDatabaseDriverWithHooks creates a fake database driver that
 // executes pre and post functions which we use to gather metrics about
 // database queries. It also registers the metrics.
-func WrapDatabaseDriverWithHooks(dbType string, tracer tracing.Tracer) string {
+func WrapDatabaseDriverWithHooks(dbType string, tracer tracing.Tracer, slowQueryThreshold time.Duration) string {
 	drivers := map[string]driver.Driver{
 		migrator.SQLite:   &sqlite3.SQLiteDriver{},
 		migrator.MySQL:    &mysql.MySQLDriver{},
@@ -53,7 +61,7 @@ func WrapDatabaseDriverWithHooks(dbType string, tracer tracing.Tracer) string {
 	}
 
 	driverWithHooks := dbType + "WithHooks"
-	sql.Register(driverWithHooks, sqlhooks.Wrap(d, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: tracer}))
+	sql.Register(driverWithHooks, sqlhooks.Wrap(d, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: tracer, slowQueryThreshold: slowQueryThreshold}))
 	core.RegisterDriver(driverWithHooks, &databaseQueryWrapperDriver{dbType: dbType})
 	return driverWithHooks
 }
@@ -61,7 +69,7 @@ func WrapDatabaseDriverWithHooks(dbType string, tracer tracing.Tracer) string {
 // WrapDatabaseDriverWithHooks creates a fake database driver that
 // executes pre and post functions which we use to gather metrics about
 // database queries. It also registers the metrics.
-func WrapDatabaseReplDriverWithHooks(dbType string, index uint, tracer tracing.Tracer) string {
+func WrapDatabaseReplDriverWithHooks(dbType string, index uint, tracer tracing.Tracer, slowQueryThreshold time.Duration) string {
 	drivers := map[string]driver.Driver{
 		migrator.SQLite:   &sqlite3.SQLiteDriver{},
 		migrator.MySQL:    &mysql.MySQLDriver{},
@@ -74,7 +82,7 @@ func WrapDatabaseReplDriverWithHooks(dbType string, index uint, tracer tracing.T
 	}
 
 	driverWithHooks := dbType + fmt.Sprintf("ReplicaWithHooks%d", index)
-	sql.Register(driverWithHooks, sqlhooks.Wrap(d, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: tracer}))
+	sql.Register(driverWithHooks, sqlhooks.Wrap(d, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: tracer, slowQueryThreshold: slowQueryThreshold}))
 	core.RegisterDriver(driverWithHooks, &databaseQueryWrapperDriver{dbType: dbType})
 	return driverWithHooks
 }
@@ -84,6 +92,9 @@ func WrapDatabaseReplDriverWithHooks(dbType string, index uint, tracer tracing.T
 type databaseQueryWrapper struct {
 	log    log.Logger
 	tracer tracing.Tracer
+	// slowQueryThreshold is the duration above which queries are logged and
+	// kept in the slow queries list, zero disables it.
+	slowQueryThreshold time.Duration
 }
 
 // databaseQueryWrapperKey is used as key to save values in `context.Context`
@@ -96,12 +107,12 @@ func (h *databaseQueryWrapper) Before(ctx context.Context, query string, args ..
 
 // After hook will get the timestamp registered on the Before hook and print the elapsed time
 func (h *databaseQueryWrapper) After(ctx context.Context, query string, args ...any) (context.Context, error) {
-	h.instrument(ctx, "success", query, nil)
+	h.instrument(ctx, "success", query, args, nil)
 
 	return ctx, nil
 }
 
-func (h *databaseQueryWrapper) instrument(ctx context.Context, status string, query string, err error) {
+func (h *databaseQueryWrapper) instrument(ctx context.Context, status string, query string, args []any, err error) {
 	begin := ctx.Value(databaseQueryWrapperKey{}).(time.Time)
 	elapsed := time.Since(begin)
 
@@ -117,6 +128,9 @@ func (h *databaseQueryWrapper) instrument(ctx context.Context, status string, qu
 		histogram.Observe(elapsed.Seconds())
 	}
 
+	caller := findQueryCaller()
+	databaseQueryCallerHistogram.WithLabelValues(caller.service, caller.method).Observe(elapsed.Seconds())
+
 	ctx = log.IncDBCallCounter(ctx)
 
 	// timestamp overridden and recorded AFTER query is run
@@ -132,6 +146,13 @@ func (h *databaseQueryWrapper) instrument(ctx context.Context, status string, qu
 
 	ctxLogger := h.log.FromContext(ctx)
 	ctxLogger.Debug("query finished", "status", status, "elapsed time", elapsed, "sql", query, "error", err)
+
+	if h.slowQueryThreshold > 0 && elapsed >= h.slowQueryThreshold {
+		query = truncateQuery(query)
+		slowQueries.record(caller, query, elapsed, time.Now())
+		ctxLogger.Warn("Slow query", "service", caller.service, "method", caller.method, "status", status,
+			"elapsed time", elapsed, "sql", query, "args", redactArgs(args))
+	}
 }
 
 // OnError will be called if any error happens
@@ -150,7 +171,7 @@ func (h *databaseQueryWrapper) OnError(ctx context.Context, err error, query str
 		status = "success"
 	}
 
-	h.instrument(ctx, status, query, err)
+	h.instrument(ctx, status, query, args, err)
 
 	return err
 }
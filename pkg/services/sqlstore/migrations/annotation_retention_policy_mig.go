@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnnotationRetentionPolicyMigrations(mg *Migrator) {
+	policyV1 := Table{
+		Name: "annotation_retention_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "annotation_type", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "max_age", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create annotation_retention_policy table v1", NewAddTableMigration(policyV1))
+	mg.AddMigration("add unique index annotation_retention_policy.org_id-uid", NewAddIndexMigration(policyV1, policyV1.Indices[0]))
+}
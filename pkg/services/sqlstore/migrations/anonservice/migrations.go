@@ -22,4 +22,11 @@ func AddMigration(mg *migrator.Migrator) {
 	mg.AddMigration("create anon_device table", migrator.NewAddTableMigration(anonV1))
 	mg.AddMigration("add unique index anon_device.device_id", migrator.NewAddIndexMigration(anonV1, anonV1.Indices[0]))
 	mg.AddMigration("add index anon_device.updated_at", migrator.NewAddIndexMigration(anonV1, anonV1.Indices[1]))
+
+	mg.AddMigration("add org_id column to anon_device", migrator.NewAddColumnMigration(anonV1, &migrator.Column{
+		Name: "org_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add index anon_device.org_id_updated_at", migrator.NewAddIndexMigration(anonV1, &migrator.Index{
+		Cols: []string{"org_id", "updated_at"}, Type: migrator.IndexType,
+	}))
 }
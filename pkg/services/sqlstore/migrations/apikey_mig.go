@@ -100,4 +100,17 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_revoked column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "is_revoked", Type: DB_Bool, Nullable: true, Default: "0",
 	}))
+
+	mg.AddMigration("Add last_used_ip column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "last_used_ip", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
+
+	mg.AddMigration("Add use_count column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "use_count", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	// expiry_notified_at is when the owners were warned that the key expires soon, so that they are warned once.
+	mg.AddMigration("Add expiry_notified_at column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "expiry_notified_at", Type: DB_DateTime, Nullable: true,
+	}))
 }
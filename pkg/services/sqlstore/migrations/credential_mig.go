@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addCredentialMigrations(mg *Migrator) {
+	credentialV1 := Table{
+		Name: "credential",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: false},
+			{Name: "rotation_interval_seconds", Type: DB_BigInt, Nullable: false},
+			{Name: "overlap_seconds", Type: DB_BigInt, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "rotated_at", Type: DB_BigInt, Nullable: false},
+			{Name: "pending_active_at", Type: DB_BigInt, Nullable: false},
+			{Name: "next_rotation_at", Type: DB_BigInt, Nullable: false},
+			{Name: "last_rotation_error", Type: DB_Text, Nullable: false},
+			{Name: "created_by", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+			{Cols: []string{"next_rotation_at"}},
+			{Cols: []string{"pending_active_at"}},
+		},
+	}
+
+	mg.AddMigration("create credential table v1", NewAddTableMigration(credentialV1))
+	mg.AddMigration("add unique index credential.org_id-uid", NewAddIndexMigration(credentialV1, credentialV1.Indices[0]))
+	mg.AddMigration("add unique index credential.org_id-name", NewAddIndexMigration(credentialV1, credentialV1.Indices[1]))
+	mg.AddMigration("add index credential.next_rotation_at", NewAddIndexMigration(credentialV1, credentialV1.Indices[2]))
+	mg.AddMigration("add index credential.pending_active_at", NewAddIndexMigration(credentialV1, credentialV1.Indices[3]))
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardLintMigrations(mg *Migrator) {
+	dashboardLintV1 := Table{
+		Name: "dashboard_lint",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "dashboard_version", Type: DB_Int, Nullable: false},
+			{Name: "warning_count", Type: DB_Int, Nullable: false},
+			{Name: "warnings", Type: DB_MediumText, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_lint table v1", NewAddTableMigration(dashboardLintV1))
+	mg.AddMigration("add unique index dashboard_lint.org_id-dashboard_uid", NewAddIndexMigration(dashboardLintV1, dashboardLintV1.Indices[0]))
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardPublicShareLinkMigrations(mg *Migrator) {
+	shareLinkV1 := Table{
+		Name: "dashboard_public_share_link",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "token_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "expires_at", Type: DB_BigInt, Nullable: false},
+			{Name: "revoked_at", Type: DB_BigInt, Nullable: false},
+			{Name: "usage_count", Type: DB_BigInt, Nullable: false},
+			{Name: "last_used_at", Type: DB_BigInt, Nullable: false},
+			{Name: "code_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "code_expires_at", Type: DB_BigInt, Nullable: false},
+			{Name: "code_attempts", Type: DB_Int, Nullable: false},
+			{Name: "code_sent_at", Type: DB_BigInt, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"token_hash"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "public_dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_public_share_link table v1", NewAddTableMigration(shareLinkV1))
+	mg.AddMigration("add unique index dashboard_public_share_link.uid", NewAddIndexMigration(shareLinkV1, shareLinkV1.Indices[0]))
+	mg.AddMigration("add unique index dashboard_public_share_link.token_hash", NewAddIndexMigration(shareLinkV1, shareLinkV1.Indices[1]))
+	mg.AddMigration("add index dashboard_public_share_link.org_id-public_dashboard_uid", NewAddIndexMigration(shareLinkV1, shareLinkV1.Indices[2]))
+
+	mg.AddMigration("add codes_sent column to dashboard_public_share_link", NewAddColumnMigration(shareLinkV1, &Column{
+		Name: "codes_sent", Type: DB_Int, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add code_window_start column to dashboard_public_share_link", NewAddColumnMigration(shareLinkV1, &Column{
+		Name: "code_window_start", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+}
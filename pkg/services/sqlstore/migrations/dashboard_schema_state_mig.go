@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardSchemaStateMigrations(mg *Migrator) {
+	dashboardSchemaStateV1 := Table{
+		Name: "dashboard_schema_state",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "schema_version", Type: DB_Int, Nullable: false},
+			{Name: "pinned", Type: DB_Bool, Nullable: false},
+			{Name: "migrated_from_schema", Type: DB_Int, Nullable: false},
+			{Name: "migrated_from_version", Type: DB_Int, Nullable: false},
+			{Name: "migrated_at", Type: DB_BigInt, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_schema_state table v1", NewAddTableMigration(dashboardSchemaStateV1))
+	mg.AddMigration("add unique index dashboard_schema_state.org_id-dashboard_uid", NewAddIndexMigration(dashboardSchemaStateV1, dashboardSchemaStateV1.Indices[0]))
+}
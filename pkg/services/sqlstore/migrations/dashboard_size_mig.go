@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardSizeMigrations(mg *Migrator) {
+	dashboardSizeV1 := Table{
+		Name: "dashboard_size",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "size", Type: DB_BigInt, Nullable: false},
+			{Name: "stored_size", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "size"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_size table v1", NewAddTableMigration(dashboardSizeV1))
+	mg.AddMigration("add unique index dashboard_size.dashboard_id", NewAddIndexMigration(dashboardSizeV1, dashboardSizeV1.Indices[0]))
+	mg.AddMigration("add index dashboard_size.org_id-size", NewAddIndexMigration(dashboardSizeV1, dashboardSizeV1.Indices[1]))
+
+	// the existing dashboards are not compressed, their size is the size of their data
+	mg.AddMigration("save the size of the existing dashboards", NewRawSQLMigration("").
+		SQLite("INSERT INTO dashboard_size (org_id, dashboard_id, size, stored_size, updated) SELECT org_id, id, LENGTH(CAST(data AS BLOB)), LENGTH(CAST(data AS BLOB)), updated FROM dashboard;").
+		Postgres("INSERT INTO dashboard_size (org_id, dashboard_id, size, stored_size, updated) SELECT org_id, id, OCTET_LENGTH(data), OCTET_LENGTH(data), updated FROM dashboard;").
+		Mysql("INSERT INTO dashboard_size (org_id, dashboard_id, size, stored_size, updated) SELECT org_id, id, OCTET_LENGTH(data), OCTET_LENGTH(data), updated FROM dashboard;"))
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDataSourceUsageMigrations(mg *Migrator) {
+	dataSourceUsageV1 := Table{
+		Name: "datasource_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "errors", Type: DB_BigInt, Nullable: false},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "max_duration_ms", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_uid", "day"}, Type: UniqueIndex},
+			{Cols: []string{"day"}},
+		},
+	}
+
+	mg.AddMigration("create datasource_usage table v1", NewAddTableMigration(dataSourceUsageV1))
+	mg.AddMigration("add unique index datasource_usage.org_id-datasource_uid-day", NewAddIndexMigration(dataSourceUsageV1, dataSourceUsageV1.Indices[0]))
+	mg.AddMigration("add index datasource_usage.day", NewAddIndexMigration(dataSourceUsageV1, dataSourceUsageV1.Indices[1]))
+
+	dataSourceUsageLatencyV1 := Table{
+		Name: "datasource_usage_latency",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "le_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_uid", "day", "le_ms"}, Type: UniqueIndex},
+			{Cols: []string{"day"}},
+		},
+	}
+
+	mg.AddMigration("create datasource_usage_latency table v1", NewAddTableMigration(dataSourceUsageLatencyV1))
+	mg.AddMigration("add unique index datasource_usage_latency.org_id-datasource_uid-day-le_ms", NewAddIndexMigration(dataSourceUsageLatencyV1, dataSourceUsageLatencyV1.Indices[0]))
+	mg.AddMigration("add index datasource_usage_latency.day", NewAddIndexMigration(dataSourceUsageLatencyV1, dataSourceUsageLatencyV1.Indices[1]))
+
+	dataSourceUsageSourceV1 := Table{
+		Name: "datasource_usage_source",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_uid", "day", "kind", "name"}, Type: UniqueIndex},
+			{Cols: []string{"day"}},
+		},
+	}
+
+	mg.AddMigration("create datasource_usage_source table v1", NewAddTableMigration(dataSourceUsageSourceV1))
+	mg.AddMigration("add unique index datasource_usage_source.org_id-datasource_uid-day-kind-name", NewAddIndexMigration(dataSourceUsageSourceV1, dataSourceUsageSourceV1.Indices[0]))
+	mg.AddMigration("add index datasource_usage_source.day", NewAddIndexMigration(dataSourceUsageSourceV1, dataSourceUsageSourceV1.Indices[1]))
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addFeatureToggleOverrideMigrations(mg *Migrator) {
+	overrideV1 := Table{
+		Name: "feature_toggle_override",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "org_ids", Type: DB_Text, Nullable: false},
+			{Name: "percentage", Type: DB_Int, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create feature_toggle_override table v1", NewAddTableMigration(overrideV1))
+	mg.AddMigration("add unique index feature_toggle_override.name", NewAddIndexMigration(overrideV1, overrideV1.Indices[0]))
+
+	changeV1 := Table{
+		Name: "feature_toggle_change",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "previous_rollout", Type: DB_Text, Nullable: true},
+			{Name: "rollout", Type: DB_Text, Nullable: true},
+			{Name: "user_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create feature_toggle_change table v1", NewAddTableMigration(changeV1))
+	mg.AddMigration("add index feature_toggle_change.name-created", NewAddIndexMigration(changeV1, changeV1.Indices[0]))
+	mg.AddMigration("add index feature_toggle_change.created", NewAddIndexMigration(changeV1, changeV1.Indices[1]))
+}
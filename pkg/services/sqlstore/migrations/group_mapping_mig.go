@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addGroupMappingMigrations(mg *Migrator) {
+	groupMappingUserV1 := Table{
+		Name: "group_mapping_user",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "auth_module", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "idp_groups", Type: DB_Text, Nullable: false},
+			{Name: "granted", Type: DB_Text, Nullable: false},
+			{Name: "synced_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+			{Cols: []string{"synced_at"}},
+		},
+	}
+
+	mg.AddMigration("create group_mapping_user table v1", NewAddTableMigration(groupMappingUserV1))
+	mg.AddMigration("add unique index group_mapping_user.user_id", NewAddIndexMigration(groupMappingUserV1, groupMappingUserV1.Indices[0]))
+	mg.AddMigration("add index group_mapping_user.synced_at", NewAddIndexMigration(groupMappingUserV1, groupMappingUserV1.Indices[1]))
+}
@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addJobMigrations(mg *Migrator) {
+	jobScheduleV1 := Table{
+		Name: "job_schedule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "schedule", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "next_run", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create job_schedule table v1", NewAddTableMigration(jobScheduleV1))
+	mg.AddMigration("add unique index job_schedule.name", NewAddIndexMigration(jobScheduleV1, jobScheduleV1.Indices[0]))
+
+	jobRunV1 := Table{
+		Name: "job_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "payload", Type: DB_Text, Nullable: false},
+			{Name: "manual", Type: DB_Bool, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "max_attempts", Type: DB_Int, Nullable: false},
+			{Name: "error_message", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "run_at", Type: DB_BigInt, Nullable: false},
+			{Name: "started", Type: DB_BigInt, Nullable: false},
+			{Name: "finished", Type: DB_BigInt, Nullable: false},
+			{Name: "locked_until", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"status", "run_at"}},
+			{Cols: []string{"name", "created"}},
+			{Cols: []string{"finished"}},
+		},
+	}
+
+	mg.AddMigration("create job_run table v1", NewAddTableMigration(jobRunV1))
+	mg.AddMigration("add index job_run.status-run_at", NewAddIndexMigration(jobRunV1, jobRunV1.Indices[0]))
+	mg.AddMigration("add index job_run.name-created", NewAddIndexMigration(jobRunV1, jobRunV1.Indices[1]))
+	mg.AddMigration("add index job_run.finished", NewAddIndexMigration(jobRunV1, jobRunV1.Indices[2]))
+}
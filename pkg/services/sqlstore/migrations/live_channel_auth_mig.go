@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addLiveChannelAuthMigrations(mg *Migrator) {
+	liveChannelAuthRuleV1 := Table{
+		Name: "live_channel_auth_rule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "pattern", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "operations", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "effect", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "roles", Type: DB_Text, Nullable: false},
+			{Name: "team_ids", Type: DB_Text, Nullable: false},
+			{Name: "max_payload_bytes", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_channel_auth_rule table v1", NewAddTableMigration(liveChannelAuthRuleV1))
+	mg.AddMigration("add unique index live_channel_auth_rule.org_id_uid", NewAddIndexMigration(liveChannelAuthRuleV1, liveChannelAuthRuleV1.Indices[0]))
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addLiveMessageHistoryMigrations(mg *Migrator) {
+	liveMessageHistoryV1 := Table{
+		Name: "live_message_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "channel", Type: DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "frame", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "channel", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create live_message_history table v1", NewAddTableMigration(liveMessageHistoryV1))
+	mg.AddMigration("add index live_message_history.org_id_channel_created", NewAddIndexMigration(liveMessageHistoryV1, liveMessageHistoryV1.Indices[0]))
+	mg.AddMigration("add index live_message_history.created", NewAddIndexMigration(liveMessageHistoryV1, liveMessageHistoryV1.Indices[1]))
+}
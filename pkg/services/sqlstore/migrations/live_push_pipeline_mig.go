@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addLivePushPipelineMigrations(mg *Migrator) {
+	livePushPipelineV1 := Table{
+		Name: "live_push_pipeline",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "stream_id", Type: DB_NVarchar, Length: 160, Nullable: false},
+			{Name: "spec", Type: DB_Text, Nullable: false},
+			{Name: "remote_write_password", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "stream_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_push_pipeline table v1", NewAddTableMigration(livePushPipelineV1))
+	mg.AddMigration("add unique index live_push_pipeline.org_id_stream_id", NewAddIndexMigration(livePushPipelineV1, livePushPipelineV1.Indices[0]))
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addMeteringMigrations(mg *Migrator) {
+	orgUsageV1 := Table{
+		Name: "org_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "period_start", Type: DB_BigInt, Nullable: false},
+			{Name: "metric", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "value", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "period_start", "metric"}, Type: UniqueIndex},
+			{Cols: []string{"period_start"}},
+		},
+	}
+
+	mg.AddMigration("create org_usage table v1", NewAddTableMigration(orgUsageV1))
+	mg.AddMigration("add unique index org_usage.org_id-period_start-metric", NewAddIndexMigration(orgUsageV1, orgUsageV1.Indices[0]))
+	mg.AddMigration("add index org_usage.period_start", NewAddIndexMigration(orgUsageV1, orgUsageV1.Indices[1]))
+}
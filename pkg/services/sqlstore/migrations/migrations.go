@@ -129,6 +129,31 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	enableTraceQLStreaming(mg, oss.features != nil && oss.features.IsEnabledGlobally(featuremgmt.FlagTraceQLStreaming))
 
 	ualert.AddReceiverActionScopesMigration(mg)
+
+	ualert.AddMaintenanceWindowTable(mg)
+
+	addQueryAuditMigrations(mg)
+	addRecordedQueryMigrations(mg)
+	addDataSourceUsageMigrations(mg)
+	addCredentialMigrations(mg)
+	addDashboardSchemaStateMigrations(mg)
+	addDashboardPublicShareLinkMigrations(mg)
+	addAnnotationRetentionPolicyMigrations(mg)
+	addDashboardLintMigrations(mg)
+	addDashboardUsageMigrations(mg)
+	addDashboardSizeMigrations(mg)
+	addScheduledReportMigrations(mg)
+	addOutboxMigrations(mg)
+	addFeatureToggleOverrideMigrations(mg)
+	addGroupMappingMigrations(mg)
+	addNetworkPolicyMigrations(mg)
+	addLiveChannelAuthMigrations(mg)
+	addLiveMessageHistoryMigrations(mg)
+	addLivePushPipelineMigrations(mg)
+	addJobMigrations(mg)
+	addMeteringMigrations(mg)
+	ualert.AddSilenceScheduleTables(mg)
+	ualert.AddRuleVersionCreatedByColumn(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
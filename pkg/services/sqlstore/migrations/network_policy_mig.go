@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addNetworkPolicyMigrations(mg *Migrator) {
+	networkPolicyV1 := Table{
+		Name: "network_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "scope", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "scope_id", Type: DB_BigInt, Nullable: false},
+			{Name: "cidrs", Type: DB_Text, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "scope", "scope_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create network_policy table v1", NewAddTableMigration(networkPolicyV1))
+	mg.AddMigration("add unique index network_policy.org_id_scope_scope_id", NewAddIndexMigration(networkPolicyV1, networkPolicyV1.Indices[0]))
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addOutboxMigrations(mg *Migrator) {
+	outboxEventV1 := Table{
+		Name: "outbox_event",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "event_type", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "next_attempt_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"next_attempt_at"}},
+		},
+	}
+
+	mg.AddMigration("create outbox_event table v1", NewAddTableMigration(outboxEventV1))
+	mg.AddMigration("add index outbox_event.next_attempt_at", NewAddIndexMigration(outboxEventV1, outboxEventV1.Indices[0]))
+}
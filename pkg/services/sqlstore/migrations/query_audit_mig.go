@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addQueryAuditMigrations(mg *Migrator) {
+	queryAuditV1 := Table{
+		Name: "query_audit",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "datasource_uids", Type: DB_Text, Nullable: false},
+			{Name: "request", Type: DB_MediumText, Nullable: false},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "bytes", Type: DB_BigInt, Nullable: false},
+			{Name: "status", Type: DB_Int, Nullable: false},
+			{Name: "created_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "created_at"}},
+		},
+	}
+
+	mg.AddMigration("create query_audit table v1", NewAddTableMigration(queryAuditV1))
+	mg.AddMigration("add unique index query_audit.org_id-uid", NewAddIndexMigration(queryAuditV1, queryAuditV1.Indices[0]))
+	mg.AddMigration("add index query_audit.org_id-created_at", NewAddIndexMigration(queryAuditV1, queryAuditV1.Indices[1]))
+}
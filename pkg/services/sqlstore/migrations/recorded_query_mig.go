@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addRecordedQueryMigrations(mg *Migrator) {
+	recordedQueryV1 := Table{
+		Name: "recorded_query",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: false},
+			{Name: "queries", Type: DB_MediumText, Nullable: false},
+			{Name: "target_ref_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "interval_seconds", Type: DB_BigInt, Nullable: false},
+			{Name: "range_seconds", Type: DB_BigInt, Nullable: false},
+			{Name: "labels", Type: DB_Text, Nullable: true},
+			{Name: "active", Type: DB_Bool, Nullable: false},
+			{Name: "created_by", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"active"}},
+		},
+	}
+
+	mg.AddMigration("create recorded_query table v1", NewAddTableMigration(recordedQueryV1))
+	mg.AddMigration("add unique index recorded_query.org_id-uid", NewAddIndexMigration(recordedQueryV1, recordedQueryV1.Indices[0]))
+	mg.AddMigration("add index recorded_query.active", NewAddIndexMigration(recordedQueryV1, recordedQueryV1.Indices[1]))
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addScheduledReportMigrations(mg *Migrator) {
+	scheduledReportV1 := Table{
+		Name: "scheduled_report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "format", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "panel_ids", Type: DB_Text, Nullable: true},
+			{Name: "schedule", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: true},
+			{Name: "webhook_url", Type: DB_Text, Nullable: false},
+			{Name: "failure_recipients", Type: DB_Text, Nullable: true},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "next_run", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"enabled", "next_run"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled_report table v1", NewAddTableMigration(scheduledReportV1))
+	mg.AddMigration("add unique index scheduled_report.org_id-uid", NewAddIndexMigration(scheduledReportV1, scheduledReportV1.Indices[0]))
+	mg.AddMigration("add index scheduled_report.enabled-next_run", NewAddIndexMigration(scheduledReportV1, scheduledReportV1.Indices[1]))
+
+	scheduledReportRunV1 := Table{
+		Name: "scheduled_report_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "report_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "manual", Type: DB_Bool, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error_message", Type: DB_Text, Nullable: false},
+			{Name: "size", Type: DB_BigInt, Nullable: false},
+			{Name: "started", Type: DB_BigInt, Nullable: false},
+			{Name: "finished", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "report_uid", "started"}},
+			{Cols: []string{"started"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled_report_run table v1", NewAddTableMigration(scheduledReportRunV1))
+	mg.AddMigration("add index scheduled_report_run.org_id-report_uid-started", NewAddIndexMigration(scheduledReportRunV1, scheduledReportRunV1.Indices[0]))
+	mg.AddMigration("add index scheduled_report_run.started", NewAddIndexMigration(scheduledReportRunV1, scheduledReportRunV1.Indices[1]))
+}
@@ -19,4 +19,24 @@ func addServerlockMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create server_lock table", migrator.NewAddTableMigration(serverLock))
 
 	mg.AddMigration("add index server_lock.operation_uid", migrator.NewAddIndexMigration(serverLock, serverLock.Indices[0]))
+
+	serverLockLease := migrator.Table{
+		Name: "server_lock_lease",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "operation_uid", Type: migrator.DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "holder", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "token", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "acquired", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "renewed", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "expires", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"operation_uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create server_lock_lease table", migrator.NewAddTableMigration(serverLockLease))
+
+	mg.AddMigration("add index server_lock_lease.operation_uid", migrator.NewAddIndexMigration(serverLockLease, serverLockLease.Indices[0]))
 }
@@ -0,0 +1,31 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddMaintenanceWindowTable creates the table that stores alerting maintenance windows.
+func AddMaintenanceWindowTable(mg *migrator.Migrator) {
+	maintenanceWindow := migrator.Table{
+		Name: "alert_maintenance_window",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "starts_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "folder_uids", Type: migrator.DB_Text, Nullable: true},
+			{Name: "datasource_uids", Type: migrator.DB_Text, Nullable: true},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "ends_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_maintenance_window table", migrator.NewAddTableMigration(maintenanceWindow))
+	mg.AddMigration("add unique index on org_id and uid to alert_maintenance_window table", migrator.NewAddIndexMigration(maintenanceWindow, maintenanceWindow.Indices[0]))
+	mg.AddMigration("add index on org_id and ends_at to alert_maintenance_window table", migrator.NewAddIndexMigration(maintenanceWindow, maintenanceWindow.Indices[1]))
+}
@@ -0,0 +1,55 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddSilenceScheduleTables creates the tables that store silence templates and recurring silences.
+func AddSilenceScheduleTables(mg *migrator.Migrator) {
+	silenceTemplate := migrator.Table{
+		Name: "alert_silence_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_silence_template table", migrator.NewAddTableMigration(silenceTemplate))
+	mg.AddMigration("add unique index on org_id and uid to alert_silence_template table", migrator.NewAddIndexMigration(silenceTemplate, silenceTemplate.Indices[0]))
+
+	recurringSilence := migrator.Table{
+		Name: "alert_recurring_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "schedule", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "timezone", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "duration", Type: migrator.DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "template_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "variables", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "silence_starts_at", Type: migrator.DB_DateTime, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "template_uid"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_recurring_silence table", migrator.NewAddTableMigration(recurringSilence))
+	mg.AddMigration("add unique index on org_id and uid to alert_recurring_silence table", migrator.NewAddIndexMigration(recurringSilence, recurringSilence.Indices[0]))
+	mg.AddMigration("add index on org_id and template_uid to alert_recurring_silence table", migrator.NewAddIndexMigration(recurringSilence, recurringSilence.Indices[1]))
+}
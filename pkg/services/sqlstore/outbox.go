@@ -0,0 +1,83 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// OutboxEvent is an event stored in the transactional outbox until it is published. Events are appended
+// in the transaction of the writes they describe, so they are published if, and only if, the writes
+// are committed, even when Grafana stops before publishing them.
+type OutboxEvent struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	EventType string `xorm:"event_type"`
+	Payload   string `xorm:"payload"`
+	Attempts  int    `xorm:"attempts"`
+	LastError string `xorm:"last_error"`
+	Created   int64  `xorm:"created"`
+	// NextAttemptAt is when the event is due for publishing, either for the first time, after a failed
+	// attempt, or after the lease of the dispatcher publishing it expires.
+	NextAttemptAt int64 `xorm:"next_attempt_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_event"
+}
+
+// OutboxEventType returns the type of an event in the outbox, which is the name of the type it points to,
+// as for the events published on the bus.
+func OutboxEventType(msg any) string {
+	t := reflect.TypeOf(msg)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// AppendOutboxEvent stores msg in the outbox, to be published with at-least-once delivery once the
+// transaction of the session is committed. msg must be a pointer to a struct that can be encoded as JSON.
+// Events of organization 0 are published on the bus only.
+func (sess *DBSession) AppendOutboxEvent(orgID int64, msg any) error {
+	if reflect.TypeOf(msg).Kind() != reflect.Pointer {
+		return fmt.Errorf("outbox event must be a pointer, got %T", msg)
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox event: %w", err)
+	}
+
+	now := time.Now().Unix()
+	if _, err := sess.Insert(&OutboxEvent{
+		OrgID:         orgID,
+		EventType:     OutboxEventType(msg),
+		Payload:       string(payload),
+		Created:       now,
+		NextAttemptAt: now,
+	}); err != nil {
+		return err
+	}
+	sess.outboxAppended = true
+	return nil
+}
+
+// OutboxNotifications receives a notification when events are appended to the outbox and committed,
+// so that the dispatcher doesn't wait for its next poll to publish them.
+func (ss *SQLStore) OutboxNotifications() <-chan struct{} {
+	ss.outboxOnce.Do(ss.initOutboxNotify)
+	return ss.outboxNotify
+}
+
+func (ss *SQLStore) notifyOutbox() {
+	ss.outboxOnce.Do(ss.initOutboxNotify)
+	select {
+	case ss.outboxNotify <- struct{}{}:
+	default: // a notification is already pending
+	}
+}
+
+func (ss *SQLStore) initOutboxNotify() {
+	ss.outboxNotify = make(chan struct{}, 1)
+}
@@ -31,6 +31,9 @@ type ReplStore struct {
 
 	// next is the index of the next read-only SQLStore in the chain.
 	next uint64
+
+	// health is the state of the read replicas, nil when the replicas are not health checked.
+	health *replicaHealth
 }
 
 // DB returns the main SQLStore.
@@ -39,24 +42,37 @@ func (rs *ReplStore) DB() *SQLStore {
 }
 
 // ReadReplica returns the read-only SQLStore. If no read replica is configured,
-// it returns the main SQLStore.
+// or none of them is healthy, it returns the main SQLStore.
 func (rs *ReplStore) ReadReplica() *SQLStore {
 	if len(rs.repls) == 0 {
 		rs.log.Debug("ReadReplica not configured, using main SQLStore")
 		return rs.SQLStore
 	}
-	return rs.nextRepl()
+	if rs.health == nil {
+		return rs.nextRepl()
+	}
+
+	rs.health.checkIfStale(rs.repls)
+	for range rs.repls {
+		index := rs.nextIndex()
+		if rs.health.isHealthy(index) {
+			return rs.repls[index]
+		}
+	}
+
+	rs.log.Debug("No healthy read replica, using main SQLStore")
+	replicaFallbackCounter.Inc()
+	return rs.SQLStore
 }
 
 // nextRepl() returns the next read-only SQLStore in the chain. If no read replica is configured, the Primary is returned.
 func (rs *ReplStore) nextRepl() *SQLStore {
-	// start by grabbing the replica at the current index
-	selected := rs.repls[(int(rs.next))%len(rs.repls)]
-
-	// then increment the index for the next call
-	atomic.AddUint64(&rs.next, 1)
+	return rs.repls[rs.nextIndex()]
+}
 
-	return selected
+// nextIndex returns the index of the next read-only SQLStore in the chain and moves the chain forward.
+func (rs *ReplStore) nextIndex() int {
+	return int((atomic.AddUint64(&rs.next, 1) - 1) % uint64(len(rs.repls)))
 }
 
 // ProvideServiceWithReadReplica creates a new *SQLStore connection intended for
@@ -66,7 +82,7 @@ func ProvideServiceWithReadReplica(primary *SQLStore, cfg *setting.Cfg,
 	features featuremgmt.FeatureToggles, migrations registry.DatabaseMigrator,
 	bus bus.Bus, tracer tracing.Tracer) (*ReplStore, error) {
 	// start with the initialized SQLStore
-	replStore := &ReplStore{primary, nil, 0}
+	replStore := &ReplStore{SQLStore: primary}
 
 	// FeatureToggle fallback: If the FlagDatabaseReadReplica feature flag is not enabled, return a single SQLStore.
 	if !features.IsEnabledGlobally(featuremgmt.FlagDatabaseReadReplica) {
@@ -96,7 +112,7 @@ func ProvideServiceWithReadReplica(primary *SQLStore, cfg *setting.Cfg,
 	for i, replCfg := range replCfgs {
 		// If the database_instrument_queries feature is enabled, wrap the driver with hooks.
 		if cfg.DatabaseInstrumentQueries {
-			replCfg.Type = WrapDatabaseReplDriverWithHooks(replCfg.Type, uint(i), tracer)
+			replCfg.Type = WrapDatabaseReplDriverWithHooks(replCfg.Type, uint(i), tracer, cfg.DatabaseSlowQueryThreshold)
 		}
 
 		s, err := newReadOnlySQLStore(cfg, &replCfg, features, bus, tracer)
@@ -113,6 +129,18 @@ func ProvideServiceWithReadReplica(primary *SQLStore, cfg *setting.Cfg,
 		}
 		replStore.repls[i] = s
 	}
+
+	if len(replStore.repls) > 0 {
+		replicasSection := cfg.Raw.Section("database_replicas")
+		replStore.health = newReplicaHealth(
+			len(replStore.repls),
+			replicasSection.Key("max_replication_lag").MustDuration(10*time.Second),
+			replicasSection.Key("health_check_interval").MustDuration(10*time.Second),
+		)
+		// route the read-only sessions of the primary SQLStore to the read replicas
+		primary.readReplicas = replStore
+	}
+
 	return replStore, nil
 }
 
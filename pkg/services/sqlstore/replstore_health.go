@@ -0,0 +1,197 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const replicaHealthCheckTimeout = 5 * time.Second
+
+var (
+	replicaHealthyGauge    *prometheus.GaugeVec
+	replicaLagGauge        *prometheus.GaugeVec
+	replicaFallbackCounter prometheus.Counter
+)
+
+func init() {
+	replicaHealthyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "database_replica_healthy",
+		Help:      "Whether a read replica is used for read-only queries (1) or skipped because it is unreachable or lagging (0)",
+	}, []string{"replica"})
+	replicaLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "database_replica_lag_seconds",
+		Help:      "Replication lag of a read replica measured by the last health check",
+	}, []string{"replica"})
+	replicaFallbackCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "database_replica_fallbacks_total",
+		Help:      "Number of read-only queries sent to the primary database because no read replica was healthy",
+	})
+
+	prometheus.MustRegister(replicaHealthyGauge, replicaLagGauge, replicaFallbackCounter)
+}
+
+// replicaHealth tracks which read replicas are reachable and close enough to the primary database.
+// Checks run in the background when a replica is selected and the last check is older than the interval,
+// so that selecting a replica never waits on the database.
+type replicaHealth struct {
+	maxLag   time.Duration
+	interval time.Duration
+
+	healthy   []atomic.Bool
+	lastCheck atomic.Int64
+	checking  atomic.Bool
+}
+
+func newReplicaHealth(replicas int, maxLag, interval time.Duration) *replicaHealth {
+	h := &replicaHealth{
+		maxLag:   maxLag,
+		interval: interval,
+		healthy:  make([]atomic.Bool, replicas),
+	}
+	// replicas are presumed healthy until the first check says otherwise
+	for i := range h.healthy {
+		h.healthy[i].Store(true)
+	}
+	return h
+}
+
+func (h *replicaHealth) isHealthy(index int) bool {
+	return h.healthy[index].Load()
+}
+
+// checkIfStale starts a background health check of the replicas unless one is running or the last
+// one is more recent than the check interval.
+func (h *replicaHealth) checkIfStale(repls []*SQLStore) {
+	if h.interval <= 0 || time.Since(time.Unix(0, h.lastCheck.Load())) < h.interval {
+		return
+	}
+	if !h.checking.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer h.checking.Store(false)
+		h.check(context.Background(), repls)
+	}()
+}
+
+func (h *replicaHealth) check(ctx context.Context, repls []*SQLStore) {
+	for i, repl := range repls {
+		label := strconv.Itoa(i)
+		healthy := true
+
+		lag, err := replicationLag(ctx, repl)
+		switch {
+		case err != nil:
+			repl.log.Warn("Read replica health check failed, sending read-only queries to the other databases", "replica", i, "error", err)
+			healthy = false
+		case h.maxLag > 0 && lag > h.maxLag:
+			repl.log.Warn("Read replica is lagging, sending read-only queries to the other databases", "replica", i, "lag", lag, "maxLag", h.maxLag)
+			healthy = false
+		}
+
+		if healthy && !h.healthy[i].Load() {
+			repl.log.Info("Read replica is healthy again", "replica", i)
+		}
+		h.healthy[i].Store(healthy)
+
+		if healthy {
+			replicaHealthyGauge.WithLabelValues(label).Set(1)
+		} else {
+			replicaHealthyGauge.WithLabelValues(label).Set(0)
+		}
+		if err == nil {
+			replicaLagGauge.WithLabelValues(label).Set(lag.Seconds())
+		}
+	}
+	h.lastCheck.Store(time.Now().UnixNano())
+}
+
+// replicationLag returns how far behind the primary database a replica is. Replicas that can't report
+// their lag, for example because replication is stopped, return an error.
+func replicationLag(ctx context.Context, repl *SQLStore) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, replicaHealthCheckTimeout)
+	defer cancel()
+
+	db := repl.engine.DB().DB
+	switch repl.dialect.DriverName() {
+	case migrator.MySQL:
+		return mysqlReplicationLag(ctx, db)
+	case migrator.Postgres:
+		return postgresReplicationLag(ctx, db)
+	default:
+		return 0, db.PingContext(ctx)
+	}
+}
+
+func mysqlReplicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		// MySQL before 8.0.22 and MariaDB before 10.5.1
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, err
+		}
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		// not a replica, the database is its own source
+		return 0, rows.Err()
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if !strings.EqualFold(column, "Seconds_Behind_Source") && !strings.EqualFold(column, "Seconds_Behind_Master") {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, errors.New("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errors.New("replication lag is not reported by the replica status")
+}
+
+func postgresReplicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var seconds sql.NullFloat64
+	err := db.QueryRowContext(ctx, `SELECT CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END`).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	if !seconds.Valid {
+		return 0, errors.New("replication is not running")
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)), nil
+}
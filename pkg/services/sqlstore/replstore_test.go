@@ -3,11 +3,13 @@ package sqlstore
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/ini.v1"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -31,6 +33,35 @@ func TestReplStore_ReadReplica(t *testing.T) {
 	}
 }
 
+func TestReplStore_ReadReplicaHealth(t *testing.T) {
+	primary := &SQLStore{dbCfg: &DatabaseConfig{ConnectionString: "primary"}, log: log.NewNopLogger()}
+	repl0 := &SQLStore{dbCfg: &DatabaseConfig{ConnectionString: "repl0"}}
+	repl1 := &SQLStore{dbCfg: &DatabaseConfig{ConnectionString: "repl1"}}
+	replStore := newReplStore(primary, repl0, repl1)
+	// a zero interval disables the background checks, so that the test controls the health of the replicas
+	replStore.health = newReplicaHealth(2, 10*time.Second, 0)
+
+	readReplicas := func(n int) []string {
+		got := make([]string, n)
+		for i := range got {
+			got[i] = replStore.ReadReplica().dbCfg.ConnectionString
+		}
+		return got
+	}
+
+	require.Equal(t, []string{"repl0", "repl1", "repl0"}, readReplicas(3))
+
+	t.Run("skips unhealthy replicas", func(t *testing.T) {
+		replStore.health.healthy[0].Store(false)
+		require.Equal(t, []string{"repl1", "repl1", "repl1"}, readReplicas(3))
+	})
+
+	t.Run("falls back to the primary when no replica is healthy", func(t *testing.T) {
+		replStore.health.healthy[1].Store(false)
+		require.Equal(t, []string{"primary", "primary"}, readReplicas(2))
+	})
+}
+
 func TestNewRODatabaseConfig(t *testing.T) {
 	t.Run("valid config", func(t *testing.T) {
 		inicfg, err := ini.Load([]byte(testReplCfg))
@@ -31,7 +31,7 @@ func (b *Builder) ToSQL(limit, page int64) (string, []any) {
 	b.buildSelect()
 
 	b.sql.WriteString("( ")
-	orderQuery := b.applyFilters()
+	orderQuery, orderParams := b.applyFilters()
 
 	b.sql.WriteString(b.Dialect.LimitOffset(limit, (page-1)*limit) + `) AS ids
 		INNER JOIN dashboard ON ids.id = dashboard.id`)
@@ -49,6 +49,7 @@ func (b *Builder) ToSQL(limit, page int64) (string, []any) {
 	LEFT OUTER JOIN dashboard_tag ON dashboard.id = dashboard_tag.dashboard_id`)
 	b.sql.WriteString("\n")
 	b.sql.WriteString(orderQuery)
+	b.params = append(b.params, orderParams...)
 
 	return b.sql.String(), b.params
 }
@@ -82,6 +83,9 @@ func (b *Builder) buildSelect() {
 	for _, f := range b.Filters {
 		if f, ok := f.(model.FilterSelect); ok {
 			b.sql.WriteString(fmt.Sprintf(", %s", f.Select()))
+			if f, ok := f.(model.FilterParams); ok {
+				b.params = append(b.params, f.Params()...)
+			}
 		}
 
 		if f, ok := f.(model.FilterWith); ok {
@@ -104,7 +108,7 @@ func (b *Builder) buildSelect() {
 	b.params = append(recQueryParams, b.params...)
 }
 
-func (b *Builder) applyFilters() (ordering string) {
+func (b *Builder) applyFilters() (ordering string, orderParams []any) {
 	joins := []string{}
 	orderJoins := []string{}
 
@@ -145,6 +149,9 @@ func (b *Builder) applyFilters() (ordering string) {
 				orderJoins = append(orderJoins, fmt.Sprintf(" LEFT OUTER JOIN %s ", f.LeftJoin()))
 			}
 			orders = append(orders, f.OrderBy())
+			if f, ok := f.(model.FilterParams); ok {
+				orderParams = append(orderParams, f.Params()...)
+			}
 		}
 	}
 
@@ -188,8 +195,9 @@ func (b *Builder) applyFilters() (ordering string) {
 
 	orderBy := fmt.Sprintf(" ORDER BY %s", strings.Join(orderByCols, ", "))
 	b.sql.WriteString(orderBy)
+	b.params = append(b.params, orderParams...)
 
 	order := strings.Join(orderJoins, "")
 	order += orderBy
-	return order
+	return order, orderParams
 }
@@ -3,6 +3,7 @@ package searchstore
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -140,20 +141,16 @@ type TagsFilter struct {
 	Tags []string
 }
 
-func (f TagsFilter) LeftJoin() string {
-	return `dashboard_tag ON dashboard_tag.dashboard_id = dashboard.id`
-}
-
-func (f TagsFilter) GroupBy() (string, []any) {
-	return `dashboard.id HAVING COUNT(dashboard.id) >= ?`, []any{len(f.Tags)}
-}
-
+// Where matches the dashboards with all the tags in a subquery rather than by grouping the joined tags, so that
+// the filter can be combined with orderings that have parameters.
 func (f TagsFilter) Where() (string, []any) {
-	params := make([]any, len(f.Tags))
-	for i, tag := range f.Tags {
-		params[i] = tag
+	params := make([]any, 0, len(f.Tags)+1)
+	for _, tag := range f.Tags {
+		params = append(params, tag)
 	}
-	return `dashboard_tag.term IN (?` + strings.Repeat(",?", len(f.Tags)-1) + `)`, params
+	params = append(params, len(f.Tags))
+	return `dashboard.id IN (SELECT dashboard_id FROM dashboard_tag WHERE term IN (?` + strings.Repeat(",?", len(f.Tags)-1) +
+		`) GROUP BY dashboard_id HAVING COUNT(dashboard_id) >= ?)`, params
 }
 
 type TitleSorter struct {
@@ -168,6 +165,91 @@ func (s TitleSorter) OrderBy() string {
 	return "dashboard.title ASC"
 }
 
+// RelevanceSorter sorts the dashboards by relevance, the most relevant first. The relevance score is the sum of
+// the weight of the title match, of how often the panels of the dashboard were queried during the last 30 days,
+// and of how recently the dashboard was updated. The score is returned as the sort meta of the hits, and ties are
+// broken by title and ID so that the results can be paginated with a keyset from the last hit of a page.
+type RelevanceSorter struct {
+	Dialect migrator.Dialect
+	OrgID   int64
+	Title   string
+	Now     time.Time
+	// After is the last hit of the previous page, nil for the first page.
+	After *RelevanceCursor
+}
+
+// RelevanceCursor is the position of a hit in the results sorted by relevance.
+type RelevanceCursor struct {
+	Score int64  `json:"score"`
+	Title string `json:"title"`
+	ID    int64  `json:"id"`
+}
+
+var (
+	_ model.FilterOrderBy  = RelevanceSorter{}
+	_ model.FilterSelect   = RelevanceSorter{}
+	_ model.FilterLeftJoin = RelevanceSorter{}
+	_ model.FilterWhere    = RelevanceSorter{}
+	_ model.FilterParams   = RelevanceSorter{}
+)
+
+func (s RelevanceSorter) score() string {
+	title := "0"
+	if s.Title != "" {
+		like := s.Dialect.LikeStr()
+		title = fmt.Sprintf("CASE WHEN LOWER(dashboard.title) = ? THEN 400 WHEN LOWER(dashboard.title) %s ? ESCAPE '!' THEN 300 "+
+			"WHEN LOWER(dashboard.title) %s ? ESCAPE '!' THEN 200 ELSE 100 END", like, like)
+	}
+	return "(" + title + ` +
+		CASE WHEN dashboard_usage.queries >= 10000 THEN 40 WHEN dashboard_usage.queries >= 1000 THEN 30
+			WHEN dashboard_usage.queries >= 100 THEN 20 WHEN dashboard_usage.queries > 0 THEN 10 ELSE 0 END +
+		CASE WHEN dashboard.updated >= ? THEN 20 WHEN dashboard.updated >= ? THEN 10 ELSE 0 END)`
+}
+
+// Params returns the parameters of the relevance score.
+func (s RelevanceSorter) Params() []any {
+	params := make([]any, 0, 5)
+	if s.Title != "" {
+		title := strings.ToLower(s.Title)
+		pattern := likeEscaper.Replace(title)
+		params = append(params, title, pattern+"%", "% "+pattern+"%")
+	}
+	return append(params, s.Now.AddDate(0, 0, -7), s.Now.AddDate(0, 0, -30))
+}
+
+func (s RelevanceSorter) Select() string {
+	return s.score() + " AS sort_meta"
+}
+
+// LeftJoin joins the number of queries of the panels of the dashboards during the last 30 days. The organization
+// and the first day are not user input, and are part of the SQL since the joins of filters have no parameters.
+func (s RelevanceSorter) LeftJoin() string {
+	return fmt.Sprintf(`(SELECT name AS dashboard_uid, SUM(queries) AS queries FROM datasource_usage_source
+		WHERE org_id = %d AND kind = 'dashboard' AND day >= '%s' GROUP BY name) AS dashboard_usage ON dashboard_usage.dashboard_uid = dashboard.uid`,
+		s.OrgID, s.Now.UTC().AddDate(0, 0, -30).Format(time.DateOnly))
+}
+
+func (s RelevanceSorter) OrderBy() string {
+	return s.score() + " DESC, dashboard.title ASC, dashboard.id ASC"
+}
+
+// Where returns the dashboards after the cursor of the previous page.
+func (s RelevanceSorter) Where() (string, []any) {
+	if s.After == nil {
+		return "", nil
+	}
+	score, scoreParams := s.score(), s.Params()
+	params := append([]any{}, scoreParams...)
+	params = append(params, s.After.Score)
+	params = append(params, scoreParams...)
+	params = append(params, s.After.Score, s.After.Title, s.After.Title, s.After.ID)
+	return fmt.Sprintf("(%s < ? OR (%s = ? AND (dashboard.title > ? OR (dashboard.title = ? AND dashboard.id > ?))))", score, score), params
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with the escape character "!", which needs no quoting in
+// any of the SQL dialects, unlike a backslash.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 func sqlIDin(column string, ids []int64) (string, []any) {
 	length := len(ids)
 	if length < 1 {
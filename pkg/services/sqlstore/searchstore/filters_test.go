@@ -2,6 +2,7 @@ package searchstore_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/stretchr/testify/assert"
@@ -57,3 +58,9 @@ func TestFolderUIDFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestRelevanceSorterParams(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	sorter := searchstore.RelevanceSorter{OrgID: 1, Title: "CPU_100%!", Now: now}
+	assert.Equal(t, []any{"cpu_100%!", "cpu!_100!%!!%", "% cpu!_100!%!!%", now.AddDate(0, 0, -7), now.AddDate(0, 0, -30)}, sorter.Params())
+}
@@ -4,6 +4,7 @@ package searchstore_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -352,6 +353,42 @@ func TestBuilder_RBAC(t *testing.T) {
 	}
 }
 
+func TestBuilder_RelevanceKeysetPagination(t *testing.T) {
+	store := setupTestEnvironment(t)
+	createDashboards(t, store, 0, 25, 1)
+
+	search := func(after *searchstore.RelevanceCursor) []dashboards.DashboardSearchProjection {
+		builder := &searchstore.Builder{
+			Filters: []any{
+				searchstore.RelevanceSorter{Dialect: store.GetDialect(), OrgID: 1, Title: "C", Now: time.Now(), After: after},
+				searchstore.OrgFilter{OrgId: 1},
+				searchstore.TagsFilter{Tags: []string{"templated"}},
+			},
+			Dialect:  store.GetDialect(),
+			Features: featuremgmt.WithFeatures(),
+		}
+		res := []dashboards.DashboardSearchProjection{}
+		err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			sql, params := builder.ToSQL(15, 1)
+			return sess.SQL(sql, params...).Find(&res)
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	resPg1 := search(nil)
+	require.Len(t, resPg1, 15)
+	assert.Equal(t, "C", resPg1[0].Title, "the exact title match should be first")
+	assert.Equal(t, int64(400), resPg1[0].SortMeta)
+	assert.Equal(t, "A", resPg1[1].Title)
+	assert.Equal(t, int64(100), resPg1[1].SortMeta)
+
+	last := resPg1[len(resPg1)-1]
+	resPg2 := search(&searchstore.RelevanceCursor{Score: last.SortMeta, Title: last.Title, ID: last.ID})
+	require.Len(t, resPg2, 10)
+	assert.Equal(t, "P", resPg2[0].Title, "page 2 should start after the last dashboard of page 1")
+}
+
 func setupTestEnvironment(t *testing.T) db.DB {
 	t.Helper()
 	store := db.InitTestDB(t)
@@ -27,6 +27,8 @@ type DBSession struct {
 	*xorm.Session
 	transactionOpen bool
 	events          []any
+	// outboxAppended is set when events are appended to the outbox in the session.
+	outboxAppended bool
 }
 
 type DBTransactionFunc func(sess *DBSession) error
@@ -80,6 +82,16 @@ func (ss *SQLStore) WithDbSession(ctx context.Context, callback DBTransactionFun
 	return ss.withDbSession(ctx, ss.engine, callback)
 }
 
+// WithReadOnlyDbSession runs read-only database operations on a healthy read replica when
+// read replicas are configured, and on the main database otherwise. Operations running within
+// a transaction available through [context.Context] always use the transaction.
+func (ss *SQLStore) WithReadOnlyDbSession(ctx context.Context, callback DBTransactionFunc) error {
+	if _, ok := ctx.Value(ContextSessionKey{}).(*DBSession); ok || ss.readReplicas == nil {
+		return ss.WithDbSession(ctx, callback)
+	}
+	return ss.readReplicas.ReadReplica().WithDbSession(ctx, callback)
+}
+
 func (ss *SQLStore) retryOnLocks(ctx context.Context, callback DBTransactionFunc, sess *DBSession, retry int) func() (retryer.RetrySignal, error) {
 	return func() (retryer.RetrySignal, error) {
 		retry++
@@ -0,0 +1,201 @@
+package sqlstore
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSlowQueries is the number of distinct slow queries kept in memory.
+	maxSlowQueries = 100
+	// maxSlowQueryLength truncates the SQL of slow queries kept in memory and logged.
+	maxSlowQueryLength = 2000
+)
+
+var slowQueries = newSlowQueryTracker(maxSlowQueries)
+
+// SlowQuery aggregates the executions of a query slower than the slow query threshold.
+type SlowQuery struct {
+	Service    string    `json:"service"`
+	Method     string    `json:"method"`
+	Query      string    `json:"query"`
+	Count      int64     `json:"count"`
+	MaxMs      float64   `json:"maxMs"`
+	TotalMs    float64   `json:"totalMs"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// SlowQueries returns the slowest queries executed since startup, slowest first. It is only
+// populated when the database queries are instrumented and a slow query threshold is set.
+func SlowQueries(limit int) []SlowQuery {
+	return slowQueries.top(limit)
+}
+
+type slowQueryKey struct {
+	service string
+	method  string
+	query   string
+}
+
+type slowQueryTracker struct {
+	mtx     sync.Mutex
+	size    int
+	queries map[slowQueryKey]*SlowQuery
+}
+
+func newSlowQueryTracker(size int) *slowQueryTracker {
+	return &slowQueryTracker{
+		size:    size,
+		queries: make(map[slowQueryKey]*SlowQuery),
+	}
+}
+
+func (t *slowQueryTracker) record(c queryCaller, query string, elapsed time.Duration, now time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	key := slowQueryKey{service: c.service, method: c.method, query: query}
+	q, ok := t.queries[key]
+	if !ok {
+		if len(t.queries) >= t.size {
+			if !t.evictFaster(elapsed) {
+				return
+			}
+		}
+		q = &SlowQuery{Service: c.service, Method: c.method, Query: query}
+		t.queries[key] = q
+	}
+
+	ms := float64(elapsed) / float64(time.Millisecond)
+	q.Count++
+	q.TotalMs += ms
+	if ms > q.MaxMs {
+		q.MaxMs = ms
+	}
+	q.LastSeenAt = now
+}
+
+// evictFaster removes the query with the lowest maximum duration if it is faster than elapsed.
+func (t *slowQueryTracker) evictFaster(elapsed time.Duration) bool {
+	var fastest slowQueryKey
+	minMs := -1.0
+	for k, q := range t.queries {
+		if minMs < 0 || q.MaxMs < minMs {
+			fastest, minMs = k, q.MaxMs
+		}
+	}
+
+	if minMs >= float64(elapsed)/float64(time.Millisecond) {
+		return false
+	}
+	delete(t.queries, fastest)
+	return true
+}
+
+func (t *slowQueryTracker) top(limit int) []SlowQuery {
+	t.mtx.Lock()
+	result := make([]SlowQuery, 0, len(t.queries))
+	for _, q := range t.queries {
+		result = append(result, *q)
+	}
+	t.mtx.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].MaxMs > result[j].MaxMs })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// queryCaller identifies the code running a query, for example service "services/dashboards/database"
+// and method "(*dashboardStore).GetDashboard".
+type queryCaller struct {
+	service string
+	method  string
+}
+
+var unknownCaller = queryCaller{service: "unknown", method: "unknown"}
+
+// callerSkipPrefixes are the packages between the code running a query and the database driver.
+var callerSkipPrefixes = []string{
+	"runtime.",
+	"database/sql.",
+	"github.com/gchaincl/sqlhooks",
+	"xorm.io/",
+	"github.com/mattn/go-sqlite3",
+	"github.com/go-sql-driver/mysql",
+	"github.com/lib/pq",
+	"github.com/jmoiron/sqlx",
+	"github.com/grafana/grafana/pkg/services/sqlstore",
+	"github.com/grafana/grafana/pkg/infra/db.",
+}
+
+var closureSuffix = regexp.MustCompile(`(\.(func|gowrap)\d+(\.\d+)*)+$`)
+
+// findQueryCaller walks the stack up to the first function outside of the database layers.
+func findQueryCaller() queryCaller {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !skipCallerFrame(frame.Function) {
+			return parseQueryCaller(frame.Function)
+		}
+		if !more {
+			return unknownCaller
+		}
+	}
+}
+
+func skipCallerFrame(function string) bool {
+	for _, prefix := range callerSkipPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseQueryCaller(function string) queryCaller {
+	function = closureSuffix.ReplaceAllString(function, "")
+
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return unknownCaller
+	}
+	dot += slash + 1
+
+	return queryCaller{
+		service: strings.TrimPrefix(function[:dot], "github.com/grafana/grafana/pkg/"),
+		method:  function[dot+1:],
+	}
+}
+
+// redactArgs replaces the bound parameters of a query by their types, so that slow query
+// logs don't leak the values stored in the database.
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			redacted[i] = "<nil>"
+			continue
+		}
+		redacted[i] = fmt.Sprintf("<%T>", arg)
+	}
+	return redacted
+}
+
+func truncateQuery(query string) string {
+	if len(query) <= maxSlowQueryLength {
+		return query
+	}
+	return query[:maxSlowQueryLength] + "..."
+}
@@ -0,0 +1,49 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQueryTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newSlowQueryTracker(2)
+	dashboards := queryCaller{service: "services/dashboards/database", method: "(*dashboardStore).GetDashboard"}
+	alerts := queryCaller{service: "services/ngalert/store", method: "(*DBstore).ListAlertRules"}
+
+	tracker.record(dashboards, "SELECT * FROM dashboard", 2*time.Second, now)
+	tracker.record(dashboards, "SELECT * FROM dashboard", time.Second, now)
+	tracker.record(alerts, "SELECT * FROM alert_rule", 3*time.Second, now)
+
+	top := tracker.top(0)
+	require.Len(t, top, 2)
+	require.Equal(t, "SELECT * FROM alert_rule", top[0].Query)
+	require.Equal(t, int64(2), top[1].Count)
+	require.Equal(t, float64(2000), top[1].MaxMs)
+	require.Equal(t, float64(3000), top[1].TotalMs)
+
+	t.Run("keeps the slowest queries when full", func(t *testing.T) {
+		tracker.record(alerts, "SELECT * FROM alert_instance", time.Second, now)
+		require.Len(t, tracker.top(0), 2, "a faster query doesn't replace a slower one")
+
+		tracker.record(alerts, "SELECT * FROM alert_instance", 5*time.Second, now)
+		top := tracker.top(1)
+		require.Len(t, top, 1)
+		require.Equal(t, "SELECT * FROM alert_instance", top[0].Query)
+		require.Len(t, tracker.top(0), 2)
+	})
+}
+
+func TestParseQueryCaller(t *testing.T) {
+	require.Equal(t, queryCaller{service: "services/dashboards/database", method: "(*dashboardStore).GetDashboard"},
+		parseQueryCaller("github.com/grafana/grafana/pkg/services/dashboards/database.(*dashboardStore).GetDashboard.func1.2"))
+	require.Equal(t, queryCaller{service: "github.com/example/plugin", method: "Load"},
+		parseQueryCaller("github.com/example/plugin.Load"))
+	require.Equal(t, unknownCaller, parseQueryCaller("main"))
+}
+
+func TestRedactArgs(t *testing.T) {
+	require.Equal(t, []string{"<string>", "<int64>", "<nil>"}, redactArgs([]any{"secret", int64(1), nil}))
+}
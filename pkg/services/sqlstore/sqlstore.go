@@ -51,6 +51,10 @@ type SQLStore struct {
 	tracer                       tracing.Tracer
 	recursiveQueriesAreSupported *bool
 	recursiveQueriesMu           sync.Mutex
+	// readReplicas runs the read-only sessions when read replicas are configured.
+	readReplicas *ReplStore
+	outboxOnce   sync.Once
+	outboxNotify chan struct{}
 }
 
 func ProvideService(cfg *setting.Cfg,
@@ -254,7 +258,7 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	ss.dbCfg = dbCfg
 
 	if ss.cfg.DatabaseInstrumentQueries {
-		ss.dbCfg.Type = WrapDatabaseDriverWithHooks(ss.dbCfg.Type, ss.tracer)
+		ss.dbCfg.Type = WrapDatabaseDriverWithHooks(ss.dbCfg.Type, ss.tracer, ss.cfg.DatabaseSlowQueryThreshold)
 	}
 
 	ss.log.Info("Connecting to DB", "dbtype", ss.dbCfg.Type)
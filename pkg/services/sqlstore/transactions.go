@@ -84,6 +84,9 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 	if err := sess.Commit(); err != nil {
 		return err
 	}
+	if sess.outboxAppended {
+		ss.notifyOutbox()
+	}
 
 	for _, e := range sess.events {
 		if err = bus.Publish(ctx, e); err != nil {
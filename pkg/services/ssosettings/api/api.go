@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"io"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -71,6 +72,7 @@ func (api *Api) RegisterAPIEndpoints() {
 		router.Get("/", auth(ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(api.listAllProvidersSettings))
 		router.Get("/:key", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.getProviderSettings))
 		router.Put("/:key", reqWriteAccess, routing.Wrap(api.updateProviderSettings))
+		router.Post("/:key/test", reqWriteAccess, routing.Wrap(api.testProviderSettings))
 		router.Delete("/:key", reqWriteAccess, routing.Wrap(api.removeProviderSettings))
 	})
 }
@@ -186,6 +188,55 @@ func (api *Api) updateProviderSettings(c *contextmodel.ReqContext) response.Resp
 	return response.Empty(http.StatusNoContent)
 }
 
+// swagger:route POST /v1/sso-settings/{key}/test sso_settings testProviderSettings
+//
+// # Test SSO Settings
+//
+// Tests the SSO Settings for a provider without saving them: validates the settings, discovers the metadata
+// of the provider, validates the client credentials where possible and maps the claims of a sample token
+// with the settings.
+//
+// You need to have a permission with action `settings:write` and scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 200: testSSOSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *Api) testProviderSettings(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read request body", err)
+	}
+
+	var settings models.SSOSettings
+	if err := json.Unmarshal(body, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to parse request body", err)
+	}
+	var cmd struct {
+		Sample models.TestSample `json:"sample"`
+	}
+	if err := json.Unmarshal(body, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to parse request body", err)
+	}
+
+	settings.Provider = key
+
+	report, err := api.SSOSettingsService.Test(c.Req.Context(), &settings, cmd.Sample, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to test provider settings", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
 // swagger:route DELETE /v1/sso-settings/{key} sso_settings removeProviderSettings
 //
 // # Remove SSO Settings
@@ -240,6 +291,19 @@ type UpdateProviderSettingsParams struct {
 	} `json:"body"`
 }
 
+// swagger:parameters testProviderSettings
+type TestProviderSettingsParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+	// in:body
+	// required:true
+	Body struct {
+		Settings map[string]any    `json:"settings"`
+		Sample   models.TestSample `json:"sample"`
+	} `json:"body"`
+}
+
 // swagger:parameters removeProviderSettings
 type RemoveProviderSettingsParams struct {
 	// in:path
@@ -268,3 +332,9 @@ type GetSSOSettingsResponse struct {
 		Source   string         `json:"source"`
 	} `json:"body"`
 }
+
+// swagger:response testSSOSettingsResponse
+type TestSSOSettingsResponse struct {
+	// in: body
+	Body models.TestReport `json:"body"`
+}
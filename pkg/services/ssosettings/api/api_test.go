@@ -259,6 +259,94 @@ func TestSSOSettingsAPI_Delete(t *testing.T) {
 	}
 }
 
+func TestSSOSettingsAPI_Test(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		key                 string
+		action              string
+		scope               string
+		expectedReport      *models.TestReport
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	report := &models.TestReport{
+		Provider: social.GenericOAuthProviderName,
+		Success:  true,
+		Checks:   []models.TestCheck{{Name: "validation", Status: models.TestStatusPassed, Message: "The settings are valid"}},
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully tests SSO settings",
+			key:                 social.GenericOAuthProviderName,
+			action:              "settings:write",
+			scope:               "settings:auth.generic_oauth:*",
+			expectedReport:      report,
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusOK,
+		},
+		{
+			desc:                "fails when action doesn't match",
+			key:                 social.GenericOAuthProviderName,
+			action:              "settings:read",
+			scope:               "settings:auth.generic_oauth:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails when scope contains another provider",
+			key:                 social.GenericOAuthProviderName,
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with not found when provider is not configurable",
+			key:                 social.GenericOAuthProviderName,
+			action:              "settings:write",
+			scope:               "settings:auth.generic_oauth:*",
+			expectedError:       ssosettings.ErrNotConfigurable,
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				service.On("Test", mock.Anything, mock.MatchedBy(func(settings *models.SSOSettings) bool {
+					return settings.Provider == tt.key && settings.Settings["client_id"] == "client"
+				}), models.TestSample{Claims: map[string]any{"email": "user@example.org"}}, mock.Anything).
+					Return(tt.expectedReport, tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			body := `{"settings": {"clientId": "client"}, "sample": {"claims": {"email": "user@example.org"}}}`
+			path := fmt.Sprintf("/api/v1/sso-settings/%s/test", tt.key)
+			req := server.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgRole:     org.RoleEditor,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			if tt.expectedReport != nil {
+				var got models.TestReport
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+				require.Equal(t, *tt.expectedReport, got)
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
 func TestSSOSettingsAPI_GetForProvider(t *testing.T) {
 	type TestCase struct {
 		desc                string
@@ -97,3 +97,41 @@ func (s *SSOSettings) UnmarshalJSON(data []byte) error {
 	s.Settings = settings
 	return nil
 }
+
+// TestSample is a sample of the claims of a user, either a JWT (usually an ID token issued by the provider) or
+// the claims themselves, which is mapped with the tested settings.
+type TestSample struct {
+	Token  string         `json:"token,omitempty"`
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+type TestStatus string
+
+const (
+	TestStatusPassed  TestStatus = "passed"
+	TestStatusWarning TestStatus = "warning"
+	TestStatusFailed  TestStatus = "failed"
+	TestStatusSkipped TestStatus = "skipped"
+)
+
+// TestCheck is the result of a check of the settings of a provider.
+type TestCheck struct {
+	Name    string         `json:"name"`
+	Status  TestStatus     `json:"status"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// TestReport contains the diagnostics of the test of the settings of a provider.
+type TestReport struct {
+	Provider string      `json:"provider"`
+	Success  bool        `json:"success"`
+	Checks   []TestCheck `json:"checks"`
+}
+
+func (r *TestReport) Add(check TestCheck) {
+	r.Checks = append(r.Checks, check)
+	if check.Status == TestStatusFailed {
+		r.Success = false
+	}
+}
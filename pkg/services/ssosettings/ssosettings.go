@@ -26,6 +26,8 @@ type Service interface {
 	GetForProviderWithRedactedSecrets(ctx context.Context, provider string) (*models.SSOSettings, error)
 	// Upsert creates or updates the SSO settings for a given provider
 	Upsert(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) error
+	// Test validates the SSO settings for a given provider against the provider, without saving them
+	Test(ctx context.Context, settings *models.SSOSettings, sample models.TestSample, requester identity.Requester) (*models.TestReport, error)
 	// Delete deletes the SSO settings for a given provider (soft delete)
 	Delete(ctx context.Context, provider string) error
 	// Patch updates the specified SSO settings (key-value pairs) for a given provider
@@ -44,6 +46,12 @@ type Reloadable interface {
 	Validate(ctx context.Context, settings models.SSOSettings, oldSettings models.SSOSettings, requester identity.Requester) error
 }
 
+// ClaimsMapper can be implemented by a Reloadable to map the claims of a user with settings which are not saved yet,
+// so that the attribute paths and the role mappings can be tested before the settings are saved.
+type ClaimsMapper interface {
+	MapClaims(ctx context.Context, settings models.SSOSettings, claims []byte) (*social.BasicUserInfo, error)
+}
+
 // FallbackStrategy is an interface that can be implemented to allow a provider to load settings from a different source
 // than the database. This is useful for providers that are not configured in the database, but instead are configured
 // using the config file and/or environment variables. Used mostly for backwards compatibility.
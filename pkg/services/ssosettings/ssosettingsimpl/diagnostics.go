@@ -0,0 +1,368 @@
+package ssosettingsimpl
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+)
+
+const (
+	testTimeout = 10 * time.Second
+	// maxDiscoveryAttempts limits the number of issuers which are derived from the auth URL to discover the metadata.
+	maxDiscoveryAttempts = 4
+	wellKnownPath        = "/.well-known/openid-configuration"
+)
+
+// Test validates the settings like Upsert does, then checks them against the provider without saving them:
+// it discovers the metadata of the provider, authenticates the client where possible, and maps the sample
+// claims of a user with the settings. The failures of the checks are reported in the diagnostics, only the
+// errors which prevent the test from running are returned.
+func (s *Service) Test(ctx context.Context, settings *models.SSOSettings, sample models.TestSample, requester identity.Requester) (*models.TestReport, error) {
+	if !s.isProviderConfigurable(settings.Provider) {
+		return nil, ssosettings.ErrNotConfigurable
+	}
+
+	reloadable, ok := s.reloadables[settings.Provider]
+	if !ok {
+		return nil, ssosettings.ErrInvalidProvider.Errorf("provider %s not found in reloadables", settings.Provider)
+	}
+
+	storedSettings, err := s.GetForProvider(ctx, settings.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSecretsForTokenURL(settings.Settings, storedSettings.Settings); err != nil {
+		return nil, err
+	}
+
+	settingsWithSecrets, err := mergeSecrets(settings.Settings, storedSettings.Settings)
+	if err != nil {
+		return nil, err
+	}
+	testSettings := *settings
+	testSettings.Settings = settingsWithSecrets
+
+	report := &models.TestReport{Provider: settings.Provider, Success: true}
+	report.Add(validationCheck(reloadable.Validate(ctx, testSettings, *storedSettings, requester)))
+
+	if !slices.Contains(ssosettings.AllOAuthProviders, settings.Provider) {
+		report.Add(skipped("discovery", "Metadata discovery is only supported by the OAuth providers"))
+		report.Add(skipped("client_credentials", "Client credentials are only validated for the OAuth providers"))
+	} else if info, err := connectors.CreateOAuthInfoFromKeyValues(testSettings.Settings); err != nil {
+		report.Add(models.TestCheck{Name: "settings", Status: models.TestStatusFailed, Message: err.Error()})
+	} else {
+		client := s.testHTTPClient(info)
+		report.Add(discoveryCheck(ctx, client, info))
+		report.Add(clientCredentialsCheck(ctx, client, info))
+	}
+
+	report.Add(s.claimMappingCheck(ctx, reloadable, testSettings, sample))
+	return report, nil
+}
+
+// checkSecretsForTokenURL ensures the stored secrets are only sent to the token URL they were saved with: the
+// secrets must be given in the request to test another token URL.
+func checkSecretsForTokenURL(settings map[string]any, storedSettings map[string]any) error {
+	tokenURL, _ := settings["token_url"].(string)
+	storedTokenURL, _ := storedSettings["token_url"].(string)
+	if tokenURL == storedTokenURL {
+		return nil
+	}
+	for k, v := range settings {
+		if value, ok := v.(string); ok && IsSecretField(k) && !isNewSecretValue(value) {
+			return ssosettings.ErrInvalidOAuthConfig(fmt.Sprintf("The %s must be given to test a token URL other than the saved one", k))
+		}
+	}
+	return nil
+}
+
+func validationCheck(err error) models.TestCheck {
+	if err == nil {
+		return models.TestCheck{Name: "validation", Status: models.TestStatusPassed, Message: "The settings are valid"}
+	}
+
+	msg := err.Error()
+	var gfErr errutil.Error
+	if errors.As(err, &gfErr) && gfErr.PublicMessage != "" {
+		msg = gfErr.PublicMessage
+	}
+	return models.TestCheck{Name: "validation", Status: models.TestStatusFailed, Message: msg}
+}
+
+func skipped(name, msg string) models.TestCheck {
+	return models.TestCheck{Name: name, Status: models.TestStatusSkipped, Message: msg}
+}
+
+func (s *Service) testHTTPClient(info *social.OAuthInfo) *http.Client {
+	if s.httpClient != nil {
+		return s.httpClient
+	}
+	return &http.Client{
+		Timeout: testTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: info.TlsSkipVerify, // #nosec G402 same as the client of the provider
+			},
+		},
+	}
+}
+
+type providerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	ScopesSupported       []string `json:"scopes_supported"`
+}
+
+// discoveryCheck fetches the OpenID configuration of the provider, trying the issuers derived from the auth URL,
+// and compares the endpoints it advertises with the configured ones.
+func discoveryCheck(ctx context.Context, client *http.Client, info *social.OAuthInfo) models.TestCheck {
+	const name = "discovery"
+	if info.AuthUrl == "" {
+		return skipped(name, "No auth URL is configured")
+	}
+
+	issuers, err := candidateIssuers(info.AuthUrl)
+	if err != nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: fmt.Sprintf("Invalid auth URL: %v", err)}
+	}
+
+	var metadata *providerMetadata
+	var location string
+	for _, issuer := range issuers {
+		location = issuer + wellKnownPath
+		if metadata, err = fetchMetadata(ctx, client, location); err == nil {
+			break
+		}
+	}
+	if metadata == nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusWarning,
+			Message: "No OpenID configuration was found for the auth URL, the endpoints could not be compared with the ones of the provider",
+			Details: map[string]any{"tried": issuers, "error": err.Error()}}
+	}
+
+	details := map[string]any{
+		"location":              location,
+		"issuer":                metadata.Issuer,
+		"authorizationEndpoint": metadata.AuthorizationEndpoint,
+		"tokenEndpoint":         metadata.TokenEndpoint,
+		"userinfoEndpoint":      metadata.UserinfoEndpoint,
+		"jwksUri":               metadata.JWKSURI,
+	}
+
+	var warnings []string
+	if metadata.AuthorizationEndpoint != "" && !sameURL(metadata.AuthorizationEndpoint, info.AuthUrl) {
+		warnings = append(warnings, fmt.Sprintf("the auth URL differs from the authorization endpoint %s", metadata.AuthorizationEndpoint))
+	}
+	if metadata.TokenEndpoint != "" && !sameURL(metadata.TokenEndpoint, info.TokenUrl) {
+		warnings = append(warnings, fmt.Sprintf("the token URL differs from the token endpoint %s", metadata.TokenEndpoint))
+	}
+	if info.ApiUrl == "" && metadata.UserinfoEndpoint != "" {
+		warnings = append(warnings, fmt.Sprintf("no API URL is configured, the userinfo endpoint is %s", metadata.UserinfoEndpoint))
+	}
+	if len(metadata.ScopesSupported) > 0 {
+		var unsupported []string
+		for _, scope := range info.Scopes {
+			if !slices.Contains(metadata.ScopesSupported, scope) {
+				unsupported = append(unsupported, scope)
+			}
+		}
+		if len(unsupported) > 0 {
+			warnings = append(warnings, fmt.Sprintf("the scopes %s are not advertised by the provider", strings.Join(unsupported, ", ")))
+		}
+	}
+
+	if len(warnings) > 0 {
+		return models.TestCheck{Name: name, Status: models.TestStatusWarning,
+			Message: "The OpenID configuration was discovered, but " + strings.Join(warnings, "; "), Details: details}
+	}
+	return models.TestCheck{Name: name, Status: models.TestStatusPassed,
+		Message: "The OpenID configuration was discovered and matches the settings", Details: details}
+}
+
+// candidateIssuers returns the URLs obtained by removing the path segments of the auth URL one by one, as the
+// authorization endpoints of the providers are usually below their issuer.
+func candidateIssuers(authURL string) ([]string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an absolute URL", authURL)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	issuers := make([]string, 0, maxDiscoveryAttempts)
+	for i := len(segments) - 1; i >= 0 && len(issuers) < maxDiscoveryAttempts; i-- {
+		path := strings.Join(segments[:i], "/")
+		issuer := u.Scheme + "://" + u.Host
+		if path != "" {
+			issuer += "/" + path
+		}
+		issuers = append(issuers, issuer)
+	}
+	return issuers, nil
+}
+
+func fetchMetadata(ctx context.Context, client *http.Client, location string) (*providerMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", location, resp.StatusCode)
+	}
+	var metadata providerMetadata
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", location, err)
+	}
+	return &metadata, nil
+}
+
+func sameURL(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// clientCredentialsCheck requests a token with the client credentials grant, which authenticates the client
+// without a user. The providers which don't allow the grant to the client still report whether they
+// authenticated it, with the error code of their response.
+func clientCredentialsCheck(ctx context.Context, client *http.Client, info *social.OAuthInfo) models.TestCheck {
+	const name = "client_credentials"
+	if info.TokenUrl == "" {
+		return skipped(name, "No token URL is configured")
+	}
+	if info.ClientSecret == "" {
+		return skipped(name, "The client has no secret to validate")
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if info.AuthStyle == "InParams" {
+		form.Set("client_id", info.ClientId)
+		form.Set("client_secret", info.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, info.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: fmt.Sprintf("Invalid token URL: %v", err)}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if info.AuthStyle != "InParams" {
+		req.SetBasicAuth(url.QueryEscape(info.ClientId), url.QueryEscape(info.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: fmt.Sprintf("Failed to request the token URL: %v", err)}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		return models.TestCheck{Name: name, Status: models.TestStatusPassed, Message: "The client credentials were accepted"}
+	}
+
+	var tokenErr struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokenErr)
+	details := map[string]any{"status": resp.StatusCode, "error": tokenErr.Error, "errorDescription": tokenErr.Description}
+
+	switch tokenErr.Error {
+	case "invalid_client":
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: "The client credentials were rejected", Details: details}
+	case "unauthorized_client", "unsupported_grant_type", "invalid_grant", "invalid_scope":
+		return models.TestCheck{Name: name, Status: models.TestStatusWarning,
+			Message: "The client isn't allowed the client credentials grant, its credentials could not be fully validated", Details: details}
+	default:
+		return models.TestCheck{Name: name, Status: models.TestStatusWarning,
+			Message: fmt.Sprintf("The token URL returned status %d, the client credentials could not be validated", resp.StatusCode), Details: details}
+	}
+}
+
+// claimMappingCheck maps the sample claims with the tested settings, as the provider does on login.
+func (s *Service) claimMappingCheck(ctx context.Context, reloadable ssosettings.Reloadable, settings models.SSOSettings, sample models.TestSample) models.TestCheck {
+	const name = "claim_mapping"
+	if sample.Token == "" && len(sample.Claims) == 0 {
+		return skipped(name, "No sample token or claims were provided")
+	}
+	mapper, ok := reloadable.(ssosettings.ClaimsMapper)
+	if !ok {
+		return skipped(name, fmt.Sprintf("The claim mapping of the %s provider can't be simulated", settings.Provider))
+	}
+
+	claims, err := sampleClaims(sample)
+	if err != nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: fmt.Sprintf("Invalid sample: %v", err)}
+	}
+
+	userInfo, err := mapper.MapClaims(ctx, settings, claims)
+	if userInfo == nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: fmt.Sprintf("The claims could not be mapped: %v", err)}
+	}
+
+	details := map[string]any{
+		"id":       userInfo.Id,
+		"name":     userInfo.Name,
+		"email":    userInfo.Email,
+		"login":    userInfo.Login,
+		"role":     userInfo.Role,
+		"orgRoles": userInfo.OrgRoles,
+		"groups":   userInfo.Groups,
+	}
+	if userInfo.IsGrafanaAdmin != nil {
+		details["isGrafanaAdmin"] = *userInfo.IsGrafanaAdmin
+	}
+
+	if err != nil {
+		return models.TestCheck{Name: name, Status: models.TestStatusFailed, Message: fmt.Sprintf("The user would not be allowed to log in: %v", err), Details: details}
+	}
+	if userInfo.Email == "" || userInfo.Login == "" {
+		return models.TestCheck{Name: name, Status: models.TestStatusWarning, Message: "No email or login was found in the claims", Details: details}
+	}
+	return models.TestCheck{Name: name, Status: models.TestStatusPassed, Message: "The claims were mapped", Details: details}
+}
+
+// sampleClaims returns the JSON claims of the sample. The signature of the sample token is not verified, as it
+// is only used to test the mapping.
+func sampleClaims(sample models.TestSample) ([]byte, error) {
+	if sample.Token == "" {
+		return json.Marshal(sample.Claims)
+	}
+
+	parts := strings.Split(sample.Token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("the sample token is not a JWT")
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the claims of the sample token: %w", err)
+	}
+	if !json.Valid(claims) {
+		return nil, errors.New("the claims of the sample token are not valid JSON")
+	}
+	return claims, nil
+}
@@ -0,0 +1,179 @@
+package ssosettingsimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeClaimsMapper struct {
+	*ssosettingstests.MockReloadable
+	claims []byte
+}
+
+func (f *fakeClaimsMapper) MapClaims(ctx context.Context, settings models.SSOSettings, claims []byte) (*social.BasicUserInfo, error) {
+	f.claims = claims
+	return &social.BasicUserInfo{Id: "1", Email: "user@example.org", Login: "user", Role: org.RoleEditor}, nil
+}
+
+func newProviderServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/realms/grafana/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 server.URL + "/realms/grafana",
+			"authorization_endpoint": server.URL + "/realms/grafana/protocol/openid-connect/auth",
+			"token_endpoint":         server.URL + "/realms/grafana/protocol/openid-connect/token",
+			"userinfo_endpoint":      server.URL + "/realms/grafana/protocol/openid-connect/userinfo",
+			"scopes_supported":       []string{"openid", "email", "profile"},
+		})
+	})
+	mux.HandleFunc("/realms/grafana/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		if !ok || clientID != "client-id" || secret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token": "token", "token_type": "Bearer"}`))
+	})
+	return server
+}
+
+func TestService_Test(t *testing.T) {
+	server := newProviderServer(t)
+
+	settingsFor := func(secret string, scopes string) *models.SSOSettings {
+		return &models.SSOSettings{
+			Provider: social.GenericOAuthProviderName,
+			Settings: map[string]any{
+				"client_id":     "client-id",
+				"client_secret": secret,
+				"auth_url":      server.URL + "/realms/grafana/protocol/openid-connect/auth",
+				"token_url":     server.URL + "/realms/grafana/protocol/openid-connect/token",
+				"api_url":       server.URL + "/realms/grafana/protocol/openid-connect/userinfo",
+				"scopes":        scopes,
+			},
+		}
+	}
+	checksByName := func(report *models.TestReport) map[string]models.TestCheck {
+		checks := make(map[string]models.TestCheck, len(report.Checks))
+		for _, check := range report.Checks {
+			checks[check.Name] = check
+		}
+		return checks
+	}
+
+	t.Run("should report the checks of valid settings", func(t *testing.T) {
+		env := setupTestEnv(t, false, false, false, false)
+		env.service.httpClient = server.Client()
+		reloadable := &fakeClaimsMapper{MockReloadable: ssosettingstests.NewMockReloadable(t)}
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		env.reloadables[social.GenericOAuthProviderName] = reloadable
+
+		claims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub": "1", "email": "user@example.org"}`))
+		sample := models.TestSample{Token: "eyJhbGciOiJub25lIn0." + claims + ".sig"}
+
+		report, err := env.service.Test(context.Background(), settingsFor("client-secret", "openid email"), sample, &user.SignedInUser{})
+		require.NoError(t, err)
+		require.True(t, report.Success)
+
+		checks := checksByName(report)
+		assert.Equal(t, models.TestStatusPassed, checks["validation"].Status)
+		assert.Equal(t, models.TestStatusPassed, checks["discovery"].Status, checks["discovery"].Message)
+		assert.Equal(t, models.TestStatusPassed, checks["client_credentials"].Status)
+		assert.Equal(t, models.TestStatusPassed, checks["claim_mapping"].Status)
+		assert.Equal(t, "user@example.org", checks["claim_mapping"].Details["email"])
+		assert.JSONEq(t, `{"sub": "1", "email": "user@example.org"}`, string(reloadable.claims))
+	})
+
+	t.Run("should report the failed and suspicious checks", func(t *testing.T) {
+		env := setupTestEnv(t, false, false, false, false)
+		env.service.httpClient = server.Client()
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(ssosettings.ErrInvalidOAuthConfig("Auth URL is invalid"))
+		env.reloadables[social.GenericOAuthProviderName] = reloadable
+
+		sample := models.TestSample{Claims: map[string]any{"email": "user@example.org"}}
+		report, err := env.service.Test(context.Background(), settingsFor("wrong-secret", "openid groups"), sample, &user.SignedInUser{})
+		require.NoError(t, err)
+		require.False(t, report.Success)
+
+		checks := checksByName(report)
+		assert.Equal(t, models.TestCheck{Name: "validation", Status: models.TestStatusFailed, Message: "Auth URL is invalid"}, checks["validation"])
+		assert.Equal(t, models.TestStatusWarning, checks["discovery"].Status)
+		assert.Contains(t, checks["discovery"].Message, "the scopes groups are not advertised")
+		assert.Equal(t, models.TestStatusFailed, checks["client_credentials"].Status)
+		assert.Equal(t, models.TestStatusSkipped, checks["claim_mapping"].Status, "the provider doesn't map claims")
+	})
+
+	t.Run("should require the secret to test another token URL than the saved one", func(t *testing.T) {
+		env := setupTestEnv(t, false, false, false, false)
+		env.service.httpClient = server.Client()
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		env.reloadables[social.GenericOAuthProviderName] = reloadable
+		env.store.ExpectedError = ssosettings.ErrNotFound
+		env.fallbackStrategy.ExpectedConfigs = map[string]map[string]any{
+			social.GenericOAuthProviderName: {
+				"client_id":     "client-id",
+				"client_secret": "client-secret",
+				"token_url":     "https://idp.example.org/token",
+			},
+		}
+
+		_, err := env.service.Test(context.Background(), settingsFor(setting.RedactedPassword, "openid"), models.TestSample{}, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrBaseInvalidOAuthConfig)
+
+		// the secret is given with the new token URL
+		report, err := env.service.Test(context.Background(), settingsFor("client-secret", "openid"), models.TestSample{}, &user.SignedInUser{})
+		require.NoError(t, err)
+		assert.Equal(t, models.TestStatusPassed, checksByName(report)["client_credentials"].Status)
+	})
+
+	t.Run("should fail when the provider is not configurable", func(t *testing.T) {
+		env := setupTestEnv(t, false, false, false, false)
+
+		_, err := env.service.Test(context.Background(), &models.SSOSettings{Provider: "grafana_com"}, models.TestSample{}, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrNotConfigurable)
+	})
+}
+
+func TestCandidateIssuers(t *testing.T) {
+	issuers, err := candidateIssuers("https://idp.example.org/realms/grafana/protocol/openid-connect/auth")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://idp.example.org/realms/grafana/protocol/openid-connect",
+		"https://idp.example.org/realms/grafana/protocol",
+		"https://idp.example.org/realms/grafana",
+		"https://idp.example.org/realms",
+	}, issuers)
+
+	issuers, err = candidateIssuers("https://idp.example.org/authorize")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://idp.example.org"}, issuers)
+
+	_, err = candidateIssuers("/authorize")
+	require.Error(t, err)
+}
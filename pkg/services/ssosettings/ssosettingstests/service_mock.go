@@ -184,6 +184,36 @@ func (_m *MockService) Reload(ctx context.Context, provider string) {
 	_m.Called(ctx, provider)
 }
 
+// Test provides a mock function with given fields: ctx, settings, sample, requester
+func (_m *MockService) Test(ctx context.Context, settings *models.SSOSettings, sample models.TestSample, requester identity.Requester) (*models.TestReport, error) {
+	ret := _m.Called(ctx, settings, sample, requester)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Test")
+	}
+
+	var r0 *models.TestReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SSOSettings, models.TestSample, identity.Requester) (*models.TestReport, error)); ok {
+		return rf(ctx, settings, sample, requester)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SSOSettings, models.TestSample, identity.Requester) *models.TestReport); ok {
+		r0 = rf(ctx, settings, sample, requester)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.TestReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SSOSettings, models.TestSample, identity.Requester) error); ok {
+		r1 = rf(ctx, settings, sample, requester)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Upsert provides a mock function with given fields: ctx, settings, requester
 func (_m *MockService) Upsert(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) error {
 	ret := _m.Called(ctx, settings, requester)
@@ -61,7 +61,8 @@ func (s *sqlStore) DeleteByUser(ctx context.Context, userID int64) error {
 
 func (s *sqlStore) List(ctx context.Context, query *star.GetUserStarsQuery) (*star.GetUserStarsResult, error) {
 	userStars := make(map[int64]bool)
-	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+	// the stars are read by every dashboard search, which are served by the read replicas
+	err := s.db.WithReadOnlyDbSession(ctx, func(dbSession *db.Session) error {
 		var stars = make([]star.Star, 0)
 		err := dbSession.Where("user_id=?", query.UserID).Find(&stars)
 		for _, star := range stars {
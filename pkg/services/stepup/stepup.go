@@ -0,0 +1,203 @@
+package stepup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// hookPriority records the authentication once the session is created, with the other post login hooks.
+	hookPriority = 50
+	cachePrefix  = "step-up-session-"
+)
+
+var ErrStepUpRequired = errutil.Unauthorized("auth.step-up-required").MustTemplate(
+	"the operation requires a recent authentication",
+	errutil.WithPublic("This operation requires you to authenticate again"),
+)
+
+// Service requires the users to have authenticated recently, or with a multi-factor authentication asserted
+// by their IdP, before they perform the sensitive operations. The users authenticate again by logging in,
+// which records the time and the methods of the authentication of their new session.
+type Service struct {
+	settings  setting.StepUpSettings
+	appSubURL string
+	cache     remotecache.CacheStorage
+	log       log.Logger
+	now       func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, cache remotecache.CacheStorage, authnService authn.Service) *Service {
+	s := &Service{
+		settings:  cfg.StepUp,
+		appSubURL: cfg.AppSubURL,
+		cache:     cache,
+		log:       log.New("step-up"),
+		now:       time.Now,
+	}
+	if s.settings.Enabled {
+		authnService.RegisterPostLoginHook(s.recordLogin, hookPriority)
+	}
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s != nil && s.settings.Enabled
+}
+
+// authentication is what the service knows of the last authentication of a session.
+type authentication struct {
+	AuthAt int64 `json:"authAt"`
+	MFA    bool  `json:"mfa"`
+}
+
+func (s *Service) recordLogin(ctx context.Context, id *authn.Identity, r *authn.Request, err error) {
+	if err != nil || id == nil || id.SessionToken == nil {
+		return
+	}
+
+	value, err := json.Marshal(authentication{AuthAt: s.now().Unix(), MFA: s.hasMFA(id)})
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, cacheKey(id.SessionToken.Id), value, s.settings.MaxAge); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to record authentication of session", "id", id.GetID(), "error", err)
+	}
+}
+
+// hasMFA returns whether the ID token of the identity asserts a multi-factor authentication.
+func (s *Service) hasMFA(id *authn.Identity) bool {
+	if id.OAuthToken == nil {
+		return false
+	}
+	rawIDToken, ok := id.OAuthToken.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return false
+	}
+
+	// The ID token was verified by the OAuth client of the login.
+	token, err := jwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		AMR []string `json:"amr"`
+	}
+	if err := token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return false
+	}
+	for _, method := range claims.AMR {
+		if slices.Contains(s.settings.MFAMethods, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rejects the requests to the sensitive operations of the sessions which didn't authenticate recently.
+// The requests authenticated without a session, such as the ones of the service accounts, are not affected.
+func (s *Service) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.isSensitive(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			c := contexthandler.FromContext(r.Context())
+			if c == nil || c.UserToken == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ok, err := s.authenticatedRecently(r.Context(), c.UserToken.Id)
+			if err != nil {
+				c.Logger.Warn("Failed to get authentication of session", "error", err)
+			}
+			if ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			s.challenge(w)
+			c.WriteErr(ErrStepUpRequired.Build(errutil.TemplateData{
+				Public: map[string]any{
+					"stepUpRequired": true,
+					"maxAge":         int64(s.settings.MaxAge.Seconds()),
+					"mfaRequired":    s.settings.RequireMFA,
+					"loginURL":       s.loginURL(c.SignedInUser.GetAuthenticatedBy()),
+				},
+			}))
+		})
+	}
+}
+
+func (s *Service) isSensitive(r *http.Request) bool {
+	for _, op := range s.settings.Operations {
+		if op.Method != "*" && op.Method != r.Method {
+			continue
+		}
+		if ok, _ := path.Match(op.Pattern, strings.TrimSuffix(r.URL.Path, "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) authenticatedRecently(ctx context.Context, sessionID int64) (bool, error) {
+	value, err := s.cache.Get(ctx, cacheKey(sessionID))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var auth authentication
+	if err := json.Unmarshal(value, &auth); err != nil {
+		return false, err
+	}
+	if s.now().Sub(time.Unix(auth.AuthAt, 0)) > s.settings.MaxAge {
+		return false, nil
+	}
+	return auth.MFA || !s.settings.RequireMFA, nil
+}
+
+// challenge sets the step-up challenge of RFC 9470, for the clients which don't read the body of the response.
+func (s *Service) challenge(w http.ResponseWriter) {
+	value := fmt.Sprintf(`Bearer error="insufficient_user_authentication", error_description="A recent authentication is required", max_age=%d`,
+		int64(s.settings.MaxAge.Seconds()))
+	if s.settings.RequireMFA {
+		value += `, acr_values="mfa"`
+	}
+	w.Header().Set("WWW-Authenticate", value)
+}
+
+// loginURL returns where the user logs in again, with the same method as the current session.
+func (s *Service) loginURL(authModule string) string {
+	if provider, ok := strings.CutPrefix(authModule, "oauth_"); ok {
+		return s.appSubURL + "/login/" + provider
+	}
+	return s.appSubURL + "/login"
+}
+
+func cacheKey(sessionID int64) string {
+	return fmt.Sprintf("%s%d", cachePrefix, sessionID)
+}
@@ -0,0 +1,117 @@
+package stepup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models/usertoken"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func newTestService(requireMFA bool) *Service {
+	return &Service{
+		settings: setting.StepUpSettings{
+			Enabled:    true,
+			MaxAge:     5 * time.Minute,
+			RequireMFA: requireMFA,
+			MFAMethods: []string{"mfa", "otp"},
+			Operations: []setting.StepUpOperation{
+				{Method: http.MethodDelete, Pattern: "/api/orgs/*"},
+				{Method: "*", Pattern: "/api/v1/sso-settings/*"},
+			},
+		},
+		cache: remotecache.NewFakeCacheStorage(),
+		log:   log.NewNopLogger(),
+		now:   time.Now,
+	}
+}
+
+func idToken(t *testing.T, amr ...string) *oauth2.Token {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("secret")}, nil)
+	require.NoError(t, err)
+	raw, err := jwt.Signed(signer).Claims(map[string]any{"sub": "1", "amr": amr}).CompactSerialize()
+	require.NoError(t, err)
+	return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]any{"id_token": raw})
+}
+
+func TestMiddleware(t *testing.T) {
+	serve := func(s *Service, method, path string, sessionID int64) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		c := &contextmodel.ReqContext{
+			Context:      &web.Context{Req: r, Resp: web.NewResponseWriter(method, rec)},
+			SignedInUser: &user.SignedInUser{UserID: 1, AuthenticatedBy: "oauth_generic_oauth"},
+			Logger:       log.NewNopLogger(),
+		}
+		if sessionID != 0 {
+			c.UserToken = &usertoken.UserToken{Id: sessionID}
+		}
+		r = r.WithContext(context.WithValue(r.Context(), ctxkey.Key{}, c))
+		c.Req = r
+
+		s.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})).ServeHTTP(c.Resp, r)
+		return rec
+	}
+	login := func(s *Service, sessionID int64, token *oauth2.Token) {
+		s.recordLogin(context.Background(), &authn.Identity{ID: "1", SessionToken: &usertoken.UserToken{Id: sessionID}, OAuthToken: token}, &authn.Request{}, nil)
+	}
+
+	t.Run("should challenge the sessions which didn't authenticate recently", func(t *testing.T) {
+		s := newTestService(false)
+
+		rec := serve(s, http.MethodDelete, "/api/orgs/2", 1)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="insufficient_user_authentication"`)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "auth.step-up-required", body["messageId"])
+		assert.Equal(t, map[string]any{"stepUpRequired": true, "maxAge": float64(300), "mfaRequired": false, "loginURL": "/login/generic_oauth"}, body["extra"])
+
+		login(s, 1, nil)
+		assert.Equal(t, http.StatusNoContent, serve(s, http.MethodDelete, "/api/orgs/2", 1).Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(s, http.MethodDelete, "/api/orgs/2", 2).Code, "the authentication is recorded per session")
+
+		s.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+		assert.Equal(t, http.StatusUnauthorized, serve(s, http.MethodDelete, "/api/orgs/2", 1).Code, "the authentication expired")
+	})
+
+	t.Run("should only challenge the sensitive operations of the sessions", func(t *testing.T) {
+		s := newTestService(false)
+
+		assert.Equal(t, http.StatusNoContent, serve(s, http.MethodGet, "/api/orgs/2", 1).Code)
+		assert.Equal(t, http.StatusNoContent, serve(s, http.MethodDelete, "/api/orgs/2/users/3", 1).Code)
+		assert.Equal(t, http.StatusNoContent, serve(s, http.MethodDelete, "/api/orgs/2", 0).Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(s, http.MethodPut, "/api/v1/sso-settings/github", 1).Code)
+	})
+
+	t.Run("should require the MFA asserted by the IdP", func(t *testing.T) {
+		s := newTestService(true)
+
+		login(s, 1, idToken(t, "pwd"))
+		assert.Equal(t, http.StatusUnauthorized, serve(s, http.MethodDelete, "/api/orgs/2", 1).Code)
+
+		login(s, 1, idToken(t, "pwd", "otp"))
+		assert.Equal(t, http.StatusNoContent, serve(s, http.MethodDelete, "/api/orgs/2", 1).Code)
+	})
+}
@@ -0,0 +1,134 @@
+package templatevars
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The variable formats supported by the frontend, see https://grafana.com/docs/grafana/latest/dashboards/variables/variable-syntax/
+const (
+	FormatCSV           = "csv"
+	FormatDoubleQuote   = "doublequote"
+	FormatGlob          = "glob"
+	FormatJSON          = "json"
+	FormatLucene        = "lucene"
+	FormatPercentEncode = "percentencode"
+	FormatPipe          = "pipe"
+	FormatQueryParam    = "queryparam"
+	FormatRaw           = "raw"
+	FormatRegex         = "regex"
+	FormatSingleQuote   = "singlequote"
+	FormatSQLString     = "sqlstring"
+	FormatText          = "text"
+)
+
+var (
+	regexSpecialChars  = regexp.MustCompile(`[\\^$*+?.()|\[\]{}/]`)
+	luceneSpecialChars = regexp.MustCompile(`[!*+\-=<>\s&|()\[\]{}^~?:\\/"]`)
+)
+
+// datasourceFormats are the formats data sources use for multi-value variables without an explicit format.
+var datasourceFormats = map[string]string{
+	"prometheus":                    FormatRegex,
+	"loki":                          FormatRegex,
+	"elasticsearch":                 FormatLucene,
+	"mysql":                         FormatSQLString,
+	"mssql":                         FormatSQLString,
+	"postgres":                      FormatSQLString,
+	"grafana-postgresql-datasource": FormatSQLString,
+}
+
+// defaultFormat formats a value without an explicit format. Single values are used as is, multiple
+// values use the format of the data source type or the glob format.
+func defaultFormat(datasourceType string, value Values) string {
+	if !value.Multi {
+		return value.single()
+	}
+	format, ok := datasourceFormats[datasourceType]
+	if !ok {
+		format = FormatGlob
+	}
+	return formatValue("", format, value, value)
+}
+
+// formatValue formats a value like the format registry of the frontend. Unknown formats fall back to glob.
+func formatValue(name, format string, value, text Values) string {
+	// some formats have arguments after a ':' that are not supported here
+	format, _, _ = strings.Cut(format, ":")
+
+	switch format {
+	case FormatRaw:
+		return value.single()
+	case FormatCSV:
+		return strings.Join(value.Values, ",")
+	case FormatPipe:
+		return strings.Join(value.Values, "|")
+	case FormatRegex:
+		escaped := mapValues(value.Values, func(v string) string { return regexSpecialChars.ReplaceAllString(v, `\$0`) })
+		if !value.Multi || len(escaped) == 1 {
+			return strings.Join(escaped, "")
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case FormatLucene:
+		escaped := mapValues(value.Values, func(v string) string { return luceneSpecialChars.ReplaceAllString(v, `\$0`) })
+		if !value.Multi {
+			return strings.Join(escaped, "")
+		}
+		if len(escaped) == 0 {
+			return "__empty__"
+		}
+		return "(" + strings.Join(mapValues(escaped, func(v string) string { return `"` + v + `"` }), " OR ") + ")"
+	case FormatJSON:
+		var b []byte
+		if value.Multi {
+			b, _ = json.Marshal(value.Values)
+		} else {
+			b, _ = json.Marshal(value.single())
+		}
+		return string(b)
+	case FormatPercentEncode:
+		if value.Multi {
+			return percentEncode("{" + strings.Join(value.Values, ",") + "}")
+		}
+		return percentEncode(value.single())
+	case FormatSingleQuote:
+		return strings.Join(mapValues(value.Values, func(v string) string { return `'` + strings.ReplaceAll(v, `'`, `\'`) + `'` }), ",")
+	case FormatDoubleQuote:
+		return strings.Join(mapValues(value.Values, func(v string) string { return `"` + strings.ReplaceAll(v, `"`, `\"`) + `"` }), ",")
+	case FormatSQLString:
+		return strings.Join(mapValues(value.Values, func(v string) string { return `'` + strings.ReplaceAll(v, `'`, `''`) + `'` }), ",")
+	case FormatText:
+		return strings.Join(text.Values, " + ")
+	case FormatQueryParam:
+		return strings.Join(mapValues(value.Values, func(v string) string { return "var-" + percentEncode(name) + "=" + percentEncode(v) }), "&")
+	default:
+		if value.Multi && len(value.Values) > 1 {
+			return "{" + strings.Join(value.Values, ",") + "}"
+		}
+		return value.single()
+	}
+}
+
+func mapValues(values []string, fn func(string) string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = fn(v)
+	}
+	return result
+}
+
+// percentEncode escapes everything but the unreserved characters of RFC 3986, like encodeURIComponentStrict in the frontend.
+func percentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,174 @@
+// Package templatevars resolves dashboard template variables in queries on the server, following the
+// interpolation rules of the frontend, so that dashboard queries can be sent verbatim to /api/ds/query.
+package templatevars
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AllValue is the value of a variable with the All option selected.
+const AllValue = "$__all"
+
+const allText = "All"
+
+// variableRegex matches $var, [[var]], [[var:format]], ${var}, ${var.fieldPath} and ${var:format}.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+// Variables are the values of the template variables of a dashboard by variable name.
+type Variables map[string]Variable
+
+// Variable is the current value of a template variable.
+type Variable struct {
+	// Value is a string, or a list of strings for multi-value variables.
+	Value Values `json:"value"`
+	// Text is the display text of the value, used by the text format. Defaults to the value.
+	Text Values `json:"text,omitempty"`
+	// AllValue is the custom value of the All option. It is used as is, without formatting.
+	AllValue string `json:"allValue,omitempty"`
+	// Options are the values the All option expands to if there is no custom all value.
+	Options []string `json:"options,omitempty"`
+}
+
+// Values is a single string or a list of strings.
+type Values struct {
+	Values []string
+	// Multi is true if the values were given as a list.
+	Multi bool
+}
+
+func (v *Values) UnmarshalJSON(b []byte) error {
+	var single any
+	if err := json.Unmarshal(b, &single); err != nil {
+		return err
+	}
+	switch value := single.(type) {
+	case nil:
+		*v = Values{}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, toString(item))
+		}
+		*v = Values{Values: values, Multi: true}
+	case map[string]any:
+		return fmt.Errorf("variable value must be a string or a list of strings")
+	default:
+		*v = Values{Values: []string{toString(value)}}
+	}
+	return nil
+}
+
+func (v Values) MarshalJSON() ([]byte, error) {
+	if v.Multi {
+		return json.Marshal(v.Values)
+	}
+	return json.Marshal(v.single())
+}
+
+func (v Values) single() string {
+	return strings.Join(v.Values, ",")
+}
+
+func (v Values) isAll() bool {
+	return len(v.Values) > 0 && v.Values[0] == AllValue
+}
+
+func toString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// Interpolate replaces the variables in s. Variables without an explicit format use the default format
+// of the data source type. Unknown variables, like the built-in $__interval, are left unchanged.
+func (vars Variables) Interpolate(s string, datasourceType string) string {
+	if len(vars) == 0 || !strings.ContainsRune(s, '$') && !strings.Contains(s, "[[") {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range variableRegex.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(s[last:m[0]])
+		last = m[1]
+
+		name, fieldPath, format := submatch(s, m, 1), "", ""
+		switch {
+		case m[4] >= 0: // [[var:format]]
+			name, format = submatch(s, m, 2), submatch(s, m, 3)
+		case m[8] >= 0: // ${var.fieldPath:format}
+			name, fieldPath, format = submatch(s, m, 4), submatch(s, m, 5), submatch(s, m, 6)
+		}
+
+		variable, ok := vars[name]
+		if !ok || fieldPath != "" {
+			b.WriteString(s[m[0]:m[1]])
+			continue
+		}
+		b.WriteString(variable.format(name, format, datasourceType))
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// InterpolateJSON replaces the variables in every string of a decoded JSON value.
+func (vars Variables) InterpolateJSON(value any, datasourceType string) any {
+	switch v := value.(type) {
+	case string:
+		return vars.Interpolate(v, datasourceType)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = vars.InterpolateJSON(item, datasourceType)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = vars.InterpolateJSON(item, datasourceType)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func submatch(s string, m []int, group int) string {
+	if m[2*group] < 0 {
+		return ""
+	}
+	return s[m[2*group]:m[2*group+1]]
+}
+
+func (v Variable) format(name, format, datasourceType string) string {
+	value, text := v.Value, v.Text
+	if len(text.Values) == 0 {
+		text = value
+	}
+
+	if value.isAll() {
+		text = Values{Values: []string{allText}}
+		if v.AllValue != "" {
+			// custom all values are not formatted, like in the frontend
+			if format != FormatText && format != FormatPercentEncode {
+				return v.AllValue
+			}
+			value = Values{Values: []string{v.AllValue}}
+		} else {
+			value = Values{Values: v.Options, Multi: true}
+		}
+	}
+
+	if format == "" {
+		return defaultFormat(datasourceType, value)
+	}
+	return formatValue(name, format, value, text)
+}
@@ -0,0 +1,82 @@
+package templatevars
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := Variables{
+		"job":      {Value: Values{Values: []string{"api", "web.1"}, Multi: true}},
+		"instance": {Value: Values{Values: []string{"host:9090"}}, Text: Values{Values: []string{"Host"}}},
+		"env":      {Value: Values{Values: []string{AllValue}, Multi: true}, AllValue: ".*"},
+		"region":   {Value: Values{Values: []string{AllValue}}, Options: []string{"eu", "us"}},
+		"name":     {Value: Values{Values: []string{"o'brien", "smith"}, Multi: true}},
+	}
+
+	for _, tc := range []struct {
+		input          string
+		datasourceType string
+		expected       string
+	}{
+		{input: `up{instance="$instance"}`, expected: `up{instance="host:9090"}`},
+		{input: `up{instance="${instance}"}`, expected: `up{instance="host:9090"}`},
+		{input: `up{instance="[[instance]]"}`, expected: `up{instance="host:9090"}`},
+		{input: `$job`, expected: `{api,web.1}`},
+		{input: `$job`, datasourceType: "prometheus", expected: `(api|web\.1)`},
+		{input: `$name`, datasourceType: "mysql", expected: `'o''brien','smith'`},
+		{input: `${job:csv}`, expected: `api,web.1`},
+		{input: `[[job:pipe]]`, expected: `api|web.1`},
+		{input: `${job:regex}`, expected: `(api|web\.1)`},
+		{input: `${job:json}`, expected: `["api","web.1"]`},
+		{input: `${job:lucene}`, expected: `("api" OR "web.1")`},
+		{input: `${job:singlequote}`, expected: `'api','web.1'`},
+		{input: `${job:doublequote}`, expected: `"api","web.1"`},
+		{input: `${job:percentencode}`, expected: `%7Bapi%2Cweb.1%7D`},
+		{input: `${job:queryparam}`, expected: `var-job=api&var-job=web.1`},
+		{input: `${job:unknown}`, expected: `{api,web.1}`},
+		{input: `${instance:text}`, expected: `Host`},
+		{input: `${instance:lucene}`, expected: `host\:9090`},
+		{input: `${env:regex}`, datasourceType: "prometheus", expected: `.*`},
+		{input: `${env:text}`, expected: `All`},
+		{input: `$region`, datasourceType: "prometheus", expected: `(eu|us)`},
+		{input: `rate(up[$__interval]) $missing ${instance.field}`, expected: `rate(up[$__interval]) $missing ${instance.field}`},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			require.Equal(t, tc.expected, vars.Interpolate(tc.input, tc.datasourceType))
+		})
+	}
+}
+
+func TestInterpolateJSON(t *testing.T) {
+	vars := Variables{"ds": {Value: Values{Values: []string{"loki-uid"}}}, "app": {Value: Values{Values: []string{"a", "b"}, Multi: true}}}
+
+	query := map[string]any{
+		"datasource": map[string]any{"uid": "${ds}"},
+		"expr":       `{app=~"$app"}`,
+		"maxLines":   json.Number("100"),
+		"tags":       []any{"$ds", true},
+	}
+	require.Equal(t, map[string]any{
+		"datasource": map[string]any{"uid": "loki-uid"},
+		"expr":       `{app=~"(a|b)"}`,
+		"maxLines":   json.Number("100"),
+		"tags":       []any{"loki-uid", true},
+	}, vars.InterpolateJSON(query, "loki"))
+}
+
+func TestVariablesUnmarshalJSON(t *testing.T) {
+	var vars Variables
+	err := json.Unmarshal([]byte(`{"single": {"value": "a"}, "multi": {"value": ["a", "b"], "text": ["A", "B"]}, "number": {"value": 5}}`), &vars)
+	require.NoError(t, err)
+	require.Equal(t, Variables{
+		"single": {Value: Values{Values: []string{"a"}}},
+		"multi":  {Value: Values{Values: []string{"a", "b"}, Multi: true}, Text: Values{Values: []string{"A", "B"}, Multi: true}},
+		"number": {Value: Values{Values: []string{"5"}}},
+	}, vars)
+
+	err = json.Unmarshal([]byte(`{"invalid": {"value": {"a": "b"}}}`), &vars)
+	require.Error(t, err)
+}
@@ -389,7 +389,7 @@ func (ss *sqlStore) Count(ctx context.Context) (int64, error) {
 	}
 
 	r := result{}
-	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+	err := ss.db.WithReadOnlyDbSession(ctx, func(sess *db.Session) error {
 		rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s WHERE is_service_account=%s", ss.db.GetDialect().Quote("user"), ss.db.GetDialect().BooleanStr(false))
 		if _, err := sess.SQL(rawSQL).Get(&r); err != nil {
 			return err
@@ -459,7 +459,7 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 	result := user.SearchUserQueryResult{
 		Users: make([]*user.UserSearchHitDTO, 0),
 	}
-	err := ss.db.WithDbSession(ctx, func(dbSess *db.Session) error {
+	err := ss.db.WithReadOnlyDbSession(ctx, func(dbSess *db.Session) error {
 		queryWithWildcards := "%" + query.Query + "%"
 
 		whereConditions := make([]string, 0)
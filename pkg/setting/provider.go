@@ -1,8 +1,11 @@
 package setting
 
 import (
+	"context"
 	"errors"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"gopkg.in/ini.v1"
@@ -52,6 +55,16 @@ type Provider interface {
 	// RegisterReloadHandler registers a handler for validation and reload
 	// of configuration updates tied to a specific section
 	RegisterReloadHandler(section string, handler ReloadHandler)
+	// Reload reads the configuration again and reloads the changed
+	// sections which have a reload handler. It returns a report of
+	// the changed settings.
+	Reload(ctx context.Context) (*ReloadReport, error)
+	// SmtpSettings returns the SMTP settings with the reloaded
+	// changes. Cfg.Smtp keeps the settings read at startup.
+	SmtpSettings() SmtpSettings
+	// QuotaSettings returns the default quotas with the reloaded
+	// changes. Cfg.Quota keeps the quotas read at startup.
+	QuotaSettings() QuotaSettings
 }
 
 // Section is a settings section copy
@@ -60,6 +73,9 @@ type Section interface {
 	// KeyValue returns a key-value
 	// abstraction for the given key.
 	KeyValue(key string) KeyValue
+	// Keys returns the names of the keys
+	// of the section.
+	Keys() []string
 }
 
 // KeyValue represents a settings key-value
@@ -92,6 +108,28 @@ type ReloadHandler interface {
 	ValidateSection(section Section) error
 }
 
+// PartialReloadHandler is a ReloadHandler which reloads some of
+// the keys of a section, the changes of the other keys require a
+// restart.
+type PartialReloadHandler interface {
+	ReloadHandler
+
+	// IsReloadable returns true if the changes of a key are reloaded.
+	IsReloadable(section, key string) bool
+}
+
+// ReloadHandlerFunc is a ReloadHandler which doesn't validate
+// the sections it reloads.
+type ReloadHandlerFunc func(section Section) error
+
+func (f ReloadHandlerFunc) ReloadSection(section Section) error {
+	return f(section)
+}
+
+func (f ReloadHandlerFunc) ValidateSection(Section) error {
+	return nil
+}
+
 type SettingsBag map[string]map[string]string
 type SettingsRemovals map[string][]string
 
@@ -104,19 +142,60 @@ const (
 )
 
 func ProvideProvider(cfg *Cfg) *OSSImpl {
-	return &OSSImpl{
+	o := &OSSImpl{
 		Cfg: cfg,
 	}
+	o.registerSettingsReloadHandlers()
+	return o
 }
 
 type OSSImpl struct {
 	Cfg *Cfg
+
+	// reloadMtx serializes the reloads
+	reloadMtx sync.Mutex
+	// reloading is the copy of the configuration whose section is being reloaded, it's guarded by reloadMtx.
+	reloading *ini.File
+	// read is the configuration as it was last read, with the reloaded changes. The configuration read again is
+	// compared to it, so that the defaults set in Raw while parsing the settings are not changes. It's guarded by
+	// reloadMtx.
+	read       *ini.File
+	handlersMu sync.RWMutex
+	handlers   map[string][]ReloadHandler
+
+	// The configuration and the settings with the reloaded changes, nil until they are reloaded. The reloads
+	// replace them with copies, so that the settings read at startup in Cfg are never changed.
+	raw   atomic.Pointer[ini.File]
+	smtp  atomic.Pointer[SmtpSettings]
+	quota atomic.Pointer[QuotaSettings]
+}
+
+// currentRaw returns the configuration with the reloaded changes.
+func (o *OSSImpl) currentRaw() *ini.File {
+	if raw := o.raw.Load(); raw != nil {
+		return raw
+	}
+	return o.Cfg.Raw
+}
+
+func (o *OSSImpl) SmtpSettings() SmtpSettings {
+	if smtp := o.smtp.Load(); smtp != nil {
+		return *smtp
+	}
+	return o.Cfg.Smtp
+}
+
+func (o *OSSImpl) QuotaSettings() QuotaSettings {
+	if quota := o.quota.Load(); quota != nil {
+		return *quota
+	}
+	return o.Cfg.Quota
 }
 
 func (o *OSSImpl) Current() SettingsBag {
 	settingsCopy := make(SettingsBag)
 
-	for _, section := range o.Cfg.Raw.Sections() {
+	for _, section := range o.currentRaw().Sections() {
 		settingsCopy[section.Name()] = make(map[string]string)
 		for _, key := range section.Keys() {
 			settingsCopy[section.Name()][key.Name()] = RedactedValue(EnvKey(section.Name(), key.Name()), key.Value())
@@ -130,7 +209,7 @@ func (o *OSSImpl) CurrentVerbose() VerboseSettingsBag {
 	return nil
 }
 
-func (OSSImpl) Update(SettingsBag, SettingsRemovals) error {
+func (o *OSSImpl) Update(SettingsBag, SettingsRemovals) error {
 	return errors.New("oss settings provider do not have support for settings updates")
 }
 
@@ -139,10 +218,23 @@ func (o *OSSImpl) KeyValue(section, key string) KeyValue {
 }
 
 func (o *OSSImpl) Section(section string) Section {
-	return &sectionImpl{section: o.Cfg.Raw.Section(section)}
+	return &sectionImpl{section: o.currentRaw().Section(section)}
 }
 
-func (*OSSImpl) RegisterReloadHandler(string, ReloadHandler) {}
+func (o *OSSImpl) RegisterReloadHandler(section string, handler ReloadHandler) {
+	o.handlersMu.Lock()
+	defer o.handlersMu.Unlock()
+	if o.handlers == nil {
+		o.handlers = make(map[string][]ReloadHandler)
+	}
+	o.handlers[section] = append(o.handlers[section], handler)
+}
+
+func (o *OSSImpl) reloadHandlers(section string) []ReloadHandler {
+	o.handlersMu.RLock()
+	defer o.handlersMu.RUnlock()
+	return o.handlers[section]
+}
 
 type keyValImpl struct {
 	key *ini.Key
@@ -175,3 +267,7 @@ type sectionImpl struct {
 func (s *sectionImpl) KeyValue(key string) KeyValue {
 	return &keyValImpl{s.section.Key(key)}
 }
+
+func (s *sectionImpl) Keys() []string {
+	return s.section.KeyStrings()
+}
@@ -0,0 +1,235 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gopkg.in/ini.v1"
+)
+
+type ChangeStatus string
+
+const (
+	// ChangeApplied is the status of the changes reloaded at runtime.
+	ChangeApplied ChangeStatus = "applied"
+	// ChangeRestartRequired is the status of the changes which can't be reloaded, they are applied on the next
+	// restart.
+	ChangeRestartRequired ChangeStatus = "restart_required"
+	// ChangeFailed is the status of the changes rejected by the validation of their section, or whose reload
+	// failed.
+	ChangeFailed ChangeStatus = "failed"
+)
+
+// SettingChange is a setting whose value changed in the configuration. The values are redacted like in the
+// settings API.
+type SettingChange struct {
+	Section  string       `json:"section"`
+	Key      string       `json:"key"`
+	OldValue string       `json:"oldValue"`
+	NewValue string       `json:"newValue"`
+	Status   ChangeStatus `json:"status"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// ReloadReport lists the settings which changed since the configuration was loaded or last reloaded.
+type ReloadReport struct {
+	Changes []SettingChange `json:"changes"`
+}
+
+// Count returns the number of changes with a status.
+func (r *ReloadReport) Count(status ChangeStatus) int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Reload reads the configuration again and reloads the changed sections which have a reload handler. The changes
+// of a section are applied when all its handlers validated it, then the handlers reload it. The changes of the
+// sections without a handler, and of the keys a PartialReloadHandler can't reload, are left for the next restart.
+func (o *OSSImpl) Reload(ctx context.Context) (*ReloadReport, error) {
+	o.reloadMtx.Lock()
+	defer o.reloadMtx.Unlock()
+
+	next, err := o.Cfg.ReadConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration: %w", err)
+	}
+
+	if o.read == nil {
+		o.read = o.Cfg.readRaw
+		if o.read == nil {
+			o.read = o.currentRaw()
+		}
+		o.read = cloneFile(o.read)
+	}
+
+	report := &ReloadReport{Changes: make([]SettingChange, 0)}
+	for _, name := range sectionNames(o.read, next) {
+		changes := diffSection(o.read, next, name)
+		if len(changes) == 0 {
+			continue
+		}
+		o.reloadSection(name, next, changes)
+		report.Changes = append(report.Changes, changes...)
+	}
+
+	for i, change := range report.Changes {
+		envKey := EnvKey(change.Section, change.Key)
+		report.Changes[i].OldValue = RedactedValue(envKey, change.OldValue)
+		report.Changes[i].NewValue = RedactedValue(envKey, change.NewValue)
+	}
+	o.Cfg.Logger.Info("Reloaded configuration", "applied", report.Count(ChangeApplied),
+		"restartRequired", report.Count(ChangeRestartRequired), "failed", report.Count(ChangeFailed))
+	return report, nil
+}
+
+// reloadSection applies the changes of a section to a copy of the configuration, reloads it, and sets the status of
+// the changes. The copy replaces the configuration once the handlers reloaded the section.
+func (o *OSSImpl) reloadSection(name string, next *ini.File, changes []SettingChange) {
+	setStatus := func(status ChangeStatus, err error) {
+		for i := range changes {
+			if changes[i].Status == "" || changes[i].Status == ChangeApplied {
+				changes[i].Status = status
+				if err != nil {
+					changes[i].Error = err.Error()
+				}
+			}
+		}
+	}
+
+	handlers := o.reloadHandlers(name)
+	if len(handlers) == 0 {
+		setStatus(ChangeRestartRequired, nil)
+		return
+	}
+
+	nextSection := next.Section(name)
+	for _, handler := range handlers {
+		if err := handler.ValidateSection(&sectionImpl{section: nextSection}); err != nil {
+			o.Cfg.Logger.Warn("Configuration section is invalid, it is not reloaded", "section", name, "error", err)
+			setStatus(ChangeFailed, err)
+			return
+		}
+	}
+
+	raw := cloneFile(o.currentRaw())
+	section, readSection := raw.Section(name), o.read.Section(name)
+	applied := 0
+	for i, change := range changes {
+		if !isReloadable(handlers, name, change.Key) {
+			changes[i].Status = ChangeRestartRequired
+			continue
+		}
+		if nextSection.HasKey(change.Key) {
+			setKey(section, change.Key, change.NewValue)
+			setKey(readSection, change.Key, change.NewValue)
+		} else {
+			section.DeleteKey(change.Key)
+			readSection.DeleteKey(change.Key)
+		}
+		changes[i].Status = ChangeApplied
+		applied++
+	}
+	if applied == 0 {
+		return
+	}
+	o.reloading = raw
+	defer func() {
+		o.reloading = nil
+		o.raw.Store(raw)
+	}()
+
+	for _, handler := range handlers {
+		if err := handler.ReloadSection(&sectionImpl{section: section}); err != nil {
+			o.Cfg.Logger.Error("Failed to reload configuration section", "section", name, "error", err)
+			setStatus(ChangeFailed, err)
+			return
+		}
+	}
+}
+
+// cloneFile returns a copy of the sections and the keys of a configuration.
+func cloneFile(f *ini.File) *ini.File {
+	clone := ini.Empty()
+	for _, section := range f.Sections() {
+		cloneSection := clone.Section(section.Name())
+		for _, key := range section.Keys() {
+			setKey(cloneSection, key.Name(), key.Value())
+		}
+	}
+	return clone
+}
+
+// setKey sets the value of a key of the section. Unlike Section.Key, it doesn't change the key of a parent section
+// when the section is a child section, like auth.ldap.team_sync of auth.ldap.
+func setKey(section *ini.Section, name, value string) {
+	if _, err := section.NewKey(name, value); err != nil {
+		section.Key(name).SetValue(value)
+	}
+}
+
+func isReloadable(handlers []ReloadHandler, section, key string) bool {
+	for _, handler := range handlers {
+		if partial, ok := handler.(PartialReloadHandler); ok && !partial.IsReloadable(section, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// sectionNames returns the names of the sections of the files, sorted.
+func sectionNames(files ...*ini.File) []string {
+	names := make(map[string]struct{})
+	for _, f := range files {
+		for _, name := range f.SectionStrings() {
+			names[name] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// diffSection returns the keys of a section whose values differ between the files, sorted. A missing key is the
+// same as an empty one.
+func diffSection(current, next *ini.File, name string) []SettingChange {
+	currentValues, nextValues := sectionValues(current, name), sectionValues(next, name)
+	keys := make([]string, 0, len(nextValues))
+	for key := range currentValues {
+		keys = append(keys, key)
+	}
+	for key := range nextValues {
+		if _, ok := currentValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]SettingChange, 0)
+	for _, key := range keys {
+		if currentValues[key] != nextValues[key] {
+			changes = append(changes, SettingChange{Section: name, Key: key, OldValue: currentValues[key], NewValue: nextValues[key]})
+		}
+	}
+	return changes
+}
+
+func sectionValues(f *ini.File, name string) map[string]string {
+	values := make(map[string]string)
+	section, err := f.GetSection(name)
+	if err != nil {
+		return values
+	}
+	for _, key := range section.Keys() {
+		values[key.Name()] = key.Value()
+	}
+	return values
+}
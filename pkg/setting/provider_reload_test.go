@@ -0,0 +1,131 @@
+package setting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "custom.ini")
+	writeConfig := func(t *testing.T, config string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+	}
+	writeConfig(t, `
+[server]
+http_port = 3000
+
+[quota]
+enabled = true
+org_dashboard = 10
+
+[smtp]
+from_address = admin@example.com
+`)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.Load(CommandLineArgs{HomePath: "../../", Config: configFile}))
+	provider := ProvideProvider(cfg)
+
+	t.Run("unchanged configuration", func(t *testing.T) {
+		report, err := provider.Reload(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, report.Changes)
+	})
+
+	t.Run("reloadable changes are applied", func(t *testing.T) {
+		var reloaded []string
+		provider.RegisterReloadHandler("smtp", ReloadHandlerFunc(func(section Section) error {
+			reloaded = append(reloaded, section.KeyValue("from_address").Value())
+			return nil
+		}))
+		writeConfig(t, `
+[server]
+http_port = 4000
+
+[quota]
+enabled = false
+org_dashboard = 20
+
+[smtp]
+from_address = grafana@example.com
+password = secret
+`)
+
+		report, err := provider.Reload(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []SettingChange{
+			{Section: "quota", Key: "enabled", OldValue: "true", NewValue: "false", Status: ChangeRestartRequired},
+			{Section: "quota", Key: "org_dashboard", OldValue: "10", NewValue: "20", Status: ChangeApplied},
+			{Section: "server", Key: "http_port", OldValue: "3000", NewValue: "4000", Status: ChangeRestartRequired},
+			{Section: "smtp", Key: "from_address", OldValue: "admin@example.com", NewValue: "grafana@example.com", Status: ChangeApplied},
+			{Section: "smtp", Key: "password", OldValue: "", NewValue: RedactedPassword, Status: ChangeApplied},
+		}, report.Changes)
+
+		require.Equal(t, int64(20), provider.QuotaSettings().Org.Dashboard)
+		require.True(t, provider.QuotaSettings().Enabled)
+		require.Equal(t, "grafana@example.com", provider.SmtpSettings().FromAddress)
+		require.Equal(t, "secret", provider.SmtpSettings().Password)
+		require.Equal(t, []string{"grafana@example.com"}, reloaded)
+		require.Equal(t, "grafana@example.com", provider.KeyValue("smtp", "from_address").Value())
+		require.Equal(t, "3000", provider.KeyValue("server", "http_port").Value(), "the changes requiring a restart are not applied")
+
+		require.Equal(t, int64(10), cfg.Quota.Org.Dashboard, "the settings read at startup are not changed")
+		require.Equal(t, "admin@example.com", cfg.Smtp.FromAddress)
+		require.Equal(t, "admin@example.com", cfg.Raw.Section("smtp").Key("from_address").String())
+	})
+
+	t.Run("invalid section is not reloaded", func(t *testing.T) {
+		writeConfig(t, `
+[server]
+http_port = 4000
+
+[quota]
+enabled = false
+org_dashboard = 20
+
+[smtp]
+from_address = grafana
+password = secret
+`)
+
+		report, err := provider.Reload(context.Background())
+		require.NoError(t, err)
+		require.Len(t, report.Changes, 3)
+		require.Equal(t, SettingChange{
+			Section:  "smtp",
+			Key:      "from_address",
+			OldValue: "grafana@example.com",
+			NewValue: "grafana",
+			Status:   ChangeFailed,
+			Error:    "invalid email address for SMTP from_address config",
+		}, report.Changes[2])
+		require.Equal(t, "grafana@example.com", provider.SmtpSettings().FromAddress)
+	})
+
+	t.Run("log levels are changed in place", func(t *testing.T) {
+		writeConfig(t, `
+[server]
+http_port = 4000
+
+[quota]
+enabled = false
+org_dashboard = 20
+
+[smtp]
+from_address = grafana@example.com
+password = secret
+
+[log]
+level = debug
+`)
+
+		report, err := provider.Reload(context.Background())
+		require.NoError(t, err)
+		require.Contains(t, report.Changes, SettingChange{Section: "log", Key: "level", OldValue: "info", NewValue: "debug", Status: ChangeApplied})
+	})
+}
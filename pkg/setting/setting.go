@@ -88,6 +88,10 @@ type Cfg struct {
 	configFiles                  []string
 	appliedCommandLineProperties []string
 	appliedEnvOverrides          []string
+	// the arguments the configuration was loaded with, to read it again when it's reloaded
+	args CommandLineArgs
+	// the configuration as it was read, Raw also has the defaults set while the settings are parsed
+	readRaw *ini.File
 
 	// HTTP Server Settings
 	CertFile          string
@@ -138,21 +142,29 @@ type Cfg struct {
 	BundledPluginsPath    string
 	EnterpriseLicensePath string
 
+	// Provisioning
+	ProvisioningChangeDetection bool
+	ProvisioningPrune           bool
+
 	// SMTP email settings
 	Smtp SmtpSettings
 
 	// Rendering
-	ImagesDir                      string
-	CSVsDir                        string
-	PDFsDir                        string
-	RendererUrl                    string
-	RendererCallbackUrl            string
-	RendererAuthToken              string
-	RendererConcurrentRequestLimit int
-	RendererRenderKeyLifeTime      time.Duration
-	RendererDefaultImageWidth      int
-	RendererDefaultImageHeight     int
-	RendererDefaultImageScale      float64
+	ImagesDir                            string
+	CSVsDir                              string
+	PDFsDir                              string
+	RendererUrl                          string
+	RendererCallbackUrl                  string
+	RendererAuthToken                    string
+	RendererConcurrentRequestLimit       int
+	RendererConcurrentRequestPerOrgLimit int
+	RendererQueueTimeout                 time.Duration
+	RendererRetryMaxAttempts             int
+	RendererRetryBackoff                 time.Duration
+	RendererRenderKeyLifeTime            time.Duration
+	RendererDefaultImageWidth            int
+	RendererDefaultImageHeight           int
+	RendererDefaultImageScale            float64
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -263,6 +275,10 @@ type Cfg struct {
 	OAuthCookieMaxAge                    int
 	OAuthAllowInsecureEmailLookup        bool
 	OAuthRefreshTokenServerLockMinWaitMs int64
+	// OAuthRefreshTokenBackground* configure the refresh of the access tokens before they expire
+	OAuthRefreshTokenBackgroundEnabled  bool
+	OAuthRefreshTokenBackgroundInterval time.Duration
+	OAuthRefreshTokenBackgroundWindow   time.Duration
 
 	JWTAuth    AuthJWTSettings
 	ExtJWTAuth ExtJWTSettings
@@ -303,6 +319,8 @@ type Cfg struct {
 	AnonymousOrgRole     string
 	AnonymousHideVersion bool
 	AnonymousDeviceLimit int64
+	// AnonymousDeviceLimitWindow is how long after their last request the anonymous devices count against the device limit.
+	AnonymousDeviceLimitWindow time.Duration
 
 	DateFormats DateFormats
 
@@ -315,6 +333,7 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	SATokenExpiryNotification SATokenExpiryNotificationSettings
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -322,6 +341,8 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationBulk                     AnnotationBulkSettings
+	AnnotationRetention                AnnotationRetentionSettings
 
 	// GrafanaJavascriptAgent config
 	GrafanaJavascriptAgent GrafanaJavascriptAgent
@@ -427,6 +448,11 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveLokiTailMaxStreamsPerOrg is a maximum number of Loki live tail
+	// streams an organization can have open at once. 0 means unlimited.
+	LiveLokiTailMaxStreamsPerOrg int
+	// LiveHistory is the history retention of the Live channels, replayed to the clients subscribing.
+	LiveHistory LiveHistorySettings
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -452,6 +478,58 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	QueryCaching QueryCachingSettings
+
+	QueryAudit QueryAuditSettings
+
+	QueryLimits QueryLimitsSettings
+
+	RecordedQueries RecordedQueriesSettings
+
+	TokenExchange TokenExchangeSettings
+
+	GroupMapping GroupMappingSettings
+
+	LDAPTeamSync LDAPTeamSyncSettings
+
+	SignedURLs SignedURLsSettings
+
+	NetworkPolicy NetworkPolicySettings
+
+	StepUp StepUpSettings
+
+	DataSourceRateLimit DataSourceRateLimitSettings
+	PluginLimits        PluginLimitsSettings
+	PluginQueryBatching PluginQueryBatchingSettings
+	PluginWebhooks      PluginWebhooksSettings
+	AdmissionWebhooks   AdmissionWebhooksSettings
+
+	DataSourceHealthCheck DataSourceHealthCheckSettings
+
+	DataSourceUsage DataSourceUsageSettings
+
+	DashboardSchemaMigration DashboardSchemaMigrationSettings
+
+	DashboardLint DashboardLintSettings
+
+	DashboardInsights DashboardInsightsSettings
+
+	DashboardTrash DashboardTrashSettings
+
+	DashboardSize DashboardSizeSettings
+
+	ScheduledReports ScheduledReportsSettings
+
+	LoadShedding LoadSheddingSettings
+
+	AuditLog AuditLogSettings
+
+	Outbox OutboxSettings
+
+	Jobs JobsSettings
+
+	Metering MeteringSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -488,6 +566,9 @@ type Cfg struct {
 	// This needs to be on the global object since its used in the
 	// sqlstore package and HTTP middlewares.
 	DatabaseInstrumentQueries bool
+	// DatabaseSlowQueryThreshold is the duration above which instrumented
+	// database queries are logged as slow queries, zero disables it.
+	DatabaseSlowQueryThreshold time.Duration
 
 	// Public dashboards
 	PublicDashboardsEnabled bool
@@ -904,6 +985,7 @@ func (cfg *Cfg) loadConfiguration(args CommandLineArgs) (*ini.File, error) {
 	if err != nil {
 		return nil, err
 	}
+	cfg.readRaw = cloneFile(parsedFile)
 
 	// update data path and logging config
 	dataPath := valueAsString(parsedFile.Section("paths"), "data", "")
@@ -1031,6 +1113,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		}
 	}
 
+	cfg.args = args
 	iniFile, err := cfg.loadConfiguration(args)
 	if err != nil {
 		return err
@@ -1074,6 +1157,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.BundledPluginsPath = makeAbsolute("plugins-bundled", cfg.HomePath)
 	provisioning := valueAsString(iniFile.Section("paths"), "provisioning", "")
 	cfg.ProvisioningPath = makeAbsolute(provisioning, cfg.HomePath)
+	provisioningSection := iniFile.Section("provisioning")
+	cfg.ProvisioningChangeDetection = provisioningSection.Key("change_detection").MustBool(true)
+	cfg.ProvisioningPrune = provisioningSection.Key("prune").MustBool(false)
 
 	if err := cfg.readServerSettings(iniFile); err != nil {
 		return err
@@ -1234,6 +1320,8 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	if err := cfg.readAnnotationSettings(); err != nil {
 		return err
 	}
+	cfg.AnnotationBulk = readAnnotationBulkSettings(iniFile)
+	cfg.AnnotationRetention = readAnnotationRetentionSettings(iniFile)
 
 	cfg.readQuotaSettings()
 
@@ -1248,6 +1336,34 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.QueryCaching = readQueryCachingSettings(iniFile)
+	cfg.QueryAudit = readQueryAuditSettings(iniFile)
+	cfg.QueryLimits = readQueryLimitsSettings(iniFile)
+	cfg.RecordedQueries = readRecordedQueriesSettings(iniFile)
+	cfg.TokenExchange = readTokenExchangeSettings(iniFile)
+	cfg.GroupMapping = readGroupMappingSettings(iniFile)
+	cfg.LDAPTeamSync = readLDAPTeamSyncSettings(iniFile)
+	cfg.SignedURLs = readSignedURLsSettings(iniFile)
+	cfg.NetworkPolicy = readNetworkPolicySettings(iniFile)
+	cfg.StepUp = readStepUpSettings(iniFile)
+	cfg.DataSourceRateLimit = readDataSourceRateLimitSettings(iniFile)
+	cfg.PluginLimits = readPluginLimitsSettings(iniFile)
+	cfg.PluginQueryBatching = readPluginQueryBatchingSettings(iniFile)
+	cfg.PluginWebhooks = readPluginWebhooksSettings(iniFile)
+	cfg.AdmissionWebhooks = readAdmissionWebhooksSettings(iniFile)
+	cfg.DataSourceHealthCheck = readDataSourceHealthCheckSettings(iniFile)
+	cfg.DataSourceUsage = readDataSourceUsageSettings(iniFile)
+	cfg.DashboardSchemaMigration = readDashboardSchemaMigrationSettings(iniFile)
+	cfg.DashboardLint = readDashboardLintSettings(iniFile)
+	cfg.DashboardInsights = readDashboardInsightsSettings(iniFile)
+	cfg.DashboardTrash = readDashboardTrashSettings(iniFile)
+	cfg.DashboardSize = readDashboardSizeSettings(iniFile)
+	cfg.ScheduledReports = readScheduledReportsSettings(iniFile)
+	cfg.LoadShedding = readLoadSheddingSettings(iniFile)
+	cfg.AuditLog = readAuditLogSettings(iniFile, cfg.LogsPath)
+	cfg.Outbox = readOutboxSettings(iniFile, cfg.AppURL)
+	cfg.Jobs = readJobsSettings(iniFile)
+	cfg.Metering = readMeteringSettings(iniFile)
 
 	var err error
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
@@ -1287,6 +1403,15 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 		ConnStr:    connStr,
 		Prefix:     prefix,
 		Encryption: encryption,
+		Caches:     make(map[string]NamedCacheOptions),
+	}
+	for _, section := range cacheServer.ChildSections() {
+		name := strings.TrimPrefix(section.Name(), "remote_cache.")
+		cfg.RemoteCacheOptions.Caches[name] = NamedCacheOptions{
+			Backend:   valueAsString(section, "backend", "local"),
+			TTL:       section.Key("ttl").MustDuration(0),
+			Namespace: valueAsString(section, "namespace", ""),
+		}
 	}
 
 	geomapSection := iniFile.Section("geomap")
@@ -1308,9 +1433,15 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	if err := cfg.readLiveSettings(iniFile); err != nil {
 		return err
 	}
+	liveHistory, err := readLiveHistorySettings(iniFile)
+	if err != nil {
+		return err
+	}
+	cfg.LiveHistory = liveHistory
 
 	databaseSection := iniFile.Section("database")
 	cfg.DatabaseInstrumentQueries = databaseSection.Key("instrument_queries").MustBool(false)
+	cfg.DatabaseSlowQueryThreshold = databaseSection.Key("slow_query_threshold").MustDuration(time.Second)
 
 	logSection := iniFile.Section("log")
 	cfg.UserFacingDefaultError = logSection.Key("user_facing_default_error").MustString("please inspect Grafana server log for details")
@@ -1336,6 +1467,18 @@ type RemoteCacheOptions struct {
 	ConnStr    string
 	Prefix     string
 	Encryption bool
+	// Caches are the options of the named caches, configured in [remote_cache.<name>] sections.
+	Caches map[string]NamedCacheOptions
+}
+
+// NamedCacheOptions configures a cache used by a Grafana service, such as the data source cache.
+type NamedCacheOptions struct {
+	// Backend is "local" to cache in memory on each instance, or "remote" to share the remote cache.
+	Backend string
+	// TTL overrides the default time to live of the cached items, when set.
+	TTL time.Duration
+	// Namespace prefixes the keys of the cache, it defaults to the name of the cache.
+	Namespace string
 }
 
 func (cfg *Cfg) readSAMLConfig() {
@@ -1606,6 +1749,13 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 
 	cfg.OAuthCookieMaxAge = auth.Key("oauth_state_cookie_max_age").MustInt(600)
 	cfg.OAuthRefreshTokenServerLockMinWaitMs = auth.Key("oauth_refresh_token_server_lock_min_wait_ms").MustInt64(1000)
+	cfg.OAuthRefreshTokenBackgroundEnabled = auth.Key("oauth_refresh_token_background_enabled").MustBool(false)
+	cfg.OAuthRefreshTokenBackgroundInterval = auth.Key("oauth_refresh_token_background_interval").MustDuration(time.Minute)
+	if cfg.OAuthRefreshTokenBackgroundInterval <= 0 {
+		cfg.OAuthRefreshTokenBackgroundInterval = time.Minute
+	}
+	// the tokens expiring before the next run are refreshed
+	cfg.OAuthRefreshTokenBackgroundWindow = max(auth.Key("oauth_refresh_token_background_window").MustDuration(5*time.Minute), cfg.OAuthRefreshTokenBackgroundInterval)
 	cfg.SignoutRedirectUrl = valueAsString(auth, "signout_redirect_url", "")
 
 	// Deprecated
@@ -1637,6 +1787,7 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	cfg.AnonymousOrgRole = valueAsString(anonSection, "org_role", "")
 	cfg.AnonymousHideVersion = anonSection.Key("hide_version").MustBool(false)
 	cfg.AnonymousDeviceLimit = anonSection.Key("device_limit").MustInt64(0)
+	cfg.AnonymousDeviceLimitWindow = anonSection.Key("device_limit_window").MustDuration(30 * 24 * time.Hour)
 
 	// basic auth
 	authBasic := iniFile.Section("auth.basic")
@@ -1739,6 +1890,7 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SATokenExpiryNotification = readSATokenExpiryNotificationSettings(serviceAccount)
 	return nil
 }
 
@@ -1763,6 +1915,10 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererConcurrentRequestPerOrgLimit = renderSec.Key("concurrent_render_request_per_org_limit").MustInt(0)
+	cfg.RendererQueueTimeout = renderSec.Key("render_queue_timeout").MustDuration(30 * time.Second)
+	cfg.RendererRetryMaxAttempts = renderSec.Key("render_retry_max_attempts").MustInt(3)
+	cfg.RendererRetryBackoff = renderSec.Key("render_retry_backoff").MustDuration(time.Second)
 	cfg.RendererRenderKeyLifeTime = renderSec.Key("render_key_lifetime").MustDuration(5 * time.Minute)
 	cfg.RendererDefaultImageWidth = renderSec.Key("default_image_width").MustInt(1000)
 	cfg.RendererDefaultImageHeight = renderSec.Key("default_image_height").MustInt(500)
@@ -2012,6 +2168,10 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveLokiTailMaxStreamsPerOrg = section.Key("loki_tail_max_streams_per_org").MustInt(50)
+	if cfg.LiveLokiTailMaxStreamsPerOrg < 0 {
+		return fmt.Errorf("unexpected value %d for [live] loki_tail_max_streams_per_org", cfg.LiveLokiTailMaxStreamsPerOrg)
+	}
 
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
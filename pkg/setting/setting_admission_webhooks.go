@@ -0,0 +1,22 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+type AdmissionWebhooksSettings struct {
+	Enabled bool
+	// Token is the bearer token the Kubernetes API server sends to authenticate the admission reviews.
+	Token string
+	// OrgID is the organization the objects of the cluster are validated against.
+	OrgID int64
+}
+
+func readAdmissionWebhooksSettings(iniFile *ini.File) AdmissionWebhooksSettings {
+	section := iniFile.Section("admission_webhooks")
+	return AdmissionWebhooksSettings{
+		Enabled: section.Key("enabled").MustBool(false),
+		Token:   section.Key("token").MustString(""),
+		OrgID:   section.Key("org_id").MustInt64(1),
+	}
+}
@@ -0,0 +1,55 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type AnnotationBulkSettings struct {
+	// MaxLines is the maximum number of annotations of a bulk request.
+	MaxLines int
+	// RateLimit is the number of annotations per second an organization can ingest with bulk requests, 0 for no limit.
+	RateLimit float64
+	// RateLimitBurst is the number of annotations an organization can ingest at once above the rate limit.
+	RateLimitBurst int
+}
+
+type AnnotationRetentionSettings struct {
+	// Enabled turns on the background application of the annotation retention policies of the organizations. The
+	// policies can be applied on demand even if it is disabled.
+	Enabled bool
+	// Interval is how often the retention policies are applied.
+	Interval time.Duration
+}
+
+func readAnnotationBulkSettings(iniFile *ini.File) AnnotationBulkSettings {
+	section := iniFile.Section("annotations.bulk")
+	s := AnnotationBulkSettings{
+		MaxLines:       section.Key("max_lines").MustInt(10000),
+		RateLimit:      section.Key("rate_limit").MustFloat64(500),
+		RateLimitBurst: section.Key("rate_limit_burst").MustInt(5000),
+	}
+	if s.MaxLines < 1 {
+		s.MaxLines = 1
+	}
+	if s.RateLimit < 0 {
+		s.RateLimit = 0
+	}
+	if s.RateLimitBurst < 1 {
+		s.RateLimitBurst = 1
+	}
+	return s
+}
+
+func readAnnotationRetentionSettings(iniFile *ini.File) AnnotationRetentionSettings {
+	section := iniFile.Section("annotations.retention")
+	s := AnnotationRetentionSettings{
+		Enabled:  section.Key("enabled").MustBool(true),
+		Interval: section.Key("interval").MustDuration(time.Hour),
+	}
+	if s.Interval < time.Minute {
+		s.Interval = time.Minute
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+package setting
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type AuditLogSettings struct {
+	Enabled bool
+	// Sinks are the destinations of the audit events: file, loki, syslog and webhook.
+	Sinks []string
+	// SampleRate is the fraction of successful requests that are recorded, between 0 and 1. Failed and
+	// denied requests are always recorded.
+	SampleRate float64
+	// RedactedFields are the names of the fields replaced by a placeholder in the events and request bodies.
+	RedactedFields []string
+	// IncludeRequestBody records the JSON body of the requests, up to MaxBodySize bytes.
+	IncludeRequestBody bool
+	MaxBodySize        int64
+	// ExcludedPaths are the path prefixes of the mutating requests that aren't recorded.
+	ExcludedPaths []string
+	// BufferSize is the number of events waiting to be written before new events are dropped.
+	BufferSize int
+	// FlushInterval is how often the buffered events are written to the sinks.
+	FlushInterval time.Duration
+
+	File    AuditLogFileSettings
+	Loki    AuditLogLokiSettings
+	Syslog  AuditLogSyslogSettings
+	Webhook AuditLogWebhookSettings
+}
+
+type AuditLogFileSettings struct {
+	Path string
+}
+
+type AuditLogLokiSettings struct {
+	URL      string
+	TenantID string
+	Username string
+	Password string
+	// Labels are the labels of the stream of the audit events.
+	Labels map[string]string
+}
+
+type AuditLogSyslogSettings struct {
+	Network  string
+	Address  string
+	Facility string
+	Tag      string
+}
+
+type AuditLogWebhookSettings struct {
+	URL string
+	// Authorization is the value of the Authorization header of the requests.
+	Authorization string
+	Timeout       time.Duration
+}
+
+func readAuditLogSettings(iniFile *ini.File, logsPath string) AuditLogSettings {
+	section := iniFile.Section("audit_log")
+	s := AuditLogSettings{
+		Enabled:            section.Key("enabled").MustBool(false),
+		Sinks:              util.SplitString(section.Key("sinks").MustString("file")),
+		SampleRate:         section.Key("sample_rate").MustFloat64(1),
+		RedactedFields:     util.SplitString(section.Key("redacted_fields").MustString("password,secureJsonData,token,secret,key,apiKey,clientSecret")),
+		IncludeRequestBody: section.Key("include_request_body").MustBool(false),
+		MaxBodySize:        section.Key("max_body_size").MustInt64(64 * 1024),
+		ExcludedPaths:      util.SplitString(section.Key("excluded_paths").MustString("/api/ds/query,/api/frontend-metrics,/api/live/")),
+		BufferSize:         section.Key("buffer_size").MustInt(10000),
+		FlushInterval:      section.Key("flush_interval").MustDuration(time.Second),
+	}
+	if s.SampleRate < 0 {
+		s.SampleRate = 0
+	}
+	if s.SampleRate > 1 {
+		s.SampleRate = 1
+	}
+
+	fileSection := iniFile.Section("audit_log.file")
+	s.File = AuditLogFileSettings{
+		Path: fileSection.Key("path").MustString(filepath.Join(logsPath, "audit.log")),
+	}
+
+	lokiSection := iniFile.Section("audit_log.loki")
+	s.Loki = AuditLogLokiSettings{
+		URL:      lokiSection.Key("url").MustString(""),
+		TenantID: lokiSection.Key("tenant_id").MustString(""),
+		Username: lokiSection.Key("username").MustString(""),
+		Password: lokiSection.Key("password").MustString(""),
+		Labels:   map[string]string{},
+	}
+	for _, label := range util.SplitString(lokiSection.Key("labels").MustString("job=grafana_audit")) {
+		name, value, ok := strings.Cut(label, "=")
+		if ok && name != "" {
+			s.Loki.Labels[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	syslogSection := iniFile.Section("audit_log.syslog")
+	s.Syslog = AuditLogSyslogSettings{
+		Network:  syslogSection.Key("network").MustString(""),
+		Address:  syslogSection.Key("address").MustString(""),
+		Facility: syslogSection.Key("facility").MustString("local7"),
+		Tag:      syslogSection.Key("tag").MustString("grafana-audit"),
+	}
+
+	webhookSection := iniFile.Section("audit_log.webhook")
+	s.Webhook = AuditLogWebhookSettings{
+		URL:           webhookSection.Key("url").MustString(""),
+		Authorization: webhookSection.Key("authorization").MustString(""),
+		Timeout:       webhookSection.Key("timeout").MustDuration(10 * time.Second),
+	}
+	return s
+}
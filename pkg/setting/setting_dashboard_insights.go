@@ -0,0 +1,50 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DashboardInsightsSettings struct {
+	Enabled bool
+	// FlushInterval is how often the usage collected in memory is added to the daily rollups in the database.
+	FlushInterval time.Duration
+	// Retention is how long the daily rollups are kept. It is at least StaleAfter.
+	Retention time.Duration
+	// StaleAfter is how long a dashboard must not be viewed to be tagged as stale, 0 to not tag stale dashboards.
+	StaleAfter time.Duration
+	// StaleTag is the tag added to the stale dashboards, and removed when they are viewed again.
+	StaleTag string
+}
+
+func readDashboardInsightsSettings(iniFile *ini.File) DashboardInsightsSettings {
+	section := iniFile.Section("dashboards.insights")
+	s := DashboardInsightsSettings{
+		Enabled:       section.Key("enabled").MustBool(false),
+		FlushInterval: section.Key("flush_interval").MustDuration(time.Minute),
+		Retention:     section.Key("retention").MustDuration(90 * 24 * time.Hour),
+		StaleAfter:    section.Key("stale_after").MustDuration(0),
+		StaleTag:      valueAsString(section, "stale_tag", "stale"),
+	}
+	if s.FlushInterval < time.Second {
+		s.FlushInterval = time.Second
+	}
+	if s.Retention < 24*time.Hour {
+		s.Retention = 24 * time.Hour
+	}
+	if s.StaleAfter < 0 {
+		s.StaleAfter = 0
+	}
+	if s.StaleAfter > 0 && s.StaleAfter < 24*time.Hour {
+		s.StaleAfter = 24 * time.Hour
+	}
+	// the views of the whole period are needed to tell whether a dashboard is stale
+	if s.Retention < s.StaleAfter {
+		s.Retention = s.StaleAfter
+	}
+	if s.StaleTag == "" {
+		s.StaleTag = "stale"
+	}
+	return s
+}
@@ -0,0 +1,25 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+type DashboardLintSettings struct {
+	// Enabled turns on the linting of the dashboards when they are saved. The dashboards can be linted with the
+	// HTTP API even if it is disabled.
+	Enabled bool
+	// MaxPanels is the number of panels above which a dashboard is reported to have too many panels.
+	MaxPanels int
+}
+
+func readDashboardLintSettings(iniFile *ini.File) DashboardLintSettings {
+	section := iniFile.Section("dashboards.lint")
+	s := DashboardLintSettings{
+		Enabled:   section.Key("enabled").MustBool(true),
+		MaxPanels: section.Key("max_panels").MustInt(100),
+	}
+	if s.MaxPanels < 1 {
+		s.MaxPanels = 1
+	}
+	return s
+}
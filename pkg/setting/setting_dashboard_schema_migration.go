@@ -0,0 +1,33 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DashboardSchemaMigrationSettings struct {
+	// Enabled turns on the background migration of the stored dashboards to the latest schema version.
+	// The dashboards can be migrated on demand even if it is disabled.
+	Enabled bool
+	// Interval is how often a batch of dashboards is migrated.
+	Interval time.Duration
+	// BatchSize is the number of dashboards scanned in a batch.
+	BatchSize int
+}
+
+func readDashboardSchemaMigrationSettings(iniFile *ini.File) DashboardSchemaMigrationSettings {
+	section := iniFile.Section("dashboards.schema_migration")
+	s := DashboardSchemaMigrationSettings{
+		Enabled:   section.Key("enabled").MustBool(false),
+		Interval:  section.Key("interval").MustDuration(10 * time.Minute),
+		BatchSize: section.Key("batch_size").MustInt(100),
+	}
+	if s.Interval < time.Minute {
+		s.Interval = time.Minute
+	}
+	if s.BatchSize < 1 {
+		s.BatchSize = 1
+	}
+	return s
+}
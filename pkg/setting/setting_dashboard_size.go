@@ -0,0 +1,29 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+type DashboardSizeSettings struct {
+	// MaxSize is the maximum size in bytes of the JSON of a dashboard, zero means no limit.
+	// Organizations can be given another limit.
+	MaxSize int64
+	// CompressMinSize is the size in bytes above which the JSON of the dashboards and their versions is stored
+	// compressed, zero means the JSON is never compressed.
+	CompressMinSize int64
+}
+
+func readDashboardSizeSettings(iniFile *ini.File) DashboardSizeSettings {
+	section := iniFile.Section("dashboards.size")
+	s := DashboardSizeSettings{
+		MaxSize:         section.Key("max_size_bytes").MustInt64(0),
+		CompressMinSize: section.Key("compress_min_size_bytes").MustInt64(0),
+	}
+	if s.MaxSize < 0 {
+		s.MaxSize = 0
+	}
+	if s.CompressMinSize < 0 {
+		s.CompressMinSize = 0
+	}
+	return s
+}
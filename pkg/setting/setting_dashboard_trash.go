@@ -0,0 +1,26 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// DefaultDashboardTrashRetention is how long the deleted dashboards and folders are kept in the trash by default.
+const DefaultDashboardTrashRetention = 30 * 24 * time.Hour
+
+type DashboardTrashSettings struct {
+	// Retention is how long the deleted dashboards and folders can be restored before they are purged.
+	Retention time.Duration
+}
+
+func readDashboardTrashSettings(iniFile *ini.File) DashboardTrashSettings {
+	section := iniFile.Section("dashboards.trash")
+	s := DashboardTrashSettings{
+		Retention: section.Key("retention").MustDuration(DefaultDashboardTrashRetention),
+	}
+	if s.Retention < time.Hour {
+		s.Retention = time.Hour
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DataSourceHealthCheckSettings struct {
+	Enabled bool
+	// Interval is how often the health of every data source is checked.
+	Interval time.Duration
+	// Timeout is how long a single health check may take.
+	Timeout time.Duration
+	// Concurrency is the maximum number of health checks that run at the same time.
+	Concurrency int
+}
+
+func readDataSourceHealthCheckSettings(iniFile *ini.File) DataSourceHealthCheckSettings {
+	section := iniFile.Section("datasources.health_check")
+	s := DataSourceHealthCheckSettings{
+		Enabled:     section.Key("enabled").MustBool(false),
+		Interval:    section.Key("interval").MustDuration(5 * time.Minute),
+		Timeout:     section.Key("timeout").MustDuration(30 * time.Second),
+		Concurrency: section.Key("concurrency").MustInt(5),
+	}
+	if s.Interval < time.Minute {
+		s.Interval = time.Minute
+	}
+	if s.Concurrency < 1 {
+		s.Concurrency = 1
+	}
+	return s
+}
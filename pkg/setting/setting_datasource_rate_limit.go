@@ -0,0 +1,72 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const dataSourceRateLimitSectionPrefix = "datasources.rate_limit."
+
+// DataSourceRateLimit limits the outbound requests Grafana makes to a single data source.
+// Zero values disable the respective limit.
+type DataSourceRateLimit struct {
+	MaxConcurrent int
+	QPS           float64
+	Burst         int
+}
+
+func (l DataSourceRateLimit) Enabled() bool {
+	return l.MaxConcurrent > 0 || l.QPS > 0
+}
+
+type DataSourceRateLimitSettings struct {
+	// Default applies to every data source without an override.
+	Default DataSourceRateLimit
+	// Overrides replaces the default limit per data source UID.
+	Overrides map[string]DataSourceRateLimit
+	// QueueTimeout is how long a request waits for a free slot before it is rejected.
+	QueueTimeout time.Duration
+	// MaxQueueSize is the maximum number of requests waiting per data source. 0 means unlimited.
+	MaxQueueSize int
+}
+
+// Limit returns the limit that applies to the data source with the given UID.
+func (s DataSourceRateLimitSettings) Limit(uid string) DataSourceRateLimit {
+	if l, ok := s.Overrides[uid]; ok {
+		return l
+	}
+	return s.Default
+}
+
+func readDataSourceRateLimitSettings(iniFile *ini.File) DataSourceRateLimitSettings {
+	section := iniFile.Section("datasources.rate_limit")
+	s := DataSourceRateLimitSettings{
+		Default:      readDataSourceRateLimit(section, DataSourceRateLimit{}),
+		Overrides:    map[string]DataSourceRateLimit{},
+		QueueTimeout: section.Key("queue_timeout").MustDuration(10 * time.Second),
+		MaxQueueSize: section.Key("max_queue_size").MustInt(0),
+	}
+
+	for _, sub := range iniFile.Sections() {
+		uid, ok := strings.CutPrefix(sub.Name(), dataSourceRateLimitSectionPrefix)
+		if !ok || uid == "" {
+			continue
+		}
+		s.Overrides[uid] = readDataSourceRateLimit(sub, s.Default)
+	}
+	return s
+}
+
+func readDataSourceRateLimit(section *ini.Section, defaults DataSourceRateLimit) DataSourceRateLimit {
+	l := DataSourceRateLimit{
+		MaxConcurrent: section.Key("max_concurrent").MustInt(defaults.MaxConcurrent),
+		QPS:           section.Key("qps").MustFloat64(defaults.QPS),
+		Burst:         section.Key("burst").MustInt(defaults.Burst),
+	}
+	if l.QPS > 0 && l.Burst <= 0 {
+		l.Burst = 1
+	}
+	return l
+}
@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DataSourceUsageSettings struct {
+	Enabled bool
+	// FlushInterval is how often the usage collected in memory is added to the daily rollups in the database.
+	FlushInterval time.Duration
+	// Retention is how long the daily rollups are kept.
+	Retention time.Duration
+	// TopLimit is the number of dashboards and users listed in the usage of a data source.
+	TopLimit int
+}
+
+func readDataSourceUsageSettings(iniFile *ini.File) DataSourceUsageSettings {
+	section := iniFile.Section("datasources.usage")
+	s := DataSourceUsageSettings{
+		Enabled:       section.Key("enabled").MustBool(false),
+		FlushInterval: section.Key("flush_interval").MustDuration(time.Minute),
+		Retention:     section.Key("retention").MustDuration(90 * 24 * time.Hour),
+		TopLimit:      section.Key("top_limit").MustInt(10),
+	}
+	if s.FlushInterval < time.Second {
+		s.FlushInterval = time.Second
+	}
+	if s.Retention < 24*time.Hour {
+		s.Retention = 24 * time.Hour
+	}
+	if s.TopLimit < 1 {
+		s.TopLimit = 1
+	}
+	return s
+}
@@ -1,6 +1,8 @@
 package setting
 
 import (
+	"time"
+
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -10,6 +12,12 @@ type FeatureMgmtSettings struct {
 	AllowEditing       bool
 	UpdateWebhook      string
 	UpdateWebhookToken string
+	// RuntimeOverrides enables the API to change the state of the flags at runtime, for all or some
+	// organizations, without restarting Grafana.
+	RuntimeOverrides bool
+	// RuntimeOverridesSyncInterval is how often the overrides are reloaded from the database, to apply
+	// the changes made on other instances.
+	RuntimeOverridesSyncInterval time.Duration
 }
 
 func (cfg *Cfg) readFeatureManagementConfig() {
@@ -35,4 +43,6 @@ func (cfg *Cfg) readFeatureManagementConfig() {
 	cfg.FeatureManagement.AllowEditing = cfg.SectionWithEnvOverrides("feature_management").Key("allow_editing").MustBool(false)
 	cfg.FeatureManagement.UpdateWebhook = cfg.SectionWithEnvOverrides("feature_management").Key("update_webhook").MustString("")
 	cfg.FeatureManagement.UpdateWebhookToken = cfg.SectionWithEnvOverrides("feature_management").Key("update_webhook_token").MustString("")
+	cfg.FeatureManagement.RuntimeOverrides = section.Key("runtime_overrides").MustBool(false)
+	cfg.FeatureManagement.RuntimeOverridesSyncInterval = section.Key("runtime_overrides_sync_interval").MustDuration(30 * time.Second)
 }
@@ -0,0 +1,42 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	// GroupMappingPolicyKeep only removes the roles and team memberships the group mapping granted itself.
+	GroupMappingPolicyKeep = "keep"
+	// GroupMappingPolicyOverride removes the roles and team memberships of every rule the user doesn't match,
+	// including the ones assigned by hand.
+	GroupMappingPolicyOverride = "override"
+)
+
+type GroupMappingSettings struct {
+	Enabled bool
+	// ConfigFile is the YAML file with the rules mapping the IdP groups to roles and teams.
+	ConfigFile string
+	// ConflictPolicy decides which roles and team memberships are removed from the users who no longer match a rule.
+	ConflictPolicy string
+	// SyncInterval is how often the users who haven't logged in recently are synced. 0 disables it.
+	SyncInterval time.Duration
+	// SyncAfter is how long after their last sync the users are synced on schedule.
+	SyncAfter time.Duration
+}
+
+func readGroupMappingSettings(iniFile *ini.File) GroupMappingSettings {
+	section := iniFile.Section("auth.group_mapping")
+	s := GroupMappingSettings{
+		Enabled:        section.Key("enabled").MustBool(false),
+		ConfigFile:     section.Key("config_file").MustString("conf/group_mapping.yaml"),
+		ConflictPolicy: section.Key("conflict_policy").In(GroupMappingPolicyKeep, []string{GroupMappingPolicyKeep, GroupMappingPolicyOverride}),
+		SyncInterval:   section.Key("sync_interval").MustDuration(time.Hour),
+		SyncAfter:      section.Key("sync_after").MustDuration(24 * time.Hour),
+	}
+	if s.SyncInterval < 0 {
+		s.SyncInterval = 0
+	}
+	return s
+}
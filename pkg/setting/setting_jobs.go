@@ -0,0 +1,28 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type JobsSettings struct {
+	// Workers is the number of jobs an instance runs at the same time.
+	Workers int
+	// PollInterval is how often the queue is checked for due jobs and the schedules for jobs to enqueue.
+	PollInterval time.Duration
+	// DefaultTimeout is the timeout of the jobs which don't set one.
+	DefaultTimeout time.Duration
+	// RunHistoryRetention is how long the finished runs of the jobs are kept.
+	RunHistoryRetention time.Duration
+}
+
+func readJobsSettings(iniFile *ini.File) JobsSettings {
+	section := iniFile.Section("jobs")
+	return JobsSettings{
+		Workers:             section.Key("workers").MustInt(4),
+		PollInterval:        section.Key("poll_interval").MustDuration(10 * time.Second),
+		DefaultTimeout:      section.Key("default_timeout").MustDuration(10 * time.Minute),
+		RunHistoryRetention: section.Key("run_history_retention").MustDuration(7 * 24 * time.Hour),
+	}
+}
@@ -0,0 +1,28 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type LDAPTeamSyncSettings struct {
+	Enabled bool
+	// ConfigFile is the YAML file with the LDAP groups synced to the teams.
+	ConfigFile string
+	// DefaultInterval is how often the teams of the groups without their own interval are synced.
+	DefaultInterval time.Duration
+}
+
+func readLDAPTeamSyncSettings(iniFile *ini.File) LDAPTeamSyncSettings {
+	section := iniFile.Section("auth.ldap.team_sync")
+	s := LDAPTeamSyncSettings{
+		Enabled:         section.Key("enabled").MustBool(false),
+		ConfigFile:      section.Key("config_file").MustString("conf/ldap_team_sync.yaml"),
+		DefaultInterval: section.Key("default_interval").MustDuration(time.Hour),
+	}
+	if s.DefaultInterval < time.Minute {
+		s.DefaultInterval = time.Minute
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/ini.v1"
+)
+
+const liveHistoryChannelSectionPrefix = "live.history."
+
+const (
+	LiveHistoryStorageRemoteCache = "remote_cache"
+	LiveHistoryStorageDatabase    = "database"
+)
+
+// LiveHistoryChannel is the history retention of the Live channels matching its pattern.
+type LiveHistoryChannel struct {
+	// Pattern is a glob matched against the channel, without its organization. "*" matches a single
+	// segment of the channel, and "**" any number of them.
+	Pattern string
+	// MaxMessages is the number of messages kept, 0 for no limit.
+	MaxMessages int
+	// MaxAge is how long the messages are kept.
+	MaxAge time.Duration
+}
+
+type LiveHistorySettings struct {
+	Enabled bool
+	// Storage is where the messages are kept, remote_cache or database.
+	Storage string
+	// Channels are the retentions of the channels which keep a history, the first matching one applies.
+	Channels []LiveHistoryChannel
+}
+
+func readLiveHistorySettings(iniFile *ini.File) (LiveHistorySettings, error) {
+	section := iniFile.Section("live.history")
+	s := LiveHistorySettings{
+		Enabled: section.Key("enabled").MustBool(false),
+		Storage: section.Key("storage").MustString(LiveHistoryStorageRemoteCache),
+	}
+	switch s.Storage {
+	case LiveHistoryStorageRemoteCache, LiveHistoryStorageDatabase:
+	default:
+		return s, fmt.Errorf("unsupported storage %q of [live.history]", s.Storage)
+	}
+
+	for _, sub := range iniFile.Sections() {
+		name, ok := strings.CutPrefix(sub.Name(), liveHistoryChannelSectionPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		channel := LiveHistoryChannel{
+			Pattern:     sub.Key("pattern").MustString(""),
+			MaxMessages: sub.Key("max_messages").MustInt(100),
+			MaxAge:      sub.Key("max_age").MustDuration(10 * time.Minute),
+		}
+		if channel.Pattern == "" {
+			return s, fmt.Errorf("missing pattern of [%s]", sub.Name())
+		}
+		if _, err := glob.Compile(channel.Pattern, '/'); err != nil {
+			return s, fmt.Errorf("invalid pattern of [%s]: %w", sub.Name(), err)
+		}
+		if channel.MaxMessages < 0 {
+			return s, fmt.Errorf("unexpected value %d for max_messages of [%s]", channel.MaxMessages, sub.Name())
+		}
+		if channel.MaxAge <= 0 || channel.MaxAge > 24*time.Hour {
+			return s, fmt.Errorf("max_age of [%s] must be between 1s and 24h", sub.Name())
+		}
+		s.Channels = append(s.Channels, channel)
+	}
+	return s, nil
+}
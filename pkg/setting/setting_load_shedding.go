@@ -0,0 +1,42 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type LoadSheddingSettings struct {
+	Enabled bool
+	// InitialLimit, MinLimit and MaxLimit bound the adaptive limit of concurrent requests.
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// RetryAfter is returned to the clients of the requests that are shed.
+	RetryAfter time.Duration
+	// AlertWebhookPaths are the path prefixes of the requests posting alerts, besides the alerts API
+	// of the Alertmanagers.
+	AlertWebhookPaths []string
+}
+
+func readLoadSheddingSettings(iniFile *ini.File) LoadSheddingSettings {
+	section := iniFile.Section("load_shedding")
+	s := LoadSheddingSettings{
+		Enabled:           section.Key("enabled").MustBool(false),
+		InitialLimit:      section.Key("initial_limit").MustInt(100),
+		MinLimit:          section.Key("min_limit").MustInt(20),
+		MaxLimit:          section.Key("max_limit").MustInt(1000),
+		RetryAfter:        section.Key("retry_after").MustDuration(5 * time.Second),
+		AlertWebhookPaths: util.SplitString(section.Key("alert_webhook_paths").MustString("")),
+	}
+	if s.MinLimit < 1 {
+		s.MinLimit = 1
+	}
+	if s.MaxLimit < s.MinLimit {
+		s.MaxLimit = s.MinLimit
+	}
+	s.InitialLimit = min(max(s.InitialLimit, s.MinLimit), s.MaxLimit)
+	return s
+}
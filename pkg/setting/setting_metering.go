@@ -0,0 +1,38 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type MeteringSettings struct {
+	Enabled bool
+	// FlushInterval is how often the usage events counted by an instance are added to the hourly records.
+	FlushInterval time.Duration
+	// Retention is how long the hourly records are kept.
+	Retention time.Duration
+	// WebhookURL is the URL the records of the previous hour are pushed to, nothing is pushed when it's empty.
+	WebhookURL string
+	// WebhookFormat is the format of the pushed records, json or csv.
+	WebhookFormat string
+	// WebhookSchedule is the cron schedule of the pushes, in UTC.
+	WebhookSchedule string
+	WebhookTimeout  time.Duration
+	// WebhookAuthorizationHeader is the value of the Authorization header of the pushes.
+	WebhookAuthorizationHeader string
+}
+
+func readMeteringSettings(iniFile *ini.File) MeteringSettings {
+	section := iniFile.Section("metering")
+	return MeteringSettings{
+		Enabled:                    section.Key("enabled").MustBool(false),
+		FlushInterval:              section.Key("flush_interval").MustDuration(time.Minute),
+		Retention:                  section.Key("retention").MustDuration(90 * 24 * time.Hour),
+		WebhookURL:                 section.Key("webhook_url").MustString(""),
+		WebhookFormat:              section.Key("webhook_format").MustString("json"),
+		WebhookSchedule:            section.Key("webhook_schedule").MustString("15 * * * *"),
+		WebhookTimeout:             section.Key("webhook_timeout").MustDuration(30 * time.Second),
+		WebhookAuthorizationHeader: section.Key("webhook_authorization_header").MustString(""),
+	}
+}
@@ -0,0 +1,22 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type NetworkPolicySettings struct {
+	Enabled bool
+	// TrustedProxies are the CIDRs of the proxies whose X-Real-IP and X-Forwarded-For headers are trusted to
+	// hold the address of the client. The headers of the other peers are ignored, so that they can't be spoofed.
+	TrustedProxies []string
+}
+
+func readNetworkPolicySettings(iniFile *ini.File) NetworkPolicySettings {
+	section := iniFile.Section("network_policy")
+	return NetworkPolicySettings{
+		Enabled:        section.Key("enabled").MustBool(false),
+		TrustedProxies: util.SplitString(section.Key("trusted_proxies").MustString("")),
+	}
+}
@@ -0,0 +1,39 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type OutboxSettings struct {
+	// PollInterval is how often the outbox is checked for events to publish, besides the events
+	// committed by this instance, which are published right away.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of events published at once.
+	BatchSize int
+	// MaxRetryBackoff caps the delay between the attempts to publish an event.
+	MaxRetryBackoff time.Duration
+	// PublishToLive publishes the events on the grafana/events/<event type> Grafana Live channels.
+	PublishToLive bool
+	// CloudEventsSinkURL is the URL the events are posted to as CloudEvents, when set.
+	CloudEventsSinkURL string
+	// CloudEventsSource is the source of the CloudEvents, which defaults to the root URL.
+	CloudEventsSource string
+	// CloudEventsSinkAuthorization is the Authorization header of the requests to the sink, which can reference
+	// the credentials of the main organization as $__credential{name}.
+	CloudEventsSinkAuthorization string
+}
+
+func readOutboxSettings(iniFile *ini.File, appURL string) OutboxSettings {
+	section := iniFile.Section("outbox")
+	return OutboxSettings{
+		PollInterval:                 section.Key("poll_interval").MustDuration(5 * time.Second),
+		BatchSize:                    section.Key("batch_size").MustInt(100),
+		MaxRetryBackoff:              section.Key("max_retry_backoff").MustDuration(5 * time.Minute),
+		PublishToLive:                section.Key("publish_to_live").MustBool(false),
+		CloudEventsSinkURL:           valueAsString(section, "cloudevents_sink_url", ""),
+		CloudEventsSource:            valueAsString(section, "cloudevents_source", appURL),
+		CloudEventsSinkAuthorization: valueAsString(section, "cloudevents_sink_authorization", ""),
+	}
+}
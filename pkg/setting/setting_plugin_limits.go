@@ -0,0 +1,76 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const pluginLimitsSectionPrefix = "plugins.limits."
+
+// PluginLimits limits the resources used by a backend plugin. Zero values disable the respective limit.
+type PluginLimits struct {
+	// MaxConcurrentRequests caps the queries, resource calls and health checks the plugin handles at once.
+	MaxConcurrentRequests int
+	// MaxMemoryMB is the memory of the plugin process above which it is restarted.
+	MaxMemoryMB int
+	// MaxCPU is the number of CPUs the plugin process is expected to use. It's advisory: it sets GOMAXPROCS for
+	// plugins written in Go, and a warning is logged when the process uses more, but the process isn't throttled.
+	MaxCPU float64
+	// RestartBudget is how many times the plugin process can be restarted within RestartBudgetWindow
+	// before the plugin is disabled.
+	RestartBudget       int
+	RestartBudgetWindow time.Duration
+}
+
+type PluginLimitsSettings struct {
+	// Default applies to every plugin without an override.
+	Default PluginLimits
+	// Overrides replaces the default limits per plugin ID.
+	Overrides map[string]PluginLimits
+	// QueueTimeout is how long a request waits for the plugin to handle fewer requests before it is rejected.
+	QueueTimeout time.Duration
+	// ResourceUsageInterval is how often the resource usage of the plugin processes is measured.
+	ResourceUsageInterval time.Duration
+}
+
+// Limits returns the limits that apply to the plugin with the given ID.
+func (s PluginLimitsSettings) Limits(pluginID string) PluginLimits {
+	if l, ok := s.Overrides[pluginID]; ok {
+		return l
+	}
+	return s.Default
+}
+
+func readPluginLimitsSettings(iniFile *ini.File) PluginLimitsSettings {
+	section := iniFile.Section("plugins.limits")
+	s := PluginLimitsSettings{
+		Default: readPluginLimits(section, PluginLimits{
+			RestartBudget:       5,
+			RestartBudgetWindow: 10 * time.Minute,
+		}),
+		Overrides:             map[string]PluginLimits{},
+		QueueTimeout:          section.Key("queue_timeout").MustDuration(10 * time.Second),
+		ResourceUsageInterval: section.Key("resource_usage_interval").MustDuration(10 * time.Second),
+	}
+
+	for _, sub := range iniFile.Sections() {
+		pluginID, ok := strings.CutPrefix(sub.Name(), pluginLimitsSectionPrefix)
+		if !ok || pluginID == "" {
+			continue
+		}
+		s.Overrides[pluginID] = readPluginLimits(sub, s.Default)
+	}
+	return s
+}
+
+func readPluginLimits(section *ini.Section, defaults PluginLimits) PluginLimits {
+	return PluginLimits{
+		MaxConcurrentRequests: section.Key("max_concurrent_requests").MustInt(defaults.MaxConcurrentRequests),
+		MaxMemoryMB:           section.Key("max_memory_mb").MustInt(defaults.MaxMemoryMB),
+		MaxCPU:                section.Key("max_cpu").MustFloat64(defaults.MaxCPU),
+		RestartBudget:         section.Key("restart_budget").MustInt(defaults.RestartBudget),
+		RestartBudgetWindow:   section.Key("restart_budget_window").MustDuration(defaults.RestartBudgetWindow),
+	}
+}
@@ -0,0 +1,26 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// PluginQueryBatchingSettings configures the coalescing of the queries sent to the backend plugins
+// that declare the queryBatching capability.
+type PluginQueryBatchingSettings struct {
+	Enabled bool
+	// Window is how long the first request of a batch waits for more requests to the same data source.
+	Window time.Duration
+	// MaxQueries is the number of queries above which a batch is sent without waiting for the window to pass.
+	MaxQueries int
+}
+
+func readPluginQueryBatchingSettings(iniFile *ini.File) PluginQueryBatchingSettings {
+	section := iniFile.Section("plugins.query_batching")
+	return PluginQueryBatchingSettings{
+		Enabled:    section.Key("enabled").MustBool(true),
+		Window:     section.Key("window").MustDuration(10 * time.Millisecond),
+		MaxQueries: section.Key("max_queries").MustInt(50),
+	}
+}
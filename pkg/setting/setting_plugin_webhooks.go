@@ -0,0 +1,22 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+type PluginWebhooksSettings struct {
+	// RateLimit is the number of requests per second each webhook of each plugin accepts, 0 means unlimited.
+	RateLimit float64
+	Burst     int
+	// MaxBodySize is the maximum size of a webhook payload in bytes.
+	MaxBodySize int64
+}
+
+func readPluginWebhooksSettings(iniFile *ini.File) PluginWebhooksSettings {
+	section := iniFile.Section("plugins.webhooks")
+	return PluginWebhooksSettings{
+		RateLimit:   section.Key("rate_limit").MustFloat64(10),
+		Burst:       section.Key("burst").MustInt(20),
+		MaxBodySize: section.Key("max_body_size").MustInt64(1024 * 1024),
+	}
+}
@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryAuditSettings struct {
+	Enabled bool
+	// SampleRate is the fraction of queries that are recorded, between 0 and 1.
+	SampleRate float64
+	// SlowQueryThreshold records every query that takes longer, regardless of the sample rate. 0 disables it.
+	SlowQueryThreshold time.Duration
+	// RecordErrors records every query that fails, regardless of the sample rate.
+	RecordErrors bool
+	// Retention is how long recorded queries are kept.
+	Retention time.Duration
+}
+
+func readQueryAuditSettings(iniFile *ini.File) QueryAuditSettings {
+	section := iniFile.Section("query_audit")
+	s := QueryAuditSettings{
+		Enabled:            section.Key("enabled").MustBool(false),
+		SampleRate:         section.Key("sample_rate").MustFloat64(1),
+		SlowQueryThreshold: section.Key("slow_query_threshold").MustDuration(0),
+		RecordErrors:       section.Key("record_errors").MustBool(true),
+		Retention:          section.Key("retention").MustDuration(7 * 24 * time.Hour),
+	}
+	if s.SampleRate < 0 {
+		s.SampleRate = 0
+	}
+	if s.SampleRate > 1 {
+		s.SampleRate = 1
+	}
+	return s
+}
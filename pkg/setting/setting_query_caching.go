@@ -0,0 +1,35 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	Enabled bool
+	// DefaultTTL is used for data sources that do not have a TTL configured in DatasourceTTLs.
+	DefaultTTL time.Duration
+	// DatasourceTTLs overrides the TTL per data source UID. A TTL of 0 disables caching for the data source.
+	DatasourceTTLs map[string]time.Duration
+	// TimeRangeRounding is the precision the time range of a query is truncated to before it is used in the cache key.
+	TimeRangeRounding time.Duration
+	MaxEntries        int
+}
+
+func readQueryCachingSettings(iniFile *ini.File) QueryCachingSettings {
+	s := QueryCachingSettings{
+		DatasourceTTLs: map[string]time.Duration{},
+	}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.DefaultTTL = section.Key("ttl").MustDuration(time.Minute)
+	s.TimeRangeRounding = section.Key("time_range_rounding").MustDuration(10 * time.Second)
+	s.MaxEntries = section.Key("max_entries").MustInt(10000)
+
+	for _, key := range iniFile.Section("query_caching.datasource_ttl").Keys() {
+		s.DatasourceTTLs[key.Name()] = key.MustDuration(s.DefaultTTL)
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package setting
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const queryLimitsSectionPrefix = "query.limits."
+
+// QueryLimit limits the result of a single query request. Zero values disable the respective limit.
+type QueryLimit struct {
+	// MaxResponseBytes is the approximate maximum size of the frames returned by a request.
+	MaxResponseBytes int64
+	// MaxFrames is the maximum number of frames returned by a request.
+	MaxFrames int
+	// Timeout is the maximum time a request may take.
+	Timeout time.Duration
+	// Truncate returns the part of the result that fits in the limits instead of rejecting it.
+	Truncate bool
+}
+
+type QueryLimitsSettings struct {
+	// Default applies to every organization without an override.
+	Default QueryLimit
+	// Overrides replaces the default limit per organization ID.
+	Overrides map[int64]QueryLimit
+}
+
+// Limit returns the limit that applies to the organization with the given ID.
+func (s QueryLimitsSettings) Limit(orgID int64) QueryLimit {
+	if l, ok := s.Overrides[orgID]; ok {
+		return l
+	}
+	return s.Default
+}
+
+func readQueryLimitsSettings(iniFile *ini.File) QueryLimitsSettings {
+	s := QueryLimitsSettings{
+		Default:   readQueryLimit(iniFile.Section("query.limits"), QueryLimit{}),
+		Overrides: map[int64]QueryLimit{},
+	}
+
+	for _, sub := range iniFile.Sections() {
+		name, ok := strings.CutPrefix(sub.Name(), queryLimitsSectionPrefix)
+		if !ok {
+			continue
+		}
+		orgID, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		s.Overrides[orgID] = readQueryLimit(sub, s.Default)
+	}
+	return s
+}
+
+func readQueryLimit(section *ini.Section, defaults QueryLimit) QueryLimit {
+	action := "reject"
+	if defaults.Truncate {
+		action = "truncate"
+	}
+	return QueryLimit{
+		MaxResponseBytes: section.Key("max_response_bytes").MustInt64(defaults.MaxResponseBytes),
+		MaxFrames:        section.Key("max_frames").MustInt(defaults.MaxFrames),
+		Timeout:          section.Key("timeout").MustDuration(defaults.Timeout),
+		Truncate:         section.Key("on_limit_exceeded").In(action, []string{"reject", "truncate"}) == "truncate",
+	}
+}
@@ -1,11 +1,14 @@
 package setting
 
+import "reflect"
+
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User            int64 `target:"org_user"`
+	DataSource      int64 `target:"data_source"`
+	Dashboard       int64 `target:"dashboard"`
+	ApiKey          int64 `target:"api_key"`
+	AlertRule       int64 `target:"alert_rule"`
+	ScheduledReport int64 `target:"scheduled_report"`
 }
 
 type UserQuota struct {
@@ -13,15 +16,16 @@ type UserQuota struct {
 }
 
 type GlobalQuota struct {
-	Org          int64 `target:"org"`
-	User         int64 `target:"user"`
-	DataSource   int64 `target:"data_source"`
-	Dashboard    int64 `target:"dashboard"`
-	ApiKey       int64 `target:"api_key"`
-	Session      int64 `target:"-"`
-	AlertRule    int64 `target:"alert_rule"`
-	File         int64 `target:"file"`
-	Correlations int64 `target:"correlations"`
+	Org             int64 `target:"org"`
+	User            int64 `target:"user"`
+	DataSource      int64 `target:"data_source"`
+	Dashboard       int64 `target:"dashboard"`
+	ApiKey          int64 `target:"api_key"`
+	Session         int64 `target:"-"`
+	AlertRule       int64 `target:"alert_rule"`
+	File            int64 `target:"file"`
+	Correlations    int64 `target:"correlations"`
+	ScheduledReport int64 `target:"scheduled_report"`
 }
 
 type QuotaSettings struct {
@@ -31,6 +35,28 @@ type QuotaSettings struct {
 	Global  GlobalQuota
 }
 
+// Limit returns the default limit of a target for a scope (global, org or user), from the target tags of the
+// settings.
+func (q QuotaSettings) Limit(scope, target string) (int64, bool) {
+	var limits reflect.Value
+	switch scope {
+	case "global":
+		limits = reflect.ValueOf(q.Global)
+	case "org":
+		limits = reflect.ValueOf(q.Org)
+	case "user":
+		limits = reflect.ValueOf(q.User)
+	default:
+		return 0, false
+	}
+	for i := 0; i < limits.NumField(); i++ {
+		if limits.Type().Field(i).Tag.Get("target") == target {
+			return limits.Field(i).Int(), true
+		}
+	}
+	return 0, false
+}
+
 func (cfg *Cfg) readQuotaSettings() {
 	// set global defaults.
 	quota := cfg.Raw.Section("quota")
@@ -38,11 +64,12 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// per ORG Limits
 	cfg.Quota.Org = OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  quota.Key("org_alert_rule").MustInt64(100),
+		User:            quota.Key("org_user").MustInt64(10),
+		DataSource:      quota.Key("org_data_source").MustInt64(10),
+		Dashboard:       quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:          quota.Key("org_api_key").MustInt64(10),
+		AlertRule:       quota.Key("org_alert_rule").MustInt64(100),
+		ScheduledReport: quota.Key("org_scheduled_report").MustInt64(10),
 	}
 
 	// per User limits
@@ -52,14 +79,15 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// Global Limits
 	cfg.Quota.Global = GlobalQuota{
-		User:         quota.Key("global_user").MustInt64(-1),
-		Org:          quota.Key("global_org").MustInt64(-1),
-		DataSource:   quota.Key("global_data_source").MustInt64(-1),
-		Dashboard:    quota.Key("global_dashboard").MustInt64(-1),
-		ApiKey:       quota.Key("global_api_key").MustInt64(-1),
-		Session:      quota.Key("global_session").MustInt64(-1),
-		File:         quota.Key("global_file").MustInt64(-1),
-		AlertRule:    quota.Key("global_alert_rule").MustInt64(-1),
-		Correlations: quota.Key("global_correlations").MustInt64(-1),
+		User:            quota.Key("global_user").MustInt64(-1),
+		Org:             quota.Key("global_org").MustInt64(-1),
+		DataSource:      quota.Key("global_data_source").MustInt64(-1),
+		Dashboard:       quota.Key("global_dashboard").MustInt64(-1),
+		ApiKey:          quota.Key("global_api_key").MustInt64(-1),
+		Session:         quota.Key("global_session").MustInt64(-1),
+		File:            quota.Key("global_file").MustInt64(-1),
+		AlertRule:       quota.Key("global_alert_rule").MustInt64(-1),
+		Correlations:    quota.Key("global_correlations").MustInt64(-1),
+		ScheduledReport: quota.Key("global_scheduled_report").MustInt64(-1),
 	}
 }
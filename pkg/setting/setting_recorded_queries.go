@@ -0,0 +1,61 @@
+package setting
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const recordedQueriesSectionPrefix = "recorded_queries."
+
+type RecordedQueriesSettings struct {
+	Enabled bool
+	// RemoteWriteURL is the Prometheus remote write endpoint the results are written to.
+	RemoteWriteURL      string
+	RemoteWriteUser     string
+	RemoteWritePassword string
+	RemoteWriteTimeout  time.Duration
+	// MinInterval is the shortest interval a recorded query can be executed at.
+	MinInterval time.Duration
+	// MaxQueries is the maximum number of recorded queries of an organization. 0 means unlimited.
+	MaxQueries int
+	// MaxQueriesOverrides replaces MaxQueries per organization ID.
+	MaxQueriesOverrides map[int64]int
+}
+
+// MaxQueriesForOrg returns the maximum number of recorded queries of the organization with the given ID.
+func (s RecordedQueriesSettings) MaxQueriesForOrg(orgID int64) int {
+	if max, ok := s.MaxQueriesOverrides[orgID]; ok {
+		return max
+	}
+	return s.MaxQueries
+}
+
+func readRecordedQueriesSettings(iniFile *ini.File) RecordedQueriesSettings {
+	section := iniFile.Section("recorded_queries")
+	s := RecordedQueriesSettings{
+		Enabled:             section.Key("enabled").MustBool(false),
+		RemoteWriteURL:      section.Key("remote_write_url").MustString(""),
+		RemoteWriteUser:     section.Key("remote_write_basic_auth_username").MustString(""),
+		RemoteWritePassword: section.Key("remote_write_basic_auth_password").MustString(""),
+		RemoteWriteTimeout:  section.Key("remote_write_timeout").MustDuration(10 * time.Second),
+		MinInterval:         section.Key("min_interval").MustDuration(time.Minute),
+		MaxQueries:          section.Key("max_queries").MustInt(20),
+		MaxQueriesOverrides: map[int64]int{},
+	}
+
+	for _, sub := range iniFile.Sections() {
+		name, ok := strings.CutPrefix(sub.Name(), recordedQueriesSectionPrefix)
+		if !ok {
+			continue
+		}
+		orgID, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		s.MaxQueriesOverrides[orgID] = sub.Key("max_queries").MustInt(s.MaxQueries)
+	}
+	return s
+}
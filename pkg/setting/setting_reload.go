@@ -0,0 +1,157 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SmtpSections are the sections the SMTP settings are read from.
+var SmtpSections = []string{"smtp", "smtp.static_headers", "emails"}
+
+// ReadConfiguration reads the configuration again from the files, the environment variables and the command line,
+// the same way it was read at startup, without applying it.
+func (cfg *Cfg) ReadConfiguration() (*ini.File, error) {
+	// the configuration is read by a copy, so that the sources logged at startup are kept
+	reader := NewCfg()
+	reader.HomePath = cfg.HomePath
+
+	parsedFile, err := ini.Load(path.Join(cfg.HomePath, "conf/defaults.ini"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse defaults.ini: %w", err)
+	}
+
+	commandLineProps := reader.getCommandLineProperties(cfg.args.Args)
+	reader.applyCommandLineDefaultProperties(commandLineProps, parsedFile)
+
+	if err := reader.loadSpecifiedConfigFile(cfg.args.Config, parsedFile); err != nil {
+		return nil, err
+	}
+	if err := reader.applyEnvVariableOverrides(parsedFile); err != nil {
+		return nil, err
+	}
+	reader.applyCommandLineProperties(commandLineProps, parsedFile)
+
+	if err := expandConfig(parsedFile); err != nil {
+		return nil, err
+	}
+	return parsedFile, nil
+}
+
+// settingsReloadHandler reloads the settings read from a section.
+type settingsReloadHandler struct {
+	o *OSSImpl
+	// reload reloads the settings from the copy of the configuration with the reloaded changes.
+	reload func(raw *ini.File) error
+	// validate validates the section before it's reloaded, it's optional.
+	validate func(section Section) error
+	// reloadable returns true for the keys which can be reloaded, all the keys are reloadable if it's nil.
+	reloadable func(key string) bool
+}
+
+func (h *settingsReloadHandler) ReloadSection(Section) error {
+	return h.reload(h.o.reloading)
+}
+
+func (h *settingsReloadHandler) ValidateSection(section Section) error {
+	if h.validate == nil {
+		return nil
+	}
+	return h.validate(section)
+}
+
+func (h *settingsReloadHandler) IsReloadable(_, key string) bool {
+	return h.reloadable == nil || h.reloadable(key)
+}
+
+// registerSettingsReloadHandlers registers the handlers reloading the settings which are safe to change at runtime:
+// the log levels, the SMTP settings and the default quotas. They are registered first, so that the handlers of the
+// services read the reloaded settings from the provider.
+func (o *OSSImpl) registerSettingsReloadHandlers() {
+	cfg := o.Cfg
+
+	// the levels are changed in place, the log targets are not opened again
+	reloadLogging := func(raw *ini.File) error {
+		log.ReloadLevels(raw)
+		return nil
+	}
+	o.RegisterReloadHandler("log", &settingsReloadHandler{
+		o:          o,
+		reload:     reloadLogging,
+		reloadable: func(key string) bool { return key == "level" || key == "filters" || key == "sampling" },
+	})
+	for _, section := range []string{"log.console", "log.file", "log.syslog"} {
+		o.RegisterReloadHandler(section, &settingsReloadHandler{
+			o:          o,
+			reload:     reloadLogging,
+			reloadable: func(key string) bool { return key == "level" },
+		})
+	}
+
+	reloadSmtp := func(raw *ini.File) error {
+		next := &Cfg{Raw: raw, InstanceName: cfg.InstanceName}
+		if err := next.readSmtpSettings(); err != nil {
+			return err
+		}
+		o.smtp.Store(&next.Smtp)
+		return nil
+	}
+	o.RegisterReloadHandler("smtp", &settingsReloadHandler{
+		o:      o,
+		reload: reloadSmtp,
+		validate: func(section Section) error {
+			if !util.IsEmail(section.KeyValue("from_address").Value()) {
+				return errors.New("invalid email address for SMTP from_address config")
+			}
+			return nil
+		},
+	})
+	o.RegisterReloadHandler("smtp.static_headers", &settingsReloadHandler{
+		o:      o,
+		reload: reloadSmtp,
+		validate: func(section Section) error {
+			for _, key := range section.Keys() {
+				if !validHeader(key) {
+					return fmt.Errorf("header %q in [smtp.static_headers] configuration: must follow canonical MIME form", key)
+				}
+			}
+			return nil
+		},
+	})
+	o.RegisterReloadHandler("emails", &settingsReloadHandler{
+		o:      o,
+		reload: reloadSmtp,
+		// the templates are parsed at startup
+		reloadable: func(key string) bool { return key != "templates_pattern" },
+	})
+
+	o.RegisterReloadHandler("quota", &settingsReloadHandler{
+		o: o,
+		reload: func(raw *ini.File) error {
+			next := &Cfg{Raw: raw}
+			next.readQuotaSettings()
+			next.Quota.Enabled = cfg.Quota.Enabled
+			o.quota.Store(&next.Quota)
+			return nil
+		},
+		validate: func(section Section) error {
+			for _, key := range section.Keys() {
+				if key == "enabled" {
+					continue
+				}
+				if _, err := strconv.ParseInt(section.KeyValue(key).Value(), 10, 64); err != nil {
+					return fmt.Errorf("invalid quota %q: %w", key, err)
+				}
+			}
+			return nil
+		},
+		// the quota service is disabled at startup when the quotas are disabled
+		reloadable: func(key string) bool { return key != "enabled" },
+	})
+}
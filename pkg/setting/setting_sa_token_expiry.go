@@ -0,0 +1,33 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type SATokenExpiryNotificationSettings struct {
+	Enabled bool
+	// NotifyBefore is how long before their expiry the owners of the tokens are warned.
+	NotifyBefore time.Duration
+	// CheckInterval is how often the expiring tokens are looked for.
+	CheckInterval time.Duration
+	// NotifyOwners sends the warnings by email to the owners of the service accounts.
+	NotifyOwners bool
+	// WebhookURL receives the warnings of all the tokens when it's set.
+	WebhookURL string
+}
+
+func readSATokenExpiryNotificationSettings(section *ini.Section) SATokenExpiryNotificationSettings {
+	s := SATokenExpiryNotificationSettings{
+		Enabled:       section.Key("token_expiry_notification_enabled").MustBool(false),
+		NotifyBefore:  section.Key("token_expiry_notification_before").MustDuration(7 * 24 * time.Hour),
+		CheckInterval: section.Key("token_expiry_notification_check_interval").MustDuration(time.Hour),
+		NotifyOwners:  section.Key("token_expiry_notification_email_owners").MustBool(true),
+		WebhookURL:    section.Key("token_expiry_notification_webhook_url").MustString(""),
+	}
+	if s.CheckInterval < time.Minute {
+		s.CheckInterval = time.Minute
+	}
+	return s
+}
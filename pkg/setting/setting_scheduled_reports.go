@@ -0,0 +1,27 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type ScheduledReportsSettings struct {
+	Enabled bool
+	// MinInterval is the shortest time between two scheduled runs of a report.
+	MinInterval time.Duration
+	// RenderTimeout is the timeout of the rendering of a report.
+	RenderTimeout time.Duration
+	// RunHistoryRetention is how long the runs of the reports are kept.
+	RunHistoryRetention time.Duration
+}
+
+func readScheduledReportsSettings(iniFile *ini.File) ScheduledReportsSettings {
+	section := iniFile.Section("scheduled_reports")
+	return ScheduledReportsSettings{
+		Enabled:             section.Key("enabled").MustBool(false),
+		MinInterval:         section.Key("min_interval").MustDuration(time.Hour),
+		RenderTimeout:       section.Key("render_timeout").MustDuration(time.Minute),
+		RunHistoryRetention: section.Key("run_history_retention").MustDuration(30 * 24 * time.Hour),
+	}
+}
@@ -0,0 +1,44 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type SignedURLsSettings struct {
+	Enabled bool
+	// AllowedPaths are the prefixes of the paths that can be signed, e.g. /render/d-solo/.
+	AllowedPaths []string
+	// DefaultTTL is how long the URLs are valid when the request doesn't say.
+	DefaultTTL time.Duration
+	// MaxTTL is the longest the URLs can be valid.
+	MaxTTL time.Duration
+	// MaxPerOrgPerHour is the number of URLs an organization can issue per hour. 0 means no limit.
+	MaxPerOrgPerHour int
+}
+
+func readSignedURLsSettings(iniFile *ini.File) SignedURLsSettings {
+	section := iniFile.Section("signed_urls")
+	s := SignedURLsSettings{
+		Enabled:          section.Key("enabled").MustBool(false),
+		DefaultTTL:       section.Key("default_ttl").MustDuration(5 * time.Minute),
+		MaxTTL:           section.Key("max_ttl").MustDuration(time.Hour),
+		MaxPerOrgPerHour: section.Key("max_per_org_per_hour").MustInt(1000),
+	}
+	for _, p := range util.SplitString(section.Key("allowed_paths").MustString("/render/")) {
+		if strings.HasPrefix(p, "/") {
+			s.AllowedPaths = append(s.AllowedPaths, p)
+		}
+	}
+	if s.MaxTTL <= 0 {
+		s.MaxTTL = time.Hour
+	}
+	if s.DefaultTTL <= 0 || s.DefaultTTL > s.MaxTTL {
+		s.DefaultTTL = s.MaxTTL
+	}
+	return s
+}
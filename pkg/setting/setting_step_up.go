@@ -0,0 +1,60 @@
+package setting
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const defaultStepUpOperations = "PUT /api/v1/sso-settings/*, DELETE /api/v1/sso-settings/*, DELETE /api/orgs/*, POST /api/admin/encryption/*"
+
+type StepUpSettings struct {
+	Enabled bool
+	// MaxAge is how long after the users authenticated they can perform the sensitive operations.
+	MaxAge time.Duration
+	// RequireMFA only accepts the authentications asserted by the IdP as multi-factor.
+	RequireMFA bool
+	// MFAMethods are the values of the amr claim of the ID tokens which assert a multi-factor authentication.
+	MFAMethods []string
+	// Operations are the sensitive operations which require a recent authentication.
+	Operations []StepUpOperation
+}
+
+// StepUpOperation is a sensitive operation, matched by the method and the path of its requests.
+type StepUpOperation struct {
+	// Method is the HTTP method of the requests, or * for any method.
+	Method string
+	// Pattern is the pattern of the path, with the syntax of path.Match, e.g. /api/orgs/*.
+	Pattern string
+}
+
+func readStepUpSettings(iniFile *ini.File) StepUpSettings {
+	section := iniFile.Section("auth.step_up")
+	s := StepUpSettings{
+		Enabled:    section.Key("enabled").MustBool(false),
+		MaxAge:     section.Key("max_age").MustDuration(5 * time.Minute),
+		RequireMFA: section.Key("require_mfa").MustBool(false),
+		MFAMethods: util.SplitString(section.Key("mfa_methods").MustString("mfa,otp,hwk,swk,sms")),
+	}
+	if s.MaxAge <= 0 {
+		s.MaxAge = 5 * time.Minute
+	}
+
+	for _, op := range strings.Split(section.Key("operations").MustString(defaultStepUpOperations), ",") {
+		method, pattern, ok := strings.Cut(strings.TrimSpace(op), " ")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || !strings.HasPrefix(pattern, "/") {
+			continue
+		}
+		// skip the invalid patterns, which would never match
+		if _, err := path.Match(pattern, ""); err != nil {
+			continue
+		}
+		s.Operations = append(s.Operations, StepUpOperation{Method: strings.ToUpper(method), Pattern: pattern})
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type TokenExchangeSettings struct {
+	Enabled bool
+	// TokenURL is the OAuth 2.0 token endpoint that supports the token exchange grant (RFC 8693).
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	// Scopes are requested for every exchanged token, in addition to the scopes of the data source.
+	Scopes []string
+	// RefreshBefore is how long before their expiry exchanged tokens are exchanged again.
+	RefreshBefore time.Duration
+	// Timeout is the timeout of the requests to the token endpoint.
+	Timeout time.Duration
+}
+
+func readTokenExchangeSettings(iniFile *ini.File) TokenExchangeSettings {
+	section := iniFile.Section("auth.token_exchange")
+	return TokenExchangeSettings{
+		Enabled:       section.Key("enabled").MustBool(false),
+		TokenURL:      section.Key("token_url").MustString(""),
+		ClientID:      section.Key("client_id").MustString(""),
+		ClientSecret:  section.Key("client_secret").MustString(""),
+		Scopes:        strings.Fields(strings.ReplaceAll(section.Key("scopes").MustString(""), ",", " ")),
+		RefreshBefore: section.Key("refresh_before").MustDuration(30 * time.Second),
+		Timeout:       section.Key("timeout").MustDuration(10 * time.Second),
+	}
+}
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -55,6 +56,7 @@ const (
 	screenshotsMaxCaptureTimeout            = 30 * time.Second
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	screenshotsDefaultCacheTimeBucket       = time.Minute
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -65,6 +67,11 @@ const (
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	enrichmentDefaultTimeout       = 2 * time.Second
+	enrichmentDefaultQueryRange    = 10 * time.Minute
+
+	notificationRateLimitOverflowDrop  = "drop"
+	notificationRateLimitOverflowBatch = "batch"
 )
 
 type UnifiedAlertingSettings struct {
@@ -110,6 +117,10 @@ type UnifiedAlertingSettings struct {
 	StateHistory                  UnifiedAlertingStateHistorySettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
+	Enrichment                    UnifiedAlertingEnrichmentSettings
+	StateExport                   AlertStateExportSettings
+	// NotificationRateLimits are the rate limits of the integrations by contact point type, e.g. slack.
+	NotificationRateLimits map[string]NotificationRateLimit
 
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency   int
@@ -132,6 +143,19 @@ type RecordingRuleSettings struct {
 	Timeout           time.Duration
 }
 
+// AlertStateExportSettings configures the export of the states of the Grafana-managed alert rules as the ALERTS and
+// ALERTS_FOR_STATE time series, written to a Prometheus remote write endpoint.
+type AlertStateExportSettings struct {
+	Enabled           bool
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	CustomHeaders     map[string]string
+	Timeout           time.Duration
+	// ExternalLabels are added to all the exported series.
+	ExternalLabels map[string]string
+}
+
 // RemoteAlertmanagerSettings contains the configuration needed
 // to disable the internal Alertmanager and use an external one instead.
 type RemoteAlertmanagerSettings struct {
@@ -147,6 +171,39 @@ type UnifiedAlertingScreenshotSettings struct {
 	CaptureTimeout             time.Duration
 	MaxConcurrentScreenshots   int64
 	UploadExternalImageStorage bool
+	// CacheTimeBucket is the length of the time buckets the screenshots of a rule and panel are reused within.
+	CacheTimeBucket time.Duration
+	// MaxScreenshotsPerOrgPerHour is the number of screenshots each organization can take per hour. 0 is unlimited.
+	MaxScreenshotsPerOrgPerHour int64
+}
+
+// UnifiedAlertingEnrichmentSettings configures the enrichment stage that adds
+// annotations to alerts before they are handed over to the Alertmanager.
+type UnifiedAlertingEnrichmentSettings struct {
+	Enabled bool
+	// Enrichers is the ordered list of enrichers to run, e.g. "runbook", "webhook".
+	Enrichers []string
+	// Timeout is the maximum time a single enricher can take for a batch of alerts.
+	Timeout            time.Duration
+	RunbookURLTemplate string
+	WebhookURLs        []string
+	// DataSourceUID is the data source queried by the datasource enricher, with the DataSourceQuery template of the
+	// JSON model of the query, over the DataSourceQueryRange before the alert is sent.
+	DataSourceUID        string
+	DataSourceQuery      string
+	DataSourceQueryRange time.Duration
+	// DataSourceAnnotation is the annotation set to the last value returned by the query.
+	DataSourceAnnotation string
+}
+
+// NotificationRateLimit limits the notifications sent by each integration of a contact point type.
+type NotificationRateLimit struct {
+	// Count is the number of notifications that can be sent per Interval.
+	Count    int
+	Interval time.Duration
+	// Batch is true if the alerts of the notifications over the limit are sent in a single digest notification once the
+	// limit allows it, and false if the notifications over the limit are dropped.
+	Batch bool
 }
 
 type UnifiedAlertingReservedLabelSettings struct {
@@ -390,6 +447,14 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfgScreenshots.MaxConcurrentScreenshots = screenshots.Key("max_concurrent_screenshots").MustInt64(screenshotsDefaultMaxConcurrent)
 	uaCfgScreenshots.UploadExternalImageStorage = screenshots.Key("upload_external_image_storage").MustBool(screenshotsDefaultUploadImageStorage)
+	uaCfgScreenshots.CacheTimeBucket = screenshots.Key("cache_time_bucket").MustDuration(screenshotsDefaultCacheTimeBucket)
+	if uaCfgScreenshots.CacheTimeBucket <= 0 {
+		return fmt.Errorf("value of setting 'cache_time_bucket' must be greater than 0")
+	}
+	uaCfgScreenshots.MaxScreenshotsPerOrgPerHour = screenshots.Key("max_screenshots_per_org_per_hour").MustInt64(0)
+	if uaCfgScreenshots.MaxScreenshotsPerOrgPerHour < 0 {
+		return fmt.Errorf("value of setting 'max_screenshots_per_org_per_hour' cannot be negative")
+	}
 	uaCfg.Screenshots = uaCfgScreenshots
 
 	reservedLabels := iniFile.Section("unified_alerting.reserved_labels")
@@ -438,6 +503,48 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
+	enrichment := iniFile.Section("unified_alerting.enrichment")
+	uaCfg.Enrichment = UnifiedAlertingEnrichmentSettings{
+		Enabled:            sectionBool(enrichment, "enabled", false),
+		Enrichers:          util.SplitString(enrichment.Key("enrichers").MustString("")),
+		Timeout:            enrichment.Key("timeout").MustDuration(enrichmentDefaultTimeout),
+		RunbookURLTemplate: enrichment.Key("runbook_url_template").MustString(""),
+		WebhookURLs:        util.SplitString(enrichment.Key("webhook_urls").MustString("")),
+
+		DataSourceUID:        enrichment.Key("datasource_uid").MustString(""),
+		DataSourceQuery:      enrichment.Key("datasource_query").MustString(""),
+		DataSourceQueryRange: enrichment.Key("datasource_query_range").MustDuration(enrichmentDefaultQueryRange),
+		DataSourceAnnotation: enrichment.Key("datasource_annotation").MustString("context_value"),
+	}
+
+	stateExport := iniFile.Section("unified_alerting.state_export")
+	uaCfg.StateExport = AlertStateExportSettings{
+		Enabled:           sectionBool(stateExport, "enabled", false),
+		URL:               stateExport.Key("url").MustString(""),
+		BasicAuthUsername: stateExport.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: stateExport.Key("basic_auth_password").MustString(""),
+		CustomHeaders:     iniFile.Section("unified_alerting.state_export.custom_headers").KeysHash(),
+		Timeout:           stateExport.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+		ExternalLabels:    iniFile.Section("unified_alerting.state_export.external_labels").KeysHash(),
+	}
+	if uaCfg.StateExport.Enabled && uaCfg.StateExport.URL == "" {
+		return fmt.Errorf("the url of the alert state export is required when it is enabled")
+	}
+
+	rateLimits := iniFile.Section("unified_alerting.notification_rate_limits")
+	overflow := rateLimits.Key("overflow").MustString(notificationRateLimitOverflowDrop)
+	uaCfg.NotificationRateLimits = make(map[string]NotificationRateLimit)
+	for _, key := range rateLimits.Keys() {
+		if key.Name() == "overflow" || key.Value() == "" {
+			continue
+		}
+		limit, err := parseNotificationRateLimit(key.Value(), overflow)
+		if err != nil {
+			return fmt.Errorf("invalid notification rate limit of %s: %w", key.Name(), err)
+		}
+		uaCfg.NotificationRateLimits[key.Name()] = limit
+	}
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	uaCfg.StatePeriodicSaveInterval, err = gtime.ParseDuration(valueAsString(ua, "state_periodic_save_interval", (time.Minute * 5).String()))
@@ -463,6 +570,43 @@ func GetAlertmanagerDefaultConfiguration() string {
 	return alertmanagerDefaultConfiguration
 }
 
+// parseNotificationRateLimit parses a rate limit in the format <count>/<interval>, optionally followed by the overflow
+// handling, e.g. 1/10s batch.
+func parseNotificationRateLimit(value string, overflow string) (NotificationRateLimit, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 2 {
+		overflow = fields[1]
+	} else if len(fields) != 1 {
+		return NotificationRateLimit{}, fmt.Errorf("expected <count>/<interval> [drop|batch], got %q", value)
+	}
+	if overflow != notificationRateLimitOverflowDrop && overflow != notificationRateLimitOverflowBatch {
+		return NotificationRateLimit{}, fmt.Errorf("unknown overflow handling %q, expected %s or %s", overflow, notificationRateLimitOverflowDrop, notificationRateLimitOverflowBatch)
+	}
+	count, interval, ok := strings.Cut(fields[0], "/")
+	if !ok {
+		return NotificationRateLimit{}, fmt.Errorf("expected <count>/<interval>, got %q", fields[0])
+	}
+	limit := NotificationRateLimit{Batch: overflow == notificationRateLimitOverflowBatch}
+	var err error
+	if limit.Count, err = strconv.Atoi(count); err != nil || limit.Count <= 0 {
+		return NotificationRateLimit{}, fmt.Errorf("the count must be a positive number, got %q", count)
+	}
+	if limit.Interval, err = gtime.ParseDuration(interval); err != nil || limit.Interval <= 0 {
+		return NotificationRateLimit{}, fmt.Errorf("the interval must be a positive duration, got %q", interval)
+	}
+	return limit, nil
+}
+
+// sectionBool reads a boolean key of the section itself. Section.Key falls back to the key of the parent section,
+// which would enable unified_alerting.state_export or unified_alerting.enrichment with unified_alerting when they
+// are not set.
+func sectionBool(section *ini.Section, name string, defaultVal bool) bool {
+	if !slices.Contains(section.KeyStrings(), name) {
+		return defaultVal
+	}
+	return section.Key(name).MustBool(defaultVal)
+}
+
 func splitTrim(s string, sep string) []string {
 	spl := strings.Split(s, sep)
 	for i := range spl {
@@ -346,3 +346,56 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestNotificationRateLimitSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting.notification_rate_limits")
+	require.NoError(t, err)
+	_, err = section.NewKey("overflow", "batch")
+	require.NoError(t, err)
+	_, err = section.NewKey("slack", "1/10s")
+	require.NoError(t, err)
+	_, err = section.NewKey("email", "5/1m drop")
+	require.NoError(t, err)
+	_, err = section.NewKey("webhook", "")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.Equal(t, map[string]NotificationRateLimit{
+		"slack": {Count: 1, Interval: 10 * time.Second, Batch: true},
+		"email": {Count: 5, Interval: time.Minute, Batch: false},
+	}, cfg.UnifiedAlerting.NotificationRateLimits)
+
+	for _, invalid := range []string{"1", "0/10s", "1/0s", "x/10s", "1/10s digest", "1/10s batch drop"} {
+		t.Run(invalid, func(t *testing.T) {
+			_, err = section.NewKey("slack", invalid)
+			require.NoError(t, err)
+			require.Error(t, cfg.ReadUnifiedAlertingSettings(f))
+		})
+	}
+}
+
+func TestStateExportSettings(t *testing.T) {
+	f := ini.Empty()
+	section, err := f.NewSection("unified_alerting")
+	require.NoError(t, err)
+	_, err = section.NewKey("enabled", "true")
+	require.NoError(t, err)
+	stateExport, err := f.NewSection("unified_alerting.state_export")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.False(t, cfg.UnifiedAlerting.StateExport.Enabled, "the export is not enabled with unified alerting")
+	require.False(t, cfg.UnifiedAlerting.Enrichment.Enabled, "the enrichment is not enabled with unified alerting")
+
+	_, err = stateExport.NewKey("enabled", "true")
+	require.NoError(t, err)
+	require.Error(t, cfg.ReadUnifiedAlertingSettings(f))
+
+	_, err = stateExport.NewKey("url", "http://localhost:9090/api/v1/write")
+	require.NoError(t, err)
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+	require.True(t, cfg.UnifiedAlerting.StateExport.Enabled)
+}
@@ -134,7 +134,7 @@ func NewResourceServer(opts ResourceServerOptions) (ResourceServer, error) {
 		backend:     opts.Backend,
 		index:       opts.Index,
 		diagnostics: opts.Diagnostics,
-		access:      opts.WriteAccess,
+		validator:   &EventValidator{Access: opts.WriteAccess},
 		lifecycle:   opts.Lifecycle,
 		now:         opts.Now,
 		ctx:         ctx,
@@ -150,7 +150,7 @@ type server struct {
 	backend     StorageBackend
 	index       ResourceIndexServer
 	diagnostics DiagnosticsServer
-	access      WriteAccessHooks
+	validator   *EventValidator
 	lifecycle   LifecycleHooks
 	now         func() int64
 
@@ -244,54 +244,9 @@ func (s *server) newEvent(ctx context.Context, user claims.AuthInfo, key *Resour
 		}
 	}
 
-	if key.Namespace != obj.GetNamespace() {
-		return nil, NewBadRequestError("key/namespace do not match")
-	}
-
-	gvk := obj.GetGroupVersionKind()
-	if gvk.Kind == "" {
-		return nil, NewBadRequestError("expecting resources with a kind in the body")
-	}
-	if gvk.Version == "" {
-		return nil, NewBadRequestError("expecting resources with an apiVersion")
-	}
-	if gvk.Group != "" && gvk.Group != key.Group {
-		return nil, NewBadRequestError(
-			fmt.Sprintf("group in key does not match group in the body (%s != %s)", key.Group, gvk.Group),
-		)
-	}
-
-	// This needs to be a create function
-	if key.Name == "" {
-		if obj.GetName() == "" {
-			return nil, NewBadRequestError("missing name")
-		}
-		key.Name = obj.GetName()
-	} else if key.Name != obj.GetName() {
-		return nil, NewBadRequestError(
-			fmt.Sprintf("key/name do not match (key: %s, name: %s)", key.Name, obj.GetName()))
-	}
-	if err := validateName(obj.GetName()); err != nil {
+	if err := s.validator.Validate(ctx, user, key, obj); err != nil {
 		return nil, err
 	}
-
-	folder := obj.GetFolder()
-	if folder != "" {
-		err = s.access.CanWriteFolder(ctx, user, folder)
-		if err != nil {
-			return nil, AsErrorResult(err)
-		}
-	}
-	origin, err := obj.GetOriginInfo()
-	if err != nil {
-		return nil, NewBadRequestError("invalid origin info")
-	}
-	if origin != nil {
-		err = s.access.CanWriteOrigin(ctx, user, origin.Name)
-		if err != nil {
-			return nil, AsErrorResult(err)
-		}
-	}
 	return event, nil
 }
 
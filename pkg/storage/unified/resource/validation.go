@@ -1,7 +1,13 @@
 package resource
 
 import (
+	"context"
+	"fmt"
 	"regexp"
+
+	"github.com/grafana/authlib/claims"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
 )
 
 var validNameCharPattern = `a-zA-Z0-9\-\_\.`
@@ -22,3 +28,60 @@ func validateName(name string) *ErrorResult {
 	// so we will be slightly more lenient than standard k8s
 	return nil
 }
+
+// EventValidator checks that an object can be written with the key: the key must match the object, the
+// name must be valid, and the user must be allowed to write to the folder and the origin of the object.
+// When the key has no name, it is set to the name of the object.
+type EventValidator struct {
+	Access WriteAccessHooks
+}
+
+func (v *EventValidator) Validate(ctx context.Context, user claims.AuthInfo, key *ResourceKey, obj utils.GrafanaMetaAccessor) *ErrorResult {
+	if key.Namespace != obj.GetNamespace() {
+		return NewBadRequestError("key/namespace do not match")
+	}
+
+	gvk := obj.GetGroupVersionKind()
+	if gvk.Kind == "" {
+		return NewBadRequestError("expecting resources with a kind in the body")
+	}
+	if gvk.Version == "" {
+		return NewBadRequestError("expecting resources with an apiVersion")
+	}
+	if gvk.Group != "" && gvk.Group != key.Group {
+		return NewBadRequestError(
+			fmt.Sprintf("group in key does not match group in the body (%s != %s)", key.Group, gvk.Group),
+		)
+	}
+
+	// This needs to be a create function
+	if key.Name == "" {
+		if obj.GetName() == "" {
+			return NewBadRequestError("missing name")
+		}
+		key.Name = obj.GetName()
+	} else if key.Name != obj.GetName() {
+		return NewBadRequestError(
+			fmt.Sprintf("key/name do not match (key: %s, name: %s)", key.Name, obj.GetName()))
+	}
+	if err := validateName(obj.GetName()); err != nil {
+		return err
+	}
+
+	folder := obj.GetFolder()
+	if folder != "" {
+		if err := v.Access.CanWriteFolder(ctx, user, folder); err != nil {
+			return AsErrorResult(err)
+		}
+	}
+	origin, err := obj.GetOriginInfo()
+	if err != nil {
+		return NewBadRequestError("invalid origin info")
+	}
+	if origin != nil {
+		if err := v.Access.CanWriteOrigin(ctx, user, origin.Name); err != nil {
+			return AsErrorResult(err)
+		}
+	}
+	return nil
+}
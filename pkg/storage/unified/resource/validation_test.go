@@ -1,9 +1,15 @@
 package resource
 
 import (
+	"context"
 	"testing"
 
+	"github.com/grafana/authlib/claims"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
 )
 
 func TestNameValidation(t *testing.T) {
@@ -28,3 +34,49 @@ func TestNameValidation(t *testing.T) {
 	require.NotNil(t, validateName("hello="))
 	require.NotNil(t, validateName("hello%"))
 }
+
+func TestEventValidator(t *testing.T) {
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserUID: "u1"}
+	validator := &EventValidator{Access: WriteAccessHooks{
+		Folder: func(ctx context.Context, user claims.AuthInfo, uid string) bool {
+			return uid == "allowed"
+		},
+	}}
+
+	newObj := func(t *testing.T, name, folder string) utils.GrafanaMetaAccessor {
+		t.Helper()
+		tmp := &unstructured.Unstructured{}
+		tmp.SetAPIVersion("dashboard.grafana.app/v0alpha1")
+		tmp.SetKind("Dashboard")
+		tmp.SetNamespace("default")
+		tmp.SetName(name)
+		obj, err := utils.MetaAccessor(tmp)
+		require.NoError(t, err)
+		obj.SetFolder(folder)
+		return obj
+	}
+
+	t.Run("sets the name of the key", func(t *testing.T) {
+		key := &ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards"}
+		require.Nil(t, validator.Validate(context.Background(), user, key, newObj(t, "abc", "allowed")))
+		require.Equal(t, "abc", key.Name)
+	})
+
+	t.Run("rejects a key that does not match", func(t *testing.T) {
+		key := &ResourceKey{Namespace: "other", Group: "dashboard.grafana.app", Resource: "dashboards"}
+		require.NotNil(t, validator.Validate(context.Background(), user, key, newObj(t, "abc", "")))
+
+		key = &ResourceKey{Namespace: "default", Group: "folder.grafana.app", Resource: "dashboards"}
+		require.NotNil(t, validator.Validate(context.Background(), user, key, newObj(t, "abc", "")))
+	})
+
+	t.Run("rejects invalid names", func(t *testing.T) {
+		key := &ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards"}
+		require.NotNil(t, validator.Validate(context.Background(), user, key, newObj(t, "hello world", "")))
+	})
+
+	t.Run("checks access to the folder", func(t *testing.T) {
+		key := &ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards"}
+		require.NotNil(t, validator.Validate(context.Background(), user, key, newObj(t, "abc", "denied")))
+	})
+}
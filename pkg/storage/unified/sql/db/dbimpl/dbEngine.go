@@ -52,7 +52,7 @@ func getEngineMySQL(getter *sectionGetter, tracer tracing.Tracer) (*xorm.Engine,
 
 	// FIXME: get rid of xorm
 	// TODO figure out why wrapping the db driver with hooks causes mysql errors when writing
-	//driverName := sqlstore.WrapDatabaseDriverWithHooks(db.DriverMySQL, tracer)
+	//driverName := sqlstore.WrapDatabaseDriverWithHooks(db.DriverMySQL, tracer, 0)
 	engine, err := xorm.NewEngine(db.DriverMySQL, config.FormatDSN())
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
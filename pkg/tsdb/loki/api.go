@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/promlib/converter"
 	"github.com/grafana/grafana/pkg/tsdb/loki/instrumentation"
+	"github.com/grafana/grafana/pkg/tsdb/queryerror"
 )
 
 type LokiAPI struct {
@@ -191,6 +192,10 @@ func (api *LokiAPI) DataQuery(ctx context.Context, query lokiQuery, responseOpts
 		if errors.Is(err, syscall.ECONNREFUSED) {
 			res.ErrorSource = backend.ErrorSourceDownstream
 		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			res.Error = queryerror.New(queryerror.TypeDownstreamTimeout, err)
+			res.ErrorSource = backend.ErrorSourceDownstream
+		}
 		return &res, nil
 	}
 
@@ -205,7 +210,8 @@ func (api *LokiAPI) DataQuery(ctx context.Context, query lokiQuery, responseOpts
 	if resp.StatusCode/100 != 2 {
 		err := readLokiError(resp.Body)
 		res := backend.DataResponse{
-			Error:       err,
+			Error:       queryerror.New(queryerror.FromHTTPStatus(resp.StatusCode), err),
+			Status:      backend.Status(resp.StatusCode),
 			ErrorSource: backend.ErrorSourceFromHTTPStatus(resp.StatusCode),
 		}
 		lp = append(lp, "status", "error", "error", err, "statusSource", res.ErrorSource)
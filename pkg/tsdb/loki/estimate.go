@@ -0,0 +1,131 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// estimateRequest is the body of a query/estimate resource call.
+type estimateRequest struct {
+	Queries []estimateQuery `json:"queries"`
+}
+
+type estimateQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType,omitempty"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	IntervalMS    int64           `json:"intervalMs"`
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Model         json.RawMessage `json:"model"`
+}
+
+type estimateResponse struct {
+	Estimates []estimate `json:"estimates"`
+}
+
+type estimate struct {
+	RefID string `json:"refId"`
+	// Bytes is the size of the chunks the query has to read
+	Bytes int64  `json:"bytes"`
+	Error string `json:"error,omitempty"`
+}
+
+// indexStats is the response of Loki's index stats endpoint.
+type indexStats struct {
+	Streams int64 `json:"streams"`
+	Chunks  int64 `json:"chunks"`
+	Bytes   int64 `json:"bytes"`
+	Entries int64 `json:"entries"`
+}
+
+// estimateQueries returns the number of bytes every query of the request has to read, as reported by the index stats of Loki.
+func estimateQueries(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender, dsInfo *datasourceInfo, plog log.Logger, tracer tracing.Tracer) error {
+	estimateReq := estimateRequest{}
+	if err := json.Unmarshal(req.Body, &estimateReq); err != nil {
+		return sender.Send(&backend.CallResourceResponse{
+			Status: http.StatusBadRequest,
+			Body:   []byte(fmt.Sprintf(`{"message": %q}`, err.Error())),
+		})
+	}
+
+	ctx, span := tracer.Start(ctx, "datasource.loki.estimateQueries", trace.WithAttributes(
+		attribute.Int("queries", len(estimateReq.Queries)),
+	))
+	defer span.End()
+
+	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, plog, tracer, false)
+	resp := estimateResponse{Estimates: make([]estimate, 0, len(estimateReq.Queries))}
+	for _, eq := range estimateReq.Queries {
+		e := estimate{RefID: eq.RefID}
+		stats, err := estimateQuery(ctx, api, eq)
+		if err != nil {
+			e.Error = err.Error()
+		} else {
+			e.Bytes = stats.Bytes
+		}
+		resp.Estimates = append(resp.Estimates, e)
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"content-type": {"application/json"}},
+		Body:    body,
+	})
+}
+
+func estimateQuery(ctx context.Context, api *LokiAPI, eq estimateQuery) (indexStats, error) {
+	queries, err := parseQuery(&backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:         eq.RefID,
+			QueryType:     eq.QueryType,
+			MaxDataPoints: eq.MaxDataPoints,
+			Interval:      time.Duration(eq.IntervalMS) * time.Millisecond,
+			TimeRange:     backend.TimeRange{From: eq.From, To: eq.To},
+			JSON:          eq.Model,
+		}},
+	})
+	if err != nil {
+		return indexStats{}, err
+	}
+	query := queries[0]
+
+	params := url.Values{}
+	params.Set("query", query.Expr)
+	params.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+
+	rawResp, err := api.RawQuery(ctx, "/loki/api/v1/index/stats?"+params.Encode())
+	if err != nil {
+		return indexStats{}, err
+	}
+	if rawResp.Status/100 != 2 {
+		lokiErr := lokiResponseError{}
+		if err := json.Unmarshal(rawResp.Body, &lokiErr); err != nil || lokiErr.Message == "" {
+			return indexStats{}, fmt.Errorf("index stats request failed with status %d", rawResp.Status)
+		}
+		return indexStats{}, fmt.Errorf("index stats request failed: %s", lokiErr.Message)
+	}
+
+	stats := indexStats{}
+	if err := json.Unmarshal(rawResp.Body, &stats); err != nil {
+		return indexStats{}, fmt.Errorf("failed to parse index stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,76 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestEstimateQueries(t *testing.T) {
+	to := time.Unix(1700000000, 0).UTC()
+	body, err := json.Marshal(estimateRequest{Queries: []estimateQuery{
+		{
+			RefID:      "A",
+			IntervalMS: 1000,
+			From:       to.Add(-time.Hour),
+			To:         to,
+			Model:      json.RawMessage(`{"expr": "{job=\"api\"} |= \"error\"", "queryType": "range"}`),
+		},
+	}})
+	require.NoError(t, err)
+
+	run := func(t *testing.T, statusCode int, responseBody string) (estimateResponse, *http.Request) {
+		t.Helper()
+		var lokiReq *http.Request
+		dsInfo := &datasourceInfo{
+			URL: "http://localhost:3100",
+			HTTPClient: &http.Client{Transport: &mockedRoundTripper{
+				statusCode:    statusCode,
+				contentType:   "application/json",
+				responseBytes: []byte(responseBody),
+				requestCallback: func(req *http.Request) {
+					lokiReq = req
+				},
+			}},
+		}
+
+		var sent *backend.CallResourceResponse
+		err := estimateQueries(context.Background(), &backend.CallResourceRequest{
+			Path:   "query/estimate",
+			Method: http.MethodPost,
+			Body:   body,
+		}, backend.CallResourceResponseSenderFunc(func(res *backend.CallResourceResponse) error {
+			sent = res
+			return nil
+		}), dsInfo, backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sent.Status)
+
+		resp := estimateResponse{}
+		require.NoError(t, json.Unmarshal(sent.Body, &resp))
+		require.Len(t, resp.Estimates, 1)
+		return resp, lokiReq
+	}
+
+	t.Run("returns the bytes of the index stats", func(t *testing.T) {
+		resp, lokiReq := run(t, http.StatusOK, `{"streams": 2, "chunks": 10, "bytes": 4096, "entries": 100}`)
+		require.Equal(t, "/loki/api/v1/index/stats", lokiReq.URL.Path)
+		require.Equal(t, `{job="api"} |= "error"`, lokiReq.URL.Query().Get("query"))
+		require.Equal(t, "1699996400000000000", lokiReq.URL.Query().Get("start"))
+		require.Equal(t, "1700000000000000000", lokiReq.URL.Query().Get("end"))
+		require.Equal(t, estimate{RefID: "A", Bytes: 4096}, resp.Estimates[0])
+	})
+
+	t.Run("reports errors of Loki", func(t *testing.T) {
+		resp, _ := run(t, http.StatusBadRequest, `{"message": "parse error"}`)
+		require.Equal(t, "A", resp.Estimates[0].RefID)
+		require.Contains(t, resp.Estimates[0].Error, "parse error")
+	})
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/queryerror"
 )
 
 // NOTE: in these tests there several different json-content-types.
@@ -130,6 +132,8 @@ func TestErrorResponse(t *testing.T) {
 			require.Len(t, dr.Frames, 0)
 			require.Equal(t, dr.Error.Error(), test.errorMessage)
 			require.Equal(t, dr.ErrorSource, backend.ErrorSourceDownstream)
+			require.Equal(t, backend.StatusBadRequest, dr.Status)
+			require.Equal(t, queryerror.TypeBadQuery, queryerror.Classify(*dr))
 		})
 	}
 }
@@ -139,31 +143,55 @@ func TestErrorsFromResponseCodes(t *testing.T) {
 		name        string
 		statusCode  int
 		errorSource backend.ErrorSource
+		errorType   queryerror.Type
 	}{
 		{
 			name:        "parse response with status code 400 into correct error",
 			statusCode:  400,
 			errorSource: backend.ErrorSourceDownstream,
+			errorType:   queryerror.TypeBadQuery,
+		},
+		{
+			name:        "parse response with status code 401 into correct error",
+			statusCode:  401,
+			errorSource: backend.ErrorSourceDownstream,
+			errorType:   queryerror.TypeAuth,
 		},
 		{
 			name:        "parse response with status code 406 into correct error",
 			statusCode:  406,
 			errorSource: backend.ErrorSourcePlugin,
+			errorType:   queryerror.TypeBadQuery,
 		},
 		{
 			name:        "parse response with status code 413 into correct error",
 			statusCode:  413,
 			errorSource: backend.ErrorSourcePlugin,
+			errorType:   queryerror.TypeBadQuery,
+		},
+		{
+			name:        "parse response with status code 429 into correct error",
+			statusCode:  429,
+			errorSource: backend.ErrorSourceDownstream,
+			errorType:   queryerror.TypeRateLimited,
 		},
 		{
 			name:        "parse response with status code 500 into correct error",
 			statusCode:  500,
 			errorSource: backend.ErrorSourceDownstream,
+			errorType:   queryerror.TypeInternal,
 		},
 		{
 			name:        "parse response with status code 501 into correct error",
 			statusCode:  501,
 			errorSource: backend.ErrorSourcePlugin,
+			errorType:   queryerror.TypeInternal,
+		},
+		{
+			name:        "parse response with status code 504 into correct error",
+			statusCode:  504,
+			errorSource: backend.ErrorSourceDownstream,
+			errorType:   queryerror.TypeDownstreamTimeout,
 		},
 	}
 
@@ -176,6 +204,7 @@ func TestErrorsFromResponseCodes(t *testing.T) {
 			require.Len(t, dr.Frames, 0)
 			require.Equal(t, dr.Error.Error(), errorString)
 			require.Equal(t, dr.ErrorSource, test.errorSource)
+			require.Equal(t, test.errorType, queryerror.Classify(*dr))
 		})
 	}
 }
@@ -21,11 +21,13 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient/tunnel"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
+	"github.com/grafana/grafana/pkg/util/proxyutil"
 )
 
 type Service struct {
@@ -59,6 +61,20 @@ var (
 	panelTitleHeader     = "X-Panel-Title"
 )
 
+// dashboardPanelHeadersPolicy forwards the titles of the dashboard and the panel a query comes from.
+var dashboardPanelHeadersPolicy = mustForwardHeadersPolicy([]proxyutil.ForwardHeaderRule{
+	{Header: dashboardTitleHeader},
+	{Header: panelTitleHeader},
+})
+
+func mustForwardHeadersPolicy(rules []proxyutil.ForwardHeaderRule) *proxyutil.ForwardHeadersPolicy {
+	policy, err := proxyutil.NewForwardHeadersPolicy(rules)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
@@ -66,6 +82,11 @@ type datasourceInfo struct {
 	// open streams
 	streams   map[string]data.FrameJSONCache
 	streamsMu sync.RWMutex
+
+	tails      *tailHub
+	tailHeader http.Header
+
+	querySplit querySplitSettings
 }
 
 type QueryJSONModel struct {
@@ -92,17 +113,33 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, err
 		}
 		opts.ForwardHTTPHeaders = true
+		if err := tunnel.Configure(settings, &opts); err != nil {
+			return nil, err
+		}
 
 		client, err := httpClientProvider.New(opts)
 		if err != nil {
 			return nil, err
 		}
 
+		tailHeader, err := tailHeaders(settings)
+		if err != nil {
+			return nil, err
+		}
+
+		querySplit, err := readQuerySplitSettings(settings)
+		if err != nil {
+			return nil, err
+		}
+
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
 			streams:    make(map[string]data.FrameJSONCache),
+			tailHeader: tailHeader,
+			querySplit: querySplit,
 		}
+		model.tails = newTailHub(model.dialTail)
 		return model, nil
 	}
 }
@@ -114,6 +151,9 @@ func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceReq
 		logger.Error("Failed to get data source info", "error", err)
 		return err
 	}
+	if req.Path == "query/estimate" && req.Method == http.MethodPost {
+		return estimateQueries(ctx, req, sender, dsInfo, logger, s.tracer)
+	}
 	return callResource(ctx, req, sender, dsInfo, logger, s.tracer)
 }
 
@@ -177,14 +217,9 @@ func (s *Service) applyHeaders(ctx context.Context, req backend.ForwardHTTPHeade
 		return
 	}
 
-	var hList = []string{dashboardTitleHeader, panelTitleHeader}
-
-	for _, hName := range hList {
-		hVal := reqCtx.Req.Header.Get(hName)
-		if hVal == "" {
-			continue
-		}
-		req.SetHTTPHeader(hName, hVal)
+	data := proxyutil.NewForwardHeadersData(ctx, reqCtx.Req.Header, reqCtx.SignedInUser, "")
+	if err := dashboardPanelHeadersPolicy.Apply(data, req.SetHTTPHeader); err != nil {
+		s.logger.FromContext(ctx).Warn("Failed to forward dashboard and panel headers", "error", err)
 	}
 }
 
@@ -217,7 +252,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, dsInfo.querySplit, responseOpts, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -226,7 +261,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, dsInfo.querySplit, responseOpts, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -234,7 +269,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, querySplit querySplitSettings, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -247,7 +282,7 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	queryRes, err := runQuery(ctx, api, query, responseOpts, plog)
+	queryRes, err := runSplitQuery(ctx, api, query, querySplit, responseOpts, plog)
 	if queryRes == nil {
 		// we always want to return a backend.DataResponse object, even if we received just an error
 		queryRes = &backend.DataResponse{}
@@ -0,0 +1,188 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const defaultQuerySplitMaxParallel = 4
+
+// querySplitSettings controls how long range queries are split into shorter queries that run in parallel.
+// Splitting is disabled when Duration is zero.
+type querySplitSettings struct {
+	Duration    time.Duration
+	MaxParallel int
+}
+
+func readQuerySplitSettings(settings backend.DataSourceInstanceSettings) (querySplitSettings, error) {
+	split := querySplitSettings{MaxParallel: defaultQuerySplitMaxParallel}
+	if len(settings.JSONData) == 0 {
+		return split, nil
+	}
+
+	var jsonData struct {
+		QuerySplitDuration    string `json:"querySplitDuration"`
+		QuerySplitMaxParallel int    `json:"querySplitMaxParallel"`
+	}
+	if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+		return split, fmt.Errorf("error reading settings: %w", err)
+	}
+	if jsonData.QuerySplitDuration != "" {
+		d, err := gtime.ParseDuration(jsonData.QuerySplitDuration)
+		if err != nil {
+			return split, fmt.Errorf("invalid querySplitDuration: %w", err)
+		}
+		if d < 0 {
+			return split, fmt.Errorf("invalid querySplitDuration: %s", jsonData.QuerySplitDuration)
+		}
+		split.Duration = d
+	}
+	if jsonData.QuerySplitMaxParallel > 0 {
+		split.MaxParallel = jsonData.QuerySplitMaxParallel
+	}
+	return split, nil
+}
+
+// splitQuery splits a range query into queries of at most duration, in the order their results are returned in.
+// The duration is rounded up to a multiple of the step, so that every part of a metric query is evaluated at the
+// same timestamps as the whole query.
+func splitQuery(query lokiQuery, duration time.Duration) []lokiQuery {
+	if query.QueryType != QueryTypeRange || duration <= 0 || query.End.Sub(query.Start) <= duration {
+		return []lokiQuery{query}
+	}
+	if query.Step > 0 {
+		duration = (duration + query.Step - 1) / query.Step * query.Step
+	}
+
+	var parts []lokiQuery
+	for start := query.Start; start.Before(query.End); start = start.Add(duration) {
+		part := query
+		part.Start = start
+		part.End = start.Add(duration)
+		if part.End.After(query.End) {
+			part.End = query.End
+		}
+		parts = append(parts, part)
+	}
+
+	if query.Direction == DirectionBackward {
+		slices.Reverse(parts)
+	}
+	return parts
+}
+
+// runSplitQuery runs the parts of a split query in parallel and merges their frames.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, split querySplitSettings, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	parts := splitQuery(*query, split.Duration)
+	if len(parts) == 1 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	plog.Debug("Splitting query", "parts", len(parts), "splitDuration", split.Duration, "maxParallel", split.MaxParallel)
+
+	var (
+		responses = make([]*backend.DataResponse, len(parts))
+		failedMu  sync.Mutex
+		failed    *backend.DataResponse
+	)
+	err := concurrency.ForEachJob(ctx, len(parts), split.MaxParallel, func(ctx context.Context, idx int) error {
+		res, err := runQuery(ctx, api, &parts[idx], responseOpts, plog)
+		if err != nil {
+			failedMu.Lock()
+			defer failedMu.Unlock()
+			if failed == nil {
+				failed = res
+			}
+			return err
+		}
+		responses[idx] = res
+		return nil
+	})
+	if err != nil {
+		return failed, err
+	}
+
+	return mergeSplitResponses(query, responses), nil
+}
+
+// mergeSplitResponses appends the rows of the frames of the same series, in the order of the responses. The
+// meta of a merged frame is the one of its first part.
+func mergeSplitResponses(query *lokiQuery, responses []*backend.DataResponse) *backend.DataResponse {
+	merged := &backend.DataResponse{}
+	byKey := map[string]*data.Frame{}
+	for _, res := range responses {
+		for _, frame := range res.Frames {
+			key := frameKey(frame)
+			existing, ok := byKey[key]
+			if !ok {
+				byKey[key] = frame
+				merged.Frames = append(merged.Frames, frame)
+				continue
+			}
+			appendFrameRows(existing, frame)
+		}
+	}
+
+	if query.MaxLines > 0 {
+		for _, frame := range merged.Frames {
+			if isLogsFrame(frame) && frame.Rows() > query.MaxLines {
+				limitFrameRows(frame, query.MaxLines)
+			}
+		}
+	}
+	return merged
+}
+
+func frameKey(frame *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		fmt.Fprintf(&b, "|%s:%s:%s", field.Name, field.Type(), field.Labels)
+	}
+	return b.String()
+}
+
+func appendFrameRows(frame *data.Frame, part *data.Frame) {
+	start := 0
+	// the parts of a metric query share the boundary timestamp
+	if !isLogsFrame(frame) && frame.Rows() > 0 && part.Rows() > 0 {
+		last, ok := frame.Fields[0].At(frame.Rows() - 1).(time.Time)
+		if first, ok2 := part.Fields[0].At(0).(time.Time); ok && ok2 && last.Equal(first) {
+			start = 1
+		}
+	}
+	for i, field := range frame.Fields {
+		for row := start; row < part.Rows(); row++ {
+			field.Append(part.Fields[i].At(row))
+		}
+	}
+}
+
+func limitFrameRows(frame *data.Frame, rows int) {
+	for i, field := range frame.Fields {
+		limited := data.NewFieldFromFieldType(field.Type(), rows)
+		limited.Name = field.Name
+		limited.Labels = field.Labels
+		limited.Config = field.Config
+		for row := 0; row < rows; row++ {
+			limited.Set(row, field.At(row))
+		}
+		frame.Fields[i] = limited
+	}
+}
+
+// isLogsFrame follows adjustFrame: metric frames have a float64 value as their second field.
+func isLogsFrame(frame *data.Frame) bool {
+	return len(frame.Fields) > 1 && frame.Fields[1].Type() != data.FieldTypeFloat64
+}
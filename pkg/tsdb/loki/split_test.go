@@ -0,0 +1,113 @@
+package loki
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadQuerySplitSettings(t *testing.T) {
+	t.Run("splitting is disabled by default", func(t *testing.T) {
+		split, err := readQuerySplitSettings(backend.DataSourceInstanceSettings{JSONData: []byte(`{}`)})
+		require.NoError(t, err)
+		require.Equal(t, querySplitSettings{MaxParallel: defaultQuerySplitMaxParallel}, split)
+	})
+
+	t.Run("reads the split duration and parallelism", func(t *testing.T) {
+		split, err := readQuerySplitSettings(backend.DataSourceInstanceSettings{
+			JSONData: []byte(`{"querySplitDuration":"1d","querySplitMaxParallel":8}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, querySplitSettings{Duration: 24 * time.Hour, MaxParallel: 8}, split)
+	})
+
+	t.Run("rejects an invalid split duration", func(t *testing.T) {
+		_, err := readQuerySplitSettings(backend.DataSourceInstanceSettings{
+			JSONData: []byte(`{"querySplitDuration":"daily"}`),
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestSplitQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("does not split instant queries and short ranges", func(t *testing.T) {
+		instant := lokiQuery{QueryType: QueryTypeInstant, Start: start, End: start.Add(72 * time.Hour)}
+		require.Len(t, splitQuery(instant, 24*time.Hour), 1)
+
+		short := lokiQuery{QueryType: QueryTypeRange, Start: start, End: start.Add(12 * time.Hour)}
+		require.Len(t, splitQuery(short, 24*time.Hour), 1)
+	})
+
+	t.Run("splits on multiples of the step", func(t *testing.T) {
+		query := lokiQuery{QueryType: QueryTypeRange, Direction: DirectionForward, Step: 7 * time.Hour, Start: start, End: start.Add(30 * time.Hour)}
+		parts := splitQuery(query, 10*time.Hour)
+		require.Len(t, parts, 3)
+		require.Equal(t, start, parts[0].Start)
+		require.Equal(t, start.Add(14*time.Hour), parts[0].End)
+		require.Equal(t, start.Add(14*time.Hour), parts[1].Start)
+		require.Equal(t, start.Add(28*time.Hour), parts[2].Start)
+		require.Equal(t, start.Add(30*time.Hour), parts[2].End)
+	})
+
+	t.Run("returns the newest part first for backward queries", func(t *testing.T) {
+		query := lokiQuery{QueryType: QueryTypeRange, Direction: DirectionBackward, Start: start, End: start.Add(48 * time.Hour)}
+		parts := splitQuery(query, 24*time.Hour)
+		require.Len(t, parts, 2)
+		require.Equal(t, start.Add(24*time.Hour), parts[0].Start)
+		require.Equal(t, start, parts[1].Start)
+	})
+}
+
+func TestMergeSplitResponses(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("merges the series of metric queries without the shared boundary", func(t *testing.T) {
+		metricFrame := func(name string, times []time.Time, values []float64) *data.Frame {
+			return data.NewFrame(name,
+				data.NewField("Time", nil, times),
+				data.NewField("Value", data.Labels{"job": name}, values),
+			)
+		}
+		res := mergeSplitResponses(&lokiQuery{}, []*backend.DataResponse{
+			{Frames: data.Frames{
+				metricFrame("a", []time.Time{t0, t0.Add(time.Hour)}, []float64{1, 2}),
+			}},
+			{Frames: data.Frames{
+				metricFrame("a", []time.Time{t0.Add(time.Hour), t0.Add(2 * time.Hour)}, []float64{2, 3}),
+				metricFrame("b", []time.Time{t0.Add(2 * time.Hour)}, []float64{4}),
+			}},
+		})
+
+		require.Len(t, res.Frames, 2)
+		require.Equal(t, 3, res.Frames[0].Rows())
+		require.Equal(t, 3.0, res.Frames[0].Fields[1].At(2))
+		require.Equal(t, "b", res.Frames[1].Name)
+	})
+
+	t.Run("limits the merged log lines", func(t *testing.T) {
+		logsFrame := func(lines ...string) *data.Frame {
+			times := make([]time.Time, len(lines))
+			for i := range lines {
+				times[i] = t0.Add(time.Duration(i) * time.Minute)
+			}
+			return data.NewFrame("",
+				data.NewField("labels", nil, make([]string, len(lines))),
+				data.NewField("Time", nil, times),
+				data.NewField("Line", nil, lines),
+			)
+		}
+		res := mergeSplitResponses(&lokiQuery{MaxLines: 3}, []*backend.DataResponse{
+			{Frames: data.Frames{logsFrame("a", "b")}},
+			{Frames: data.Frames{logsFrame("c", "d")}},
+		})
+
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 3, res.Frames[0].Rows())
+		require.Equal(t, "c", res.Frames[0].Fields[2].At(2))
+	})
+}
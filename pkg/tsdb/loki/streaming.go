@@ -2,15 +2,9 @@ package loki
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/url"
-	"os"
-	"os/signal"
 	"strings"
-	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -34,7 +28,7 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 	if err != nil {
 		return nil, err
 	}
-	if query.Expr != nil {
+	if query.Expr == nil {
 		return &backend.SubscribeStreamResponse{
 			Status: backend.SubscribeStreamStatusNotFound,
 		}, fmt.Errorf("missing expr in channel (subscribe)")
@@ -58,7 +52,8 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 	}, err
 }
 
-// Single instance for each channel (results are shared with all listeners)
+// Single instance for each channel (results are shared with all listeners).
+// Channels tailing the same query share one connection to Loki.
 func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
 	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
 	if err != nil {
@@ -69,101 +64,51 @@ func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest,
 	if err != nil {
 		return err
 	}
-	if query.Expr != nil {
-		return fmt.Errorf("missing expr in cuannel")
+	if query.Expr == nil {
+		return fmt.Errorf("missing expr in channel")
 	}
 
 	logger := s.logger.FromContext(ctx)
-	count := int64(0)
 
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-
-	params := url.Values{}
-	params.Add("query", *query.Expr)
-
-	wsurl, _ := url.Parse(dsInfo.URL)
-
-	wsurl.Path = "/loki/api/v2alpha/tail"
+	prev := data.FrameJSONCache{}
+	send := func(frame *data.Frame) error {
+		next, err := data.FrameToJSONCache(frame)
+		if err != nil {
+			return err
+		}
+		if next.SameSchema(&prev) {
+			err = sender.SendBytes(next.Bytes(data.IncludeDataOnly))
+		} else {
+			err = sender.SendFrame(frame, data.IncludeAll)
+		}
+		prev = next
 
-	if wsurl.Scheme == "https" {
-		wsurl.Scheme = "wss"
-	} else {
-		wsurl.Scheme = "ws"
+		// Cache the initial data
+		dsInfo.streamsMu.Lock()
+		dsInfo.streams[req.Path] = prev
+		dsInfo.streamsMu.Unlock()
+		return err
 	}
-	wsurl.RawQuery = params.Encode()
 
-	logger.Info("Connecting to websocket", "url", wsurl)
-	c, r, err := websocket.DefaultDialer.Dial(wsurl.String(), nil)
+	done, unsubscribe, err := dsInfo.tails.subscribe(ctx, logger, *query.Expr, send)
 	if err != nil {
-		logger.Error("Error connecting to websocket", "err", err)
-		return fmt.Errorf("error connecting to websocket")
+		logger.Error("Error connecting to websocket", "error", err)
+		return err
 	}
-
 	defer func() {
+		unsubscribe()
 		dsInfo.streamsMu.Lock()
 		delete(dsInfo.streams, req.Path)
 		dsInfo.streamsMu.Unlock()
-		if r != nil {
-			_ = r.Body.Close()
-		}
-		err = c.Close()
-		logger.Error("Closing loki websocket", "err", err)
 	}()
 
-	prev := data.FrameJSONCache{}
-
-	// Read all messages
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		for {
-			_, message, err := c.ReadMessage()
-			if err != nil {
-				logger.Error("Websocket read:", "err", err)
-				return
-			}
-
-			frame := &data.Frame{}
-			err = json.Unmarshal(message, &frame)
-
-			if err == nil && frame != nil {
-				next, _ := data.FrameToJSONCache(frame)
-				if next.SameSchema(&prev) {
-					err = sender.SendBytes(next.Bytes(data.IncludeDataOnly))
-				} else {
-					err = sender.SendFrame(frame, data.IncludeAll)
-				}
-				prev = next
-
-				// Cache the initial data
-				dsInfo.streamsMu.Lock()
-				dsInfo.streams[req.Path] = prev
-				dsInfo.streamsMu.Unlock()
-			}
-
-			if err != nil {
-				logger.Error("Websocket write:", "err", err, "raw", message)
-				return
-			}
-		}
-	}()
-
-	ticker := time.NewTicker(time.Second * 60) // .Step)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-done:
-			logger.Info("Socket done")
-			return nil
-		case <-ctx.Done():
-			logger.Info("Stop streaming (context canceled)")
-			return nil
-		case t := <-ticker.C:
-			count++
-			logger.Error("Loki websocket ping?", "time", t, "count", count)
-		}
+	select {
+	case <-done:
+		logger.Info("Socket done")
+		return nil
+	case <-ctx.Done():
+		logger.Info("Stop streaming (context canceled)")
+		return nil
 	}
 }
 
@@ -0,0 +1,189 @@
+package loki
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const tailPath = "/loki/api/v2alpha/tail"
+
+// tailHub multiplexes the live tail streams of a data source, so that all panels tailing
+// the same query share a single upstream websocket connection to Loki.
+type tailHub struct {
+	dial func(ctx context.Context, expr string) (*websocket.Conn, error)
+
+	mu    sync.Mutex
+	tails map[string]*tail
+}
+
+type tail struct {
+	conn        *websocket.Conn
+	subscribers map[*tailSubscriber]struct{}
+	done        chan struct{}
+}
+
+type tailSubscriber struct {
+	send func(frame *data.Frame) error
+}
+
+func newTailHub(dial func(ctx context.Context, expr string) (*websocket.Conn, error)) *tailHub {
+	return &tailHub{
+		dial:  dial,
+		tails: make(map[string]*tail),
+	}
+}
+
+// subscribe calls send for every frame Loki returns for expr. It opens the upstream connection if this is
+// the first subscriber of expr. The returned channel is closed when the upstream connection is closed, and
+// unsubscribe closes the upstream connection once the last subscriber leaves.
+func (h *tailHub) subscribe(ctx context.Context, logger log.Logger, expr string, send func(frame *data.Frame) error) (<-chan struct{}, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.tails[expr]
+	if !ok {
+		conn, err := h.dial(ctx, expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		t = &tail{
+			conn:        conn,
+			subscribers: make(map[*tailSubscriber]struct{}),
+			done:        make(chan struct{}),
+		}
+		h.tails[expr] = t
+		go h.read(logger, expr, t)
+	}
+
+	sub := &tailSubscriber{send: send}
+	t.subscribers[sub] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(t.subscribers, sub)
+		if len(t.subscribers) > 0 {
+			return
+		}
+		if h.tails[expr] == t {
+			delete(h.tails, expr)
+		}
+		if err := t.conn.Close(); err != nil {
+			logger.Debug("Error closing loki websocket", "error", err)
+		}
+	}
+	return t.done, unsubscribe, nil
+}
+
+func (h *tailHub) read(logger log.Logger, expr string, t *tail) {
+	defer func() {
+		h.mu.Lock()
+		if h.tails[expr] == t {
+			delete(h.tails, expr)
+		}
+		h.mu.Unlock()
+		close(t.done)
+	}()
+
+	for {
+		_, message, err := t.conn.ReadMessage()
+		if err != nil {
+			logger.Debug("Loki websocket closed", "error", err)
+			return
+		}
+
+		frame := &data.Frame{}
+		if err := json.Unmarshal(message, &frame); err != nil || frame == nil {
+			logger.Error("Error parsing loki websocket message", "error", err)
+			continue
+		}
+
+		h.mu.Lock()
+		subscribers := make([]*tailSubscriber, 0, len(t.subscribers))
+		for sub := range t.subscribers {
+			subscribers = append(subscribers, sub)
+		}
+		h.mu.Unlock()
+
+		for _, sub := range subscribers {
+			if err := sub.send(frame); err != nil {
+				logger.Error("Error sending loki tail frame", "error", err)
+			}
+		}
+	}
+}
+
+// dialTail opens a websocket connection to the tail endpoint of the data source.
+func (dsInfo *datasourceInfo) dialTail(ctx context.Context, expr string) (*websocket.Conn, error) {
+	u, err := tailURL(dsInfo.URL, expr)
+	if err != nil {
+		return nil, err
+	}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), dsInfo.tailHeader)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to websocket: %w", err)
+	}
+	return conn, nil
+}
+
+// tailURL returns the websocket URL of the tail endpoint of the Loki instance at baseURL.
+func tailURL(baseURL string, expr string) (*url.URL, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join("/", u.Path, tailPath)
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	params := url.Values{}
+	params.Add("query", expr)
+	u.RawQuery = params.Encode()
+	return u, nil
+}
+
+// tailHeaders returns the authentication and custom headers configured for the data source.
+// The websocket connection does not go through the HTTP client of the data source, so they
+// have to be added explicitly.
+func tailHeaders(settings backend.DataSourceInstanceSettings) (http.Header, error) {
+	header := http.Header{}
+	if settings.BasicAuthEnabled {
+		credentials := settings.BasicAuthUser + ":" + settings.DecryptedSecureJSONData["basicAuthPassword"]
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+
+	if len(settings.JSONData) == 0 {
+		return header, nil
+	}
+	var jsonData map[string]any
+	if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+		return nil, fmt.Errorf("error reading settings: %w", err)
+	}
+	for i := 1; ; i++ {
+		name, ok := jsonData[fmt.Sprintf("httpHeaderName%d", i)].(string)
+		if !ok {
+			break
+		}
+		if name == "" {
+			continue
+		}
+		header.Set(name, settings.DecryptedSecureJSONData[fmt.Sprintf("httpHeaderValue%d", i)])
+	}
+	return header, nil
+}
@@ -0,0 +1,110 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailURL(t *testing.T) {
+	u, err := tailURL("https://loki.example.com/prefix/", `{job="app"}`)
+	require.NoError(t, err)
+	require.Equal(t, "wss://loki.example.com/prefix/loki/api/v2alpha/tail?query=%7Bjob%3D%22app%22%7D", u.String())
+
+	u, err = tailURL("http://localhost:3100", `{job="app"}`)
+	require.NoError(t, err)
+	require.Equal(t, "ws://localhost:3100/loki/api/v2alpha/tail?query=%7Bjob%3D%22app%22%7D", u.String())
+}
+
+func TestTailHeaders(t *testing.T) {
+	header, err := tailHeaders(backend.DataSourceInstanceSettings{
+		BasicAuthEnabled: true,
+		BasicAuthUser:    "user",
+		JSONData:         []byte(`{"httpHeaderName1": "X-Scope-OrgID", "httpHeaderName2": "X-Other"}`),
+		DecryptedSecureJSONData: map[string]string{
+			"basicAuthPassword": "pass",
+			"httpHeaderValue1":  "tenant",
+			"httpHeaderValue2":  "other",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.Header{
+		"Authorization": {"Basic dXNlcjpwYXNz"},
+		"X-Scope-Orgid": {"tenant"},
+		"X-Other":       {"other"},
+	}, header)
+}
+
+func TestTailHub(t *testing.T) {
+	var connections atomic.Int32
+	frames := make(chan []byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		connections.Add(1)
+		for msg := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(frames)
+
+	dsInfo := &datasourceInfo{URL: server.URL, tailHeader: http.Header{"X-Scope-Orgid": {"tenant"}}}
+	hub := newTailHub(dsInfo.dialTail)
+	logger := log.New()
+
+	received1 := make(chan *data.Frame, 1)
+	received2 := make(chan *data.Frame, 1)
+	done1, unsubscribe1, err := hub.subscribe(context.Background(), logger, `{job="app"}`, func(frame *data.Frame) error {
+		received1 <- frame
+		return nil
+	})
+	require.NoError(t, err)
+	done2, unsubscribe2, err := hub.subscribe(context.Background(), logger, `{job="app"}`, func(frame *data.Frame) error {
+		received2 <- frame
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, done1, done2)
+
+	msg, err := data.NewFrame("logs", data.NewField("line", nil, []string{"hello"})).MarshalJSON()
+	require.NoError(t, err)
+	frames <- msg
+	for _, received := range []chan *data.Frame{received1, received2} {
+		select {
+		case frame := <-received:
+			require.Equal(t, "logs", frame.Name)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	require.Equal(t, int32(1), connections.Load())
+
+	unsubscribe1()
+	select {
+	case <-done1:
+		t.Fatal("upstream connection closed while it still has subscribers")
+	default:
+	}
+
+	unsubscribe2()
+	select {
+	case <-done2:
+	case <-time.After(time.Second):
+		t.Fatal("upstream connection was not closed after the last subscriber left")
+	}
+	require.Empty(t, hub.tails)
+}
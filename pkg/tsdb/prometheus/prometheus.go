@@ -9,6 +9,7 @@ import (
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 
+	"github.com/grafana/grafana/pkg/infra/httpclient/tunnel"
 	"github.com/grafana/grafana/pkg/promlib"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/azureauth"
 )
@@ -63,6 +64,10 @@ func extendClientOpts(ctx context.Context, settings backend.DataSourceInstanceSe
 		clientOpts.SigV4.Service = "aps"
 	}
 
+	if err := tunnel.Configure(settings, clientOpts); err != nil {
+		return fmt.Errorf("error configuring tunnel: %v", err)
+	}
+
 	azureSettings, err := azsettings.ReadSettings(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to read Azure settings from Grafana: %v", err)
@@ -0,0 +1,120 @@
+// Package queryerror classifies the errors of data source queries, so that clients can tell a query that has to be
+// fixed from a data source that is slow, unavailable or refuses the credentials of Grafana.
+package queryerror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Type is the type of the error of a query.
+type Type string
+
+const (
+	TypeDownstreamTimeout Type = "downstream_timeout"
+	TypeAuth              Type = "auth"
+	TypeRateLimited       Type = "rate_limited"
+	TypeBadQuery          Type = "bad_query"
+	TypeInternal          Type = "internal"
+)
+
+// MetaKey is the key of the error type in the custom meta of the first frame of a failed query.
+const MetaKey = "errorType"
+
+// Error is an error of a query backend with its type.
+type Error struct {
+	Type Type
+	Err  error
+}
+
+// New returns err with the type t.
+func New(t Type, err error) error {
+	return Error{Type: t, Err: err}
+}
+
+func (e Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// FromHTTPStatus returns the type of the error of a data source that responded with the status code.
+func FromHTTPStatus(status int) Type {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusProxyAuthRequired:
+		return TypeAuth
+	case status == http.StatusTooManyRequests:
+		return TypeRateLimited
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return TypeDownstreamTimeout
+	case status >= 400 && status < 500:
+		return TypeBadQuery
+	default:
+		return TypeInternal
+	}
+}
+
+// Classify returns the type of the error of a failed query. The type set by the backend has precedence over the
+// status of the response. Errors without either are bad queries unless the plugin reported them as its own.
+func Classify(res backend.DataResponse) Type {
+	var typed Error
+	switch {
+	case errors.As(res.Error, &typed):
+		return typed.Type
+	case res.Status != 0:
+		return FromHTTPStatus(int(res.Status))
+	case errors.Is(res.Error, context.DeadlineExceeded):
+		return TypeDownstreamTimeout
+	case res.ErrorSource == backend.ErrorSourcePlugin:
+		return TypeInternal
+	default:
+		return TypeBadQuery
+	}
+}
+
+// Annotate classifies the error of a failed query, sets the status of the response when the backend didn't and
+// adds the type to the custom meta of its first frame.
+func Annotate(res *backend.DataResponse) Type {
+	t := Classify(*res)
+	if res.Status == 0 {
+		res.Status = backend.Status(t.HTTPStatus())
+	}
+
+	if len(res.Frames) == 0 {
+		res.Frames = data.Frames{data.NewFrame("")}
+	}
+	frame := res.Frames[0]
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	switch custom := frame.Meta.Custom.(type) {
+	case nil:
+		frame.Meta.Custom = map[string]any{MetaKey: t}
+	case map[string]any:
+		custom[MetaKey] = t
+	}
+	return t
+}
+
+// HTTPStatus returns the status of an API response for queries that failed with an error of the type. Auth errors of
+// a data source are forbidden rather than unauthorized, so that they don't end the session of the user.
+func (t Type) HTTPStatus() int {
+	switch t {
+	case TypeDownstreamTimeout:
+		return http.StatusGatewayTimeout
+	case TypeAuth:
+		return http.StatusForbidden
+	case TypeRateLimited:
+		return http.StatusTooManyRequests
+	case TypeBadQuery:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,78 @@
+package queryerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tcs := []struct {
+		name     string
+		res      backend.DataResponse
+		expected Type
+	}{
+		{
+			name:     "type set by the backend",
+			res:      backend.DataResponse{Error: fmt.Errorf("query: %w", New(TypeRateLimited, errors.New("slow down"))), Status: backend.StatusBadRequest},
+			expected: TypeRateLimited,
+		},
+		{
+			name:     "status of the response",
+			res:      backend.DataResponse{Error: errors.New("denied"), Status: backend.StatusUnauthorized},
+			expected: TypeAuth,
+		},
+		{
+			name:     "deadline exceeded",
+			res:      backend.DataResponse{Error: fmt.Errorf("request: %w", context.DeadlineExceeded)},
+			expected: TypeDownstreamTimeout,
+		},
+		{
+			name:     "plugin error",
+			res:      backend.DataResponse{Error: errors.New("panic"), ErrorSource: backend.ErrorSourcePlugin},
+			expected: TypeInternal,
+		},
+		{
+			name:     "untyped error",
+			res:      backend.DataResponse{Error: errors.New("parse error")},
+			expected: TypeBadQuery,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Classify(tc.res))
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	t.Run("adds a frame with the error type", func(t *testing.T) {
+		res := backend.DataResponse{Error: New(TypeDownstreamTimeout, errors.New("timeout"))}
+		require.Equal(t, TypeDownstreamTimeout, Annotate(&res))
+		require.Equal(t, backend.StatusTimeout, res.Status)
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, map[string]any{MetaKey: TypeDownstreamTimeout}, res.Frames[0].Meta.Custom)
+	})
+
+	t.Run("keeps the status and the custom meta of the backend", func(t *testing.T) {
+		frame := data.NewFrame("").SetMeta(&data.FrameMeta{Custom: map[string]string{"frameType": "logs"}})
+		res := backend.DataResponse{Error: errors.New("bad gateway"), Status: backend.StatusBadGateway, Frames: data.Frames{frame}}
+		require.Equal(t, TypeInternal, Annotate(&res))
+		require.Equal(t, backend.StatusBadGateway, res.Status)
+		require.Equal(t, map[string]string{"frameType": "logs"}, frame.Meta.Custom)
+	})
+}
+
+func TestTypeHTTPStatus(t *testing.T) {
+	require.Equal(t, http.StatusForbidden, TypeAuth.HTTPStatus())
+	require.Equal(t, http.StatusTooManyRequests, TypeRateLimited.HTTPStatus())
+	require.Equal(t, http.StatusBadRequest, TypeBadQuery.HTTPStatus())
+	require.Equal(t, http.StatusGatewayTimeout, TypeDownstreamTimeout.HTTPStatus())
+	require.Equal(t, http.StatusInternalServerError, TypeInternal.HTTPStatus())
+}
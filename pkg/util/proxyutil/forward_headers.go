@@ -0,0 +1,166 @@
+package proxyutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// ForwardHeadersJSONDataKey is the key of the header forwarding policy in the JSON data of a data source.
+const ForwardHeadersJSONDataKey = "forwardHeaders"
+
+// ForwardHeaderRule describes one header that is forwarded to a data source.
+type ForwardHeaderRule struct {
+	// Header is the name of the header of the incoming request that is forwarded.
+	// It can be empty if Value is set.
+	Header string `json:"header,omitempty"`
+	// Rename is the name of the header sent to the data source. Defaults to Header.
+	Rename string `json:"rename,omitempty"`
+	// Value is a text/template the value of the header is rendered from, for example "{{ .Login }}".
+	// The value of the incoming header is available as .Value. Defaults to the value of the incoming header.
+	Value string `json:"value,omitempty"`
+}
+
+// ForwardHeadersData is the request context the values of forwarded headers can be rendered from.
+type ForwardHeadersData struct {
+	Login         string
+	Email         string
+	OrgID         int64
+	DashboardUID  string
+	PanelID       string
+	DatasourceUID string
+	TraceID       string
+	// Value is the value of the incoming header of the rule being applied.
+	Value string
+
+	header http.Header
+}
+
+// NewForwardHeadersData collects the data of the incoming request and the user that can be forwarded.
+func NewForwardHeadersData(ctx context.Context, header http.Header, user identity.Requester, datasourceUID string) ForwardHeadersData {
+	data := ForwardHeadersData{
+		DatasourceUID: datasourceUID,
+		TraceID:       tracing.TraceIDFromContext(ctx, false),
+		header:        header,
+	}
+	if header != nil {
+		data.DashboardUID = header.Get("X-Dashboard-Uid")
+		data.PanelID = header.Get("X-Panel-Id")
+	}
+	if user != nil && !user.IsNil() {
+		data.Login = user.GetLogin()
+		data.Email = user.GetEmail()
+		data.OrgID = user.GetOrgID()
+	}
+	return data
+}
+
+type forwardHeader struct {
+	header string
+	name   string
+	value  *template.Template
+}
+
+// ForwardHeadersPolicy decides which headers of an incoming request are forwarded to a data source and how.
+type ForwardHeadersPolicy struct {
+	headers []forwardHeader
+}
+
+// NewForwardHeadersPolicy validates the rules and compiles their templates.
+func NewForwardHeadersPolicy(rules []ForwardHeaderRule) (*ForwardHeadersPolicy, error) {
+	p := &ForwardHeadersPolicy{headers: make([]forwardHeader, 0, len(rules))}
+	for i, rule := range rules {
+		name := rule.Rename
+		if name == "" {
+			name = rule.Header
+		}
+		if name == "" {
+			return nil, fmt.Errorf("forward header rule %d: header or rename is required", i)
+		}
+		if rule.Header == "" && rule.Value == "" {
+			return nil, fmt.Errorf("forward header rule %d: header or value is required", i)
+		}
+		// the credentials can't be read under another name, nor replaced by another header
+		if rule.Header != "" && isRestrictedHeader(rule.Header) {
+			return nil, fmt.Errorf("forward header rule %d: header %q cannot be forwarded", i, rule.Header)
+		}
+		if isRestrictedHeader(name) {
+			return nil, fmt.Errorf("forward header rule %d: header %q cannot be forwarded", i, name)
+		}
+
+		h := forwardHeader{header: rule.Header, name: http.CanonicalHeaderKey(name)}
+		if rule.Value != "" {
+			tmpl, err := template.New(name).Option("missingkey=zero").Parse(rule.Value)
+			if err != nil {
+				return nil, fmt.Errorf("forward header rule %d: invalid value template: %w", i, err)
+			}
+			h.value = tmpl
+		}
+		p.headers = append(p.headers, h)
+	}
+	return p, nil
+}
+
+// ParseForwardHeadersPolicy reads the header forwarding policy from the JSON data of a data source.
+// It returns nil if the data source has no policy.
+func ParseForwardHeadersPolicy(jsonData []byte) (*ForwardHeadersPolicy, error) {
+	if len(jsonData) == 0 {
+		return nil, nil
+	}
+	var settings struct {
+		Rules []ForwardHeaderRule `json:"forwardHeaders"`
+	}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return nil, err
+	}
+	if len(settings.Rules) == 0 {
+		return nil, nil
+	}
+	return NewForwardHeadersPolicy(settings.Rules)
+}
+
+// Apply calls set for every header of the policy that has a non-empty value.
+func (p *ForwardHeadersPolicy) Apply(data ForwardHeadersData, set func(name, value string)) error {
+	if p == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, h := range p.headers {
+		data.Value = ""
+		if h.header != "" && data.header != nil {
+			data.Value = data.header.Get(h.header)
+		}
+
+		value := data.Value
+		if h.value != nil {
+			var sb strings.Builder
+			if err := h.value.Execute(&sb, data); err != nil {
+				errs = append(errs, fmt.Errorf("header %s: %w", h.name, err))
+				continue
+			}
+			value = sb.String()
+		}
+		if value == "" {
+			continue
+		}
+		set(h.name, value)
+	}
+	return errors.Join(errs...)
+}
+
+// isRestrictedHeader returns true for headers that carry credentials or are managed by Grafana itself.
+func isRestrictedHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Cookie", "X-Id-Token", "X-Ds-Authorization", IDHeaderName:
+		return true
+	}
+	return false
+}
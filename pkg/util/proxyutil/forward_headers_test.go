@@ -0,0 +1,65 @@
+package proxyutil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestForwardHeadersPolicy(t *testing.T) {
+	t.Run("forwards, renames and renders headers", func(t *testing.T) {
+		policy, err := ParseForwardHeadersPolicy([]byte(`{"forwardHeaders": [
+			{"header": "X-Dashboard-Title"},
+			{"header": "X-Panel-Title", "rename": "X-Source-Panel"},
+			{"rename": "X-Grafana-Login", "value": "{{ .Login }}@{{ .OrgID }}"},
+			{"header": "X-Dashboard-Uid", "rename": "X-Dashboard", "value": "dashboard/{{ .Value }}"},
+			{"header": "X-Missing"}
+		]}`))
+		require.NoError(t, err)
+
+		incoming := http.Header{}
+		incoming.Set("X-Dashboard-Title", "Overview")
+		incoming.Set("X-Panel-Title", "Errors")
+		incoming.Set("X-Dashboard-Uid", "abc")
+		usr := &user.SignedInUser{Login: "admin", OrgID: 2}
+
+		forwarded := http.Header{}
+		err = policy.Apply(NewForwardHeadersData(context.Background(), incoming, usr, "ds"), forwarded.Set)
+		require.NoError(t, err)
+		require.Equal(t, http.Header{
+			"X-Dashboard-Title": {"Overview"},
+			"X-Source-Panel":    {"Errors"},
+			"X-Grafana-Login":   {"admin@2"},
+			"X-Dashboard":       {"dashboard/abc"},
+		}, forwarded)
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		policy, err := ParseForwardHeadersPolicy([]byte(`{"timeout": 10}`))
+		require.NoError(t, err)
+		require.Nil(t, policy)
+		require.NoError(t, policy.Apply(ForwardHeadersData{}, func(string, string) { t.Fatal("nothing should be forwarded") }))
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		for _, rule := range []ForwardHeaderRule{
+			{},
+			{Rename: "X-Empty"},
+			{Header: "Authorization"},
+			{Header: "X-Token", Rename: "authorization"},
+			{Header: "Cookie", Rename: "X-Foo"},
+			{Header: "authorization", Rename: "X-Auth"},
+			{Header: "X-Id-Token", Rename: "X-Identity"},
+			{Header: "X-DS-Authorization", Rename: "X-Upstream", Value: "Bearer {{ .Value }}"},
+			{Header: IDHeaderName, Rename: "X-Grafana-Identity"},
+			{Header: "X-Broken", Value: "{{ .Login "},
+		} {
+			_, err := NewForwardHeadersPolicy([]ForwardHeaderRule{rule})
+			require.Error(t, err, "%+v", rule)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Package resourcehash computes a hash of the resources written by the provisioning APIs, which only changes when the
+// resource changes, and the fields changed by a write. Clients such as Terraform use them to detect drift and to tell
+// a no-op from an update, instead of comparing the resources.
+package resourcehash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"sort"
+)
+
+// Header is the header of the hash of the resource in the responses which write or hash a resource.
+const Header = "X-Grafana-Resource-Hash"
+
+// Result is the hash of a resource after a write, and the fields the write changed.
+type Result struct {
+	// FieldsChanged are the names of the fields changed by the write, sorted by name. It's empty if the resource
+	// was unchanged.
+	FieldsChanged []string `json:"fieldsChanged"`
+	Hash          string   `json:"hash"`
+}
+
+// State is the state of a resource, from which its hash and the fields changed by a write are computed.
+type State map[string]json.RawMessage
+
+// New returns the state of a resource, which must marshal to a JSON object. The volatile fields, which the server sets
+// on each write, are left out.
+func New(resource any, volatileFields ...string) (State, error) {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	state := State{}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+	for _, field := range volatileFields {
+		delete(state, field)
+	}
+	return state, nil
+}
+
+// Hash returns the SHA-256 of the state. The fields are marshaled in the order of their names, so the hash is stable.
+func (s State) Hash() (string, error) {
+	raw, err := json.Marshal(map[string]json.RawMessage(s))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FieldsChanged returns the names of the fields which differ from the state before, sorted by name. All the fields
+// are changed if the resource did not exist before.
+func (s State) FieldsChanged(before State) []string {
+	changed := []string{}
+	for field, value := range s {
+		if previous, ok := before[field]; !ok || !rawEqual(previous, value) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range before {
+		if _, ok := s[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Compare returns the hash of the state and the fields changed since the state before, which is nil if the resource
+// did not exist. Fields which cannot be compared, such as secrets, are passed as changed, and are added to the fields
+// changed if the state has them.
+func (s State) Compare(before State, changed ...string) (Result, error) {
+	hash, err := s.Hash()
+	if err != nil {
+		return Result{}, err
+	}
+	fieldsChanged := s.FieldsChanged(before)
+	for _, field := range changed {
+		if _, ok := s[field]; ok && !slices.Contains(fieldsChanged, field) {
+			fieldsChanged = append(fieldsChanged, field)
+		}
+	}
+	sort.Strings(fieldsChanged)
+	return Result{FieldsChanged: fieldsChanged, Hash: hash}, nil
+}
+
+// Unchanged returns the state of the current resource, and true if writing the candidate would not change it.
+func Unchanged(current, candidate any, volatileFields ...string) (State, bool, error) {
+	before, err := New(current, volatileFields...)
+	if err != nil {
+		return nil, false, err
+	}
+	after, err := New(candidate, volatileFields...)
+	if err != nil {
+		return nil, false, err
+	}
+	return before, len(after.FieldsChanged(before)) == 0, nil
+}
+
+func rawEqual(a, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+	if err := json.Compact(&compactA, a); err != nil {
+		return false
+	}
+	if err := json.Compact(&compactB, b); err != nil {
+		return false
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
@@ -0,0 +1,78 @@
+package resourcehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type template struct {
+	Name       string `json:"name"`
+	Template   string `json:"template"`
+	Provenance string `json:"provenance,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
+func TestState(t *testing.T) {
+	tmpl := template{Name: "slack", Template: `{{ define "slack" }}{{ end }}`, Version: "1"}
+
+	before, err := New(tmpl, "version")
+	require.NoError(t, err)
+	require.NotContains(t, before, "version")
+
+	t.Run("hash ignores the volatile fields", func(t *testing.T) {
+		updated := tmpl
+		updated.Version = "2"
+		after, err := New(updated, "version")
+		require.NoError(t, err)
+
+		beforeHash, err := before.Hash()
+		require.NoError(t, err)
+		afterHash, err := after.Hash()
+		require.NoError(t, err)
+		require.Equal(t, beforeHash, afterHash)
+		require.Empty(t, after.FieldsChanged(before))
+	})
+
+	t.Run("fields changed are sorted", func(t *testing.T) {
+		updated := tmpl
+		updated.Template = `{{ define "slack" }}{{ .Status }}{{ end }}`
+		updated.Provenance = "api"
+		after, err := New(updated, "version")
+		require.NoError(t, err)
+
+		beforeHash, err := before.Hash()
+		require.NoError(t, err)
+		afterHash, err := after.Hash()
+		require.NoError(t, err)
+		require.NotEqual(t, beforeHash, afterHash)
+		require.Equal(t, []string{"provenance", "template"}, after.FieldsChanged(before))
+	})
+
+	t.Run("all fields changed if the resource did not exist", func(t *testing.T) {
+		require.Equal(t, []string{"name", "template"}, before.FieldsChanged(nil))
+	})
+
+	t.Run("fields which cannot be compared are added to the fields changed", func(t *testing.T) {
+		result, err := before.Compare(before, "template", "secrets")
+		require.NoError(t, err)
+		require.Equal(t, []string{"template"}, result.FieldsChanged)
+
+		hash, err := before.Hash()
+		require.NoError(t, err)
+		require.Equal(t, hash, result.Hash)
+	})
+
+	t.Run("unchanged compares the resources without the volatile fields", func(t *testing.T) {
+		updated := tmpl
+		updated.Version = "2"
+		_, noop, err := Unchanged(tmpl, updated, "version")
+		require.NoError(t, err)
+		require.True(t, noop)
+
+		updated.Template = ""
+		_, noop, err = Unchanged(tmpl, updated, "version")
+		require.NoError(t, err)
+		require.False(t, noop)
+	})
+}
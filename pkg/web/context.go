@@ -95,6 +95,73 @@ func RemoteAddr(req *http.Request) string {
 	return addr
 }
 
+// TrustedRemoteAddr returns the address of the client of a request, or nil if it can't be parsed. Unlike
+// RemoteAddr, the X-Forwarded-For header is only read when the peer of the request is one of the trusted proxies,
+// and it is read from the right: every proxy appends the address of its peer, so the address of the client is the
+// rightmost one which isn't a trusted proxy, and the entries on its left can be spoofed by the client.
+func TrustedRemoteAddr(req *http.Request, trustedProxies []*net.IPNet) net.IP {
+	addr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(addr)
+	if !trustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip = net.ParseIP(strings.TrimSpace(hops[i]))
+		if !trustedProxy(ip, trustedProxies) {
+			return ip
+		}
+	}
+	// every hop is a trusted proxy, the leftmost one sent the request
+	return ip
+}
+
+// ParseCIDRs parses the CIDRs, reading the IP addresses as single host CIDRs.
+func ParseCIDRs(raw []string) ([]*net.IPNet, error) {
+	cidrs := make([]*net.IPNet, 0, len(raw))
+	for _, r := range raw {
+		r = strings.TrimSpace(r)
+		if !strings.Contains(r, "/") {
+			ip := net.ParseIP(r)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", r)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", r)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
+func trustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range trustedProxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 const (
 	headerContentType = "Content-Type"
 	contentTypeJSON   = "application/json; charset=UTF-8"
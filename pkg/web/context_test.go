@@ -1,11 +1,13 @@
 package web
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 )
@@ -98,6 +100,55 @@ func TestContext_RemoteAddr(t *testing.T) {
 	}
 }
 
+func TestTrustedRemoteAddr(t *testing.T) {
+	_, trusted, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	trustedProxies := []*net.IPNet{trusted}
+
+	request := func(remoteAddr string, forwardedFor ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		for _, header := range forwardedFor {
+			r.Header.Add("X-Forwarded-For", header)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{name: "without header", req: request("203.0.113.5:1234"), want: "203.0.113.5"},
+		{name: "ignores the header of untrusted peers", req: request("203.0.113.5:1234", "198.51.100.7"), want: "203.0.113.5"},
+		{name: "reads the header of trusted proxies", req: request("10.1.2.3:1234", "198.51.100.7"), want: "198.51.100.7"},
+		{name: "ignores the entries set by the client", req: request("10.1.2.3:1234", "192.0.2.1, 198.51.100.7"), want: "198.51.100.7"},
+		{name: "skips the trusted proxies", req: request("10.1.2.3:1234", "192.0.2.1, 198.51.100.7, 10.4.5.6"), want: "198.51.100.7"},
+		{name: "reads every header", req: request("10.1.2.3:1234", "192.0.2.1", "198.51.100.7, 10.4.5.6"), want: "198.51.100.7"},
+		{name: "returns the leftmost proxy", req: request("10.1.2.3:1234", "10.4.5.6"), want: "10.4.5.6"},
+		{name: "returns the proxy without header", req: request("10.1.2.3:1234"), want: "10.1.2.3"},
+		{name: "rejects invalid entries", req: request("10.1.2.3:1234", "198.51.100.7, not an IP"), want: "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TrustedRemoteAddr(tt.req, trustedProxies).String())
+		})
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	cidrs, err := ParseCIDRs([]string{"10.0.0.0/8", " 192.168.1.10 ", "2001:db8::/32", "2001:db8::1"})
+	require.NoError(t, err)
+	require.Len(t, cidrs, 4)
+	assert.Equal(t, "192.168.1.10/32", cidrs[1].String())
+	assert.Equal(t, "2001:db8::1/128", cidrs[3].String())
+
+	_, err = ParseCIDRs([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+	_, err = ParseCIDRs([]string{"not-an-ip"})
+	require.Error(t, err)
+}
+
 func TestContext_noHandler(t *testing.T) {
 	recorder := httptest.NewRecorder()
 
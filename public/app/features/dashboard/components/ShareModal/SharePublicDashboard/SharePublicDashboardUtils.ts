@@ -11,6 +11,7 @@ import { supportedDatasources } from './SupportedPubdashDatasources';
 export enum PublicDashboardShareType {
   PUBLIC = 'public',
   EMAIL = 'email',
+  LINK = 'link',
 }
 
 export interface PublicDashboardSettings {
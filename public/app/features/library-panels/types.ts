@@ -23,6 +23,7 @@ export interface LibraryElementConnectionDTO {
   elementId: number;
   connectionId: number;
   connectionUid: string;
+  connectionTitle: string;
   created: string;
   createdBy: LibraryElementDTOMetaUser;
 }
@@ -32,6 +32,7 @@ export interface DashboardSearchHit extends WithAccessControlMetadata {
   sortMetaName?: string;
   isDeleted?: boolean;
   permanentlyDeleteDate?: string;
+  lintWarnings?: number;
 }
 
 /**
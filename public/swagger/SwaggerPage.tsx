@@ -16,7 +16,8 @@ export const Page = () => {
   const urls = useAsync(async () => {
     const v2 = { label: 'Grafana API (OpenAPI v2)', key: 'openapi2', value: 'public/api-merged.json' };
     const v3 = { label: 'Grafana API (OpenAPI v3)', key: 'openapi3', value: 'public/openapi3.json' };
-    const urls: Array<SelectableValue<string>> = [v2, v3];
+    const merged = { label: 'Grafana API and API groups (OpenAPI v3)', key: 'merged', value: 'api/openapi/v3' };
+    const urls: Array<SelectableValue<string>> = [v2, v3, merged];
 
     const rsp = await fetch('openapi/v3');
     const apis = await rsp.json();